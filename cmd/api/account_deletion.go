@@ -0,0 +1,198 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/accesscontrol"
+	"khel/internal/domain/users"
+	"khel/internal/jobs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type AccountDeletionResponse struct {
+	DeletionScheduledFor time.Time `json:"deletion_scheduled_for"`
+}
+
+// purgeDeletedAccountsBatch caps how many accounts one run anonymizes; the
+// rest wait for the next run.
+const purgeDeletedAccountsBatch = 100
+
+// cancelPendingDeletion runs on sign-in: coming back within the grace period
+// keeps the account.
+func (app *application) cancelPendingDeletion(ctx context.Context, user *users.User) error {
+	if user.DeletionScheduledFor == nil {
+		return nil
+	}
+	cancelled, err := app.store.Users.CancelDeletion(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if cancelled {
+		app.logger.Infow("account deletion cancelled by sign-in", "user_id", user.ID)
+	}
+	user.DeletionScheduledFor = nil
+	return nil
+}
+
+func (app *application) runPurgeDeletedAccounts(ctx context.Context) error {
+	ids, err := app.store.Users.ListDueDeletions(ctx, purgeDeletedAccountsBatch)
+	if err != nil {
+		return err
+	}
+
+	var purged int64
+	for _, id := range ids {
+		res, err := app.store.Users.AnonymizeDue(ctx, id)
+		if err != nil {
+			// Cancelled between listing and locking.
+			if errors.Is(err, users.ErrNotFound) || errors.Is(err, users.ErrAlreadyAnonymized) {
+				continue
+			}
+			return fmt.Errorf("anonymize user %d: %w", id, err)
+		}
+		purged++
+		if res.ProfilePictureURL != nil {
+			app.enqueuePhotoDelete(*res.ProfilePictureURL)
+		}
+	}
+	jobs.SetRowsAffected(ctx, purged)
+	app.logger.Infow("purged deleted accounts", "accounts", purged)
+	return nil
+}
+
+// dataExportHandler godoc
+//
+//	@Summary		Download my data
+//	@Description	Returns the logged-in user's profile, bookings, games, orders, reviews and favorite venues. format=zip wraps the same JSON in a zip archive.
+//	@Tags			users
+//	@Produce		json
+//	@Produce		application/zip
+//	@Param			format	query		string	false	"json (default) or zip"
+//	@Success		200		{object}	users.DataExport
+//	@Failure		400		{object}	error	"Bad request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		500		{object}	error	"Internal server error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/data-export [get]
+func (app *application) dataExportHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" && format != "json" && format != "zip" {
+		app.badRequestResponse(w, r, fmt.Errorf("format must be json or zip"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	export, err := app.store.Users.ExportData(ctx, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if format != "zip" {
+		app.jsonResponse(w, http.StatusOK, export)
+		return
+	}
+
+	body, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	name := fmt.Sprintf("khel-data-%d-%s", user.ID, export.GeneratedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(name + ".json")
+	if err == nil {
+		_, err = f.Write(body)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		// Headers are already out; all we can do is log.
+		app.logger.Errorw("failed to write data export", "user_id", user.ID, "error", err)
+	}
+}
+
+// adminAnonymizeUserHandler godoc
+//
+//	@Summary		Anonymize a user (admin)
+//	@Description	Immediately scrubs a user's name, contact details, profile photo, booking contact fields, review comments and open join requests, and removes their personal lists. Bookings, games, ratings and orders are kept for reporting. This cannot be undone.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			userID	path	int	true	"User ID"
+//	@Success		204		"User anonymized"
+//	@Failure		400		{object}	error	"Bad request"
+//	@Failure		403		{object}	error	"Target is an admin"
+//	@Failure		404		{object}	error	"User not found"
+//	@Failure		409		{object}	error	"Already anonymized or still owns venues"
+//	@Failure		500		{object}	error	"Internal server error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/users/{userID}/anonymize [post]
+func (app *application) adminAnonymizeUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+	if userID == getUserFromContext(r).ID {
+		app.badRequestResponse(w, r, errors.New("cannot anonymize yourself"))
+		return
+	}
+
+	ctx := r.Context()
+	isAdmin, err := app.store.AccessControl.UserHasRole(ctx, userID, string(accesscontrol.RoleAdmin))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if isAdmin {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	venueIDs, err := app.store.Venues.GetOwnedVenueIDs(ctx, userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if len(venueIDs) > 0 {
+		app.conflictResponse(w, r, errors.New("user still owns venues"))
+		return
+	}
+
+	res, err := app.store.Users.Anonymize(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, users.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, users.ErrAlreadyAnonymized):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if res.ProfilePictureURL != nil {
+		app.enqueuePhotoDelete(*res.ProfilePictureURL)
+	}
+
+	app.recordAudit(r, audit.EntityUser, audit.ActionAnonymize, userID, nil, nil)
+	app.logger.Infow("user anonymized by admin", "user_id", userID, "admin_id", getUserFromContext(r).ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
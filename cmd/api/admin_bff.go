@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/admindashboard"
+	"khel/internal/domain/venues"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Backend-for-frontend endpoints for the admin dashboard: each one answers
+// a whole screen in one round trip, running the store calls side by side.
+
+const adminVenueHeldReviewsLimit = 10
+
+type AdminOverviewResponse struct {
+	Totals      admindashboard.Overview `json:"totals"`
+	Today       admindashboard.Today    `json:"today"`
+	Queues      admindashboard.Queues   `json:"queues"`
+	GeneratedAt time.Time               `json:"generated_at"`
+}
+
+type AdminVenueFullResponse struct {
+	Venue         venues.Venue                  `json:"venue"`
+	BookingsToday []admindashboard.VenueBooking `json:"bookings_today"`
+	HeldReviews   []admindashboard.HeldReview   `json:"held_reviews"`
+	Revenue       admindashboard.VenueRevenue   `json:"revenue"`
+	GeneratedAt   time.Time                     `json:"generated_at"`
+}
+
+// parallel runs fns concurrently and returns the first error, if any. The
+// shared ctx is cancelled as soon as one fails so the rest stop early.
+func parallel(ctx context.Context, fns ...func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, fn := range fns {
+		wg.Add(1)
+		go func(fn func(ctx context.Context) error) {
+			defer wg.Done()
+			if err := fn(ctx); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(fn)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// nepalDayStart is midnight today in Nepal.
+func nepalDayStart() (time.Time, error) {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load Nepal timezone: %w", err)
+	}
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), nil
+}
+
+// adminBFFOverviewHandler godoc
+//
+//	@Summary		Admin dashboard home
+//	@Description	Everything the dashboard home shows in one response: platform totals, today's activity (Nepal day) and the queues waiting on an admin. Totals and today's activity leave out demo venues.
+//	@Tags			superadmin-overview
+//	@Produce		json
+//	@Success		200	{object}	AdminOverviewResponse
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/admin/overview [get]
+func (app *application) adminBFFOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	dayStart, err := nepalDayStart()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 12*time.Second)
+	defer cancel()
+
+	var (
+		totals *admindashboard.Overview
+		today  *admindashboard.Today
+		queues *admindashboard.Queues
+	)
+	err = parallel(ctx,
+		func(ctx context.Context) (err error) {
+			totals, err = app.store.AdminDashboard.GetOverview(ctx)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			today, err = app.store.AdminDashboard.GetToday(ctx, dayStart, dayStart.AddDate(0, 0, 1))
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			queues, err = app.store.AdminDashboard.GetQueues(ctx)
+			return err
+		},
+	)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, AdminOverviewResponse{
+		Totals:      *totals,
+		Today:       *today,
+		Queues:      *queues,
+		GeneratedAt: time.Now(),
+	})
+}
+
+// adminBFFVenueFullHandler godoc
+//
+//	@Summary		Admin venue screen
+//	@Description	A venue with today's bookings (Nepal day), reviews held for moderation and revenue collected today and over the last 7 and 30 days.
+//	@Tags			superadmin-venue
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	AdminVenueFullResponse
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/admin/venues/{venueID}/full [get]
+func (app *application) adminBFFVenueFullHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	dayStart, err := nepalDayStart()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 12*time.Second)
+	defer cancel()
+
+	var (
+		venue    *venues.Venue
+		bookings []admindashboard.VenueBooking
+		held     []admindashboard.HeldReview
+		revenue  *admindashboard.VenueRevenue
+	)
+	err = parallel(ctx,
+		func(ctx context.Context) (err error) {
+			venue, err = app.store.Venues.GetVenueByID(ctx, venueID)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			bookings, err = app.store.AdminDashboard.ListVenueBookings(ctx, venueID, dayStart, dayStart.AddDate(0, 0, 1))
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			held, err = app.store.AdminDashboard.ListVenueHeldReviews(ctx, venueID, adminVenueHeldReviewsLimit)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			revenue, err = app.store.AdminDashboard.GetVenueRevenue(ctx, venueID, dayStart)
+			return err
+		},
+	)
+	if err != nil {
+		if errors.Is(err, venues.ErrVenueNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, AdminVenueFullResponse{
+		Venue:         *venue,
+		BookingsToday: bookings,
+		HeldReviews:   held,
+		Revenue:       *revenue,
+		GeneratedAt:   time.Now(),
+	})
+}
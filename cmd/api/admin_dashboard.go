@@ -12,7 +12,7 @@ import (
 // AdminOverview godoc
 //
 //	@Summary		Admin overview totals
-//	@Description	Returns totals for admin dashboard: users, games, venue requests, venues.
+//	@Description	Returns totals for admin dashboard: users, games, venue requests, venues. Demo venues and their games and bookings are not counted.
 //	@Tags			superadmin-overview
 //	@Produce		json
 //	@Success		200	{object}	admindashboard.Overview
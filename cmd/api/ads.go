@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"khel/internal/audit"
 	"khel/internal/domain/ads"
+	"khel/internal/params"
 	"net/http"
 	"strconv"
 	"time"
@@ -78,35 +80,13 @@ func (app *application) getAllAdsHandler(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	// Parse query parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10 // default
-	if limitStr != "" {
-		parsedLimit, err := strconv.Atoi(limitStr)
-		if err != nil || parsedLimit <= 0 {
-			app.badRequestResponse(w, r, errors.New("invalid limit parameter"))
-			return
-		}
-		if parsedLimit > 100 { // max limit to prevent abuse
-			limit = 100
-		} else {
-			limit = parsedLimit
-		}
-	}
-
-	offset := 0 // default
-	if offsetStr != "" {
-		parsedOffset, err := strconv.Atoi(offsetStr)
-		if err != nil || parsedOffset < 0 {
-			app.badRequestResponse(w, r, errors.New("invalid offset parameter"))
-			return
-		}
-		offset = parsedOffset
+	q := params.LimitOffset{Limit: 10}
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
 	}
 
-	ads, total, err := app.store.Ads.GetAllAds(ctx, limit, offset)
+	ads, total, err := app.store.Ads.GetAllAds(ctx, q.Limit, q.Offset)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -115,8 +95,8 @@ func (app *application) getAllAdsHandler(w http.ResponseWriter, r *http.Request)
 	response := map[string]interface{}{
 		"ads":    ads,
 		"total":  total,
-		"limit":  limit,
-		"offset": offset,
+		"limit":  q.Limit,
+		"offset": q.Offset,
 	}
 
 	app.jsonResponse(w, http.StatusOK, response)
@@ -228,8 +208,8 @@ func (app *application) createAdHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Upload to Cloudinary
-	imageURL, err := app.uploadAdImageToCloudinary(file, payload.Title)
+	// Upload the image
+	imageURL, err := app.uploadAdImage(file, payload.Title)
 	if err != nil {
 		app.internalServerError(w, r, fmt.Errorf("failed to upload image: %w", err))
 		return
@@ -248,11 +228,14 @@ func (app *application) createAdHandler(w http.ResponseWriter, r *http.Request)
 	ad, err := app.store.Ads.CreateAd(ctx, req)
 	if err != nil {
 		// Clean up uploaded image on failure
-		app.deletePhotoFromCloudinary(imageURL)
+		app.enqueuePhotoDelete(imageURL)
 		app.internalServerError(w, r, err)
 		return
 	}
 
+	app.recordAudit(r, audit.EntityAd, audit.ActionCreate, ad.ID, nil, ad)
+	app.enqueueImageVariants()
+
 	app.jsonResponse(w, http.StatusCreated, ad)
 }
 
@@ -380,8 +363,8 @@ func (app *application) updateAdHandler(w http.ResponseWriter, r *http.Request)
 			title = *payload.Title
 		}
 
-		// Upload new image to Cloudinary
-		newImageURL, err = app.uploadAdImageToCloudinary(file, title)
+		// Upload the new image
+		newImageURL, err = app.uploadAdImage(file, title)
 		if err != nil {
 			app.internalServerError(w, r, fmt.Errorf("failed to upload new image: %w", err))
 			return
@@ -414,10 +397,7 @@ func (app *application) updateAdHandler(w http.ResponseWriter, r *http.Request)
 	if err != nil {
 		// If update fails and we uploaded a new image, clean it up
 		if newImageURL != "" {
-			if deleteErr := app.deletePhotoFromCloudinary(newImageURL); deleteErr != nil {
-				app.logger.Errorw("failed to cleanup new uploaded image after update failure",
-					"image_url", newImageURL, "error", deleteErr)
-			}
+			app.enqueuePhotoDelete(newImageURL)
 		}
 
 		if err.Error() == "ad not found" {
@@ -434,12 +414,13 @@ func (app *application) updateAdHandler(w http.ResponseWriter, r *http.Request)
 
 	// If update was successful and we have a new image, delete the old one
 	if oldImageURL != "" && newImageURL != "" {
-		if err := app.deletePhotoFromCloudinary(oldImageURL); err != nil {
-			// Log the error but don't fail the request
-			app.logger.Errorw("failed to delete old ad image from Cloudinary",
-				"old_image_url", oldImageURL, "ad_id", aID, "error", err)
-		}
+		app.enqueuePhotoDelete(oldImageURL)
 	}
+	if newImageURL != "" {
+		app.enqueueImageVariants()
+	}
+
+	app.recordAudit(r, audit.EntityAd, audit.ActionUpdate, aID, currentAd, ad)
 
 	app.jsonResponse(w, http.StatusOK, ad)
 }
@@ -490,11 +471,10 @@ func (app *application) deleteAdHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Delete image from Cloudinary
-	if err := app.deletePhotoFromCloudinary(ad.ImageURL); err != nil {
-		app.logger.Errorw("failed to delete ad image from Cloudinary",
-			"ad_id", aID, "image_url", ad.ImageURL, "error", err)
-	}
+	// Delete the stored image
+	app.enqueuePhotoDelete(ad.ImageURL)
+
+	app.recordAudit(r, audit.EntityAd, audit.ActionDelete, aID, ad, nil)
 
 	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "ad deleted successfully"})
 }
@@ -534,6 +514,8 @@ func (app *application) toggleAdStatusHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, audit.EntityAd, audit.ActionToggle, aID, nil, ad)
+
 	app.jsonResponse(w, http.StatusOK, ad)
 }
 
@@ -674,5 +656,7 @@ func (app *application) bulkUpdateDisplayOrderHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	app.recordAudit(r, audit.EntityAd, audit.ActionReorder, nil, nil, payload.Updates)
+
 	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "display orders updated successfully"})
 }
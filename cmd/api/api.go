@@ -8,11 +8,21 @@ import (
 	"khel/docs" //this is required to generate swagger docs
 	"khel/internal/auth"
 	"khel/internal/domain/accesscontrol"
+	"khel/internal/domain/games"
+	"khel/internal/domain/organizations"
 	"khel/internal/domain/storage"
+	"khel/internal/domain/venuestaff"
+	"khel/internal/events"
+	"khel/internal/images"
+	"khel/internal/jobs"
 	"khel/internal/mailer"
+	"khel/internal/media"
+	"khel/internal/moderation"
 	"khel/internal/notifications"
 	"khel/internal/payments"
 	"khel/internal/ratelimiter"
+	"khel/internal/searchindex"
+	"khel/internal/sms"
 
 	"net/http"
 	"os"
@@ -23,7 +33,6 @@ import (
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 	"github.com/speps/go-hashids/v2"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	"go.uber.org/zap"
@@ -40,13 +49,31 @@ type application struct {
 
 	// strict limiter for public venue request endpoint and password reset endpoint 5 req/min per IP
 	venueRequestLimiter ratelimiter.Limiter
-	push                *notifications.ExpoAdapter
-	hashID              *hashids.HashID
-	payments            *payments.PaymentManager
+	// per-user limiter for revealing customer phone numbers to owners
+	contactRevealLimiter ratelimiter.Limiter
+	push                 notifications.PushSender
+	hashID               *hashids.HashID
+	payments             payments.Processor
+	jobs                 *jobs.Runner
+	events               *events.Bus
+	search               searchindex.Backend
+	// searchIndexer is nil unless the search backend keeps its own index.
+	searchIndexer searchindex.Indexer
+	images        *images.Processor
+	media         media.ObjectStorage
+	moderation    moderation.Checker
+	// sms is nil when no SMS backend is configured; guest checkout is
+	// then off.
+	sms sms.Sender
+	// per-IP limiters for guest checkout: every widget call, and the
+	// stricter one for texting codes
+	guestLimiter    ratelimiter.Limiter
+	guestOTPLimiter ratelimiter.Limiter
 }
 
 type config struct {
 	addr        string //for swagger doc fetching
+	port        string
 	db          dbConfig
 	env         string
 	apiURL      string
@@ -55,8 +82,26 @@ type config struct {
 	auth        authConfig
 	rateLimiter ratelimiter.Config
 	payment     paymentConfig
+	store       storeConfig
+	bookings    bookingsConfig
+	cors        corsConfig
+	security    securityHeadersConfig
 
 	turnstile turnstileConfig
+	// mediaDir is served at /media when uploads are stored locally.
+	mediaDir string
+}
+
+type storeConfig struct {
+	// orderCancelWindow is how long after checkout a buyer may cancel an
+	// order that has not shipped.
+	orderCancelWindow time.Duration
+}
+
+type bookingsConfig struct {
+	// pendingTTL is how long a booking request waits for the venue before
+	// it expires, unless the venue sets its own.
+	pendingTTL time.Duration
 }
 
 type turnstileConfig struct {
@@ -117,45 +162,111 @@ type khaltiConfig struct {
 func (app *application) mount() http.Handler {
 	r := chi.NewRouter()
 
-	r.Use(middleware.RequestID)
 	r.Use(middleware.StripSlashes)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(app.tracingMiddleware)
+	r.Use(app.requestLoggingMiddleware)
 	r.Use(middleware.Recoverer)
 	r.Use(app.RateLimiterMiddleware)
 
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://khel.gocloudnepal.com", "https://khel-staging.vercel.app/"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300, // Maximum value not ignored by any of major browsers
-	}))
+	r.Use(app.corsMiddleware())
+	r.Use(app.securityHeadersMiddleware)
+	r.Use(app.compressMiddleware)
 
 	//Set a timeout value on the request context (ctx), that will signal through ctx.Done() that the request has timed out and further processing should be stopped
 	r.Use(middleware.Timeout(40 * time.Second))
 
+	r.NotFound(app.routeNotFoundResponse)
+	r.MethodNotAllowed(app.methodNotAllowedResponse)
+
+	if app.config.mediaDir != "" {
+		r.Handle("/media/*", http.StripPrefix("/media/", http.FileServer(http.Dir(app.config.mediaDir))))
+	}
+
 	r.Route("/v1", func(r chi.Router) {
-		r.Get("/venue/{id}", app.getVenueDetailHandler)
+		r.With(app.etagMiddleware(cacheVenue)).Get("/venue/{id}", app.getVenueDetailHandler)
 		r.Get("/venues/search", app.searchVenuesHandler)
 		r.Get("/venues/search/fts", app.fullTextSearchVenuesHandler)
+		r.With(app.optionalAuth).Get("/search", app.searchHandler)
 		r.Get("/health", app.healthCheckHandler)
 		docsURL := fmt.Sprintf("%s/v1/swagger/doc.json", app.config.addr)
 		r.With(app.BasicAuthMiddleware()).Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL(docsURL)))
 
 		r.With(app.BasicAuthMiddleware()).Get("/debug/vars", expvar.Handler().ServeHTTP)
 
+		// Email template previews with sample data; never exposed in production.
+		if app.config.env != "production" && app.config.env != "prod" {
+			r.Route("/dev/emails", func(r chi.Router) {
+				r.Get("/", app.listEmailPreviewsHandler)
+				r.Get("/{template}", app.previewEmailHandler)
+			})
+		}
+
 		r.Route("/venue-requests", func(r chi.Router) {
 			// strict limiter ONLY for this endpoint
 			r.With(app.StrictLimiterMiddleware(app.venueRequestLimiter)).Post("/", app.createVenueRequestHandler)
 		})
 
+		// Guest checkout for the embeddable venue widget; needs SMS for
+		// the phone codes.
+		if app.sms != nil {
+			r.Route("/widget", func(r chi.Router) {
+				r.Use(app.StrictLimiterMiddleware(app.guestLimiter))
+				r.With(app.StrictLimiterMiddleware(app.guestOTPLimiter)).Post("/venues/{venueID}/guest/otp", app.requestGuestOTPHandler)
+				r.Post("/venues/{venueID}/facilities/{facilityID}/guest-bookings", app.createGuestBookingHandler)
+				r.Get("/deposits/{token}", app.getGuestDepositHandler)
+				r.Post("/deposits/{token}/pay", app.payGuestDepositHandler)
+				r.Post("/deposits/{token}/verify", app.verifyGuestDepositHandler)
+			})
+		}
+
 		r.Route("/app-reviews", func(r chi.Router) {
 			r.Use(app.AuthTokenMiddleware)
 			r.Post("/", app.submitReviewHandler)
 		})
 
+		// Public help center
+		r.Route("/help", func(r chi.Router) {
+			r.Get("/categories", app.listHelpCategoriesHandler)
+			r.Get("/articles", app.listHelpArticlesHandler)
+			r.Get("/articles/{slug}", app.getHelpArticleHandler)
+		})
+
+		r.Route("/support/tickets", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Post("/", app.createSupportTicketHandler)
+			r.Get("/", app.listMySupportTicketsHandler)
+			r.Get("/{ticketID}", app.getMySupportTicketHandler)
+			r.Post("/{ticketID}/messages", app.replySupportTicketHandler)
+			r.Post("/{ticketID}/close", app.closeSupportTicketHandler)
+		})
+
+		r.Route("/disputes", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/{disputeID}", app.getDisputeHandler)
+			r.Post("/{disputeID}/evidence", app.addDisputeEvidenceHandler)
+		})
+
+		r.Route("/bookings/{bookingID}", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/receipt", app.getBookingReceiptHandler)
+			r.Get("/timeline", app.getBookingTimelineHandler)
+			r.Get("/calendar.ics", app.bookingCalendarHandler)
+			r.Get("/qr", app.getBookingQRHandler)
+			r.Get("/refund", app.getBookingRefundHandler)
+			r.Post("/refund", app.requestRefundHandler)
+		})
+
+		r.Get("/holidays", app.listHolidaysHandler)
+		r.With(app.etagMiddleware("")).Get("/home/layout", app.getHomeLayoutHandler)
+
+		r.Route("/owner", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/settlements", app.listOwnerSettlementsHandler)
+			r.Get("/settlements/{settlementID}", app.getOwnerSettlementHandler)
+			r.With(app.IsOwnerMiddleware).Get("/venues/{venueID}/price-benchmark", app.getPriceBenchmarkHandler)
+		})
+
 		// Public ads routes
 		r.Route("/ads", func(r chi.Router) {
 			r.Get("/active", app.getActiveAdsHandler)
@@ -178,9 +289,39 @@ func (app *application) mount() http.Handler {
 			r.Post("/bulk-update-order", app.bulkUpdateDisplayOrderHandler)
 		})
 
+		r.Route("/admin/home-layout", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Use(app.RequireRoleMiddleware(accesscontrol.RoleMerchant))
+
+			r.Get("/", app.adminGetHomeLayoutHandler)
+			r.Put("/", app.adminReplaceHomeLayoutHandler)
+		})
+
+		r.With(app.AuthTokenMiddleware, app.RequireRoleMiddleware(accesscontrol.RoleAdmin)).
+			Get("/admin/audit-logs", app.listAuditLogsHandler)
+		r.With(app.AuthTokenMiddleware, app.RequireRoleMiddleware(accesscontrol.RoleAdmin)).
+			Post("/admin/impersonate/{userID}", app.impersonateUserHandler)
+		r.With(app.AuthTokenMiddleware, app.RequireRoleMiddleware(accesscontrol.RoleAdmin)).
+			Get("/admin/overview", app.adminBFFOverviewHandler)
+		r.With(app.AuthTokenMiddleware, app.RequireRoleMiddleware(accesscontrol.RoleAdmin)).
+			Get("/admin/venues/{venueID}/full", app.adminBFFVenueFullHandler)
+		r.Route("/admin/venues", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Use(app.RequireRoleMiddleware(accesscontrol.RoleAdmin))
+
+			r.Get("/review-queue", app.adminVenueReviewQueueHandler)
+			r.Get("/{venueID}/review", app.adminGetVenueReviewHandler)
+			r.Post("/{venueID}/review", app.adminReviewVenueHandler)
+			r.Get("/{venueID}/booking-rules", app.getBookingRulesHandler)
+			r.Put("/{venueID}/booking-rules", app.setBookingRulesHandler)
+			r.Delete("/{venueID}/booking-rules", app.deleteBookingRulesHandler)
+		})
+
 		r.With(app.optionalAuth).Get("/venues/list-venues", app.listVenuesHandler)
+		r.Get("/venues/map-clusters", app.venueMapClustersHandler)
 
 		r.With(app.optionalAuth).Get("/venues/{venueID}/reviews", app.getVenueReviewsHandler)
+		r.With(app.optionalAuth).Get("/venues/{venueID}/announcements", app.listVenueAnnouncementsHandler)
 		r.With(app.optionalAuth).Get("/venues/{venueID}/facilities", app.listFacilitiesHandler)
 		r.With(app.optionalAuth).Get("/venues/{venueID}/facilities/{facilityID}", app.getFacilityHandler)
 		r.With(app.optionalAuth).Get("/venues/{venueID}/facilities/{facilityID}/pricing", app.getFacilityPricingHandler)
@@ -197,77 +338,157 @@ func (app *application) mount() http.Handler {
 			r.Post("/", app.createVenueHandler)
 			r.Post("/{venueID}/reviews", app.createVenueReviewHandler)
 			r.Post("/{venueID}/cancel-bookings/{bookingID}", app.cancelBookingHandler)
+			r.Post("/{venueID}/slot-alerts", app.createSlotAlertHandler)
 			r.Post("/{venueID}/bookings", app.bookVenueHandler)
 
 			r.Post("/{venueID}/favorite", app.addFavoriteHandler)      // Add favorite
 			r.Delete("/{venueID}/favorite", app.removeFavoriteHandler) // Remove favorite
 
-			// Routes that require venue ownership
+			// Routes for the venue's owner and staff. Staff run bookings and
+			// games, managers run the venue, and only owners change its status,
+			// verification, staff or delete it.
 			r.Route("/{venueID}", func(r chi.Router) {
-				r.Use(app.IsOwnerMiddleware)
-
-				//New facility management routes
-
-				r.Post("/facilities", app.createFacilityHandler)
-				r.Patch("/facilities/{facilityID}", app.updateFacilityHandler)
-				r.Delete("/facilities/{facilityID}", app.deleteFacilityHandler)
-
-				// Facility images management
-				r.Get("/facilities/{facilityID}/photos", app.getFacilityAllPhotosHandler)
-				r.Post("/facilities/{facilityID}/photos", app.uploadFacilityPhotoHandler)
-				r.Delete("/facilities/{facilityID}/photos", app.deleteFacilityPhotoHandler)
-				// Facility based pricing routes
-
-				r.Post("/facilities/{facilityID}/pricing", app.createFacilityPricingHandler)
-				r.Put("/facilities/{facilityID}/pricing/{pricingID}", app.updateFacilityPricingHandler)
-				r.Delete("/facilities/{facilityID}/pricing/{pricingID}", app.deleteFacilityPricingHandler)
-
-				// facility booking and available for venue owner
-
-				r.Post("/facilities/{facilityID}/bookings/manual", app.createManualFacilityBookingHandler)
-
-				// Facility booking view for venue owner
-				r.Get("/facilities/{facilityID}/pending-bookings", app.getPendingFacilityBookingsHandler)
-				r.Get("/facilities/{facilityID}/scheduled-bookings", app.getScheduledFacilityBookingsHandler)
-				r.Get("/facilities/{facilityID}/canceled-bookings", app.getCanceledFacilityBookingsHandler)
-
-				r.Get("/customers", app.listVenueCustomersHandler)
-				r.Get("/customers/{userID}", app.getVenueCustomerDetailHandler)
-				r.Get("/earnings", app.getVenueEarningsHandler)
-				r.Post("/games/{bookingID}/checkout", app.checkoutGameHandler)
-
-				r.Get("/inventory", app.listInventoryItemsHandler)
-				r.Post("/inventory", app.createInventoryItemHandler)
-				r.Patch("/inventory/{itemID}", app.updateInventoryItemHandler)
-				r.Delete("/inventory/{itemID}", app.deleteInventoryItemHandler)
-
-				r.Get("/games/active", app.listActiveGamesHandler)
-				r.Get("/games/{bookingID}", app.getGameDetailHandler)
-				r.Post("/games/{bookingID}/items", app.addItemToGameHandler)
-
-				r.Patch("/status", app.updateVenueStatusOwnerHandler)
-				r.Post("/bookings/manual", app.createManualBookingHandler)
-				r.Get("/pricing", app.getVenuePricing)
-				r.Delete("/", app.deleteVenueHandler)
-				r.Get("/pending-bookings", app.getPendingBookingsHandler)
-				r.Get("/canceled-bookings", app.getCanceledBookingsHandler)
-				r.Get("/venue-info", app.getVenueInfoHandler)
-				r.Get("/scheduled-bookings", app.getScheduledBookingsHandler)
-				r.Post("/pending-bookings/{bookingID}/accept", app.acceptBookingHandler)
-				r.Post("/pending-bookings/{bookingID}/reject", app.rejectBookingHandler)
-				r.Post("/pricing", app.createVenuePricingHandler)
-				r.Put("/pricing/{pricingID}", app.updateVenuePricingHandler)
-				r.Delete("/pricing/{pricingID}", app.deleteVenuePricingHandler)
-				r.Patch("/", app.updateVenueInfo)
-				r.Get("/photos", app.getVenueAllPhotosHandler)
-				r.Delete("/photos", app.deleteVenuePhotoHandler)
-				r.Post("/photos", app.uploadVenuePhotoHandler)
+				r.Group(func(r chi.Router) {
+					r.Use(app.requireVenueRole(venuestaff.RoleOwner, venuestaff.RoleManager, venuestaff.RoleStaff))
+
+					// facility booking and available for venue owner
+
+					r.Post("/facilities/{facilityID}/bookings/manual", app.createManualFacilityBookingHandler)
+
+					// Facility booking view for venue owner
+					r.Get("/facilities/{facilityID}/pending-bookings", app.getPendingFacilityBookingsHandler)
+					r.Get("/facilities/{facilityID}/scheduled-bookings", app.getScheduledFacilityBookingsHandler)
+					r.Get("/facilities/{facilityID}/canceled-bookings", app.getCanceledFacilityBookingsHandler)
+
+					r.Post("/games/{bookingID}/checkout", app.checkoutGameHandler)
+					r.Get("/games/active", app.listActiveGamesHandler)
+					r.Get("/games/{bookingID}", app.getGameDetailHandler)
+					r.Post("/games/{bookingID}/items", app.addItemToGameHandler)
+
+					r.Post("/bookings/manual", app.createManualBookingHandler)
+					r.Get("/pending-bookings", app.getPendingBookingsHandler)
+					r.Get("/canceled-bookings", app.getCanceledBookingsHandler)
+					r.Get("/scheduled-bookings", app.getScheduledBookingsHandler)
+					r.Post("/pending-bookings/{bookingID}/accept", app.acceptBookingHandler)
+					r.Post("/pending-bookings/{bookingID}/reject", app.rejectBookingHandler)
+					r.Post("/bookings/{bookingID}/reveal-contact", app.revealBookingContactHandler)
+
+					r.Get("/venue-info", app.getVenueInfoHandler)
+					r.Get("/pricing", app.getVenuePricing)
+					r.Get("/inventory", app.listInventoryItemsHandler)
+					r.Get("/closures", app.listClosuresHandler)
+					r.Get("/calendar", app.getVenueCalendarHandler)
+					r.Post("/check-in", app.checkInHandler)
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.requireVenueRole(venuestaff.RoleOwner, venuestaff.RoleManager))
+
+					//New facility management routes
+
+					r.Post("/facilities", app.createFacilityHandler)
+					r.Patch("/facilities/{facilityID}", app.updateFacilityHandler)
+					r.Delete("/facilities/{facilityID}", app.deleteFacilityHandler)
+
+					// Facility images management
+					r.Get("/facilities/{facilityID}/photos", app.getFacilityAllPhotosHandler)
+					r.Post("/facilities/{facilityID}/photos", app.uploadFacilityPhotoHandler)
+					r.Delete("/facilities/{facilityID}/photos", app.deleteFacilityPhotoHandler)
+					// Facility based pricing routes
+
+					r.Post("/facilities/{facilityID}/pricing", app.createFacilityPricingHandler)
+					r.Put("/facilities/{facilityID}/pricing/{pricingID}", app.updateFacilityPricingHandler)
+					r.Delete("/facilities/{facilityID}/pricing/{pricingID}", app.deleteFacilityPricingHandler)
+					r.Get("/facilities/{facilityID}/pricing-overrides", app.listPricingOverridesHandler)
+					r.Post("/facilities/{facilityID}/pricing-overrides", app.createPricingOverridesHandler)
+					r.Put("/facilities/{facilityID}/pricing-overrides/{overrideID}", app.updatePricingOverrideHandler)
+					r.Delete("/facilities/{facilityID}/pricing-overrides/{overrideID}", app.deletePricingOverrideHandler)
+
+					r.Get("/customers", app.listVenueCustomersHandler)
+					r.Get("/customers/{userID}", app.getVenueCustomerDetailHandler)
+					r.Get("/earnings", app.getVenueEarningsHandler)
+					r.Get("/forecast", app.getVenueForecastHandler)
+					r.Get("/response-times", app.getBookingResponseTimesHandler)
+
+					r.Post("/inventory", app.createInventoryItemHandler)
+					r.Patch("/inventory/{itemID}", app.updateInventoryItemHandler)
+					r.Delete("/inventory/{itemID}", app.deleteInventoryItemHandler)
+					r.Get("/inventory/stock", app.exportInventoryStockHandler)
+					r.Post("/inventory/stocktakes", app.createStocktakeHandler)
+					r.Get("/inventory/movements", app.listStockMovementsHandler)
+					r.Get("/inventory/suppliers", app.listSuppliersHandler)
+					r.Post("/inventory/suppliers", app.createSupplierHandler)
+					r.Put("/inventory/suppliers/{supplierID}", app.updateSupplierHandler)
+					r.Get("/inventory/purchase-orders", app.listPurchaseOrdersHandler)
+					r.Post("/inventory/purchase-orders", app.createPurchaseOrderHandler)
+					r.Get("/inventory/purchase-orders/{purchaseOrderID}", app.getPurchaseOrderHandler)
+					r.Put("/inventory/purchase-orders/{purchaseOrderID}", app.updatePurchaseOrderHandler)
+					r.Post("/inventory/purchase-orders/{purchaseOrderID}/order", app.orderPurchaseOrderHandler)
+					r.Post("/inventory/purchase-orders/{purchaseOrderID}/cancel", app.cancelPurchaseOrderHandler)
+					r.Post("/inventory/purchase-orders/{purchaseOrderID}/receive", app.receivePurchaseOrderHandler)
+
+					r.Post("/pricing", app.createVenuePricingHandler)
+					r.Put("/pricing/{pricingID}", app.updateVenuePricingHandler)
+					r.Delete("/pricing/{pricingID}", app.deleteVenuePricingHandler)
+					r.Patch("/", app.updateVenueInfo)
+					r.Get("/photos", app.getVenueAllPhotosHandler)
+					r.Delete("/photos", app.deleteVenuePhotoHandler)
+					r.Post("/photos", app.uploadVenuePhotoHandler)
+
+					r.Post("/announcements", app.createVenueAnnouncementHandler)
+					r.Get("/announcements/all", app.listOwnerVenueAnnouncementsHandler)
+					r.Patch("/announcements/{announcementID}", app.updateVenueAnnouncementHandler)
+					r.Delete("/announcements/{announcementID}", app.deleteVenueAnnouncementHandler)
+
+					r.Post("/bookings/{bookingID}/dispute", app.openVenueDisputeHandler)
+					r.Get("/disputes", app.listVenueDisputesHandler)
+
+					r.Get("/refund-policy", app.getRefundPolicyHandler)
+					r.Put("/refund-policy", app.setRefundPolicyHandler)
+					r.Get("/refunds", app.listVenueRefundsHandler)
+					r.Post("/refunds/{refundID}/approve", app.approveVenueRefundHandler)
+					r.Post("/refunds/{refundID}/reject", app.rejectVenueRefundHandler)
+
+					r.Get("/holiday-settings", app.getHolidaySettingsHandler)
+					r.Put("/holiday-settings", app.setHolidaySettingsHandler)
+					r.Put("/accessibility", app.setVenueAccessibilityHandler)
+					r.Put("/auto-accept", app.setAutoAcceptHandler)
+					r.Get("/booking-rules", app.getBookingRulesHandler)
+					r.Put("/booking-rules", app.setBookingRulesHandler)
+					r.Delete("/booking-rules", app.deleteBookingRulesHandler)
+					r.Get("/holiday-utilization", app.getHolidayUtilizationHandler)
+
+					r.Post("/closures", app.createClosureHandler)
+					r.Delete("/closures/{closureID}", app.deleteClosureHandler)
+					r.Get("/close-day", app.previewDayCloseHandler)
+					r.Post("/close-day", app.closeDayHandler)
+					r.Get("/day-closes", app.listDayClosesHandler)
+
+					r.Get("/staff", app.listVenueStaffHandler)
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.requireVenueRole(venuestaff.RoleOwner))
+
+					r.Patch("/status", app.updateVenueStatusOwnerHandler)
+
+					r.Get("/verification", app.getVenueVerificationHandler)
+					r.Post("/verification/documents", app.uploadVenueDocumentHandler)
+					r.Delete("/verification/documents/{documentID}", app.deleteVenueDocumentHandler)
+					r.Post("/verification/resubmit", app.resubmitVenueVerificationHandler)
+					r.Delete("/", app.deleteVenueHandler)
+
+					r.Post("/staff", app.inviteVenueStaffHandler)
+					r.Delete("/staff/{staffID}", app.removeVenueStaffHandler)
+				})
 			})
 
 			r.With(app.IsReviewOwnerMiddleware).Delete("/{venueID}/reviews/{reviewID}", app.deleteVenueReviewHandler)
 		})
 		// Route that does NOT require authentication
 		r.Put("/users/activate/{token}", app.activateUserHandler)
+		r.Get("/users/calendar.ics", app.calendarFeedHandler)
+		r.Get("/users/history/exports/{exportID}/download", app.downloadHistoryExportHandler)
 		r.With(app.AuthTokenIgnoreExpiryMiddleware).Post("/users/logout", app.logoutHandler)
 		r.Route("/users", func(r chi.Router) {
 
@@ -277,22 +498,112 @@ func (app *application) mount() http.Handler {
 			r.Post("/push-tokens/bulk-remove", app.bulkRemoveTokensHandler)
 			r.Delete("/push-tokens", app.removePushTokenHandler)
 			r.Get("/bookings", app.getBookingsByUserHandler)
+			r.Get("/calendar-link", app.getCalendarLinkHandler)
+			r.Get("/history/export", app.exportHistoryHandler)
+			r.Post("/bookings/{bookingID}/rebook", app.rebookHandler)
+			r.Route("/bookings/{bookingID}/split", func(r chi.Router) {
+				r.Get("/", app.getPaymentSplitHandler)
+				r.Post("/", app.createPaymentSplitHandler)
+				r.Delete("/", app.cancelPaymentSplitHandler)
+			})
+			r.Post("/payment-shares/{shareID}/pay", app.payShareHandler)
+			r.Post("/payment-shares/{shareID}/verify", app.verifyShareHandler)
+			r.Route("/price-alerts", func(r chi.Router) {
+				r.Get("/", app.listPriceAlertsHandler)
+				r.Post("/", app.createPriceAlertHandler)
+				r.Delete("/{alertID}", app.deletePriceAlertHandler)
+			})
+			r.Route("/saved-searches", func(r chi.Router) {
+				r.Get("/", app.listSavedSearchesHandler)
+				r.Post("/", app.createSavedSearchHandler)
+				r.Put("/{searchID}", app.updateSavedSearchHandler)
+				r.Delete("/{searchID}", app.deleteSavedSearchHandler)
+			})
+			r.Route("/slot-alerts", func(r chi.Router) {
+				r.Get("/", app.listSlotAlertsHandler)
+				r.Delete("/{alertID}", app.deleteSlotAlertHandler)
+			})
+			r.Post("/bookings/{bookingID}/dispute", app.openUserDisputeHandler)
+			r.Get("/disputes", app.listMyDisputesHandler)
+			r.Get("/reliability", app.getMyReliabilityHandler)
+			r.Get("/blocked", app.listBlockedUsersHandler)
+			r.Get("/venue-invitations", app.listVenueInvitationsHandler)
+			r.Post("/venue-invitations/{invitationID}/accept", app.acceptVenueInvitationHandler)
+			r.Delete("/venue-invitations/{invitationID}", app.declineVenueInvitationHandler)
+			r.Get("/game-invitations", app.listMyGameInvitationsHandler)
+			r.Post("/game-invitations/{invitationID}/accept", app.acceptGameInvitationHandler)
+			r.Delete("/game-invitations/{invitationID}", app.declineGameInvitationHandler)
 			r.Get("/me", app.getCurrentUserHandler)
 			r.Delete("/me", app.deleteUserAccountHandler)
+			r.Get("/data-export", app.dataExportHandler)
 			r.Patch("/update-profile", app.editProfileHandler)
 			r.Put("/", app.updateUserHandler)
 			r.Post("/profile-picture", app.uploadProfilePictureHandler)
 			r.Put("/profile-picture", app.updateProfilePictureHandler)
+			r.Get("/availability", app.getAvailabilityHandler)
+			r.Put("/availability", app.updateAvailabilityHandler)
+			r.Get("/feed", app.getFeedHandler)
+			r.Get("/privacy", app.getPrivacyHandler)
+			r.Put("/privacy", app.updatePrivacyHandler)
+			r.Get("/notification-preferences", app.getNotificationPreferencesHandler)
+			r.Put("/notification-preferences", app.updateNotificationPreferencesHandler)
+			r.Route("/notifications", func(r chi.Router) {
+				r.Get("/", app.listNotificationsHandler)
+				r.Delete("/", app.clearNotificationsHandler)
+				r.Get("/unread-count", app.unreadNotificationCountHandler)
+				r.Post("/read-all", app.markAllNotificationsReadHandler)
+				r.Post("/{notificationID}/read", app.markNotificationReadHandler)
+			})
+
+			r.Route("/friends", func(r chi.Router) {
+				r.Get("/", app.listFriendsHandler)
+				r.Get("/games-nearby", app.friendsGamesNearbyHandler)
+				r.Get("/requests", app.listFriendRequestsHandler)
+				r.Post("/requests/{requestID}/accept", app.acceptFriendRequestHandler)
+				r.Delete("/requests/{requestID}", app.deleteFriendRequestHandler)
+			})
 
 			r.Route("/{userID}", func(r chi.Router) {
 				r.Use(app.AuthTokenMiddleware)
 				r.Put("/follow", app.followUserHandler)
 				r.Put("/unfollow", app.unfollowUserHandler)
+				r.Post("/friend-request", app.sendFriendRequestHandler)
+				r.Delete("/friend", app.removeFriendHandler)
+				r.Put("/block", app.blockUserHandler)
+				r.Delete("/block", app.unblockUserHandler)
+				r.Post("/report", app.reportUserHandler)
+				r.Get("/games", app.getUserGamesHandler)
+			})
+		})
+
+		r.Route("/organizations", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/", app.listMyOrganizationsHandler)
+			r.Post("/", app.createOrganizationHandler)
+
+			r.Route("/{orgID}", func(r chi.Router) {
+				owner := app.requireOrgRole(organizations.RoleOwner)
+				manager := app.requireOrgRole(organizations.RoleOwner, organizations.RoleManager)
+
+				r.With(app.requireOrgRole(organizations.RoleOwner, organizations.RoleManager, organizations.RoleStaff)).Get("/", app.getOrganizationHandler)
+				r.With(owner).Patch("/", app.updateOrganizationHandler)
+				r.With(owner).Put("/logo", app.uploadOrganizationLogoHandler)
+				r.With(manager).Get("/members", app.listOrganizationMembersHandler)
+				r.With(owner).Post("/members", app.addOrganizationMemberHandler)
+				r.With(owner).Patch("/members/{userID}", app.updateOrganizationMemberHandler)
+				r.With(owner).Delete("/members/{userID}", app.removeOrganizationMemberHandler)
+				r.With(manager).Get("/venues", app.listOrganizationVenuesHandler)
+				r.With(owner).Put("/venues/{venueID}", app.attachOrganizationVenueHandler)
+				r.With(owner).Delete("/venues/{venueID}", app.detachOrganizationVenueHandler)
+				r.With(manager).Get("/dashboard", app.getOrganizationDashboardHandler)
+				r.With(owner).Get("/settlements", app.getOrganizationSettlementHandler)
 			})
 		})
 
-		r.With(app.optionalAuth).Get("/games/get-games", app.getGamesHandler)
+		r.With(app.optionalAuth, deprecated(v1GamesListDeprecation)).Get("/games/get-games", app.getGamesHandler)
 		r.With(app.optionalAuth).Get("/games/{gameID}", app.getGameDetailsHandler)
+		r.Get("/games/{gameID}/seats", app.getGameSeatsHandler)
+		r.Get("/games/{gameID}/seats/stream", app.streamGameSeatsHandler)
 
 		r.With(app.optionalAuth).Get("/games/{venueID}/upcoming", app.getUpcomingGamesByVenueHandler)
 
@@ -308,19 +619,46 @@ func (app *application) mount() http.Handler {
 
 			})
 
+			r.With(app.AuthTokenMiddleware).Post("/invitations/{code}/accept", app.acceptInviteLinkHandler)
+
 			r.Route("/{gameID}", func(r chi.Router) {
 				r.Use(app.AuthTokenMiddleware)
 				r.Post("/shortlist", app.addShortlistedGameHandler)      // Add game to shortlist
 				r.Delete("/shortlist", app.removeShortlistedGameHandler) // Remove game from shortlist
 				r.With(app.CheckGameAdmin).Post("/assign-assistant/{playerID}", app.AssignAssistantHandler)
 				r.Get("/players", app.getGamePlayersHandler)
+				r.Get("/qr", app.getGameQRHandler)
 				r.Post("/request", app.CreateJoinRequest)
 				r.Delete("/request", app.DeleteJoinRequest)
-				r.With(app.RequireGameAdminAssistant).Post("/accept", app.AcceptJoinRequest)
-				r.With(app.RequireGameAdminAssistant).Get("/requests", app.getAllGameJoinRequestsHandler)
-				r.With(app.RequireGameAdminAssistant).Post("/reject", app.RejectJoinRequest)
-				r.With(app.RequireGameAdminAssistant).Patch("/toggle-match-full", app.toggleMatchFullHandler)
+				r.With(app.RequireGamePermission(games.PermAcceptRequests)).Post("/accept", app.AcceptJoinRequest)
+				r.With(app.RequireGamePermission(games.PermAcceptRequests)).Get("/requests", app.getAllGameJoinRequestsHandler)
+				r.With(app.RequireGamePermission(games.PermAcceptRequests)).Post("/reject", app.RejectJoinRequest)
+				r.With(app.RequireGamePermission(games.PermEditDetails)).Patch("/toggle-match-full", app.toggleMatchFullHandler)
+				r.With(app.RequireGamePermission(games.PermEditDetails)).Patch("/", app.updateGameDetailsHandler)
+				r.Get("/changes", app.getGameChangesHandler)
+				r.With(app.RequireGamePermission(games.PermKickPlayers)).Delete("/players/{playerID}", app.kickGamePlayerHandler)
+				r.With(app.RequireGamePermission(games.PermRecordScore)).Put("/score", app.recordGameScoreHandler)
+				r.With(app.CheckGameAdmin).Get("/assistants", app.listGameAssistantsHandler)
+				r.With(app.CheckGameAdmin).Put("/assistants/{playerID}/permissions", app.setAssistantPermissionsHandler)
 				r.With(app.RequireGameAdminAssistant).Patch("/cancel-game", app.cancelGameHandler)
+				r.With(app.CheckGameAdmin).Put("/auto-cancel", app.setGameAutoCancelHandler)
+				r.With(app.CheckGameAdmin).Put("/bench", app.setGameBenchSizeHandler)
+				r.With(app.CheckGameAdmin).Put("/join-policy", app.setGameJoinPolicyHandler)
+				r.Get("/payments", app.getGamePaymentsHandler)
+				r.With(app.CheckGameAdmin).Put("/payments/{playerID}", app.markGamePaymentHandler)
+				r.With(app.CheckGameAdmin).Delete("/payments/{playerID}", app.unmarkGamePaymentHandler)
+				r.Post("/leave", app.leaveGameHandler)
+				r.Post("/ratings", app.ratePlayersHandler)
+				r.Post("/mvp-vote", app.voteMVPHandler)
+				r.Get("/results", app.getGameResultsHandler)
+
+				r.Route("/invitations", func(r chi.Router) {
+					r.Use(app.RequireGamePermission(games.PermAcceptRequests))
+					r.Get("/", app.listGameInvitationsHandler)
+					r.Post("/", app.inviteUsersHandler)
+					r.Post("/link", app.createInviteLinkHandler)
+					r.Delete("/{invitationID}", app.revokeGameInvitationHandler)
+				})
 
 				r.Route("/questions", func(r chi.Router) {
 					r.Post("/", app.createQuestionHandler)
@@ -367,11 +705,25 @@ func (app *application) mount() http.Handler {
 			r.Post("/categories", app.createCategoryHandler)
 			r.Patch("/categories/{categoryID}", app.updateCategoryHandler)
 			r.Delete("/categories/{categoryID}", app.deleteCategoryHandler)
+			r.Post("/brands/{brandID}/restore", app.restoreBrandHandler)
+			r.Post("/categories/{categoryID}/restore", app.restoreCategoryHandler)
+			r.Get("/categories/{categoryID}/attributes", app.listCategoryAttributesHandler)
+			r.Post("/categories/{categoryID}/attributes", app.createCategoryAttributeHandler)
+			r.Patch("/categories/{categoryID}/attributes/{attributeID}", app.updateCategoryAttributeHandler)
+			r.Delete("/categories/{categoryID}/attributes/{attributeID}", app.deleteCategoryAttributeHandler)
+
+			r.Get("/trash/brands", app.listTrashedBrandsHandler)
+			r.Get("/trash/categories", app.listTrashedCategoriesHandler)
+			r.Get("/trash/products", app.listTrashedProductsHandler)
 
 			r.Get("/products", app.adminListProductsHandler)
 			r.Get("/category/products", app.listProductsHandler)
 			r.Post("/products", app.createProductHandler)
+			r.Post("/products/import", app.importProductsCSVHandler)
+			r.Get("/products/export", app.exportProductsHandler)
 			r.Patch("/products/{productID}", app.updateProductHandler)
+			r.Delete("/products/{productID}", app.deleteProductHandler)
+			r.Post("/products/{productID}/restore", app.restoreProductHandler)
 			r.Post("/products/{productID}/publish", app.publishProductHandler)
 			r.Post("/products/images", app.createProductImageHandler)
 			r.Get("/products/{productID}/images", app.listProductImagesHandler)
@@ -379,10 +731,20 @@ func (app *application) mount() http.Handler {
 			r.Patch("/products/images/{id}", app.updateProductImageHandler)
 			r.Delete("/products/images/{id}", app.deleteProductImageHandler)
 			r.Post("/products/{productID}/images/reorder", app.reorderProductImagesHandler)
+			r.Put("/products/{productID}/labels", app.setProductLabelsHandler)
+			r.Get("/labels", app.listProductLabelsHandler)
+			r.Post("/labels", app.createProductLabelHandler)
+			r.Post("/labels/refresh", app.refreshProductLabelsHandler)
+			r.Put("/labels/{labelID}", app.updateProductLabelHandler)
+			r.Delete("/labels/{labelID}", app.deleteProductLabelHandler)
 			r.Post("/products/variants", app.createVariantHandler)
 			r.Patch("/products/variants/{id}", app.updateVariantHandler)
 			r.Delete("/products/variants/{id}", app.deleteVariantHandler)
 			r.Get("/products/variants", app.listAllVariantsHandler)
+			r.Get("/variants/by-sku/{sku}", app.getVariantBySKUHandler)
+			r.Get("/variants/by-barcode/{barcode}", app.getVariantByBarcodeHandler)
+			r.Get("/variants/export", app.exportVariantsCSVHandler)
+			r.Post("/variants/import", app.importVariantsCSVHandler)
 
 			r.Get("/carts", app.adminListCartsHandler)
 			r.Get("/carts/{cartID}", app.adminGetCartHandler)
@@ -392,6 +754,7 @@ func (app *application) mount() http.Handler {
 			r.Get("/orders", app.adminListOrdersHandler)
 			r.Get("/orders/{orderID}", app.adminGetOrderHandler)
 			r.Patch("/orders/{orderID}/status", app.adminUpdateOrderStatusHandler)
+			r.Get("/analytics/margins", app.adminStoreMarginsHandler)
 
 			r.Route("/featured", func(r chi.Router) {
 				// Collections CRUD
@@ -419,22 +782,25 @@ func (app *application) mount() http.Handler {
 			r.Get("/payments/khalti", app.khaltiReturnHandler)
 			r.Get("/payments/esewa/return", app.esewaReturnHandler)
 			// ---------- PUBLIC CATALOG ----------
-			r.Get("/featured/home", app.getHomeFeaturedCollectionsHandler)
+			r.With(app.etagMiddleware(cacheCatalog)).Get("/featured/home", app.getHomeFeaturedCollectionsHandler)
+			r.With(app.etagMiddleware(cacheFeed)).Get("/feed/google.xml", app.merchantFeedHandler)
 			r.Get("/featured/collections/{collectionKey}", app.getFeaturedCollectionItemsHandler)
 
 			r.Get("/brands", app.getAllBrandsHandler)
 			r.Get("/categories", app.listCategoriesHandler)
 			r.Get("/categories/{categoryID}", app.getCategoryByIDHandler)
+			r.Get("/categories/{categoryID}/attributes", app.listCategoryAttributesHandler)
 			r.Get("/categories/tree", app.getCategoryTreeHandler)
 			r.Get("/categories/search", app.searchCategoriesHandler)
 			r.Get("/categories/search/fts", app.fullTextSearchCategoriesHandler)
-			r.Get("/products", app.listProductsHandler)
-			r.Get("/products/{productID}", app.getProductByIDHandler)
-			r.Get("/products/slug/{slug}", app.getProductDetailHandler)
+			r.With(app.optionalAuth).Get("/products", app.listProductsHandler)
+			r.With(app.etagMiddleware(cacheCatalog)).Get("/products/{productID}", app.getProductByIDHandler)
+			r.With(app.etagMiddleware(cacheCatalog)).Get("/products/slug/{slug}", app.getProductDetailHandler)
+			r.With(app.optionalAuth).Get("/products/{slug}/related", app.getRelatedProductsHandler)
 			r.Get("/{product_id}/variants", app.listVariantsByProductHandler)
 
-			r.Get("/products/search", app.searchProductsHandler)
-			r.Get("/products/search/fts", app.fullTextSearchProductsHandler)
+			r.With(app.optionalAuth).Get("/products/search", app.searchProductsHandler)
+			r.With(app.optionalAuth).Get("/products/search/fts", app.fullTextSearchProductsHandler)
 
 			r.Get("/variants/{id}", app.getVariantHandler)
 
@@ -450,8 +816,13 @@ func (app *application) mount() http.Handler {
 				r.Delete("/cart/items/{itemID}", app.removeCartItemHandler)
 				r.Delete("/cart", app.clearCartHandler)
 
+				r.Get("/wishlist", app.listWishlistHandler)
+				r.Post("/wishlist", app.addToWishlistHandler)
+				r.Delete("/wishlist/{productID}", app.removeFromWishlistHandler)
+
 				r.Get("/orders", app.listMyOrdersHandler)
 				r.Get("/orders/{orderID}", app.getMyOrderHandler)
+				r.Post("/orders/{orderID}/cancel", app.cancelMyOrderHandler)
 
 				r.Post("/checkout", app.checkoutHandler)
 				r.Post("/payments/verify", app.verifyPaymentHandler)
@@ -465,6 +836,7 @@ func (app *application) mount() http.Handler {
 			r.Use(app.RequireRoleMiddleware(accesscontrol.RoleAdmin))
 			r.Get("/users", app.adminListUsersHandler)
 			r.Get("/users/{userID}", app.AdminUserOverviewHandler)
+			r.Post("/users/{userID}/anonymize", app.adminAnonymizeUserHandler)
 			r.Get("/{userID}/roles", app.adminGetUserRolesHandler)
 			r.Post("/{userID}/roles", app.adminAssignUserRoleHandler)
 			r.Delete("/{userID}/roles/{roleID}", app.adminRemoveUserRoleHandler)
@@ -476,12 +848,89 @@ func (app *application) mount() http.Handler {
 
 			r.Get("/overview", app.adminOverviewHandler)
 
+			r.Get("/jobs", app.listJobsHandler)
+			r.Get("/jobs/runs", app.listJobRunsHandler)
+			r.Get("/jobs/schedules", app.listJobSchedulesHandler)
+			r.Get("/jobs/{jobID}", app.getJobHandler)
+			r.Post("/jobs/{jobID}/retry", app.retryJobHandler)
+
+			r.Get("/support/tickets", app.adminListSupportTicketsHandler)
+			r.Get("/support/tickets/{ticketID}", app.adminGetSupportTicketHandler)
+			r.Post("/support/tickets/{ticketID}/assign", app.adminAssignSupportTicketHandler)
+			r.Post("/support/tickets/{ticketID}/messages", app.adminReplySupportTicketHandler)
+			r.Patch("/support/tickets/{ticketID}/status", app.adminUpdateSupportTicketStatusHandler)
+
+			r.Get("/disputes", app.adminListDisputesHandler)
+			r.Get("/disputes/{disputeID}", app.adminGetDisputeHandler)
+			r.Post("/disputes/{disputeID}/resolve", app.adminResolveDisputeHandler)
+
+			r.Get("/reports", app.adminListUserReportsHandler)
+			r.Post("/reports/{reportID}/resolve", app.adminResolveUserReportHandler)
+
+			r.Get("/moderation", app.adminListModerationHandler)
+			r.Post("/moderation/{itemID}/approve", app.adminApproveModerationHandler)
+			r.Post("/moderation/{itemID}/remove", app.adminRemoveModerationHandler)
+
+			r.Get("/refunds", app.adminListRefundsHandler)
+			r.Post("/refunds/{refundID}/approve", app.adminApproveRefundHandler)
+			r.Post("/refunds/{refundID}/reject", app.adminRejectRefundHandler)
+
+			r.Get("/holidays", app.listHolidaysHandler)
+			r.Post("/holidays", app.adminCreateHolidayHandler)
+			r.Post("/holidays/import", app.adminImportHolidaysHandler)
+			r.Put("/holidays/{holidayID}", app.adminUpdateHolidayHandler)
+			r.Delete("/holidays/{holidayID}", app.adminDeleteHolidayHandler)
+
+			r.Get("/settlements", app.adminListSettlementsHandler)
+			r.Get("/settlements/{settlementID}", app.adminGetSettlementHandler)
+			r.Patch("/settlements/{settlementID}/status", app.adminUpdateSettlementStatusHandler)
+
+			r.Get("/commissions", app.adminListCommissionRatesHandler)
+			r.Post("/commissions", app.adminCreateCommissionRateHandler)
+			r.Delete("/commissions/{rateID}", app.adminDeleteCommissionRateHandler)
+			r.Get("/venues/{venueID}/commission", app.adminGetVenueCommissionHandler)
+			r.Post("/venues/{venueID}/day-closes/{date}/reopen", app.adminReopenDayHandler)
+
+			r.Get("/reviews", app.adminListReviewsHandler)
+			r.Post("/reviews/{reviewID}/approve", app.adminApproveReviewHandler)
+			r.Post("/reviews/{reviewID}/reject", app.adminRejectReviewHandler)
+
+			r.Route("/help", func(r chi.Router) {
+				r.Get("/categories", app.adminListHelpCategoriesHandler)
+				r.Post("/categories", app.adminCreateHelpCategoryHandler)
+				r.Patch("/categories/{categoryID}", app.adminUpdateHelpCategoryHandler)
+				r.Delete("/categories/{categoryID}", app.adminDeleteHelpCategoryHandler)
+
+				r.Get("/articles", app.adminListHelpArticlesHandler)
+				r.Post("/articles", app.adminCreateHelpArticleHandler)
+				r.Get("/articles/{articleID}", app.adminGetHelpArticleHandler)
+				r.Patch("/articles/{articleID}", app.adminUpdateHelpArticleHandler)
+				r.Delete("/articles/{articleID}", app.adminDeleteHelpArticleHandler)
+			})
+
+			r.Get("/demo-sandboxes", app.adminListDemoSandboxesHandler)
+			r.Post("/demo-sandboxes", app.adminCreateDemoSandboxHandler)
+			r.Patch("/demo-sandboxes/{sandboxID}", app.adminUpdateDemoSandboxHandler)
+			r.Post("/demo-sandboxes/{sandboxID}/reset", app.adminResetDemoSandboxHandler)
+			r.Delete("/demo-sandboxes/{sandboxID}", app.adminDeleteDemoSandboxHandler)
+
+			r.Get("/photo-migrations", app.adminListPhotoMigrationsHandler)
+			r.Post("/photo-migrations", app.adminStartPhotoMigrationHandler)
+			r.Get("/photo-migrations/{migrationID}", app.adminGetPhotoMigrationHandler)
+			r.Post("/photo-migrations/{migrationID}/cancel", app.adminCancelPhotoMigrationHandler)
+
+			r.Post("/search/reindex", app.adminReindexSearchHandler)
+			r.Get("/media/orphans", app.adminListOrphanedMediaHandler)
+
 			r.Get("/app-reviews", app.getAllAppReviewsHandler)
 			r.Get("/venues", app.AdminlistVenuesHandler)
 
 		})
 
 	})
+
+	// v2 only carries what changed shape; see mountV2.
+	r.Route("/v2", app.mountV2)
 	return r
 }
 
@@ -491,13 +940,8 @@ func (app *application) run(mux http.Handler, cancel context.CancelFunc) error {
 	docs.SwaggerInfo.Host = app.config.apiURL
 	docs.SwaggerInfo.BasePath = "/v1"
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
 	srv := &http.Server{
-		Addr:              "0.0.0.0:" + port,
+		Addr:              "0.0.0.0:" + app.config.port,
 		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second,
 		ReadTimeout:       2 * time.Minute,
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordAudit writes one audit trail entry for an admin mutation.
+// It is best-effort: a failure is logged but never fails the request,
+// because the mutation itself has already been committed.
+//
+// before/after are any JSON-serialisable snapshots; pass nil when there is
+// nothing on that side (create has no before, delete has no after).
+func (app *application) recordAudit(r *http.Request, entity, action string, entityID any, before, after any) {
+	e := &audit.Entry{
+		Entity: entity,
+		Action: action,
+	}
+
+	if user := getUserFromContext(r); user != nil {
+		id := user.ID
+		e.ActorID = &id
+	}
+	// Under impersonation the admin is the one acting.
+	if id, ok := getImpersonatorID(r); ok {
+		e.ActorID = &id
+	}
+	if entityID != nil {
+		id := fmt.Sprint(entityID)
+		e.EntityID = &id
+	}
+	if ip := clientIP(r); ip != "" {
+		e.IP = &ip
+	}
+	if ua := strings.TrimSpace(r.UserAgent()); ua != "" {
+		e.UserAgent = &ua
+	}
+
+	e.Before = app.auditSnapshot(entity, action, before)
+	e.After = app.auditSnapshot(entity, action, after)
+
+	// Detach from the request so a client disconnect does not drop the entry.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 3*time.Second)
+	defer cancel()
+
+	if err := app.store.Audit.Record(ctx, e); err != nil {
+		app.logger.Errorw("audit: record failed",
+			"entity", entity,
+			"entity_id", e.EntityID,
+			"action", action,
+			"error", err,
+		)
+	}
+}
+
+// auditSnapshot marshals v for the audit trail. nil values (including typed
+// nil pointers) yield no snapshot rather than a JSON null.
+func (app *application) auditSnapshot(entity, action string, v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		app.logger.Warnw("audit: marshal snapshot failed", "entity", entity, "action", action, "error", err)
+		return nil
+	}
+	if string(b) == "null" {
+		return nil
+	}
+	return b
+}
+
+// listAuditLogsHandler godoc
+//
+//	@Summary		List audit logs
+//	@Description	Returns admin mutations, newest first. All filters are optional.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			actor_id	query		int				false	"Filter by acting user ID"
+//	@Param			entity		query		string			false	"Filter by entity (brand, category, product, ad, featured_collection, featured_item, venue, venue_request, help_category, help_article, booking_dispute)"
+//	@Param			entity_id	query		string			false	"Filter by entity ID"
+//	@Param			action		query		string			false	"Filter by action (create, update, delete, restore, ...)"
+//	@Param			from		query		string			false	"Only entries at or after this time (RFC3339)"
+//	@Param			to			query		string			false	"Only entries before this time (RFC3339)"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"audit_logs + pagination metadata"
+//	@Failure		400			{object}	error			"Bad Request"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/audit-logs [get]
+func (app *application) listAuditLogsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	q := r.URL.Query()
+
+	f := audit.Filter{
+		Entity:   strings.TrimSpace(q.Get("entity")),
+		EntityID: strings.TrimSpace(q.Get("entity_id")),
+		Action:   strings.TrimSpace(q.Get("action")),
+	}
+
+	if v := strings.TrimSpace(q.Get("actor_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid actor_id"))
+			return
+		}
+		f.ActorID = &id
+	}
+	if v := strings.TrimSpace(q.Get("from")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid from: must be RFC3339"))
+			return
+		}
+		f.From = &t
+	}
+	if v := strings.TrimSpace(q.Get("to")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid to: must be RFC3339"))
+			return
+		}
+		f.To = &t
+	}
+
+	pagination := params.ParsePagination(q)
+	entries, total, err := app.store.Audit.List(ctx, f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"audit_logs": entries,
+		"pagination": pagination,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"khel/internal/domain/moderationqueue"
 	"khel/internal/domain/users"
 	"khel/internal/mailer"
 	"net/http"
@@ -16,26 +17,6 @@ import (
 	"github.com/google/uuid"
 )
 
-// ErrorBadRequestResponse represents the standard error format for bad request API responses.
-//
-//	@name			ErrorBadRequestResponse
-//	@description	Standard error response format returned by all bad request API endpoints
-type ErrorBadRequestResponse struct {
-	Success bool   `json:"success" example:"false"`
-	Message string `json:"message" example:"It show error from err.Error()"`
-	Status  int    `json:"status" example:"400"`
-}
-
-// ErrorInternalServerResponse represents the standard error format for internal server API responses.
-//
-//	@name			ErrorInternalServerResponse
-//	@description	Standard error response format returned by all internal server error API endpoints
-type ErrorInternalServerResponse struct {
-	Success bool   `json:"success" example:"false"`
-	Message string `json:"message" example:"the server encountered a problem"`
-	Status  int    `json:"status" example:"500"`
-}
-
 type RegisterUserPayload struct {
 	FirstName string `json:"first_name" validate:"required,max=40"`
 	LastName  string `json:"last_name" validate:"required,max=40"`
@@ -53,15 +34,15 @@ type UserWithToken struct {
 // registerUserHandler godoc
 //
 //	@Summary		Registers a user
-//	@Description	Registers a user via Mobile App, Server will send activation url on email and need to click there to verify its your email
+//	@Description	Registers a user via Mobile App, Server will send activation url on email and need to click there to verify its your email. Registering with the phone of a guest who booked from a venue widget turns that guest into the account, bookings included.
 //	@Tags			authentication
 //	@Accept			json
 //	@Produce		json
 //	@Param			payload	body		RegisterUserPayload			true	"User credentials"
 //	@Success		201		{object}	UserWithToken				"User registered"
 //
-//	@Failure		400		{object}	ErrorBadRequestResponse		"Bad request"
-//	@Failure		500		{object}	ErrorInternalServerResponse	"Internal Server Error"
+//	@Failure		400		{object}	ErrorResponse	"Bad request"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
 //
 //	@Router			/authentication/user [post]
 func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -122,21 +103,23 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		ActivationURL: activationURL,
 	}
 
-	//send email
-	status, err := app.mailer.Send(mailer.UserWelcomeTemplate, user.FirstName, user.Email, vars)
-	if err != nil {
-		app.logger.Errorw("error sending welcome email", "error", err)
+	// queue the email; the job runner retries delivery failures
+	if err := app.enqueueEmail(ctx, mailer.UserWelcomeTemplate, mailer.NormalizeLocale(r.Header.Get("Accept-Language")), user.FirstName, user.Email, vars); err != nil {
+		app.logger.Errorw("error queueing welcome email", "error", err)
 
-		// rollback user creation if email fails (SAGA pattern)
-		if err := app.store.Users.Delete(ctx, user.ID); err != nil {
-			app.logger.Errorw("error deleting user", "error", err)
+		// rollback user creation if email cannot be queued (SAGA pattern);
+		// a claimed guest row keeps its bookings, so it stays
+		if !user.ClaimedGuest {
+			if err := app.store.Users.Delete(ctx, user.ID); err != nil {
+				app.logger.Errorw("error deleting user", "error", err)
+			}
 		}
 
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	app.logger.Infow("Email sent", "status code", status)
+	app.enqueueScreen(moderationqueue.ContentProfileName, user.ID, user.ID, user.FirstName+" "+user.LastName)
 
 	if err := app.jsonResponse(w, http.StatusCreated, userWithToken); err != nil {
 		app.internalServerError(w, r, err)
@@ -202,6 +185,11 @@ func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if err := app.cancelPendingDeletion(r.Context(), user); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	venueIDs, err := app.store.Venues.GetOwnedVenueIDs(r.Context(), user.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -255,10 +243,16 @@ func (app *application) logoutHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 	userID := user.ID
 
+	// An impersonation session has no refresh token of its own; keep the
+	// user's real session alive.
+	if _, ok := getImpersonatorID(r); ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	// Delete refresh token from DB
 	err := app.store.Users.DeleteRefreshToken(r.Context(), userID)
 	if err != nil {
-		fmt.Printf("the error from handler is %s", err)
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -471,18 +465,18 @@ func (app *application) requestResetPasswordHandler(w http.ResponseWriter, r *ht
 		ResetURL: resetURL,
 	}
 
-	status, err := app.mailer.Send(
+	err = app.enqueueEmail(
+		ctx,
 		mailer.ResetPasswordTemplate,
+		app.userLocale(ctx, user.ID),
 		payload.Email, // toEmail
 		payload.Email, // toName (you can change if you store full name)
 		vars,
 	)
-	app.logger.Infow("reset password email sent", "status_code", status)
 	if err != nil {
-		// ⚠️ This is a server problem (SMTP, provider issues, etc)
-		// Some teams still return 200 here to avoid leaking delivery status.
-		// But returning 500 is also acceptable for your own app UX.
-		app.logger.Errorw("error sending reset password email", "error", err)
+		// ⚠️ This is a server problem (queue unavailable)
+		// Delivery failures are retried by the job runner and never reach here.
+		app.logger.Errorw("error queueing reset password email", "error", err)
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -531,7 +525,6 @@ func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Requ
 	// Hash the token to compare with the stored hash
 	hash := sha256.Sum256([]byte(payload.Token))
 	hashToken := hex.EncodeToString(hash[:])
-	fmt.Printf("this is hashToken: %s", hashToken)
 
 	// Get user by reset token
 	user, err := app.store.Users.GetByResetToken(ctx, hashToken)
@@ -541,7 +534,6 @@ func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Requ
 			return
 		}
 		app.internalServerError(w, r, err)
-		fmt.Println(err)
 		return
 	}
 
@@ -568,7 +560,6 @@ func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Requ
 	// Save the updated user
 	if err := app.store.Users.Update(ctx, user); err != nil {
 		app.internalServerError(w, r, err)
-		fmt.Println(err)
 		return
 	}
 
@@ -598,8 +589,8 @@ type AdminCreateUserPayload struct {
 //	@Produce		json
 //	@Param			payload	body		AdminCreateUserPayload		true	"User details"
 //	@Success		201		{object}	users.User					"User created"
-//	@Failure		400		{object}	ErrorBadRequestResponse		"Bad request"
-//	@Failure		500		{object}	ErrorInternalServerResponse	"Internal Server Error"
+//	@Failure		400		{object}	ErrorResponse	"Bad request"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/superadmin/users [post]
 func (app *application) adminCreateUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -107,6 +107,11 @@ func (app *application) createTokenCookieHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	if err := app.cancelPendingDeletion(r.Context(), user); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	venueIDs, err := app.store.Venues.GetOwnedVenueIDs(r.Context(), user.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
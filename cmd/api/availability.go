@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/availability"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type UpdateAvailabilityPayload struct {
+	// An empty list clears the schedule (user is treated as always available).
+	Slots []AvailabilitySlotPayload `json:"slots" validate:"max=28,dive"`
+}
+
+type AvailabilitySlotPayload struct {
+	DayOfWeek string `json:"day_of_week" validate:"required,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+
+	// StartTime/EndTime must be in 24-hour HH:mm:ss format, Nepal local time.
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+}
+
+// getAvailabilityHandler godoc
+//
+//	@Summary		Get my weekly availability
+//	@Description	Returns the weekly time windows in which the current user can play. An empty list means no preference.
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{array}		availability.Slot
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/availability [get]
+func (app *application) getAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	slots, err := app.store.Availability.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, slots)
+}
+
+// updateAvailabilityHandler godoc
+//
+//	@Summary		Replace my weekly availability
+//	@Description	Replaces the current user's weekly schedule. Matchmaking and alerts skip users outside these windows.
+//	@Description	start_time and end_time must use 24-hour HH:mm:ss format in Nepal time. Windows on the same day must not overlap.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		UpdateAvailabilityPayload	true	"Weekly schedule"
+//	@Success		200		{array}		availability.Slot
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/availability [put]
+func (app *application) updateAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	var payload UpdateAvailabilityPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	type window struct{ start, end time.Time }
+	byDay := make(map[string][]window)
+	slots := make([]availability.Slot, 0, len(payload.Slots))
+
+	for i, in := range payload.Slots {
+		st, err := time.Parse("15:04:05", in.StartTime)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("slot %d: invalid start_time", i))
+			return
+		}
+		et, err := time.Parse("15:04:05", in.EndTime)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("slot %d: invalid end_time", i))
+			return
+		}
+		if !st.Before(et) {
+			app.badRequestResponse(w, r, fmt.Errorf("slot %d: start_time must be before end_time", i))
+			return
+		}
+
+		day := strings.ToLower(strings.TrimSpace(in.DayOfWeek))
+		byDay[day] = append(byDay[day], window{st, et})
+		slots = append(slots, availability.Slot{
+			DayOfWeek: day,
+			StartTime: st.Format("15:04:05"),
+			EndTime:   et.Format("15:04:05"),
+		})
+	}
+
+	for day, ws := range byDay {
+		sort.Slice(ws, func(i, j int) bool { return ws[i].start.Before(ws[j].start) })
+		for i := 1; i < len(ws); i++ {
+			if ws[i].start.Before(ws[i-1].end) {
+				app.badRequestResponse(w, r, fmt.Errorf("overlapping windows on %s", day))
+				return
+			}
+		}
+	}
+
+	if err := app.store.Availability.ReplaceForUser(r.Context(), user.ID, slots); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	saved, err := app.store.Availability.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, saved)
+}
+
+// availableAt returns which of the users can play at the given instant.
+// A lookup failure is logged and everyone is treated as available, since
+// the matches that led here are already claimed and would otherwise be lost.
+func (app *application) availableAt(ctx context.Context, userIDs []int64, at time.Time) map[int64]bool {
+	out := make(map[int64]bool, len(userIDs))
+	ids, err := app.store.Availability.FilterAvailable(ctx, userIDs, at)
+	if err != nil {
+		app.logger.Warnw("failed to filter users by availability", "error", err)
+		ids = userIDs
+	}
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out
+}
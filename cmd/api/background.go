@@ -2,39 +2,87 @@ package main
 
 import (
 	"context"
+	"khel/internal/events"
+	"khel/internal/jobs"
+	"strconv"
 	"time"
 )
 
-func (app *application) runMarkCompletedGames() {
-	if err := app.store.Games.MarkCompletedGames(); err != nil {
-		app.logger.Errorf("Error marking games as completed: %v", err)
-	} else {
-		app.logger.Infof("Successfully marked games as completed at %s", time.Now().UTC().Format(time.RFC3339))
+// Background work runs as jobs; see registerJobs for the schedules.
+
+// runMarkCompletedGames closes finished games and publishes game.completed
+// for each, which drives the post-game summary push.
+func (app *application) runMarkCompletedGames(ctx context.Context) error {
+	started := time.Now()
+	completed, err := app.store.Games.MarkCompletedGames(ctx)
+	if err != nil {
+		app.logger.Errorw("mark completed games failed", "duration_ms", time.Since(started).Milliseconds(), "error", err)
+		return err
 	}
-}
+	jobs.SetRowsAffected(ctx, int64(len(completed)))
+
+	var closedRequests int64
+	for _, g := range completed {
+		closedRequests += g.ClosedRequests
+	}
+	app.logger.Infow("marked games as completed",
+		"games", len(completed),
+		"closed_requests", closedRequests,
+		"duration_ms", time.Since(started).Milliseconds(),
+	)
 
-func (app *application) markCompletedGamesEvery30Mins(ctx context.Context) {
-	go func() {
-
-		defer func() {
-			if r := recover(); r != nil {
-				app.logger.Errorf("Recovered from panic in markCompletedGamesEvery30Mins: %v", r)
-			}
-		}()
-		ticker := time.NewTicker(30 * time.Minute)
-		defer ticker.Stop()
-
-		// Run once immediately
-		app.runMarkCompletedGames()
-
-		for {
-			select {
-			case <-ctx.Done():
-				app.logger.Info("Stopped markCompletedGamesEvery30Mins due to context cancellation")
-				return
-			case <-ticker.C:
-				app.runMarkCompletedGames()
-			}
+	// The games are already completed, so a failed publish can't be retried
+	// by this job; log it and carry on with the rest.
+	for _, g := range completed {
+		err := app.events.Publish(ctx, events.GameCompleted, strconv.FormatInt(g.ID, 10), events.GameCompletedPayload{
+			GameID:         g.ID,
+			VenueID:        g.VenueID,
+			AdminID:        g.AdminID,
+			SportType:      g.SportType,
+			EndTime:        g.EndTime,
+			RatingClosesAt: g.RatingClosesAt,
+		})
+		if err != nil {
+			app.logger.Errorw("failed to publish game.completed", "game_id", g.ID, "error", err)
 		}
-	}()
+	}
+	return nil
+}
+
+// catalogTrashRetention is how long a soft-deleted brand, category or product
+// stays restorable before the purge job removes it for good.
+const catalogTrashRetention = 30 * 24 * time.Hour
+
+func (app *application) runPurgeCatalogTrash(ctx context.Context) error {
+	res, err := app.store.Products.PurgeTrash(ctx, catalogTrashRetention)
+	if err != nil {
+		app.logger.Errorf("Error purging catalog trash: %v", err)
+		return err
+	}
+	app.logger.Infow("purged catalog trash",
+		"brands", res.Brands,
+		"categories", res.Categories,
+		"products", res.Products,
+		"images", len(res.ImageURLs),
+	)
+
+	// The rows are gone, so the images have no owner left.
+	for _, url := range res.ImageURLs {
+		app.enqueuePhotoDelete(url)
+	}
+	return nil
+}
+
+// notificationRetention is how long an inbox entry is kept, read or not.
+const notificationRetention = 90 * 24 * time.Hour
+
+func (app *application) runPruneNotifications(ctx context.Context) error {
+	n, err := app.store.Inbox.Prune(ctx, notificationRetention)
+	if err != nil {
+		app.logger.Errorw("prune notifications failed", "error", err)
+		return err
+	}
+	jobs.SetRowsAffected(ctx, n)
+	app.logger.Infow("pruned notifications", "deleted", n)
+	return nil
 }
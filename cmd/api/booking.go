@@ -8,9 +8,9 @@ import (
 	"fmt"
 	"khel/internal/domain/bookings"
 	"khel/internal/domain/inventory"
+	"khel/internal/mailer"
 	"khel/internal/notifications"
 
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -89,6 +89,12 @@ type HourlySlot struct {
 	Available    bool      `json:"available"`
 }
 
+// availableTimesQuery takes the day as a full RFC3339 time, e.g.
+// 2025-06-28T00:00:00+05:45, kept in Nepal time when the offset matches.
+type availableTimesQuery struct {
+	Date time.Time `query:"date" tz:"Asia/Kathmandu" validate:"required"`
+}
+
 // AvailableTimes godoc
 //
 //	@Summary		List available time slots for a venue
@@ -112,9 +118,9 @@ func (app *application) availableTimesHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	//  Parse `date` from query param in format 2025-06-28T00:00:00+05:45
-	dateStr := r.URL.Query().Get("date")
-	if dateStr == "" {
-		app.badRequestResponse(w, r, fmt.Errorf("missing date"))
+	var q availableTimesQuery
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
@@ -125,18 +131,9 @@ func (app *application) availableTimesHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Parse into named location so date.Location()==Asia/Kathmandu date will be
-	// 2025-06-30 00:00:00 +0545 +0545. first +0545 is for offset and second is for location
-	date, err := time.ParseInLocation(time.RFC3339, dateStr, loc)
-	if err != nil {
-		app.badRequestResponse(w, r, fmt.Errorf("invalid date format: %w", err))
-		return
-	}
-
+	date := q.Date
 	dateInKtm := date.In(loc)
 
-	dayOfWeek := strings.ToLower(dateInKtm.Weekday().String())
-
 	defaultFacility, err := app.store.Facilities.GetDefaultByVenueID(r.Context(), venueID)
 	if err != nil {
 		app.notFoundResponse(w, r, err)
@@ -144,11 +141,11 @@ func (app *application) availableTimesHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Step 3: Load pricing slots and bookings for the venue and the selected date
-	pricingSlots, err := app.store.Bookings.GetPricingSlots(
+	pricingSlots, err := app.store.Bookings.GetPricingForDate(
 		r.Context(),
 		venueID,
 		defaultFacility.ID,
-		dayOfWeek,
+		dateInKtm,
 	)
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -255,7 +252,7 @@ type BookVenuePayload struct {
 // BookVenue godoc
 //
 //	@Summary		Book a venue time slot
-//	@Description	Books a venue for the specified time slot if available and calculates the total price based on the applicable pricing slot.
+//	@Description	Books a venue for the specified time slot if available and calculates the total price based on the applicable pricing slot. The venue's booking rules limit the duration and how far ahead it can be; with auto_confirm the booking is confirmed straight away.
 //	@Tags			Venue
 //	@Accept			json
 //	@Produce		json
@@ -263,7 +260,7 @@ type BookVenuePayload struct {
 //	@Param			payload	body		BookVenuePayload	true	"Booking details payload"
 //	@Success		201		{object}	BookingResponse		"Booking created successfully"
 //	@Failure		400		{object}	error				"Bad Request: Invalid input"
-//	@Failure		409		{object}	error				"Conflict: Time slot is already booked"
+//	@Failure		409		{object}	SlotTakenResponse	"Conflict: Time slot is already booked; includes alternative slots"
 //	@Failure		500		{object}	error				"Internal Server Error: Could not create booking"
 //	@Security		ApiKeyAuth
 //	@Router			/venues/{venueID}/bookings [post]
@@ -271,7 +268,7 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 	venueIDStr := chi.URLParam(r, "venueID")
 	venueID, err := strconv.ParseInt(venueIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid venue ID", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
 		return
 	}
 	var payload BookVenuePayload
@@ -287,7 +284,17 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 
 	user := getUserFromContext(r)
 	if user == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		app.unauthorizedErrorResponse(w, r, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	rules, err := app.store.BookingRules.Get(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := rules.CheckBooking(payload.StartTime, payload.EndTime, time.Now()); err != nil {
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
@@ -300,8 +307,6 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 	// Determine the day and fetch pricing slots for that day.
 	localStart := payload.StartTime.In(loc)
 
-	dayOfWeek := strings.ToLower(localStart.Weekday().String())
-
 	defaultFacility, err := app.store.Facilities.GetDefaultByVenueID(r.Context(), venueID)
 	if err != nil {
 		app.notFoundResponse(w, r, err)
@@ -313,14 +318,18 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 	//end_time 🎯: 2025-07-02 09:00:00 +0545 +0545
 	//localStart 🎯: 2025-07-02 08:00:00 +0545 +0545
 	//dayOfWeek 🎯: wednesday
-	pricingSlots, err := app.store.Bookings.GetPricingSlots(
+	pricingSlots, err := app.store.Bookings.GetPricingForDate(
 		r.Context(),
 		venueID,
 		defaultFacility.ID,
-		dayOfWeek,
+		localStart,
 	)
-	if err != nil || len(pricingSlots) == 0 {
-		http.Error(w, "No pricing available for this day", http.StatusBadRequest)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if len(pricingSlots) == 0 {
+		app.badRequestResponse(w, r, fmt.Errorf("no pricing available for this day: %w", errSlotOutOfPricing))
 		return
 	}
 
@@ -341,7 +350,7 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 	if !validSlot {
-		http.Error(w, "Requested time slot is not within available pricing intervals", http.StatusBadRequest)
+		app.badRequestResponse(w, r, errSlotOutOfPricing)
 		return
 	}
 
@@ -353,13 +362,13 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 		payload.StartTime,
 	)
 	if err != nil {
-		http.Error(w, "Error checking bookings", http.StatusInternalServerError)
+		app.internalServerError(w, r, err)
 		return
 	}
 	requestedInterval := bookings.Interval{Start: payload.StartTime, End: payload.EndTime}
 	for _, b := range bookingsList {
 		if intervalsOverlap(requestedInterval, b) {
-			http.Error(w, "Time slot is already booked", http.StatusConflict)
+			app.slotTakenResponse(w, r, venueID, defaultFacility.ID, payload.StartTime, payload.EndTime)
 			return
 		}
 	}
@@ -368,6 +377,12 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 	duration := payload.EndTime.Sub(payload.StartTime).Hours()
 	totalPrice := int(duration * float64(applicablePrice))
 
+	status, err := app.bookingStatusFor(r.Context(), rules, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	// Create the booking.
 	booking := &bookings.Booking{
 		VenueID:    venueID,
@@ -376,15 +391,21 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 		StartTime:  payload.StartTime,
 		EndTime:    payload.EndTime,
 		TotalPrice: totalPrice,
-		Status:     "pending",
+		Status:     status,
 	}
 
 	bookingID, err := app.store.Bookings.CreateBooking(r.Context(), booking)
 	if err != nil {
-		log.Printf("CreateBooking failed: %v", err)
-		http.Error(w, "Error creating booking", http.StatusInternalServerError)
+		if isConfirmedSlotClash(err) {
+			app.slotTakenResponse(w, r, venueID, defaultFacility.ID, payload.StartTime, payload.EndTime)
+			return
+		}
+		app.internalServerError(w, r, fmt.Errorf("create booking: %w", err))
 		return
 	}
+	if booking.Status == "confirmed" {
+		app.notifyBookingAutoConfirmed(booking)
+	}
 
 	ownerID, err := app.store.Venues.GetOwnerIDFromVenueID(r.Context(), venueID)
 	if err != nil {
@@ -404,7 +425,7 @@ func (app *application) bookVenueHandler(w http.ResponseWriter, r *http.Request)
 			app.EncodeBookingID(bookingID),
 		)
 		if err != nil {
-			fmt.Printf("❌ Failed to send booking created notification: %v\n", err)
+			app.requestLogger(r).Errorw("failed to send booking created notification", "booking_id", bookingID, "error", err)
 		}
 	}()
 
@@ -444,7 +465,7 @@ func (app *application) createManualBookingHandler(w http.ResponseWriter, r *htt
 	venueIDStr := chi.URLParam(r, "venueID")
 	venueID, err := strconv.ParseInt(venueIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid venue ID", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
 		return
 	}
 	var payload ManualBookingPayload
@@ -477,20 +498,20 @@ func (app *application) createManualBookingHandler(w http.ResponseWriter, r *htt
 		payload.StartTime,
 	)
 	if err != nil {
-		http.Error(w, "Error checking bookings", http.StatusInternalServerError)
+		app.internalServerError(w, r, err)
 		return
 	}
 	requestedInterval := bookings.Interval{Start: payload.StartTime, End: payload.EndTime}
 	for _, b := range bookingList {
 		if intervalsOverlap(requestedInterval, b) {
-			http.Error(w, "Time slot is already booked", http.StatusConflict)
+			app.conflictResponse(w, r, errSlotTaken)
 			return
 		}
 	}
 
 	user := getUserFromContext(r)
 	if user == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		app.unauthorizedErrorResponse(w, r, fmt.Errorf("unauthorized"))
 		return
 	}
 
@@ -500,9 +521,8 @@ func (app *application) createManualBookingHandler(w http.ResponseWriter, r *htt
 		targetUser, err := app.store.Users.GetByEmail(r.Context(), payload.Email)
 		if err == nil && targetUser != nil {
 			bookingUserID = targetUser.ID
-			fmt.Printf("Target user name is: %s targetUserID 🎯: %d\n ", targetUser.FirstName, targetUser.ID)
 		} else {
-			log.Printf("Email %s not found, using owner ID", payload.Email)
+			app.requestLogger(r).Infow("booking email not found, booking under owner", "email", payload.Email)
 		}
 	}
 	//Trim empty strings before setting pointer fields (to avoid storing "" instead of NULL)
@@ -650,9 +670,10 @@ func (app *application) updateVenuePricingHandler(w http.ResponseWriter, r *http
 	}
 
 	if err := app.store.Bookings.UpdatePricing(r.Context(), pricing); err != nil {
-		http.Error(w, "Error updating pricing", http.StatusInternalServerError)
+		app.internalServerError(w, r, err)
 		return
 	}
+	app.enqueuePriceAlertCheck(venueID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(pricing)
@@ -698,7 +719,7 @@ func (app *application) deleteVenuePricingHandler(w http.ResponseWriter, r *http
 	err = app.store.Bookings.DeletePricingSlot(r.Context(), venueID, defaultFacility.ID, pricingID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no pricing slot found") {
-			http.Error(w, err.Error(), http.StatusNotFound)
+			app.notFoundResponse(w, r, err)
 		} else {
 			app.internalServerError(w, r, err)
 		}
@@ -794,15 +815,22 @@ func (app *application) createVenuePricingHandler(w http.ResponseWriter, r *http
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.enqueuePriceAlertCheck(venueID)
 
 	// 5) Return 201 + full slice (with IDs)
 	app.jsonResponse(w, http.StatusCreated, slots)
 }
 
+// bookingDateQuery is the ?date=YYYY-MM-DD the owner booking lists take,
+// read as a day in Nepal.
+type bookingDateQuery struct {
+	Date time.Time `query:"date" layout:"2006-01-02" tz:"Asia/Kathmandu" validate:"required"`
+}
+
 // getPendingBookingsHandler godoc
 //
 //	@Summary		List pending booking requests for a venue
-//	@Description	Returns all bookings with status="pending" for a given venue and date.
+//	@Description	Returns all bookings with status="pending" for a given venue and date. Phone numbers are masked; use reveal-contact for the full number.
 //	@Tags			Venue-Owner
 //	@Accept			json
 //	@Produce		json
@@ -822,17 +850,12 @@ func (app *application) getPendingBookingsHandler(w http.ResponseWriter, r *http
 	}
 
 	// 2) parse date query
-	dateStr := r.URL.Query().Get("date")
-	if dateStr == "" {
-		app.badRequestResponse(w, r, errors.New("missing date"))
-		return
-	}
-
-	date, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		app.badRequestResponse(w, r, fmt.Errorf("invalid date format: %w", err))
+	var q bookingDateQuery
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
 		return
 	}
+	date := q.Date
 
 	defaultFacility, err := app.store.Facilities.GetDefaultByVenueID(r.Context(), vid)
 	if err != nil {
@@ -854,7 +877,7 @@ func (app *application) getPendingBookingsHandler(w http.ResponseWriter, r *http
 			UserID:       b.UserID,
 			UserName:     b.UserName,
 			UserImageURL: b.UserImageURL,
-			UserPhone:    b.UserPhone,
+			UserPhone:    maskPhone(b.UserPhone),
 			Price:        b.Price,
 			RequestedAt:  b.RequestedAt,
 			StartTime:    b.StartTime,
@@ -868,7 +891,7 @@ func (app *application) getPendingBookingsHandler(w http.ResponseWriter, r *http
 // getScheduledBookingsHandler godoc
 //
 //	@Summary		List Scheduled booking requests for a venue
-//	@Description	Returns all bookings with status="confirmed" for a given venue and date.
+//	@Description	Returns all bookings with status="confirmed" for a given venue and date. Phone numbers are masked; use reveal-contact for the full number.
 //	@Tags			Venue-Owner
 //	@Accept			json
 //	@Produce		json
@@ -888,20 +911,12 @@ func (app *application) getScheduledBookingsHandler(w http.ResponseWriter, r *ht
 	}
 
 	// 2) parse date query
-	dateStr := r.URL.Query().Get("date")
-	if dateStr == "" {
-		app.badRequestResponse(w, r, errors.New("missing date"))
-		return
-	}
-
-	loc, _ := time.LoadLocation("Asia/Kathmandu")
-
-	// Parse date string in Kathmandu local time
-	date, err := time.ParseInLocation("2006-01-02", dateStr, loc)
-	if err != nil {
-		app.badRequestResponse(w, r, fmt.Errorf("invalid date format: %w", err))
+	var q bookingDateQuery
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
 		return
 	}
+	date := q.Date
 
 	defaultFacility, err := app.store.Facilities.GetDefaultByVenueID(r.Context(), vid)
 	if err != nil {
@@ -926,13 +941,13 @@ func (app *application) getScheduledBookingsHandler(w http.ResponseWriter, r *ht
 			UserID:        b.UserID,
 			UserName:      b.UserName,
 			UserImageURL:  b.UserImageURL,
-			UserPhone:     b.UserPhone,
+			UserPhone:     maskPhone(b.UserPhone),
 			Price:         b.Price,
 			AcceptedAt:    b.AcceptedAt,
 			StartTime:     b.StartTime,
 			EndTime:       b.EndTime,
 			CustomerName:  b.CustomerName,
-			CustomerPhone: b.CustomerPhone,
+			CustomerPhone: maskOptionalPhone(b.CustomerPhone),
 			Note:          b.Note,
 		})
 	}
@@ -943,7 +958,7 @@ func (app *application) getScheduledBookingsHandler(w http.ResponseWriter, r *ht
 // getCanceledBookingsHandler godoc
 //
 //	@Summary		List Canceled booking requests for a venue
-//	@Description	Returns all bookings with status="canceled" for a given venue and date.
+//	@Description	Returns all bookings with status="canceled" for a given venue and date. Phone numbers are masked; use reveal-contact for the full number.
 //	@Tags			Venue-Owner
 //	@Accept			json
 //	@Produce		json
@@ -963,20 +978,12 @@ func (app *application) getCanceledBookingsHandler(w http.ResponseWriter, r *htt
 	}
 
 	// 2) parse date query
-	dateStr := r.URL.Query().Get("date")
-	if dateStr == "" {
-		app.badRequestResponse(w, r, errors.New("missing date"))
-		return
-	}
-
-	loc, _ := time.LoadLocation("Asia/Kathmandu")
-
-	// Parse date string in Kathmandu local time
-	date, err := time.ParseInLocation("2006-01-02", dateStr, loc)
-	if err != nil {
-		app.badRequestResponse(w, r, fmt.Errorf("invalid date format: %w", err))
+	var q bookingDateQuery
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
 		return
 	}
+	date := q.Date
 
 	defaultFacility, err := app.store.Facilities.GetDefaultByVenueID(r.Context(), vid)
 	if err != nil {
@@ -1013,7 +1020,7 @@ func (app *application) getCanceledBookingsHandler(w http.ResponseWriter, r *htt
 			UserID:       b.UserID,
 			UserName:     b.UserName,
 			UserImageURL: b.UserImageURL,
-			UserPhone:    b.UserPhone,
+			UserPhone:    maskPhone(b.UserPhone),
 			Price:        b.Price,
 			RequestedAt:  b.RequestedAt,
 			StartTime:    b.StartTime,
@@ -1058,7 +1065,7 @@ func (app *application) acceptBookingHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err := app.store.Bookings.AcceptBooking(r.Context(), vid, bid); err != nil {
+	if err := app.store.Bookings.AcceptBooking(r.Context(), vid, bid, getUserFromContext(r).ID); err != nil {
 		if err == sql.ErrNoRows {
 			app.notFoundResponse(w, r, errors.New("not found"))
 			return
@@ -1087,9 +1094,10 @@ func (app *application) acceptBookingHandler(w http.ResponseWriter, r *http.Requ
 			app.EncodeBookingID(booking.ID),
 		)
 		if err != nil {
-			fmt.Printf("❌ Failed to send booking accepted notification: %v\n", err)
+			app.requestLogger(r).Errorw("failed to send booking accepted notification", "booking_id", booking.ID, "error", err)
 		}
 	}()
+	go app.emailBookingDecision(booking, mailer.BookingConfirmationTemplate)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1097,7 +1105,7 @@ func (app *application) acceptBookingHandler(w http.ResponseWriter, r *http.Requ
 // rejectBookingHandler godoc
 //
 //	@Summary		Reject a pending booking request
-//	@Description	Marks the booking with status="pending" as "rejected".
+//	@Description	Marks the booking with status="pending" as "rejected". Anything the player already paid online is filed for a full refund.
 //	@Tags			Venue-Owner
 //	@Accept			json
 //	@Produce		json
@@ -1128,7 +1136,7 @@ func (app *application) rejectBookingHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err := app.store.Bookings.RejectBooking(r.Context(), vid, bid); err != nil {
+	if err := app.store.Bookings.RejectBooking(r.Context(), vid, bid, getUserFromContext(r).ID); err != nil {
 		if err == sql.ErrNoRows {
 			app.notFoundResponse(w, r, errors.New("not found"))
 		} else {
@@ -1136,6 +1144,10 @@ func (app *application) rejectBookingHandler(w http.ResponseWriter, r *http.Requ
 		}
 		return
 	}
+	app.publishBookingReleased(booking, "rejected")
+	if err := app.refundRejectedBooking(r.Context(), booking, "Booking request was rejected by the venue"); err != nil {
+		app.requestLogger(r).Errorw("failed to file refund for rejected booking", "booking_id", booking.ID, "error", err)
+	}
 
 	// Send push notification
 	go func() {
@@ -1150,9 +1162,10 @@ func (app *application) rejectBookingHandler(w http.ResponseWriter, r *http.Requ
 			app.EncodeBookingID(booking.ID),
 		)
 		if err != nil {
-			fmt.Printf("❌ Failed to send booking accepted notification: %v\n", err)
+			app.requestLogger(r).Errorw("failed to send booking accepted notification", "booking_id", booking.ID, "error", err)
 		}
 	}()
+	go app.emailBookingDecision(booking, mailer.BookingRejectionTemplate)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1160,7 +1173,7 @@ func (app *application) rejectBookingHandler(w http.ResponseWriter, r *http.Requ
 // cancelBookingHandler godoc
 //
 //	@Summary		Cancel a pending booking request or confirmed booking
-//	@Description	Marks the booking with status="pending or confirmed" as "canceled".
+//	@Description	Marks the booking with status="pending or confirmed" as "canceled". Confirmed bookings can't be canceled once the venue's cancellation cutoff has passed.
 //	@Tags			Venue
 //	@Accept			json
 //	@Produce		json
@@ -1207,11 +1220,32 @@ func (app *application) cancelBookingHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	booking, err := app.store.Bookings.GetBookingByID(r.Context(), bid)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Pending requests can always be withdrawn; the venue's cutoff only
+	// protects confirmed slots.
+	if booking.Status == "confirmed" {
+		rules, err := app.store.BookingRules.Get(r.Context(), vid)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if err := rules.CheckCancel(booking.StartTime, time.Now()); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
 	// ✅ Step 3: Cancel booking
-	if err := app.store.Bookings.CancelBooking(r.Context(), vid, bid); err != nil {
+	if err := app.store.Bookings.CancelBooking(r.Context(), vid, bid, authUser.ID); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.publishBookingReleased(booking, "canceled")
 
 	venueOwnerID, err := app.store.Bookings.GetVenueOwnerIDFromBookingID(r.Context(), bid)
 	if err != nil {
@@ -1231,13 +1265,20 @@ func (app *application) cancelBookingHandler(w http.ResponseWriter, r *http.Requ
 			app.EncodeBookingID(bid),
 		)
 		if err != nil {
-			fmt.Printf("❌ Failed to send booking accepted notification: %v\n", err)
+			app.requestLogger(r).Errorw("failed to send booking accepted notification", "booking_id", booking.ID, "error", err)
 		}
 	}()
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// userBookingsQuery is the query string of getBookingsByUserHandler.
+type userBookingsQuery struct {
+	Page   int     `query:"page" validate:"min=1"`
+	Limit  int     `query:"limit" validate:"min=1,max=50"`
+	Status *string `query:"status" validate:"omitempty,oneof=pending confirmed rejected canceled done"`
+}
+
 // getBookingsByUserHandler godoc
 //
 //	@Summary		List all bookings for a user
@@ -1247,7 +1288,7 @@ func (app *application) cancelBookingHandler(w http.ResponseWriter, r *http.Requ
 //	@Produce		json
 //	@Param			page	query		int		false	"Page number (1-based)"		default(1)	minimum(1)
 //	@Param			limit	query		int		false	"Items per page (max 50)"	default(7)	minimum(1)	maximum(50)
-//	@Param			status	query		string	false	"Filter by booking status"	Enums(confirmed, pending, rejected, done)
+//	@Param			status	query		string	false	"Filter by booking status"	Enums(confirmed, pending, rejected, canceled, done)
 //	@Success		200		{array}		[]UserBookingResponse
 //	@Failure		400		{object}	error	"Bad Request"
 //	@Failure		401		{object}	error	"Unauthorized"
@@ -1261,26 +1302,16 @@ func (app *application) getBookingsByUserHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Parse query params
-	q := r.URL.Query()
-	page, _ := strconv.Atoi(q.Get("page"))
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(q.Get("limit"))
-	if limit < 1 || limit > 50 {
-		limit = 7
-	}
-
-	var status *string
-	if s := q.Get("status"); s != "" {
-		status = &s
+	q := userBookingsQuery{Page: 1, Limit: 7}
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
 	}
 
 	filter := bookings.BookingFilter{
-		Status: status,
-		Page:   page,
-		Limit:  limit,
+		Status: q.Status,
+		Page:   q.Page,
+		Limit:  q.Limit,
 	}
 
 	bookings, err := app.store.Bookings.GetBookingsByUser(r.Context(), user.ID, filter)
@@ -1414,6 +1445,7 @@ func (app *application) checkoutGameHandler(w http.ResponseWriter, r *http.Reque
 		payload.PaymentMethod,
 		payload.PaidAmount,
 		summary.GrandTotal,
+		getUserFromContext(r).ID,
 	)
 	if err != nil {
 		app.internalServerError(w, r, err)
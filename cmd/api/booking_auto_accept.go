@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/checkins"
+	"khel/internal/domain/venues"
+	"khel/internal/jobs"
+	"khel/internal/mailer"
+	"khel/internal/notifications"
+	"net/http"
+)
+
+// autoAcceptBatch caps how many bookings one run of the job confirms.
+const autoAcceptBatch = 200
+
+// AutoAcceptPayload sets the venue's auto-accept window. null switches it
+// off.
+type AutoAcceptPayload struct {
+	AfterMinutes *int `json:"after_minutes" validate:"omitempty,min=5,max=1440"`
+}
+
+// setAutoAcceptHandler godoc
+//
+//	@Summary		Auto-accept booking requests I don't answer
+//	@Description	Pending bookings that haven't been accepted or rejected within after_minutes (5 to 1440) are confirmed automatically, as long as they haven't started, don't clash with a confirmed booking and the player meets the venue's min_reliability_score (if set). Both the player and the owner are notified. Send null to answer every request yourself.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int					true	"Venue ID"
+//	@Param			payload	body		AutoAcceptPayload	true	"Auto-accept window"
+//	@Success		200		{object}	AutoAcceptPayload
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		403		{object}	ErrorResponse	"Forbidden"
+//	@Failure		404		{object}	ErrorResponse	"Venue not found"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/auto-accept [put]
+func (app *application) setAutoAcceptHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload AutoAcceptPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Venues.SetAutoAccept(r.Context(), venueID, payload.AfterMinutes); err != nil {
+		if errors.Is(err, venues.ErrVenueNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, payload)
+}
+
+// runAutoAcceptBookings confirms the booking requests owners left waiting
+// past their venue's window and tells both sides. Players below the venue's
+// min_reliability_score are left pending for the owner, as with
+// auto_confirm. The bookings are already confirmed when notifying, so
+// failures there are only logged.
+func (app *application) runAutoAcceptBookings(ctx context.Context) error {
+	var accepted int64
+	defer func() {
+		jobs.SetRowsAffected(ctx, accepted)
+		if accepted > 0 {
+			app.logger.Infow("auto-accepted pending bookings", "bookings", accepted)
+		}
+	}()
+
+	// Held-back bookings stay due until the owner answers or they expire,
+	// so page past them instead of scanning the same batch every run.
+	var afterID int64
+	for accepted < autoAcceptBatch {
+		due, err := app.store.Bookings.DueForAutoAccept(ctx, afterID, autoAcceptBatch)
+		if err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+		afterID = due[len(due)-1].BookingID
+
+		scores, err := app.autoAcceptReliability(ctx, due)
+		if err != nil {
+			return err
+		}
+		for _, c := range due {
+			if c.MinReliabilityScore != nil && !scores[c.UserID].Meets(*c.MinReliabilityScore) {
+				continue
+			}
+			a, err := app.store.Bookings.AutoAccept(ctx, c.BookingID)
+			if err != nil {
+				// Bookings confirmed before a failure are kept; the rest
+				// wait for the next run.
+				return err
+			}
+			if a == nil {
+				continue
+			}
+			accepted++
+			app.notifyAutoAccepted(ctx, *a)
+		}
+		if len(due) < autoAcceptBatch {
+			return nil
+		}
+	}
+	return nil
+}
+
+// autoAcceptReliability scores the players of the bookings whose venue sets
+// a min_reliability_score.
+func (app *application) autoAcceptReliability(ctx context.Context, due []bookings.AutoAcceptCandidate) (map[int64]checkins.Reliability, error) {
+	var userIDs []int64
+	for _, c := range due {
+		if c.MinReliabilityScore != nil {
+			userIDs = append(userIDs, c.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return app.store.CheckIns.Reliability(ctx, userIDs)
+}
+
+func (app *application) notifyAutoAccepted(ctx context.Context, a bookings.AutoAccepted) {
+	bookingID := app.EncodeBookingID(a.BookingID)
+
+	if err := notifications.SendBookingNotification(ctx, app.push, app.store, a.UserID, notifications.BookingAccepted, bookingID); err != nil {
+		app.logger.Errorw("failed to send booking accepted notification", "booking_id", a.BookingID, "error", err)
+	}
+	if err := notifications.SendBookingAutoAccepted(ctx, app.push, app.store, a, bookingID); err != nil {
+		app.logger.Errorw("failed to send auto-accept notification", "booking_id", a.BookingID, "error", err)
+	}
+
+	booking, err := app.store.Bookings.GetBookingByID(ctx, a.BookingID)
+	if err != nil {
+		app.logger.Errorw("failed to load auto-accepted booking", "booking_id", a.BookingID, "error", err)
+		return
+	}
+	app.emailBookingDecision(booking, mailer.BookingConfirmationTemplate)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type responseTimesQuery struct {
+	Days int `query:"days" validate:"min=1,max=365"`
+}
+
+// getBookingTimelineHandler godoc
+//
+//	@Summary		Get the timeline of my booking
+//	@Description	Every change of the booking's status or time, oldest first: requested (or booked, when it was confirmed straight away), accepted, rejected, rescheduled, canceled, completed. source says what made the change: user, owner, auto_accept, closure, game_auto_cancel, payment_split or expiry (a request the venue never answered); backfill marks steps reconstructed for bookings made before timelines were kept.
+//	@Tags			Bookings
+//	@Produce		json
+//	@Param			bookingID	path		string	true	"Booking ID (hash or numeric)"
+//	@Success		200			{array}		bookings.Event
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Booking not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{bookingID}/timeline [get]
+func (app *application) getBookingTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	booking, err := app.store.Bookings.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if booking.UserID != getUserFromContext(r).ID {
+		app.notFoundResponse(w, r, bookings.ErrNotFound)
+		return
+	}
+
+	events, err := app.store.Bookings.ListEvents(ctx, booking.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, events)
+}
+
+// getBookingResponseTimesHandler godoc
+//
+//	@Summary		How fast my venue answers booking requests
+//	@Description	Derived from the timelines of the requests made in the last days days: how many were accepted (and how many of those by auto-accept), rejected (and how many of those expired unanswered), canceled by the player before an answer, or are still unanswered, with the average, median and 90th percentile minutes from request to acceptance.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Param			days	query		int	false	"Days to look back (1-365)"	default(30)
+//	@Success		200		{object}	bookings.ResponseStats
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/response-times [get]
+func (app *application) getBookingResponseTimesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	q := responseTimesQuery{Days: 30}
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -q.Days)
+	stats, err := app.store.Bookings.ResponseTimes(r.Context(), venueID, since)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, stats)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"khel/internal/jobs"
+	"khel/internal/mailer"
+	"khel/internal/notifications"
+)
+
+// expireBatch caps how many bookings one run of the expiry job rejects.
+const expireBatch = 500
+
+// runExpirePendingBookings rejects the booking requests venues left
+// unanswered past their TTL or start time, tells the players, files full
+// refunds of anything paid online and frees the slots for anyone waiting on
+// them. The bookings are already rejected when notifying, so failures there
+// are only logged.
+func (app *application) runExpirePendingBookings(ctx context.Context) error {
+	ttlMinutes := int(app.config.bookings.pendingTTL.Minutes())
+	expired, err := app.store.Bookings.ExpireStale(ctx, ttlMinutes, expireBatch)
+	if err != nil {
+		return err
+	}
+	jobs.SetRowsAffected(ctx, int64(len(expired)))
+
+	for _, e := range expired {
+		bookingID := app.EncodeBookingID(e.BookingID)
+
+		if err := notifications.SendBookingExpired(ctx, app.push, app.store, e, bookingID); err != nil {
+			app.logger.Errorw("failed to send booking expired notification", "booking_id", e.BookingID, "error", err)
+		}
+
+		booking, err := app.store.Bookings.GetBookingByID(ctx, e.BookingID)
+		if err != nil {
+			app.logger.Errorw("failed to load expired booking", "booking_id", e.BookingID, "error", err)
+			continue
+		}
+		app.publishBookingReleased(booking, "rejected")
+		if err := app.refundRejectedBooking(ctx, booking, "Booking request expired without an answer from the venue"); err != nil {
+			app.logger.Errorw("failed to file refund for expired booking", "booking_id", e.BookingID, "error", err)
+		}
+		if !e.Started {
+			app.emailBookingDecision(booking, mailer.BookingRejectionTemplate)
+		}
+	}
+	if len(expired) > 0 {
+		app.logger.Infow("expired pending bookings", "bookings", len(expired))
+	}
+	return nil
+}
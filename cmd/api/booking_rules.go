@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/bookingrules"
+	"khel/internal/domain/bookings"
+	"khel/internal/mailer"
+	"khel/internal/notifications"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// BookingRulesPayload replaces a venue's booking rules. Leave a limit out,
+// or send null, to lift it.
+type BookingRulesPayload struct {
+	MinDurationMinutes *int `json:"min_duration_minutes" validate:"omitempty,min=30,max=1440"`
+	MaxDurationMinutes *int `json:"max_duration_minutes" validate:"omitempty,min=30,max=1440"`
+	MaxAdvanceDays     *int `json:"max_advance_days" validate:"omitempty,min=1,max=365"`
+	CancelCutoffHours  *int `json:"cancel_cutoff_hours" validate:"omitempty,min=0,max=168"`
+	AutoConfirm        bool `json:"auto_confirm"`
+	PendingTTLMinutes  *int `json:"pending_ttl_minutes" validate:"omitempty,min=15,max=10080"`
+	// MinReliabilityScore holds back players below it for the owner's
+	// approval even with auto_confirm or the venue's auto-accept window.
+	MinReliabilityScore *int `json:"min_reliability_score" validate:"omitempty,min=0,max=100"`
+	// GuestDepositPercent is what guests booking from the widget pay up
+	// front; null uses the platform default of 25%.
+	GuestDepositPercent *int `json:"guest_deposit_percent" validate:"omitempty,min=1,max=100"`
+}
+
+// getBookingRulesHandler godoc
+//
+//	@Summary		Get a venue's booking rules
+//	@Description	Venues that never set any have no limits and every booking waits for the owner. Also served to admins under /admin/venues/{venueID}/booking-rules.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	bookingrules.Rules
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/booking-rules [get]
+func (app *application) getBookingRulesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	rules, err := app.store.BookingRules.Get(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, rules)
+}
+
+// setBookingRulesHandler godoc
+//
+//	@Summary		Set a venue's booking rules
+//	@Description	Durations are in minutes, the advance window in days and the cancellation cutoff in hours before the start. With auto_confirm, bookings that pass the rules are confirmed straight away instead of waiting for the owner. With min_reliability_score as well, only players whose reliability score is at least that (or who have no score yet) are confirmed straight away or by the venue's auto-accept window; the rest wait for the owner. Requests left unanswered for pending_ttl_minutes (the platform default when null) are rejected automatically. Guests booking from the venue widget pay guest_deposit_percent of the price up front (25% when null). Applies to bookings and cancellations from now on. Also served to admins under /admin/venues/{venueID}/booking-rules.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int					true	"Venue ID"
+//	@Param			payload	body		BookingRulesPayload	true	"Rules"
+//	@Success		200		{object}	bookingrules.Rules
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/booking-rules [put]
+func (app *application) setBookingRulesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload BookingRulesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.MinDurationMinutes != nil && payload.MaxDurationMinutes != nil &&
+		*payload.MinDurationMinutes > *payload.MaxDurationMinutes {
+		app.badRequestResponse(w, r, bookingrules.ErrInvalidMinMax)
+		return
+	}
+
+	before, err := app.store.BookingRules.Get(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	rules := &bookingrules.Rules{
+		VenueID:             venueID,
+		MinDurationMinutes:  payload.MinDurationMinutes,
+		MaxDurationMinutes:  payload.MaxDurationMinutes,
+		MaxAdvanceDays:      payload.MaxAdvanceDays,
+		CancelCutoffHours:   payload.CancelCutoffHours,
+		AutoConfirm:         payload.AutoConfirm,
+		PendingTTLMinutes:   payload.PendingTTLMinutes,
+		MinReliabilityScore: payload.MinReliabilityScore,
+		GuestDepositPercent: payload.GuestDepositPercent,
+		UpdatedBy:           &user.ID,
+	}
+	if err := app.store.BookingRules.Set(r.Context(), rules); err != nil {
+		if errors.Is(err, bookingrules.ErrVenueNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.recordAudit(r, audit.EntityBookingRules, audit.ActionUpdate, venueID, before, rules)
+
+	app.jsonResponse(w, http.StatusOK, rules)
+}
+
+// deleteBookingRulesHandler godoc
+//
+//	@Summary		Reset a venue's booking rules
+//	@Description	Lifts every limit and turns auto_confirm off. Also served to admins under /admin/venues/{venueID}/booking-rules.
+//	@Tags			Venue-Owner
+//	@Param			venueID	path	int	true	"Venue ID"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/booking-rules [delete]
+func (app *application) deleteBookingRulesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	before, err := app.store.BookingRules.Get(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.store.BookingRules.Delete(r.Context(), venueID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.recordAudit(r, audit.EntityBookingRules, audit.ActionDelete, venueID, before, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bookingStatusFor returns the status a player's new booking starts in under
+// the venue's rules.
+func (app *application) bookingStatusFor(ctx context.Context, rules bookingrules.Rules, userID int64) (string, error) {
+	if !rules.AutoConfirm {
+		return "pending", nil
+	}
+	if rules.MinReliabilityScore != nil {
+		scores, err := app.store.CheckIns.Reliability(ctx, []int64{userID})
+		if err != nil {
+			return "", err
+		}
+		if !scores[userID].Meets(*rules.MinReliabilityScore) {
+			return "pending", nil
+		}
+	}
+	return "confirmed", nil
+}
+
+// isConfirmedSlotClash reports whether err is a confirmed booking landing on
+// a slot another confirmed booking took first.
+func isConfirmedSlotClash(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" &&
+		pgErr.ConstraintName == "unique_confirmed_bookings_per_venue_time"
+}
+
+// notifyBookingAutoConfirmed tells the player a booking was confirmed by the
+// venue's auto_confirm rule, as if the owner had accepted it.
+func (app *application) notifyBookingAutoConfirmed(booking *bookings.Booking) {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendBookingNotification(ctx, app.push, app.store, booking.UserID,
+			notifications.BookingAccepted, app.EncodeBookingID(booking.ID))
+	}, "booking auto-confirmed push")
+	go app.emailBookingDecision(booking, mailer.BookingConfirmationTemplate)
+}
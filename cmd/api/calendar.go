@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"khel/internal/documents"
+	"khel/internal/domain/bookings"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// calendarFeedLimit caps how many upcoming bookings go into a feed.
+const calendarFeedLimit = 200
+
+// calendarSignature signs a user's calendar feed URL. Calendar apps cannot
+// send our auth header, so the signature in the URL is what grants access.
+func (app *application) calendarSignature(userID int64) string {
+	mac := hmac.New(sha256.New, []byte(app.config.auth.token.secret))
+	_, _ = fmt.Fprintf(mac, "calendar:%d", userID)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// publicBaseURL is the scheme and host for links we hand out, such as feed
+// and download URLs. Plain http is only used outside production when the
+// request itself came in over http.
+func (app *application) publicBaseURL(r *http.Request) string {
+	scheme := "https"
+	if r.TLS == nil && r.Header.Get("X-Forwarded-Proto") != "https" && app.config.env != "production" && app.config.env != "prod" {
+		scheme = "http"
+	}
+	return scheme + "://" + app.config.apiURL
+}
+
+func (app *application) calendarFeedURL(r *http.Request, userID int64) string {
+	return fmt.Sprintf("%s/v1/users/calendar.ics?uid=%d&sig=%s", app.publicBaseURL(r), userID, app.calendarSignature(userID))
+}
+
+// getCalendarLinkHandler godoc
+//
+//	@Summary		Get my calendar subscription link
+//	@Description	Returns the URL of my personal ICS feed with upcoming confirmed bookings and joined games. Add it to Google Calendar ("From URL") or Apple Calendar (open webcal_url). Anyone with the link can read the feed.
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{object}	map[string]string	"url and webcal_url"
+//	@Security		ApiKeyAuth
+//	@Router			/users/calendar-link [get]
+func (app *application) getCalendarLinkHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	url := app.calendarFeedURL(r, user.ID)
+	_, rest, _ := strings.Cut(url, "://")
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{
+		"url":        url,
+		"webcal_url": "webcal://" + rest,
+	})
+}
+
+// calendarFeedHandler godoc
+//
+//	@Summary		My calendar feed
+//	@Description	iCalendar feed of a user's upcoming confirmed bookings and joined games. Authenticated by the signature in the link from /users/calendar-link instead of a token, so calendar apps can subscribe to it.
+//	@Tags			Users
+//	@Produce		text/calendar
+//	@Param			uid	query		int		true	"User ID"
+//	@Param			sig	query		string	true	"Signature"
+//	@Success		200	{string}	string	"ICS feed"
+//	@Failure		404	{object}	error	"Not Found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/users/calendar.ics [get]
+func (app *application) calendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, err := strconv.ParseInt(q.Get("uid"), 10, 64)
+	if err != nil || userID < 1 {
+		app.notFoundResponse(w, r, errors.New("calendar not found"))
+		return
+	}
+	if !hmac.Equal([]byte(q.Get("sig")), []byte(app.calendarSignature(userID))) {
+		app.notFoundResponse(w, r, errors.New("calendar not found"))
+		return
+	}
+
+	ctx := r.Context()
+	upcoming, err := app.store.Bookings.GetUpcomingBookingsByUser(ctx, userID, calendarFeedLimit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	games, err := app.store.Games.GetUpcomingGamesByUser(ctx, userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	events := make([]documents.Event, 0, len(upcoming)+len(games))
+	for _, b := range upcoming {
+		events = append(events, app.bookingEvent(b))
+	}
+	for _, g := range games {
+		lat, lon := g.VenueLat, g.VenueLon
+		desc := fmt.Sprintf("Hosted by %s. %d of %d players.", g.GameAdminName, g.CurrentPlayer, g.MaxPlayers)
+		if g.Price != nil {
+			desc += fmt.Sprintf(" Rs. %d per player.", *g.Price)
+		}
+		events = append(events, documents.Event{
+			UID:         fmt.Sprintf("game-%d@khel", g.GameID),
+			Start:       g.StartTime,
+			End:         g.EndTime,
+			Summary:     fmt.Sprintf("%s game at %s", sportTitle(g.SportType), g.VenueName),
+			Location:    g.VenueName,
+			Description: desc,
+			Latitude:    &lat,
+			Longitude:   &lon,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	_, _ = w.Write(documents.RenderCalendar("Khel", events))
+}
+
+// bookingCalendarHandler godoc
+//
+//	@Summary		Download a booking as a calendar event
+//	@Tags			Bookings
+//	@Produce		text/calendar
+//	@Param			bookingID	path		string	true	"Booking ID (hash or numeric)"
+//	@Success		200			{string}	string	"ICS file"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Booking not found"
+//	@Failure		409			{object}	error	"Booking was canceled or rejected"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{bookingID}/calendar.ics [get]
+func (app *application) bookingCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	booking, err := app.store.Bookings.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if booking.UserID != getUserFromContext(r).ID {
+		app.notFoundResponse(w, r, bookings.ErrNotFound)
+		return
+	}
+	if booking.Status == "canceled" || booking.Status == "rejected" {
+		app.conflictResponse(w, r, fmt.Errorf("a %s booking cannot be added to a calendar", booking.Status))
+		return
+	}
+
+	venue, err := app.store.Venues.GetVenueByID(ctx, booking.VenueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	ub := bookings.UserBooking{
+		BookingID:    booking.ID,
+		VenueID:      booking.VenueID,
+		FacilityID:   booking.FacilityID,
+		VenueName:    venue.Name,
+		VenueAddress: venue.Address,
+		StartTime:    booking.StartTime,
+		EndTime:      booking.EndTime,
+		TotalPrice:   booking.TotalPrice,
+		Status:       booking.Status,
+	}
+	if facility, err := app.store.Facilities.GetByID(ctx, booking.VenueID, booking.FacilityID); err == nil {
+		ub.FacilityName = facility.Name
+	}
+
+	hash := app.EncodeBookingID(booking.ID)
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="booking-%s.ics"`, hash))
+	_, _ = w.Write(documents.RenderCalendar(venue.Name, []documents.Event{app.bookingEvent(ub)}))
+}
+
+func (app *application) bookingEvent(b bookings.UserBooking) documents.Event {
+	summary := "Booking at " + b.VenueName
+	if b.FacilityName != "" {
+		summary += " (" + b.FacilityName + ")"
+	}
+	return documents.Event{
+		UID:         fmt.Sprintf("booking-%d@khel", b.BookingID),
+		Start:       b.StartTime,
+		End:         b.EndTime,
+		Summary:     summary,
+		Location:    b.VenueAddress,
+		Description: fmt.Sprintf("Booking %s. Rs. %d.", app.EncodeBookingID(b.BookingID), b.TotalPrice),
+	}
+}
+
+// sportTitle capitalises a sport key such as "futsal" for display.
+func sportTitle(sport string) string {
+	if sport == "" {
+		return "Pickup"
+	}
+	return strings.ToUpper(sport[:1]) + sport[1:]
+}
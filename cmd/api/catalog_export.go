@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"khel/internal/domain/products"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// productCSVHeader is the export's column order. The product import reads
+// the same names and ignores the id and is_active columns.
+var productCSVHeader = []string{
+	"product_id", "variant_id", "name", "slug", "description", "category", "brand",
+	"sku", "barcode", "price_cents", "cost_price_cents", "attributes", "image_urls", "is_active",
+}
+
+// cacheFeed lets feed crawlers, which fetch a few times a day, revalidate
+// hourly.
+const cacheFeed = "public, max-age=3600"
+
+type productExportQuery struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv json"`
+}
+
+// exportProductsHandler godoc
+//
+//	@Summary		Export the product catalog
+//	@Description	Streams every product that isn't in the trash, one row per variant, with prices, cost prices, attributes and image URLs. Products without variants get one row with empty variant columns. format=csv downloads a sheet whose columns the product import understands; json returns the rows under data.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			format	query		string	false	"Response format"	Enums(csv,json)	default(csv)
+//	@Success		200		{array}		products.CatalogRow
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/products/export [get]
+func (app *application) exportProductsHandler(w http.ResponseWriter, r *http.Request) {
+	q := productExportQuery{Format: "csv"}
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Nothing is sent until the first row arrives, so a failing query still
+	// gets a proper error response.
+	var (
+		started bool
+		write   func(products.CatalogRow) error
+		finish  func() error
+	)
+	start := func() {
+		started = true
+		stamp := time.Now().Format("2006-01-02")
+		if q.Format == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products-%s.json"`, stamp))
+			w.WriteHeader(http.StatusOK)
+			write, finish = jsonArrayStream(w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products-%s.csv"`, stamp))
+		w.WriteHeader(http.StatusOK)
+		write, finish = productCSVStream(w)
+	}
+
+	err := app.store.Products.EachCatalogRow(r.Context(), false, func(row products.CatalogRow) error {
+		if !started {
+			start()
+		}
+		return write(row)
+	})
+	if err != nil && !started {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !started {
+		start()
+	}
+	if err == nil {
+		err = finish()
+	}
+	if err != nil {
+		app.logger.Errorw("failed to stream product export", "format", q.Format, "error", err)
+	}
+}
+
+// jsonArrayStream writes rows as they come inside the usual data envelope.
+func jsonArrayStream(w io.Writer) (write func(products.CatalogRow) error, finish func() error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	write = func(row products.CatalogRow) error {
+		sep := ","
+		if n == 0 {
+			sep = `{"data":[`
+		}
+		n++
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		return enc.Encode(row)
+	}
+	finish = func() error {
+		end := "]}"
+		if n == 0 {
+			end = `{"data":[]}`
+		}
+		_, err := io.WriteString(w, end)
+		return err
+	}
+	return write, finish
+}
+
+func productCSVStream(w io.Writer) (write func(products.CatalogRow) error, finish func() error) {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(productCSVHeader)
+	write = func(row products.CatalogRow) error {
+		attrs := ""
+		if len(row.Attributes) > 0 {
+			b, err := json.Marshal(row.Attributes)
+			if err != nil {
+				return fmt.Errorf("marshal attributes of product %d: %w", row.ProductID, err)
+			}
+			attrs = string(b)
+		}
+		active := row.ProductActive && (row.VariantActive == nil || *row.VariantActive)
+		return cw.Write([]string{
+			strconv.FormatInt(row.ProductID, 10),
+			optionalInt(row.VariantID),
+			row.Name,
+			row.Slug,
+			optionalString(row.Description),
+			optionalString(row.CategorySlug),
+			optionalString(row.BrandSlug),
+			optionalString(row.SKU),
+			optionalString(row.Barcode),
+			optionalInt(row.PriceCents),
+			optionalInt(row.CostPriceCents),
+			attrs,
+			strings.Join(row.ImageURLs, "|"),
+			strconv.FormatBool(active),
+		})
+	}
+	finish = func() error {
+		cw.Flush()
+		return cw.Error()
+	}
+	return write, finish
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optionalInt(n *int64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatInt(*n, 10)
+}
+
+// merchantFeedItem is one <item> of a Google Merchant Center RSS feed.
+type merchantFeedItem struct {
+	XMLName         xml.Name `xml:"item"`
+	ID              string   `xml:"g:id"`
+	ItemGroupID     string   `xml:"g:item_group_id"`
+	Title           string   `xml:"g:title"`
+	Description     string   `xml:"g:description"`
+	Link            string   `xml:"g:link"`
+	ImageLink       string   `xml:"g:image_link,omitempty"`
+	AdditionalImage []string `xml:"g:additional_image_link,omitempty"`
+	Availability    string   `xml:"g:availability"`
+	Price           string   `xml:"g:price"`
+	Brand           string   `xml:"g:brand,omitempty"`
+	GTIN            string   `xml:"g:gtin,omitempty"`
+	MPN             string   `xml:"g:mpn"`
+	ProductType     string   `xml:"g:product_type,omitempty"`
+	Condition       string   `xml:"g:condition"`
+}
+
+// merchantFeedHandler godoc
+//
+//	@Summary		Product feed for shopping syndication
+//	@Description	Every active variant of every published product as a Google Merchant Center RSS 2.0 feed, so the catalog can be listed on shopping sites. Variants of one product share an item_group_id; the SKU is the item id. Prices are in NPR.
+//	@Tags			Store-Products
+//	@Produce		xml
+//	@Success		200	{string}	string	"RSS feed"
+//	@Failure		500	{object}	ErrorResponse	"Internal Server Error"
+//	@Router			/store/feed/google.xml [get]
+func (app *application) merchantFeedHandler(w http.ResponseWriter, r *http.Request) {
+	var items []merchantFeedItem
+	err := app.store.Products.EachCatalogRow(r.Context(), true, func(row products.CatalogRow) error {
+		items = append(items, app.merchantFeedItem(row))
+		return nil
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = io.WriteString(w, xml.Header+`<rss version="2.0" xmlns:g="http://base.google.com/ns/1.0"><channel>`)
+	enc := xml.NewEncoder(w)
+	for _, el := range []struct{ name, value string }{
+		{"title", "Khel Store"},
+		{"link", app.config.frontendURL + "/store"},
+		{"description", "Khel store products"},
+	} {
+		_ = enc.EncodeElement(el.value, xml.StartElement{Name: xml.Name{Local: el.name}})
+	}
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			app.logger.Errorw("failed to write merchant feed", "error", err)
+			return
+		}
+	}
+	_, _ = io.WriteString(w, `</channel></rss>`)
+}
+
+func (app *application) merchantFeedItem(row products.CatalogRow) merchantFeedItem {
+	title := row.Name
+	if len(row.Attributes) > 0 {
+		keys := make([]string, 0, len(row.Attributes))
+		for k := range row.Attributes {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		values := make([]string, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, fmt.Sprint(row.Attributes[k]))
+		}
+		title += " - " + strings.Join(values, ", ")
+	}
+
+	item := merchantFeedItem{
+		ID:           optionalString(row.SKU),
+		ItemGroupID:  strconv.FormatInt(row.ProductID, 10),
+		Title:        title,
+		Description:  row.Name,
+		Link:         fmt.Sprintf("%s/store/products/%s", app.config.frontendURL, row.Slug),
+		Availability: "in_stock",
+		Brand:        optionalString(row.BrandName),
+		GTIN:         optionalString(row.Barcode),
+		MPN:          optionalString(row.SKU),
+		ProductType:  optionalString(row.CategoryName),
+		Condition:    "new",
+	}
+	if row.Description != nil && *row.Description != "" {
+		item.Description = *row.Description
+	}
+	if row.PriceCents != nil {
+		item.Price = fmt.Sprintf("%d.%02d NPR", *row.PriceCents/100, *row.PriceCents%100)
+	}
+	if len(row.ImageURLs) > 0 {
+		item.ImageLink = row.ImageURLs[0]
+		// Merchant Center reads at most ten additional images.
+		item.AdditionalImage = row.ImageURLs[1:min(len(row.ImageURLs), 11)]
+	}
+	return item
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/products"
+	"khel/internal/params"
+	"net/http"
+	"time"
+)
+
+// ---------- Admin: Catalog trash ----------
+
+// ListTrashedBrands godoc
+//
+//	@Summary		List trashed brands
+//	@Description	Returns soft-deleted brands, most recently deleted first.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"brands + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/trash/brands [get]
+func (app *application) listTrashedBrandsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pagination := params.ParsePagination(r.URL.Query())
+	brands, total, err := app.store.Products.ListTrashedBrands(ctx, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"brands":     brands,
+		"pagination": pagination,
+	})
+}
+
+// ListTrashedCategories godoc
+//
+//	@Summary		List trashed categories
+//	@Description	Returns soft-deleted categories, most recently deleted first.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"categories + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/trash/categories [get]
+func (app *application) listTrashedCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pagination := params.ParsePagination(r.URL.Query())
+	cats, total, err := app.store.Products.ListTrashedCategories(ctx, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"categories": cats,
+		"pagination": pagination,
+	})
+}
+
+// ListTrashedProducts godoc
+//
+//	@Summary		List trashed products
+//	@Description	Returns soft-deleted products, most recently deleted first.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"products + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/trash/products [get]
+func (app *application) listTrashedProductsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pagination := params.ParsePagination(r.URL.Query())
+	list, total, err := app.store.Products.ListTrashedProducts(ctx, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"products":   list,
+		"pagination": pagination,
+	})
+}
+
+// RestoreBrand godoc
+//
+//	@Summary		Restore a brand
+//	@Description	Takes a brand out of the trash.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Param			brandID	path		int				true	"Brand ID"
+//	@Success		200		{object}	products.Brand	"Restored brand"
+//	@Failure		400		{object}	error			"Bad Request: invalid brand ID"
+//	@Failure		404		{object}	error			"Brand is not in the trash"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/brands/{brandID}/restore [post]
+func (app *application) restoreBrandHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := readIDParam(r, "brandID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid brand ID"))
+		return
+	}
+
+	brand, err := app.store.Products.RestoreBrand(ctx, id)
+	if err != nil {
+		if errors.Is(err, products.ErrNotInTrash) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityBrand, audit.ActionRestore, id, nil, brand)
+
+	app.jsonResponse(w, http.StatusOK, brand)
+}
+
+// RestoreCategory godoc
+//
+//	@Summary		Restore a category
+//	@Description	Takes a category out of the trash. Its parent category must be restored first.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Param			categoryID	path		int					true	"Category ID"
+//	@Success		200			{object}	products.Category	"Restored category"
+//	@Failure		400			{object}	error				"Bad Request: invalid category ID"
+//	@Failure		404			{object}	error				"Category is not in the trash"
+//	@Failure		409			{object}	error				"Parent category is still in the trash"
+//	@Failure		500			{object}	error				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/categories/{categoryID}/restore [post]
+func (app *application) restoreCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := readIDParam(r, "categoryID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid category ID"))
+		return
+	}
+
+	cat, err := app.store.Products.RestoreCategory(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, products.ErrNotInTrash):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, products.ErrInvalidParent):
+			app.conflictResponse(w, r, fmt.Errorf("restore the parent category first"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityCategory, audit.ActionRestore, id, nil, cat)
+
+	app.jsonResponse(w, http.StatusOK, cat)
+}
+
+// RestoreProduct godoc
+//
+//	@Summary		Restore a product
+//	@Description	Takes a product out of the trash together with its variants and images.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Param			productID	path		int					true	"Product ID"
+//	@Success		200			{object}	products.Product	"Restored product"
+//	@Failure		400			{object}	error				"Bad Request: invalid product ID"
+//	@Failure		404			{object}	error				"Product is not in the trash"
+//	@Failure		500			{object}	error				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/products/{productID}/restore [post]
+func (app *application) restoreProductHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := readIDParam(r, "productID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid product ID"))
+		return
+	}
+
+	p, err := app.store.Products.RestoreProduct(ctx, id)
+	if err != nil {
+		if errors.Is(err, products.ErrNotInTrash) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityProduct, audit.ActionRestore, id, nil, p)
+	app.publishProductChanged(ctx, id)
+
+	app.jsonResponse(w, http.StatusOK, p)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/checkins"
+	"khel/internal/domain/games"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CheckInCodeResponse is what the player's app turns into a QR code.
+type CheckInCodeResponse struct {
+	Token     string    `json:"token"`
+	Kind      string    `json:"kind"`
+	BookingID string    `json:"booking_id,omitempty"`
+	GameID    *int64    `json:"game_id,omitempty"`
+	VenueID   int64     `json:"venue_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type CheckInPayload struct {
+	Token string `json:"token" validate:"required,max=200"`
+}
+
+type CheckInResponse struct {
+	checkins.CheckIn
+	BookingID string `json:"booking_id,omitempty"`
+	// AlreadyCheckedIn is set when the code had been scanned before; the
+	// first check-in is returned.
+	AlreadyCheckedIn bool      `json:"already_checked_in"`
+	PlayerName       string    `json:"player_name"`
+	StartTime        time.Time `json:"start_time"`
+	EndTime          time.Time `json:"end_time"`
+}
+
+func (app *application) checkInSecret() []byte {
+	return []byte(app.config.auth.token.secret)
+}
+
+// getBookingQRHandler godoc
+//
+//	@Summary		Get my booking's check-in code
+//	@Description	Returns the token to show as a QR code at the venue. Only confirmed bookings have one; the venue can scan it from an hour before the start until the end.
+//	@Tags			Bookings
+//	@Produce		json
+//	@Param			bookingID	path		string	true	"Booking ID"
+//	@Success		200			{object}	CheckInCodeResponse
+//	@Failure		404			{object}	error	"Booking not found"
+//	@Failure		409			{object}	error	"Booking is not confirmed"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{bookingID}/qr [get]
+func (app *application) getBookingQRHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	booking, err := app.store.Bookings.GetBookingByID(r.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	user := getUserFromContext(r)
+	if booking.UserID != user.ID {
+		app.notFoundResponse(w, r, bookings.ErrNotFound)
+		return
+	}
+	if booking.Status != "confirmed" {
+		app.conflictResponse(w, r, fmt.Errorf("a %s booking has no check-in code", booking.Status))
+		return
+	}
+
+	token := checkins.Sign(app.checkInSecret(), checkins.Subject{Kind: checkins.KindBooking, ID: booking.ID, UserID: user.ID})
+	app.jsonResponse(w, http.StatusOK, CheckInCodeResponse{
+		Token:     token,
+		Kind:      checkins.KindBooking,
+		BookingID: app.EncodeBookingID(booking.ID),
+		VenueID:   booking.VenueID,
+		StartTime: booking.StartTime,
+		EndTime:   booking.EndTime,
+	})
+}
+
+// getGameQRHandler godoc
+//
+//	@Summary		Get my check-in code for a game
+//	@Description	Returns the token to show as a QR code at the venue. Every player of a game that isn't cancelled has their own; the venue can scan it from an hour before the start until the end.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{object}	CheckInCodeResponse
+//	@Failure		404		{object}	error	"Not a player of this game"
+//	@Failure		409		{object}	error	"Game is cancelled"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/qr [get]
+func (app *application) getGameQRHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if _, err := app.store.Games.GetMember(r.Context(), gameID, user.ID); err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("you are not a player of this game"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	game, err := app.store.Games.GetGameByID(r.Context(), gameID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if game.Status == "cancelled" {
+		app.conflictResponse(w, r, errors.New("a cancelled game has no check-in code"))
+		return
+	}
+
+	token := checkins.Sign(app.checkInSecret(), checkins.Subject{Kind: checkins.KindGame, ID: game.ID, UserID: user.ID})
+	app.jsonResponse(w, http.StatusOK, CheckInCodeResponse{
+		Token:     token,
+		Kind:      checkins.KindGame,
+		GameID:    &game.ID,
+		VenueID:   game.VenueID,
+		StartTime: game.StartTime,
+		EndTime:   game.EndTime,
+	})
+}
+
+// checkInHandler godoc
+//
+//	@Summary		Check a player in
+//	@Description	Scans a player's booking or game QR code and records their attendance. Works from an hour before the start until the end. Scanning a code again returns the first check-in with already_checked_in set.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			payload	body		CheckInPayload	true	"Scanned token"
+//	@Success		201		{object}	CheckInResponse	"Checked in"
+//	@Success		200		{object}	CheckInResponse	"Already checked in"
+//	@Failure		400		{object}	error	"Invalid code, or outside the check-in window"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		409		{object}	error	"Booking or game can't be checked in"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/check-in [post]
+func (app *application) checkInHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, _ := readIDParam(r, "venueID")
+
+	var payload CheckInPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	subject, err := checkins.Parse(app.checkInSecret(), payload.Token)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	c := &checkins.CheckIn{Kind: subject.Kind, UserID: subject.UserID}
+	var start, end time.Time
+	switch subject.Kind {
+	case checkins.KindBooking:
+		booking, err := app.store.Bookings.GetBookingByID(ctx, subject.ID)
+		if err != nil {
+			if errors.Is(err, bookings.ErrNotFound) {
+				app.badRequestResponse(w, r, checkins.ErrInvalidToken)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+		if booking.UserID != subject.UserID {
+			app.badRequestResponse(w, r, checkins.ErrInvalidToken)
+			return
+		}
+		if booking.Status != "confirmed" && booking.Status != "done" {
+			app.conflictResponse(w, r, fmt.Errorf("%w: the booking is %s", checkins.ErrNotCheckable, booking.Status))
+			return
+		}
+		c.VenueID, c.BookingID = booking.VenueID, &booking.ID
+		start, end = booking.StartTime, booking.EndTime
+
+	case checkins.KindGame:
+		game, err := app.store.Games.GetGameByID(ctx, subject.ID)
+		if err != nil {
+			app.badRequestResponse(w, r, checkins.ErrInvalidToken)
+			return
+		}
+		if game.Status == "cancelled" {
+			app.conflictResponse(w, r, fmt.Errorf("%w: the game is cancelled", checkins.ErrNotCheckable))
+			return
+		}
+		if _, err := app.store.Games.GetMember(ctx, game.ID, subject.UserID); err != nil {
+			if errors.Is(err, games.ErrNotFound) {
+				app.conflictResponse(w, r, fmt.Errorf("%w: the player has left the game", checkins.ErrNotCheckable))
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+		c.VenueID, c.GameID = game.VenueID, &game.ID
+		start, end = game.StartTime, game.EndTime
+	}
+
+	if c.VenueID != venueID {
+		app.badRequestResponse(w, r, checkins.ErrWrongVenue)
+		return
+	}
+	if err := checkins.CheckWindow(start, end, time.Now()); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	staff := getUserFromContext(r)
+	c.CheckedInBy = &staff.ID
+	created, err := app.store.CheckIns.Record(ctx, c)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	resp := CheckInResponse{
+		CheckIn:          *c,
+		AlreadyCheckedIn: !created,
+		StartTime:        start,
+		EndTime:          end,
+	}
+	if c.BookingID != nil {
+		resp.BookingID = app.EncodeBookingID(*c.BookingID)
+	}
+	if player, err := app.store.Users.GetByID(ctx, c.UserID); err == nil {
+		resp.PlayerName = strings.TrimSpace(player.FirstName + " " + player.LastName)
+	}
+
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	app.jsonResponse(w, status, resp)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/closures"
+	"khel/internal/domain/refunds"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxClosureSpan keeps a mistyped end date from wiping out months of
+// bookings.
+const maxClosureSpan = 14 * 24 * time.Hour
+
+type CreateClosurePayload struct {
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+	Reason    string    `json:"reason" validate:"required,oneof=flood power_cut weather other"`
+	Note      *string   `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+type processClosurePayload struct {
+	ClosureID int64                      `json:"closure_id"`
+	VenueID   int64                      `json:"venue_id"`
+	OwnerID   int64                      `json:"owner_id"`
+	Reason    string                     `json:"reason"`
+	Bookings  []closures.CanceledBooking `json:"bookings"`
+	Games     []closureGamePayload       `json:"games"`
+}
+
+type closureGamePayload struct {
+	ID                 int64     `json:"id"`
+	SportType          string    `json:"sport_type"`
+	StartTime          time.Time `json:"start_time"`
+	RejectedRequesters []int64   `json:"rejected_requesters"`
+}
+
+// createClosureHandler godoc
+//
+//	@Summary		Declare an emergency closure
+//	@Description	Blacks out the venue between start_time and end_time (at most 14 days) so nothing can be booked then. Pending and confirmed bookings that haven't started are canceled, with anything paid online refunded in full right away, and active games in the period are canceled. Everyone affected is notified in the background.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int						true	"Venue ID"
+//	@Param			payload	body		CreateClosurePayload	true	"Closure"
+//	@Success		201		{object}	closures.Result
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		403		{object}	ErrorResponse	"Forbidden"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/closures [post]
+func (app *application) createClosureHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload CreateClosurePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if !payload.EndTime.After(time.Now()) {
+		app.badRequestResponse(w, r, errors.New("end_time must be in the future"))
+		return
+	}
+	if payload.EndTime.Sub(payload.StartTime) > maxClosureSpan {
+		app.badRequestResponse(w, r, errors.New("a closure can last at most 14 days"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	c := &closures.Closure{
+		VenueID:   venueID,
+		StartTime: payload.StartTime,
+		EndTime:   payload.EndTime,
+		Reason:    payload.Reason,
+		Note:      cleanOptionalString(payload.Note),
+		CreatedBy: &user.ID,
+	}
+
+	ctx := r.Context()
+	res, err := app.store.Closures.Create(ctx, c)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if len(res.Bookings) > 0 || len(res.Games) > 0 {
+		p := processClosurePayload{
+			ClosureID: c.ID,
+			VenueID:   venueID,
+			OwnerID:   user.ID,
+			Reason:    c.Reason,
+			Bookings:  res.Bookings,
+			Games:     make([]closureGamePayload, 0, len(res.Games)),
+		}
+		for _, g := range res.Games {
+			p.Games = append(p.Games, closureGamePayload(g))
+		}
+		// The cancellations are committed; a failed enqueue only loses the
+		// follow-up, so log it rather than fail the request.
+		if _, err := app.store.Jobs.Enqueue(ctx, jobProcessClosure, p, jobs.EnqueueOptions{}); err != nil {
+			app.requestLogger(r).Errorw("failed to enqueue closure follow-up", "closure_id", c.ID, "error", err)
+		}
+	}
+
+	app.jsonResponse(w, http.StatusCreated, res)
+}
+
+// listClosuresHandler godoc
+//
+//	@Summary		List my venue's closures
+//	@Description	Closures that haven't ended yet, soonest first.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{array}		closures.Closure
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		403		{object}	ErrorResponse	"Forbidden"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/closures [get]
+func (app *application) listClosuresHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	list, err := app.store.Closures.ListByVenue(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// deleteClosureHandler godoc
+//
+//	@Summary		Lift a closure
+//	@Description	Opens the period for booking again. Bookings and games the closure canceled stay canceled.
+//	@Tags			Venue-Owner
+//	@Param			venueID		path	int	true	"Venue ID"
+//	@Param			closureID	path	int	true	"Closure ID"
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse	"Bad Request"
+//	@Failure		403	{object}	ErrorResponse	"Forbidden"
+//	@Failure		404	{object}	ErrorResponse	"Closure not found"
+//	@Failure		500	{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/closures/{closureID} [delete]
+func (app *application) deleteClosureHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	closureID, err := readIDParam(r, "closureID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid closure ID"))
+		return
+	}
+
+	if err := app.store.Closures.Delete(r.Context(), venueID, closureID); err != nil {
+		if errors.Is(err, closures.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runProcessClosure follows up on what a closure canceled: freed slots are
+// announced, online payments are refunded in full and everyone affected is
+// notified. The cancellations are already committed, so failures are logged
+// and the rest carries on.
+func (app *application) runProcessClosure(ctx context.Context, p processClosurePayload) error {
+	venueName := "The venue"
+	if v, err := app.store.Venues.GetVenueByID(ctx, p.VenueID); err == nil {
+		venueName = v.Name
+	} else {
+		app.logger.Errorw("failed to load closed venue", "venue_id", p.VenueID, "error", err)
+	}
+
+	reason := fmt.Sprintf("Venue closed (%s).", strings.ReplaceAll(p.Reason, "_", " "))
+	for _, cb := range p.Bookings {
+		booking, err := app.store.Bookings.GetBookingByID(ctx, cb.ID)
+		if err != nil {
+			app.logger.Errorw("failed to load closed booking", "booking_id", cb.ID, "error", err)
+			continue
+		}
+		app.publishBookingReleased(booking, "canceled")
+
+		// Manual bookings belong to the owner, who knows about the closure.
+		if booking.UserID == p.OwnerID {
+			continue
+		}
+
+		refunded, err := app.refundClosedBooking(ctx, p.OwnerID, booking, reason)
+		if err != nil {
+			app.logger.Errorw("failed to refund closed booking", "closure_id", p.ClosureID, "booking_id", booking.ID, "error", err)
+		}
+
+		if err := notifications.SendClosureBookingCanceled(ctx, app.push, app.store, booking.UserID,
+			app.EncodeBookingID(booking.ID), venueName, p.Reason, booking.StartTime, refunded); err != nil {
+			app.logger.Errorw("failed to send closure booking notification", "booking_id", booking.ID, "error", err)
+		}
+	}
+
+	for _, g := range p.Games {
+		if err := notifications.SendClosureGameCanceled(ctx, app.push, app.store, closures.CanceledGame(g), venueName, p.Reason); err != nil {
+			app.logger.Errorw("failed to send closure game notifications", "game_id", g.ID, "error", err)
+		}
+	}
+
+	jobs.SetRowsAffected(ctx, int64(len(p.Bookings)+len(p.Games)))
+	return nil
+}
+
+// refundClosedBooking refunds everything paid online for a booking the venue
+// canceled, approved by the owner on the spot. A refund the player already
+// requested, sized by the cancellation policy, is raised to the full amount.
+// It returns the amount being refunded: 0 when nothing was paid online, or
+// what an earlier refund already being paid out covers.
+func (app *application) refundClosedBooking(ctx context.Context, ownerID int64, booking *bookings.Booking, reason string) (int, error) {
+	total, err := app.paidOnline(ctx, booking.ID)
+	if err != nil {
+		if errors.Is(err, errNothingPaidOnline) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	rf, err := app.createBookingRefund(ctx, booking, booking.UserID, &reason, total, 100)
+	if errors.Is(err, refunds.ErrAlreadyRequested) {
+		rf, err = app.store.Refunds.RaiseToFull(ctx, booking.ID, total, &reason)
+		if errors.Is(err, refunds.ErrNotPending) {
+			existing, gerr := app.store.Refunds.GetByBooking(ctx, booking.ID)
+			if gerr != nil {
+				return 0, gerr
+			}
+			return existing.RefundAmount, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := app.store.Refunds.Approve(ctx, rf.ID, ownerID, &reason); err != nil {
+		return 0, err
+	}
+	if err := app.payOutRefund(ctx, rf); err != nil {
+		return 0, err
+	}
+	return rf.RefundAmount, nil
+}
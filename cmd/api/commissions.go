@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/commissions"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CreateCommissionRatePayload struct {
+	// VenueID is omitted for the platform default.
+	VenueID *int64 `json:"venue_id" validate:"omitempty,min=1"`
+	Percent *int   `json:"percent" validate:"required,min=0,max=50"`
+	// EffectiveFrom is RFC3339; omitted means now.
+	EffectiveFrom *time.Time `json:"effective_from"`
+	Note          *string    `json:"note" validate:"omitempty,max=500"`
+}
+
+// adminListCommissionRatesHandler godoc
+//
+//	@Summary		List commission rates
+//	@Description	Platform defaults first, then venue overrides, newest first within each. A rate applies from effective_from until the next rate of the same scope; a venue override beats the default.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			venue_id	query		int				false	"Only this venue's overrides"
+//	@Param			defaults	query		bool			false	"Only platform defaults"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"rates + pagination metadata"
+//	@Failure		400			{object}	error			"Bad Request"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/commissions [get]
+func (app *application) adminListCommissionRatesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var f commissions.Filter
+	if v := strings.TrimSpace(q.Get("venue_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid venue_id"))
+			return
+		}
+		f.VenueID = &id
+	}
+	f.DefaultsOnly = q.Get("defaults") == "true"
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Commissions.List(r.Context(), f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"rates":      list,
+		"pagination": pagination,
+	})
+}
+
+// adminCreateCommissionRateHandler godoc
+//
+//	@Summary		Add a commission rate
+//	@Description	Sets the platform default (no venue_id) or a venue override from effective_from on. Bookings keep the rate in force when they were confirmed, so a new rate only affects bookings confirmed after it takes effect.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateCommissionRatePayload	true	"Rate"
+//	@Success		201		{object}	commissions.Rate
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		409		{object}	error	"A rate already starts at that time"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/commissions [post]
+func (app *application) adminCreateCommissionRateHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateCommissionRatePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	rate := &commissions.Rate{
+		VenueID:       payload.VenueID,
+		Percent:       *payload.Percent,
+		EffectiveFrom: time.Now(),
+		Note:          cleanOptionalString(payload.Note),
+		CreatedBy:     &getUserFromContext(r).ID,
+	}
+	if payload.EffectiveFrom != nil {
+		if payload.EffectiveFrom.Before(time.Now().Add(-time.Minute)) {
+			app.badRequestResponse(w, r, errors.New("effective_from cannot be in the past"))
+			return
+		}
+		rate.EffectiveFrom = *payload.EffectiveFrom
+	}
+	if rate.VenueID != nil {
+		venue, err := app.store.Venues.GetVenueByID(ctx, *rate.VenueID)
+		if err != nil {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		rate.VenueName = &venue.Name
+	}
+
+	if err := app.store.Commissions.Create(ctx, rate); err != nil {
+		if errors.Is(err, commissions.ErrDuplicate) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.recordAudit(r, audit.EntityCommissionRate, audit.ActionCreate, rate.ID, nil, rate)
+
+	app.jsonResponse(w, http.StatusCreated, rate)
+}
+
+// adminDeleteCommissionRateHandler godoc
+//
+//	@Summary		Delete a scheduled commission rate
+//	@Description	Only rates that have not taken effect yet can be deleted; to change a rate in force, add a new one.
+//	@Tags			Admin
+//	@Param			rateID	path		int		true	"Rate ID"
+//	@Success		204		{string}	string	"Deleted"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Rate not found"
+//	@Failure		409		{object}	error	"Rate already in effect"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/commissions/{rateID} [delete]
+func (app *application) adminDeleteCommissionRateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "rateID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid rate ID"))
+		return
+	}
+
+	rate, err := app.store.Commissions.Delete(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, commissions.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, commissions.ErrAlreadyEffective):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.recordAudit(r, audit.EntityCommissionRate, audit.ActionDelete, id, rate, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminGetVenueCommissionHandler godoc
+//
+//	@Summary		Get a venue's current commission rate
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	commissions.Effective
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/venues/{venueID}/commission [get]
+func (app *application) adminGetVenueCommissionHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	e, err := app.store.Commissions.Effective(r.Context(), venueID, time.Now())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, e)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressMinSize is the smallest body worth compressing. Below it the
+// gzip framing and CPU cost more than the bytes saved.
+const compressMinSize = 1024
+
+// compressibleTypes are the media types compressMiddleware touches. Images,
+// PDFs and event streams are left alone: the first two are already
+// compressed and the last must reach the client as it is written.
+var compressibleTypes = map[string]bool{
+	"application/json":       true,
+	"application/javascript": true,
+	"application/xml":        true,
+	"text/css":               true,
+	"text/csv":               true,
+	"text/html":              true,
+	"text/plain":             true,
+	"image/svg+xml":          true,
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var compressorPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		zw, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return zw
+	}},
+	"deflate": {New: func() any {
+		zw, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return zw
+	}},
+}
+
+// compressMiddleware gzips or deflates responses for clients that ask for it
+// in Accept-Encoding. Only bodies of a compressible type and at least
+// compressMinSize bytes are compressed; everything else goes out as written.
+func (app *application) compressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks gzip, then deflate, from an Accept-Encoding header,
+// skipping any the client refused with q=0. It returns "" for identity.
+func negotiateEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if name != "" {
+			accepted[name] = q > 0
+		}
+	}
+	for _, enc := range []string{"gzip", "deflate"} {
+		if ok, listed := accepted[enc]; listed {
+			if ok {
+				return enc
+			}
+			continue
+		}
+		if accepted["*"] {
+			return enc
+		}
+	}
+	return ""
+}
+
+// compressResponseWriter holds back the first compressMinSize bytes so it
+// can tell whether the body is worth compressing before any header is sent.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	zw          compressor
+}
+
+func (cw *compressResponseWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.status = status
+	cw.wroteHeader = true
+	if !cw.mayCompress() {
+		_ = cw.decide(false)
+	}
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.zw != nil {
+			return cw.zw.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= compressMinSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends what has been written so far. A flush before the threshold
+// is reached means the handler is streaming, so the rest goes uncompressed.
+func (cw *compressResponseWriter) Flush() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		_ = cw.decide(false)
+	}
+	if cw.zw != nil {
+		_ = cw.zw.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// mayCompress reports whether the response could still be compressed,
+// judging by what is known before the body arrives.
+func (cw *compressResponseWriter) mayCompress() bool {
+	if cw.status < http.StatusOK || cw.status == http.StatusNoContent ||
+		cw.status == http.StatusNotModified || cw.status == http.StatusPartialContent {
+		return false
+	}
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" {
+		// Sniffed from the body once it arrives.
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	return err == nil && compressibleTypes[mediaType]
+}
+
+// decide sends the headers and whatever was buffered, compressing from here
+// on when big is set and the response qualifies.
+func (cw *compressResponseWriter) decide(big bool) error {
+	cw.decided = true
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	if big && cw.mayCompress() {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Add("Vary", "Accept-Encoding")
+		h.Del("Content-Length")
+		// The compressed bytes differ from what the ETag was hashed from,
+		// so it can only stand as a weak validator.
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.zw = compressorPools[cw.encoding].Get().(compressor)
+		cw.zw.Reset(cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.zw != nil {
+		_, err := cw.zw.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// close finishes the response once the handler returns: a body that never
+// reached the threshold is sent as is, and a compressor is flushed and
+// returned to its pool.
+func (cw *compressResponseWriter) close() {
+	if !cw.decided && cw.wroteHeader {
+		_ = cw.decide(false)
+	}
+	if cw.zw != nil {
+		_ = cw.zw.Close()
+		cw.zw.Reset(io.Discard)
+		compressorPools[cw.encoding].Put(cw.zw)
+		cw.zw = nil
+	}
+}
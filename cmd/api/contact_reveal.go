@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/bookings"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maskPhone hides all but the last three digits of a phone number, keeping
+// its length and any leading "+". Owner booking lists only ever show masked
+// numbers; the full one comes from the reveal endpoint.
+func maskPhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	if phone == "" {
+		return ""
+	}
+	prefix := ""
+	if strings.HasPrefix(phone, "+") {
+		prefix, phone = "+", phone[1:]
+	}
+	keep := 3
+	if len(phone) <= keep+2 {
+		keep = 0
+	}
+	return prefix + strings.Repeat("*", len(phone)-keep) + phone[len(phone)-keep:]
+}
+
+func maskOptionalPhone(phone *string) *string {
+	if phone == nil {
+		return nil
+	}
+	masked := maskPhone(*phone)
+	return &masked
+}
+
+// revealBookingContactHandler godoc
+//
+//	@Summary		Reveal a booking's phone numbers
+//	@Description	Booking lists show phone numbers masked. This returns the full numbers of one booking at the venue. Every reveal is written to the audit log, and an owner account can reveal at most 30 bookings an hour.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID		path		int		true	"Venue ID"
+//	@Param			bookingID	path		string	true	"Booking ID"
+//	@Success		200			{object}	bookings.Contact
+//	@Failure		400			{object}	ErrorResponse	"Bad Request"
+//	@Failure		403			{object}	ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	ErrorResponse	"Booking not found"
+//	@Failure		429			{object}	ErrorResponse	"Too Many Requests"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/bookings/{bookingID}/reveal-contact [post]
+func (app *application) revealBookingContactHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Keyed by account rather than IP so staff sharing one login at the
+	// front desk can't get around it, and owners on one network don't
+	// share a budget.
+	user := getUserFromContext(r)
+	if allow, retryAfter := app.contactRevealLimiter.Allow("user:" + strconv.FormatInt(user.ID, 10)); !allow {
+		app.rateLimitExceededResponse(w, r, retryAfter.String())
+		return
+	}
+
+	contact, err := app.store.Bookings.GetContact(r.Context(), venueID, bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// The numbers themselves stay out of the audit trail.
+	app.recordAudit(r, audit.EntityBooking, audit.ActionRevealContact, bookingID, nil, map[string]any{
+		"venue_id":           venueID,
+		"has_customer_phone": contact.CustomerPhone != nil,
+	})
+
+	w.Header().Set("Cache-Control", "no-store")
+	app.jsonResponse(w, http.StatusOK, contact)
+}
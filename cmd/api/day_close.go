@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/venuedaycloses"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CloseDayPayload struct {
+	Date string  `json:"date" validate:"required,datetime=2006-01-02"`
+	Note *string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+type ReopenDayPayload struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type dayClosesQuery struct {
+	Limit int `query:"limit" validate:"min=1,max=100"`
+}
+
+// parseBusinessDate reads a YYYY-MM-DD as a day in Nepal. Days that haven't
+// started yet can't be closed or reopened.
+func parseBusinessDate(s string) (time.Time, error) {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return time.Time{}, err
+	}
+	date, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		return time.Time{}, errors.New("date must be YYYY-MM-DD")
+	}
+	if date.After(time.Now().In(loc)) {
+		return time.Time{}, errors.New("date is in the future")
+	}
+	return date, nil
+}
+
+// previewDayCloseHandler godoc
+//
+//	@Summary		Preview a day's close
+//	@Description	The figures closing the day would record: bookings honored, no-shows, cancellations, cash and other payments taken at the counter, and online payments. unfinished counts bookings still pending or not over yet; the day can't be closed until it is 0.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int		true	"Venue ID"
+//	@Param			date	query		string	true	"Date in YYYY-MM-DD format"
+//	@Success		200		{object}	venuedaycloses.Summary
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		403		{object}	ErrorResponse	"Forbidden"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/close-day [get]
+func (app *application) previewDayCloseHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	var q bookingDateQuery
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	s, err := app.store.DayCloses.Summarize(r.Context(), venueID, q.Date)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, s)
+}
+
+// closeDayHandler godoc
+//
+//	@Summary		Close a business day
+//	@Description	Records the day's summary for good, once every booking on it is answered and over. Settlements list the days of their period that aren't closed yet. After closing, that day's bookings can't be added, checked out, canceled or moved (409 DAY_CLOSED) until an admin reopens it.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			payload	body		CloseDayPayload	true	"Day to close"
+//	@Success		201		{object}	venuedaycloses.DayClose
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		403		{object}	ErrorResponse	"Forbidden"
+//	@Failure		409		{object}	ErrorResponse	"Day already closed or still has open bookings"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/close-day [post]
+func (app *application) closeDayHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload CloseDayPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	date, err := parseBusinessDate(payload.Date)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	c, err := app.store.DayCloses.Close(r.Context(), venueID, date, user.ID, cleanOptionalString(payload.Note))
+	if err != nil {
+		switch {
+		case errors.Is(err, venuedaycloses.ErrAlreadyClosed), errors.Is(err, venuedaycloses.ErrDayNotOver):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.jsonResponse(w, http.StatusCreated, c)
+}
+
+// listDayClosesHandler godoc
+//
+//	@Summary		List my venue's day closes
+//	@Description	Closed days, newest first. Reopened closes stay in the list with reopened_at set.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Param			limit	query		int	false	"Max rows (1-100)"	default(30)
+//	@Success		200		{array}		venuedaycloses.DayClose
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		403		{object}	ErrorResponse	"Forbidden"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/day-closes [get]
+func (app *application) listDayClosesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	q := dayClosesQuery{Limit: 30}
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	list, err := app.store.DayCloses.ListByVenue(r.Context(), venueID, q.Limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// adminReopenDayHandler godoc
+//
+//	@Summary		Reopen a closed day
+//	@Description	Admin override that lets a closed day's bookings change again, e.g. to fix a checkout recorded with the wrong amount. The close is kept with the reason; the owner closes the day again afterwards.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int					true	"Venue ID"
+//	@Param			date	path		string				true	"Date in YYYY-MM-DD format"
+//	@Param			payload	body		ReopenDayPayload	true	"Why it is reopened"
+//	@Success		200		{object}	venuedaycloses.DayClose
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		404		{object}	ErrorResponse	"Day is not closed"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/venues/{venueID}/day-closes/{date}/reopen [post]
+func (app *application) adminReopenDayHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	date, err := parseBusinessDate(chi.URLParam(r, "date"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload ReopenDayPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	c, err := app.store.DayCloses.Reopen(r.Context(), venueID, date, user.ID, payload.Reason)
+	if err != nil {
+		if errors.Is(err, venuedaycloses.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityVenueDayClose, audit.ActionReopen, c.ID, nil, c)
+	app.jsonResponse(w, http.StatusOK, c)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/demosandboxes"
+	"khel/internal/jobs"
+	"net/http"
+	"time"
+)
+
+// DemoSettingsPayload leaves out what should keep its current (or default)
+// value.
+type DemoSettingsPayload struct {
+	DaysBack       *int `json:"days_back" validate:"omitempty,min=0,max=30"`
+	DaysAhead      *int `json:"days_ahead" validate:"omitempty,min=0,max=14"`
+	BookingsPerDay *int `json:"bookings_per_day" validate:"omitempty,min=0,max=15"`
+	GamesPerWeek   *int `json:"games_per_week" validate:"omitempty,min=0,max=20"`
+}
+
+func (p DemoSettingsPayload) apply(s demosandboxes.Settings) demosandboxes.Settings {
+	if p.DaysBack != nil {
+		s.DaysBack = *p.DaysBack
+	}
+	if p.DaysAhead != nil {
+		s.DaysAhead = *p.DaysAhead
+	}
+	if p.BookingsPerDay != nil {
+		s.BookingsPerDay = *p.BookingsPerDay
+	}
+	if p.GamesPerWeek != nil {
+		s.GamesPerWeek = *p.GamesPerWeek
+	}
+	return s
+}
+
+type CreateDemoSandboxPayload struct {
+	UserID int64 `json:"user_id" validate:"required,min=1"`
+	DemoSettingsPayload
+}
+
+// adminListDemoSandboxesHandler godoc
+//
+//	@Summary		List demo sandboxes
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}		demosandboxes.Sandbox
+//	@Failure		500	{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/demo-sandboxes [get]
+func (app *application) adminListDemoSandboxesHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := app.store.DemoSandboxes.List(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// adminCreateDemoSandboxHandler godoc
+//
+//	@Summary		Give a sales account a demo sandbox
+//	@Description	Creates a demo venue owned by the account, with weekly pricing and made-up bookings and private games from days_back days ago to days_ahead days on (defaults 14, 7, 6 bookings a day and 4 games a week). The venue stays on hold, so players never see it, and it is left out of settlements. Its data is regenerated every night, which undoes whatever was changed during a demo.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateDemoSandboxPayload	true	"Account and settings"
+//	@Success		201		{object}	demosandboxes.Sandbox
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		404		{object}	ErrorResponse	"User not found"
+//	@Failure		409		{object}	ErrorResponse	"The account already has a sandbox"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/demo-sandboxes [post]
+func (app *application) adminCreateDemoSandboxHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateDemoSandboxPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	settings := payload.apply(demosandboxes.DefaultSettings)
+	sb, err := app.store.DemoSandboxes.Create(r.Context(), payload.UserID, getUserFromContext(r).ID, settings)
+	if err != nil {
+		switch {
+		case errors.Is(err, demosandboxes.ErrUserNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, demosandboxes.ErrAlreadyHasDemo):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityDemoSandbox, audit.ActionCreate, sb.ID, nil, sb)
+	app.jsonResponse(w, http.StatusCreated, sb)
+}
+
+// adminUpdateDemoSandboxHandler godoc
+//
+//	@Summary		Change a demo sandbox's settings
+//	@Description	The new settings apply from the next regeneration; call reset to apply them now.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			sandboxID	path		int					true	"Sandbox ID"
+//	@Param			payload		body		DemoSettingsPayload	true	"Settings to change"
+//	@Success		200			{object}	demosandboxes.Sandbox
+//	@Failure		400			{object}	ErrorResponse	"Bad Request"
+//	@Failure		404			{object}	ErrorResponse	"Sandbox not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/demo-sandboxes/{sandboxID} [patch]
+func (app *application) adminUpdateDemoSandboxHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "sandboxID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid sandbox ID"))
+		return
+	}
+	var payload DemoSettingsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	before, err := app.store.DemoSandboxes.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, demosandboxes.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	after, err := app.store.DemoSandboxes.UpdateSettings(ctx, id, payload.apply(before.Settings))
+	if err != nil {
+		if errors.Is(err, demosandboxes.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityDemoSandbox, audit.ActionUpdate, id, before.Settings, after.Settings)
+	app.jsonResponse(w, http.StatusOK, after)
+}
+
+// adminResetDemoSandboxHandler godoc
+//
+//	@Summary		Regenerate a demo sandbox now
+//	@Description	Throws away the demo venue's bookings, games and day closes and writes a fresh set, as the nightly run does. Useful right before a demo.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			sandboxID	path		int	true	"Sandbox ID"
+//	@Success		200			{object}	demosandboxes.Regenerated
+//	@Failure		400			{object}	ErrorResponse	"Bad Request"
+//	@Failure		404			{object}	ErrorResponse	"Sandbox not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/demo-sandboxes/{sandboxID}/reset [post]
+func (app *application) adminResetDemoSandboxHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "sandboxID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid sandbox ID"))
+		return
+	}
+
+	res, err := app.store.DemoSandboxes.Regenerate(r.Context(), id, time.Now())
+	if err != nil {
+		if errors.Is(err, demosandboxes.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, res)
+}
+
+// adminDeleteDemoSandboxHandler godoc
+//
+//	@Summary		Delete a demo sandbox
+//	@Description	Deletes the demo venue with everything on it. The account keeps its owner role.
+//	@Tags			Admin
+//	@Param			sandboxID	path	int	true	"Sandbox ID"
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse	"Bad Request"
+//	@Failure		404	{object}	ErrorResponse	"Sandbox not found"
+//	@Failure		500	{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/demo-sandboxes/{sandboxID} [delete]
+func (app *application) adminDeleteDemoSandboxHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "sandboxID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid sandbox ID"))
+		return
+	}
+
+	if err := app.store.DemoSandboxes.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, demosandboxes.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityDemoSandbox, audit.ActionDelete, id, nil, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runRegenerateDemoSandboxes refreshes every sandbox not regenerated since
+// midnight in Nepal, so each starts the day with a clean, current set. One
+// failing sandbox doesn't hold up the others.
+func (app *application) runRegenerateDemoSandboxes(ctx context.Context) error {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	ids, err := app.store.DemoSandboxes.ListStale(ctx, midnight)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, id := range ids {
+		if _, err := app.store.DemoSandboxes.Regenerate(ctx, id, now); err != nil {
+			errs = append(errs, fmt.Errorf("sandbox %d: %w", id, err))
+		}
+	}
+	jobs.SetRowsAffected(ctx, int64(len(ids)-len(errs)))
+	return errors.Join(errs...)
+}
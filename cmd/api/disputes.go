@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/disputes"
+	"khel/internal/notifications"
+	"khel/internal/params"
+	"net/http"
+	"strings"
+)
+
+const maxDisputeEvidenceFiles = 5
+
+type OpenUserDisputePayload struct {
+	Reason      string `json:"reason" validate:"required,oneof=venue_closed other"`
+	Description string `json:"description" validate:"required,max=2000"`
+}
+
+type OpenVenueDisputePayload struct {
+	Reason      string `json:"reason" validate:"required,oneof=no_show other"`
+	Description string `json:"description" validate:"required,max=2000"`
+}
+
+type ResolveDisputePayload struct {
+	Resolution string `json:"resolution" validate:"required,oneof=refund_user penalize_user dismissed"`
+	// Amount in NPR; required for refund_user and penalize_user.
+	Amount *int    `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Note   *string `json:"note,omitempty" validate:"omitempty,max=2000"`
+}
+
+// openUserDisputeHandler godoc
+//
+//	@Summary		Dispute one of my bookings
+//	@Description	For when the venue was closed or the booking was not honoured. Allowed from the booking start until 7 days after it ends.
+//	@Tags			Disputes
+//	@Accept			json
+//	@Produce		json
+//	@Param			bookingID	path		int						true	"Booking ID"
+//	@Param			payload		body		OpenUserDisputePayload	true	"Dispute"
+//	@Success		201			{object}	disputes.Dispute
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Booking not found"
+//	@Failure		409			{object}	error	"Booking already has an open dispute"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/bookings/{bookingID}/dispute [post]
+func (app *application) openUserDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := readIDParam(r, "bookingID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid booking ID"))
+		return
+	}
+
+	var payload OpenUserDisputePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	app.openDispute(w, r, disputes.OpenInput{
+		BookingID:   bookingID,
+		OpenedBy:    user.ID,
+		OpenerRole:  disputes.RoleUser,
+		Reason:      payload.Reason,
+		Description: strings.TrimSpace(payload.Description),
+	})
+}
+
+// openVenueDisputeHandler godoc
+//
+//	@Summary		Dispute a booking at my venue
+//	@Description	For when the player did not show up. Allowed from the booking start until 7 days after it ends.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID		path		int						true	"Venue ID"
+//	@Param			bookingID	path		int						true	"Booking ID"
+//	@Param			payload		body		OpenVenueDisputePayload	true	"Dispute"
+//	@Success		201			{object}	disputes.Dispute
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Booking not found"
+//	@Failure		409			{object}	error	"Booking already has an open dispute"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/bookings/{bookingID}/dispute [post]
+func (app *application) openVenueDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	bookingID, err := readIDParam(r, "bookingID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid booking ID"))
+		return
+	}
+
+	var payload OpenVenueDisputePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	app.openDispute(w, r, disputes.OpenInput{
+		BookingID:   bookingID,
+		OpenedBy:    user.ID,
+		OpenerRole:  disputes.RoleVenue,
+		Reason:      payload.Reason,
+		Description: strings.TrimSpace(payload.Description),
+		VenueID:     venueID,
+	})
+}
+
+// listMyDisputesHandler godoc
+//
+//	@Summary		List disputes on my bookings
+//	@Description	Includes disputes the venue opened against me.
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			status	query		string			false	"open or resolved"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"disputes + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/disputes [get]
+func (app *application) listMyDisputesHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	app.listDisputes(w, r, disputes.Filter{UserID: &user.ID})
+}
+
+// listVenueDisputesHandler godoc
+//
+//	@Summary		List disputes on my venue's bookings
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			status	query		string			false	"open or resolved"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"disputes + pagination metadata"
+//	@Failure		400		{object}	error			"Bad Request"
+//	@Failure		403		{object}	error			"Forbidden"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/disputes [get]
+func (app *application) listVenueDisputesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	app.listDisputes(w, r, disputes.Filter{VenueID: &venueID})
+}
+
+// getDisputeHandler godoc
+//
+//	@Summary		Get a dispute
+//	@Description	Visible to the player and the venue owner, with all evidence.
+//	@Tags			Disputes
+//	@Produce		json
+//	@Param			disputeID	path		int	true	"Dispute ID"
+//	@Success		200			{object}	disputes.Dispute
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Dispute not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/disputes/{disputeID} [get]
+func (app *application) getDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	d, ok := app.loadDisputeForParty(w, r)
+	if !ok {
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, d)
+}
+
+// addDisputeEvidenceHandler godoc
+//
+//	@Summary		Add evidence to a dispute
+//	@Description	Either party may upload photos (max 5 per request) while the dispute is open.
+//	@Tags			Disputes
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			disputeID	path		int		true	"Dispute ID"
+//	@Param			files		formData	file	true	"Photos or screenshots"
+//	@Param			note		formData	string	false	"What the evidence shows"
+//	@Success		201			{array}		disputes.Evidence
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Dispute not found"
+//	@Failure		409			{object}	error	"Dispute is already resolved"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/disputes/{disputeID}/evidence [post]
+func (app *application) addDisputeEvidenceHandler(w http.ResponseWriter, r *http.Request) {
+	d, ok := app.loadDisputeForParty(w, r)
+	if !ok {
+		return
+	}
+	if d.Status != disputes.StatusOpen {
+		app.conflictResponse(w, r, disputes.ErrAlreadyResolved)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxFacilityImageMemory); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid multipart form: %w", err))
+		return
+	}
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		app.badRequestResponse(w, r, errors.New("at least one file is required"))
+		return
+	}
+	if len(files) > maxDisputeEvidenceFiles {
+		app.badRequestResponse(w, r, fmt.Errorf("a maximum of %d files can be uploaded at once", maxDisputeEvidenceFiles))
+		return
+	}
+	note := parseOptionalStringForm(r, "note")
+
+	user := getUserFromContext(r)
+	urls, err := app.uploadAttachments(files, "disputes", fmt.Sprintf("dispute_%d_user_%d", d.ID, user.ID))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	added := make([]disputes.Evidence, 0, len(urls))
+	for i, url := range urls {
+		e := &disputes.Evidence{
+			DisputeID:  d.ID,
+			UploadedBy: &user.ID,
+			URL:        url,
+			Note:       note,
+		}
+		if err := app.store.Disputes.AddEvidence(r.Context(), e); err != nil {
+			app.deleteImagesAsync(urls[i:])
+			if errors.Is(err, disputes.ErrAlreadyResolved) {
+				app.conflictResponse(w, r, err)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+		added = append(added, *e)
+	}
+
+	app.jsonResponse(w, http.StatusCreated, added)
+}
+
+// adminListDisputesHandler godoc
+//
+//	@Summary		List booking disputes
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status	query		string			false	"open or resolved"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"disputes + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/disputes [get]
+func (app *application) adminListDisputesHandler(w http.ResponseWriter, r *http.Request) {
+	app.listDisputes(w, r, disputes.Filter{})
+}
+
+// adminGetDisputeHandler godoc
+//
+//	@Summary		Get a booking dispute
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			disputeID	path		int	true	"Dispute ID"
+//	@Success		200			{object}	disputes.Dispute
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Dispute not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/disputes/{disputeID} [get]
+func (app *application) adminGetDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "disputeID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid dispute ID"))
+		return
+	}
+
+	d, err := app.store.Disputes.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, disputes.ErrDisputeNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, d)
+}
+
+// adminResolveDisputeHandler godoc
+//
+//	@Summary		Resolve a booking dispute
+//	@Description	refund_user records a refund owed by the venue and deducts it from the booking's final amount; penalize_user records a penalty owed by the player; dismissed changes nothing. Both parties are notified.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			disputeID	path		int						true	"Dispute ID"
+//	@Param			payload		body		ResolveDisputePayload	true	"Resolution"
+//	@Success		200			{object}	disputes.Dispute
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Dispute not found"
+//	@Failure		409			{object}	error	"Dispute is already resolved"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/disputes/{disputeID}/resolve [post]
+func (app *application) adminResolveDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "disputeID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid dispute ID"))
+		return
+	}
+
+	var payload ResolveDisputePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	switch {
+	case payload.Resolution == disputes.ResolutionDismissed && payload.Amount != nil:
+		app.badRequestResponse(w, r, errors.New("amount must be omitted when dismissing"))
+		return
+	case payload.Resolution != disputes.ResolutionDismissed && payload.Amount == nil:
+		app.badRequestResponse(w, r, errors.New("amount is required for refund_user and penalize_user"))
+		return
+	}
+
+	admin := getUserFromContext(r)
+	d, err := app.store.Disputes.Resolve(r.Context(), id, disputes.ResolveInput{
+		Resolution: payload.Resolution,
+		Amount:     payload.Amount,
+		Note:       payload.Note,
+		ResolvedBy: admin.ID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, disputes.ErrDisputeNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, disputes.ErrAlreadyResolved):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, disputes.ErrInvalidAmount):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityBookingDispute, audit.ActionResolve, d.ID, nil, d)
+
+	body := "The dispute was dismissed."
+	switch payload.Resolution {
+	case disputes.ResolutionRefundUser:
+		body = fmt.Sprintf("A refund of Rs %d was approved for the player.", *payload.Amount)
+	case disputes.ResolutionPenalizeUser:
+		body = fmt.Sprintf("A penalty of Rs %d was applied to the player.", *payload.Amount)
+	}
+	app.notifyDisputeParties(d, "Booking dispute resolved", body)
+
+	app.jsonResponse(w, http.StatusOK, d)
+}
+
+func (app *application) openDispute(w http.ResponseWriter, r *http.Request, in disputes.OpenInput) {
+	d, err := app.store.Disputes.Open(r.Context(), in)
+	if err != nil {
+		switch {
+		case errors.Is(err, disputes.ErrBookingNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, disputes.ErrBookingNotDisputable):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, disputes.ErrDisputeExists):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Tell the other side.
+	other := d.VenueOwnerID
+	if in.OpenerRole == disputes.RoleVenue {
+		other = d.UserID
+	}
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendDisputeUpdate(ctx, app.push, app.store, []int64{other}, d.ID, d.BookingID,
+			"Booking disputed", "A dispute was opened on your booking. Add your evidence in the app.")
+	}, "booking dispute opened push")
+
+	app.jsonResponse(w, http.StatusCreated, d)
+}
+
+func (app *application) notifyDisputeParties(d *disputes.Dispute, title, body string) {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendDisputeUpdate(ctx, app.push, app.store, []int64{d.UserID, d.VenueOwnerID}, d.ID, d.BookingID, title, body)
+	}, "booking dispute resolved push")
+}
+
+func (app *application) listDisputes(w http.ResponseWriter, r *http.Request, f disputes.Filter) {
+	q := r.URL.Query()
+	f.Status = strings.TrimSpace(q.Get("status"))
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Disputes.List(r.Context(), f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"disputes":   list,
+		"pagination": pagination,
+	})
+}
+
+// loadDisputeForParty fetches the dispute in the URL for the player or the
+// venue owner. Anyone else gets a 404.
+func (app *application) loadDisputeForParty(w http.ResponseWriter, r *http.Request) (*disputes.Dispute, bool) {
+	id, err := readIDParam(r, "disputeID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid dispute ID"))
+		return nil, false
+	}
+
+	d, err := app.store.Disputes.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, disputes.ErrDisputeNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	user := getUserFromContext(r)
+	if !d.IsParty(user.ID) {
+		app.notFoundResponse(w, r, disputes.ErrDisputeNotFound)
+		return nil, false
+	}
+	return d, true
+}
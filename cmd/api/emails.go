@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/guests"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/mailer"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// userLocale returns the email language the user picked, or the default
+// when it can't be read.
+func (app *application) userLocale(ctx context.Context, userID int64) string {
+	prefs, err := app.store.NotificationPrefs.Get(ctx, userID)
+	if err != nil {
+		app.logger.Warnw("failed to load user locale", "user_id", userID, "error", err)
+		return mailer.DefaultLocale
+	}
+	return prefs.Locale
+}
+
+// emailBookingDecision emails the player that their booking was accepted or
+// rejected, unless they turned off booking emails. Best effort; the booking
+// is already updated and the push goes out separately.
+func (app *application) emailBookingDecision(b *bookings.Booking, template string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prefs, err := app.store.NotificationPrefs.Get(ctx, b.UserID)
+	if err != nil {
+		app.logger.Errorw("failed to load notification preferences for booking email", "booking_id", b.ID, "error", err)
+		return
+	}
+	if !prefs.Allows(notificationprefs.CategoryBookingUpdates, notificationprefs.ChannelEmail) {
+		return
+	}
+
+	user, err := app.store.Users.GetByID(ctx, b.UserID)
+	if err != nil {
+		app.logger.Errorw("failed to load user for booking email", "booking_id", b.ID, "error", err)
+		return
+	}
+	venue, err := app.store.Venues.GetVenueByID(ctx, b.VenueID)
+	if err != nil {
+		app.logger.Errorw("failed to load venue for booking email", "booking_id", b.ID, "error", err)
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		loc = time.FixedZone("NPT", 5*60*60+45*60)
+	}
+	start, end := b.StartTime.In(loc), b.EndTime.In(loc)
+
+	if guests.IsGuestEmail(user.Email) {
+		app.textGuestBookingDecision(ctx, user.Phone, venue.Name, start.Format("Mon, 2 Jan 3:04 PM"), template)
+		return
+	}
+
+	data := struct {
+		Username  string
+		BookingID string
+		VenueName string
+		Date      string
+		StartTime string
+		EndTime   string
+		Price     int
+		// Branding of the venue's organization; empty for independent venues.
+		BrandName  string
+		BrandColor string
+		LogoURL    string
+	}{
+		Username:  user.FirstName,
+		BookingID: app.EncodeBookingID(b.ID),
+		VenueName: venue.Name,
+		Date:      start.Format("Mon, 2 Jan 2006"),
+		StartTime: start.Format("3:04 PM"),
+		EndTime:   end.Format("3:04 PM"),
+		Price:     b.TotalPrice,
+	}
+	if br := venue.Branding; br != nil {
+		data.BrandName = br.DisplayName
+		if br.Color != nil {
+			data.BrandColor = *br.Color
+		}
+		if br.LogoURL != nil {
+			data.LogoURL = *br.LogoURL
+		}
+	}
+	if err := app.enqueueEmail(ctx, template, prefs.Locale, user.FirstName, user.Email, data); err != nil {
+		app.logger.Errorw("failed to enqueue booking email", "booking_id", b.ID, "template", template, "error", err)
+	}
+}
+
+// listEmailPreviewsHandler lists the templates the preview endpoint can
+// render. Only mounted outside production.
+func (app *application) listEmailPreviewsHandler(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(mailer.PreviewData))
+	for name := range mailer.PreviewData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"templates": names,
+		"locales":   []string{mailer.LocaleEnglish, mailer.LocaleNepali},
+	})
+}
+
+// previewEmailHandler renders a template with sample data as a browser
+// page; ?locale=ne switches the subject line, which is also returned in the
+// X-Email-Subject header. Only mounted outside production.
+func (app *application) previewEmailHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "template")
+	data, ok := mailer.PreviewData[name]
+	if !ok {
+		app.notFoundResponse(w, r, fmt.Errorf("unknown email template %q", name))
+		return
+	}
+
+	subject, body, err := mailer.Render(name, r.URL.Query().Get("locale"), data)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Email-Subject", subject)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body))
+}
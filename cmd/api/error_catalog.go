@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"khel/internal/domain/facilities"
+	"khel/internal/domain/friends"
+	"khel/internal/domain/games"
+	"khel/internal/domain/guests"
+	"khel/internal/domain/inventory"
+	"khel/internal/domain/orders"
+	"khel/internal/domain/paymentsplits"
+	"khel/internal/domain/pricealerts"
+	"khel/internal/domain/refunds"
+	"khel/internal/domain/savedsearches"
+	"khel/internal/domain/slotalerts"
+	"khel/internal/domain/users"
+	"khel/internal/domain/venuedaycloses"
+	venuereviews "khel/internal/domain/venuereview"
+	"khel/internal/domain/venues"
+)
+
+// errorCode is the machine-readable part of an error response. Clients
+// branch on the code; the message is for people and may change wording.
+type errorCode string
+
+// Generic codes, one per helper in errors.go.
+const (
+	codeBadRequest       errorCode = "BAD_REQUEST"
+	codeValidationFailed errorCode = "VALIDATION_FAILED"
+	codeUnauthorized     errorCode = "UNAUTHORIZED"
+	codeForbidden        errorCode = "FORBIDDEN"
+	codeNotFound         errorCode = "NOT_FOUND"
+	codeMethodNotAllowed errorCode = "METHOD_NOT_ALLOWED"
+	codeConflict         errorCode = "CONFLICT"
+	codeRateLimited      errorCode = "RATE_LIMITED"
+	codeInternal         errorCode = "INTERNAL_ERROR"
+)
+
+// Specific codes for errors clients are expected to handle on their own.
+const (
+	codeBookingConflict   errorCode = "BOOKING_CONFLICT"    // the slot overlaps a pending or confirmed booking
+	codeSlotOutOfPricing  errorCode = "SLOT_OUT_OF_PRICING" // the slot falls outside the venue's priced hours
+	codeVenueNotFound     errorCode = "VENUE_NOT_FOUND"
+	codeFacilityNotFound  errorCode = "FACILITY_NOT_FOUND"
+	codeGameFull          errorCode = "GAME_FULL"
+	codeAlreadyInGame     errorCode = "ALREADY_IN_GAME"
+	codeNotInGame         errorCode = "NOT_IN_GAME"
+	codeEmailTaken        errorCode = "EMAIL_TAKEN"
+	codePhoneTaken        errorCode = "PHONE_TAKEN"
+	codeLimitReached      errorCode = "LIMIT_REACHED" // per-user caps on alerts, saved searches, inventory and guest bookings
+	codeAlreadyPaid       errorCode = "ALREADY_PAID"
+	codeAlreadyRequested  errorCode = "ALREADY_REQUESTED"
+	codeWindowClosed      errorCode = "WINDOW_CLOSED" // rating, review, cancellation or deposit window has passed
+	codeAccountDeleting   errorCode = "ACCOUNT_DELETION_PENDING"
+	codeAlreadyFriends    errorCode = "ALREADY_FRIENDS"
+	codeFriendRequestSent errorCode = "FRIEND_REQUEST_EXISTS"
+	codeDayClosed         errorCode = "DAY_CLOSED" // the booking's business day was closed by the venue
+	codeOTPInvalid        errorCode = "OTP_INVALID"
+)
+
+// Errors raised in the handlers themselves that have a catalog entry.
+var (
+	errSlotTaken        = errors.New("time slot is already booked")
+	errSlotOutOfPricing = errors.New("requested time is outside the venue's pricing hours")
+)
+
+// errorCatalog gives domain errors their own code. Entries are matched with
+// errors.Is in order, so wrapped errors are found too.
+var errorCatalog = []struct {
+	err  error
+	code errorCode
+}{
+	{errSlotTaken, codeBookingConflict},
+	{errSlotOutOfPricing, codeSlotOutOfPricing},
+	{venues.ErrVenueNotFound, codeVenueNotFound},
+	{slotalerts.ErrVenueNotFound, codeVenueNotFound},
+	{pricealerts.ErrVenueNotFound, codeVenueNotFound},
+	{facilities.ErrFacilityNotFound, codeFacilityNotFound},
+	{slotalerts.ErrFacilityNotFound, codeFacilityNotFound},
+	{games.ErrGameFull, codeGameFull},
+	{games.ErrAlreadyInGame, codeAlreadyInGame},
+	{games.ErrNotInGame, codeNotInGame},
+	{games.ErrRatingWindowClosed, codeWindowClosed},
+	{venuereviews.ErrReviewCooldown, codeWindowClosed},
+	{orders.ErrCancelWindowClosed, codeWindowClosed},
+	{users.ErrDuplicateEmail, codeEmailTaken},
+	{games.ErrDuplicateEmail, codeEmailTaken},
+	{users.ErrDuplicatePhoneNumber, codePhoneTaken},
+	{games.ErrDuplicatePhoneNumber, codePhoneTaken},
+	{guests.ErrPhoneRegistered, codePhoneTaken},
+	{guests.ErrOTPInvalid, codeOTPInvalid},
+	{guests.ErrOpenBooking, codeLimitReached},
+	{users.ErrDeletionPending, codeAccountDeleting},
+	{slotalerts.ErrLimitExceeded, codeLimitReached},
+	{pricealerts.ErrLimitExceeded, codeLimitReached},
+	{savedsearches.ErrLimitExceeded, codeLimitReached},
+	{inventory.ErrInventoryLimitReached, codeLimitReached},
+	{paymentsplits.ErrAlreadyPaid, codeAlreadyPaid},
+	{guests.ErrDepositPaid, codeAlreadyPaid},
+	{guests.ErrDepositNotActive, codeWindowClosed},
+	{refunds.ErrAlreadyRequested, codeAlreadyRequested},
+	{friends.ErrAlreadyFriends, codeAlreadyFriends},
+	{friends.ErrRequestExists, codeFriendRequestSent},
+	{venuedaycloses.ErrDayClosed, codeDayClosed},
+	{venuedaycloses.ErrAlreadyClosed, codeDayClosed},
+}
+
+// codeFor returns the catalog code for err, or fallback when it has none.
+func codeFor(err error, fallback errorCode) errorCode {
+	for _, e := range errorCatalog {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return fallback
+}
@@ -1,56 +1,101 @@
 package main
 
 import (
+	"errors"
+	"khel/internal/domain/venuedaycloses"
+	"khel/internal/params"
 	"net/http"
+
+	"github.com/go-playground/validator/v10"
 )
 
+// fieldError is one failed rule in a VALIDATION_FAILED response.
+type fieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Errorw("internal error", "method", r.Method, "path", r.URL.Path,
+	// Any booking write can hit the closed-day trigger; it is the caller's
+	// problem, not the server's.
+	if venuedaycloses.IsClosedDayWrite(err) {
+		app.conflictResponse(w, r, venuedaycloses.ErrDayClosed)
+		return
+	}
+	app.requestLogger(r).Errorw("internal error", "method", r.Method, "path", r.URL.Path,
 		"error", err.Error())
-	writeJSONError(w, http.StatusInternalServerError, "the server encountered a problem")
+	writeJSONError(w, r, http.StatusInternalServerError, codeInternal, "the server encountered a problem", nil)
 }
 
 func (app *application) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("bad request", "method", r.Method, "path", r.URL.Path,
+	app.requestLogger(r).Warnw("bad request", "method", r.Method, "path", r.URL.Path,
 		"error", err.Error())
-	writeJSONError(w, http.StatusBadRequest, err.Error())
+
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		details := make([]fieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			details = append(details, fieldError{Field: fe.Field(), Rule: fe.Tag(), Param: fe.Param()})
+		}
+		writeJSONError(w, r, http.StatusBadRequest, codeValidationFailed, err.Error(), details)
+		return
+	}
+	var berr *params.BindError
+	if errors.As(err, &berr) {
+		details := []fieldError{{Field: berr.Param, Rule: "type", Param: berr.Want}}
+		writeJSONError(w, r, http.StatusBadRequest, codeValidationFailed, err.Error(), details)
+		return
+	}
+	writeJSONError(w, r, http.StatusBadRequest, codeFor(err, codeBadRequest), err.Error(), nil)
 }
 
 func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("not found error", "method", r.Method, "path", r.URL.Path,
+	app.requestLogger(r).Warnw("not found error", "method", r.Method, "path", r.URL.Path,
 		"error", err.Error())
-	writeJSONError(w, http.StatusNotFound, "not found")
+	writeJSONError(w, r, http.StatusNotFound, codeFor(err, codeNotFound), "not found", nil)
 }
 
 func (app *application) conflictResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Errorf("conflict response", "method", r.Method, "path", r.URL.Path,
+	app.requestLogger(r).Errorw("conflict response", "method", r.Method, "path", r.URL.Path,
 		"error", err.Error())
-	writeJSONError(w, http.StatusConflict, err.Error())
+	writeJSONError(w, r, http.StatusConflict, codeFor(err, codeConflict), err.Error(), nil)
 }
 
 func (app *application) unauthorizedErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("unauthorized error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	writeJSONError(w, http.StatusUnauthorized, err.Error())
+	app.requestLogger(r).Warnw("unauthorized error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	writeJSONError(w, r, http.StatusUnauthorized, codeFor(err, codeUnauthorized), err.Error(), nil)
 }
 
 func (app *application) unauthorizedBasicErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("unauthorized basic error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.requestLogger(r).Warnw("unauthorized basic error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
 
 	//check WWW-Authenticate docs in mdn web docs
 	w.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
-	writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+	writeJSONError(w, r, http.StatusUnauthorized, codeUnauthorized, "unauthorized", nil)
 }
 
 func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request) {
-	app.logger.Warnw("forbidden", "method", r.Method, "path", r.URL.Path, "error")
+	app.requestLogger(r).Warnw("forbidden", "method", r.Method, "path", r.URL.Path)
 
-	writeJSONError(w, http.StatusForbidden, "forbidden")
+	writeJSONError(w, r, http.StatusForbidden, codeForbidden, "forbidden", nil)
 }
 
 func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter string) {
-	app.logger.Warnw("rate limit exceeded", "method", r.Method, "path", r.URL.Path)
+	app.requestLogger(r).Warnw("rate limit exceeded", "method", r.Method, "path", r.URL.Path)
 
 	w.Header().Set("Retry-After", retryAfter)
 
-	writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded, retry after: "+retryAfter)
+	writeJSONError(w, r, http.StatusTooManyRequests, codeRateLimited, "rate limit exceeded, retry after: "+retryAfter,
+		map[string]string{"retry_after": retryAfter})
+}
+
+// routeNotFoundResponse and methodNotAllowedResponse replace chi's plain-text
+// defaults so unknown routes answer with the same envelope.
+func (app *application) routeNotFoundResponse(w http.ResponseWriter, r *http.Request) {
+	writeJSONError(w, r, http.StatusNotFound, codeNotFound, "not found", nil)
+}
+
+func (app *application) methodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
+	writeJSONError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, r.Method+" is not allowed here", nil)
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Cache-Control values for the routes wrapped in etagMiddleware. Clients
+// revalidate with If-None-Match once max-age runs out, so a short max-age
+// still saves the download when nothing changed.
+const (
+	cacheCatalog = "public, max-age=60"
+	cacheVenue   = "public, max-age=30"
+)
+
+// etagMiddleware buffers successful GET responses, tags them with an ETag
+// hashed from the body and answers 304 Not Modified when the client already
+// has that version. cacheControl is sent with both; pass "" to leave the
+// handler's own Cache-Control alone.
+func (app *application) etagMiddleware(cacheControl string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(bw, r)
+
+			if bw.status != http.StatusOK {
+				w.WriteHeader(bw.status)
+				_, _ = w.Write(bw.buf.Bytes())
+				return
+			}
+
+			sum := sha256.Sum256(bw.buf.Bytes())
+			etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+			h := w.Header()
+			h.Set("ETag", etag)
+			if cacheControl != "" {
+				h.Set("Cache-Control", cacheControl)
+			}
+
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				h.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(bw.buf.Bytes())
+		})
+	}
+}
+
+// etagMatches reports whether an If-None-Match header names etag. The
+// comparison is weak, as RFC 9110 asks for If-None-Match, so a W/ prefix
+// added by a proxy still matches.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponseWriter holds a response back so it can be hashed before
+// anything reaches the client. Headers go straight to the real writer's
+// map; only the status and body wait.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+}
+
+func (bw *bufferedResponseWriter) WriteHeader(status int) {
+	if bw.wroteHeader {
+		return
+	}
+	bw.status = status
+	bw.wroteHeader = true
+}
+
+func (bw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	bw.wroteHeader = true
+	return bw.buf.Write(b)
+}
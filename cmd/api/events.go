@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"khel/internal/events"
+	"khel/internal/notifications"
+)
+
+// registerEventSubscribers wires event handlers onto the bus. Each
+// subscriber runs as its own job kind, so it retries independently.
+func (app *application) registerEventSubscribers() {
+	app.events.Subscribe(events.GameCompleted, "summary_push", func(ctx context.Context, raw json.RawMessage) error {
+		var p events.GameCompletedPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return notifications.SendGameSummary(ctx, app.push, app.store, p.GameID, p.SportType, p.RatingClosesAt)
+	})
+
+	app.events.Subscribe(events.BookingReleased, "slot_alerts", func(ctx context.Context, raw json.RawMessage) error {
+		var p events.BookingReleasedPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runMatchSlotAlerts(ctx, p)
+	})
+}
@@ -58,8 +58,11 @@ type CreateFacilityPayload struct {
 	Sport       *string  `json:"sport,omitempty" validate:"omitempty,max=50"`
 	SurfaceType *string  `json:"surface_type,omitempty" validate:"omitempty,max=80"`
 	Capacity    *int     `json:"capacity,omitempty" validate:"omitempty,gt=0"`
+	IsCovered   bool     `json:"is_covered"`
 	ImageURLs   []string `json:"image_urls,omitempty"`
 	IsDefault   bool     `json:"is_default"`
+
+	PriceAdjustmentPercent *int `json:"price_adjustment_percent,omitempty" validate:"omitempty,gte=-90,lte=300"`
 }
 
 type UpdateFacilityPayload struct {
@@ -68,8 +71,11 @@ type UpdateFacilityPayload struct {
 	Sport       *string `json:"sport,omitempty" validate:"omitempty,max=50"`
 	SurfaceType *string `json:"surface_type,omitempty" validate:"omitempty,max=80"`
 	Capacity    *int    `json:"capacity,omitempty" validate:"omitempty,gt=0"`
+	IsCovered   *bool   `json:"is_covered,omitempty"`
 	IsActive    *bool   `json:"is_active,omitempty"`
 	IsDefault   *bool   `json:"is_default,omitempty"`
+
+	PriceAdjustmentPercent *int `json:"price_adjustment_percent,omitempty" validate:"omitempty,gte=-90,lte=300"`
 }
 
 // createFacilityHandler godoc
@@ -87,6 +93,8 @@ type UpdateFacilityPayload struct {
 //	@Param			sport			formData	string				false	"Sport type. Example: football, futsal, cricket"
 //	@Param			surface_type	formData	string				false	"Surface type. Example: turf, grass, concrete"
 //	@Param			capacity		formData	int					false	"Maximum player/person capacity"
+//	@Param			is_covered		formData	bool				false	"Whether the facility is covered (indoor or roofed)"
+//	@Param			price_adjustment_percent	formData	int	false	"Reuse the default facility's pricing on days without own pricing, adjusted by this percent (-90 to 300)"
 //	@Param			is_default		formData	bool				false	"Whether this facility is the default facility for the venue"
 //	@Param			image_action	formData	string				false	"Image behavior when no image file is uploaded. Allowed values: default, skip. Empty also uses venue default images."
 //	@Param			images			formData	file				false	"Facility image files. Can be sent multiple times with the same field name: images"
@@ -129,13 +137,27 @@ func (app *application) createFacilityHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	isCovered, err := parseBoolFormDefault(r, "is_covered", false)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	priceAdjustment, err := parseOptionalIntForm(r, "price_adjustment_percent")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	payload := CreateFacilityPayload{
-		Name:        name,
-		Description: parseOptionalStringForm(r, "description"),
-		Sport:       parseOptionalStringForm(r, "sport"),
-		SurfaceType: parseOptionalStringForm(r, "surface_type"),
-		Capacity:    capacity,
-		IsDefault:   isDefault,
+		Name:                   name,
+		Description:            parseOptionalStringForm(r, "description"),
+		Sport:                  parseOptionalStringForm(r, "sport"),
+		SurfaceType:            parseOptionalStringForm(r, "surface_type"),
+		Capacity:               capacity,
+		IsCovered:              isCovered,
+		IsDefault:              isDefault,
+		PriceAdjustmentPercent: priceAdjustment,
 	}
 
 	if err := Validate.Struct(payload); err != nil {
@@ -183,6 +205,9 @@ func (app *application) createFacilityHandler(w http.ResponseWriter, r *http.Req
 		Capacity:    payload.Capacity,
 		ImageURLs:   imageURLs,
 
+		IsCovered:              payload.IsCovered,
+		PriceAdjustmentPercent: payload.PriceAdjustmentPercent,
+
 		// Always create as non-default first.
 		// If the client requested default, we call SetDefault after creation.
 		IsDefault: false,
@@ -293,6 +318,8 @@ func (app *application) getFacilityHandler(w http.ResponseWriter, r *http.Reques
 //	@Param			sport			formData	string				false	"Sport type. Example: football, futsal, cricket"
 //	@Param			surface_type	formData	string				false	"Surface type. Example: turf, grass, concrete"
 //	@Param			capacity		formData	int					false	"Maximum player/person capacity"
+//	@Param			is_covered		formData	bool				false	"Whether the facility is covered (indoor or roofed)"
+//	@Param			price_adjustment_percent	formData	string	false	"Reuse the default facility's pricing on days without own pricing, adjusted by this percent (-90 to 300). Send none to stop."
 //	@Param			is_active		formData	bool				false	"Whether this facility is active"
 //	@Param			is_default		formData	bool				false	"Set to true to make this facility the venue default. False is not allowed."
 //	@Success		200				{object}	facilities.Facility	"Facility updated successfully"
@@ -322,6 +349,23 @@ func (app *application) updateFacilityHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	isCovered, err := parseOptionalBoolForm(r, "is_covered")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// "none" stops the facility from reusing the default facility's pricing.
+	clearPriceAdjustment := strings.EqualFold(strings.TrimSpace(r.FormValue("price_adjustment_percent")), "none")
+	var priceAdjustment *int
+	if !clearPriceAdjustment {
+		priceAdjustment, err = parseOptionalIntForm(r, "price_adjustment_percent")
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
 	isDefault, err := parseOptionalBoolForm(r, "is_default")
 	if err != nil {
 		app.badRequestResponse(w, r, err)
@@ -348,8 +392,11 @@ func (app *application) updateFacilityHandler(w http.ResponseWriter, r *http.Req
 		Sport:       parseOptionalStringForm(r, "sport"),
 		SurfaceType: parseOptionalStringForm(r, "surface_type"),
 		Capacity:    capacity,
+		IsCovered:   isCovered,
 		IsActive:    isActive,
 		IsDefault:   isDefault,
+
+		PriceAdjustmentPercent: priceAdjustment,
 	}
 
 	if err := Validate.Struct(payload); err != nil {
@@ -367,9 +414,13 @@ func (app *application) updateFacilityHandler(w http.ResponseWriter, r *http.Req
 		Sport:       payload.Sport,
 		SurfaceType: payload.SurfaceType,
 		Capacity:    payload.Capacity,
+		IsCovered:   payload.IsCovered,
 		IsActive:    payload.IsActive,
 		IsDefault:   nil,
 
+		PriceAdjustmentPercent: payload.PriceAdjustmentPercent,
+		ClearPriceAdjustment:   clearPriceAdjustment,
+
 		// ImageURLs are intentionally not updated here.
 		// Facility photos have dedicated CRUD endpoints so we can safely handle
 		// shared venue images without accidentally deleting them from Cloudinary.
@@ -486,7 +537,7 @@ func (app *application) deleteFacilityHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	app.deleteCloudinaryImagesAsync(safeToDeleteURLs)
+	app.deleteImagesAsync(safeToDeleteURLs)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -537,7 +588,7 @@ func (app *application) uploadFacilityImagesToCloudinary(
 			time.Now().UnixNano(),
 		)
 
-		url, err := app.uploadToCloudinaryWithID(file, publicID, folder)
+		url, err := app.uploadFileWithID(file, publicID, folder)
 
 		closeErr := file.Close()
 		if closeErr != nil && err == nil {
@@ -554,32 +605,13 @@ func (app *application) uploadFacilityImagesToCloudinary(
 	return urls, nil
 }
 
-// deleteCloudinaryImagesAsync deletes images from Cloudinary in a goroutine.
+// deleteImagesAsync queues file deletes on the job queue.
 // This is used after facility update/delete so the API response does not block
-// on external Cloudinary deletion.
-//
-// Important:
-// Do not write HTTP responses from inside this goroutine. Only log failures.
-func (app *application) deleteCloudinaryImagesAsync(urls []string) {
-	if len(urls) == 0 {
-		return
+// on the storage provider, and failed deletes are retried.
+func (app *application) deleteImagesAsync(urls []string) {
+	for _, url := range urls {
+		app.enqueuePhotoDelete(url)
 	}
-
-	go func(urls []string) {
-		for _, url := range urls {
-			if strings.TrimSpace(url) == "" {
-				continue
-			}
-
-			if err := app.deletePhotoFromCloudinary(url); err != nil {
-				app.logger.Warnw(
-					"failed to delete image from Cloudinary",
-					"url", url,
-					"err", err,
-				)
-			}
-		}
-	}(urls)
 }
 
 const maxFacilityImageMemory = 20 << 20 // 20MB
@@ -84,7 +84,7 @@ func parseBookingDateFromQuery(r *http.Request) (time.Time, error) {
 // getPendingFacilityBookingsHandler godoc
 //
 //	@Summary		Get pending bookings for a facility
-//	@Description	Returns pending booking requests for one facility under a venue. This is the facility-level replacement for venue-level pending bookings.
+//	@Description	Returns pending booking requests for one facility under a venue. This is the facility-level replacement for venue-level pending bookings. Phone numbers are masked; use reveal-contact for the full number.
 //	@Tags			Facility Bookings
 //	@Accept			json
 //	@Produce		json
@@ -144,7 +144,7 @@ func (app *application) getPendingFacilityBookingsHandler(w http.ResponseWriter,
 // getScheduledFacilityBookingsHandler godoc
 //
 //	@Summary		Get scheduled bookings for a facility
-//	@Description	Returns confirmed/scheduled bookings for one facility under a venue. This lets owners manage each ground/court separately.
+//	@Description	Returns confirmed/scheduled bookings for one facility under a venue. This lets owners manage each ground/court separately. Phone numbers are masked; use reveal-contact for the full number.
 //	@Tags			Facility Bookings
 //	@Accept			json
 //	@Produce		json
@@ -203,7 +203,7 @@ func (app *application) getScheduledFacilityBookingsHandler(w http.ResponseWrite
 // getCanceledFacilityBookingsHandler godoc
 //
 //	@Summary		Get canceled bookings for a facility
-//	@Description	Returns canceled bookings for one facility under a venue. This keeps canceled bookings separated per facility.
+//	@Description	Returns canceled bookings for one facility under a venue. This keeps canceled bookings separated per facility. Phone numbers are masked; use reveal-contact for the full number.
 //	@Tags			Facility Bookings
 //	@Accept			json
 //	@Produce		json
@@ -384,7 +384,7 @@ func (app *application) availableFacilityTimesHandler(w http.ResponseWriter, r *
 // bookFacilityHandler godoc
 //
 //	@Summary		Request booking for a facility
-//	@Description	Creates a pending booking request for a specific facility under a venue. Availability and pricing are checked at facility level.
+//	@Description	Creates a pending booking request for a specific facility under a venue. Availability and pricing are checked at facility level, and the venue's booking rules limit the duration and how far ahead it can be. Venues with auto_confirm confirm it straight away.
 //	@Tags			Facility Bookings
 //	@Accept			json
 //	@Produce		json
@@ -439,6 +439,16 @@ func (app *application) bookFacilityHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	rules, err := app.store.BookingRules.Get(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := rules.CheckBooking(payload.StartTime, payload.EndTime, time.Now()); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	totalPrice, err := app.calculateFacilityBookingPrice(
 		r,
 		venueID,
@@ -462,6 +472,12 @@ func (app *application) bookFacilityHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	status, err := app.bookingStatusFor(r.Context(), rules, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	booking := &bookings.Booking{
 		VenueID:    venueID,
 		FacilityID: facilityID,
@@ -471,15 +487,22 @@ func (app *application) bookFacilityHandler(w http.ResponseWriter, r *http.Reque
 		EndTime:    payload.EndTime,
 		TotalPrice: totalPrice,
 
-		// Normal user booking starts as pending.
-		// Venue owner can later accept/reject it.
-		Status: "pending",
+		// Normal user booking starts as pending and the venue owner later
+		// accepts or rejects it, unless the venue auto-confirms.
+		Status: status,
 	}
 
 	if _, err := app.store.Bookings.CreateBooking(r.Context(), booking); err != nil {
+		if isConfirmedSlotClash(err) {
+			app.conflictResponse(w, r, errSlotTaken)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
+	if booking.Status == "confirmed" {
+		app.notifyBookingAutoConfirmed(booking)
+	}
 
 	// Use your existing response mapper if you already have it.
 	app.jsonResponse(w, http.StatusCreated, FacilityBookingResponse{
@@ -692,13 +715,12 @@ func (app *application) buildHourlyAvailableTimesForFacility(
 	}
 
 	localDate := date.In(loc)
-	dayOfWeek := strings.ToLower(localDate.Weekday().String())
 
-	pricingSlots, err := app.store.Bookings.GetPricingSlots(
+	pricingSlots, err := app.store.Bookings.GetPricingForDate(
 		r.Context(),
 		venueID,
 		facilityID,
-		dayOfWeek,
+		localDate,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("get pricing slots: %w", err)
@@ -853,18 +875,18 @@ func (app *application) calculateFacilityBookingPrice(
 	localStart := startTime.In(loc)
 	dayOfWeek := strings.ToLower(localStart.Weekday().String())
 
-	pricingSlots, err := app.store.Bookings.GetPricingSlots(
+	pricingSlots, err := app.store.Bookings.GetPricingForDate(
 		r.Context(),
 		venueID,
 		facilityID,
-		dayOfWeek,
+		localStart,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("get pricing slots: %w", err)
 	}
 
 	if len(pricingSlots) == 0 {
-		return 0, fmt.Errorf("no pricing available for this facility on %s", dayOfWeek)
+		return 0, fmt.Errorf("no pricing available for this facility on %s: %w", dayOfWeek, errSlotOutOfPricing)
 	}
 
 	total := 0
@@ -889,7 +911,7 @@ func (app *application) calculateFacilityBookingPrice(
 		}
 
 		if matchedSlot == nil {
-			return 0, fmt.Errorf("requested time is outside facility pricing hours")
+			return 0, errSlotOutOfPricing
 		}
 
 		priceUntil := matchedSlotEnd
@@ -945,7 +967,7 @@ func (app *application) ensureFacilityTimeIsAvailable(
 
 	for _, existing := range existingBookings {
 		if intervalsOverlap(requested, existing) {
-			return fmt.Errorf("time slot is already booked")
+			return errSlotTaken
 		}
 	}
 
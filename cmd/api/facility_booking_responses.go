@@ -61,7 +61,7 @@ func (app *application) pendingBookingToResponse(b bookings.PendingBooking) Pend
 		UserID:       b.UserID,
 		UserName:     b.UserName,
 		UserImageURL: b.UserImageURL,
-		UserPhone:    b.UserPhone,
+		UserPhone:    maskPhone(b.UserPhone),
 		Price:        b.Price,
 		RequestedAt:  b.RequestedAt,
 		StartTime:    b.StartTime,
@@ -101,7 +101,7 @@ func (app *application) canceledBookingToResponse(b bookings.CanceledBooking) Ca
 		UserID:       b.UserID,
 		UserName:     b.UserName,
 		UserImageURL: b.UserImageURL,
-		UserPhone:    b.UserPhone,
+		UserPhone:    maskPhone(b.UserPhone),
 		Price:        b.Price,
 		RequestedAt:  b.RequestedAt,
 		StartTime:    b.StartTime,
@@ -144,13 +144,13 @@ func (app *application) scheduledBookingToResponse(b bookings.ScheduledBooking)
 		UserID:        b.UserID,
 		UserName:      b.UserName,
 		UserImageURL:  b.UserImageURL,
-		UserPhone:     b.UserPhone,
+		UserPhone:     maskPhone(b.UserPhone),
 		Price:         b.Price,
 		AcceptedAt:    b.AcceptedAt,
 		StartTime:     b.StartTime,
 		EndTime:       b.EndTime,
 		CustomerName:  b.CustomerName,
-		CustomerPhone: b.CustomerPhone,
+		CustomerPhone: maskOptionalPhone(b.CustomerPhone),
 		Note:          b.Note,
 	}
 }
@@ -181,7 +181,7 @@ func (app *application) uploadFacilityPhotoHandler(w http.ResponseWriter, r *htt
 		time.Now().UnixNano(),
 	)
 
-	newPhotoURL, err := app.uploadToCloudinaryWithID(file, publicID, folder)
+	newPhotoURL, err := app.uploadFileWithID(file, publicID, folder)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -268,10 +268,7 @@ func (app *application) deleteFacilityPhotoHandler(w http.ResponseWriter, r *htt
 	// If the photo is one of the venue's own images, do NOT delete it from Cloudinary.
 	// Just removing it from facility.image_urls is enough.
 	if !containsURL(venueURLs, photoURL) {
-		if err := app.deletePhotoFromCloudinary(photoURL); err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
+		app.enqueuePhotoDelete(photoURL)
 	}
 
 	app.jsonResponse(w, http.StatusOK, map[string]string{
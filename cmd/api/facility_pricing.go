@@ -122,6 +122,7 @@ func (app *application) createFacilityPricingHandler(w http.ResponseWriter, r *h
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.enqueuePriceAlertCheck(venueID)
 
 	app.jsonResponse(w, http.StatusCreated, slots)
 }
@@ -198,6 +199,7 @@ func (app *application) updateFacilityPricingHandler(w http.ResponseWriter, r *h
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.enqueuePriceAlertCheck(venueID)
 
 	app.jsonResponse(w, http.StatusOK, pricing)
 }
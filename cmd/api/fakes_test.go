@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/guests"
+	"khel/internal/domain/inbox"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/paymentsplits"
+	"khel/internal/domain/pushtokens"
+	"khel/internal/domain/refunds"
+	"khel/internal/domain/storage"
+	"khel/internal/notifications"
+	"khel/internal/payments"
+	"sync"
+	"time"
+
+	"github.com/9ssi7/exponent"
+	"go.uber.org/zap"
+)
+
+// Hand-written fakes for handler tests. Each embeds the interface it stands
+// in for, so a handler calling a method the test didn't expect panics
+// instead of silently getting zero values.
+
+type fakeBookings struct {
+	bookings.Store
+	byID       map[int64]*bookings.Booking
+	canceledAt map[int64]time.Time
+}
+
+func (f *fakeBookings) GetBookingByID(_ context.Context, bookingID int64) (*bookings.Booking, error) {
+	b, ok := f.byID[bookingID]
+	if !ok {
+		return nil, bookings.ErrNotFound
+	}
+	cp := *b
+	return &cp, nil
+}
+
+func (f *fakeBookings) CanceledAt(_ context.Context, bookingID int64) (time.Time, error) {
+	at, ok := f.canceledAt[bookingID]
+	if !ok {
+		return time.Time{}, bookings.ErrNotFound
+	}
+	return at, nil
+}
+
+type fakePaymentSplits struct {
+	paymentsplits.Store
+	splits map[int64]*paymentsplits.Split // by booking ID
+	shares map[int64]*paymentsplits.Share
+
+	// providerSet records SetShareProvider calls as shareID -> ref.
+	providerSet map[int64]string
+	// paid records the shares passed to MarkSharePaid.
+	paid []int64
+}
+
+func (f *fakePaymentSplits) GetByBooking(_ context.Context, bookingID int64) (*paymentsplits.Split, error) {
+	s, ok := f.splits[bookingID]
+	if !ok {
+		return nil, paymentsplits.ErrNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (f *fakePaymentSplits) GetShare(_ context.Context, shareID int64) (*paymentsplits.Share, error) {
+	sh, ok := f.shares[shareID]
+	if !ok {
+		return nil, paymentsplits.ErrShareNotFound
+	}
+	cp := *sh
+	return &cp, nil
+}
+
+func (f *fakePaymentSplits) SetShareProvider(_ context.Context, shareID int64, _, ref string) error {
+	if f.providerSet == nil {
+		f.providerSet = map[int64]string{}
+	}
+	f.providerSet[shareID] = ref
+	return nil
+}
+
+func (f *fakePaymentSplits) MarkSharePaid(_ context.Context, shareID int64) (*paymentsplits.PaidResult, error) {
+	f.paid = append(f.paid, shareID)
+	sh := f.shares[shareID]
+	return &paymentsplits.PaidResult{SplitID: sh.SplitID, BookingID: sh.BookingID}, nil
+}
+
+type fakeRefunds struct {
+	refunds.Store
+	byID    map[int64]*refunds.Refund
+	policy  refunds.Policy
+	paid    []refunds.Payment
+	paidErr error
+
+	// created records the refunds passed to Create.
+	created []*refunds.Refund
+	// completed records Complete calls as refundID -> status, items.
+	completed map[int64]refundOutcome
+}
+
+type refundOutcome struct {
+	status string
+	items  []refunds.Item
+}
+
+func (f *fakeRefunds) GetByID(_ context.Context, id int64) (*refunds.Refund, error) {
+	rf, ok := f.byID[id]
+	if !ok {
+		return nil, refunds.ErrNotFound
+	}
+	cp := *rf
+	return &cp, nil
+}
+
+func (f *fakeRefunds) Approve(_ context.Context, id, decidedBy int64, note *string) error {
+	rf, ok := f.byID[id]
+	if !ok {
+		return refunds.ErrNotFound
+	}
+	if rf.Status != refunds.StatusPending {
+		return refunds.ErrNotPending
+	}
+	rf.Status = refunds.StatusProcessing
+	rf.DecidedBy = &decidedBy
+	rf.DecisionNote = note
+	return nil
+}
+
+func (f *fakeRefunds) GetPolicy(context.Context, int64) (refunds.Policy, error) {
+	return f.policy, nil
+}
+
+func (f *fakeRefunds) PaidPayments(context.Context, int64) ([]refunds.Payment, error) {
+	return f.paid, f.paidErr
+}
+
+func (f *fakeRefunds) Complete(_ context.Context, id int64, status string, items []refunds.Item) error {
+	if f.completed == nil {
+		f.completed = map[int64]refundOutcome{}
+	}
+	f.completed[id] = refundOutcome{status, items}
+	if rf, ok := f.byID[id]; ok {
+		rf.Status = status
+	}
+	return nil
+}
+
+func (f *fakeRefunds) Create(_ context.Context, rf *refunds.Refund) error {
+	rf.ID = int64(len(f.created) + 1)
+	rf.Status = refunds.StatusPending
+	f.created = append(f.created, rf)
+	return nil
+}
+
+type fakeGuests struct {
+	guests.Store
+	deposits map[string]*guests.Deposit // by token hash
+
+	// paid records the bookings passed to MarkDepositPaid.
+	paid []int64
+}
+
+func (f *fakeGuests) GetDeposit(_ context.Context, tokenHash string) (*guests.Deposit, error) {
+	d, ok := f.deposits[tokenHash]
+	if !ok {
+		return nil, guests.ErrDepositNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+func (f *fakeGuests) MarkDepositPaid(_ context.Context, bookingID int64) (bool, error) {
+	f.paid = append(f.paid, bookingID)
+	return true, nil
+}
+
+// fakeInbox records the notifications saved to users' inboxes. Senders
+// run in the background, so it is safe for concurrent use.
+type fakeInbox struct {
+	inbox.Store
+	mu      sync.Mutex
+	created []string // titles
+}
+
+func (f *fakeInbox) Create(_ context.Context, _ []int64, _, title, _ string, _ map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, title)
+	return nil
+}
+
+// fakeNotificationPrefs lets every user receive every category.
+type fakeNotificationPrefs struct {
+	notificationprefs.Store
+}
+
+func (fakeNotificationPrefs) FilterAllowed(_ context.Context, userIDs []int64, _ notificationprefs.Category, _ notificationprefs.Channel) ([]int64, error) {
+	return userIDs, nil
+}
+
+type fakePushTokens struct {
+	pushtokens.Store
+	tokens map[int64][]string
+}
+
+func (f *fakePushTokens) GetTokensByUserIDs(_ context.Context, userIDs []int64) (map[int64][]string, error) {
+	out := map[int64][]string{}
+	for _, id := range userIDs {
+		if t, ok := f.tokens[id]; ok {
+			out[id] = append([]string(nil), t...)
+		}
+	}
+	return out, nil
+}
+
+// fakePush hands every published message to sent, so a test can wait for a
+// push sent in the background.
+type fakePush struct {
+	sent chan *exponent.Message
+}
+
+var _ notifications.PushSender = (*fakePush)(nil)
+
+func newFakePush() *fakePush {
+	return &fakePush{sent: make(chan *exponent.Message, 16)}
+}
+
+func (f *fakePush) Publish(_ context.Context, msgs []*exponent.Message) ([]*exponent.MessageResponse, error) {
+	for _, m := range msgs {
+		f.sent <- m
+	}
+	return nil, nil
+}
+
+func (f *fakePush) PublishSingle(ctx context.Context, msg *exponent.Message) ([]*exponent.MessageResponse, error) {
+	return f.Publish(ctx, []*exponent.Message{msg})
+}
+
+// fakePayments records the payments started and checked through it and
+// answers with fixed gateway responses.
+type fakePayments struct {
+	initiated []payments.PaymentRequest
+	resp      payments.PaymentResponse
+
+	verified []payments.PaymentVerifyRequest
+	verify   payments.PaymentVerifyResponse
+
+	refunded  []payments.RefundRequest
+	refundErr error
+}
+
+var _ payments.Processor = (*fakePayments)(nil)
+
+func (f *fakePayments) InitiatePayment(_ context.Context, _ string, req payments.PaymentRequest) (payments.PaymentResponse, error) {
+	f.initiated = append(f.initiated, req)
+	return f.resp, nil
+}
+
+func (f *fakePayments) VerifyPayment(_ context.Context, _ string, req payments.PaymentVerifyRequest) (payments.PaymentVerifyResponse, error) {
+	f.verified = append(f.verified, req)
+	return f.verify, nil
+}
+
+func (f *fakePayments) RefundPayment(_ context.Context, _ string, req payments.RefundRequest) (payments.RefundResponse, error) {
+	f.refunded = append(f.refunded, req)
+	if f.refundErr != nil {
+		return payments.RefundResponse{}, f.refundErr
+	}
+	return payments.RefundResponse{ProviderRef: "refund-" + req.TransactionID, State: "Refunded"}, nil
+}
+
+// newTestApplication returns an application wired to store and pay, with
+// logging discarded.
+func newTestApplication(store *storage.Container, pay payments.Processor) *application {
+	return &application{
+		logger:   zap.NewNop().Sugar(),
+		store:    store,
+		payments: pay,
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"khel/internal/audit"
 	"khel/internal/domain/featured"
 	"khel/internal/params"
 	"net/http"
@@ -14,79 +15,14 @@ import (
 
 // -------------------- small helpers --------------------
 
-// parseLimitOffset keeps your existing query style (limit/offset) but fills params.Pagination.
-func parseLimitOffset(r *http.Request) (params.Pagination, error) {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10
-	if limitStr != "" {
-		v, err := strconv.Atoi(limitStr)
-		if err != nil || v <= 0 {
-			return params.Pagination{}, errors.New("invalid limit parameter")
-		}
-		if v > 100 {
-			limit = 100
-		} else {
-			limit = v
-		}
-	}
-
-	offset := 0
-	if offsetStr != "" {
-		v, err := strconv.Atoi(offsetStr)
-		if err != nil || v < 0 {
-			return params.Pagination{}, errors.New("invalid offset parameter")
-		}
-		offset = v
-	}
-
-	// If your params.Pagination expects Page, compute a best-effort page.
-	page := 1
-	if limit > 0 {
-		page = (offset / limit) + 1
-	}
-
-	return params.Pagination{
-		Page:   page,
-		Limit:  limit,
-		Offset: offset,
-	}, nil
+type adminFeaturedCollectionsQuery struct {
+	params.LimitOffset
+	featured.CollectionFilters
 }
 
-func parsePageLimit(r *http.Request) (params.Pagination, error) {
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
-
-	page := 1
-	if pageStr != "" {
-		v, err := strconv.Atoi(pageStr)
-		if err != nil || v <= 0 {
-			return params.Pagination{}, errors.New("invalid page parameter")
-		}
-		page = v
-	}
-
-	limit := 20
-	if limitStr != "" {
-		v, err := strconv.Atoi(limitStr)
-		if err != nil || v <= 0 {
-			return params.Pagination{}, errors.New("invalid limit parameter")
-		}
-		if v > 100 {
-			limit = 100
-		} else {
-			limit = v
-		}
-	}
-
-	offset := (page - 1) * limit
-
-	return params.Pagination{
-		Page:   page,
-		Limit:  limit,
-		Offset: offset,
-	}, nil
+type adminFeaturedItemsQuery struct {
+	params.LimitOffset
+	featured.ItemFilters
 }
 
 // best-effort refresh: do not fail the request if refresh fails (changes are already committed).
@@ -173,30 +109,13 @@ func (app *application) adminListFeaturedCollectionsHandler(w http.ResponseWrite
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	p, err := parseLimitOffset(r)
-	if err != nil {
+	q := adminFeaturedCollectionsQuery{LimitOffset: params.LimitOffset{Limit: 10}}
+	if err := readQuery(r, &q); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	var filters featured.CollectionFilters
-
-	if s := r.URL.Query().Get("search"); s != "" {
-		filters.Search = &s
-	}
-	if t := r.URL.Query().Get("type"); t != "" {
-		filters.Type = &t
-	}
-	if a := r.URL.Query().Get("active"); a != "" {
-		parsed, err := strconv.ParseBool(a)
-		if err != nil {
-			app.badRequestResponse(w, r, errors.New("invalid active parameter"))
-			return
-		}
-		filters.Active = &parsed
-	}
-
-	out, err := app.store.Featured.ListCollections(ctx, p, filters)
+	out, err := app.store.Featured.ListCollections(ctx, q.Pagination(), q.CollectionFilters)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -292,6 +211,8 @@ func (app *application) adminCreateFeaturedCollectionHandler(w http.ResponseWrit
 		return
 	}
 
+	app.recordAudit(r, audit.EntityFeaturedCollection, audit.ActionCreate, created.ID, nil, created)
+
 	cacheOK := app.tryRefreshFeaturedCache(ctx)
 
 	app.jsonResponse(w, http.StatusCreated, map[string]interface{}{
@@ -348,6 +269,9 @@ func (app *application) adminUpdateFeaturedCollectionHandler(w http.ResponseWrit
 		EndsAt:      payload.EndsAt,
 	}
 
+	// snapshot for the audit trail; a missing row is reported by UpdateCollection
+	before, _ := app.store.Featured.GetCollectionByID(ctx, id)
+
 	updated, err := app.store.Featured.UpdateCollection(ctx, id, req)
 	if err != nil {
 		if errors.Is(err, featured.ErrNotFound) {
@@ -366,6 +290,8 @@ func (app *application) adminUpdateFeaturedCollectionHandler(w http.ResponseWrit
 		return
 	}
 
+	app.recordAudit(r, audit.EntityFeaturedCollection, audit.ActionUpdate, id, before, updated)
+
 	cacheOK := app.tryRefreshFeaturedCache(ctx)
 
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
@@ -399,6 +325,8 @@ func (app *application) adminDeleteFeaturedCollectionHandler(w http.ResponseWrit
 		return
 	}
 
+	before, _ := app.store.Featured.GetCollectionByID(ctx, id)
+
 	if err := app.store.Featured.DeleteCollection(ctx, id); err != nil {
 		if errors.Is(err, featured.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
@@ -408,6 +336,8 @@ func (app *application) adminDeleteFeaturedCollectionHandler(w http.ResponseWrit
 		return
 	}
 
+	app.recordAudit(r, audit.EntityFeaturedCollection, audit.ActionDelete, id, before, nil)
+
 	cacheOK := app.tryRefreshFeaturedCache(ctx)
 
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
@@ -443,23 +373,13 @@ func (app *application) adminListFeaturedItemsHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	p, err := parseLimitOffset(r)
-	if err != nil {
+	q := adminFeaturedItemsQuery{LimitOffset: params.LimitOffset{Limit: 10}}
+	if err := readQuery(r, &q); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	var filters featured.ItemFilters
-	if a := r.URL.Query().Get("active"); a != "" {
-		parsed, err := strconv.ParseBool(a)
-		if err != nil {
-			app.badRequestResponse(w, r, errors.New("invalid active parameter"))
-			return
-		}
-		filters.Active = &parsed
-	}
-
-	out, err := app.store.Featured.ListItemsByCollection(ctx, collectionID, p, filters)
+	out, err := app.store.Featured.ListItemsByCollection(ctx, collectionID, q.Pagination(), q.ItemFilters)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -532,6 +452,8 @@ func (app *application) adminCreateFeaturedItemHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, audit.EntityFeaturedItem, audit.ActionCreate, created.ID, nil, created)
+
 	cacheOK := app.tryRefreshFeaturedCache(ctx)
 
 	app.jsonResponse(w, http.StatusCreated, map[string]interface{}{
@@ -590,6 +512,9 @@ func (app *application) adminUpdateFeaturedItemHandler(w http.ResponseWriter, r
 		EndsAt:           payload.EndsAt,
 	}
 
+	// snapshot for the audit trail; a missing row is reported by UpdateItem
+	before, _ := app.store.Featured.GetItemByID(ctx, itemID)
+
 	updated, err := app.store.Featured.UpdateItem(ctx, itemID, req)
 	if err != nil {
 		if errors.Is(err, featured.ErrItemNotFound) {
@@ -608,6 +533,8 @@ func (app *application) adminUpdateFeaturedItemHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, audit.EntityFeaturedItem, audit.ActionUpdate, itemID, before, updated)
+
 	cacheOK := app.tryRefreshFeaturedCache(ctx)
 
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
@@ -641,6 +568,8 @@ func (app *application) adminDeleteFeaturedItemHandler(w http.ResponseWriter, r
 		return
 	}
 
+	before, _ := app.store.Featured.GetItemByID(ctx, itemID)
+
 	if err := app.store.Featured.DeleteItem(ctx, itemID); err != nil {
 		if errors.Is(err, featured.ErrItemNotFound) {
 			app.notFoundResponse(w, r, err)
@@ -650,6 +579,8 @@ func (app *application) adminDeleteFeaturedItemHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, audit.EntityFeaturedItem, audit.ActionDelete, itemID, before, nil)
+
 	cacheOK := app.tryRefreshFeaturedCache(ctx)
 
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
@@ -733,13 +664,13 @@ func (app *application) getFeaturedCollectionItemsHandler(w http.ResponseWriter,
 		return
 	}
 
-	p, err := parsePageLimit(r)
-	if err != nil {
+	q := params.PageLimit{Page: 1, Limit: 20}
+	if err := readQuery(r, &q); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	out, err := app.store.Featured.GetCollectionItems(ctx, collectionKey, p)
+	out, err := app.store.Featured.GetCollectionItems(ctx, collectionKey, q.Pagination())
 	if err != nil {
 		if errors.Is(err, featured.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"khel/internal/domain/feed"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 50
+)
+
+// getFeedHandler godoc
+//
+//	@Summary		Get my activity feed
+//	@Description	Friends' completed games, new public games by organizers you follow and announcements from your favorite venues, newest first.
+//	@Description	Pass next_cursor from the previous page as cursor to load more; it is omitted on the last page.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			cursor	query		string		false	"Opaque cursor from the previous page"
+//	@Param			limit	query		int			false	"Items per page (default: 20, max: 50)"
+//	@Success		200		{object}	feed.Page
+//	@Failure		400		{object}	error		"Bad Request"
+//	@Failure		401		{object}	error		"Unauthorized"
+//	@Failure		500		{object}	error		"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/feed [get]
+func (app *application) getFeedHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	q := r.URL.Query()
+
+	limit := defaultFeedLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid limit"))
+			return
+		}
+		limit = min(n, maxFeedLimit)
+	}
+
+	cursor, err := feed.DecodeCursor(q.Get("cursor"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	page, err := app.store.Feed.List(r.Context(), user.ID, cursor, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, page)
+}
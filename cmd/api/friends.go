@@ -0,0 +1,392 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/domain/friends"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultFriendsNearbyRadiusKm = 10
+	maxFriendsNearbyRadiusKm     = 50
+	friendsNearbyLimit           = 30
+)
+
+type UpdatePrivacyPayload struct {
+	// GamesVisibility controls who can see the games you have joined.
+	GamesVisibility string `json:"games_visibility" validate:"required,oneof=public friends"`
+}
+
+type PrivacySettings struct {
+	GamesVisibility string `json:"games_visibility"`
+}
+
+// listFriendsHandler godoc
+//
+//	@Summary		List my friends
+//	@Description	Returns the current user's accepted friends, ordered by name.
+//	@Tags			Friends
+//	@Produce		json
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"friends + pagination metadata"
+//	@Failure		401		{object}	error			"Unauthorized"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/friends [get]
+func (app *application) listFriendsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	pagination := params.ParsePagination(r.URL.Query())
+	list, total, err := app.store.Friends.ListFriends(r.Context(), user.ID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"friends":    list,
+		"pagination": pagination,
+	})
+}
+
+// listFriendRequestsHandler godoc
+//
+//	@Summary		List incoming friend requests
+//	@Description	Returns pending friend requests sent to the current user, newest first.
+//	@Tags			Friends
+//	@Produce		json
+//	@Success		200	{array}		friends.FriendRequest
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/friends/requests [get]
+func (app *application) listFriendRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	reqs, err := app.store.Friends.ListIncomingRequests(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, reqs)
+}
+
+// sendFriendRequestHandler godoc
+//
+//	@Summary		Send a friend request
+//	@Description	Sends a friend request to another user. If that user already sent you one, you become friends right away.
+//	@Tags			Friends
+//	@Produce		json
+//	@Param			userID	path		int						true	"User ID"
+//	@Success		201		{object}	friends.FriendRequest	"Request sent (status pending) or accepted"
+//	@Failure		400		{object}	error					"Bad Request"
+//	@Failure		404		{object}	error					"User not found"
+//	@Failure		409		{object}	error					"Request already exists or already friends"
+//	@Failure		500		{object}	error					"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/friend-request [post]
+func (app *application) sendFriendRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	targetID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	fr, err := app.store.Friends.SendRequest(r.Context(), user.ID, targetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, friends.ErrSelfRequest):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, friends.ErrUserNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, friends.ErrRequestExists), errors.Is(err, friends.ErrAlreadyFriends):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, fr)
+}
+
+// acceptFriendRequestHandler godoc
+//
+//	@Summary		Accept a friend request
+//	@Description	Accepts a pending friend request sent to the current user.
+//	@Tags			Friends
+//	@Produce		json
+//	@Param			requestID	path		int		true	"Friend request ID"
+//	@Success		204			{string}	string	"Accepted"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Request not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/friends/requests/{requestID}/accept [post]
+func (app *application) acceptFriendRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	requestID, err := readIDParam(r, "requestID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid request ID"))
+		return
+	}
+
+	if err := app.store.Friends.AcceptRequest(r.Context(), requestID, user.ID); err != nil {
+		if errors.Is(err, friends.ErrRequestNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteFriendRequestHandler godoc
+//
+//	@Summary		Decline or cancel a friend request
+//	@Description	The addressee declines, or the requester cancels, a pending friend request.
+//	@Tags			Friends
+//	@Produce		json
+//	@Param			requestID	path		int		true	"Friend request ID"
+//	@Success		204			{string}	string	"Deleted"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Request not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/friends/requests/{requestID} [delete]
+func (app *application) deleteFriendRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	requestID, err := readIDParam(r, "requestID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid request ID"))
+		return
+	}
+
+	if err := app.store.Friends.DeleteRequest(r.Context(), requestID, user.ID); err != nil {
+		if errors.Is(err, friends.ErrRequestNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeFriendHandler godoc
+//
+//	@Summary		Remove a friend
+//	@Description	Ends the friendship between the current user and userID.
+//	@Tags			Friends
+//	@Produce		json
+//	@Param			userID	path		int		true	"User ID"
+//	@Success		204		{string}	string	"Removed"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Not friends"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/friend [delete]
+func (app *application) removeFriendHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	friendID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	if err := app.store.Friends.RemoveFriend(r.Context(), user.ID, friendID); err != nil {
+		if errors.Is(err, friends.ErrNotFriends) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// friendsGamesNearbyHandler godoc
+//
+//	@Summary		Friends playing nearby
+//	@Description	Upcoming public games within radius km of lat/lon that at least one friend has joined and you have not.
+//	@Tags			Friends
+//	@Produce		json
+//	@Param			lat		query		number	true	"Latitude"
+//	@Param			lon		query		number	true	"Longitude"
+//	@Param			radius	query		int		false	"Radius in km (default: 10, max: 50)"
+//	@Success		200		{array}		friends.FriendGame
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/friends/games-nearby [get]
+func (app *application) friendsGamesNearbyHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	q := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid lat value"))
+		return
+	}
+	lon, err := strconv.ParseFloat(q.Get("lon"), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid lon value"))
+		return
+	}
+
+	radius := defaultFriendsNearbyRadiusKm
+	if v := q.Get("radius"); v != "" {
+		radius, err = strconv.Atoi(v)
+		if err != nil || radius <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid radius value"))
+			return
+		}
+		if radius > maxFriendsNearbyRadiusKm {
+			radius = maxFriendsNearbyRadiusKm
+		}
+	}
+
+	games, err := app.store.Friends.ListFriendsGamesNearby(r.Context(), user.ID, lat, lon, radius, friendsNearbyLimit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, games)
+}
+
+// getUserGamesHandler godoc
+//
+//	@Summary		List a user's upcoming games
+//	@Description	Returns the upcoming games userID has joined. If they set games_visibility to friends, only their friends can see them.
+//	@Tags			Friends
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		200		{array}		games.GameSummary
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Games are visible to friends only"
+//	@Failure		404		{object}	error	"User not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/games [get]
+func (app *application) getUserGamesHandler(w http.ResponseWriter, r *http.Request) {
+	viewer := getUserFromContext(r)
+
+	userID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	if userID != viewer.ID {
+		visibility, err := app.store.Friends.GetGamesVisibility(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, friends.ErrUserNotFound) {
+				app.notFoundResponse(w, r, err)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		blocked, err := app.store.Safety.IsBlocked(r.Context(), viewer.ID, userID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if blocked {
+			app.notFoundResponse(w, r, friends.ErrUserNotFound)
+			return
+		}
+
+		if visibility == friends.VisibilityFriends {
+			ok, err := app.store.Friends.AreFriends(r.Context(), viewer.ID, userID)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if !ok {
+				app.forbiddenResponse(w, r)
+				return
+			}
+		}
+	}
+
+	games, err := app.store.Games.GetUpcomingGamesByUser(r.Context(), userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, games)
+}
+
+// getPrivacyHandler godoc
+//
+//	@Summary		Get my privacy settings
+//	@Tags			Friends
+//	@Produce		json
+//	@Success		200	{object}	PrivacySettings
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/privacy [get]
+func (app *application) getPrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	visibility, err := app.store.Friends.GetGamesVisibility(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, PrivacySettings{GamesVisibility: visibility})
+}
+
+// updatePrivacyHandler godoc
+//
+//	@Summary		Update my privacy settings
+//	@Description	games_visibility is public (anyone can see your joined games) or friends (friends only).
+//	@Tags			Friends
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		UpdatePrivacyPayload	true	"Privacy settings"
+//	@Success		200		{object}	PrivacySettings
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/privacy [put]
+func (app *application) updatePrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	var payload UpdatePrivacyPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Friends.SetGamesVisibility(r.Context(), user.ID, payload.GamesVisibility); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, PrivacySettings{GamesVisibility: payload.GamesVisibility})
+}
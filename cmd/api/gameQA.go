@@ -3,11 +3,11 @@ package main
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"khel/internal/domain/gameqa"
+	"khel/internal/domain/moderationqueue"
 	"khel/internal/notifications"
 
 	"github.com/go-chi/chi/v5"
@@ -36,7 +36,6 @@ type ReplyPayload struct {
 // @Router			/games/{gameID}/questions [post]
 func (app *application) createQuestionHandler(w http.ResponseWriter, r *http.Request) {
 	gameID, err := strconv.ParseInt(chi.URLParam(r, "gameID"), 10, 64)
-	fmt.Printf("successful parse gameID %v", gameID)
 	if err != nil {
 		app.badRequestResponse(w, r, errors.New("invalid game ID"))
 		return
@@ -54,7 +53,6 @@ func (app *application) createQuestionHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	user := getUserFromContext(r)
-	fmt.Printf("login userID is %v", user.ID)
 
 	question := &gameqa.Question{
 		GameID:   gameID,
@@ -66,8 +64,9 @@ func (app *application) createQuestionHandler(w http.ResponseWriter, r *http.Req
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.enqueueScreen(moderationqueue.ContentGameQuestion, question.ID, user.ID, question.Question)
 
-	notifications.CallAsync(func(ctx context.Context) error {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
 		return notifications.NotifyGameQuestionToAdmin(ctx, app.push, app.store, gameID, user.FirstName)
 	}, "SendingCreateGameQuestion")
 
@@ -149,8 +148,9 @@ func (app *application) createReplyHandler(w http.ResponseWriter, r *http.Reques
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.enqueueScreen(moderationqueue.ContentGameReply, reply.ID, user.ID, reply.Reply)
 
-	notifications.CallAsync(func(ctx context.Context) error {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
 		return notifications.SendQuestionReply(ctx, app.push, app.store, questionID, gameID)
 	}, "SendingQuestionReplyToUser")
 
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/games"
+	"khel/internal/domain/refunds"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"net/http"
+	"time"
+)
+
+type GameAutoCancelPayload struct {
+	// Leave both out to turn auto-cancel off.
+	MinPlayers   *int       `json:"min_players,omitempty" validate:"required_with=AutoCancelAt,omitempty,min=2"`
+	AutoCancelAt *time.Time `json:"auto_cancel_at,omitempty" validate:"required_with=MinPlayers"`
+}
+
+// validateAutoCancel checks an auto-cancel rule against the game it is for.
+func validateAutoCancel(minPlayers *int, cutoff *time.Time, maxPlayers int, start time.Time) error {
+	if minPlayers == nil || cutoff == nil {
+		return nil
+	}
+	if *minPlayers > maxPlayers {
+		return fmt.Errorf("min_players can't be more than max_players (%d)", maxPlayers)
+	}
+	if !cutoff.After(time.Now()) {
+		return errors.New("auto_cancel_at must be in the future")
+	}
+	if !cutoff.Before(start) {
+		return errors.New("auto_cancel_at must be before the game starts")
+	}
+	return nil
+}
+
+// checkGameBooking makes sure a booking linked at game creation is the
+// organizer's own, still live, at the game's venue and covers its time.
+func (app *application) checkGameBooking(ctx context.Context, userID, bookingID, venueID int64, start, end time.Time) error {
+	b, err := app.store.Bookings.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			return errors.New("booking not found")
+		}
+		return err
+	}
+	if b.UserID != userID {
+		return errors.New("booking not found")
+	}
+	if b.VenueID != venueID {
+		return errors.New("booking is for a different venue")
+	}
+	if b.Status != "pending" && b.Status != "confirmed" {
+		return fmt.Errorf("booking is %s", b.Status)
+	}
+	if start.Before(b.StartTime) || end.After(b.EndTime) {
+		return errors.New("game time must fall within the booking")
+	}
+	return nil
+}
+
+// setGameAutoCancelHandler godoc
+//
+//	@Summary		Set the game's auto-cancel rule
+//	@Description	If fewer than min_players have joined by auto_cancel_at, the game is canceled, everyone in it is notified and the linked booking is released, with any online payment refunded per the venue's refund policy. Send an empty body to turn it off.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int						true	"Game ID"
+//	@Param			payload	body		GameAutoCancelPayload	true	"Auto-cancel rule"
+//	@Success		200		{object}	games.Game
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Game not found"
+//	@Failure		409		{object}	error	"Game is no longer active"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/auto-cancel [put]
+func (app *application) setGameAutoCancelHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	var payload GameAutoCancelPayload
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	game, err := app.store.Games.GetGameByID(ctx, gameID)
+	if err != nil {
+		app.notFoundResponse(w, r, errors.New("game not found"))
+		return
+	}
+	if game.Status != "active" {
+		app.conflictResponse(w, r, errors.New("game is no longer active"))
+		return
+	}
+	if err := validateAutoCancel(payload.MinPlayers, payload.AutoCancelAt, game.MaxPlayers, game.StartTime); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Games.SetAutoCancel(ctx, gameID, payload.MinPlayers, payload.AutoCancelAt); err != nil {
+		switch {
+		case errors.Is(err, games.ErrInvalidAutoCancel):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, games.ErrNotFound):
+			app.conflictResponse(w, r, errors.New("game is no longer active"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	game.MinPlayers = payload.MinPlayers
+	game.AutoCancelAt = payload.AutoCancelAt
+	app.jsonResponse(w, http.StatusOK, game)
+}
+
+// runAutoCancelGames cancels games that missed their player threshold, then
+// releases their bookings and tells everyone involved.
+func (app *application) runAutoCancelGames(ctx context.Context) error {
+	started := time.Now()
+	cancelled, err := app.store.Games.AutoCancelUnderfilled(ctx)
+	if err != nil {
+		app.logger.Errorw("auto-cancel games failed", "duration_ms", time.Since(started).Milliseconds(), "error", err)
+		return err
+	}
+	jobs.SetRowsAffected(ctx, int64(len(cancelled)))
+	if len(cancelled) == 0 {
+		return nil
+	}
+
+	released := 0
+	for _, g := range cancelled {
+		if g.BookingReleased {
+			released++
+		}
+	}
+	app.logger.Infow("auto-cancelled underfilled games",
+		"games", len(cancelled),
+		"bookings_released", released,
+		"duration_ms", time.Since(started).Milliseconds(),
+	)
+
+	// The games are already cancelled, so nothing below can be retried by
+	// this job; log failures and carry on with the rest.
+	for _, g := range cancelled {
+		if g.BookingReleased {
+			app.releaseGameBooking(ctx, *g.BookingID)
+		}
+		if err := notifications.SendGameAutoCancelled(ctx, app.push, app.store, g); err != nil {
+			app.logger.Errorw("failed to send auto-cancel notifications", "game_id", g.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// releaseGameBooking follows up on a booking the auto-cancel job canceled:
+// slot alerts are told the time is free and anything paid online goes into
+// a refund sized by the venue's policy, waiting on the usual approval.
+func (app *application) releaseGameBooking(ctx context.Context, bookingID int64) {
+	booking, err := app.store.Bookings.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		app.logger.Errorw("failed to load released booking", "booking_id", bookingID, "error", err)
+		return
+	}
+	app.publishBookingReleased(booking, "canceled")
+
+	reason := "Game canceled automatically: not enough players joined."
+	_, err = app.fileBookingRefund(ctx, booking, booking.UserID, &reason)
+	if err != nil && !errors.Is(err, errNothingPaidOnline) && !errors.Is(err, refunds.ErrAlreadyRequested) {
+		app.logger.Errorw("failed to file refund for released booking", "booking_id", bookingID, "error", err)
+	}
+}
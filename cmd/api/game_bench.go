@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"khel/internal/domain/games"
+	"khel/internal/notifications"
+	"net/http"
+)
+
+type GameBenchSizePayload struct {
+	BenchSize int `json:"bench_size" validate:"min=0,max=20"`
+}
+
+// setGameBenchSizeHandler godoc
+//
+//	@Summary		Set the game's bench size
+//	@Description	How many players can still be accepted once the game is full. They wait on the bench and move into the game, longest-waiting first, when a player leaves. It can't be set below the number already waiting.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int						true	"Game ID"
+//	@Param			payload	body		GameBenchSizePayload	true	"Bench size"
+//	@Success		200		{object}	map[string]int
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Game not found or inactive"
+//	@Failure		409		{object}	error	"More players already on the bench"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/bench [put]
+func (app *application) setGameBenchSizeHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	var payload GameBenchSizePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Games.SetBenchSize(r.Context(), gameID, payload.BenchSize); err != nil {
+		switch {
+		case errors.Is(err, games.ErrNotFound):
+			app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+		case errors.Is(err, games.ErrBenchTooSmall):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]int{"bench_size": payload.BenchSize})
+}
+
+// leaveGameHandler godoc
+//
+//	@Summary		Leave a game
+//	@Description	Takes the current user out of the game or off its bench. A seat freed this way goes to the longest-waiting bench player, who is notified. The game admin can't leave; they cancel the game instead.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{object}	map[string]string
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Game not found or not in it"
+//	@Failure		409		{object}	error	"Admin can't leave"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/leave [post]
+func (app *application) leaveGameHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+	user := getUserFromContext(r)
+
+	promoted, err := app.store.Games.RemovePlayer(r.Context(), gameID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, games.ErrNotFound):
+			app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+		case errors.Is(err, games.ErrNotInGame):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, games.ErrAdminCannotLeave):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if promoted != nil {
+		promotedID := *promoted
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendBenchPromotedToUser(ctx, app.push, app.store, promotedID, gameID)
+		}, "SendingBenchPromotedToUser")
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "You left the game"})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"khel/internal/domain/games"
+	"khel/internal/notifications"
+	"net/http"
+	"time"
+)
+
+type UpdateGameDetailsPayload struct {
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	MaxPlayers  *int       `json:"max_players,omitempty" validate:"omitempty,min=1"`
+	Price       *int       `json:"price,omitempty" validate:"omitempty,min=0"`
+	Format      *string    `json:"format,omitempty" validate:"omitempty,max=20"`
+	GameLevel   *string    `json:"game_level,omitempty" validate:"omitempty,oneof=beginner intermediate advanced"`
+	Visibility  *string    `json:"visibility,omitempty" validate:"omitempty,oneof=public private"`
+	Instruction *string    `json:"instruction,omitempty" validate:"omitempty,max=500"`
+	// An empty string clears the safety info or emergency contact.
+	SafetyInfo            *string `json:"safety_info,omitempty" validate:"omitempty,max=500"`
+	EmergencyContactName  *string `json:"emergency_contact_name,omitempty" validate:"omitempty,max=100"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone,omitempty" validate:"omitempty,max=20"`
+}
+
+// updateGameDetailsHandler godoc
+//
+//	@Summary		Edit game details
+//	@Description	Changes the time, player limit, price, format, level, visibility, instructions, safety info or emergency contact of an active game without losing its roster. max_players can't go below the players already in; if it grows, bench players fill the new seats. A game with a linked booking has to stay within the booked slot. Every change is kept in the game's history and everyone in the game is notified. Needs the edit_details permission.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int							true	"Game ID"
+//	@Param			payload	body		UpdateGameDetailsPayload	true	"Fields to change"
+//	@Success		200		{object}	games.Game
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Game not found or inactive"
+//	@Failure		409		{object}	error	"More players joined than the new max_players"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID} [patch]
+func (app *application) updateGameDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	var payload UpdateGameDetailsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// A booked game can only move within the slot it holds.
+	if payload.StartTime != nil || payload.EndTime != nil {
+		game, err := app.store.Games.GetGameByID(ctx, gameID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if game.BookingID != nil {
+			start, end := game.StartTime, game.EndTime
+			if payload.StartTime != nil {
+				start = *payload.StartTime
+			}
+			if payload.EndTime != nil {
+				end = *payload.EndTime
+			}
+			if err := app.checkGameBooking(ctx, game.AdminID, *game.BookingID, game.VenueID, start, end); err != nil {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+		}
+	}
+
+	user := getUserFromContext(r)
+	edit, err := app.store.Games.UpdateDetails(ctx, gameID, user.ID, games.GameDetailsUpdate{
+		StartTime:   payload.StartTime,
+		EndTime:     payload.EndTime,
+		MaxPlayers:  payload.MaxPlayers,
+		Price:       payload.Price,
+		Format:      payload.Format,
+		GameLevel:   payload.GameLevel,
+		Visibility:  payload.Visibility,
+		Instruction: payload.Instruction,
+
+		SafetyInfo:            payload.SafetyInfo,
+		EmergencyContactName:  payload.EmergencyContactName,
+		EmergencyContactPhone: payload.EmergencyContactPhone,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, games.ErrNotFound):
+			app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+		case errors.Is(err, games.ErrInvalidGameUpdate):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, games.ErrRosterTooLarge):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if len(edit.Changes) > 0 {
+		changes := edit.Changes
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendGameUpdatedToPlayers(ctx, app.push, app.store, gameID, user.ID, changes)
+		}, "SendingGameUpdatedToPlayers")
+	}
+	for _, promotedID := range edit.Promoted {
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendBenchPromotedToUser(ctx, app.push, app.store, promotedID, gameID)
+		}, "SendingBenchPromotedToUser")
+	}
+
+	game, err := app.store.Games.GetGameByID(ctx, gameID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, game)
+}
+
+// getGameChangesHandler godoc
+//
+//	@Summary		Game edit history
+//	@Description	Every change made to the game's details, newest first, with the old and new value of each field.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{array}		games.GameChange
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/changes [get]
+func (app *application) getGameChangesHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	changes, err := app.store.Games.ListChanges(r.Context(), gameID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, changes)
+}
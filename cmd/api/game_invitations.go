@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/games"
+	"khel/internal/notifications"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type InviteLinkPayload struct {
+	// MaxUses caps how many players can join through the link.
+	MaxUses   *int       `json:"max_uses,omitempty" validate:"omitempty,min=1,max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type InviteUsersPayload struct {
+	UserIDs []int64 `json:"user_ids" validate:"required,min=1,max=50,dive,min=1"`
+}
+
+// GameInvitationResponse is an invitation with the code and link to share
+// when it is a link.
+type GameInvitationResponse struct {
+	games.Invitation
+	Code string `json:"code,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// AcceptInvitationResponse is where accepting put the user.
+type AcceptInvitationResponse struct {
+	GameID        int64 `json:"game_id"`
+	BenchPosition int   `json:"bench_position,omitempty"`
+}
+
+func (app *application) invitationResponse(inv games.Invitation) GameInvitationResponse {
+	resp := GameInvitationResponse{Invitation: inv}
+	if inv.LinkNonce != nil {
+		code, err := app.hashID.Encode([]int{int(inv.ID), *inv.LinkNonce})
+		if err != nil {
+			app.logger.Errorw("failed to encode invite link", "invitation_id", inv.ID, "error", err)
+			return resp
+		}
+		resp.Code = code
+		resp.URL = fmt.Sprintf("%s/games/invite/%s", app.config.frontendURL, code)
+	}
+	return resp
+}
+
+// createInviteLinkHandler godoc
+//
+//	@Summary		Create an invite link
+//	@Description	Anyone with the link can join the game straight away, past its join policy and request queue, taking a seat or, when the game is full, a bench spot. Limit it with max_uses and expires_at, or revoke it.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int					true	"Game ID"
+//	@Param			payload	body		InviteLinkPayload	true	"Link limits"
+//	@Success		201		{object}	GameInvitationResponse
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Game not found or inactive"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/invitations/link [post]
+func (app *application) createInviteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	var payload InviteLinkPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		app.badRequestResponse(w, r, errors.New("expires_at must be in the future"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	inv := &games.Invitation{
+		GameID:    gameID,
+		InvitedBy: &user.ID,
+		MaxUses:   payload.MaxUses,
+		ExpiresAt: payload.ExpiresAt,
+	}
+	if err := app.store.Games.CreateInviteLink(r.Context(), inv); err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, app.invitationResponse(*inv))
+}
+
+// inviteUsersHandler godoc
+//
+//	@Summary		Invite players to a game
+//	@Description	Sends each user an invitation they can accept to join straight away, past the game's join policy and request queue. Users already in the game, on its bench, already invited or with a block with the game admin are skipped; invited lists the ones invited now, who get a push notification.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int					true	"Game ID"
+//	@Param			payload	body		InviteUsersPayload	true	"Users to invite"
+//	@Success		201		{object}	map[string][]int64
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Game not found or inactive"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/invitations [post]
+func (app *application) inviteUsersHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	var payload InviteUsersPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	game, err := app.store.Games.GetGameByID(r.Context(), gameID)
+	if err != nil || game.Status != "active" || !game.StartTime.After(time.Now()) {
+		app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	blocked, err := app.store.Safety.BlockedAmong(r.Context(), game.AdminID, payload.UserIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	userIDs := make([]int64, 0, len(payload.UserIDs))
+	for _, id := range payload.UserIDs {
+		if !blocked[id] {
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	invited, err := app.store.Games.InviteUsers(r.Context(), gameID, user.ID, userIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	inviterName := user.FirstName
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendGameInvitation(ctx, app.push, app.store, invited, gameID, inviterName)
+	}, "SendingGameInvitation")
+
+	app.jsonResponse(w, http.StatusCreated, map[string][]int64{"invited": invited})
+}
+
+// listGameInvitationsHandler godoc
+//
+//	@Summary		List a game's invitations
+//	@Description	Every invitation and link with its status, newest first. Links come with their code and URL and count the players who joined through them in uses.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{array}		GameInvitationResponse
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/invitations [get]
+func (app *application) listGameInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	invitations, err := app.store.Games.ListInvitations(r.Context(), gameID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	resp := make([]GameInvitationResponse, len(invitations))
+	for i, inv := range invitations {
+		resp[i] = app.invitationResponse(inv)
+	}
+	app.jsonResponse(w, http.StatusOK, resp)
+}
+
+// revokeGameInvitationHandler godoc
+//
+//	@Summary		Revoke an invitation or link
+//	@Tags			Games
+//	@Param			gameID			path	int	true	"Game ID"
+//	@Param			invitationID	path	int	true	"Invitation ID"
+//	@Success		204				"No Content"
+//	@Failure		403				{object}	error	"Forbidden"
+//	@Failure		404				{object}	error	"Invitation not found or no longer pending"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/invitations/{invitationID} [delete]
+func (app *application) revokeGameInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+	invitationID, err := readIDParam(r, "invitationID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid invitation ID"))
+		return
+	}
+
+	if err := app.store.Games.RevokeInvitation(r.Context(), gameID, invitationID); err != nil {
+		if errors.Is(err, games.ErrInvitationNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listMyGameInvitationsHandler godoc
+//
+//	@Summary		List my game invitations
+//	@Description	Pending invitations to games that haven't started yet, soonest first.
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{array}		games.UserInvitation
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/game-invitations [get]
+func (app *application) listMyGameInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	invitations, err := app.store.Games.UserInvitations(r.Context(), getUserFromContext(r).ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, invitations)
+}
+
+// acceptGameInvitationHandler godoc
+//
+//	@Summary		Accept a game invitation
+//	@Description	Joins the game straight away, or its bench when it is full, without a join request.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			invitationID	path		int	true	"Invitation ID"
+//	@Success		200				{object}	AcceptInvitationResponse
+//	@Failure		404				{object}	error	"Invitation not found"
+//	@Failure		409				{object}	error	"Invitation closed, game started or full, or already in it"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/game-invitations/{invitationID}/accept [post]
+func (app *application) acceptGameInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := readIDParam(r, "invitationID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid invitation ID"))
+		return
+	}
+	app.acceptInvitation(w, r, invitationID, nil)
+}
+
+// acceptInviteLinkHandler godoc
+//
+//	@Summary		Join a game through an invite link
+//	@Description	Joins the game straight away, or its bench when it is full, without a join request.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			code	path		string	true	"Invite code from the link"
+//	@Success		200		{object}	AcceptInvitationResponse
+//	@Failure		404		{object}	error	"Invalid link"
+//	@Failure		409		{object}	error	"Link expired or used up, game started or full, or already in it"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/invitations/{code}/accept [post]
+func (app *application) acceptInviteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	ids, err := app.hashID.DecodeWithError(chi.URLParam(r, "code"))
+	if err != nil || len(ids) != 2 {
+		app.notFoundResponse(w, r, games.ErrInvitationNotFound)
+		return
+	}
+	nonce := ids[1]
+	app.acceptInvitation(w, r, int64(ids[0]), &nonce)
+}
+
+func (app *application) acceptInvitation(w http.ResponseWriter, r *http.Request, invitationID int64, nonce *int) {
+	user := getUserFromContext(r)
+	accepted, err := app.store.Games.AcceptInvitation(r.Context(), invitationID, nonce, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, games.ErrInvitationNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, games.ErrNotFound):
+			app.conflictResponse(w, r, errors.New("game is no longer active"))
+		case errors.Is(err, games.ErrInvitationClosed), errors.Is(err, games.ErrGameStarted),
+			errors.Is(err, games.ErrGameFull), errors.Is(err, games.ErrAlreadyInGame):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	playerName := user.FirstName
+	if accepted.BenchPosition > 0 {
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendBenchedToUser(ctx, app.push, app.store, user.ID, accepted.GameID, accepted.BenchPosition)
+		}, "SendingBenchedToUser")
+	} else {
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendPlayerJoinedToAdmin(ctx, app.push, app.store, accepted.AdminID, accepted.GameID, playerName)
+		}, "SendingPlayerJoinedToAdmin")
+	}
+
+	app.jsonResponse(w, http.StatusOK, AcceptInvitationResponse{
+		GameID:        accepted.GameID,
+		BenchPosition: accepted.BenchPosition,
+	})
+}
+
+// declineGameInvitationHandler godoc
+//
+//	@Summary		Decline a game invitation
+//	@Tags			Users
+//	@Param			invitationID	path	int	true	"Invitation ID"
+//	@Success		204				"No Content"
+//	@Failure		404				{object}	error	"Invitation not found"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/game-invitations/{invitationID} [delete]
+func (app *application) declineGameInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := readIDParam(r, "invitationID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid invitation ID"))
+		return
+	}
+
+	if err := app.store.Games.DeclineInvitation(r.Context(), invitationID, getUserFromContext(r).ID); err != nil {
+		if errors.Is(err, games.ErrInvitationNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
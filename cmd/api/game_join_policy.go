@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"khel/internal/domain/games"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"net/http"
+	"time"
+)
+
+type GameJoinPolicyPayload struct {
+	JoinPolicy string `json:"join_policy" validate:"required,oneof=auto_accept manual invite_only"`
+}
+
+// setGameJoinPolicyHandler godoc
+//
+//	@Summary		Set the game's join policy
+//	@Description	auto_accept seats players as soon as they ask while there is a free seat; once the game is full their requests wait for approval. manual leaves every request to the admin and assistants. invite_only takes no requests; players join through invitations. Requests already pending are left as they are.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int						true	"Game ID"
+//	@Param			payload	body		GameJoinPolicyPayload	true	"Join policy"
+//	@Success		200		{object}	map[string]string
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Game not found or inactive"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/join-policy [put]
+func (app *application) setGameJoinPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	var payload GameJoinPolicyPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Games.SetJoinPolicy(r.Context(), gameID, payload.JoinPolicy); err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"join_policy": payload.JoinPolicy})
+}
+
+// runExpireJoinRequests rejects join requests nobody answered before their
+// game started and tells the requesters.
+func (app *application) runExpireJoinRequests(ctx context.Context) error {
+	started := time.Now()
+	expired, err := app.store.Games.ExpireJoinRequests(ctx)
+	if err != nil {
+		app.logger.Errorw("expire join requests failed", "duration_ms", time.Since(started).Milliseconds(), "error", err)
+		return err
+	}
+	jobs.SetRowsAffected(ctx, int64(len(expired)))
+	if len(expired) == 0 {
+		return nil
+	}
+	app.logger.Infow("expired stale join requests",
+		"requests", len(expired),
+		"duration_ms", time.Since(started).Milliseconds(),
+	)
+
+	// The requests are already rejected; log failures and carry on.
+	for _, e := range expired {
+		if err := notifications.SendJoinRequestExpired(ctx, app.push, app.store, e.UserID, e.GameID); err != nil {
+			app.logger.Errorw("failed to send join request expiry", "game_id", e.GameID, "user_id", e.UserID, "error", err)
+		}
+	}
+	return nil
+}
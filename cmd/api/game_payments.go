@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"khel/internal/domain/gamepayments"
+	"khel/internal/domain/games"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"net/http"
+)
+
+// gamePaymentReminderBatchSize caps the games one reminder run claims.
+const gamePaymentReminderBatchSize = 200
+
+// getGamePaymentsHandler godoc
+//
+//	@Summary		Get who has paid for a game
+//	@Description	For games with a price, every player except the admin owes the admin that price. Lists each player's balance, those still owing first, with the totals. Visible to everyone in the game.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{object}	gamepayments.Summary
+//	@Failure		403		{object}	error	"Not in the game"
+//	@Failure		404		{object}	error	"Game not found"
+//	@Failure		409		{object}	error	"Game is free"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/payments [get]
+func (app *application) getGamePaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	if _, err := app.store.Games.GetMember(r.Context(), gameID, getUserFromContext(r).ID); err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	summary, err := app.store.GamePayments.Summary(r.Context(), gameID)
+	if err != nil {
+		app.gamePaymentError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, summary)
+}
+
+// markGamePaymentHandler godoc
+//
+//	@Summary		Mark a player as paid
+//	@Description	Records that the player paid the game's price to the admin in cash.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID		path		int	true	"Game ID"
+//	@Param			playerID	path		int	true	"Player's user ID"
+//	@Success		200			{object}	gamepayments.Summary
+//	@Failure		400			{object}	error	"Not a player of this game"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Game not found"
+//	@Failure		409			{object}	error	"Game is free"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/payments/{playerID} [put]
+func (app *application) markGamePaymentHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, playerID, ok := app.readGamePlayerParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.store.GamePayments.MarkPaid(r.Context(), gameID, playerID, getUserFromContext(r).ID); err != nil {
+		app.gamePaymentError(w, r, err)
+		return
+	}
+
+	summary, err := app.store.GamePayments.Summary(r.Context(), gameID)
+	if err != nil {
+		app.gamePaymentError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, summary)
+}
+
+// unmarkGamePaymentHandler godoc
+//
+//	@Summary		Undo marking a player as paid
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID		path		int	true	"Game ID"
+//	@Param			playerID	path		int	true	"Player's user ID"
+//	@Success		200			{object}	gamepayments.Summary
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Player wasn't marked paid"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/payments/{playerID} [delete]
+func (app *application) unmarkGamePaymentHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, playerID, ok := app.readGamePlayerParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.store.GamePayments.Unmark(r.Context(), gameID, playerID); err != nil {
+		app.gamePaymentError(w, r, err)
+		return
+	}
+
+	summary, err := app.store.GamePayments.Summary(r.Context(), gameID)
+	if err != nil {
+		app.gamePaymentError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, summary)
+}
+
+func (app *application) readGamePlayerParams(w http.ResponseWriter, r *http.Request) (gameID, playerID int64, ok bool) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return 0, 0, false
+	}
+	playerID, err = readIDParam(r, "playerID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid player ID"))
+		return 0, 0, false
+	}
+	return gameID, playerID, true
+}
+
+func (app *application) gamePaymentError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, gamepayments.ErrGameNotFound), errors.Is(err, gamepayments.ErrNotPaid):
+		app.notFoundResponse(w, r, err)
+	case errors.Is(err, gamepayments.ErrNoPrice):
+		app.conflictResponse(w, r, err)
+	case errors.Is(err, gamepayments.ErrNotPlayer):
+		app.badRequestResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+// runSendGamePaymentReminders pushes one nudge, the day after a priced
+// game, to each player who hasn't paid the admin.
+func (app *application) runSendGamePaymentReminders(ctx context.Context) error {
+	due, err := app.store.GamePayments.ClaimReminders(ctx, gamePaymentReminderBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, rm := range due {
+		if err := notifications.SendGamePaymentReminder(ctx, app.push, app.store, rm); err != nil {
+			app.logger.Warnw("failed to push game payment reminder", "game_id", rm.GameID, "user_id", rm.UserID, "error", err)
+		}
+	}
+	jobs.SetRowsAffected(ctx, int64(len(due)))
+	return nil
+}
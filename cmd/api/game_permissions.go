@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"khel/internal/domain/games"
+	"khel/internal/notifications"
+	"net/http"
+)
+
+type AssistantPermissionsPayload struct {
+	Permissions []games.Permission `json:"permissions" validate:"dive,oneof=accept_requests kick_players edit_details record_score"`
+}
+
+type RecordScorePayload struct {
+	TeamA *int `json:"team_a" validate:"required,min=0,max=999"`
+	TeamB *int `json:"team_b" validate:"required,min=0,max=999"`
+}
+
+// listGameAssistantsHandler godoc
+//
+//	@Summary		List the game's assistants and their permissions
+//	@Description	Only the game admin can see this. Permissions are accept_requests, kick_players, edit_details and record_score; the admin always has all of them.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{array}		games.AssistantPermissions
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/assistants [get]
+func (app *application) listGameAssistantsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	assistants, err := app.store.Games.ListAssistantPermissions(r.Context(), gameID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, assistants)
+}
+
+// setAssistantPermissionsHandler godoc
+//
+//	@Summary		Set what an assistant may do
+//	@Description	Replaces the assistant's permissions. Only the game admin can change them.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID		path		int							true	"Game ID"
+//	@Param			playerID	path		int							true	"Assistant's user ID"
+//	@Param			payload		body		AssistantPermissionsPayload	true	"Permissions"
+//	@Success		200			{object}	games.AssistantPermissions
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Assistant not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/assistants/{playerID}/permissions [put]
+func (app *application) setAssistantPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+	playerID, err := readIDParam(r, "playerID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid player ID"))
+		return
+	}
+
+	var payload AssistantPermissionsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Games.SetAssistantPermissions(r.Context(), gameID, playerID, payload.Permissions); err != nil {
+		switch {
+		case errors.Is(err, games.ErrUnknownPermission):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, games.ErrNotFound):
+			app.notFoundResponse(w, r, errors.New("assistant not found in this game"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	member, err := app.store.Games.GetMember(r.Context(), gameID, playerID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, member)
+}
+
+// kickGamePlayerHandler godoc
+//
+//	@Summary		Remove a player from a game
+//	@Description	Takes a player out of the game or off its bench; the freed seat goes to the next bench player. Needs the kick_players permission. Only the admin can remove an assistant, and the admin can't be removed.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID		path		int	true	"Game ID"
+//	@Param			playerID	path		int	true	"User ID to remove"
+//	@Success		200			{object}	map[string]string
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Player not in the game"
+//	@Failure		409			{object}	error	"Admin can't be removed"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/players/{playerID} [delete]
+func (app *application) kickGamePlayerHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+	playerID, err := readIDParam(r, "playerID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid player ID"))
+		return
+	}
+
+	actor := getGameMember(r)
+	if playerID == actor.UserID {
+		app.badRequestResponse(w, r, errors.New("use leave to remove yourself"))
+		return
+	}
+
+	// Bench players aren't members yet, so a missing member is fine here.
+	target, err := app.store.Games.GetMember(r.Context(), gameID, playerID)
+	if err != nil && !errors.Is(err, games.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if target != nil && target.Role == "assistant" && actor.Role != "admin" {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	promoted, err := app.store.Games.RemovePlayer(r.Context(), gameID, playerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, games.ErrNotFound):
+			app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+		case errors.Is(err, games.ErrNotInGame):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, games.ErrAdminCannotLeave):
+			app.conflictResponse(w, r, errors.New("the game admin can't be removed"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendRemovedFromGame(ctx, app.push, app.store, playerID, gameID)
+	}, "SendingRemovedFromGame")
+	if promoted != nil {
+		promotedID := *promoted
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendBenchPromotedToUser(ctx, app.push, app.store, promotedID, gameID)
+		}, "SendingBenchPromotedToUser")
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "Player removed from the game"})
+}
+
+// recordGameScoreHandler godoc
+//
+//	@Summary		Record the final score
+//	@Description	Saves the game's score once it has started; sending it again corrects it. Needs the record_score permission. The score shows up in the game's results.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int					true	"Game ID"
+//	@Param			payload	body		RecordScorePayload	true	"Score"
+//	@Success		200		{object}	games.GameScore
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		409		{object}	error	"Game not started or cancelled"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/score [put]
+func (app *application) recordGameScoreHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	var payload RecordScorePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	score, err := app.store.Games.RecordScore(r.Context(), gameID, user.ID, *payload.TeamA, *payload.TeamB)
+	if err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.conflictResponse(w, r, errors.New("the score can only be recorded once the game has started, and not for a cancelled game"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, score)
+}
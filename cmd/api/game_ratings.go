@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/domain/games"
+	"net/http"
+)
+
+type RatePlayersPayload struct {
+	Ratings []games.PlayerRating `json:"ratings" validate:"required,min=1,max=30,dive"`
+}
+
+type VoteMVPPayload struct {
+	UserID int64 `json:"user_id" validate:"required,gt=0"`
+}
+
+// ratePlayersHandler godoc
+//
+//	@Summary		Rate players after a game
+//	@Description	Players of a completed game can rate each other 1-5 until the rating window closes (48h after completion). Rating someone again replaces the earlier score.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int					true	"Game ID"
+//	@Param			payload	body		RatePlayersPayload	true	"Ratings"
+//	@Success		200		{object}	map[string]string	"Ratings saved"
+//	@Failure		400		{object}	error				"Bad Request"
+//	@Failure		404		{object}	error				"Game not found"
+//	@Failure		409		{object}	error				"Rating window is closed"
+//	@Failure		500		{object}	error				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/ratings [post]
+func (app *application) ratePlayersHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
+		return
+	}
+
+	var payload RatePlayersPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	for _, rt := range payload.Ratings {
+		if rt.UserID == user.ID {
+			app.badRequestResponse(w, r, errors.New("you cannot rate yourself"))
+			return
+		}
+	}
+
+	if err := app.store.Games.RatePlayers(r.Context(), gameID, user.ID, payload.Ratings); err != nil {
+		app.gameRatingError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "Ratings saved"})
+}
+
+// voteMVPHandler godoc
+//
+//	@Summary		Vote for the game MVP
+//	@Description	Each player of a completed game gets one MVP vote while the rating window is open. Voting again changes the pick.
+//	@Tags			Games
+//	@Accept			json
+//	@Produce		json
+//	@Param			gameID	path		int					true	"Game ID"
+//	@Param			payload	body		VoteMVPPayload		true	"Player to vote for"
+//	@Success		200		{object}	map[string]string	"Vote saved"
+//	@Failure		400		{object}	error				"Bad Request"
+//	@Failure		404		{object}	error				"Game not found"
+//	@Failure		409		{object}	error				"Rating window is closed"
+//	@Failure		500		{object}	error				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/mvp-vote [post]
+func (app *application) voteMVPHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
+		return
+	}
+
+	var payload VoteMVPPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	if payload.UserID == user.ID {
+		app.badRequestResponse(w, r, errors.New("you cannot vote for yourself"))
+		return
+	}
+
+	if err := app.store.Games.VoteMVP(r.Context(), gameID, user.ID, payload.UserID); err != nil {
+		app.gameRatingError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "Vote saved"})
+}
+
+// getGameResultsHandler godoc
+//
+//	@Summary		Get post-game ratings and MVP
+//	@Description	Average rating and MVP votes per player, best first. mvp_user_id is set once anyone has voted.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{object}	games.GameResults
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Game not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/games/{gameID}/results [get]
+func (app *application) getGameResultsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
+		return
+	}
+
+	res, err := app.store.Games.GetGameResults(r.Context(), gameID)
+	if err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, res)
+}
+
+func (app *application) gameRatingError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, games.ErrNotFound):
+		app.notFoundResponse(w, r, err)
+	case errors.Is(err, games.ErrRatingWindowClosed):
+		app.conflictResponse(w, r, err)
+	case errors.Is(err, games.ErrNotGamePlayer):
+		app.badRequestResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
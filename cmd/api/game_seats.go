@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"khel/internal/domain/games"
+	"net/http"
+	"time"
+)
+
+const (
+	// seatsStreamInterval is how often the stream re-reads the counts.
+	seatsStreamInterval = 3 * time.Second
+	// seatsStreamLifetime keeps each stream inside the router's 40s request
+	// timeout; EventSource reconnects on its own when it ends.
+	seatsStreamLifetime = 30 * time.Second
+)
+
+// getGameSeatsHandler godoc
+//
+//	@Summary		Get live seat counts for a game
+//	@Description	Player count, seats left, pending join requests and match_full, without the rest of the game details. Meant to be polled every few seconds from the game screen; see /games/{gameID}/seats/stream for a push variant.
+//	@Tags			Games
+//	@Produce		json
+//	@Param			gameID	path		int	true	"Game ID"
+//	@Success		200		{object}	games.Seats
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Game not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/games/{gameID}/seats [get]
+func (app *application) getGameSeatsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	seats, err := app.store.Games.GetSeats(r.Context(), gameID)
+	if err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("game not found"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	app.jsonResponse(w, http.StatusOK, seats)
+}
+
+// streamGameSeatsHandler godoc
+//
+//	@Summary		Stream live seat counts for a game
+//	@Description	Server-sent events: a "seats" event with games.Seats right away and again whenever the counts change. The stream closes after about 30 seconds and the client reconnects.
+//	@Tags			Games
+//	@Produce		text/event-stream
+//	@Param			gameID	path		int		true	"Game ID"
+//	@Success		200		{string}	string	"event stream"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Game not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/games/{gameID}/seats/stream [get]
+func (app *application) streamGameSeatsHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, err := readIDParam(r, "gameID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid game ID"))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		app.internalServerError(w, r, errors.New("streaming unsupported"))
+		return
+	}
+
+	ctx := r.Context()
+	seats, err := app.store.Games.GetSeats(ctx, gameID)
+	if err != nil {
+		if errors.Is(err, games.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("game not found"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+	// Stop nginx/Traefik-style proxies from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", seatsStreamInterval.Milliseconds())
+
+	last, err := json.Marshal(seats)
+	if err != nil {
+		return
+	}
+	if err := writeSeatsEvent(w, last); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(seatsStreamInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(seatsStreamLifetime)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			return
+		case <-ticker.C:
+			seats, err := app.store.Games.GetSeats(ctx, gameID)
+			if err != nil {
+				if ctx.Err() == nil {
+					app.requestLogger(r).Warnw("game seats stream read failed", "game_id", gameID, "error", err)
+				}
+				return
+			}
+
+			body, err := json.Marshal(seats)
+			if err != nil {
+				return
+			}
+			if bytes.Equal(body, last) {
+				// Comment line: keeps idle proxies from closing the stream.
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			} else {
+				if err := writeSeatsEvent(w, body); err != nil {
+					return
+				}
+				last = body
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSeatsEvent(w http.ResponseWriter, body []byte) error {
+	_, err := fmt.Fprintf(w, "event: seats\ndata: %s\n\n", body)
+	return err
+}
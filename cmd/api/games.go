@@ -3,11 +3,11 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"khel/internal/domain/games"
 	"khel/internal/notifications"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -27,6 +27,21 @@ type CreateGamePayload struct {
 	EndTime     time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
 	Visibility  string    `json:"visibility" validate:"required,oneof=public private"`
 	Instruction *string   `json:"instruction,omitempty" validate:"omitempty,max=500"`
+	// Auto-cancel: if fewer than MinPlayers have joined by AutoCancelAt the
+	// game is called off and BookingID, when set, is released.
+	MinPlayers   *int       `json:"min_players,omitempty" validate:"required_with=AutoCancelAt,omitempty,min=2"`
+	AutoCancelAt *time.Time `json:"auto_cancel_at,omitempty" validate:"required_with=MinPlayers"`
+	BookingID    *int64     `json:"booking_id,omitempty" validate:"omitempty,min=1"`
+	// BenchSize lets that many more players be accepted once the game is
+	// full; they move in when someone leaves.
+	BenchSize int `json:"bench_size,omitempty" validate:"min=0,max=20"`
+	// JoinPolicy is auto_accept, manual (the default) or invite_only.
+	JoinPolicy string `json:"join_policy,omitempty" validate:"omitempty,oneof=auto_accept manual invite_only"`
+	// SafetyInfo and the emergency contact are shown to players and in the
+	// start reminder. Without a contact the venue's phone is used.
+	SafetyInfo            *string `json:"safety_info,omitempty" validate:"omitempty,max=500"`
+	EmergencyContactName  *string `json:"emergency_contact_name,omitempty" validate:"omitempty,max=100"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone,omitempty" validate:"omitempty,max=20"`
 }
 
 // CreateGame godoc
@@ -58,9 +73,22 @@ func (app *application) createGameHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if err := validateAutoCancel(payload.MinPlayers, payload.AutoCancelAt, payload.MaxPlayers, payload.StartTime); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	// 2. Get the authenticated user
 	user := getUserFromContext(r)
 
+	// 3. A linked booking has to be the organizer's own slot for this game
+	if payload.BookingID != nil {
+		if err := app.checkGameBooking(r.Context(), user.ID, *payload.BookingID, payload.VenueID, payload.StartTime, payload.EndTime); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
 	// 4. Create the game
 	game := &games.Game{
 		SportType:     payload.SportType,
@@ -77,6 +105,15 @@ func (app *application) createGameHandler(w http.ResponseWriter, r *http.Request
 		Status:        "active",  // Default status
 		BookingStatus: "pending", // Default value
 		MatchFull:     false,     // Default value
+		MinPlayers:    payload.MinPlayers,
+		AutoCancelAt:  payload.AutoCancelAt,
+		BookingID:     payload.BookingID,
+		BenchSize:     payload.BenchSize,
+		JoinPolicy:    payload.JoinPolicy,
+
+		SafetyInfo:            payload.SafetyInfo,
+		EmergencyContactName:  payload.EmergencyContactName,
+		EmergencyContactPhone: payload.EmergencyContactPhone,
 	}
 
 	// 5. Save the game to the database
@@ -89,10 +126,12 @@ func (app *application) createGameHandler(w http.ResponseWriter, r *http.Request
 	// Put the admin in the game player
 	err = app.store.Games.InsertAdminInPlayer(r.Context(), gameID, user.ID)
 	if err != nil {
-		log.Println("🚨🚨🚨InsertAdminInPlayer failed:", err)
-		writeJSONError(w, http.StatusInternalServerError, "Failed to add player")
+		app.internalServerError(w, r, fmt.Errorf("insert admin in game players: %w", err))
 		return
 	}
+
+	app.enqueueSavedSearchMatch(gameID)
+
 	// 6. Return the created game as the response
 	if err := app.jsonResponse(w, http.StatusCreated, game); err != nil {
 
@@ -104,15 +143,15 @@ func (app *application) createGameHandler(w http.ResponseWriter, r *http.Request
 // CreateJoinRequest godoc
 //
 //	@Summary		Send a request to join a game
-//	@Description	Allows a user to send a request to join a specific game. The game ID is provided in the URL path.
+//	@Description	Allows a user to send a request to join a specific game. The game ID is provided in the URL path. Under the game's auto_accept policy the user is seated straight away while there is a free seat and joined is true; otherwise the request waits for approval. Invite-only games take no requests, and requests close once the game starts.
 //	@Tags			Games
 //	@Accept			json
 //	@Produce		json
-//	@Param			gameID	path		int					true	"Game ID"
-//	@Success		201		{object}	map[string]string	"Join request submitted for approval"
-//	@Failure		400		{object}	error				"Invalid game ID"
-//	@Failure		404		{object}	error				"Game not found or inactive"
-//	@Failure		409		{object}	error				"Join request already sent"
+//	@Param			gameID	path		int						true	"Game ID"
+//	@Success		201		{object}	map[string]interface{}	"Join request submitted for approval, or joined"
+//	@Failure		400		{object}	error					"Invalid game ID"
+//	@Failure		404		{object}	error					"Game not found or inactive"
+//	@Failure		409		{object}	error					"Join request already sent, already in the game, game is invite only or has started"
 //	@Failure		500		{object}	error				"Internal server error"
 //	@Security		ApiKeyAuth
 //	@Router			/games/{gameID}/request [post]
@@ -124,7 +163,7 @@ func (app *application) CreateJoinRequest(w http.ResponseWriter, r *http.Request
 	gameIDStr := chi.URLParam(r, "gameID")
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 
@@ -141,42 +180,87 @@ func (app *application) CreateJoinRequest(w http.ResponseWriter, r *http.Request
 	}
 	adminID := game.AdminID
 
+	blocked, err := app.store.Safety.IsBlocked(r.Context(), user.ID, adminID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if blocked {
+		app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+		return
+	}
+
+	if !time.Now().Before(game.StartTime) {
+		app.conflictResponse(w, r, games.ErrGameStarted)
+		return
+	}
+	if game.JoinPolicy == games.JoinPolicyInviteOnly {
+		app.conflictResponse(w, r, games.ErrInviteOnly)
+		return
+	}
+
 	// Check if a join request already exists
 	exists, err := app.store.Games.CheckRequestExist(r.Context(), gameID, user.ID)
 	if err != nil {
-		app.logger.Errorf("Error checking join request: %v", err)
-		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		app.internalServerError(w, r, fmt.Errorf("check join request: %w", err))
 		return
 	}
 
 	if exists {
-		writeJSONError(w, http.StatusConflict, "Already sent request to this game")
+		app.conflictResponse(w, r, errors.New("already sent request to this game"))
 		return // ✅ Fix: Stop execution after sending conflict response
 	}
 
+	// Seat the user straight away while there's room, if the game allows it
+	if game.JoinPolicy == games.JoinPolicyAutoAccept && !game.MatchFull {
+		joined, err := app.store.Games.JoinIfSeat(r.Context(), gameID, user.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, games.ErrAlreadyInGame):
+				app.conflictResponse(w, r, err)
+			case errors.Is(err, games.ErrNotFound):
+				app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+		if joined {
+			notifications.CallAsync(app.logger, func(ctx context.Context) error {
+				return notifications.SendPlayerJoinedToAdmin(ctx, app.push, app.store, adminID, gameID, userName)
+			}, "SendingPlayerJoinedToAdmin")
+
+			writeJSON(w, http.StatusCreated, map[string]interface{}{
+				"message": "Joined the game",
+				"joined":  true,
+			})
+			return
+		}
+	}
+
 	// Create the join request
 	err = app.store.Games.AddToGameRequest(r.Context(), gameID, user.ID)
 	if err != nil {
-		app.logger.Errorf("Error inserting join request: %v", err)
-		writeJSONError(w, http.StatusInternalServerError, "Failed to create request")
+		app.internalServerError(w, r, fmt.Errorf("insert join request: %w", err))
 		return
 	}
 
 	//that anonymous function is a closure because it refers to variable from the outer scope: app.push, app.store, adminID, gameID. The closure captures the variables it needs from the surrounding scope.
-	notifications.CallAsync(func(ctx context.Context) error {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
 		return notifications.SendJoinRequestToAdmin(ctx, app.push, app.store, adminID, gameID, userName)
 	}, "SendingJoinRequestToAdmin")
 
 	// Success response
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
 		"message": "Join request submitted for approval",
+		"joined":  false,
 	})
 }
 
 // AcceptJoinRequest godoc
 //
 //	@Summary		Accept a join request for a game
-//	@Description	Accepts a pending join request for a game by updating the request status to accepted and inserting the player into the game. The game ID is provided in the URL path and the user ID is provided in the request body.
+//	@Description	Accepts a pending join request for a game by updating the request status to accepted and inserting the player into the game. When the game is full the player goes on the bench instead, if the game has room there. The game ID is provided in the URL path and the user ID is provided in the request body.
 //	@Tags			Games
 //	@Accept			json
 //	@Produce		json
@@ -185,6 +269,7 @@ func (app *application) CreateJoinRequest(w http.ResponseWriter, r *http.Request
 //	@Success		200		{object}	map[string]interface{}	"Message confirming the join request acceptance and player addition"
 //	@Failure		400		{object}	error					"Invalid game ID, payload error, or request is not in pending state"
 //	@Failure		404		{object}	error					"Join request not found"
+//	@Failure		409		{object}	error					"Game and bench are full"
 //	@Failure		500		{object}	error					"Internal server error"
 //	@Security		ApiKeyAuth
 //	@Router			/games/{gameID}/accept [post]
@@ -194,7 +279,7 @@ func (app *application) AcceptJoinRequest(w http.ResponseWriter, r *http.Request
 	gameIDStr := chi.URLParam(r, "gameID")
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 
@@ -202,7 +287,7 @@ func (app *application) AcceptJoinRequest(w http.ResponseWriter, r *http.Request
 		UserID int64 `json:"user_id"`
 	}
 	if err := readJSON(w, r, &payload); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "cannot read the payload")
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
@@ -210,10 +295,10 @@ func (app *application) AcceptJoinRequest(w http.ResponseWriter, r *http.Request
 	req, err := app.store.Games.GetJoinRequest(r.Context(), gameID, payload.UserID)
 	if err != nil {
 		if errors.Is(err, games.ErrNotFound) {
-			writeJSONError(w, http.StatusNotFound, "Invalid request")
+			app.notFoundResponse(w, r, err)
 			return
 		}
-		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		app.internalServerError(w, r, err)
 		return
 	}
 	if req.Status != games.GameRequestStatusPending {
@@ -221,23 +306,43 @@ func (app *application) AcceptJoinRequest(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Seat the player, or bench them if the game is full
+	benchPosition, err := app.store.Games.AddPlayerOrBench(r.Context(), gameID, payload.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, games.ErrGameFull), errors.Is(err, games.ErrAlreadyInGame):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, games.ErrNotFound):
+			app.notFoundResponse(w, r, errors.New("game not found or is inactive"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
 	// Update request status
 	err = app.store.Games.UpdateRequestStatus(r.Context(), gameID, payload.UserID, games.GameRequestStatusAccepted)
 	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "Failed to update request")
+		app.internalServerError(w, r, err)
 		return
 	}
 
-	notifications.CallAsync(func(ctx context.Context) error {
-		return notifications.SendAcceptJoinRequestToUser(ctx, app.push, app.store, req.UserID, gameID)
-	}, "SendingAcceptJoinRequestToUser")
+	if benchPosition > 0 {
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendBenchedToUser(ctx, app.push, app.store, req.UserID, gameID, benchPosition)
+		}, "SendingBenchedToUser")
 
-	err = app.store.Games.InsertNewPlayer(r.Context(), gameID, payload.UserID)
-	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "Failed to add player")
+		app.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"message":        fmt.Sprintf("Game is full, userID: %d is on the bench of gameID: %d", req.UserID, req.GameID),
+			"bench_position": benchPosition,
+		})
 		return
 	}
 
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendAcceptJoinRequestToUser(ctx, app.push, app.store, req.UserID, gameID)
+	}, "SendingAcceptJoinRequestToUser")
+
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
 		"message": fmt.Sprintf("Successfully added userID: %d to the gameID: %d ✅", req.UserID, req.GameID),
 	})
@@ -262,7 +367,7 @@ func (app *application) DeleteJoinRequest(w http.ResponseWriter, r *http.Request
 	gameIDStr := chi.URLParam(r, "gameID")
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 
@@ -273,12 +378,11 @@ func (app *application) DeleteJoinRequest(w http.ResponseWriter, r *http.Request
 			app.notFoundResponse(w, r, fmt.Errorf("no join request found for game_id=%d", gameID))
 			return
 		}
-		app.logger.Errorf("Error deleting join request: %v", err)
-		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		app.internalServerError(w, r, fmt.Errorf("delete join request: %w", err))
 		return
 	}
 
-	notifications.CallAsync(func(ctx context.Context) error {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
 		return notifications.SendDeleteJoinRequestToAdmin(ctx, app.push, app.store, gameID, user.FirstName)
 	}, "SendingDeleteJoinRequestToAdmin")
 
@@ -367,7 +471,7 @@ func (app *application) RejectJoinRequest(w http.ResponseWriter, r *http.Request
 	gameIDStr := chi.URLParam(r, "gameID")
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 
@@ -376,7 +480,7 @@ func (app *application) RejectJoinRequest(w http.ResponseWriter, r *http.Request
 		UserID int64 `json:"user_id"`
 	}
 	if err := readJSON(w, r, &payload); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Cannot read the payload")
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
@@ -384,10 +488,10 @@ func (app *application) RejectJoinRequest(w http.ResponseWriter, r *http.Request
 	req, err := app.store.Games.GetJoinRequest(r.Context(), gameID, payload.UserID)
 	if err != nil {
 		if errors.Is(err, games.ErrNotFound) {
-			writeJSONError(w, http.StatusNotFound, "Invalid request")
+			app.notFoundResponse(w, r, err)
 			return
 		}
-		writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+		app.internalServerError(w, r, err)
 		return
 	}
 
@@ -400,11 +504,11 @@ func (app *application) RejectJoinRequest(w http.ResponseWriter, r *http.Request
 	// Update request status to rejected
 	err = app.store.Games.UpdateRequestStatus(r.Context(), gameID, payload.UserID, games.GameRequestStatusRejected)
 	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "Failed to update request status")
+		app.internalServerError(w, r, err)
 		return
 	}
 
-	notifications.CallAsync(func(ctx context.Context) error {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
 		return notifications.SendRejectJoinRequestToUser(ctx, app.push, app.store, payload.UserID, gameID)
 	}, "SendingRejectJoinRequestToUser")
 
@@ -436,13 +540,13 @@ func (app *application) AssignAssistantHandler(w http.ResponseWriter, r *http.Re
 
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid game ID")
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 
 	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid player ID")
+		app.badRequestResponse(w, r, fmt.Errorf("invalid player ID"))
 		return
 	}
 
@@ -450,30 +554,69 @@ func (app *application) AssignAssistantHandler(w http.ResponseWriter, r *http.Re
 	err = app.store.Games.AssignAssistant(r.Context(), gameID, playerID)
 	if err != nil {
 		if err.Error() == "only game admins can assign assistants" {
-			writeJSONError(w, http.StatusForbidden, err.Error())
+			app.forbiddenResponse(w, r)
 			return
 		}
 		if err.Error() == "player not found or already an assistant" {
-			writeJSONError(w, http.StatusBadRequest, err.Error())
+			app.badRequestResponse(w, r, err)
 			return
 		}
-		app.logger.Errorf("Error assigning assistant: %v", err)
-		writeJSONError(w, http.StatusInternalServerError, "Database error")
+		app.internalServerError(w, r, fmt.Errorf("assign assistant: %w", err))
 		return
 	}
 
 	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "Assistant role assigned successfully"})
 }
 
+// defaultGameFilter is the game list before any query parameters: the next
+// ten upcoming games, soonest first.
+func defaultGameFilter() games.GameFilterQuery {
+	return games.GameFilterQuery{
+		Limit:      10,
+		Offset:     0,
+		Sort:       "asc",
+		Radius:     0,
+		StartAfter: time.Now(),
+	}
+}
+
+// markShortlisted flags the games the caller has shortlisted. Anonymous
+// callers get the list as is.
+func (app *application) markShortlisted(r *http.Request, gameList []games.GameSummary) error {
+	user := getUserFromContext(r) // Can be nil
+	if user == nil {
+		return nil
+	}
+
+	shortlistedGames, err := app.store.Games.GetShortlistedGamesByUser(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	shortlistedIDs := make(map[int64]struct{}, len(shortlistedGames))
+	for _, sg := range shortlistedGames {
+		shortlistedIDs[sg.ID] = struct{}{}
+	}
+
+	for i, game := range gameList {
+		if _, found := shortlistedIDs[game.GameID]; found {
+			gameList[i].Shortlisted = true
+		}
+	}
+	return nil
+}
+
 // GetGames godoc
 //
 //	@Summary		Retrieve a list of games
 //
 //	@Description	Returns a list of games. Authentication is optional; if an API key is provided, user's shortlisted games will be included.
+//	@Description	format=geojson returns a bare GeoJSON FeatureCollection (no data envelope) with one point per game at its venue, for use as a Mapbox source.
+//	@Description	Deprecated in favour of /v2/games, which returns the same games with pagination; see the Deprecation and Sunset headers.
 //
 //	@Tags			Games
 //	@Accept			json
 //	@Produce		json
+//	@Produce		application/geo+json
 //	@Param			sport_type		query		string				false	"Sport type to filter games (e.g., basketball)"
 //	@Param			game_level		query		string				false	"Game level (e.g., intermediate)"
 //	@Param			venue_id		query		int					false	"Venue ID to filter games"
@@ -491,58 +634,39 @@ func (app *application) AssignAssistantHandler(w http.ResponseWriter, r *http.Re
 //	@Param			limit			query		int					false	"Maximum number of results to return"
 //	@Param			offset			query		int					false	"Pagination offset"
 //	@Param			sort			query		string				false	"Sort order, either 'asc' or 'desc'"
-//	@Success		200				{object}	[]games.GameSummary	"List of games and GeoJSON features"
+//	@Param			format			query		string				false	"Response format"	Enums(json,geojson)	default(json)
+//	@Success		200				{object}	[]games.GameSummary	"List of games, or a games.FeatureCollection for format=geojson"
 //	@Failure		400				{object}	error				"Invalid request parameters"
 //	@Failure		500				{object}	error				"Internal server error"
+//	@Deprecated
 //	@Router			/games/get-games [get]
 func (app *application) getGamesHandler(w http.ResponseWriter, r *http.Request) {
-	fq := games.GameFilterQuery{
-		Limit:      10,
-		Offset:     0,
-		Sort:       "asc",
-		Radius:     0,
-		StartAfter: time.Now(),
-	}
-
-	fq, err := fq.Parse(r)
-	if err != nil {
+	fq := defaultGameFilter()
+	if err := readQuery(r, &fq); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
-
-	if err := Validate.Struct(fq); err != nil {
-		app.badRequestResponse(w, r, err)
-		return
+	if user := getUserFromContext(r); user != nil {
+		fq.ViewerID = user.ID
 	}
 
-	user := getUserFromContext(r) // Can be nil
-
-	gameList, err := app.store.Games.GetGames(r.Context(), fq)
+	gameList, _, err := app.store.Games.GetGames(r.Context(), fq)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
-
-	// Prepare shortlisted map
-	shortlistedIDs := make(map[int64]struct{})
-
-	if user != nil { // Only fetch if authenticated
-		shortlistedGames, err := app.store.Games.GetShortlistedGamesByUser(r.Context(), user.ID)
-		if err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
-
-		for _, sg := range shortlistedGames {
-			shortlistedIDs[sg.ID] = struct{}{}
-		}
+	if err := app.markShortlisted(r, gameList); err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 
-	// Mark shortlisted
-	for i, game := range gameList {
-		if _, found := shortlistedIDs[game.GameID]; found {
-			gameList[i].Shortlisted = true
+	if r.URL.Query().Get("format") == "geojson" {
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(games.ToFeatureCollection(gameList)); err != nil {
+			app.logger.Errorw("failed to write games geojson", "error", err)
 		}
+		return
 	}
 
 	response := make([]games.GameSummary, len(gameList))
@@ -572,7 +696,7 @@ func (app *application) toggleMatchFullHandler(w http.ResponseWriter, r *http.Re
 	gameIDStr := chi.URLParam(r, "gameID")
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid game ID")
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 	// Toggle match full status
@@ -607,7 +731,7 @@ func (app *application) cancelGameHandler(w http.ResponseWriter, r *http.Request
 	gameIDStr := chi.URLParam(r, "gameID")
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid game ID")
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 
@@ -621,7 +745,7 @@ func (app *application) cancelGameHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	notifications.CallAsync(func(ctx context.Context) error {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
 		return notifications.SendCancelGameToPlayers(ctx, app.push, app.store, gameID)
 	}, "SendingCancelGameNotificationToAllPlayers")
 
@@ -631,12 +755,12 @@ func (app *application) cancelGameHandler(w http.ResponseWriter, r *http.Request
 // GetAllGameJoinRequests godoc
 //
 //	@Summary		Get all join requests for a game
-//	@Description	Retrieve all join requests for a specific game by game ID, including user details.
+//	@Description	Retrieve all join requests for a specific game by game ID, including user details and how reliably each player turns up.
 //	@Tags			Games
 //	@Accept			json
 //	@Produce		json
 //	@Param			gameID	path		int							true	"Game ID"
-//	@Success		200		{array}		games.GameRequestWithUser	"List of join requests with user details"
+//	@Success		200		{array}		JoinRequestResponse	"List of join requests with user details"
 //	@Failure		400		{object}	error						"Invalid game ID"
 //	@Failure		500		{object}	error						"Internal server error"
 //	@Security		ApiKeyAuth
@@ -646,7 +770,7 @@ func (app *application) getAllGameJoinRequestsHandler(w http.ResponseWriter, r *
 	gameIDStr := chi.URLParam(r, "gameID")
 	gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "Invalid game ID")
+		app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 		return
 	}
 
@@ -657,8 +781,40 @@ func (app *application) getAllGameJoinRequestsHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	userIDs := make([]int64, len(requests))
+	for i, req := range requests {
+		userIDs[i] = req.UserID
+	}
+
+	// Leave out anyone the viewer has a block with
+	blocked, err := app.store.Safety.BlockedAmong(r.Context(), getUserFromContext(r).ID, userIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if len(blocked) > 0 {
+		visible := requests[:0]
+		userIDs = userIDs[:0]
+		for _, req := range requests {
+			if !blocked[req.UserID] {
+				visible = append(visible, req)
+				userIDs = append(userIDs, req.UserID)
+			}
+		}
+		requests = visible
+	}
+	scores, err := app.store.CheckIns.Reliability(r.Context(), userIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	resp := make([]JoinRequestResponse, len(requests))
+	for i, req := range requests {
+		resp[i] = JoinRequestResponse{GameRequestWithUser: req, Reliability: scores[req.UserID]}
+	}
+
 	// Respond with the join requests
-	if err := app.jsonResponse(w, http.StatusOK, requests); err != nil {
+	if err := app.jsonResponse(w, http.StatusOK, resp); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -698,6 +854,19 @@ func (app *application) getGameDetailsHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// A game run by someone the viewer has a block with doesn't exist for them
+	if user := getUserFromContext(r); user != nil && user.ID != game.AdminID {
+		blocked, err := app.store.Safety.IsBlocked(r.Context(), user.ID, game.AdminID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if blocked {
+			app.notFoundResponse(w, r, errors.New("game not found"))
+			return
+		}
+	}
+
 	// Return JSON response
 	if err := app.jsonResponse(w, http.StatusOK, game); err != nil {
 		app.internalServerError(w, r, err)
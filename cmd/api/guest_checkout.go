@@ -0,0 +1,518 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/facilities"
+	"khel/internal/domain/guests"
+	"khel/internal/domain/venues"
+	"khel/internal/jobs"
+	"khel/internal/mailer"
+	"khel/internal/notifications"
+	"khel/internal/payments"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// guestDepositExpiryBatch caps how many unpaid guest bookings one run of
+// the expiry job cancels.
+const guestDepositExpiryBatch = 200
+
+// hashGuestSecret hashes an OTP or checkout token for storage; only the
+// guest ever sees the plain value.
+func hashGuestSecret(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, ":")))
+	return hex.EncodeToString(sum[:])
+}
+
+type GuestOTPPayload struct {
+	Phone          string `json:"phone" validate:"required,len=10,numeric"`
+	TurnstileToken string `json:"cf_turnstile_response"`
+}
+
+// requestGuestOTPHandler godoc
+//
+//	@Summary		Text a guest a booking code
+//	@Description	Public route for the venue widget. Texts a 6-digit code to the phone; it is good for 5 minutes and 5 guesses. A phone gets at most one code a minute. Protected by Turnstile and a strict per-IP rate limit.
+//	@Tags			Widget
+//	@Accept			json
+//	@Param			venueID	path	int				true	"Venue ID"
+//	@Param			payload	body	GuestOTPPayload	true	"Phone"
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse	"Bad Request"
+//	@Failure		404	{object}	ErrorResponse	"Venue not found"
+//	@Failure		429	{object}	ErrorResponse	"Too many requests"
+//	@Failure		500	{object}	ErrorResponse	"Internal Server Error"
+//	@Router			/widget/venues/{venueID}/guest/otp [post]
+func (app *application) requestGuestOTPHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid venue ID"))
+		return
+	}
+
+	var payload GuestOTPPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Phone = strings.TrimSpace(payload.Phone)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if app.config.env == "production" {
+		if _, err := app.verifyTurnstile(r.Context(), payload.TurnstileToken, clientIP(r)); err != nil {
+			app.badRequestResponse(w, r, errInvalidRequest("invalid verification"))
+			return
+		}
+	}
+
+	if _, err := app.store.Venues.GetVenueByID(r.Context(), venueID); err != nil {
+		if errors.Is(err, venues.ErrVenueNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	if err := app.store.Guests.CreateOTP(r.Context(), payload.Phone, hashGuestSecret(payload.Phone, code)); err != nil {
+		if errors.Is(err, guests.ErrOTPTooSoon) {
+			app.rateLimitExceededResponse(w, r, guests.OTPResendAfter.String())
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	body := fmt.Sprintf("%s is your Khel booking code. It expires in %d minutes.", code, int(guests.OTPTTL.Minutes()))
+	if err := app.sms.Send(r.Context(), payload.Phone, body); err != nil {
+		app.internalServerError(w, r, fmt.Errorf("send guest otp: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type CreateGuestBookingPayload struct {
+	FirstName string    `json:"first_name" validate:"required,max=40"`
+	LastName  string    `json:"last_name" validate:"required,max=40"`
+	Phone     string    `json:"phone" validate:"required,len=10,numeric"`
+	Code      string    `json:"code" validate:"required,len=6,numeric"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required"`
+}
+
+type GuestBookingResponse struct {
+	Booking BookingResponse `json:"booking"`
+	Deposit *guests.Deposit `json:"deposit"`
+	// CheckoutToken pays and tracks the deposit. It is only shown once.
+	CheckoutToken string `json:"checkout_token"`
+}
+
+// createGuestBookingHandler godoc
+//
+//	@Summary		Book a facility as a guest
+//	@Description	Public route for the venue widget. Checks the texted code, then requests the booking like a signed-in player would, under a guest user for the phone. The booking stays pending, and is never auto-confirmed, until the deposit (the venue's guest_deposit_percent of the price) is paid with the checkout token; unpaid after 15 minutes it is canceled. A phone can hold one upcoming guest booking at a time. Registering later with the same phone keeps the booking on the new account.
+//	@Tags			Widget
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID		path		int							true	"Venue ID"
+//	@Param			facilityID	path		int							true	"Facility ID"
+//	@Param			payload		body		CreateGuestBookingPayload	true	"Guest and slot"
+//	@Success		201			{object}	GuestBookingResponse
+//	@Failure		400			{object}	ErrorResponse	"Bad Request or wrong code"
+//	@Failure		404			{object}	ErrorResponse	"Facility not found"
+//	@Failure		409			{object}	ErrorResponse	"Slot taken, phone has an account or an open guest booking"
+//	@Failure		429			{object}	ErrorResponse	"Too many requests"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Router			/widget/venues/{venueID}/facilities/{facilityID}/guest-bookings [post]
+func (app *application) createGuestBookingHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, facilityID, err := app.parseVenueAndFacilityID(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.requireFacilityBelongsToVenue(r.Context(), venueID, facilityID); err != nil {
+		if errors.Is(err, facilities.ErrFacilityNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var payload CreateGuestBookingPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.FirstName = strings.TrimSpace(payload.FirstName)
+	payload.LastName = strings.TrimSpace(payload.LastName)
+	payload.Phone = strings.TrimSpace(payload.Phone)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := validateBookingTimeRange(payload.StartTime, payload.EndTime); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	rules, err := app.store.BookingRules.Get(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := rules.CheckBooking(payload.StartTime, payload.EndTime, time.Now()); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	totalPrice, err := app.calculateFacilityBookingPrice(r, venueID, facilityID, payload.StartTime, payload.EndTime)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.ensureFacilityTimeIsAvailable(r, venueID, facilityID, payload.StartTime, payload.EndTime); err != nil {
+		app.conflictResponse(w, r, err)
+		return
+	}
+
+	// The code is checked last so a taken slot doesn't use it up.
+	if err := app.store.Guests.VerifyOTP(r.Context(), payload.Phone, hashGuestSecret(payload.Phone, payload.Code)); err != nil {
+		if errors.Is(err, guests.ErrOTPInvalid) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	guestID, err := app.store.Guests.UpsertGuest(r.Context(), payload.Phone, payload.FirstName, payload.LastName)
+	if err != nil {
+		if errors.Is(err, guests.ErrPhoneRegistered) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	checkoutToken := hex.EncodeToString(token)
+
+	booking := &bookings.Booking{
+		VenueID:    venueID,
+		FacilityID: facilityID,
+		UserID:     guestID,
+		StartTime:  payload.StartTime,
+		EndTime:    payload.EndTime,
+		TotalPrice: totalPrice,
+		// Guests always wait for the venue, whatever auto_confirm says.
+		Status: "pending",
+	}
+	deposit := &guests.Deposit{Amount: rules.GuestDeposit(totalPrice)}
+	if err := app.store.Guests.CreateBooking(r.Context(), booking, deposit, hashGuestSecret(checkoutToken)); err != nil {
+		if errors.Is(err, guests.ErrOpenBooking) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, GuestBookingResponse{
+		Booking:       app.bookingToResponse(booking),
+		Deposit:       deposit,
+		CheckoutToken: checkoutToken,
+	})
+}
+
+// loadGuestDeposit finds the deposit and booking for the checkout token in
+// the URL, writing the error response when it can't.
+func (app *application) loadGuestDeposit(w http.ResponseWriter, r *http.Request) (*guests.Deposit, *bookings.Booking, bool) {
+	token := chi.URLParam(r, "token")
+	deposit, err := app.store.Guests.GetDeposit(r.Context(), hashGuestSecret(token))
+	if err != nil {
+		if errors.Is(err, guests.ErrDepositNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, nil, false
+	}
+	booking, err := app.store.Bookings.GetBookingByID(r.Context(), deposit.BookingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return nil, nil, false
+	}
+	return deposit, booking, true
+}
+
+type GuestDepositResponse struct {
+	Booking BookingResponse `json:"booking"`
+	Deposit *guests.Deposit `json:"deposit"`
+}
+
+// getGuestDepositHandler godoc
+//
+//	@Summary		Get a guest booking's deposit
+//	@Description	Public route for the venue widget, keyed by the checkout token from booking. Shows the deposit and where the booking stands.
+//	@Tags			Widget
+//	@Produce		json
+//	@Param			token	path		string	true	"Checkout token"
+//	@Success		200		{object}	GuestDepositResponse
+//	@Failure		404		{object}	ErrorResponse	"Deposit not found"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Router			/widget/deposits/{token} [get]
+func (app *application) getGuestDepositHandler(w http.ResponseWriter, r *http.Request) {
+	deposit, booking, ok := app.loadGuestDeposit(w, r)
+	if !ok {
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, GuestDepositResponse{
+		Booking: app.bookingToResponse(booking),
+		Deposit: deposit,
+	})
+}
+
+// payGuestDepositHandler godoc
+//
+//	@Summary		Pay a guest booking's deposit
+//	@Description	Public route for the venue widget. Starts an online payment for the deposit and returns the gateway link (payment_url, plus form fields for eSewa). After the gateway returns, call the verify endpoint with the gateway data.
+//	@Tags			Widget
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	path		string			true	"Checkout token"
+//	@Param			payload	body		PayShareRequest	true	"Gateway"
+//	@Success		200		{object}	map[string]any
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		404		{object}	ErrorResponse	"Deposit not found"
+//	@Failure		409		{object}	ErrorResponse	"Deposit already paid, expired or booking closed"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Router			/widget/deposits/{token}/pay [post]
+func (app *application) payGuestDepositHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	var payload PayShareRequest
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Method = strings.ToLower(strings.TrimSpace(payload.Method))
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	deposit, booking, ok := app.loadGuestDeposit(w, r)
+	if !ok {
+		return
+	}
+	switch {
+	case deposit.Status == guests.DepositPaid:
+		app.conflictResponse(w, r, guests.ErrDepositPaid)
+		return
+	case deposit.Status != guests.DepositPending, !deposit.ExpiresAt.After(time.Now()),
+		booking.Status != "pending" && booking.Status != "confirmed":
+		app.conflictResponse(w, r, guests.ErrDepositNotActive)
+		return
+	}
+
+	guest, err := app.store.Users.GetByID(ctx, deposit.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	resp, err := app.payments.InitiatePayment(ctx, payload.Method, payments.PaymentRequest{
+		Amount:        float64(deposit.Amount),
+		TransactionID: fmt.Sprintf("guest-deposit-%d", deposit.BookingID),
+		ProductName:   fmt.Sprintf("Booking deposit %s", app.EncodeBookingID(deposit.BookingID)),
+		CustomerName:  strings.TrimSpace(guest.FirstName + " " + guest.LastName),
+		CustomerPhone: guest.Phone,
+	})
+	if err != nil {
+		app.internalServerError(w, r, fmt.Errorf("payment init: %w", err))
+		return
+	}
+
+	ref := resp.Data["pidx"]
+	if payload.Method == "esewa" {
+		ref = resp.Data["transaction_uuid"]
+	}
+	if err := app.store.Guests.SetDepositProvider(ctx, deposit.BookingID, payload.Method, ref); err != nil {
+		if errors.Is(err, guests.ErrDepositNotActive) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"amount":       deposit.Amount,
+		"payment_url":  resp.PaymentURL,
+		"payment_data": resp.Data,
+	})
+}
+
+// verifyGuestDepositHandler godoc
+//
+//	@Summary		Verify a guest deposit payment
+//	@Description	Public route for the venue widget. Re-checks the deposit's latest payment with the gateway, using the reference stored when it started; a request body is ignored. A completed payment marks the deposit paid, provided the gateway reports that same payment for the deposit's amount, and the venue is asked to answer the request. Money that arrives after the booking was canceled or rejected is refunded in full. Pending gateway states leave the deposit unpaid so the call can be retried.
+//	@Tags			Widget
+//	@Produce		json
+//	@Param			token	path		string	true	"Checkout token"
+//	@Success		200		{object}	map[string]any
+//	@Failure		400		{object}	ErrorResponse	"Bad Request or payment doesn't match the deposit"
+//	@Failure		404		{object}	ErrorResponse	"Deposit not found"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Router			/widget/deposits/{token}/verify [post]
+func (app *application) verifyGuestDepositHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	deposit, booking, ok := app.loadGuestDeposit(w, r)
+	if !ok {
+		return
+	}
+	if deposit.Status == guests.DepositPaid {
+		app.jsonResponse(w, http.StatusOK, map[string]any{"success": true, "idempotent": true})
+		return
+	}
+	if deposit.Provider == nil || deposit.ProviderRef == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("no payment started for this deposit"))
+		return
+	}
+
+	ver, err := app.verifyStoredPayment(ctx, *deposit.Provider, *deposit.ProviderRef, deposit.Amount)
+	if err != nil {
+		if errors.Is(err, errPaymentMismatch) {
+			app.requestLogger(r).Warnw("deposit payment doesn't match the deposit", "booking_id", deposit.BookingID,
+				"ref", ver.ProviderRef, "amount", ver.Amount, "error", err)
+		}
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if !ver.Success {
+		app.jsonResponse(w, http.StatusOK, map[string]any{
+			"success":  false,
+			"terminal": ver.Terminal,
+			"state":    ver.State,
+		})
+		return
+	}
+
+	marked, err := app.store.Guests.MarkDepositPaid(ctx, deposit.BookingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !marked {
+		app.jsonResponse(w, http.StatusOK, map[string]any{"success": true, "idempotent": true})
+		return
+	}
+
+	if booking.Status != "pending" && booking.Status != "confirmed" {
+		// Paid after the hold ran out or the venue said no.
+		if err := app.refundRejectedBooking(ctx, booking, "Deposit arrived after the booking was closed"); err != nil {
+			app.logger.Errorw("failed to file refund for late deposit", "booking_id", booking.ID, "error", err)
+		}
+		app.jsonResponse(w, http.StatusOK, map[string]any{"success": true, "refunded": true})
+		return
+	}
+	if booking.Status == "pending" {
+		app.notifyGuestBookingRequested(booking)
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{"success": true})
+}
+
+// notifyGuestBookingRequested tells the venue owner a guest's booking
+// request is ready to answer now that its deposit is paid.
+func (app *application) notifyGuestBookingRequested(b *bookings.Booking) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		ownerID, err := app.store.Venues.GetOwnerIDFromVenueID(ctx, b.VenueID)
+		if err != nil {
+			app.logger.Errorw("failed to load venue owner for guest booking", "venue_id", b.VenueID, "error", err)
+			return
+		}
+		if err := notifications.SendBookingNotification(ctx, app.push, app.store, ownerID, notifications.BookingCreated, app.EncodeBookingID(b.ID)); err != nil {
+			app.logger.Errorw("failed to notify owner of guest booking", "booking_id", b.ID, "error", err)
+		}
+	}()
+}
+
+// textGuestBookingDecision texts a guest, who has no inbox, push token or
+// real email, that the venue accepted or rejected their booking.
+func (app *application) textGuestBookingDecision(ctx context.Context, phone, venueName, when, template string) {
+	if app.sms == nil {
+		return
+	}
+	body := fmt.Sprintf("Your booking at %s on %s is confirmed.", venueName, when)
+	if template == mailer.BookingRejectionTemplate {
+		body = fmt.Sprintf("Sorry, %s couldn't take your booking on %s. Anything you paid online will be refunded.", venueName, when)
+	}
+	if err := app.sms.Send(ctx, phone, body); err != nil {
+		app.logger.Errorw("failed to text guest booking decision", "error", err)
+	}
+}
+
+// runExpireGuestDeposits cancels guest bookings whose deposit wasn't paid
+// within the hold window, frees their slots and texts the guests.
+func (app *application) runExpireGuestDeposits(ctx context.Context) error {
+	expired, err := app.store.Guests.ExpireUnpaid(ctx, guestDepositExpiryBatch)
+	if err != nil {
+		return err
+	}
+	jobs.SetRowsAffected(ctx, int64(len(expired)))
+
+	for _, e := range expired {
+		booking, err := app.store.Bookings.GetBookingByID(ctx, e.BookingID)
+		if err != nil {
+			app.logger.Errorw("failed to load expired guest booking", "booking_id", e.BookingID, "error", err)
+			continue
+		}
+		app.publishBookingReleased(booking, "canceled")
+
+		if app.sms == nil {
+			continue
+		}
+		body := fmt.Sprintf("Your Khel booking %s was canceled because the deposit wasn't paid within %d minutes.",
+			app.EncodeBookingID(e.BookingID), int(guests.HoldWindow.Minutes()))
+		if err := app.sms.Send(ctx, e.Phone, body); err != nil {
+			app.logger.Warnw("failed to text guest about expired deposit", "booking_id", e.BookingID, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/guests"
+	"khel/internal/domain/storage"
+	"khel/internal/payments"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestVerifyGuestDepositHandler(t *testing.T) {
+	const (
+		bookingID = int64(40)
+		token     = "checkout-token"
+	)
+
+	tests := []struct {
+		name       string
+		verify     payments.PaymentVerifyResponse
+		wantStatus int
+		wantPaid   bool
+	}{
+		{
+			name:       "gateway confirms the started payment",
+			verify:     payments.PaymentVerifyResponse{Success: true, ProviderRef: "pidx-1", Amount: 250},
+			wantStatus: http.StatusOK,
+			wantPaid:   true,
+		},
+		{
+			name:       "gateway confirms another payment",
+			verify:     payments.PaymentVerifyResponse{Success: true, ProviderRef: "pidx-2", Amount: 250},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "gateway confirms another amount",
+			verify:     payments.PaymentVerifyResponse{Success: true, ProviderRef: "pidx-1", Amount: 1},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, ref := "khalti", "pidx-1"
+			// Confirmed by the owner before the deposit came in, so paying
+			// it doesn't ask the venue again.
+			booking := bookings.Booking{ID: bookingID, Status: "confirmed", StartTime: time.Now().Add(24 * time.Hour)}
+			gs := &fakeGuests{deposits: map[string]*guests.Deposit{
+				hashGuestSecret(token): {BookingID: bookingID, Amount: 250, Status: guests.DepositPending,
+					Provider: &provider, ProviderRef: &ref, ExpiresAt: time.Now().Add(time.Minute)},
+			}}
+			pay := &fakePayments{verify: tt.verify}
+			app := newTestApplication(&storage.Container{
+				Bookings: &fakeBookings{byID: map[int64]*bookings.Booking{bookingID: &booking}},
+				Guests:   gs,
+			}, pay)
+
+			// The widget's gateway data names someone else's payment; it
+			// must not be what gets checked.
+			body := `{"data":{"pidx":"pidx-2"}}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/widget/deposits/"+token+"/verify", strings.NewReader(body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("token", token)
+			rec := httptest.NewRecorder()
+
+			app.verifyGuestDepositHandler(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if len(pay.verified) != 1 || pay.verified[0].TransactionID != ref || pay.verified[0].Data["pidx"] != ref {
+				t.Errorf("gateway checks = %+v, want one for %s", pay.verified, ref)
+			}
+			if paid := len(gs.paid) > 0; paid != tt.wantPaid {
+				t.Errorf("deposit marked paid = %v, want %v", paid, tt.wantPaid)
+			}
+		})
+	}
+}
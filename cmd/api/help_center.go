@@ -0,0 +1,573 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/helpcenter"
+	"khel/internal/params"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CreateHelpCategoryPayload struct {
+	Name        string  `json:"name" validate:"required,max=80"`
+	Slug        string  `json:"slug,omitempty"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	Position    int     `json:"position"`
+}
+
+type UpdateHelpCategoryPayload struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,max=80"`
+	Slug        *string `json:"slug,omitempty"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	Position    *int    `json:"position,omitempty"`
+}
+
+type CreateHelpArticlePayload struct {
+	CategoryID   int64  `json:"category_id" validate:"required,gt=0"`
+	Title        string `json:"title" validate:"required,max=200"`
+	Slug         string `json:"slug,omitempty"`
+	BodyMarkdown string `json:"body_markdown" validate:"required"`
+	IsPublished  bool   `json:"is_published"`
+	Position     int    `json:"position"`
+}
+
+type UpdateHelpArticlePayload struct {
+	CategoryID   *int64  `json:"category_id,omitempty" validate:"omitempty,gt=0"`
+	Title        *string `json:"title,omitempty" validate:"omitempty,max=200"`
+	Slug         *string `json:"slug,omitempty"`
+	BodyMarkdown *string `json:"body_markdown,omitempty"`
+	IsPublished  *bool   `json:"is_published,omitempty"`
+	Position     *int    `json:"position,omitempty"`
+}
+
+// ---------- Public ----------
+
+// listHelpCategoriesHandler godoc
+//
+//	@Summary		List help categories
+//	@Description	Categories that have at least one published article, in display order.
+//	@Tags			Help
+//	@Produce		json
+//	@Success		200	{array}		helpcenter.Category
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/help/categories [get]
+func (app *application) listHelpCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	all, err := app.store.HelpCenter.ListCategories(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	list := make([]helpcenter.Category, 0, len(all))
+	for _, c := range all {
+		if c.ArticleCount > 0 {
+			list = append(list, c)
+		}
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// listHelpArticlesHandler godoc
+//
+//	@Summary		Search help articles
+//	@Description	Lists published articles. With q, results are full-text matched on title and body and ranked by relevance.
+//	@Tags			Help
+//	@Produce		json
+//	@Param			q			query		string			false	"Search text"
+//	@Param			category	query		string			false	"Category slug"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"articles + pagination metadata"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Router			/help/articles [get]
+func (app *application) listHelpArticlesHandler(w http.ResponseWriter, r *http.Request) {
+	app.listHelpArticles(w, r, true)
+}
+
+// getHelpArticleHandler godoc
+//
+//	@Summary		Get a help article
+//	@Tags			Help
+//	@Produce		json
+//	@Param			slug	path		string	true	"Article slug"
+//	@Success		200		{object}	helpcenter.Article
+//	@Failure		404		{object}	error	"Article not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/help/articles/{slug} [get]
+func (app *application) getHelpArticleHandler(w http.ResponseWriter, r *http.Request) {
+	slug := strings.TrimSpace(chi.URLParam(r, "slug"))
+
+	a, err := app.store.HelpCenter.GetPublishedArticleBySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, helpcenter.ErrArticleNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, a)
+}
+
+// ---------- Admin ----------
+
+// adminListHelpCategoriesHandler godoc
+//
+//	@Summary		List help categories (admin)
+//	@Description	Includes categories without published articles. article_count counts published articles only.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}		helpcenter.Category
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/categories [get]
+func (app *application) adminListHelpCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := app.store.HelpCenter.ListCategories(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// adminCreateHelpCategoryHandler godoc
+//
+//	@Summary		Create a help category
+//	@Description	slug is generated from the name when omitted.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateHelpCategoryPayload	true	"Category"
+//	@Success		201		{object}	helpcenter.Category
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		409		{object}	error	"Slug already in use"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/categories [post]
+func (app *application) adminCreateHelpCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateHelpCategoryPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	name := strings.TrimSpace(payload.Name)
+	slug := strings.TrimSpace(payload.Slug)
+	if slug == "" {
+		slug = generateSlug(name)
+	}
+	if !isValidSlug(slug) {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid slug format"))
+		return
+	}
+
+	c := &helpcenter.Category{
+		Name:        name,
+		Slug:        slug,
+		Description: payload.Description,
+		Position:    payload.Position,
+	}
+	if err := app.store.HelpCenter.CreateCategory(r.Context(), c); err != nil {
+		if errors.Is(err, helpcenter.ErrSlugTaken) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityHelpCategory, audit.ActionCreate, c.ID, nil, c)
+
+	app.jsonResponse(w, http.StatusCreated, c)
+}
+
+// adminUpdateHelpCategoryHandler godoc
+//
+//	@Summary		Update a help category
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			categoryID	path		int							true	"Category ID"
+//	@Param			payload		body		UpdateHelpCategoryPayload	true	"Fields to update"
+//	@Success		200			{object}	helpcenter.Category
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Category not found"
+//	@Failure		409			{object}	error	"Slug already in use"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/categories/{categoryID} [patch]
+func (app *application) adminUpdateHelpCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "categoryID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid category ID"))
+		return
+	}
+
+	var payload UpdateHelpCategoryPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	upd := helpcenter.UpdateCategory{
+		Description: payload.Description,
+		Position:    payload.Position,
+	}
+	if payload.Name != nil {
+		name := strings.TrimSpace(*payload.Name)
+		if name == "" {
+			app.badRequestResponse(w, r, fmt.Errorf("name cannot be empty"))
+			return
+		}
+		upd.Name = &name
+	}
+	if payload.Slug != nil {
+		slug := strings.TrimSpace(*payload.Slug)
+		if !isValidSlug(slug) {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid slug format"))
+			return
+		}
+		upd.Slug = &slug
+	}
+
+	c, err := app.store.HelpCenter.UpdateCategory(r.Context(), id, upd)
+	if err != nil {
+		switch {
+		case errors.Is(err, helpcenter.ErrCategoryNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, helpcenter.ErrSlugTaken):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityHelpCategory, audit.ActionUpdate, id, nil, c)
+
+	app.jsonResponse(w, http.StatusOK, c)
+}
+
+// adminDeleteHelpCategoryHandler godoc
+//
+//	@Summary		Delete a help category
+//	@Description	Only empty categories can be deleted; move or delete their articles first.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			categoryID	path		int		true	"Category ID"
+//	@Success		204			{string}	string	"Deleted"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Category not found"
+//	@Failure		409			{object}	error	"Category still has articles"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/categories/{categoryID} [delete]
+func (app *application) adminDeleteHelpCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "categoryID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid category ID"))
+		return
+	}
+
+	if err := app.store.HelpCenter.DeleteCategory(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, helpcenter.ErrCategoryNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, helpcenter.ErrCategoryInUse):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityHelpCategory, audit.ActionDelete, id, nil, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminListHelpArticlesHandler godoc
+//
+//	@Summary		List help articles (admin)
+//	@Description	Same as the public search but includes drafts.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			q			query		string			false	"Search text"
+//	@Param			category	query		string			false	"Category slug"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"articles + pagination metadata"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/articles [get]
+func (app *application) adminListHelpArticlesHandler(w http.ResponseWriter, r *http.Request) {
+	app.listHelpArticles(w, r, false)
+}
+
+// adminGetHelpArticleHandler godoc
+//
+//	@Summary		Get a help article (admin)
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			articleID	path		int	true	"Article ID"
+//	@Success		200			{object}	helpcenter.Article
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Article not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/articles/{articleID} [get]
+func (app *application) adminGetHelpArticleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "articleID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid article ID"))
+		return
+	}
+
+	a, err := app.store.HelpCenter.GetArticle(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, helpcenter.ErrArticleNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, a)
+}
+
+// adminCreateHelpArticleHandler godoc
+//
+//	@Summary		Create a help article
+//	@Description	body_markdown is stored as-is and rendered by the client. slug is generated from the title when omitted.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateHelpArticlePayload	true	"Article"
+//	@Success		201		{object}	helpcenter.Article
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		409		{object}	error	"Slug already in use"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/articles [post]
+func (app *application) adminCreateHelpArticleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateHelpArticlePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	title := strings.TrimSpace(payload.Title)
+	slug := strings.TrimSpace(payload.Slug)
+	if slug == "" {
+		slug = generateSlug(title)
+		if len(slug) > 50 {
+			slug = strings.Trim(slug[:50], "-")
+		}
+	}
+	if !isValidSlug(slug) {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid slug format"))
+		return
+	}
+
+	a := &helpcenter.Article{
+		CategoryID:   payload.CategoryID,
+		Title:        title,
+		Slug:         slug,
+		BodyMarkdown: payload.BodyMarkdown,
+		IsPublished:  payload.IsPublished,
+		Position:     payload.Position,
+	}
+	if err := app.store.HelpCenter.CreateArticle(r.Context(), a); err != nil {
+		switch {
+		case errors.Is(err, helpcenter.ErrCategoryNotFound):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, helpcenter.ErrSlugTaken):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityHelpArticle, audit.ActionCreate, a.ID, nil, a)
+
+	app.jsonResponse(w, http.StatusCreated, a)
+}
+
+// adminUpdateHelpArticleHandler godoc
+//
+//	@Summary		Update a help article
+//	@Description	Only provided fields change. Set is_published to publish or unpublish.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			articleID	path		int							true	"Article ID"
+//	@Param			payload		body		UpdateHelpArticlePayload	true	"Fields to update"
+//	@Success		200			{object}	helpcenter.Article
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Article not found"
+//	@Failure		409			{object}	error	"Slug already in use"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/articles/{articleID} [patch]
+func (app *application) adminUpdateHelpArticleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "articleID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid article ID"))
+		return
+	}
+
+	var payload UpdateHelpArticlePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	upd := helpcenter.UpdateArticle{
+		CategoryID:   payload.CategoryID,
+		BodyMarkdown: payload.BodyMarkdown,
+		IsPublished:  payload.IsPublished,
+		Position:     payload.Position,
+	}
+	if payload.Title != nil {
+		title := strings.TrimSpace(*payload.Title)
+		if title == "" {
+			app.badRequestResponse(w, r, fmt.Errorf("title cannot be empty"))
+			return
+		}
+		upd.Title = &title
+	}
+	if payload.Slug != nil {
+		slug := strings.TrimSpace(*payload.Slug)
+		if !isValidSlug(slug) {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid slug format"))
+			return
+		}
+		upd.Slug = &slug
+	}
+	if payload.BodyMarkdown != nil && strings.TrimSpace(*payload.BodyMarkdown) == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("body_markdown cannot be empty"))
+		return
+	}
+
+	before, err := app.store.HelpCenter.GetArticle(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, helpcenter.ErrArticleNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	a, err := app.store.HelpCenter.UpdateArticle(r.Context(), id, upd)
+	if err != nil {
+		switch {
+		case errors.Is(err, helpcenter.ErrArticleNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, helpcenter.ErrCategoryNotFound):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, helpcenter.ErrSlugTaken):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	action := audit.ActionUpdate
+	if before.IsPublished != a.IsPublished {
+		action = audit.ActionPublish
+	}
+	app.recordAudit(r, audit.EntityHelpArticle, action, id, before, a)
+
+	app.jsonResponse(w, http.StatusOK, a)
+}
+
+// adminDeleteHelpArticleHandler godoc
+//
+//	@Summary		Delete a help article
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			articleID	path		int		true	"Article ID"
+//	@Success		204			{string}	string	"Deleted"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Article not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/help/articles/{articleID} [delete]
+func (app *application) adminDeleteHelpArticleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "articleID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid article ID"))
+		return
+	}
+
+	before, err := app.store.HelpCenter.GetArticle(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, helpcenter.ErrArticleNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.HelpCenter.DeleteArticle(r.Context(), id); err != nil {
+		if errors.Is(err, helpcenter.ErrArticleNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityHelpArticle, audit.ActionDelete, id, before, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) listHelpArticles(w http.ResponseWriter, r *http.Request, publishedOnly bool) {
+	q := r.URL.Query()
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.HelpCenter.ListArticles(r.Context(), helpcenter.ArticleFilter{
+		Search:        strings.TrimSpace(q.Get("q")),
+		CategorySlug:  strings.TrimSpace(q.Get("category")),
+		PublishedOnly: publishedOnly,
+	}, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"articles":   list,
+		"pagination": pagination,
+	})
+}
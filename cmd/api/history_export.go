@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"khel/internal/domain/users"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type exportUserHistoryPayload struct {
+	ExportID int64  `json:"export_id"`
+	UserID   int64  `json:"user_id"`
+	BaseURL  string `json:"base_url"`
+}
+
+// historyExportSignature signs a download link for one export. The expiry is
+// part of the signature, so the link can't be stretched past it.
+func (app *application) historyExportSignature(exportID, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(app.config.auth.token.secret))
+	_, _ = fmt.Fprintf(mac, "history-export:%d:%d", exportID, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (app *application) historyExportURL(baseURL string, e *users.HistoryExport) string {
+	exp := e.ExpiresAt.Unix()
+	return fmt.Sprintf("%s/v1/users/history/exports/%d/download?exp=%d&sig=%s", baseURL, e.ID, exp, app.historyExportSignature(e.ID, exp))
+}
+
+// exportHistoryHandler godoc
+//
+//	@Summary		Export my booking and game history
+//	@Description	Starts a CSV export of my past bookings and the games I played, with dates, venues, prices, statuses and recorded scores. The file is built in the background; a notification then carries a download link that works for 24 hours. Asking again while an export is still being built returns that export.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			format	query		string	false	"Export format"	Enums(csv)	default(csv)
+//	@Success		202		{object}	users.HistoryExport
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/history/export [get]
+func (app *application) exportHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" && format != "csv" {
+		app.badRequestResponse(w, r, fmt.Errorf("format must be csv"))
+		return
+	}
+
+	ctx := r.Context()
+	export, created, err := app.store.Users.CreateHistoryExport(ctx, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if created {
+		_, err := app.store.Jobs.Enqueue(ctx, jobExportUserHistory, exportUserHistoryPayload{
+			ExportID: export.ID,
+			UserID:   user.ID,
+			BaseURL:  app.publicBaseURL(r),
+		}, jobs.EnqueueOptions{})
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	app.jsonResponse(w, http.StatusAccepted, export)
+}
+
+// downloadHistoryExportHandler godoc
+//
+//	@Summary		Download a history export
+//	@Description	Serves the CSV built by /users/history/export. Authenticated by the signed link from the notification instead of a token, so it opens in a browser.
+//	@Tags			Users
+//	@Produce		text/csv
+//	@Param			exportID	path		int		true	"Export ID"
+//	@Param			exp			query		int		true	"Link expiry (unix seconds)"
+//	@Param			sig			query		string	true	"Signature"
+//	@Success		200			{string}	string	"CSV file"
+//	@Failure		404			{object}	error	"Export not found or link expired"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Router			/users/history/exports/{exportID}/download [get]
+func (app *application) downloadHistoryExportHandler(w http.ResponseWriter, r *http.Request) {
+	notFound := errors.New("export not found or link expired")
+
+	exportID, err := readIDParam(r, "exportID")
+	if err != nil || exportID < 1 {
+		app.notFoundResponse(w, r, notFound)
+		return
+	}
+	q := r.URL.Query()
+	exp, err := strconv.ParseInt(q.Get("exp"), 10, 64)
+	if err != nil || time.Now().Unix() >= exp {
+		app.notFoundResponse(w, r, notFound)
+		return
+	}
+	if !hmac.Equal([]byte(q.Get("sig")), []byte(app.historyExportSignature(exportID, exp))) {
+		app.notFoundResponse(w, r, notFound)
+		return
+	}
+
+	export, content, err := app.store.Users.GetHistoryExportFile(r.Context(), exportID)
+	if err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			app.notFoundResponse(w, r, notFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="khel-history-%s.csv"`, export.CreatedAt.Format("20060102")))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+// runExportUserHistory builds the CSV for one export, stores it and sends
+// the user the download link.
+func (app *application) runExportUserHistory(ctx context.Context, p exportUserHistoryPayload) error {
+	entries, err := app.store.Users.ListHistory(ctx, p.UserID)
+	if err != nil {
+		return err
+	}
+
+	content, err := app.historyCSV(entries)
+	if err != nil {
+		return err
+	}
+
+	export, err := app.store.Users.CompleteHistoryExport(ctx, p.ExportID, content, len(entries))
+	if err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			// Purged or the user is gone; nothing left to deliver.
+			return nil
+		}
+		return err
+	}
+	jobs.SetRowsAffected(ctx, int64(len(entries)))
+
+	// The file is stored, so a failed push isn't worth rebuilding it for; the
+	// inbox entry still carries the link.
+	url := app.historyExportURL(p.BaseURL, export)
+	if err := notifications.SendHistoryExportReady(ctx, app.push, app.store, p.UserID, export.ID, url); err != nil {
+		app.logger.Errorw("failed to send history export notification", "export_id", export.ID, "error", err)
+	}
+	return nil
+}
+
+func (app *application) historyCSV(entries []users.HistoryEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write([]string{"type", "id", "venue", "sport", "role", "start_time", "end_time", "price", "status", "score"})
+	for _, h := range entries {
+		id := strconv.FormatInt(h.ID, 10)
+		if h.Kind == "booking" {
+			id = app.EncodeBookingID(h.ID)
+		}
+		var sport, role, price, score string
+		if h.Sport != nil {
+			sport = *h.Sport
+		}
+		if h.Role != nil {
+			role = *h.Role
+		}
+		if h.Price != nil {
+			price = strconv.Itoa(*h.Price)
+		}
+		if h.ScoreA != nil && h.ScoreB != nil {
+			score = fmt.Sprintf("%d-%d", *h.ScoreA, *h.ScoreB)
+		}
+		cw.Write([]string{
+			h.Kind,
+			id,
+			h.VenueName,
+			sport,
+			role,
+			h.StartTime.Format(time.RFC3339),
+			h.EndTime.Format(time.RFC3339),
+			price,
+			h.Status,
+			score,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, fmt.Errorf("write history csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (app *application) runPurgeHistoryExports(ctx context.Context) error {
+	purged, err := app.store.Users.PurgeExpiredHistoryExports(ctx)
+	if err != nil {
+		return err
+	}
+	jobs.SetRowsAffected(ctx, purged)
+	return nil
+}
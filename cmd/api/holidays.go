@@ -0,0 +1,373 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/holidays"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultUtilizationLookback = 90
+	maxUtilizationDays         = 366
+)
+
+type HolidayPayload struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name string `json:"name" validate:"required,max=120"`
+}
+
+type ImportHolidaysPayload struct {
+	Holidays []HolidayPayload `json:"holidays" validate:"required,min=1,max=200,dive"`
+}
+
+type HolidaySettingsPayload struct {
+	// RatePercent adjusts weekly prices on holidays: 20 charges 20% more.
+	RatePercent int  `json:"rate_percent" validate:"gte=-90,lte=300"`
+	Closed      bool `json:"closed"`
+}
+
+// listHolidaysHandler godoc
+//
+//	@Summary		List public holidays
+//	@Description	Nepal public holidays between from and to (YYYY-MM-DD, inclusive). Defaults to the current year.
+//	@Tags			Holidays
+//	@Produce		json
+//	@Param			from	query		string	false	"First date"
+//	@Param			to		query		string	false	"Last date"
+//	@Success		200		{array}		holidays.Holiday
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/holidays [get]
+func (app *application) listHolidaysHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, to := strings.TrimSpace(q.Get("from")), strings.TrimSpace(q.Get("to"))
+	if from == "" && to == "" {
+		loc, err := time.LoadLocation("Asia/Kathmandu")
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		year := time.Now().In(loc).Year()
+		from, to = fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-12-31", year)
+	}
+	for _, d := range []string{from, to} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid date format, use YYYY-MM-DD"))
+			return
+		}
+	}
+
+	list, err := app.store.Holidays.List(r.Context(), from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// adminCreateHolidayHandler godoc
+//
+//	@Summary		Add a public holiday
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		HolidayPayload	true	"Holiday"
+//	@Success		201		{object}	holidays.Holiday
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		409		{object}	error	"A holiday already exists on that date"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/holidays [post]
+func (app *application) adminCreateHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	var payload HolidayPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	h := &holidays.Holiday{Date: payload.Date, Name: strings.TrimSpace(payload.Name)}
+	if err := app.store.Holidays.Create(r.Context(), h); err != nil {
+		if errors.Is(err, holidays.ErrDuplicate) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityPublicHoliday, audit.ActionCreate, h.ID, nil, h)
+	app.jsonResponse(w, http.StatusCreated, h)
+}
+
+// adminUpdateHolidayHandler godoc
+//
+//	@Summary		Update a public holiday
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			holidayID	path		int				true	"Holiday ID"
+//	@Param			payload		body		HolidayPayload	true	"Holiday"
+//	@Success		200			{object}	holidays.Holiday
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Holiday not found"
+//	@Failure		409			{object}	error	"A holiday already exists on that date"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/holidays/{holidayID} [put]
+func (app *application) adminUpdateHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "holidayID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid holiday ID"))
+		return
+	}
+
+	var payload HolidayPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	h := &holidays.Holiday{ID: id, Date: payload.Date, Name: strings.TrimSpace(payload.Name)}
+	if err := app.store.Holidays.Update(r.Context(), h); err != nil {
+		switch {
+		case errors.Is(err, holidays.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, holidays.ErrDuplicate):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityPublicHoliday, audit.ActionUpdate, h.ID, nil, h)
+	app.jsonResponse(w, http.StatusOK, h)
+}
+
+// adminDeleteHolidayHandler godoc
+//
+//	@Summary		Delete a public holiday
+//	@Tags			Admin
+//	@Param			holidayID	path	int	true	"Holiday ID"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		404	{object}	error	"Holiday not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/holidays/{holidayID} [delete]
+func (app *application) adminDeleteHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "holidayID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid holiday ID"))
+		return
+	}
+
+	if err := app.store.Holidays.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, holidays.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityPublicHoliday, audit.ActionDelete, id, nil, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminImportHolidaysHandler godoc
+//
+//	@Summary		Import public holidays
+//	@Description	Adds holidays in bulk, e.g. the government's list for a new year. Existing dates are renamed, nothing is deleted.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ImportHolidaysPayload	true	"Holidays"
+//	@Success		200		{object}	map[string]int64		"changed: rows added or renamed"
+//	@Failure		400		{object}	error					"Bad Request"
+//	@Failure		500		{object}	error					"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/holidays/import [post]
+func (app *application) adminImportHolidaysHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ImportHolidaysPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	seen := make(map[string]bool, len(payload.Holidays))
+	list := make([]holidays.Holiday, 0, len(payload.Holidays))
+	for _, h := range payload.Holidays {
+		if seen[h.Date] {
+			app.badRequestResponse(w, r, fmt.Errorf("%s is listed more than once", h.Date))
+			return
+		}
+		seen[h.Date] = true
+		list = append(list, holidays.Holiday{Date: h.Date, Name: strings.TrimSpace(h.Name)})
+	}
+
+	changed, err := app.store.Holidays.Import(r.Context(), list)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityPublicHoliday, audit.ActionCreate, nil, nil, list)
+	app.jsonResponse(w, http.StatusOK, map[string]int64{"changed": changed})
+}
+
+// getHolidaySettingsHandler godoc
+//
+//	@Summary		Get my venue's holiday settings
+//	@Description	Venues that never set them keep their weekly pricing on holidays.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	holidays.VenueSettings
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/holiday-settings [get]
+func (app *application) getHolidaySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	settings, err := app.store.Holidays.GetVenueSettings(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, settings)
+}
+
+// setHolidaySettingsHandler godoc
+//
+//	@Summary		Set my venue's holiday settings
+//	@Description	On public holidays weekly prices are adjusted by rate_percent, or the venue takes no bookings when closed is true. Date-specific pricing overrides still take precedence.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int						true	"Venue ID"
+//	@Param			payload	body		HolidaySettingsPayload	true	"Settings"
+//	@Success		200		{object}	holidays.VenueSettings
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/holiday-settings [put]
+func (app *application) setHolidaySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload HolidaySettingsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	settings := &holidays.VenueSettings{
+		VenueID:     venueID,
+		RatePercent: payload.RatePercent,
+		Closed:      payload.Closed,
+	}
+	if err := app.store.Holidays.SetVenueSettings(r.Context(), settings); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, settings)
+}
+
+// getHolidayUtilizationHandler godoc
+//
+//	@Summary		Compare holiday and normal-day utilization
+//	@Description	Bookings, booked hours, open hours (from weekly pricing), utilization rate and revenue for public holidays vs other days. Defaults to the last 90 days; at most 366 days.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int		true	"Venue ID"
+//	@Param			from	query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to		query		string	false	"Last date (YYYY-MM-DD)"
+//	@Success		200		{object}	holidays.UtilizationReport
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/holiday-utilization [get]
+func (app *application) getHolidayUtilizationHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from := to.AddDate(0, 0, -(defaultUtilizationLookback - 1))
+
+	q := r.URL.Query()
+	if v := strings.TrimSpace(q.Get("from")); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid from date, use YYYY-MM-DD"))
+			return
+		}
+	}
+	if v := strings.TrimSpace(q.Get("to")); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid to date, use YYYY-MM-DD"))
+			return
+		}
+	}
+	if to.Before(from) {
+		app.badRequestResponse(w, r, errors.New("from must not be after to"))
+		return
+	}
+	if to.Sub(from) >= maxUtilizationDays*24*time.Hour {
+		app.badRequestResponse(w, r, fmt.Errorf("the range can span at most %d days", maxUtilizationDays))
+		return
+	}
+
+	report, err := app.store.Holidays.Utilization(r.Context(), venueID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, report)
+}
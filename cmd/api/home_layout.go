@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/homelayout"
+	"net/http"
+	"time"
+)
+
+type HomeSectionPayload struct {
+	Type     string          `json:"type" validate:"required"`
+	Title    *string         `json:"title" validate:"omitempty,max=80"`
+	Params   json.RawMessage `json:"params" swaggertype:"object"`
+	IsActive *bool           `json:"is_active"`
+	StartsAt *time.Time      `json:"starts_at"`
+	EndsAt   *time.Time      `json:"ends_at"`
+}
+
+type ReplaceHomeLayoutPayload struct {
+	Sections []HomeSectionPayload `json:"sections" validate:"required,max=20,dive"`
+}
+
+// getHomeLayoutHandler godoc
+//
+//	@Summary		Get the home screen layout
+//	@Description	Sections the app renders on the home screen, top to bottom. Each has a type (ads_carousel, featured_rails, featured_collection, nearby_games, venues) and type-specific params. Clients should skip types they don't know.
+//	@Tags			Home
+//	@Produce		json
+//	@Success		200	{object}	homelayout.Layout
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/home/layout [get]
+func (app *application) getHomeLayoutHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	layout, err := app.store.HomeLayout.Active(ctx, time.Now())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	app.jsonResponse(w, http.StatusOK, layout)
+}
+
+// adminGetHomeLayoutHandler godoc
+//
+//	@Summary		Get the home layout (admin)
+//	@Description	Every home section in order, including inactive and scheduled ones.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}		homelayout.Section
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/home-layout [get]
+func (app *application) adminGetHomeLayoutHandler(w http.ResponseWriter, r *http.Request) {
+	sections, err := app.store.HomeLayout.List(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, sections)
+}
+
+// adminReplaceHomeLayoutHandler godoc
+//
+//	@Summary		Replace the home layout (admin)
+//	@Description	Saves the whole home screen at once; sections are shown in the order given. Params are checked against the section type. is_active defaults to true; starts_at/ends_at limit when a section is shown.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ReplaceHomeLayoutPayload	true	"Sections in display order"
+//	@Success		200		{array}		homelayout.Section
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/home-layout [put]
+func (app *application) adminReplaceHomeLayoutHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ReplaceHomeLayoutPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	sections := make([]homelayout.Section, 0, len(payload.Sections))
+	for i, p := range payload.Sections {
+		params, err := homelayout.NormalizeParams(p.Type, p.Params)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("section %d: %w", i, err))
+			return
+		}
+		if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+			app.badRequestResponse(w, r, fmt.Errorf("section %d: ends_at must be after starts_at", i))
+			return
+		}
+
+		s := homelayout.Section{
+			Type:     p.Type,
+			Title:    cleanOptionalString(p.Title),
+			Params:   params,
+			IsActive: true,
+			StartsAt: p.StartsAt,
+			EndsAt:   p.EndsAt,
+		}
+		if p.IsActive != nil {
+			s.IsActive = *p.IsActive
+		}
+		sections = append(sections, s)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	before, err := app.store.HomeLayout.List(ctx)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	saved, err := app.store.HomeLayout.Replace(ctx, sections)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityHomeLayout, audit.ActionUpdate, nil, before, saved)
+	app.jsonResponse(w, http.StatusOK, saved)
+}
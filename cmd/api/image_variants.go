@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"khel/internal/images"
+	"khel/internal/jobs"
+	"time"
+)
+
+const (
+	// imageVariantsBatch is how many images are read from the database at
+	// once.
+	imageVariantsBatch = 20
+	// imageVariantsBudget keeps a run inside the job timeout; whatever is
+	// left waits for the next one.
+	imageVariantsBudget = 90 * time.Second
+)
+
+func (app *application) registerImageVariants() {
+	app.jobs.Register(jobProcessImageVariants, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runProcessImageVariants(ctx)
+	})
+	app.jobs.Every(jobProcessImageVariants, time.Hour)
+}
+
+// enqueueImageVariants starts processing new images now rather than at the
+// next hourly run. A run already going picks them up before it finishes.
+func (app *application) enqueueImageVariants() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := app.store.Jobs.Enqueue(ctx, jobProcessImageVariants, struct{}{}, jobs.EnqueueOptions{UniqueKey: jobProcessImageVariants})
+	if err != nil {
+		app.logger.Errorw("failed to enqueue image variants", "error", err)
+	}
+}
+
+// runProcessImageVariants fills in the sizes and placeholder of product
+// images, venue photos and ads that don't have them yet. An image that
+// can't be read gets its sizes only, so it isn't tried again; one that
+// can't be fetched right now is left for the next run.
+func (app *application) runProcessImageVariants(ctx context.Context) error {
+	deadline := time.Now().Add(imageVariantsBudget)
+	var saved int64
+	defer func() { jobs.SetRowsAffected(ctx, saved) }()
+
+	for time.Now().Before(deadline) {
+		pending, err := app.store.ImageVariants.Pending(ctx, imageVariantsBatch)
+		if err != nil {
+			return err
+		}
+
+		progress := false
+		for _, p := range pending {
+			v, err := app.images.Process(ctx, p.URL)
+			if err != nil {
+				if !errors.Is(err, images.ErrUnusable) {
+					app.logger.Warnw("failed to process image", "target", p.Target, "id", p.ID, "url", p.URL, "error", err)
+					continue
+				}
+				app.logger.Infow("image has no placeholder", "target", p.Target, "id", p.ID, "url", p.URL, "reason", err)
+			}
+
+			ok, err := app.store.ImageVariants.Save(ctx, p, v)
+			if err != nil {
+				return err
+			}
+			if ok {
+				saved++
+			}
+			progress = true
+		}
+
+		// A batch that only failed to fetch would come back unchanged.
+		if len(pending) < imageVariantsBatch || !progress {
+			break
+		}
+	}
+	return nil
+}
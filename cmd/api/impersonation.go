@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/auth"
+	"khel/internal/domain/accesscontrol"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// impersonationTTL is how long an impersonation token lasts. It cannot be
+// refreshed; support asks for a new one.
+const impersonationTTL = 15 * time.Minute
+
+const impersonatorCtx userKey = "impersonator"
+
+// withImpersonator stores the impersonating admin's ID from the access
+// token claims, if there is one.
+func withImpersonator(ctx context.Context, claims jwt.MapClaims) context.Context {
+	v, ok := claims[auth.ImpersonatorClaim].(float64)
+	if !ok || v <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, impersonatorCtx, int64(v))
+}
+
+// getImpersonatorID returns the admin acting as the request's user when the
+// request came with an impersonation token.
+func getImpersonatorID(r *http.Request) (int64, bool) {
+	id, ok := r.Context().Value(impersonatorCtx).(int64)
+	return id, ok
+}
+
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	UserID      string    `json:"user_id"`
+	Role        string    `json:"role"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// impersonateUserHandler godoc
+//
+//	@Summary		Impersonate a user
+//	@Description	Issues a 15-minute access token for the user so support can see what they see. The token carries an impersonator claim, cannot be refreshed, and audit entries made with it name the admin as the actor. Other admins cannot be impersonated. Every use of this endpoint is written to the audit log.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		200		{object}	ImpersonationResponse
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Target is an admin"
+//	@Failure		404		{object}	error	"User not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/impersonate/{userID} [post]
+func (app *application) impersonateUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	admin := getUserFromContext(r)
+	if userID == admin.ID {
+		app.badRequestResponse(w, r, errors.New("cannot impersonate yourself"))
+		return
+	}
+
+	ctx := r.Context()
+	target, err := app.store.Users.GetByID(ctx, userID)
+	if err != nil || target == nil {
+		app.notFoundResponse(w, r, errors.New("user not found"))
+		return
+	}
+
+	isAdmin, err := app.store.AccessControl.UserHasRole(ctx, userID, string(accesscontrol.RoleAdmin))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if isAdmin {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	venueIDs, err := app.store.Venues.GetOwnedVenueIDs(ctx, userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	role := "user"
+	if len(venueIDs) > 0 {
+		role = "venue_owner"
+	}
+
+	expiresAt := time.Now().Add(impersonationTTL)
+	token, err := app.authenticator.GenerateImpersonationToken(userID, role, admin.ID, impersonationTTL)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityUser, audit.ActionImpersonate, userID, nil, map[string]any{
+		"expires_at": expiresAt,
+		"role":       role,
+	})
+	app.logger.Infow("admin impersonation started", "admin_id", admin.ID, "user_id", userID, "expires_at", expiresAt)
+
+	app.jsonResponse(w, http.StatusOK, ImpersonationResponse{
+		AccessToken: token,
+		UserID:      strconv.FormatInt(userID, 10),
+		Role:        role,
+		ExpiresAt:   expiresAt,
+	})
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/domain/inbox"
+	"khel/internal/params"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// listNotificationsHandler godoc
+//
+//	@Summary		List my notifications
+//	@Description	In-app inbox, newest first. Includes notifications whose push was not delivered.
+//	@Tags			users
+//	@Produce		json
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"notifications + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications [get]
+func (app *application) listNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	pagination := params.ParsePagination(r.URL.Query())
+	list, total, err := app.store.Inbox.List(r.Context(), user.ID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"notifications": list,
+		"pagination":    pagination,
+	})
+}
+
+// unreadNotificationCountHandler godoc
+//
+//	@Summary		Count my unread notifications
+//	@Description	For the app badge.
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	map[string]int	"unread"
+//	@Failure		500	{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications/unread-count [get]
+func (app *application) unreadNotificationCountHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	n, err := app.store.Inbox.UnreadCount(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]int{"unread": n})
+}
+
+// markNotificationReadHandler godoc
+//
+//	@Summary		Mark a notification as read
+//	@Tags			users
+//	@Produce		json
+//	@Param			notificationID	path		int		true	"Notification ID"
+//	@Success		204				{string}	string	"Marked read"
+//	@Failure		400				{object}	error	"Bad Request"
+//	@Failure		404				{object}	error	"Notification not found"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications/{notificationID}/read [post]
+func (app *application) markNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "notificationID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid notification ID"))
+		return
+	}
+	user := getUserFromContext(r)
+
+	if err := app.store.Inbox.MarkRead(r.Context(), user.ID, id); err != nil {
+		if errors.Is(err, inbox.ErrNotificationNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// markAllNotificationsReadHandler godoc
+//
+//	@Summary		Mark all my notifications as read
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	map[string]int64	"updated"
+//	@Failure		500	{object}	error				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications/read-all [post]
+func (app *application) markAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	n, err := app.store.Inbox.MarkAllRead(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]int64{"updated": n})
+}
+
+// clearNotificationsHandler godoc
+//
+//	@Summary		Clear old notifications
+//	@Description	Deletes my notifications created before the given time. The server also keeps at most 500 notifications per user and drops anything older than 90 days.
+//	@Tags			users
+//	@Produce		json
+//	@Param			before	query		string				true	"RFC3339 timestamp or YYYY-MM-DD (start of that day, UTC)"
+//	@Success		200		{object}	map[string]int64	"deleted"
+//	@Failure		400		{object}	error				"Bad Request"
+//	@Failure		500		{object}	error				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications [delete]
+func (app *application) clearNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	v := strings.TrimSpace(r.URL.Query().Get("before"))
+	if v == "" {
+		app.badRequestResponse(w, r, errors.New("missing before"))
+		return
+	}
+	before, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		before, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid before: must be RFC3339 or YYYY-MM-DD"))
+			return
+		}
+	}
+	user := getUserFromContext(r)
+
+	n, err := app.store.Inbox.DeleteBefore(r.Context(), user.ID, before)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]int64{"deleted": n})
+}
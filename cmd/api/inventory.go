@@ -1,19 +1,18 @@
 package main
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"io"
+	"khel/internal/domain/bookings"
 	"khel/internal/domain/inventory"
+	"khel/internal/media"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/cloudinary/cloudinary-go/v2/api"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -22,7 +21,7 @@ func readIDParam(r *http.Request, name string) (int64, error) {
 	return strconv.ParseInt(idStr, 10, 64)
 }
 
-func (app *application) uploadInventoryImageToCloudinary(
+func (app *application) uploadInventoryImage(
 	file io.Reader,
 	venueID int64,
 	itemName string,
@@ -37,23 +36,12 @@ func (app *application) uploadInventoryImageToCloudinary(
 	safeItemName := app.createSafePublicID(itemName)
 	publicID := fmt.Sprintf("venue_%d_%s_%d", venueID, safeItemName, time.Now().UnixNano())
 
-	resp, err := app.cld.Upload.Upload(
-		context.Background(),
-		file,
-		uploader.UploadParams{
-			Folder:    folder,
-			PublicID:  publicID,
-			Overwrite: api.Bool(false),
-			// good for small product images
-			Transformation: "w_500,h_500,c_fill,q_auto,f_auto",
-		},
-	)
-
-	if err != nil {
-		return "", fmt.Errorf("cloudinary inventory upload failed: %w", err)
-	}
-
-	return resp.SecureURL, nil
+	return app.upload(file, media.UploadOptions{
+		Folder: folder,
+		Name:   publicID,
+		// good for small product images
+		Transformation: "w_500,h_500,c_fill,q_auto,f_auto",
+	})
 }
 
 // createInventoryItemHandler godoc
@@ -129,7 +117,7 @@ func (app *application) createInventoryItemHandler(w http.ResponseWriter, r *htt
 			return
 		}
 
-		url, err := app.uploadInventoryImageToCloudinary(file, venueID, name)
+		url, err := app.uploadInventoryImage(file, venueID, name)
 		if err != nil {
 			app.internalServerError(w, r, err)
 			return
@@ -252,12 +240,13 @@ type GameDetailResponseItem struct {
 	Inventory      []inventory.InventoryItem        `json:"inventory_items"`
 	AddedItems     []inventory.BookingInventoryItem `json:"added_items"`
 	BillingSummary inventory.BillingSummary         `json:"billing_summary"`
+	Timeline       []bookings.Event                 `json:"timeline"`
 }
 
 // getGameDetailHandler godoc
 //
 //	@Summary		Get game detail
-//	@Description	Returns game detail, inventory items, added items, and billing summary.
+//	@Description	Returns game detail, inventory items, added items, billing summary, and the booking's timeline (requested, accepted, rescheduled, canceled, ...) oldest first.
 //	@Tags			venue games
 //	@Accept			json
 //	@Produce		json
@@ -307,6 +296,12 @@ func (app *application) getGameDetailHandler(w http.ResponseWriter, r *http.Requ
 		BillingSummary: detail.BillingSummary,
 	}
 
+	resp.Timeline, err = app.store.Bookings.ListEvents(r.Context(), bookingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	app.jsonResponse(w, http.StatusOK, resp)
 }
 
@@ -553,7 +548,7 @@ func (app *application) updateInventoryItemHandler(w http.ResponseWriter, r *htt
 			itemNameForPublicID = *payload.Name
 		}
 
-		uploadedURL, err := app.uploadInventoryImageToCloudinary(file, venueID, itemNameForPublicID)
+		uploadedURL, err := app.uploadInventoryImage(file, venueID, itemNameForPublicID)
 		if err != nil {
 			app.internalServerError(w, r, err)
 			return
@@ -572,11 +567,7 @@ func (app *application) updateInventoryItemHandler(w http.ResponseWriter, r *htt
 		// If DB update fails after new Cloudinary upload, delete the newly uploaded image
 		// to avoid leaving unused images in Cloudinary.
 		if newImageURL != nil {
-			go func(url string) {
-				if err := app.deletePhotoFromCloudinary(url); err != nil {
-					app.logger.Error("failed to delete newly uploaded inventory image from Cloudinary", "url", url, "err", err)
-				}
-			}(*newImageURL)
+			app.enqueuePhotoDelete(*newImageURL)
 		}
 
 		if errors.Is(err, inventory.ErrInventoryItemNotFound) {
@@ -592,11 +583,7 @@ func (app *application) updateInventoryItemHandler(w http.ResponseWriter, r *htt
 	// Delete old Cloudinary image only after DB update succeeds.
 	// This prevents broken image_url in DB if database update fails.
 	if newImageURL != nil && oldItem.ImageURL != nil && *oldItem.ImageURL != "" {
-		go func(url string) {
-			if err := app.deletePhotoFromCloudinary(url); err != nil {
-				app.logger.Error("failed to delete old inventory image from Cloudinary", "url", url, "err", err)
-			}
-		}(*oldItem.ImageURL)
+		app.enqueuePhotoDelete(*oldItem.ImageURL)
 	}
 
 	updatedItem, err := app.store.Inventory.GetInventoryItemByID(r.Context(), venueID, itemID)
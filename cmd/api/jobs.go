@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"khel/internal/jobs"
+	"khel/internal/params"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Job kinds handled by the API process.
+const (
+	jobMarkCompletedGames = "games.mark_completed"
+	jobAutoCancelGames    = "games.auto_cancel_underfilled"
+	jobExpireJoinRequests = "games.expire_join_requests"
+	jobPurgeCatalogTrash  = "catalog.purge_trash"
+	jobPruneNotifications = "notifications.prune"
+	jobMediaDelete        = "cloudinary.delete" // named before other storages; queued jobs keep the kind
+	jobSendEmail          = "email.send"
+
+	jobNotifyVenueAnnouncements = "venues.notify_announcements"
+	jobSendStartReminders       = "reminders.send_start"
+	jobSendReviewInvites        = "reminders.review_invites"
+	jobEvaluatePriceAlerts      = "pricing.evaluate_alerts"
+	jobSendSplitReminders       = "payments.split_reminders"
+	jobSendGamePaymentReminders = "games.payment_reminders"
+	jobCreateSettlements        = "settlements.create_weekly"
+	jobMatchSavedSearches       = "games.match_saved_searches"
+	jobPurgeDeletedAccounts     = "users.purge_deleted_accounts"
+	jobRefreshProductLabels     = "catalog.refresh_labels"
+	jobExportUserHistory        = "users.export_history"
+	jobPurgeHistoryExports      = "users.purge_history_exports"
+	jobProcessClosure           = "venues.process_closure"
+	jobAutoAcceptBookings       = "bookings.auto_accept"
+	jobExpirePendingBookings    = "bookings.expire_pending"
+	jobExpireGuestDeposits      = "bookings.expire_guest_deposits"
+	jobRegenerateDemoSandboxes  = "demo.regenerate_sandboxes"
+	jobMigrateVenuePhotos       = "venues.migrate_photos"
+	jobCheckWishlistPrices      = "catalog.check_wishlist_prices"
+	jobRefreshRecommendations   = "catalog.refresh_recommendations"
+	jobReindexSearch            = "search.reindex"
+	jobProcessImageVariants     = "images.process_variants"
+	jobCollectOrphanedMedia     = "media.collect_orphans"
+	jobScreenContent            = "moderation.screen"
+)
+
+type mediaDeletePayload struct {
+	URL string `json:"url"`
+}
+
+type sendEmailPayload struct {
+	Template string          `json:"template"`
+	Locale   string          `json:"locale,omitempty"`
+	Username string          `json:"username"`
+	Email    string          `json:"email"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// registerJobs wires job kinds to their handlers and periodic schedules.
+func (app *application) registerJobs() {
+	app.jobs.Register(jobMarkCompletedGames, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runMarkCompletedGames(ctx)
+	})
+	app.jobs.Every(jobMarkCompletedGames, 30*time.Minute)
+
+	app.jobs.Register(jobAutoCancelGames, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runAutoCancelGames(ctx)
+	})
+	app.jobs.Every(jobAutoCancelGames, 5*time.Minute)
+
+	app.jobs.Register(jobExpireJoinRequests, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runExpireJoinRequests(ctx)
+	})
+	app.jobs.Every(jobExpireJoinRequests, 5*time.Minute)
+
+	app.jobs.Register(jobPurgeCatalogTrash, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runPurgeCatalogTrash(ctx)
+	})
+	app.jobs.Every(jobPurgeCatalogTrash, 24*time.Hour)
+
+	app.jobs.Register(jobRefreshProductLabels, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runRefreshProductLabels(ctx)
+	})
+	app.jobs.Every(jobRefreshProductLabels, 24*time.Hour)
+
+	app.jobs.Register(jobRefreshRecommendations, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runRefreshRecommendations(ctx)
+	})
+	app.jobs.Every(jobRefreshRecommendations, 24*time.Hour)
+
+	app.jobs.Register(jobPruneNotifications, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runPruneNotifications(ctx)
+	})
+	app.jobs.Every(jobPruneNotifications, 24*time.Hour)
+
+	app.jobs.Register(jobNotifyVenueAnnouncements, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runNotifyVenueAnnouncements(ctx)
+	})
+	app.jobs.Every(jobNotifyVenueAnnouncements, time.Minute)
+
+	app.jobs.Register(jobSendStartReminders, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runSendStartReminders(ctx)
+	})
+	app.jobs.Every(jobSendStartReminders, time.Minute)
+
+	app.jobs.Register(jobSendReviewInvites, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runSendReviewInvites(ctx)
+	})
+	app.jobs.Every(jobSendReviewInvites, 10*time.Minute)
+
+	app.jobs.Register(jobSendSplitReminders, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runSendSplitReminders(ctx)
+	})
+	app.jobs.Every(jobSendSplitReminders, time.Hour)
+
+	app.jobs.Register(jobSendGamePaymentReminders, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runSendGamePaymentReminders(ctx)
+	})
+	app.jobs.Every(jobSendGamePaymentReminders, time.Hour)
+
+	app.jobs.Register(jobCreateSettlements, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runCreateSettlements(ctx)
+	})
+	app.jobs.Every(jobCreateSettlements, 6*time.Hour)
+
+	app.jobs.Register(jobPurgeDeletedAccounts, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runPurgeDeletedAccounts(ctx)
+	})
+	app.jobs.Every(jobPurgeDeletedAccounts, time.Hour)
+
+	app.jobs.Register(jobPurgeHistoryExports, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runPurgeHistoryExports(ctx)
+	})
+	app.jobs.Every(jobPurgeHistoryExports, time.Hour)
+
+	app.jobs.Register(jobAutoAcceptBookings, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runAutoAcceptBookings(ctx)
+	})
+	app.jobs.Every(jobAutoAcceptBookings, time.Minute)
+
+	app.jobs.Register(jobExpirePendingBookings, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runExpirePendingBookings(ctx)
+	})
+	app.jobs.Every(jobExpirePendingBookings, 5*time.Minute)
+
+	app.jobs.Register(jobExpireGuestDeposits, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runExpireGuestDeposits(ctx)
+	})
+	app.jobs.Every(jobExpireGuestDeposits, time.Minute)
+
+	// Hourly, so a sandbox regenerates within an hour of midnight even
+	// when the first run after it fails.
+	app.jobs.Register(jobRegenerateDemoSandboxes, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runRegenerateDemoSandboxes(ctx)
+	})
+	app.jobs.Every(jobRegenerateDemoSandboxes, time.Hour)
+
+	app.jobs.Register(jobMediaDelete, func(ctx context.Context, raw json.RawMessage) error {
+		var p mediaDeletePayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.deleteStoredFile(ctx, p.URL)
+	})
+
+	app.jobs.Register(jobEvaluatePriceAlerts, func(ctx context.Context, raw json.RawMessage) error {
+		var p evaluatePriceAlertsPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runEvaluatePriceAlerts(ctx, p.VenueID)
+	})
+
+	app.jobs.Register(jobMatchSavedSearches, func(ctx context.Context, raw json.RawMessage) error {
+		var p matchSavedSearchesPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runMatchSavedSearches(ctx, p.GameID)
+	})
+
+	app.jobs.Register(jobProcessClosure, func(ctx context.Context, raw json.RawMessage) error {
+		var p processClosurePayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runProcessClosure(ctx, p)
+	})
+
+	app.jobs.Register(jobCheckWishlistPrices, func(ctx context.Context, raw json.RawMessage) error {
+		var p checkWishlistPricesPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runCheckWishlistPrices(ctx, p)
+	})
+
+	app.jobs.Register(jobMigrateVenuePhotos, func(ctx context.Context, raw json.RawMessage) error {
+		var p migrateVenuePhotosPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runMigrateVenuePhotos(ctx, p)
+	})
+
+	app.jobs.Register(jobScreenContent, func(ctx context.Context, raw json.RawMessage) error {
+		var p screenContentPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runScreenContent(ctx, p)
+	})
+
+	app.jobs.Register(jobExportUserHistory, func(ctx context.Context, raw json.RawMessage) error {
+		var p exportUserHistoryPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return app.runExportUserHistory(ctx, p)
+	})
+
+	app.jobs.Register(jobSendEmail, func(ctx context.Context, raw json.RawMessage) error {
+		var p sendEmailPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		// templates only read fields, so a decoded map renders the same as the original struct
+		var data map[string]any
+		if len(p.Data) > 0 {
+			if err := json.Unmarshal(p.Data, &data); err != nil {
+				return fmt.Errorf("decode email data: %w", err)
+			}
+		}
+		status, err := app.mailer.Send(p.Template, p.Locale, p.Username, p.Email, data)
+		if err != nil {
+			return err
+		}
+		app.logger.Infow("Email sent", "template", p.Template, "status code", status)
+		return nil
+	})
+
+	app.registerEventSubscribers()
+	app.registerSearchIndexer()
+	app.registerImageVariants()
+	app.registerMediaGC()
+}
+
+// enqueuePhotoDelete queues removal of an uploaded file. Failures to queue
+// are logged; the asset is simply left behind.
+func (app *application) enqueuePhotoDelete(url string) {
+	if strings.TrimSpace(url) == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := app.store.Jobs.Enqueue(ctx, jobMediaDelete, mediaDeletePayload{URL: url}, jobs.EnqueueOptions{}); err != nil {
+		app.logger.Errorw("failed to enqueue media delete", "url", url, "error", err)
+	}
+}
+
+// enqueueEmail queues a templated email. data must be JSON-serialisable;
+// exported struct fields keep their names, so templates render unchanged.
+// locale picks the subject translation; empty means English.
+func (app *application) enqueueEmail(ctx context.Context, template, locale, username, email string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal email data: %w", err)
+	}
+	_, err = app.store.Jobs.Enqueue(ctx, jobSendEmail, sendEmailPayload{
+		Template: template,
+		Locale:   locale,
+		Username: username,
+		Email:    email,
+		Data:     b,
+	}, jobs.EnqueueOptions{})
+	return err
+}
+
+// listJobsHandler godoc
+//
+//	@Summary		List background jobs
+//	@Description	Returns queued, running, succeeded and dead jobs, newest first.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status	query		string			false	"Filter by status (queued, running, succeeded, dead)"
+//	@Param			kind	query		string			false	"Filter by job kind"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"jobs + pagination metadata"
+//	@Failure		400		{object}	error			"Bad Request"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/jobs [get]
+func (app *application) listJobsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	q := r.URL.Query()
+	f := jobs.Filter{
+		Status: strings.TrimSpace(q.Get("status")),
+		Kind:   strings.TrimSpace(q.Get("kind")),
+	}
+	switch f.Status {
+	case "", jobs.StatusQueued, jobs.StatusRunning, jobs.StatusSucceeded, jobs.StatusDead:
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("invalid status"))
+		return
+	}
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Jobs.List(ctx, f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"jobs":       list,
+		"pagination": pagination,
+	})
+}
+
+// getJobHandler godoc
+//
+//	@Summary		Get a background job
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			jobID	path		int			true	"Job ID"
+//	@Success		200		{object}	jobs.Job
+//	@Failure		400		{object}	error		"Bad Request"
+//	@Failure		404		{object}	error		"Job not found"
+//	@Failure		500		{object}	error		"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/jobs/{jobID} [get]
+func (app *application) getJobHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "jobID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid job ID"))
+		return
+	}
+
+	job, err := app.store.Jobs.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, job)
+}
+
+// retryJobHandler godoc
+//
+//	@Summary		Retry a dead job
+//	@Description	Requeues a job that ran out of attempts, with a fresh attempt budget.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			jobID	path		int			true	"Job ID"
+//	@Success		200		{object}	jobs.Job
+//	@Failure		400		{object}	error		"Bad Request"
+//	@Failure		404		{object}	error		"Job not found"
+//	@Failure		409		{object}	error		"Job is not dead"
+//	@Failure		500		{object}	error		"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/jobs/{jobID}/retry [post]
+func (app *application) retryJobHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "jobID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid job ID"))
+		return
+	}
+
+	job, err := app.store.Jobs.Retry(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, jobs.ErrNotRetryable):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, job)
+}
+
+// listJobRunsHandler godoc
+//
+//	@Summary		List periodic job runs
+//	@Description	One row per execution of a periodic job (started, duration, rows affected, error), newest first. Kept for 30 days.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			kind	query		string			false	"Filter by job kind"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"runs + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/jobs/runs [get]
+func (app *application) listJobRunsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	q := r.URL.Query()
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Jobs.ListRuns(ctx, strings.TrimSpace(q.Get("kind")), pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"runs":       list,
+		"pagination": pagination,
+	})
+}
+
+// listJobSchedulesHandler godoc
+//
+//	@Summary		List periodic job schedules
+//	@Description	Each periodic job with its interval and last run. overdue is true when no run started within two intervals.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}		jobs.Schedule
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/jobs/schedules [get]
+func (app *application) listJobSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	schedules, err := app.jobs.Schedules(ctx)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, schedules)
+}
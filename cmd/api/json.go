@@ -2,9 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"khel/internal/params"
 	"net/http"
+	"reflect"
 	"regexp"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -25,6 +28,12 @@ func init() {
 		matched, _ := regexp.MatchString(`^98[4-9][0-9]{7}$`, phone)
 		return matched
 	})
+
+	// Query structs are reported by parameter name, so a VALIDATION_FAILED
+	// for ?limit=500 names "limit" rather than the Go field.
+	Validate.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		return sf.Tag.Get("query")
+	})
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) error {
@@ -52,17 +61,23 @@ func readJSON(w http.ResponseWriter, r *http.Request, data any) error {
 	return decoder.Decode(data)
 }
 
-func writeJSONError(w http.ResponseWriter, status int, message string) error {
-	type envelope struct {
-		Success bool   `json:"success"`
-		Message string `json:"message"`
-		Status  int    `json:"status"`
+// readQuery binds the request's query parameters into dst with params.Bind
+// and validates the result. Set defaults on dst first.
+func readQuery(r *http.Request, dst any) error {
+	if err := params.Bind(r.URL.Query(), dst); err != nil {
+		return err
 	}
+	return Validate.Struct(dst)
+}
 
-	return writeJSON(w, status, &envelope{
-		Success: false,
-		Message: message,
-		Status:  status,
+// writeJSONError writes the error envelope every handler answers with. The
+// request ID lets a user's report be matched to the server logs.
+func writeJSONError(w http.ResponseWriter, r *http.Request, status int, code errorCode, message string, details any) error {
+	return writeJSON(w, status, &ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: middleware.GetReqID(r.Context()),
 	})
 }
 
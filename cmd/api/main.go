@@ -5,23 +5,30 @@ import (
 	"expvar"
 	"fmt"
 	"khel/internal/auth"
+	appconfig "khel/internal/config"
 	"khel/internal/db"
 	"khel/internal/domain/orders"
 	"khel/internal/domain/storage"
+	"khel/internal/events"
+	"khel/internal/images"
+	"khel/internal/jobs"
 	"khel/internal/mailer"
+	"khel/internal/media"
+	"khel/internal/moderation"
 	"khel/internal/notifications"
 	"khel/internal/payments"
 	"khel/internal/ratelimiter"
+	"khel/internal/searchindex"
+	"khel/internal/sms"
+	"khel/internal/tracing"
 	"log"
 	"net/http"
 	"os"
 	"runtime"
-	"strconv"
 	"time"
 
 	"github.com/9ssi7/exponent"
 	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	"github.com/speps/go-hashids/v2"
 
@@ -29,39 +36,6 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// LoadRateLimiterConfig retrieves rate limiter settings from environment variables
-func LoadRateLimiterConfig() ratelimiter.Config {
-	// Default values
-	defaultRequests := 300
-	defaultEnabled := false
-
-	// Retrieve request count with error handling
-	requestsPerTimeFrame := defaultRequests
-	if val, exists := os.LookupEnv("RATELIMITER_REQUESTS_COUNT"); exists {
-		if parsedVal, err := strconv.Atoi(val); err == nil {
-			requestsPerTimeFrame = parsedVal
-		} else {
-			fmt.Println("Invalid RATELIMITER_REQUESTS_COUNT, defaulting to", defaultRequests)
-		}
-	}
-
-	// Retrieve enabled flag with error handling
-	enabled := defaultEnabled
-	if val, exists := os.LookupEnv("RATE_LIMITER_ENABLED"); exists {
-		if parsedVal, err := strconv.ParseBool(val); err == nil {
-			enabled = parsedVal
-		} else {
-			fmt.Println("Invalid RATE_LIMITER_ENABLED, defaulting to", defaultEnabled)
-		}
-	}
-
-	return ratelimiter.Config{
-		RequestsPerTimeFrame: requestsPerTimeFrame,
-		TimeFrame:            1 * time.Minute,
-		Enabled:              enabled,
-	}
-}
-
 // NewLogger creates a new zap logger with color.
 func NewLogger() (*zap.SugaredLogger, error) {
 	// Configure the encoder to be a console encoder with color
@@ -102,83 +76,89 @@ var version = "2.0.0"
 // @name						Authorization
 // @description
 func main() {
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "development"
-	}
-
-	if os.Getenv("DOCKER") == "" && env == "development" {
-		_ = godotenv.Load(".env.development")
+	appCfg, err := appconfig.Load()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	hashSalt := os.Getenv("HASHIDS_SALT")
 	hd := hashids.NewData()
-	hd.Salt = hashSalt
+	hd.Salt = appCfg.HashIDSalt
 	hd.MinLength = 8
 	h, err := hashids.NewWithData(hd)
 	if err != nil {
 		log.Fatal("Failed to initialize HashID:", err)
 	}
 
-	// Retrieve and convert maxOpenConns
-	maxOpenConns := 10
-	if v := os.Getenv("DB_MAX_OPEN_CONNS"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			maxOpenConns = n
-		} else {
-			log.Fatalf("Invalid DB_MAX_OPEN_CONNS: %v", err)
-		}
-	}
-
 	cfg := config{
-		addr:        os.Getenv("ADDR"),
-		env:         os.Getenv("ENV"),
-		frontendURL: os.Getenv("FRONTEND_URL"),
-		apiURL:      os.Getenv("EXTERNAL_URL"),
+		addr:        appCfg.Addr,
+		port:        appCfg.Port,
+		env:         appCfg.Env,
+		frontendURL: appCfg.FrontendURL,
+		apiURL:      appCfg.APIURL,
 		db: dbConfig{
-			addr:         os.Getenv("DB_ADDR"),
-			maxOpenConns: maxOpenConns,
-			maxIdleTime:  os.Getenv("DB_MAX_IDLE_TIME"),
+			addr:         appCfg.DB.Addr,
+			maxOpenConns: appCfg.DB.MaxOpenConns,
+			maxIdleTime:  appCfg.DB.MaxIdleTime.String(),
 		},
 		mail: mailConfig{
 			exp:       time.Hour * 24 * 1, //1 days
-			fromEmail: os.Getenv("SENDGRID_FROM_EMAIL"),
+			fromEmail: appCfg.Mail.FromEmail,
 			mailtrap: mailTrapConfig{
-				apiKey: os.Getenv("MAILTRAP_API_KEY"),
+				apiKey: appCfg.Mail.MailtrapAPIKey,
 			},
 		},
 		auth: authConfig{
 			basic: basicConfig{
-				user: os.Getenv("AUTH_BASIC_USER"),
-				pass: os.Getenv("AUTH_BASIC_PASS"),
+				user: appCfg.Auth.BasicUser,
+				pass: appCfg.Auth.BasicPass,
 			},
 			token: tokenConfig{
-				refreshSecret:   os.Getenv("AUTH_TOKEN_REFRESH_SECRET"),
-				secret:          os.Getenv("AUTH_TOKEN_SECRET"),
+				refreshSecret:   appCfg.Auth.RefreshSecret,
+				secret:          appCfg.Auth.TokenSecret,
 				accessTokenExp:  time.Hour * 24 * 1, // 1 days
 				refreshTokenExp: time.Hour * 24 * 2, // 2 days
 				iss:             "Khel",
 			},
 		},
-		rateLimiter: LoadRateLimiterConfig(),
+		rateLimiter: ratelimiter.Config{
+			RequestsPerTimeFrame: appCfg.RateLimiter.RequestsPerMinute,
+			TimeFrame:            1 * time.Minute,
+			Enabled:              appCfg.RateLimiter.Enabled,
+		},
 		payment: paymentConfig{
 			Esewa: esewaConfig{
-				MerchantID: os.Getenv("ESEWA_MERCHANT_ID"),
-				SecretKey:  os.Getenv("ESEWA_SECRET_KEY"),
-				SuccessURL: os.Getenv("ESEWA_SUCCESS_URL"),
-				FailureURL: os.Getenv("ESEWA_FAILURE_URL"),
+				MerchantID: appCfg.Payment.EsewaMerchantID,
+				SecretKey:  appCfg.Payment.EsewaSecretKey,
+				SuccessURL: appCfg.Payment.EsewaSuccessURL,
+				FailureURL: appCfg.Payment.EsewaFailureURL,
 			},
 			Khalti: khaltiConfig{
-				SecretKey:  os.Getenv("KHALTI_SECRET_KEY"),
-				ReturnURL:  os.Getenv("KHALTI_RETURN_URL"),
-				WebsiteURL: os.Getenv("KHALTI_WEBSITE_URL"),
+				SecretKey:  appCfg.Payment.KhaltiSecretKey,
+				ReturnURL:  appCfg.Payment.KhaltiReturnURL,
+				WebsiteURL: appCfg.Payment.KhaltiWebsiteURL,
 			},
 		},
+		store: storeConfig{
+			orderCancelWindow: appCfg.Store.OrderCancelWindow,
+		},
+		bookings: bookingsConfig{
+			pendingTTL: appCfg.Bookings.PendingTTL,
+		},
+		cors: corsConfig{
+			allowedOrigins: appCfg.CORS.AllowedOrigins,
+		},
+		security: securityHeadersConfig{
+			hsts:           appCfg.Security.HSTS,
+			frameAncestors: appCfg.Security.FrameAncestors,
+		},
 		turnstile: turnstileConfig{
-			secretKey:        os.Getenv("TURNSTILE_SECRET_KEY"),
-			expectedHostname: os.Getenv("TURNSTILE_EXPECTED_HOSTNAME"),
+			secretKey:        appCfg.Turnstile.SecretKey,
+			expectedHostname: appCfg.Turnstile.ExpectedHostname,
 		},
 	}
+	if appCfg.Media.Backend == appconfig.MediaLocal {
+		cfg.mediaDir = appCfg.Media.LocalDir
+	}
 
 	// Logger
 	// Create the logger
@@ -189,13 +169,29 @@ func main() {
 	}
 	defer logger.Sync()
 
-	//Unique Order Number Generator with userid embedded
-	orderSecret := os.Getenv("ORDER_NUMBER_SECRET")
-	if orderSecret == "" {
-		logger.Fatal("ORDER_NUMBER_SECRET is required")
+	// Tracing; spans leave the process only when an OTLP endpoint is set
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:    appCfg.Tracing.ServiceName,
+		ServiceVersion: version,
+		Environment:    appCfg.AppEnv,
+		OTLPEndpoint:   appCfg.Tracing.OTLPEndpoint,
+	})
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warnw("failed to flush traces", "error", err)
+		}
+	}()
+	if appCfg.Tracing.OTLPEndpoint != "" {
+		logger.Infow("exporting traces", "endpoint", appCfg.Tracing.OTLPEndpoint, "service", appCfg.Tracing.ServiceName)
 	}
 
-	orderGen := orders.NewOrderNumberGenerator(orderSecret)
+	//Unique Order Number Generator with userid embedded
+	orderGen := orders.NewOrderNumberGenerator(appCfg.OrderNumberSecret)
 
 	// Database
 	dbpool, err := db.New(
@@ -213,19 +209,24 @@ func main() {
 
 	//storage
 
-	storeContainer := storage.NewContainer(dbpool, orderGen)
+	storeContainer := storage.NewContainer(dbpool, orderGen, logger)
+	notifications.SetLogger(logger)
 
-	//cloudinary
-	cloudinaryUrl := os.Getenv("CLOUDINARY_URL")
-	cld, err := cloudinary.NewFromURL(cloudinaryUrl)
-	if err != nil {
-		logger.Fatal(err)
+	//cloudinary, optional when uploads go elsewhere
+	var cld *cloudinary.Cloudinary
+	if appCfg.CloudinaryURL != "" {
+		cld, err = cloudinary.NewFromURL(appCfg.CloudinaryURL)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		cld.Upload.Client.Transport = tracing.Transport(tracing.KindCloudinary, nil)
 	}
 
 	//expo push message (notification)
 
 	httpClient := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout:   10 * time.Second,
+		Transport: tracing.Transport(tracing.KindExpo, nil),
 	}
 
 	client := exponent.NewClient(
@@ -253,6 +254,13 @@ func main() {
 	// 5 req/min per IP
 	venueReqLimiter := ratelimiter.NewFixedWindowLimiter(5, 1*time.Minute)
 
+	// 30 reveals/hour per owner account
+	contactRevealLimiter := ratelimiter.NewFixedWindowLimiter(30, 1*time.Hour)
+
+	// guest checkout: 20 widget calls and 3 texted codes per 10 min per IP
+	guestLimiter := ratelimiter.NewFixedWindowLimiter(20, 10*time.Minute)
+	guestOTPLimiter := ratelimiter.NewFixedWindowLimiter(3, 10*time.Minute)
+
 	// Authenticator
 	jwtAuthenticator := auth.NewJWTAuthenticator(
 		cfg.auth.token.refreshSecret,
@@ -261,7 +269,7 @@ func main() {
 		cfg.auth.token.iss,
 	)
 
-	isProd := os.Getenv("APP_ENV") == "prod"
+	isProd := appCfg.IsProd()
 
 	// Payments
 	pm := payments.NewPaymentManager()
@@ -286,18 +294,64 @@ func main() {
 	)
 
 	app := &application{
-		config:              cfg,
-		logger:              logger,
-		store:               storeContainer,
-		cld:                 cld,
-		mailer:              mailtrap,
-		authenticator:       jwtAuthenticator,
-		rateLimiter:         rateLimiter,
-		venueRequestLimiter: venueReqLimiter,
-		push:                sender,
-		hashID:              h,
-		payments:            pm,
+		config:               cfg,
+		logger:               logger,
+		store:                storeContainer,
+		cld:                  cld,
+		mailer:               mailtrap,
+		authenticator:        jwtAuthenticator,
+		rateLimiter:          rateLimiter,
+		venueRequestLimiter:  venueReqLimiter,
+		contactRevealLimiter: contactRevealLimiter,
+		guestLimiter:         guestLimiter,
+		guestOTPLimiter:      guestOTPLimiter,
+		push:                 sender,
+		hashID:               h,
+		payments:             pm,
+		jobs:                 jobs.NewRunner(storeContainer.Jobs, logger),
+		images:               images.NewProcessor(),
+		moderation:           moderation.NewWordList(moderation.DefaultWords()),
+	}
+	app.events = events.NewBus(app.jobs, storeContainer.Jobs)
+
+	switch appCfg.SMS.Backend {
+	case appconfig.SMSSparrow:
+		app.sms = sms.NewSparrow(appCfg.SMS.SparrowToken, appCfg.SMS.SparrowFrom)
+	case appconfig.SMSLog:
+		app.sms = sms.NewLog(logger)
+	}
+
+	switch appCfg.Search.Backend {
+	case appconfig.SearchMeilisearch:
+		meili := searchindex.NewMeilisearch(appCfg.Search.MeilisearchURL, appCfg.Search.MeilisearchKey, storeContainer.Venues, storeContainer.Products)
+		app.search, app.searchIndexer = meili, meili
+	default:
+		app.search = searchindex.NewPostgres(storeContainer.Venues, storeContainer.Products)
+	}
+	logger.Infow("search backend ready", "backend", app.search.Name())
+
+	// Files uploaded to Cloudinary before a switch can still be deleted.
+	var older []media.ObjectStorage
+	switch appCfg.Media.Backend {
+	case appconfig.MediaS3:
+		app.media = media.NewS3(media.S3Config{
+			Endpoint:        appCfg.Media.S3Endpoint,
+			Region:          appCfg.Media.S3Region,
+			Bucket:          appCfg.Media.S3Bucket,
+			AccessKeyID:     appCfg.Media.S3AccessKeyID,
+			SecretAccessKey: appCfg.Media.S3SecretAccessKey,
+			PublicURL:       appCfg.Media.S3PublicURL,
+		})
+	case appconfig.MediaLocal:
+		app.media = media.NewLocal(appCfg.Media.LocalDir, appCfg.Media.LocalURL)
+	default:
+		app.media = media.NewCloudinary(cld)
+	}
+	if cld != nil && app.media.Name() != "cloudinary" {
+		older = append(older, media.NewCloudinary(cld))
 	}
+	app.media = media.WithFallbacks(app.media, older...)
+	logger.Infow("media storage ready", "backend", app.media.Name())
 
 	//Metrics collected http://localhost:8080/v1/debug/vars
 	expvar.NewString("version").Set(version)
@@ -311,7 +365,8 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	app.markCompletedGamesEvery30Mins(ctx)
+	app.registerJobs()
+	app.jobs.Start(ctx)
 
 	mux := app.mount()
 
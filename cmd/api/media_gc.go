@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"khel/internal/domain/mediaassets"
+	"khel/internal/jobs"
+	"khel/internal/media"
+	"net/http"
+	"time"
+)
+
+const (
+	// mediaOrphanGrace is how old an upload must be before it counts as
+	// orphaned, so a request still saving what it uploaded isn't raced.
+	mediaOrphanGrace = 24 * time.Hour
+	// mediaGCBatch is how many orphans one run deletes.
+	mediaGCBatch = 200
+	// mediaOrphanPreview is how many orphans the dry run lists.
+	mediaOrphanPreview = 100
+)
+
+func (app *application) registerMediaGC() {
+	app.jobs.Register(jobCollectOrphanedMedia, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runCollectOrphanedMedia(ctx)
+	})
+	app.jobs.Every(jobCollectOrphanedMedia, 24*time.Hour)
+}
+
+// recordMediaAsset adds an upload to the registry the collector works
+// from. Files replaced in place under a fixed name are left out: an old
+// URL of theirs points at the current file. Failures are logged; the file
+// is then just never collected.
+func (app *application) recordMediaAsset(obj *media.Object, opts media.UploadOptions) {
+	if opts.Overwrite {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := app.store.MediaAssets.Record(ctx, mediaassets.Asset{
+		URL:     obj.URL,
+		Storage: app.media.Name(),
+		Folder:  opts.Folder,
+		Bytes:   obj.Bytes,
+	})
+	if err != nil {
+		app.logger.Errorw("failed to record media asset", "url", obj.URL, "error", err)
+	}
+}
+
+// adminListOrphanedMediaHandler godoc
+//
+//	@Summary		Preview the orphaned media collection
+//	@Description	Dry run of the nightly collection: counts the uploaded files older than a day that no venue, product, ad, user or other row points at, with their total size, and lists the oldest 100. Nothing is deleted.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{object}	mediaassets.OrphanReport
+//	@Failure		500	{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/media/orphans [get]
+func (app *application) adminListOrphanedMediaHandler(w http.ResponseWriter, r *http.Request) {
+	rep, err := app.store.MediaAssets.OrphanReport(r.Context(), time.Now().Add(-mediaOrphanGrace), mediaOrphanPreview)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, rep)
+}
+
+// runCollectOrphanedMedia deletes a batch of uploads no row points at.
+// Each is claimed first, so one that gained a reference since the listing
+// is kept. A file that fails to delete goes back in the registry for the
+// next run.
+func (app *application) runCollectOrphanedMedia(ctx context.Context) error {
+	orphans, err := app.store.MediaAssets.Orphans(ctx, time.Now().Add(-mediaOrphanGrace), mediaGCBatch)
+	if err != nil {
+		return err
+	}
+
+	var deleted int64
+	for _, a := range orphans {
+		claimed, err := app.store.MediaAssets.Claim(ctx, a.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		err = app.media.Delete(ctx, a.URL)
+		if errors.Is(err, media.ErrNotOwned) {
+			app.logger.Warnw("orphaned media outside media storage", "url", a.URL, "storage", a.Storage)
+			continue
+		}
+		if err != nil {
+			app.logger.Warnw("failed to delete orphaned media", "url", a.URL, "error", err)
+			if err := app.store.MediaAssets.Record(ctx, a); err != nil {
+				return err
+			}
+			continue
+		}
+		deleted++
+	}
+
+	jobs.SetRowsAffected(ctx, deleted)
+	if deleted > 0 {
+		app.logger.Infow("collected orphaned media", "deleted", deleted)
+	}
+	return nil
+}
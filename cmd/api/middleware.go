@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"khel/internal/auth"
 	"khel/internal/domain/accesscontrol"
+	"khel/internal/domain/games"
+	"khel/internal/domain/users"
 	"net"
 	"net/http"
 	"strconv"
@@ -103,8 +105,14 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 			app.unauthorizedErrorResponse(w, r, fmt.Errorf("user not found"))
 			return
 		}
+		// A pending deletion disables the account; signing in again cancels it.
+		if user.DeletionScheduledFor != nil {
+			app.unauthorizedErrorResponse(w, r, users.ErrDeletionPending)
+			return
+		}
 
-		ctx = context.WithValue(ctx, userCtx, user)
+		ctx = withUser(ctx, user)
+		ctx = withImpersonator(ctx, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -149,14 +157,15 @@ func (app *application) optionalAuth(next http.Handler) http.Handler {
 
 		ctx := r.Context()
 		user, err := app.store.Users.GetByID(ctx, userID)
-		if err != nil {
+		if err != nil || user.DeletionScheduledFor != nil {
 			// User not found or other error, but continue without user
 			next.ServeHTTP(w, r)
 			return
 		}
 
 		// If we successfully got the user, add to context
-		ctx = context.WithValue(ctx, userCtx, user)
+		ctx = withUser(ctx, user)
+		ctx = withImpersonator(ctx, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -169,19 +178,18 @@ func (app *application) RequireGameAdminAssistant(next http.Handler) http.Handle
 		gameIDStr := chi.URLParam(r, "gameID")
 		gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 		if err != nil {
-			writeJSONError(w, http.StatusBadRequest, "Invalid game ID")
+			app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 			return
 		}
 
 		// Check if user is admin or assistant
 		isAdminAssistant, err := app.store.Games.IsAdminAssistant(r.Context(), gameID, user.ID)
 		if err != nil {
-			app.logger.Errorf("Error checking admin status: %v", err)
-			writeJSONError(w, http.StatusInternalServerError, "Database error")
+			app.internalServerError(w, r, err)
 			return
 		}
 		if !isAdminAssistant {
-			writeJSONError(w, http.StatusForbidden, "Insufficient privileges")
+			app.forbiddenResponse(w, r)
 			return
 		}
 
@@ -190,6 +198,43 @@ func (app *application) RequireGameAdminAssistant(next http.Handler) http.Handle
 	})
 }
 
+const gameMemberCtx userKey = "game_member"
+
+// RequireGamePermission lets the request through only when the user's role
+// in the game grants perm (see games.Member.Can). The member is put on the
+// context for the handler.
+func (app *application) RequireGamePermission(perm games.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := getUserFromContext(r)
+
+			gameID, err := readIDParam(r, "gameID")
+			if err != nil {
+				app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
+				return
+			}
+
+			member, err := app.store.Games.GetMember(r.Context(), gameID, user.ID)
+			if err != nil && !errors.Is(err, games.ErrNotFound) {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if member == nil || !member.Can(perm) {
+				app.forbiddenResponse(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), gameMemberCtx, member)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func getGameMember(r *http.Request) *games.Member {
+	member, _ := r.Context().Value(gameMemberCtx).(*games.Member)
+	return member
+}
+
 func (app *application) CheckGameAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := getUserFromContext(r)
@@ -198,19 +243,18 @@ func (app *application) CheckGameAdmin(next http.Handler) http.Handler {
 		gameIDStr := chi.URLParam(r, "gameID")
 		gameID, err := strconv.ParseInt(gameIDStr, 10, 64)
 		if err != nil {
-			writeJSONError(w, http.StatusBadRequest, "Invalid game ID")
+			app.badRequestResponse(w, r, fmt.Errorf("invalid game ID"))
 			return
 		}
 
 		// Check if user is admin
 		isAdmin, err := app.store.Games.IsAdmin(r.Context(), gameID, user.ID)
 		if err != nil {
-			app.logger.Errorf("Error checking admin status: %v", err)
-			writeJSONError(w, http.StatusInternalServerError, "Database error")
+			app.internalServerError(w, r, err)
 			return
 		}
 		if !isAdmin {
-			writeJSONError(w, http.StatusForbidden, "Insufficient privileges")
+			app.forbiddenResponse(w, r)
 			return
 		}
 
@@ -346,8 +390,13 @@ func (app *application) AuthTokenIgnoreExpiryMiddleware(next http.Handler) http.
 			app.unauthorizedErrorResponse(w, r, fmt.Errorf("user not found: %w", err))
 			return
 		}
+		if user.DeletionScheduledFor != nil {
+			app.unauthorizedErrorResponse(w, r, users.ErrDeletionPending)
+			return
+		}
 
-		ctx := context.WithValue(r.Context(), userCtx, user)
+		ctx := withUser(r.Context(), user)
+		ctx = withImpersonator(ctx, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
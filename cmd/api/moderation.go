@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/gameqa"
+	"khel/internal/domain/moderationqueue"
+	venuereviews "khel/internal/domain/venuereview"
+	"khel/internal/jobs"
+	"khel/internal/moderation"
+	"khel/internal/params"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// removedProfileName replaces a profile name moderators took down.
+const removedProfileName = "Player"
+
+type screenContentPayload struct {
+	ContentType string `json:"content_type"`
+	ContentID   int64  `json:"content_id"`
+	UserID      int64  `json:"user_id"`
+	Text        string `json:"text"`
+}
+
+// enqueueScreen queues text a user just posted for the moderation checker.
+// Screening after the write keeps a slow checker out of the request, and a
+// failing one is retried by the job runner.
+func (app *application) enqueueScreen(contentType string, contentID, userID int64, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := app.store.Jobs.Enqueue(ctx, jobScreenContent, screenContentPayload{
+		ContentType: contentType,
+		ContentID:   contentID,
+		UserID:      userID,
+		Text:        text,
+	}, jobs.EnqueueOptions{})
+	if err != nil {
+		app.logger.Errorw("failed to enqueue content screening", "content_type", contentType, "content_id", contentID, "error", err)
+	}
+}
+
+func (app *application) runScreenContent(ctx context.Context, p screenContentPayload) error {
+	v, err := app.moderation.Check(ctx, p.Text)
+	if err != nil {
+		return fmt.Errorf("%s check: %w", app.moderation.Name(), err)
+	}
+	if !v.Flagged {
+		return nil
+	}
+	return app.flagContent(ctx, p.ContentType, p.ContentID, p.UserID, p.Text, v)
+}
+
+// flagContent puts content the checker flagged on the moderation queue.
+func (app *application) flagContent(ctx context.Context, contentType string, contentID, userID int64, text string, v moderation.Verdict) error {
+	return app.store.ModerationQueue.Enqueue(ctx, &moderationqueue.Item{
+		ContentType: contentType,
+		ContentID:   contentID,
+		UserID:      &userID,
+		Content:     text,
+		Reason:      v.Reason,
+		Checker:     app.moderation.Name(),
+	})
+}
+
+// screenReview checks a review before it is saved, so a flagged one can be
+// held instead of published. If the checker fails the review goes up and
+// is logged; nothing is held on a guess.
+func (app *application) screenReview(ctx context.Context, text string) moderation.Verdict {
+	if strings.TrimSpace(text) == "" {
+		return moderation.Verdict{}
+	}
+	v, err := app.moderation.Check(ctx, text)
+	if err != nil {
+		app.logger.Warnw("review moderation check failed", "checker", app.moderation.Name(), "error", err)
+		return moderation.Verdict{}
+	}
+	return v
+}
+
+// screenProfileName queues the name fields among updates for screening.
+func (app *application) screenProfileName(userID int64, updates map[string]interface{}) {
+	var parts []string
+	for _, field := range []string{"first_name", "last_name"} {
+		if v, ok := updates[field].(string); ok {
+			parts = append(parts, v)
+		}
+	}
+	if len(parts) > 0 {
+		app.enqueueScreen(moderationqueue.ContentProfileName, userID, userID, strings.Join(parts, " "))
+	}
+}
+
+// adminListModerationHandler godoc
+//
+//	@Summary		List the moderation queue
+//	@Description	Content the moderation checker flagged, oldest first. Defaults to items waiting for a decision; status=all lists every item. Flagged reviews are held until approved; game questions, replies and profile names stay up unless removed.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status			query		string			false	"pending (default), approved, removed or all"
+//	@Param			content_type	query		string			false	"review, game_question, game_reply or profile_name"
+//	@Param			page			query		int				false	"Page number (default: 1)"
+//	@Param			limit			query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200				{object}	map[string]any	"items + pagination metadata"
+//	@Failure		400				{object}	error			"Bad Request"
+//	@Failure		500				{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/moderation [get]
+func (app *application) adminListModerationHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := moderationqueue.Filter{ContentType: strings.TrimSpace(q.Get("content_type"))}
+	switch status := strings.TrimSpace(q.Get("status")); status {
+	case "":
+		f.Status = moderationqueue.StatusPending
+	case "all":
+	case moderationqueue.StatusPending, moderationqueue.StatusApproved, moderationqueue.StatusRemoved:
+		f.Status = status
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("invalid status"))
+		return
+	}
+	switch f.ContentType {
+	case "", moderationqueue.ContentReview, moderationqueue.ContentGameQuestion,
+		moderationqueue.ContentGameReply, moderationqueue.ContentProfileName:
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("invalid content_type"))
+		return
+	}
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.ModerationQueue.List(r.Context(), f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"items":      list,
+		"pagination": pagination,
+	})
+}
+
+// adminApproveModerationHandler godoc
+//
+//	@Summary		Approve flagged content
+//	@Description	Keeps the content up; a held review is published.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			itemID	path		int	true	"Moderation item ID"
+//	@Success		200		{object}	moderationqueue.Item
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Moderation item not found"
+//	@Failure		409		{object}	error	"Item is already decided"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/moderation/{itemID}/approve [post]
+func (app *application) adminApproveModerationHandler(w http.ResponseWriter, r *http.Request) {
+	app.decideModeration(w, r, moderationqueue.StatusApproved, audit.ActionApprove)
+}
+
+// adminRemoveModerationHandler godoc
+//
+//	@Summary		Remove flagged content
+//	@Description	Takes the content down: a held review is rejected, a game question or reply is deleted, and a profile name is replaced with "Player".
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			itemID	path		int	true	"Moderation item ID"
+//	@Success		200		{object}	moderationqueue.Item
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Moderation item not found"
+//	@Failure		409		{object}	error	"Item is already decided, or the review was already moderated"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/moderation/{itemID}/remove [post]
+func (app *application) adminRemoveModerationHandler(w http.ResponseWriter, r *http.Request) {
+	app.decideModeration(w, r, moderationqueue.StatusRemoved, audit.ActionDelete)
+}
+
+func (app *application) decideModeration(w http.ResponseWriter, r *http.Request, status, action string) {
+	id, err := readIDParam(r, "itemID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid moderation item ID"))
+		return
+	}
+
+	ctx := r.Context()
+	item, err := app.store.ModerationQueue.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, moderationqueue.ErrItemNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if item.Status != moderationqueue.StatusPending {
+		app.conflictResponse(w, r, moderationqueue.ErrAlreadyDecided)
+		return
+	}
+
+	admin := getUserFromContext(r)
+	if err := app.applyModeration(ctx, item, status, admin.ID); err != nil {
+		if errors.Is(err, venuereviews.ErrReviewNotHeld) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	decided, err := app.store.ModerationQueue.Decide(ctx, id, status, admin.ID)
+	if err != nil {
+		if errors.Is(err, moderationqueue.ErrAlreadyDecided) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.recordAudit(r, audit.EntityModerationItem, action, id,
+		map[string]string{"status": moderationqueue.StatusPending},
+		map[string]string{"status": status})
+
+	app.jsonResponse(w, http.StatusOK, decided)
+}
+
+// applyModeration carries out the decision on the content itself. Content
+// its author already deleted is left alone.
+func (app *application) applyModeration(ctx context.Context, item *moderationqueue.Item, status string, adminID int64) error {
+	switch item.ContentType {
+	case moderationqueue.ContentReview:
+		reviewStatus := venuereviews.StatusPublished
+		if status == moderationqueue.StatusRemoved {
+			reviewStatus = venuereviews.StatusRejected
+		}
+		err := app.store.VenuesReviews.Moderate(ctx, item.ContentID, adminID, reviewStatus)
+		switch {
+		case errors.Is(err, venuereviews.ErrReviewNotFound):
+			return nil
+		case errors.Is(err, venuereviews.ErrReviewNotHeld) && status == moderationqueue.StatusApproved:
+			// Already published from the review queue
+			return nil
+		}
+		return err
+	}
+
+	if status != moderationqueue.StatusRemoved {
+		return nil
+	}
+	switch item.ContentType {
+	case moderationqueue.ContentGameQuestion:
+		if item.UserID == nil {
+			return nil
+		}
+		err := app.store.GameQA.DeleteQuestion(ctx, item.ContentID, *item.UserID)
+		if errors.Is(err, gameqa.ErrQuestionNotFound) {
+			return nil
+		}
+		return err
+	case moderationqueue.ContentGameReply:
+		err := app.store.GameQA.DeleteReply(ctx, item.ContentID)
+		if errors.Is(err, gameqa.ErrReplyNotFound) {
+			return nil
+		}
+		return err
+	case moderationqueue.ContentProfileName:
+		return app.store.Users.UpdateUser(ctx, item.ContentID, map[string]interface{}{
+			"first_name": removedProfileName,
+			"last_name":  "",
+		})
+	}
+	return fmt.Errorf("unknown content type %q for moderation item %d", item.ContentType, item.ID)
+}
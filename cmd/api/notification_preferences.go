@@ -0,0 +1,85 @@
+package main
+
+import (
+	"khel/internal/domain/notificationprefs"
+	"net/http"
+)
+
+// UpdateNotificationPreferencesPayload - omitted fields keep their current value.
+type UpdateNotificationPreferencesPayload struct {
+	BookingUpdatesPush  *bool `json:"booking_updates_push,omitempty"`
+	BookingUpdatesEmail *bool `json:"booking_updates_email,omitempty"`
+	GameInvitesPush     *bool `json:"game_invites_push,omitempty"`
+	GameInvitesEmail    *bool `json:"game_invites_email,omitempty"`
+	MarketingPush       *bool `json:"marketing_push,omitempty"`
+	MarketingEmail      *bool `json:"marketing_email,omitempty"`
+	RemindersPush       *bool `json:"reminders_push,omitempty"`
+	// Locale is the email language: en or ne.
+	Locale *string `json:"locale,omitempty" validate:"omitempty,oneof=en ne"`
+}
+
+// getNotificationPreferencesHandler godoc
+//
+//	@Summary		Get my notification preferences
+//	@Description	Push and email opt-ins per category. Users who never changed a setting get the defaults.
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	notificationprefs.Preferences
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/notification-preferences [get]
+func (app *application) getNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	prefs, err := app.store.NotificationPrefs.Get(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, prefs)
+}
+
+// updateNotificationPreferencesHandler godoc
+//
+//	@Summary		Update my notification preferences
+//	@Description	Only provided fields change. Account and support messages are always sent. locale (en or ne) sets the email language.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		UpdateNotificationPreferencesPayload	true	"Preferences to change"
+//	@Success		200		{object}	notificationprefs.Preferences
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/notification-preferences [put]
+func (app *application) updateNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	var payload UpdateNotificationPreferencesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	prefs, err := app.store.NotificationPrefs.Update(r.Context(), user.ID, notificationprefs.UpdatePreferences{
+		BookingUpdatesPush:  payload.BookingUpdatesPush,
+		BookingUpdatesEmail: payload.BookingUpdatesEmail,
+		GameInvitesPush:     payload.GameInvitesPush,
+		GameInvitesEmail:    payload.GameInvitesEmail,
+		MarketingPush:       payload.MarketingPush,
+		MarketingEmail:      payload.MarketingEmail,
+		RemindersPush:       payload.RemindersPush,
+		Locale:              payload.Locale,
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, prefs)
+}
@@ -0,0 +1,662 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/organizations"
+	"khel/internal/domain/users"
+	"khel/internal/domain/venueearnings"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+type orgKey string
+
+const orgRoleCtx orgKey = "org_role"
+
+// requireOrgRole lets the request through only when the user is a member of
+// the {orgID} organization with one of the given roles. The role is stored
+// on the context for handlers that need it.
+func (app *application) requireOrgRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			orgID, err := readIDParam(r, "orgID")
+			if err != nil {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+
+			user := getUserFromContext(r)
+			role, err := app.store.Organizations.MemberRole(r.Context(), orgID, user.ID)
+			if err != nil {
+				if errors.Is(err, organizations.ErrNotMember) {
+					app.notFoundResponse(w, r, organizations.ErrNotFound)
+					return
+				}
+				app.internalServerError(w, r, err)
+				return
+			}
+			if !slices.Contains(roles, role) {
+				app.forbiddenResponse(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), orgRoleCtx, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+type CreateOrganizationPayload struct {
+	Name                string  `json:"name" validate:"required,min=2,max=120"`
+	PayoutBankName      *string `json:"payout_bank_name" validate:"omitempty,max=120"`
+	PayoutAccountName   *string `json:"payout_account_name" validate:"omitempty,max=120"`
+	PayoutAccountNumber *string `json:"payout_account_number" validate:"omitempty,max=40"`
+}
+
+// createOrganizationHandler godoc
+//
+//	@Summary		Create an organization
+//	@Description	Creates an organization owned by the current user. Venues the user owns can then be attached to it; venues that are never attached keep working as before.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateOrganizationPayload	true	"Organization"
+//	@Success		201		{object}	organizations.Organization
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations [post]
+func (app *application) createOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateOrganizationPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	org := &organizations.Organization{
+		Name:                payload.Name,
+		OwnerID:             user.ID,
+		PayoutBankName:      cleanOptionalString(payload.PayoutBankName),
+		PayoutAccountName:   cleanOptionalString(payload.PayoutAccountName),
+		PayoutAccountNumber: cleanOptionalString(payload.PayoutAccountNumber),
+		Role:                organizations.RoleOwner,
+	}
+	if err := app.store.Organizations.Create(r.Context(), org); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, org)
+}
+
+// listMyOrganizationsHandler godoc
+//
+//	@Summary		List my organizations
+//	@Description	Lists the organizations the current user belongs to, with their role in each.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Success		200	{array}		organizations.Organization
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations [get]
+func (app *application) listMyOrganizationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	list, err := app.store.Organizations.ListForUser(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// getOrganizationHandler godoc
+//
+//	@Summary		Get an organization
+//	@Description	Payout details are only returned to the owner.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID	path		int	true	"Organization ID"
+//	@Success		200		{object}	organizations.Organization
+//	@Failure		404		{object}	error	"Not a member"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID} [get]
+func (app *application) getOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+	org, err := app.store.Organizations.GetByID(r.Context(), orgID)
+	if err != nil {
+		if errors.Is(err, organizations.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	org.Role, _ = r.Context().Value(orgRoleCtx).(string)
+	if org.Role != organizations.RoleOwner {
+		org.PayoutBankName, org.PayoutAccountName, org.PayoutAccountNumber = nil, nil, nil
+	}
+	app.jsonResponse(w, http.StatusOK, org)
+}
+
+type UpdateOrganizationPayload struct {
+	Name                *string `json:"name" validate:"omitempty,min=2,max=120"`
+	PayoutBankName      *string `json:"payout_bank_name" validate:"omitempty,max=120"`
+	PayoutAccountName   *string `json:"payout_account_name" validate:"omitempty,max=120"`
+	PayoutAccountNumber *string `json:"payout_account_number" validate:"omitempty,max=40"`
+	DisplayName         *string `json:"display_name" validate:"omitempty,max=60"`
+	BrandColor          *string `json:"brand_color" validate:"omitempty,hexcolor,len=7"`
+}
+
+// updateOrganizationHandler godoc
+//
+//	@Summary		Update an organization
+//	@Description	Owner only. Omitted fields are left unchanged. display_name and brand_color (#RRGGBB) are shown on the organization's venues and in their booking emails.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			orgID	path		int							true	"Organization ID"
+//	@Param			payload	body		UpdateOrganizationPayload	true	"Fields to change"
+//	@Success		200		{object}	organizations.Organization
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not Found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID} [patch]
+func (app *application) updateOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+
+	var payload UpdateOrganizationPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.Name != nil {
+		name := strings.TrimSpace(*payload.Name)
+		payload.Name = &name
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	org, err := app.store.Organizations.Update(r.Context(), orgID, organizations.UpdateInput{
+		Name:                payload.Name,
+		PayoutBankName:      cleanOptionalString(payload.PayoutBankName),
+		PayoutAccountName:   cleanOptionalString(payload.PayoutAccountName),
+		PayoutAccountNumber: cleanOptionalString(payload.PayoutAccountNumber),
+		DisplayName:         cleanOptionalString(payload.DisplayName),
+		BrandColor:          cleanOptionalString(payload.BrandColor),
+	})
+	if err != nil {
+		if errors.Is(err, organizations.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	org.Role = organizations.RoleOwner
+	app.jsonResponse(w, http.StatusOK, org)
+}
+
+// uploadOrganizationLogoHandler godoc
+//
+//	@Summary		Upload an organization logo
+//	@Description	Owner only. Replaces the current logo, which is then deleted from storage. JPEG, PNG or WebP up to 2MB.
+//	@Tags			Organizations
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			orgID	path		int		true	"Organization ID"
+//	@Param			logo	formData	file	true	"Logo image"
+//	@Success		200		{object}	organizations.Organization
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not Found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/logo [put]
+func (app *application) uploadOrganizationLogoHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+
+	if err := r.ParseMultipartForm(2 << 20); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("unable to parse form, file size limit is 2MB"))
+		return
+	}
+	file, header, err := r.FormFile("logo")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("logo file is required"))
+		return
+	}
+	defer file.Close()
+
+	if !app.isValidAdImageType(header.Header.Get("Content-Type")) {
+		app.badRequestResponse(w, r, fmt.Errorf("only JPEG, PNG and WebP images are allowed"))
+		return
+	}
+
+	folder := "testOrganizations"
+	if env := os.Getenv("APP_ENV"); env == "prod" || env == "production" {
+		folder = "organizations"
+	}
+	url, err := app.uploadFileWithID(file, fmt.Sprintf("org_%d_logo_%d", orgID, time.Now().UnixNano()), folder)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	old, err := app.store.Organizations.SetLogo(r.Context(), orgID, url)
+	if err != nil {
+		app.enqueuePhotoDelete(url)
+		if errors.Is(err, organizations.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.enqueuePhotoDelete(old)
+
+	org, err := app.store.Organizations.GetByID(r.Context(), orgID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	org.Role = organizations.RoleOwner
+	app.jsonResponse(w, http.StatusOK, org)
+}
+
+// listOrganizationMembersHandler godoc
+//
+//	@Summary		List organization members
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID	path		int	true	"Organization ID"
+//	@Success		200		{array}		organizations.Member
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not Found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/members [get]
+func (app *application) listOrganizationMembersHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+	members, err := app.store.Organizations.ListMembers(r.Context(), orgID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, members)
+}
+
+type AddOrganizationMemberPayload struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=manager staff"`
+}
+
+// addOrganizationMemberHandler godoc
+//
+//	@Summary		Add a member to an organization
+//	@Description	Owner only. The user is looked up by email and must already have an account. Managers can manage the organization's venues and see its dashboard; staff have read-only membership.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			orgID	path		int								true	"Organization ID"
+//	@Param			payload	body		AddOrganizationMemberPayload	true	"Member"
+//	@Success		201		{object}	map[string]string
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"User not found"
+//	@Failure		409		{object}	error	"Already a member"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/members [post]
+func (app *application) addOrganizationMemberHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+
+	var payload AddOrganizationMemberPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Email = strings.ToLower(strings.TrimSpace(payload.Email))
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	member, err := app.store.Users.GetByEmail(r.Context(), payload.Email)
+	if err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			app.notFoundResponse(w, r, fmt.Errorf("no user with that email"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Organizations.AddMember(r.Context(), orgID, member.ID, payload.Role); err != nil {
+		switch {
+		case errors.Is(err, organizations.ErrAlreadyMember):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, organizations.ErrMemberNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, map[string]string{"message": "member added"})
+}
+
+type UpdateOrganizationMemberPayload struct {
+	Role string `json:"role" validate:"required,oneof=manager staff"`
+}
+
+// updateOrganizationMemberHandler godoc
+//
+//	@Summary		Change a member's role
+//	@Description	Owner only. The owner's own role cannot be changed.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			orgID	path		int								true	"Organization ID"
+//	@Param			userID	path		int								true	"Member user ID"
+//	@Param			payload	body		UpdateOrganizationMemberPayload	true	"New role"
+//	@Success		200		{object}	map[string]string
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Member not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/members/{userID} [patch]
+func (app *application) updateOrganizationMemberHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+	userID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload UpdateOrganizationMemberPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Organizations.UpdateMemberRole(r.Context(), orgID, userID, payload.Role); err != nil {
+		if errors.Is(err, organizations.ErrMemberNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "member updated"})
+}
+
+// removeOrganizationMemberHandler godoc
+//
+//	@Summary		Remove a member from an organization
+//	@Description	Owner only. The owner cannot be removed.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID	path	int	true	"Organization ID"
+//	@Param			userID	path	int	true	"Member user ID"
+//	@Success		204		"No Content"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Member not found"
+//	@Failure		409		{object}	error	"Owner cannot be removed"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/members/{userID} [delete]
+func (app *application) removeOrganizationMemberHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+	userID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Organizations.RemoveMember(r.Context(), orgID, userID); err != nil {
+		switch {
+		case errors.Is(err, organizations.ErrLastOwner):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, organizations.ErrMemberNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listOrganizationVenuesHandler godoc
+//
+//	@Summary		List an organization's venues
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID	path		int	true	"Organization ID"
+//	@Success		200		{array}		organizations.Venue
+//	@Failure		404		{object}	error	"Not Found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/venues [get]
+func (app *application) listOrganizationVenuesHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+	list, err := app.store.Organizations.ListVenues(r.Context(), orgID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// attachOrganizationVenueHandler godoc
+//
+//	@Summary		Move a venue into an organization
+//	@Description	Owner only, and only for venues the owner owns directly. The venue's owner_id is unchanged; managers of the organization can then manage it too.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID	path		int	true	"Organization ID"
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	map[string]string
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Venue not found or not owned"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/venues/{venueID} [put]
+func (app *application) attachOrganizationVenueHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.Organizations.AttachVenue(r.Context(), orgID, venueID, user.ID); err != nil {
+		if errors.Is(err, organizations.ErrVenueNotOwned) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "venue added to organization"})
+}
+
+// detachOrganizationVenueHandler godoc
+//
+//	@Summary		Remove a venue from an organization
+//	@Description	Owner only. The venue goes back to being managed by its owner alone.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID	path	int	true	"Organization ID"
+//	@Param			venueID	path	int	true	"Venue ID"
+//	@Success		204		"No Content"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Venue not in organization"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/venues/{venueID} [delete]
+func (app *application) detachOrganizationVenueHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, _ := readIDParam(r, "orgID")
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Organizations.DetachVenue(r.Context(), orgID, venueID); err != nil {
+		if errors.Is(err, organizations.ErrVenueNotInOrg) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseOrganizationPeriod reads the same period/start_date/end_date query
+// parameters as the venue earnings endpoint.
+func parseOrganizationPeriod(r *http.Request) (time.Time, time.Time, error) {
+	period := strings.TrimSpace(r.URL.Query().Get("period"))
+	if period == "" {
+		period = string(venueearnings.PeriodToday)
+	}
+	if !venueearnings.IsValidPeriod(period) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+	return parseVenueEarningDateRange(r, venueearnings.Period(period))
+}
+
+// getOrganizationDashboardHandler godoc
+//
+//	@Summary		Organization dashboard
+//	@Description	Earnings across all of the organization's venues, with a per-venue breakdown. Uses the same periods and money rules as venue earnings.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID		path		int		true	"Organization ID"
+//	@Param			period		query		string	false	"Earning period"	Enums(today,this_week,last_month,custom)	default(today)
+//	@Param			start_date	query		string	false	"Start date for custom period. Format: YYYY-MM-DD"
+//	@Param			end_date	query		string	false	"End date for custom period. Format: YYYY-MM-DD"
+//	@Success		200			{object}	organizations.Dashboard
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Not Found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/dashboard [get]
+func (app *application) getOrganizationDashboardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	orgID, _ := readIDParam(r, "orgID")
+	from, to, err := parseOrganizationPeriod(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	venues, err := app.store.Organizations.Earnings(ctx, orgID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	dash := organizations.Dashboard{
+		OrganizationID: orgID,
+		StartDate:      from,
+		EndDate:        to,
+		Venues:         venues,
+	}
+	for _, v := range venues {
+		dash.TotalBookings += v.TotalBookings
+		dash.TotalEarning += v.TotalEarning
+	}
+	app.jsonResponse(w, http.StatusOK, dash)
+}
+
+// getOrganizationSettlementHandler godoc
+//
+//	@Summary		Organization settlement
+//	@Description	Owner only. What the platform owes the organization for the period: online payments across its venues, to be paid to the organization's payout account. Cash collected at the venues is shown for reference.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			orgID		path		int		true	"Organization ID"
+//	@Param			period		query		string	false	"Settlement period"	Enums(today,this_week,last_month,custom)	default(today)
+//	@Param			start_date	query		string	false	"Start date for custom period. Format: YYYY-MM-DD"
+//	@Param			end_date	query		string	false	"End date for custom period. Format: YYYY-MM-DD"
+//	@Success		200			{object}	organizations.Settlement
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Not Found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/organizations/{orgID}/settlements [get]
+func (app *application) getOrganizationSettlementHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	orgID, _ := readIDParam(r, "orgID")
+	from, to, err := parseOrganizationPeriod(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	org, err := app.store.Organizations.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, organizations.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	venues, err := app.store.Organizations.Earnings(ctx, orgID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	s := organizations.Settlement{
+		OrganizationID:      orgID,
+		StartDate:           from,
+		EndDate:             to,
+		PayoutBankName:      org.PayoutBankName,
+		PayoutAccountName:   org.PayoutAccountName,
+		PayoutAccountNumber: org.PayoutAccountNumber,
+		Venues:              venues,
+	}
+	for _, v := range venues {
+		s.Payable += v.OnlineEarning
+		s.CollectedAtVenue += v.CashEarning
+	}
+	app.jsonResponse(w, http.StatusOK, s)
+}
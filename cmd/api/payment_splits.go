@@ -0,0 +1,564 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/paymentsplits"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"khel/internal/payments"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// splitReminderEvery is how often an unpaid participant is nudged.
+	splitReminderEvery     = 24 * time.Hour
+	splitReminderBatchSize = 500
+)
+
+type SplitShareInput struct {
+	UserID int64 `json:"user_id" validate:"required,gt=0"`
+	Amount int   `json:"amount" validate:"omitempty,gt=0"`
+}
+
+type CreatePaymentSplitPayload struct {
+	Shares []SplitShareInput `json:"shares" validate:"required,min=1,max=30,dive"`
+}
+
+// createPaymentSplitHandler godoc
+//
+//	@Summary		Split a booking's cost between players
+//	@Description	The booking's owner splits its price between participants. Give every share an amount (they must add up to the booking price) or none to split equally; the remainder of an equal split goes to the owner. The owner is added automatically if missing. Each participant pays their own share; when all are paid a pending booking is confirmed. Unpaid participants get a push reminder once a day until the booking starts.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			bookingID	path		string						true	"Booking ID (hash or numeric)"
+//	@Param			payload		body		CreatePaymentSplitPayload	true	"Participants"
+//	@Success		201			{object}	paymentsplits.Split
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Booking or participant not found"
+//	@Failure		409			{object}	error	"Booking already split or not splittable"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/bookings/{bookingID}/split [post]
+func (app *application) createPaymentSplitHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload CreatePaymentSplitPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	booking, err := app.store.Bookings.GetBookingByID(r.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if booking.UserID != user.ID {
+		app.notFoundResponse(w, r, bookings.ErrNotFound)
+		return
+	}
+	if booking.Status != "pending" && booking.Status != "confirmed" {
+		app.conflictResponse(w, r, fmt.Errorf("a %s booking cannot be split", booking.Status))
+		return
+	}
+	if !booking.StartTime.After(time.Now()) {
+		app.conflictResponse(w, r, fmt.Errorf("booking has already started"))
+		return
+	}
+
+	shares, err := buildSplitShares(user.ID, booking.TotalPrice, payload.Shares)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	split, err := app.store.PaymentSplits.Create(r.Context(), booking.ID, user.ID, booking.TotalPrice, shares)
+	if err != nil {
+		switch {
+		case errors.Is(err, paymentsplits.ErrAlreadyExists), errors.Is(err, paymentsplits.ErrSharesPaid):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, paymentsplits.ErrUserNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, paymentsplits.ErrAmountMismatch):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.notifySplitParticipants(user.FirstName, booking.VenueID, split)
+	app.jsonResponse(w, http.StatusCreated, split)
+}
+
+// buildSplitShares checks the requested shares and fills in amounts for an
+// equal split. The owner always has a share.
+func buildSplitShares(ownerID int64, total int, in []SplitShareInput) ([]paymentsplits.ShareInput, error) {
+	seen := map[int64]bool{}
+	withAmount := 0
+	for _, s := range in {
+		if seen[s.UserID] {
+			return nil, fmt.Errorf("user %d is listed twice", s.UserID)
+		}
+		seen[s.UserID] = true
+		if s.Amount > 0 {
+			withAmount++
+		}
+	}
+	if withAmount != 0 && withAmount != len(in) {
+		return nil, fmt.Errorf("give every share an amount or none")
+	}
+
+	shares := make([]paymentsplits.ShareInput, 0, len(in)+1)
+	if !seen[ownerID] {
+		if withAmount > 0 {
+			return nil, fmt.Errorf("include your own share when setting amounts")
+		}
+		shares = append(shares, paymentsplits.ShareInput{UserID: ownerID})
+	}
+	for _, s := range in {
+		shares = append(shares, paymentsplits.ShareInput{UserID: s.UserID, Amount: s.Amount})
+	}
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("add at least one other player")
+	}
+	if len(shares) > paymentsplits.MaxShares {
+		return nil, fmt.Errorf("a booking can be split between at most %d players", paymentsplits.MaxShares)
+	}
+
+	if withAmount == 0 {
+		each := total / len(shares)
+		if each == 0 {
+			return nil, fmt.Errorf("booking price is too low to split %d ways", len(shares))
+		}
+		for i := range shares {
+			shares[i].Amount = each
+			if shares[i].UserID == ownerID {
+				shares[i].Amount += total - each*len(shares)
+			}
+		}
+	}
+	return shares, nil
+}
+
+func (app *application) notifySplitParticipants(organizer string, venueID int64, split *paymentsplits.Split) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		venue, err := app.store.Venues.GetVenueByID(ctx, venueID)
+		if err != nil {
+			app.logger.Errorw("failed to load venue for split notification", "venue_id", venueID, "error", err)
+			return
+		}
+		if err := notifications.SendSplitRequest(ctx, app.push, app.store, organizer, venue.Name, split); err != nil {
+			app.logger.Errorw("failed to send split notification", "booking_id", split.BookingID, "error", err)
+		}
+	}()
+}
+
+// loadSplitForUser returns the booking's split if the user owns the booking
+// or has a share in it.
+func (app *application) loadSplitForUser(ctx context.Context, bookingID, userID int64) (*paymentsplits.Split, error) {
+	split, err := app.store.PaymentSplits.GetByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if split.CreatedBy == userID {
+		return split, nil
+	}
+	for _, sh := range split.Shares {
+		if sh.UserID == userID {
+			return split, nil
+		}
+	}
+	return nil, paymentsplits.ErrNotFound
+}
+
+// getPaymentSplitHandler godoc
+//
+//	@Summary		Get a booking's payment split
+//	@Description	Visible to the booking's owner and its participants.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			bookingID	path		string	true	"Booking ID (hash or numeric)"
+//	@Success		200			{object}	paymentsplits.Split
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Split not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/bookings/{bookingID}/split [get]
+func (app *application) getPaymentSplitHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	split, err := app.loadSplitForUser(r.Context(), bookingID, user.ID)
+	if err != nil {
+		if errors.Is(err, paymentsplits.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, split)
+}
+
+// cancelPaymentSplitHandler godoc
+//
+//	@Summary		Cancel a booking's payment split
+//	@Description	The booking's owner can cancel an open split as long as nobody has paid yet. The booking itself is untouched.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			bookingID	path	string	true	"Booking ID (hash or numeric)"
+//	@Success		204			"No Content"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Split not found"
+//	@Failure		409			{object}	error	"Split is closed or has paid shares"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/bookings/{bookingID}/split [delete]
+func (app *application) cancelPaymentSplitHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	split, err := app.store.PaymentSplits.GetByBooking(r.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, paymentsplits.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if split.CreatedBy != user.ID {
+		app.notFoundResponse(w, r, paymentsplits.ErrNotFound)
+		return
+	}
+
+	if err := app.store.PaymentSplits.Cancel(r.Context(), bookingID); err != nil {
+		switch {
+		case errors.Is(err, paymentsplits.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, paymentsplits.ErrSplitClosed), errors.Is(err, paymentsplits.ErrSharesPaid):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// errBookingNotPayable is returned for shares of a booking that was
+// canceled, rejected or has already started.
+var errBookingNotPayable = errors.New("booking is no longer open for payment")
+
+// loadShareForPayment returns the user's own share. An unpaid share is only
+// returned while its split is open and the booking is pending or confirmed
+// and hasn't started; otherwise ErrSplitClosed or errBookingNotPayable.
+func (app *application) loadShareForPayment(ctx context.Context, shareID, userID int64) (*paymentsplits.Share, error) {
+	share, err := app.store.PaymentSplits.GetShare(ctx, shareID)
+	if err != nil {
+		return nil, err
+	}
+	if share.UserID != userID {
+		return nil, paymentsplits.ErrShareNotFound
+	}
+	if share.Status == paymentsplits.SharePaid {
+		return share, nil
+	}
+
+	split, err := app.store.PaymentSplits.GetByBooking(ctx, share.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	if split.ID != share.SplitID || split.Status != paymentsplits.StatusOpen {
+		return nil, paymentsplits.ErrSplitClosed
+	}
+
+	booking, err := app.store.Bookings.GetBookingByID(ctx, share.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != "pending" && booking.Status != "confirmed" {
+		return nil, errBookingNotPayable
+	}
+	if !booking.StartTime.After(time.Now()) {
+		return nil, errBookingNotPayable
+	}
+	return share, nil
+}
+
+// shareLoadError writes the response for a loadShareForPayment error.
+func (app *application) shareLoadError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, paymentsplits.ErrShareNotFound):
+		app.notFoundResponse(w, r, err)
+	case errors.Is(err, paymentsplits.ErrSplitClosed), errors.Is(err, errBookingNotPayable):
+		app.conflictResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+type PayShareRequest struct {
+	Method string `json:"method" validate:"required,oneof=khalti esewa"`
+}
+
+// payShareHandler godoc
+//
+//	@Summary		Pay my share of a split booking
+//	@Description	Starts an online payment for the user's share and returns the gateway link (payment_url, plus form fields for eSewa). After the gateway returns, call the verify endpoint with the gateway data.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			shareID	path		int				true	"Share ID"
+//	@Param			payload	body		PayShareRequest	true	"Gateway"
+//	@Success		200		{object}	map[string]any
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Share not found"
+//	@Failure		409		{object}	error	"Share already paid, split closed or booking no longer payable"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/payment-shares/{shareID}/pay [post]
+func (app *application) payShareHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	shareID, err := readIDParam(r, "shareID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload PayShareRequest
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Method = strings.ToLower(strings.TrimSpace(payload.Method))
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	share, err := app.loadShareForPayment(ctx, shareID, user.ID)
+	if err != nil {
+		app.shareLoadError(w, r, err)
+		return
+	}
+	if share.Status == paymentsplits.SharePaid {
+		app.conflictResponse(w, r, paymentsplits.ErrAlreadyPaid)
+		return
+	}
+
+	resp, err := app.payments.InitiatePayment(ctx, payload.Method, payments.PaymentRequest{
+		Amount:        float64(share.Amount),
+		TransactionID: fmt.Sprintf("split-share-%d", share.ID),
+		ProductName:   fmt.Sprintf("Booking share #%d", share.ID),
+		CustomerName:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+		CustomerEmail: user.Email,
+	})
+	if err != nil {
+		app.internalServerError(w, r, fmt.Errorf("payment init: %w", err))
+		return
+	}
+
+	ref := resp.Data["pidx"]
+	if payload.Method == "esewa" {
+		ref = resp.Data["transaction_uuid"]
+	}
+	if err := app.store.PaymentSplits.SetShareProvider(ctx, share.ID, payload.Method, ref); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"share_id":     share.ID,
+		"amount":       share.Amount,
+		"payment_url":  resp.PaymentURL,
+		"payment_data": resp.Data,
+	})
+}
+
+// errPaymentMismatch means the gateway confirmed a payment other than the
+// one started, or for a different amount.
+var errPaymentMismatch = errors.New("payment does not match the one started")
+
+// verifyStoredPayment checks the payment started with provider under ref
+// with the gateway. Only the stored reference is sent, never one from the
+// client, so a caller can't pass off some other completed payment; and a
+// completed payment only counts when the gateway reports that same
+// reference and amount (in rupees).
+func (app *application) verifyStoredPayment(ctx context.Context, provider, ref string, amount int) (payments.PaymentVerifyResponse, error) {
+	data := map[string]string{}
+	switch provider {
+	case "khalti":
+		data["pidx"] = ref
+	case "esewa":
+		data["transaction_uuid"] = ref
+		data["total_amount"] = fmt.Sprintf("%.2f", float64(amount))
+		data["product_code"] = app.config.payment.Esewa.MerchantID
+	}
+
+	ver, err := app.payments.VerifyPayment(ctx, provider, payments.PaymentVerifyRequest{
+		TransactionID: ref,
+		Data:          data,
+	})
+	if err != nil {
+		return ver, err
+	}
+	if ver.Success && (ver.ProviderRef != ref || math.Round(ver.Amount*100) != float64(amount*100)) {
+		return ver, errPaymentMismatch
+	}
+	return ver, nil
+}
+
+// verifyShareHandler godoc
+//
+//	@Summary		Verify a share payment
+//	@Description	Re-checks the share's latest payment with the gateway, using the reference stored when it started; a request body is ignored. A completed payment marks the share paid, provided the gateway reports that same payment for the share's amount; when it is the last one the split is settled and a pending booking is confirmed. Pending gateway states leave the share unpaid so the call can be retried.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			shareID	path		int	true	"Share ID"
+//	@Success		200		{object}	map[string]any
+//	@Failure		400		{object}	error	"Bad Request or payment doesn't match the share"
+//	@Failure		404		{object}	error	"Share not found"
+//	@Failure		409		{object}	error	"Split closed or booking no longer payable"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/payment-shares/{shareID}/verify [post]
+func (app *application) verifyShareHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	shareID, err := readIDParam(r, "shareID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	share, err := app.loadShareForPayment(ctx, shareID, user.ID)
+	if err != nil {
+		app.shareLoadError(w, r, err)
+		return
+	}
+	if share.Status == paymentsplits.SharePaid {
+		app.jsonResponse(w, http.StatusOK, map[string]any{"success": true, "idempotent": true})
+		return
+	}
+	if share.Provider == nil || share.ProviderRef == nil {
+		app.badRequestResponse(w, r, fmt.Errorf("no payment started for this share"))
+		return
+	}
+
+	ver, err := app.verifyStoredPayment(ctx, *share.Provider, *share.ProviderRef, share.Amount)
+	if err != nil {
+		if errors.Is(err, errPaymentMismatch) {
+			app.requestLogger(r).Warnw("share payment doesn't match the share", "share_id", share.ID,
+				"ref", ver.ProviderRef, "amount", ver.Amount, "error", err)
+		}
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if !ver.Success {
+		app.jsonResponse(w, http.StatusOK, map[string]any{
+			"success":  false,
+			"terminal": ver.Terminal,
+			"state":    ver.State,
+		})
+		return
+	}
+
+	res, err := app.store.PaymentSplits.MarkSharePaid(ctx, share.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if res.Confirmed {
+		app.notifySplitBookingConfirmed(res.BookingID)
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"success":   true,
+		"settled":   res.Settled,
+		"confirmed": res.Confirmed,
+	})
+}
+
+// notifySplitBookingConfirmed tells the booking's owner and the venue owner
+// that the fully paid booking is confirmed.
+func (app *application) notifySplitBookingConfirmed(bookingID int64) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		b, err := app.store.Bookings.GetBookingByID(ctx, bookingID)
+		if err != nil {
+			app.logger.Errorw("failed to load booking for split confirmation", "booking_id", bookingID, "error", err)
+			return
+		}
+		encoded := app.EncodeBookingID(bookingID)
+		if err := notifications.SendBookingNotification(ctx, app.push, app.store, b.UserID, notifications.BookingAccepted, encoded); err != nil {
+			app.logger.Errorw("failed to send split confirmation", "booking_id", bookingID, "error", err)
+		}
+		ownerID, err := app.store.Venues.GetOwnerIDFromVenueID(ctx, b.VenueID)
+		if err != nil {
+			app.logger.Errorw("failed to load venue owner for split confirmation", "venue_id", b.VenueID, "error", err)
+			return
+		}
+		if err := notifications.SendBookingNotification(ctx, app.push, app.store, ownerID, notifications.BookingAccepted, encoded); err != nil {
+			app.logger.Errorw("failed to send split confirmation to owner", "booking_id", bookingID, "error", err)
+		}
+	}()
+}
+
+// runSendSplitReminders pushes a daily nudge to participants who haven't
+// paid their share of an upcoming booking.
+func (app *application) runSendSplitReminders(ctx context.Context) error {
+	due, err := app.store.PaymentSplits.ClaimReminders(ctx, splitReminderEvery, splitReminderBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, rm := range due {
+		if err := notifications.SendSplitReminder(ctx, app.push, app.store, rm); err != nil {
+			app.logger.Warnw("failed to push split reminder", "share_id", rm.ShareID, "error", err)
+		}
+	}
+	jobs.SetRowsAffected(ctx, int64(len(due)))
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/paymentsplits"
+	"khel/internal/domain/storage"
+	"khel/internal/domain/users"
+	"khel/internal/payments"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestPayShareHandler(t *testing.T) {
+	const (
+		userID    = int64(7)
+		bookingID = int64(40)
+		splitID   = int64(3)
+		shareID   = int64(11)
+	)
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		splitStatus  string
+		shareOwner   int64  // defaults to userID
+		shareStatus  string // defaults to pending
+		booking      bookings.Booking
+		wantStatus   int
+		wantInitiate bool
+	}{
+		{
+			name:         "open split on upcoming booking",
+			splitStatus:  paymentsplits.StatusOpen,
+			booking:      bookings.Booking{ID: bookingID, Status: "pending", StartTime: tomorrow},
+			wantStatus:   http.StatusOK,
+			wantInitiate: true,
+		},
+		{
+			name:        "someone else's share",
+			splitStatus: paymentsplits.StatusOpen,
+			shareOwner:  userID + 1,
+			booking:     bookings.Booking{ID: bookingID, Status: "pending", StartTime: tomorrow},
+			wantStatus:  http.StatusNotFound,
+		},
+		{
+			name:        "share already paid",
+			splitStatus: paymentsplits.StatusOpen,
+			shareStatus: paymentsplits.SharePaid,
+			booking:     bookings.Booking{ID: bookingID, Status: "pending", StartTime: tomorrow},
+			wantStatus:  http.StatusConflict,
+		},
+		{
+			name:        "canceled split",
+			splitStatus: paymentsplits.StatusCanceled,
+			booking:     bookings.Booking{ID: bookingID, Status: "pending", StartTime: tomorrow},
+			wantStatus:  http.StatusConflict,
+		},
+		{
+			name:        "rejected booking",
+			splitStatus: paymentsplits.StatusOpen,
+			booking:     bookings.Booking{ID: bookingID, Status: "rejected", StartTime: tomorrow},
+			wantStatus:  http.StatusConflict,
+		},
+		{
+			name:        "booking already started",
+			splitStatus: paymentsplits.StatusOpen,
+			booking:     bookings.Booking{ID: bookingID, Status: "confirmed", StartTime: time.Now().Add(-time.Minute)},
+			wantStatus:  http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, status := userID, paymentsplits.SharePending
+			if tt.shareOwner != 0 {
+				owner = tt.shareOwner
+			}
+			if tt.shareStatus != "" {
+				status = tt.shareStatus
+			}
+			splits := &fakePaymentSplits{
+				splits: map[int64]*paymentsplits.Split{
+					bookingID: {ID: splitID, BookingID: bookingID, Status: tt.splitStatus},
+				},
+				shares: map[int64]*paymentsplits.Share{
+					shareID: {ID: shareID, SplitID: splitID, BookingID: bookingID, UserID: owner, Amount: 500, Status: status},
+				},
+			}
+			pay := &fakePayments{resp: payments.PaymentResponse{
+				PaymentURL: "https://gateway.example/pay",
+				Data:       map[string]string{"pidx": "pidx-1"},
+			}}
+			app := newTestApplication(&storage.Container{
+				Bookings:      &fakeBookings{byID: map[int64]*bookings.Booking{bookingID: &tt.booking}},
+				PaymentSplits: splits,
+			}, pay)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/users/payment-shares/11/pay", strings.NewReader(`{"method":"khalti"}`))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("shareID", "11")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = withUser(ctx, &users.User{ID: userID})
+			rec := httptest.NewRecorder()
+
+			app.payShareHandler(rec, req.WithContext(ctx))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !tt.wantInitiate {
+				if len(pay.initiated) != 0 {
+					t.Errorf("started %d gateway payments, want none", len(pay.initiated))
+				}
+				return
+			}
+			if len(pay.initiated) != 1 || pay.initiated[0].Amount != 500 {
+				t.Fatalf("gateway payments = %+v, want one for 500", pay.initiated)
+			}
+			if got := splits.providerSet[shareID]; got != "pidx-1" {
+				t.Errorf("provider ref = %q, want pidx-1", got)
+			}
+		})
+	}
+}
+
+func TestVerifyShareHandler(t *testing.T) {
+	const (
+		userID    = int64(7)
+		bookingID = int64(40)
+		splitID   = int64(3)
+		shareID   = int64(11)
+	)
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		verify     payments.PaymentVerifyResponse
+		wantStatus int
+		wantPaid   bool
+	}{
+		{
+			name:       "gateway confirms the started payment",
+			verify:     payments.PaymentVerifyResponse{Success: true, ProviderRef: "pidx-1", Amount: 500},
+			wantStatus: http.StatusOK,
+			wantPaid:   true,
+		},
+		{
+			name:       "gateway confirms another payment",
+			verify:     payments.PaymentVerifyResponse{Success: true, ProviderRef: "pidx-2", Amount: 500},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "gateway confirms another amount",
+			verify:     payments.PaymentVerifyResponse{Success: true, ProviderRef: "pidx-1", Amount: 10},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "payment still pending",
+			verify:     payments.PaymentVerifyResponse{State: "Pending", ProviderRef: "pidx-1"},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, ref := "khalti", "pidx-1"
+			booking := bookings.Booking{ID: bookingID, Status: "pending", StartTime: tomorrow}
+			splits := &fakePaymentSplits{
+				splits: map[int64]*paymentsplits.Split{
+					bookingID: {ID: splitID, BookingID: bookingID, Status: paymentsplits.StatusOpen},
+				},
+				shares: map[int64]*paymentsplits.Share{
+					shareID: {ID: shareID, SplitID: splitID, BookingID: bookingID, UserID: userID, Amount: 500,
+						Status: paymentsplits.SharePending, Provider: &provider, ProviderRef: &ref},
+				},
+			}
+			pay := &fakePayments{verify: tt.verify}
+			app := newTestApplication(&storage.Container{
+				Bookings:      &fakeBookings{byID: map[int64]*bookings.Booking{bookingID: &booking}},
+				PaymentSplits: splits,
+			}, pay)
+
+			// The client's gateway data names someone else's payment; it
+			// must not be what gets checked.
+			body := `{"data":{"pidx":"pidx-2"}}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/users/payment-shares/11/verify", strings.NewReader(body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("shareID", "11")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = withUser(ctx, &users.User{ID: userID})
+			rec := httptest.NewRecorder()
+
+			app.verifyShareHandler(rec, req.WithContext(ctx))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if len(pay.verified) != 1 || pay.verified[0].TransactionID != ref || pay.verified[0].Data["pidx"] != ref {
+				t.Errorf("gateway checks = %+v, want one for %s", pay.verified, ref)
+			}
+			if paid := len(splits.paid) > 0; paid != tt.wantPaid {
+				t.Errorf("share marked paid = %v, want %v", paid, tt.wantPaid)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"khel/internal/audit"
+	"khel/internal/domain/photomigrations"
+	"khel/internal/jobs"
+	"khel/internal/media"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// photoMigrationBackoff is how long a migration waits after Cloudinary says
+// it's being called too often.
+const photoMigrationBackoff = 15 * time.Minute
+
+type migrateVenuePhotosPayload struct {
+	MigrationID int64 `json:"migration_id"`
+}
+
+type StartPhotoMigrationPayload struct {
+	Format    string `json:"format" validate:"omitempty,oneof=webp avif"`
+	MaxWidth  int    `json:"max_width" validate:"omitempty,min=320,max=4096"`
+	PerMinute int    `json:"per_minute" validate:"omitempty,min=1,max=60"`
+}
+
+// errCloudinaryRateLimited stops a batch so the rest waits for the backoff.
+var errCloudinaryRateLimited = errors.New("cloudinary rate limit exceeded")
+
+// adminStartPhotoMigrationHandler godoc
+//
+//	@Summary		Re-encode venue photos into a lighter format
+//	@Description	Starts a background pass over every venue photo on Cloudinary that isn't webp or avif yet. Each is re-encoded into format (default webp), no wider than max_width (default 1920), and replaces the original on its venue, which is then deleted. At most per_minute photos (default 20) are processed a minute; when Cloudinary reports its rate limit the pass waits 15 minutes. A photo that comes out no smaller is left as it was. Only one pass runs at a time.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		StartPhotoMigrationPayload	false	"Settings"
+//	@Success		202		{object}	photomigrations.Migration
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		409		{object}	ErrorResponse	"A migration is already running, or Cloudinary isn't configured"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/photo-migrations [post]
+func (app *application) adminStartPhotoMigrationHandler(w http.ResponseWriter, r *http.Request) {
+	if app.cld == nil {
+		app.conflictResponse(w, r, errors.New("photo migrations need CLOUDINARY_URL"))
+		return
+	}
+
+	var payload StartPhotoMigrationPayload
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	s := photomigrations.Settings{Format: "webp", MaxWidth: 1920, PerMinute: 20}
+	if payload.Format != "" {
+		s.Format = payload.Format
+	}
+	if payload.MaxWidth != 0 {
+		s.MaxWidth = payload.MaxWidth
+	}
+	if payload.PerMinute != 0 {
+		s.PerMinute = payload.PerMinute
+	}
+
+	ctx := r.Context()
+	m, err := app.store.PhotoMigrations.Start(ctx, s, getUserFromContext(r).ID)
+	if err != nil {
+		if errors.Is(err, photomigrations.ErrAlreadyRunning) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if _, err := app.store.Jobs.Enqueue(ctx, jobMigrateVenuePhotos, migrateVenuePhotosPayload{MigrationID: m.ID}, jobs.EnqueueOptions{}); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityPhotoMigration, audit.ActionCreate, m.ID, nil, m.Settings)
+	app.jsonResponse(w, http.StatusAccepted, m)
+}
+
+// adminListPhotoMigrationsHandler godoc
+//
+//	@Summary		List venue photo migrations
+//	@Description	The last 50 passes, newest first, each with its progress and the bytes saved on the photos it replaced.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}		photomigrations.Migration
+//	@Failure		500	{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/photo-migrations [get]
+func (app *application) adminListPhotoMigrationsHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := app.store.PhotoMigrations.List(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// adminGetPhotoMigrationHandler godoc
+//
+//	@Summary		Get a venue photo migration
+//	@Description	Progress and savings of one pass, with the photos that failed and why.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			migrationID	path		int				true	"Migration ID"
+//	@Success		200			{object}	map[string]any	"migration + failed"
+//	@Failure		400			{object}	ErrorResponse	"Bad Request"
+//	@Failure		404			{object}	ErrorResponse	"Migration not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/photo-migrations/{migrationID} [get]
+func (app *application) adminGetPhotoMigrationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "migrationID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid migration ID"))
+		return
+	}
+
+	ctx := r.Context()
+	m, err := app.store.PhotoMigrations.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, photomigrations.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	failed, err := app.store.PhotoMigrations.ListFailed(ctx, id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"migration": m,
+		"failed":    failed,
+	})
+}
+
+// adminCancelPhotoMigrationHandler godoc
+//
+//	@Summary		Cancel a venue photo migration
+//	@Description	Stops the pass after the photo in hand. Photos already replaced keep their new version.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			migrationID	path		int	true	"Migration ID"
+//	@Success		200			{object}	photomigrations.Migration
+//	@Failure		400			{object}	ErrorResponse	"Bad Request"
+//	@Failure		404			{object}	ErrorResponse	"Migration not found"
+//	@Failure		409			{object}	ErrorResponse	"Migration is not running"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/photo-migrations/{migrationID}/cancel [post]
+func (app *application) adminCancelPhotoMigrationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "migrationID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid migration ID"))
+		return
+	}
+
+	m, err := app.store.PhotoMigrations.Cancel(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, photomigrations.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, photomigrations.ErrNotRunning):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityPhotoMigration, audit.ActionStatus, id, nil, map[string]string{"status": m.Status})
+	app.jsonResponse(w, http.StatusOK, m)
+}
+
+// runMigrateVenuePhotos works through one minute's worth of a migration's
+// photos, spread over the minute, and queues the next batch for a minute
+// after it started. A photo that fails is recorded and skipped; the run
+// only errors when the database does.
+func (app *application) runMigrateVenuePhotos(ctx context.Context, p migrateVenuePhotosPayload) error {
+	if app.cld == nil {
+		return errors.New("photo migrations need CLOUDINARY_URL")
+	}
+	started := time.Now()
+
+	m, err := app.store.PhotoMigrations.Get(ctx, p.MigrationID)
+	if err != nil {
+		if errors.Is(err, photomigrations.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	items, err := app.store.PhotoMigrations.NextItems(ctx, m.ID, m.PerMinute)
+	if err != nil {
+		return err
+	}
+
+	next := started.Add(time.Minute)
+	gap := time.Minute / time.Duration(m.PerMinute)
+	processed := 0
+	for i, item := range items {
+		// Uploads can be slow; what doesn't fit in the minute waits for
+		// the next batch rather than running into the job timeout.
+		if time.Since(started) > time.Minute {
+			break
+		}
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(gap):
+			}
+		}
+
+		err := app.migrateVenuePhoto(ctx, m.Settings, item)
+		if errors.Is(err, errCloudinaryRateLimited) {
+			app.logger.Warnw("cloudinary rate limited photo migration", "migration_id", m.ID)
+			next = time.Now().Add(photoMigrationBackoff)
+			break
+		}
+		if err != nil {
+			return err
+		}
+		processed++
+	}
+	jobs.SetRowsAffected(ctx, int64(processed))
+
+	if len(items) == 0 {
+		if _, err := app.store.PhotoMigrations.Finish(ctx, m.ID); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	_, err = app.store.Jobs.Enqueue(ctx, jobMigrateVenuePhotos, p, jobs.EnqueueOptions{RunAt: next})
+	return err
+}
+
+// migrateVenuePhoto re-encodes one photo and swaps it in. Only database
+// errors and Cloudinary's rate limit come back; anything else about the
+// photo is recorded on its item.
+func (app *application) migrateVenuePhoto(ctx context.Context, s photomigrations.Settings, item photomigrations.Item) error {
+	bytesBefore, err := remoteSize(ctx, item.OldURL)
+	if err != nil {
+		return app.store.PhotoMigrations.Fail(ctx, item.ID, err.Error())
+	}
+	publicID, err := media.CloudinaryPublicID(item.OldURL)
+	if err != nil {
+		return app.store.PhotoMigrations.Skip(ctx, item.ID, err.Error())
+	}
+
+	resp, err := app.cld.Upload.Upload(ctx, item.OldURL, uploader.UploadParams{
+		PublicID:       fmt.Sprintf("%s_%s", publicID, s.Format),
+		Overwrite:      api.Bool(false),
+		Format:         s.Format,
+		Transformation: fmt.Sprintf("c_limit,w_%d,q_auto", s.MaxWidth),
+	})
+	if err == nil && resp.Error.Message != "" {
+		err = errors.New(resp.Error.Message)
+	}
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "rate limit") {
+			return errCloudinaryRateLimited
+		}
+		return app.store.PhotoMigrations.Fail(ctx, item.ID, fmt.Sprintf("cloudinary upload: %v", err))
+	}
+
+	bytesAfter := int64(resp.Bytes)
+	if bytesAfter >= bytesBefore {
+		app.enqueuePhotoDelete(resp.SecureURL)
+		return app.store.PhotoMigrations.Skip(ctx, item.ID, fmt.Sprintf("%s version is no smaller (%d vs %d bytes)", s.Format, bytesAfter, bytesBefore))
+	}
+
+	replaced, err := app.store.PhotoMigrations.Replace(ctx, item, resp.SecureURL, bytesBefore, bytesAfter)
+	if err != nil {
+		app.enqueuePhotoDelete(resp.SecureURL)
+		return err
+	}
+	if !replaced {
+		// The owner removed the original meanwhile; nobody needs the copy.
+		app.enqueuePhotoDelete(resp.SecureURL)
+		return nil
+	}
+	app.enqueuePhotoDelete(item.OldURL)
+	return nil
+}
+
+// remoteSize is the size in bytes of the file at url, read from a HEAD
+// request or, when that doesn't say, by downloading it.
+func remoteSize(ctx context.Context, url string) (int64, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("fetch original: %w", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("fetch original: status %d", res.StatusCode)
+	}
+	if res.ContentLength >= 0 {
+		return res.ContentLength, nil
+	}
+
+	req.Method = http.MethodGet
+	res, err = client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("fetch original: %w", err)
+	}
+	defer res.Body.Close()
+	n, err := io.Copy(io.Discard, res.Body)
+	if err != nil {
+		return 0, fmt.Errorf("read original: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/pricealerts"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type evaluatePriceAlertsPayload struct {
+	VenueID int64 `json:"venue_id"`
+}
+
+type CreatePriceAlertPayload struct {
+	VenueID    int64  `json:"venue_id" validate:"required,gt=0"`
+	DayOfWeek  string `json:"day_of_week" validate:"required,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+	StartTime  string `json:"start_time" validate:"required"`
+	PriceBelow int    `json:"price_below" validate:"required,gt=0"`
+}
+
+// createPriceAlertHandler godoc
+//
+//	@Summary		Watch a venue slot for a price drop
+//	@Description	Notifies the user when the cheapest price for the venue's weekly slot (day + start time, local Nepal time as HH:mm:ss) falls below price_below. Each lower price notifies once; the alert re-arms when the price goes back up. Up to 20 alerts per user.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreatePriceAlertPayload	true	"Slot and threshold"
+//	@Success		201		{object}	pricealerts.Alert
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		409		{object}	error	"Alert already exists or limit reached"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/price-alerts [post]
+func (app *application) createPriceAlertHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreatePriceAlertPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.DayOfWeek = strings.ToLower(strings.TrimSpace(payload.DayOfWeek))
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	st, err := time.Parse("15:04:05", payload.StartTime)
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid start_time, use HH:mm:ss"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	alert := &pricealerts.Alert{
+		UserID:     user.ID,
+		VenueID:    payload.VenueID,
+		DayOfWeek:  payload.DayOfWeek,
+		StartTime:  st.Format("15:04:05"),
+		PriceBelow: payload.PriceBelow,
+	}
+	if err := app.store.PriceAlerts.Create(r.Context(), alert); err != nil {
+		switch {
+		case errors.Is(err, pricealerts.ErrVenueNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, pricealerts.ErrDuplicate), errors.Is(err, pricealerts.ErrLimitExceeded):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// The slot may already be below the threshold.
+	app.enqueuePriceAlertCheck(alert.VenueID)
+
+	app.jsonResponse(w, http.StatusCreated, alert)
+}
+
+// listPriceAlertsHandler godoc
+//
+//	@Summary		List my price alerts
+//	@Description	Includes the slot's current cheapest price when the venue prices it.
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{array}		pricealerts.Alert
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/price-alerts [get]
+func (app *application) listPriceAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	list, err := app.store.PriceAlerts.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// deletePriceAlertHandler godoc
+//
+//	@Summary		Delete a price alert
+//	@Tags			Users
+//	@Param			alertID	path	int	true	"Alert ID"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Alert not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/price-alerts/{alertID} [delete]
+func (app *application) deletePriceAlertHandler(w http.ResponseWriter, r *http.Request) {
+	alertID, err := readIDParam(r, "alertID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid alert ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.PriceAlerts.Delete(r.Context(), user.ID, alertID); err != nil {
+		if errors.Is(err, pricealerts.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// enqueuePriceAlertCheck queues evaluation of the venue's price alerts after
+// its pricing changed. One pending check per venue is enough.
+func (app *application) enqueuePriceAlertCheck(venueID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := app.store.Jobs.Enqueue(ctx, jobEvaluatePriceAlerts, evaluatePriceAlertsPayload{VenueID: venueID}, jobs.EnqueueOptions{
+		UniqueKey: jobEvaluatePriceAlerts + ":" + strconv.FormatInt(venueID, 10),
+	})
+	if err != nil {
+		app.logger.Errorw("failed to enqueue price alert check", "venue_id", venueID, "error", err)
+	}
+}
+
+// runEvaluatePriceAlerts pushes every alert on the venue that just dropped
+// below its threshold. Alerts are claimed before sending, so a failed push
+// is not retried.
+func (app *application) runEvaluatePriceAlerts(ctx context.Context, venueID int64) error {
+	triggered, err := app.store.PriceAlerts.EvaluateVenue(ctx, venueID)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range triggered {
+		if err := notifications.SendPriceAlert(ctx, app.push, app.store, t); err != nil {
+			app.logger.Warnw("failed to push price alert", "alert_id", t.AlertID, "venue_id", venueID, "error", err)
+		}
+	}
+	return nil
+}
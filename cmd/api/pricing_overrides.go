@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type PricingOverrideSlotPayload struct {
+	// StartTime and EndTime must be in 24-hour HH:mm:ss format.
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+	Price     int    `json:"price" validate:"gte=0"`
+}
+
+type CreatePricingOverridesPayload struct {
+	Date  string                       `json:"date" validate:"required"` // YYYY-MM-DD
+	Label *string                      `json:"label" validate:"omitempty,max=100"`
+	Slots []PricingOverrideSlotPayload `json:"slots" validate:"required,min=1,dive,required"`
+}
+
+type UpdatePricingOverridePayload struct {
+	Date      string  `json:"date" validate:"required"`
+	StartTime string  `json:"start_time" validate:"required"`
+	EndTime   string  `json:"end_time" validate:"required"`
+	Price     int     `json:"price" validate:"gte=0"`
+	Label     *string `json:"label" validate:"omitempty,max=100"`
+}
+
+// parseOverrideTimes checks the date and clock range of one override row.
+func parseOverrideTimes(date, start, end string) (time.Time, time.Time, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date format, use YYYY-MM-DD")
+	}
+	st, err := time.Parse("15:04:05", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start_time")
+	}
+	et, err := time.Parse("15:04:05", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end_time")
+	}
+	if !st.Before(et) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_time must be before end_time")
+	}
+	return st, et, nil
+}
+
+// listPricingOverridesHandler godoc
+//
+//	@Summary		List date-specific pricing overrides for a facility
+//	@Description	Optional from/to (YYYY-MM-DD, inclusive) narrow the date range.
+//	@Tags			Facility Pricing
+//	@Produce		json
+//	@Param			venueID		path		int							true	"Venue ID"
+//	@Param			facilityID	path		int							true	"Facility ID"
+//	@Param			from		query		string						false	"First date"
+//	@Param			to			query		string						false	"Last date"
+//	@Success		200			{array}		bookings.PricingOverride	"Overrides"
+//	@Failure		400			{object}	ErrorResponse				"Bad Request"
+//	@Failure		404			{object}	ErrorResponse				"Facility not found"
+//	@Failure		500			{object}	ErrorResponse				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/facilities/{facilityID}/pricing-overrides [get]
+func (app *application) listPricingOverridesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, facilityID, err := app.parseVenueAndFacilityID(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.requireFacilityBelongsToVenue(r.Context(), venueID, facilityID); err != nil {
+		app.notFoundResponse(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	from, to := strings.TrimSpace(q.Get("from")), strings.TrimSpace(q.Get("to"))
+	for _, d := range []string{from, to} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid date format, use YYYY-MM-DD"))
+			return
+		}
+	}
+
+	list, err := app.store.Bookings.ListPricingOverrides(r.Context(), venueID, facilityID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// createPricingOverridesHandler godoc
+//
+//	@Summary		Set date-specific pricing for a facility
+//	@Description	Adds override slots for one date, e.g. special prices during Dashain. Once a date has any override, it replaces that day's weekly pricing entirely: hours without an override slot cannot be booked.
+//	@Description	start_time and end_time must use 24-hour HH:mm:ss format.
+//	@Tags			Facility Pricing
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID		path		int								true	"Venue ID"
+//	@Param			facilityID	path		int								true	"Facility ID"
+//	@Param			payload		body		CreatePricingOverridesPayload	true	"Date and slots"
+//	@Success		201			{array}		bookings.PricingOverride		"Overrides created"
+//	@Failure		400			{object}	ErrorResponse					"Bad Request"
+//	@Failure		404			{object}	ErrorResponse					"Facility not found"
+//	@Failure		409			{object}	ErrorResponse					"Overlaps an existing override"
+//	@Failure		500			{object}	ErrorResponse					"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/facilities/{facilityID}/pricing-overrides [post]
+func (app *application) createPricingOverridesHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, facilityID, err := app.parseVenueAndFacilityID(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.requireFacilityBelongsToVenue(r.Context(), venueID, facilityID); err != nil {
+		app.notFoundResponse(w, r, err)
+		return
+	}
+
+	var payload CreatePricingOverridesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	label := cleanOptionalString(payload.Label)
+	overrides := make([]*bookings.PricingOverride, 0, len(payload.Slots))
+	for i, in := range payload.Slots {
+		st, et, err := parseOverrideTimes(payload.Date, in.StartTime, in.EndTime)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("slot %d: %w", i, err))
+			return
+		}
+		overrides = append(overrides, &bookings.PricingOverride{
+			VenueID:    venueID,
+			FacilityID: facilityID,
+			Date:       payload.Date,
+			StartTime:  st,
+			EndTime:    et,
+			Price:      in.Price,
+			Label:      label,
+		})
+	}
+
+	if err := app.store.Bookings.CreatePricingOverrides(r.Context(), overrides); err != nil {
+		if errors.Is(err, bookings.ErrOverrideOverlap) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, overrides)
+}
+
+// updatePricingOverrideHandler godoc
+//
+//	@Summary		Update a pricing override
+//	@Tags			Facility Pricing
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID		path		int								true	"Venue ID"
+//	@Param			facilityID	path		int								true	"Facility ID"
+//	@Param			overrideID	path		int								true	"Override ID"
+//	@Param			payload		body		UpdatePricingOverridePayload	true	"Override"
+//	@Success		200			{object}	bookings.PricingOverride		"Override updated"
+//	@Failure		400			{object}	ErrorResponse					"Bad Request"
+//	@Failure		404			{object}	ErrorResponse					"Override not found"
+//	@Failure		409			{object}	ErrorResponse					"Overlaps an existing override"
+//	@Failure		500			{object}	ErrorResponse					"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/facilities/{facilityID}/pricing-overrides/{overrideID} [put]
+func (app *application) updatePricingOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, facilityID, err := app.parseVenueAndFacilityID(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	overrideID, err := parseInt64PathParam(r, "overrideID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload UpdatePricingOverridePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	st, et, err := parseOverrideTimes(payload.Date, payload.StartTime, payload.EndTime)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	override := &bookings.PricingOverride{
+		ID:         overrideID,
+		VenueID:    venueID,
+		FacilityID: facilityID,
+		Date:       payload.Date,
+		StartTime:  st,
+		EndTime:    et,
+		Price:      payload.Price,
+		Label:      cleanOptionalString(payload.Label),
+	}
+	if err := app.store.Bookings.UpdatePricingOverride(r.Context(), override); err != nil {
+		switch {
+		case errors.Is(err, bookings.ErrOverrideNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, bookings.ErrOverrideOverlap):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, override)
+}
+
+// deletePricingOverrideHandler godoc
+//
+//	@Summary		Delete a pricing override
+//	@Description	When the last override of a date is deleted, the weekly pricing applies again.
+//	@Tags			Facility Pricing
+//	@Produce		json
+//	@Param			venueID		path	int	true	"Venue ID"
+//	@Param			facilityID	path	int	true	"Facility ID"
+//	@Param			overrideID	path	int	true	"Override ID"
+//	@Success		204			"No Content"
+//	@Failure		400			{object}	ErrorResponse	"Bad Request"
+//	@Failure		404			{object}	ErrorResponse	"Override not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/facilities/{facilityID}/pricing-overrides/{overrideID} [delete]
+func (app *application) deletePricingOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, facilityID, err := app.parseVenueAndFacilityID(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	overrideID, err := parseInt64PathParam(r, "overrideID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Bookings.DeletePricingOverride(r.Context(), venueID, facilityID, overrideID); err != nil {
+		if errors.Is(err, bookings.ErrOverrideNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
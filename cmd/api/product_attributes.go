@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/products"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ---------- Admin: Category attributes ----------
+
+// attributeKeyRE matches the category_attributes key check.
+var attributeKeyRE = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+const (
+	maxAttributeFilterKeys   = 10
+	maxAttributeFilterValues = 20
+)
+
+type CategoryAttributePayload struct {
+	Key           string   `json:"key" validate:"required,max=50"`
+	Name          string   `json:"name" validate:"required,max=60"`
+	Type          string   `json:"type" validate:"required,oneof=enum number text boolean"`
+	AllowedValues []string `json:"allowed_values" validate:"required_if=Type enum,max=100,dive,required,max=60"`
+	Required      bool     `json:"required"`
+	Filterable    *bool    `json:"filterable"`
+	SortOrder     int      `json:"sort_order" validate:"min=0,max=1000"`
+}
+
+// UpdateCategoryAttributePayload leaves the key and type alone; variants
+// already carry values of that type under that key.
+type UpdateCategoryAttributePayload struct {
+	Name          *string  `json:"name" validate:"omitempty,min=1,max=60"`
+	AllowedValues []string `json:"allowed_values" validate:"omitempty,max=100,dive,required,max=60"`
+	Required      *bool    `json:"required"`
+	Filterable    *bool    `json:"filterable"`
+	SortOrder     *int     `json:"sort_order" validate:"omitempty,min=0,max=1000"`
+}
+
+// ListCategoryAttributes godoc
+//
+//	@Summary		List a category's attributes
+//	@Description	The attributes variants of products in this category take, including those inherited from parent categories. A subcategory's definition of a key replaces its parent's.
+//	@Tags			Store-Products
+//	@Produce		json
+//	@Param			categoryID	path		int	true	"Category ID"
+//	@Success		200			{array}		products.AttributeDefinition
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Router			/store/categories/{categoryID}/attributes [get]
+func (app *application) listCategoryAttributesHandler(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := readIDParam(r, "categoryID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid category ID"))
+		return
+	}
+
+	defs, err := app.store.Products.ListCategoryAttributes(r.Context(), categoryID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, defs)
+}
+
+// CreateCategoryAttribute godoc
+//
+//	@Summary		Define a category attribute
+//	@Description	Adds an attribute that variants of the category's products (and its subcategories') are checked against. key is what variants use in their attributes and what attr.<key> filters on. enum attributes need allowed_values, listed in the order facets show them. Filterable attributes appear in the product list's facets.
+//	@Tags			Store-Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			categoryID	path		int							true	"Category ID"
+//	@Param			payload		body		CategoryAttributePayload	true	"Attribute"
+//	@Success		201			{object}	products.AttributeDefinition
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Category not found"
+//	@Failure		409			{object}	error	"Key already defined on the category"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/categories/{categoryID}/attributes [post]
+func (app *application) createCategoryAttributeHandler(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := readIDParam(r, "categoryID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid category ID"))
+		return
+	}
+
+	var payload CategoryAttributePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Key = strings.TrimSpace(payload.Key)
+	payload.Name = strings.TrimSpace(payload.Name)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if !attributeKeyRE.MatchString(payload.Key) {
+		app.badRequestResponse(w, r, fmt.Errorf("key must start with a-z and only use a-z, 0-9 and _"))
+		return
+	}
+	values, err := cleanAllowedValues(payload.Type, payload.AllowedValues)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	a := &products.AttributeDefinition{
+		CategoryID:    categoryID,
+		Key:           payload.Key,
+		Name:          payload.Name,
+		Type:          payload.Type,
+		AllowedValues: values,
+		Required:      payload.Required,
+		Filterable:    true,
+		SortOrder:     payload.SortOrder,
+	}
+	if payload.Filterable != nil {
+		a.Filterable = *payload.Filterable
+	}
+	if err := app.store.Products.CreateCategoryAttribute(r.Context(), a); err != nil {
+		switch {
+		case errors.Is(err, products.ErrCategoryNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, products.ErrDuplicateAttribute):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityCategoryAttribute, audit.ActionCreate, a.ID, nil, a)
+	app.jsonResponse(w, http.StatusCreated, a)
+}
+
+// UpdateCategoryAttribute godoc
+//
+//	@Summary		Update a category attribute
+//	@Description	Changes the name, allowed values, required and filterable flags and sort order. The key and type can't change; delete the attribute and define it again instead. New rules apply to variants created or given new attributes afterwards.
+//	@Tags			Store-Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			categoryID	path		int								true	"Category ID"
+//	@Param			attributeID	path		int								true	"Attribute ID"
+//	@Param			payload		body		UpdateCategoryAttributePayload	true	"Fields to change"
+//	@Success		200			{object}	products.AttributeDefinition
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Attribute not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/categories/{categoryID}/attributes/{attributeID} [patch]
+func (app *application) updateCategoryAttributeHandler(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := readIDParam(r, "categoryID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid category ID"))
+		return
+	}
+	id, err := readIDParam(r, "attributeID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid attribute ID"))
+		return
+	}
+
+	var payload UpdateCategoryAttributePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	before, err := app.categoryAttribute(ctx, categoryID, id)
+	if err != nil {
+		if errors.Is(err, products.ErrAttributeNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	after := *before
+	if payload.Name != nil {
+		after.Name = strings.TrimSpace(*payload.Name)
+	}
+	if payload.AllowedValues != nil {
+		if after.AllowedValues, err = cleanAllowedValues(after.Type, payload.AllowedValues); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if payload.Required != nil {
+		after.Required = *payload.Required
+	}
+	if payload.Filterable != nil {
+		after.Filterable = *payload.Filterable
+	}
+	if payload.SortOrder != nil {
+		after.SortOrder = *payload.SortOrder
+	}
+
+	if err := app.store.Products.UpdateCategoryAttribute(ctx, &after); err != nil {
+		if errors.Is(err, products.ErrAttributeNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityCategoryAttribute, audit.ActionUpdate, id, before, after)
+	app.jsonResponse(w, http.StatusOK, after)
+}
+
+// DeleteCategoryAttribute godoc
+//
+//	@Summary		Delete a category attribute
+//	@Description	Stops checking and faceting the key. Variants keep the values they have.
+//	@Tags			Store-Admin
+//	@Param			categoryID	path	int	true	"Category ID"
+//	@Param			attributeID	path	int	true	"Attribute ID"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		404	{object}	error	"Attribute not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/categories/{categoryID}/attributes/{attributeID} [delete]
+func (app *application) deleteCategoryAttributeHandler(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := readIDParam(r, "categoryID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid category ID"))
+		return
+	}
+	id, err := readIDParam(r, "attributeID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid attribute ID"))
+		return
+	}
+
+	if err := app.store.Products.DeleteCategoryAttribute(r.Context(), categoryID, id); err != nil {
+		if errors.Is(err, products.ErrAttributeNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityCategoryAttribute, audit.ActionDelete, id, nil, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// categoryAttribute finds one of the category's own definitions, not an
+// inherited one.
+func (app *application) categoryAttribute(ctx context.Context, categoryID, id int64) (*products.AttributeDefinition, error) {
+	defs, err := app.store.Products.ListCategoryAttributes(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range defs {
+		if d.ID == id && d.CategoryID == categoryID {
+			return d, nil
+		}
+	}
+	return nil, products.ErrAttributeNotFound
+}
+
+// cleanAllowedValues trims and de-duplicates the values, keeping their
+// order. Only enum attributes have them.
+func cleanAllowedValues(typ string, values []string) ([]string, error) {
+	if typ != products.AttrEnum {
+		if len(values) > 0 {
+			return nil, fmt.Errorf("only enum attributes take allowed_values")
+		}
+		return []string{}, nil
+	}
+	out := make([]string, 0, len(values))
+	seen := map[string]bool{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("enum attributes need allowed_values")
+	}
+	return out, nil
+}
+
+// parseAttributeFilter reads attr.<key>=v1,v2 query parameters.
+func parseAttributeFilter(q url.Values) (products.AttributeFilter, error) {
+	var f products.AttributeFilter
+	for name, raw := range q {
+		key, ok := strings.CutPrefix(name, "attr.")
+		if !ok {
+			continue
+		}
+		if !attributeKeyRE.MatchString(key) {
+			return nil, fmt.Errorf("invalid attribute filter %q", name)
+		}
+		var values []string
+		for _, r := range raw {
+			for _, v := range strings.Split(r, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if len(values) > maxAttributeFilterValues {
+			return nil, fmt.Errorf("%s takes at most %d values", name, maxAttributeFilterValues)
+		}
+		if f == nil {
+			f = products.AttributeFilter{}
+		}
+		f[key] = values
+	}
+	if len(f) > maxAttributeFilterKeys {
+		return nil, fmt.Errorf("at most %d attribute filters", maxAttributeFilterKeys)
+	}
+	return f, nil
+}
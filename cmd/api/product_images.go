@@ -70,7 +70,7 @@ func (app *application) createProductImageHandler(w http.ResponseWriter, r *http
 	}
 
 	publicID := fmt.Sprintf("products/%d/%d_%d", productID, time.Now().Unix(), rand.Intn(9999))
-	imageURL, err := app.uploadToCloudinaryWithID(file, publicID, "products")
+	imageURL, err := app.uploadImageWithID(file, publicID, "products")
 	if err != nil {
 		app.internalServerError(w, r, fmt.Errorf("failed to upload image: %w", err))
 		return
@@ -88,10 +88,11 @@ func (app *application) createProductImageHandler(w http.ResponseWriter, r *http
 	created, err := app.store.Products.CreateProductImage(ctx, img)
 	if err != nil {
 		// cleanup failed upload
-		go app.deletePhotoFromCloudinary(imageURL)
+		app.enqueuePhotoDelete(imageURL)
 		app.internalServerError(w, r, fmt.Errorf("failed to save image: %w", err))
 		return
 	}
+	app.enqueueImageVariants()
 
 	app.jsonResponse(w, http.StatusCreated, map[string]interface{}{
 		"message": "Image uploaded successfully",
@@ -234,11 +235,7 @@ func (app *application) deleteProductImageHandler(w http.ResponseWriter, r *http
 	}
 
 	// Async Cloudinary cleanup
-	go func(url string) {
-		if err := app.deletePhotoFromCloudinary(url); err != nil {
-			app.logger.Error("failed to delete product image from Cloudinary", "url", url, "err", err)
-		}
-	}(img.URL)
+	app.enqueuePhotoDelete(img.URL)
 
 	app.jsonResponse(w, http.StatusOK, map[string]string{
 		"message": "Image deleted successfully",
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"khel/internal/audit"
+	"khel/internal/domain/products"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxProductImportRows bounds one upload; larger catalogs go in parts.
+	maxProductImportRows = 2000
+	// productImportBatch is how many products share one transaction.
+	productImportBatch = 50
+)
+
+// importProductsCSVHandler godoc
+//
+//	@Summary		Import products from CSV
+//	@Description	Creates draft products with their variants and images from a CSV upload (field "file", max 5MB, 2000 rows). One row per variant; rows sharing a slug (or a name, when slug is blank) make one product, whose name, description, category and brand come from its first row. Columns: name, slug, description, category (slug), brand (slug), sku, barcode, price_cents, cost_price_cents, attributes (JSON object), image_urls (separated by |; the first becomes primary). Products are written 50 per transaction. A product with an invalid row is skipped as a whole; every skipped row is listed in issues with its line number.
+//	@Tags			Store-Admin
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file	true	"Products CSV"
+//	@Success		200		{object}	products.ProductImportResult
+//	@Failure		400		{object}	ErrorResponse	"Bad Request"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/products/import [post]
+func (app *application) importProductsCSVHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(5 << 20); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("file too large or invalid form"))
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("file is required"))
+		return
+	}
+	defer file.Close()
+
+	items, issues, err := parseProductCSV(file)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	res, err := app.store.Products.ImportProducts(r.Context(), items, productImportBatch)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	res.Issues = append(issues, res.Issues...)
+	slices.SortStableFunc(res.Issues, func(a, b products.ProductImportIssue) int { return a.Line - b.Line })
+
+	app.recordAudit(r, audit.EntityProduct, audit.ActionImport, nil, nil, map[string]any{
+		"products":     res.Products,
+		"variants":     res.Variants,
+		"images":       res.Images,
+		"skipped_rows": len(res.Issues),
+	})
+
+	app.jsonResponse(w, http.StatusOK, res)
+}
+
+// parseProductCSV groups the rows into products by slug. Cell errors are
+// collected per line and drop the whole product; err is only set when the
+// file itself is unusable.
+func parseProductCSV(src io.Reader) ([]products.ProductImport, []products.ProductImportIssue, error) {
+	cr := csv.NewReader(src)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "sku", "price_cents"} {
+		if _, ok := col[required]; !ok {
+			return nil, nil, fmt.Errorf("csv must have a %s column", required)
+		}
+	}
+	cell := func(rec []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+	optional := func(rec []string, name string) *string {
+		if v := cell(rec, name); v != "" {
+			return &v
+		}
+		return nil
+	}
+
+	var (
+		order   []string
+		bySlug  = map[string]*products.ProductImport{}
+		lines   = map[string][]int{}
+		failed  = map[string]int{}
+		issues  []products.ProductImportIssue
+		seenSKU = map[string]int{}
+		total   int
+	)
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if total++; total > maxProductImportRows {
+			return nil, nil, fmt.Errorf("at most %d rows per import", maxProductImportRows)
+		}
+
+		name := cell(rec, "name")
+		slug := strings.ToLower(cell(rec, "slug"))
+		if slug == "" {
+			slug = generateSlug(name)
+		}
+		sku := cell(rec, "sku")
+		fail := func(msg string) {
+			issues = append(issues, products.ProductImportIssue{Line: line, Slug: slug, SKU: sku, Error: msg})
+			if _, ok := failed[slug]; !ok {
+				failed[slug] = line
+			}
+		}
+
+		if slug == "" {
+			fail("name or slug is required")
+			continue
+		}
+		if !isValidSlug(slug) {
+			fail("slug must be 3-50 lowercase letters, digits or hyphens")
+			continue
+		}
+		lines[slug] = append(lines[slug], line)
+
+		p, ok := bySlug[slug]
+		if !ok {
+			if name == "" {
+				fail("name is empty on the product's first row")
+				continue
+			}
+			p = &products.ProductImport{
+				Line:         line,
+				Name:         name,
+				Slug:         slug,
+				Description:  optional(rec, "description"),
+				CategorySlug: optional(rec, "category"),
+				BrandSlug:    optional(rec, "brand"),
+			}
+			bySlug[slug] = p
+			order = append(order, slug)
+		}
+
+		v, msg := parseProductCSVVariant(line, sku, cell, rec)
+		if msg != "" {
+			fail(msg)
+			continue
+		}
+		if first, dup := seenSKU[strings.ToLower(sku)]; dup {
+			fail(fmt.Sprintf("sku repeats line %d", first))
+			continue
+		}
+		seenSKU[strings.ToLower(sku)] = line
+		p.Variants = append(p.Variants, v)
+
+		for _, raw := range strings.Split(cell(rec, "image_urls"), "|") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				fail(fmt.Sprintf("invalid image url %q", raw))
+				break
+			}
+			if !slices.Contains(p.ImageURLs, raw) {
+				p.ImageURLs = append(p.ImageURLs, raw)
+			}
+		}
+	}
+	if total == 0 {
+		return nil, nil, errors.New("csv has no rows")
+	}
+
+	// A product goes in whole or not at all; its other rows are reported
+	// as skipped so every line is accounted for.
+	reported := make(map[int]bool, len(issues))
+	for _, is := range issues {
+		reported[is.Line] = true
+	}
+	items := make([]products.ProductImport, 0, len(order))
+	for _, slug := range order {
+		bad, isBad := failed[slug]
+		if !isBad {
+			items = append(items, *bySlug[slug])
+			continue
+		}
+		for _, l := range lines[slug] {
+			if !reported[l] {
+				issues = append(issues, products.ProductImportIssue{Line: l, Slug: slug, Error: fmt.Sprintf("skipped: line %d of this product is invalid", bad)})
+			}
+		}
+	}
+	return items, issues, nil
+}
+
+func parseProductCSVVariant(line int, sku string, cell func([]string, string) string, rec []string) (products.VariantImport, string) {
+	v := products.VariantImport{Line: line, SKU: sku}
+	if sku == "" {
+		return v, "sku is empty"
+	}
+	if len(sku) > 64 {
+		return v, "sku must be at most 64 characters"
+	}
+	if b := cell(rec, "barcode"); b != "" {
+		if len(b) > 64 {
+			return v, "barcode must be at most 64 characters"
+		}
+		v.Barcode = &b
+	}
+
+	n, err := strconv.ParseInt(cell(rec, "price_cents"), 10, 64)
+	if err != nil || n < 0 {
+		return v, "price_cents must be a whole number >= 0"
+	}
+	v.PriceCents = n
+	if c := cell(rec, "cost_price_cents"); c != "" {
+		n, err := strconv.ParseInt(c, 10, 64)
+		if err != nil || n < 0 {
+			return v, "cost_price_cents must be a whole number >= 0"
+		}
+		v.CostPriceCents = &n
+	}
+
+	v.Attributes = map[string]any{}
+	if a := cell(rec, "attributes"); a != "" {
+		if err := json.Unmarshal([]byte(a), &v.Attributes); err != nil || v.Attributes == nil {
+			return v, "attributes must be a JSON object"
+		}
+	}
+	return v, ""
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/products"
+	"khel/internal/jobs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ---------- Admin: Product labels ----------
+
+type ProductLabelPayload struct {
+	Key      string  `json:"key" validate:"omitempty,max=40"`
+	Name     string  `json:"name" validate:"required,max=40"`
+	Color    *string `json:"color" validate:"omitempty,hexcolor,len=7"`
+	Priority int     `json:"priority" validate:"min=-1000,max=1000"`
+	IsActive *bool   `json:"is_active"`
+}
+
+type SetProductLabelsPayload struct {
+	LabelIDs []int64 `json:"label_ids" validate:"max=10,dive,min=1"`
+}
+
+// ListProductLabels godoc
+//
+//	@Summary		List product labels
+//	@Description	All labels, highest priority first, with how many products carry each. Labels with an auto_rule are assigned by the nightly refresh.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Success		200	{array}		products.ProductLabel
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/labels [get]
+func (app *application) listProductLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	labels, err := app.store.Products.ListLabels(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, labels)
+}
+
+// CreateProductLabel godoc
+//
+//	@Summary		Create a product label
+//	@Description	Adds a manual label. key is what the app matches on to pick a badge style; it defaults to the name in lower case.
+//	@Tags			Store-Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ProductLabelPayload	true	"Label"
+//	@Success		201		{object}	products.ProductLabel
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		409		{object}	error	"Key already used"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/labels [post]
+func (app *application) createProductLabelHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ProductLabelPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	key := strings.TrimSpace(payload.Key)
+	if key == "" {
+		key = strings.ReplaceAll(strings.ToLower(payload.Name), " ", "-")
+	}
+	if !validLabelKey(key) {
+		app.badRequestResponse(w, r, fmt.Errorf("key may only use a-z, 0-9, - and _"))
+		return
+	}
+
+	l := &products.ProductLabel{
+		Key:      key,
+		Name:     payload.Name,
+		Color:    payload.Color,
+		Priority: payload.Priority,
+		IsActive: true,
+	}
+	if payload.IsActive != nil {
+		l.IsActive = *payload.IsActive
+	}
+	if err := app.store.Products.CreateLabel(r.Context(), l); err != nil {
+		if errors.Is(err, products.ErrDuplicateLabel) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityProductLabel, audit.ActionCreate, l.ID, nil, l)
+	app.jsonResponse(w, http.StatusCreated, l)
+}
+
+// UpdateProductLabel godoc
+//
+//	@Summary		Update a product label
+//	@Description	Changes the name, color, priority and active flag. The key and auto rule are fixed. Deactivating an auto label also stops the refresh from assigning it.
+//	@Tags			Store-Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			labelID	path		int					true	"Label ID"
+//	@Param			payload	body		ProductLabelPayload	true	"Label"
+//	@Success		200		{object}	products.ProductLabel
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Label not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/labels/{labelID} [put]
+func (app *application) updateProductLabelHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "labelID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid label ID"))
+		return
+	}
+
+	var payload ProductLabelPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	l := &products.ProductLabel{
+		ID:       id,
+		Name:     payload.Name,
+		Color:    payload.Color,
+		Priority: payload.Priority,
+		IsActive: true,
+	}
+	if payload.IsActive != nil {
+		l.IsActive = *payload.IsActive
+	}
+	if err := app.store.Products.UpdateLabel(r.Context(), l); err != nil {
+		if errors.Is(err, products.ErrLabelNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityProductLabel, audit.ActionUpdate, l.ID, nil, l)
+	app.jsonResponse(w, http.StatusOK, l)
+}
+
+// DeleteProductLabel godoc
+//
+//	@Summary		Delete a product label
+//	@Description	Removes a manual label from every product. Auto labels can only be deactivated.
+//	@Tags			Store-Admin
+//	@Param			labelID	path	int	true	"Label ID"
+//	@Success		204		"Deleted"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Label not found"
+//	@Failure		409		{object}	error	"Label has an auto rule"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/labels/{labelID} [delete]
+func (app *application) deleteProductLabelHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "labelID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid label ID"))
+		return
+	}
+
+	if err := app.store.Products.DeleteLabel(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, products.ErrLabelNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, products.ErrAutoLabel):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityProductLabel, audit.ActionDelete, id, nil, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetProductLabels godoc
+//
+//	@Summary		Set a product's labels
+//	@Description	Replaces the labels attached by hand. Choosing an auto label pins it so the refresh won't take it off; auto labels not listed stay under the refresh's control.
+//	@Tags			Store-Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			productID	path		int						true	"Product ID"
+//	@Param			payload		body		SetProductLabelsPayload	true	"Label IDs"
+//	@Success		200			{array}		products.CardLabel
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Product or label not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/products/{productID}/labels [put]
+func (app *application) setProductLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := readIDParam(r, "productID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid product ID"))
+		return
+	}
+
+	var payload SetProductLabelsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ids := make([]int64, 0, len(payload.LabelIDs))
+	seen := make(map[int64]bool, len(payload.LabelIDs))
+	for _, id := range payload.LabelIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	labels, err := app.store.Products.SetProductLabels(r.Context(), productID, ids)
+	if err != nil {
+		if errors.Is(err, products.ErrProductNotFound) || errors.Is(err, products.ErrLabelNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if labels == nil {
+		labels = []products.CardLabel{}
+	}
+
+	app.recordAudit(r, audit.EntityProduct, audit.ActionUpdate, productID, nil, map[string]any{"label_ids": ids})
+	app.jsonResponse(w, http.StatusOK, labels)
+}
+
+// RefreshProductLabels godoc
+//
+//	@Summary		Refresh auto labels now
+//	@Description	Runs the nightly auto-label rules immediately: "new" for products created in the last 30 days, "bestseller" for the top 10% of products by paid units over the last 90 days.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Success		200	{object}	products.LabelRefreshResult
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/labels/refresh [post]
+func (app *application) refreshProductLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	res, err := app.store.Products.RefreshAutoLabels(ctx)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, res)
+}
+
+func (app *application) runRefreshProductLabels(ctx context.Context) error {
+	res, err := app.store.Products.RefreshAutoLabels(ctx)
+	if err != nil {
+		app.logger.Errorw("refresh product labels failed", "error", err)
+		return err
+	}
+	jobs.SetRowsAffected(ctx, res.Added+res.Removed)
+	app.logger.Infow("refreshed product labels", "added", res.Added, "removed", res.Removed)
+	return nil
+}
+
+func validLabelKey(key string) bool {
+	if key == "" || len(key) > 40 {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/products"
+	"khel/internal/jobs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getRelatedProductsHandler godoc
+//
+//	@Summary		Related products
+//	@Description	For the product detail screen. frequently_bought_together lists products often in the same paid order over the last 180 days, best match first, refreshed nightly; it is empty until a product has sales. similar lists other published products in the same category or from the same brand, same category first, never repeating the first list. wishlisted is set for signed-in shoppers.
+//	@Tags			Store-Products
+//	@Produce		json
+//	@Param			slug	path		string						true	"Product slug"
+//	@Param			limit	query		int							false	"Items per list (default: 8, max: 12)"
+//	@Success		200		{object}	products.RelatedProducts	"Related products"
+//	@Failure		400		{object}	error						"Bad Request"
+//	@Failure		404		{object}	error						"Product not found (missing or inactive)"
+//	@Failure		500		{object}	error						"Internal Server Error"
+//	@Router			/store/products/{slug}/related [get]
+func (app *application) getRelatedProductsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	slug := chi.URLParam(r, "slug")
+	if strings.TrimSpace(slug) == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("slug is required"))
+		return
+	}
+
+	limit := 8
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > products.MaxRecommendations {
+			app.badRequestResponse(w, r, fmt.Errorf("limit must be between 1 and %d", products.MaxRecommendations))
+			return
+		}
+		limit = n
+	}
+
+	product, err := app.store.Products.GetProductBySlug(ctx, slug)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if product == nil || !product.IsActive {
+		app.notFoundResponse(w, r, fmt.Errorf("product not found"))
+		return
+	}
+
+	related, err := app.store.Products.RelatedProducts(ctx, product.ID, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.markWishlisted(r, related.FrequentlyBoughtTogether); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.markWishlisted(r, related.Similar); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, related)
+}
+
+func (app *application) runRefreshRecommendations(ctx context.Context) error {
+	res, err := app.store.Products.RefreshRecommendations(ctx)
+	if err != nil {
+		app.logger.Errorw("refresh product recommendations failed", "error", err)
+		return err
+	}
+	jobs.SetRowsAffected(ctx, res.Pairs)
+	app.logger.Infow("refreshed product recommendations", "pairs", res.Pairs)
+	return nil
+}
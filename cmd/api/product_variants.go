@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"khel/internal/domain/products"
 	"khel/internal/params"
@@ -30,6 +31,7 @@ func (app *application) getVariantHandler(w http.ResponseWriter, r *http.Request
 		app.notFoundResponse(w, r, err)
 		return
 	}
+	variant.CostPriceCents = nil
 
 	app.jsonResponse(w, http.StatusOK, variant)
 }
@@ -74,6 +76,10 @@ func (app *application) listVariantsByProductHandler(w http.ResponseWriter, r *h
 			}
 		}
 	}
+	// public route: cost price is merchant-only
+	for _, v := range filtered {
+		v.CostPriceCents = nil
+	}
 
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
 		"variants": filtered,
@@ -85,10 +91,13 @@ func (app *application) createVariantHandler(w http.ResponseWriter, r *http.Requ
 	ctx := r.Context()
 
 	var input struct {
-		ProductID  int64                  `json:"product_id"`
-		PriceCents int64                  `json:"price_cents"`
-		Attributes map[string]interface{} `json:"attributes"`
-		IsActive   bool                   `json:"is_active"`
+		ProductID      int64                  `json:"product_id"`
+		SKU            string                 `json:"sku"`
+		Barcode        *string                `json:"barcode"`
+		PriceCents     int64                  `json:"price_cents"`
+		CostPriceCents *int64                 `json:"cost_price_cents"`
+		Attributes     map[string]interface{} `json:"attributes"`
+		IsActive       bool                   `json:"is_active"`
 	}
 
 	if err := readJSON(w, r, &input); err != nil {
@@ -100,24 +109,58 @@ func (app *application) createVariantHandler(w http.ResponseWriter, r *http.Requ
 		app.badRequestResponse(w, r, fmt.Errorf("product_id is required"))
 		return
 	}
+	input.SKU = strings.TrimSpace(input.SKU)
+	if input.SKU == "" || len(input.SKU) > 64 {
+		app.badRequestResponse(w, r, fmt.Errorf("sku is required (max 64 characters)"))
+		return
+	}
+	input.Barcode = cleanOptionalString(input.Barcode)
+	if input.Barcode != nil && len(*input.Barcode) > 64 {
+		app.badRequestResponse(w, r, fmt.Errorf("barcode must be at most 64 characters"))
+		return
+	}
 	if input.PriceCents < 0 {
 		app.badRequestResponse(w, r, fmt.Errorf("price_cents must be >= 0"))
 		return
 	}
+	if input.CostPriceCents != nil && *input.CostPriceCents < 0 {
+		app.badRequestResponse(w, r, fmt.Errorf("cost_price_cents must be >= 0"))
+		return
+	}
+
+	defs, err := app.store.Products.ProductAttributes(ctx, input.ProductID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := products.ValidateAttributes(defs, input.Attributes); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
 
 	variant := &products.ProductVariant{
-		ProductID:  input.ProductID,
-		PriceCents: input.PriceCents,
-		Attributes: input.Attributes,
-		IsActive:   input.IsActive,
+		ProductID:      input.ProductID,
+		SKU:            input.SKU,
+		Barcode:        input.Barcode,
+		PriceCents:     input.PriceCents,
+		CostPriceCents: input.CostPriceCents,
+		Attributes:     input.Attributes,
+		IsActive:       input.IsActive,
 	}
 
 	created, err := app.store.Products.CreateVariant(ctx, variant)
 	if err != nil {
+		if errors.Is(err, products.ErrDuplicateSKU) || errors.Is(err, products.ErrDuplicateBarcode) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, fmt.Errorf("failed to create variant: %w", err))
 		return
 	}
 
+	// A cheaper variant lowers what the product costs on wishlists.
+	app.enqueueWishlistPriceCheck(created.ProductID)
+
 	app.jsonResponse(w, http.StatusCreated, created)
 }
 
@@ -141,19 +184,56 @@ func (app *application) updateVariantHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	var input struct {
-		PriceCents *int64                 `json:"price_cents,omitempty"`
-		Attributes map[string]interface{} `json:"attributes,omitempty"`
-		IsActive   *bool                  `json:"is_active,omitempty"`
+		SKU            *string                `json:"sku,omitempty"`
+		Barcode        *string                `json:"barcode,omitempty"` // "" removes the barcode
+		PriceCents     *int64                 `json:"price_cents,omitempty"`
+		CostPriceCents *int64                 `json:"cost_price_cents,omitempty"`
+		Attributes     map[string]interface{} `json:"attributes,omitempty"`
+		IsActive       *bool                  `json:"is_active,omitempty"`
 	}
 	if err := readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
+	oldPrice, wasActive := existing.PriceCents, existing.IsActive
 
+	if input.SKU != nil {
+		sku := strings.TrimSpace(*input.SKU)
+		if sku == "" || len(sku) > 64 {
+			app.badRequestResponse(w, r, fmt.Errorf("sku must be 1-64 characters"))
+			return
+		}
+		existing.SKU = sku
+	}
+	if input.Barcode != nil {
+		existing.Barcode = cleanOptionalString(input.Barcode)
+		if existing.Barcode != nil && len(*existing.Barcode) > 64 {
+			app.badRequestResponse(w, r, fmt.Errorf("barcode must be at most 64 characters"))
+			return
+		}
+	}
 	if input.PriceCents != nil {
 		existing.PriceCents = *input.PriceCents
 	}
+	if input.CostPriceCents != nil {
+		if *input.CostPriceCents < 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("cost_price_cents must be >= 0"))
+			return
+		}
+		existing.CostPriceCents = input.CostPriceCents
+	}
 	if input.Attributes != nil {
+		// Only new attributes are checked, so a variant made before its
+		// category's rules changed can still be repriced.
+		defs, err := app.store.Products.ProductAttributes(ctx, existing.ProductID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if err := products.ValidateAttributes(defs, input.Attributes); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
 		existing.Attributes = input.Attributes
 	}
 	if input.IsActive != nil {
@@ -161,9 +241,16 @@ func (app *application) updateVariantHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	if err := app.store.Products.UpdateVariant(ctx, existing); err != nil {
+		if errors.Is(err, products.ErrDuplicateSKU) || errors.Is(err, products.ErrDuplicateBarcode) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, fmt.Errorf("failed to update variant: %w", err))
 		return
 	}
+	if existing.PriceCents != oldPrice || existing.IsActive != wasActive {
+		app.enqueueWishlistPriceCheck(existing.ProductID)
+	}
 
 	app.jsonResponse(w, http.StatusOK, existing)
 }
@@ -203,3 +290,45 @@ func (app *application) listAllVariantsHandler(w http.ResponseWriter, r *http.Re
 		"pagination": pagination,
 	})
 }
+
+// Lookup by SKU for warehouse scanners (admin); matching ignores case
+func (app *application) getVariantBySKUHandler(w http.ResponseWriter, r *http.Request) {
+	sku := strings.TrimSpace(chi.URLParam(r, "sku"))
+	if sku == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("sku is required"))
+		return
+	}
+
+	variant, err := app.store.Products.GetVariantBySKU(r.Context(), sku)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if variant == nil {
+		app.notFoundResponse(w, r, fmt.Errorf("no variant with sku %q", sku))
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, variant)
+}
+
+// Lookup by barcode (admin)
+func (app *application) getVariantByBarcodeHandler(w http.ResponseWriter, r *http.Request) {
+	barcode := strings.TrimSpace(chi.URLParam(r, "barcode"))
+	if barcode == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("barcode is required"))
+		return
+	}
+
+	variant, err := app.store.Products.GetVariantByBarcode(r.Context(), barcode)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if variant == nil {
+		app.notFoundResponse(w, r, fmt.Errorf("no variant with barcode %q", barcode))
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, variant)
+}
@@ -6,9 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"khel/internal/audit"
 	"khel/internal/domain/products"
 	"khel/internal/params"
-	"log"
 	"mime/multipart"
 	"net/http"
 	"regexp"
@@ -58,7 +58,7 @@ func sniffMIME(file multipart.File) (string, error) {
 // CreateBrand godoc
 //
 //	@Summary		Create a new brand
-//	@Description	Creates a new brand with optional logo upload to Cloudinary. Name is required; slug is optional (auto-generated from name if empty).
+//	@Description	Creates a new brand with optional logo upload. Name is required; slug is optional (auto-generated from name if empty).
 //	@Tags			Store-Admin
 //	@Accept			multipart/form-data
 //	@Produce		json
@@ -136,7 +136,7 @@ func (app *application) createBrandHandler(w http.ResponseWriter, r *http.Reques
 		publicID := fmt.Sprintf("brand/%s_logo_%d", slug, time.Now().UnixNano())
 
 		// upload using the same file reader (we reset it in sniffMIME)
-		url, upErr := app.uploadToCloudinaryWithID(file, publicID, "brands")
+		url, upErr := app.uploadFileWithID(file, publicID, "brands")
 		if upErr != nil {
 			app.internalServerError(w, r, fmt.Errorf("upload logo: %w", upErr))
 			return
@@ -165,16 +165,14 @@ func (app *application) createBrandHandler(w http.ResponseWriter, r *http.Reques
 	created, err := app.store.Products.CreateBrand(ctx, brand)
 	if err != nil {
 		if logoURL != "" {
-			go func(url string) {
-				if delErr := app.deletePhotoFromCloudinary(url); delErr != nil {
-					app.logger.Error("cloudinary cleanup failed", "url", url, "err", delErr)
-				}
-			}(logoURL)
+			app.enqueuePhotoDelete(logoURL)
 		}
 		app.internalServerError(w, r, fmt.Errorf("create brand: %w", err))
 		return
 	}
 
+	app.recordAudit(r, audit.EntityBrand, audit.ActionCreate, created.ID, nil, created)
+
 	w.Header().Set("Location", fmt.Sprintf("/v1/store/admin/brands/%d", created.ID))
 	app.jsonResponse(w, http.StatusCreated, created)
 }
@@ -294,7 +292,7 @@ func (app *application) updateBrandHandler(w http.ResponseWriter, r *http.Reques
 			return
 		}
 
-		// choose slug for Cloudinary folder: prefer updated slug, else existing, else generate from (updated) name
+		// choose slug for the upload folder: prefer updated slug, else existing, else generate from (updated) name
 		baseSlug := ""
 		switch {
 		case upd.Slug != nil && *upd.Slug != "":
@@ -306,7 +304,7 @@ func (app *application) updateBrandHandler(w http.ResponseWriter, r *http.Reques
 		}
 
 		publicID := fmt.Sprintf("brand/%s_logo_%d", baseSlug, time.Now().UnixNano())
-		url, upErr := app.uploadToCloudinaryWithID(file, publicID, "brands")
+		url, upErr := app.uploadFileWithID(file, publicID, "brands")
 		if upErr != nil {
 			app.internalServerError(w, r, fmt.Errorf("upload logo: %w", upErr))
 			return
@@ -323,12 +321,12 @@ func (app *application) updateBrandHandler(w http.ResponseWriter, r *http.Reques
 			// UNIQUE violation on name/slug
 			app.conflictResponse(w, r, fmt.Errorf("brand with same name or slug already exists"))
 			if newLogoURL != "" {
-				go func(u string) { _ = app.deletePhotoFromCloudinary(u) }(newLogoURL)
+				app.enqueuePhotoDelete(newLogoURL)
 			}
 			return
 		}
 		if newLogoURL != "" {
-			go func(u string) { _ = app.deletePhotoFromCloudinary(u) }(newLogoURL)
+			app.enqueuePhotoDelete(newLogoURL)
 		}
 		app.internalServerError(w, r, fmt.Errorf("update brand: %w", err))
 		return
@@ -336,7 +334,7 @@ func (app *application) updateBrandHandler(w http.ResponseWriter, r *http.Reques
 
 	// 8) Clean up old logo AFTER success if replaced
 	if newLogoURL != "" && oldLogoURL != "" && oldLogoURL != newLogoURL {
-		go func(u string) { _ = app.deletePhotoFromCloudinary(u) }(oldLogoURL)
+		app.enqueuePhotoDelete(oldLogoURL)
 	}
 
 	// 9) Return fresh row
@@ -346,6 +344,8 @@ func (app *application) updateBrandHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.recordAudit(r, audit.EntityBrand, audit.ActionUpdate, id, existing, updated)
+
 	app.jsonResponse(w, http.StatusOK, map[string]any{
 		"message": "Brand updated successfully",
 		"brand":   updated,
@@ -355,7 +355,7 @@ func (app *application) updateBrandHandler(w http.ResponseWriter, r *http.Reques
 // DeleteBrand godoc
 //
 //	@Summary		Delete a brand
-//	@Description	Deletes a brand by ID. Fails if the brand is referenced by any products.
+//	@Description	Moves a brand to the trash. It can be restored until the purge job removes it. Fails if the brand is referenced by any products.
 //	@Tags			Store-Admin
 //	@Produce		json
 //	@Param			brandID	path		int		true	"Brand ID"
@@ -377,16 +377,16 @@ func (app *application) deleteBrandHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Load once to get logoURL for cleanup (and to 404 early)
+	// 404 early
 	brand, err := app.store.Products.GetBrandByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, products.ErrBrandNotFound) {
-			app.notFoundResponse(w, r, err)
-			return
-		}
 		app.internalServerError(w, r, err)
 		return
 	}
+	if brand == nil {
+		app.notFoundResponse(w, r, products.ErrBrandNotFound)
+		return
+	}
 
 	hasProducts, err := app.store.Products.BrandHasProducts(ctx, id)
 	if err != nil {
@@ -398,31 +398,17 @@ func (app *application) deleteBrandHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Delete
+	// Soft delete; the logo stays in storage until the brand is purged.
 	if err := app.store.Products.DeleteBrand(ctx, id); err != nil {
-		switch {
-		case errors.Is(err, products.ErrBrandNotFound):
+		if errors.Is(err, products.ErrBrandNotFound) {
 			app.notFoundResponse(w, r, err)
-		default:
-			// Map FK violation/guards to 409
-			if strings.Contains(err.Error(), "dependent records") {
-				app.conflictResponse(w, r, fmt.Errorf("cannot delete brand: dependent records exist"))
-				return
-			}
-			// Unique/other DB errors
-			app.internalServerError(w, r, err)
+			return
 		}
+		app.internalServerError(w, r, err)
 		return
 	}
 
-	// Best-effort async Cloudinary cleanup
-	if brand.LogoURL != nil && *brand.LogoURL != "" {
-		go func(url string) {
-			if err := app.deletePhotoFromCloudinary(url); err != nil {
-				app.logger.Error("cloudinary delete failed", "brand_id", id, "url", url, "err", err)
-			}
-		}(*brand.LogoURL)
-	}
+	app.recordAudit(r, audit.EntityBrand, audit.ActionDelete, id, brand, nil)
 
 	// 204 No Content on success
 	w.WriteHeader(http.StatusNoContent)
@@ -529,7 +515,7 @@ func (app *application) createCategoryHandler(w http.ResponseWriter, r *http.Req
 			parentIDPtr = &parsedID
 		} else {
 			// Handle error - log it and leave as nil, or return error
-			log.Printf("Invalid parent_id: %s, error: %v", parentIDStr, err)
+			app.requestLogger(r).Warnw("invalid parent_id", "parent_id", parentIDStr, "error", err)
 			parentIDPtr = nil
 		}
 	}
@@ -572,7 +558,7 @@ func (app *application) createCategoryHandler(w http.ResponseWriter, r *http.Req
 		publicID := fmt.Sprintf("category/%s_logo_%d", slug, time.Now().UnixNano())
 
 		// upload using the same file reader (we reset it in sniffMIME)
-		url, upErr := app.uploadToCloudinaryWithID(file, publicID, "categories")
+		url, upErr := app.uploadFileWithID(file, publicID, "categories")
 		if upErr != nil {
 			app.internalServerError(w, r, fmt.Errorf("upload logo: %w", upErr))
 			return
@@ -595,16 +581,14 @@ func (app *application) createCategoryHandler(w http.ResponseWriter, r *http.Req
 	if err != nil {
 		// Clean up uploaded logo if brand creation fails
 		if logoURL != "" {
-			go func(url string) {
-				if err := app.deletePhotoFromCloudinary(url); err != nil {
-					app.logger.Error("failed to delete category logo from Cloudinary", "url", url, "err", err)
-				}
-			}(logoURL)
+			app.enqueuePhotoDelete(logoURL)
 		}
 		app.internalServerError(w, r, fmt.Errorf("failed to create category: %w", err))
 		return
 	}
 
+	app.recordAudit(r, audit.EntityCategory, audit.ActionCreate, CreatedCategory.ID, nil, CreatedCategory)
+
 	// Respond with created brand
 	app.jsonResponse(w, http.StatusCreated, CreatedCategory)
 }
@@ -652,7 +636,7 @@ func (app *application) listCategoriesHandler(w http.ResponseWriter, r *http.Req
 // DeleteCategory godoc
 //
 //	@Summary		Delete a category
-//	@Description	Deletes a category and asynchronously removes its images from Cloudinary. Fails if category has children or dependent records.
+//	@Description	Moves a category to the trash. Its images are kept until the purge job removes it. Fails if category has children.
 //	@Tags			Store-Admin
 //	@Produce		json
 //	@Param			categoryID	path		int				true	"Category ID"
@@ -674,7 +658,7 @@ func (app *application) deleteCategoryHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check if category exists first (required for getting image URLs)
+	// Check if category exists first (needed for the response and audit line)
 	existingCategory, err := app.store.Products.GetCategoryByID(ctx, id)
 	if err != nil {
 		switch {
@@ -699,31 +683,9 @@ func (app *application) deleteCategoryHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Delete images from Cloudinary (async - don't block response)
-	if len(existingCategory.ImageURLs) > 0 {
-		go func(imageURLs []string) {
-
-			for _, url := range imageURLs {
-				if url != "" {
-					if err := app.deletePhotoFromCloudinary(url); err != nil {
-						app.logger.Error("failed to delete category image from Cloudinary",
-							"url", url,
-							"category_id", id,
-							"category_name", existingCategory.Name,
-							"err", err)
-					} else {
-						app.logger.Info("successfully deleted category image from Cloudinary",
-							"url", url,
-							"category_id", id,
-							"category_name", existingCategory.Name)
-					}
-				}
-			}
-		}(existingCategory.ImageURLs)
-	}
-
 	// Log the deletion for audit purposes
-	app.logger.Info("category deleted",
+	app.recordAudit(r, audit.EntityCategory, audit.ActionDelete, id, existingCategory, nil)
+	app.logger.Info("category moved to trash",
 		"category_id", id,
 		"category_name", existingCategory.Name,
 		"image_count", len(existingCategory.ImageURLs),
@@ -732,7 +694,7 @@ func (app *application) deleteCategoryHandler(w http.ResponseWriter, r *http.Req
 
 	// Return success response
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"message": "Category deleted successfully",
+		"message": "Category moved to trash",
 		"deleted_category": map[string]interface{}{
 			"id":          id,
 			"name":        existingCategory.Name,
@@ -836,7 +798,7 @@ func (app *application) updateCategoryHandler(w http.ResponseWriter, r *http.Req
 		upd.IsActive = strings.EqualFold(isActiveStr, "true")
 	}
 
-	// Track Cloudinary URLs for cleanup decisions
+	// Track uploaded URLs for cleanup decisions
 	var (
 		newLogoURL       string
 		oldURLsToDelete  []string // filled only when replace_logo=true
@@ -864,7 +826,7 @@ func (app *application) updateCategoryHandler(w http.ResponseWriter, r *http.Req
 		}
 
 		publicID := fmt.Sprintf("category/%s_logo_%d", baseSlug, time.Now().UnixNano())
-		url, upErr := app.uploadToCloudinaryWithID(file, publicID, "categories")
+		url, upErr := app.uploadFileWithID(file, publicID, "categories")
 		if upErr != nil {
 			app.internalServerError(w, r, fmt.Errorf("upload logo: %w", upErr))
 			return
@@ -895,11 +857,7 @@ func (app *application) updateCategoryHandler(w http.ResponseWriter, r *http.Req
 	if err != nil {
 		// rollback newly-uploaded url to avoid orphaning
 		if newLogoURL != "" {
-			go func(u string) {
-				if delErr := app.deletePhotoFromCloudinary(u); delErr != nil {
-					app.logger.Error("rollback: failed to delete uploaded category image", "url", u, "err", delErr)
-				}
-			}(newLogoURL)
+			app.enqueuePhotoDelete(newLogoURL)
 		}
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
 			app.conflictResponse(w, r, fmt.Errorf("category with same slug already exists"))
@@ -910,22 +868,13 @@ func (app *application) updateCategoryHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// After a successful UPDATE:
-	// If we replaced logos, delete old Cloudinary images async
-	if len(oldURLsToDelete) > 0 {
-		go func(urls []string) {
-			for _, u := range urls {
-				if u == "" {
-					continue
-				}
-				if delErr := app.deletePhotoFromCloudinary(u); delErr != nil {
-					app.logger.Error("failed to delete old category image from Cloudinary", "url", u, "category_id", id, "err", delErr)
-				} else {
-					app.logger.Info("deleted old category image from Cloudinary", "url", u, "category_id", id)
-				}
-			}
-		}(oldURLsToDelete)
+	// If we replaced logos, delete old images async
+	for _, u := range oldURLsToDelete {
+		app.enqueuePhotoDelete(u)
 	}
 
+	app.recordAudit(r, audit.EntityCategory, audit.ActionUpdate, id, existing, updated)
+
 	app.jsonResponse(w, http.StatusOK, map[string]any{
 		"message":  "Category updated successfully",
 		"category": updated,
@@ -1134,15 +1083,16 @@ func (app *application) getProductByIDHandler(w http.ResponseWriter, r *http.Req
 // ListProducts godoc
 //
 //	@Summary		List products (admin)
-//	@Description	Returns a paginated list of product cards for the admin panel. Supports optional filtering by category slug.
+//	@Description	Returns a paginated list of product cards for the admin panel. Supports optional filtering by category slug and by variant attributes: attr.<key>=v1,v2 keeps products with an active variant whose attribute is one of the values, and several attr parameters must all match the same variant (e.g. ?attr.size=42&attr.color=red). facets counts the matching products per value of each filterable attribute of the category, ignoring that attribute's own filter. Signed-in shoppers also get wishlisted on each card.
 //	@Tags			Products
 //	@Produce		json
 //
 //	@Param			category_slug	query		string			false	"Filter products by category slug"
+//	@Param			attr.{key}		query		string			false	"Comma-separated attribute values, e.g. attr.size=41,42"
 //	@Param			page			query		int				false	"Page number (default: 1)"
 //	@Param			limit			query		int				false	"Items per page (default: 15)"
 //
-//	@Success		200				{object}	map[string]any	"products list with pagination and applied filters"
+//	@Success		200				{object}	map[string]any	"products list with pagination, facets and applied filters"
 //	@Failure		400				{object}	error			"Bad Request"
 //	@Failure		500				{object}	error			"Internal Server Error"
 //	@Security		ApiKeyAuth
@@ -1151,18 +1101,34 @@ func (app *application) listProductsHandler(w http.ResponseWriter, r *http.Reque
 	ctx := r.Context()
 	pg := params.ParsePagination(r.URL.Query())
 	categorySlug := strings.TrimSpace(r.URL.Query().Get("category_slug"))
+	attrs, err := parseAttributeFilter(r.URL.Query())
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
 
-	items, total, err := app.store.Products.ListProductCards(ctx, categorySlug, pg.Limit, pg.Offset)
+	items, total, err := app.store.Products.ListProductCards(ctx, categorySlug, attrs, pg.Limit, pg.Offset)
 	if err != nil {
 		app.internalServerError(w, r, fmt.Errorf("list products: %w", err))
 		return
 	}
 	pg.ComputeMeta(total)
+	if err := app.markWishlisted(r, items); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	facets, err := app.store.Products.ProductFacets(ctx, categorySlug, attrs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
 	app.jsonResponse(w, http.StatusOK, map[string]any{
 		"products":   items,
 		"pagination": pg,
-		"filters":    map[string]any{"category_slug": categorySlug},
+		"facets":     facets,
+		"filters":    map[string]any{"category_slug": categorySlug, "attributes": attrs},
 	})
 }
 
@@ -1263,6 +1229,8 @@ func (app *application) createProductHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	app.recordAudit(r, audit.EntityProduct, audit.ActionCreate, created.ID, nil, created)
+
 	w.Header().Set("Location", fmt.Sprintf("/v1/store/admin/products/%d", created.ID))
 	app.jsonResponse(w, http.StatusCreated, created)
 }
@@ -1310,6 +1278,11 @@ func (app *application) updateProductHandler(w http.ResponseWriter, r *http.Requ
 		app.internalServerError(w, r, err)
 		return
 	}
+	if existing == nil {
+		app.notFoundResponse(w, r, products.ErrProductNotFound)
+		return
+	}
+	before := *existing
 
 	// 4) Apply changes + validate
 	if in.Name != nil {
@@ -1372,6 +1345,9 @@ func (app *application) updateProductHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	app.recordAudit(r, audit.EntityProduct, audit.ActionUpdate, productID, before, updated)
+	app.publishProductChanged(ctx, productID)
+
 	// 6) Respond
 	app.jsonResponse(w, http.StatusOK, updated)
 }
@@ -1406,6 +1382,7 @@ func (app *application) publishProductHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	before := *p
 	p.IsActive = true
 	updated, err := app.store.Products.UpdateProduct(ctx, p)
 	if err != nil {
@@ -1413,12 +1390,54 @@ func (app *application) publishProductHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, audit.EntityProduct, audit.ActionPublish, id, before, updated)
+	app.publishProductChanged(ctx, id)
+
 	app.jsonResponse(w, http.StatusOK, map[string]any{
 		"message": "published",
 		"product": updated,
 	})
 }
 
+// DeleteProduct godoc
+//
+//	@Summary		Delete a product
+//	@Description	Moves a product to the trash. Variants, images and order history are kept so it can be restored.
+//	@Tags			Store-Admin
+//	@Produce		json
+//	@Param			productID	path		int		true	"Product ID"
+//	@Success		204			{string}	string	"No Content"
+//	@Failure		400			{object}	error	"Bad Request: invalid product ID"
+//	@Failure		404			{object}	error	"Not Found: product not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/admin/products/{productID} [delete]
+func (app *application) deleteProductHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	idStr := chi.URLParam(r, "productID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid product ID: %s", idStr))
+		return
+	}
+
+	if err := app.store.Products.DeleteProduct(ctx, id); err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, audit.EntityProduct, audit.ActionDelete, id, nil, nil)
+	app.publishProductChanged(ctx, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (app *application) searchProductsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -1436,6 +1455,10 @@ func (app *application) searchProductsHandler(w http.ResponseWriter, r *http.Req
 		app.internalServerError(w, r, err)
 		return
 	}
+	if err := app.markWishlisted(r, products); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
 	pagination.ComputeMeta(total)
 
@@ -1459,16 +1482,24 @@ func (app *application) fullTextSearchProductsHandler(w http.ResponseWriter, r *
 
 	pagination := params.ParsePagination(r.URL.Query())
 
-	products, total, err := app.store.Products.FullTextSearchProducts(ctx, q, pagination.Limit, pagination.Offset)
+	results, total, err := app.search.SearchProducts(ctx, q, pagination.Limit, pagination.Offset)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+	cards := make([]*products.ProductCard, len(results))
+	for i := range results {
+		cards[i] = &results[i].ProductCard
+	}
+	if err := app.markWishlisted(r, cards); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
 	pagination.ComputeMeta(total)
 
 	app.jsonResponse(w, http.StatusOK, map[string]any{
-		"products":    products,
+		"products":    results,
 		"pagination":  pagination,
 		"query":       q,
 		"search_type": "full_text",
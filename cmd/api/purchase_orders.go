@@ -0,0 +1,521 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/inventory"
+	"khel/internal/events"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SupplierPayload struct {
+	Name  string  `json:"name" validate:"required,max=120"`
+	Phone *string `json:"phone" validate:"omitempty,max=30"`
+	Email *string `json:"email" validate:"omitempty,email,max=255"`
+	Note  *string `json:"note" validate:"omitempty,max=500"`
+	// IsActive is only read on update; omitted keeps the supplier active.
+	IsActive *bool `json:"is_active"`
+}
+
+type PurchaseOrderLinePayload struct {
+	InventoryItemID int64 `json:"inventory_item_id" validate:"required,min=1"`
+	Quantity        int   `json:"quantity" validate:"required,min=1"`
+	UnitCost        *int  `json:"unit_cost" validate:"required,min=0"`
+}
+
+type PurchaseOrderPayload struct {
+	SupplierID int64                      `json:"supplier_id" validate:"required,min=1"`
+	Note       *string                    `json:"note" validate:"omitempty,max=500"`
+	Lines      []PurchaseOrderLinePayload `json:"lines" validate:"required,min=1,max=100,dive"`
+}
+
+// toPurchaseOrder builds the order's lines, refusing an item listed twice.
+func (p PurchaseOrderPayload) toPurchaseOrder(venueID int64) (*inventory.PurchaseOrder, error) {
+	po := &inventory.PurchaseOrder{
+		VenueID:    venueID,
+		SupplierID: p.SupplierID,
+		Note:       cleanOptionalString(p.Note),
+		Lines:      make([]inventory.PurchaseOrderLine, 0, len(p.Lines)),
+	}
+	seen := make(map[int64]bool, len(p.Lines))
+	for _, l := range p.Lines {
+		if seen[l.InventoryItemID] {
+			return nil, fmt.Errorf("item %d is listed twice", l.InventoryItemID)
+		}
+		seen[l.InventoryItemID] = true
+		po.Lines = append(po.Lines, inventory.PurchaseOrderLine{
+			InventoryItemID: l.InventoryItemID,
+			Quantity:        l.Quantity,
+			UnitCost:        *l.UnitCost,
+		})
+	}
+	return po, nil
+}
+
+// listSuppliersHandler godoc
+//
+//	@Summary		List suppliers
+//	@Description	Active suppliers first, then by name.
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{array}		inventory.Supplier
+//	@Failure		400		{object}	ErrorResponse	"Bad request"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/suppliers [get]
+func (app *application) listSuppliersHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	suppliers, err := app.store.Inventory.ListSuppliers(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, suppliers)
+}
+
+// createSupplierHandler godoc
+//
+//	@Summary		Add a supplier
+//	@Tags			venue inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			payload	body		SupplierPayload	true	"Supplier"
+//	@Success		201		{object}	inventory.Supplier
+//	@Failure		400		{object}	ErrorResponse	"Bad request"
+//	@Failure		409		{object}	ErrorResponse	"Supplier name already used"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/suppliers [post]
+func (app *application) createSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload SupplierPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	sup := &inventory.Supplier{
+		VenueID: venueID,
+		Name:    payload.Name,
+		Phone:   cleanOptionalString(payload.Phone),
+		Email:   cleanOptionalString(payload.Email),
+		Note:    cleanOptionalString(payload.Note),
+	}
+	if err := app.store.Inventory.CreateSupplier(r.Context(), sup); err != nil {
+		if errors.Is(err, inventory.ErrDuplicateSupplier) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, sup)
+}
+
+// updateSupplierHandler godoc
+//
+//	@Summary		Update a supplier
+//	@Description	Replaces the supplier's details. Deactivated suppliers stay on past purchase orders but cannot be used for new ones.
+//	@Tags			venue inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID		path		int				true	"Venue ID"
+//	@Param			supplierID	path		int				true	"Supplier ID"
+//	@Param			payload		body		SupplierPayload	true	"Supplier"
+//	@Success		200			{object}	inventory.Supplier
+//	@Failure		400			{object}	ErrorResponse	"Bad request"
+//	@Failure		404			{object}	ErrorResponse	"Supplier not found"
+//	@Failure		409			{object}	ErrorResponse	"Supplier name already used"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/suppliers/{supplierID} [put]
+func (app *application) updateSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	supplierID, err := readIDParam(r, "supplierID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid supplier ID"))
+		return
+	}
+
+	var payload SupplierPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	sup := &inventory.Supplier{
+		ID:       supplierID,
+		VenueID:  venueID,
+		Name:     payload.Name,
+		Phone:    cleanOptionalString(payload.Phone),
+		Email:    cleanOptionalString(payload.Email),
+		Note:     cleanOptionalString(payload.Note),
+		IsActive: true,
+	}
+	if payload.IsActive != nil {
+		sup.IsActive = *payload.IsActive
+	}
+	if err := app.store.Inventory.UpdateSupplier(r.Context(), sup); err != nil {
+		switch {
+		case errors.Is(err, inventory.ErrSupplierNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, inventory.ErrDuplicateSupplier):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, sup)
+}
+
+// createPurchaseOrderHandler godoc
+//
+//	@Summary		Draft a purchase order
+//	@Description	Saves a draft order to a supplier. Drafts can be edited, then placed with /order and booked into stock with /receive.
+//	@Tags			venue inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int						true	"Venue ID"
+//	@Param			payload	body		PurchaseOrderPayload	true	"Supplier and lines"
+//	@Success		201		{object}	inventory.PurchaseOrder
+//	@Failure		400		{object}	ErrorResponse	"Bad request"
+//	@Failure		404		{object}	ErrorResponse	"Supplier or inventory item not found"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/purchase-orders [post]
+func (app *application) createPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload PurchaseOrderPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	po, err := payload.toPurchaseOrder(venueID)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	po.CreatedBy = &getUserFromContext(r).ID
+
+	if err := app.store.Inventory.CreatePurchaseOrder(r.Context(), po); err != nil {
+		app.purchaseOrderError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, po)
+}
+
+// listPurchaseOrdersHandler godoc
+//
+//	@Summary		List purchase orders
+//	@Description	Newest first, without lines.
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			status	query		string			false	"draft, ordered, received or cancelled"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"purchase_orders + pagination metadata"
+//	@Failure		400		{object}	ErrorResponse	"Bad request"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/purchase-orders [get]
+func (app *application) listPurchaseOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	status := strings.TrimSpace(q.Get("status"))
+	switch status {
+	case "", inventory.PurchaseOrderDraft, inventory.PurchaseOrderOrdered, inventory.PurchaseOrderReceived, inventory.PurchaseOrderCancelled:
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("invalid status"))
+		return
+	}
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Inventory.ListPurchaseOrders(r.Context(), venueID, status, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"purchase_orders": list,
+		"pagination":      pagination,
+	})
+}
+
+// getPurchaseOrderHandler godoc
+//
+//	@Summary		Get a purchase order
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Param			venueID			path		int	true	"Venue ID"
+//	@Param			purchaseOrderID	path		int	true	"Purchase order ID"
+//	@Success		200				{object}	inventory.PurchaseOrder
+//	@Failure		400				{object}	ErrorResponse	"Bad request"
+//	@Failure		404				{object}	ErrorResponse	"Purchase order not found"
+//	@Failure		500				{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/purchase-orders/{purchaseOrderID} [get]
+func (app *application) getPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, poID, ok := app.readPurchaseOrderParams(w, r)
+	if !ok {
+		return
+	}
+
+	po, err := app.store.Inventory.GetPurchaseOrder(r.Context(), venueID, poID)
+	if err != nil {
+		app.purchaseOrderError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, po)
+}
+
+// updatePurchaseOrderHandler godoc
+//
+//	@Summary		Edit a draft purchase order
+//	@Description	Replaces the supplier, note and lines. Only drafts can be edited.
+//	@Tags			venue inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID			path		int						true	"Venue ID"
+//	@Param			purchaseOrderID	path		int						true	"Purchase order ID"
+//	@Param			payload			body		PurchaseOrderPayload	true	"Supplier and lines"
+//	@Success		200				{object}	inventory.PurchaseOrder
+//	@Failure		400				{object}	ErrorResponse	"Bad request"
+//	@Failure		404				{object}	ErrorResponse	"Purchase order, supplier or item not found"
+//	@Failure		409				{object}	ErrorResponse	"Purchase order is no longer a draft"
+//	@Failure		500				{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/purchase-orders/{purchaseOrderID} [put]
+func (app *application) updatePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, poID, ok := app.readPurchaseOrderParams(w, r)
+	if !ok {
+		return
+	}
+
+	var payload PurchaseOrderPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	po, err := payload.toPurchaseOrder(venueID)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	po.ID = poID
+
+	if err := app.store.Inventory.UpdateDraftPurchaseOrder(r.Context(), po); err != nil {
+		app.purchaseOrderError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, po)
+}
+
+// orderPurchaseOrderHandler godoc
+//
+//	@Summary		Place a purchase order
+//	@Description	Moves a draft to ordered. The lines are fixed from then on.
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Param			venueID			path		int	true	"Venue ID"
+//	@Param			purchaseOrderID	path		int	true	"Purchase order ID"
+//	@Success		200				{object}	inventory.PurchaseOrder
+//	@Failure		400				{object}	ErrorResponse	"Bad request"
+//	@Failure		404				{object}	ErrorResponse	"Purchase order not found"
+//	@Failure		409				{object}	ErrorResponse	"Purchase order is not a draft"
+//	@Failure		500				{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/purchase-orders/{purchaseOrderID}/order [post]
+func (app *application) orderPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, poID, ok := app.readPurchaseOrderParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.store.Inventory.MarkPurchaseOrderOrdered(r.Context(), venueID, poID); err != nil {
+		app.purchaseOrderError(w, r, err)
+		return
+	}
+
+	app.respondWithPurchaseOrder(w, r, venueID, poID)
+}
+
+// cancelPurchaseOrderHandler godoc
+//
+//	@Summary		Cancel a purchase order
+//	@Description	Drafts and placed orders can be cancelled; received ones cannot.
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Param			venueID			path		int	true	"Venue ID"
+//	@Param			purchaseOrderID	path		int	true	"Purchase order ID"
+//	@Success		200				{object}	inventory.PurchaseOrder
+//	@Failure		400				{object}	ErrorResponse	"Bad request"
+//	@Failure		404				{object}	ErrorResponse	"Purchase order not found"
+//	@Failure		409				{object}	ErrorResponse	"Purchase order already received or cancelled"
+//	@Failure		500				{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/purchase-orders/{purchaseOrderID}/cancel [post]
+func (app *application) cancelPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, poID, ok := app.readPurchaseOrderParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.store.Inventory.CancelPurchaseOrder(r.Context(), venueID, poID); err != nil {
+		app.purchaseOrderError(w, r, err)
+		return
+	}
+
+	app.respondWithPurchaseOrder(w, r, venueID, poID)
+}
+
+// receivePurchaseOrderHandler godoc
+//
+//	@Summary		Receive a purchase order
+//	@Description	Adds every line's quantity to stock, recording a purchase movement per item in the stock ledger, and turns on stock tracking for those items. Items that had run out are announced as back in stock.
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Param			venueID			path		int	true	"Venue ID"
+//	@Param			purchaseOrderID	path		int	true	"Purchase order ID"
+//	@Success		200				{object}	inventory.PurchaseOrder
+//	@Failure		400				{object}	ErrorResponse	"Bad request"
+//	@Failure		404				{object}	ErrorResponse	"Purchase order not found"
+//	@Failure		409				{object}	ErrorResponse	"Purchase order is not placed"
+//	@Failure		500				{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/purchase-orders/{purchaseOrderID}/receive [post]
+func (app *application) receivePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, poID, ok := app.readPurchaseOrderParams(w, r)
+	if !ok {
+		return
+	}
+
+	restocked, err := app.store.Inventory.ReceivePurchaseOrder(r.Context(), venueID, poID, getUserFromContext(r).ID)
+	if err != nil {
+		app.purchaseOrderError(w, r, err)
+		return
+	}
+	app.publishRestocked(poID, restocked)
+
+	app.respondWithPurchaseOrder(w, r, venueID, poID)
+}
+
+func (app *application) readPurchaseOrderParams(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return 0, 0, false
+	}
+	poID, err := readIDParam(r, "purchaseOrderID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid purchase order ID"))
+		return 0, 0, false
+	}
+	return venueID, poID, true
+}
+
+func (app *application) respondWithPurchaseOrder(w http.ResponseWriter, r *http.Request, venueID, poID int64) {
+	po, err := app.store.Inventory.GetPurchaseOrder(r.Context(), venueID, poID)
+	if err != nil {
+		app.purchaseOrderError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, po)
+}
+
+func (app *application) purchaseOrderError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, inventory.ErrPurchaseOrderNotFound),
+		errors.Is(err, inventory.ErrSupplierNotFound),
+		errors.Is(err, inventory.ErrInventoryItemNotFound):
+		app.notFoundResponse(w, r, err)
+	case errors.Is(err, inventory.ErrPurchaseOrderStatus):
+		app.conflictResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+// publishRestocked announces items a received purchase order brought back
+// into stock. Failures are logged; the stock itself is already booked.
+func (app *application) publishRestocked(poID int64, restocked []inventory.Restocked) {
+	if len(restocked) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	for _, it := range restocked {
+		key := strconv.FormatInt(poID, 10) + ":" + strconv.FormatInt(it.InventoryItemID, 10)
+		err := app.events.Publish(ctx, events.InventoryRestocked, key, events.InventoryRestockedPayload{
+			VenueID:         it.VenueID,
+			InventoryItemID: it.InventoryItemID,
+			ItemName:        it.ItemName,
+			QuantityAfter:   it.QuantityAfter,
+			PurchaseOrderID: poID,
+		})
+		if err != nil {
+			app.logger.Errorw("failed to publish inventory restocked", "purchase_order_id", poID, "item_id", it.InventoryItemID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/notifications"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// rebookSearchWindow is how far either side of the original start time we
+	// look for an alternative on the same facility.
+	rebookSearchWindow = 3 * time.Hour
+	rebookStep         = 30 * time.Minute
+	maxRebookOptions   = 5
+)
+
+// RebookAlternative is a free slot offered when the original one is taken.
+type RebookAlternative struct {
+	FacilityID   int64     `json:"facility_id"`
+	FacilityName string    `json:"facility_name"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	TotalPrice   int       `json:"total_price"`
+}
+
+// RebookResponse carries either the new booking or, when the slot is taken,
+// the nearest free alternatives.
+type RebookResponse struct {
+	Rebooked       bool                `json:"rebooked"`
+	Booking        *BookingResponse    `json:"booking,omitempty"`
+	RequestedStart time.Time           `json:"requested_start"`
+	RequestedEnd   time.Time           `json:"requested_end"`
+	Alternatives   []RebookAlternative `json:"alternatives"`
+}
+
+// rebookHandler godoc
+//
+//	@Summary		Rebook the same slot next week
+//	@Description	Books the same venue, facility and time one week after the original booking (or the next future week if that has passed). The new booking is pending like any other. If the slot is taken, nothing is booked and up to 5 nearest free alternatives are returned: the same time on another facility of the venue, or the same facility up to 3 hours earlier or later that day.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			bookingID	path		string			true	"Booking ID (hash or numeric)"
+//	@Success		201			{object}	RebookResponse	"Booking created"
+//	@Success		200			{object}	RebookResponse	"Slot taken; alternatives returned"
+//	@Failure		400			{object}	error			"Bad Request"
+//	@Failure		404			{object}	error			"Booking not found"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/bookings/{bookingID}/rebook [post]
+func (app *application) rebookHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	original, err := app.store.Bookings.GetBookingByID(r.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	// Don't reveal other users' bookings.
+	if original.UserID != user.ID {
+		app.notFoundResponse(w, r, bookings.ErrNotFound)
+		return
+	}
+
+	start, end := nextWeeklySlot(original.StartTime, original.EndTime, time.Now())
+	resp := RebookResponse{
+		RequestedStart: start,
+		RequestedEnd:   end,
+		Alternatives:   []RebookAlternative{},
+	}
+
+	price, priceErr := app.calculateFacilityBookingPrice(r, original.VenueID, original.FacilityID, start, end)
+	if priceErr == nil {
+		if err := app.ensureFacilityTimeIsAvailable(r, original.VenueID, original.FacilityID, start, end); err == nil {
+			booking := &bookings.Booking{
+				VenueID:    original.VenueID,
+				FacilityID: original.FacilityID,
+				UserID:     user.ID,
+				StartTime:  start,
+				EndTime:    end,
+				TotalPrice: price,
+				Status:     "pending",
+			}
+			if _, err := app.store.Bookings.CreateBooking(r.Context(), booking); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+
+			app.notifyOwnerOfBooking(original.VenueID, booking.ID)
+
+			br := app.bookingToResponse(booking)
+			resp.Rebooked = true
+			resp.Booking = &br
+			app.jsonResponse(w, http.StatusCreated, resp)
+			return
+		}
+	}
+
+	alternatives, err := app.findRebookAlternatives(r, original, start, end)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	resp.Alternatives = alternatives
+
+	app.jsonResponse(w, http.StatusOK, resp)
+}
+
+// nextWeeklySlot moves a booking forward a week at a time until it starts
+// after now. Nepal has no DST, so adding whole days keeps the local time.
+func nextWeeklySlot(start, end, now time.Time) (time.Time, time.Time) {
+	start, end = start.AddDate(0, 0, 7), end.AddDate(0, 0, 7)
+	for !start.After(now) {
+		start, end = start.AddDate(0, 0, 7), end.AddDate(0, 0, 7)
+	}
+	return start, end
+}
+
+// findRebookAlternatives looks for free slots of the same length: the same
+// time on other active facilities of the venue (same sport when known),
+// then the same facility shifted in 30 minute steps within the same day.
+// Results are ordered by how far they move from the requested start.
+func (app *application) findRebookAlternatives(r *http.Request, original *bookings.Booking, start, end time.Time) ([]RebookAlternative, error) {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Nepal timezone: %w", err)
+	}
+
+	facilityList, err := app.store.Facilities.ListByVenueID(r.Context(), original.VenueID)
+	if err != nil {
+		return nil, fmt.Errorf("list facilities: %w", err)
+	}
+
+	var sport *string
+	names := make(map[int64]string, len(facilityList))
+	for _, f := range facilityList {
+		names[f.ID] = f.Name
+		if f.ID == original.FacilityID {
+			sport = f.Sport
+		}
+	}
+
+	type candidate struct {
+		facilityID int64
+		start      time.Time
+	}
+	var candidates []candidate
+
+	for _, f := range facilityList {
+		if f.ID == original.FacilityID || !f.IsActive {
+			continue
+		}
+		if sport != nil && f.Sport != nil && *f.Sport != *sport {
+			continue
+		}
+		candidates = append(candidates, candidate{facilityID: f.ID, start: start})
+	}
+
+	local := start.In(loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	length := end.Sub(start)
+	for shift := rebookStep; shift <= rebookSearchWindow; shift += rebookStep {
+		for _, s := range []time.Time{start.Add(-shift), start.Add(shift)} {
+			if s.Before(dayStart) || s.Add(length).After(dayEnd) || !s.After(time.Now()) {
+				continue
+			}
+			candidates = append(candidates, candidate{facilityID: original.FacilityID, start: s})
+		}
+	}
+
+	// One lookup of existing bookings per facility; the candidates all fall
+	// on the same day.
+	booked := map[int64][]bookings.Interval{}
+	options := []RebookAlternative{}
+	for _, c := range candidates {
+		if len(options) == maxRebookOptions {
+			break
+		}
+
+		intervals, ok := booked[c.facilityID]
+		if !ok {
+			intervals, err = app.store.Bookings.GetBookingsForDate(r.Context(), original.VenueID, c.facilityID, start)
+			if err != nil {
+				return nil, fmt.Errorf("get existing bookings: %w", err)
+			}
+			booked[c.facilityID] = intervals
+		}
+
+		slot := bookings.Interval{Start: c.start, End: c.start.Add(length)}
+		if isIntervalBooked(slot, intervals) {
+			continue
+		}
+
+		// Outside pricing hours means the facility is closed then.
+		price, err := app.calculateFacilityBookingPrice(r, original.VenueID, c.facilityID, slot.Start, slot.End)
+		if err != nil {
+			continue
+		}
+
+		options = append(options, RebookAlternative{
+			FacilityID:   c.facilityID,
+			FacilityName: names[c.facilityID],
+			StartTime:    slot.Start,
+			EndTime:      slot.End,
+			TotalPrice:   price,
+		})
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		return absDuration(options[i].StartTime.Sub(start)) < absDuration(options[j].StartTime.Sub(start))
+	})
+	return options, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// notifyOwnerOfBooking pushes a "new booking request" to the venue owner.
+func (app *application) notifyOwnerOfBooking(venueID, bookingID int64) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		ownerID, err := app.store.Venues.GetOwnerIDFromVenueID(ctx, venueID)
+		if err != nil {
+			app.logger.Errorw("failed to load venue owner for booking notification", "venue_id", venueID, "error", err)
+			return
+		}
+		err = notifications.SendBookingNotification(ctx, app.push, app.store, ownerID, notifications.BookingCreated, app.EncodeBookingID(bookingID))
+		if err != nil {
+			app.logger.Errorw("failed to send booking created notification", "booking_id", bookingID, "error", err)
+		}
+	}()
+}
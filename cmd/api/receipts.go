@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/documents"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/facilities"
+	"khel/internal/domain/paymentsplits"
+	"khel/internal/domain/users"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getBookingReceiptHandler godoc
+//
+//	@Summary		Get the receipt of my booking
+//	@Description	Returns a link to a PDF receipt with the venue, time, price and online payment references. The PDF is generated on first request and regenerated when the booking or its payments change.
+//	@Tags			Bookings
+//	@Produce		json
+//	@Param			bookingID	path		string	true	"Booking ID (hash or numeric)"
+//	@Success		200			{object}	bookings.Receipt
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Booking not found"
+//	@Failure		409			{object}	error	"Booking is not confirmed"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{bookingID}/receipt [get]
+func (app *application) getBookingReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user := getUserFromContext(r)
+	booking, err := app.store.Bookings.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if booking.UserID != user.ID {
+		app.notFoundResponse(w, r, bookings.ErrNotFound)
+		return
+	}
+	if booking.Status != "confirmed" && booking.Status != "done" {
+		app.conflictResponse(w, r, fmt.Errorf("a %s booking has no receipt", booking.Status))
+		return
+	}
+
+	split, err := app.store.PaymentSplits.GetByBooking(ctx, booking.ID)
+	if err != nil && !errors.Is(err, paymentsplits.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	existing, err := app.store.Bookings.GetReceipt(ctx, booking.ID)
+	if err != nil && !errors.Is(err, bookings.ErrReceiptNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if existing != nil && !receiptStale(existing, booking, split) {
+		app.jsonResponse(w, http.StatusOK, existing)
+		return
+	}
+
+	rc, err := app.generateBookingReceipt(ctx, booking, user, split)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if existing != nil {
+		app.enqueuePhotoDelete(existing.URL)
+	}
+
+	app.jsonResponse(w, http.StatusOK, rc)
+}
+
+// receiptStale reports whether the booking or one of its payments changed
+// after the receipt was generated.
+func receiptStale(rc *bookings.Receipt, b *bookings.Booking, split *paymentsplits.Split) bool {
+	if b.UpdatedAt.After(rc.GeneratedAt) {
+		return true
+	}
+	if split == nil {
+		return false
+	}
+	for _, sh := range split.Shares {
+		if sh.PaidAt != nil && sh.PaidAt.After(rc.GeneratedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *application) generateBookingReceipt(ctx context.Context, b *bookings.Booking, user *users.User, split *paymentsplits.Split) (*bookings.Receipt, error) {
+	venue, err := app.store.Venues.GetVenueByID(ctx, b.VenueID)
+	if err != nil {
+		return nil, err
+	}
+	facility, err := app.store.Facilities.GetByID(ctx, b.VenueID, b.FacilityID)
+	if err != nil && !errors.Is(err, facilities.ErrFacilityNotFound) {
+		return nil, err
+	}
+
+	customer := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if b.CustomerName != nil && strings.TrimSpace(*b.CustomerName) != "" {
+		customer = strings.TrimSpace(*b.CustomerName)
+	}
+
+	doc := documents.Receipt{
+		Number:       "KHEL-" + app.EncodeBookingID(b.ID),
+		IssuedAt:     time.Now(),
+		CustomerName: customer,
+		VenueName:    venue.Name,
+		VenueAddress: venue.Address,
+		VenuePhone:   venue.PhoneNumber,
+		StartTime:    b.StartTime,
+		EndTime:      b.EndTime,
+		Total:        b.TotalPrice,
+	}
+	if facility != nil {
+		doc.FacilityName = facility.Name
+	}
+	if venue.Branding != nil {
+		doc.BrandName = venue.Branding.DisplayName
+		if venue.Branding.Color != nil {
+			doc.BrandColor = *venue.Branding.Color
+		}
+	}
+	if split != nil {
+		for _, sh := range split.Shares {
+			if sh.Status != paymentsplits.SharePaid || sh.Provider == nil {
+				continue
+			}
+			p := documents.ReceiptPayment{
+				Payer:    strings.TrimSpace(sh.FirstName + " " + sh.LastName),
+				Provider: *sh.Provider,
+				Amount:   sh.Amount,
+				PaidAt:   sh.PaidAt,
+			}
+			if sh.ProviderRef != nil {
+				p.Reference = *sh.ProviderRef
+			}
+			doc.Payments = append(doc.Payments, p)
+		}
+	}
+
+	folder := "testReceipts"
+	if env := os.Getenv("APP_ENV"); env == "prod" || env == "production" {
+		folder = "receipts"
+	}
+	publicID := fmt.Sprintf("booking_%d_receipt_%d", b.ID, time.Now().UnixNano())
+	url, err := app.uploadFileWithID(bytes.NewReader(documents.RenderReceipt(doc)), publicID, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &bookings.Receipt{BookingID: b.ID, URL: url}
+	if err := app.store.Bookings.SaveReceipt(ctx, rc); err != nil {
+		app.enqueuePhotoDelete(url)
+		return nil, err
+	}
+	return rc, nil
+}
@@ -0,0 +1,651 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/refunds"
+	"khel/internal/notifications"
+	"khel/internal/params"
+	"khel/internal/payments"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RequestRefundPayload struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
+
+type RefundPolicyPayload struct {
+	FullRefundHours   int `json:"full_refund_hours" validate:"gte=0,lte=720"`
+	LateRefundPercent int `json:"late_refund_percent" validate:"gte=0,lte=100"`
+}
+
+type RefundDecisionPayload struct {
+	Note *string `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+// requestRefundHandler godoc
+//
+//	@Summary		Request a refund for a canceled or rejected booking
+//	@Description	Refunds what was paid online for the booking according to the venue's refund policy: everything when it was canceled more than full_refund_hours before the start (24 by default), late_refund_percent otherwise (50 by default). Rejected bookings are always refunded in full. The refund waits for the venue owner or an admin to approve it.
+//	@Tags			Bookings
+//	@Accept			json
+//	@Produce		json
+//	@Param			bookingID	path		string					true	"Booking ID (hash or numeric)"
+//	@Param			payload		body		RequestRefundPayload	false	"Reason"
+//	@Success		201			{object}	refunds.Refund
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Booking not found"
+//	@Failure		409			{object}	error	"Booking not refundable or refund already requested"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{bookingID}/refund [post]
+func (app *application) requestRefundHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload RequestRefundPayload
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user := getUserFromContext(r)
+	booking, err := app.store.Bookings.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if booking.UserID != user.ID {
+		app.notFoundResponse(w, r, bookings.ErrNotFound)
+		return
+	}
+	if booking.Status != "canceled" && booking.Status != "rejected" {
+		app.conflictResponse(w, r, errors.New("only canceled or rejected bookings can be refunded"))
+		return
+	}
+
+	rf, err := app.fileBookingRefund(ctx, booking, user.ID, cleanOptionalString(payload.Reason))
+	if err != nil {
+		if errors.Is(err, errNothingPaidOnline) || errors.Is(err, refunds.ErrAlreadyRequested) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, rf)
+}
+
+var errNothingPaidOnline = errors.New("nothing was paid online for this booking")
+
+// fileBookingRefund opens a refund for what was paid online towards a
+// canceled booking, sized by the venue's refund policy. A rejected booking
+// never went ahead through no fault of the player, so it is refunded in full.
+func (app *application) fileBookingRefund(ctx context.Context, booking *bookings.Booking, requestedBy int64, reason *string) (*refunds.Refund, error) {
+	total, err := app.paidOnline(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status == "rejected" {
+		return app.createBookingRefund(ctx, booking, requestedBy, reason, total, 100)
+	}
+
+	policy, err := app.store.Refunds.GetPolicy(ctx, booking.VenueID)
+	if err != nil {
+		return nil, err
+	}
+	// updated_at moves with any later edit (a note, a check-in, a
+	// closure), so the policy tier comes from the cancel on the timeline.
+	canceledAt, err := app.store.Bookings.CanceledAt(ctx, booking.ID)
+	if err != nil {
+		return nil, fmt.Errorf("booking cancel time: %w", err)
+	}
+	return app.createBookingRefund(ctx, booking, requestedBy, reason, total, policy.Percent(canceledAt, booking.StartTime))
+}
+
+// refundRejectedBooking files a full refund, for the owner or an admin to
+// approve, of anything the player already paid online towards a booking
+// request that was rejected or expired. Bookings with nothing paid online,
+// or with a refund already filed, are left alone.
+func (app *application) refundRejectedBooking(ctx context.Context, booking *bookings.Booking, reason string) error {
+	total, err := app.paidOnline(ctx, booking.ID)
+	if err != nil {
+		if errors.Is(err, errNothingPaidOnline) {
+			return nil
+		}
+		return err
+	}
+	if _, err := app.createBookingRefund(ctx, booking, booking.UserID, &reason, total, 100); err != nil {
+		if errors.Is(err, refunds.ErrAlreadyRequested) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// paidOnline sums the online payments made towards a booking, failing with
+// errNothingPaidOnline when there are none.
+func (app *application) paidOnline(ctx context.Context, bookingID int64) (int, error) {
+	paid, err := app.store.Refunds.PaidPayments(ctx, bookingID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, p := range paid {
+		total += p.Amount
+	}
+	if total == 0 {
+		return 0, errNothingPaidOnline
+	}
+	return total, nil
+}
+
+// createBookingRefund opens a refund of percent of total, the amount paid
+// online.
+func (app *application) createBookingRefund(ctx context.Context, booking *bookings.Booking, requestedBy int64, reason *string, total, percent int) (*refunds.Refund, error) {
+	rf := &refunds.Refund{
+		BookingID:     booking.ID,
+		VenueID:       booking.VenueID,
+		RequestedBy:   requestedBy,
+		PaidAmount:    total,
+		RefundPercent: percent,
+		RefundAmount:  total * percent / 100,
+		Reason:        reason,
+	}
+	if err := app.store.Refunds.Create(ctx, rf); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// getBookingRefundHandler godoc
+//
+//	@Summary		Get the refund of my booking
+//	@Tags			Bookings
+//	@Produce		json
+//	@Param			bookingID	path		string	true	"Booking ID (hash or numeric)"
+//	@Success		200			{object}	refunds.Refund
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Refund not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{bookingID}/refund [get]
+func (app *application) getBookingRefundHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := app.parseBookingParam(chi.URLParam(r, "bookingID"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	rf, err := app.store.Refunds.GetByBooking(r.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, refunds.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if rf.RequestedBy != getUserFromContext(r).ID {
+		app.notFoundResponse(w, r, refunds.ErrNotFound)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, rf)
+}
+
+// getRefundPolicyHandler godoc
+//
+//	@Summary		Get my venue's refund policy
+//	@Description	Venues that never set one use the default: a full refund when canceled more than 24 hours ahead, 50% otherwise.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	refunds.Policy
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/refund-policy [get]
+func (app *application) getRefundPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	policy, err := app.store.Refunds.GetPolicy(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, policy)
+}
+
+// setRefundPolicyHandler godoc
+//
+//	@Summary		Set my venue's refund policy
+//	@Description	Applies to refunds requested from now on.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int					true	"Venue ID"
+//	@Param			payload	body		RefundPolicyPayload	true	"Policy"
+//	@Success		200		{object}	refunds.Policy
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/refund-policy [put]
+func (app *application) setRefundPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload RefundPolicyPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	policy := &refunds.Policy{
+		VenueID:           venueID,
+		FullRefundHours:   payload.FullRefundHours,
+		LateRefundPercent: payload.LateRefundPercent,
+	}
+	if err := app.store.Refunds.SetPolicy(r.Context(), policy); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, policy)
+}
+
+// listVenueRefundsHandler godoc
+//
+//	@Summary		List refunds on my venue's bookings
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			status	query		string			false	"pending, processing, rejected, refunded, manual or failed"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"refunds + pagination metadata"
+//	@Failure		400		{object}	error			"Bad Request"
+//	@Failure		403		{object}	error			"Forbidden"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/refunds [get]
+func (app *application) listVenueRefundsHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	app.listRefunds(w, r, refunds.Filter{VenueID: &venueID})
+}
+
+// approveVenueRefundHandler godoc
+//
+//	@Summary		Approve a refund on my venue's booking
+//	@Description	Refunds each online payment through its gateway. Gateways without a refund API (eSewa) are marked manual and have to be paid out by hand.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID		path		int						true	"Venue ID"
+//	@Param			refundID	path		int						true	"Refund ID"
+//	@Param			payload		body		RefundDecisionPayload	false	"Note"
+//	@Success		200			{object}	refunds.Refund
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Refund not found"
+//	@Failure		409			{object}	error	"Refund already decided"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/refunds/{refundID}/approve [post]
+func (app *application) approveVenueRefundHandler(w http.ResponseWriter, r *http.Request) {
+	rf, note, ok := app.loadVenueRefundDecision(w, r)
+	if !ok {
+		return
+	}
+	app.approveRefund(w, r, rf, note)
+}
+
+// rejectVenueRefundHandler godoc
+//
+//	@Summary		Reject a refund on my venue's booking
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID		path		int						true	"Venue ID"
+//	@Param			refundID	path		int						true	"Refund ID"
+//	@Param			payload		body		RefundDecisionPayload	false	"Note shown to the player"
+//	@Success		200			{object}	refunds.Refund
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Refund not found"
+//	@Failure		409			{object}	error	"Refund already decided"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/refunds/{refundID}/reject [post]
+func (app *application) rejectVenueRefundHandler(w http.ResponseWriter, r *http.Request) {
+	rf, note, ok := app.loadVenueRefundDecision(w, r)
+	if !ok {
+		return
+	}
+	app.rejectRefund(w, r, rf, note)
+}
+
+// adminListRefundsHandler godoc
+//
+//	@Summary		List refunds
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status	query		string			false	"pending, processing, rejected, refunded, manual or failed"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"refunds + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/refunds [get]
+func (app *application) adminListRefundsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listRefunds(w, r, refunds.Filter{})
+}
+
+// adminApproveRefundHandler godoc
+//
+//	@Summary		Approve a refund
+//	@Description	Same as the venue owner's approval, for refunds the owner has not acted on.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			refundID	path		int						true	"Refund ID"
+//	@Param			payload		body		RefundDecisionPayload	false	"Note"
+//	@Success		200			{object}	refunds.Refund
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Refund not found"
+//	@Failure		409			{object}	error	"Refund already decided"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/refunds/{refundID}/approve [post]
+func (app *application) adminApproveRefundHandler(w http.ResponseWriter, r *http.Request) {
+	rf, note, ok := app.loadRefundDecision(w, r)
+	if !ok {
+		return
+	}
+	if updated, ok := app.approveRefund(w, r, rf, note); ok {
+		app.recordAudit(r, audit.EntityRefund, audit.ActionApprove, rf.ID, rf, updated)
+	}
+}
+
+// adminRejectRefundHandler godoc
+//
+//	@Summary		Reject a refund
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			refundID	path		int						true	"Refund ID"
+//	@Param			payload		body		RefundDecisionPayload	false	"Note shown to the player"
+//	@Success		200			{object}	refunds.Refund
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Refund not found"
+//	@Failure		409			{object}	error	"Refund already decided"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/refunds/{refundID}/reject [post]
+func (app *application) adminRejectRefundHandler(w http.ResponseWriter, r *http.Request) {
+	rf, note, ok := app.loadRefundDecision(w, r)
+	if !ok {
+		return
+	}
+	if updated, ok := app.rejectRefund(w, r, rf, note); ok {
+		app.recordAudit(r, audit.EntityRefund, audit.ActionReject, rf.ID, rf, updated)
+	}
+}
+
+func (app *application) listRefunds(w http.ResponseWriter, r *http.Request, f refunds.Filter) {
+	q := r.URL.Query()
+	f.Status = strings.TrimSpace(q.Get("status"))
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Refunds.List(r.Context(), f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"refunds":    list,
+		"pagination": pagination,
+	})
+}
+
+// loadRefundDecision reads the refund in the URL and the optional decision
+// note.
+func (app *application) loadRefundDecision(w http.ResponseWriter, r *http.Request) (*refunds.Refund, *string, bool) {
+	id, err := readIDParam(r, "refundID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid refund ID"))
+		return nil, nil, false
+	}
+
+	var payload RefundDecisionPayload
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return nil, nil, false
+		}
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, nil, false
+	}
+
+	rf, err := app.store.Refunds.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, refunds.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, nil, false
+	}
+	return rf, cleanOptionalString(payload.Note), true
+}
+
+// loadVenueRefundDecision is loadRefundDecision for venue owners; refunds of
+// other venues are not found.
+func (app *application) loadVenueRefundDecision(w http.ResponseWriter, r *http.Request) (*refunds.Refund, *string, bool) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return nil, nil, false
+	}
+	rf, note, ok := app.loadRefundDecision(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	if rf.VenueID != venueID {
+		app.notFoundResponse(w, r, refunds.ErrNotFound)
+		return nil, nil, false
+	}
+	return rf, note, true
+}
+
+func (app *application) rejectRefund(w http.ResponseWriter, r *http.Request, rf *refunds.Refund, note *string) (*refunds.Refund, bool) {
+	ctx := r.Context()
+	user := getUserFromContext(r)
+	if err := app.store.Refunds.Reject(ctx, rf.ID, user.ID, note); err != nil {
+		app.refundDecisionError(w, r, err)
+		return nil, false
+	}
+
+	updated, err := app.store.Refunds.GetByID(ctx, rf.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	body := "Your refund request was declined."
+	if note != nil {
+		body = fmt.Sprintf("Your refund request was declined: %s", *note)
+	}
+	app.notifyRefund(updated, "Refund declined", body)
+
+	app.jsonResponse(w, http.StatusOK, updated)
+	return updated, true
+}
+
+func (app *application) approveRefund(w http.ResponseWriter, r *http.Request, rf *refunds.Refund, note *string) (*refunds.Refund, bool) {
+	ctx := r.Context()
+	user := getUserFromContext(r)
+	if err := app.store.Refunds.Approve(ctx, rf.ID, user.ID, note); err != nil {
+		app.refundDecisionError(w, r, err)
+		return nil, false
+	}
+
+	if err := app.payOutRefund(ctx, rf); err != nil {
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	updated, err := app.store.Refunds.GetByID(ctx, rf.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	body := fmt.Sprintf("Rs. %d is on its way back to you.", updated.RefundAmount)
+	if updated.Status == refunds.StatusFailed {
+		body = fmt.Sprintf("Your refund of Rs. %d was approved. We are sorting out a problem with the payment and will get it to you soon.", updated.RefundAmount)
+	}
+	app.notifyRefund(updated, "Refund approved", body)
+
+	app.jsonResponse(w, http.StatusOK, updated)
+	return updated, true
+}
+
+// payOutRefund splits the refund over the booking's online payments in
+// proportion to what each one paid and refunds them through their gateways.
+// It runs after Approve has committed, so a payout that cannot finish leaves
+// the refund failed for admins to pick up rather than stuck in processing.
+func (app *application) payOutRefund(ctx context.Context, rf *refunds.Refund) error {
+	// Money may already be moving; a client hanging up must not cut the
+	// payout short.
+	ctx = context.WithoutCancel(ctx)
+	err := app.refundPayments(ctx, rf)
+	if err == nil {
+		return nil
+	}
+	if ferr := app.store.Refunds.Complete(ctx, rf.ID, refunds.StatusFailed, nil); ferr != nil {
+		app.logger.Errorw("mark refund failed", "refund_id", rf.ID, "error", ferr)
+	}
+	return err
+}
+
+func (app *application) refundPayments(ctx context.Context, rf *refunds.Refund) error {
+	paid, err := app.store.Refunds.PaidPayments(ctx, rf.BookingID)
+	if err != nil {
+		return fmt.Errorf("refund payments: %w", err)
+	}
+
+	items := make([]refunds.Item, 0, len(paid))
+	remaining := rf.RefundAmount
+	failed, manual := false, false
+	for i, p := range paid {
+		amount := rf.RefundAmount * p.Amount / rf.PaidAmount
+		if i == len(paid)-1 {
+			amount = remaining
+		}
+		remaining -= amount
+
+		item := refunds.Item{
+			UserID:   p.UserID,
+			Amount:   amount,
+			Provider: p.Provider,
+			Status:   refunds.ItemRefunded,
+		}
+		if p.ShareID != 0 {
+			shareID := p.ShareID
+			item.ShareID = &shareID
+		}
+		if amount > 0 {
+			res, err := app.payments.RefundPayment(ctx, p.Provider, payments.RefundRequest{
+				TransactionID: p.ProviderRef,
+				Amount:        float64(amount),
+			})
+			switch {
+			case errors.Is(err, payments.ErrRefundNotSupported):
+				item.Status = refunds.ItemManual
+				manual = true
+			case err != nil:
+				app.logger.Errorw("refund payment failed", "refund_id", rf.ID, "share_id", p.ShareID, "error", err)
+				msg := err.Error()
+				item.Status = refunds.ItemFailed
+				item.Error = &msg
+				failed = true
+			default:
+				if res.ProviderRef != "" {
+					item.ProviderRef = &res.ProviderRef
+				}
+			}
+		}
+		items = append(items, item)
+	}
+
+	status := refunds.StatusRefunded
+	switch {
+	case failed:
+		status = refunds.StatusFailed
+	case manual:
+		status = refunds.StatusManual
+	}
+	return app.store.Refunds.Complete(ctx, rf.ID, status, items)
+}
+
+func (app *application) refundDecisionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, refunds.ErrNotFound):
+		app.notFoundResponse(w, r, err)
+	case errors.Is(err, refunds.ErrNotPending):
+		app.conflictResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) notifyRefund(rf *refunds.Refund, title, body string) {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendRefundUpdate(ctx, app.push, app.store, rf.RequestedBy, rf.ID, rf.BookingID, title, body)
+	}, "refund update push")
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/refunds"
+	"khel/internal/domain/storage"
+	"khel/internal/domain/users"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestFileBookingRefundPolicyTier(t *testing.T) {
+	const bookingID = int64(40)
+	start := time.Date(2026, 3, 14, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		canceledAt  time.Time
+		wantPercent int
+	}{
+		{"canceled exactly full_refund_hours before", start.Add(-24 * time.Hour), 100},
+		{"canceled a minute too late", start.Add(-24*time.Hour + time.Minute), 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &bookings.Booking{
+				ID: bookingID, VenueID: 2, UserID: 7, Status: "canceled", StartTime: start,
+				// Edited an hour before the start, long after the cancel;
+				// the tier must not come from this.
+				UpdatedAt: start.Add(-time.Hour),
+			}
+			rfs := &fakeRefunds{
+				policy: refunds.DefaultPolicy(2),
+				paid:   []refunds.Payment{{ShareID: 11, UserID: 7, Amount: 1000, Provider: "khalti"}},
+			}
+			app := newTestApplication(&storage.Container{
+				Bookings: &fakeBookings{canceledAt: map[int64]time.Time{bookingID: tt.canceledAt}},
+				Refunds:  rfs,
+			}, nil)
+
+			rf, err := app.fileBookingRefund(context.Background(), booking, 7, nil)
+			if err != nil {
+				t.Fatalf("fileBookingRefund: %v", err)
+			}
+			if rf.RefundPercent != tt.wantPercent || rf.RefundAmount != 1000*tt.wantPercent/100 {
+				t.Errorf("refund = %d%% (%d), want %d%%", rf.RefundPercent, rf.RefundAmount, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestPayOutRefundFailures(t *testing.T) {
+	rf := &refunds.Refund{ID: 5, BookingID: 40, PaidAmount: 1000, RefundAmount: 500, Status: refunds.StatusProcessing}
+	paid := []refunds.Payment{
+		{ShareID: 11, UserID: 7, Amount: 600, Provider: "khalti", ProviderRef: "pidx-1"},
+		{ShareID: 12, UserID: 8, Amount: 400, Provider: "khalti", ProviderRef: "pidx-2"},
+	}
+
+	tests := []struct {
+		name       string
+		paidErr    error
+		refundErr  error
+		wantErr    bool
+		wantStatus string
+		wantItems  []int // amounts
+	}{
+		{name: "gateway refunds both shares", wantStatus: refunds.StatusRefunded, wantItems: []int{300, 200}},
+		{name: "gateway fails", refundErr: errors.New("khalti down"), wantStatus: refunds.StatusFailed, wantItems: []int{300, 200}},
+		{name: "payments cannot be loaded", paidErr: errors.New("db down"), wantErr: true, wantStatus: refunds.StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rfs := &fakeRefunds{paid: paid, paidErr: tt.paidErr}
+			app := newTestApplication(&storage.Container{Refunds: rfs}, &fakePayments{refundErr: tt.refundErr})
+
+			err := app.payOutRefund(context.Background(), rf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("payOutRefund error = %v, want error %v", err, tt.wantErr)
+			}
+			got, ok := rfs.completed[rf.ID]
+			if !ok {
+				t.Fatalf("refund left %s, want %s", rf.Status, tt.wantStatus)
+			}
+			if got.status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.status, tt.wantStatus)
+			}
+			if len(got.items) != len(tt.wantItems) {
+				t.Fatalf("items = %+v, want amounts %v", got.items, tt.wantItems)
+			}
+			for i, it := range got.items {
+				if it.Amount != tt.wantItems[i] {
+					t.Errorf("item %d amount = %d, want %d", i, it.Amount, tt.wantItems[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApproveVenueRefundHandler(t *testing.T) {
+	const (
+		ownerID  = int64(2)
+		playerID = int64(7)
+		venueID  = int64(9)
+		refundID = int64(5)
+	)
+
+	tests := []struct {
+		name       string
+		venueID    int64 // venue the refund belongs to, defaults to venueID
+		status     string
+		refundErr  error
+		wantStatus int
+		wantRefund string // status after the decision; empty when not approved
+		wantPush   string // body prefix
+	}{
+		{
+			name:       "gateway pays the player back",
+			status:     refunds.StatusPending,
+			wantStatus: http.StatusOK,
+			wantRefund: refunds.StatusRefunded,
+			wantPush:   "Rs. 500 is on its way",
+		},
+		{
+			name:       "gateway refund fails",
+			status:     refunds.StatusPending,
+			refundErr:  errors.New("khalti down"),
+			wantStatus: http.StatusOK,
+			wantRefund: refunds.StatusFailed,
+			wantPush:   "Your refund of Rs. 500 was approved",
+		},
+		{
+			name:       "another venue's refund",
+			venueID:    venueID + 1,
+			status:     refunds.StatusPending,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "already decided",
+			status:     refunds.StatusRejected,
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rfVenue := venueID
+			if tt.venueID != 0 {
+				rfVenue = tt.venueID
+			}
+			rfs := &fakeRefunds{
+				byID: map[int64]*refunds.Refund{refundID: {
+					ID: refundID, BookingID: 40, VenueID: rfVenue, RequestedBy: playerID,
+					PaidAmount: 1000, RefundPercent: 50, RefundAmount: 500, Status: tt.status,
+				}},
+				paid: []refunds.Payment{{ShareID: 11, UserID: playerID, Amount: 1000, Provider: "khalti", ProviderRef: "pidx-1"}},
+			}
+			inbox := &fakeInbox{}
+			pay := &fakePayments{refundErr: tt.refundErr}
+			push := newFakePush()
+			app := newTestApplication(&storage.Container{
+				Refunds:           rfs,
+				Inbox:             inbox,
+				NotificationPrefs: fakeNotificationPrefs{},
+				PushTokens:        &fakePushTokens{tokens: map[int64][]string{playerID: {"ExponentPushToken[player]"}}},
+			}, pay)
+			app.push = push
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/venues/9/refunds/5/approve", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("venueID", "9")
+			rctx.URLParams.Add("refundID", "5")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = withUser(ctx, &users.User{ID: ownerID})
+			rec := httptest.NewRecorder()
+
+			app.approveVenueRefundHandler(rec, req.WithContext(ctx))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantRefund == "" {
+				if len(pay.refunded) != 0 {
+					t.Errorf("gateway refunds = %+v, want none", pay.refunded)
+				}
+				if got := rfs.byID[refundID].Status; got != tt.status {
+					t.Errorf("refund status = %s, want it left %s", got, tt.status)
+				}
+				return
+			}
+
+			if len(pay.refunded) != 1 || pay.refunded[0].TransactionID != "pidx-1" || pay.refunded[0].Amount != 500 {
+				t.Errorf("gateway refunds = %+v, want 500 on pidx-1", pay.refunded)
+			}
+			if got := rfs.byID[refundID].Status; got != tt.wantRefund {
+				t.Errorf("refund status = %s, want %s", got, tt.wantRefund)
+			}
+
+			select {
+			case msg := <-push.sent:
+				if len(msg.To) != 1 || *msg.To[0] != "ExponentPushToken[player]" {
+					t.Errorf("push went to %v, want the player's token", msg.To)
+				}
+				if msg.Title != "Refund approved" || !strings.HasPrefix(msg.Body, tt.wantPush) {
+					t.Errorf("push = %q / %q, want Refund approved / %q...", msg.Title, msg.Body, tt.wantPush)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no push sent to the player")
+			}
+			inbox.mu.Lock()
+			defer inbox.mu.Unlock()
+			if len(inbox.created) != 1 {
+				t.Errorf("inbox entries = %v, want one", inbox.created)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"khel/internal/domain/checkins"
+	"khel/internal/domain/games"
+	"net/http"
+)
+
+// JoinRequestResponse is a join request with the player's reliability, so
+// game admins can tell who tends to turn up.
+type JoinRequestResponse struct {
+	*games.GameRequestWithUser
+	Reliability checkins.Reliability `json:"reliability"`
+}
+
+// getMyReliabilityHandler godoc
+//
+//	@Summary		Get my reliability score
+//	@Description	How often you turned up for games you joined and bookings you made over the last 180 days, going by venue check-ins. Only venues that check players in count. The score stays null until there are 3 games or bookings to go on.
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	checkins.Reliability
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		500	{object}	error	"Internal server error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/reliability [get]
+func (app *application) getMyReliabilityHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	scores, err := app.store.CheckIns.Reliability(r.Context(), []int64{user.ID})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, scores[user.ID])
+}
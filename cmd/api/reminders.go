@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/reminders"
+	"khel/internal/jobs"
+	"khel/internal/mailer"
+	"khel/internal/notifications"
+	"time"
+)
+
+// reminderBatchSize caps how many reminders one run claims per lead; the
+// rest are picked up on the next run a minute later.
+const reminderBatchSize = 500
+
+// runSendStartReminders pushes the 2h and 30m reminders for confirmed
+// bookings and active games. Each lead only claims what starts after the
+// next shorter lead, so a booking made 20 minutes before kick-off gets one
+// reminder rather than both.
+func (app *application) runSendStartReminders(ctx context.Context) error {
+	for i, lead := range reminders.Leads {
+		var floor time.Duration
+		if i+1 < len(reminders.Leads) {
+			floor = reminders.Leads[i+1]
+		}
+
+		due, err := app.store.Reminders.ClaimDue(ctx, lead, floor, reminderBatchSize)
+		if err != nil {
+			return err
+		}
+
+		// One push batch per booking/game rather than per player.
+		type subject struct {
+			typ string
+			id  int64
+		}
+		groups := map[subject][]reminders.Due{}
+		order := []subject{}
+		for _, d := range due {
+			key := subject{d.SubjectType, d.SubjectID}
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+			groups[key] = append(groups[key], d)
+		}
+
+		for _, key := range order {
+			group := groups[key]
+			userIDs := make([]int64, 0, len(group))
+			for _, d := range group {
+				userIDs = append(userIDs, d.UserID)
+			}
+			if err := notifications.SendStartReminder(ctx, app.push, app.store, userIDs, group[0], lead); err != nil {
+				app.logger.Warnw("failed to push start reminder", "subject", key.typ, "subject_id", key.id, "lead", lead, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// runSendReviewInvites asks players to review the venue two hours after a
+// booking the venue closed as played.
+func (app *application) runSendReviewInvites(ctx context.Context) error {
+	invites, err := app.store.Reminders.ClaimReviewInvites(ctx, reminderBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, inv := range invites {
+		if err := notifications.SendReviewInvite(ctx, app.push, app.store, inv); err != nil {
+			app.logger.Warnw("failed to push review invite", "booking_id", inv.BookingID, "error", err)
+		}
+		app.emailReviewInvite(ctx, inv)
+	}
+	jobs.SetRowsAffected(ctx, int64(len(invites)))
+	return nil
+}
+
+// emailReviewInvite follows the booking updates email setting, since
+// reminders have no email channel.
+func (app *application) emailReviewInvite(ctx context.Context, inv reminders.ReviewInvite) {
+	prefs, err := app.store.NotificationPrefs.Get(ctx, inv.UserID)
+	if err != nil {
+		app.logger.Errorw("failed to load notification preferences for review invite", "booking_id", inv.BookingID, "error", err)
+		return
+	}
+	if !prefs.Allows(notificationprefs.CategoryBookingUpdates, notificationprefs.ChannelEmail) {
+		return
+	}
+	user, err := app.store.Users.GetByID(ctx, inv.UserID)
+	if err != nil {
+		app.logger.Errorw("failed to load user for review invite", "booking_id", inv.BookingID, "error", err)
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		loc = time.FixedZone("NPT", 5*60*60+45*60)
+	}
+	data := struct {
+		Username  string
+		VenueName string
+		Date      string
+		ReviewURL string
+	}{
+		Username:  user.FirstName,
+		VenueName: inv.VenueName,
+		Date:      inv.EndTime.In(loc).Format("Mon, 2 Jan 2006"),
+		ReviewURL: fmt.Sprintf("%s/venues/%d/review", app.config.frontendURL, inv.VenueID),
+	}
+	if err := app.enqueueEmail(ctx, mailer.ReviewInviteTemplate, prefs.Locale, user.FirstName, user.Email, data); err != nil {
+		app.logger.Errorw("failed to enqueue review invite email", "booking_id", inv.BookingID, "error", err)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"khel/internal/domain/users"
+	"khel/internal/tracing"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+const (
+	requestIDHeader   = "X-Request-ID"
+	traceIDHeader     = "X-Trace-ID"
+	traceparentHeader = "traceparent"
+)
+
+const requestMetaCtx userKey = "request_meta"
+
+// requestMeta travels with the request so the access log written after the
+// handler returns can see who the auth middleware resolved.
+type requestMeta struct {
+	requestID string
+	traceID   string
+	userID    int64
+}
+
+// requestLoggingMiddleware tags every request with a request ID and the
+// trace ID of its span, echoes both in the response headers and writes one
+// access log line when the request finishes. A caller-supplied X-Request-ID
+// is kept, as is the trace of a W3C traceparent (see tracingMiddleware), so
+// IDs line up with the client's and proxy's logs.
+func (app *application) requestLoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		meta := &requestMeta{
+			requestID: incomingID(r.Header.Get(requestIDHeader)),
+			traceID:   tracing.TraceID(r.Context()),
+		}
+		if meta.requestID == "" {
+			meta.requestID = tracing.NewID(8)
+		}
+		if meta.traceID == "" {
+			meta.traceID = tracing.NewID(16)
+		}
+
+		w.Header().Set(requestIDHeader, meta.requestID)
+		w.Header().Set(traceIDHeader, meta.traceID)
+
+		ctx := context.WithValue(r.Context(), requestMetaCtx, meta)
+		// Keep chi's accessor working for anything that reads it.
+		ctx = context.WithValue(ctx, middleware.RequestIDKey, meta.requestID)
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			fields := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"bytes", ww.BytesWritten(),
+				"ip", r.RemoteAddr,
+				"request_id", meta.requestID,
+				"trace_id", meta.traceID,
+			}
+			if meta.userID != 0 {
+				fields = append(fields, "user_id", meta.userID)
+			}
+
+			switch {
+			case status >= 500:
+				app.logger.Errorw("request", fields...)
+			case status >= 400:
+				app.logger.Warnw("request", fields...)
+			default:
+				app.logger.Infow("request", fields...)
+			}
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
+
+// withUser stores the authenticated user on ctx and records their ID for the
+// access log.
+func withUser(ctx context.Context, user *users.User) context.Context {
+	if meta, ok := ctx.Value(requestMetaCtx).(*requestMeta); ok {
+		meta.userID = user.ID
+	}
+	return context.WithValue(ctx, userCtx, user)
+}
+
+// requestLogger returns the app logger tagged with the request's IDs (and
+// user, once known). Handlers log through it so their lines can be joined
+// with the access log.
+func (app *application) requestLogger(r *http.Request) *zap.SugaredLogger {
+	meta, ok := r.Context().Value(requestMetaCtx).(*requestMeta)
+	if !ok {
+		return app.logger
+	}
+	l := app.logger.With("request_id", meta.requestID, "trace_id", meta.traceID)
+	if meta.userID != 0 {
+		l = l.With("user_id", meta.userID)
+	}
+	return l
+}
+
+// incomingID accepts a client request ID only if it is short and plain, so
+// it can't be used to inject anything into the logs.
+func incomingID(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" || len(v) > 64 {
+		return ""
+	}
+	for _, c := range v {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return ""
+		}
+	}
+	return v
+}
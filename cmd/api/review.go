@@ -4,11 +4,15 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/moderationqueue"
 	venuereviews "khel/internal/domain/venuereview"
+	"khel/internal/params"
 
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -22,7 +26,7 @@ type createReviewPayload struct {
 // CreateVenueReview godoc
 //
 //	@Summary		Create a review for a venue
-//	@Description	Creates a new review for a specific venue. The review includes a rating and comment.
+//	@Description	Creates a new review for a specific venue. The review includes a rating and comment. A user can review a venue once every 90 days; a later review replaces the earlier one. status is "held" when the review waits for moderation.
 //	@Tags			Venue
 //	@Accept			json
 //	@Produce		json
@@ -30,13 +34,13 @@ type createReviewPayload struct {
 //	@Param			payload	body		createReviewPayload	true	"Review payload"
 //	@Success		201		{object}	venuereviews.Review	"Review created successfully"
 //	@Failure		400		{object}	error				"Bad Request: Invalid input"
+//	@Failure		409		{object}	error				"Reviewed this venue in the last 90 days"
 //	@Failure		500		{object}	error				"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/venues/{venueID}/reviews [post]
 func (app *application) createVenueReviewHandler(w http.ResponseWriter, r *http.Request) {
 	venueID := chi.URLParam(r, "venueID")
 	vID, err := strconv.ParseInt(venueID, 10, 64)
-	fmt.Printf("I got this venue id on review %v", vID)
 	if err != nil {
 		app.badRequestResponse(w, r, errors.New("invalid venue ID"))
 		return
@@ -47,23 +51,14 @@ func (app *application) createVenueReviewHandler(w http.ResponseWriter, r *http.
 		app.badRequestResponse(w, r, err)
 		return
 	}
-
-	fmt.Printf("the parsed payload rating is: %d and comment is: %s", payload.Rating, payload.Comment)
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
 
 	user := getUserFromContext(r)
 	userID := user.ID
 
-	already, err := app.store.VenuesReviews.HasReview(r.Context(), vID, userID)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-	if already {
-		// 409 Conflict is the usual HTTP response when the resource already exists
-		app.conflictResponse(w, r, errors.New("you have already reviewed this venue. delete previous one to review again"))
-		return
-	}
-
 	review := &venuereviews.Review{
 		VenueID: vID,
 		UserID:  userID,
@@ -71,11 +66,27 @@ func (app *application) createVenueReviewHandler(w http.ResponseWriter, r *http.
 		Comment: payload.Comment,
 	}
 
+	verdict := app.screenReview(r.Context(), review.Comment)
+	if verdict.Flagged {
+		reason := "flagged by moderation: " + verdict.Reason
+		review.HoldReason = &reason
+	}
+
 	if err := app.store.VenuesReviews.CreateReview(r.Context(), review); err != nil {
+		if errors.Is(err, venuereviews.ErrReviewCooldown) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
 
+	if verdict.Flagged {
+		if err := app.flagContent(r.Context(), moderationqueue.ContentReview, review.ID, userID, review.Comment, verdict); err != nil {
+			app.logger.Errorw("failed to queue flagged review", "review_id", review.ID, "error", err)
+		}
+	}
+
 	app.jsonResponse(w, http.StatusCreated, review)
 }
 
@@ -158,3 +169,104 @@ func (app *application) deleteVenueReviewHandler(w http.ResponseWriter, r *http.
 
 	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "review deleted"})
 }
+
+// adminListReviewsHandler godoc
+//
+//	@Summary		List reviews for moderation
+//	@Description	Reviews newest first with the reviewer's account age. Defaults to reviews held for moderation; status=all lists every review.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status	query		string			false	"held (default), published, rejected, replaced, deleted or all"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"reviews + pagination metadata"
+//	@Failure		400		{object}	error			"Bad Request"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/reviews [get]
+func (app *application) adminListReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := strings.TrimSpace(q.Get("status"))
+	switch status {
+	case "":
+		status = venuereviews.StatusHeld
+	case "all":
+		status = ""
+	case venuereviews.StatusPublished, venuereviews.StatusHeld, venuereviews.StatusRejected,
+		venuereviews.StatusReplaced, venuereviews.StatusDeleted:
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("invalid status"))
+		return
+	}
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.VenuesReviews.ListForModeration(r.Context(), status, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"reviews":    list,
+		"pagination": pagination,
+	})
+}
+
+// adminApproveReviewHandler godoc
+//
+//	@Summary		Publish a held review
+//	@Tags			Admin
+//	@Param			reviewID	path		int		true	"Review ID"
+//	@Success		204			{string}	string	"Published"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Review not found"
+//	@Failure		409			{object}	error	"Review is not held"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/reviews/{reviewID}/approve [post]
+func (app *application) adminApproveReviewHandler(w http.ResponseWriter, r *http.Request) {
+	app.moderateReview(w, r, venuereviews.StatusPublished, audit.ActionApprove)
+}
+
+// adminRejectReviewHandler godoc
+//
+//	@Summary		Reject a held review
+//	@Description	The review stays hidden. It still counts towards the reviewer's 90-day limit for the venue.
+//	@Tags			Admin
+//	@Param			reviewID	path		int		true	"Review ID"
+//	@Success		204			{string}	string	"Rejected"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Review not found"
+//	@Failure		409			{object}	error	"Review is not held"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/reviews/{reviewID}/reject [post]
+func (app *application) adminRejectReviewHandler(w http.ResponseWriter, r *http.Request) {
+	app.moderateReview(w, r, venuereviews.StatusRejected, audit.ActionReject)
+}
+
+func (app *application) moderateReview(w http.ResponseWriter, r *http.Request, status, action string) {
+	id, err := readIDParam(r, "reviewID")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid review ID"))
+		return
+	}
+
+	if err := app.store.VenuesReviews.Moderate(r.Context(), id, getUserFromContext(r).ID, status); err != nil {
+		switch {
+		case errors.Is(err, venuereviews.ErrReviewNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, venuereviews.ErrReviewNotHeld):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.recordAudit(r, audit.EntityVenueReview, action, id,
+		map[string]string{"status": venuereviews.StatusHeld},
+		map[string]string{"status": status})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/safety"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ReportUserPayload struct {
+	Reason  string `json:"reason" validate:"required,oneof=harassment abusive_language cheating no_show fake_profile other"`
+	Details string `json:"details" validate:"max=2000"`
+	// GameID is the game it happened in, if any.
+	GameID *int64 `json:"game_id,omitempty" validate:"omitempty,gt=0"`
+	// Block also blocks the player.
+	Block bool `json:"block"`
+}
+
+type ResolveReportPayload struct {
+	Status string  `json:"status" validate:"required,oneof=actioned dismissed"`
+	Note   *string `json:"note,omitempty" validate:"omitempty,max=2000"`
+}
+
+// blockUserHandler godoc
+//
+//	@Summary		Block a player
+//	@Description	Hides the two of you from each other: neither sees the other's games or join requests, and neither can join or be invited to the other's games. Any friendship between you ends, neither keeps following the other, and pending join requests between you are rejected. The other player isn't told.
+//	@Tags			Users
+//	@Param			userID	path		int		true	"User ID"
+//	@Success		204		{string}	string	"Blocked"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"User not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/block [put]
+func (app *application) blockUserHandler(w http.ResponseWriter, r *http.Request) {
+	blockedID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	if err := app.store.Safety.Block(r.Context(), getUserFromContext(r).ID, blockedID); err != nil {
+		switch {
+		case errors.Is(err, safety.ErrSelfBlock):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, safety.ErrUserNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unblockUserHandler godoc
+//
+//	@Summary		Unblock a player
+//	@Tags			Users
+//	@Param			userID	path		int		true	"User ID"
+//	@Success		204		{string}	string	"Unblocked"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"User is not blocked"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/block [delete]
+func (app *application) unblockUserHandler(w http.ResponseWriter, r *http.Request) {
+	blockedID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	if err := app.store.Safety.Unblock(r.Context(), getUserFromContext(r).ID, blockedID); err != nil {
+		if errors.Is(err, safety.ErrNotBlocked) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listBlockedUsersHandler godoc
+//
+//	@Summary		List the players I've blocked
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{array}		safety.BlockedUser
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/blocked [get]
+func (app *application) listBlockedUsersHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := app.store.Safety.ListBlocked(r.Context(), getUserFromContext(r).ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// reportUserHandler godoc
+//
+//	@Summary		Report a player
+//	@Description	Files a report for the admins to review. Set block to also block the player.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int					true	"User ID"
+//	@Param			payload	body		ReportUserPayload	true	"Report"
+//	@Success		201		{object}	safety.Report
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"User not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/report [post]
+func (app *application) reportUserHandler(w http.ResponseWriter, r *http.Request) {
+	reportedID, err := readIDParam(r, "userID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	var payload ReportUserPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	rep := &safety.Report{
+		ReporterID: &user.ID,
+		ReportedID: reportedID,
+		GameID:     payload.GameID,
+		Reason:     payload.Reason,
+		Details:    strings.TrimSpace(payload.Details),
+	}
+	if err := app.store.Safety.Report(r.Context(), rep); err != nil {
+		switch {
+		case errors.Is(err, safety.ErrSelfReport):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, safety.ErrUserNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if payload.Block {
+		if err := app.store.Safety.Block(r.Context(), user.ID, reportedID); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	app.jsonResponse(w, http.StatusCreated, rep)
+}
+
+// adminListUserReportsHandler godoc
+//
+//	@Summary		List player reports
+//	@Description	Newest first. open_reports on each counts the open reports against the same player.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status		query		string			false	"open, actioned or dismissed"
+//	@Param			reported_id	query		int				false	"Only reports against this user"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"reports + pagination metadata"
+//	@Failure		400			{object}	error			"Bad Request"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/reports [get]
+func (app *application) adminListUserReportsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := safety.ReportFilter{Status: strings.TrimSpace(q.Get("status"))}
+	if v := q.Get("reported_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid reported_id"))
+			return
+		}
+		f.ReportedID = &id
+	}
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Safety.ListReports(r.Context(), f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"reports":    list,
+		"pagination": pagination,
+	})
+}
+
+// adminResolveUserReportHandler godoc
+//
+//	@Summary		Resolve a player report
+//	@Description	actioned records that the admins acted on the report; dismissed closes it without action.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			reportID	path		int						true	"Report ID"
+//	@Param			payload		body		ResolveReportPayload	true	"Resolution"
+//	@Success		200			{object}	safety.Report
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Report not found"
+//	@Failure		409			{object}	error	"Report is already resolved"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/reports/{reportID}/resolve [post]
+func (app *application) adminResolveUserReportHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "reportID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid report ID"))
+		return
+	}
+
+	var payload ResolveReportPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	rep, err := app.store.Safety.ResolveReport(r.Context(), id, payload.Status, payload.Note, getUserFromContext(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, safety.ErrReportNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, safety.ErrAlreadyResolved):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityUserReport, audit.ActionResolve, rep.ID, nil, rep)
+
+	app.jsonResponse(w, http.StatusOK, rep)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"khel/internal/domain/orders"
 	"khel/internal/domain/paymentsrepo"
 	"khel/internal/domain/storage"
+	"khel/internal/notifications"
 	"khel/internal/params"
 	"khel/internal/payments"
 
@@ -346,6 +348,154 @@ func (app *application) getMyOrderHandler(w http.ResponseWriter, r *http.Request
 	app.jsonResponse(w, http.StatusOK, detail)
 }
 
+type CancelMyOrderPayload struct {
+	Reason *string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// CancelMyOrder godoc
+//
+//	@Summary		Cancel my order
+//	@Description	Cancels an order that has not shipped, within the store's cancellation window after checkout (24 hours unless configured otherwise). A paid online order is refunded through its gateway; where the gateway has no refund API the refund is paid out by hand. The store's merchants are notified.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderID	path		int						true	"Order ID"	minimum(1)
+//	@Param			payload	body		CancelMyOrderPayload	false	"Why the order is cancelled"
+//	@Success		200		{object}	orders.Order			"cancelled order"
+//	@Failure		400		{object}	error					"Bad Request: invalid orderID"
+//	@Failure		404		{object}	error					"Order not found"
+//	@Failure		409		{object}	error					"Order already shipped or cancellation window closed"
+//	@Failure		500		{object}	error					"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/orders/{orderID}/cancel [post]
+func (app *application) cancelMyOrderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	user := getUserFromContext(r)
+
+	idStr := chi.URLParam(r, "orderID")
+	orderID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || orderID <= 0 {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid orderID"))
+		return
+	}
+
+	var payload CancelMyOrderPayload
+	if r.ContentLength > 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		if err := Validate.Struct(payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	reason := cleanOptionalString(payload.Reason)
+	if reason == nil {
+		byCustomer := "Cancelled by customer"
+		reason = &byCustomer
+	}
+
+	// Cancel, reopen a cart still locked for payment and refund the payment
+	// as one unit of work: if the gateway refund fails the order stays as it
+	// was and the buyer can try again.
+	var order *orders.Order
+	manualRefund := false
+	err = app.store.WithSalesTx(ctx, func(s *storage.SalesTx) error {
+		var err error
+		order, err = s.Orders.CancelByUser(ctx, user.ID, orderID, app.config.store.orderCancelWindow, reason)
+		if err != nil {
+			return err
+		}
+		if err := s.Carts.UnlockCheckoutCart(ctx, order.ID); err != nil {
+			return fmt.Errorf("unlock checkout cart: %w", err)
+		}
+
+		pays, err := s.Payments.GetByOrderID(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		for _, p := range pays {
+			switch p.Status {
+			case "pending":
+				// A late webhook for this payment must not revive the order.
+				if err := s.Payments.SetStatus(ctx, p.ID, "failed"); err != nil {
+					return err
+				}
+			case "paid":
+				ref := ""
+				if p.ProviderRef != nil {
+					ref = *p.ProviderRef
+				}
+				res, gerr := app.payments.RefundPayment(ctx, p.Provider, payments.RefundRequest{
+					TransactionID: ref,
+					Amount:        float64(p.AmountCents) / 100.0,
+				})
+				if errors.Is(gerr, payments.ErrRefundNotSupported) {
+					manualRefund = true
+					if err := s.PayLogs.InsertPaymentLog(ctx, p.ID, "error", map[string]any{
+						"stage": "refund",
+						"error": "gateway has no refund API; refund manually",
+					}); err != nil {
+						return err
+					}
+					continue
+				}
+				if gerr != nil {
+					_ = app.store.Sales.PayLogs.InsertPaymentLog(ctx, p.ID, "error", map[string]any{
+						"stage": "refund",
+						"error": gerr.Error(),
+					})
+					return fmt.Errorf("refund payment: %w", gerr)
+				}
+				if err := s.PayLogs.InsertPaymentLog(ctx, p.ID, "response", res); err != nil {
+					return err
+				}
+				if err := s.Payments.SetStatus(ctx, p.ID, "refunded"); err != nil {
+					return err
+				}
+			}
+		}
+
+		if order.PaymentStatus == "paid" && !manualRefund &&
+			order.PaymentMethod != nil && *order.PaymentMethod != "cash_on_delivery" {
+			if err := s.Orders.MarkRefunded(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrOrderNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, orders.ErrNotCancellable), errors.Is(err, orders.ErrCancelWindowClosed):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	updated, err := app.store.Sales.Orders.GetByID(ctx, order.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	body := fmt.Sprintf("The customer cancelled it: %s", *reason)
+	if manualRefund {
+		body += fmt.Sprintf(" Rs. %.2f was paid online and must be refunded by hand.", float64(order.TotalCents)/100.0)
+	}
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendOrderCancelledToMerchants(ctx, app.push, app.store, order.ID, order.OrderNumber, body)
+	}, "order cancelled push")
+
+	app.jsonResponse(w, http.StatusOK, updated)
+}
+
 // POST /v1/store/payments/webhook
 //
 // Webhook responsibilities:
@@ -435,7 +585,7 @@ func (app *application) paymentWebhookHandler(w http.ResponseWriter, r *http.Req
 	if err != nil {
 		app.logger.Errorw("verify payment failed", "provider", provider, "ref", providerRef, "err", err)
 		// Return 5xx so gateway retries (typical webhook behavior)
-		http.Error(w, "verification error", http.StatusInternalServerError)
+		writeJSONError(w, r, http.StatusInternalServerError, codeInternal, "verification error", nil)
 		return
 	}
 
@@ -443,7 +593,7 @@ func (app *application) paymentWebhookHandler(w http.ResponseWriter, r *http.Req
 	pay, err := app.store.Sales.Payments.GetByProviderRef(ctx, provider, providerRef)
 	if err != nil {
 		app.logger.Errorw("get payment by provider_ref failed", "provider", provider, "ref", providerRef, "err", err)
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeJSONError(w, r, http.StatusInternalServerError, codeInternal, "internal error", nil)
 		return
 	}
 
@@ -512,7 +662,7 @@ func (app *application) paymentWebhookHandler(w http.ResponseWriter, r *http.Req
 		return nil
 	}); err != nil {
 		app.logger.Errorw("paid transition failed", "payment_id", pay.ID, "provider", provider, "ref", providerRef, "err", err)
-		http.Error(w, "failed to update payment", http.StatusInternalServerError)
+		writeJSONError(w, r, http.StatusInternalServerError, codeInternal, "failed to update payment", nil)
 		return
 	}
 
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/savedsearches"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type matchSavedSearchesPayload struct {
+	GameID int64 `json:"game_id"`
+}
+
+// SavedSearchPayload is used for both create and update; an update replaces
+// every criterion.
+type SavedSearchPayload struct {
+	Name      string   `json:"name" validate:"required,max=60"`
+	SportType *string  `json:"sport_type" validate:"omitempty,oneof=futsal basketball badminton e-sport cricket tennis"`
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude RadiusKm,omitempty,latitude"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude RadiusKm,omitempty,longitude"`
+	RadiusKm  *int     `json:"radius_km" validate:"required_with=Latitude Longitude,omitempty,min=1,max=100"`
+	MinPrice  *int     `json:"min_price" validate:"omitempty,min=0"`
+	MaxPrice  *int     `json:"max_price" validate:"omitempty,min=0"`
+	StartFrom *string  `json:"start_from" validate:"required_with=StartTo"`
+	StartTo   *string  `json:"start_to" validate:"required_with=StartFrom"`
+	Notify    *bool    `json:"notify"`
+}
+
+// toSearch validates the payload beyond struct tags and builds the search.
+func (p SavedSearchPayload) toSearch(userID int64) (*savedsearches.Search, error) {
+	s := &savedsearches.Search{
+		UserID:    userID,
+		Name:      strings.TrimSpace(p.Name),
+		SportType: p.SportType,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		RadiusKm:  p.RadiusKm,
+		MinPrice:  p.MinPrice,
+		MaxPrice:  p.MaxPrice,
+		Notify:    true,
+	}
+	if s.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if p.MinPrice != nil && p.MaxPrice != nil && *p.MinPrice > *p.MaxPrice {
+		return nil, errors.New("min_price cannot be greater than max_price")
+	}
+	if p.StartFrom != nil {
+		from, err := time.Parse("15:04", *p.StartFrom)
+		if err != nil {
+			return nil, errors.New("invalid start_from, use HH:mm")
+		}
+		to, err := time.Parse("15:04", *p.StartTo)
+		if err != nil {
+			return nil, errors.New("invalid start_to, use HH:mm")
+		}
+		fromStr, toStr := from.Format("15:04:05"), to.Format("15:04:05")
+		s.StartFrom, s.StartTo = &fromStr, &toStr
+	}
+	if p.Notify != nil {
+		s.Notify = *p.Notify
+	}
+	return s, nil
+}
+
+// createSavedSearchHandler godoc
+//
+//	@Summary		Save a game search
+//	@Description	Saves a game filter and pushes the user when a new public game matches it. Every criterion is optional: sport, a radius around a point, a price range and a start-time window (local Nepal time as HH:mm, may wrap past midnight). A game is pushed once even if several searches match. Up to 10 searches per user.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		SavedSearchPayload	true	"Filter"
+//	@Success		201		{object}	savedsearches.Search
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		409		{object}	error	"Limit reached"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/saved-searches [post]
+func (app *application) createSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	var payload SavedSearchPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	search, err := payload.toSearch(user.ID)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.SavedSearches.Create(r.Context(), search); err != nil {
+		if errors.Is(err, savedsearches.ErrLimitExceeded) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, search)
+}
+
+// listSavedSearchesHandler godoc
+//
+//	@Summary		List my saved searches
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{array}		savedsearches.Search
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/saved-searches [get]
+func (app *application) listSavedSearchesHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	list, err := app.store.SavedSearches.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// updateSavedSearchHandler godoc
+//
+//	@Summary		Update a saved search
+//	@Description	Replaces the search's name and criteria; omitted criteria are cleared. Games already pushed for this search are not pushed again.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Param			searchID	path		int					true	"Saved search ID"
+//	@Param			payload		body		SavedSearchPayload	true	"Filter"
+//	@Success		200			{object}	savedsearches.Search
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Saved search not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/saved-searches/{searchID} [put]
+func (app *application) updateSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	searchID, err := readIDParam(r, "searchID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid saved search ID"))
+		return
+	}
+
+	var payload SavedSearchPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	search, err := payload.toSearch(user.ID)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	search.ID = searchID
+
+	if err := app.store.SavedSearches.Update(r.Context(), search); err != nil {
+		if errors.Is(err, savedsearches.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, search)
+}
+
+// deleteSavedSearchHandler godoc
+//
+//	@Summary		Delete a saved search
+//	@Tags			Users
+//	@Param			searchID	path	int	true	"Saved search ID"
+//	@Success		204			"No Content"
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Saved search not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/saved-searches/{searchID} [delete]
+func (app *application) deleteSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	searchID, err := readIDParam(r, "searchID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid saved search ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.SavedSearches.Delete(r.Context(), user.ID, searchID); err != nil {
+		if errors.Is(err, savedsearches.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// enqueueSavedSearchMatch queues matching a newly created game against
+// saved searches.
+func (app *application) enqueueSavedSearchMatch(gameID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := app.store.Jobs.Enqueue(ctx, jobMatchSavedSearches, matchSavedSearchesPayload{GameID: gameID}, jobs.EnqueueOptions{
+		UniqueKey: jobMatchSavedSearches + ":" + strconv.FormatInt(gameID, 10),
+	})
+	if err != nil {
+		app.logger.Errorw("failed to enqueue saved search match", "game_id", gameID, "error", err)
+	}
+}
+
+// runMatchSavedSearches pushes every user with a saved search the game fits,
+// skipping those whose weekly availability rules out the game's start time.
+// Matches are claimed before sending, so a failed push is not retried.
+func (app *application) runMatchSavedSearches(ctx context.Context, gameID int64) error {
+	matches, err := app.store.SavedSearches.MatchGame(ctx, gameID)
+	if err != nil {
+		return err
+	}
+
+	if len(matches) == 0 {
+		return nil
+	}
+	userIDs := make([]int64, 0, len(matches))
+	for _, m := range matches {
+		userIDs = append(userIDs, m.UserID)
+	}
+	available := app.availableAt(ctx, userIDs, matches[0].StartTime)
+
+	for _, m := range matches {
+		if !available[m.UserID] {
+			continue
+		}
+		if err := notifications.SendSavedSearchMatch(ctx, app.push, app.store, m); err != nil {
+			app.logger.Warnw("failed to push saved search match", "search_id", m.SearchID, "game_id", gameID, "error", err)
+		}
+	}
+	return nil
+}
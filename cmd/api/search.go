@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"khel/internal/domain/search"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// searchHandler godoc
+//
+//	@Summary		Search everything
+//	@Description	One search box across active venues, upcoming public games, published products and brands. Results come back as one group per type, in that order, each ranked best first; ranks compare only within a group. Matches full text on venue name and sport and product name and description, plus any name containing the query. Games match on their venue or sport; signed in, games run by players you've blocked or who blocked you are left out.
+//	@Tags			Search
+//	@Produce		json
+//	@Param			q		query		string			true	"Search query"
+//	@Param			type	query		string			false	"Comma-separated types to search: venue, game, product, brand (default: all)"
+//	@Param			limit	query		int				false	"Hits per type (default: 5, max: 20)"
+//	@Success		200		{object}	search.Results	"Grouped results"
+//	@Failure		400		{object}	error			"Bad Request"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/search [get]
+func (app *application) searchHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("search query is required"))
+		return
+	}
+	if len(q) > 100 {
+		app.badRequestResponse(w, r, fmt.Errorf("search query must be at most 100 characters"))
+		return
+	}
+
+	types := search.Types
+	if v := query.Get("type"); v != "" {
+		types = nil
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(t)
+			if !slices.Contains(search.Types, t) {
+				app.badRequestResponse(w, r, fmt.Errorf("type must be one of %s", strings.Join(search.Types, ", ")))
+				return
+			}
+			if !slices.Contains(types, t) {
+				types = append(types, t)
+			}
+		}
+	}
+
+	limit := search.DefaultLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > search.MaxLimit {
+			app.badRequestResponse(w, r, fmt.Errorf("limit must be between 1 and %d", search.MaxLimit))
+			return
+		}
+		limit = n
+	}
+
+	var viewerID int64
+	if user := getUserFromContext(r); user != nil {
+		viewerID = user.ID
+	}
+
+	res, err := app.store.Search.Search(r.Context(), q, types, limit, viewerID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, res)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"khel/internal/domain/search"
+	"khel/internal/events"
+	"khel/internal/jobs"
+	"khel/internal/searchindex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// searchReindexBatch is how many documents a reindex loads and sends at once.
+const searchReindexBatch = 500
+
+// publishVenueChanged tells the search indexer to look at the venue again.
+// With the default backend nothing subscribes, so this is a no-op.
+func (app *application) publishVenueChanged(ctx context.Context, venueID int64) {
+	err := app.events.Publish(ctx, events.VenueChanged, strconv.FormatInt(venueID, 10), events.VenueChangedPayload{VenueID: venueID})
+	if err != nil {
+		app.logger.Errorw("failed to publish venue change", "venue_id", venueID, "error", err)
+	}
+}
+
+func (app *application) publishProductChanged(ctx context.Context, productID int64) {
+	err := app.events.Publish(ctx, events.ProductChanged, strconv.FormatInt(productID, 10), events.ProductChangedPayload{ProductID: productID})
+	if err != nil {
+		app.logger.Errorw("failed to publish product change", "product_id", productID, "error", err)
+	}
+}
+
+// registerSearchIndexer mirrors venue and product changes into the search
+// index and reindexes nightly, which also catches what events can't: a
+// brand rename, or a change made while the same row was being indexed.
+func (app *application) registerSearchIndexer() {
+	if app.searchIndexer == nil {
+		return
+	}
+
+	app.events.Subscribe(events.VenueChanged, "search_index", func(ctx context.Context, raw json.RawMessage) error {
+		var p events.VenueChangedPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		docs, err := app.store.Search.VenueDocuments(ctx, search.DocumentFilter{IDs: []int64{p.VenueID}})
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return app.searchIndexer.Delete(ctx, searchindex.IndexVenues, []int64{p.VenueID})
+		}
+		return app.searchIndexer.UpsertVenues(ctx, docs, time.Now())
+	})
+
+	app.events.Subscribe(events.ProductChanged, "search_index", func(ctx context.Context, raw json.RawMessage) error {
+		var p events.ProductChangedPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		docs, err := app.store.Search.ProductDocuments(ctx, search.DocumentFilter{IDs: []int64{p.ProductID}})
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return app.searchIndexer.Delete(ctx, searchindex.IndexProducts, []int64{p.ProductID})
+		}
+		return app.searchIndexer.UpsertProducts(ctx, docs, time.Now())
+	})
+
+	app.jobs.Register(jobReindexSearch, func(ctx context.Context, _ json.RawMessage) error {
+		return app.runReindexSearch(ctx)
+	})
+	app.jobs.Every(jobReindexSearch, 24*time.Hour)
+}
+
+// adminReindexSearchHandler godoc
+//
+//	@Summary		Rebuild the search index
+//	@Description	Only for an external search backend (SEARCH_BACKEND=meilisearch): queues a job that applies the index settings, sends every active venue and published product, then removes whatever it didn't send. Run it once after switching the backend on; it also runs nightly. Venues and products are otherwise kept in sync as they change.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		202	{object}	map[string]string	"Reindex queued"
+//	@Failure		409	{object}	ErrorResponse		"Search runs on Postgres, which has no index to rebuild"
+//	@Failure		500	{object}	ErrorResponse		"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/search/reindex [post]
+func (app *application) adminReindexSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if app.searchIndexer == nil {
+		app.conflictResponse(w, r, errors.New("search runs on postgres, which has no index to rebuild"))
+		return
+	}
+
+	_, err := app.store.Jobs.Enqueue(r.Context(), jobReindexSearch, struct{}{}, jobs.EnqueueOptions{UniqueKey: jobReindexSearch})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusAccepted, map[string]string{"message": "search reindex queued"})
+}
+
+func (app *application) runReindexSearch(ctx context.Context) error {
+	start := time.Now()
+	if err := app.searchIndexer.Configure(ctx); err != nil {
+		return err
+	}
+
+	var sent int64
+	for after := int64(0); ; {
+		docs, err := app.store.Search.VenueDocuments(ctx, search.DocumentFilter{AfterID: after, Limit: searchReindexBatch})
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			break
+		}
+		if err := app.searchIndexer.UpsertVenues(ctx, docs, start); err != nil {
+			return err
+		}
+		sent += int64(len(docs))
+		after = docs[len(docs)-1].ID
+	}
+	for after := int64(0); ; {
+		docs, err := app.store.Search.ProductDocuments(ctx, search.DocumentFilter{AfterID: after, Limit: searchReindexBatch})
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			break
+		}
+		if err := app.searchIndexer.UpsertProducts(ctx, docs, start); err != nil {
+			return err
+		}
+		sent += int64(len(docs))
+		after = docs[len(docs)-1].ID
+	}
+
+	// Documents written by events during the run are newer than start, so
+	// only what's gone from the database is pruned.
+	for _, index := range []string{searchindex.IndexVenues, searchindex.IndexProducts} {
+		if err := app.searchIndexer.Prune(ctx, index, start); err != nil {
+			return err
+		}
+	}
+
+	jobs.SetRowsAffected(ctx, sent)
+	app.logger.Infow("reindexed search", "backend", app.searchIndexer.Name(), "documents", sent)
+	return nil
+}
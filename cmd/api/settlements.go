@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/settlements"
+	"khel/internal/jobs"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type UpdateSettlementStatusPayload struct {
+	Status string `json:"status" validate:"required,oneof=pending on_hold paid"`
+	// Reference of the bank or wallet transfer, required when marking paid.
+	Reference *string `json:"reference" validate:"omitempty,max=200"`
+	Note      *string `json:"note" validate:"omitempty,max=1000"`
+}
+
+// runCreateSettlements settles last week's bookings. It runs several times
+// a day; only the first run after Monday midnight creates anything.
+func (app *application) runCreateSettlements(ctx context.Context) error {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	// Monday of this week; weekdays count from Sunday = 0.
+	end := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	n, err := app.store.Settlements.CreateBatch(ctx, settlements.Period{Start: end.AddDate(0, 0, -7), End: end})
+	if err != nil {
+		return err
+	}
+	jobs.SetRowsAffected(ctx, int64(n))
+	if n > 0 {
+		app.logger.Infow("created settlements", "count", n, "period_end", end.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// listOwnerSettlementsHandler godoc
+//
+//	@Summary		List my settlements
+//	@Description	Weekly statements for every venue I own or manage, newest first. payout_amount is what the platform owes the venue after refunds and commission; a negative amount is commission owed on cash bookings.
+//	@Tags			Venue-Owner-Earnings
+//	@Produce		json
+//	@Param			status		query		string			false	"pending, on_hold or paid"
+//	@Param			venue_id	query		int				false	"Only this venue"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"settlements + pagination metadata"
+//	@Failure		400			{object}	error			"Bad Request"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/owner/settlements [get]
+func (app *application) listOwnerSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	app.listSettlements(w, r, settlements.Filter{ManagerID: &user.ID})
+}
+
+// getOwnerSettlementHandler godoc
+//
+//	@Summary		Get a settlement
+//	@Description	The settlement with one line per booking. unclosed_days lists the days of the period with bookings the venue hasn't closed yet. format=csv downloads the line items as a CSV file.
+//	@Tags			Venue-Owner-Earnings
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			settlementID	path		int		true	"Settlement ID"
+//	@Param			format			query		string	false	"Response format"	Enums(json,csv)	default(json)
+//	@Success		200				{object}	settlements.Settlement
+//	@Failure		400				{object}	error	"Bad Request"
+//	@Failure		404				{object}	error	"Settlement not found"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/owner/settlements/{settlementID} [get]
+func (app *application) getOwnerSettlementHandler(w http.ResponseWriter, r *http.Request) {
+	s, ok := app.loadSettlement(w, r)
+	if !ok {
+		return
+	}
+	owner, err := app.store.Venues.IsOwner(r.Context(), s.VenueID, getUserFromContext(r).ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !owner {
+		app.notFoundResponse(w, r, settlements.ErrNotFound)
+		return
+	}
+	app.writeSettlement(w, r, s)
+}
+
+// adminListSettlementsHandler godoc
+//
+//	@Summary		List settlements
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status		query		string			false	"pending, on_hold or paid"
+//	@Param			venue_id	query		int				false	"Only this venue"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"settlements + pagination metadata"
+//	@Failure		400			{object}	error			"Bad Request"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/settlements [get]
+func (app *application) adminListSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listSettlements(w, r, settlements.Filter{})
+}
+
+// adminGetSettlementHandler godoc
+//
+//	@Summary		Get a settlement
+//	@Tags			Admin
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			settlementID	path		int		true	"Settlement ID"
+//	@Param			format			query		string	false	"Response format"	Enums(json,csv)	default(json)
+//	@Success		200				{object}	settlements.Settlement
+//	@Failure		400				{object}	error	"Bad Request"
+//	@Failure		404				{object}	error	"Settlement not found"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/settlements/{settlementID} [get]
+func (app *application) adminGetSettlementHandler(w http.ResponseWriter, r *http.Request) {
+	s, ok := app.loadSettlement(w, r)
+	if !ok {
+		return
+	}
+	app.writeSettlement(w, r, s)
+}
+
+// adminUpdateSettlementStatusHandler godoc
+//
+//	@Summary		Change settlement status
+//	@Description	pending can move to on_hold or paid, on_hold back to pending or to paid. paid is final and needs the transfer reference.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			settlementID	path		int								true	"Settlement ID"
+//	@Param			payload			body		UpdateSettlementStatusPayload	true	"New status"
+//	@Success		200				{object}	settlements.Settlement
+//	@Failure		400				{object}	error	"Bad Request"
+//	@Failure		404				{object}	error	"Settlement not found"
+//	@Failure		409				{object}	error	"Invalid status transition"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/settlements/{settlementID}/status [patch]
+func (app *application) adminUpdateSettlementStatusHandler(w http.ResponseWriter, r *http.Request) {
+	s, ok := app.loadSettlement(w, r)
+	if !ok {
+		return
+	}
+
+	var payload UpdateSettlementStatusPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Reference = cleanOptionalString(payload.Reference)
+	payload.Note = cleanOptionalString(payload.Note)
+	if payload.Status == settlements.StatusPaid && payload.Reference == nil {
+		app.badRequestResponse(w, r, errors.New("reference is required when marking a settlement paid"))
+		return
+	}
+
+	ctx := r.Context()
+	err := app.store.Settlements.UpdateStatus(ctx, s.ID, s.Status, payload.Status, payload.Reference, payload.Note)
+	if err != nil {
+		if errors.Is(err, settlements.ErrInvalidTransition) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	updated, err := app.store.Settlements.GetByID(ctx, s.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	s.Items, updated.Items = nil, nil
+	app.recordAudit(r, audit.EntitySettlement, audit.ActionStatus, s.ID, s, updated)
+
+	app.jsonResponse(w, http.StatusOK, updated)
+}
+
+func (app *application) listSettlements(w http.ResponseWriter, r *http.Request, f settlements.Filter) {
+	q := r.URL.Query()
+	f.Status = strings.TrimSpace(q.Get("status"))
+	if v := strings.TrimSpace(q.Get("venue_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid venue_id"))
+			return
+		}
+		f.VenueID = &id
+	}
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Settlements.List(r.Context(), f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"settlements": list,
+		"pagination":  pagination,
+	})
+}
+
+func (app *application) loadSettlement(w http.ResponseWriter, r *http.Request) (*settlements.Settlement, bool) {
+	id, err := readIDParam(r, "settlementID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid settlement ID"))
+		return nil, false
+	}
+
+	s, err := app.store.Settlements.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, settlements.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+	return s, true
+}
+
+// writeSettlement answers with JSON, or the line items as CSV when the
+// request asks for format=csv.
+func (app *application) writeSettlement(w http.ResponseWriter, r *http.Request, s *settlements.Settlement) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" && format != "json" && format != "csv" {
+		app.badRequestResponse(w, r, fmt.Errorf("format must be json or csv"))
+		return
+	}
+	if format != "csv" {
+		app.jsonResponse(w, http.StatusOK, s)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="venue-%d-settlement-%s.csv"`, s.VenueID, s.PeriodStart))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"booking", "status", "start_time", "booking_amount", "online_amount", "refunded_amount", "commission_percent", "commission_amount", "net_amount"})
+	for _, it := range s.Items {
+		cw.Write([]string{
+			app.EncodeBookingID(it.BookingID),
+			it.BookingStatus,
+			it.StartTime.Format(time.RFC3339),
+			strconv.Itoa(it.BookingAmount),
+			strconv.Itoa(it.OnlineAmount),
+			strconv.Itoa(it.RefundedAmount),
+			strconv.Itoa(it.CommissionPercent),
+			strconv.Itoa(it.CommissionAmount),
+			strconv.Itoa(it.NetAmount),
+		})
+	}
+	cw.Write([]string{"total", "", "", strconv.Itoa(s.GrossAmount), strconv.Itoa(s.OnlineAmount), strconv.Itoa(s.RefundedAmount), "", strconv.Itoa(s.CommissionAmount), strconv.Itoa(s.PayoutAmount)})
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		app.logger.Errorw("failed to write settlement csv", "settlement_id", s.ID, "error", err)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/slotalerts"
+	"khel/internal/events"
+	"khel/internal/notifications"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type CreateSlotAlertPayload struct {
+	// FacilityID narrows the alert to one facility; omitted means any.
+	FacilityID *int64 `json:"facility_id" validate:"omitempty,gt=0"`
+	Date       string `json:"date" validate:"required"`
+	StartTime  string `json:"start_time" validate:"required"`
+	EndTime    string `json:"end_time" validate:"required"`
+}
+
+// createSlotAlertHandler godoc
+//
+//	@Summary		Get notified when a slot frees up
+//	@Description	Watches a time range at the venue (date as YYYY-MM-DD, start and end as HH:mm, local Nepal time; end before start runs past midnight). When a booking overlapping the range is canceled or rejected, the user gets a push and the alert expires. Up to 20 waiting alerts per user.
+//	@Tags			Venue
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int						true	"Venue ID"
+//	@Param			payload	body		CreateSlotAlertPayload	true	"Time range"
+//	@Success		201		{object}	slotalerts.Alert
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Venue or facility not found"
+//	@Failure		409		{object}	error	"Alert already exists or limit reached"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/slot-alerts [post]
+func (app *application) createSlotAlertHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload CreateSlotAlertPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		app.internalServerError(w, r, fmt.Errorf("failed to load Nepal timezone: %w", err))
+		return
+	}
+	start, err := time.ParseInLocation("2006-01-02 15:04", payload.Date+" "+payload.StartTime, loc)
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid date or start_time, use YYYY-MM-DD and HH:mm"))
+		return
+	}
+	end, err := time.ParseInLocation("2006-01-02 15:04", payload.Date+" "+payload.EndTime, loc)
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid end_time, use HH:mm"))
+		return
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	if !end.After(time.Now()) {
+		app.badRequestResponse(w, r, errors.New("time range is already over"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	alert := &slotalerts.Alert{
+		UserID:     user.ID,
+		VenueID:    venueID,
+		FacilityID: payload.FacilityID,
+		StartTime:  start,
+		EndTime:    end,
+	}
+	if err := app.store.SlotAlerts.Create(r.Context(), alert); err != nil {
+		switch {
+		case errors.Is(err, slotalerts.ErrVenueNotFound), errors.Is(err, slotalerts.ErrFacilityNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, slotalerts.ErrDuplicate), errors.Is(err, slotalerts.ErrLimitExceeded):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, alert)
+}
+
+// listSlotAlertsHandler godoc
+//
+//	@Summary		List my slot alerts
+//	@Description	Newest first. status is waiting, notified or expired (the range passed without a slot freeing up).
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{array}		slotalerts.Alert
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/slot-alerts [get]
+func (app *application) listSlotAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	list, err := app.store.SlotAlerts.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// deleteSlotAlertHandler godoc
+//
+//	@Summary		Delete a slot alert
+//	@Tags			Users
+//	@Param			alertID	path	int	true	"Alert ID"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Alert not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/slot-alerts/{alertID} [delete]
+func (app *application) deleteSlotAlertHandler(w http.ResponseWriter, r *http.Request) {
+	alertID, err := readIDParam(r, "alertID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid alert ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.SlotAlerts.Delete(r.Context(), user.ID, alertID); err != nil {
+		if errors.Is(err, slotalerts.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// publishBookingReleased announces that a canceled or rejected booking's
+// time is free again. Failures are logged; the slot is simply not
+// announced.
+func (app *application) publishBookingReleased(b *bookings.Booking, status string) {
+	if !b.EndTime.After(time.Now()) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := app.events.Publish(ctx, events.BookingReleased, strconv.FormatInt(b.ID, 10), events.BookingReleasedPayload{
+		BookingID:  b.ID,
+		VenueID:    b.VenueID,
+		FacilityID: b.FacilityID,
+		UserID:     b.UserID,
+		StartTime:  b.StartTime,
+		EndTime:    b.EndTime,
+		Status:     status,
+	})
+	if err != nil {
+		app.logger.Errorw("failed to publish booking released", "booking_id", b.ID, "error", err)
+	}
+}
+
+// runMatchSlotAlerts pushes every alert the released booking frees up to
+// users who are available at the slot's start time. Alerts are claimed before sending, so a failed push is not retried.
+func (app *application) runMatchSlotAlerts(ctx context.Context, p events.BookingReleasedPayload) error {
+	triggered, err := app.store.SlotAlerts.MatchReleased(ctx, slotalerts.Released{
+		BookingID:  p.BookingID,
+		VenueID:    p.VenueID,
+		FacilityID: p.FacilityID,
+		UserID:     p.UserID,
+		StartTime:  p.StartTime,
+		EndTime:    p.EndTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(triggered) == 0 {
+		return nil
+	}
+	userIDs := make([]int64, 0, len(triggered))
+	for _, t := range triggered {
+		userIDs = append(userIDs, t.UserID)
+	}
+	available := app.availableAt(ctx, userIDs, p.StartTime)
+
+	for _, t := range triggered {
+		if !available[t.UserID] {
+			continue
+		}
+		if err := notifications.SendSlotFreed(ctx, app.push, app.store, t); err != nil {
+			app.logger.Warnw("failed to push slot alert", "alert_id", t.AlertID, "booking_id", p.BookingID, "error", err)
+		}
+	}
+	return nil
+}
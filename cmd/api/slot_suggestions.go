@@ -0,0 +1,46 @@
+package main
+
+import (
+	"khel/internal/domain/bookings"
+	"net/http"
+	"time"
+)
+
+const (
+	suggestionRadiusMeters = 5000
+	suggestionsPerKind     = 3
+)
+
+// SlotTakenResponse is the 409 body when a requested slot is already booked:
+// a BOOKING_CONFLICT error whose details carry free alternatives.
+type SlotTakenResponse struct {
+	Code      errorCode        `json:"code" swaggertype:"string" example:"BOOKING_CONFLICT"`
+	Message   string           `json:"message"`
+	Details   slotTakenDetails `json:"details"`
+	RequestID string           `json:"request_id,omitempty"`
+}
+
+type slotTakenDetails struct {
+	Suggestions []bookings.SlotSuggestion `json:"suggestions"`
+}
+
+// slotTakenResponse answers 409 with the same slot at nearby venues and the
+// nearest free slots at this venue that day. Suggestions are best effort; a
+// failed lookup still returns the conflict.
+func (app *application) slotTakenResponse(w http.ResponseWriter, r *http.Request, venueID, facilityID int64, start, end time.Time) {
+	suggestions, err := app.store.Bookings.SuggestSlots(r.Context(), bookings.SuggestionQuery{
+		VenueID:      venueID,
+		FacilityID:   facilityID,
+		Start:        start,
+		End:          end,
+		RadiusMeters: suggestionRadiusMeters,
+		Limit:        suggestionsPerKind,
+	})
+	if err != nil {
+		app.logger.Errorw("failed to suggest slots", "venue_id", venueID, "facility_id", facilityID, "error", err)
+		suggestions = []bookings.SlotSuggestion{}
+	}
+
+	writeJSONError(w, r, http.StatusConflict, codeBookingConflict, errSlotTaken.Error(),
+		slotTakenDetails{Suggestions: suggestions})
+}
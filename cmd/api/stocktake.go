@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"khel/internal/domain/inventory"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StocktakeLinePayload struct {
+	InventoryItemID int64   `json:"inventory_item_id" validate:"required,min=1"`
+	Counted         *int    `json:"counted" validate:"required,min=0"`
+	Reason          *string `json:"reason" validate:"omitempty,max=255"`
+}
+
+type CreateStocktakePayload struct {
+	Note  *string                `json:"note" validate:"omitempty,max=500"`
+	Lines []StocktakeLinePayload `json:"lines" validate:"required,min=1,max=100,dive"`
+}
+
+// Response DTO since the ledger stores booking IDs but consumers see encoded ones
+type StockMovementResponseItem struct {
+	ID              int64     `json:"id"`
+	InventoryItemID int64     `json:"inventory_item_id"`
+	ItemName        string    `json:"item_name"`
+	Delta           int       `json:"delta"`
+	QuantityAfter   int       `json:"quantity_after"`
+	Reason          string    `json:"reason"`
+	Note            *string   `json:"note,omitempty"`
+	BookingID       *string   `json:"booking_id,omitempty"`
+	StocktakeID     *int64    `json:"stocktake_id,omitempty"`
+	PurchaseOrderID *int64    `json:"purchase_order_id,omitempty"`
+	CreatedBy       *int64    `json:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// exportInventoryStockHandler godoc
+//
+//	@Summary		Export current stock
+//	@Description	Current stock of every active inventory item, to count against. format=csv downloads a sheet with empty counted and reason columns to fill in during the stocktake.
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			venueID	path		int						true	"Venue ID"
+//	@Param			format	query		string					false	"Response format"	Enums(json,csv)	default(json)
+//	@Success		200		{object}	InventoryItemsResponse	"Venue inventory items"
+//	@Failure		400		{object}	ErrorResponse			"Bad request"
+//	@Failure		500		{object}	ErrorResponse			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/stock [get]
+func (app *application) exportInventoryStockHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" && format != "json" && format != "csv" {
+		app.badRequestResponse(w, r, fmt.Errorf("format must be json or csv"))
+		return
+	}
+
+	items, err := app.store.Inventory.ListInventoryItems(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	active := make([]inventory.InventoryItem, 0, len(items))
+	for _, it := range items {
+		if it.IsActive {
+			active = append(active, it)
+		}
+	}
+
+	if format != "csv" {
+		app.jsonResponse(w, http.StatusOK, active)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="venue-%d-stock-%s.csv"`, venueID, time.Now().Format("2006-01-02")))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"inventory_item_id", "name", "track_stock", "stock_quantity", "counted", "reason"})
+	for _, it := range active {
+		stock := ""
+		if it.StockQuantity != nil {
+			stock = strconv.Itoa(*it.StockQuantity)
+		}
+		cw.Write([]string{strconv.FormatInt(it.ID, 10), it.Name, strconv.FormatBool(it.TrackStock), stock, "", ""})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		app.logger.Errorw("failed to write stock csv", "venue_id", venueID, "error", err)
+	}
+}
+
+// createStocktakeHandler godoc
+//
+//	@Summary		Submit a stocktake
+//	@Description	Sets each listed item's stock to the counted quantity and records every difference in the stock ledger with its reason. Items left out are not touched. Counting an item turns on stock tracking for it.
+//	@Tags			venue inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int						true	"Venue ID"
+//	@Param			payload	body		CreateStocktakePayload	true	"Counted quantities"
+//	@Success		201		{object}	inventory.Stocktake		"Stocktake with expected and counted quantities"
+//	@Failure		400		{object}	ErrorResponse			"Bad request"
+//	@Failure		404		{object}	ErrorResponse			"Inventory item not found"
+//	@Failure		500		{object}	ErrorResponse			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/stocktakes [post]
+func (app *application) createStocktakeHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload CreateStocktakePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	st := &inventory.Stocktake{
+		VenueID:   venueID,
+		Note:      cleanOptionalString(payload.Note),
+		CreatedBy: getUserFromContext(r).ID,
+		Lines:     make([]inventory.StocktakeLine, 0, len(payload.Lines)),
+	}
+	seen := make(map[int64]bool, len(payload.Lines))
+	for _, l := range payload.Lines {
+		if seen[l.InventoryItemID] {
+			app.badRequestResponse(w, r, fmt.Errorf("item %d is counted twice", l.InventoryItemID))
+			return
+		}
+		seen[l.InventoryItemID] = true
+		st.Lines = append(st.Lines, inventory.StocktakeLine{
+			InventoryItemID: l.InventoryItemID,
+			Counted:         *l.Counted,
+			Reason:          cleanOptionalString(l.Reason),
+		})
+	}
+
+	if err := app.store.Inventory.CreateStocktake(r.Context(), st); err != nil {
+		switch {
+		case errors.Is(err, inventory.ErrInventoryItemNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, inventory.ErrEmptyStocktake):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, st)
+}
+
+// listStockMovementsHandler godoc
+//
+//	@Summary		List stock movements
+//	@Description	The stock ledger of the venue, newest first: opening stock, sales to games, manual edits, stocktake corrections and received purchase orders.
+//	@Tags			venue inventory
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			item_id	query		int				false	"Only this inventory item"
+//	@Param			reason	query		string			false	"opening, sale, adjustment, stocktake or purchase"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"movements + pagination metadata"
+//	@Failure		400		{object}	ErrorResponse	"Bad request"
+//	@Failure		500		{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/inventory/movements [get]
+func (app *application) listStockMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := inventory.MovementFilter{VenueID: venueID}
+	if v := strings.TrimSpace(q.Get("item_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid item_id"))
+			return
+		}
+		filter.ItemID = &id
+	}
+	switch reason := strings.TrimSpace(q.Get("reason")); reason {
+	case "", inventory.MovementOpening, inventory.MovementSale, inventory.MovementAdjustment, inventory.MovementStocktake, inventory.MovementPurchase:
+		filter.Reason = reason
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("invalid reason"))
+		return
+	}
+
+	pagination := params.ParsePagination(q)
+	movements, total, err := app.store.Inventory.ListMovements(r.Context(), filter, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	out := make([]StockMovementResponseItem, 0, len(movements))
+	for _, m := range movements {
+		item := StockMovementResponseItem{
+			ID:              m.ID,
+			InventoryItemID: m.InventoryItemID,
+			ItemName:        m.ItemName,
+			Delta:           m.Delta,
+			QuantityAfter:   m.QuantityAfter,
+			Reason:          m.Reason,
+			Note:            m.Note,
+			StocktakeID:     m.StocktakeID,
+			PurchaseOrderID: m.PurchaseOrderID,
+			CreatedBy:       m.CreatedBy,
+			CreatedAt:       m.CreatedAt,
+		}
+		if m.BookingID != nil {
+			hash := app.EncodeBookingID(*m.BookingID)
+			item.BookingID = &hash
+		}
+		out = append(out, item)
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"movements":  out,
+		"pagination": pagination,
+	})
+}
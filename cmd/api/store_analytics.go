@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/orders"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StoreMarginsResponse is the payload inside { "data": ... }.
+type StoreMarginsResponse struct {
+	From     string                `json:"from"`
+	To       string                `json:"to"`
+	Variants []orders.VariantSales `json:"variants"`
+}
+
+// adminStoreMarginsHandler godoc
+//
+//	@Summary		Sales and margin per variant (merchant)
+//	@Description	Units, revenue (after discounts), landed cost and margin per variant for orders placed in the range that are processing, shipped or delivered. Cost is the variant's cost price at checkout; units sold before a cost was recorded are counted in units_without_cost and left out of cost and margin. Dates are Nepal dates, both inclusive; the default is the last 30 days.
+//	@Tags			Store-Admin-Orders
+//	@Produce		json
+//	@Param			from	query		string	false	"Start date (YYYY-MM-DD)"
+//	@Param			to		query		string	false	"End date (YYYY-MM-DD)"
+//	@Success		200		{object}	envelope{data=StoreMarginsResponse}
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/store/admin/analytics/margins [get]
+//	@Security		ApiKeyAuth
+func (app *application) adminStoreMarginsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		app.internalServerError(w, r, fmt.Errorf("failed to load Nepal timezone: %w", err))
+		return
+	}
+
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from := to.AddDate(0, 0, -29)
+
+	q := r.URL.Query()
+	if v := strings.TrimSpace(q.Get("from")); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid from, use YYYY-MM-DD"))
+			return
+		}
+	}
+	if v := strings.TrimSpace(q.Get("to")); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid to, use YYYY-MM-DD"))
+			return
+		}
+	}
+	if to.Before(from) {
+		app.badRequestResponse(w, r, fmt.Errorf("to cannot be before from"))
+		return
+	}
+
+	variants, err := app.store.Sales.Orders.VariantSales(ctx, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, StoreMarginsResponse{
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Variants: variants,
+	})
+}
@@ -0,0 +1,573 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/support"
+	"khel/internal/mailer"
+	"khel/internal/notifications"
+	"khel/internal/params"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxSupportAttachments = 3
+
+type CreateTicketPayload struct {
+	Category  string `validate:"required,oneof=booking payment order account venue game other"`
+	Subject   string `validate:"required,max=150"`
+	Message   string `validate:"required,max=5000"`
+	BookingID *int64
+	OrderID   *int64
+}
+
+type AssignTicketPayload struct {
+	// AssigneeID is the staff user to assign; null unassigns.
+	AssigneeID *int64 `json:"assignee_id"`
+}
+
+type UpdateTicketStatusPayload struct {
+	Status string `json:"status" validate:"required,oneof=open in_progress awaiting_user resolved closed"`
+}
+
+// createSupportTicketHandler godoc
+//
+//	@Summary		Open a support ticket
+//	@Description	Creates a ticket with its first message. Accepts multipart/form-data so screenshots can be attached (max 3).
+//	@Tags			Support
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			category	formData	string	true	"booking, payment, order, account, venue, game or other"
+//	@Param			subject		formData	string	true	"Short summary (max 150 chars)"
+//	@Param			message		formData	string	true	"Describe the problem"
+//	@Param			booking_id	formData	int		false	"Related booking"
+//	@Param			order_id	formData	int		false	"Related order"
+//	@Param			attachments	formData	file	false	"Screenshots (max 3)"
+//	@Success		201			{object}	support.Ticket
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/support/tickets [post]
+func (app *application) createSupportTicketHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxFacilityImageMemory); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid multipart form: %w", err))
+		return
+	}
+
+	bookingID, err := parseOptionalInt64Form(r, "booking_id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	orderID, err := parseOptionalInt64Form(r, "order_id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	payload := CreateTicketPayload{
+		Category:  strings.ToLower(strings.TrimSpace(r.FormValue("category"))),
+		Subject:   strings.TrimSpace(r.FormValue("subject")),
+		Message:   strings.TrimSpace(r.FormValue("message")),
+		BookingID: bookingID,
+		OrderID:   orderID,
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+
+	attachmentURLs, err := app.uploadSupportAttachments(r.MultipartForm.File["attachments"], user.ID)
+	if err != nil {
+		if errors.Is(err, errTooManyAttachments) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	ticket, err := app.store.Support.Create(r.Context(), support.CreateTicketInput{
+		UserID:         user.ID,
+		Category:       payload.Category,
+		Subject:        payload.Subject,
+		Body:           payload.Message,
+		AttachmentURLs: attachmentURLs,
+		BookingID:      payload.BookingID,
+		OrderID:        payload.OrderID,
+	})
+	if err != nil {
+		app.deleteImagesAsync(attachmentURLs)
+		if errors.Is(err, support.ErrInvalidReference) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, ticket)
+}
+
+// listMySupportTicketsHandler godoc
+//
+//	@Summary		List my support tickets
+//	@Tags			Support
+//	@Produce		json
+//	@Param			status	query		string			false	"Filter by status"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"tickets + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/support/tickets [get]
+func (app *application) listMySupportTicketsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	q := r.URL.Query()
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Support.List(r.Context(), support.Filter{
+		Status: strings.TrimSpace(q.Get("status")),
+		UserID: &user.ID,
+	}, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"tickets":    list,
+		"pagination": pagination,
+	})
+}
+
+// getMySupportTicketHandler godoc
+//
+//	@Summary		Get one of my support tickets
+//	@Description	Returns the ticket with the full message thread.
+//	@Tags			Support
+//	@Produce		json
+//	@Param			ticketID	path		int	true	"Ticket ID"
+//	@Success		200			{object}	support.Ticket
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Ticket not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/support/tickets/{ticketID} [get]
+func (app *application) getMySupportTicketHandler(w http.ResponseWriter, r *http.Request) {
+	ticket, ok := app.loadOwnTicket(w, r)
+	if !ok {
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, ticket)
+}
+
+// replySupportTicketHandler godoc
+//
+//	@Summary		Reply to my support ticket
+//	@Description	Adds a message to the thread. Replying to a resolved or awaiting ticket reopens it; closed tickets cannot be replied to.
+//	@Tags			Support
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			ticketID	path		int		true	"Ticket ID"
+//	@Param			message		formData	string	true	"Message"
+//	@Param			attachments	formData	file	false	"Screenshots (max 3)"
+//	@Success		201			{object}	support.Message
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Ticket not found"
+//	@Failure		409			{object}	error	"Ticket is closed"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/support/tickets/{ticketID}/messages [post]
+func (app *application) replySupportTicketHandler(w http.ResponseWriter, r *http.Request) {
+	ticket, ok := app.loadOwnTicket(w, r)
+	if !ok {
+		return
+	}
+	user := getUserFromContext(r)
+
+	msg, _, ok := app.addTicketMessage(w, r, ticket.ID, user.ID, false)
+	if !ok {
+		return
+	}
+	app.jsonResponse(w, http.StatusCreated, msg)
+}
+
+// closeSupportTicketHandler godoc
+//
+//	@Summary		Close my support ticket
+//	@Tags			Support
+//	@Produce		json
+//	@Param			ticketID	path		int	true	"Ticket ID"
+//	@Success		200			{object}	support.Ticket
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Ticket not found"
+//	@Failure		409			{object}	error	"Ticket already closed"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/support/tickets/{ticketID}/close [post]
+func (app *application) closeSupportTicketHandler(w http.ResponseWriter, r *http.Request) {
+	ticket, ok := app.loadOwnTicket(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := app.store.Support.SetStatus(r.Context(), ticket.ID, support.StatusClosed)
+	if err != nil {
+		if errors.Is(err, support.ErrInvalidTransition) {
+			app.conflictResponse(w, r, support.ErrTicketClosed)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, updated)
+}
+
+// adminListSupportTicketsHandler godoc
+//
+//	@Summary		List support tickets
+//	@Description	Most recently active first. All filters are optional.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status		query		string			false	"open, in_progress, awaiting_user, resolved or closed"
+//	@Param			category	query		string			false	"Ticket category"
+//	@Param			assigned_to	query		int				false	"Assignee user ID"
+//	@Param			user_id		query		int				false	"Ticket owner user ID"
+//	@Param			page		query		int				false	"Page number (default: 1)"
+//	@Param			limit		query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200			{object}	map[string]any	"tickets + pagination metadata"
+//	@Failure		400			{object}	error			"Bad Request"
+//	@Failure		500			{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/support/tickets [get]
+func (app *application) adminListSupportTicketsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	f := support.Filter{
+		Status:   strings.TrimSpace(q.Get("status")),
+		Category: strings.TrimSpace(q.Get("category")),
+	}
+	if v := strings.TrimSpace(q.Get("assigned_to")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("assigned_to must be a positive number"))
+			return
+		}
+		f.AssignedTo = &id
+	}
+	if v := strings.TrimSpace(q.Get("user_id")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("user_id must be a positive number"))
+			return
+		}
+		f.UserID = &id
+	}
+
+	pagination := params.ParsePagination(q)
+	list, total, err := app.store.Support.List(r.Context(), f, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"tickets":    list,
+		"pagination": pagination,
+	})
+}
+
+// adminGetSupportTicketHandler godoc
+//
+//	@Summary		Get a support ticket
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			ticketID	path		int	true	"Ticket ID"
+//	@Success		200			{object}	support.Ticket
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Ticket not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/support/tickets/{ticketID} [get]
+func (app *application) adminGetSupportTicketHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "ticketID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid ticket ID"))
+		return
+	}
+
+	ticket, err := app.store.Support.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, support.ErrTicketNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, ticket)
+}
+
+// adminAssignSupportTicketHandler godoc
+//
+//	@Summary		Assign a support ticket
+//	@Description	Assigns the ticket to a staff member (null unassigns). Assigning an open ticket moves it to in_progress.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			ticketID	path		int					true	"Ticket ID"
+//	@Param			payload		body		AssignTicketPayload	true	"Assignee"
+//	@Success		200			{object}	support.Ticket
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Ticket not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/support/tickets/{ticketID}/assign [post]
+func (app *application) adminAssignSupportTicketHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "ticketID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid ticket ID"))
+		return
+	}
+
+	var payload AssignTicketPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ticket, err := app.store.Support.Assign(r.Context(), id, payload.AssigneeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, support.ErrTicketNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, support.ErrInvalidAssignee):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, ticket)
+}
+
+// adminReplySupportTicketHandler godoc
+//
+//	@Summary		Reply to a support ticket
+//	@Description	Adds a staff reply and sets the ticket to awaiting_user. The owner is notified by push and email.
+//	@Tags			Admin
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			ticketID	path		int		true	"Ticket ID"
+//	@Param			message		formData	string	true	"Reply"
+//	@Param			attachments	formData	file	false	"Attachments (max 3)"
+//	@Success		201			{object}	support.Message
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Ticket not found"
+//	@Failure		409			{object}	error	"Ticket is closed"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/support/tickets/{ticketID}/messages [post]
+func (app *application) adminReplySupportTicketHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "ticketID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid ticket ID"))
+		return
+	}
+	staff := getUserFromContext(r)
+
+	msg, ticket, ok := app.addTicketMessage(w, r, id, staff.ID, true)
+	if !ok {
+		return
+	}
+
+	app.notifySupportReply(ticket, msg.Body)
+
+	app.jsonResponse(w, http.StatusCreated, msg)
+}
+
+// adminUpdateSupportTicketStatusHandler godoc
+//
+//	@Summary		Change support ticket status
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			ticketID	path		int							true	"Ticket ID"
+//	@Param			payload		body		UpdateTicketStatusPayload	true	"New status"
+//	@Success		200			{object}	support.Ticket
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Ticket not found"
+//	@Failure		409			{object}	error	"Invalid status transition"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/superadmin/support/tickets/{ticketID}/status [patch]
+func (app *application) adminUpdateSupportTicketStatusHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "ticketID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid ticket ID"))
+		return
+	}
+
+	var payload UpdateTicketStatusPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ticket, err := app.store.Support.SetStatus(r.Context(), id, payload.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, support.ErrTicketNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, support.ErrInvalidTransition):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, ticket)
+}
+
+// loadOwnTicket fetches the ticket in the URL and makes sure it belongs to the
+// caller. Someone else's ticket is reported as not found.
+func (app *application) loadOwnTicket(w http.ResponseWriter, r *http.Request) (*support.Ticket, bool) {
+	id, err := readIDParam(r, "ticketID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid ticket ID"))
+		return nil, false
+	}
+
+	ticket, err := app.store.Support.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, support.ErrTicketNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	user := getUserFromContext(r)
+	if ticket.UserID != user.ID {
+		app.notFoundResponse(w, r, support.ErrTicketNotFound)
+		return nil, false
+	}
+	return ticket, true
+}
+
+// addTicketMessage parses a multipart reply, uploads its attachments and
+// appends it to the thread. It writes the error response itself.
+func (app *application) addTicketMessage(w http.ResponseWriter, r *http.Request, ticketID, authorID int64, isStaff bool) (*support.Message, *support.Ticket, bool) {
+	if err := r.ParseMultipartForm(maxFacilityImageMemory); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid multipart form: %w", err))
+		return nil, nil, false
+	}
+
+	body := strings.TrimSpace(r.FormValue("message"))
+	if body == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("message is required"))
+		return nil, nil, false
+	}
+	if len(body) > 5000 {
+		app.badRequestResponse(w, r, fmt.Errorf("message must be at most 5000 characters"))
+		return nil, nil, false
+	}
+
+	attachmentURLs, err := app.uploadSupportAttachments(r.MultipartForm.File["attachments"], authorID)
+	if err != nil {
+		if errors.Is(err, errTooManyAttachments) {
+			app.badRequestResponse(w, r, err)
+			return nil, nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, nil, false
+	}
+
+	msg, ticket, err := app.store.Support.AddMessage(r.Context(), ticketID, authorID, isStaff, body, attachmentURLs)
+	if err != nil {
+		app.deleteImagesAsync(attachmentURLs)
+		switch {
+		case errors.Is(err, support.ErrTicketNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, support.ErrTicketClosed):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return nil, nil, false
+	}
+	return msg, ticket, true
+}
+
+// notifySupportReply pushes and emails the ticket owner about a staff reply.
+// Both are best effort; the reply is already saved.
+func (app *application) notifySupportReply(ticket *support.Ticket, reply string) {
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendSupportReply(ctx, app.push, app.store, ticket.UserID, ticket.ID, ticket.Subject)
+	}, "support reply push")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	owner, err := app.store.Users.GetByID(ctx, ticket.UserID)
+	if err != nil {
+		app.logger.Errorw("failed to load ticket owner for reply email", "ticket_id", ticket.ID, "error", err)
+		return
+	}
+
+	data := struct {
+		Username string
+		TicketID int64
+		Subject  string
+		Reply    string
+	}{
+		Username: owner.FirstName,
+		TicketID: ticket.ID,
+		Subject:  ticket.Subject,
+		Reply:    reply,
+	}
+	if err := app.enqueueEmail(ctx, mailer.SupportReplyTemplate, app.userLocale(ctx, owner.ID), owner.FirstName, owner.Email, data); err != nil {
+		app.logger.Errorw("failed to enqueue support reply email", "ticket_id", ticket.ID, "error", err)
+	}
+}
+
+var errTooManyAttachments = fmt.Errorf("a message can have at most %d attachments", maxSupportAttachments)
+
+func (app *application) uploadSupportAttachments(files []*multipart.FileHeader, userID int64) ([]string, error) {
+	if len(files) > maxSupportAttachments {
+		return nil, errTooManyAttachments
+	}
+	return app.uploadAttachments(files, "support", fmt.Sprintf("user_%d_support", userID))
+}
+
+func parseOptionalInt64Form(r *http.Request, key string) (*int64, error) {
+	value := strings.TrimSpace(r.FormValue(key))
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("%s must be a positive number", key)
+	}
+
+	return &n, nil
+}
@@ -2,8 +2,14 @@ package main
 
 import "khel/internal/domain/inventory"
 
+// ErrorResponse is the body of every error response. Code is stable and
+// listed in error_catalog.go; Details depends on the code, e.g. the failed
+// fields for VALIDATION_FAILED.
 type ErrorResponse struct {
-	Error string `json:"error" example:"Bad request"`
+	Code      errorCode `json:"code" swaggertype:"string" example:"BOOKING_CONFLICT"`
+	Message   string    `json:"message" example:"time slot is already booked"`
+	Details   any       `json:"details,omitempty" swaggertype:"object"`
+	RequestID string    `json:"request_id,omitempty" example:"9f86d081884c7d65"`
 }
 
 type MessageResponse struct {
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"khel/internal/media"
+	"mime/multipart"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// deleteStoredFile removes a file from whichever storage it was uploaded
+// to. URLs that none of them own, such as seeded placeholders, are left
+// alone.
+func (app *application) deleteStoredFile(ctx context.Context, fileURL string) error {
+	err := app.media.Delete(ctx, fileURL)
+	if errors.Is(err, media.ErrNotOwned) {
+		app.logger.Warnw("not deleting file outside media storage", "url", fileURL)
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	return app.store.MediaAssets.Forget(ctx, fileURL)
+}
+
+// -----------------------------------------------
+// Upload Functions with Controlled Naming
+// -----------------------------------------------
+
+func (app *application) uploadFileWithID(
+	file io.Reader,
+	publicID string,
+	folder string,
+) (string, error) {
+	return app.upload(file, media.UploadOptions{Folder: folder, Name: publicID})
+}
+
+// uploadImageWithID is uploadFileWithID for images the apps show, asking
+// for every size in images.Sizes up front where the storage makes them.
+// Call enqueueImageVariants once the URL is saved.
+func (app *application) uploadImageWithID(
+	file io.Reader,
+	publicID string,
+	folder string,
+) (string, error) {
+	return app.upload(file, media.UploadOptions{Folder: folder, Name: publicID, Variants: true})
+}
+
+func (app *application) upload(file io.Reader, opts media.UploadOptions) (string, error) {
+	// If caller passed empty folder → choose based on environment
+	if strings.TrimSpace(opts.Folder) == "" {
+		env := os.Getenv("APP_ENV")
+		if env == "prod" || env == "production" {
+			opts.Folder = "venues"
+		} else {
+			opts.Folder = "testVenues"
+		}
+	}
+
+	obj, err := app.media.Upload(context.Background(), file, opts)
+	if err != nil {
+		return "", fmt.Errorf("upload: %w", err)
+	}
+	app.recordMediaAsset(obj, opts)
+	return obj.URL, nil
+}
+
+// uploadImagesWithVenueID iterates over provided files and uploads them,
+// using the venueID along with an image index to control the public ID.
+func (app *application) uploadImagesWithVenueID(
+	files []*multipart.FileHeader,
+	venueID int64,
+) ([]string, error) {
+	var urls []string
+
+	for _, fileHeader := range files {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open file: %w", err)
+		}
+		// It's important to close the file after we're done using it.
+		// Since we are in a loop, we call Close() after each upload.
+		defer file.Close()
+
+		// Generate a custom Cloudinary public ID using the venue ID and image number.
+		publicID := fmt.Sprintf("venue_%d_image_%d", venueID, time.Now().UnixNano())
+		url, err := app.uploadImageWithID(file, publicID, "")
+		if err != nil {
+			return nil, fmt.Errorf("upload: %w", err)
+		}
+
+		urls = append(urls, url)
+	}
+
+	return urls, nil
+}
+
+func (app *application) uploadAdImage(file io.Reader, adTitle string) (string, error) {
+	env := os.Getenv("APP_ENV")
+
+	folder := "testAds"
+	if env == "prod" || env == "production" {
+		folder = "ads"
+	}
+	// Create safe public ID from title
+	publicID := app.createSafePublicID(adTitle)
+	publicID = fmt.Sprintf("%s_%d", publicID, time.Now().UnixNano())
+
+	return app.upload(file, media.UploadOptions{
+		Folder:         folder,
+		Name:           publicID,
+		Transformation: "w_800,h_450,c_fill,q_auto,f_auto",
+		Variants:       true,
+	})
+}
+
+// Helper function to validate ad image file types
+func (app *application) isValidAdImageType(contentType string) bool {
+	validTypes := []string{
+		"image/jpeg",
+		"image/png",
+		"image/webp",
+		"image/jpg",
+	}
+
+	for _, validType := range validTypes {
+		if contentType == validType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createSafePublicID creates a safe public ID from an ad title
+func (app *application) createSafePublicID(title string) string {
+	// Convert to lowercase
+	safeID := strings.ToLower(title)
+
+	// Replace spaces and special characters with underscores
+	reg := regexp.MustCompile(`[^a-z0-9]+`)
+	safeID = reg.ReplaceAllString(safeID, "_")
+
+	// Remove leading/trailing underscores
+	safeID = strings.Trim(safeID, "_")
+
+	// Limit length to 50 characters
+	if len(safeID) > 50 {
+		safeID = safeID[:50]
+	}
+
+	// Ensure it's not empty
+	if safeID == "" {
+		safeID = "ad_image"
+	}
+
+	return safeID
+}
+
+// uploadAttachments uploads user-supplied files (screenshots, receipts) to
+// folder, or to its "test" twin outside production. If one upload fails the
+// ones already done are queued for deletion.
+func (app *application) uploadAttachments(files []*multipart.FileHeader, folder, publicIDPrefix string) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	folder = attachmentFolder(folder)
+
+	urls := make([]string, 0, len(files))
+	for _, fileHeader := range files {
+		file, err := fileHeader.Open()
+		if err != nil {
+			app.deleteImagesAsync(urls)
+			return nil, fmt.Errorf("open attachment: %w", err)
+		}
+
+		publicID := fmt.Sprintf("%s_%d", publicIDPrefix, time.Now().UnixNano())
+		url, err := app.uploadFileWithID(file, publicID, folder)
+
+		closeErr := file.Close()
+		if closeErr != nil && err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			app.deleteImagesAsync(urls)
+			return nil, fmt.Errorf("upload attachment: %w", err)
+		}
+
+		urls = append(urls, url)
+	}
+
+	return urls, nil
+}
+
+// attachmentFolder is folder in production and its "test" twin elsewhere.
+func attachmentFolder(folder string) string {
+	env := os.Getenv("APP_ENV")
+	if env != "prod" && env != "production" {
+		folder = "test" + strings.ToUpper(folder[:1]) + folder[1:]
+	}
+	return folder
+}
@@ -9,13 +9,13 @@ import (
 	"khel/internal/domain/bookings"
 	"khel/internal/domain/users"
 	"khel/internal/helpers"
+	"khel/internal/media"
 	"khel/internal/params"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -23,9 +23,15 @@ type userKey string
 
 const userCtx userKey = "user"
 
-// for cloudinary uploadParams
-func boolPtr(b bool) *bool {
-	return &b
+// profilePictureOptions stores a user's picture under their ID, so a new
+// one replaces the old.
+func profilePictureOptions(userID int64) media.UploadOptions {
+	return media.UploadOptions{
+		Folder:         "profile_pictures",
+		Name:           fmt.Sprintf("/%d", userID),
+		Overwrite:      true,
+		Transformation: "w_128,h_128,c_fill,q_auto,f_auto",
+	}
 }
 
 // uploadProfilePictureHandler godoc
@@ -44,19 +50,18 @@ func boolPtr(b bool) *bool {
 func (app *application) uploadProfilePictureHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 	userID := user.ID
-	overwrite := boolPtr(true) // Using the helper function
 
 	// Parse the multipart form
 	err := r.ParseMultipartForm(2 << 20) // 2 MB
 	if err != nil {
-		http.Error(w, "Unable to parse form, file size limit is 2MB", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("unable to parse form, file size limit is 2MB"))
 		return
 	}
 
 	// Retrieve the file from the form data
 	file, fileHeader, err := r.FormFile("profile_picture")
 	if err != nil {
-		http.Error(w, "Unable to retrieve file", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("unable to retrieve file"))
 		return
 	}
 	defer file.Close()
@@ -64,35 +69,26 @@ func (app *application) uploadProfilePictureHandler(w http.ResponseWriter, r *ht
 	// Validate file type (allow only JPEG & PNG)
 	contentType := fileHeader.Header.Get("Content-Type")
 	if contentType != "image/jpeg" && contentType != "image/png" {
-		http.Error(w, "Only JPEG and PNG images are allowed", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("only JPEG and PNG images are allowed"))
 		return
 	}
 
-	// Upload the file to Cloudinary
-	ctx := context.Background()
-
-	uploadParams := uploader.UploadParams{
-		PublicID:  fmt.Sprintf("/%d", userID), // Save with userID as filename
-		Overwrite: overwrite,
-		// Replace old profile pic
-		Folder:         "profile_pictures", // Organized storage
-		Transformation: "w_128,h_128,c_fill,q_auto,f_auto",
-	}
-	uploadResult, err := app.cld.Upload.Upload(ctx, file, uploadParams)
+	// Upload the file
+	profileURL, err := app.upload(file, profilePictureOptions(userID))
 	if err != nil {
-		http.Error(w, "Failed to upload image to Cloudinary", http.StatusInternalServerError)
+		app.internalServerError(w, r, fmt.Errorf("upload profile picture: %w", err))
 		return
 	}
 
 	// Save the URL in the database
 
-	if err := app.store.Users.SetProfile(r.Context(), uploadResult.SecureURL, userID); err != nil {
+	if err := app.store.Users.SetProfile(r.Context(), profileURL, userID); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(fmt.Sprintf("Profile picture uploaded successfully: %s", uploadResult.SecureURL)))
+	w.Write([]byte(fmt.Sprintf("Profile picture uploaded successfully: %s", profileURL)))
 }
 
 // updateProfilePictureHandler godoc
@@ -115,41 +111,34 @@ func (app *application) updateProfilePictureHandler(w http.ResponseWriter, r *ht
 	// Parse the multipart form
 	err := r.ParseMultipartForm(2 << 20) // 2 MB limit
 	if err != nil {
-		http.Error(w, "Unable to parse form, file size limit is 2MB", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("unable to parse form, file size limit is 2MB"))
 		return
 	}
 
 	// Retrieve the file from form data
 	file, _, err := r.FormFile("profile_picture")
 	if err != nil {
-		http.Error(w, "Unable to retrieve file", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("unable to retrieve file"))
 		return
 	}
 	defer file.Close()
 
-	// Upload the new file to Cloudinary with specific PublicID to ensure replacement
-	uploadParams := uploader.UploadParams{
-		Folder:         "profile_pictures",
-		Overwrite:      boolPtr(true),                      // Ensure overwrite of the existing file
-		Transformation: "w_128,h_128,c_fill,q_auto,f_auto", // Optional transformations (e.g., resizing)
-		PublicID:       fmt.Sprintf("/%d", userID),         // Use userID as the PublicID to replace the old image
-	}
-
-	uploadResult, err := app.cld.Upload.Upload(r.Context(), file, uploadParams)
+	// Upload the new file under the user's ID so it replaces the old image
+	profileURL, err := app.upload(file, profilePictureOptions(userID))
 	if err != nil {
-		http.Error(w, "Failed to upload image to Cloudinary", http.StatusInternalServerError)
+		app.internalServerError(w, r, fmt.Errorf("upload profile picture: %w", err))
 		return
 	}
 
 	// Save the new profile picture URL in the database
-	err = app.store.Users.SetProfile(r.Context(), uploadResult.SecureURL, userID)
+	err = app.store.Users.SetProfile(r.Context(), profileURL, userID)
 	if err != nil {
-		http.Error(w, "Failed to update profile picture URL in database", http.StatusInternalServerError)
+		app.internalServerError(w, r, err)
 		return
 	}
 
 	// Return JSON response with new image URL
-	if err := app.jsonResponse(w, http.StatusOK, uploadResult.SecureURL); err != nil {
+	if err := app.jsonResponse(w, http.StatusOK, profileURL); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -209,6 +198,7 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.screenProfileName(userID, updates)
 
 	w.WriteHeader(http.StatusNoContent) // No content response on success
 	w.Write([]byte(fmt.Sprintf("User info updated successfully: %s", updates)))
@@ -345,7 +335,7 @@ func (app *application) editProfileHandler(w http.ResponseWriter, r *http.Reques
 	userID := user.ID
 
 	if err := r.ParseMultipartForm(5 << 20); err != nil {
-		http.Error(w, "Could not parse form", http.StatusBadRequest)
+		app.badRequestResponse(w, r, fmt.Errorf("could not parse form: %w", err))
 		return
 	}
 
@@ -365,21 +355,14 @@ func (app *application) editProfileHandler(w http.ResponseWriter, r *http.Reques
 		defer file.Close()
 		ct := header.Header.Get("Content-Type")
 		if ct != "image/jpeg" && ct != "image/png" {
-			http.Error(w, "only jpeg/png", http.StatusBadRequest)
+			app.badRequestResponse(w, r, fmt.Errorf("only JPEG and PNG images are allowed"))
 			return
 		}
-		uploadParams := uploader.UploadParams{
-			PublicID:       fmt.Sprintf("/%d", userID),
-			Overwrite:      boolPtr(true),
-			Folder:         "profile_pictures",
-			Transformation: "w_128,h_128,c_fill,q_auto,f_auto",
-		}
-		res, err := app.cld.Upload.Upload(r.Context(), file, uploadParams)
+		u, err := app.upload(file, profilePictureOptions(userID))
 		if err != nil {
-			http.Error(w, "upload failed", http.StatusInternalServerError)
+			app.internalServerError(w, r, fmt.Errorf("upload profile picture: %w", err))
 			return
 		}
-		u := res.SecureURL
 		newURL = &u
 	}
 
@@ -388,6 +371,7 @@ func (app *application) editProfileHandler(w http.ResponseWriter, r *http.Reques
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.screenProfileName(userID, updates)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -407,7 +391,7 @@ func (app *application) editProfileHandler(w http.ResponseWriter, r *http.Reques
 func (app *application) getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
 	userCtx := getUserFromContext(r)
 	if userCtx == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		app.unauthorizedErrorResponse(w, r, fmt.Errorf("unauthorized"))
 		return
 	}
 
@@ -415,7 +399,7 @@ func (app *application) getCurrentUserHandler(w http.ResponseWriter, r *http.Req
 	user, err := app.store.Users.GetByID(r.Context(), userCtx.ID)
 	if err != nil {
 		if errors.Is(err, users.ErrNotFound) {
-			http.Error(w, "User not found", http.StatusNotFound)
+			app.notFoundResponse(w, r, err)
 		} else {
 			app.internalServerError(w, r, err)
 		}
@@ -460,42 +444,40 @@ func (app *application) getCurrentUserHandler(w http.ResponseWriter, r *http.Req
 // deleteUserAccountHandler godoc
 //
 //	@Summary		Delete current user account
-//	@Description	Deletes the logged-in user's account and Cloudinary profile photo
+//	@Description	Schedules the logged-in user's account for deletion and signs it out. Signing in again within 14 days cancels the request; after that the account's personal data is erased.
 //	@Tags			users
 //	@Produce		json
-//	@Success		204	{string}	string	"User deleted"
+//	@Success		202	{object}	AccountDeletionResponse
 //	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		409	{object}	error	"User still owns venues"
 //	@Failure		500	{object}	error	"Internal server error"
 //	@Security		ApiKeyAuth
 //	@Router			/users/me [delete]
 func (app *application) deleteUserAccountHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 	if user == nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		app.unauthorizedErrorResponse(w, r, fmt.Errorf("unauthorized"))
 		return
 	}
 
-	// Delete profile picture if one exists
-	if user.ProfilePictureURL.Valid {
-		err := app.deletePhotoFromCloudinary(user.ProfilePictureURL.String)
-		if err != nil {
-			app.logger.Error(err, nil) // Log failure, don't block deletion
-		} else {
-			app.logger.Info("Successfully deleted profile picture from Cloudinary", map[string]any{
-				"user_id": user.ID,
-				"url":     user.ProfilePictureURL.String,
-			})
-		}
+	// Venues would be left without anyone to run them.
+	venueIDs, err := app.store.Venues.GetOwnedVenueIDs(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if len(venueIDs) > 0 {
+		app.conflictResponse(w, r, errors.New("transfer or remove your venues before deleting your account"))
+		return
 	}
 
-	// Delete user from DB
-	err := app.store.Users.Delete(r.Context(), user.ID)
+	due, err := app.store.Users.RequestDeletion(r.Context(), user.ID, users.DeletionGracePeriod)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	app.jsonResponse(w, http.StatusAccepted, AccountDeletionResponse{DeletionScheduledFor: due})
 }
 
 // ListUsers (admin) godoc
@@ -516,7 +498,7 @@ func (app *application) deleteUserAccountHandler(w http.ResponseWriter, r *http.
 func (app *application) adminListUsersHandler(w http.ResponseWriter, r *http.Request) {
 	current := getUserFromContext(r)
 	if current == nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		app.unauthorizedErrorResponse(w, r, fmt.Errorf("unauthorized"))
 		return
 	}
 
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"khel/internal/domain/products"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// variantCSVHeader is shared by export and import, so an exported sheet can
+// be edited and uploaded again as is.
+var variantCSVHeader = []string{"variant_id", "product_id", "product_name", "sku", "barcode", "price_cents", "cost_price_cents", "is_active"}
+
+// maxVariantImportRows keeps one upload inside a single short transaction.
+const maxVariantImportRows = 5000
+
+// Export every variant as CSV (admin)
+func (app *application) exportVariantsCSVHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := app.store.Products.ListVariantsForExport(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="variants-%s.csv"`, time.Now().Format("2006-01-02")))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(variantCSVHeader)
+	for _, v := range rows {
+		barcode, cost := "", ""
+		if v.Barcode != nil {
+			barcode = *v.Barcode
+		}
+		if v.CostPriceCents != nil {
+			cost = strconv.FormatInt(*v.CostPriceCents, 10)
+		}
+		_ = cw.Write([]string{
+			strconv.FormatInt(v.VariantID, 10),
+			strconv.FormatInt(v.ProductID, 10),
+			v.ProductName,
+			v.SKU,
+			barcode,
+			strconv.FormatInt(v.PriceCents, 10),
+			cost,
+			strconv.FormatBool(v.IsActive),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		app.logger.Errorw("failed to write variants csv", "error", err)
+	}
+}
+
+// Import a variant CSV (admin). Rows are matched by sku; barcode, price,
+// cost and active flag are updated from the non-empty cells. Other columns
+// are ignored. A barcode of "-" removes it. Nothing is saved unless every
+// row applies.
+func (app *application) importVariantsCSVHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(5 << 20); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("file too large or invalid form"))
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("file is required"))
+		return
+	}
+	defer file.Close()
+
+	rows, issues, err := parseVariantCSV(file)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if len(issues) > 0 {
+		writeJSONError(w, r, http.StatusUnprocessableEntity, codeValidationFailed, "the file has invalid rows; nothing was updated",
+			map[string]any{"issues": issues})
+		return
+	}
+
+	issues, err = app.store.Products.ImportVariants(r.Context(), rows)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if len(issues) > 0 {
+		writeJSONError(w, r, http.StatusUnprocessableEntity, codeValidationFailed, "the file has invalid rows; nothing was updated",
+			map[string]any{"issues": issues})
+		return
+	}
+	// Rows name variants by SKU, so look over every wishlist rather than
+	// resolving each one.
+	app.enqueueWishlistPriceCheck(0)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{"updated": len(rows), "issues": []products.VariantImportIssue{}})
+}
+
+// parseVariantCSV reads rows by header name. Cell errors are collected per
+// line; err is only set when the file itself is unusable.
+func parseVariantCSV(src io.Reader) ([]products.VariantImportRow, []products.VariantImportIssue, error) {
+	cr := csv.NewReader(src)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := col["sku"]; !ok {
+		return nil, nil, errors.New("csv must have a sku column")
+	}
+	cell := func(rec []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var (
+		rows   []products.VariantImportRow
+		issues []products.VariantImportIssue
+		seen   = map[string]int{}
+	)
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(rows)+len(issues) >= maxVariantImportRows {
+			return nil, nil, fmt.Errorf("at most %d rows per import", maxVariantImportRows)
+		}
+
+		row := products.VariantImportRow{Line: line, SKU: cell(rec, "sku")}
+		fail := func(msg string) {
+			issues = append(issues, products.VariantImportIssue{Line: line, SKU: row.SKU, Error: msg})
+		}
+		if row.SKU == "" {
+			fail("sku is empty")
+			continue
+		}
+		if first, dup := seen[strings.ToLower(row.SKU)]; dup {
+			fail(fmt.Sprintf("sku repeats line %d", first))
+			continue
+		}
+		seen[strings.ToLower(row.SKU)] = line
+
+		switch b := cell(rec, "barcode"); {
+		case b == "-":
+			row.ClearBarcode = true
+		case len(b) > 64:
+			fail("barcode must be at most 64 characters")
+			continue
+		case b != "":
+			row.Barcode = &b
+		}
+		if v := cell(rec, "price_cents"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				fail("price_cents must be a whole number >= 0")
+				continue
+			}
+			row.PriceCents = &n
+		}
+		if v := cell(rec, "cost_price_cents"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				fail("cost_price_cents must be a whole number >= 0")
+				continue
+			}
+			row.CostPriceCents = &n
+		}
+		if v := cell(rec, "is_active"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				fail("is_active must be true or false")
+				continue
+			}
+			row.IsActive = &b
+		}
+		rows = append(rows, row)
+	}
+	if len(rows)+len(issues) == 0 {
+		return nil, nil, errors.New("csv has no rows")
+	}
+	return rows, issues, nil
+}
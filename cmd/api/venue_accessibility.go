@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"khel/internal/domain/venues"
+	"net/http"
+	"strings"
+)
+
+// VenueAccessibilityPayload replaces all accessibility fields at once. Leave
+// a field null when it isn't known.
+type VenueAccessibilityPayload struct {
+	WheelchairAccess  *bool   `json:"wheelchair_access"`
+	AccessibleToilets *bool   `json:"accessible_toilets"`
+	AccessibleParking *bool   `json:"accessible_parking"`
+	Notes             *string `json:"notes" validate:"omitempty,max=500"`
+}
+
+// setVenueAccessibilityHandler godoc
+//
+//	@Summary		Set my venue's accessibility info
+//	@Description	Wheelchair access, accessible toilets and accessible parking are shown on the venue page and can be filtered on in the venue list. null means unknown; notes can describe details such as a ramp at the side entrance.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int							true	"Venue ID"
+//	@Param			payload	body		VenueAccessibilityPayload	true	"Accessibility"
+//	@Success		200		{object}	venues.Accessibility
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/accessibility [put]
+func (app *application) setVenueAccessibilityHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload VenueAccessibilityPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	a := venues.Accessibility{
+		WheelchairAccess:  payload.WheelchairAccess,
+		AccessibleToilets: payload.AccessibleToilets,
+		AccessibleParking: payload.AccessibleParking,
+	}
+	if payload.Notes != nil {
+		if notes := strings.TrimSpace(*payload.Notes); notes != "" {
+			a.Notes = &notes
+		}
+	}
+
+	if err := app.store.Venues.SetAccessibility(r.Context(), venueID, a); err != nil {
+		if errors.Is(err, venues.ErrVenueNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, a)
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/venueannouncements"
+	"khel/internal/notifications"
+	"khel/internal/params"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type CreateAnnouncementPayload struct {
+	Title string `json:"title" validate:"required,max=120"`
+	Body  string `json:"body" validate:"required,max=2000"`
+	// PublishAt schedules the post; omitted means publish now.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// ExpiresAt hides the post afterwards; omitted means it stays up.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type UpdateAnnouncementPayload struct {
+	Title       *string    `json:"title,omitempty" validate:"omitempty,max=120"`
+	Body        *string    `json:"body,omitempty" validate:"omitempty,max=2000"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ClearExpiry bool       `json:"clear_expiry,omitempty"`
+}
+
+// createVenueAnnouncementHandler godoc
+//
+//	@Summary		Create a venue announcement
+//	@Description	Publishes (or schedules) a short post on the venue page. Users who favorited the venue get a push when it goes live.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int									true	"Venue ID"
+//	@Param			payload	body		CreateAnnouncementPayload			true	"Announcement"
+//	@Success		201		{object}	venueannouncements.Announcement
+//	@Failure		400		{object}	error								"Bad Request"
+//	@Failure		403		{object}	error								"Forbidden"
+//	@Failure		500		{object}	error								"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/announcements [post]
+func (app *application) createVenueAnnouncementHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload CreateAnnouncementPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	publishAt := time.Now()
+	if payload.PublishAt != nil {
+		publishAt = *payload.PublishAt
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(publishAt) {
+		app.badRequestResponse(w, r, fmt.Errorf("expires_at must be after publish_at"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	a := &venueannouncements.Announcement{
+		VenueID:   venueID,
+		AuthorID:  &user.ID,
+		Title:     strings.TrimSpace(payload.Title),
+		Body:      strings.TrimSpace(payload.Body),
+		PublishAt: publishAt,
+		ExpiresAt: payload.ExpiresAt,
+	}
+	if err := app.store.VenueAnnouncements.Create(r.Context(), a); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, a)
+}
+
+// listVenueAnnouncementsHandler godoc
+//
+//	@Summary		List live venue announcements
+//	@Description	Announcements currently published and not expired, newest first.
+//	@Tags			Venue
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{array}		venueannouncements.Announcement
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/venues/{venueID}/announcements [get]
+func (app *application) listVenueAnnouncementsHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	list, err := app.store.VenueAnnouncements.ListActive(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// listOwnerVenueAnnouncementsHandler godoc
+//
+//	@Summary		List all venue announcements (owner)
+//	@Description	Includes scheduled and expired announcements.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int				true	"Venue ID"
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"announcements + pagination metadata"
+//	@Failure		400		{object}	error			"Bad Request"
+//	@Failure		403		{object}	error			"Forbidden"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/announcements/all [get]
+func (app *application) listOwnerVenueAnnouncementsHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	pagination := params.ParsePagination(r.URL.Query())
+	list, total, err := app.store.VenueAnnouncements.ListByVenue(r.Context(), venueID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"announcements": list,
+		"pagination":    pagination,
+	})
+}
+
+// updateVenueAnnouncementHandler godoc
+//
+//	@Summary		Update a venue announcement
+//	@Description	Only provided fields change. Moving publish_at into the future re-arms the follower push.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID			path		int							true	"Venue ID"
+//	@Param			announcementID	path		int							true	"Announcement ID"
+//	@Param			payload			body		UpdateAnnouncementPayload	true	"Fields to update"
+//	@Success		200				{object}	venueannouncements.Announcement
+//	@Failure		400				{object}	error	"Bad Request"
+//	@Failure		403				{object}	error	"Forbidden"
+//	@Failure		404				{object}	error	"Announcement not found"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/announcements/{announcementID} [patch]
+func (app *application) updateVenueAnnouncementHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	id, err := readIDParam(r, "announcementID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid announcement ID"))
+		return
+	}
+
+	var payload UpdateAnnouncementPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.ClearExpiry && payload.ExpiresAt != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("expires_at and clear_expiry are mutually exclusive"))
+		return
+	}
+
+	upd := venueannouncements.UpdateAnnouncement{
+		PublishAt:   payload.PublishAt,
+		ExpiresAt:   payload.ExpiresAt,
+		ClearExpiry: payload.ClearExpiry,
+	}
+	if payload.Title != nil {
+		t := strings.TrimSpace(*payload.Title)
+		if t == "" {
+			app.badRequestResponse(w, r, fmt.Errorf("title cannot be empty"))
+			return
+		}
+		upd.Title = &t
+	}
+	if payload.Body != nil {
+		b := strings.TrimSpace(*payload.Body)
+		if b == "" {
+			app.badRequestResponse(w, r, fmt.Errorf("body cannot be empty"))
+			return
+		}
+		upd.Body = &b
+	}
+
+	a, err := app.store.VenueAnnouncements.Update(r.Context(), venueID, id, upd)
+	if err != nil {
+		if errors.Is(err, venueannouncements.ErrAnnouncementNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		// expires_at <= publish_at trips the table CHECK
+		if strings.Contains(err.Error(), "venue_announcements_expiry_after_publish") {
+			app.badRequestResponse(w, r, fmt.Errorf("expires_at must be after publish_at"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, a)
+}
+
+// deleteVenueAnnouncementHandler godoc
+//
+//	@Summary		Delete a venue announcement
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID			path		int		true	"Venue ID"
+//	@Param			announcementID	path		int		true	"Announcement ID"
+//	@Success		204				{string}	string	"Deleted"
+//	@Failure		400				{object}	error	"Bad Request"
+//	@Failure		403				{object}	error	"Forbidden"
+//	@Failure		404				{object}	error	"Announcement not found"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/announcements/{announcementID} [delete]
+func (app *application) deleteVenueAnnouncementHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	id, err := readIDParam(r, "announcementID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid announcement ID"))
+		return
+	}
+
+	if err := app.store.VenueAnnouncements.Delete(r.Context(), venueID, id); err != nil {
+		if errors.Is(err, venueannouncements.ErrAnnouncementNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runNotifyVenueAnnouncements pushes announcements that have gone live since
+// the last run. Claiming marks them notified up front, so a failed push is
+// logged rather than retried: a late duplicate is worse than a missed one.
+func (app *application) runNotifyVenueAnnouncements(ctx context.Context) error {
+	pending, err := app.store.VenueAnnouncements.ClaimPendingNotifications(ctx, 50)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		if err := notifications.SendVenueAnnouncement(ctx, app.push, app.store, p.VenueID, p.ID, p.VenueName, p.Title); err != nil {
+			app.logger.Warnw("failed to push venue announcement", "announcement_id", p.ID, "venue_id", p.VenueID, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"khel/internal/domain/venuebenchmark"
+	"net/http"
+)
+
+type priceBenchmarkQuery struct {
+	RadiusKm int `query:"radius_km" validate:"min=1,max=25"`
+}
+
+// getPriceBenchmarkHandler godoc
+//
+//	@Summary		Compare my prices with nearby venues
+//	@Description	For each sport and time band (morning before 12:00, afternoon until 17:00, evening after) the venue has pricing in, shows its average hourly price next to the 25th percentile, median and 75th percentile of active venues within radius_km. Other venues are never named, and the percentiles stay null until at least min_venues of them price the same sport and band.
+//	@Tags			Venue-Owner-Earnings
+//	@Produce		json
+//	@Param			venueID		path		int	true	"Venue ID"
+//	@Param			radius_km	query		int	false	"Radius in kilometres (default: 5, max: 25)"
+//	@Success		200			{object}	venuebenchmark.Benchmark
+//	@Failure		400			{object}	ErrorResponse	"Bad Request"
+//	@Failure		403			{object}	ErrorResponse	"Forbidden"
+//	@Failure		500			{object}	ErrorResponse	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/owner/venues/{venueID}/price-benchmark [get]
+func (app *application) getPriceBenchmarkHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	q := priceBenchmarkQuery{RadiusKm: venuebenchmark.DefaultRadiusKm}
+	if err := readQuery(r, &q); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	bands, err := app.store.VenueBenchmark.Compare(r.Context(), venueID, q.RadiusKm)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, venuebenchmark.Benchmark{
+		VenueID:   venueID,
+		RadiusKm:  q.RadiusKm,
+		MinVenues: venuebenchmark.MinVenues,
+		Bands:     bands,
+	})
+}
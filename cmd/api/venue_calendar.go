@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"khel/internal/domain/venuecalendar"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getVenueCalendarHandler godoc
+//
+//	@Summary		Occupancy calendar
+//	@Description	A day by hour matrix of the venue's bookings from from to to (inclusive, YYYY-MM-DD, default the next 7 days, at most 31), in one call. Each hour says how many facilities are confirmed, pending or free, and a state: free while any facility can still be booked, pending when the rest only hold unanswered requests, confirmed when fully booked, closed outside pricing hours or under an emergency closure. With facility_id the calendar covers one facility, otherwise all active facilities. format=csv downloads it as a CSV file.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			venueID		path		int		true	"Venue ID"
+//	@Param			from		query		string	false	"First day, YYYY-MM-DD (default: today)"
+//	@Param			to			query		string	false	"Last day, YYYY-MM-DD (default: from + 6 days)"
+//	@Param			facility_id	query		int		false	"Facility ID"
+//	@Param			format		query		string	false	"Response format"	Enums(json,csv)	default(json)
+//	@Success		200			{object}	venuecalendar.Calendar
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Facility not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/calendar [get]
+func (app *application) getVenueCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil || venueID <= 0 {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venueID"))
+		return
+	}
+
+	q := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(q.Get("format")))
+	if format != "" && format != "json" && format != "csv" {
+		app.badRequestResponse(w, r, fmt.Errorf("format must be json or csv"))
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if s := strings.TrimSpace(q.Get("from")); s != "" {
+		if from, err = time.ParseInLocation("2006-01-02", s, loc); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("from must be YYYY-MM-DD"))
+			return
+		}
+	}
+	to := from.AddDate(0, 0, venuecalendar.DefaultDays-1)
+	if s := strings.TrimSpace(q.Get("to")); s != "" {
+		if to, err = time.ParseInLocation("2006-01-02", s, loc); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("to must be YYYY-MM-DD"))
+			return
+		}
+	}
+	if to.Before(from) {
+		app.badRequestResponse(w, r, fmt.Errorf("to must not be before from"))
+		return
+	}
+	if to.After(from.AddDate(0, 0, venuecalendar.MaxDays-1)) {
+		app.badRequestResponse(w, r, fmt.Errorf("the calendar covers at most %d days", venuecalendar.MaxDays))
+		return
+	}
+
+	var facilityID *int64
+	if s := strings.TrimSpace(q.Get("facility_id")); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid facility_id"))
+			return
+		}
+		facilityList, err := app.store.Facilities.ListByVenueID(ctx, venueID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		for _, f := range facilityList {
+			if f.ID == id {
+				facilityID = &id
+			}
+		}
+		if facilityID == nil {
+			app.notFoundResponse(w, r, fmt.Errorf("facility not found"))
+			return
+		}
+	}
+
+	hours, err := app.store.VenueCalendar.Hours(ctx, venueID, facilityID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	calendar := venuecalendar.Calendar{
+		VenueID:    venueID,
+		FacilityID: facilityID,
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		Days:       venuecalendar.Build(hours),
+	}
+
+	if format == "csv" {
+		app.writeCalendarCSV(w, calendar)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, calendar)
+}
+
+func (app *application) writeCalendarCSV(w http.ResponseWriter, c venuecalendar.Calendar) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="venue-%d-calendar-%s.csv"`, c.VenueID, c.From))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "day_of_week", "hour", "state", "confirmed", "pending", "free"})
+	for _, d := range c.Days {
+		for _, h := range d.Hours {
+			cw.Write([]string{
+				d.Date,
+				d.DayOfWeek,
+				fmt.Sprintf("%02d:00", h.Hour),
+				h.State,
+				strconv.Itoa(h.Confirmed),
+				strconv.Itoa(h.Pending),
+				strconv.Itoa(h.Free),
+			})
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		app.logger.Errorw("failed to write calendar csv", "venue_id", c.VenueID, "error", err)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"khel/internal/domain/venueforecast"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getVenueForecastHandler godoc
+//
+//	@Summary		Forecast next week's occupancy
+//	@Description	Projects occupancy (0-1) for each bookable hour of the next 7 days, starting tomorrow, from the same hour over the past weeks of confirmed and completed bookings. forecast is the moving average, seasonal_naive is the same hour last week, and lower/upper bound an 80% band. With facility_id the forecast covers one facility, otherwise all active facilities of the venue. format=csv downloads the slots as a CSV file.
+//	@Tags			Venue-Owner-Earnings
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			venueID		path		int		true	"Venue ID"
+//	@Param			facility_id	query		int		false	"Facility ID"
+//	@Param			weeks		query		int		false	"Weeks of history (default: 8, max: 26)"
+//	@Param			format		query		string	false	"Response format"	Enums(json,csv)	default(json)
+//	@Success		200			{object}	venueforecast.Forecast
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		404			{object}	error	"Facility not found"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/forecast [get]
+func (app *application) getVenueForecastHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	venueID, err := strconv.ParseInt(chi.URLParam(r, "venueID"), 10, 64)
+	if err != nil || venueID <= 0 {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venueID"))
+		return
+	}
+
+	q := r.URL.Query()
+	weeks := venueforecast.DefaultWeeks
+	if s := strings.TrimSpace(q.Get("weeks")); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > venueforecast.MaxWeeks {
+			app.badRequestResponse(w, r, fmt.Errorf("weeks must be between 1 and %d", venueforecast.MaxWeeks))
+			return
+		}
+		weeks = n
+	}
+
+	format := strings.ToLower(strings.TrimSpace(q.Get("format")))
+	if format != "" && format != "json" && format != "csv" {
+		app.badRequestResponse(w, r, fmt.Errorf("format must be json or csv"))
+		return
+	}
+
+	facilityList, err := app.store.Facilities.ListByVenueID(ctx, venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var facilityID *int64
+	capacity := 0
+	if s := strings.TrimSpace(q.Get("facility_id")); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid facility_id"))
+			return
+		}
+		for _, f := range facilityList {
+			if f.ID == id {
+				facilityID = &id
+				capacity = 1
+			}
+		}
+		if facilityID == nil {
+			app.notFoundResponse(w, r, fmt.Errorf("facility not found"))
+			return
+		}
+	} else {
+		for _, f := range facilityList {
+			if f.IsActive {
+				capacity++
+			}
+		}
+	}
+
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	start := today.AddDate(0, 0, 1)
+	// History is whole weeks up to the start of the forecast, so every
+	// weekday has the same number of observations.
+	from := start.AddDate(0, 0, -7*weeks)
+
+	obs, err := app.store.VenueForecast.Observations(ctx, venueID, facilityID, from, start)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	forecast := venueforecast.Forecast{
+		VenueID:    venueID,
+		FacilityID: facilityID,
+		Weeks:      weeks,
+		From:       start.Format("2006-01-02"),
+		To:         start.AddDate(0, 0, 6).Format("2006-01-02"),
+		Slots:      venueforecast.Build(obs, capacity, start),
+	}
+
+	if format == "csv" {
+		app.writeForecastCSV(w, forecast)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, forecast)
+}
+
+func (app *application) writeForecastCSV(w http.ResponseWriter, f venueforecast.Forecast) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="venue-%d-forecast-%s.csv"`, f.VenueID, f.From))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "day_of_week", "hour", "forecast", "lower", "upper", "seasonal_naive", "weeks_observed"})
+	for _, s := range f.Slots {
+		cw.Write([]string{
+			s.Date,
+			s.DayOfWeek,
+			fmt.Sprintf("%02d:00", s.Hour),
+			strconv.FormatFloat(s.Forecast, 'f', 3, 64),
+			strconv.FormatFloat(s.Lower, 'f', 3, 64),
+			strconv.FormatFloat(s.Upper, 'f', 3, 64),
+			strconv.FormatFloat(s.SeasonalNaive, 'f', 3, 64),
+			strconv.Itoa(s.WeeksObserved),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		app.logger.Errorw("failed to write forecast csv", "venue_id", f.VenueID, "error", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"khel/internal/domain/venues"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxMapZoom is the deepest zoom we cluster at; beyond it venues that share
+// a building would still be drawn as separate pins on top of each other.
+const maxMapZoom = 20
+
+// venueMapClustersHandler godoc
+//
+//	@Summary		Venue clusters for the map
+//	@Description	Groups the active venues inside bbox into clusters sized for the zoom level, so the map draws one pin with a count instead of every venue. A cluster of one includes venue_id and venue_name. Zoom in to a cluster's bounds to split it.
+//	@Tags			Venue
+//	@Produce		json
+//	@Param			bbox	query		string				true	"min_lon,min_lat,max_lon,max_lat"
+//	@Param			zoom	query		int					true	"Map zoom level (0-20)"
+//	@Param			sport	query		string				false	"Filter by sport type"
+//	@Success		200		{object}	map[string]any		"clusters"
+//	@Failure		400		{object}	error				"Bad Request"
+//	@Failure		500		{object}	error				"Internal Server Error"
+//	@Router			/venues/map-clusters [get]
+func (app *application) venueMapClustersHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	bbox, err := parseBBox(q.Get("bbox"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	zoom, err := strconv.Atoi(q.Get("zoom"))
+	if err != nil || zoom < 0 {
+		app.badRequestResponse(w, r, errors.New("zoom must be a whole number from 0 to 20"))
+		return
+	}
+	zoom = min(zoom, maxMapZoom)
+
+	clusters, err := app.store.Venues.MapClusters(r.Context(), venues.MapClusterFilter{
+		MinLon:   bbox[0],
+		MinLat:   bbox[1],
+		MaxLon:   bbox[2],
+		MaxLat:   bbox[3],
+		CellSize: venues.ClusterCellSize(zoom),
+		Sport:    nullString(strings.TrimSpace(q.Get("sport"))),
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"zoom":     zoom,
+		"clusters": clusters,
+	})
+}
+
+// parseBBox reads a "min_lon,min_lat,max_lon,max_lat" bounding box.
+func parseBBox(s string) ([4]float64, error) {
+	var bbox [4]float64
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return bbox, errors.New("bbox must be min_lon,min_lat,max_lon,max_lat")
+	}
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return bbox, errors.New("bbox must be min_lon,min_lat,max_lon,max_lat")
+		}
+		bbox[i] = v
+	}
+	if bbox[0] < -180 || bbox[2] > 180 || bbox[1] < -90 || bbox[3] > 90 ||
+		bbox[0] >= bbox[2] || bbox[1] >= bbox[3] {
+		return bbox, errors.New("bbox is out of range")
+	}
+	return bbox, nil
+}
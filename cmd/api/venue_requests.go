@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"khel/internal/audit"
 	"khel/internal/domain/venuerequest"
 	"khel/internal/domain/venues"
 
@@ -229,7 +230,8 @@ func (app *application) adminApproveVenueRequestHandler(w http.ResponseWriter, r
 		return
 	}
 
-	// 2) create real venue (no images at approval)
+	// 2) create real venue (no images at approval). The request was the
+	// review, so the owner can put it live once it has photos.
 	v := &venues.Venue{
 		OwnerID:     payload.OwnerID,
 		Name:        req.Name,
@@ -241,6 +243,7 @@ func (app *application) adminApproveVenueRequestHandler(w http.ResponseWriter, r
 		Sport:       req.Sport,
 		PhoneNumber: req.PhoneNumber,
 		ImageURLs:   []string{},
+		Status:      venues.VenueStatusRequested,
 	}
 
 	if err := app.store.Venues.Create(r.Context(), v); err != nil {
@@ -254,6 +257,9 @@ func (app *application) adminApproveVenueRequestHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, audit.EntityVenueRequest, audit.ActionApprove, requestID, req, v)
+	app.publishVenueChanged(r.Context(), v.ID)
+
 	if err := app.jsonResponse(w, http.StatusCreated, v); err != nil {
 		app.internalServerError(w, r, err)
 	}
@@ -313,6 +319,8 @@ func (app *application) adminRejectVenueRequestHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, audit.EntityVenueRequest, audit.ActionReject, requestID, req, payload)
+
 	if err := app.jsonResponse(w, http.StatusOK, map[string]string{"message": "request rejected"}); err != nil {
 		app.internalServerError(w, r, err)
 	}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/venuestaff"
+	"khel/internal/mailer"
+	"khel/internal/notifications"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+type venueRoleKey string
+
+const venueRoleCtx venueRoleKey = "venue_role"
+
+// requireVenueRole lets the request through only when the user has one of
+// the given roles at the {venueID} venue. The role is stored on the context
+// for handlers that need it.
+func (app *application) requireVenueRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			venueID, err := readIDParam(r, "venueID")
+			if err != nil || venueID <= 0 {
+				app.badRequestResponse(w, r, fmt.Errorf("invalid venueID"))
+				return
+			}
+
+			user := getUserFromContext(r)
+			role, err := app.store.VenueStaff.Role(r.Context(), venueID, user.ID)
+			if err != nil {
+				if errors.Is(err, venuestaff.ErrNoAccess) {
+					app.forbiddenResponse(w, r)
+					return
+				}
+				app.internalServerError(w, r, err)
+				return
+			}
+			if !slices.Contains(roles, role) {
+				app.forbiddenResponse(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), venueRoleCtx, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+type InviteVenueStaffPayload struct {
+	Email *string `json:"email,omitempty" validate:"omitempty,email"`
+	Phone *string `json:"phone,omitempty" validate:"omitempty,len=10,numeric"`
+	Role  string  `json:"role" validate:"required,oneof=manager staff"`
+}
+
+// listVenueStaffHandler godoc
+//
+//	@Summary		List a venue's staff
+//	@Description	Members first, then pending invitations.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{array}		venuestaff.Member
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/staff [get]
+func (app *application) listVenueStaffHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, _ := readIDParam(r, "venueID")
+	members, err := app.store.VenueStaff.List(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, members)
+}
+
+// inviteVenueStaffHandler godoc
+//
+//	@Summary		Invite someone to a venue's staff
+//	@Description	Owner only. Send either an email or a 10 digit phone number; whoever signs in with it can accept. Managers run pricing, facilities, inventory and bookings; staff handle bookings and games.
+//	@Tags			Venue-Owner
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int						true	"Venue ID"
+//	@Param			payload	body		InviteVenueStaffPayload	true	"Invitation"
+//	@Success		201		{object}	venuestaff.Member
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		409		{object}	error	"Already invited or already on staff"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/staff [post]
+func (app *application) inviteVenueStaffHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, _ := readIDParam(r, "venueID")
+
+	var payload InviteVenueStaffPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	payload.Email = trimmedOrNil(payload.Email)
+	payload.Phone = trimmedOrNil(payload.Phone)
+	if payload.Email != nil {
+		email := strings.ToLower(*payload.Email)
+		payload.Email = &email
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if (payload.Email == nil) == (payload.Phone == nil) {
+		app.badRequestResponse(w, r, errors.New("send either email or phone"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	m := &venuestaff.Member{
+		VenueID:      venueID,
+		Role:         payload.Role,
+		InvitedEmail: payload.Email,
+		InvitedPhone: payload.Phone,
+		InvitedBy:    &user.ID,
+	}
+	inviteeID, err := app.store.VenueStaff.Invite(r.Context(), m)
+	if err != nil {
+		switch {
+		case errors.Is(err, venuestaff.ErrAlreadyInvited), errors.Is(err, venuestaff.ErrAlreadyStaff):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, audit.EntityVenueStaff, audit.ActionCreate, m.ID, nil, m)
+	app.sendVenueStaffInvite(m, inviteeID, user.FirstName)
+
+	app.jsonResponse(w, http.StatusCreated, m)
+}
+
+// removeVenueStaffHandler godoc
+//
+//	@Summary		Remove a staff member
+//	@Description	Owner only. Removes a member or withdraws a pending invitation.
+//	@Tags			Venue-Owner
+//	@Param			venueID	path	int	true	"Venue ID"
+//	@Param			staffID	path	int	true	"Staff member ID"
+//	@Success		204		"No Content"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Staff member not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/staff/{staffID} [delete]
+func (app *application) removeVenueStaffHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, _ := readIDParam(r, "venueID")
+	staffID, err := readIDParam(r, "staffID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid staff ID"))
+		return
+	}
+
+	if err := app.store.VenueStaff.Remove(r.Context(), venueID, staffID); err != nil {
+		if errors.Is(err, venuestaff.ErrMemberNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.recordAudit(r, audit.EntityVenueStaff, audit.ActionDelete, staffID, nil, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listVenueInvitationsHandler godoc
+//
+//	@Summary		List my venue invitations
+//	@Description	Pending invitations sent to the current user's email or phone.
+//	@Tags			Users
+//	@Produce		json
+//	@Success		200	{array}		venuestaff.Invitation
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/venue-invitations [get]
+func (app *application) listVenueInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	list, err := app.store.VenueStaff.Invitations(r.Context(), user.Email, user.Phone)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, list)
+}
+
+// acceptVenueInvitationHandler godoc
+//
+//	@Summary		Accept a venue invitation
+//	@Tags			Users
+//	@Produce		json
+//	@Param			invitationID	path		int	true	"Invitation ID"
+//	@Success		200				{object}	venuestaff.Membership
+//	@Failure		404				{object}	error	"Invitation not found"
+//	@Failure		409				{object}	error	"Already on staff"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/venue-invitations/{invitationID}/accept [post]
+func (app *application) acceptVenueInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "invitationID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid invitation ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	m, err := app.store.VenueStaff.Accept(r.Context(), id, user.ID, user.Email, user.Phone)
+	if err != nil {
+		switch {
+		case errors.Is(err, venuestaff.ErrInvitationNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, venuestaff.ErrAlreadyStaff):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.recordAudit(r, audit.EntityVenueStaff, audit.ActionApprove, id, nil, m)
+
+	app.jsonResponse(w, http.StatusOK, m)
+}
+
+// declineVenueInvitationHandler godoc
+//
+//	@Summary		Decline a venue invitation
+//	@Tags			Users
+//	@Param			invitationID	path	int	true	"Invitation ID"
+//	@Success		204				"No Content"
+//	@Failure		404				{object}	error	"Invitation not found"
+//	@Failure		500				{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/venue-invitations/{invitationID} [delete]
+func (app *application) declineVenueInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readIDParam(r, "invitationID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid invitation ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.VenueStaff.Decline(r.Context(), id, user.Email, user.Phone); err != nil {
+		if errors.Is(err, venuestaff.ErrInvitationNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendVenueStaffInvite pushes the invitation to the invitee if they have an
+// account, and emails it when it was sent to an email. Best effort.
+func (app *application) sendVenueStaffInvite(m *venuestaff.Member, inviteeID *int64, inviterName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	venue, err := app.store.Venues.GetVenueByID(ctx, m.VenueID)
+	if err != nil {
+		app.logger.Errorw("failed to load venue for staff invitation", "venue_id", m.VenueID, "error", err)
+		return
+	}
+
+	username, locale := "there", mailer.DefaultLocale
+	if inviteeID != nil {
+		userID := *inviteeID
+		notifications.CallAsync(app.logger, func(ctx context.Context) error {
+			return notifications.SendVenueStaffInvite(ctx, app.push, app.store, userID, m.VenueID, m.ID, venue.Name, m.Role)
+		}, "venue staff invite push")
+
+		if invitee, err := app.store.Users.GetByID(ctx, userID); err == nil {
+			username = invitee.FirstName
+		}
+		locale = app.userLocale(ctx, userID)
+	}
+
+	if m.InvitedEmail == nil {
+		return
+	}
+	data := struct {
+		Username    string
+		InviterName string
+		VenueName   string
+		Role        string
+		InviteURL   string
+	}{
+		Username:    username,
+		InviterName: inviterName,
+		VenueName:   venue.Name,
+		Role:        m.Role,
+		InviteURL:   app.config.frontendURL + "/venue-invitations",
+	}
+	if err := app.enqueueEmail(ctx, mailer.VenueStaffInviteTemplate, locale, username, *m.InvitedEmail, data); err != nil {
+		app.logger.Errorw("failed to enqueue venue staff invitation email", "invitation_id", m.ID, "error", err)
+	}
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"khel/internal/audit"
+	"khel/internal/domain/venueverification"
+	"khel/internal/media"
+	"khel/internal/notifications"
+	"khel/internal/params"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	maxVenueDocumentBytes = 10 << 20 // 10MB
+	// venueDocumentLinkTTL is how long the signed links to ownership
+	// documents work.
+	venueDocumentLinkTTL = 15 * time.Minute
+)
+
+// venueDocumentTypes are the sniffed content types accepted as documents.
+var venueDocumentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+type ReviewVenuePayload struct {
+	Decision string  `json:"decision" validate:"required,oneof=approve reject"`
+	Reason   *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
+
+// getVenueVerificationHandler godoc
+//
+//	@Summary		Get a venue's verification
+//	@Description	Where the venue stands in review, the last rejection reason and the uploaded documents with links that work for 15 minutes.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	venueverification.Verification
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/verification [get]
+func (app *application) getVenueVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeVenueVerification(w, r)
+}
+
+// uploadVenueDocumentHandler godoc
+//
+//	@Summary		Upload an ownership document
+//	@Description	Adds a PDF, JPEG or PNG (max 10MB) proving the owner runs the venue, while it is pending review or rejected. The file is stored privately.
+//	@Tags			Venue-Owner
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			venueID		path		int		true	"Venue ID"
+//	@Param			kind		formData	string	true	"ownership, lease, business_registration, tax_registration or other"
+//	@Param			document	formData	file	true	"The document"
+//	@Success		201			{object}	venueverification.Document
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		409			{object}	error	"Venue is not under review"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/verification/documents [post]
+func (app *application) uploadVenueDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxVenueDocumentBytes+1<<20)
+	if err := r.ParseMultipartForm(maxVenueDocumentBytes); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid multipart form: %w", err))
+		return
+	}
+	kind := strings.TrimSpace(r.FormValue("kind"))
+	if !slices.Contains(venueverification.DocumentKinds, kind) {
+		app.badRequestResponse(w, r, fmt.Errorf("kind must be one of %s", strings.Join(venueverification.DocumentKinds, ", ")))
+		return
+	}
+	file, header, err := r.FormFile("document")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("document file is required"))
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxVenueDocumentBytes {
+		app.badRequestResponse(w, r, errors.New("document must be 10MB or smaller"))
+		return
+	}
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	contentType := http.DetectContentType(head[:n])
+	if !slices.Contains(venueDocumentTypes, contentType) {
+		app.badRequestResponse(w, r, errors.New("document must be a PDF, JPEG or PNG"))
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Checked before uploading so a settled venue doesn't leave a stray file;
+	// AddDocument checks again.
+	v, err := app.store.VenueVerification.Get(r.Context(), venueID)
+	if err != nil {
+		app.venueVerificationError(w, r, err)
+		return
+	}
+	if v.Status != "pending_review" && v.Status != "rejected" {
+		app.conflictResponse(w, r, venueverification.ErrNotUnderReview)
+		return
+	}
+
+	user := getUserFromContext(r)
+	url, err := app.upload(file, media.UploadOptions{
+		Folder:  attachmentFolder("venueDocuments"),
+		Name:    fmt.Sprintf("venue_%d_%s_%d", venueID, kind, time.Now().UnixNano()),
+		Private: true,
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	d := &venueverification.Document{
+		VenueID:    venueID,
+		Kind:       kind,
+		URL:        url,
+		FileName:   header.Filename,
+		UploadedBy: &user.ID,
+	}
+	if err := app.store.VenueVerification.AddDocument(r.Context(), d); err != nil {
+		app.deleteImagesAsync([]string{url})
+		app.venueVerificationError(w, r, err)
+		return
+	}
+	docs := []venueverification.Document{*d}
+	app.signVenueDocuments(r.Context(), docs)
+
+	app.jsonResponse(w, http.StatusCreated, docs[0])
+}
+
+// deleteVenueDocumentHandler godoc
+//
+//	@Summary		Delete an ownership document
+//	@Description	Only while the venue is pending review or rejected.
+//	@Tags			Venue-Owner
+//	@Param			venueID		path	int	true	"Venue ID"
+//	@Param			documentID	path	int	true	"Document ID"
+//	@Success		204
+//	@Failure		404	{object}	error	"Document not found"
+//	@Failure		409	{object}	error	"Venue is not under review"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/verification/documents/{documentID} [delete]
+func (app *application) deleteVenueDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+	documentID, err := readIDParam(r, "documentID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid document ID"))
+		return
+	}
+
+	url, err := app.store.VenueVerification.DeleteDocument(r.Context(), venueID, documentID)
+	if err != nil {
+		app.venueVerificationError(w, r, err)
+		return
+	}
+	app.deleteImagesAsync([]string{url})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resubmitVenueVerificationHandler godoc
+//
+//	@Summary		Resubmit a rejected venue
+//	@Description	Puts a rejected venue back in the review queue once its documents are fixed.
+//	@Tags			Venue-Owner
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	venueverification.Verification
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		409		{object}	error	"Venue was not rejected, or has no documents"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/venues/{venueID}/verification/resubmit [post]
+func (app *application) resubmitVenueVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	if err := app.store.VenueVerification.Resubmit(r.Context(), venueID); err != nil {
+		app.venueVerificationError(w, r, err)
+		return
+	}
+	app.recordAudit(r, audit.EntityVenue, audit.ActionStatus, venueID, nil, map[string]string{"status": "pending_review"})
+
+	app.writeVenueVerification(w, r)
+}
+
+// adminVenueReviewQueueHandler godoc
+//
+//	@Summary		List venues pending review
+//	@Description	Oldest first, with how many documents each has.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"venues + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/venues/review-queue [get]
+func (app *application) adminVenueReviewQueueHandler(w http.ResponseWriter, r *http.Request) {
+	pagination := params.ParsePagination(r.URL.Query())
+	list, total, err := app.store.VenueVerification.Queue(r.Context(), pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"venues":     list,
+		"pagination": pagination,
+	})
+}
+
+// adminGetVenueReviewHandler godoc
+//
+//	@Summary		Get a venue's verification (admin)
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			venueID	path		int	true	"Venue ID"
+//	@Success		200		{object}	venueverification.Verification
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/venues/{venueID}/review [get]
+func (app *application) adminGetVenueReviewHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeVenueVerification(w, r)
+}
+
+// adminReviewVenueHandler godoc
+//
+//	@Summary		Approve or reject a venue
+//	@Description	Approving makes the venue active so it shows in listings. Rejecting needs a reason, which the owner sees; they can fix their documents and resubmit.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			venueID	path		int					true	"Venue ID"
+//	@Param			payload	body		ReviewVenuePayload	true	"Decision"
+//	@Success		200		{object}	venueverification.Verification
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Venue not found"
+//	@Failure		409		{object}	error	"Venue is not pending review"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/venues/{venueID}/review [post]
+func (app *application) adminReviewVenueHandler(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	var payload ReviewVenuePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.Reason != nil {
+		reason := strings.TrimSpace(*payload.Reason)
+		payload.Reason = &reason
+		if reason == "" {
+			payload.Reason = nil
+		}
+	}
+	decision := venueverification.Decision(payload.Decision)
+	if decision == venueverification.DecisionReject && payload.Reason == nil {
+		app.badRequestResponse(w, r, errors.New("a reason is required to reject a venue"))
+		return
+	}
+
+	admin := getUserFromContext(r)
+	if err := app.store.VenueVerification.Review(r.Context(), venueID, admin.ID, decision, payload.Reason); err != nil {
+		app.venueVerificationError(w, r, err)
+		return
+	}
+
+	v, err := app.store.VenueVerification.Get(r.Context(), venueID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	action, title, body := audit.ActionApprove, "Venue approved", fmt.Sprintf("%s is now live on Khel.", v.VenueName)
+	if decision == venueverification.DecisionReject {
+		action, title = audit.ActionReject, "Venue not approved"
+		body = fmt.Sprintf("%s wasn't approved: %s", v.VenueName, *payload.Reason)
+	}
+	app.recordAudit(r, audit.EntityVenue, action, venueID, nil, map[string]any{"status": v.Status, "reason": v.Reason})
+	app.publishVenueChanged(r.Context(), venueID)
+
+	notifications.CallAsync(app.logger, func(ctx context.Context) error {
+		return notifications.SendVenueReviewDecision(ctx, app.push, app.store, v.OwnerID, venueID, title, body)
+	}, "venue review push")
+
+	app.signVenueDocuments(r.Context(), v.Documents)
+	app.jsonResponse(w, http.StatusOK, v)
+}
+
+// writeVenueVerification responds with the verification of the venue in
+// the URL, its documents signed.
+func (app *application) writeVenueVerification(w http.ResponseWriter, r *http.Request) {
+	venueID, err := readIDParam(r, "venueID")
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid venue ID"))
+		return
+	}
+
+	v, err := app.store.VenueVerification.Get(r.Context(), venueID)
+	if err != nil {
+		app.venueVerificationError(w, r, err)
+		return
+	}
+	app.signVenueDocuments(r.Context(), v.Documents)
+
+	app.jsonResponse(w, http.StatusOK, v)
+}
+
+// signVenueDocuments fills in each document's DownloadURL. A document that
+// can't be signed is left without one rather than failing the response.
+func (app *application) signVenueDocuments(ctx context.Context, docs []venueverification.Document) {
+	for i, d := range docs {
+		signed, err := app.media.SignedURL(ctx, d.URL, venueDocumentLinkTTL)
+		if err != nil {
+			app.logger.Warnw("failed to sign venue document", "document_id", d.ID, "error", err)
+			continue
+		}
+		docs[i].DownloadURL = signed
+	}
+}
+
+func (app *application) venueVerificationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, venueverification.ErrVenueNotFound),
+		errors.Is(err, venueverification.ErrDocumentNotFound):
+		app.notFoundResponse(w, r, err)
+	case errors.Is(err, venueverification.ErrNotUnderReview),
+		errors.Is(err, venueverification.ErrNotPendingReview),
+		errors.Is(err, venueverification.ErrNotRejected),
+		errors.Is(err, venueverification.ErrNoDocuments):
+		app.conflictResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
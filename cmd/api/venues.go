@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"khel/internal/audit"
+	"khel/internal/domain/venueannouncements"
 	"khel/internal/domain/venues"
+	"khel/internal/domain/venuestaff"
 	"mime/multipart"
 	"net/http"
 	"strconv"
@@ -15,14 +18,15 @@ import (
 )
 
 type isOwnerResponse struct {
-	IsOwner  bool    `json:"is_owner"`
-	VenueIDs []int64 `json:"venue_ids,omitempty"`
+	IsOwner     bool                    `json:"is_owner"`
+	VenueIDs    []int64                 `json:"venue_ids,omitempty"`
+	StaffVenues []venuestaff.Membership `json:"staff_venues,omitempty"`
 }
 
 // IsVenueOwner godoc
 //
 //	@Summary		Check if user is a venue owner
-//	@Description	Determines whether the authenticated user owns at least one venue
+//	@Description	Determines whether the authenticated user owns at least one venue, and lists the venues they are staff at
 //	@Tags			Venue-Owner
 //	@Produce		json
 //	@Success		200	{object}	isOwnerResponse	"Ownership check result"
@@ -39,9 +43,16 @@ func (app *application) isVenueOwnerHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	staffVenues, err := app.store.VenueStaff.Memberships(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	resp := isOwnerResponse{
-		IsOwner:  len(venueIDs) > 0,
-		VenueIDs: venueIDs,
+		IsOwner:     len(venueIDs) > 0,
+		VenueIDs:    venueIDs,
+		StaffVenues: staffVenues,
 	}
 
 	if err := app.jsonResponse(w, http.StatusOK, resp); err != nil {
@@ -64,7 +75,7 @@ type CreateVenuePayload struct {
 // DeleteVenuePhoto godoc
 //
 //	@Summary		Delete a venue photo
-//	@Description	Deletes a specific venue photo from Cloudinary and removes it from the database.
+//	@Description	Deletes a specific venue photo from storage and removes it from the database.
 //	@Tags			Venue-Owner
 //	@Accept			json
 //	@Produce		json
@@ -92,13 +103,6 @@ func (app *application) deleteVenuePhotoHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Delete the photo from Cloudinary
-	if err := app.deletePhotoFromCloudinary(photoURL); err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-
-	fmt.Println("Deleted photo from the cloudinary")
 	// Remove the photo URL from the database
 	ctx := r.Context()
 	if err := app.store.Venues.RemovePhotoURL(ctx, venueID, photoURL); err != nil {
@@ -106,6 +110,9 @@ func (app *application) deleteVenuePhotoHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// Delete the stored photo
+	app.enqueuePhotoDelete(photoURL)
+
 	// Respond with success
 	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "photo deleted successfully"})
 }
@@ -113,7 +120,7 @@ func (app *application) deleteVenuePhotoHandler(w http.ResponseWriter, r *http.R
 // UploadVenuePhoto godoc
 //
 //	@Summary		Upload a new photo for a venue
-//	@Description	Uploads a new venue photo to Cloudinary and adds the new photo URL to the venue record.
+//	@Description	Uploads a new venue photo and adds the new photo URL to the venue record.
 //	@Tags			Venue-Owner
 //	@Accept			multipart/form-data
 //	@Produce		json
@@ -164,9 +171,9 @@ func (app *application) uploadVenuePhotoHandler(w http.ResponseWriter, r *http.R
 	}
 	defer file.Close()
 
-	// Generate a custom Cloudinary public ID using the venue ID and image number.
+	// Generate a custom public ID using the venue ID and image number.
 	publicID := fmt.Sprintf("venue_%d_image_%d", venueID, time.Now().UnixNano())
-	newPhotoURL, err := app.uploadToCloudinaryWithID(file, publicID, "")
+	newPhotoURL, err := app.uploadImageWithID(file, publicID, "")
 	if err != nil {
 		return
 	}
@@ -175,6 +182,7 @@ func (app *application) uploadVenuePhotoHandler(w http.ResponseWriter, r *http.R
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.enqueueImageVariants()
 
 	// Respond with the new photo URL
 	app.jsonResponse(w, http.StatusOK, map[string]string{"photo_url": newPhotoURL})
@@ -221,6 +229,7 @@ func (app *application) updateVenueInfo(w http.ResponseWriter, r *http.Request)
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.publishVenueChanged(r.Context(), venueID)
 
 	// Respond with success
 	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "venue updated successfully"})
@@ -250,6 +259,11 @@ type VenueListResponse struct {
 //	@Param			lat			query	number	false	"Latitude for location filter"
 //	@Param			lng			query	number	false	"Longitude for location filter"
 //	@Param			distance	query	number	false	"Distance in meters from location"
+//	@Param			surface		query	string	false	"Only venues with a court of this surface type, e.g. turf or wooden"
+//	@Param			covered		query	bool	false	"Only venues with a covered (true) or open (false) court"
+//	@Param			wheelchair	query	bool	false	"Only venues with wheelchair access"
+//	@Param			accessible_toilets	query	bool	false	"Only venues with accessible toilets"
+//	@Param			accessible_parking	query	bool	false	"Only venues with accessible parking"
 //	@Param			page		query	int		false	"Page number"		default(1)
 //	@Param			limit		query	int		false	"Items per page"	default(7)
 //	@Success		200			{array}	VenueListResponse
@@ -277,9 +291,32 @@ func (app *application) listVenuesHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	filter := venues.VenueFilter{
-		Sport: nullString(q.Get("sport")),
-		Page:  page,
-		Limit: limit,
+		Sport:       nullString(q.Get("sport")),
+		SurfaceType: nullString(strings.TrimSpace(q.Get("surface"))),
+		Page:        page,
+		Limit:       limit,
+	}
+	if covered := q.Get("covered"); covered != "" {
+		parsed, err := strconv.ParseBool(covered)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("covered must be true or false"))
+			return
+		}
+		filter.Covered = &parsed
+	}
+	for param, dst := range map[string]*bool{
+		"wheelchair":         &filter.WheelchairAccess,
+		"accessible_toilets": &filter.AccessibleToilets,
+		"accessible_parking": &filter.AccessibleParking,
+	} {
+		if v := q.Get(param); v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				app.badRequestResponse(w, r, fmt.Errorf("%s must be true or false", param))
+				return
+			}
+			*dst = parsed
+		}
 	}
 
 	// Parse location filter
@@ -348,7 +385,7 @@ func nullString(s string) *string {
 
 // CreateAndUploadVenue implements the SAGA pattern.
 // 1. Create a venue record in the database (without images).
-// 2. Upload images externally to Cloudinary using custom public IDs.
+// 2. Upload images to media storage using custom public IDs.
 // 3. Update the venue record with the returned image URLs.
 // If an error occurs at any external step, the venue is deleted to ensure consistency.
 func (app *application) CreateAndUploadVenue(ctx context.Context, venue *venues.Venue, files []*multipart.FileHeader, w http.ResponseWriter, r *http.Request) error {
@@ -376,6 +413,8 @@ func (app *application) CreateAndUploadVenue(ctx context.Context, venue *venues.
 
 	// Optionally update the venue struct with URLs.
 	venue.ImageURLs = imageUrls
+	app.publishVenueChanged(ctx, venue.ID)
+	app.enqueueImageVariants()
 	return nil
 }
 
@@ -389,7 +428,7 @@ func (app *application) CreateAndUploadVenue(ctx context.Context, venue *venues.
 // CreateVenue godoc
 //
 //	@Summary		Register a venue in our system
-//	@Description	Register a new venue with details such as name, address, location, and amenities.
+//	@Description	Register a new venue with details such as name, address, location, and amenities. It starts in pending_review and stays out of listings until a superadmin approves its verification documents.
 //	@Tags			Venue-Owner
 //	@Accept			multipart/form-data
 //	@Produce		json
@@ -412,9 +451,8 @@ func (app *application) createVenueHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	//for debug only
 	for _, fh := range files {
-		fmt.Printf("📷 got file: %q (%d bytes)\n", fh.Filename, fh.Size)
+		app.requestLogger(r).Debugw("venue image received", "filename", fh.Filename, "bytes", fh.Size)
 	}
 
 	user := getUserFromContext(r)
@@ -463,6 +501,8 @@ type VenueDetailResponse struct {
 	AverageRating  float64   `json:"average_rating"`
 	UpcomingGames  int       `json:"upcoming_games"`
 	CompletedGames int       `json:"completed_games"`
+
+	Announcements []venueannouncements.Announcement `json:"announcements"`
 }
 
 // getVenueDetailHandler handles the GET /venue/{id} endpoint.
@@ -485,8 +525,6 @@ func (app *application) getVenueDetailHandler(w http.ResponseWriter, r *http.Req
 		app.badRequestResponse(w, r, fmt.Errorf("invalid venue id"))
 		return
 	}
-	fmt.Printf("the venue id is %v", venueID)
-
 	// Query the venue detail using the repository method.
 	vd, err := app.store.Venues.GetVenueDetail(r.Context(), venueID)
 	if err != nil {
@@ -515,6 +553,13 @@ func (app *application) getVenueDetailHandler(w http.ResponseWriter, r *http.Req
 		CompletedGames: vd.CompletedGames,
 	}
 
+	// Announcements are a nice-to-have on the detail page; don't fail the venue over them.
+	resp.Announcements, err = app.store.VenueAnnouncements.ListActive(r.Context(), venueID)
+	if err != nil {
+		app.logger.Warnw("failed to load venue announcements", "venue_id", venueID, "error", err)
+		resp.Announcements = []venueannouncements.Announcement{}
+	}
+
 	// Send the response as JSON.
 	if err := app.jsonResponse(w, http.StatusOK, resp); err != nil {
 		app.internalServerError(w, r, err)
@@ -557,21 +602,16 @@ func (app *application) deleteVenueHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// 2) Delete each from Cloudinary
-	for _, url := range urls {
-		fmt.Println(url)
-		if err := app.deletePhotoFromCloudinary(url); err != nil {
-			app.logger.Warnw("failed to delete image from Cloudinary", "url", url, "err", err)
-			app.internalServerError(w, r, err)
-			return
-
-		}
-	}
-
 	if err := app.store.Venues.Delete(r.Context(), venueID); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.publishVenueChanged(r.Context(), venueID)
+
+	// 2) Delete each from Cloudinary once the venue is gone
+	for _, url := range urls {
+		app.enqueuePhotoDelete(url)
+	}
 
 	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "Venue deleted successfully"})
 }
@@ -720,7 +760,7 @@ func (app *application) fullTextSearchVenuesHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	venues, err := app.store.Venues.FullTextSearchVenues(ctx, q)
+	venues, err := app.search.SearchVenues(ctx, q)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -740,7 +780,7 @@ type updateVenueStatusPayload struct {
 // UpdateVenueStatusOwner godoc
 //
 //	@Summary		Owner updates venue status
-//	@Description	Allows venue owner to change status only between requested and active.
+//	@Description	Allows venue owner to change status only between requested and active. Venues pending review or rejected can't be changed here.
 //	@Tags			Venue-Owner
 //	@Accept			json
 //	@Produce		json
@@ -810,6 +850,9 @@ func (app *application) updateVenueStatusOwnerHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	app.recordAudit(r, audit.EntityVenue, audit.ActionStatus, venueID, nil, map[string]string{"status": next})
+	app.publishVenueChanged(ctx, venueID)
+
 	_ = app.jsonResponse(w, http.StatusOK, map[string]string{
 		"message": "venue status updated",
 	})
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"khel/internal/domain/games"
+	"khel/internal/params"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	deprecationHeader = "Deprecation"
+	sunsetHeader      = "Sunset"
+)
+
+// deprecation describes a v1 endpoint that has a replacement. It is sent on
+// every response as the Deprecation (RFC 9745), Sunset (RFC 8594) and Link
+// headers, so clients can warn before the endpoint goes away.
+type deprecation struct {
+	since     time.Time // when the replacement became available
+	sunset    time.Time // when the endpoint may be removed
+	successor string    // path of the replacement, e.g. /v2/games
+}
+
+var v1GamesListDeprecation = deprecation{
+	since:     time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC),
+	sunset:    time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+	successor: "/v2/games",
+}
+
+// deprecated marks the routes it wraps with d. The endpoint itself keeps
+// working unchanged.
+func deprecated(d deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set(deprecationHeader, fmt.Sprintf("@%d", d.since.Unix()))
+			h.Set(sunsetHeader, d.sunset.Format(http.TimeFormat))
+			h.Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, d.successor))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// mountV2 holds the endpoints whose response shape changed in v2. Everything
+// else is only served under /v1, which stays the default for the mobile app.
+func (app *application) mountV2(r chi.Router) {
+	r.With(app.optionalAuth).Get("/games", app.getGamesV2Handler)
+}
+
+// gamesPage is the v2 list envelope: the page of games under data, with the
+// paging beside it instead of leaving clients to guess whether there is more.
+type gamesPage struct {
+	Data       []games.GameSummary `json:"data"`
+	Pagination params.Pagination   `json:"pagination"`
+}
+
+// getGamesV2Handler serves GET /v2/games. It takes the same filters as
+// /v1/games/get-games but answers with a gamesPage; GeoJSON stays on v1.
+// The swagger spec only covers /v1, so the route is documented here.
+func (app *application) getGamesV2Handler(w http.ResponseWriter, r *http.Request) {
+	fq := defaultGameFilter()
+	if err := readQuery(r, &fq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if user := getUserFromContext(r); user != nil {
+		fq.ViewerID = user.ID
+	}
+
+	gameList, total, err := app.store.Games.GetGames(r.Context(), fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.markShortlisted(r, gameList); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	p := params.LimitOffset{Limit: fq.Limit, Offset: fq.Offset}.Pagination()
+	p.ComputeMeta(total)
+	if gameList == nil {
+		gameList = []games.GameSummary{}
+	}
+
+	if err := writeJSON(w, http.StatusOK, gamesPage{Data: gameList, Pagination: p}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
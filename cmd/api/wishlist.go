@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/domain/products"
+	"khel/internal/jobs"
+	"khel/internal/notifications"
+	"khel/internal/params"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type checkWishlistPricesPayload struct {
+	// ProductID is 0 when any product may have changed price.
+	ProductID int64 `json:"product_id"`
+}
+
+type AddToWishlistPayload struct {
+	ProductID int64  `json:"product_id" validate:"required,min=1"`
+	VariantID *int64 `json:"variant_id" validate:"omitempty,min=1"`
+}
+
+// addToWishlistHandler godoc
+//
+//	@Summary		Save a product to my wishlist
+//	@Description	Saves a published product, optionally pinned to one of its variants (say, a size). Saving it again changes the pin. When its price drops below what it cost when saved, or at the last drop, a push says so.
+//	@Tags			Store-Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		AddToWishlistPayload	true	"Product and optional variant"
+//	@Success		201		{object}	map[string]string		"Product saved"
+//	@Failure		400		{object}	error					"Bad Request"
+//	@Failure		404		{object}	error					"Product or variant not found"
+//	@Failure		500		{object}	error					"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/wishlist [post]
+func (app *application) addToWishlistHandler(w http.ResponseWriter, r *http.Request) {
+	var payload AddToWishlistPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.Products.AddToWishlist(r.Context(), user.ID, payload.ProductID, payload.VariantID); err != nil {
+		if errors.Is(err, products.ErrProductNotFound) || errors.Is(err, products.ErrVariantNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, map[string]string{"message": "product added to wishlist"})
+}
+
+// removeFromWishlistHandler godoc
+//
+//	@Summary		Remove a product from my wishlist
+//	@Tags			Store-Wishlist
+//	@Produce		json
+//	@Param			productID	path		int					true	"Product ID"
+//	@Success		200			{object}	map[string]string	"Product removed"
+//	@Failure		400			{object}	error				"Bad Request"
+//	@Failure		500			{object}	error				"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/wishlist/{productID} [delete]
+func (app *application) removeFromWishlistHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := readIDParam(r, "productID")
+	if err != nil || productID < 1 {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid product ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.Products.RemoveFromWishlist(r.Context(), user.ID, productID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "product removed from wishlist"})
+}
+
+// listWishlistHandler godoc
+//
+//	@Summary		List my wishlist
+//	@Description	Saved products, newest first, with their current price (the pinned variant's, or the cheapest) next to the one last seen. available is false once a product is unpublished, removed or has nothing on sale.
+//	@Tags			Store-Wishlist
+//	@Produce		json
+//	@Param			page	query		int				false	"Page number (default: 1)"
+//	@Param			limit	query		int				false	"Items per page (default: 15, max: 30)"
+//	@Success		200		{object}	map[string]any	"items + pagination metadata"
+//	@Failure		500		{object}	error			"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/store/wishlist [get]
+func (app *application) listWishlistHandler(w http.ResponseWriter, r *http.Request) {
+	pagination := params.ParsePagination(r.URL.Query())
+
+	user := getUserFromContext(r)
+	items, total, err := app.store.Products.ListWishlist(r.Context(), user.ID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pagination.ComputeMeta(total)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{
+		"items":      items,
+		"pagination": pagination,
+	})
+}
+
+// markWishlisted sets the wishlisted flag on cards for a signed-in shopper
+// and leaves it out for everyone else.
+func (app *application) markWishlisted(r *http.Request, cards []*products.ProductCard) error {
+	user := getUserFromContext(r)
+	if user == nil || len(cards) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(cards))
+	for i, c := range cards {
+		ids[i] = c.ID
+	}
+	saved, err := app.store.Products.WishlistedProductIDs(r.Context(), user.ID, ids)
+	if err != nil {
+		return err
+	}
+	for _, c := range cards {
+		_, ok := saved[c.ID]
+		c.Wishlisted = &ok
+	}
+	return nil
+}
+
+// enqueueWishlistPriceCheck queues a look for wishlist price drops after a
+// product's prices changed; 0 checks every product. One pending check per
+// product is enough.
+func (app *application) enqueueWishlistPriceCheck(productID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := app.store.Jobs.Enqueue(ctx, jobCheckWishlistPrices, checkWishlistPricesPayload{ProductID: productID}, jobs.EnqueueOptions{
+		UniqueKey: jobCheckWishlistPrices + ":" + strconv.FormatInt(productID, 10),
+	})
+	if err != nil {
+		app.logger.Errorw("failed to enqueue wishlist price check", "product_id", productID, "error", err)
+	}
+}
+
+// runCheckWishlistPrices pushes every wishlist price drop on the product.
+// Drops are claimed before sending, so a failed push is not retried.
+func (app *application) runCheckWishlistPrices(ctx context.Context, p checkWishlistPricesPayload) error {
+	drops, err := app.store.Products.ClaimWishlistPriceDrops(ctx, p.ProductID)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range drops {
+		if err := notifications.SendWishlistPriceDrop(ctx, app.push, app.store, d); err != nil {
+			app.logger.Warnw("failed to push wishlist price drop", "user_id", d.UserID, "product_id", d.ProductID, "error", err)
+		}
+	}
+	jobs.SetRowsAffected(ctx, int64(len(drops)))
+	return nil
+}
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_products_deleted_at;
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_brands_deleted_at;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE categories
+DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE brands
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,26 @@
+-- Soft delete for the catalog.
+-- Rows are hidden by setting deleted_at instead of being removed, so that
+-- order history, carts and featured items keep pointing at a real row.
+-- A background job hard-deletes rows that stayed in the trash long enough.
+
+ALTER TABLE brands
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE categories
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Trash listings and the purge job only ever look at deleted rows.
+CREATE INDEX IF NOT EXISTS idx_brands_deleted_at
+ON brands (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at
+ON categories (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at
+ON products (deleted_at)
+WHERE deleted_at IS NOT NULL;
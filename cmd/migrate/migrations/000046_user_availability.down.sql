@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_availability_user_day;
+DROP TABLE IF EXISTS user_availability;
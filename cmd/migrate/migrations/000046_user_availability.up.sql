@@ -0,0 +1,17 @@
+-- Weekly availability windows a user declares on their profile.
+-- Times are local Nepal time (Asia/Kathmandu), same as venue_pricing.
+-- A user with no rows is treated as "always available".
+CREATE TABLE IF NOT EXISTS user_availability (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day_of_week VARCHAR(10) NOT NULL,  -- e.g. 'monday', 'tuesday', …, 'sunday'
+    start_time TIME NOT NULL,          -- e.g. 18:00:00
+    end_time TIME NOT NULL,            -- e.g. 21:00:00
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT user_availability_valid_day CHECK (day_of_week IN ('sunday', 'monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday')),
+    CONSTRAINT user_availability_valid_range CHECK (start_time < end_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_availability_user_day
+ON user_availability (user_id, day_of_week);
@@ -0,0 +1,28 @@
+-- Append-only trail of admin mutations: who changed what, from where.
+-- before_data / after_data hold JSON snapshots of the entity (NULL on create / delete).
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+
+    entity TEXT NOT NULL,      -- e.g. 'brand', 'category', 'product', 'ad', 'venue'
+    entity_id TEXT,            -- TEXT so composite or non-numeric keys fit too
+    action TEXT NOT NULL,      -- e.g. 'create', 'update', 'delete', 'restore'
+
+    before_data JSONB,
+    after_data JSONB,
+
+    ip TEXT,
+    user_agent TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at
+ON audit_logs (created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity
+ON audit_logs (entity, entity_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor
+ON audit_logs (actor_id, created_at DESC);
@@ -0,0 +1,10 @@
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_games_visibility_check;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS games_visibility;
+
+DROP INDEX IF EXISTS idx_friendships_requester_status;
+DROP INDEX IF EXISTS idx_friendships_addressee_status;
+DROP INDEX IF EXISTS friendships_pair_unique_idx;
+DROP TABLE IF EXISTS friendships;
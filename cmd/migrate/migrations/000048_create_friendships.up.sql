@@ -0,0 +1,31 @@
+-- Two-way friendships. One row per pair of users.
+-- status: pending (requester asked, addressee has not answered) | accepted
+CREATE TABLE IF NOT EXISTS friendships (
+    id BIGSERIAL PRIMARY KEY,
+    requester_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    addressee_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ,
+
+    CONSTRAINT friendships_not_self CHECK (requester_id <> addressee_id),
+    CONSTRAINT friendships_valid_status CHECK (status IN ('pending', 'accepted'))
+);
+
+-- A pair can only have one row, whoever asked first.
+CREATE UNIQUE INDEX IF NOT EXISTS friendships_pair_unique_idx
+ON friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+
+CREATE INDEX IF NOT EXISTS idx_friendships_addressee_status
+ON friendships (addressee_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_friendships_requester_status
+ON friendships (requester_id, status);
+
+-- Who can see the games a user has joined.
+-- public: anyone | friends: accepted friends only
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS games_visibility TEXT NOT NULL DEFAULT 'public';
+
+ALTER TABLE users
+ADD CONSTRAINT users_games_visibility_check CHECK (games_visibility IN ('public', 'friends'));
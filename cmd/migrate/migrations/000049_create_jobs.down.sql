@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS jobs_unique_key_active_idx;
+DROP INDEX IF EXISTS idx_jobs_status_created_at;
+DROP INDEX IF EXISTS idx_jobs_running_locked_at;
+DROP INDEX IF EXISTS idx_jobs_queued_run_at;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,47 @@
+-- Persistent background job queue.
+-- Workers claim rows with FOR UPDATE SKIP LOCKED, so several API instances
+-- can share the queue without double-running a job.
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+    -- queued -> running -> succeeded
+    --                  \-> queued (retry with backoff) -> ... -> dead
+    status TEXT NOT NULL DEFAULT 'queued',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+
+    -- Optional dedupe key: at most one queued/running job per key.
+    unique_key TEXT,
+
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMPTZ,
+    locked_by TEXT,
+    last_error TEXT,
+    finished_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT jobs_valid_status CHECK (status IN ('queued', 'running', 'succeeded', 'dead')),
+    CONSTRAINT jobs_attempts_positive CHECK (attempts >= 0 AND max_attempts > 0)
+);
+
+-- Claim query: next due queued jobs
+CREATE INDEX IF NOT EXISTS idx_jobs_queued_run_at
+ON jobs (run_at)
+WHERE status = 'queued';
+
+-- Rescue query: jobs stuck in running after a crash
+CREATE INDEX IF NOT EXISTS idx_jobs_running_locked_at
+ON jobs (locked_at)
+WHERE status = 'running';
+
+-- Admin listing
+CREATE INDEX IF NOT EXISTS idx_jobs_status_created_at
+ON jobs (status, created_at DESC);
+
+CREATE UNIQUE INDEX IF NOT EXISTS jobs_unique_key_active_idx
+ON jobs (unique_key)
+WHERE unique_key IS NOT NULL AND status IN ('queued', 'running');
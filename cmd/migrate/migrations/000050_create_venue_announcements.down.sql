@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_venue_announcements_pending_notify;
+DROP INDEX IF EXISTS idx_venue_announcements_venue_publish;
+DROP TABLE IF EXISTS venue_announcements;
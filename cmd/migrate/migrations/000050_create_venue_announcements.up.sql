@@ -0,0 +1,26 @@
+-- Short posts by venue owners (tournament coming, closed for Dashain, new turf).
+-- An announcement is visible between publish_at and expires_at; followers
+-- (users who favorited the venue) get a push once it goes live.
+CREATE TABLE IF NOT EXISTS venue_announcements (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    author_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(120) NOT NULL,
+    body TEXT NOT NULL,
+    publish_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    notified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT venue_announcements_expiry_after_publish
+        CHECK (expires_at IS NULL OR expires_at > publish_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_venue_announcements_venue_publish
+ON venue_announcements (venue_id, publish_at DESC);
+
+-- Notifier query: live announcements nobody has been told about yet
+CREATE INDEX IF NOT EXISTS idx_venue_announcements_pending_notify
+ON venue_announcements (publish_at)
+WHERE notified_at IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_support_ticket_messages_ticket;
+DROP TABLE IF EXISTS support_ticket_messages;
+
+DROP INDEX IF EXISTS idx_support_tickets_assigned_to;
+DROP INDEX IF EXISTS idx_support_tickets_status_last_message;
+DROP INDEX IF EXISTS idx_support_tickets_user_created;
+DROP TABLE IF EXISTS support_tickets;
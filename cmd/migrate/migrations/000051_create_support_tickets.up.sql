@@ -0,0 +1,52 @@
+-- In-app support. A ticket is a conversation between the user and staff.
+--
+-- status workflow:
+--   open -> in_progress -> awaiting_user -> resolved -> closed
+--   a user reply on awaiting_user/resolved reopens the ticket; closed is final.
+CREATE TABLE IF NOT EXISTS support_tickets (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    subject VARCHAR(150) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+
+    -- optional context the ticket is about
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL,
+
+    assigned_to BIGINT REFERENCES users(id) ON DELETE SET NULL,
+
+    last_message_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT support_tickets_valid_category
+        CHECK (category IN ('booking', 'payment', 'order', 'account', 'venue', 'game', 'other')),
+    CONSTRAINT support_tickets_valid_status
+        CHECK (status IN ('open', 'in_progress', 'awaiting_user', 'resolved', 'closed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_support_tickets_user_created
+ON support_tickets (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_support_tickets_status_last_message
+ON support_tickets (status, last_message_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_support_tickets_assigned_to
+ON support_tickets (assigned_to)
+WHERE assigned_to IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS support_ticket_messages (
+    id BIGSERIAL PRIMARY KEY,
+    ticket_id BIGINT NOT NULL REFERENCES support_tickets(id) ON DELETE CASCADE,
+    author_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    is_staff BOOLEAN NOT NULL DEFAULT FALSE,
+    body TEXT NOT NULL,
+    attachment_urls TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_support_ticket_messages_ticket
+ON support_ticket_messages (ticket_id, created_at);
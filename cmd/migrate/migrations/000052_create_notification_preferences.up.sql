@@ -0,0 +1,12 @@
+-- Per-user opt-outs by category and channel. A user without a row gets the
+-- column defaults, so rows are only written once someone changes a setting.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    booking_updates_push BOOLEAN NOT NULL DEFAULT TRUE,
+    booking_updates_email BOOLEAN NOT NULL DEFAULT TRUE,
+    game_invites_push BOOLEAN NOT NULL DEFAULT TRUE,
+    game_invites_email BOOLEAN NOT NULL DEFAULT TRUE,
+    marketing_push BOOLEAN NOT NULL DEFAULT TRUE,
+    marketing_email BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_help_articles_fts;
+DROP INDEX IF EXISTS idx_help_articles_category;
+DROP TABLE IF EXISTS help_articles;
+DROP TABLE IF EXISTS help_categories;
@@ -0,0 +1,35 @@
+-- Admin-managed help center. Article bodies are markdown and rendered by
+-- the client; only published articles are visible outside the admin panel.
+CREATE TABLE IF NOT EXISTS help_categories (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(80) NOT NULL,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS help_articles (
+    id BIGSERIAL PRIMARY KEY,
+    category_id BIGINT NOT NULL REFERENCES help_categories(id) ON DELETE RESTRICT,
+    title VARCHAR(200) NOT NULL,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    body_markdown TEXT NOT NULL,
+    is_published BOOLEAN NOT NULL DEFAULT FALSE,
+    position INT NOT NULL DEFAULT 0,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    fts tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(body_markdown, '')), 'B')
+    ) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_help_articles_category
+ON help_articles (category_id, position)
+WHERE is_published = TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_help_articles_fts ON help_articles USING gin (fts);
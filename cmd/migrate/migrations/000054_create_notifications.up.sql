@@ -0,0 +1,20 @@
+-- In-app inbox. Every notification the backend sends is stored here per
+-- recipient, whether or not a push actually went out.
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+ON notifications (user_id, created_at DESC);
+
+-- Badge count
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+ON notifications (user_id)
+WHERE read_at IS NULL;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_booking_adjustments_venue;
+DROP INDEX IF EXISTS idx_booking_adjustments_user;
+DROP TABLE IF EXISTS booking_adjustments;
+DROP INDEX IF EXISTS idx_booking_dispute_evidence_dispute;
+DROP TABLE IF EXISTS booking_dispute_evidence;
+DROP INDEX IF EXISTS idx_booking_disputes_status_created;
+DROP INDEX IF EXISTS uq_booking_disputes_open;
+DROP TABLE IF EXISTS booking_disputes;
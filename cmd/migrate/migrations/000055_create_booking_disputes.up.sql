@@ -0,0 +1,59 @@
+-- A disagreement about a booking: the player says the venue was closed, or
+-- the owner says the player never showed up. Both sides attach evidence and
+-- an admin resolves it; the resolution is applied as a booking adjustment.
+CREATE TABLE IF NOT EXISTS booking_disputes (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    opened_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    opener_role VARCHAR(10) NOT NULL CHECK (opener_role IN ('user', 'venue')),
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('venue_closed', 'no_show', 'other')),
+    description TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+    resolution VARCHAR(20) CHECK (resolution IN ('refund_user', 'penalize_user', 'dismissed')),
+    adjustment_amount INT CHECK (adjustment_amount IS NULL OR adjustment_amount > 0),
+    resolution_note TEXT,
+    resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT booking_disputes_resolution_when_resolved
+        CHECK ((status = 'resolved') = (resolution IS NOT NULL))
+);
+
+-- One open dispute per booking at a time
+CREATE UNIQUE INDEX IF NOT EXISTS uq_booking_disputes_open
+ON booking_disputes (booking_id)
+WHERE status = 'open';
+
+CREATE INDEX IF NOT EXISTS idx_booking_disputes_status_created
+ON booking_disputes (status, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS booking_dispute_evidence (
+    id BIGSERIAL PRIMARY KEY,
+    dispute_id BIGINT NOT NULL REFERENCES booking_disputes(id) ON DELETE CASCADE,
+    uploaded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    url TEXT NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_dispute_evidence_dispute
+ON booking_dispute_evidence (dispute_id);
+
+-- Money movements that come out of dispute resolutions. A refund is owed
+-- by the venue to the player and is netted out of the booking's final
+-- amount; a penalty is owed by the player.
+CREATE TABLE IF NOT EXISTS booking_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    dispute_id BIGINT REFERENCES booking_disputes(id) ON DELETE SET NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('refund', 'penalty')),
+    amount INT NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_adjustments_user ON booking_adjustments (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_booking_adjustments_venue ON booking_adjustments (venue_id, created_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS reminder_log_sent_at_idx;
+DROP TABLE IF EXISTS reminder_log;
+
+ALTER TABLE notification_preferences
+DROP COLUMN IF EXISTS reminders_push;
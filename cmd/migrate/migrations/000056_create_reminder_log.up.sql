@@ -0,0 +1,16 @@
+-- Users can turn off start-time reminders on their own.
+ALTER TABLE notification_preferences
+ADD COLUMN IF NOT EXISTS reminders_push BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- One row per reminder sent, so overlapping or retried runs of the
+-- reminder job never push the same reminder twice.
+CREATE TABLE IF NOT EXISTS reminder_log (
+    subject_type VARCHAR(10) NOT NULL CHECK (subject_type IN ('booking', 'game')),
+    subject_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    lead_minutes INT NOT NULL CHECK (lead_minutes > 0),
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subject_type, subject_id, user_id, lead_minutes)
+);
+
+CREATE INDEX IF NOT EXISTS reminder_log_sent_at_idx ON reminder_log (sent_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS game_mvp_votes;
+DROP INDEX IF EXISTS idx_game_player_ratings_ratee;
+DROP TABLE IF EXISTS game_player_ratings;
+
+ALTER TABLE games
+DROP COLUMN IF EXISTS rating_closes_at;
@@ -0,0 +1,27 @@
+-- Set when a game is completed; players can rate each other and vote for
+-- an MVP until then.
+ALTER TABLE games
+ADD COLUMN IF NOT EXISTS rating_closes_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS game_player_ratings (
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    rater_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ratee_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, rater_id, ratee_id),
+    CONSTRAINT game_player_ratings_not_self CHECK (rater_id <> ratee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_player_ratings_ratee ON game_player_ratings (ratee_id);
+
+CREATE TABLE IF NOT EXISTS game_mvp_votes (
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    voter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    nominee_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, voter_id),
+    CONSTRAINT game_mvp_votes_not_self CHECK (voter_id <> nominee_id)
+);
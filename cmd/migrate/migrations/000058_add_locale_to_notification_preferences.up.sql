@@ -0,0 +1,4 @@
+-- Language for emails (subject lines today). Defaults to English.
+ALTER TABLE notification_preferences
+ADD COLUMN IF NOT EXISTS locale VARCHAR(5) NOT NULL DEFAULT 'en'
+    CHECK (locale IN ('en', 'ne'));
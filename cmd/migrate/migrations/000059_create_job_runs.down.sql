@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_job_runs_kind_started_at;
+DROP TABLE IF EXISTS job_runs;
@@ -0,0 +1,18 @@
+-- One row per execution of a periodic job, so admins can see whether each
+-- schedule is actually firing and what it did. Kept after the job row
+-- itself is pruned.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job_id BIGINT REFERENCES jobs(id) ON DELETE SET NULL,
+    kind TEXT NOT NULL,
+    worker TEXT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    rows_affected BIGINT,
+    error TEXT,
+    succeeded BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_kind_started_at
+ON job_runs (kind, started_at DESC);
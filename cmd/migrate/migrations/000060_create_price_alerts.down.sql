@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_price_alerts_venue_id;
+DROP TABLE IF EXISTS price_alerts;
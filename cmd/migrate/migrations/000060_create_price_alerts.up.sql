@@ -0,0 +1,19 @@
+-- A user watching a venue's weekly slot (day + start time) for a price
+-- below their threshold. last_notified_price stops repeat pushes for the
+-- same price; it is cleared once the price goes back above the threshold.
+CREATE TABLE IF NOT EXISTS price_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    day_of_week VARCHAR(10) NOT NULL,
+    start_time TIME NOT NULL,
+    price_below INT NOT NULL CHECK (price_below > 0),
+    last_notified_price INT,
+    last_notified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT price_alerts_valid_day CHECK (day_of_week IN ('sunday', 'monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday')),
+    CONSTRAINT price_alerts_unique_slot UNIQUE (user_id, venue_id, day_of_week, start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_alerts_venue_id
+ON price_alerts (venue_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS venue_pricing_overrides_facility_date_start_idx;
+DROP TABLE IF EXISTS venue_pricing_overrides;
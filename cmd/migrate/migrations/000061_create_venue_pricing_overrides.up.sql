@@ -0,0 +1,20 @@
+-- Date-specific pricing for a facility (festivals, holidays). When a facility
+-- has any override rows on a date, they replace that day's weekly
+-- venue_pricing entirely; hours without an override row are closed.
+-- Times are local Nepal time, same as venue_pricing.
+CREATE TABLE IF NOT EXISTS venue_pricing_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    facility_id BIGINT NOT NULL REFERENCES facilities(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    price INT NOT NULL CHECK (price >= 0),
+    label TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT venue_pricing_overrides_valid_range CHECK (start_time < end_time)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS venue_pricing_overrides_facility_date_start_idx
+ON venue_pricing_overrides (facility_id, date, start_time);
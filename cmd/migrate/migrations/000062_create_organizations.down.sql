@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_venues_organization_id;
+ALTER TABLE venues DROP COLUMN IF EXISTS organization_id;
+DROP INDEX IF EXISTS idx_organization_members_user_id;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
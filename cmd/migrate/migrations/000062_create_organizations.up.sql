@@ -0,0 +1,34 @@
+-- Organizations group venues run under one brand. A venue without an
+-- organization keeps working exactly as before: its owner_id alone decides
+-- who manages it.
+CREATE TABLE IF NOT EXISTS organizations (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    owner_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    payout_bank_name TEXT,
+    payout_account_name TEXT,
+    payout_account_number TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- owner: everything, including members and payouts.
+-- manager: manages the organization's venues and sees the dashboard.
+-- staff: read-only membership for now.
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'manager', 'staff')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id
+ON organization_members (user_id);
+
+ALTER TABLE venues
+ADD COLUMN IF NOT EXISTS organization_id BIGINT REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_venues_organization_id
+ON venues (organization_id)
+WHERE organization_id IS NOT NULL;
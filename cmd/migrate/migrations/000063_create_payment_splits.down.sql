@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_split_shares;
+DROP TABLE IF EXISTS payment_splits;
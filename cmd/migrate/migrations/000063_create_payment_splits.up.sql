@@ -0,0 +1,35 @@
+-- A payment split divides a booking's price between the players of the slot.
+-- Each participant pays their own share online; once every share is paid the
+-- split is settled and a pending booking is confirmed.
+CREATE TABLE IF NOT EXISTS payment_splits (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    total_amount INT NOT NULL CHECK (total_amount > 0),
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paid', 'canceled')),
+    paid_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS payment_split_shares (
+    id BIGSERIAL PRIMARY KEY,
+    split_id BIGINT NOT NULL REFERENCES payment_splits(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount > 0),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid')),
+    provider TEXT,
+    provider_ref TEXT,
+    paid_at TIMESTAMPTZ,
+    last_reminded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (split_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_split_shares_user_id
+ON payment_split_shares (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_payment_split_shares_unpaid
+ON payment_split_shares (last_reminded_at)
+WHERE status = 'pending';
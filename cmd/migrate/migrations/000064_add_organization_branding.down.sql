@@ -0,0 +1,4 @@
+ALTER TABLE organizations
+DROP COLUMN IF EXISTS logo_url,
+DROP COLUMN IF EXISTS brand_color,
+DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,6 @@
+-- Branding shown on the organization's venues and in booking emails. Every
+-- field is optional; display_name falls back to the organization name.
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS display_name TEXT,
+ADD COLUMN IF NOT EXISTS brand_color TEXT CHECK (brand_color ~ '^#[0-9A-Fa-f]{6}$'),
+ADD COLUMN IF NOT EXISTS logo_url TEXT;
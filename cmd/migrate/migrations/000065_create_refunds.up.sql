@@ -0,0 +1,50 @@
+-- How much of an online payment a venue returns when a booking is canceled.
+-- Venues without a row use the defaults: everything back when canceled more
+-- than 24 hours before the start, half otherwise.
+CREATE TABLE IF NOT EXISTS venue_refund_policies (
+    venue_id BIGINT PRIMARY KEY REFERENCES venues(id) ON DELETE CASCADE,
+    full_refund_hours INT NOT NULL DEFAULT 24 CHECK (full_refund_hours >= 0),
+    late_refund_percent INT NOT NULL DEFAULT 50 CHECK (late_refund_percent BETWEEN 0 AND 100),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- pending: waiting for the venue owner or an admin.
+-- rejected: declined, nothing is paid back.
+-- refunded: every payment was refunded through its gateway.
+-- manual: approved, but part of it has to be paid out by hand.
+-- failed: approved, but a gateway refund failed.
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    requested_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    paid_amount INT NOT NULL CHECK (paid_amount > 0),
+    refund_percent INT NOT NULL CHECK (refund_percent BETWEEN 0 AND 100),
+    refund_amount INT NOT NULL CHECK (refund_amount >= 0),
+    reason TEXT,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'rejected', 'refunded', 'manual', 'failed')),
+    decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    decision_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_venue_status ON refunds (venue_id, status);
+
+-- One row per payment being refunded.
+CREATE TABLE IF NOT EXISTS refund_items (
+    id BIGSERIAL PRIMARY KEY,
+    refund_id BIGINT NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    share_id BIGINT REFERENCES payment_split_shares(id) ON DELETE SET NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount >= 0),
+    provider TEXT NOT NULL,
+    provider_ref TEXT,
+    status TEXT NOT NULL CHECK (status IN ('refunded', 'manual', 'failed')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refund_items_refund_id ON refund_items (refund_id);
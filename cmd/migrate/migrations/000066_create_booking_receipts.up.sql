@@ -0,0 +1,7 @@
+-- The last receipt PDF generated for a booking. It is regenerated when the
+-- booking or its payments change after generated_at.
+CREATE TABLE IF NOT EXISTS booking_receipts (
+    booking_id BIGINT PRIMARY KEY REFERENCES bookings(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_facilities_venue_attributes;
+
+ALTER TABLE facilities
+DROP COLUMN IF EXISTS price_adjustment_percent,
+DROP COLUMN IF EXISTS is_covered;
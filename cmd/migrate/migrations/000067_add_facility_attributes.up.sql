@@ -0,0 +1,10 @@
+-- Court attributes players filter on, and an optional price adjustment for
+-- courts that reuse the default facility's pricing instead of their own.
+ALTER TABLE facilities
+ADD COLUMN IF NOT EXISTS is_covered BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS price_adjustment_percent INT
+    CHECK (price_adjustment_percent BETWEEN -90 AND 300);
+
+CREATE INDEX IF NOT EXISTS idx_facilities_venue_attributes
+ON facilities (venue_id, LOWER(surface_type), is_covered)
+WHERE is_active;
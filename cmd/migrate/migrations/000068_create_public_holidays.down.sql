@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS venue_holiday_settings;
+DROP TABLE IF EXISTS public_holidays;
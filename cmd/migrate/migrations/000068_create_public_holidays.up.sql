@@ -0,0 +1,30 @@
+-- Nepal public holidays, kept up to date by admins. Festival dates follow the
+-- lunar calendar and move every year, so only the holidays fixed in the
+-- Bikram Sambat calendar are seeded here.
+CREATE TABLE IF NOT EXISTS public_holidays (
+    id BIGSERIAL PRIMARY KEY,
+    date DATE NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO public_holidays (date, name) VALUES
+    ('2026-01-11', 'Prithvi Jayanti'),
+    ('2026-02-19', 'Democracy Day'),
+    ('2026-03-08', 'International Women''s Day'),
+    ('2026-04-14', 'Nepali New Year'),
+    ('2026-05-01', 'Labour Day'),
+    ('2026-05-29', 'Republic Day'),
+    ('2026-09-19', 'Constitution Day')
+ON CONFLICT (date) DO NOTHING;
+
+-- How a venue prices public holidays. Weekly pricing is adjusted by
+-- rate_percent on holidays; closed venues take no bookings at all. Date
+-- overrides still win over both.
+CREATE TABLE IF NOT EXISTS venue_holiday_settings (
+    venue_id BIGINT PRIMARY KEY REFERENCES venues(id) ON DELETE CASCADE,
+    rate_percent INT NOT NULL DEFAULT 0 CHECK (rate_percent BETWEEN -90 AND 300),
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS settlement_items;
+DROP TABLE IF EXISTS settlements;
+DROP TABLE IF EXISTS venue_commission_rates;
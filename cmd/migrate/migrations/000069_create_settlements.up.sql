@@ -0,0 +1,53 @@
+-- Commission the platform keeps from a venue's bookings. Venues without a
+-- row pay the default rate set in code.
+CREATE TABLE IF NOT EXISTS venue_commission_rates (
+    venue_id BIGINT PRIMARY KEY REFERENCES venues(id) ON DELETE CASCADE,
+    percent INT NOT NULL CHECK (percent BETWEEN 0 AND 50),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One settlement per venue and week (Monday to Monday, Nepal time).
+-- payout_amount is what the platform owes the venue: online payments it
+-- collected, less refunds and commission. It is negative when the venue owes
+-- commission on bookings paid in cash.
+-- pending: waiting to be paid out.
+-- on_hold: held back by an admin, e.g. while a dispute is open.
+-- paid: paid out; payout_reference records the transfer.
+CREATE TABLE IF NOT EXISTS settlements (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    booking_count INT NOT NULL,
+    gross_amount INT NOT NULL,
+    online_amount INT NOT NULL,
+    refunded_amount INT NOT NULL,
+    commission_amount INT NOT NULL,
+    payout_amount INT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'on_hold', 'paid')),
+    payout_reference TEXT,
+    note TEXT,
+    paid_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (venue_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlements_status ON settlements (status, period_start DESC);
+
+-- A booking is settled once, in the first batch after it ends.
+CREATE TABLE IF NOT EXISTS settlement_items (
+    id BIGSERIAL PRIMARY KEY,
+    settlement_id BIGINT NOT NULL REFERENCES settlements(id) ON DELETE CASCADE,
+    booking_id BIGINT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+    booking_status TEXT NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    booking_amount INT NOT NULL,
+    online_amount INT NOT NULL,
+    refunded_amount INT NOT NULL,
+    commission_percent INT NOT NULL,
+    commission_amount INT NOT NULL,
+    net_amount INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlement_items_settlement_id ON settlement_items (settlement_id);
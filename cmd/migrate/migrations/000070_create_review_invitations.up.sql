@@ -0,0 +1,11 @@
+-- Bookings whose player was asked to review the venue. One row per booking
+-- so the invitation goes out once.
+CREATE TABLE IF NOT EXISTS review_invitations (
+    booking_id BIGINT PRIMARY KEY REFERENCES bookings(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_invitations_user_venue
+ON review_invitations (user_id, venue_id, sent_at DESC);
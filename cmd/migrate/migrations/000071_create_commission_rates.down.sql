@@ -0,0 +1,18 @@
+DROP TRIGGER IF EXISTS set_booking_commission ON bookings;
+DROP FUNCTION IF EXISTS set_booking_commission();
+ALTER TABLE bookings DROP COLUMN IF EXISTS commission_percent;
+DROP FUNCTION IF EXISTS commission_percent_at(BIGINT, TIMESTAMPTZ);
+
+CREATE TABLE IF NOT EXISTS venue_commission_rates (
+    venue_id BIGINT PRIMARY KEY REFERENCES venues(id) ON DELETE CASCADE,
+    percent INT NOT NULL CHECK (percent BETWEEN 0 AND 50),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO venue_commission_rates (venue_id, percent)
+SELECT DISTINCT ON (venue_id) venue_id, percent
+FROM commission_rates
+WHERE venue_id IS NOT NULL AND effective_from <= NOW()
+ORDER BY venue_id, effective_from DESC;
+
+DROP TABLE IF EXISTS commission_rates;
@@ -0,0 +1,57 @@
+-- Commission the platform keeps from bookings. A row without venue_id is the
+-- platform default; a venue row overrides the default from its
+-- effective_from on. The rate in force when a booking is confirmed is copied
+-- onto the booking, so later changes never touch confirmed bookings.
+CREATE TABLE IF NOT EXISTS commission_rates (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT REFERENCES venues(id) ON DELETE CASCADE,
+    percent INT NOT NULL CHECK (percent BETWEEN 0 AND 50),
+    effective_from TIMESTAMPTZ NOT NULL,
+    note TEXT,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_commission_rates_venue_effective
+ON commission_rates (COALESCE(venue_id, 0), effective_from);
+
+INSERT INTO commission_rates (venue_id, percent, effective_from, note)
+VALUES (NULL, 10, '2000-01-01 00:00:00+00', 'Initial platform default');
+
+INSERT INTO commission_rates (venue_id, percent, effective_from, note)
+SELECT venue_id, percent, '2000-01-01 00:00:00+00', 'Carried over from venue_commission_rates'
+FROM venue_commission_rates;
+
+DROP TABLE IF EXISTS venue_commission_rates;
+
+-- The venue's override in force at p_at, else the platform default.
+CREATE OR REPLACE FUNCTION commission_percent_at(p_venue_id BIGINT, p_at TIMESTAMPTZ)
+RETURNS INT AS $$
+    SELECT COALESCE(
+        (SELECT percent FROM commission_rates
+         WHERE venue_id = p_venue_id AND effective_from <= p_at
+         ORDER BY effective_from DESC LIMIT 1),
+        (SELECT percent FROM commission_rates
+         WHERE venue_id IS NULL AND effective_from <= p_at
+         ORDER BY effective_from DESC LIMIT 1),
+        0
+    );
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS commission_percent INT;
+
+-- Every path that confirms a booking (owner accept, paid split, manual
+-- booking) goes through this, so none of them has to look the rate up.
+CREATE OR REPLACE FUNCTION set_booking_commission()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'confirmed' AND NEW.commission_percent IS NULL THEN
+        NEW.commission_percent = commission_percent_at(NEW.venue_id, NOW());
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_booking_commission
+BEFORE INSERT OR UPDATE OF status ON bookings
+FOR EACH ROW EXECUTE FUNCTION set_booking_commission();
@@ -0,0 +1,20 @@
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_one_per_cooldown;
+DROP INDEX IF EXISTS idx_reviews_held;
+
+-- Keep the newest review per user and venue so the old unique constraint fits.
+DELETE FROM reviews r
+USING reviews newer
+WHERE newer.venue_id = r.venue_id
+  AND newer.user_id = r.user_id
+  AND (newer.created_at, newer.id) > (r.created_at, r.id);
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS cooldown_until,
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS hold_reason,
+    DROP COLUMN IF EXISTS status;
+
+ALTER TABLE reviews
+ADD CONSTRAINT uq_reviews_venue_user
+  UNIQUE (venue_id, user_id);
@@ -0,0 +1,28 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- published: counts towards the venue's rating.
+-- held: waiting for a moderator, e.g. part of a burst of 1-star reviews.
+-- rejected: removed by a moderator.
+-- replaced: an older review the author replaced after the cooldown.
+-- deleted: removed by its author. Kept so deleting doesn't reset the cooldown.
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'
+        CHECK (status IN ('published', 'held', 'rejected', 'replaced', 'deleted')),
+    ADD COLUMN IF NOT EXISTS hold_reason TEXT,
+    ADD COLUMN IF NOT EXISTS moderated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS cooldown_until TIMESTAMPTZ;
+
+UPDATE reviews SET cooldown_until = created_at + INTERVAL '90 days';
+
+ALTER TABLE reviews
+    ALTER COLUMN cooldown_until SET DEFAULT NOW() + INTERVAL '90 days',
+    ALTER COLUMN cooldown_until SET NOT NULL;
+
+-- One review per user and venue every 90 days, whatever happened to it.
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS uq_reviews_venue_user;
+ALTER TABLE reviews
+    ADD CONSTRAINT reviews_one_per_cooldown
+    EXCLUDE USING gist (venue_id WITH =, user_id WITH =, tstzrange(created_at, cooldown_until) WITH &&);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_held ON reviews (created_at) WHERE status = 'held';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS inventory_stock_movements;
+DROP TABLE IF EXISTS inventory_stocktakes;
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS inventory_stocktakes (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    note TEXT,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_stocktakes_venue
+ON inventory_stocktakes(venue_id, created_at DESC);
+
+-- Every change to an item's stock_quantity, so the current figure can be
+-- traced back through sales, manual edits and counts.
+CREATE TABLE IF NOT EXISTS inventory_stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    inventory_item_id BIGINT NOT NULL REFERENCES venue_inventory_items(id) ON DELETE CASCADE,
+
+    delta INT NOT NULL,
+    quantity_after INT NOT NULL CHECK (quantity_after >= 0),
+    reason TEXT NOT NULL CHECK (reason IN ('opening', 'sale', 'adjustment', 'stocktake')),
+    note TEXT,
+
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    stocktake_id BIGINT REFERENCES inventory_stocktakes(id) ON DELETE CASCADE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_stock_movements_item
+ON inventory_stock_movements(inventory_item_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_stock_movements_venue
+ON inventory_stock_movements(venue_id, created_at DESC);
+
+-- Open the ledger with what is on hand today.
+INSERT INTO inventory_stock_movements (venue_id, inventory_item_id, delta, quantity_after, reason, note)
+SELECT venue_id, id, stock_quantity, stock_quantity, 'opening', 'Stock on hand when the ledger started'
+FROM venue_inventory_items
+WHERE stock_quantity IS NOT NULL;
@@ -0,0 +1,41 @@
+-- A game filter a user saved to be told about new games that match it.
+-- Every criterion is optional; the location needs all three of latitude,
+-- longitude and radius. start_from/start_to are local Nepal time of day and
+-- may wrap past midnight.
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(60) NOT NULL,
+    sport_type VARCHAR(50),
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    radius_km INT CHECK (radius_km BETWEEN 1 AND 100),
+    min_price INT CHECK (min_price >= 0),
+    max_price INT CHECK (max_price >= 0),
+    start_from TIME,
+    start_to TIME,
+    notify BOOLEAN NOT NULL DEFAULT TRUE,
+    last_notified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT saved_searches_location CHECK (
+        (latitude IS NULL AND longitude IS NULL AND radius_km IS NULL) OR
+        (latitude IS NOT NULL AND longitude IS NOT NULL AND radius_km IS NOT NULL)
+    ),
+    CONSTRAINT saved_searches_price_range CHECK (min_price IS NULL OR max_price IS NULL OR min_price <= max_price),
+    CONSTRAINT saved_searches_time_window CHECK ((start_from IS NULL) = (start_to IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id
+ON saved_searches (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_sport
+ON saved_searches (sport_type) WHERE notify;
+
+-- Games a saved search already alerted about, so a game is pushed once.
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    saved_search_id BIGINT NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (saved_search_id, game_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_cost_cents;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS cost_price_cents;
@@ -0,0 +1,9 @@
+-- What the merchant paid to land one unit of the variant. Never shown to
+-- buyers.
+ALTER TABLE product_variants
+    ADD COLUMN IF NOT EXISTS cost_price_cents BIGINT CHECK (cost_price_cents >= 0);
+
+-- Cost snapshotted at checkout, so margins on past orders do not move when
+-- the cost price is updated. NULL when the variant had no cost recorded.
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS unit_cost_cents BIGINT CHECK (unit_cost_cents >= 0);
@@ -0,0 +1,21 @@
+-- A user waiting for a time range at a venue to free up. facility_id NULL
+-- means any facility at the venue. An alert fires once: notified_at is set
+-- when a booking overlapping the range is canceled or rejected.
+CREATE TABLE IF NOT EXISTS slot_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    facility_id BIGINT REFERENCES facilities(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    notified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slot_alerts_user_id
+ON slot_alerts (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_slot_alerts_pending
+ON slot_alerts (venue_id, start_time, end_time)
+WHERE notified_at IS NULL;
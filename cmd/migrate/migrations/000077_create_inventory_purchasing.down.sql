@@ -0,0 +1,14 @@
+ALTER TABLE inventory_stock_movements DROP COLUMN IF EXISTS purchase_order_id;
+
+DELETE FROM inventory_stock_movements WHERE reason = 'purchase';
+
+ALTER TABLE inventory_stock_movements
+    DROP CONSTRAINT IF EXISTS inventory_stock_movements_reason_check;
+
+ALTER TABLE inventory_stock_movements
+    ADD CONSTRAINT inventory_stock_movements_reason_check
+    CHECK (reason IN ('opening', 'sale', 'adjustment', 'stocktake'));
+
+DROP TABLE IF EXISTS inventory_purchase_order_lines;
+DROP TABLE IF EXISTS inventory_purchase_orders;
+DROP TABLE IF EXISTS inventory_suppliers;
@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS inventory_suppliers (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    name VARCHAR(120) NOT NULL,
+    phone VARCHAR(30),
+    email VARCHAR(255),
+    note TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inventory_suppliers_venue_name
+ON inventory_suppliers(venue_id, LOWER(name));
+
+-- A purchase order moves draft -> ordered -> received. Draft and ordered
+-- orders can be cancelled; received ones are final.
+CREATE TABLE IF NOT EXISTS inventory_purchase_orders (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    supplier_id BIGINT NOT NULL REFERENCES inventory_suppliers(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'ordered', 'received', 'cancelled')),
+    note TEXT,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ordered_at TIMESTAMP WITH TIME ZONE,
+    received_at TIMESTAMP WITH TIME ZONE,
+    received_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_purchase_orders_venue
+ON inventory_purchase_orders(venue_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS inventory_purchase_order_lines (
+    id BIGSERIAL PRIMARY KEY,
+    purchase_order_id BIGINT NOT NULL REFERENCES inventory_purchase_orders(id) ON DELETE CASCADE,
+    inventory_item_id BIGINT NOT NULL REFERENCES venue_inventory_items(id) ON DELETE RESTRICT,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_cost INT NOT NULL CHECK (unit_cost >= 0),
+    UNIQUE (purchase_order_id, inventory_item_id)
+);
+
+-- Receiving a purchase order is a stock movement of its own.
+ALTER TABLE inventory_stock_movements
+    DROP CONSTRAINT IF EXISTS inventory_stock_movements_reason_check;
+
+ALTER TABLE inventory_stock_movements
+    ADD CONSTRAINT inventory_stock_movements_reason_check
+    CHECK (reason IN ('opening', 'sale', 'adjustment', 'stocktake', 'purchase'));
+
+ALTER TABLE inventory_stock_movements
+    ADD COLUMN IF NOT EXISTS purchase_order_id BIGINT REFERENCES inventory_purchase_orders(id) ON DELETE SET NULL;
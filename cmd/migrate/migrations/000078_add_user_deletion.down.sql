@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_users_deletion_due;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_check;
+ALTER TABLE users ADD CONSTRAINT users_phone_check
+    CHECK (phone ~ '^[0-9]{10}$') NOT VALID;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS anonymized_at,
+    DROP COLUMN IF EXISTS deletion_scheduled_for,
+    DROP COLUMN IF EXISTS deletion_requested_at;
@@ -0,0 +1,17 @@
+-- Two-phase account deletion. A request sets deletion_scheduled_for; signing
+-- in before then clears it. Once it passes, the purge job scrubs the row and
+-- stamps anonymized_at. The row itself stays so orders, bookings and games
+-- keep their history.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_for TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
+
+-- Anonymized users keep a unique placeholder in place of a phone number.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_check;
+ALTER TABLE users ADD CONSTRAINT users_phone_check
+    CHECK (phone ~ '^[0-9]{10}$' OR anonymized_at IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_due
+ON users (deletion_scheduled_for)
+WHERE deletion_scheduled_for IS NOT NULL;
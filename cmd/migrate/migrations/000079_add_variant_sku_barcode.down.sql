@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS ux_product_variants_barcode;
+DROP INDEX IF EXISTS ux_product_variants_sku;
+
+ALTER TABLE product_variants
+    DROP CONSTRAINT IF EXISTS product_variants_barcode_check,
+    DROP CONSTRAINT IF EXISTS product_variants_sku_check,
+    DROP COLUMN IF EXISTS barcode,
+    DROP COLUMN IF EXISTS sku;
@@ -0,0 +1,19 @@
+-- Warehouse identifiers for variants. SKUs are unique regardless of case;
+-- existing variants get a placeholder SKU derived from their id.
+ALTER TABLE product_variants
+    ADD COLUMN IF NOT EXISTS sku TEXT,
+    ADD COLUMN IF NOT EXISTS barcode TEXT;
+
+UPDATE product_variants SET sku = 'SKU-' || id WHERE sku IS NULL;
+
+ALTER TABLE product_variants
+    ALTER COLUMN sku SET NOT NULL,
+    ADD CONSTRAINT product_variants_sku_check CHECK (char_length(sku) BETWEEN 1 AND 64),
+    ADD CONSTRAINT product_variants_barcode_check CHECK (barcode IS NULL OR char_length(barcode) BETWEEN 1 AND 64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_product_variants_sku
+ON product_variants (LOWER(sku));
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_product_variants_barcode
+ON product_variants (barcode)
+WHERE barcode IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_label_assignments;
+DROP TABLE IF EXISTS product_labels;
@@ -0,0 +1,31 @@
+-- Badges shown on product cards. A label with auto_rule is assigned by the
+-- nightly refresh; the others are attached by hand. A manual assignment of
+-- an auto label pins it, and the refresh leaves it alone.
+CREATE TABLE IF NOT EXISTS product_labels (
+    id BIGSERIAL PRIMARY KEY,
+    key TEXT NOT NULL UNIQUE CHECK (key ~ '^[a-z0-9_-]{1,40}$'),
+    name TEXT NOT NULL CHECK (char_length(name) BETWEEN 1 AND 40),
+    color TEXT CHECK (color ~ '^#[0-9A-Fa-f]{6}$'),
+    priority INT NOT NULL DEFAULT 0,
+    auto_rule TEXT UNIQUE CHECK (auto_rule IN ('new', 'bestseller')),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS product_label_assignments (
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    label_id BIGINT NOT NULL REFERENCES product_labels(id) ON DELETE CASCADE,
+    source TEXT NOT NULL CHECK (source IN ('manual', 'auto')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_label_assignments_label
+ON product_label_assignments (label_id);
+
+INSERT INTO product_labels (key, name, color, priority, auto_rule) VALUES
+    ('limited', 'Limited', '#C62828', 30, NULL),
+    ('bestseller', 'Bestseller', '#EF6C00', 20, 'bestseller'),
+    ('new', 'New', '#2E7D32', 10, 'new')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,24 @@
+-- Ordered sections of the app home screen. The app renders them top to
+-- bottom by position; params carry per-type settings (see the homelayout
+-- package for what each type accepts).
+CREATE TABLE IF NOT EXISTS home_sections (
+    id BIGSERIAL PRIMARY KEY,
+    position INT NOT NULL CHECK (position >= 0),
+    type TEXT NOT NULL CHECK (type IN ('ads_carousel', 'featured_rails', 'featured_collection', 'nearby_games', 'venues')),
+    title TEXT CHECK (char_length(title) <= 80),
+    params JSONB NOT NULL DEFAULT '{}'::jsonb,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT home_sections_window_check CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at),
+    CONSTRAINT ux_home_sections_position UNIQUE (position)
+);
+
+-- Matches the order the app hard-coded before the layout came from the API.
+INSERT INTO home_sections (position, type, title, params) VALUES
+    (0, 'ads_carousel', NULL, '{}'),
+    (1, 'featured_rails', NULL, '{}'),
+    (2, 'nearby_games', 'Games near you', '{"limit": 10}'),
+    (3, 'venues', 'Venues near you', '{"sort": "nearby", "limit": 10}');
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_games_auto_cancel_at;
+
+ALTER TABLE games
+    DROP CONSTRAINT IF EXISTS games_auto_cancel_check,
+    DROP CONSTRAINT IF EXISTS games_min_players_check,
+    DROP COLUMN IF EXISTS cancel_reason,
+    DROP COLUMN IF EXISTS booking_id,
+    DROP COLUMN IF EXISTS auto_cancel_at,
+    DROP COLUMN IF EXISTS min_players;
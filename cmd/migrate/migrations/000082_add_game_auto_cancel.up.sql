@@ -0,0 +1,18 @@
+-- Organizers can ask for a game to be called off when it hasn't filled up:
+-- once auto_cancel_at passes with fewer than min_players in the game, the
+-- auto-cancel job cancels it and releases the linked booking, if any.
+ALTER TABLE games
+    ADD COLUMN IF NOT EXISTS min_players INT,
+    ADD COLUMN IF NOT EXISTS auto_cancel_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS cancel_reason TEXT;
+
+ALTER TABLE games
+    ADD CONSTRAINT games_min_players_check
+        CHECK (min_players IS NULL OR (min_players >= 2 AND min_players <= max_players)),
+    ADD CONSTRAINT games_auto_cancel_check
+        CHECK ((min_players IS NULL) = (auto_cancel_at IS NULL) AND (auto_cancel_at IS NULL OR auto_cancel_at < start_time));
+
+CREATE INDEX IF NOT EXISTS idx_games_auto_cancel_at
+    ON games (auto_cancel_at)
+    WHERE status = 'active' AND auto_cancel_at IS NOT NULL;
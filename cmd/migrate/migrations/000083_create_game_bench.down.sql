@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS game_bench;
+
+ALTER TABLE games DROP COLUMN IF EXISTS bench_size;
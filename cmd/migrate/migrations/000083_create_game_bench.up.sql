@@ -0,0 +1,15 @@
+-- Players accepted after a game is full wait on the bench, up to bench_size
+-- of them. When someone leaves, the longest-waiting bench player is moved
+-- into game_players.
+ALTER TABLE games
+    ADD COLUMN IF NOT EXISTS bench_size INT NOT NULL DEFAULT 0 CHECK (bench_size BETWEEN 0 AND 20);
+
+CREATE TABLE IF NOT EXISTS game_bench (
+    id BIGSERIAL PRIMARY KEY,
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT unique_game_bench_user UNIQUE (game_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_bench_queue ON game_bench (game_id, joined_at, id);
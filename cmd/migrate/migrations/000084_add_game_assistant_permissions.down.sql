@@ -0,0 +1,7 @@
+ALTER TABLE games
+    DROP COLUMN IF EXISTS score_recorded_at,
+    DROP COLUMN IF EXISTS score_recorded_by,
+    DROP COLUMN IF EXISTS score_b,
+    DROP COLUMN IF EXISTS score_a;
+
+ALTER TABLE game_players DROP COLUMN IF EXISTS permissions;
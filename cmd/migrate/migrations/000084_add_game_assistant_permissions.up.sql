@@ -0,0 +1,13 @@
+-- What each assistant may do in their game. The game admin can do
+-- everything and plain players nothing, so only assistants read this; the
+-- default matches what assistants could do before permissions existed.
+ALTER TABLE game_players
+    ADD COLUMN IF NOT EXISTS permissions TEXT[] NOT NULL DEFAULT '{accept_requests,edit_details}'
+        CHECK (permissions <@ ARRAY['accept_requests', 'kick_players', 'edit_details', 'record_score']);
+
+-- Final score, recorded by the admin or an assistant allowed to.
+ALTER TABLE games
+    ADD COLUMN IF NOT EXISTS score_a INT CHECK (score_a >= 0),
+    ADD COLUMN IF NOT EXISTS score_b INT CHECK (score_b >= 0),
+    ADD COLUMN IF NOT EXISTS score_recorded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS score_recorded_at TIMESTAMPTZ;
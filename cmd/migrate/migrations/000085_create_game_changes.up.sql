@@ -0,0 +1,11 @@
+-- Every edit to a game's details, so players can see what moved since they
+-- joined. changes is a list of {field, old, new}.
+CREATE TABLE IF NOT EXISTS game_changes (
+    id BIGSERIAL PRIMARY KEY,
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_changes_game ON game_changes (game_id, created_at DESC);
@@ -0,0 +1,16 @@
+-- CSV exports of a user's past bookings and games. The file is built by a
+-- background job and kept only until expires_at, when the download link
+-- stops working and the purge job removes the row.
+CREATE TABLE IF NOT EXISTS history_exports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready')),
+    content BYTEA,
+    row_count INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_history_exports_user ON history_exports (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_history_exports_expires ON history_exports (expires_at);
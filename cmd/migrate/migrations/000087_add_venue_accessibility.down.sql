@@ -0,0 +1,5 @@
+ALTER TABLE venues
+    DROP COLUMN IF EXISTS accessibility_notes,
+    DROP COLUMN IF EXISTS accessible_parking,
+    DROP COLUMN IF EXISTS accessible_toilets,
+    DROP COLUMN IF EXISTS wheelchair_access;
@@ -0,0 +1,7 @@
+-- Structured accessibility info, set by the owner. NULL means the owner
+-- hasn't said, which the app shows differently from a plain "no".
+ALTER TABLE venues
+    ADD COLUMN IF NOT EXISTS wheelchair_access BOOLEAN,
+    ADD COLUMN IF NOT EXISTS accessible_toilets BOOLEAN,
+    ADD COLUMN IF NOT EXISTS accessible_parking BOOLEAN,
+    ADD COLUMN IF NOT EXISTS accessibility_notes TEXT CHECK (char_length(accessibility_notes) <= 500);
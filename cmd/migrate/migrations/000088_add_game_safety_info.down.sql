@@ -0,0 +1,4 @@
+ALTER TABLE games
+    DROP COLUMN IF EXISTS emergency_contact_phone,
+    DROP COLUMN IF EXISTS emergency_contact_name,
+    DROP COLUMN IF EXISTS safety_info;
@@ -0,0 +1,6 @@
+-- Safety notes and an emergency contact the organiser attaches to a game.
+-- New games default the contact to the venue's registered phone.
+ALTER TABLE games
+    ADD COLUMN IF NOT EXISTS safety_info TEXT CHECK (char_length(safety_info) <= 500),
+    ADD COLUMN IF NOT EXISTS emergency_contact_name TEXT CHECK (char_length(emergency_contact_name) <= 100),
+    ADD COLUMN IF NOT EXISTS emergency_contact_phone TEXT CHECK (char_length(emergency_contact_phone) <= 20);
@@ -0,0 +1,15 @@
+-- Emergency closures an owner declares for a venue (flood, power cut...).
+-- While one is in effect its period counts as booked, so nobody can book it.
+CREATE TABLE IF NOT EXISTS venue_closures (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('flood', 'power_cut', 'weather', 'other')),
+    note TEXT CHECK (char_length(note) <= 500),
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_venue_closures_venue ON venue_closures (venue_id, end_time);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS bookings_pending_created_idx;
+
+ALTER TABLE venues DROP COLUMN IF EXISTS auto_accept_after_minutes;
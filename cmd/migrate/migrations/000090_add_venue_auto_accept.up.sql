@@ -0,0 +1,10 @@
+-- Owners can have pending bookings confirmed automatically when they haven't
+-- answered within this many minutes. NULL leaves every request to the owner.
+ALTER TABLE venues
+    ADD COLUMN IF NOT EXISTS auto_accept_after_minutes INT
+        CONSTRAINT venues_auto_accept_range CHECK (auto_accept_after_minutes BETWEEN 5 AND 1440);
+
+-- The auto-accept job scans pending bookings oldest first.
+CREATE INDEX IF NOT EXISTS bookings_pending_created_idx
+    ON bookings (created_at)
+    WHERE status = 'pending';
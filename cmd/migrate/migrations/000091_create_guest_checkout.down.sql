@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS booking_deposits;
+
+DROP TABLE IF EXISTS guest_otps;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS is_guest;
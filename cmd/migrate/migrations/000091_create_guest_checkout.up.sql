@@ -0,0 +1,36 @@
+-- Guests book from a venue's widget with a name and an OTP-verified phone.
+-- Each gets a provisional user: a placeholder email, a password that never
+-- matches and is_active off, so it can't log in. Registering with the same
+-- phone later turns it into a full account and keeps its bookings.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS is_guest BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One live code per phone, stored hashed. A code dies after 5 wrong guesses.
+CREATE TABLE IF NOT EXISTS guest_otps (
+    phone TEXT PRIMARY KEY,
+    code_hash TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The deposit holding a guest's pending booking. The guest pays it with the
+-- checkout token they got when booking; unpaid past expires_at, the booking
+-- is canceled.
+CREATE TABLE IF NOT EXISTS booking_deposits (
+    booking_id BIGINT PRIMARY KEY REFERENCES bookings(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount > 0),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'expired')),
+    token_hash TEXT NOT NULL UNIQUE,
+    provider TEXT,
+    provider_ref TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    paid_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_deposits_unpaid
+ON booking_deposits (expires_at)
+WHERE status = 'pending';
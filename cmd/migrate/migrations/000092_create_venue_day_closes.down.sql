@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS bookings_guard_closed_day ON bookings;
+DROP FUNCTION IF EXISTS fn_bookings_guard_closed_day();
+DROP TABLE IF EXISTS venue_day_closes;
@@ -0,0 +1,61 @@
+-- The summary an owner confirms at the end of each business day (Nepal
+-- date). Rows are never edited: an admin override reopens the day by
+-- stamping reopened_at, and closing it again adds a new row.
+CREATE TABLE IF NOT EXISTS venue_day_closes (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    business_date DATE NOT NULL,
+    bookings_honored INT NOT NULL,
+    no_shows INT NOT NULL,
+    canceled INT NOT NULL,
+    cash_amount INT NOT NULL,
+    counter_other_amount INT NOT NULL,
+    online_amount INT NOT NULL,
+    note TEXT CHECK (char_length(note) <= 500),
+    closed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reopened_at TIMESTAMPTZ,
+    reopened_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reopen_reason TEXT CHECK (char_length(reopen_reason) <= 500)
+);
+
+-- One standing close per venue and day.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_venue_day_closes_active
+    ON venue_day_closes (venue_id, business_date)
+    WHERE reopened_at IS NULL;
+
+-- Bookings of a closed day keep the figures the owner confirmed: their
+-- status, price, payment and time can't change, and none can be added,
+-- until an admin reopens the day. Contact fields stay editable so account
+-- anonymization still works.
+CREATE OR REPLACE FUNCTION fn_bookings_guard_closed_day()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+       AND (NEW.status, NEW.total_price, NEW.paid_amount, NEW.final_amount, NEW.payment_method,
+            NEW.start_time, NEW.end_time, NEW.venue_id)
+           IS NOT DISTINCT FROM
+           (OLD.status, OLD.total_price, OLD.paid_amount, OLD.final_amount, OLD.payment_method,
+            OLD.start_time, OLD.end_time, OLD.venue_id) THEN
+        RETURN NEW;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM venue_day_closes c
+        WHERE c.reopened_at IS NULL
+          AND ((c.venue_id = NEW.venue_id
+                AND c.business_date = (NEW.start_time AT TIME ZONE 'Asia/Kathmandu')::date)
+            OR (TG_OP = 'UPDATE'
+                AND c.venue_id = OLD.venue_id
+                AND c.business_date = (OLD.start_time AT TIME ZONE 'Asia/Kathmandu')::date))
+    ) THEN
+        RAISE EXCEPTION 'booking falls on a closed business day'
+            USING ERRCODE = 'KH001';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bookings_guard_closed_day
+    BEFORE INSERT OR UPDATE ON bookings
+    FOR EACH ROW EXECUTE FUNCTION fn_bookings_guard_closed_day();
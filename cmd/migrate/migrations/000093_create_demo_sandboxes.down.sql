@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS demo_sandboxes;
+
+ALTER TABLE venues
+    DROP CONSTRAINT IF EXISTS venues_demo_not_active,
+    DROP COLUMN IF EXISTS is_demo;
@@ -0,0 +1,20 @@
+-- Demo venues are sandboxes for sales demos. They stay on hold, so players
+-- never find them, and their data is regenerated every night.
+ALTER TABLE venues
+    ADD COLUMN IF NOT EXISTS is_demo BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT venues_demo_not_active CHECK (NOT is_demo OR status <> 'active');
+
+-- One sandbox per sales account. The settings say how much fake data the
+-- nightly regeneration writes around today.
+CREATE TABLE IF NOT EXISTS demo_sandboxes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    venue_id BIGINT NOT NULL UNIQUE REFERENCES venues(id) ON DELETE CASCADE,
+    days_back INT NOT NULL DEFAULT 14 CHECK (days_back BETWEEN 0 AND 30),
+    days_ahead INT NOT NULL DEFAULT 7 CHECK (days_ahead BETWEEN 0 AND 14),
+    bookings_per_day INT NOT NULL DEFAULT 6 CHECK (bookings_per_day BETWEEN 0 AND 15),
+    games_per_week INT NOT NULL DEFAULT 4 CHECK (games_per_week BETWEEN 0 AND 20),
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    regenerated_at TIMESTAMPTZ
+);
@@ -0,0 +1,19 @@
+-- The variant attributes a category's products use, so the store can
+-- validate them and build filters. Subcategories inherit their parents'
+-- definitions; a subcategory may redefine a key.
+CREATE TABLE IF NOT EXISTS category_attributes (
+    id BIGSERIAL PRIMARY KEY,
+    category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL CHECK (key ~ '^[a-z][a-z0-9_]*$'),
+    name VARCHAR(100) NOT NULL CHECK (length(trim(name)) > 0),
+    type VARCHAR(10) NOT NULL CHECK (type IN ('enum', 'number', 'text', 'boolean')),
+    allowed_values TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    filterable BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT category_attributes_enum_values
+        CHECK ((type = 'enum') = (cardinality(allowed_values) > 0)),
+    CONSTRAINT uq_category_attributes_key UNIQUE (category_id, key)
+);
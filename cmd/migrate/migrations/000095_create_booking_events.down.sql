@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS bookings_record_event ON bookings;
+DROP FUNCTION IF EXISTS fn_bookings_record_event();
+DROP TABLE IF EXISTS booking_events;
+DROP FUNCTION IF EXISTS fn_booking_events_append_only();
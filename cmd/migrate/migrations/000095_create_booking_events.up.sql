@@ -0,0 +1,103 @@
+-- Every change to a booking's status or time, in order. A trigger writes
+-- the rows, so each path that moves a booking along is covered. The code
+-- making the change can name who did it and why through the khel.actor_id
+-- and khel.event_source settings of its transaction.
+CREATE TABLE IF NOT EXISTS booking_events (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN (
+        'requested', 'booked', 'accepted', 'rejected', 'canceled',
+        'completed', 'rescheduled', 'status_changed'
+    )),
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    from_start_time TIMESTAMPTZ,
+    from_end_time TIMESTAMPTZ,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    source VARCHAR(30),
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_events_booking
+    ON booking_events (booking_id, occurred_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_booking_events_kind
+    ON booking_events (kind, occurred_at);
+
+CREATE OR REPLACE FUNCTION fn_bookings_record_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_actor BIGINT := NULLIF(current_setting('khel.actor_id', true), '')::BIGINT;
+    v_source TEXT := NULLIF(current_setting('khel.event_source', true), '');
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO booking_events (booking_id, kind, to_status, start_time, end_time, actor_id, source)
+        VALUES (NEW.id,
+                CASE NEW.status::text WHEN 'pending' THEN 'requested' WHEN 'confirmed' THEN 'booked' ELSE 'status_changed' END,
+                NEW.status::text, NEW.start_time, NEW.end_time,
+                COALESCE(v_actor, NEW.user_id), v_source);
+        RETURN NEW;
+    END IF;
+
+    IF (NEW.start_time, NEW.end_time) IS DISTINCT FROM (OLD.start_time, OLD.end_time) THEN
+        INSERT INTO booking_events (booking_id, kind, from_status, to_status, from_start_time, from_end_time,
+                                    start_time, end_time, actor_id, source)
+        VALUES (NEW.id, 'rescheduled', OLD.status::text, OLD.status::text, OLD.start_time, OLD.end_time,
+                NEW.start_time, NEW.end_time, v_actor, v_source);
+    END IF;
+
+    IF NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO booking_events (booking_id, kind, from_status, to_status, start_time, end_time, actor_id, source)
+        VALUES (NEW.id,
+                CASE
+                    WHEN OLD.status::text = 'pending' AND NEW.status::text = 'confirmed' THEN 'accepted'
+                    WHEN NEW.status::text = 'rejected' THEN 'rejected'
+                    WHEN NEW.status::text = 'canceled' THEN 'canceled'
+                    WHEN NEW.status::text = 'done' THEN 'completed'
+                    ELSE 'status_changed'
+                END,
+                OLD.status::text, NEW.status::text, NEW.start_time, NEW.end_time, v_actor, v_source);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bookings_record_event
+    AFTER INSERT OR UPDATE OF status, start_time, end_time ON bookings
+    FOR EACH ROW EXECUTE FUNCTION fn_bookings_record_event();
+
+-- Events are never edited. Deleting is left to the cascade from bookings,
+-- which runs one trigger level down.
+CREATE OR REPLACE FUNCTION fn_booking_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' OR pg_trigger_depth() = 1 THEN
+        RAISE EXCEPTION 'booking_events is append-only';
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER booking_events_append_only
+    BEFORE UPDATE OR DELETE ON booking_events
+    FOR EACH ROW EXECUTE FUNCTION fn_booking_events_append_only();
+
+-- What existing bookings can still tell: when they were made and, for
+-- those since answered, their current status as of their last update.
+INSERT INTO booking_events (booking_id, kind, to_status, start_time, end_time, actor_id, source, occurred_at)
+SELECT b.id, 'requested', 'pending', b.start_time, b.end_time, b.user_id, 'backfill', b.created_at
+FROM bookings b;
+
+INSERT INTO booking_events (booking_id, kind, from_status, to_status, start_time, end_time, source, occurred_at)
+SELECT b.id,
+       CASE b.status::text
+           WHEN 'confirmed' THEN 'accepted'
+           WHEN 'rejected' THEN 'rejected'
+           WHEN 'canceled' THEN 'canceled'
+           WHEN 'done' THEN 'completed'
+       END,
+       'pending', b.status::text, b.start_time, b.end_time, 'backfill', b.updated_at
+FROM bookings b
+WHERE b.status::text IN ('confirmed', 'rejected', 'canceled', 'done');
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS photo_migration_items;
+DROP TABLE IF EXISTS photo_migrations;
@@ -0,0 +1,36 @@
+-- An admin-started pass that re-encodes venue photos stored on Cloudinary
+-- into a lighter format. Each photo is an item, so a run survives restarts
+-- and reports what it saved.
+CREATE TABLE IF NOT EXISTS photo_migrations (
+    id BIGSERIAL PRIMARY KEY,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('webp', 'avif')),
+    max_width INT NOT NULL CHECK (max_width BETWEEN 320 AND 4096),
+    per_minute INT NOT NULL CHECK (per_minute BETWEEN 1 AND 60),
+    status VARCHAR(20) NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'completed', 'canceled')),
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+-- One pass at a time, so two never race over the same photo.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_photo_migrations_active
+    ON photo_migrations ((TRUE)) WHERE status = 'running';
+
+CREATE TABLE IF NOT EXISTS photo_migration_items (
+    id BIGSERIAL PRIMARY KEY,
+    migration_id BIGINT NOT NULL REFERENCES photo_migrations(id) ON DELETE CASCADE,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    old_url TEXT NOT NULL,
+    new_url TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'done', 'skipped', 'failed')),
+    bytes_before BIGINT,
+    bytes_after BIGINT,
+    error TEXT,
+    processed_at TIMESTAMPTZ,
+    UNIQUE (migration_id, venue_id, old_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_photo_migration_items_pending
+    ON photo_migration_items (migration_id, id) WHERE status = 'pending';
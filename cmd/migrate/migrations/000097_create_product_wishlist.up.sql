@@ -0,0 +1,15 @@
+-- Products a shopper saved for later, optionally pinned to one variant.
+-- seen_price_cents is the price the shopper last knew about: when they
+-- saved it, or the one in the last price-drop push. A drop is measured
+-- against it so each one is announced once.
+CREATE TABLE IF NOT EXISTS product_wishlist (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id BIGINT REFERENCES product_variants(id) ON DELETE SET NULL,
+    seen_price_cents BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_wishlist_product
+    ON product_wishlist (product_id);
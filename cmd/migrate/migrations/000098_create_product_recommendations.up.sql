@@ -0,0 +1,15 @@
+-- Products bought together, recomputed nightly from paid orders. Rows are
+-- per side of a pair, keeping each product's best few, so a product's list
+-- is one index scan.
+CREATE TABLE IF NOT EXISTS product_recommendations (
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    related_product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    orders INT NOT NULL CHECK (orders > 0),
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, related_product_id),
+    CHECK (product_id <> related_product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_recommendations_score
+    ON product_recommendations (product_id, score DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE venues DROP COLUMN IF EXISTS image_variants;
+ALTER TABLE ads DROP COLUMN IF EXISTS image_variants;
+ALTER TABLE product_images DROP COLUMN IF EXISTS variants;
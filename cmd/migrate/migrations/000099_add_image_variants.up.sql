@@ -0,0 +1,11 @@
+-- Sizes and placeholders of uploaded images, filled in by a background job.
+-- NULL (or, for venues, a missing key) means not processed yet. Venue
+-- photos are an array, so their variants are keyed by URL.
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS variants JSONB;
+
+ALTER TABLE ads
+    ADD COLUMN IF NOT EXISTS image_variants JSONB;
+
+ALTER TABLE venues
+    ADD COLUMN IF NOT EXISTS image_variants JSONB NOT NULL DEFAULT '{}'::jsonb;
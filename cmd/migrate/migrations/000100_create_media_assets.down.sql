@@ -0,0 +1,2 @@
+DROP VIEW IF EXISTS media_references;
+DROP TABLE IF EXISTS media_assets;
@@ -0,0 +1,29 @@
+-- Every file the API uploads, so ones no row points at can be found and
+-- deleted. Files uploaded before this table, or replaced in place under a
+-- fixed name, are never listed and so never collected.
+CREATE TABLE IF NOT EXISTS media_assets (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT NOT NULL UNIQUE,
+    storage TEXT NOT NULL,
+    folder TEXT NOT NULL DEFAULT '',
+    bytes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_assets_created ON media_assets (created_at);
+
+-- Every column that holds an uploaded file's URL. A table that starts
+-- storing uploads must be added here, or its files will be collected.
+CREATE OR REPLACE VIEW media_references (url) AS
+    SELECT profile_picture_url FROM users WHERE profile_picture_url IS NOT NULL
+    UNION ALL SELECT unnest(image_urls) FROM venues
+    UNION ALL SELECT unnest(image_urls) FROM facilities
+    UNION ALL SELECT unnest(image_urls) FROM categories
+    UNION ALL SELECT logo_url FROM brands WHERE logo_url IS NOT NULL
+    UNION ALL SELECT url FROM product_images
+    UNION ALL SELECT image_url FROM ads
+    UNION ALL SELECT image_url FROM venue_inventory_items WHERE image_url IS NOT NULL
+    UNION ALL SELECT logo_url FROM organizations WHERE logo_url IS NOT NULL
+    UNION ALL SELECT unnest(attachment_urls) FROM support_ticket_messages
+    UNION ALL SELECT url FROM booking_dispute_evidence
+    UNION ALL SELECT url FROM booking_receipts;
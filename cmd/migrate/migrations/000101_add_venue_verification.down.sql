@@ -0,0 +1,23 @@
+CREATE OR REPLACE VIEW media_references (url) AS
+    SELECT profile_picture_url FROM users WHERE profile_picture_url IS NOT NULL
+    UNION ALL SELECT unnest(image_urls) FROM venues
+    UNION ALL SELECT unnest(image_urls) FROM facilities
+    UNION ALL SELECT unnest(image_urls) FROM categories
+    UNION ALL SELECT logo_url FROM brands WHERE logo_url IS NOT NULL
+    UNION ALL SELECT url FROM product_images
+    UNION ALL SELECT image_url FROM ads
+    UNION ALL SELECT image_url FROM venue_inventory_items WHERE image_url IS NOT NULL
+    UNION ALL SELECT logo_url FROM organizations WHERE logo_url IS NOT NULL
+    UNION ALL SELECT unnest(attachment_urls) FROM support_ticket_messages
+    UNION ALL SELECT url FROM booking_dispute_evidence
+    UNION ALL SELECT url FROM booking_receipts;
+
+DROP TABLE IF EXISTS venue_verification_documents;
+
+ALTER TABLE venues
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS review_reason;
+
+-- Enum values can't be dropped; park the venues under review instead.
+UPDATE venues SET status = 'requested' WHERE status = 'pending_review';
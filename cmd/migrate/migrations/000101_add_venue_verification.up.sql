@@ -0,0 +1,39 @@
+-- New venues wait here until a superadmin has looked at their ownership
+-- documents. The value can't be used in this migration's transaction, so
+-- the column default stays 'requested' and the API sets it on insert.
+ALTER TYPE venue_status ADD VALUE IF NOT EXISTS 'pending_review';
+
+ALTER TABLE venues
+    ADD COLUMN IF NOT EXISTS review_reason TEXT,
+    ADD COLUMN IF NOT EXISTS reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+-- Proof that the owner runs the venue. The files are uploaded privately and
+-- only reachable through short-lived signed links.
+CREATE TABLE IF NOT EXISTS venue_verification_documents (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('ownership', 'lease', 'business_registration', 'tax_registration', 'other')),
+    url TEXT NOT NULL,
+    file_name TEXT NOT NULL DEFAULT '',
+    uploaded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_venue_verification_documents_venue
+    ON venue_verification_documents (venue_id, created_at);
+
+CREATE OR REPLACE VIEW media_references (url) AS
+    SELECT profile_picture_url FROM users WHERE profile_picture_url IS NOT NULL
+    UNION ALL SELECT unnest(image_urls) FROM venues
+    UNION ALL SELECT unnest(image_urls) FROM facilities
+    UNION ALL SELECT unnest(image_urls) FROM categories
+    UNION ALL SELECT logo_url FROM brands WHERE logo_url IS NOT NULL
+    UNION ALL SELECT url FROM product_images
+    UNION ALL SELECT image_url FROM ads
+    UNION ALL SELECT image_url FROM venue_inventory_items WHERE image_url IS NOT NULL
+    UNION ALL SELECT logo_url FROM organizations WHERE logo_url IS NOT NULL
+    UNION ALL SELECT unnest(attachment_urls) FROM support_ticket_messages
+    UNION ALL SELECT url FROM booking_dispute_evidence
+    UNION ALL SELECT url FROM booking_receipts
+    UNION ALL SELECT url FROM venue_verification_documents;
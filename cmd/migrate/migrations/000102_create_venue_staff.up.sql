@@ -0,0 +1,29 @@
+-- People other than the owner who run a venue. A row starts as an
+-- invitation to an email or phone number and becomes membership once a user
+-- with that email or phone accepts it.
+CREATE TABLE IF NOT EXISTS venue_staff (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('manager', 'staff')),
+    invited_email TEXT,
+    invited_phone TEXT,
+    invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (invited_email IS NOT NULL OR invited_phone IS NOT NULL),
+    CHECK ((accepted_at IS NULL) = (user_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_venue_staff_member
+    ON venue_staff (venue_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_venue_staff_invited_email
+    ON venue_staff (venue_id, LOWER(invited_email)) WHERE accepted_at IS NULL AND invited_email IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_venue_staff_invited_phone
+    ON venue_staff (venue_id, invited_phone) WHERE accepted_at IS NULL AND invited_phone IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_venue_staff_user ON venue_staff (user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_venue_staff_pending_email
+    ON venue_staff (LOWER(invited_email)) WHERE accepted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_venue_staff_pending_phone
+    ON venue_staff (invited_phone) WHERE accepted_at IS NULL;
@@ -0,0 +1,18 @@
+-- Per-venue limits on what players can book and when they can cancel.
+-- Venues without a row, and NULL columns, have no limit. auto_confirm skips
+-- the owner's approval for bookings that pass the rules.
+-- guest_deposit_percent is the share of the price guests booking from the
+-- widget pay up front; NULL uses the platform default.
+CREATE TABLE IF NOT EXISTS venue_booking_rules (
+    venue_id BIGINT PRIMARY KEY REFERENCES venues(id) ON DELETE CASCADE,
+    min_duration_minutes INT CHECK (min_duration_minutes > 0),
+    max_duration_minutes INT CHECK (max_duration_minutes > 0),
+    max_advance_days INT CHECK (max_advance_days > 0),
+    cancel_cutoff_hours INT CHECK (cancel_cutoff_hours >= 0),
+    auto_confirm BOOLEAN NOT NULL DEFAULT FALSE,
+    guest_deposit_percent INT CHECK (guest_deposit_percent BETWEEN 1 AND 100),
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (min_duration_minutes IS NULL OR max_duration_minutes IS NULL
+           OR min_duration_minutes <= max_duration_minutes)
+);
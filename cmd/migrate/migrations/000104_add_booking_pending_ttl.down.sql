@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bookings_pending_created;
+
+ALTER TABLE venue_booking_rules DROP COLUMN IF EXISTS pending_ttl_minutes;
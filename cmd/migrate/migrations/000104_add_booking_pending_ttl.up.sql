@@ -0,0 +1,8 @@
+-- How long a booking request may wait for the venue before it is rejected
+-- automatically. NULL uses the platform default (BOOKING_PENDING_TTL).
+ALTER TABLE venue_booking_rules
+    ADD COLUMN IF NOT EXISTS pending_ttl_minutes INT CHECK (pending_ttl_minutes > 0);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_pending_created
+    ON bookings (created_at)
+    WHERE status = 'pending';
@@ -0,0 +1,19 @@
+-- Attendance recorded when the venue scans a player's check-in QR code,
+-- either for a booking (its booker) or for a game (one of its players).
+CREATE TABLE IF NOT EXISTS check_ins (
+    id BIGSERIAL PRIMARY KEY,
+    venue_id BIGINT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE CASCADE,
+    game_id BIGINT REFERENCES games(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    checked_in_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    checked_in_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((booking_id IS NULL) <> (game_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_check_ins_booking_user
+    ON check_ins (booking_id, user_id) WHERE booking_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_check_ins_game_user
+    ON check_ins (game_id, user_id) WHERE game_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_check_ins_venue ON check_ins (venue_id, checked_in_at);
+CREATE INDEX IF NOT EXISTS idx_check_ins_user ON check_ins (user_id);
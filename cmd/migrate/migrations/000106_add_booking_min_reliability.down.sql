@@ -0,0 +1 @@
+ALTER TABLE venue_booking_rules DROP COLUMN IF EXISTS min_reliability_score;
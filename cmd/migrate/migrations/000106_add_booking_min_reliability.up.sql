@@ -0,0 +1,5 @@
+-- Auto-confirm only players whose reliability score is at least this; the
+-- rest wait for the owner. NULL auto-confirms everyone.
+ALTER TABLE venue_booking_rules
+    ADD COLUMN IF NOT EXISTS min_reliability_score INT
+        CHECK (min_reliability_score BETWEEN 0 AND 100);
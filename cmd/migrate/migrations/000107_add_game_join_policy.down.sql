@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_game_join_requests_pending;
+
+ALTER TABLE games DROP COLUMN IF EXISTS join_policy;
@@ -0,0 +1,11 @@
+-- How requests to join a game are handled: auto_accept seats players
+-- straight away while there is room and leaves the rest for the admin,
+-- manual waits for the admin, invite_only takes no requests.
+ALTER TABLE games
+    ADD COLUMN IF NOT EXISTS join_policy TEXT NOT NULL DEFAULT 'manual'
+        CHECK (join_policy IN ('auto_accept', 'manual', 'invite_only'));
+
+-- Pending requests are rejected once their game starts.
+CREATE INDEX IF NOT EXISTS idx_game_join_requests_pending
+    ON game_join_requests (game_id)
+    WHERE status = 'pending';
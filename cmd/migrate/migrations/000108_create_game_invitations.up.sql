@@ -0,0 +1,27 @@
+-- Invitations into a game, past its join policy and request queue. A row
+-- with invitee_id is for one user; a row without is a shareable link whose
+-- code mixes in link_nonce so it can't be guessed from the id. Links stay
+-- pending until revoked, expired or used max_uses times.
+CREATE TABLE IF NOT EXISTS game_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    invitee_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    link_nonce INT,
+    max_uses INT CHECK (max_uses > 0),
+    uses INT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    expires_at TIMESTAMPTZ,
+    responded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((invitee_id IS NULL) = (link_nonce IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_game_invitations_pending_invitee
+    ON game_invitations (game_id, invitee_id)
+    WHERE invitee_id IS NOT NULL AND status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_game_invitations_invitee
+    ON game_invitations (invitee_id)
+    WHERE status = 'pending';
@@ -0,0 +1,3 @@
+ALTER TABLE games DROP COLUMN IF EXISTS payment_reminded_at;
+
+DROP TABLE IF EXISTS game_payments;
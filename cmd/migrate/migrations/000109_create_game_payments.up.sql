@@ -0,0 +1,15 @@
+-- Cash collected by a game's admin from its players. Every player but the
+-- admin owes the game's price; a row here means they have paid it.
+CREATE TABLE IF NOT EXISTS game_payments (
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount >= 0),
+    method TEXT NOT NULL DEFAULT 'cash' CHECK (method IN ('cash')),
+    marked_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    paid_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, user_id)
+);
+
+-- Set once players still owing were reminded, the day after the game.
+ALTER TABLE games
+    ADD COLUMN IF NOT EXISTS payment_reminded_at TIMESTAMPTZ;
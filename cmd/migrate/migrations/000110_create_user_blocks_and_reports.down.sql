@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_reports;
+DROP TABLE IF EXISTS blocked_users;
@@ -0,0 +1,39 @@
+-- A block hides the two users from each other both ways: neither sees the
+-- other's games or join requests. Only the blocker can lift it.
+CREATE TABLE IF NOT EXISTS blocked_users (
+    blocker_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+
+    CONSTRAINT blocked_users_not_self CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blocked_users_blocked
+ON blocked_users (blocked_id);
+
+-- Reports of a player's behaviour, triaged by admins.
+-- status: open | actioned (admin acted on it) | dismissed
+CREATE TABLE IF NOT EXISTS user_reports (
+    id BIGSERIAL PRIMARY KEY,
+    reporter_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reported_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_id BIGINT REFERENCES games(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    resolution_note TEXT,
+    resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT user_reports_not_self CHECK (reporter_id <> reported_id),
+    CONSTRAINT user_reports_valid_reason CHECK (reason IN ('harassment', 'abusive_language', 'cheating', 'no_show', 'fake_profile', 'other')),
+    CONSTRAINT user_reports_valid_status CHECK (status IN ('open', 'actioned', 'dismissed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_reports_status_created
+ON user_reports (status, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_user_reports_reported
+ON user_reports (reported_id);
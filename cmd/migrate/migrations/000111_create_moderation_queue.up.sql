@@ -0,0 +1,29 @@
+-- Text the moderation checker flagged, waiting for an admin. content_id
+-- points at the review, game question or reply, or for profile_name at the
+-- user; content is the text as it was flagged. Held reviews stay hidden
+-- until approved; everything else stays up unless removed.
+CREATE TABLE IF NOT EXISTS moderation_queue (
+    id BIGSERIAL PRIMARY KEY,
+    content_type TEXT NOT NULL,
+    content_id BIGINT NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    checker TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT moderation_queue_valid_type CHECK (content_type IN ('review', 'game_question', 'game_reply', 'profile_name')),
+    CONSTRAINT moderation_queue_valid_status CHECK (status IN ('pending', 'approved', 'removed'))
+);
+
+-- One pending item per piece of content; a profile name flagged again
+-- refreshes the pending item instead.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_moderation_queue_pending_content
+ON moderation_queue (content_type, content_id)
+WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_moderation_queue_status_created
+ON moderation_queue (status, created_at);
@@ -0,0 +1,148 @@
+package main
+
+// Fixture data. Everything is fictional; names and places are picked so the
+// app looks like it does in Kathmandu.
+
+type seedUser struct {
+	first, last, skill string
+	role               string // extra role on top of customer, if any
+}
+
+var seedUsers = []seedUser{
+	{"Pasang", "Sherpa", "advanced", "owner"},
+	{"Anjali", "Shrestha", "intermediate", "owner"},
+	{"Admin", "Khel", "intermediate", "admin"},
+	{"Store", "Merchant", "beginner", "merchant"},
+	{"Aarav", "Karki", "advanced", ""},
+	{"Bibek", "Thapa", "intermediate", ""},
+	{"Sushant", "Rai", "advanced", ""},
+	{"Nischal", "Gurung", "beginner", ""},
+	{"Prabin", "Magar", "intermediate", ""},
+	{"Sabina", "Tamang", "beginner", ""},
+	{"Rojina", "Maharjan", "intermediate", ""},
+	{"Kiran", "Adhikari", "advanced", ""},
+	{"Sandesh", "Poudel", "intermediate", ""},
+	{"Ritika", "Basnet", "beginner", ""},
+	{"Ujjwal", "Bhandari", "advanced", ""},
+	{"Manish", "Lama", "intermediate", ""},
+	{"Srijana", "KC", "beginner", ""},
+	{"Dipesh", "Joshi", "intermediate", ""},
+	{"Nabin", "Khadka", "advanced", ""},
+	{"Asmita", "Dahal", "intermediate", ""},
+	{"Roshan", "Bista", "beginner", ""},
+	{"Kabita", "Pandey", "intermediate", ""},
+}
+
+type seedVenue struct {
+	name, address, sport, phone string
+	lat, lng                    float64
+	amenities                   []string
+	// hourly prices for the morning, day and evening slots
+	prices [3]int
+}
+
+var seedVenues = []seedVenue{
+	{"Baneshwor Futsal Arena", "New Baneshwor, Kathmandu", "futsal", "9801000001", 27.6915, 85.3420,
+		[]string{"parking", "changing_room", "drinking_water", "floodlights"}, [3]int{1200, 1500, 2000}},
+	{"Thamel Sports Hub", "Thamel, Kathmandu", "futsal", "9801000002", 27.7154, 85.3123,
+		[]string{"cafe", "changing_room", "floodlights"}, [3]int{1300, 1600, 2200}},
+	{"Jhamsikhel Hoops", "Jhamsikhel, Lalitpur", "basketball", "9801000003", 27.6805, 85.3095,
+		[]string{"parking", "drinking_water"}, [3]int{800, 1000, 1400}},
+	{"Boudha Shuttle Court", "Boudha, Kathmandu", "badminton", "9801000004", 27.7215, 85.3620,
+		[]string{"changing_room", "equipment_rental"}, [3]int{600, 700, 900}},
+	{"Kalanki Cricket Ground", "Kalanki, Kathmandu", "cricket", "9801000005", 27.6933, 85.2816,
+		[]string{"parking", "seating", "drinking_water"}, [3]int{2500, 3000, 3500}},
+	{"Maharajgunj Tennis Club", "Maharajgunj, Kathmandu", "tennis", "9801000006", 27.7369, 85.3306,
+		[]string{"parking", "cafe", "changing_room"}, [3]int{1000, 1200, 1500}},
+	{"Koteshwor Kick Off", "Koteshwor, Kathmandu", "futsal", "9801000007", 27.6780, 85.3490,
+		[]string{"parking", "floodlights", "first_aid"}, [3]int{1100, 1400, 1900}},
+	{"Bhaktapur Durbar Futsal", "Suryabinayak, Bhaktapur", "futsal", "9801000008", 27.6710, 85.4298,
+		[]string{"parking", "drinking_water", "floodlights"}, [3]int{1000, 1200, 1700}},
+}
+
+// venueSlots are the daily pricing slots, matched to seedVenue.prices.
+var venueSlots = [3][2]string{
+	{"06:00", "10:00"},
+	{"10:00", "16:00"},
+	{"16:00", "21:00"},
+}
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+type seedCategory struct {
+	name, slug, parent string
+}
+
+// Parents come before their children.
+var seedCategories = []seedCategory{
+	{"Footwear", "footwear", ""},
+	{"Futsal Shoes", "futsal-shoes", "footwear"},
+	{"Running Shoes", "running-shoes", "footwear"},
+	{"Apparel", "apparel", ""},
+	{"Jerseys", "jerseys", "apparel"},
+	{"Equipment", "equipment", ""},
+	{"Balls", "balls", "equipment"},
+	{"Rackets", "rackets", "equipment"},
+	{"Cricket Gear", "cricket-gear", "equipment"},
+}
+
+type seedBrand struct {
+	name, slug string
+}
+
+var seedBrands = []seedBrand{
+	{"Nike", "nike"},
+	{"Adidas", "adidas"},
+	{"Puma", "puma"},
+	{"Yonex", "yonex"},
+	{"Li-Ning", "li-ning"},
+	{"Kookaburra", "kookaburra"},
+	{"Goldstar", "goldstar"},
+}
+
+type seedVariant struct {
+	sku        string
+	priceCents int64
+	attributes map[string]string
+}
+
+type seedProduct struct {
+	name, slug, description, category, brand string
+	variants                                 []seedVariant
+}
+
+func sizes(prefix string, priceCents int64, sizes ...string) []seedVariant {
+	out := make([]seedVariant, 0, len(sizes))
+	for _, s := range sizes {
+		out = append(out, seedVariant{
+			sku:        prefix + "-" + s,
+			priceCents: priceCents,
+			attributes: map[string]string{"size": s},
+		})
+	}
+	return out
+}
+
+var seedProducts = []seedProduct{
+	{"Nike Streetgato", "nike-streetgato", "Low-profile indoor shoe with a gum sole for futsal courts.", "futsal-shoes", "nike",
+		sizes("SEED-NK-STG", 899900, "40", "41", "42", "43", "44")},
+	{"Adidas Top Sala", "adidas-top-sala", "Suede upper and non-marking sole for quick cuts.", "futsal-shoes", "adidas",
+		sizes("SEED-AD-TSL", 799900, "40", "41", "42", "43")},
+	{"Goldstar Runner 032", "goldstar-runner-032", "Everyday running shoe, made in Nepal.", "running-shoes", "goldstar",
+		sizes("SEED-GS-032", 249900, "39", "40", "41", "42", "43", "44")},
+	{"Puma teamGOAL Jersey", "puma-teamgoal-jersey", "Breathable match jersey.", "jerseys", "puma",
+		sizes("SEED-PM-TGJ", 299900, "S", "M", "L", "XL")},
+	{"Adidas Al Rihla Futsal Ball", "adidas-al-rihla-futsal-ball", "Low-bounce size 4 futsal ball.", "balls", "adidas",
+		[]seedVariant{{"SEED-AD-ARF", 449900, map[string]string{"size": "4"}}}},
+	{"Nike Elite Basketball", "nike-elite-basketball", "Composite leather ball for indoor courts.", "balls", "nike",
+		[]seedVariant{{"SEED-NK-ELB", 549900, map[string]string{"size": "7"}}}},
+	{"Yonex Astrox 77", "yonex-astrox-77", "Head-heavy racket for attacking play.", "rackets", "yonex",
+		[]seedVariant{
+			{"SEED-YX-AX77-3U", 1899900, map[string]string{"weight": "3U"}},
+			{"SEED-YX-AX77-4U", 1899900, map[string]string{"weight": "4U"}},
+		}},
+	{"Li-Ning Windstorm 72", "li-ning-windstorm-72", "Very light racket for beginners.", "rackets", "li-ning",
+		[]seedVariant{{"SEED-LN-WS72", 999900, map[string]string{"weight": "6U"}}}},
+	{"Kookaburra Kahuna Bat", "kookaburra-kahuna-bat", "English willow bat with a mid-low sweet spot.", "cricket-gear", "kookaburra",
+		sizes("SEED-KB-KHN", 2499900, "SH", "LH")},
+}
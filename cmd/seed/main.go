@@ -0,0 +1,386 @@
+// Command seed fills a local database with fixture data: users, venues
+// around Kathmandu with weekly pricing, upcoming games, and a small store
+// catalog. It is meant for development only and refuses to run against
+// prod.
+//
+//	make seed
+//	go run ./cmd/seed -games 30
+//
+// Every seeded user signs in with the password printed at the end. Running
+// it again is a no-op unless -force is given.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/db"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	seedPassword = "khel-seed-123"
+	emailDomain  = "seed.khel.dev"
+)
+
+func main() {
+	games := flag.Int("games", 20, "number of upcoming games to create")
+	force := flag.Bool("force", false, "seed again even if seed users already exist")
+	randSeed := flag.Uint64("rand", 1, "random seed, so runs produce the same data")
+	flag.Parse()
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+	if env == "prod" {
+		log.Fatal("refusing to seed a prod database")
+	}
+	if os.Getenv("DOCKER") == "" {
+		_ = godotenv.Load(".env." + env)
+	}
+	addr := os.Getenv("DB_ADDR")
+	if addr == "" {
+		log.Fatal("DB_ADDR is required")
+	}
+
+	pool, err := db.New(addr, 4, "1m")
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	s := &seeder{
+		rnd:      rand.New(rand.NewPCG(*randSeed, *randSeed)),
+		roleIDs:  map[string]int64{},
+		catIDs:   map[string]int64{},
+		brandIDs: map[string]int64{},
+	}
+
+	if !*force {
+		var seeded bool
+		err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE email LIKE '%@' || $1)`, emailDomain).Scan(&seeded)
+		if err != nil {
+			log.Fatalf("check for seed data: %v", err)
+		}
+		if seeded {
+			log.Printf("seed users already exist (@%s), nothing to do; pass -force to add another batch", emailDomain)
+			return
+		}
+	}
+
+	if err := s.run(ctx, pool, *games); err != nil {
+		log.Fatalf("seed: %v", err)
+	}
+
+	log.Printf("seeded %d users, %d venues, %d games, %d products", len(s.userIDs), len(s.venueIDs), s.games, s.products)
+	log.Printf("sign in as any %s user, e.g. %s, with password %q", emailDomain, s.emails[0], seedPassword)
+}
+
+type seeder struct {
+	rnd *rand.Rand
+	// batch tells -force runs apart, so emails and phones stay unique.
+	batch int
+
+	roleIDs  map[string]int64
+	userIDs  []int64
+	emails   []string
+	owners   []int64
+	players  []int64
+	venueIDs []int64
+	sports   []string
+	catIDs   map[string]int64
+	brandIDs map[string]int64
+
+	games    int
+	products int
+}
+
+func (s *seeder) run(ctx context.Context, pool *pgxpool.Pool, games int) error {
+	return database.WithTx(pool, ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email LIKE 'pasang.sherpa%@' || $1`, emailDomain).Scan(&s.batch)
+		if err != nil {
+			return fmt.Errorf("count earlier batches: %w", err)
+		}
+
+		steps := []struct {
+			name string
+			fn   func(context.Context, pgx.Tx) error
+		}{
+			{"roles", s.loadRoles},
+			{"users", s.seedUsers},
+			{"venues", s.seedVenues},
+			{"games", func(ctx context.Context, tx pgx.Tx) error { return s.seedGames(ctx, tx, games) }},
+			{"catalog", s.seedCatalog},
+		}
+		for _, step := range steps {
+			if err := step.fn(ctx, tx); err != nil {
+				return fmt.Errorf("%s: %w", step.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *seeder) loadRoles(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `SELECT id, name FROM roles`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		s.roleIDs[name] = id
+	}
+	return rows.Err()
+}
+
+func (s *seeder) seedUsers(ctx context.Context, tx pgx.Tx) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(seedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	suffix := ""
+	if s.batch > 0 {
+		suffix = fmt.Sprint(s.batch + 1)
+	}
+
+	for i, u := range seedUsers {
+		email := fmt.Sprintf("%s.%s%s@%s", strings.ToLower(u.first), strings.ToLower(u.last), suffix, emailDomain)
+		phone := fmt.Sprintf("97%04d%04d", s.batch, i)
+
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO users (email, phone, password, first_name, last_name, skill_level, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, TRUE)
+			RETURNING id
+		`, email, phone, hash, u.first, u.last, u.skill).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("insert %s: %w", email, err)
+		}
+
+		roles := []string{"customer"}
+		if u.role != "" {
+			roles = append(roles, u.role)
+		}
+		for _, role := range roles {
+			roleID, ok := s.roleIDs[role]
+			if !ok {
+				return fmt.Errorf("role %q is missing, run the migrations first", role)
+			}
+			if _, err := tx.Exec(ctx, `INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, id, roleID); err != nil {
+				return fmt.Errorf("assign %s to %s: %w", role, email, err)
+			}
+		}
+
+		s.userIDs = append(s.userIDs, id)
+		s.emails = append(s.emails, email)
+		switch u.role {
+		case "owner":
+			s.owners = append(s.owners, id)
+		case "":
+			s.players = append(s.players, id)
+		}
+	}
+	return nil
+}
+
+func (s *seeder) seedVenues(ctx context.Context, tx pgx.Tx) error {
+	for i, v := range seedVenues {
+		owner := s.owners[i%len(s.owners)]
+		images := []string{fmt.Sprintf("https://picsum.photos/seed/khel-venue-%d/800/600", i+1)}
+
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO venues (owner_id, name, address, location, description, amenities,
+			                    open_time, image_urls, sport, phone_number, status)
+			VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326)::geography, $6, $7,
+			        '06:00 - 21:00', $8, $9, $10, 'active')
+			RETURNING id
+		`, owner, v.name, v.address, v.lng, v.lat,
+			fmt.Sprintf("A %s venue in %s.", v.sport, strings.Split(v.address, ",")[0]),
+			v.amenities, images, v.sport, v.phone).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("insert %s: %w", v.name, err)
+		}
+
+		// Bookings and pricing hang off a facility; give each venue the
+		// same default one the facilities migration gave existing venues.
+		var facilityID int64
+		err = tx.QueryRow(ctx, `
+			INSERT INTO facilities (venue_id, name, sport, is_default)
+			VALUES ($1, 'Main Facility', $2, TRUE)
+			RETURNING id
+		`, id, v.sport).Scan(&facilityID)
+		if err != nil {
+			return fmt.Errorf("insert facility for %s: %w", v.name, err)
+		}
+
+		for _, day := range weekdays {
+			for slot, times := range venueSlots {
+				price := v.prices[slot]
+				if day == "saturday" {
+					price += price / 5 // Saturday is the weekend here
+				}
+				_, err := tx.Exec(ctx, `
+					INSERT INTO venue_pricing (venue_id, facility_id, day_of_week, start_time, end_time, price)
+					VALUES ($1, $2, $3, $4, $5, $6)
+				`, id, facilityID, day, times[0], times[1], price)
+				if err != nil {
+					return fmt.Errorf("insert pricing for %s: %w", v.name, err)
+				}
+			}
+		}
+
+		s.venueIDs = append(s.venueIDs, id)
+		s.sports = append(s.sports, v.sport)
+	}
+	return nil
+}
+
+func (s *seeder) seedGames(ctx context.Context, tx pgx.Tx, n int) error {
+	levels := []string{"beginner", "intermediate", "advanced"}
+	today := time.Now().Truncate(24 * time.Hour)
+
+	for range n {
+		i := s.rnd.IntN(len(s.venueIDs))
+		start := today.Add(time.Duration(1+s.rnd.IntN(7))*24*time.Hour + time.Duration(6+s.rnd.IntN(14))*time.Hour)
+		maxPlayers := 6 + 2*s.rnd.IntN(6)
+		admin := s.players[s.rnd.IntN(len(s.players))]
+		visibility := "public"
+		if s.rnd.IntN(5) == 0 {
+			visibility = "private"
+		}
+
+		var gameID int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO games (sport_type, price, format, venue_id, admin_id, max_players, game_level,
+			                   start_time, end_time, visibility, instruction)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id
+		`, s.sports[i], 100*(1+s.rnd.IntN(5)), fmt.Sprintf("%dv%d", maxPlayers/2, maxPlayers/2),
+			s.venueIDs[i], admin, maxPlayers, levels[s.rnd.IntN(len(levels))],
+			start, start.Add(time.Hour), visibility, "Bring both a light and a dark shirt.").Scan(&gameID)
+		if err != nil {
+			return fmt.Errorf("insert game: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO game_players (game_id, user_id, role) VALUES ($1, $2, 'admin')
+		`, gameID, admin); err != nil {
+			return fmt.Errorf("add game admin: %w", err)
+		}
+
+		// Fill part of the game from the other players.
+		target, joined := 1+s.rnd.IntN(maxPlayers), 1
+		for _, p := range s.rnd.Perm(len(s.players)) {
+			if joined >= target {
+				break
+			}
+			if s.players[p] == admin {
+				continue
+			}
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO game_players (game_id, user_id, role) VALUES ($1, $2, 'player')
+			`, gameID, s.players[p]); err != nil {
+				return fmt.Errorf("add game player: %w", err)
+			}
+			joined++
+		}
+		s.games++
+	}
+	return nil
+}
+
+func (s *seeder) seedCatalog(ctx context.Context, tx pgx.Tx) error {
+	for _, c := range seedCategories {
+		var parent *int64
+		if c.parent != "" {
+			id := s.catIDs[c.parent]
+			parent = &id
+		}
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO categories (name, slug, parent_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, c.name, c.slug, parent).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("upsert category %s: %w", c.slug, err)
+		}
+		s.catIDs[c.slug] = id
+	}
+
+	for _, b := range seedBrands {
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO brands (name, slug)
+			VALUES ($1, $2)
+			ON CONFLICT (name) DO UPDATE SET slug = COALESCE(brands.slug, EXCLUDED.slug)
+			RETURNING id
+		`, b.name, b.slug).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("upsert brand %s: %w", b.slug, err)
+		}
+		s.brandIDs[b.slug] = id
+	}
+
+	for i, p := range seedProducts {
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO products (name, slug, description, category_id, brand_id)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (slug) DO NOTHING
+			RETURNING id
+		`, p.name, p.slug, p.description, s.catIDs[p.category], s.brandIDs[p.brand]).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // already there from an earlier run
+		}
+		if err != nil {
+			return fmt.Errorf("insert product %s: %w", p.slug, err)
+		}
+
+		for _, v := range p.variants {
+			attrs, err := json.Marshal(v.attributes)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO product_variants (product_id, sku, price_cents, attributes)
+				VALUES ($1, $2, $3, $4)
+			`, id, v.sku, v.priceCents, attrs); err != nil {
+				return fmt.Errorf("insert variant %s: %w", v.sku, err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_images (product_id, url, alt, is_primary)
+			VALUES ($1, $2, $3, TRUE)
+		`, id, fmt.Sprintf("https://picsum.photos/seed/khel-product-%d/600/600", i+1), p.name); err != nil {
+			return fmt.Errorf("insert image for %s: %w", p.slug, err)
+		}
+		s.products++
+	}
+	return nil
+}
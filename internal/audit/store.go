@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Record(ctx context.Context, e *Entry) error {
+	const q = `
+		INSERT INTO audit_logs (actor_id, entity, entity_id, action, before_data, after_data, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	// nil RawMessage must go in as SQL NULL, not as an empty JSON document
+	var before, after any
+	if len(e.Before) > 0 {
+		before = []byte(e.Before)
+	}
+	if len(e.After) > 0 {
+		after = []byte(e.After)
+	}
+
+	err := r.db.QueryRow(ctx, q,
+		e.ActorID, e.Entity, e.EntityID, e.Action, before, after, e.IP, e.UserAgent,
+	).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("record audit log: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Entry, int, error) {
+	const q = `
+		SELECT id, actor_id, entity, entity_id, action, before_data, after_data, ip, user_agent, created_at,
+		       COUNT(*) OVER() AS total_count
+		FROM audit_logs
+		WHERE ($1::bigint IS NULL OR actor_id = $1)
+		  AND ($2 = '' OR entity = $2)
+		  AND ($3 = '' OR entity_id = $3)
+		  AND ($4 = '' OR action = $4)
+		  AND ($5::timestamptz IS NULL OR created_at >= $5)
+		  AND ($6::timestamptz IS NULL OR created_at <  $6)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`
+	rows, err := r.db.Query(ctx, q, f.ActorID, f.Entity, f.EntityID, f.Action, f.From, f.To, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	var total int
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(
+			&e.ID, &e.ActorID, &e.Entity, &e.EntityID, &e.Action,
+			&e.Before, &e.After, &e.IP, &e.UserAgent, &e.CreatedAt, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan audit log: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return entries, total, nil
+}
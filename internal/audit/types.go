@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Entities that show up in the audit trail.
+const (
+	EntityBrand              = "brand"
+	EntityCategory           = "category"
+	EntityProduct            = "product"
+	EntityAd                 = "ad"
+	EntityFeaturedCollection = "featured_collection"
+	EntityFeaturedItem       = "featured_item"
+	EntityVenue              = "venue"
+	EntityVenueRequest       = "venue_request"
+	EntityHelpCategory       = "help_category"
+	EntityHelpArticle        = "help_article"
+	EntityBookingDispute     = "booking_dispute"
+	EntityRefund             = "refund"
+	EntityPublicHoliday      = "public_holiday"
+	EntitySettlement         = "settlement"
+	EntityCommissionRate     = "commission_rate"
+	EntityVenueReview        = "venue_review"
+	EntityUser               = "user"
+	EntityProductLabel       = "product_label"
+	EntityHomeLayout         = "home_layout"
+	EntityBooking            = "booking"
+	EntityVenueDayClose      = "venue_day_close"
+	EntityDemoSandbox        = "demo_sandbox"
+	EntityCategoryAttribute  = "category_attribute"
+	EntityPhotoMigration     = "photo_migration"
+	EntityVenueStaff         = "venue_staff"
+	EntityBookingRules       = "booking_rules"
+	EntityUserReport         = "user_report"
+	EntityModerationItem     = "moderation_item"
+)
+
+// Actions recorded against an entity.
+const (
+	ActionCreate        = "create"
+	ActionUpdate        = "update"
+	ActionDelete        = "delete"
+	ActionRestore       = "restore"
+	ActionPublish       = "publish"
+	ActionToggle        = "toggle"
+	ActionReorder       = "reorder"
+	ActionApprove       = "approve"
+	ActionReject        = "reject"
+	ActionStatus        = "status_change"
+	ActionResolve       = "resolve"
+	ActionImpersonate   = "impersonate"
+	ActionAnonymize     = "anonymize"
+	ActionRevealContact = "reveal_contact"
+	ActionImport        = "import"
+	ActionReopen        = "reopen"
+)
+
+type Entry struct {
+	ID        int64           `json:"id"`
+	ActorID   *int64          `json:"actor_id,omitempty"`
+	Entity    string          `json:"entity"`
+	EntityID  *string         `json:"entity_id,omitempty"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	IP        *string         `json:"ip,omitempty"`
+	UserAgent *string         `json:"user_agent,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Filter narrows List. Zero values are ignored.
+type Filter struct {
+	ActorID  *int64
+	Entity   string
+	EntityID string
+	Action   string
+	From     *time.Time
+	To       *time.Time
+}
+
+type Store interface {
+	Record(ctx context.Context, e *Entry) error
+	List(ctx context.Context, f Filter, limit, offset int) ([]Entry, int, error)
+}
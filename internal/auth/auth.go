@@ -1,9 +1,21 @@
 package auth
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ImpersonatorClaim names the access token claim holding the ID of the admin
+// acting as the token's subject.
+const ImpersonatorClaim = "impersonator"
 
 type Authenticator interface {
 	GenerateTokens(userID int64, role string) (string, string, error)
+	// GenerateImpersonationToken issues an access token for userID that
+	// carries the impersonating admin's ID and expires after ttl. There is
+	// no refresh token.
+	GenerateImpersonationToken(userID int64, role string, impersonatorID int64, ttl time.Duration) (string, error)
 	ValidateAccessToken(token string) (*jwt.Token, error)
 	ValidateRefreshToken(token string) (*jwt.Token, error)
 }
@@ -50,6 +50,22 @@ func (a *JWTAuthenticator) GenerateTokens(userID int64, role string) (string, st
 	return accessToken, refreshToken, nil
 }
 
+// GenerateImpersonationToken generates a short-lived access token marked
+// with the impersonator claim
+func (a *JWTAuthenticator) GenerateImpersonationToken(userID int64, role string, impersonatorID int64, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":             userID,
+		"role":            role,
+		ImpersonatorClaim: impersonatorID,
+		"exp":             time.Now().Add(ttl).Unix(),
+		"iat":             time.Now().Unix(),
+		"nbf":             time.Now().Unix(),
+		"iss":             a.iss,
+		"aud":             a.aud,
+	}
+	return a.generateTokenWithClaims(claims, a.secret)
+}
+
 func (a *JWTAuthenticator) generateTokenWithClaims(claims jwt.Claims, secret string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
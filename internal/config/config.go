@@ -0,0 +1,497 @@
+// Package config loads the API's settings from the environment once at
+// startup, fills in defaults and validates them, so a bad deploy fails
+// with one clear message instead of halfway through boot.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Environments APP_ENV may name. Each has its own .env.<name> file for local
+// runs and its own defaults (see overlays).
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProd        = "prod"
+)
+
+// Storages MEDIA_BACKEND may name.
+const (
+	MediaCloudinary = "cloudinary"
+	MediaS3         = "s3"
+	MediaLocal      = "local"
+)
+
+// Senders SMS_BACKEND may name.
+const (
+	SMSSparrow = "sparrow"
+	SMSLog     = "log"
+)
+
+// Search backends SEARCH_BACKEND may name.
+const (
+	SearchPostgres    = "postgres"
+	SearchMeilisearch = "meilisearch"
+)
+
+type Config struct {
+	// AppEnv is APP_ENV: development, staging or prod.
+	AppEnv string
+	// Env is ENV, the label /health reports and a few dev-only routes check.
+	Env         string
+	Addr        string
+	Port        string
+	APIURL      string
+	FrontendURL string
+
+	HashIDSalt        string
+	OrderNumberSecret string
+	CloudinaryURL     string
+
+	DB          DB
+	Mail        Mail
+	Auth        Auth
+	RateLimiter RateLimiter
+	Payment     Payment
+	Store       Store
+	Bookings    Bookings
+	CORS        CORS
+	Security    Security
+	Turnstile   Turnstile
+	Search      Search
+	Media       Media
+	Tracing     Tracing
+	SMS         SMS
+}
+
+type DB struct {
+	Addr         string
+	MaxOpenConns int
+	MaxIdleTime  time.Duration
+}
+
+type Mail struct {
+	FromEmail      string
+	MailtrapAPIKey string
+}
+
+type Auth struct {
+	BasicUser     string
+	BasicPass     string
+	TokenSecret   string
+	RefreshSecret string
+}
+
+type RateLimiter struct {
+	RequestsPerMinute int
+	Enabled           bool
+}
+
+type Payment struct {
+	EsewaMerchantID  string
+	EsewaSecretKey   string
+	EsewaSuccessURL  string
+	EsewaFailureURL  string
+	KhaltiSecretKey  string
+	KhaltiReturnURL  string
+	KhaltiWebsiteURL string
+}
+
+type Store struct {
+	// OrderCancelWindow is how long after checkout a buyer may cancel an
+	// order that has not shipped.
+	OrderCancelWindow time.Duration
+}
+
+type Bookings struct {
+	// PendingTTL is how long a booking request waits for the venue before
+	// it is rejected automatically. Venues can set their own.
+	PendingTTL time.Duration
+}
+
+type CORS struct {
+	AllowedOrigins []string
+}
+
+type Security struct {
+	HSTS           bool
+	FrameAncestors []string
+}
+
+type Turnstile struct {
+	SecretKey        string
+	ExpectedHostname string
+}
+
+type Search struct {
+	// Backend answers the full-text search endpoints: postgres (the
+	// default) or meilisearch, which venues and products are mirrored into.
+	Backend        string
+	MeilisearchURL string
+	MeilisearchKey string
+}
+
+type Media struct {
+	// Backend stores uploads: cloudinary (the default), s3 for any
+	// S3-compatible bucket, or local, a directory the API serves itself.
+	// CloudinaryURL is still used, when set, to delete files uploaded
+	// there before a switch.
+	Backend string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PublicURL       string
+
+	LocalDir string
+	LocalURL string
+}
+
+type Tracing struct {
+	// ServiceName is the service.name spans are reported under.
+	ServiceName string
+	// OTLPEndpoint is the collector's OTLP/HTTP URL. Spans are only
+	// exported when it is set.
+	OTLPEndpoint string
+}
+
+type SMS struct {
+	// Backend sends text messages: sparrow for Sparrow SMS, or log to only
+	// write them to the log in development. Empty means the API can't
+	// text anyone, which turns guest checkout off.
+	Backend      string
+	SparrowToken string
+	SparrowFrom  string
+}
+
+// overlay holds the defaults that differ between environments. Anything
+// set in the environment wins over them.
+type overlay struct {
+	corsOrigins []string
+	hsts        bool
+	// strict environments also require the settings that only matter once
+	// real users and money are involved.
+	strict bool
+}
+
+var overlays = map[string]overlay{
+	EnvProd: {
+		corsOrigins: []string{"https://khel.gocloudnepal.com"},
+		hsts:        true,
+		strict:      true,
+	},
+	EnvStaging: {
+		corsOrigins: []string{"https://khel-staging.vercel.app"},
+		hsts:        true,
+		strict:      true,
+	},
+	EnvDevelopment: {
+		corsOrigins: []string{
+			"http://localhost:3000",
+			"http://localhost:5173",
+			"https://khel-staging.vercel.app",
+		},
+	},
+}
+
+// Load reads the process environment. Outside Docker it first loads
+// .env.<APP_ENV> if there is one; variables already set are kept.
+func Load() (*Config, error) {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = EnvDevelopment
+	}
+	if os.Getenv("DOCKER") == "" {
+		_ = godotenv.Load(".env." + env)
+	}
+	return LoadFrom(os.LookupEnv)
+}
+
+// LoadFrom builds the config from lookup, which has the signature of
+// os.LookupEnv. Every problem found is reported in the one error.
+func LoadFrom(lookup func(string) (string, bool)) (*Config, error) {
+	l := &loader{lookup: lookup}
+
+	cfg := &Config{AppEnv: l.str("APP_ENV", EnvDevelopment)}
+	ov, ok := overlays[cfg.AppEnv]
+	if !ok {
+		l.fail("APP_ENV", fmt.Errorf("must be one of %s, %s or %s, got %q", EnvDevelopment, EnvStaging, EnvProd, cfg.AppEnv))
+		ov = overlays[EnvProd]
+	}
+
+	cfg.Env = l.str("ENV", "")
+	cfg.Addr = l.str("ADDR", "")
+	cfg.Port = l.str("PORT", "8080")
+	cfg.APIURL = l.str("EXTERNAL_URL", "")
+	cfg.FrontendURL = l.str("FRONTEND_URL", "")
+
+	cfg.HashIDSalt = l.str("HASHIDS_SALT", "")
+	if ov.strict && cfg.HashIDSalt == "" {
+		l.fail("HASHIDS_SALT", errors.New("is required outside development"))
+	}
+	cfg.OrderNumberSecret = l.required("ORDER_NUMBER_SECRET")
+	cfg.CloudinaryURL = l.str("CLOUDINARY_URL", "")
+
+	cfg.DB = DB{
+		Addr:         l.required("DB_ADDR"),
+		MaxOpenConns: l.int("DB_MAX_OPEN_CONNS", 10, 1),
+		MaxIdleTime:  l.duration("DB_MAX_IDLE_TIME", 15*time.Minute),
+	}
+
+	cfg.Mail = Mail{
+		FromEmail:      l.str("SENDGRID_FROM_EMAIL", ""),
+		MailtrapAPIKey: l.required("MAILTRAP_API_KEY"),
+	}
+
+	cfg.Auth = Auth{
+		BasicUser:     l.str("AUTH_BASIC_USER", ""),
+		BasicPass:     l.str("AUTH_BASIC_PASS", ""),
+		TokenSecret:   l.required("AUTH_TOKEN_SECRET"),
+		RefreshSecret: l.required("AUTH_TOKEN_REFRESH_SECRET"),
+	}
+
+	cfg.RateLimiter = RateLimiter{
+		RequestsPerMinute: l.int("RATELIMITER_REQUESTS_COUNT", 300, 1),
+		Enabled:           l.bool("RATE_LIMITER_ENABLED", false),
+	}
+
+	cfg.Payment = Payment{
+		EsewaMerchantID:  l.str("ESEWA_MERCHANT_ID", ""),
+		EsewaSecretKey:   l.str("ESEWA_SECRET_KEY", ""),
+		EsewaSuccessURL:  l.str("ESEWA_SUCCESS_URL", ""),
+		EsewaFailureURL:  l.str("ESEWA_FAILURE_URL", ""),
+		KhaltiSecretKey:  l.str("KHALTI_SECRET_KEY", ""),
+		KhaltiReturnURL:  l.str("KHALTI_RETURN_URL", ""),
+		KhaltiWebsiteURL: l.str("KHALTI_WEBSITE_URL", ""),
+	}
+
+	cfg.Store = Store{
+		OrderCancelWindow: l.duration("STORE_ORDER_CANCEL_WINDOW", 24*time.Hour),
+	}
+
+	cfg.Bookings = Bookings{
+		PendingTTL: l.duration("BOOKING_PENDING_TTL", 6*time.Hour),
+	}
+
+	cfg.CORS = CORS{AllowedOrigins: l.origins("CORS_ALLOWED_ORIGINS", ov.corsOrigins)}
+	cfg.Security = Security{
+		HSTS:           l.bool("HSTS_ENABLED", ov.hsts),
+		FrameAncestors: l.origins("FRAME_ANCESTORS", nil),
+	}
+
+	cfg.Turnstile = Turnstile{
+		SecretKey:        l.str("TURNSTILE_SECRET_KEY", ""),
+		ExpectedHostname: l.str("TURNSTILE_EXPECTED_HOSTNAME", ""),
+	}
+
+	cfg.Search = Search{
+		Backend:        l.str("SEARCH_BACKEND", SearchPostgres),
+		MeilisearchURL: strings.TrimRight(l.str("MEILISEARCH_URL", ""), "/"),
+		MeilisearchKey: l.str("MEILISEARCH_API_KEY", ""),
+	}
+	switch cfg.Search.Backend {
+	case SearchPostgres:
+	case SearchMeilisearch:
+		if cfg.Search.MeilisearchURL == "" {
+			l.fail("MEILISEARCH_URL", errors.New("is required when SEARCH_BACKEND is meilisearch"))
+		}
+	default:
+		l.fail("SEARCH_BACKEND", fmt.Errorf("must be %s or %s, got %q", SearchPostgres, SearchMeilisearch, cfg.Search.Backend))
+	}
+
+	cfg.Media = Media{
+		Backend:           l.str("MEDIA_BACKEND", MediaCloudinary),
+		S3Endpoint:        l.str("S3_ENDPOINT", ""),
+		S3Region:          l.str("S3_REGION", "us-east-1"),
+		S3Bucket:          l.str("S3_BUCKET", ""),
+		S3AccessKeyID:     l.str("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey: l.str("S3_SECRET_ACCESS_KEY", ""),
+		S3PublicURL:       l.str("S3_PUBLIC_URL", ""),
+		LocalDir:          l.str("MEDIA_LOCAL_DIR", "uploads"),
+		LocalURL:          l.str("MEDIA_LOCAL_URL", ""),
+	}
+	switch cfg.Media.Backend {
+	case MediaCloudinary:
+		if cfg.CloudinaryURL == "" {
+			l.fail("CLOUDINARY_URL", errors.New("is required when MEDIA_BACKEND is cloudinary"))
+		}
+	case MediaS3:
+		required := []struct{ key, value string }{
+			{"S3_ENDPOINT", cfg.Media.S3Endpoint},
+			{"S3_BUCKET", cfg.Media.S3Bucket},
+			{"S3_ACCESS_KEY_ID", cfg.Media.S3AccessKeyID},
+			{"S3_SECRET_ACCESS_KEY", cfg.Media.S3SecretAccessKey},
+		}
+		for _, r := range required {
+			if r.value == "" {
+				l.fail(r.key, errors.New("is required when MEDIA_BACKEND is s3"))
+			}
+		}
+	case MediaLocal:
+		if ov.strict {
+			l.fail("MEDIA_BACKEND", errors.New("local is only for development"))
+		}
+		if cfg.Media.LocalURL == "" {
+			l.fail("MEDIA_LOCAL_URL", errors.New("is required when MEDIA_BACKEND is local"))
+		}
+	default:
+		l.fail("MEDIA_BACKEND", fmt.Errorf("must be %s, %s or %s, got %q", MediaCloudinary, MediaS3, MediaLocal, cfg.Media.Backend))
+	}
+
+	cfg.Tracing = Tracing{
+		ServiceName:  l.str("OTEL_SERVICE_NAME", "khel-api"),
+		OTLPEndpoint: strings.TrimRight(l.str("OTEL_EXPORTER_OTLP_ENDPOINT", ""), "/"),
+	}
+	if e := cfg.Tracing.OTLPEndpoint; e != "" && !strings.HasPrefix(e, "http://") && !strings.HasPrefix(e, "https://") {
+		l.fail("OTEL_EXPORTER_OTLP_ENDPOINT", fmt.Errorf("must start with http:// or https://, got %q", e))
+	}
+
+	cfg.SMS = SMS{
+		Backend:      l.str("SMS_BACKEND", ""),
+		SparrowToken: l.str("SPARROW_SMS_TOKEN", ""),
+		SparrowFrom:  l.str("SPARROW_SMS_FROM", ""),
+	}
+	switch cfg.SMS.Backend {
+	case "":
+	case SMSSparrow:
+		if cfg.SMS.SparrowToken == "" {
+			l.fail("SPARROW_SMS_TOKEN", errors.New("is required when SMS_BACKEND is sparrow"))
+		}
+		if cfg.SMS.SparrowFrom == "" {
+			l.fail("SPARROW_SMS_FROM", errors.New("is required when SMS_BACKEND is sparrow"))
+		}
+	case SMSLog:
+		if ov.strict {
+			l.fail("SMS_BACKEND", errors.New("log is only for development"))
+		}
+	default:
+		l.fail("SMS_BACKEND", fmt.Errorf("must be %s or %s, got %q", SMSSparrow, SMSLog, cfg.SMS.Backend))
+	}
+
+	if len(l.errs) > 0 {
+		msgs := make([]string, len(l.errs))
+		for i, err := range l.errs {
+			msgs[i] = err.Error()
+		}
+		return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(msgs, "\n  "))
+	}
+	return cfg, nil
+}
+
+// IsProd reports whether the API runs against production data.
+func (c *Config) IsProd() bool {
+	return c.AppEnv == EnvProd
+}
+
+// loader reads typed values and collects the problems instead of stopping
+// at the first one.
+type loader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func (l *loader) fail(key string, err error) {
+	l.errs = append(l.errs, fmt.Errorf("%s %w", key, err))
+}
+
+func (l *loader) raw(key string) (string, bool) {
+	v, ok := l.lookup(key)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
+
+func (l *loader) str(key, def string) string {
+	if v, ok := l.raw(key); ok {
+		return v
+	}
+	return def
+}
+
+func (l *loader) required(key string) string {
+	v, ok := l.raw(key)
+	if !ok {
+		l.fail(key, errors.New("is required"))
+	}
+	return v
+}
+
+func (l *loader) int(key string, def, atLeast int) int {
+	v, ok := l.raw(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.fail(key, fmt.Errorf("must be a whole number, got %q", v))
+		return def
+	}
+	if n < atLeast {
+		l.fail(key, fmt.Errorf("must be at least %d, got %d", atLeast, n))
+		return def
+	}
+	return n
+}
+
+func (l *loader) bool(key string, def bool) bool {
+	v, ok := l.raw(key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.fail(key, fmt.Errorf("must be true or false, got %q", v))
+		return def
+	}
+	return b
+}
+
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v, ok := l.raw(key)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		l.fail(key, fmt.Errorf("must be a duration like 15m or 24h, got %q", v))
+		return def
+	}
+	if d <= 0 {
+		l.fail(key, fmt.Errorf("must be positive, got %s", v))
+		return def
+	}
+	return d
+}
+
+// origins parses a comma separated origin list. Browsers send Origin
+// without a trailing slash, so one in the config would never match.
+func (l *loader) origins(key string, def []string) []string {
+	v, ok := l.raw(key)
+	if !ok {
+		return def
+	}
+	var out []string
+	for _, o := range strings.Split(v, ",") {
+		o = strings.TrimRight(strings.TrimSpace(o), "/")
+		if o == "" {
+			continue
+		}
+		if !strings.HasPrefix(o, "http://") && !strings.HasPrefix(o, "https://") {
+			l.fail(key, fmt.Errorf("entries must start with http:// or https://, got %q", o))
+			continue
+		}
+		out = append(out, o)
+	}
+	if len(out) == 0 {
+		return def
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"khel/internal/tracing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,6 +25,9 @@ func New(addr string, maxConns int32, maxIdleTime string) (*pgxpool.Pool, error)
 	}
 	config.MaxConnIdleTime = duration
 
+	// Record a span for each query made while serving a traced request.
+	config.ConnConfig.Tracer = tracing.QueryTracer{}
+
 	// This timeout applied to pool initialization, including establishing initial connections to the database. Running the Ping() test. if nay step exceeds the quoted time, the pool fails to start.
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -0,0 +1,95 @@
+package documents
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is one entry of an iCalendar feed.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Location    string
+	Description string
+	// Latitude and Longitude are written as GEO when both are set.
+	Latitude  *float64
+	Longitude *float64
+}
+
+// RenderCalendar returns an RFC 5545 calendar named name with the events.
+func RenderCalendar(name string, events []Event) []byte {
+	var b bytes.Buffer
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//Khel//Khel Calendar//EN")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeICal(name))
+	// Ask subscribed calendars to refresh hourly.
+	writeLine(&b, "REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	writeLine(&b, "X-PUBLISHED-TTL:PT1H")
+
+	stamp := icalTime(time.Now())
+	for _, e := range events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+e.UID)
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+icalTime(e.Start))
+		writeLine(&b, "DTEND:"+icalTime(e.End))
+		writeLine(&b, "SUMMARY:"+escapeICal(e.Summary))
+		if e.Location != "" {
+			writeLine(&b, "LOCATION:"+escapeICal(e.Location))
+		}
+		if e.Latitude != nil && e.Longitude != nil {
+			writeLine(&b, "GEO:"+formatCoord(*e.Latitude)+";"+formatCoord(*e.Longitude))
+		}
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeICal(e.Description))
+		}
+		writeLine(&b, "STATUS:CONFIRMED")
+		writeLine(&b, "END:VEVENT")
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+func icalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func formatCoord(v float64) string {
+	return strings.TrimRight(strings.TrimRight(strconv.FormatFloat(v, 'f', 6, 64), "0"), ".")
+}
+
+// escapeICal escapes TEXT values as RFC 5545 section 3.3.11 requires.
+func escapeICal(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeLine folds content lines longer than 75 octets, without splitting a
+// UTF-8 sequence, and ends them with CRLF.
+func writeLine(b *bytes.Buffer, line string) {
+	// Continuation lines start with a space, which counts towards the limit.
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
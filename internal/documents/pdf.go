@@ -0,0 +1,126 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// page is a single A4 page built from PDF drawing operators. Only the two
+// standard Helvetica faces are used, so no fonts need to be embedded.
+type page struct {
+	content bytes.Buffer
+}
+
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+)
+
+// text draws s with its baseline at (x, y), measured from the top-left corner.
+func (p *page) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, pageHeight-y, escapeText(s))
+}
+
+// textRight draws s so that it ends at x.
+func (p *page) textRight(x, y, size float64, bold bool, s string) {
+	p.text(x-textWidth(s, size, bold), y, size, bold, s)
+}
+
+func (p *page) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f m %.2f %.2f l S\n", x1, pageHeight-y1, x2, pageHeight-y2)
+}
+
+func (p *page) fillRect(x, y, w, h float64, c rgb) {
+	fmt.Fprintf(&p.content, "q %.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f Q\n", c.r, c.g, c.b, x, pageHeight-y-h, w, h)
+}
+
+func (p *page) fillColor(c rgb) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f rg\n", c.r, c.g, c.b)
+}
+
+// bytes wraps the page into a complete PDF file.
+func (p *page) bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", pageWidth, pageHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// escapeText keeps s printable in a PDF string. Characters outside Latin-1
+// have no glyph in the standard fonts and become '?'.
+func escapeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r < 256:
+			b.WriteString(`\` + strconv.FormatInt(int64(r), 8))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// textWidth approximates the width of s in Helvetica. Good enough for right
+// aligning short amounts.
+func textWidth(s string, size float64, bold bool) float64 {
+	per := 0.556
+	if bold {
+		per = 0.611
+	}
+	return float64(len([]rune(s))) * per * size
+}
+
+type rgb struct {
+	r, g, b float64
+}
+
+// parseHexColor reads a #RRGGBB color, falling back to def.
+func parseHexColor(s string, def rgb) rgb {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 {
+		return def
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return def
+	}
+	return rgb{
+		r: float64(v>>16&0xff) / 255,
+		g: float64(v>>8&0xff) / 255,
+		b: float64(v&0xff) / 255,
+	}
+}
@@ -0,0 +1,134 @@
+// Package documents renders the PDF files we hand to users.
+package documents
+
+import (
+	"fmt"
+	"time"
+)
+
+var nepalTime = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return time.FixedZone("NPT", 5*3600+45*60)
+	}
+	return loc
+}()
+
+// Receipt is everything printed on a booking receipt. Amounts are in NPR.
+type Receipt struct {
+	Number       string
+	IssuedAt     time.Time
+	CustomerName string
+
+	VenueName    string
+	VenueAddress string
+	VenuePhone   string
+	FacilityName string
+	// BrandName and BrandColor come from the venue's organization, if any.
+	BrandName  string
+	BrandColor string
+
+	StartTime time.Time
+	EndTime   time.Time
+	Total     int
+	Payments  []ReceiptPayment
+}
+
+// ReceiptPayment is one online payment made towards the booking.
+type ReceiptPayment struct {
+	Payer     string
+	Provider  string
+	Reference string
+	Amount    int
+	PaidAt    *time.Time
+}
+
+var defaultBrandColor = rgb{r: 0.055, g: 0.647, b: 0.914}
+
+// RenderReceipt returns the receipt as a one-page PDF.
+func RenderReceipt(rc Receipt) []byte {
+	var p page
+	const left, right = 50.0, pageWidth - 50
+
+	brand := rc.BrandName
+	if brand == "" {
+		brand = "Khel"
+	}
+	p.fillRect(0, 0, pageWidth, 90, parseHexColor(rc.BrandColor, defaultBrandColor))
+	p.fillColor(rgb{1, 1, 1})
+	p.text(left, 52, 22, true, brand)
+	p.textRight(right, 52, 14, false, "Booking receipt")
+	p.fillColor(rgb{0.1, 0.1, 0.1})
+
+	y := 130.0
+	p.text(left, y, 10, false, "Receipt no.")
+	p.text(left+110, y, 10, true, rc.Number)
+	p.textRight(right, y, 10, false, "Issued "+rc.IssuedAt.In(nepalTime).Format("2 Jan 2006, 3:04 PM"))
+	y += 18
+	p.text(left, y, 10, false, "Billed to")
+	p.text(left+110, y, 10, true, rc.CustomerName)
+
+	y += 40
+	p.text(left, y, 13, true, "Booking")
+	y += 8
+	p.line(left, y, right, y)
+	rows := [][2]string{
+		{"Venue", rc.VenueName},
+		{"Address", rc.VenueAddress},
+		{"Phone", rc.VenuePhone},
+		{"Court", rc.FacilityName},
+		{"Date", rc.StartTime.In(nepalTime).Format("Monday, 2 January 2006")},
+		{"Time", rc.StartTime.In(nepalTime).Format("3:04 PM") + " - " + rc.EndTime.In(nepalTime).Format("3:04 PM")},
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		y += 20
+		p.text(left, y, 10, false, row[0])
+		p.text(left+110, y, 10, false, row[1])
+	}
+
+	y += 40
+	p.text(left, y, 13, true, "Payment")
+	y += 8
+	p.line(left, y, right, y)
+	paid := 0
+	if len(rc.Payments) == 0 {
+		y += 20
+		p.text(left, y, 10, false, "To be paid at the venue")
+	}
+	for _, pay := range rc.Payments {
+		y += 20
+		p.text(left, y, 10, false, pay.Payer)
+		p.text(left+160, y, 10, false, pay.Provider)
+		p.text(left+240, y, 9, false, pay.Reference)
+		p.textRight(right, y, 10, false, formatAmount(pay.Amount))
+		paid += pay.Amount
+	}
+
+	y += 16
+	p.line(left, y, right, y)
+	y += 20
+	p.text(left, y, 11, true, "Total")
+	p.textRight(right, y, 11, true, formatAmount(rc.Total))
+	if len(rc.Payments) > 0 {
+		y += 18
+		p.text(left, y, 10, false, "Paid online")
+		p.textRight(right, y, 10, false, formatAmount(paid))
+		if due := rc.Total - paid; due > 0 {
+			y += 18
+			p.text(left, y, 10, false, "Due at the venue")
+			p.textRight(right, y, 10, false, formatAmount(due))
+		}
+	}
+
+	p.fillColor(rgb{0.45, 0.45, 0.45})
+	p.text(left, pageHeight-50, 8, false, "This receipt was generated by Khel. Show it at the venue if asked.")
+
+	return p.bytes()
+}
+
+func formatAmount(n int) string {
+	return fmt.Sprintf("Rs. %d", n)
+}
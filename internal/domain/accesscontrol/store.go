@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,6 +13,7 @@ type Store interface {
 	RemoveRole(ctx context.Context, userID, roleID int64) error
 	GetUserRoles(ctx context.Context, userID int64) ([]Role, error)
 	UserHasRole(ctx context.Context, userID int64, roleName string) (bool, error)
+	UserIDsWithRole(ctx context.Context, roleName string) ([]int64, error)
 }
 
 type Repository struct {
@@ -81,3 +83,17 @@ func (r *Repository) UserHasRole(ctx context.Context, userID int64, roleName str
 	err := r.db.QueryRow(ctx, query, userID, roleName).Scan(&exists)
 	return exists, err
 }
+
+func (r *Repository) UserIDsWithRole(ctx context.Context, roleName string) ([]int64, error) {
+	query := `
+        SELECT ur.user_id
+        FROM user_roles ur
+        JOIN roles r ON ur.role_id = r.id
+        WHERE r.name = $1
+    `
+	rows, err := r.db.Query(ctx, query, roleName)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
@@ -17,27 +17,41 @@ func NewRepository(db *pgxpool.Pool) Store {
 
 func (r *Repository) GetOverview(ctx context.Context) (*Overview, error) {
 	const q = `
+		WITH real_bookings AS (
+			SELECT b.status
+			FROM bookings b
+			JOIN venues v ON v.id = b.venue_id
+			WHERE NOT v.is_demo
+		),
+		real_games AS (
+			SELECT g.sport_type
+			FROM games g
+			JOIN venues v ON v.id = g.venue_id
+			WHERE NOT v.is_demo
+		)
 		SELECT
 			(SELECT COUNT(*) FROM users),
 			(SELECT COUNT(*) FROM users WHERE is_active = true),
 			(SELECT COUNT(*) FROM users WHERE is_active = false),
 
-			(SELECT COUNT(*) FROM games WHERE sport_type = 'futsal'),
-			(SELECT COUNT(*) FROM games WHERE sport_type = 'basketball'),
-			(SELECT COUNT(*) FROM games WHERE sport_type = 'badminton'),
+			(SELECT COUNT(*) FROM real_games WHERE sport_type = 'futsal'),
+			(SELECT COUNT(*) FROM real_games WHERE sport_type = 'basketball'),
+			(SELECT COUNT(*) FROM real_games WHERE sport_type = 'badminton'),
 
 			(SELECT COUNT(*) FROM venue_requests),
 			(SELECT COUNT(*) FROM venue_requests WHERE status = 'requested'),
 
-			(SELECT COUNT(*) FROM venues),
-			(SELECT COUNT(*) FROM venues WHERE status = 'active'),
-			(SELECT COUNT(*) FROM venues WHERE status = 'requested'),
+			(SELECT COUNT(*) FROM venues WHERE NOT is_demo),
+			(SELECT COUNT(*) FROM venues WHERE status = 'active' AND NOT is_demo),
+			(SELECT COUNT(*) FROM venues WHERE status = 'pending_review' AND NOT is_demo),
 
-			(SELECT COUNT(*) FROM bookings),
-			(SELECT COUNT(*) FROM bookings WHERE status = 'confirmed'),
-			(SELECT COUNT(*) FROM bookings WHERE status = 'pending'),
-			(SELECT COUNT(*) FROM bookings WHERE status = 'rejected'),
-			(SELECT COUNT(*) FROM bookings WHERE status = 'done')
+			(SELECT COUNT(*) FROM real_bookings),
+			(SELECT COUNT(*) FROM real_bookings WHERE status = 'confirmed'),
+			(SELECT COUNT(*) FROM real_bookings WHERE status = 'pending'),
+			(SELECT COUNT(*) FROM real_bookings WHERE status = 'rejected'),
+			(SELECT COUNT(*) FROM real_bookings WHERE status = 'done'),
+
+			(SELECT COALESCE(SUM(commission_amount), 0) FROM settlements)
 	`
 
 	var o Overview
@@ -62,6 +76,8 @@ func (r *Repository) GetOverview(ctx context.Context) (*Overview, error) {
 		&o.TotalPendingBookings,
 		&o.TotalRejectedBookings,
 		&o.TotalCompletedBookings,
+
+		&o.TotalCommission,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("get admin overview: %w", err)
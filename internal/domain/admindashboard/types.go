@@ -1,6 +1,9 @@
 package admindashboard
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Overview struct {
 	// Users
@@ -28,8 +31,74 @@ type Overview struct {
 	TotalPendingBookings   int64 `json:"total_pending_bookings"`
 	TotalRejectedBookings  int64 `json:"total_rejected_bookings"`
 	TotalCompletedBookings int64 `json:"total_completed_bookings"`
+
+	// Commission the platform has charged in settlements so far.
+	TotalCommission int64 `json:"total_commission"`
+}
+
+// Today is platform activity between two instants, normally the current
+// Nepal calendar day.
+type Today struct {
+	Bookings          int64 `json:"bookings"`
+	BookingRevenue    int64 `json:"booking_revenue"`
+	Orders            int64 `json:"orders"`
+	OrderRevenueCents int64 `json:"order_revenue_cents"`
+	NewUsers          int64 `json:"new_users"`
+}
+
+// Queues counts the items waiting on an admin.
+type Queues struct {
+	PendingVenueRequests int64 `json:"pending_venue_requests"`
+	PendingVenueReviews  int64 `json:"pending_venue_reviews"`
+	OpenSupportTickets   int64 `json:"open_support_tickets"`
+	OpenDisputes         int64 `json:"open_disputes"`
+	PendingRefunds       int64 `json:"pending_refunds"`
+	HeldReviews          int64 `json:"held_reviews"`
+}
+
+type VenueBooking struct {
+	ID            int64     `json:"id"`
+	FacilityID    *int64    `json:"facility_id,omitempty"`
+	FacilityName  *string   `json:"facility_name,omitempty"`
+	UserID        int64     `json:"user_id"`
+	CustomerName  *string   `json:"customer_name,omitempty"`
+	CustomerPhone *string   `json:"customer_phone,omitempty"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Status        string    `json:"status"`
+	TotalPrice    int       `json:"total_price"`
+}
+
+type HeldReview struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	Rating     int       `json:"rating"`
+	Comment    *string   `json:"comment,omitempty"`
+	HoldReason *string   `json:"hold_reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// VenueRevenue is money collected on closed bookings (by paid_at), counted
+// the same way as the owner's earnings screen.
+type VenueRevenue struct {
+	Today              int64 `json:"today"`
+	Last7Days          int64 `json:"last_7_days"`
+	Last30Days         int64 `json:"last_30_days"`
+	BookingsLast30Days int64 `json:"bookings_last_30_days"`
 }
 
 type Store interface {
+	// GetOverview and GetToday count platform activity. Demo venues, and
+	// the games and bookings regenerated on them every night, are left out.
 	GetOverview(ctx context.Context) (*Overview, error)
+	GetToday(ctx context.Context, from, to time.Time) (*Today, error)
+	GetQueues(ctx context.Context) (*Queues, error)
+
+	// ListVenueBookings returns the venue's bookings starting in [from, to).
+	ListVenueBookings(ctx context.Context, venueID int64, from, to time.Time) ([]VenueBooking, error)
+	ListVenueHeldReviews(ctx context.Context, venueID int64, limit int) ([]HeldReview, error)
+	// GetVenueRevenue sums revenue for the day starting at dayStart and the
+	// 7 and 30 days ending with it.
+	GetVenueRevenue(ctx context.Context, venueID int64, dayStart time.Time) (*VenueRevenue, error)
 }
@@ -0,0 +1,136 @@
+package admindashboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (r *Repository) GetToday(ctx context.Context, from, to time.Time) (*Today, error) {
+	const q = `
+		SELECT
+			(SELECT COUNT(*)
+			   FROM bookings b
+			   JOIN venues v ON v.id = b.venue_id
+			  WHERE NOT v.is_demo AND b.created_at >= $1 AND b.created_at < $2),
+			(SELECT COALESCE(SUM(COALESCE(b.final_amount, b.paid_amount, b.total_price, 0)), 0)
+			   FROM bookings b
+			   JOIN venues v ON v.id = b.venue_id
+			  WHERE NOT v.is_demo AND b.status = 'done' AND b.paid_at >= $1 AND b.paid_at < $2),
+			(SELECT COUNT(*) FROM orders WHERE created_at >= $1 AND created_at < $2),
+			(SELECT COALESCE(SUM(total_cents), 0)
+			   FROM orders
+			  WHERE payment_status = 'paid' AND created_at >= $1 AND created_at < $2),
+			(SELECT COUNT(*) FROM users WHERE created_at >= $1 AND created_at < $2)
+	`
+	var t Today
+	if err := r.db.QueryRow(ctx, q, from, to).Scan(
+		&t.Bookings, &t.BookingRevenue, &t.Orders, &t.OrderRevenueCents, &t.NewUsers,
+	); err != nil {
+		return nil, fmt.Errorf("get admin today: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *Repository) GetQueues(ctx context.Context) (*Queues, error) {
+	const q = `
+		SELECT
+			(SELECT COUNT(*) FROM venue_requests WHERE status = 'requested'),
+			(SELECT COUNT(*) FROM venues WHERE status = 'pending_review'),
+			(SELECT COUNT(*) FROM support_tickets WHERE status IN ('open', 'in_progress')),
+			(SELECT COUNT(*) FROM booking_disputes WHERE status = 'open'),
+			(SELECT COUNT(*) FROM refunds WHERE status IN ('pending', 'manual')),
+			(SELECT COUNT(*) FROM reviews WHERE status = 'held')
+	`
+	var out Queues
+	if err := r.db.QueryRow(ctx, q).Scan(
+		&out.PendingVenueRequests, &out.PendingVenueReviews, &out.OpenSupportTickets, &out.OpenDisputes,
+		&out.PendingRefunds, &out.HeldReviews,
+	); err != nil {
+		return nil, fmt.Errorf("get admin queues: %w", err)
+	}
+	return &out, nil
+}
+
+func (r *Repository) ListVenueBookings(ctx context.Context, venueID int64, from, to time.Time) ([]VenueBooking, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT b.id, b.facility_id, f.name, b.user_id, b.customer_name, b.customer_phone,
+		       b.start_time, b.end_time, b.status::text, b.total_price
+		FROM bookings b
+		LEFT JOIN facilities f ON f.id = b.facility_id
+		WHERE b.venue_id = $1 AND b.start_time >= $2 AND b.start_time < $3
+		ORDER BY b.start_time, b.id
+	`, venueID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list venue bookings: %w", err)
+	}
+	defer rows.Close()
+
+	out := []VenueBooking{}
+	for rows.Next() {
+		var b VenueBooking
+		if err := rows.Scan(&b.ID, &b.FacilityID, &b.FacilityName, &b.UserID, &b.CustomerName, &b.CustomerPhone,
+			&b.StartTime, &b.EndTime, &b.Status, &b.TotalPrice); err != nil {
+			return nil, fmt.Errorf("scan venue booking: %w", err)
+		}
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) ListVenueHeldReviews(ctx context.Context, venueID int64, limit int) ([]HeldReview, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT rv.id, rv.user_id, u.first_name, rv.rating, rv.comment, rv.hold_reason, rv.created_at
+		FROM reviews rv
+		JOIN users u ON u.id = rv.user_id
+		WHERE rv.venue_id = $1 AND rv.status = 'held'
+		ORDER BY rv.created_at DESC, rv.id DESC
+		LIMIT $2
+	`, venueID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list venue held reviews: %w", err)
+	}
+	defer rows.Close()
+
+	out := []HeldReview{}
+	for rows.Next() {
+		var h HeldReview
+		if err := rows.Scan(&h.ID, &h.UserID, &h.UserName, &h.Rating, &h.Comment, &h.HoldReason, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan held review: %w", err)
+		}
+		out = append(out, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) GetVenueRevenue(ctx context.Context, venueID int64, dayStart time.Time) (*VenueRevenue, error) {
+	const q = `
+		WITH paid AS (
+			SELECT paid_at, COALESCE(final_amount, paid_amount, total_price, 0) AS amount
+			FROM bookings
+			WHERE venue_id = $1
+			  AND status = 'done'
+			  AND paid_at >= $3
+			  AND paid_at < $5
+		)
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE paid_at >= $2), 0),
+			COALESCE(SUM(amount) FILTER (WHERE paid_at >= $4), 0),
+			COALESCE(SUM(amount), 0),
+			COUNT(*)
+		FROM paid
+	`
+	var out VenueRevenue
+	if err := r.db.QueryRow(ctx, q, venueID, dayStart, dayStart.AddDate(0, 0, -29), dayStart.AddDate(0, 0, -6), dayStart.AddDate(0, 0, 1)).Scan(
+		&out.Today, &out.Last7Days, &out.Last30Days, &out.BookingsLast30Days,
+	); err != nil {
+		return nil, fmt.Errorf("get venue revenue: %w", err)
+	}
+	return &out, nil
+}
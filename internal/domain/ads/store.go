@@ -36,7 +36,7 @@ func NewRepository(db *pgxpool.Pool) Store {
 func (r *Repository) GetActiveAds(ctx context.Context) ([]Ad, error) {
 	query := `
 		SELECT id, title, description, image_url, image_alt, link, active, 
-		       display_order, impressions, clicks, created_at, updated_at
+		       display_order, impressions, clicks, created_at, updated_at, image_variants
 		FROM ads 
 		WHERE active = TRUE 
 		ORDER BY display_order ASC, created_at DESC
@@ -54,7 +54,7 @@ func (r *Repository) GetActiveAds(ctx context.Context) ([]Ad, error) {
 		err := rows.Scan(
 			&ad.ID, &ad.Title, &ad.Description, &ad.ImageURL, &ad.ImageAlt,
 			&ad.Link, &ad.Active, &ad.DisplayOrder, &ad.Impressions, &ad.Clicks,
-			&ad.CreatedAt, &ad.UpdatedAt,
+			&ad.CreatedAt, &ad.UpdatedAt, &ad.ImageVariants,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan ad row: %w", err)
@@ -82,7 +82,7 @@ func (r *Repository) GetAllAds(ctx context.Context, limit, offset int) ([]Ad, in
 	//Get ads with pagination
 
 	query := `
-	   SELECT id, title, description, image_url, image_alt, link, active,        display_order, impressions, clicks, created_at, updated_at 
+	   SELECT id, title, description, image_url, image_alt, link, active,        display_order, impressions, clicks, created_at, updated_at, image_variants 
 	   FROM ads
 	   ORDER BY display_order ASC, created_at DESC
 	   LIMIT $1 OFFSET $2
@@ -100,7 +100,7 @@ func (r *Repository) GetAllAds(ctx context.Context, limit, offset int) ([]Ad, in
 		err := rows.Scan(
 			&ad.ID, &ad.Title, &ad.Description, &ad.ImageURL, &ad.ImageAlt,
 			&ad.Link, &ad.Active, &ad.DisplayOrder, &ad.Impressions, &ad.Clicks,
-			&ad.CreatedAt, &ad.UpdatedAt,
+			&ad.CreatedAt, &ad.UpdatedAt, &ad.ImageVariants,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan ad row: %w", err)
@@ -118,7 +118,7 @@ func (r *Repository) GetAllAds(ctx context.Context, limit, offset int) ([]Ad, in
 func (r *Repository) GetAdByID(ctx context.Context, id int64) (*Ad, error) {
 	query := `
 		SELECT id, title, description, image_url, image_alt, link, active, 
-		       display_order, impressions, clicks, created_at, updated_at
+		       display_order, impressions, clicks, created_at, updated_at, image_variants
 		FROM ads 
 		WHERE id = $1
 	`
@@ -127,7 +127,7 @@ func (r *Repository) GetAdByID(ctx context.Context, id int64) (*Ad, error) {
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&ad.ID, &ad.Title, &ad.Description, &ad.ImageURL, &ad.ImageAlt,
 		&ad.Link, &ad.Active, &ad.DisplayOrder, &ad.Impressions, &ad.Clicks,
-		&ad.CreatedAt, &ad.UpdatedAt,
+		&ad.CreatedAt, &ad.UpdatedAt, &ad.ImageVariants,
 	)
 
 	if err != nil {
@@ -146,7 +146,7 @@ func (r *Repository) CreateAd(ctx context.Context, req CreateAdRequest) (*Ad, er
 		INSERT INTO ads (title, description, image_url, image_alt, link, display_order)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, title, description, image_url, image_alt, link, active, 
-		         display_order, impressions, clicks, created_at, updated_at
+		         display_order, impressions, clicks, created_at, updated_at, image_variants
 	`
 
 	var ad Ad
@@ -155,7 +155,7 @@ func (r *Repository) CreateAd(ctx context.Context, req CreateAdRequest) (*Ad, er
 	).Scan(
 		&ad.ID, &ad.Title, &ad.Description, &ad.ImageURL, &ad.ImageAlt,
 		&ad.Link, &ad.Active, &ad.DisplayOrder, &ad.Impressions, &ad.Clicks,
-		&ad.CreatedAt, &ad.UpdatedAt,
+		&ad.CreatedAt, &ad.UpdatedAt, &ad.ImageVariants,
 	)
 
 	if err != nil {
@@ -185,6 +185,7 @@ func (r *Repository) UpdateAd(ctx context.Context, id int64, req UpdateAdRequest
 		setParts = append(setParts, fmt.Sprintf("image_url = $%d", argIndex))
 		args = append(args, *req.ImageURL)
 		argIndex++
+		setParts = append(setParts, "image_variants = NULL")
 	}
 	if req.ImageAlt != nil {
 		setParts = append(setParts, fmt.Sprintf("image_alt = $%d", argIndex))
@@ -224,14 +225,14 @@ func (r *Repository) UpdateAd(ctx context.Context, id int64, req UpdateAdRequest
 		SET %s
 		WHERE id = $%d
 		RETURNING id, title, description, image_url, image_alt, link, active, 
-		         display_order, impressions, clicks, created_at, updated_at
+		         display_order, impressions, clicks, created_at, updated_at, image_variants
 	`, strings.Join(setParts, ", "), argIndex)
 
 	var ad Ad
 	err := r.db.QueryRow(ctx, query, args...).Scan(
 		&ad.ID, &ad.Title, &ad.Description, &ad.ImageURL, &ad.ImageAlt,
 		&ad.Link, &ad.Active, &ad.DisplayOrder, &ad.Impressions, &ad.Clicks,
-		&ad.CreatedAt, &ad.UpdatedAt,
+		&ad.CreatedAt, &ad.UpdatedAt, &ad.ImageVariants,
 	)
 
 	if err != nil {
@@ -267,14 +268,14 @@ func (r *Repository) ToggleAdStatus(ctx context.Context, id int64) (*Ad, error)
 		SET active = NOT active, updated_at = NOW()
 		WHERE id = $1
 		RETURNING id, title, description, image_url, image_alt, link, active, 
-		         display_order, impressions, clicks, created_at, updated_at
+		         display_order, impressions, clicks, created_at, updated_at, image_variants
 	`
 
 	var ad Ad
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&ad.ID, &ad.Title, &ad.Description, &ad.ImageURL, &ad.ImageAlt,
 		&ad.Link, &ad.Active, &ad.DisplayOrder, &ad.Impressions, &ad.Clicks,
-		&ad.CreatedAt, &ad.UpdatedAt,
+		&ad.CreatedAt, &ad.UpdatedAt, &ad.ImageVariants,
 	)
 
 	if err != nil {
@@ -361,7 +362,7 @@ func (r *Repository) GetAdsAnalytics(ctx context.Context) (*Analytics, error) {
 	// Get top performing ads (by CTR)
 	topAdsQuery := `
 		SELECT id, title, description, image_url, image_alt, link, active, 
-		       display_order, impressions, clicks, created_at, updated_at
+		       display_order, impressions, clicks, created_at, updated_at, image_variants
 		FROM ads 
 		WHERE impressions > 0
 		ORDER BY (CAST(clicks AS FLOAT) / CAST(impressions AS FLOAT)) DESC
@@ -379,7 +380,7 @@ func (r *Repository) GetAdsAnalytics(ctx context.Context) (*Analytics, error) {
 		err := rows.Scan(
 			&ad.ID, &ad.Title, &ad.Description, &ad.ImageURL, &ad.ImageAlt,
 			&ad.Link, &ad.Active, &ad.DisplayOrder, &ad.Impressions, &ad.Clicks,
-			&ad.CreatedAt, &ad.UpdatedAt,
+			&ad.CreatedAt, &ad.UpdatedAt, &ad.ImageVariants,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan top performing ad: %w", err)
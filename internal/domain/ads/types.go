@@ -1,6 +1,9 @@
 package ads
 
-import "time"
+import (
+	"khel/internal/images"
+	"time"
+)
 
 // Ad represents the ads table structure
 type Ad struct {
@@ -16,6 +19,8 @@ type Ad struct {
 	Clicks       int       `json:"clicks"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	// ImageVariants is nil until the image has been processed.
+	ImageVariants *images.Variants `json:"image_variants,omitempty"`
 }
 
 // CreateAdRequest represents the request payload for creating an ad
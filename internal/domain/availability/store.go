@@ -0,0 +1,110 @@
+package availability
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]Slot, error) {
+	const q = `
+		SELECT day_of_week, start_time::text, end_time::text
+		FROM user_availability
+		WHERE user_id = $1
+		ORDER BY
+			array_position(ARRAY['sunday','monday','tuesday','wednesday','thursday','friday','saturday']::varchar[], day_of_week),
+			start_time
+	`
+	rows, err := r.db.Query(ctx, q, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list availability: %w", err)
+	}
+	defer rows.Close()
+
+	slots := []Slot{}
+	for rows.Next() {
+		var s Slot
+		if err := rows.Scan(&s.DayOfWeek, &s.StartTime, &s.EndTime); err != nil {
+			return nil, fmt.Errorf("scan availability: %w", err)
+		}
+		slots = append(slots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return slots, nil
+}
+
+func (r *Repository) ReplaceForUser(ctx context.Context, userID int64, slots []Slot) error {
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM user_availability WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("clear availability: %w", err)
+		}
+		if len(slots) == 0 {
+			return nil
+		}
+
+		batch := &pgx.Batch{}
+		for _, s := range slots {
+			batch.Queue(`
+				INSERT INTO user_availability (user_id, day_of_week, start_time, end_time)
+				VALUES ($1, $2, $3::time, $4::time)`,
+				userID, s.DayOfWeek, s.StartTime, s.EndTime)
+		}
+
+		br := tx.SendBatch(ctx, batch)
+		defer br.Close()
+		for i := range slots {
+			if _, err := br.Exec(); err != nil {
+				return fmt.Errorf("insert availability slot[%d]: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Repository) FilterAvailable(ctx context.Context, userIDs []int64, at time.Time) ([]int64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	// Slots are stored in Nepal local time, so compare against the local
+	// weekday and time-of-day of the given instant.
+	const q = `
+		WITH local AS (
+			SELECT lower(to_char($2::timestamptz AT TIME ZONE 'Asia/Kathmandu', 'FMday')) AS dow,
+			       ($2::timestamptz AT TIME ZONE 'Asia/Kathmandu')::time               AS tod
+		)
+		SELECT u.id
+		FROM unnest($1::bigint[]) AS u(id), local
+		WHERE NOT EXISTS (SELECT 1 FROM user_availability a WHERE a.user_id = u.id)
+		   OR EXISTS (
+		       SELECT 1 FROM user_availability a
+		       WHERE a.user_id = u.id
+		         AND a.day_of_week = local.dow
+		         AND local.tod >= a.start_time
+		         AND local.tod <  a.end_time
+		   )
+	`
+	rows, err := r.db.Query(ctx, q, userIDs, at)
+	if err != nil {
+		return nil, fmt.Errorf("filter available users: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("scan available users: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,27 @@
+package availability
+
+import (
+	"context"
+	"time"
+)
+
+// Slot is one weekly window in which a user can play.
+// StartTime/EndTime are local Nepal time in 24-hour HH:mm:ss format.
+type Slot struct {
+	DayOfWeek string `json:"day_of_week"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+type Store interface {
+	// ListByUser returns the user's weekly schedule ordered by day and time.
+	ListByUser(ctx context.Context, userID int64) ([]Slot, error)
+
+	// ReplaceForUser overwrites the whole schedule. An empty slice clears it,
+	// which means the user is treated as always available again.
+	ReplaceForUser(ctx context.Context, userID int64, slots []Slot) error
+
+	// FilterAvailable keeps only the users who can play at the given instant.
+	// Users without any declared schedule are kept.
+	FilterAvailable(ctx context.Context, userIDs []int64, at time.Time) ([]int64, error)
+}
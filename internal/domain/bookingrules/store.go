@@ -0,0 +1,85 @@
+package bookingrules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Get(ctx context.Context, venueID int64) (Rules, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rules := DefaultRules(venueID)
+	err := r.db.QueryRow(ctx, `
+		SELECT min_duration_minutes, max_duration_minutes, max_advance_days,
+		       cancel_cutoff_hours, auto_confirm, pending_ttl_minutes, min_reliability_score,
+		       guest_deposit_percent, updated_by, updated_at
+		FROM venue_booking_rules
+		WHERE venue_id = $1
+	`, venueID).Scan(&rules.MinDurationMinutes, &rules.MaxDurationMinutes, &rules.MaxAdvanceDays,
+		&rules.CancelCutoffHours, &rules.AutoConfirm, &rules.PendingTTLMinutes, &rules.MinReliabilityScore,
+		&rules.GuestDepositPercent, &rules.UpdatedBy, &rules.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return rules, fmt.Errorf("get booking rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *Repository) Set(ctx context.Context, rules *Rules) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO venue_booking_rules (
+			venue_id, min_duration_minutes, max_duration_minutes, max_advance_days,
+			cancel_cutoff_hours, auto_confirm, pending_ttl_minutes, min_reliability_score,
+			guest_deposit_percent, updated_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (venue_id) DO UPDATE
+		SET min_duration_minutes = EXCLUDED.min_duration_minutes,
+		    max_duration_minutes = EXCLUDED.max_duration_minutes,
+		    max_advance_days = EXCLUDED.max_advance_days,
+		    cancel_cutoff_hours = EXCLUDED.cancel_cutoff_hours,
+		    auto_confirm = EXCLUDED.auto_confirm,
+		    pending_ttl_minutes = EXCLUDED.pending_ttl_minutes,
+		    min_reliability_score = EXCLUDED.min_reliability_score,
+		    guest_deposit_percent = EXCLUDED.guest_deposit_percent,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, rules.VenueID, rules.MinDurationMinutes, rules.MaxDurationMinutes, rules.MaxAdvanceDays,
+		rules.CancelCutoffHours, rules.AutoConfirm, rules.PendingTTLMinutes, rules.MinReliabilityScore,
+		rules.GuestDepositPercent, rules.UpdatedBy).Scan(&rules.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrVenueNotFound
+		}
+		return fmt.Errorf("set booking rules: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, venueID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, `DELETE FROM venue_booking_rules WHERE venue_id = $1`, venueID); err != nil {
+		return fmt.Errorf("delete booking rules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package bookingrules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// DefaultGuestDepositPercent is the share of the price guests pay up front
+// at venues that haven't set their own.
+const DefaultGuestDepositPercent = 25
+
+var (
+	ErrTooShort      = errors.New("booking is shorter than this venue allows")
+	ErrTooLong       = errors.New("booking is longer than this venue allows")
+	ErrTooFarAhead   = errors.New("booking is further ahead than this venue allows")
+	ErrCancelTooLate = errors.New("this venue's cancellation cutoff has passed")
+	ErrInvalidMinMax = errors.New("min_duration_minutes cannot exceed max_duration_minutes")
+	ErrVenueNotFound = errors.New("venue not found")
+)
+
+// Rules limit what players can book at a venue and when they can cancel.
+// A nil limit means there is none.
+type Rules struct {
+	VenueID            int64 `json:"venue_id"`
+	MinDurationMinutes *int  `json:"min_duration_minutes"`
+	MaxDurationMinutes *int  `json:"max_duration_minutes"`
+	MaxAdvanceDays     *int  `json:"max_advance_days"`
+	CancelCutoffHours  *int  `json:"cancel_cutoff_hours"`
+	AutoConfirm        bool  `json:"auto_confirm"`
+	// PendingTTLMinutes is how long a request waits for the venue before it
+	// expires; nil uses the platform default.
+	PendingTTLMinutes *int `json:"pending_ttl_minutes"`
+	// MinReliabilityScore limits AutoConfirm and the venue's auto-accept
+	// window to players with at least this reliability score; nil
+	// auto-confirms everyone.
+	MinReliabilityScore *int `json:"min_reliability_score"`
+	// GuestDepositPercent is the share of the price a guest booking from
+	// the widget pays up front; nil uses DefaultGuestDepositPercent.
+	GuestDepositPercent *int       `json:"guest_deposit_percent"`
+	UpdatedBy           *int64     `json:"updated_by,omitempty"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultRules apply to venues that never set their own: no limits, and
+// every booking waits for the owner.
+func DefaultRules(venueID int64) Rules {
+	return Rules{VenueID: venueID}
+}
+
+// CheckBooking reports why a booking from start to end, made at now, breaks
+// the rules, or nil if it doesn't.
+func (r Rules) CheckBooking(start, end, now time.Time) error {
+	minutes := int(end.Sub(start) / time.Minute)
+	if r.MinDurationMinutes != nil && minutes < *r.MinDurationMinutes {
+		return fmt.Errorf("%w: at least %d minutes", ErrTooShort, *r.MinDurationMinutes)
+	}
+	if r.MaxDurationMinutes != nil && minutes > *r.MaxDurationMinutes {
+		return fmt.Errorf("%w: at most %d minutes", ErrTooLong, *r.MaxDurationMinutes)
+	}
+	if r.MaxAdvanceDays != nil && start.After(now.AddDate(0, 0, *r.MaxAdvanceDays)) {
+		return fmt.Errorf("%w: up to %d days ahead", ErrTooFarAhead, *r.MaxAdvanceDays)
+	}
+	return nil
+}
+
+// GuestDeposit is the deposit a guest pays up front for a booking costing
+// price: at least 1 and never more than price.
+func (r Rules) GuestDeposit(price int) int {
+	percent := DefaultGuestDepositPercent
+	if r.GuestDepositPercent != nil {
+		percent = *r.GuestDepositPercent
+	}
+	deposit := price * percent / 100
+	if deposit < 1 {
+		deposit = 1
+	}
+	if deposit > price {
+		deposit = price
+	}
+	return deposit
+}
+
+// CheckCancel reports whether a booking starting at start can still be
+// canceled at now.
+func (r Rules) CheckCancel(start, now time.Time) error {
+	if r.CancelCutoffHours == nil {
+		return nil
+	}
+	if start.Sub(now) < time.Duration(*r.CancelCutoffHours)*time.Hour {
+		return fmt.Errorf("%w: cancel at least %d hours before the start", ErrCancelTooLate, *r.CancelCutoffHours)
+	}
+	return nil
+}
+
+type Store interface {
+	Get(ctx context.Context, venueID int64) (Rules, error)
+	Set(ctx context.Context, r *Rules) error
+	// Delete puts the venue back on DefaultRules.
+	Delete(ctx context.Context, venueID int64) error
+}
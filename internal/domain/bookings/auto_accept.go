@@ -0,0 +1,127 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// AutoAccepted is a pending booking the auto-accept job confirmed because
+// the owner didn't answer within the venue's window.
+type AutoAccepted struct {
+	BookingID int64
+	VenueID   int64
+	VenueName string
+	OwnerID   int64
+	UserID    int64
+	StartTime time.Time
+	EndTime   time.Time
+	// WaitedMinutes is the venue's window at the time of accepting.
+	WaitedMinutes int
+}
+
+// AutoAcceptCandidate is a pending booking whose venue's auto-accept window
+// has passed.
+type AutoAcceptCandidate struct {
+	BookingID int64
+	UserID    int64
+	// MinReliabilityScore is the venue's booking rule; players below it are
+	// left for the owner to answer.
+	MinReliabilityScore *int
+}
+
+// DueForAutoAccept lists up to limit pending bookings with an id above
+// afterID, in id order, that have waited longer than their venue's
+// auto_accept_after_minutes and haven't started. A guest booking waits
+// until its deposit is paid.
+func (r *Repository) DueForAutoAccept(ctx context.Context, afterID int64, limit int) ([]AutoAcceptCandidate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT b.id, b.user_id, br.min_reliability_score
+		FROM bookings b
+		JOIN venues v ON v.id = b.venue_id
+		LEFT JOIN venue_booking_rules br ON br.venue_id = b.venue_id
+		WHERE b.status = 'pending'
+		  AND b.id > $1
+		  AND v.auto_accept_after_minutes IS NOT NULL
+		  AND b.created_at <= NOW() - make_interval(mins => v.auto_accept_after_minutes)
+		  AND b.start_time > NOW()
+		  AND NOT EXISTS (
+			SELECT 1 FROM booking_deposits d
+			WHERE d.booking_id = b.id AND d.status <> 'paid'
+		  )
+		ORDER BY b.id
+		LIMIT $2
+	`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find bookings to auto-accept: %w", err)
+	}
+	defer rows.Close()
+
+	due := []AutoAcceptCandidate{}
+	for rows.Next() {
+		var c AutoAcceptCandidate
+		if err := rows.Scan(&c.BookingID, &c.UserID, &c.MinReliabilityScore); err != nil {
+			return nil, fmt.Errorf("find bookings to auto-accept: %w", err)
+		}
+		due = append(due, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("find bookings to auto-accept: %w", err)
+	}
+	return due, nil
+}
+
+// AutoAccept confirms one booking returned by DueForAutoAccept. It returns
+// nil when the booking was answered or canceled since, or overlaps one
+// already confirmed on the same facility; that one is left pending for the
+// owner to sort out. One statement per booking, so two overlapping requests
+// can't both be confirmed in the same pass.
+func (r *Repository) AutoAccept(ctx context.Context, bookingID int64) (*AutoAccepted, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var a AutoAccepted
+	err := r.updateAttributed(ctx, 0, SourceAutoAccept, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, `
+			UPDATE bookings b
+			SET status = 'confirmed', updated_at = NOW()
+			FROM venues v
+			WHERE b.id = $1
+			  AND b.status = 'pending'
+			  AND v.id = b.venue_id
+			  AND v.auto_accept_after_minutes IS NOT NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM booking_deposits d
+				WHERE d.booking_id = b.id AND d.status <> 'paid'
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM bookings o
+				WHERE o.facility_id = b.facility_id
+				  AND o.id <> b.id
+				  AND o.status = 'confirmed'
+				  AND o.start_time < b.end_time
+				  AND o.end_time > b.start_time
+			  )
+			RETURNING b.id, b.venue_id, v.name, v.owner_id, b.user_id, b.start_time, b.end_time, v.auto_accept_after_minutes
+		`, bookingID).Scan(&a.BookingID, &a.VenueID, &a.VenueName, &a.OwnerID, &a.UserID, &a.StartTime, &a.EndTime, &a.WaitedMinutes)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Answered, canceled or clashing since the scan.
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("auto-accept booking %d: %w", bookingID, err)
+	}
+	return &a, nil
+}
@@ -0,0 +1,37 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Contact is the unmasked phone numbers of one booking: the booking user's
+// and, for manual bookings, the walk-in customer's.
+type Contact struct {
+	UserName      string  `json:"user_name"`
+	UserPhone     string  `json:"user_number"`
+	CustomerName  *string `json:"customer_name,omitempty" swaggertype:"string"`
+	CustomerPhone *string `json:"customer_phone,omitempty" swaggertype:"string"`
+}
+
+// GetContact returns the contact details of a booking at venueID, or
+// ErrNotFound when the booking belongs to another venue.
+func (r *Repository) GetContact(ctx context.Context, venueID, bookingID int64) (*Contact, error) {
+	var c Contact
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(u.first_name, ''), COALESCE(u.phone, ''), b.customer_name, b.customer_phone
+		FROM bookings b
+		JOIN users u ON u.id = b.user_id
+		WHERE b.id = $1 AND b.venue_id = $2
+	`, bookingID, venueID).Scan(&c.UserName, &c.UserPhone, &c.CustomerName, &c.CustomerPhone)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get booking contact: %w", err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,148 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/infra/dbx"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Where a booking change came from, as recorded on its event.
+const (
+	SourceUser           = "user"
+	SourceOwner          = "owner"
+	SourceAutoAccept     = "auto_accept"
+	SourceClosure        = "closure"
+	SourceGameAutoCancel = "game_auto_cancel"
+	SourcePaymentSplit   = "payment_split"
+	SourceExpiry         = "expiry"
+)
+
+// Attribute names who (actorID, 0 for nobody) and what (source) makes the
+// booking changes that follow in tx, for the events the database records
+// for them. It only lasts until tx ends.
+func Attribute(ctx context.Context, tx dbx.Querier, actorID int64, source string) error {
+	actor := ""
+	if actorID > 0 {
+		actor = strconv.FormatInt(actorID, 10)
+	}
+	if _, err := tx.Exec(ctx, `
+		SELECT set_config('khel.actor_id', $1, true), set_config('khel.event_source', $2, true)
+	`, actor, source); err != nil {
+		return fmt.Errorf("attribute booking change: %w", err)
+	}
+	return nil
+}
+
+// updateAttributed runs a booking update in a transaction attributed to
+// actorID and source.
+func (r *Repository) updateAttributed(ctx context.Context, actorID int64, source string, fn func(tx pgx.Tx) error) error {
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if err := Attribute(ctx, tx, actorID, source); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// ListEvents returns the booking's timeline, oldest first.
+func (r *Repository) ListEvents(ctx context.Context, bookingID int64) ([]Event, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT e.id, e.kind, e.from_status, e.to_status, e.from_start_time, e.from_end_time,
+		       e.start_time, e.end_time, e.actor_id, u.first_name, e.source, e.occurred_at
+		FROM booking_events e
+		LEFT JOIN users u ON u.id = e.actor_id
+		WHERE e.booking_id = $1
+		ORDER BY e.occurred_at, e.id
+	`, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("list booking events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Kind, &e.FromStatus, &e.ToStatus, &e.FromStartTime, &e.FromEndTime,
+			&e.StartTime, &e.EndTime, &e.ActorID, &e.ActorName, &e.Source, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("scan booking event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return events, nil
+}
+
+func (r *Repository) CanceledAt(ctx context.Context, bookingID int64) (time.Time, error) {
+	var at time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT occurred_at
+		FROM booking_events
+		WHERE booking_id = $1 AND kind = 'canceled'
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT 1
+	`, bookingID).Scan(&at)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrNotFound
+		}
+		return time.Time{}, fmt.Errorf("booking canceled at: %w", err)
+	}
+	return at, nil
+}
+
+// ResponseTimes is how a venue answered the booking requests made since
+// `since`. Requests made before events were recorded are left out, since
+// their answer time isn't known.
+func (r *Repository) ResponseTimes(ctx context.Context, venueID int64, since time.Time) (*ResponseStats, error) {
+	const q = `
+		WITH req AS (
+			SELECT e.booking_id, e.occurred_at AS requested_at
+			FROM booking_events e
+			JOIN bookings b ON b.id = e.booking_id
+			WHERE b.venue_id = $1
+			  AND e.kind = 'requested'
+			  AND e.source IS DISTINCT FROM 'backfill'
+			  AND e.occurred_at >= $2
+		),
+		answer AS (
+			SELECT DISTINCT ON (req.booking_id)
+			       req.booking_id, a.kind, a.source,
+			       EXTRACT(EPOCH FROM a.occurred_at - req.requested_at) / 60.0 AS minutes
+			FROM req
+			JOIN booking_events a ON a.booking_id = req.booking_id
+			WHERE a.kind IN ('accepted', 'rejected', 'canceled')
+			  AND a.from_status = 'pending'
+			ORDER BY req.booking_id, a.occurred_at, a.id
+		)
+		SELECT
+			(SELECT COUNT(*) FROM req)::INT,
+			COUNT(*) FILTER (WHERE kind = 'accepted')::INT,
+			COUNT(*) FILTER (WHERE kind = 'accepted' AND source = 'auto_accept')::INT,
+			COUNT(*) FILTER (WHERE kind = 'rejected')::INT,
+			COUNT(*) FILTER (WHERE kind = 'rejected' AND source = 'expiry')::INT,
+			COUNT(*) FILTER (WHERE kind = 'canceled')::INT,
+			AVG(minutes) FILTER (WHERE kind = 'accepted'),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY minutes) FILTER (WHERE kind = 'accepted'),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY minutes) FILTER (WHERE kind = 'accepted'),
+			AVG(minutes) FILTER (WHERE kind = 'rejected' AND source IS DISTINCT FROM 'expiry')
+		FROM answer
+	`
+	s := ResponseStats{Since: since}
+	err := r.db.QueryRow(ctx, q, venueID, since).Scan(
+		&s.Requests, &s.Accepted, &s.AutoAccepted, &s.Rejected, &s.Expired, &s.CanceledBeforeAnswer,
+		&s.AvgMinutesToAccept, &s.MedianMinutesToAccept, &s.P90MinutesToAccept, &s.AvgMinutesToReject,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("booking response times: %w", err)
+	}
+	s.Unanswered = s.Requests - s.Accepted - s.Rejected - s.CanceledBeforeAnswer
+	return &s, nil
+}
@@ -0,0 +1,81 @@
+package bookings
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Expired is a pending booking the expiry job rejected because the venue
+// never answered it.
+type Expired struct {
+	BookingID  int64
+	VenueID    int64
+	VenueName  string
+	FacilityID int64
+	UserID     int64
+	StartTime  time.Time
+	EndTime    time.Time
+	// Started is set when the request was still pending at its start time,
+	// rather than having waited out the TTL.
+	Started bool
+}
+
+// ExpireStale rejects up to limit pending bookings, oldest first, that have
+// started or waited longer than their venue's pending_ttl_minutes
+// (defaultTTLMinutes when it has none). A venue's auto-accept window, when
+// longer, gives its requests that long instead, so the auto-accept job gets
+// its turn first.
+func (r *Repository) ExpireStale(ctx context.Context, defaultTTLMinutes, limit int) ([]Expired, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	expired := []Expired{}
+	err := r.updateAttributed(ctx, 0, SourceExpiry, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			WITH due AS (
+				SELECT b.id
+				FROM bookings b
+				JOIN venues v ON v.id = b.venue_id
+				LEFT JOIN venue_booking_rules br ON br.venue_id = b.venue_id
+				WHERE b.status = 'pending'
+				  AND (
+					b.start_time <= NOW()
+					OR b.created_at <= NOW() - make_interval(mins => GREATEST(
+						COALESCE(br.pending_ttl_minutes, $1),
+						COALESCE(v.auto_accept_after_minutes, 0)
+					))
+				  )
+				ORDER BY b.created_at
+				LIMIT $2
+				FOR UPDATE OF b SKIP LOCKED
+			)
+			UPDATE bookings b
+			SET status = 'rejected', updated_at = NOW()
+			FROM due, venues v
+			WHERE b.id = due.id AND v.id = b.venue_id AND b.status = 'pending'
+			RETURNING b.id, b.venue_id, v.name, b.facility_id, b.user_id, b.start_time, b.end_time,
+			          b.start_time <= NOW()
+		`, defaultTTLMinutes, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var e Expired
+			if err := rows.Scan(&e.BookingID, &e.VenueID, &e.VenueName, &e.FacilityID, &e.UserID,
+				&e.StartTime, &e.EndTime, &e.Started); err != nil {
+				return err
+			}
+			expired = append(expired, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("expire pending bookings: %w", err)
+	}
+	return expired, nil
+}
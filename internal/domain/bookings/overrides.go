@@ -0,0 +1,263 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrOverrideNotFound = errors.New("pricing override not found")
+	ErrOverrideOverlap  = errors.New("pricing override overlaps another override on that date")
+)
+
+// PricingOverride prices a facility on one date. StartTime and EndTime carry
+// only the clock part, like PricingSlot.
+type PricingOverride struct {
+	ID         int64     `json:"id"`
+	VenueID    int64     `json:"venue_id"`
+	FacilityID int64     `json:"facility_id"`
+	Date       string    `json:"date"` // YYYY-MM-DD, Nepal local date
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Price      int       `json:"price"`
+	Label      *string   `json:"label,omitempty" swaggertype:"string"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+const overrideColumns = `id, venue_id, facility_id, to_char(date, 'YYYY-MM-DD'), start_time, end_time, price, label, created_at, updated_at`
+
+func scanOverride(row pgx.Row, o *PricingOverride) error {
+	return row.Scan(&o.ID, &o.VenueID, &o.FacilityID, &o.Date, &o.StartTime, &o.EndTime, &o.Price, &o.Label, &o.CreatedAt, &o.UpdatedAt)
+}
+
+// GetPricingForDate returns the pricing that applies to a facility on the
+// local date of date: that date's overrides if there are any, otherwise the
+// weekly slots for its weekday, adjusted by the venue's holiday rate (or
+// dropped if it closes) when the date is a public holiday. A facility with a price adjustment and no
+// pricing of its own that day is priced like the venue's default facility,
+// adjusted.
+func (r *Repository) GetPricingForDate(ctx context.Context, venueID, facilityID int64, date time.Time) ([]PricingSlot, error) {
+	slots, err := r.ownPricingForDate(ctx, venueID, facilityID, date)
+	if err != nil || len(slots) > 0 {
+		return slots, err
+	}
+
+	var (
+		defaultID  int64
+		adjustment int
+	)
+	err = r.db.QueryRow(ctx, `
+		SELECT d.id, f.price_adjustment_percent
+		FROM facilities f
+		JOIN facilities d ON d.venue_id = f.venue_id
+		                 AND d.is_default
+		                 AND d.id <> f.id
+		WHERE f.id = $1
+		  AND f.venue_id = $2
+		  AND f.price_adjustment_percent IS NOT NULL
+	`, facilityID, venueID).Scan(&defaultID, &adjustment)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return slots, nil
+		}
+		return nil, fmt.Errorf("get facility price adjustment: %w", err)
+	}
+
+	slots, err = r.ownPricingForDate(ctx, venueID, defaultID, date)
+	if err != nil {
+		return nil, err
+	}
+	for i := range slots {
+		slots[i].FacilityID = facilityID
+		slots[i].Price = slots[i].Price * (100 + adjustment) / 100
+		slots[i].Inherited = true
+	}
+	return slots, nil
+}
+
+func (r *Repository) ownPricingForDate(ctx context.Context, venueID, facilityID int64, date time.Time) ([]PricingSlot, error) {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kathmandu timezone: %w", err)
+	}
+	local := date.In(loc)
+	dayOfWeek := strings.ToLower(local.Weekday().String())
+
+	query := `
+		WITH o AS (
+			SELECT id, venue_id, facility_id, start_time, end_time, price
+			FROM venue_pricing_overrides
+			WHERE venue_id = $1
+			  AND facility_id = $2
+			  AND date = $3::date
+		),
+		-- The venue's holiday settings, only when the date is a public holiday.
+		hol AS (
+			SELECT rate_percent, closed
+			FROM venue_holiday_settings
+			WHERE venue_id = $1
+			  AND EXISTS (SELECT 1 FROM public_holidays WHERE date = $3::date)
+		)
+		SELECT id, venue_id, facility_id, $4::text, start_time, end_time, price, TRUE, FALSE
+		FROM o
+		UNION ALL
+		SELECT id, venue_id, facility_id, day_of_week, start_time, end_time,
+		       price * (100 + COALESCE((SELECT rate_percent FROM hol), 0)) / 100,
+		       FALSE, EXISTS (SELECT 1 FROM hol)
+		FROM venue_pricing
+		WHERE venue_id = $1
+		  AND facility_id = $2
+		  AND day_of_week = $4
+		  AND NOT EXISTS (SELECT 1 FROM o)
+		  AND NOT COALESCE((SELECT closed FROM hol), FALSE)
+		ORDER BY 5
+	`
+
+	rows, err := r.db.Query(ctx, query, venueID, facilityID, local.Format("2006-01-02"), dayOfWeek)
+	if err != nil {
+		return nil, fmt.Errorf("get pricing for date: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []PricingSlot
+	for rows.Next() {
+		var ps PricingSlot
+		if err := rows.Scan(&ps.ID, &ps.VenueID, &ps.FacilityID, &ps.DayOfWeek, &ps.StartTime, &ps.EndTime, &ps.Price, &ps.Override, &ps.Holiday); err != nil {
+			return nil, fmt.Errorf("scan pricing slot: %w", err)
+		}
+		slots = append(slots, ps)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return slots, nil
+}
+
+// ListPricingOverrides returns a facility's overrides between from and to
+// (inclusive, YYYY-MM-DD). Empty bounds are open.
+func (r *Repository) ListPricingOverrides(ctx context.Context, venueID, facilityID int64, from, to string) ([]PricingOverride, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+overrideColumns+`
+		FROM venue_pricing_overrides
+		WHERE venue_id = $1
+		  AND facility_id = $2
+		  AND ($3 = '' OR date >= $3::date)
+		  AND ($4 = '' OR date <= $4::date)
+		ORDER BY date, start_time
+	`, venueID, facilityID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list pricing overrides: %w", err)
+	}
+	defer rows.Close()
+
+	list := []PricingOverride{}
+	for rows.Next() {
+		var o PricingOverride
+		if err := scanOverride(rows, &o); err != nil {
+			return nil, fmt.Errorf("scan pricing override: %w", err)
+		}
+		list = append(list, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+// checkOverrideOverlap rejects an override that overlaps another one of the
+// same facility and date. excludeID skips the row being updated.
+func checkOverrideOverlap(ctx context.Context, tx pgx.Tx, o *PricingOverride, excludeID int64) error {
+	var overlaps bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM venue_pricing_overrides
+			WHERE facility_id = $1
+			  AND date = $2::date
+			  AND id <> $3
+			  AND start_time < $5
+			  AND end_time > $4
+		)
+	`, o.FacilityID, o.Date, excludeID, o.StartTime, o.EndTime).Scan(&overlaps)
+	if err != nil {
+		return fmt.Errorf("check override overlap: %w", err)
+	}
+	if overlaps {
+		return ErrOverrideOverlap
+	}
+	return nil
+}
+
+// CreatePricingOverrides inserts overrides in one transaction; any overlap,
+// with each other or with existing rows, rejects the whole batch.
+func (r *Repository) CreatePricingOverrides(ctx context.Context, overrides []*PricingOverride) error {
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		for _, o := range overrides {
+			if err := checkOverrideOverlap(ctx, tx, o, 0); err != nil {
+				return err
+			}
+			err := scanOverride(tx.QueryRow(ctx, `
+				INSERT INTO venue_pricing_overrides (venue_id, facility_id, date, start_time, end_time, price, label)
+				VALUES ($1, $2, $3::date, $4, $5, $6, $7)
+				RETURNING `+overrideColumns,
+				o.VenueID, o.FacilityID, o.Date, o.StartTime, o.EndTime, o.Price, o.Label,
+			), o)
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+					return ErrOverrideOverlap
+				}
+				return fmt.Errorf("create pricing override: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Repository) UpdatePricingOverride(ctx context.Context, o *PricingOverride) error {
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if err := checkOverrideOverlap(ctx, tx, o, o.ID); err != nil {
+			return err
+		}
+		err := scanOverride(tx.QueryRow(ctx, `
+			UPDATE venue_pricing_overrides
+			SET date = $4::date, start_time = $5, end_time = $6, price = $7, label = $8, updated_at = NOW()
+			WHERE id = $1 AND venue_id = $2 AND facility_id = $3
+			RETURNING `+overrideColumns,
+			o.ID, o.VenueID, o.FacilityID, o.Date, o.StartTime, o.EndTime, o.Price, o.Label,
+		), o)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrOverrideNotFound
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrOverrideOverlap
+			}
+			return fmt.Errorf("update pricing override: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) DeletePricingOverride(ctx context.Context, venueID, facilityID, overrideID int64) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM venue_pricing_overrides
+		WHERE id = $1 AND venue_id = $2 AND facility_id = $3
+	`, overrideID, venueID, facilityID)
+	if err != nil {
+		return fmt.Errorf("delete pricing override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrReceiptNotFound = errors.New("booking receipt not found")
+
+// Receipt points at the stored PDF receipt of a booking.
+type Receipt struct {
+	BookingID   int64     `json:"booking_id"`
+	URL         string    `json:"url"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+func (r *Repository) GetReceipt(ctx context.Context, bookingID int64) (*Receipt, error) {
+	rc := Receipt{BookingID: bookingID}
+	err := r.db.QueryRow(ctx, `
+		SELECT url, generated_at FROM booking_receipts WHERE booking_id = $1
+	`, bookingID).Scan(&rc.URL, &rc.GeneratedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReceiptNotFound
+		}
+		return nil, fmt.Errorf("get booking receipt: %w", err)
+	}
+	return &rc, nil
+}
+
+func (r *Repository) SaveReceipt(ctx context.Context, rc *Receipt) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO booking_receipts (booking_id, url)
+		VALUES ($1, $2)
+		ON CONFLICT (booking_id) DO UPDATE
+		SET url = EXCLUDED.url, generated_at = NOW()
+		RETURNING generated_at
+	`, rc.BookingID, rc.URL).Scan(&rc.GeneratedAt)
+	if err != nil {
+		return fmt.Errorf("save booking receipt: %w", err)
+	}
+	return nil
+}
@@ -20,6 +20,16 @@ type Store interface {
 	UpdatePricing(ctx context.Context, p *PricingSlot) error
 	DeletePricingSlot(ctx context.Context, venueID, facilityID, pricingID int64) error
 
+	// GetPricingForDate is what bookings are priced with: the date's
+	// overrides when it has any, else the weekly slots.
+	GetPricingForDate(ctx context.Context, venueID, facilityID int64, date time.Time) ([]PricingSlot, error)
+	ListPricingOverrides(ctx context.Context, venueID, facilityID int64, from, to string) ([]PricingOverride, error)
+	CreatePricingOverrides(ctx context.Context, overrides []*PricingOverride) error
+	UpdatePricingOverride(ctx context.Context, o *PricingOverride) error
+	DeletePricingOverride(ctx context.Context, venueID, facilityID, overrideID int64) error
+
+	// GetBookingsForDate returns the busy intervals on the local date: live
+	// bookings of the facility and the venue's emergency closures.
 	GetBookingsForDate(ctx context.Context, venueID, facilityID int64, date time.Time) ([]Interval, error)
 	CreateBooking(ctx context.Context, booking *Booking) (int64, error)
 	GetBookingByID(ctx context.Context, bookingID int64) (*Booking, error)
@@ -28,15 +38,45 @@ type Store interface {
 	GetCanceledBookingsForVenueDate(ctx context.Context, venueID, facilityID int64, date time.Time) ([]CanceledBooking, error)
 	GetScheduledBookingsForVenueDate(ctx context.Context, venueID, facilityID int64, date time.Time) ([]ScheduledBooking, error)
 
-	UpdateBookingStatus(ctx context.Context, venueID, bookingID int64, status string) error
-	AcceptBooking(ctx context.Context, venueID, bookingID int64) error
-	RejectBooking(ctx context.Context, venueID, bookingID int64) error
-	CancelBooking(ctx context.Context, venueID, bookingID int64) error
+	// UpdateBookingStatus and the methods built on it attribute the change
+	// to actorID acting as source on the booking's timeline.
+	UpdateBookingStatus(ctx context.Context, venueID, bookingID int64, status string, actorID int64, source string) error
+	AcceptBooking(ctx context.Context, venueID, bookingID, actorID int64) error
+	RejectBooking(ctx context.Context, venueID, bookingID, actorID int64) error
+	CancelBooking(ctx context.Context, venueID, bookingID, actorID int64) error
+	// DueForAutoAccept lists pending bookings whose venue's auto-accept
+	// window has passed, and AutoAccept confirms one of them.
+	DueForAutoAccept(ctx context.Context, afterID int64, limit int) ([]AutoAcceptCandidate, error)
+	AutoAccept(ctx context.Context, bookingID int64) (*AutoAccepted, error)
+	// ExpireStale rejects pending bookings the venue left unanswered past
+	// their TTL or start time.
+	ExpireStale(ctx context.Context, defaultTTLMinutes, limit int) ([]Expired, error)
 
 	GetBookingsByUser(ctx context.Context, userID int64, filter BookingFilter) ([]UserBooking, error)
+	GetUpcomingBookingsByUser(ctx context.Context, userID int64, limit int) ([]UserBooking, error)
 	GetVenueOwnerIDFromBookingID(ctx context.Context, bookingID int64) (int64, error)
 
-	CloseBooking(ctx context.Context, venueID int64, bookingID int64, method string, paidAmount int, finalAmount int) error
+	CloseBooking(ctx context.Context, venueID int64, bookingID int64, method string, paidAmount int, finalAmount int, actorID int64) error
+
+	// ListEvents is the booking's timeline: every change of status or time,
+	// oldest first.
+	ListEvents(ctx context.Context, bookingID int64) ([]Event, error)
+	// CanceledAt is when the booking was last canceled, from its timeline.
+	// ErrNotFound when it never was.
+	CanceledAt(ctx context.Context, bookingID int64) (time.Time, error)
+	// ResponseTimes derives how fast the venue answers requests from the
+	// timelines of those made since the given time.
+	ResponseTimes(ctx context.Context, venueID int64, since time.Time) (*ResponseStats, error)
+
+	// SuggestSlots returns free alternatives when a requested slot is taken.
+	SuggestSlots(ctx context.Context, q SuggestionQuery) ([]SlotSuggestion, error)
+
+	// GetContact returns a booking's unmasked phone numbers for the reveal
+	// action; lists only ever show them masked.
+	GetContact(ctx context.Context, venueID, bookingID int64) (*Contact, error)
+
+	GetReceipt(ctx context.Context, bookingID int64) (*Receipt, error)
+	SaveReceipt(ctx context.Context, rc *Receipt) error
 }
 
 type Repository struct {
@@ -54,6 +94,7 @@ func (r *Repository) CloseBooking(
 	method string,
 	paidAmount int,
 	finalAmount int,
+	actorID int64,
 ) error {
 	query := `
 		UPDATE bookings
@@ -69,24 +110,26 @@ func (r *Repository) CloseBooking(
 		  AND status = 'confirmed'
 	`
 
-	result, err := r.db.Exec(
-		ctx,
-		query,
-		method,
-		paidAmount,
-		finalAmount,
-		bookingID,
-		venueID,
-	)
-	if err != nil {
-		return fmt.Errorf("close booking: %w", err)
-	}
+	return r.updateAttributed(ctx, actorID, SourceOwner, func(tx pgx.Tx) error {
+		result, err := tx.Exec(
+			ctx,
+			query,
+			method,
+			paidAmount,
+			finalAmount,
+			bookingID,
+			venueID,
+		)
+		if err != nil {
+			return fmt.Errorf("close booking: %w", err)
+		}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("booking not found or already closed")
-	}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("booking not found or already closed")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (r *Repository) GetBookingOwner(ctx context.Context, venueID, bookingID int64) (int64, error) {
@@ -177,7 +220,16 @@ func (r *Repository) GetBookingsForDate(ctx context.Context, venueID, facilityID
 		  AND status IN ('pending', 'confirmed')
 		  AND start_time < $3
 		  AND end_time > $4
-		ORDER BY start_time
+
+		UNION ALL
+
+		-- A closure shuts every facility, so it blocks the time like a booking.
+		SELECT start_time, end_time
+		FROM venue_closures
+		WHERE venue_id = $1
+		  AND start_time < $3
+		  AND end_time > $4
+		ORDER BY 1
 	`
 
 	rows, err := r.db.Query(ctx, query, venueID, facilityID, endOfDayUTC, startOfDayUTC)
@@ -389,7 +441,7 @@ func (r *Repository) GetBookingByID(ctx context.Context, bookingID int64) (*Book
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("booking not found")
+			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get booking: %w", err)
 	}
@@ -718,7 +770,7 @@ func (r *Repository) GetScheduledBookingsForVenueDate(
 }
 
 // UpdateBookingStatus sets a new status ("confirmed", "rejected", etc.) on a booking.
-func (r *Repository) UpdateBookingStatus(ctx context.Context, venueID, bookingID int64, status string) error {
+func (r *Repository) UpdateBookingStatus(ctx context.Context, venueID, bookingID int64, status string, actorID int64, source string) error {
 	const q = `
       UPDATE bookings
       SET status    = $1,
@@ -726,31 +778,33 @@ func (r *Repository) UpdateBookingStatus(ctx context.Context, venueID, bookingID
       WHERE id       = $2
         AND venue_id = $3
     `
-	res, err := r.db.Exec(ctx, q, status, bookingID, venueID)
-	if err != nil {
-		return err
-	}
-	rows := res.RowsAffected()
+	return r.updateAttributed(ctx, actorID, source, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, q, status, bookingID, venueID)
+		if err != nil {
+			return err
+		}
+		rows := res.RowsAffected()
 
-	if rows == 0 {
-		return fmt.Errorf("failed to update booking status for bookingID=%d and venueID=%d", bookingID, venueID)
-	}
-	return nil
+		if rows == 0 {
+			return fmt.Errorf("failed to update booking status for bookingID=%d and venueID=%d", bookingID, venueID)
+		}
+		return nil
+	})
 }
 
 // AcceptBooking marks a pending booking as confirmed.
-func (r *Repository) AcceptBooking(ctx context.Context, venueID, bookingID int64) error {
-	return r.UpdateBookingStatus(ctx, venueID, bookingID, "confirmed")
+func (r *Repository) AcceptBooking(ctx context.Context, venueID, bookingID, actorID int64) error {
+	return r.UpdateBookingStatus(ctx, venueID, bookingID, "confirmed", actorID, SourceOwner)
 }
 
 // RejectBooking marks a pending booking as rejected.
-func (r *Repository) RejectBooking(ctx context.Context, venueID, bookingID int64) error {
-	return r.UpdateBookingStatus(ctx, venueID, bookingID, "rejected")
+func (r *Repository) RejectBooking(ctx context.Context, venueID, bookingID, actorID int64) error {
+	return r.UpdateBookingStatus(ctx, venueID, bookingID, "rejected", actorID, SourceOwner)
 }
 
-// RejectBooking marks a pending booking as rejected.
-func (r *Repository) CancelBooking(ctx context.Context, venueID, bookingID int64) error {
-	return r.UpdateBookingStatus(ctx, venueID, bookingID, "canceled")
+// CancelBooking is the booker calling a booking off.
+func (r *Repository) CancelBooking(ctx context.Context, venueID, bookingID, actorID int64) error {
+	return r.UpdateBookingStatus(ctx, venueID, bookingID, "canceled", actorID, SourceUser)
 }
 
 func (r *Repository) GetBookingsByUser(ctx context.Context, userID int64, filter BookingFilter) ([]UserBooking, error) {
@@ -810,3 +864,61 @@ func (r *Repository) GetBookingsByUser(ctx context.Context, userID int64, filter
 	}
 	return out, rows.Err()
 }
+
+// GetUpcomingBookingsByUser returns the user's confirmed bookings that have
+// not ended yet, soonest first.
+func (r *Repository) GetUpcomingBookingsByUser(ctx context.Context, userID int64, limit int) ([]UserBooking, error) {
+	const query = `
+		SELECT
+			b.id,
+			b.venue_id,
+			b.facility_id,
+			v.name,
+			COALESCE(f.name, ''),
+			v.address,
+			b.start_time,
+			b.end_time,
+			b.total_price,
+			b.status,
+			b.created_at
+		FROM bookings b
+		JOIN venues v ON v.id = b.venue_id
+		LEFT JOIN facilities f ON f.id = b.facility_id
+		WHERE b.user_id = $1
+		  AND b.status = 'confirmed'
+		  AND b.end_time > NOW()
+		ORDER BY b.start_time
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get upcoming bookings: %w", err)
+	}
+	defer rows.Close()
+
+	out := []UserBooking{}
+	for rows.Next() {
+		var ub UserBooking
+		if err := rows.Scan(
+			&ub.BookingID,
+			&ub.VenueID,
+			&ub.FacilityID,
+			&ub.VenueName,
+			&ub.FacilityName,
+			&ub.VenueAddress,
+			&ub.StartTime,
+			&ub.EndTime,
+			&ub.TotalPrice,
+			&ub.Status,
+			&ub.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan upcoming booking: %w", err)
+		}
+		out = append(out, ub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
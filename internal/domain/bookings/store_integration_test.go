@@ -0,0 +1,149 @@
+//go:build integration
+
+package bookings
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"khel/internal/testutil"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+var kathmandu = time.FixedZone("NPT", 5*3600+45*60)
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, time.March, day, hour, minute, 0, 0, kathmandu)
+}
+
+func clock(hour int) time.Time {
+	return time.Date(0, 1, 1, hour, 0, 0, 0, time.UTC)
+}
+
+func insertBooking(t *testing.T, db *pgxpool.Pool, venueID, facilityID, userID int64, start, end time.Time, status string) {
+	t.Helper()
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO bookings (venue_id, facility_id, user_id, start_time, end_time, total_price, status)
+		VALUES ($1, $2, $3, $4, $5, 1000, $6::booking_status)
+	`, venueID, facilityID, userID, start, end, status)
+	if err != nil {
+		t.Fatalf("insert booking: %v", err)
+	}
+}
+
+func TestGetBookingsForDate(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	user := testutil.CreateUser(t, db)
+	venueID, facilityID := testutil.CreateVenue(t, db, testutil.Venue{})
+	_, otherFacility := testutil.CreateVenue(t, db, testutil.Venue{})
+
+	// Day 10, Nepal time.
+	insertBooking(t, db, venueID, facilityID, user, at(10, 7, 0), at(10, 8, 0), "confirmed")
+	insertBooking(t, db, venueID, facilityID, user, at(10, 18, 0), at(10, 19, 0), "pending")
+	insertBooking(t, db, venueID, facilityID, user, at(10, 12, 0), at(10, 13, 0), "canceled")
+	insertBooking(t, db, venueID, facilityID, user, at(10, 14, 0), at(10, 15, 0), "rejected")
+	// Runs over midnight into day 10.
+	insertBooking(t, db, venueID, facilityID, user, at(9, 23, 0), at(10, 1, 0), "confirmed")
+	// Ends exactly when day 10 starts, so it doesn't touch it.
+	insertBooking(t, db, venueID, facilityID, user, at(9, 22, 0), at(10, 0, 0), "confirmed")
+	// Starts exactly when day 10 ends.
+	insertBooking(t, db, venueID, facilityID, user, at(11, 0, 0), at(11, 1, 0), "confirmed")
+	// Same time, different facility.
+	insertBooking(t, db, venueID, otherFacility, user, at(10, 9, 0), at(10, 10, 0), "confirmed")
+
+	// Midday UTC on day 10 is still day 10 in Kathmandu.
+	got, err := repo.GetBookingsForDate(ctx, venueID, facilityID, at(10, 12, 0).UTC())
+	if err != nil {
+		t.Fatalf("GetBookingsForDate: %v", err)
+	}
+
+	want := []Interval{
+		{at(9, 23, 0), at(10, 1, 0)},
+		{at(10, 7, 0), at(10, 8, 0)},
+		{at(10, 18, 0), at(10, 19, 0)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d intervals %v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d = %s-%s, want %s-%s", i, got[i].Start, got[i].End, want[i].Start, want[i].End)
+		}
+	}
+}
+
+func TestConfirmedBookingsCannotShareASlot(t *testing.T) {
+	db := testutil.DB(t)
+	user := testutil.CreateUser(t, db)
+	venueID, facilityID := testutil.CreateVenue(t, db, testutil.Venue{})
+
+	insertBooking(t, db, venueID, facilityID, user, at(12, 7, 0), at(12, 8, 0), "confirmed")
+	// Any number of pending requests may ask for the same slot.
+	insertBooking(t, db, venueID, facilityID, user, at(12, 7, 0), at(12, 8, 0), "pending")
+	insertBooking(t, db, venueID, facilityID, user, at(12, 7, 0), at(12, 8, 0), "pending")
+
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO bookings (venue_id, facility_id, user_id, start_time, end_time, total_price, status)
+		VALUES ($1, $2, $3, $4, $5, 1000, 'confirmed')
+	`, venueID, facilityID, user, at(12, 7, 0), at(12, 8, 0))
+	if err == nil {
+		t.Fatal("a second confirmed booking for the same slot was accepted")
+	}
+}
+
+func TestCreatePricingOverridesRejectsOverlap(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewRepository(db).(*Repository)
+	ctx := context.Background()
+
+	venueID, facilityID := testutil.CreateVenue(t, db, testutil.Venue{})
+	override := func(date string, from, to int) *PricingOverride {
+		return &PricingOverride{
+			VenueID: venueID, FacilityID: facilityID, Date: date,
+			StartTime: clock(from), EndTime: clock(to), Price: 1500,
+		}
+	}
+
+	if err := repo.CreatePricingOverrides(ctx, []*PricingOverride{
+		override("2026-04-14", 6, 10),
+		override("2026-04-14", 10, 14), // touching is fine
+	}); err != nil {
+		t.Fatalf("CreatePricingOverrides: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		batch []*PricingOverride
+		want  error
+	}{
+		{"overlaps an existing override", []*PricingOverride{override("2026-04-14", 9, 11)}, ErrOverrideOverlap},
+		{"inside an existing override", []*PricingOverride{override("2026-04-14", 7, 8)}, ErrOverrideOverlap},
+		{"overlaps within the batch", []*PricingOverride{override("2026-04-15", 6, 9), override("2026-04-15", 8, 12)}, ErrOverrideOverlap},
+		{"another date", []*PricingOverride{override("2026-04-15", 6, 10)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.CreatePricingOverrides(ctx, tt.batch)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// The rejected batch on the 15th must not have left half of itself behind.
+	var count int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM venue_pricing_overrides WHERE date = '2026-04-15'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("overrides on 2026-04-15 = %d, want 1", count)
+	}
+}
@@ -0,0 +1,191 @@
+package bookings
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SuggestionSameVenue   = "same_venue"
+	SuggestionNearbyVenue = "nearby_venue"
+)
+
+// SlotSuggestion is a free slot offered when the requested one is taken.
+type SlotSuggestion struct {
+	Kind           string    `json:"kind"`
+	VenueID        int64     `json:"venue_id"`
+	VenueName      string    `json:"venue_name"`
+	FacilityID     int64     `json:"facility_id"`
+	FacilityName   string    `json:"facility_name"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	PricePerHour   int       `json:"price_per_hour"`
+	DistanceMeters float64   `json:"distance_meters"`
+}
+
+// SuggestionQuery describes the slot that was taken.
+type SuggestionQuery struct {
+	VenueID    int64
+	FacilityID int64
+	Start      time.Time
+	End        time.Time
+	// RadiusMeters bounds the nearby-venue search.
+	RadiusMeters float64
+	// Limit applies to each kind separately.
+	Limit int
+}
+
+// SuggestSlots finds free slots of the same length and sport, in one query:
+// the same time at other active venues within the radius (nearest first,
+// one facility per venue), and other times the same local day at the
+// requested venue (closest to the requested start first). A slot counts as
+// free when a single pricing row for that date (override, weekly or
+// inherited from the default facility) covers it and no pending or
+// confirmed booking overlaps it.
+func (r *Repository) SuggestSlots(ctx context.Context, q SuggestionQuery) ([]SlotSuggestion, error) {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kathmandu timezone: %w", err)
+	}
+	local := q.Start.In(loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	dayEnd := dayStart.Add(24 * time.Hour)
+
+	const query = `
+		WITH req AS (
+			SELECT v.location, COALESCE(f.sport, v.sport) AS sport
+			FROM facilities f
+			JOIN venues v ON v.id = f.venue_id
+			WHERE f.id = $2 AND f.venue_id = $1
+		),
+		candidates AS (
+			SELECT $8::text AS kind, v.id AS venue_id, v.name AS venue_name,
+			       f.id AS facility_id, f.name AS facility_name,
+			       $3::timestamptz AS start_time, $4::timestamptz AS end_time,
+			       ST_Distance(v.location, req.location) AS distance_m
+			FROM req
+			JOIN venues v ON v.id <> $1
+			             AND v.status = 'active'
+			             AND ST_DWithin(v.location, req.location, $5)
+			JOIN facilities f ON f.venue_id = v.id
+			                 AND f.is_active
+			                 AND COALESCE(f.sport, v.sport) = req.sport
+
+			UNION ALL
+
+			SELECT $9::text, v.id, v.name, f.id, f.name,
+			       s, s + ($4::timestamptz - $3::timestamptz), 0::float8
+			FROM req
+			JOIN venues v ON v.id = $1
+			JOIN facilities f ON f.venue_id = v.id
+			                 AND f.is_active
+			                 AND COALESCE(f.sport, v.sport) = req.sport
+			CROSS JOIN generate_series(
+				$6::timestamptz,
+				$7::timestamptz - ($4::timestamptz - $3::timestamptz),
+				interval '30 minutes'
+			) AS s
+			WHERE s > NOW()
+			  AND NOT (f.id = $2 AND s = $3::timestamptz)
+		),
+		-- Every candidate falls on the requested local date, so pricing is
+		-- that date's overrides per facility, else its weekly slots with the
+		-- venue's holiday rate applied on public holidays.
+		own_pricing AS (
+			SELECT o.facility_id, o.start_time, o.end_time, o.price
+			FROM venue_pricing_overrides o
+			WHERE o.date = $12::date
+			UNION ALL
+			SELECT vp.facility_id, vp.start_time, vp.end_time,
+			       vp.price * (100 + COALESCE(hs.rate_percent, 0)) / 100
+			FROM venue_pricing vp
+			LEFT JOIN venue_holiday_settings hs
+			       ON hs.venue_id = vp.venue_id
+			      AND EXISTS (SELECT 1 FROM public_holidays h WHERE h.date = $12::date)
+			WHERE vp.day_of_week = $11
+			  AND NOT COALESCE(hs.closed, FALSE)
+			  AND NOT EXISTS (
+			      SELECT 1
+			      FROM venue_pricing_overrides o
+			      WHERE o.facility_id = vp.facility_id
+			        AND o.date = $12::date
+			  )
+		),
+		-- Facilities with a price adjustment and no pricing of their own
+		-- that day are priced like the venue's default facility.
+		pricing AS (
+			SELECT * FROM own_pricing
+			UNION ALL
+			SELECT f.id, p.start_time, p.end_time, p.price * (100 + f.price_adjustment_percent) / 100
+			FROM facilities f
+			JOIN facilities d ON d.venue_id = f.venue_id
+			                 AND d.is_default
+			                 AND d.id <> f.id
+			JOIN own_pricing p ON p.facility_id = d.id
+			WHERE f.price_adjustment_percent IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM own_pricing x WHERE x.facility_id = f.id)
+		),
+		free AS (
+			SELECT c.*, p.price,
+			       ROW_NUMBER() OVER (
+			           PARTITION BY c.kind, c.venue_id, c.start_time
+			           ORDER BY (c.facility_id = $2) DESC, c.facility_id
+			       ) AS per_slot
+			FROM candidates c
+			JOIN pricing p
+			  ON p.facility_id = c.facility_id
+			 AND p.start_time <= (c.start_time AT TIME ZONE 'Asia/Kathmandu')::time
+			 AND p.end_time >= (c.end_time AT TIME ZONE 'Asia/Kathmandu')::time
+			WHERE NOT EXISTS (
+				SELECT 1
+				FROM bookings b
+				WHERE b.facility_id = c.facility_id
+				  AND b.status IN ('pending', 'confirmed')
+				  AND b.start_time < c.end_time
+				  AND b.end_time > c.start_time
+			)
+		),
+		ranked AS (
+			SELECT free.*,
+			       ROW_NUMBER() OVER (
+			           PARTITION BY kind
+			           ORDER BY distance_m, ABS(EXTRACT(EPOCH FROM start_time - $3::timestamptz)), start_time
+			       ) AS rn
+			FROM free
+			WHERE per_slot = 1
+		)
+		SELECT kind, venue_id, venue_name, facility_id, facility_name,
+		       start_time, end_time, price, distance_m
+		FROM ranked
+		WHERE rn <= $10
+		ORDER BY kind DESC, rn
+	`
+
+	rows, err := r.db.Query(ctx, query,
+		q.VenueID, q.FacilityID, q.Start, q.End, q.RadiusMeters,
+		dayStart, dayEnd, SuggestionNearbyVenue, SuggestionSameVenue, q.Limit,
+		strings.ToLower(local.Weekday().String()), local.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("suggest slots: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []SlotSuggestion{}
+	for rows.Next() {
+		var s SlotSuggestion
+		if err := rows.Scan(
+			&s.Kind, &s.VenueID, &s.VenueName, &s.FacilityID, &s.FacilityName,
+			&s.StartTime, &s.EndTime, &s.PricePerHour, &s.DistanceMeters,
+		); err != nil {
+			return nil, fmt.Errorf("scan slot suggestion: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return suggestions, nil
+}
@@ -1,6 +1,11 @@
 package bookings
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("booking not found")
 
 type PricingSlot struct {
 	ID         int64     `json:"id"`
@@ -10,6 +15,14 @@ type PricingSlot struct {
 	StartTime  time.Time `json:"start_time"`
 	EndTime    time.Time `json:"end_time"`
 	Price      int       `json:"price"`
+	// Override is set when the slot comes from a date-specific override.
+	Override bool `json:"override,omitempty"`
+	// Inherited is set when the slot comes from the venue's default facility
+	// and was adjusted by the facility's price adjustment.
+	Inherited bool `json:"inherited,omitempty"`
+	// Holiday is set when the date is a public holiday and the venue's
+	// holiday rate was applied.
+	Holiday bool `json:"holiday,omitempty"`
 }
 
 // Booking represents a booking record.
@@ -108,3 +121,39 @@ type BookingFilter struct {
 func (f BookingFilter) offset() int {
 	return (f.Page - 1) * f.Limit
 }
+
+// Event is one step of a booking's timeline. FromStartTime and FromEndTime
+// are set on rescheduled events only.
+type Event struct {
+	ID            int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	FromStatus    *string    `json:"from_status,omitempty"`
+	ToStatus      string     `json:"to_status"`
+	FromStartTime *time.Time `json:"from_start_time,omitempty"`
+	FromEndTime   *time.Time `json:"from_end_time,omitempty"`
+	StartTime     time.Time  `json:"start_time"`
+	EndTime       time.Time  `json:"end_time"`
+	ActorID       *int64     `json:"actor_id,omitempty"`
+	ActorName     *string    `json:"actor_name,omitempty"`
+	Source        *string    `json:"source,omitempty"`
+	OccurredAt    time.Time  `json:"occurred_at"`
+}
+
+// ResponseStats describes how quickly a venue answers booking requests.
+// Times are in minutes from the request; they are null when nothing was
+// answered that way.
+type ResponseStats struct {
+	Since        time.Time `json:"since"`
+	Requests     int       `json:"requests"`
+	Accepted     int       `json:"accepted"`
+	AutoAccepted int       `json:"auto_accepted"`
+	Rejected     int       `json:"rejected"`
+	// Expired counts the rejections made by the expiry job, not the venue.
+	Expired               int      `json:"expired"`
+	CanceledBeforeAnswer  int      `json:"canceled_before_answer"`
+	Unanswered            int      `json:"unanswered"`
+	AvgMinutesToAccept    *float64 `json:"avg_minutes_to_accept"`
+	MedianMinutesToAccept *float64 `json:"median_minutes_to_accept"`
+	P90MinutesToAccept    *float64 `json:"p90_minutes_to_accept"`
+	AvgMinutesToReject    *float64 `json:"avg_minutes_to_reject"`
+}
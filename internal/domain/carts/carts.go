@@ -245,9 +245,10 @@ func (r *Repository) AddItem(ctx context.Context, userID, variantID int64, qty i
 
 	const q = `
 WITH pv AS (
-  SELECT price_cents
-  FROM product_variants
-  WHERE id = $1 AND is_active = true
+  SELECT v.price_cents
+  FROM product_variants v
+  JOIN products p ON p.id = v.product_id
+  WHERE v.id = $1 AND v.is_active = true AND p.deleted_at IS NULL
 )
 INSERT INTO cart_items (cart_id, product_variant_id, quantity, price_cents)
 SELECT $2, $1, $3, pv.price_cents
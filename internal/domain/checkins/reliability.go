@@ -0,0 +1,111 @@
+package checkins
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Reliability only looks at what ended in the last ReliabilityLookbackDays,
+// and gives no score until a player has ReliabilityMinSample commitments.
+const (
+	ReliabilityLookbackDays = 180
+	ReliabilityMinSample    = 3
+)
+
+// Reliability is how often a player turned up for what they committed to.
+// Only games and bookings at venues that check players in are counted, so
+// venues that never scan don't turn everyone into a no-show.
+type Reliability struct {
+	UserID            int64 `json:"user_id"`
+	GamesJoined       int   `json:"games_joined"`
+	GamesAttended     int   `json:"games_attended"`
+	BookingsHonored   int   `json:"bookings_honored"`
+	BookingsAbandoned int   `json:"bookings_abandoned"`
+	// Score is the share of commitments attended, 0-100; nil until there
+	// are enough to go on.
+	Score *int `json:"score"`
+}
+
+func newReliability(userID int64, joined, attended, honored, abandoned int) Reliability {
+	rel := Reliability{
+		UserID:            userID,
+		GamesJoined:       joined,
+		GamesAttended:     attended,
+		BookingsHonored:   honored,
+		BookingsAbandoned: abandoned,
+	}
+	if total := joined + honored + abandoned; total >= ReliabilityMinSample {
+		score := int(math.Round(100 * float64(attended+honored) / float64(total)))
+		rel.Score = &score
+	}
+	return rel
+}
+
+// Meets reports whether the player's score is at least min. Players without
+// a score yet get the benefit of the doubt.
+func (rel Reliability) Meets(min int) bool {
+	return rel.Score == nil || *rel.Score >= min
+}
+
+func (r *Repository) Reliability(ctx context.Context, userIDs []int64) (map[int64]Reliability, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		WITH scanning AS (
+			SELECT venue_id, MIN(checked_in_at) AS since
+			FROM check_ins
+			GROUP BY venue_id
+		),
+		played AS (
+			SELECT gp.user_id, COUNT(*) AS joined, COUNT(ci.id) AS attended
+			FROM game_players gp
+			JOIN games g ON g.id = gp.game_id
+			JOIN scanning s ON s.venue_id = g.venue_id AND s.since <= g.end_time
+			LEFT JOIN check_ins ci ON ci.game_id = g.id AND ci.user_id = gp.user_id
+			WHERE gp.user_id = ANY($1)
+			  AND g.status <> 'cancelled'
+			  AND g.end_time < NOW()
+			  AND g.end_time >= NOW() - make_interval(days => $2)
+			GROUP BY gp.user_id
+		),
+		booked AS (
+			SELECT b.user_id,
+			       COUNT(ci.id) AS honored,
+			       COUNT(*) - COUNT(ci.id) AS abandoned
+			FROM bookings b
+			JOIN scanning s ON s.venue_id = b.venue_id AND s.since <= b.end_time
+			LEFT JOIN check_ins ci ON ci.booking_id = b.id AND ci.user_id = b.user_id
+			WHERE b.user_id = ANY($1)
+			  AND b.status IN ('confirmed', 'done')
+			  AND b.end_time < NOW()
+			  AND b.end_time >= NOW() - make_interval(days => $2)
+			GROUP BY b.user_id
+		)
+		SELECT u.id,
+		       COALESCE(p.joined, 0), COALESCE(p.attended, 0),
+		       COALESCE(b.honored, 0), COALESCE(b.abandoned, 0)
+		FROM unnest($1::bigint[]) AS u(id)
+		LEFT JOIN played p ON p.user_id = u.id
+		LEFT JOIN booked b ON b.user_id = u.id
+	`, userIDs, ReliabilityLookbackDays)
+	if err != nil {
+		return nil, fmt.Errorf("get reliability: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]Reliability, len(userIDs))
+	for rows.Next() {
+		var userID int64
+		var joined, attended, honored, abandoned int
+		if err := rows.Scan(&userID, &joined, &attended, &honored, &abandoned); err != nil {
+			return nil, fmt.Errorf("scan reliability: %w", err)
+		}
+		out[userID] = newReliability(userID, joined, attended, honored, abandoned)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get reliability: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,17 @@
+package checkins
+
+import "testing"
+
+func TestReliabilityScore(t *testing.T) {
+	if rel := newReliability(1, 1, 1, 1, 0); rel.Score != nil || !rel.Meets(100) {
+		t.Errorf("two commitments: score = %v, want none", rel.Score)
+	}
+
+	rel := newReliability(1, 4, 3, 1, 2) // 4 of 7 kept
+	if rel.Score == nil || *rel.Score != 57 {
+		t.Fatalf("score = %v, want 57", rel.Score)
+	}
+	if !rel.Meets(57) || rel.Meets(58) {
+		t.Errorf("Meets around 57 is wrong")
+	}
+}
@@ -0,0 +1,47 @@
+package checkins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Record(ctx context.Context, c *CheckIn) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO check_ins (venue_id, booking_id, game_id, user_id, checked_in_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+		RETURNING id, checked_in_at
+	`, c.VenueID, c.BookingID, c.GameID, c.UserID, c.CheckedInBy).Scan(&c.ID, &c.CheckedInAt)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("record check-in: %w", err)
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT id, checked_in_by, checked_in_at
+		FROM check_ins
+		WHERE user_id = $1
+		  AND (booking_id = $2 OR game_id = $3)
+	`, c.UserID, c.BookingID, c.GameID).Scan(&c.ID, &c.CheckedInBy, &c.CheckedInAt)
+	if err != nil {
+		return false, fmt.Errorf("get check-in: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,59 @@
+package checkins
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Subject is who a check-in token admits to what.
+type Subject struct {
+	Kind   string
+	ID     int64 // booking or game
+	UserID int64
+}
+
+var kindCodes = map[string]string{KindBooking: "b", KindGame: "g"}
+
+// Sign returns the token shown as the player's QR code. It never expires;
+// the check-in window is enforced when it is scanned.
+func Sign(secret []byte, s Subject) string {
+	body := fmt.Sprintf("%s.%d.%d", kindCodes[s.Kind], s.ID, s.UserID)
+	return body + "." + signature(secret, body)
+}
+
+// Parse checks a scanned token and returns what it admits to.
+func Parse(secret []byte, token string) (Subject, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 4 {
+		return Subject{}, ErrInvalidToken
+	}
+	body := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(signature(secret, body))) {
+		return Subject{}, ErrInvalidToken
+	}
+
+	var s Subject
+	for kind, code := range kindCodes {
+		if parts[0] == code {
+			s.Kind = kind
+		}
+	}
+	id, err1 := strconv.ParseInt(parts[1], 10, 64)
+	userID, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if s.Kind == "" || err1 != nil || err2 != nil {
+		return Subject{}, ErrInvalidToken
+	}
+	s.ID, s.UserID = id, userID
+	return s, nil
+}
+
+// signature is truncated to 128 bits to keep the QR code small.
+func signature(secret []byte, body string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("checkin:" + body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
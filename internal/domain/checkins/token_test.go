@@ -0,0 +1,36 @@
+package checkins
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	secret := []byte("secret")
+	for _, s := range []Subject{
+		{Kind: KindBooking, ID: 42, UserID: 7},
+		{Kind: KindGame, ID: 9, UserID: 1234},
+	} {
+		got, err := Parse(secret, Sign(secret, s))
+		if err != nil || got != s {
+			t.Errorf("Parse(Sign(%+v)) = %+v, %v", s, got, err)
+		}
+	}
+}
+
+func TestParseRejectsTampering(t *testing.T) {
+	secret := []byte("secret")
+	token := Sign(secret, Subject{Kind: KindBooking, ID: 42, UserID: 7})
+
+	for name, bad := range map[string]string{
+		"other user":   strings.Replace(token, ".7.", ".8.", 1),
+		"other kind":   "g" + token[1:],
+		"other secret": Sign([]byte("other"), Subject{Kind: KindBooking, ID: 42, UserID: 7}),
+		"garbage":      "not-a-token",
+	} {
+		if _, err := Parse(secret, bad); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: Parse() = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package checkins
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// What a check-in token admits its holder to.
+const (
+	KindBooking = "booking"
+	KindGame    = "game"
+)
+
+// Check-in opens this long before the start and closes at the end.
+const EarlyWindow = time.Hour
+
+var (
+	ErrInvalidToken = errors.New("invalid check-in code")
+	ErrWrongVenue   = errors.New("this check-in code is for another venue")
+	ErrNotCheckable = errors.New("this booking or game can't be checked in")
+	ErrTooEarly     = errors.New("check-in opens an hour before the start")
+	ErrTooLate      = errors.New("check-in closed when the booking or game ended")
+)
+
+// CheckIn is one player's recorded attendance.
+type CheckIn struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	VenueID     int64     `json:"venue_id"`
+	BookingID   *int64    `json:"-"`
+	GameID      *int64    `json:"game_id,omitempty"`
+	UserID      int64     `json:"user_id"`
+	CheckedInBy *int64    `json:"checked_in_by,omitempty"`
+	CheckedInAt time.Time `json:"checked_in_at"`
+}
+
+// CheckWindow reports whether something running from start to end can be
+// checked in at now.
+func CheckWindow(start, end, now time.Time) error {
+	if now.Before(start.Add(-EarlyWindow)) {
+		return ErrTooEarly
+	}
+	if !now.Before(end) {
+		return ErrTooLate
+	}
+	return nil
+}
+
+type Store interface {
+	// Record saves the check-in. Scanning the same code twice keeps the
+	// first one and reports created as false.
+	Record(ctx context.Context, c *CheckIn) (created bool, err error)
+	// Reliability returns a score for each of the users, including those
+	// with no history.
+	Reliability(ctx context.Context, userIDs []int64) (map[int64]Reliability, error)
+}
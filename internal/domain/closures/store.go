@@ -0,0 +1,194 @@
+package closures
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/games"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const closureColumns = `id, venue_id, start_time, end_time, reason, note, created_by, created_at`
+
+func scanClosure(row pgx.Row, c *Closure) error {
+	return row.Scan(&c.ID, &c.VenueID, &c.StartTime, &c.EndTime, &c.Reason, &c.Note, &c.CreatedBy, &c.CreatedAt)
+}
+
+func (r *Repository) Create(ctx context.Context, c *Closure) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res := &Result{Bookings: []CanceledBooking{}, Games: []CanceledGame{}}
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		err := scanClosure(tx.QueryRow(ctx, `
+			INSERT INTO venue_closures (venue_id, start_time, end_time, reason, note, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING `+closureColumns,
+			c.VenueID, c.StartTime, c.EndTime, c.Reason, c.Note, c.CreatedBy), c)
+		if err != nil {
+			return fmt.Errorf("create closure: %w", err)
+		}
+		res.Closure = *c
+
+		// Bookings that already started are left alone; the closure only
+		// reaches into what is still to come.
+		var actorID int64
+		if c.CreatedBy != nil {
+			actorID = *c.CreatedBy
+		}
+		if err := bookings.Attribute(ctx, tx, actorID, bookings.SourceClosure); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `
+			WITH hit AS (
+				SELECT id, status::text AS previous_status
+				FROM bookings
+				WHERE venue_id = $1
+				  AND status IN ('pending', 'confirmed')
+				  AND start_time < $3
+				  AND end_time > $2
+				  AND start_time > NOW()
+				FOR UPDATE
+			)
+			UPDATE bookings b
+			SET status = 'canceled', updated_at = NOW()
+			FROM hit
+			WHERE b.id = hit.id
+			RETURNING b.id, b.user_id, b.start_time, hit.previous_status
+		`, c.VenueID, c.StartTime, c.EndTime)
+		if err != nil {
+			return fmt.Errorf("cancel bookings: %w", err)
+		}
+		for rows.Next() {
+			var b CanceledBooking
+			if err := rows.Scan(&b.ID, &b.UserID, &b.StartTime, &b.Status); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan canceled booking: %w", err)
+			}
+			res.Bookings = append(res.Bookings, b)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+
+		rows, err = tx.Query(ctx, `
+			UPDATE games
+			SET status = 'cancelled',
+			    cancel_reason = $4,
+			    booking_status = 'cancelled'
+			WHERE venue_id = $1
+			  AND status = 'active'
+			  AND start_time < $3
+			  AND end_time > $2
+			  AND start_time > NOW()
+			RETURNING id, COALESCE(sport_type::text, ''), start_time
+		`, c.VenueID, c.StartTime, c.EndTime, games.CancelReasonVenueClosed)
+		if err != nil {
+			return fmt.Errorf("cancel games: %w", err)
+		}
+		ids := []int64{}
+		for rows.Next() {
+			var g CanceledGame
+			if err := rows.Scan(&g.ID, &g.SportType, &g.StartTime); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan canceled game: %w", err)
+			}
+			res.Games = append(res.Games, g)
+			ids = append(ids, g.ID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		rows, err = tx.Query(ctx, `
+			UPDATE game_join_requests
+			SET status = 'rejected'
+			WHERE game_id = ANY($1)
+			  AND status = 'pending'
+			RETURNING game_id, user_id
+		`, ids)
+		if err != nil {
+			return fmt.Errorf("close join requests: %w", err)
+		}
+		defer rows.Close()
+
+		requesters := map[int64][]int64{}
+		for rows.Next() {
+			var gameID, userID int64
+			if err := rows.Scan(&gameID, &userID); err != nil {
+				return fmt.Errorf("scan closed request: %w", err)
+			}
+			requesters[gameID] = append(requesters[gameID], userID)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+		for i := range res.Games {
+			res.Games[i].RejectedRequesters = requesters[res.Games[i].ID]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *Repository) ListByVenue(ctx context.Context, venueID int64) ([]Closure, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+closureColumns+`
+		FROM venue_closures
+		WHERE venue_id = $1 AND end_time > NOW()
+		ORDER BY start_time
+	`, venueID)
+	if err != nil {
+		return nil, fmt.Errorf("list closures: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Closure{}
+	for rows.Next() {
+		var c Closure
+		if err := scanClosure(rows, &c); err != nil {
+			return nil, fmt.Errorf("scan closure: %w", err)
+		}
+		list = append(list, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, venueID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM venue_closures WHERE id = $1 AND venue_id = $2`, id, venueID)
+	if err != nil {
+		return fmt.Errorf("delete closure: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
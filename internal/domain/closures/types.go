@@ -0,0 +1,66 @@
+package closures
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 15
+
+// Reasons an owner can give for closing.
+const (
+	ReasonFlood    = "flood"
+	ReasonPowerCut = "power_cut"
+	ReasonWeather  = "weather"
+	ReasonOther    = "other"
+)
+
+var ErrNotFound = errors.New("closure not found")
+
+// Closure blacks out a venue between StartTime and EndTime.
+type Closure struct {
+	ID        int64     `json:"id"`
+	VenueID   int64     `json:"venue_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    string    `json:"reason"`
+	Note      *string   `json:"note,omitempty"`
+	CreatedBy *int64    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CanceledBooking is a booking a closure called off.
+type CanceledBooking struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	StartTime time.Time `json:"start_time"`
+	Status    string    `json:"previous_status"` // pending or confirmed
+}
+
+// CanceledGame is a game a closure called off. RejectedRequesters are the
+// users whose pending join requests were closed with it.
+type CanceledGame struct {
+	ID                 int64     `json:"id"`
+	SportType          string    `json:"sport_type"`
+	StartTime          time.Time `json:"start_time"`
+	RejectedRequesters []int64   `json:"-"`
+}
+
+// Result is what declaring a closure changed.
+type Result struct {
+	Closure  Closure           `json:"closure"`
+	Bookings []CanceledBooking `json:"canceled_bookings"`
+	Games    []CanceledGame    `json:"canceled_games"`
+}
+
+type Store interface {
+	// Create records the closure and, in the same transaction, cancels the
+	// venue's pending and confirmed bookings and active games that overlap
+	// it.
+	Create(ctx context.Context, c *Closure) (*Result, error)
+	// ListByVenue returns closures that haven't ended yet, soonest first.
+	ListByVenue(ctx context.Context, venueID int64) ([]Closure, error)
+	// Delete lifts a closure. Whatever it canceled stays canceled.
+	Delete(ctx context.Context, venueID, id int64) error
+}
@@ -0,0 +1,120 @@
+package commissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const rateSelect = `
+	SELECT c.id, c.venue_id, v.name, c.percent, c.effective_from, c.note, c.created_by, c.created_at
+	FROM commission_rates c
+	LEFT JOIN venues v ON v.id = c.venue_id`
+
+func rateScanArgs(rt *Rate) []any {
+	return []any{&rt.ID, &rt.VenueID, &rt.VenueName, &rt.Percent, &rt.EffectiveFrom, &rt.Note, &rt.CreatedBy, &rt.CreatedAt}
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Rate, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH filtered AS (` + rateSelect + `
+			WHERE ($1::bigint IS NULL OR c.venue_id = $1)
+			  AND (NOT $2 OR c.venue_id IS NULL)
+		)
+		SELECT filtered.*, COUNT(*) OVER() AS total_count
+		FROM filtered
+		ORDER BY venue_id NULLS FIRST, effective_from DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, f.VenueID, f.DefaultsOnly, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list commission rates: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Rate{}
+	var total int
+	for rows.Next() {
+		var rt Rate
+		if err := rows.Scan(append(rateScanArgs(&rt), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan commission rate: %w", err)
+		}
+		list = append(list, rt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) Create(ctx context.Context, rt *Rate) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO commission_rates (venue_id, percent, effective_from, note, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, rt.VenueID, rt.Percent, rt.EffectiveFrom, rt.Note, rt.CreatedBy).Scan(&rt.ID, &rt.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("create commission rate: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) (*Rate, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var rt Rate
+	if err := r.db.QueryRow(ctx, rateSelect+` WHERE c.id = $1`, id).Scan(rateScanArgs(&rt)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get commission rate: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM commission_rates WHERE id = $1 AND effective_from > NOW()`, id)
+	if err != nil {
+		return nil, fmt.Errorf("delete commission rate: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrAlreadyEffective
+	}
+	return &rt, nil
+}
+
+func (r *Repository) Effective(ctx context.Context, venueID int64, at time.Time) (*Effective, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	e := Effective{VenueID: venueID}
+	err := r.db.QueryRow(ctx, `
+		SELECT commission_percent_at($1, $2),
+		       EXISTS (SELECT 1 FROM commission_rates WHERE venue_id = $1 AND effective_from <= $2)
+	`, venueID, at).Scan(&e.Percent, &e.Override)
+	if err != nil {
+		return nil, fmt.Errorf("get effective commission rate: %w", err)
+	}
+	return &e, nil
+}
@@ -0,0 +1,53 @@
+package commissions
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+var (
+	ErrNotFound         = errors.New("commission rate not found")
+	ErrAlreadyEffective = errors.New("commission rate is already in effect")
+	ErrDuplicate        = errors.New("a commission rate already starts at that time")
+)
+
+// Rate is a commission percent in force from EffectiveFrom until the next
+// rate for the same scope. VenueID is nil for the platform default.
+type Rate struct {
+	ID            int64     `json:"id"`
+	VenueID       *int64    `json:"venue_id,omitempty"`
+	VenueName     *string   `json:"venue_name,omitempty"`
+	Percent       int       `json:"percent"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	Note          *string   `json:"note,omitempty"`
+	CreatedBy     *int64    `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Effective is the rate a venue pays at a point in time and where it comes
+// from.
+type Effective struct {
+	VenueID int64 `json:"venue_id"`
+	Percent int   `json:"percent"`
+	// Override is true when the venue has its own rate rather than the
+	// platform default.
+	Override bool `json:"override"`
+}
+
+type Filter struct {
+	// VenueID limits the list to one venue's overrides.
+	VenueID *int64
+	// DefaultsOnly lists only platform defaults.
+	DefaultsOnly bool
+}
+
+type Store interface {
+	List(ctx context.Context, f Filter, limit, offset int) ([]Rate, int, error)
+	Create(ctx context.Context, rate *Rate) error
+	// Delete removes a rate that has not taken effect yet.
+	Delete(ctx context.Context, id int64) (*Rate, error)
+	Effective(ctx context.Context, venueID int64, at time.Time) (*Effective, error)
+}
@@ -0,0 +1,115 @@
+package demosandboxes
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// demoEmailDomain can't receive mail, so nothing sent to a demo player
+// leaves the building.
+const demoEmailDomain = "demo.khel.invalid"
+
+// demoPlayers are the fake customers every sandbox books under. They share
+// the pool across sandboxes and can't sign in.
+var demoPlayers = []struct{ first, last, skill string }{
+	{"Aayush", "Shrestha", "advanced"},
+	{"Binita", "Gurung", "intermediate"},
+	{"Chiran", "Thapa", "beginner"},
+	{"Deepa", "Rai", "intermediate"},
+	{"Ekraj", "Magar", "advanced"},
+	{"Furba", "Sherpa", "intermediate"},
+	{"Gita", "Tamang", "beginner"},
+	{"Hemant", "Karki", "advanced"},
+	{"Ishan", "Maharjan", "intermediate"},
+	{"Jyoti", "Basnet", "beginner"},
+	{"Kushal", "Adhikari", "intermediate"},
+	{"Laxmi", "Poudel", "advanced"},
+}
+
+// demoSlots is the sandbox's weekly pricing; Saturday costs a fifth more.
+var demoSlots = []struct {
+	start, end string
+	from, to   int
+	price      int
+}{
+	{"06:00", "10:00", 6, 10, 1200},
+	{"10:00", "16:00", 10, 16, 1000},
+	{"16:00", "21:00", 16, 21, 1500},
+}
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+func slotPrice(i int, day time.Weekday) int {
+	p := demoSlots[i].price
+	if day == time.Saturday {
+		p += p / 5
+	}
+	return p
+}
+
+func hourPrice(hour int, day time.Weekday) int {
+	for i, s := range demoSlots {
+		if hour >= s.from && hour < s.to {
+			return slotPrice(i, day)
+		}
+	}
+	return 0
+}
+
+type plannedBooking struct {
+	player        int
+	start, end    time.Time
+	price         int
+	status        string
+	paymentMethod *string
+}
+
+// planDay lays out one day's bookings. The hours, players and outcomes come
+// from a generator seeded with the sandbox and the date, so the same day
+// looks the same every night; only what has happened by now differs.
+func planDay(sandboxID int64, day, now time.Time, perDay int) []plannedBooking {
+	first, last := demoSlots[0].from, demoSlots[len(demoSlots)-1].to
+	perDay = min(perDay, last-first)
+
+	seed := uint64(day.Year())*1000 + uint64(day.YearDay())
+	rnd := rand.New(rand.NewPCG(uint64(sandboxID), seed))
+
+	out := make([]plannedBooking, 0, perDay)
+	for _, h := range rnd.Perm(last - first)[:perDay] {
+		start := time.Date(day.Year(), day.Month(), day.Day(), first+h, 0, 0, 0, day.Location())
+		b := plannedBooking{
+			player: rnd.IntN(len(demoPlayers)),
+			start:  start,
+			end:    start.Add(time.Hour),
+			price:  hourPrice(first+h, day.Weekday()),
+		}
+
+		// Draw every number whether it is used or not, so a booking's
+		// outcome doesn't shift the ones after it.
+		outcome, method := rnd.IntN(10), rnd.IntN(10)
+		switch {
+		case !b.end.After(now):
+			switch outcome {
+			case 0:
+				b.status = "canceled"
+			case 1:
+				b.status = "rejected"
+			default:
+				b.status = "done"
+				pm := "cash"
+				if method >= 8 {
+					pm = "khalti"
+				} else if method >= 6 {
+					pm = "esewa"
+				}
+				b.paymentMethod = &pm
+			}
+		case b.start.After(now) && outcome < 3:
+			b.status = "pending"
+		default:
+			b.status = "confirmed"
+		}
+		out = append(out, b)
+	}
+	return out
+}
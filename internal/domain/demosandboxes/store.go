@@ -0,0 +1,396 @@
+package demosandboxes
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/infra/dbx"
+	mrand "math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const sandboxSelect = `
+	SELECT d.id, d.user_id, u.email, d.venue_id, v.name,
+	       d.days_back, d.days_ahead, d.bookings_per_day, d.games_per_week,
+	       d.created_by, d.created_at, d.regenerated_at
+	FROM demo_sandboxes d
+	JOIN users u ON u.id = d.user_id
+	JOIN venues v ON v.id = d.venue_id`
+
+func scanSandbox(row pgx.Row, s *Sandbox) error {
+	return row.Scan(
+		&s.ID, &s.UserID, &s.UserEmail, &s.VenueID, &s.VenueName,
+		&s.DaysBack, &s.DaysAhead, &s.BookingsPerDay, &s.GamesPerWeek,
+		&s.CreatedBy, &s.CreatedAt, &s.RegeneratedAt,
+	)
+}
+
+func getSandbox(ctx context.Context, q dbx.Querier, id int64, lock bool) (*Sandbox, error) {
+	query := sandboxSelect + ` WHERE d.id = $1`
+	if lock {
+		query += ` FOR UPDATE OF d`
+	}
+	var s Sandbox
+	if err := scanSandbox(q.QueryRow(ctx, query, id), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get demo sandbox: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *Repository) Create(ctx context.Context, userID, createdBy int64, s Settings) (*Sandbox, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var sb *Sandbox
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var firstName, phone string
+		err := tx.QueryRow(ctx, `SELECT first_name, phone FROM users WHERE id = $1`, userID).Scan(&firstName, &phone)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("load user: %w", err)
+		}
+
+		var venueID, facilityID int64
+		err = tx.QueryRow(ctx, `
+			INSERT INTO venues (owner_id, name, address, location, description, amenities,
+			                    open_time, sport, phone_number, status, is_demo)
+			VALUES ($1, $2, 'Demo Street, Kathmandu', ST_SetSRID(ST_MakePoint(85.3240, 27.7172), 4326)::geography,
+			        'Sandbox venue for demos. Its bookings and games are made up and regenerated every night.',
+			        ARRAY['parking', 'changing_room', 'floodlights'], '06:00 - 21:00', 'futsal', $3, 'hold', TRUE)
+			RETURNING id
+		`, userID, firstName+"'s Demo Arena", phone).Scan(&venueID)
+		if err != nil {
+			return fmt.Errorf("create demo venue: %w", err)
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO facilities (venue_id, name, sport, is_default)
+			VALUES ($1, 'Main Court', 'futsal', TRUE)
+			RETURNING id
+		`, venueID).Scan(&facilityID)
+		if err != nil {
+			return fmt.Errorf("create demo facility: %w", err)
+		}
+		for d, day := range weekdays {
+			for i, slot := range demoSlots {
+				_, err := tx.Exec(ctx, `
+					INSERT INTO venue_pricing (venue_id, facility_id, day_of_week, start_time, end_time, price)
+					VALUES ($1, $2, $3, $4, $5, $6)
+				`, venueID, facilityID, day, slot.start, slot.end, slotPrice(i, time.Weekday(d)))
+				if err != nil {
+					return fmt.Errorf("create demo pricing: %w", err)
+				}
+			}
+		}
+
+		// The owner screens are what gets demoed.
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_roles (user_id, role_id)
+			SELECT $1, id FROM roles WHERE name = 'owner'
+			ON CONFLICT DO NOTHING
+		`, userID); err != nil {
+			return fmt.Errorf("grant owner role: %w", err)
+		}
+
+		var id int64
+		err = tx.QueryRow(ctx, `
+			INSERT INTO demo_sandboxes (user_id, venue_id, days_back, days_ahead, bookings_per_day, games_per_week, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`, userID, venueID, s.DaysBack, s.DaysAhead, s.BookingsPerDay, s.GamesPerWeek, createdBy).Scan(&id)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "demo_sandboxes_user_id_key" {
+				return ErrAlreadyHasDemo
+			}
+			return fmt.Errorf("create demo sandbox: %w", err)
+		}
+
+		if sb, err = getSandbox(ctx, tx, id, false); err != nil {
+			return err
+		}
+		_, err = regenerate(ctx, tx, sb, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (*Sandbox, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return getSandbox(ctx, r.db, id, false)
+}
+
+func (r *Repository) List(ctx context.Context) ([]Sandbox, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, sandboxSelect+` ORDER BY d.created_at DESC, d.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list demo sandboxes: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Sandbox{}
+	for rows.Next() {
+		var s Sandbox
+		if err := scanSandbox(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan demo sandbox: %w", err)
+		}
+		list = append(list, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) UpdateSettings(ctx context.Context, id int64, s Settings) (*Sandbox, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	ct, err := r.db.Exec(ctx, `
+		UPDATE demo_sandboxes
+		SET days_back = $2, days_ahead = $3, bookings_per_day = $4, games_per_week = $5
+		WHERE id = $1
+	`, id, s.DaysBack, s.DaysAhead, s.BookingsPerDay, s.GamesPerWeek)
+	if err != nil {
+		return nil, fmt.Errorf("update demo sandbox: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+	return getSandbox(ctx, r.db, id, false)
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// The sandbox row, bookings and games go with the venue.
+	ct, err := r.db.Exec(ctx, `
+		DELETE FROM venues
+		WHERE is_demo AND id = (SELECT venue_id FROM demo_sandboxes WHERE id = $1)
+	`, id)
+	if err != nil {
+		return fmt.Errorf("delete demo sandbox: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) Regenerate(ctx context.Context, id int64, now time.Time) (*Regenerated, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var res *Regenerated
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		sb, err := getSandbox(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		res, err = regenerate(ctx, tx, sb, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *Repository) ListStale(ctx context.Context, before time.Time) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id FROM demo_sandboxes
+		WHERE regenerated_at IS NULL OR regenerated_at < $1
+		ORDER BY id
+	`, before)
+	if err != nil {
+		return nil, fmt.Errorf("list stale demo sandboxes: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan demo sandbox id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return ids, nil
+}
+
+// regenerate replaces everything on the sandbox venue with a fresh set of
+// bookings from DaysBack days ago to DaysAhead days on, and private games
+// on some of them.
+func regenerate(ctx context.Context, tx pgx.Tx, sb *Sandbox, now time.Time) (*Regenerated, error) {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	players, err := ensurePlayers(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Day closes go first: the closed-day trigger would refuse the new
+	// bookings. Deletes don't fire it.
+	for _, q := range []string{
+		`DELETE FROM venue_day_closes WHERE venue_id = $1`,
+		`DELETE FROM games WHERE venue_id = $1`,
+		`DELETE FROM bookings WHERE venue_id = $1`,
+	} {
+		if _, err := tx.Exec(ctx, q, sb.VenueID); err != nil {
+			return nil, fmt.Errorf("clear demo venue: %w", err)
+		}
+	}
+
+	var facilityID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM facilities WHERE venue_id = $1 AND is_default`, sb.VenueID).Scan(&facilityID)
+	if err != nil {
+		return nil, fmt.Errorf("load demo facility: %w", err)
+	}
+
+	type gameSlot struct {
+		bookingID int64
+		b         plannedBooking
+	}
+	var (
+		res   Regenerated
+		slots []gameSlot
+	)
+	for d := -sb.DaysBack; d <= sb.DaysAhead; d++ {
+		for _, b := range planDay(sb.ID, today.AddDate(0, 0, d), now, sb.BookingsPerDay) {
+			var paidAmount *int
+			var paidAt *time.Time
+			if b.status == "done" {
+				paidAmount, paidAt = &b.price, &b.end
+			}
+			var id int64
+			err := tx.QueryRow(ctx, `
+				INSERT INTO bookings (venue_id, facility_id, user_id, start_time, end_time, total_price,
+				                      status, payment_method, paid_amount, final_amount, paid_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7::booking_status, $8, $9, $9, $10)
+				RETURNING id
+			`, sb.VenueID, facilityID, players[b.player], b.start, b.end, b.price,
+				b.status, b.paymentMethod, paidAmount, paidAt).Scan(&id)
+			if err != nil {
+				return nil, fmt.Errorf("insert demo booking: %w", err)
+			}
+			res.Bookings++
+			if b.status == "confirmed" || b.status == "done" {
+				slots = append(slots, gameSlot{id, b})
+			}
+		}
+	}
+
+	days := sb.DaysBack + sb.DaysAhead + 1
+	want := min((sb.GamesPerWeek*days+6)/7, len(slots))
+	rnd := mrand.New(mrand.NewPCG(uint64(sb.ID), uint64(today.Unix())))
+	levels := []string{"beginner", "intermediate", "advanced"}
+	for _, i := range rnd.Perm(len(slots))[:want] {
+		s := slots[i]
+		status := "active"
+		if !s.b.end.After(now) {
+			status = "completed"
+		}
+		var gameID int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO games (sport_type, price, format, venue_id, admin_id, max_players, game_level,
+			                   start_time, end_time, visibility, instruction, status, booking_status,
+			                   is_booked, booking_id)
+			VALUES ('futsal', $1, '5v5', $2, $3, 10, $4, $5, $6, 'private',
+			        'Bring both a light and a dark shirt.', $7, 'booked', TRUE, $8)
+			RETURNING id
+		`, s.b.price/10, sb.VenueID, players[s.b.player], levels[rnd.IntN(len(levels))],
+			s.b.start, s.b.end, status, s.bookingID).Scan(&gameID)
+		if err != nil {
+			return nil, fmt.Errorf("insert demo game: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO game_players (game_id, user_id, role) VALUES ($1, $2, 'admin')
+		`, gameID, players[s.b.player]); err != nil {
+			return nil, fmt.Errorf("add demo game admin: %w", err)
+		}
+		target, joined := 4+rnd.IntN(7), 1
+		for _, p := range rnd.Perm(len(players)) {
+			if joined >= target {
+				break
+			}
+			if p == s.b.player {
+				continue
+			}
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO game_players (game_id, user_id, role) VALUES ($1, $2, 'player')
+			`, gameID, players[p]); err != nil {
+				return nil, fmt.Errorf("add demo game player: %w", err)
+			}
+			joined++
+		}
+		res.Games++
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE demo_sandboxes SET regenerated_at = $2 WHERE id = $1`, sb.ID, now); err != nil {
+		return nil, fmt.Errorf("stamp demo sandbox: %w", err)
+	}
+	sb.RegeneratedAt = &now
+	return &res, nil
+}
+
+// ensurePlayers returns the demo players' ids, creating any that are
+// missing. Their password is random bytes, which no bcrypt check accepts,
+// and they are never activated.
+func ensurePlayers(ctx context.Context, tx pgx.Tx) ([]int64, error) {
+	ids := make([]int64, len(demoPlayers))
+	for i, p := range demoPlayers {
+		password := make([]byte, 32)
+		if _, err := rand.Read(password); err != nil {
+			return nil, err
+		}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO users (email, phone, password, first_name, last_name, skill_level, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, FALSE)
+			ON CONFLICT (email) DO UPDATE SET email = EXCLUDED.email
+			RETURNING id
+		`, fmt.Sprintf("player%02d@%s", i+1, demoEmailDomain), fmt.Sprintf("90000000%02d", i+1),
+			password, p.first, p.last, p.skill).Scan(&ids[i])
+		if err != nil {
+			return nil, fmt.Errorf("ensure demo player: %w", err)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,65 @@
+package demosandboxes
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 30
+
+var (
+	ErrNotFound       = errors.New("demo sandbox not found")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrAlreadyHasDemo = errors.New("that account already has a demo sandbox")
+)
+
+// Settings say how much fake data a sandbox gets around today.
+type Settings struct {
+	DaysBack       int `json:"days_back"`
+	DaysAhead      int `json:"days_ahead"`
+	BookingsPerDay int `json:"bookings_per_day"`
+	GamesPerWeek   int `json:"games_per_week"`
+}
+
+// DefaultSettings fill two weeks of history and a week of upcoming
+// bookings, enough for the earnings and forecast screens to show something.
+var DefaultSettings = Settings{DaysBack: 14, DaysAhead: 7, BookingsPerDay: 6, GamesPerWeek: 4}
+
+// Sandbox is a sales account's demo venue. The venue is on hold and marked
+// is_demo, so it never shows up for players or in settlements.
+type Sandbox struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	UserEmail string `json:"user_email"`
+	VenueID   int64  `json:"venue_id"`
+	VenueName string `json:"venue_name"`
+	Settings
+	CreatedBy     *int64     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RegeneratedAt *time.Time `json:"regenerated_at,omitempty"`
+}
+
+// Regenerated counts what a regeneration wrote.
+type Regenerated struct {
+	Bookings int `json:"bookings"`
+	Games    int `json:"games"`
+}
+
+type Store interface {
+	// Create sets up the demo venue for userID, makes them its owner and
+	// fills it with data.
+	Create(ctx context.Context, userID, createdBy int64, s Settings) (*Sandbox, error)
+	Get(ctx context.Context, id int64) (*Sandbox, error)
+	List(ctx context.Context) ([]Sandbox, error)
+	// UpdateSettings takes effect at the next regeneration.
+	UpdateSettings(ctx context.Context, id int64, s Settings) (*Sandbox, error)
+	// Delete removes the demo venue and everything on it.
+	Delete(ctx context.Context, id int64) error
+	// Regenerate throws away the venue's bookings, games and day closes and
+	// writes a fresh set around now. Days come out the same for the same
+	// sandbox and settings, so a demo can be rehearsed.
+	Regenerate(ctx context.Context, id int64, now time.Time) (*Regenerated, error)
+	// ListStale returns the sandboxes not regenerated since before.
+	ListStale(ctx context.Context, before time.Time) ([]int64, error)
+}
@@ -0,0 +1,284 @@
+package disputes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const disputeSelect = `
+	SELECT d.id, d.booking_id, d.opened_by, d.opener_role, d.reason, d.description, d.status,
+	       d.resolution, d.adjustment_amount, d.resolution_note, d.resolved_by, d.resolved_at,
+	       d.created_at, d.updated_at,
+	       b.venue_id, b.user_id, v.owner_id, b.start_time, b.end_time,
+	       COALESCE(b.final_amount, b.paid_amount, b.total_price, 0) AS booking_amount
+	FROM booking_disputes d
+	JOIN bookings b ON b.id = d.booking_id
+	JOIN venues v ON v.id = b.venue_id
+`
+
+func disputeScanArgs(d *Dispute) []any {
+	return []any{
+		&d.ID, &d.BookingID, &d.OpenedBy, &d.OpenerRole, &d.Reason, &d.Description, &d.Status,
+		&d.Resolution, &d.AdjustmentAmount, &d.ResolutionNote, &d.ResolvedBy, &d.ResolvedAt,
+		&d.CreatedAt, &d.UpdatedAt,
+		&d.VenueID, &d.UserID, &d.VenueOwnerID, &d.BookingStart, &d.BookingEnd,
+		&d.BookingAmount,
+	}
+}
+
+func (r *Repository) Open(ctx context.Context, in OpenInput) (*Dispute, error) {
+	var id int64
+
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var (
+			userID, venueID int64
+			status          string
+			start, end      time.Time
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT user_id, venue_id, status::text, start_time, end_time
+			FROM bookings
+			WHERE id = $1
+		`, in.BookingID).Scan(&userID, &venueID, &status, &start, &end)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrBookingNotFound
+			}
+			return fmt.Errorf("load booking: %w", err)
+		}
+
+		// The player may only dispute their own booking, the owner only
+		// bookings at their venue.
+		switch in.OpenerRole {
+		case RoleUser:
+			if userID != in.OpenedBy {
+				return ErrBookingNotFound
+			}
+		case RoleVenue:
+			if venueID != in.VenueID {
+				return ErrBookingNotFound
+			}
+		default:
+			return fmt.Errorf("unknown opener role %q", in.OpenerRole)
+		}
+
+		// Only bookings that were on and have started can go wrong.
+		now := time.Now()
+		if (status != "confirmed" && status != "done") || now.Before(start) || now.After(end.Add(Window)) {
+			return ErrBookingNotDisputable
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO booking_disputes (booking_id, opened_by, opener_role, reason, description)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, in.BookingID, in.OpenedBy, in.OpenerRole, in.Reason, in.Description).Scan(&id)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrDisputeExists
+			}
+			return fmt.Errorf("create dispute: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (*Dispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var d Dispute
+	if err := r.db.QueryRow(ctx, disputeSelect+` WHERE d.id = $1`, id).Scan(disputeScanArgs(&d)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDisputeNotFound
+		}
+		return nil, fmt.Errorf("get dispute: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, dispute_id, uploaded_by, url, note, created_at
+		FROM booking_dispute_evidence
+		WHERE dispute_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list dispute evidence: %w", err)
+	}
+	defer rows.Close()
+
+	d.Evidence = []Evidence{}
+	for rows.Next() {
+		var e Evidence
+		if err := rows.Scan(&e.ID, &e.DisputeID, &e.UploadedBy, &e.URL, &e.Note, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan dispute evidence: %w", err)
+		}
+		d.Evidence = append(d.Evidence, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return &d, nil
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Dispute, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH filtered AS (` + disputeSelect + `
+			WHERE ($1 = '' OR d.status = $1)
+			  AND ($2::bigint IS NULL OR b.venue_id = $2)
+			  AND ($3::bigint IS NULL OR b.user_id = $3)
+		)
+		SELECT filtered.*, COUNT(*) OVER() AS total_count
+		FROM filtered
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := r.db.Query(ctx, query, f.Status, f.VenueID, f.UserID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list disputes: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Dispute{}
+	var total int
+	for rows.Next() {
+		var d Dispute
+		if err := rows.Scan(append(disputeScanArgs(&d), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan dispute: %w", err)
+		}
+		list = append(list, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) AddEvidence(ctx context.Context, e *Evidence) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Evidence is only accepted while the dispute is open.
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO booking_dispute_evidence (dispute_id, uploaded_by, url, note)
+		SELECT id, $2, $3, $4
+		FROM booking_disputes
+		WHERE id = $1 AND status = 'open'
+		RETURNING id, created_at
+	`, e.DisputeID, e.UploadedBy, e.URL, e.Note).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAlreadyResolved
+		}
+		return fmt.Errorf("add dispute evidence: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Resolve(ctx context.Context, id int64, in ResolveInput) (*Dispute, error) {
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var (
+			status                     string
+			bookingID, userID, venueID int64
+			bookingAmount, listPrice   int
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT d.status, b.id, b.user_id, b.venue_id,
+			       COALESCE(b.final_amount, b.paid_amount, b.total_price, 0),
+			       b.total_price
+			FROM booking_disputes d
+			JOIN bookings b ON b.id = d.booking_id
+			WHERE d.id = $1
+			FOR UPDATE OF d, b
+		`, id).Scan(&status, &bookingID, &userID, &venueID, &bookingAmount, &listPrice)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrDisputeNotFound
+			}
+			return fmt.Errorf("lock dispute: %w", err)
+		}
+		if status != StatusOpen {
+			return ErrAlreadyResolved
+		}
+
+		switch in.Resolution {
+		case ResolutionRefundUser:
+			if in.Amount == nil || *in.Amount > bookingAmount {
+				return ErrInvalidAmount
+			}
+			if err := insertAdjustment(ctx, tx, bookingID, id, userID, venueID, "refund", *in.Amount); err != nil {
+				return err
+			}
+			// Net the refund out of what the venue earned on this booking.
+			if _, err := tx.Exec(ctx, `
+				UPDATE bookings
+				SET final_amount = GREATEST(COALESCE(final_amount, paid_amount, total_price, 0) - $2, 0),
+				    updated_at = NOW()
+				WHERE id = $1
+			`, bookingID, *in.Amount); err != nil {
+				return fmt.Errorf("apply refund: %w", err)
+			}
+
+		case ResolutionPenalizeUser:
+			if in.Amount == nil || *in.Amount > listPrice {
+				return ErrInvalidAmount
+			}
+			if err := insertAdjustment(ctx, tx, bookingID, id, userID, venueID, "penalty", *in.Amount); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE booking_disputes
+			SET status = 'resolved',
+			    resolution = $2,
+			    adjustment_amount = $3,
+			    resolution_note = $4,
+			    resolved_by = $5,
+			    resolved_at = NOW(),
+			    updated_at = NOW()
+			WHERE id = $1
+		`, id, in.Resolution, in.Amount, in.Note, in.ResolvedBy)
+		if err != nil {
+			return fmt.Errorf("resolve dispute: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func insertAdjustment(ctx context.Context, tx pgx.Tx, bookingID, disputeID, userID, venueID int64, kind string, amount int) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO booking_adjustments (booking_id, dispute_id, user_id, venue_id, kind, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, bookingID, disputeID, userID, venueID, kind, amount)
+	if err != nil {
+		return fmt.Errorf("insert booking adjustment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package disputes
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// Window is how long after a booking ends either side may still open a dispute.
+const Window = 7 * 24 * time.Hour
+
+var (
+	ErrDisputeNotFound      = errors.New("dispute not found")
+	ErrDisputeExists        = errors.New("booking already has an open dispute")
+	ErrBookingNotFound      = errors.New("booking not found")
+	ErrBookingNotDisputable = errors.New("booking cannot be disputed")
+	ErrAlreadyResolved      = errors.New("dispute is already resolved")
+	ErrInvalidAmount        = errors.New("amount exceeds the booking amount")
+)
+
+// Which side opened the dispute.
+const (
+	RoleUser  = "user"
+	RoleVenue = "venue"
+)
+
+// Reasons
+const (
+	ReasonVenueClosed = "venue_closed"
+	ReasonNoShow      = "no_show"
+	ReasonOther       = "other"
+)
+
+// Statuses
+const (
+	StatusOpen     = "open"
+	StatusResolved = "resolved"
+)
+
+// Resolutions and what they apply:
+//   - refund_user: the venue was at fault. A refund adjustment is recorded and
+//     netted out of the booking's final amount, so venue earnings drop by it.
+//   - penalize_user: the player was at fault. A penalty adjustment is
+//     recorded against the player.
+//   - dismissed: nothing changes.
+const (
+	ResolutionRefundUser   = "refund_user"
+	ResolutionPenalizeUser = "penalize_user"
+	ResolutionDismissed    = "dismissed"
+)
+
+type Dispute struct {
+	ID               int64      `json:"id"`
+	BookingID        int64      `json:"booking_id"`
+	OpenedBy         *int64     `json:"opened_by,omitempty"`
+	OpenerRole       string     `json:"opener_role"`
+	Reason           string     `json:"reason"`
+	Description      string     `json:"description"`
+	Status           string     `json:"status"`
+	Resolution       *string    `json:"resolution,omitempty"`
+	AdjustmentAmount *int       `json:"adjustment_amount,omitempty"`
+	ResolutionNote   *string    `json:"resolution_note,omitempty"`
+	ResolvedBy       *int64     `json:"resolved_by,omitempty"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Booking context
+	VenueID       int64     `json:"venue_id"`
+	UserID        int64     `json:"user_id"`
+	VenueOwnerID  int64     `json:"venue_owner_id"`
+	BookingStart  time.Time `json:"booking_start"`
+	BookingEnd    time.Time `json:"booking_end"`
+	BookingAmount int       `json:"booking_amount"`
+
+	Evidence []Evidence `json:"evidence,omitempty"`
+}
+
+// IsParty reports whether userID is the player or the venue owner.
+func (d *Dispute) IsParty(userID int64) bool {
+	return d.UserID == userID || d.VenueOwnerID == userID
+}
+
+type Evidence struct {
+	ID         int64     `json:"id"`
+	DisputeID  int64     `json:"dispute_id"`
+	UploadedBy *int64    `json:"uploaded_by,omitempty"`
+	URL        string    `json:"url"`
+	Note       *string   `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OpenInput struct {
+	BookingID   int64
+	OpenedBy    int64
+	OpenerRole  string
+	Reason      string
+	Description string
+	// VenueID must match the booking when the venue side opens the dispute.
+	VenueID int64
+}
+
+type ResolveInput struct {
+	Resolution string
+	// Amount is required for refund_user and penalize_user.
+	Amount     *int
+	Note       *string
+	ResolvedBy int64
+}
+
+// Filter narrows listings. Zero values are ignored.
+type Filter struct {
+	Status  string
+	VenueID *int64
+	UserID  *int64
+}
+
+type Store interface {
+	Open(ctx context.Context, in OpenInput) (*Dispute, error)
+	// Get returns the dispute with its evidence.
+	Get(ctx context.Context, id int64) (*Dispute, error)
+	List(ctx context.Context, f Filter, limit, offset int) ([]Dispute, int, error)
+	AddEvidence(ctx context.Context, e *Evidence) error
+	// Resolve closes the dispute and applies the resolution atomically.
+	Resolve(ctx context.Context, id int64, in ResolveInput) (*Dispute, error)
+}
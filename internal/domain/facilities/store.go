@@ -29,6 +29,8 @@ func scanFacility(row pgx.Row) (*Facility, error) {
 		&f.Sport,
 		&f.SurfaceType,
 		&f.Capacity,
+		&f.IsCovered,
+		&f.PriceAdjustmentPercent,
 		&f.ImageURLs,
 		&f.IsDefault,
 		&f.IsActive,
@@ -55,11 +57,13 @@ func (r *Repository) Create(ctx context.Context, input CreateFacilityInput) (*Fa
 			sport,
 			surface_type,
 			capacity,
+			is_covered,
+			price_adjustment_percent,
 			image_urls,
 			is_default,
 			is_active
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,TRUE)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,TRUE)
 		RETURNING
 			id,
 			venue_id,
@@ -68,6 +72,8 @@ func (r *Repository) Create(ctx context.Context, input CreateFacilityInput) (*Fa
 			sport,
 			surface_type,
 			capacity,
+			is_covered,
+			price_adjustment_percent,
 			image_urls,
 			is_default,
 			is_active,
@@ -84,6 +90,8 @@ func (r *Repository) Create(ctx context.Context, input CreateFacilityInput) (*Fa
 		input.Sport,
 		input.SurfaceType,
 		input.Capacity,
+		input.IsCovered,
+		input.PriceAdjustmentPercent,
 		input.ImageURLs,
 		input.IsDefault,
 	))
@@ -99,6 +107,8 @@ func (r *Repository) GetByID(ctx context.Context, venueID, facilityID int64) (*F
 			sport,
 			surface_type,
 			capacity,
+			is_covered,
+			price_adjustment_percent,
 			image_urls,
 			is_default,
 			is_active,
@@ -122,6 +132,8 @@ func (r *Repository) GetDefaultByVenueID(ctx context.Context, venueID int64) (*F
 			sport,
 			surface_type,
 			capacity,
+			is_covered,
+			price_adjustment_percent,
 			image_urls,
 			is_default,
 			is_active,
@@ -146,6 +158,8 @@ func (r *Repository) ListByVenueID(ctx context.Context, venueID int64) ([]Facili
 			sport,
 			surface_type,
 			capacity,
+			is_covered,
+			price_adjustment_percent,
 			image_urls,
 			is_default,
 			is_active,
@@ -175,6 +189,8 @@ func (r *Repository) ListByVenueID(ctx context.Context, venueID int64) ([]Facili
 			&f.Sport,
 			&f.SurfaceType,
 			&f.Capacity,
+			&f.IsCovered,
+			&f.PriceAdjustmentPercent,
 			&f.ImageURLs,
 			&f.IsDefault,
 			&f.IsActive,
@@ -229,6 +245,20 @@ func (r *Repository) Update(ctx context.Context, venueID, facilityID int64, inpu
 		arg++
 	}
 
+	if input.IsCovered != nil {
+		set = append(set, fmt.Sprintf("is_covered = $%d", arg))
+		args = append(args, *input.IsCovered)
+		arg++
+	}
+
+	if input.ClearPriceAdjustment {
+		set = append(set, "price_adjustment_percent = NULL")
+	} else if input.PriceAdjustmentPercent != nil {
+		set = append(set, fmt.Sprintf("price_adjustment_percent = $%d", arg))
+		args = append(args, *input.PriceAdjustmentPercent)
+		arg++
+	}
+
 	if input.ImageURLs != nil {
 		set = append(set, fmt.Sprintf("image_urls = $%d", arg))
 		args = append(args, input.ImageURLs)
@@ -266,6 +296,8 @@ func (r *Repository) Update(ctx context.Context, venueID, facilityID int64, inpu
 			sport,
 			surface_type,
 			capacity,
+			is_covered,
+			price_adjustment_percent,
 			image_urls,
 			is_default,
 			is_active,
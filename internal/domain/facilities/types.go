@@ -19,8 +19,14 @@ type Facility struct {
 
 	SurfaceType *string  `json:"surface_type,omitempty"`
 	Capacity    *int     `json:"capacity,omitempty"`
+	IsCovered   bool     `json:"is_covered"`
 	ImageURLs   []string `json:"image_urls,omitempty"`
 
+	// PriceAdjustmentPercent lets a court reuse the venue's default facility
+	// pricing, adjusted by this percent (-10 = 10% cheaper), on days it has
+	// no pricing of its own. Nil means the court only uses its own pricing.
+	PriceAdjustmentPercent *int `json:"price_adjustment_percent,omitempty"`
+
 	IsDefault bool      `json:"is_default"`
 	IsActive  bool      `json:"is_active"`
 	CreatedAt time.Time `json:"created_at"`
@@ -28,25 +34,32 @@ type Facility struct {
 }
 
 type CreateFacilityInput struct {
-	VenueID     int64
-	Name        string
-	Description *string
-	Sport       *string
-	SurfaceType *string
-	Capacity    *int
-	ImageURLs   []string
-	IsDefault   bool
+	VenueID                int64
+	Name                   string
+	Description            *string
+	Sport                  *string
+	SurfaceType            *string
+	Capacity               *int
+	IsCovered              bool
+	PriceAdjustmentPercent *int
+	ImageURLs              []string
+	IsDefault              bool
 }
 
 type UpdateFacilityInput struct {
-	Name        *string
-	Description *string
-	Sport       *string
-	SurfaceType *string
-	Capacity    *int
-	ImageURLs   []string
-	IsActive    *bool
-	IsDefault   *bool
+	Name                   *string
+	Description            *string
+	Sport                  *string
+	SurfaceType            *string
+	Capacity               *int
+	IsCovered              *bool
+	PriceAdjustmentPercent *int
+	// ClearPriceAdjustment stops the court from reusing the default
+	// facility's pricing.
+	ClearPriceAdjustment bool
+	ImageURLs            []string
+	IsActive             *bool
+	IsDefault            *bool
 }
 
 type Store interface {
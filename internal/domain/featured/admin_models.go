@@ -108,13 +108,13 @@ type UpdateItemRequest struct {
 // ---------- Admin List Responses (use your params.Pagination) ----------
 
 type CollectionFilters struct {
-	Search *string // matches key/title
-	Type   *string
-	Active *bool
+	Search *string `query:"search"` // matches key/title
+	Type   *string `query:"type"`
+	Active *bool   `query:"active"`
 }
 
 type ItemFilters struct {
-	Active *bool
+	Active *bool `query:"active"`
 }
 
 type CollectionList struct {
@@ -0,0 +1,152 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// List fans out on read: every source is a subquery over existing tables,
+// unioned and keyset-paginated on (occurred_at, item_key).
+func (r *Repository) List(ctx context.Context, userID int64, after *Cursor, limit int) (*Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	const query = `
+		WITH my_friends AS (
+			SELECT CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END AS friend_id
+			FROM friendships f
+			WHERE (f.requester_id = $1 OR f.addressee_id = $1)
+			  AND f.status = 'accepted'
+		),
+		items AS (
+			-- friends' completed games, one item per game
+			SELECT 'friend_game_completed' AS type,
+			       g.end_time AS occurred_at,
+			       'g' || g.id AS item_key,
+			       json_agg(json_build_object(
+			           'user_id', u.id,
+			           'first_name', u.first_name,
+			           'last_name', u.last_name,
+			           'profile_picture_url', u.profile_picture_url
+			       ) ORDER BY u.first_name) AS actors,
+			       json_build_object(
+			           'game_id', g.id, 'venue_id', g.venue_id, 'venue_name', v.name,
+			           'sport_type', g.sport_type, 'start_time', g.start_time, 'end_time', g.end_time
+			       ) AS game,
+			       NULL::json AS announcement
+			FROM games g
+			JOIN venues v ON v.id = g.venue_id
+			JOIN game_players gp ON gp.game_id = g.id
+			JOIN my_friends mf ON mf.friend_id = gp.user_id
+			JOIN users u ON u.id = gp.user_id
+			WHERE g.status = 'completed'
+			  AND g.visibility = 'public'
+			  AND g.end_time >= $4
+			GROUP BY g.id, v.id
+
+			UNION ALL
+
+			-- new public games created by organizers the user follows
+			SELECT 'organizer_game_created',
+			       g.created_at,
+			       'n' || g.id,
+			       json_build_array(json_build_object(
+			           'user_id', u.id,
+			           'first_name', u.first_name,
+			           'last_name', u.last_name,
+			           'profile_picture_url', u.profile_picture_url
+			       )),
+			       json_build_object(
+			           'game_id', g.id, 'venue_id', g.venue_id, 'venue_name', v.name,
+			           'sport_type', g.sport_type, 'start_time', g.start_time, 'end_time', g.end_time
+			       ),
+			       NULL::json
+			FROM followers fo
+			JOIN games g ON g.admin_id = fo.user_id
+			JOIN venues v ON v.id = g.venue_id
+			JOIN users u ON u.id = g.admin_id
+			WHERE fo.follower_id = $1
+			  AND g.status = 'active'
+			  AND g.visibility = 'public'
+			  AND g.start_time >= NOW()
+			  AND g.created_at >= $4
+			  AND NOT EXISTS (
+			      SELECT 1 FROM blocked_users b
+			      WHERE (b.blocker_id = $1 AND b.blocked_id = g.admin_id)
+			         OR (b.blocker_id = g.admin_id AND b.blocked_id = $1)
+			  )
+
+			UNION ALL
+
+			-- live announcements from venues the user favorited
+			SELECT 'venue_announcement',
+			       a.publish_at,
+			       'a' || a.id,
+			       '[]'::json,
+			       NULL::json,
+			       json_build_object(
+			           'id', a.id, 'venue_id', a.venue_id, 'venue_name', v.name,
+			           'title', a.title, 'body', a.body
+			       )
+			FROM favorite_venues fv
+			JOIN venue_announcements a ON a.venue_id = fv.venue_id
+			JOIN venues v ON v.id = a.venue_id
+			WHERE fv.user_id = $1
+			  AND a.publish_at <= NOW()
+			  AND a.publish_at >= $4
+			  AND (a.expires_at IS NULL OR a.expires_at > NOW())
+		)
+		SELECT type, occurred_at, item_key, actors, game, announcement
+		FROM items
+		WHERE $2::timestamptz IS NULL OR (occurred_at, item_key) < ($2::timestamptz, $3::text)
+		ORDER BY occurred_at DESC, item_key DESC
+		LIMIT $5
+	`
+
+	var (
+		afterTS  *time.Time
+		afterKey string
+	)
+	if after != nil {
+		afterTS = &after.OccurredAt
+		afterKey = after.Key
+	}
+
+	// fetch one extra row to know whether another page exists
+	rows, err := r.db.Query(ctx, query, userID, afterTS, afterKey, time.Now().Add(-Lookback), limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list feed: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(&it.Type, &it.OccurredAt, &it.key, &it.Actors, &it.Game, &it.Announcement); err != nil {
+			return nil, fmt.Errorf("scan feed item: %w", err)
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	page := &Page{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = Cursor{OccurredAt: last.OccurredAt, Key: last.key}.Encode()
+	}
+	return page, nil
+}
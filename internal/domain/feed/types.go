@@ -0,0 +1,104 @@
+package feed
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// Lookback bounds how far back the feed reaches. The feed is built on read,
+// so this keeps the union small for users with many friends.
+const Lookback = 30 * 24 * time.Hour
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Item types
+const (
+	TypeFriendGameCompleted = "friend_game_completed"
+	TypeOrganizerGameNew    = "organizer_game_created"
+	TypeVenueAnnouncement   = "venue_announcement"
+)
+
+type Actor struct {
+	UserID            int64   `json:"user_id"`
+	FirstName         string  `json:"first_name"`
+	LastName          string  `json:"last_name"`
+	ProfilePictureURL *string `json:"profile_picture_url,omitempty"`
+}
+
+type GameRef struct {
+	GameID    int64     `json:"game_id"`
+	VenueID   int64     `json:"venue_id"`
+	VenueName string    `json:"venue_name"`
+	SportType string    `json:"sport_type"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type AnnouncementRef struct {
+	ID        int64  `json:"id"`
+	VenueID   int64  `json:"venue_id"`
+	VenueName string `json:"venue_name"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+}
+
+// Item is one entry in the activity feed. Which of Game/Announcement is set
+// depends on Type; Actors is empty for venue announcements.
+type Item struct {
+	Type         string           `json:"type"`
+	OccurredAt   time.Time        `json:"occurred_at"`
+	Actors       []Actor          `json:"actors"`
+	Game         *GameRef         `json:"game,omitempty"`
+	Announcement *AnnouncementRef `json:"announcement,omitempty"`
+
+	key string
+}
+
+// Cursor is the position of the last item the client has seen.
+// Items are ordered by (OccurredAt DESC, Key DESC).
+type Cursor struct {
+	OccurredAt time.Time
+	Key        string
+}
+
+func (c Cursor) Encode() string {
+	raw := c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.Key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, key, ok := strings.Cut(string(b), "|")
+	if !ok || key == "" {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return &Cursor{OccurredAt: t, Key: key}, nil
+}
+
+// Page is one slice of the feed. NextCursor is empty on the last page.
+type Page struct {
+	Items      []Item `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type Store interface {
+	// List builds the user's feed on read from friendships, followers, games
+	// and announcements of favorited venues.
+	List(ctx context.Context, userID int64, after *Cursor, limit int) (*Page, error)
+}
@@ -3,7 +3,6 @@ package followers
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -31,8 +30,6 @@ func (r *Repository) Follow(ctx context.Context, followerID, userID int64) error
 
 	_, err := r.db.Exec(ctx, query, userID, followerID)
 	if err != nil {
-		log.Printf("Follow query failed for userID=%d, followerID=%d: %v", userID, followerID, err)
-
 		return fmt.Errorf("failed to follow user: %w", err)
 	}
 	return nil
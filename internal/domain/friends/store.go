@@ -0,0 +1,321 @@
+package friends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// SendRequest creates a pending request from requester to addressee.
+// If the addressee already asked the requester, that request is accepted instead.
+func (r *Repository) SendRequest(ctx context.Context, requesterID, addresseeID int64) (*FriendRequest, error) {
+	if requesterID == addresseeID {
+		return nil, ErrSelfRequest
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, addresseeID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check user: %w", err)
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	var fr FriendRequest
+	err := r.db.QueryRow(ctx, `
+		SELECT id, requester_id, addressee_id, status, created_at, responded_at
+		FROM friendships
+		WHERE LEAST(requester_id, addressee_id) = LEAST($1::bigint, $2::bigint)
+		  AND GREATEST(requester_id, addressee_id) = GREATEST($1::bigint, $2::bigint)
+	`, requesterID, addresseeID).Scan(&fr.ID, &fr.RequesterID, &fr.AddresseeID, &fr.Status, &fr.CreatedAt, &fr.RespondedAt)
+	switch {
+	case err == nil:
+		if fr.Status == StatusAccepted {
+			return nil, ErrAlreadyFriends
+		}
+		if fr.RequesterID == requesterID {
+			return nil, ErrRequestExists
+		}
+		// They already asked us: treat this as an accept.
+		if err := r.AcceptRequest(ctx, fr.ID, requesterID); err != nil {
+			return nil, err
+		}
+		fr.Status = StatusAccepted
+		return &fr, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("get friendship: %w", err)
+	}
+
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO friendships (requester_id, addressee_id)
+		VALUES ($1, $2)
+		RETURNING id, requester_id, addressee_id, status, created_at, responded_at
+	`, requesterID, addresseeID).Scan(&fr.ID, &fr.RequesterID, &fr.AddresseeID, &fr.Status, &fr.CreatedAt, &fr.RespondedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			// lost a race with the other side sending at the same time
+			return nil, ErrRequestExists
+		}
+		return nil, fmt.Errorf("create friend request: %w", err)
+	}
+	return &fr, nil
+}
+
+func (r *Repository) AcceptRequest(ctx context.Context, requestID, addresseeID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE friendships
+		SET status = 'accepted', responded_at = NOW()
+		WHERE id = $1 AND addressee_id = $2 AND status = 'pending'
+	`, requestID, addresseeID)
+	if err != nil {
+		return fmt.Errorf("accept friend request: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRequestNotFound
+	}
+	return nil
+}
+
+func (r *Repository) DeleteRequest(ctx context.Context, requestID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM friendships
+		WHERE id = $1
+		  AND status = 'pending'
+		  AND (requester_id = $2 OR addressee_id = $2)
+	`, requestID, userID)
+	if err != nil {
+		return fmt.Errorf("delete friend request: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRequestNotFound
+	}
+	return nil
+}
+
+func (r *Repository) ListIncomingRequests(ctx context.Context, userID int64) ([]FriendRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT f.id, f.requester_id, f.addressee_id, f.status, f.created_at, f.responded_at,
+		       u.first_name, u.last_name, u.profile_picture_url
+		FROM friendships f
+		JOIN users u ON u.id = f.requester_id
+		WHERE f.addressee_id = $1 AND f.status = 'pending'
+		ORDER BY f.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list friend requests: %w", err)
+	}
+	defer rows.Close()
+
+	reqs := []FriendRequest{}
+	for rows.Next() {
+		var fr FriendRequest
+		if err := rows.Scan(
+			&fr.ID, &fr.RequesterID, &fr.AddresseeID, &fr.Status, &fr.CreatedAt, &fr.RespondedAt,
+			&fr.FirstName, &fr.LastName, &fr.ProfilePictureURL,
+		); err != nil {
+			return nil, fmt.Errorf("scan friend request: %w", err)
+		}
+		reqs = append(reqs, fr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return reqs, nil
+}
+
+func (r *Repository) ListFriends(ctx context.Context, userID int64, limit, offset int) ([]Friend, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.first_name, u.last_name, u.profile_picture_url, u.skill_level,
+		       COALESCE(f.responded_at, f.created_at) AS since,
+		       COUNT(*) OVER() AS total_count
+		FROM friendships f
+		JOIN users u
+		  ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE (f.requester_id = $1 OR f.addressee_id = $1)
+		  AND f.status = 'accepted'
+		ORDER BY u.first_name, u.last_name, u.id
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list friends: %w", err)
+	}
+	defer rows.Close()
+
+	friends := []Friend{}
+	var total int
+	for rows.Next() {
+		var f Friend
+		if err := rows.Scan(&f.UserID, &f.FirstName, &f.LastName, &f.ProfilePictureURL, &f.SkillLevel, &f.Since, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan friend: %w", err)
+		}
+		friends = append(friends, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return friends, total, nil
+}
+
+func (r *Repository) RemoveFriend(ctx context.Context, userID, friendID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM friendships
+		WHERE LEAST(requester_id, addressee_id) = LEAST($1::bigint, $2::bigint)
+		  AND GREATEST(requester_id, addressee_id) = GREATEST($1::bigint, $2::bigint)
+		  AND status = 'accepted'
+	`, userID, friendID)
+	if err != nil {
+		return fmt.Errorf("remove friend: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFriends
+	}
+	return nil
+}
+
+func (r *Repository) AreFriends(ctx context.Context, a, b int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM friendships
+			WHERE LEAST(requester_id, addressee_id) = LEAST($1::bigint, $2::bigint)
+			  AND GREATEST(requester_id, addressee_id) = GREATEST($1::bigint, $2::bigint)
+			  AND status = 'accepted'
+		)
+	`, a, b).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check friendship: %w", err)
+	}
+	return ok, nil
+}
+
+func (r *Repository) GetGamesVisibility(ctx context.Context, userID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var v string
+	err := r.db.QueryRow(ctx, `SELECT games_visibility FROM users WHERE id = $1`, userID).Scan(&v)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("get games visibility: %w", err)
+	}
+	return v, nil
+}
+
+func (r *Repository) SetGamesVisibility(ctx context.Context, userID int64, visibility string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE users SET games_visibility = $2, updated_at = NOW() WHERE id = $1
+	`, userID, visibility)
+	if err != nil {
+		return fmt.Errorf("set games visibility: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *Repository) ListFriendsGamesNearby(ctx context.Context, userID int64, lat, lon float64, radiusKm, limit int) ([]FriendGame, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Friends see each other's games under both visibility settings,
+	// so no games_visibility check is needed here.
+	const query = `
+		WITH my_friends AS (
+			SELECT CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END AS friend_id,
+			       COALESCE(f.responded_at, f.created_at) AS since
+			FROM friendships f
+			WHERE (f.requester_id = $1 OR f.addressee_id = $1)
+			  AND f.status = 'accepted'
+		)
+		SELECT g.id, g.venue_id, v.name, g.sport_type, g.start_time, g.end_time, g.max_players,
+		       (SELECT COUNT(*) FROM game_players gp2 WHERE gp2.game_id = g.id) AS current_players,
+		       ST_Distance(v.location, ST_MakePoint($3, $2)::geography) AS distance_meters,
+		       ST_Y(v.location::geometry) AS venue_lat,
+		       ST_X(v.location::geometry) AS venue_lon,
+		       json_agg(json_build_object(
+		           'user_id', u.id,
+		           'first_name', u.first_name,
+		           'last_name', u.last_name,
+		           'profile_picture_url', u.profile_picture_url,
+		           'skill_level', u.skill_level,
+		           'since', mf.since
+		       ) ORDER BY u.first_name) AS friends
+		FROM games g
+		JOIN venues v ON v.id = g.venue_id
+		JOIN game_players gp ON gp.game_id = g.id
+		JOIN my_friends mf ON mf.friend_id = gp.user_id
+		JOIN users u ON u.id = gp.user_id
+		WHERE g.status = 'active'
+		  AND g.visibility = 'public'
+		  AND g.start_time >= NOW()
+		  AND ST_DWithin(v.location, ST_MakePoint($3, $2)::geography, $4)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM game_players me WHERE me.game_id = g.id AND me.user_id = $1
+		  )
+		GROUP BY g.id, v.id
+		ORDER BY g.start_time ASC, distance_meters ASC
+		LIMIT $5
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, lat, lon, float64(radiusKm)*1000, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list friends games nearby: %w", err)
+	}
+	defer rows.Close()
+
+	games := []FriendGame{}
+	for rows.Next() {
+		var g FriendGame
+		if err := rows.Scan(
+			&g.GameID, &g.VenueID, &g.VenueName, &g.SportType, &g.StartTime, &g.EndTime, &g.MaxPlayers,
+			&g.CurrentPlayers, &g.DistanceMeters, &g.VenueLat, &g.VenueLon, &g.Friends,
+		); err != nil {
+			return nil, fmt.Errorf("scan friend game: %w", err)
+		}
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return games, nil
+}
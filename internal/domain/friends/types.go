@@ -0,0 +1,88 @@
+package friends
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+var (
+	ErrRequestNotFound = errors.New("friend request not found")
+	ErrRequestExists   = errors.New("friend request already exists")
+	ErrAlreadyFriends  = errors.New("already friends")
+	ErrNotFriends      = errors.New("not friends")
+	ErrSelfRequest     = errors.New("cannot send a friend request to yourself")
+	ErrUserNotFound    = errors.New("user not found")
+)
+
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+)
+
+// Games visibility: who may see the games a user has joined.
+const (
+	VisibilityPublic  = "public"
+	VisibilityFriends = "friends"
+)
+
+// Friend is the public profile of someone on the other side of a friendship.
+type Friend struct {
+	UserID            int64     `json:"user_id"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	ProfilePictureURL *string   `json:"profile_picture_url,omitempty"`
+	SkillLevel        *string   `json:"skill_level,omitempty"`
+	Since             time.Time `json:"since"`
+}
+
+type FriendRequest struct {
+	ID          int64      `json:"id"`
+	RequesterID int64      `json:"requester_id"`
+	AddresseeID int64      `json:"addressee_id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+
+	// Requester profile, filled on incoming request lists.
+	FirstName         string  `json:"first_name,omitempty"`
+	LastName          string  `json:"last_name,omitempty"`
+	ProfilePictureURL *string `json:"profile_picture_url,omitempty"`
+}
+
+// FriendGame is an upcoming game near the caller that at least one friend joined.
+type FriendGame struct {
+	GameID         int64     `json:"game_id"`
+	VenueID        int64     `json:"venue_id"`
+	VenueName      string    `json:"venue_name"`
+	SportType      string    `json:"sport_type"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	MaxPlayers     int       `json:"max_players"`
+	CurrentPlayers int       `json:"current_players"`
+	DistanceMeters float64   `json:"distance_meters"`
+	VenueLat       float64   `json:"venue_lat"`
+	VenueLon       float64   `json:"venue_lon"`
+	Friends        []Friend  `json:"friends"`
+}
+
+type Store interface {
+	SendRequest(ctx context.Context, requesterID, addresseeID int64) (*FriendRequest, error)
+	AcceptRequest(ctx context.Context, requestID, addresseeID int64) error
+	// DeleteRequest declines (addressee) or cancels (requester) a pending request.
+	DeleteRequest(ctx context.Context, requestID, userID int64) error
+	ListIncomingRequests(ctx context.Context, userID int64) ([]FriendRequest, error)
+
+	ListFriends(ctx context.Context, userID int64, limit, offset int) ([]Friend, int, error)
+	RemoveFriend(ctx context.Context, userID, friendID int64) error
+	AreFriends(ctx context.Context, a, b int64) (bool, error)
+
+	GetGamesVisibility(ctx context.Context, userID int64) (string, error)
+	SetGamesVisibility(ctx context.Context, userID int64, visibility string) error
+
+	// ListFriendsGamesNearby returns upcoming active games within radiusKm of
+	// (lat, lon) that at least one of the user's friends has joined.
+	ListFriendsGamesNearby(ctx context.Context, userID int64, lat, lon float64, radiusKm, limit int) ([]FriendGame, error)
+}
@@ -0,0 +1,172 @@
+package gamepayments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Summary(ctx context.Context, gameID int64) (*Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	s := &Summary{GameID: gameID, Players: []PlayerBalance{}}
+	var price *int
+	err := r.db.QueryRow(ctx, `SELECT admin_id, price FROM games WHERE id = $1`, gameID).Scan(&s.AdminID, &price)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get game price: %w", err)
+	}
+	if price == nil || *price <= 0 {
+		return nil, ErrNoPrice
+	}
+	s.Price = *price
+
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.first_name, u.last_name, COALESCE(p.amount, 0), p.method, p.paid_at
+		FROM game_players gp
+		JOIN users u ON u.id = gp.user_id
+		LEFT JOIN game_payments p ON p.game_id = gp.game_id AND p.user_id = gp.user_id
+		WHERE gp.game_id = $1
+		  AND gp.user_id <> $2
+		ORDER BY p.paid_at IS NOT NULL, u.first_name, u.id
+	`, gameID, s.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("get game balances: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		b := PlayerBalance{Owed: s.Price}
+		if err := rows.Scan(&b.UserID, &b.FirstName, &b.LastName, &b.Paid, &b.Method, &b.PaidAt); err != nil {
+			return nil, fmt.Errorf("scan game balance: %w", err)
+		}
+		s.Expected += b.Owed
+		s.Collected += min(b.Paid, b.Owed)
+		s.Players = append(s.Players, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	s.Outstanding = s.Expected - s.Collected
+	return s, nil
+}
+
+func (r *Repository) MarkPaid(ctx context.Context, gameID, userID, markedBy int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var price *int
+	var isPlayer bool
+	err := r.db.QueryRow(ctx, `
+		SELECT g.price,
+		       EXISTS (SELECT 1 FROM game_players gp WHERE gp.game_id = g.id AND gp.user_id = $2)
+		       AND g.admin_id <> $2
+		FROM games g
+		WHERE g.id = $1
+	`, gameID, userID).Scan(&price, &isPlayer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrGameNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("get game price: %w", err)
+	}
+	if price == nil || *price <= 0 {
+		return ErrNoPrice
+	}
+	if !isPlayer {
+		return ErrNotPlayer
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO game_payments (game_id, user_id, amount, method, marked_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (game_id, user_id) DO UPDATE
+		SET amount = EXCLUDED.amount,
+		    method = EXCLUDED.method,
+		    marked_by = EXCLUDED.marked_by,
+		    paid_at = NOW()
+	`, gameID, userID, *price, MethodCash, markedBy)
+	if err != nil {
+		return fmt.Errorf("mark game payment: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Unmark(ctx context.Context, gameID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM game_payments WHERE game_id = $1 AND user_id = $2`, gameID, userID)
+	if err != nil {
+		return fmt.Errorf("unmark game payment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotPaid
+	}
+	return nil
+}
+
+func (r *Repository) ClaimReminders(ctx context.Context, limit int) ([]Reminder, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM games
+			WHERE payment_reminded_at IS NULL
+			  AND price > 0
+			  AND status <> 'cancelled'
+			  AND end_time <= NOW() - INTERVAL '1 day'
+			  AND end_time >  NOW() - INTERVAL '7 days'
+			ORDER BY end_time
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		),
+		stamped AS (
+			UPDATE games g
+			SET payment_reminded_at = NOW()
+			FROM due
+			WHERE g.id = due.id
+			RETURNING g.id, g.admin_id, g.price, COALESCE(g.sport_type, '') AS sport_type, g.start_time
+		)
+		SELECT s.id, gp.user_id, s.price, a.first_name, s.sport_type, s.start_time
+		FROM stamped s
+		JOIN users a ON a.id = s.admin_id
+		JOIN game_players gp ON gp.game_id = s.id AND gp.user_id <> s.admin_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM game_payments p WHERE p.game_id = s.id AND p.user_id = gp.user_id
+		)
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim game payment reminders: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Reminder{}
+	for rows.Next() {
+		var rm Reminder
+		if err := rows.Scan(&rm.GameID, &rm.UserID, &rm.Amount, &rm.AdminName, &rm.SportType, &rm.StartTime); err != nil {
+			return nil, fmt.Errorf("scan game payment reminder: %w", err)
+		}
+		list = append(list, rm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
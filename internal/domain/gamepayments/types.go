@@ -0,0 +1,71 @@
+package gamepayments
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// MethodCash is a payment handed to the game admin in person.
+const MethodCash = "cash"
+
+var (
+	ErrGameNotFound = errors.New("game not found")
+	ErrNoPrice      = errors.New("this game is free")
+	ErrNotPlayer    = errors.New("user is not a player of this game")
+	ErrNotPaid      = errors.New("player hasn't been marked paid")
+)
+
+// PlayerBalance is what one player owes for a game.
+type PlayerBalance struct {
+	UserID    int64      `json:"user_id"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	Owed      int        `json:"owed"`
+	Paid      int        `json:"paid"`
+	Method    *string    `json:"method,omitempty"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
+}
+
+// Settled reports whether the player has paid what they owe.
+func (b PlayerBalance) Settled() bool {
+	return b.Paid >= b.Owed
+}
+
+// Summary is who has paid a priced game's admin and who still owes. The
+// admin isn't listed; they collect.
+type Summary struct {
+	GameID      int64           `json:"game_id"`
+	AdminID     int64           `json:"admin_id"`
+	Price       int             `json:"price"`
+	Expected    int             `json:"expected"`
+	Collected   int             `json:"collected"`
+	Outstanding int             `json:"outstanding"`
+	Players     []PlayerBalance `json:"players"`
+}
+
+// Reminder is a player who still owes for a game that ended a day ago.
+type Reminder struct {
+	GameID    int64
+	UserID    int64
+	Amount    int
+	AdminName string
+	SportType string
+	StartTime time.Time
+}
+
+type Store interface {
+	// Summary returns the game's balances, unpaid players first.
+	Summary(ctx context.Context, gameID int64) (*Summary, error)
+	// MarkPaid records that the player paid the game's price in cash.
+	// Marking them again updates who marked it and when.
+	MarkPaid(ctx context.Context, gameID, userID, markedBy int64) error
+	// Unmark undoes MarkPaid.
+	Unmark(ctx context.Context, gameID, userID int64) error
+	// ClaimReminders stamps up to limit priced games that ended between one
+	// and seven days ago and returns their players who still owe. Each game
+	// is claimed once.
+	ClaimReminders(ctx context.Context, limit int) ([]Reminder, error)
+}
@@ -18,6 +18,7 @@ type Store interface {
 	CreateReply(ctx context.Context, reply *Reply) error
 	GetRepliesByQuestion(ctx context.Context, questionID int64) ([]Reply, error)
 	DeleteQuestion(ctx context.Context, questionID, userID int64) error
+	DeleteReply(ctx context.Context, replyID int64) error
 	GetQuestionsWithReplies(ctx context.Context, gameID int64) ([]QuestionWithReplies, error)
 }
 
@@ -182,6 +183,18 @@ func (r *Repository) DeleteQuestion(ctx context.Context, questionID, userID int6
 	return nil
 }
 
+// DeleteReply removes a reply, for moderators taking it down
+func (r *Repository) DeleteReply(ctx context.Context, replyID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM game_question_replies WHERE id = $1`, replyID)
+	if err != nil {
+		return fmt.Errorf("error deleting reply: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrReplyNotFound
+	}
+	return nil
+}
+
 func (r *Repository) GetQuestionsWithReplies(ctx context.Context, gameID int64) ([]QuestionWithReplies, error) {
 	query := `
 		SELECT 
@@ -0,0 +1,190 @@
+package games
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/domain/bookings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CancelReasonInsufficientPlayers is recorded on games the auto-cancel job
+// calls off.
+const CancelReasonInsufficientPlayers = "insufficient_players"
+
+// CancelReasonVenueClosed is recorded on games called off by an emergency
+// venue closure.
+const CancelReasonVenueClosed = "venue_closed"
+
+// ErrInvalidAutoCancel is returned when min_players or the cutoff don't fit
+// the game: the threshold has to be between 2 and max_players, and the
+// cutoff has to come before the start.
+var ErrInvalidAutoCancel = errors.New("invalid auto-cancel settings")
+
+// AutoCancelledGame is a game the auto-cancel job just called off.
+type AutoCancelledGame struct {
+	ID          int64
+	VenueID     int64
+	AdminID     int64
+	SportType   string
+	StartTime   time.Time
+	MinPlayers  int
+	PlayerCount int
+	// BookingID is the linked booking, if any. BookingReleased says whether
+	// the job canceled it; bookings already rejected or canceled are left
+	// alone.
+	BookingID       *int64
+	BookingReleased bool
+	// RejectedRequesters are the users whose pending join requests were
+	// closed along with the game.
+	RejectedRequesters []int64
+}
+
+// SetAutoCancel sets or, with both nil, clears the game's auto-cancel rule.
+func (r *Repository) SetAutoCancel(ctx context.Context, gameID int64, minPlayers *int, cutoff *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE games
+		SET min_players = $2, auto_cancel_at = $3
+		WHERE id = $1 AND status = 'active'
+	`, gameID, minPlayers, cutoff)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+			return ErrInvalidAutoCancel
+		}
+		return fmt.Errorf("set auto-cancel: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AutoCancelUnderfilled cancels every active game whose cutoff has passed
+// with fewer than min_players in it. In the same transaction the linked
+// booking is canceled, freeing the slot, and pending join requests are
+// rejected.
+func (r *Repository) AutoCancelUnderfilled(ctx context.Context) ([]AutoCancelledGame, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	cancelled := []AutoCancelledGame{}
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			WITH due AS (
+				SELECT g.id,
+				       (SELECT COUNT(*) FROM game_players gp WHERE gp.game_id = g.id) AS players
+				FROM games g
+				WHERE g.status = 'active'
+				  AND g.auto_cancel_at <= NOW()
+				  AND g.start_time > NOW()
+				FOR UPDATE SKIP LOCKED
+			)
+			UPDATE games g
+			SET status = 'cancelled',
+			    cancel_reason = $1,
+			    booking_status = 'cancelled'
+			FROM due
+			WHERE g.id = due.id
+			  AND due.players < g.min_players
+			RETURNING g.id, g.venue_id, g.admin_id, COALESCE(g.sport_type, ''), g.start_time,
+			          g.min_players, due.players, g.booking_id
+		`, CancelReasonInsufficientPlayers)
+		if err != nil {
+			return fmt.Errorf("cancel underfilled games: %w", err)
+		}
+		ids := []int64{}
+		bookingIDs := []int64{}
+		for rows.Next() {
+			var g AutoCancelledGame
+			if err := rows.Scan(&g.ID, &g.VenueID, &g.AdminID, &g.SportType, &g.StartTime,
+				&g.MinPlayers, &g.PlayerCount, &g.BookingID); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan cancelled game: %w", err)
+			}
+			cancelled = append(cancelled, g)
+			ids = append(ids, g.ID)
+			if g.BookingID != nil {
+				bookingIDs = append(bookingIDs, *g.BookingID)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		released := map[int64]bool{}
+		if len(bookingIDs) > 0 {
+			if err := bookings.Attribute(ctx, tx, 0, bookings.SourceGameAutoCancel); err != nil {
+				return err
+			}
+			rows, err = tx.Query(ctx, `
+				UPDATE bookings
+				SET status = 'canceled', updated_at = NOW()
+				WHERE id = ANY($1)
+				  AND status IN ('pending', 'confirmed')
+				RETURNING id
+			`, bookingIDs)
+			if err != nil {
+				return fmt.Errorf("release bookings: %w", err)
+			}
+			for rows.Next() {
+				var id int64
+				if err := rows.Scan(&id); err != nil {
+					rows.Close()
+					return fmt.Errorf("scan released booking: %w", err)
+				}
+				released[id] = true
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("rows iteration: %w", err)
+			}
+		}
+
+		rows, err = tx.Query(ctx, `
+			UPDATE game_join_requests
+			SET status = 'rejected'
+			WHERE game_id = ANY($1)
+			  AND status = 'pending'
+			RETURNING game_id, user_id
+		`, ids)
+		if err != nil {
+			return fmt.Errorf("close join requests: %w", err)
+		}
+		defer rows.Close()
+
+		requesters := map[int64][]int64{}
+		for rows.Next() {
+			var gameID, userID int64
+			if err := rows.Scan(&gameID, &userID); err != nil {
+				return fmt.Errorf("scan closed request: %w", err)
+			}
+			requesters[gameID] = append(requesters[gameID], userID)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+
+		for i := range cancelled {
+			g := &cancelled[i]
+			g.BookingReleased = g.BookingID != nil && released[*g.BookingID]
+			g.RejectedRequesters = requesters[g.ID]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
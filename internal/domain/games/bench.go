@@ -0,0 +1,224 @@
+package games
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrGameFull         = errors.New("game and bench are full")
+	ErrAlreadyInGame    = errors.New("user is already in the game or on its bench")
+	ErrNotInGame        = errors.New("user is not in the game")
+	ErrAdminCannotLeave = errors.New("the game admin can't leave their own game")
+	ErrBenchTooSmall    = errors.New("bench size is smaller than the players already on it")
+)
+
+// lockActiveGame locks the game row so seat and bench counts can't change
+// underneath the caller, and returns its limits.
+func lockActiveGame(ctx context.Context, tx pgx.Tx, gameID int64) (maxPlayers, benchSize int, err error) {
+	err = tx.QueryRow(ctx, `
+		SELECT max_players, bench_size
+		FROM games
+		WHERE id = $1 AND status = 'active'
+		FOR UPDATE
+	`, gameID).Scan(&maxPlayers, &benchSize)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("lock game: %w", err)
+	}
+	return maxPlayers, benchSize, nil
+}
+
+// AddPlayerOrBench puts the user in the game, or on the bench when every
+// seat is taken. benchPosition is their place in the bench queue, or 0 when
+// they got a seat.
+func (r *Repository) AddPlayerOrBench(ctx context.Context, gameID, userID int64) (benchPosition int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err = database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		maxPlayers, benchSize, err := lockActiveGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		benchPosition, err = seatOrBench(ctx, tx, gameID, userID, maxPlayers, benchSize)
+		return err
+	})
+	return benchPosition, err
+}
+
+// seatOrBench does the work of AddPlayerOrBench inside the caller's
+// transaction, which must already hold the lock from lockActiveGame.
+func seatOrBench(ctx context.Context, tx pgx.Tx, gameID, userID int64, maxPlayers, benchSize int) (benchPosition int, err error) {
+	var players, bench int
+	var already bool
+	err = tx.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM game_players WHERE game_id = $1),
+			(SELECT COUNT(*) FROM game_bench WHERE game_id = $1),
+			EXISTS (SELECT 1 FROM game_players WHERE game_id = $1 AND user_id = $2)
+			OR EXISTS (SELECT 1 FROM game_bench WHERE game_id = $1 AND user_id = $2)
+	`, gameID, userID).Scan(&players, &bench, &already)
+	if err != nil {
+		return 0, fmt.Errorf("count players: %w", err)
+	}
+	if already {
+		return 0, ErrAlreadyInGame
+	}
+
+	switch {
+	case players < maxPlayers:
+		_, err = tx.Exec(ctx, `
+			INSERT INTO game_players (game_id, user_id, role, joined_at)
+			VALUES ($1, $2, 'player', NOW())
+		`, gameID, userID)
+		if err != nil {
+			return 0, fmt.Errorf("error inserting player into game: %w", err)
+		}
+		return 0, nil
+	case bench < benchSize:
+		_, err = tx.Exec(ctx, `
+			INSERT INTO game_bench (game_id, user_id) VALUES ($1, $2)
+		`, gameID, userID)
+		if err != nil {
+			return 0, fmt.Errorf("insert bench player: %w", err)
+		}
+		return bench + 1, nil
+	default:
+		return 0, ErrGameFull
+	}
+}
+
+// RemovePlayer takes the user out of the game or off its bench. When a seat
+// frees up, the longest-waiting bench player is moved into it and returned
+// as promoted.
+func (r *Repository) RemovePlayer(ctx context.Context, gameID, userID int64) (promoted *int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err = database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if _, _, err := lockActiveGame(ctx, tx, gameID); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, `DELETE FROM game_bench WHERE game_id = $1 AND user_id = $2`, gameID, userID)
+		if err != nil {
+			return fmt.Errorf("remove bench player: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			var role string
+			err = tx.QueryRow(ctx, `
+				SELECT COALESCE(role, 'player') FROM game_players WHERE game_id = $1 AND user_id = $2
+			`, gameID, userID).Scan(&role)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotInGame
+			}
+			if err != nil {
+				return fmt.Errorf("get player role: %w", err)
+			}
+			if role == "admin" {
+				return ErrAdminCannotLeave
+			}
+
+			if _, err := tx.Exec(ctx, `DELETE FROM game_players WHERE game_id = $1 AND user_id = $2`, gameID, userID); err != nil {
+				return fmt.Errorf("remove player: %w", err)
+			}
+
+			var next int64
+			err = tx.QueryRow(ctx, `
+				DELETE FROM game_bench
+				WHERE id = (
+					SELECT id FROM game_bench
+					WHERE game_id = $1
+					ORDER BY joined_at, id
+					LIMIT 1
+				)
+				RETURNING user_id
+			`, gameID).Scan(&next)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+			case err != nil:
+				return fmt.Errorf("take bench player: %w", err)
+			default:
+				_, err = tx.Exec(ctx, `
+					INSERT INTO game_players (game_id, user_id, role, joined_at)
+					VALUES ($1, $2, 'player', NOW())
+				`, gameID, next)
+				if err != nil {
+					return fmt.Errorf("promote bench player: %w", err)
+				}
+				promoted = &next
+			}
+		}
+
+		// Let them ask to join again later.
+		_, err = tx.Exec(ctx, `DELETE FROM game_join_requests WHERE game_id = $1 AND user_id = $2`, gameID, userID)
+		if err != nil {
+			return fmt.Errorf("clear join request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
+// SetBenchSize changes how many players may wait on the bench. It can't drop
+// below the number already waiting.
+func (r *Repository) SetBenchSize(ctx context.Context, gameID int64, size int) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if _, _, err := lockActiveGame(ctx, tx, gameID); err != nil {
+			return err
+		}
+
+		var waiting int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM game_bench WHERE game_id = $1`, gameID).Scan(&waiting); err != nil {
+			return fmt.Errorf("count bench: %w", err)
+		}
+		if size < waiting {
+			return ErrBenchTooSmall
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE games SET bench_size = $2 WHERE id = $1`, gameID, size); err != nil {
+			return fmt.Errorf("set bench size: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetBenchPlayerIDs returns the users on the bench in promotion order.
+func (r *Repository) GetBenchPlayerIDs(ctx context.Context, gameID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT user_id FROM game_bench WHERE game_id = $1 ORDER BY joined_at, id
+	`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("query bench players: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan bench player: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,255 @@
+package games
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidGameUpdate = errors.New("invalid game update")
+	ErrRosterTooLarge    = errors.New("more players have already joined than the new max_players")
+)
+
+// GameDetailsUpdate holds the details an admin or assistant may change.
+// Nil fields are left as they are; an empty safety or contact field clears it.
+type GameDetailsUpdate struct {
+	StartTime             *time.Time
+	EndTime               *time.Time
+	MaxPlayers            *int
+	Price                 *int
+	Format                *string
+	GameLevel             *string
+	Visibility            *string
+	Instruction           *string
+	SafetyInfo            *string
+	EmergencyContactName  *string
+	EmergencyContactPhone *string
+}
+
+// FieldChange is one detail an edit changed.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// GameChange is one edit in a game's history.
+type GameChange struct {
+	ID        int64         `json:"id"`
+	ChangedBy *int64        `json:"changed_by,omitempty"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// GameEdit is what UpdateDetails did: the fields that actually changed and
+// the bench players moved into seats a bigger max_players opened up.
+type GameEdit struct {
+	Changes  []FieldChange
+	Promoted []int64
+}
+
+// UpdateDetails applies u to an active game and records what changed in
+// the game's history. max_players can't drop below the players already in
+// the game. Nothing is written when u changes nothing.
+func (r *Repository) UpdateDetails(ctx context.Context, gameID, changedBy int64, u GameDetailsUpdate) (*GameEdit, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	edit := &GameEdit{}
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var (
+			g            Game
+			autoCancelAt *time.Time
+			players      int
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT start_time, end_time, max_players, price, format, game_level,
+			       visibility, instruction, min_players, auto_cancel_at,
+			       safety_info, emergency_contact_name, emergency_contact_phone
+			FROM games
+			WHERE id = $1 AND status = 'active'
+			FOR UPDATE
+		`, gameID).Scan(
+			&g.StartTime, &g.EndTime, &g.MaxPlayers, &g.Price, &g.Format, &g.GameLevel,
+			&g.Visibility, &g.Instruction, &g.MinPlayers, &autoCancelAt,
+			&g.SafetyInfo, &g.EmergencyContactName, &g.EmergencyContactPhone,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("lock game: %w", err)
+		}
+		oldMax := g.MaxPlayers
+
+		var changes []FieldChange
+		changeTime(&changes, "start_time", &g.StartTime, u.StartTime)
+		changeTime(&changes, "end_time", &g.EndTime, u.EndTime)
+		changeValue(&changes, "max_players", &g.MaxPlayers, u.MaxPlayers)
+		changeNullable(&changes, "price", &g.Price, u.Price)
+		changeNullable(&changes, "format", &g.Format, u.Format)
+		changeNullable(&changes, "game_level", &g.GameLevel, u.GameLevel)
+		changeValue(&changes, "visibility", &g.Visibility, u.Visibility)
+		changeNullable(&changes, "instruction", &g.Instruction, u.Instruction)
+		changeNullable(&changes, "safety_info", &g.SafetyInfo, u.SafetyInfo)
+		changeNullable(&changes, "emergency_contact_name", &g.EmergencyContactName, u.EmergencyContactName)
+		changeNullable(&changes, "emergency_contact_phone", &g.EmergencyContactPhone, u.EmergencyContactPhone)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if !g.EndTime.After(g.StartTime) {
+			return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidGameUpdate)
+		}
+		if u.StartTime != nil && !g.StartTime.After(time.Now()) {
+			return fmt.Errorf("%w: start_time must be in the future", ErrInvalidGameUpdate)
+		}
+		if autoCancelAt != nil && !autoCancelAt.Before(g.StartTime) {
+			return fmt.Errorf("%w: auto_cancel_at must stay before the game starts, change or turn off auto-cancel first", ErrInvalidGameUpdate)
+		}
+		if g.MinPlayers != nil && *g.MinPlayers > g.MaxPlayers {
+			return fmt.Errorf("%w: max_players can't be less than min_players (%d)", ErrInvalidGameUpdate, *g.MinPlayers)
+		}
+
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM game_players WHERE game_id = $1`, gameID).Scan(&players); err != nil {
+			return fmt.Errorf("count players: %w", err)
+		}
+		if g.MaxPlayers < players {
+			return fmt.Errorf("%w (%d joined)", ErrRosterTooLarge, players)
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE games
+			SET start_time = $2, end_time = $3, max_players = $4, price = $5,
+			    format = $6, game_level = $7, visibility = $8, instruction = $9,
+			    safety_info = NULLIF($10, ''), emergency_contact_name = NULLIF($11, ''),
+			    emergency_contact_phone = NULLIF($12, '')
+			WHERE id = $1
+		`, gameID, g.StartTime, g.EndTime, g.MaxPlayers, g.Price,
+			g.Format, g.GameLevel, g.Visibility, g.Instruction,
+			g.SafetyInfo, g.EmergencyContactName, g.EmergencyContactPhone)
+		if err != nil {
+			return fmt.Errorf("update game details: %w", err)
+		}
+
+		// Seats a bigger game opens go to the bench, longest-waiting first.
+		if open := g.MaxPlayers - players; g.MaxPlayers > oldMax && open > 0 {
+			rows, err := tx.Query(ctx, `
+				WITH moved AS (
+					DELETE FROM game_bench
+					WHERE id IN (
+						SELECT id FROM game_bench
+						WHERE game_id = $1
+						ORDER BY joined_at, id
+						LIMIT $2
+					)
+					RETURNING user_id
+				)
+				INSERT INTO game_players (game_id, user_id, role, joined_at)
+				SELECT $1, user_id, 'player', NOW() FROM moved
+				RETURNING user_id
+			`, gameID, open)
+			if err != nil {
+				return fmt.Errorf("promote bench players: %w", err)
+			}
+			edit.Promoted, err = pgx.CollectRows(rows, pgx.RowTo[int64])
+			if err != nil {
+				return fmt.Errorf("promote bench players: %w", err)
+			}
+		}
+
+		raw, err := json.Marshal(changes)
+		if err != nil {
+			return fmt.Errorf("encode game changes: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO game_changes (game_id, changed_by, changes)
+			VALUES ($1, $2, $3)
+		`, gameID, changedBy, raw)
+		if err != nil {
+			return fmt.Errorf("record game changes: %w", err)
+		}
+
+		edit.Changes = changes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edit, nil
+}
+
+// ListChanges returns the game's edit history, newest first.
+func (r *Repository) ListChanges(ctx context.Context, gameID int64) ([]GameChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, changed_by, changes, created_at
+		FROM game_changes
+		WHERE game_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("list game changes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []GameChange{}
+	for rows.Next() {
+		var c GameChange
+		var raw []byte
+		if err := rows.Scan(&c.ID, &c.ChangedBy, &raw, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan game change: %w", err)
+		}
+		if err := json.Unmarshal(raw, &c.Changes); err != nil {
+			return nil, fmt.Errorf("decode game change %d: %w", c.ID, err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+func changeValue[T comparable](changes *[]FieldChange, field string, cur *T, req *T) {
+	if req == nil || *cur == *req {
+		return
+	}
+	*changes = append(*changes, FieldChange{Field: field, Old: *cur, New: *req})
+	*cur = *req
+}
+
+func changeNullable[T comparable](changes *[]FieldChange, field string, cur **T, req *T) {
+	if req == nil || (*cur != nil && **cur == *req) {
+		return
+	}
+	var old any
+	if *cur != nil {
+		old = **cur
+	}
+	v := *req
+	*changes = append(*changes, FieldChange{Field: field, Old: old, New: v})
+	*cur = &v
+}
+
+// changeTime compares instants rather than using ==, which also compares
+// the location.
+func changeTime(changes *[]FieldChange, field string, cur *time.Time, req *time.Time) {
+	if req == nil {
+		return
+	}
+	v := req.Truncate(time.Second) // the columns keep whole seconds
+	if cur.Equal(v) {
+		return
+	}
+	*changes = append(*changes, FieldChange{Field: field, Old: *cur, New: v})
+	*cur = v
+}
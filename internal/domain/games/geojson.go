@@ -0,0 +1,75 @@
+package games
+
+import "time"
+
+// FeatureCollection is a GeoJSON (RFC 7946) feature collection of games,
+// shaped so Mapbox can load it directly as a source.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string            `json:"type"`
+	ID         int64             `json:"id"`
+	Geometry   Point             `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+// Point holds [longitude, latitude], the GeoJSON order.
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type FeatureProperties struct {
+	GameID        int64         `json:"game_id"`
+	VenueID       int64         `json:"venue_id"`
+	VenueName     string        `json:"venue_name"`
+	SportType     string        `json:"sport_type"`
+	Price         *int          `json:"price,omitempty"`
+	Format        *string       `json:"format,omitempty"`
+	GameLevel     *string       `json:"game_level,omitempty"`
+	StartTime     time.Time     `json:"start_time"`
+	EndTime       time.Time     `json:"end_time"`
+	MaxPlayers    int           `json:"max_players"`
+	CurrentPlayer int           `json:"current_player"`
+	BookingStatus BookingStatus `json:"booking_status"`
+	MatchFull     bool          `json:"match_full"`
+	Shortlisted   bool          `json:"shortlisted"`
+	Status        string        `json:"status"`
+}
+
+// ToFeatureCollection turns games into one point feature each, placed at
+// the venue.
+func ToFeatureCollection(list []GameSummary) FeatureCollection {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(list))}
+	for _, g := range list {
+		fc.Features = append(fc.Features, Feature{
+			Type: "Feature",
+			ID:   g.GameID,
+			Geometry: Point{
+				Type:        "Point",
+				Coordinates: [2]float64{g.VenueLon, g.VenueLat},
+			},
+			Properties: FeatureProperties{
+				GameID:        g.GameID,
+				VenueID:       g.VenueID,
+				VenueName:     g.VenueName,
+				SportType:     g.SportType,
+				Price:         g.Price,
+				Format:        g.Format,
+				GameLevel:     g.GameLevel,
+				StartTime:     g.StartTime,
+				EndTime:       g.EndTime,
+				MaxPlayers:    g.MaxPlayers,
+				CurrentPlayer: g.CurrentPlayer,
+				BookingStatus: g.BookingStatus,
+				MatchFull:     g.MatchFull,
+				Shortlisted:   g.Shortlisted,
+				Status:        g.Status,
+			},
+		})
+	}
+	return fc
+}
@@ -0,0 +1,346 @@
+package games
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Invitation statuses. Links stay pending until revoked; their Uses count
+// the players who joined through them.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationClosed   = errors.New("this invitation has expired or been used up")
+)
+
+// Invitation lets a user, or anyone holding a link when InviteeID is nil,
+// into the game without a join request.
+type Invitation struct {
+	ID          int64      `json:"id"`
+	GameID      int64      `json:"game_id"`
+	InvitedBy   *int64     `json:"invited_by,omitempty"`
+	InviteeID   *int64     `json:"invitee_id,omitempty"`
+	InviteeName string     `json:"invitee_name,omitempty"`
+	LinkNonce   *int       `json:"-"`
+	MaxUses     *int       `json:"max_uses,omitempty"`
+	Uses        int        `json:"uses"`
+	Status      string     `json:"status"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// UserInvitation is a pending invitation as its recipient sees it.
+type UserInvitation struct {
+	ID          int64     `json:"id"`
+	GameID      int64     `json:"game_id"`
+	SportType   string    `json:"sport_type"`
+	VenueName   string    `json:"venue_name"`
+	StartTime   time.Time `json:"start_time"`
+	InviterName string    `json:"inviter_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InvitationSummary counts a game's invitations for GameDetails.
+type InvitationSummary struct {
+	Pending   int `json:"pending"`
+	Accepted  int `json:"accepted"`
+	Declined  int `json:"declined"`
+	LinkJoins int `json:"link_joins"`
+}
+
+// AcceptedInvitation is where accepting an invitation put the user.
+type AcceptedInvitation struct {
+	GameID  int64
+	AdminID int64
+	// BenchPosition is the user's place on the bench, or 0 when they got a
+	// seat.
+	BenchPosition int
+}
+
+// CreateInviteLink saves inv as a shareable link for an active game and
+// gives it a fresh nonce.
+func (r *Repository) CreateInviteLink(ctx context.Context, inv *Invitation) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var buf [4]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return fmt.Errorf("link nonce: %w", err)
+	}
+	nonce := int(binary.BigEndian.Uint32(buf[:]) >> 1)
+	inv.LinkNonce = &nonce
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO game_invitations (game_id, invited_by, link_nonce, max_uses, expires_at)
+		SELECT id, $2, $3, $4, $5
+		FROM games
+		WHERE id = $1 AND status = 'active'
+		RETURNING id, uses, status, created_at
+	`, inv.GameID, inv.InvitedBy, nonce, inv.MaxUses, inv.ExpiresAt).Scan(&inv.ID, &inv.Uses, &inv.Status, &inv.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("create invite link: %w", err)
+	}
+	return nil
+}
+
+// InviteUsers invites each of the users to the game and returns the ones
+// invited now. Users already in the game, on its bench or holding a pending
+// invitation are skipped.
+func (r *Repository) InviteUsers(ctx context.Context, gameID, invitedBy int64, userIDs []int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		INSERT INTO game_invitations (game_id, invited_by, invitee_id)
+		SELECT $1, $2, u.id
+		FROM users u
+		WHERE u.id = ANY($3)
+		  AND u.id <> $2
+		  AND NOT EXISTS (SELECT 1 FROM game_players gp WHERE gp.game_id = $1 AND gp.user_id = u.id)
+		  AND NOT EXISTS (SELECT 1 FROM game_bench gb WHERE gb.game_id = $1 AND gb.user_id = u.id)
+		ON CONFLICT DO NOTHING
+		RETURNING invitee_id
+	`, gameID, invitedBy, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invite users: %w", err)
+	}
+	defer rows.Close()
+
+	invited := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan invitee: %w", err)
+		}
+		invited = append(invited, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return invited, nil
+}
+
+// ListInvitations returns the game's invitations and links, newest first.
+func (r *Repository) ListInvitations(ctx context.Context, gameID int64) ([]Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, i.game_id, i.invited_by, i.invitee_id,
+		       COALESCE(TRIM(u.first_name || ' ' || u.last_name), ''),
+		       i.link_nonce, i.max_uses, i.uses, i.status, i.expires_at, i.responded_at, i.created_at
+		FROM game_invitations i
+		LEFT JOIN users u ON u.id = i.invitee_id
+		WHERE i.game_id = $1
+		ORDER BY i.created_at DESC, i.id DESC
+	`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		var inv Invitation
+		if err := rows.Scan(&inv.ID, &inv.GameID, &inv.InvitedBy, &inv.InviteeID, &inv.InviteeName,
+			&inv.LinkNonce, &inv.MaxUses, &inv.Uses, &inv.Status, &inv.ExpiresAt, &inv.RespondedAt, &inv.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation withdraws a pending invitation or disables a link.
+func (r *Repository) RevokeInvitation(ctx context.Context, gameID, invitationID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE game_invitations
+		SET status = 'revoked', responded_at = NOW()
+		WHERE id = $1 AND game_id = $2 AND status = 'pending'
+	`, invitationID, gameID)
+	if err != nil {
+		return fmt.Errorf("revoke invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// UserInvitations lists the user's pending invitations to games that are
+// still to come.
+func (r *Repository) UserInvitations(ctx context.Context, userID int64) ([]UserInvitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, g.id, COALESCE(g.sport_type, ''), v.name, g.start_time,
+		       COALESCE(inviter.first_name, ''), i.created_at
+		FROM game_invitations i
+		JOIN games g ON g.id = i.game_id
+		JOIN venues v ON v.id = g.venue_id
+		LEFT JOIN users inviter ON inviter.id = i.invited_by
+		WHERE i.invitee_id = $1
+		  AND i.status = 'pending'
+		  AND (i.expires_at IS NULL OR i.expires_at > NOW())
+		  AND g.status = 'active'
+		  AND g.start_time > NOW()
+		ORDER BY g.start_time
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list user invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []UserInvitation{}
+	for rows.Next() {
+		var inv UserInvitation
+		if err := rows.Scan(&inv.ID, &inv.GameID, &inv.SportType, &inv.VenueName, &inv.StartTime,
+			&inv.InviterName, &inv.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan user invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return invitations, nil
+}
+
+// AcceptInvitation puts the user in the game, or on its bench when it is
+// full, through an invitation. A nil nonce accepts an invitation addressed to
+// the user; otherwise it is a link's nonce. Any join request the user has
+// pending for the game is marked accepted with it.
+func (r *Repository) AcceptInvitation(ctx context.Context, invitationID int64, nonce *int, userID int64) (*AcceptedInvitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var accepted AcceptedInvitation
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var inv Invitation
+		err := tx.QueryRow(ctx, `
+			SELECT game_id, invitee_id, link_nonce, max_uses, uses, status, expires_at
+			FROM game_invitations
+			WHERE id = $1
+			FOR UPDATE
+		`, invitationID).Scan(&inv.GameID, &inv.InviteeID, &inv.LinkNonce, &inv.MaxUses, &inv.Uses, &inv.Status, &inv.ExpiresAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvitationNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("lock invitation: %w", err)
+		}
+
+		if nonce == nil {
+			if inv.InviteeID == nil || *inv.InviteeID != userID {
+				return ErrInvitationNotFound
+			}
+		} else if inv.LinkNonce == nil || *inv.LinkNonce != *nonce {
+			return ErrInvitationNotFound
+		}
+		if inv.Status != InvitationPending ||
+			(inv.ExpiresAt != nil && !inv.ExpiresAt.After(time.Now())) ||
+			(inv.MaxUses != nil && inv.Uses >= *inv.MaxUses) {
+			return ErrInvitationClosed
+		}
+
+		maxPlayers, benchSize, err := lockActiveGame(ctx, tx, inv.GameID)
+		if err != nil {
+			return err
+		}
+		var (
+			start   time.Time
+			blocked bool
+		)
+		if err := tx.QueryRow(ctx, `
+			SELECT g.start_time, g.admin_id, EXISTS (
+				SELECT 1 FROM blocked_users b
+				WHERE (b.blocker_id = g.admin_id AND b.blocked_id = $2)
+				   OR (b.blocker_id = $2 AND b.blocked_id = g.admin_id)
+			)
+			FROM games g WHERE g.id = $1
+		`, inv.GameID, userID).Scan(&start, &accepted.AdminID, &blocked); err != nil {
+			return fmt.Errorf("get game: %w", err)
+		}
+		// A link shared on to someone with a block with the admin doesn't work for them
+		if blocked {
+			return ErrInvitationNotFound
+		}
+		if !start.After(time.Now()) {
+			return ErrGameStarted
+		}
+
+		accepted.GameID = inv.GameID
+		accepted.BenchPosition, err = seatOrBench(ctx, tx, inv.GameID, userID, maxPlayers, benchSize)
+		if err != nil {
+			return err
+		}
+
+		if nonce != nil {
+			if _, err := tx.Exec(ctx, `UPDATE game_invitations SET uses = uses + 1 WHERE id = $1`, invitationID); err != nil {
+				return fmt.Errorf("count link use: %w", err)
+			}
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE game_invitations
+			SET status = 'accepted', responded_at = NOW()
+			WHERE game_id = $1 AND invitee_id = $2 AND status = 'pending'
+		`, inv.GameID, userID); err != nil {
+			return fmt.Errorf("accept invitation: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE game_join_requests
+			SET status = 'accepted'
+			WHERE game_id = $1 AND user_id = $2 AND status = 'pending'
+		`, inv.GameID, userID); err != nil {
+			return fmt.Errorf("accept join request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &accepted, nil
+}
+
+// DeclineInvitation turns down an invitation addressed to the user.
+func (r *Repository) DeclineInvitation(ctx context.Context, invitationID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE game_invitations
+		SET status = 'declined', responded_at = NOW()
+		WHERE id = $1 AND invitee_id = $2 AND status = 'pending'
+	`, invitationID, userID)
+	if err != nil {
+		return fmt.Errorf("decline invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package games
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Join policies decide what happens to a request to join a game.
+const (
+	// JoinPolicyAutoAccept seats players straight away while there is room;
+	// once the game is full, requests wait for the admin.
+	JoinPolicyAutoAccept = "auto_accept"
+	JoinPolicyManual     = "manual"
+	// JoinPolicyInviteOnly takes no requests; players join through invitations.
+	JoinPolicyInviteOnly = "invite_only"
+)
+
+var (
+	ErrInviteOnly  = errors.New("this game is invite only")
+	ErrGameStarted = errors.New("the game has already started")
+)
+
+// ExpiredJoinRequest is a pending request the expiry sweep rejected.
+type ExpiredJoinRequest struct {
+	GameID int64
+	UserID int64
+}
+
+// SetJoinPolicy changes how the game's join requests are handled. Requests
+// already pending are left for the admin.
+func (r *Repository) SetJoinPolicy(ctx context.Context, gameID int64, policy string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE games SET join_policy = $2 WHERE id = $1 AND status = 'active'
+	`, gameID, policy)
+	if err != nil {
+		return fmt.Errorf("set join policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// JoinIfSeat seats the user when the game has a free seat, recording their
+// request as accepted. joined is false when the game is full.
+func (r *Repository) JoinIfSeat(ctx context.Context, gameID, userID int64) (joined bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err = database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		maxPlayers, _, err := lockActiveGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+
+		var players int
+		var already bool
+		err = tx.QueryRow(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM game_players WHERE game_id = $1),
+				EXISTS (SELECT 1 FROM game_players WHERE game_id = $1 AND user_id = $2)
+				OR EXISTS (SELECT 1 FROM game_bench WHERE game_id = $1 AND user_id = $2)
+		`, gameID, userID).Scan(&players, &already)
+		if err != nil {
+			return fmt.Errorf("count players: %w", err)
+		}
+		if already {
+			return ErrAlreadyInGame
+		}
+		if players >= maxPlayers {
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO game_players (game_id, user_id, role, joined_at)
+			VALUES ($1, $2, 'player', NOW())
+		`, gameID, userID); err != nil {
+			return fmt.Errorf("insert player: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO game_join_requests (game_id, user_id, status)
+			VALUES ($1, $2, 'accepted')
+			ON CONFLICT (game_id, user_id) DO UPDATE SET status = 'accepted'
+		`, gameID, userID); err != nil {
+			return fmt.Errorf("record join request: %w", err)
+		}
+		joined = true
+		return nil
+	})
+	return joined, err
+}
+
+// ExpireJoinRequests rejects pending requests for games that have started or
+// are no longer active.
+func (r *Repository) ExpireJoinRequests(ctx context.Context) ([]ExpiredJoinRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		UPDATE game_join_requests gr
+		SET status = 'rejected'
+		FROM games g
+		WHERE g.id = gr.game_id
+		  AND gr.status = 'pending'
+		  AND (g.start_time <= NOW() OR g.status <> 'active')
+		RETURNING gr.game_id, gr.user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("expire join requests: %w", err)
+	}
+	defer rows.Close()
+
+	expired := []ExpiredJoinRequest{}
+	for rows.Next() {
+		var e ExpiredJoinRequest
+		if err := rows.Scan(&e.GameID, &e.UserID); err != nil {
+			return nil, fmt.Errorf("scan expired request: %w", err)
+		}
+		expired = append(expired, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return expired, nil
+}
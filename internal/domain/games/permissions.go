@@ -0,0 +1,196 @@
+package games
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Permission is something a game's admin or assistants may be allowed to do.
+type Permission string
+
+const (
+	PermAcceptRequests Permission = "accept_requests"
+	PermKickPlayers    Permission = "kick_players"
+	PermEditDetails    Permission = "edit_details"
+	PermRecordScore    Permission = "record_score"
+)
+
+// AllPermissions lists every permission, in the order the app shows them.
+var AllPermissions = []Permission{PermAcceptRequests, PermKickPlayers, PermEditDetails, PermRecordScore}
+
+// DefaultAssistantPermissions is what a newly assigned assistant may do.
+var DefaultAssistantPermissions = []Permission{PermAcceptRequests, PermEditDetails}
+
+var ErrUnknownPermission = errors.New("unknown permission")
+
+// ValidPermission reports whether p is a known permission.
+func ValidPermission(p Permission) bool {
+	return slices.Contains(AllPermissions, p)
+}
+
+// Member is a user's place in a game: their role and, for assistants, what
+// the admin lets them do.
+type Member struct {
+	UserID      int64        `json:"user_id"`
+	Role        string       `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Can applies the permission matrix: the admin may do everything, an
+// assistant what was granted to them and a player nothing.
+func (m *Member) Can(p Permission) bool {
+	switch m.Role {
+	case "admin":
+		return true
+	case "assistant":
+		return slices.Contains(m.Permissions, p)
+	default:
+		return false
+	}
+}
+
+// AssistantPermissions is an assistant and what they may do, for the admin's
+// co-host screen.
+type AssistantPermissions struct {
+	UserID      int64        `json:"user_id"`
+	FirstName   string       `json:"first_name"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// GameScore is the final score recorded for a game.
+type GameScore struct {
+	TeamA      int       `json:"team_a"`
+	TeamB      int       `json:"team_b"`
+	RecordedBy *int64    `json:"recorded_by,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// GetMember returns the user's role and permissions in the game, or
+// ErrNotFound when they aren't in it.
+func (r *Repository) GetMember(ctx context.Context, gameID, userID int64) (*Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	m := Member{UserID: userID}
+	var perms []string
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(role, 'player'), permissions
+		FROM game_players
+		WHERE game_id = $1 AND user_id = $2
+	`, gameID, userID).Scan(&m.Role, &perms)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get game member: %w", err)
+	}
+	m.Permissions = toPermissions(perms)
+	return &m, nil
+}
+
+// ListAssistantPermissions returns every assistant in the game with what
+// they may do.
+func (r *Repository) ListAssistantPermissions(ctx context.Context, gameID int64) ([]AssistantPermissions, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT gp.user_id, u.first_name, gp.permissions
+		FROM game_players gp
+		JOIN users u ON u.id = gp.user_id
+		WHERE gp.game_id = $1 AND gp.role = 'assistant'
+		ORDER BY gp.joined_at, gp.user_id
+	`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("list assistants: %w", err)
+	}
+	defer rows.Close()
+
+	out := []AssistantPermissions{}
+	for rows.Next() {
+		var a AssistantPermissions
+		var perms []string
+		if err := rows.Scan(&a.UserID, &a.FirstName, &perms); err != nil {
+			return nil, fmt.Errorf("scan assistant: %w", err)
+		}
+		a.Permissions = toPermissions(perms)
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+// SetAssistantPermissions replaces what an assistant may do. It returns
+// ErrNotFound when the user isn't an assistant in the game.
+func (r *Repository) SetAssistantPermissions(ctx context.Context, gameID, userID int64, perms []Permission) error {
+	for _, p := range perms {
+		if !ValidPermission(p) {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, p)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE game_players
+		SET permissions = $3
+		WHERE game_id = $1 AND user_id = $2 AND role = 'assistant'
+	`, gameID, userID, fromPermissions(perms))
+	if err != nil {
+		return fmt.Errorf("set assistant permissions: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordScore saves the final score. It can be recorded once the game has
+// started and corrected afterwards, but not for a cancelled game.
+func (r *Repository) RecordScore(ctx context.Context, gameID, recordedBy int64, teamA, teamB int) (*GameScore, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	score := GameScore{TeamA: teamA, TeamB: teamB}
+	err := r.db.QueryRow(ctx, `
+		UPDATE games
+		SET score_a = $2, score_b = $3, score_recorded_by = $4, score_recorded_at = NOW()
+		WHERE id = $1
+		  AND status IN ('active', 'completed')
+		  AND start_time <= NOW()
+		RETURNING score_recorded_by, score_recorded_at
+	`, gameID, teamA, teamB, recordedBy).Scan(&score.RecordedBy, &score.RecordedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("record score: %w", err)
+	}
+	return &score, nil
+}
+
+func toPermissions(in []string) []Permission {
+	out := make([]Permission, 0, len(in))
+	for _, p := range in {
+		out = append(out, Permission(p))
+	}
+	return out
+}
+
+func fromPermissions(in []Permission) []string {
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		if !slices.Contains(out, string(p)) {
+			out = append(out, string(p))
+		}
+	}
+	return out
+}
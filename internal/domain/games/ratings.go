@@ -0,0 +1,291 @@
+package games
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RatingWindow is how long after a game is completed its players can rate
+// each other and vote for an MVP.
+const RatingWindow = 48 * time.Hour
+
+var (
+	ErrRatingWindowClosed = errors.New("rating window is closed")
+	ErrNotGamePlayer      = errors.New("user did not play in this game")
+)
+
+// CompletedGame is a game the completion job just closed.
+type CompletedGame struct {
+	ID             int64
+	VenueID        int64
+	AdminID        int64
+	SportType      string
+	EndTime        time.Time
+	RatingClosesAt time.Time
+	// ClosedRequests is how many pending join requests were rejected.
+	ClosedRequests int64
+}
+
+type PlayerRating struct {
+	UserID int64 `json:"user_id" validate:"required,gt=0"`
+	Rating int   `json:"rating" validate:"required,min=1,max=5"`
+}
+
+type PlayerResult struct {
+	UserID       int64    `json:"user_id"`
+	FirstName    string   `json:"first_name"`
+	LastName     string   `json:"last_name"`
+	ProfilePic   *string  `json:"profile_picture_url,omitempty"`
+	AvgRating    *float64 `json:"avg_rating,omitempty"`
+	RatingsCount int      `json:"ratings_count"`
+	MVPVotes     int      `json:"mvp_votes"`
+}
+
+type GameResults struct {
+	GameID         int64          `json:"game_id"`
+	RatingClosesAt *time.Time     `json:"rating_closes_at,omitempty"`
+	RatingOpen     bool           `json:"rating_open"`
+	MVPUserID      *int64         `json:"mvp_user_id,omitempty"`
+	Score          *GameScore     `json:"score,omitempty"`
+	Players        []PlayerResult `json:"players"`
+}
+
+// MarkCompletedGames closes every active game that has ended: the game is
+// flipped to completed, its rating window opens and pending join requests
+// are rejected, all in one transaction.
+func (r *Repository) MarkCompletedGames(ctx context.Context) ([]CompletedGame, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	completed := []CompletedGame{}
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			UPDATE games
+			SET status = 'completed',
+			    rating_closes_at = NOW() + make_interval(hours => $1)
+			WHERE end_time < NOW()
+			  AND status = 'active'
+			RETURNING id, venue_id, admin_id, COALESCE(sport_type, ''), end_time, rating_closes_at
+		`, int(RatingWindow/time.Hour))
+		if err != nil {
+			return fmt.Errorf("failed to update games: %w", err)
+		}
+		ids := []int64{}
+		for rows.Next() {
+			var g CompletedGame
+			if err := rows.Scan(&g.ID, &g.VenueID, &g.AdminID, &g.SportType, &g.EndTime, &g.RatingClosesAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan completed game: %w", err)
+			}
+			completed = append(completed, g)
+			ids = append(ids, g.ID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		rows, err = tx.Query(ctx, `
+			UPDATE game_join_requests
+			SET status = 'rejected'
+			WHERE game_id = ANY($1)
+			  AND status = 'pending'
+			RETURNING game_id
+		`, ids)
+		if err != nil {
+			return fmt.Errorf("close join requests: %w", err)
+		}
+		defer rows.Close()
+
+		closed := map[int64]int64{}
+		for rows.Next() {
+			var gameID int64
+			if err := rows.Scan(&gameID); err != nil {
+				return fmt.Errorf("scan closed request: %w", err)
+			}
+			closed[gameID]++
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+		for i := range completed {
+			completed[i].ClosedRequests = closed[completed[i].ID]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return completed, nil
+}
+
+// checkRatingOpen makes sure the game's rating window is open and that every
+// user in userIDs played in it.
+func checkRatingOpen(ctx context.Context, tx pgx.Tx, gameID int64, userIDs []int64) error {
+	var open bool
+	err := tx.QueryRow(ctx, `
+		SELECT status = 'completed' AND rating_closes_at > NOW()
+		FROM games
+		WHERE id = $1
+	`, gameID).Scan(&open)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("check rating window: %w", err)
+	}
+	if !open {
+		return ErrRatingWindowClosed
+	}
+
+	var players int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT user_id)
+		FROM game_players
+		WHERE game_id = $1 AND user_id = ANY($2)
+	`, gameID, userIDs).Scan(&players)
+	if err != nil {
+		return fmt.Errorf("check game players: %w", err)
+	}
+
+	distinct := map[int64]struct{}{}
+	for _, id := range userIDs {
+		distinct[id] = struct{}{}
+	}
+	if players != len(distinct) {
+		return ErrNotGamePlayer
+	}
+	return nil
+}
+
+// RatePlayers stores raterID's ratings of other players. Rating someone
+// again replaces the earlier score.
+func (r *Repository) RatePlayers(ctx context.Context, gameID, raterID int64, ratings []PlayerRating) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	userIDs := []int64{raterID}
+	for _, rt := range ratings {
+		userIDs = append(userIDs, rt.UserID)
+	}
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if err := checkRatingOpen(ctx, tx, gameID, userIDs); err != nil {
+			return err
+		}
+
+		for _, rt := range ratings {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO game_player_ratings (game_id, rater_id, ratee_id, rating)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (game_id, rater_id, ratee_id)
+				DO UPDATE SET rating = EXCLUDED.rating, updated_at = NOW()
+			`, gameID, raterID, rt.UserID, rt.Rating)
+			if err != nil {
+				return fmt.Errorf("save rating: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// VoteMVP records voterID's MVP pick. Voting again changes the pick.
+func (r *Repository) VoteMVP(ctx context.Context, gameID, voterID, nomineeID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if err := checkRatingOpen(ctx, tx, gameID, []int64{voterID, nomineeID}); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO game_mvp_votes (game_id, voter_id, nominee_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (game_id, voter_id)
+			DO UPDATE SET nominee_id = EXCLUDED.nominee_id, updated_at = NOW()
+		`, gameID, voterID, nomineeID)
+		if err != nil {
+			return fmt.Errorf("save mvp vote: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetGameResults returns each player's average rating and MVP votes. The MVP
+// is the player with the most votes; ties go to the better average rating.
+func (r *Repository) GetGameResults(ctx context.Context, gameID int64) (*GameResults, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res := GameResults{GameID: gameID, Players: []PlayerResult{}}
+	var (
+		scoreA, scoreB  *int
+		scoreBy         *int64
+		scoreRecordedAt *time.Time
+	)
+	err := r.db.QueryRow(ctx, `
+		SELECT rating_closes_at, COALESCE(status = 'completed' AND rating_closes_at > NOW(), FALSE),
+		       score_a, score_b, score_recorded_by, score_recorded_at
+		FROM games
+		WHERE id = $1
+	`, gameID).Scan(&res.RatingClosesAt, &res.RatingOpen, &scoreA, &scoreB, &scoreBy, &scoreRecordedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get game: %w", err)
+	}
+	if scoreA != nil && scoreB != nil && scoreRecordedAt != nil {
+		res.Score = &GameScore{TeamA: *scoreA, TeamB: *scoreB, RecordedBy: scoreBy, RecordedAt: *scoreRecordedAt}
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.first_name, u.last_name, u.profile_picture_url,
+		       rt.avg_rating, COALESCE(rt.ratings_count, 0), COALESCE(mv.votes, 0)
+		FROM game_players gp
+		JOIN users u ON u.id = gp.user_id
+		LEFT JOIN (
+			SELECT ratee_id, AVG(rating)::float8 AS avg_rating, COUNT(*) AS ratings_count
+			FROM game_player_ratings
+			WHERE game_id = $1
+			GROUP BY ratee_id
+		) rt ON rt.ratee_id = gp.user_id
+		LEFT JOIN (
+			SELECT nominee_id, COUNT(*) AS votes
+			FROM game_mvp_votes
+			WHERE game_id = $1
+			GROUP BY nominee_id
+		) mv ON mv.nominee_id = gp.user_id
+		WHERE gp.game_id = $1
+		ORDER BY COALESCE(mv.votes, 0) DESC, rt.avg_rating DESC NULLS LAST, u.id
+	`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("get game results: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p PlayerResult
+		if err := rows.Scan(&p.UserID, &p.FirstName, &p.LastName, &p.ProfilePic, &p.AvgRating, &p.RatingsCount, &p.MVPVotes); err != nil {
+			return nil, fmt.Errorf("scan player result: %w", err)
+		}
+		res.Players = append(res.Players, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	if len(res.Players) > 0 && res.Players[0].MVPVotes > 0 {
+		res.MVPUserID = &res.Players[0].UserID
+	}
+	return &res, nil
+}
@@ -12,7 +12,7 @@ import (
 )
 
 type Store interface {
-	GetGames(ctx context.Context, q GameFilterQuery) ([]GameSummary, error)
+	GetGames(ctx context.Context, q GameFilterQuery) ([]GameSummary, int, error)
 	Create(ctx context.Context, game *Game) (int64, error)
 	GetAdminID(ctx context.Context, gameID int64) (int64, error)
 	GetGameByID(ctx context.Context, gameID int64) (*Game, error)
@@ -32,11 +32,43 @@ type Store interface {
 	AssignAssistant(ctx context.Context, gameID, playerID int64) error
 	CancelGame(ctx context.Context, gameID int64) error
 	GetGameDetailsWithID(ctx context.Context, gameID int64) (*GameDetails, error)
+	GetSeats(ctx context.Context, gameID int64) (*Seats, error)
 	GetUpcomingGamesByVenue(ctx context.Context, venueID int64) ([]GameSummary, error)
 	GetUpcomingGamesByUser(ctx context.Context, userID int64) ([]GameSummary, error)
-	MarkCompletedGames() error
+	MarkCompletedGames(ctx context.Context) ([]CompletedGame, error)
+	SetAutoCancel(ctx context.Context, gameID int64, minPlayers *int, cutoff *time.Time) error
+	AutoCancelUnderfilled(ctx context.Context) ([]AutoCancelledGame, error)
+	AddPlayerOrBench(ctx context.Context, gameID, userID int64) (int, error)
+	RemovePlayer(ctx context.Context, gameID, userID int64) (*int64, error)
+	SetBenchSize(ctx context.Context, gameID int64, size int) error
+	GetBenchPlayerIDs(ctx context.Context, gameID int64) ([]int64, error)
+	SetJoinPolicy(ctx context.Context, gameID int64, policy string) error
+	JoinIfSeat(ctx context.Context, gameID, userID int64) (bool, error)
+	ExpireJoinRequests(ctx context.Context) ([]ExpiredJoinRequest, error)
+
+	//... Invitations
+
+	CreateInviteLink(ctx context.Context, inv *Invitation) error
+	InviteUsers(ctx context.Context, gameID, invitedBy int64, userIDs []int64) ([]int64, error)
+	ListInvitations(ctx context.Context, gameID int64) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, gameID, invitationID int64) error
+	UserInvitations(ctx context.Context, userID int64) ([]UserInvitation, error)
+	AcceptInvitation(ctx context.Context, invitationID int64, nonce *int, userID int64) (*AcceptedInvitation, error)
+	DeclineInvitation(ctx context.Context, invitationID, userID int64) error
+	GetMember(ctx context.Context, gameID, userID int64) (*Member, error)
+	ListAssistantPermissions(ctx context.Context, gameID int64) ([]AssistantPermissions, error)
+	SetAssistantPermissions(ctx context.Context, gameID, userID int64, perms []Permission) error
+	UpdateDetails(ctx context.Context, gameID, changedBy int64, u GameDetailsUpdate) (*GameEdit, error)
+	ListChanges(ctx context.Context, gameID int64) ([]GameChange, error)
+	RecordScore(ctx context.Context, gameID, recordedBy int64, teamA, teamB int) (*GameScore, error)
 	GetAllGamePlayerIDs(ctx context.Context, gameID int64) ([]int64, error)
 
+	//... Ratings and MVP
+
+	RatePlayers(ctx context.Context, gameID, raterID int64, ratings []PlayerRating) error
+	VoteMVP(ctx context.Context, gameID, voterID, nomineeID int64) error
+	GetGameResults(ctx context.Context, gameID int64) (*GameResults, error)
+
 	//... Shortlisted games
 
 	AddShortlist(ctx context.Context, userID, gameID int64) error
@@ -59,10 +91,20 @@ func (r *Repository) Create(ctx context.Context, game *Game) (int64, error) {
 	query := `
 		INSERT INTO games (
 			sport_type, price, format, venue_id, admin_id, max_players, game_level,
-			start_time, end_time, visibility, instruction, status, booking_status, match_full
+			start_time, end_time, visibility, instruction, status, booking_status, match_full,
+			min_players, auto_cancel_at, booking_id, bench_size,
+			safety_info, emergency_contact_name, emergency_contact_phone, join_policy
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-		RETURNING id, created_at, updated_at
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+			NULLIF($19, ''),
+			-- Without a contact of their own, players get the venue's.
+			CASE WHEN NULLIF($20, '') IS NULL AND NULLIF($21, '') IS NULL
+			     THEN (SELECT name FROM venues WHERE id = $4)
+			     ELSE NULLIF($20, '') END,
+			COALESCE(NULLIF($21, ''), (SELECT NULLIF(phone_number, '') FROM venues WHERE id = $4)),
+			COALESCE(NULLIF($22, ''), 'manual')
+		)
+		RETURNING id, emergency_contact_name, emergency_contact_phone, join_policy, created_at, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
@@ -84,8 +126,19 @@ func (r *Repository) Create(ctx context.Context, game *Game) (int64, error) {
 		game.Status,
 		game.BookingStatus,
 		game.MatchFull,
+		game.MinPlayers,
+		game.AutoCancelAt,
+		game.BookingID,
+		game.BenchSize,
+		game.SafetyInfo,
+		game.EmergencyContactName,
+		game.EmergencyContactPhone,
+		game.JoinPolicy,
 	).Scan(
 		&game.ID,
+		&game.EmergencyContactName,
+		&game.EmergencyContactPhone,
+		&game.JoinPolicy,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 	)
@@ -118,7 +171,9 @@ func (r *Repository) GetGameByID(ctx context.Context, gameID int64) (*Game, erro
 	query := `
 		SELECT id, sport_type, price, format, venue_id, admin_id, max_players, 
 			   game_level, start_time, end_time, visibility, instruction, status, 
-			   booking_status, match_full, created_at, updated_at
+			   booking_status, match_full, min_players, auto_cancel_at, booking_id,
+			   cancel_reason, bench_size, safety_info, emergency_contact_name,
+			   emergency_contact_phone, join_policy, created_at, updated_at
 		FROM games 
 		WHERE id = $1
 	`
@@ -143,6 +198,15 @@ func (r *Repository) GetGameByID(ctx context.Context, gameID int64) (*Game, erro
 		&game.Status,
 		&game.BookingStatus,
 		&game.MatchFull,
+		&game.MinPlayers,
+		&game.AutoCancelAt,
+		&game.BookingID,
+		&game.CancelReason,
+		&game.BenchSize,
+		&game.SafetyInfo,
+		&game.EmergencyContactName,
+		&game.EmergencyContactPhone,
+		&game.JoinPolicy,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 	)
@@ -356,6 +420,33 @@ func (r *Repository) GetJoinRequest(ctx context.Context, gameID, userID int64) (
 	return &req, nil
 }
 
+// GetSeats is a single indexed lookup, cheap enough to poll every few
+// seconds per viewer.
+func (r *Repository) GetSeats(ctx context.Context, gameID int64) (*Seats, error) {
+	query := `
+		SELECT g.id, g.max_players, g.match_full, g.status,
+		       (SELECT COUNT(*) FROM game_players gp WHERE gp.game_id = g.id),
+		       (SELECT COUNT(*) FROM game_join_requests jr WHERE jr.game_id = g.id AND jr.status = 'pending')
+		FROM games g
+		WHERE g.id = $1
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var s Seats
+	err := r.db.QueryRow(ctx, query, gameID).Scan(
+		&s.GameID, &s.MaxPlayers, &s.MatchFull, &s.Status, &s.CurrentPlayers, &s.PendingRequests,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get game seats: %w", err)
+	}
+	s.SeatsLeft = max(s.MaxPlayers-s.CurrentPlayers, 0)
+	return &s, nil
+}
+
 func (r *Repository) GetPlayerCount(ctx context.Context, gameID int) (int, error) {
 	query := `
 	 SELECT COUNT(*) 
@@ -468,10 +559,10 @@ func (r *Repository) GetGamePlayers(ctx context.Context, gameID int64) ([]*users
 
 func (r *Repository) AssignAssistant(ctx context.Context, gameID, playerID int64) error {
 
-	// Update player's role to 'assistant'
+	// Update player's role to 'assistant', starting from the default permissions
 	query := `
 		UPDATE game_players 
-		SET role = 'assistant' 
+		SET role = 'assistant', permissions = DEFAULT
 		WHERE game_id = $1 AND user_id = $2 AND role = 'player'
 	`
 	res, err := r.db.Exec(ctx, query, gameID, playerID)
@@ -489,7 +580,8 @@ func (r *Repository) AssignAssistant(ctx context.Context, gameID, playerID int64
 }
 
 // GetGames queries the database for games that match the provided filters.
-func (r *Repository) GetGames(ctx context.Context, q GameFilterQuery) ([]GameSummary, error) {
+// The count is of all matching games, ignoring limit and offset.
+func (r *Repository) GetGames(ctx context.Context, q GameFilterQuery) ([]GameSummary, int, error) {
 	// build the base of your SQL once
 	baseQuery := `
 SELECT 
@@ -521,11 +613,12 @@ SELECT
     g.match_full,
     g.status,
     ST_Y(v.location::geometry) AS venue_lat,
-    ST_X(v.location::geometry) AS venue_lon
+    ST_X(v.location::geometry) AS venue_lon,
+    COUNT(*) OVER()::INT AS total_count
 FROM games g
 JOIN venues v ON g.venue_id = v.id
 JOIN users u ON g.admin_id = u.id
-WHERE 1 = 1
+WHERE NOT v.is_demo
   AND ($1::varchar IS NULL OR g.sport_type = $1)
   AND ($2::varchar IS NULL OR g.game_level = $2)
   AND ($3::int IS NULL OR g.venue_id = $3)
@@ -540,6 +633,11 @@ WHERE 1 = 1
            ST_MakePoint($11, $12)::geography, 
            $10 * 1000
   ))
+  AND ($15::bigint IS NULL OR NOT EXISTS (
+           SELECT 1 FROM blocked_users b
+           WHERE (b.blocker_id = $15 AND b.blocked_id = g.admin_id)
+              OR (b.blocker_id = g.admin_id AND b.blocked_id = $15)
+  ))
 ORDER BY g.start_time 
 `
 
@@ -567,13 +665,15 @@ LIMIT $13 OFFSET $14
 		q.UserLat,                // $12
 		q.Limit,                  // $13
 		q.Offset,                 // $14
+		nullIfZero64(q.ViewerID), // $15
 	)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
 	var games []GameSummary
+	total := 0
 	for rows.Next() {
 		var g GameSummary
 		if err := rows.Scan(
@@ -595,13 +695,18 @@ LIMIT $13 OFFSET $14
 			&g.Status,
 			&g.VenueLat,
 			&g.VenueLon,
+			&total,
 		); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		games = append(games, g)
 	}
 
-	return games, nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return games, total, nil
 }
 
 // Helper functions to return nil if the value is the default.
@@ -619,6 +724,13 @@ func nullIfZero(i int) interface{} {
 	return i
 }
 
+func nullIfZero64(i int64) interface{} {
+	if i == 0 {
+		return nil
+	}
+	return i
+}
+
 func nullTime(t time.Time) interface{} {
 	if t.IsZero() {
 		return nil
@@ -737,11 +849,38 @@ SELECT
 		),
 		'{}'
 	)                   AS requested_player_ids,
+	COALESCE(
+		(
+			SELECT array_agg(gi.invitee_id)
+			FROM game_invitations gi
+			WHERE gi.game_id = g.id
+			  AND gi.invitee_id IS NOT NULL
+			  AND gi.status = 'pending'
+		),
+		'{}'
+	)                   AS invited_player_ids,
+	(SELECT COUNT(*) FROM game_invitations gi WHERE gi.game_id = g.id AND gi.invitee_id IS NOT NULL AND gi.status = 'pending')  AS invitations_pending,
+	(SELECT COUNT(*) FROM game_invitations gi WHERE gi.game_id = g.id AND gi.invitee_id IS NOT NULL AND gi.status = 'accepted') AS invitations_accepted,
+	(SELECT COUNT(*) FROM game_invitations gi WHERE gi.game_id = g.id AND gi.invitee_id IS NOT NULL AND gi.status = 'declined') AS invitations_declined,
+	(SELECT COALESCE(SUM(gi.uses), 0)::int FROM game_invitations gi WHERE gi.game_id = g.id AND gi.invitee_id IS NULL)           AS invite_link_joins,
+	g.bench_size,
+	COALESCE(
+		(
+			SELECT array_agg(gb.user_id ORDER BY gb.joined_at, gb.id)
+			FROM game_bench gb
+			WHERE gb.game_id = g.id
+		),
+		'{}'
+	)                   AS bench_player_ids,
 					    g.booking_status,
     g.match_full,
 	g.status,
 	ST_Y(v.location::geometry) AS venue_lat,
-	ST_X(v.location::geometry) AS venue_lon
+	ST_X(v.location::geometry) AS venue_lon,
+	g.safety_info,
+	g.emergency_contact_name,
+	g.emergency_contact_phone,
+	g.join_policy
 FROM games g
 JOIN venues v ON g.venue_id = v.id
 JOIN users u ON g.admin_id = u.id
@@ -770,11 +909,22 @@ WHERE g.id = $1
 		&gd.PlayerImages,
 		&gd.PlayerIDs,
 		&gd.RequestedPlayerIDs,
+		&gd.InvitedPlayerIDs,
+		&gd.Invitations.Pending,
+		&gd.Invitations.Accepted,
+		&gd.Invitations.Declined,
+		&gd.Invitations.LinkJoins,
+		&gd.BenchSize,
+		&gd.BenchPlayerIDs,
 		&gd.BookingStatus,
 		&gd.MatchFull,
 		&gd.Status,
 		&gd.VenueLat,
 		&gd.VenueLon,
+		&gd.SafetyInfo,
+		&gd.EmergencyContactName,
+		&gd.EmergencyContactPhone,
+		&gd.JoinPolicy,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -961,25 +1111,6 @@ ORDER BY g.start_time ASC
 	return games, nil
 }
 
-func (r *Repository) MarkCompletedGames() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	query := `
-		UPDATE games
-		SET status = 'completed'
-		WHERE end_time < NOW()
-		  AND status = 'active'
-	`
-
-	ct, err := r.db.Exec(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to update games: %w", err)
-	}
-	fmt.Printf("Marked %d games as completed at %s\n", ct.RowsAffected(), time.Now().Format(time.RFC1123))
-	return nil
-}
-
 // AddShortlist adds a game to the user's shortlist.
 func (r *Repository) AddShortlist(ctx context.Context, userID, gameID int64) error {
 	query := `
@@ -2,9 +2,6 @@ package games
 
 import (
 	"errors"
-	"fmt"
-	"net/http"
-	"strconv"
 	"time"
 )
 
@@ -42,9 +39,21 @@ type Game struct {
 	Instruction   *string       `json:"instruction,omitempty"` // Game instructions (nullable)
 	Status        string        `json:"status"`                // Game status (active, cancelled, completed)
 	BookingStatus BookingStatus `json:"booking_status"`
-	MatchFull     bool          `json:"match_full"` // Whether the game is full
-	CreatedAt     time.Time     `json:"created_at"` // Timestamp when the game was created
-	UpdatedAt     time.Time     `json:"updated_at"` // Timestamp when the game was last updated
+	MatchFull     bool          `json:"match_full"`               // Whether the game is full
+	MinPlayers    *int          `json:"min_players,omitempty"`    // Auto-cancel below this many players (nullable)
+	AutoCancelAt  *time.Time    `json:"auto_cancel_at,omitempty"` // When the min_players check runs (nullable)
+	BookingID     *int64        `json:"booking_id,omitempty"`     // Venue booking holding the slot (nullable)
+	CancelReason  *string       `json:"cancel_reason,omitempty"`  // Why the game was cancelled (nullable)
+	BenchSize     int           `json:"bench_size"`               // Players allowed to wait beyond max_players
+	JoinPolicy    string        `json:"join_policy"`              // auto_accept, manual or invite_only
+	CreatedAt     time.Time     `json:"created_at"`               // Timestamp when the game was created
+	UpdatedAt     time.Time     `json:"updated_at"`               // Timestamp when the game was last updated
+
+	// Shown to players and in the start reminder; the contact defaults to
+	// the venue's phone.
+	SafetyInfo            *string `json:"safety_info,omitempty"`
+	EmergencyContactName  *string `json:"emergency_contact_name,omitempty"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone,omitempty"`
 }
 
 // GameRequest represents a request to join a game in the system
@@ -124,27 +133,48 @@ type GameWithVenue struct {
 
 // GameDetails holds full info for a single game, including admin, booking and player lists.
 type GameDetails struct {
-	GameID             int64         `json:"game_id"`
-	VenueID            int64         `json:"venue_id"`
-	VenueName          string        `json:"venue_name"`
-	SportType          string        `json:"sport_type"`
-	Price              *int          `json:"price,omitempty"`
-	Format             *string       `json:"format,omitempty"`
-	GameLevel          *string       `json:"game_level,omitempty"`
-	AdminID            int64         `json:"admin_id"`
-	GameAdminName      string        `json:"game_admin_name"`
-	StartTime          time.Time     `json:"start_time"`
-	EndTime            time.Time     `json:"end_time"`
-	MaxPlayers         int           `json:"max_players"`
-	CurrentPlayer      int           `json:"current_player"`
-	PlayerImages       []string      `json:"player_images"`
-	PlayerIDs          []int64       `json:"player_ids"`           // all joined player user IDs
-	RequestedPlayerIDs []int64       `json:"requested_player_ids"` // pending request user IDs
-	BookingStatus      BookingStatus `json:"booking_status"`
-	MatchFull          bool          `json:"match_full"`
-	Status             string        `json:"status"`
-	VenueLat           float64       `json:"venue_lat"`
-	VenueLon           float64       `json:"venue_lon"`
+	GameID             int64             `json:"game_id"`
+	VenueID            int64             `json:"venue_id"`
+	VenueName          string            `json:"venue_name"`
+	SportType          string            `json:"sport_type"`
+	Price              *int              `json:"price,omitempty"`
+	Format             *string           `json:"format,omitempty"`
+	GameLevel          *string           `json:"game_level,omitempty"`
+	AdminID            int64             `json:"admin_id"`
+	GameAdminName      string            `json:"game_admin_name"`
+	StartTime          time.Time         `json:"start_time"`
+	EndTime            time.Time         `json:"end_time"`
+	MaxPlayers         int               `json:"max_players"`
+	CurrentPlayer      int               `json:"current_player"`
+	PlayerImages       []string          `json:"player_images"`
+	PlayerIDs          []int64           `json:"player_ids"`           // all joined player user IDs
+	RequestedPlayerIDs []int64           `json:"requested_player_ids"` // pending request user IDs
+	InvitedPlayerIDs   []int64           `json:"invited_player_ids"`   // users with a pending invitation
+	Invitations        InvitationSummary `json:"invitations"`
+	BenchSize          int               `json:"bench_size"`
+	BenchPlayerIDs     []int64           `json:"bench_player_ids"` // bench user IDs, next to be promoted first
+	JoinPolicy         string            `json:"join_policy"`
+	BookingStatus      BookingStatus     `json:"booking_status"`
+	MatchFull          bool              `json:"match_full"`
+	Status             string            `json:"status"`
+	VenueLat           float64           `json:"venue_lat"`
+	VenueLon           float64           `json:"venue_lon"`
+
+	SafetyInfo            *string `json:"safety_info,omitempty"`
+	EmergencyContactName  *string `json:"emergency_contact_name,omitempty"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone,omitempty"`
+}
+
+// Seats is the live occupancy of a game: the part of GameDetails the game
+// screen polls for.
+type Seats struct {
+	GameID          int64  `json:"game_id"`
+	MaxPlayers      int    `json:"max_players"`
+	CurrentPlayers  int    `json:"current_players"`
+	SeatsLeft       int    `json:"seats_left"`
+	PendingRequests int    `json:"pending_requests"`
+	MatchFull       bool   `json:"match_full"`
+	Status          string `json:"status"`
 }
 
 type GameRequestWithUser struct {
@@ -173,144 +203,34 @@ type ShortlistedGameDetail struct {
 	VenueAddress string `json:"venue_address"`
 }
 
+// GameFilterQuery is the query string of the game list, bound with
+// params.Bind. Defaults are set before binding.
 type GameFilterQuery struct {
-	Limit         int            `validate:"gte=1"`          // Maximum number of results to return
-	Offset        int            `validate:"gte=0"`          // Pagination offset
-	Sort          string         `validate:"oneof=asc desc"` // Sorting order for start_time
-	SportType     string         // Filter by sport type (e.g., "basketball")
-	GameLevel     string         // Filter by game level (e.g., "intermediate")
-	VenueID       int            // Filter by a specific venue id
-	BookingStatus *BookingStatus // Filter based on booking status (nil = no filter)
-	Status        *string        `validate:"omitempty,oneof=active cancelled completed"`
+	Limit     int    `query:"limit" validate:"gte=1"`         // Maximum number of results to return
+	Offset    int    `query:"offset" validate:"gte=0"`        // Pagination offset
+	Sort      string `query:"sort" validate:"oneof=asc desc"` // Sorting order for start_time
+	SportType string `query:"sport_type"`                     // Filter by sport type (e.g., "basketball")
+	GameLevel string `query:"game_level"`                     // Filter by game level (e.g., "intermediate")
+	VenueID   int    `query:"venue_id"`                       // Filter by a specific venue id
+
+	// Status filtering; nil = no filter
+	BookingStatus *BookingStatus `query:"booking_status" validate:"omitempty,oneof=pending requested booked rejected cancelled"`
+	Status        *string        `query:"status" validate:"omitempty,oneof=active cancelled completed"`
 
 	// Location-based filtering
-	UserLat float64 // User's latitude for radius filter
-	UserLon float64 // User's longitude for radius filter
-	Radius  int     // Radius in kilometers; 0 means no radius filtering
+	UserLat float64 `query:"lat"`    // User's latitude for radius filter
+	UserLon float64 `query:"lon"`    // User's longitude for radius filter
+	Radius  int     `query:"radius"` // Radius in kilometers; 0 means no radius filtering
 
 	// Time filtering
-	StartAfter time.Time // Return games starting after this time
-	EndBefore  time.Time // Return games ending before this time
+	StartAfter time.Time `query:"start_after"` // Return games starting after this time
+	EndBefore  time.Time `query:"end_before"`  // Return games ending before this time
 
 	// Price filtering
-	MinPrice int
-	MaxPrice int
-}
-
-// Parse extracts query parameters from the request URL and populates the GameFilterQuery.
-func (q GameFilterQuery) Parse(r *http.Request) (GameFilterQuery, error) {
-	params := r.URL.Query()
-
-	if sportType := params.Get("sport_type"); sportType != "" {
-		q.SportType = sportType
-	}
-
-	if gameLevel := params.Get("game_level"); gameLevel != "" {
-		q.GameLevel = gameLevel
-	}
-
-	if venueIDStr := params.Get("venue_id"); venueIDStr != "" {
-		venueID, err := strconv.Atoi(venueIDStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid venue_id: %w", err)
-		}
-		q.VenueID = venueID
-	}
-
-	if status := params.Get("booking_status"); status != "" {
-		bs := BookingStatus(status) // convert string -> BookingStatus
-		switch bs {
-		case BookingPending, BookingRequested, BookingBooked, BookingRejected, BookingCancelled:
-			q.BookingStatus = &bs
-		default:
-			return q, fmt.Errorf("invalid booking_status value: %s", status)
-		}
-	}
-
-	if status := params.Get("status"); status != "" {
-		// you may want to validate it's one of your enum values here
-		q.Status = &status
-	}
-
-	if latStr := params.Get("lat"); latStr != "" {
-		lat, err := strconv.ParseFloat(latStr, 64)
-		if err != nil {
-			return q, fmt.Errorf("invalid lat value: %w", err)
-		}
-		q.UserLat = lat
-	}
-
-	if lonStr := params.Get("lon"); lonStr != "" {
-		lon, err := strconv.ParseFloat(lonStr, 64)
-		if err != nil {
-			return q, fmt.Errorf("invalid lon value: %w", err)
-		}
-		q.UserLon = lon
-	}
-
-	if radiusStr := params.Get("radius"); radiusStr != "" {
-		radius, err := strconv.Atoi(radiusStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid radius value: %w", err)
-		}
-		q.Radius = radius
-	}
-
-	if startAfterStr := params.Get("start_after"); startAfterStr != "" {
-		startAfter, err := time.Parse(time.RFC3339, startAfterStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid start_after value: %w", err)
-		}
-		q.StartAfter = startAfter
-	}
-
-	if endBeforeStr := params.Get("end_before"); endBeforeStr != "" {
-		endBefore, err := time.Parse(time.RFC3339, endBeforeStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid end_before value: %w", err)
-		}
-		q.EndBefore = endBefore
-	}
-
-	if minPriceStr := params.Get("min_price"); minPriceStr != "" {
-		minPrice, err := strconv.Atoi(minPriceStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid min_price: %w", err)
-		}
-		q.MinPrice = minPrice
-	}
-
-	if maxPriceStr := params.Get("max_price"); maxPriceStr != "" {
-		maxPrice, err := strconv.Atoi(maxPriceStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid max_price: %w", err)
-		}
-		q.MaxPrice = maxPrice
-	}
-
-	// Optional: Allow overriding the default pagination values.
-	if limitStr := params.Get("limit"); limitStr != "" {
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid limit: %w", err)
-		}
-		q.Limit = limit
-	}
-
-	if offsetStr := params.Get("offset"); offsetStr != "" {
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil {
-			return q, fmt.Errorf("invalid offset: %w", err)
-		}
-		q.Offset = offset
-	}
-
-	if sort := params.Get("sort"); sort != "" {
-		if sort != "asc" && sort != "desc" {
-			return q, fmt.Errorf("invalid sort value: must be 'asc' or 'desc'")
-		}
-		q.Sort = sort
-	}
+	MinPrice int `query:"min_price"`
+	MaxPrice int `query:"max_price"`
 
-	return q, nil
+	// ViewerID hides games whose admin has a block with the viewer; 0 for
+	// anonymous callers.
+	ViewerID int64
 }
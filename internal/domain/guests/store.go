@@ -0,0 +1,259 @@
+package guests
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/domain/bookings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateOTP(ctx context.Context, phone, codeHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO guest_otps (phone, code_hash, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+		ON CONFLICT (phone) DO UPDATE
+		SET code_hash = EXCLUDED.code_hash,
+		    attempts = 0,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = NOW()
+		WHERE guest_otps.created_at <= NOW() - make_interval(secs => $4)
+	`, phone, codeHash, OTPTTL.Seconds(), OTPResendAfter.Seconds())
+	if err != nil {
+		return fmt.Errorf("create guest otp: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOTPTooSoon
+	}
+	return nil
+}
+
+func (r *Repository) VerifyOTP(ctx context.Context, phone, codeHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var match bool
+	err := r.db.QueryRow(ctx, `
+		UPDATE guest_otps
+		SET attempts = attempts + 1
+		WHERE phone = $1 AND expires_at > NOW() AND attempts < $3
+		RETURNING code_hash = $2
+	`, phone, codeHash, MaxOTPAttempts).Scan(&match)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOTPInvalid
+		}
+		return fmt.Errorf("verify guest otp: %w", err)
+	}
+	if !match {
+		return ErrOTPInvalid
+	}
+
+	if _, err := r.db.Exec(ctx, `DELETE FROM guest_otps WHERE phone = $1`, phone); err != nil {
+		return fmt.Errorf("use guest otp: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) UpsertGuest(ctx context.Context, phone, firstName, lastName string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Random bytes rather than a bcrypt hash, so no password ever matches.
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO users (first_name, last_name, email, phone, password, is_guest)
+		VALUES ($1, $2, $3, $4, $5, TRUE)
+		ON CONFLICT (phone) DO UPDATE
+		SET first_name = EXCLUDED.first_name,
+		    last_name = EXCLUDED.last_name,
+		    updated_at = NOW()
+		WHERE users.is_guest
+		RETURNING id
+	`, firstName, lastName, Email(phone), phone, password).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrPhoneRegistered
+		}
+		return 0, fmt.Errorf("upsert guest: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Repository) CreateBooking(ctx context.Context, b *bookings.Booking, d *Deposit, tokenHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		// Lock the guest so two requests can't both pass the check below.
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, b.UserID); err != nil {
+			return fmt.Errorf("lock guest: %w", err)
+		}
+
+		var open bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM bookings
+				WHERE user_id = $1
+				  AND status IN ('pending', 'confirmed')
+				  AND end_time > NOW()
+			)
+		`, b.UserID).Scan(&open)
+		if err != nil {
+			return fmt.Errorf("check open guest bookings: %w", err)
+		}
+		if open {
+			return ErrOpenBooking
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO bookings (venue_id, facility_id, user_id, start_time, end_time, total_price, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at, updated_at
+		`, b.VenueID, b.FacilityID, b.UserID, b.StartTime, b.EndTime, b.TotalPrice, b.Status).
+			Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("create guest booking: %w", err)
+		}
+
+		d.BookingID = b.ID
+		d.UserID = b.UserID
+		err = tx.QueryRow(ctx, `
+			INSERT INTO booking_deposits (booking_id, user_id, amount, token_hash, expires_at)
+			VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+			RETURNING status, expires_at, created_at
+		`, d.BookingID, d.UserID, d.Amount, tokenHash, HoldWindow.Seconds()).
+			Scan(&d.Status, &d.ExpiresAt, &d.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("create booking deposit: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) GetDeposit(ctx context.Context, tokenHash string) (*Deposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var d Deposit
+	err := r.db.QueryRow(ctx, `
+		SELECT booking_id, user_id, amount, status, provider, provider_ref, expires_at, paid_at, created_at
+		FROM booking_deposits
+		WHERE token_hash = $1
+	`, tokenHash).Scan(&d.BookingID, &d.UserID, &d.Amount, &d.Status, &d.Provider, &d.ProviderRef,
+		&d.ExpiresAt, &d.PaidAt, &d.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDepositNotFound
+		}
+		return nil, fmt.Errorf("get booking deposit: %w", err)
+	}
+	return &d, nil
+}
+
+func (r *Repository) SetDepositProvider(ctx context.Context, bookingID int64, provider, ref string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE booking_deposits
+		SET provider = $2, provider_ref = $3, updated_at = NOW()
+		WHERE booking_id = $1 AND status = 'pending'
+	`, bookingID, provider, ref)
+	if err != nil {
+		return fmt.Errorf("set deposit provider: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDepositNotActive
+	}
+	return nil
+}
+
+func (r *Repository) MarkDepositPaid(ctx context.Context, bookingID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// An expired deposit can still be paid: the gateway took the money
+	// after the hold ran out, and the caller refunds it.
+	tag, err := r.db.Exec(ctx, `
+		UPDATE booking_deposits
+		SET status = 'paid', paid_at = NOW(), updated_at = NOW()
+		WHERE booking_id = $1 AND status <> 'paid'
+	`, bookingID)
+	if err != nil {
+		return false, fmt.Errorf("mark deposit paid: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *Repository) ExpireUnpaid(ctx context.Context, limit int) ([]Expired, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	expired := []Expired{}
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if err := bookings.Attribute(ctx, tx, 0, bookings.SourceExpiry); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `
+			WITH due AS (
+				SELECT booking_id
+				FROM booking_deposits
+				WHERE status = 'pending' AND expires_at <= NOW()
+				ORDER BY expires_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			), lapsed AS (
+				UPDATE booking_deposits d
+				SET status = 'expired', updated_at = NOW()
+				FROM due
+				WHERE d.booking_id = due.booking_id
+				RETURNING d.booking_id
+			)
+			UPDATE bookings b
+			SET status = 'canceled', updated_at = NOW()
+			FROM lapsed, users u
+			WHERE b.id = lapsed.booking_id AND u.id = b.user_id AND b.status = 'pending'
+			RETURNING b.id, b.venue_id, b.user_id, u.phone, b.start_time
+		`, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var e Expired
+			if err := rows.Scan(&e.BookingID, &e.VenueID, &e.UserID, &e.Phone, &e.StartTime); err != nil {
+				return err
+			}
+			expired = append(expired, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("expire unpaid deposits: %w", err)
+	}
+	return expired, nil
+}
@@ -0,0 +1,103 @@
+package guests
+
+import (
+	"context"
+	"errors"
+	"khel/internal/domain/bookings"
+	"strings"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+const (
+	// OTPTTL is how long a texted code can be used.
+	OTPTTL = 5 * time.Minute
+	// OTPResendAfter is how long a phone waits before it can get a new code.
+	OTPResendAfter = time.Minute
+	// MaxOTPAttempts is how many guesses one code allows.
+	MaxOTPAttempts = 5
+	// HoldWindow is how long a guest booking waits for its deposit.
+	HoldWindow = 15 * time.Minute
+
+	// EmailDomain holds the placeholder addresses of guest users. It is
+	// reserved (RFC 6761), so nothing is ever delivered there.
+	EmailDomain = "guest.invalid"
+)
+
+const (
+	DepositPending = "pending"
+	DepositPaid    = "paid"
+	DepositExpired = "expired"
+)
+
+var (
+	ErrOTPInvalid       = errors.New("code is wrong or has expired")
+	ErrOTPTooSoon       = errors.New("a code was sent to this phone a moment ago")
+	ErrPhoneRegistered  = errors.New("this phone belongs to an account; log in to book")
+	ErrOpenBooking      = errors.New("this phone already has an upcoming guest booking")
+	ErrDepositNotFound  = errors.New("deposit not found")
+	ErrDepositNotActive = errors.New("deposit can no longer be paid")
+	ErrDepositPaid      = errors.New("deposit is already paid")
+)
+
+// Email is the placeholder address of the guest user for phone.
+func Email(phone string) string {
+	return phone + "@" + EmailDomain
+}
+
+// IsGuestEmail reports whether email is a guest's placeholder address.
+func IsGuestEmail(email string) bool {
+	return strings.HasSuffix(email, "@"+EmailDomain)
+}
+
+// Deposit is what a guest pays up front to hold a pending booking.
+type Deposit struct {
+	BookingID   int64      `json:"booking_id"`
+	UserID      int64      `json:"-"`
+	Amount      int        `json:"amount"`
+	Status      string     `json:"status"`
+	Provider    *string    `json:"provider,omitempty"`
+	ProviderRef *string    `json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Expired is a guest booking canceled because its deposit wasn't paid in
+// time.
+type Expired struct {
+	BookingID int64
+	VenueID   int64
+	UserID    int64
+	Phone     string
+	StartTime time.Time
+}
+
+type Store interface {
+	// CreateOTP stores codeHash as phone's code, replacing an older one.
+	// ErrOTPTooSoon when the last code went out less than OTPResendAfter
+	// ago.
+	CreateOTP(ctx context.Context, phone, codeHash string) error
+	// VerifyOTP uses up phone's code if codeHash matches it. Every guess
+	// counts against MaxOTPAttempts.
+	VerifyOTP(ctx context.Context, phone, codeHash string) error
+	// UpsertGuest returns the guest user for phone, creating it or
+	// updating its name. ErrPhoneRegistered when the phone belongs to a
+	// full account.
+	UpsertGuest(ctx context.Context, phone, firstName, lastName string) (int64, error)
+	// CreateBooking adds the guest's pending booking and the deposit
+	// holding it, unless the guest already has one that hasn't ended
+	// (ErrOpenBooking).
+	CreateBooking(ctx context.Context, b *bookings.Booking, d *Deposit, tokenHash string) error
+	// GetDeposit finds a deposit by the hash of its checkout token.
+	GetDeposit(ctx context.Context, tokenHash string) (*Deposit, error)
+	SetDepositProvider(ctx context.Context, bookingID int64, provider, ref string) error
+	// MarkDepositPaid records the payment. It reports false when the
+	// deposit was already paid.
+	MarkDepositPaid(ctx context.Context, bookingID int64) (bool, error)
+	// ExpireUnpaid cancels up to limit guest bookings still pending with
+	// their deposit unpaid past its deadline. Deposits of bookings the
+	// owner confirmed anyway just expire.
+	ExpireUnpaid(ctx context.Context, limit int) ([]Expired, error)
+}
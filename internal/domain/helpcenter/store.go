@@ -0,0 +1,311 @@
+package helpcenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func pgCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// ---------- Categories ----------
+
+const categoryColumns = `c.id, c.name, c.slug, c.description, c.position, c.created_at, c.updated_at`
+
+func scanCategory(row pgx.Row, c *Category) error {
+	return row.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.Position, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func (r *Repository) ListCategories(ctx context.Context) ([]Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		SELECT ` + categoryColumns + `,
+		       COUNT(a.id) FILTER (WHERE a.is_published) AS article_count
+		FROM help_categories c
+		LEFT JOIN help_articles a ON a.category_id = c.id
+		GROUP BY c.id
+		ORDER BY c.position, c.name
+	`
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list help categories: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Category{}
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.Position, &c.CreatedAt, &c.UpdatedAt, &c.ArticleCount); err != nil {
+			return nil, fmt.Errorf("scan help category: %w", err)
+		}
+		list = append(list, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) CreateCategory(ctx context.Context, c *Category) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		INSERT INTO help_categories AS c (name, slug, description, position)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + categoryColumns
+	if err := scanCategory(r.db.QueryRow(ctx, q, c.Name, c.Slug, c.Description, c.Position), c); err != nil {
+		if pgCode(err) == "23505" {
+			return ErrSlugTaken
+		}
+		return fmt.Errorf("create help category: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) UpdateCategory(ctx context.Context, id int64, upd UpdateCategory) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		UPDATE help_categories AS c
+		SET name = COALESCE($2, name),
+		    slug = COALESCE($3, slug),
+		    description = COALESCE($4, description),
+		    position = COALESCE($5, position),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + categoryColumns
+
+	var c Category
+	if err := scanCategory(r.db.QueryRow(ctx, q, id, upd.Name, upd.Slug, upd.Description, upd.Position), &c); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		if pgCode(err) == "23505" {
+			return nil, ErrSlugTaken
+		}
+		return nil, fmt.Errorf("update help category: %w", err)
+	}
+	return &c, nil
+}
+
+func (r *Repository) DeleteCategory(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM help_categories WHERE id = $1`, id)
+	if err != nil {
+		// articles reference the category with ON DELETE RESTRICT
+		if pgCode(err) == "23503" {
+			return ErrCategoryInUse
+		}
+		return fmt.Errorf("delete help category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// ---------- Articles ----------
+
+const articleColumns = `
+	a.id, a.category_id, c.slug, a.title, a.slug, a.body_markdown, a.is_published,
+	a.position, a.published_at, a.created_at, a.updated_at
+`
+
+func scanArticle(row pgx.Row, a *Article) error {
+	return row.Scan(
+		&a.ID, &a.CategoryID, &a.CategorySlug, &a.Title, &a.Slug, &a.BodyMarkdown, &a.IsPublished,
+		&a.Position, &a.PublishedAt, &a.CreatedAt, &a.UpdatedAt,
+	)
+}
+
+func (r *Repository) ListArticles(ctx context.Context, f ArticleFilter, limit, offset int) ([]ArticleSummary, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// With a search term, rank by relevance and highlight the match;
+	// otherwise list in the admin-defined order with a plain excerpt.
+	q := `
+		SELECT a.id, c.slug, a.title, a.slug,
+		       CASE WHEN $1 = '' THEN LEFT(a.body_markdown, 200)
+		            ELSE ts_headline('english', a.body_markdown, plainto_tsquery('english', $1),
+		                             'MaxWords=30, MinWords=10, StartSel=**, StopSel=**')
+		       END AS snippet,
+		       a.is_published,
+		       CASE WHEN $1 = '' THEN 0
+		            ELSE ts_rank_cd(a.fts, plainto_tsquery('english', $1))
+		       END AS rank,
+		       COUNT(*) OVER() AS total_count
+		FROM help_articles a
+		JOIN help_categories c ON c.id = a.category_id
+		WHERE ($1 = '' OR a.fts @@ plainto_tsquery('english', $1))
+		  AND ($2 = '' OR c.slug = $2)
+		  AND (NOT $3 OR a.is_published)
+		ORDER BY rank DESC, c.position, a.position, a.id
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := r.db.Query(ctx, q, f.Search, f.CategorySlug, f.PublishedOnly, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list help articles: %w", err)
+	}
+	defer rows.Close()
+
+	list := []ArticleSummary{}
+	var total int
+	for rows.Next() {
+		var s ArticleSummary
+		if err := rows.Scan(&s.ID, &s.CategorySlug, &s.Title, &s.Slug, &s.Snippet, &s.IsPublished, &s.Rank, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan help article: %w", err)
+		}
+		list = append(list, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) GetArticle(ctx context.Context, id int64) (*Article, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		SELECT ` + articleColumns + `
+		FROM help_articles a
+		JOIN help_categories c ON c.id = a.category_id
+		WHERE a.id = $1
+	`
+	var a Article
+	if err := scanArticle(r.db.QueryRow(ctx, q, id), &a); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("get help article: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *Repository) GetPublishedArticleBySlug(ctx context.Context, slug string) (*Article, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		SELECT ` + articleColumns + `
+		FROM help_articles a
+		JOIN help_categories c ON c.id = a.category_id
+		WHERE a.slug = $1 AND a.is_published
+	`
+	var a Article
+	if err := scanArticle(r.db.QueryRow(ctx, q, slug), &a); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("get help article by slug: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *Repository) CreateArticle(ctx context.Context, a *Article) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		WITH a AS (
+			INSERT INTO help_articles (category_id, title, slug, body_markdown, is_published, position, published_at)
+			VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $5 THEN NOW() END)
+			RETURNING *
+		)
+		SELECT ` + articleColumns + `
+		FROM a
+		JOIN help_categories c ON c.id = a.category_id
+	`
+	if err := scanArticle(r.db.QueryRow(ctx, q, a.CategoryID, a.Title, a.Slug, a.BodyMarkdown, a.IsPublished, a.Position), a); err != nil {
+		switch pgCode(err) {
+		case "23505":
+			return ErrSlugTaken
+		case "23503":
+			return ErrCategoryNotFound
+		}
+		return fmt.Errorf("create help article: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) UpdateArticle(ctx context.Context, id int64, upd UpdateArticle) (*Article, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// published_at records the first time an article went live.
+	q := `
+		WITH a AS (
+			UPDATE help_articles
+			SET category_id = COALESCE($2, category_id),
+			    title = COALESCE($3, title),
+			    slug = COALESCE($4, slug),
+			    body_markdown = COALESCE($5, body_markdown),
+			    is_published = COALESCE($6, is_published),
+			    position = COALESCE($7, position),
+			    published_at = CASE WHEN COALESCE($6, is_published) AND published_at IS NULL
+			                        THEN NOW() ELSE published_at END,
+			    updated_at = NOW()
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT ` + articleColumns + `
+		FROM a
+		JOIN help_categories c ON c.id = a.category_id
+	`
+	var a Article
+	if err := scanArticle(r.db.QueryRow(ctx, q, id,
+		upd.CategoryID, upd.Title, upd.Slug, upd.BodyMarkdown, upd.IsPublished, upd.Position,
+	), &a); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrArticleNotFound
+		}
+		switch pgCode(err) {
+		case "23505":
+			return nil, ErrSlugTaken
+		case "23503":
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("update help article: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *Repository) DeleteArticle(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM help_articles WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete help article: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrArticleNotFound
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package helpcenter
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+var (
+	ErrCategoryNotFound = errors.New("help category not found")
+	ErrArticleNotFound  = errors.New("help article not found")
+	ErrSlugTaken        = errors.New("slug already in use")
+	ErrCategoryInUse    = errors.New("help category still has articles")
+)
+
+type Category struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description *string   `json:"description,omitempty"`
+	Position    int       `json:"position"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// ArticleCount is the number of published articles; only set on listings.
+	ArticleCount int `json:"article_count"`
+}
+
+type Article struct {
+	ID           int64      `json:"id"`
+	CategoryID   int64      `json:"category_id"`
+	CategorySlug string     `json:"category_slug"`
+	Title        string     `json:"title"`
+	Slug         string     `json:"slug"`
+	BodyMarkdown string     `json:"body_markdown"`
+	IsPublished  bool       `json:"is_published"`
+	Position     int        `json:"position"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ArticleSummary is a search/list hit without the full body.
+type ArticleSummary struct {
+	ID           int64   `json:"id"`
+	CategorySlug string  `json:"category_slug"`
+	Title        string  `json:"title"`
+	Slug         string  `json:"slug"`
+	Snippet      string  `json:"snippet"`
+	IsPublished  bool    `json:"is_published"`
+	Rank         float64 `json:"rank,omitempty"`
+}
+
+type UpdateCategory struct {
+	Name        *string
+	Slug        *string
+	Description *string
+	Position    *int
+}
+
+type UpdateArticle struct {
+	CategoryID   *int64
+	Title        *string
+	Slug         *string
+	BodyMarkdown *string
+	IsPublished  *bool
+	Position     *int
+}
+
+// ArticleFilter narrows listings. Search, when set, ranks by relevance
+// instead of position.
+type ArticleFilter struct {
+	CategorySlug  string
+	Search        string
+	PublishedOnly bool
+}
+
+type Store interface {
+	ListCategories(ctx context.Context) ([]Category, error)
+	CreateCategory(ctx context.Context, c *Category) error
+	UpdateCategory(ctx context.Context, id int64, upd UpdateCategory) (*Category, error)
+	DeleteCategory(ctx context.Context, id int64) error
+
+	ListArticles(ctx context.Context, f ArticleFilter, limit, offset int) ([]ArticleSummary, int, error)
+	GetArticle(ctx context.Context, id int64) (*Article, error)
+	// GetPublishedArticleBySlug is the public read path.
+	GetPublishedArticleBySlug(ctx context.Context, slug string) (*Article, error)
+	CreateArticle(ctx context.Context, a *Article) error
+	UpdateArticle(ctx context.Context, id int64, upd UpdateArticle) (*Article, error)
+	DeleteArticle(ctx context.Context, id int64) error
+}
@@ -0,0 +1,248 @@
+package holidays
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const holidayColumns = `id, to_char(date, 'YYYY-MM-DD'), name, created_at, updated_at`
+
+func (r *Repository) List(ctx context.Context, from, to string) ([]Holiday, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+holidayColumns+`
+		FROM public_holidays
+		WHERE ($1 = '' OR date >= $1::date)
+		  AND ($2 = '' OR date <= $2::date)
+		ORDER BY date
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list holidays: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Holiday{}
+	for rows.Next() {
+		var h Holiday
+		if err := rows.Scan(&h.ID, &h.Date, &h.Name, &h.CreatedAt, &h.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan holiday: %w", err)
+		}
+		list = append(list, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Create(ctx context.Context, h *Holiday) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO public_holidays (date, name)
+		VALUES ($1::date, $2)
+		RETURNING `+holidayColumns,
+		h.Date, h.Name,
+	).Scan(&h.ID, &h.Date, &h.Name, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		return holidayWriteError("create holiday", err)
+	}
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, h *Holiday) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		UPDATE public_holidays
+		SET date = $2::date, name = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+holidayColumns,
+		h.ID, h.Date, h.Name,
+	).Scan(&h.ID, &h.Date, &h.Name, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return holidayWriteError("update holiday", err)
+	}
+	return nil
+}
+
+func holidayWriteError(op string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM public_holidays WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete holiday: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) Import(ctx context.Context, list []Holiday) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var changed int64
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		for _, h := range list {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO public_holidays (date, name)
+				VALUES ($1::date, $2)
+				ON CONFLICT (date) DO UPDATE
+				SET name = EXCLUDED.name, updated_at = NOW()
+				WHERE public_holidays.name <> EXCLUDED.name
+			`, h.Date, h.Name)
+			if err != nil {
+				return fmt.Errorf("import holiday %s: %w", h.Date, err)
+			}
+			changed += tag.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+func (r *Repository) GetVenueSettings(ctx context.Context, venueID int64) (VenueSettings, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	s := VenueSettings{VenueID: venueID}
+	err := r.db.QueryRow(ctx, `
+		SELECT rate_percent, closed, updated_at
+		FROM venue_holiday_settings
+		WHERE venue_id = $1
+	`, venueID).Scan(&s.RatePercent, &s.Closed, &s.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return s, fmt.Errorf("get venue holiday settings: %w", err)
+	}
+	return s, nil
+}
+
+func (r *Repository) SetVenueSettings(ctx context.Context, s *VenueSettings) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO venue_holiday_settings (venue_id, rate_percent, closed)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (venue_id) DO UPDATE
+		SET rate_percent = EXCLUDED.rate_percent,
+		    closed = EXCLUDED.closed,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, s.VenueID, s.RatePercent, s.Closed).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("set venue holiday settings: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Utilization(ctx context.Context, venueID int64, from, to time.Time) (*UtilizationReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	report := &UtilizationReport{
+		From: from.Format("2006-01-02"),
+		To:   to.Format("2006-01-02"),
+	}
+
+	// Open hours come from the weekly pricing of each day; date overrides
+	// are not counted.
+	rows, err := r.db.Query(ctx, `
+		WITH days AS (
+			SELECT d::date AS day,
+			       EXISTS (SELECT 1 FROM public_holidays h WHERE h.date = d::date) AS is_holiday
+			FROM generate_series($2::date, $3::date, interval '1 day') AS d
+		),
+		open AS (
+			SELECT d.day, SUM(EXTRACT(EPOCH FROM vp.end_time - vp.start_time)) / 3600 AS hours
+			FROM days d
+			JOIN venue_pricing vp
+			  ON vp.venue_id = $1
+			 AND vp.day_of_week = lower(to_char(d.day, 'FMDay'))
+			GROUP BY d.day
+		),
+		booked AS (
+			SELECT (b.start_time AT TIME ZONE 'Asia/Kathmandu')::date AS day,
+			       COUNT(*) AS bookings,
+			       SUM(EXTRACT(EPOCH FROM b.end_time - b.start_time)) / 3600 AS hours,
+			       SUM(b.total_price) AS revenue
+			FROM bookings b
+			WHERE b.venue_id = $1
+			  AND b.status IN ('confirmed', 'done')
+			  AND (b.start_time AT TIME ZONE 'Asia/Kathmandu')::date BETWEEN $2::date AND $3::date
+			GROUP BY 1
+		)
+		SELECT d.is_holiday,
+		       COUNT(*)::int,
+		       COALESCE(SUM(bk.bookings), 0)::int,
+		       COALESCE(SUM(bk.hours), 0)::float8,
+		       COALESCE(SUM(o.hours), 0)::float8,
+		       COALESCE(SUM(bk.revenue), 0)::int
+		FROM days d
+		LEFT JOIN open o ON o.day = d.day
+		LEFT JOIN booked bk ON bk.day = d.day
+		GROUP BY d.is_holiday
+	`, venueID, report.From, report.To)
+	if err != nil {
+		return nil, fmt.Errorf("holiday utilization: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			isHoliday bool
+			u         Utilization
+		)
+		if err := rows.Scan(&isHoliday, &u.Days, &u.Bookings, &u.BookedHours, &u.OpenHours, &u.Revenue); err != nil {
+			return nil, fmt.Errorf("scan holiday utilization: %w", err)
+		}
+		if u.OpenHours > 0 {
+			u.Rate = u.BookedHours / u.OpenHours
+		}
+		if isHoliday {
+			report.Holiday = u
+		} else {
+			report.Normal = u
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return report, nil
+}
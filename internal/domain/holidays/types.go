@@ -0,0 +1,67 @@
+package holidays
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 10
+
+var (
+	ErrNotFound  = errors.New("holiday not found")
+	ErrDuplicate = errors.New("a holiday already exists on that date")
+)
+
+type Holiday struct {
+	ID        int64     `json:"id"`
+	Date      string    `json:"date"` // YYYY-MM-DD
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VenueSettings is how a venue treats public holidays. Venues without a row
+// keep their weekly pricing.
+type VenueSettings struct {
+	VenueID     int64     `json:"venue_id"`
+	RatePercent int       `json:"rate_percent"`
+	Closed      bool      `json:"closed"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+// Utilization sums up one kind of day (holidays or normal days) in a range.
+type Utilization struct {
+	Days        int     `json:"days"`
+	Bookings    int     `json:"bookings"`
+	BookedHours float64 `json:"booked_hours"`
+	OpenHours   float64 `json:"open_hours"`
+	// Rate is BookedHours / OpenHours, 0 when the venue had no open hours.
+	Rate    float64 `json:"rate"`
+	Revenue int     `json:"revenue"`
+}
+
+type UtilizationReport struct {
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	Holiday Utilization `json:"holiday"`
+	Normal  Utilization `json:"normal"`
+}
+
+type Store interface {
+	// List returns holidays between from and to (inclusive, YYYY-MM-DD).
+	// Empty bounds are open.
+	List(ctx context.Context, from, to string) ([]Holiday, error)
+	Create(ctx context.Context, h *Holiday) error
+	Update(ctx context.Context, h *Holiday) error
+	Delete(ctx context.Context, id int64) error
+	// Import upserts holidays by date and returns how many rows changed.
+	Import(ctx context.Context, list []Holiday) (int64, error)
+
+	GetVenueSettings(ctx context.Context, venueID int64) (VenueSettings, error)
+	SetVenueSettings(ctx context.Context, s *VenueSettings) error
+
+	// Utilization compares holiday and normal days at a venue between from
+	// and to (inclusive local dates).
+	Utilization(ctx context.Context, venueID int64, from, to time.Time) (*UtilizationReport, error)
+}
@@ -0,0 +1,111 @@
+package homelayout
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const sectionColumns = `id, position, type, title, params, is_active, starts_at, ends_at, created_at, updated_at`
+
+func (r *Repository) List(ctx context.Context) ([]Section, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+sectionColumns+` FROM home_sections ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("list home sections: %w", err)
+	}
+	return collectSections(rows)
+}
+
+func (r *Repository) Active(ctx context.Context, now time.Time) (*Layout, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT type, title, params, updated_at
+		FROM home_sections
+		WHERE is_active = TRUE
+		  AND (starts_at IS NULL OR starts_at <= $1)
+		  AND (ends_at IS NULL OR ends_at > $1)
+		ORDER BY position
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("active home sections: %w", err)
+	}
+	defer rows.Close()
+
+	out := &Layout{Sections: []PublicSection{}}
+	for rows.Next() {
+		var (
+			s         PublicSection
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&s.Type, &s.Title, &s.Params, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan home section: %w", err)
+		}
+		if out.UpdatedAt == nil || updatedAt.After(*out.UpdatedAt) {
+			out.UpdatedAt = &updatedAt
+		}
+		out.Sections = append(out.Sections, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+// Replace rewrites the table in one transaction so the app never sees a
+// half-saved layout. Params are expected to be normalized already.
+func (r *Repository) Replace(ctx context.Context, sections []Section) ([]Section, error) {
+	var out []Section
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM home_sections`); err != nil {
+			return fmt.Errorf("clear home sections: %w", err)
+		}
+		for i, s := range sections {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO home_sections (position, type, title, params, is_active, starts_at, ends_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, i, s.Type, s.Title, s.Params, s.IsActive, s.StartsAt, s.EndsAt); err != nil {
+				return fmt.Errorf("insert home section %d: %w", i, err)
+			}
+		}
+
+		rows, err := tx.Query(ctx, `SELECT `+sectionColumns+` FROM home_sections ORDER BY position`)
+		if err != nil {
+			return fmt.Errorf("list home sections: %w", err)
+		}
+		out, err = collectSections(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func collectSections(rows pgx.Rows) ([]Section, error) {
+	defer rows.Close()
+
+	out := []Section{}
+	for rows.Next() {
+		var s Section
+		if err := rows.Scan(&s.ID, &s.Position, &s.Type, &s.Title, &s.Params, &s.IsActive,
+			&s.StartsAt, &s.EndsAt, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan home section: %w", err)
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,159 @@
+package homelayout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Section types the app knows how to render.
+const (
+	TypeAdsCarousel        = "ads_carousel"
+	TypeFeaturedRails      = "featured_rails"
+	TypeFeaturedCollection = "featured_collection"
+	TypeNearbyGames        = "nearby_games"
+	TypeVenues             = "venues"
+)
+
+var (
+	ErrUnknownType   = errors.New("unknown section type")
+	ErrInvalidParams = errors.New("invalid section params")
+)
+
+type Section struct {
+	ID       int64           `json:"id"`
+	Position int             `json:"position"`
+	Type     string          `json:"type"`
+	Title    *string         `json:"title,omitempty"`
+	Params   json.RawMessage `json:"params" swaggertype:"object"`
+	IsActive bool            `json:"is_active"`
+	StartsAt *time.Time      `json:"starts_at,omitempty"`
+	EndsAt   *time.Time      `json:"ends_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PublicSection is what the app gets: only what it needs to render.
+type PublicSection struct {
+	Type   string          `json:"type"`
+	Title  *string         `json:"title,omitempty"`
+	Params json.RawMessage `json:"params" swaggertype:"object"`
+}
+
+type Layout struct {
+	Sections  []PublicSection `json:"sections"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
+}
+
+// Per-type params. Unknown fields are rejected so a typo doesn't silently
+// fall back to the app default.
+type adsCarouselParams struct {
+	Limit *int `json:"limit,omitempty"`
+}
+
+type featuredRailsParams struct {
+	Limit *int `json:"limit,omitempty"`
+}
+
+type featuredCollectionParams struct {
+	CollectionKey string `json:"collection_key"`
+	Limit         *int   `json:"limit,omitempty"`
+}
+
+type nearbyGamesParams struct {
+	RadiusKm  *float64 `json:"radius_km,omitempty"`
+	SportType *string  `json:"sport_type,omitempty"`
+	Limit     *int     `json:"limit,omitempty"`
+}
+
+type venuesParams struct {
+	Sort      *string `json:"sort,omitempty"`
+	SportType *string `json:"sport_type,omitempty"`
+	Limit     *int    `json:"limit,omitempty"`
+}
+
+// NormalizeParams checks raw against the params the section type accepts
+// and returns it re-encoded without extra whitespace. Empty params become {}.
+func NormalizeParams(typ string, raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = json.RawMessage(`{}`)
+	}
+
+	var (
+		p     any
+		limit *int
+		check func() error
+	)
+	switch typ {
+	case TypeAdsCarousel:
+		v := &adsCarouselParams{}
+		p, check = v, func() error { limit = v.Limit; return nil }
+	case TypeFeaturedRails:
+		v := &featuredRailsParams{}
+		p, check = v, func() error { limit = v.Limit; return nil }
+	case TypeFeaturedCollection:
+		v := &featuredCollectionParams{}
+		p, check = v, func() error {
+			limit = v.Limit
+			if v.CollectionKey == "" {
+				return errors.New("collection_key is required")
+			}
+			return nil
+		}
+	case TypeNearbyGames:
+		v := &nearbyGamesParams{}
+		p, check = v, func() error {
+			limit = v.Limit
+			if v.RadiusKm != nil && (*v.RadiusKm <= 0 || *v.RadiusKm > 100) {
+				return errors.New("radius_km must be between 0 and 100")
+			}
+			return nil
+		}
+	case TypeVenues:
+		v := &venuesParams{}
+		p, check = v, func() error {
+			limit = v.Limit
+			if v.Sort != nil {
+				switch *v.Sort {
+				case "nearby", "top_rated", "newest":
+				default:
+					return errors.New("sort must be nearby, top_rated or newest")
+				}
+			}
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, typ)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("%w for %s: %v", ErrInvalidParams, typ, err)
+	}
+	if err := check(); err != nil {
+		return nil, fmt.Errorf("%w for %s: %v", ErrInvalidParams, typ, err)
+	}
+	if limit != nil && (*limit < 1 || *limit > 50) {
+		return nil, fmt.Errorf("%w for %s: limit must be between 1 and 50", ErrInvalidParams, typ)
+	}
+
+	out, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type Store interface {
+	// List returns every section, including inactive and scheduled ones.
+	List(ctx context.Context) ([]Section, error)
+	// Active returns the sections live at now, in display order.
+	Active(ctx context.Context, now time.Time) (*Layout, error)
+	// Replace swaps the whole layout for sections, positioned in slice order.
+	Replace(ctx context.Context, sections []Section) ([]Section, error)
+}
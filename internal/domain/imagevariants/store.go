@@ -0,0 +1,81 @@
+package imagevariants
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/images"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Pending(ctx context.Context, limit int) ([]Pending, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT 'product_image', id, url
+		  FROM product_images
+		 WHERE variants IS NULL
+		UNION ALL
+		SELECT 'venue', v.id, u.url
+		  FROM venues v
+		 CROSS JOIN LATERAL unnest(v.image_urls) AS u(url)
+		 WHERE NOT v.image_variants ? u.url
+		UNION ALL
+		SELECT 'ad', id, image_url
+		  FROM ads
+		 WHERE image_variants IS NULL AND image_url <> ''
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list pending images: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Pending
+	for rows.Next() {
+		var p Pending
+		if err := rows.Scan(&p.Target, &p.ID, &p.URL); err != nil {
+			return nil, fmt.Errorf("scan pending image: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) Save(ctx context.Context, p Pending, v images.Variants) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var q string
+	switch p.Target {
+	case TargetProductImage:
+		q = `UPDATE product_images SET variants = $3 WHERE id = $1 AND url = $2`
+	case TargetAd:
+		q = `UPDATE ads SET image_variants = $3 WHERE id = $1 AND image_url = $2`
+	case TargetVenue:
+		// Keys of photos since removed are dropped on the way.
+		q = `
+			UPDATE venues
+			   SET image_variants = (
+			         SELECT COALESCE(jsonb_object_agg(e.key, e.value), '{}'::jsonb)
+			           FROM jsonb_each(image_variants || jsonb_build_object($2::text, $3::jsonb)) e
+			          WHERE e.key = ANY(image_urls))
+			 WHERE id = $1 AND $2 = ANY(image_urls)`
+	default:
+		return false, fmt.Errorf("unknown image target %q", p.Target)
+	}
+
+	tag, err := r.db.Exec(ctx, q, p.ID, p.URL, v)
+	if err != nil {
+		return false, fmt.Errorf("save %s image variants: %w", p.Target, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
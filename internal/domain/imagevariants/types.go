@@ -0,0 +1,33 @@
+package imagevariants
+
+import (
+	"context"
+	"khel/internal/images"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 10
+
+// Where an image is used.
+const (
+	TargetProductImage = "product_image"
+	TargetVenue        = "venue"
+	TargetAd           = "ad"
+)
+
+// Pending is an image that has no variants yet. ID is the product image,
+// venue or ad it belongs to.
+type Pending struct {
+	Target string
+	ID     int64
+	URL    string
+}
+
+type Store interface {
+	// Pending lists up to limit images without variants, across every
+	// target.
+	Pending(ctx context.Context, limit int) ([]Pending, error)
+	// Save stores v for p, reporting false when p's URL was replaced or
+	// removed meanwhile.
+	Save(ctx context.Context, p Pending, v images.Variants) (bool, error)
+}
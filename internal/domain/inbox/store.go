@@ -0,0 +1,180 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, userIDs []int64, typ, title, body string, data map[string]string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		INSERT INTO notifications (user_id, type, title, body, data)
+		SELECT u.id, $2, $3, $4, $5
+		FROM unnest($1::bigint[]) AS u(id)
+	`
+	if _, err := r.db.Exec(ctx, q, userIDs, typ, title, body, data); err != nil {
+		return fmt.Errorf("create notifications: %w", err)
+	}
+
+	// Keep only the newest MaxPerUser per recipient.
+	trim := `
+		DELETE FROM notifications n
+		USING (
+			SELECT id
+			FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC, id DESC) AS rn
+				FROM notifications
+				WHERE user_id = ANY($1::bigint[])
+			) ranked
+			WHERE rn > $2
+		) d
+		WHERE n.id = d.id
+	`
+	if _, err := r.db.Exec(ctx, trim, userIDs, MaxPerUser); err != nil {
+		return fmt.Errorf("trim notifications: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) List(ctx context.Context, userID int64, limit, offset int) ([]Notification, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		SELECT id, user_id, type, title, body, data, read_at, created_at,
+		       COUNT(*) OVER() AS total_count
+		FROM notifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, q, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Notification{}
+	var total int
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.Data, &n.ReadAt, &n.CreatedAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan notification: %w", err)
+		}
+		list = append(list, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+// MarkRead is idempotent: marking an already-read notification keeps the
+// original read_at.
+func (r *Repository) MarkRead(ctx context.Context, userID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("mark notification read: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *Repository) UnreadCount(ctx context.Context, userID int64) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var n int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL
+	`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return n, nil
+}
+
+func (r *Repository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("mark all notifications read: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *Repository) DeleteBefore(ctx context.Context, userID int64, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM notifications
+		WHERE user_id = $1 AND created_at < $2
+	`, userID, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete notifications: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Prune runs from a daily job, so it gets a longer timeout than request
+// queries.
+func (r *Repository) Prune(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-olderThan)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM notifications n
+		USING (
+			SELECT id
+			FROM (
+				SELECT id, created_at,
+				       ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC, id DESC) AS rn
+				FROM notifications
+			) ranked
+			WHERE rn > $1 OR created_at < $2
+		) d
+		WHERE n.id = d.id
+	`, MaxPerUser, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("prune notifications: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
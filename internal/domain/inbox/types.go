@@ -0,0 +1,46 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// MaxPerUser is how many notifications a user keeps. Older ones are dropped
+// as new ones arrive, so the inbox table stays bounded for heavy users.
+const MaxPerUser = 500
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// Notification is one inbox entry. Data carries the same deep-link fields
+// as the push payload (type, screen, ids), so the app handles a tap on
+// either the same way.
+type Notification struct {
+	ID        int64             `json:"id"`
+	UserID    int64             `json:"user_id"`
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Data      map[string]string `json:"data"`
+	ReadAt    *time.Time        `json:"read_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type Store interface {
+	// Create stores the same notification for every user in userIDs.
+	Create(ctx context.Context, userIDs []int64, typ, title, body string, data map[string]string) error
+	List(ctx context.Context, userID int64, limit, offset int) ([]Notification, int, error)
+	MarkRead(ctx context.Context, userID, id int64) error
+	UnreadCount(ctx context.Context, userID int64) (int, error)
+	// MarkAllRead marks every unread notification of the user as read and
+	// returns how many changed.
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	// DeleteBefore removes the user's notifications created before the given
+	// time and returns how many were removed.
+	DeleteBefore(ctx context.Context, userID int64, before time.Time) (int64, error)
+	// Prune removes notifications older than olderThan and anything past
+	// MaxPerUser for every user.
+	Prune(ctx context.Context, olderThan time.Duration) (int64, error)
+}
@@ -0,0 +1,444 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func (r *Repository) CreateSupplier(ctx context.Context, sup *Supplier) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO inventory_suppliers (venue_id, name, phone, email, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, is_active, created_at, updated_at
+	`, sup.VenueID, sup.Name, sup.Phone, sup.Email, sup.Note).Scan(&sup.ID, &sup.IsActive, &sup.CreatedAt, &sup.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateSupplier
+		}
+		return fmt.Errorf("create supplier: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListSuppliers(ctx context.Context, venueID int64) ([]Supplier, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, venue_id, name, phone, email, note, is_active, created_at, updated_at
+		FROM inventory_suppliers
+		WHERE venue_id = $1
+		ORDER BY is_active DESC, LOWER(name)
+	`, venueID)
+	if err != nil {
+		return nil, fmt.Errorf("list suppliers: %w", err)
+	}
+	defer rows.Close()
+
+	suppliers := []Supplier{}
+	for rows.Next() {
+		var s Supplier
+		if err := rows.Scan(&s.ID, &s.VenueID, &s.Name, &s.Phone, &s.Email, &s.Note, &s.IsActive, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan supplier: %w", err)
+		}
+		suppliers = append(suppliers, s)
+	}
+	return suppliers, rows.Err()
+}
+
+func (r *Repository) UpdateSupplier(ctx context.Context, sup *Supplier) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE inventory_suppliers
+		SET name = $3, phone = $4, email = $5, note = $6, is_active = $7, updated_at = NOW()
+		WHERE id = $1 AND venue_id = $2
+		RETURNING created_at, updated_at
+	`, sup.ID, sup.VenueID, sup.Name, sup.Phone, sup.Email, sup.Note, sup.IsActive).Scan(&sup.CreatedAt, &sup.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSupplierNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateSupplier
+		}
+		return fmt.Errorf("update supplier: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CreatePurchaseOrder(ctx context.Context, po *PurchaseOrder) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := activeSupplierName(ctx, tx, po); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO inventory_purchase_orders (venue_id, supplier_id, note, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, created_at, updated_at
+	`, po.VenueID, po.SupplierID, po.Note, po.CreatedBy).Scan(&po.ID, &po.Status, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create purchase order: %w", err)
+	}
+
+	if err := insertPurchaseOrderLines(ctx, tx, po); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit purchase order transaction: %w", err)
+	}
+	return nil
+}
+
+// activeSupplierName checks the supplier belongs to the venue and fills in
+// its name.
+func activeSupplierName(ctx context.Context, tx pgx.Tx, po *PurchaseOrder) error {
+	err := tx.QueryRow(ctx, `
+		SELECT name FROM inventory_suppliers
+		WHERE id = $1 AND venue_id = $2 AND is_active = TRUE
+	`, po.SupplierID, po.VenueID).Scan(&po.SupplierName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSupplierNotFound
+		}
+		return fmt.Errorf("get supplier: %w", err)
+	}
+	return nil
+}
+
+func insertPurchaseOrderLines(ctx context.Context, tx pgx.Tx, po *PurchaseOrder) error {
+	po.TotalCost = 0
+	for i := range po.Lines {
+		line := &po.Lines[i]
+		err := tx.QueryRow(ctx, `
+			SELECT name FROM venue_inventory_items
+			WHERE id = $1 AND venue_id = $2
+		`, line.InventoryItemID, po.VenueID).Scan(&line.ItemName)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: id=%d", ErrInventoryItemNotFound, line.InventoryItemID)
+			}
+			return fmt.Errorf("get inventory item: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO inventory_purchase_order_lines (purchase_order_id, inventory_item_id, quantity, unit_cost)
+			VALUES ($1, $2, $3, $4)
+		`, po.ID, line.InventoryItemID, line.Quantity, line.UnitCost)
+		if err != nil {
+			return fmt.Errorf("create purchase order line: %w", err)
+		}
+		line.LineTotal = line.Quantity * line.UnitCost
+		po.TotalCost += line.LineTotal
+	}
+	return nil
+}
+
+func (r *Repository) ListPurchaseOrders(ctx context.Context, venueID int64, status string, limit, offset int) ([]PurchaseOrder, int, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			po.id, po.venue_id, po.supplier_id, s.name, po.status, po.note,
+			COALESCE((SELECT SUM(l.quantity * l.unit_cost) FROM inventory_purchase_order_lines l WHERE l.purchase_order_id = po.id), 0),
+			po.created_by, po.ordered_at, po.received_at, po.received_by, po.cancelled_at,
+			po.created_at, po.updated_at,
+			COUNT(*) OVER() AS total_count
+		FROM inventory_purchase_orders po
+		JOIN inventory_suppliers s ON s.id = po.supplier_id
+		WHERE po.venue_id = $1
+		  AND ($2::text = '' OR po.status = $2)
+		ORDER BY po.created_at DESC, po.id DESC
+		LIMIT $3 OFFSET $4
+	`, venueID, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list purchase orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []PurchaseOrder{}
+	var total int
+	for rows.Next() {
+		var po PurchaseOrder
+		if err := rows.Scan(
+			&po.ID, &po.VenueID, &po.SupplierID, &po.SupplierName, &po.Status, &po.Note,
+			&po.TotalCost,
+			&po.CreatedBy, &po.OrderedAt, &po.ReceivedAt, &po.ReceivedBy, &po.CancelledAt,
+			&po.CreatedAt, &po.UpdatedAt,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan purchase order: %w", err)
+		}
+		orders = append(orders, po)
+	}
+	return orders, total, rows.Err()
+}
+
+func (r *Repository) GetPurchaseOrder(ctx context.Context, venueID, poID int64) (*PurchaseOrder, error) {
+	var po PurchaseOrder
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			po.id, po.venue_id, po.supplier_id, s.name, po.status, po.note,
+			po.created_by, po.ordered_at, po.received_at, po.received_by, po.cancelled_at,
+			po.created_at, po.updated_at
+		FROM inventory_purchase_orders po
+		JOIN inventory_suppliers s ON s.id = po.supplier_id
+		WHERE po.id = $1 AND po.venue_id = $2
+	`, poID, venueID).Scan(
+		&po.ID, &po.VenueID, &po.SupplierID, &po.SupplierName, &po.Status, &po.Note,
+		&po.CreatedBy, &po.OrderedAt, &po.ReceivedAt, &po.ReceivedBy, &po.CancelledAt,
+		&po.CreatedAt, &po.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPurchaseOrderNotFound
+		}
+		return nil, fmt.Errorf("get purchase order: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT l.inventory_item_id, i.name, l.quantity, l.unit_cost
+		FROM inventory_purchase_order_lines l
+		JOIN venue_inventory_items i ON i.id = l.inventory_item_id
+		WHERE l.purchase_order_id = $1
+		ORDER BY l.id
+	`, poID)
+	if err != nil {
+		return nil, fmt.Errorf("list purchase order lines: %w", err)
+	}
+	defer rows.Close()
+
+	po.Lines = []PurchaseOrderLine{}
+	for rows.Next() {
+		var l PurchaseOrderLine
+		if err := rows.Scan(&l.InventoryItemID, &l.ItemName, &l.Quantity, &l.UnitCost); err != nil {
+			return nil, fmt.Errorf("scan purchase order line: %w", err)
+		}
+		l.LineTotal = l.Quantity * l.UnitCost
+		po.TotalCost += l.LineTotal
+		po.Lines = append(po.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return &po, nil
+}
+
+// lockPurchaseOrder locks the order and returns its status.
+func lockPurchaseOrder(ctx context.Context, tx pgx.Tx, venueID, poID int64) (string, error) {
+	var status string
+	err := tx.QueryRow(ctx, `
+		SELECT status FROM inventory_purchase_orders
+		WHERE id = $1 AND venue_id = $2
+		FOR UPDATE
+	`, poID, venueID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrPurchaseOrderNotFound
+		}
+		return "", fmt.Errorf("lock purchase order: %w", err)
+	}
+	return status, nil
+}
+
+func (r *Repository) UpdateDraftPurchaseOrder(ctx context.Context, po *PurchaseOrder) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockPurchaseOrder(ctx, tx, po.VenueID, po.ID)
+	if err != nil {
+		return err
+	}
+	if status != PurchaseOrderDraft {
+		return ErrPurchaseOrderStatus
+	}
+	if err := activeSupplierName(ctx, tx, po); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE inventory_purchase_orders
+		SET supplier_id = $2, note = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING status, created_by, created_at, updated_at
+	`, po.ID, po.SupplierID, po.Note).Scan(&po.Status, &po.CreatedBy, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update purchase order: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM inventory_purchase_order_lines WHERE purchase_order_id = $1`, po.ID); err != nil {
+		return fmt.Errorf("clear purchase order lines: %w", err)
+	}
+	if err := insertPurchaseOrderLines(ctx, tx, po); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit purchase order transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) MarkPurchaseOrderOrdered(ctx context.Context, venueID, poID int64) error {
+	return r.transitionPurchaseOrder(ctx, venueID, poID, []string{PurchaseOrderDraft}, `
+		UPDATE inventory_purchase_orders
+		SET status = 'ordered', ordered_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`)
+}
+
+func (r *Repository) CancelPurchaseOrder(ctx context.Context, venueID, poID int64) error {
+	return r.transitionPurchaseOrder(ctx, venueID, poID, []string{PurchaseOrderDraft, PurchaseOrderOrdered}, `
+		UPDATE inventory_purchase_orders
+		SET status = 'cancelled', cancelled_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`)
+}
+
+func (r *Repository) transitionPurchaseOrder(ctx context.Context, venueID, poID int64, from []string, update string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockPurchaseOrder(ctx, tx, venueID, poID)
+	if err != nil {
+		return err
+	}
+	allowed := false
+	for _, s := range from {
+		if status == s {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return ErrPurchaseOrderStatus
+	}
+
+	if _, err := tx.Exec(ctx, update, poID); err != nil {
+		return fmt.Errorf("update purchase order status: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit purchase order transaction: %w", err)
+	}
+	return nil
+}
+
+// ReceivePurchaseOrder locks the order and then each item, like a
+// stocktake, so sales recorded at the same time stay in order in the
+// ledger. Receiving turns on stock tracking for the items.
+func (r *Repository) ReceivePurchaseOrder(ctx context.Context, venueID, poID, receivedBy int64) ([]Restocked, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockPurchaseOrder(ctx, tx, venueID, poID)
+	if err != nil {
+		return nil, err
+	}
+	if status != PurchaseOrderOrdered {
+		return nil, ErrPurchaseOrderStatus
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT inventory_item_id, quantity
+		FROM inventory_purchase_order_lines
+		WHERE purchase_order_id = $1
+		ORDER BY inventory_item_id
+	`, poID)
+	if err != nil {
+		return nil, fmt.Errorf("list purchase order lines: %w", err)
+	}
+	type receipt struct {
+		itemID   int64
+		quantity int
+	}
+	var receipts []receipt
+	for rows.Next() {
+		var rc receipt
+		if err := rows.Scan(&rc.itemID, &rc.quantity); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan purchase order line: %w", err)
+		}
+		receipts = append(receipts, rc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	restocked := []Restocked{}
+	for _, rc := range receipts {
+		var (
+			name    string
+			current sql.NullInt32
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT name, stock_quantity
+			FROM venue_inventory_items
+			WHERE id = $1
+			FOR UPDATE
+		`, rc.itemID).Scan(&name, &current)
+		if err != nil {
+			return nil, fmt.Errorf("lock inventory item: %w", err)
+		}
+
+		after := int(current.Int32) + rc.quantity
+		_, err = tx.Exec(ctx, `
+			UPDATE venue_inventory_items
+			SET stock_quantity = $1, track_stock = TRUE, updated_at = NOW()
+			WHERE id = $2
+		`, after, rc.itemID)
+		if err != nil {
+			return nil, fmt.Errorf("add received stock: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO inventory_stock_movements (
+				venue_id, inventory_item_id, delta, quantity_after, reason, purchase_order_id, created_by
+			)
+			VALUES ($1, $2, $3, $4, 'purchase', $5, $6)
+		`, venueID, rc.itemID, rc.quantity, after, poID, receivedBy)
+		if err != nil {
+			return nil, fmt.Errorf("record purchase movement: %w", err)
+		}
+
+		if current.Valid && current.Int32 <= 0 {
+			restocked = append(restocked, Restocked{
+				VenueID:         venueID,
+				InventoryItemID: rc.itemID,
+				ItemName:        name,
+				QuantityAfter:   after,
+			})
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE inventory_purchase_orders
+		SET status = 'received', received_at = NOW(), received_by = $2, updated_at = NOW()
+		WHERE id = $1
+	`, poID, receivedBy)
+	if err != nil {
+		return nil, fmt.Errorf("mark purchase order received: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit purchase order transaction: %w", err)
+	}
+	return restocked, nil
+}
@@ -3,9 +3,11 @@ package inventory
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -27,18 +29,27 @@ func (r *Repository) CreateInventoryItem(ctx context.Context, item *InventoryIte
 		return ErrInventoryLimitReached
 	}
 
+	// The opening stock is the first entry in the item's stock ledger.
 	query := `
-		INSERT INTO venue_inventory_items (
-			venue_id,
-			name,
-			description,
-			unit_price,
-			image_url,
-			stock_quantity,
-			track_stock
+		WITH item AS (
+			INSERT INTO venue_inventory_items (
+				venue_id,
+				name,
+				description,
+				unit_price,
+				image_url,
+				stock_quantity,
+				track_stock
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, venue_id, stock_quantity, is_active, created_at, updated_at
+		), opening AS (
+			INSERT INTO inventory_stock_movements (venue_id, inventory_item_id, delta, quantity_after, reason)
+			SELECT venue_id, id, stock_quantity, stock_quantity, 'opening'
+			FROM item
+			WHERE stock_quantity IS NOT NULL
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, is_active, created_at, updated_at
+		SELECT id, is_active, created_at, updated_at FROM item
 	`
 
 	err = r.db.QueryRow(
@@ -240,7 +251,31 @@ func (r *Repository) UpdateInventoryItem(ctx context.Context, venueID, itemID in
 	query += fmt.Sprintf(" WHERE venue_id = $%d AND id = $%d", argCounter, argCounter+1)
 	args = append(args, venueID, itemID)
 
-	result, err := r.db.Exec(ctx, query, args...)
+	// A stock edit is recorded in the ledger, so the update and the ledger
+	// entry go in one transaction with the item row locked.
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var oldStock sql.NullInt32
+	if payload.StockQuantity != nil {
+		err := tx.QueryRow(ctx, `
+			SELECT stock_quantity
+			FROM venue_inventory_items
+			WHERE venue_id = $1 AND id = $2
+			FOR UPDATE
+		`, venueID, itemID).Scan(&oldStock)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInventoryItemNotFound
+			}
+			return fmt.Errorf("lock inventory item: %w", err)
+		}
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("update inventory item: %w", err)
 	}
@@ -249,6 +284,20 @@ func (r *Repository) UpdateInventoryItem(ctx context.Context, venueID, itemID in
 		return ErrInventoryItemNotFound
 	}
 
+	if payload.StockQuantity != nil && (!oldStock.Valid || int(oldStock.Int32) != *payload.StockQuantity) {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO inventory_stock_movements (venue_id, inventory_item_id, delta, quantity_after, reason)
+			VALUES ($1, $2, $3, $4, 'adjustment')
+		`, venueID, itemID, *payload.StockQuantity-int(oldStock.Int32), *payload.StockQuantity)
+		if err != nil {
+			return fmt.Errorf("record stock adjustment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit update item transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -404,18 +453,28 @@ func (r *Repository) AddItemToBooking(
 			return nil, fmt.Errorf("not enough stock")
 		}
 
-		// Reduce stock only after confirming there is enough.
+		// Reduce stock only after confirming there is enough, and record
+		// the sale in the stock ledger.
 		_, err = tx.Exec(
 			ctx,
 			`
-				UPDATE venue_inventory_items
-				SET stock_quantity = stock_quantity - $1,
-				    updated_at = NOW()
-				WHERE id = $2 AND venue_id = $3
+				WITH item AS (
+					UPDATE venue_inventory_items
+					SET stock_quantity = stock_quantity - $1,
+					    updated_at = NOW()
+					WHERE id = $2 AND venue_id = $3
+					RETURNING stock_quantity
+				)
+				INSERT INTO inventory_stock_movements (
+					venue_id, inventory_item_id, delta, quantity_after, reason, booking_id
+				)
+				SELECT $3, $2, -$1::int, stock_quantity, 'sale', $4
+				FROM item
 			`,
 			quantity,
 			inventoryItemID,
 			venueID,
+			bookingID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("decrease stock: %w", err)
@@ -620,3 +679,138 @@ func (r *Repository) GetGameDetail(ctx context.Context, venueID, bookingID int64
 		BillingSummary: *summary,
 	}, nil
 }
+
+// CreateStocktake locks every counted item, so sales recorded while the
+// stocktake is saved land either before or after the count, never in
+// between.
+func (r *Repository) CreateStocktake(ctx context.Context, st *Stocktake) error {
+	if len(st.Lines) == 0 {
+		return ErrEmptyStocktake
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO inventory_stocktakes (venue_id, note, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, st.VenueID, st.Note, st.CreatedBy).Scan(&st.ID, &st.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create stocktake: %w", err)
+	}
+
+	for i := range st.Lines {
+		line := &st.Lines[i]
+
+		var current sql.NullInt32
+		err := tx.QueryRow(ctx, `
+			SELECT name, stock_quantity
+			FROM venue_inventory_items
+			WHERE id = $1 AND venue_id = $2 AND is_active = TRUE
+			FOR UPDATE
+		`, line.InventoryItemID, st.VenueID).Scan(&line.ItemName, &current)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: id=%d", ErrInventoryItemNotFound, line.InventoryItemID)
+			}
+			return fmt.Errorf("lock inventory item: %w", err)
+		}
+
+		line.Expected = int(current.Int32)
+		line.Delta = line.Counted - line.Expected
+
+		_, err = tx.Exec(ctx, `
+			UPDATE venue_inventory_items
+			SET stock_quantity = $1, track_stock = TRUE, updated_at = NOW()
+			WHERE id = $2
+		`, line.Counted, line.InventoryItemID)
+		if err != nil {
+			return fmt.Errorf("set counted stock: %w", err)
+		}
+
+		// Matching counts are not movements, but an item counted for the
+		// first time still gets its opening figure in the ledger.
+		if line.Delta == 0 && current.Valid {
+			continue
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO inventory_stock_movements (
+				venue_id, inventory_item_id, delta, quantity_after, reason, note, stocktake_id, created_by
+			)
+			VALUES ($1, $2, $3, $4, 'stocktake', $5, $6, $7)
+		`, st.VenueID, line.InventoryItemID, line.Delta, line.Counted, line.Reason, st.ID, st.CreatedBy)
+		if err != nil {
+			return fmt.Errorf("record stocktake movement: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit stocktake transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) ListMovements(ctx context.Context, filter MovementFilter, limit, offset int) ([]StockMovement, int, error) {
+	query := `
+		SELECT
+			m.id,
+			m.venue_id,
+			m.inventory_item_id,
+			i.name,
+			m.delta,
+			m.quantity_after,
+			m.reason,
+			m.note,
+			m.booking_id,
+			m.stocktake_id,
+			m.purchase_order_id,
+			m.created_by,
+			m.created_at,
+			COUNT(*) OVER() AS total_count
+		FROM inventory_stock_movements m
+		JOIN venue_inventory_items i ON i.id = m.inventory_item_id
+		WHERE m.venue_id = $1
+		  AND ($2::bigint IS NULL OR m.inventory_item_id = $2)
+		  AND ($3::text = '' OR m.reason = $3)
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := r.db.Query(ctx, query, filter.VenueID, filter.ItemID, filter.Reason, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := []StockMovement{}
+	var total int
+	for rows.Next() {
+		var m StockMovement
+		if err := rows.Scan(
+			&m.ID,
+			&m.VenueID,
+			&m.InventoryItemID,
+			&m.ItemName,
+			&m.Delta,
+			&m.QuantityAfter,
+			&m.Reason,
+			&m.Note,
+			&m.BookingID,
+			&m.StocktakeID,
+			&m.PurchaseOrderID,
+			&m.CreatedBy,
+			&m.CreatedAt,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan stock movement: %w", err)
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, total, rows.Err()
+}
@@ -9,6 +9,28 @@ import (
 var ErrInventoryItemNotFound = errors.New("inventory item not found")
 var ErrInventoryLimitReached = errors.New("venue inventory limit reached")
 var ErrBookingNotActive = errors.New("booking is not active right now")
+var ErrEmptyStocktake = errors.New("stocktake has no counted items")
+var ErrSupplierNotFound = errors.New("supplier not found")
+var ErrDuplicateSupplier = errors.New("a supplier with this name already exists")
+var ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+var ErrPurchaseOrderStatus = errors.New("purchase order is not in a status that allows this")
+
+// Reasons a stock movement is recorded.
+const (
+	MovementOpening    = "opening"
+	MovementSale       = "sale"
+	MovementAdjustment = "adjustment"
+	MovementStocktake  = "stocktake"
+	MovementPurchase   = "purchase"
+)
+
+// Purchase order statuses.
+const (
+	PurchaseOrderDraft     = "draft"
+	PurchaseOrderOrdered   = "ordered"
+	PurchaseOrderReceived  = "received"
+	PurchaseOrderCancelled = "cancelled"
+)
 
 type InventoryItem struct {
 	ID            int64     `json:"id"`
@@ -77,6 +99,96 @@ type GameDetail struct {
 	BillingSummary BillingSummary         `json:"billing_summary"`
 }
 
+// StockMovement is one entry in an item's stock ledger.
+type StockMovement struct {
+	ID              int64     `json:"id"`
+	VenueID         int64     `json:"venue_id"`
+	InventoryItemID int64     `json:"inventory_item_id"`
+	ItemName        string    `json:"item_name"`
+	Delta           int       `json:"delta"`
+	QuantityAfter   int       `json:"quantity_after"`
+	Reason          string    `json:"reason"`
+	Note            *string   `json:"note,omitempty"`
+	BookingID       *int64    `json:"booking_id,omitempty"`
+	StocktakeID     *int64    `json:"stocktake_id,omitempty"`
+	PurchaseOrderID *int64    `json:"purchase_order_id,omitempty"`
+	CreatedBy       *int64    `json:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type MovementFilter struct {
+	VenueID int64
+	ItemID  *int64
+	Reason  string
+}
+
+// StocktakeLine is one counted item. Expected and Delta are filled in when
+// the stocktake is saved.
+type StocktakeLine struct {
+	InventoryItemID int64   `json:"inventory_item_id"`
+	ItemName        string  `json:"item_name"`
+	Expected        int     `json:"expected"`
+	Counted         int     `json:"counted"`
+	Delta           int     `json:"delta"`
+	Reason          *string `json:"reason,omitempty"`
+}
+
+type Stocktake struct {
+	ID        int64           `json:"id"`
+	VenueID   int64           `json:"venue_id"`
+	Note      *string         `json:"note,omitempty"`
+	CreatedBy int64           `json:"created_by"`
+	CreatedAt time.Time       `json:"created_at"`
+	Lines     []StocktakeLine `json:"lines"`
+}
+
+type Supplier struct {
+	ID        int64     `json:"id"`
+	VenueID   int64     `json:"venue_id"`
+	Name      string    `json:"name"`
+	Phone     *string   `json:"phone,omitempty"`
+	Email     *string   `json:"email,omitempty"`
+	Note      *string   `json:"note,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PurchaseOrderLine struct {
+	InventoryItemID int64  `json:"inventory_item_id"`
+	ItemName        string `json:"item_name"`
+	Quantity        int    `json:"quantity"`
+	UnitCost        int    `json:"unit_cost"`
+	LineTotal       int    `json:"line_total"`
+}
+
+type PurchaseOrder struct {
+	ID           int64               `json:"id"`
+	VenueID      int64               `json:"venue_id"`
+	SupplierID   int64               `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	Status       string              `json:"status"`
+	Note         *string             `json:"note,omitempty"`
+	TotalCost    int                 `json:"total_cost"`
+	CreatedBy    *int64              `json:"created_by,omitempty"`
+	OrderedAt    *time.Time          `json:"ordered_at,omitempty"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty"`
+	ReceivedBy   *int64              `json:"received_by,omitempty"`
+	CancelledAt  *time.Time          `json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	Lines        []PurchaseOrderLine `json:"lines,omitempty"`
+}
+
+// Restocked is an item that had run out and is back in stock after a
+// purchase order was received.
+type Restocked struct {
+	VenueID         int64
+	InventoryItemID int64
+	ItemName        string
+	QuantityAfter   int
+}
+
 type Store interface {
 	CreateInventoryItem(ctx context.Context, item *InventoryItem) error
 	ListInventoryItems(ctx context.Context, venueID int64) ([]InventoryItem, error)
@@ -91,4 +203,26 @@ type Store interface {
 	AddItemToBooking(ctx context.Context, venueID, bookingID, inventoryItemID int64, quantity int) (*BookingInventoryItem, error)
 	ListBookingItems(ctx context.Context, venueID, bookingID int64) ([]BookingInventoryItem, error)
 	GetBillingSummary(ctx context.Context, venueID, bookingID int64) (*BillingSummary, error)
+
+	// CreateStocktake sets each counted item's stock to the count and
+	// records the difference in the stock ledger. Counting an item turns on
+	// stock tracking for it.
+	CreateStocktake(ctx context.Context, st *Stocktake) error
+	ListMovements(ctx context.Context, filter MovementFilter, limit, offset int) ([]StockMovement, int, error)
+
+	CreateSupplier(ctx context.Context, sup *Supplier) error
+	ListSuppliers(ctx context.Context, venueID int64) ([]Supplier, error)
+	UpdateSupplier(ctx context.Context, sup *Supplier) error
+
+	// CreatePurchaseOrder saves a draft. Lines must name items of the venue.
+	CreatePurchaseOrder(ctx context.Context, po *PurchaseOrder) error
+	ListPurchaseOrders(ctx context.Context, venueID int64, status string, limit, offset int) ([]PurchaseOrder, int, error)
+	GetPurchaseOrder(ctx context.Context, venueID, poID int64) (*PurchaseOrder, error)
+	// UpdateDraftPurchaseOrder replaces a draft's supplier, note and lines.
+	UpdateDraftPurchaseOrder(ctx context.Context, po *PurchaseOrder) error
+	MarkPurchaseOrderOrdered(ctx context.Context, venueID, poID int64) error
+	CancelPurchaseOrder(ctx context.Context, venueID, poID int64) error
+	// ReceivePurchaseOrder adds every line to stock with a purchase
+	// movement and returns the items that were out of stock before.
+	ReceivePurchaseOrder(ctx context.Context, venueID, poID, receivedBy int64) ([]Restocked, error)
 }
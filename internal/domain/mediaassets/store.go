@@ -0,0 +1,106 @@
+package mediaassets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// unreferenced holds for an asset aliased a that no row points at.
+const unreferenced = `NOT EXISTS (SELECT 1 FROM media_references r WHERE r.url = a.url)`
+
+func (r *Repository) Record(ctx context.Context, a Asset) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var createdAt *time.Time
+	if !a.CreatedAt.IsZero() {
+		createdAt = &a.CreatedAt
+	}
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO media_assets (url, storage, folder, bytes, created_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5, NOW()))
+		ON CONFLICT (url) DO NOTHING`,
+		a.URL, a.Storage, a.Folder, a.Bytes, createdAt)
+	if err != nil {
+		return fmt.Errorf("record media asset: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Forget(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, `DELETE FROM media_assets WHERE url = $1`, url); err != nil {
+		return fmt.Errorf("forget media asset: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Orphans(ctx context.Context, before time.Time, limit int) ([]Asset, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, a.url, a.storage, a.folder, a.bytes, a.created_at
+		FROM media_assets a
+		WHERE a.created_at < $1 AND `+unreferenced+`
+		ORDER BY a.created_at, a.id
+		LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list orphaned media: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Asset{}
+	for rows.Next() {
+		var a Asset
+		if err := rows.Scan(&a.ID, &a.URL, &a.Storage, &a.Folder, &a.Bytes, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan media asset: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) OrphanReport(ctx context.Context, before time.Time, limit int) (*OrphanReport, error) {
+	assets, err := r.Orphans(ctx, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rep := &OrphanReport{Assets: assets}
+	err = r.db.QueryRow(ctx, `
+		SELECT COUNT(*)::INT, COALESCE(SUM(a.bytes), 0)::BIGINT
+		FROM media_assets a
+		WHERE a.created_at < $1 AND `+unreferenced, before).Scan(&rep.Count, &rep.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("count orphaned media: %w", err)
+	}
+	return rep, nil
+}
+
+func (r *Repository) Claim(ctx context.Context, id int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM media_assets a WHERE a.id = $1 AND `+unreferenced, id)
+	if err != nil {
+		return false, fmt.Errorf("claim media asset: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
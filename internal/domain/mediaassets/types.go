@@ -0,0 +1,44 @@
+package mediaassets
+
+import (
+	"context"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 30
+
+// Asset is one uploaded file.
+type Asset struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Storage   string    `json:"storage"`
+	Folder    string    `json:"folder"`
+	Bytes     int64     `json:"bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrphanReport is what a collection would delete: every orphan counted,
+// the oldest listed.
+type OrphanReport struct {
+	Count  int     `json:"count"`
+	Bytes  int64   `json:"bytes"`
+	Assets []Asset `json:"assets"`
+}
+
+type Store interface {
+	// Record adds an upload to the registry, dated now unless CreatedAt
+	// is set. Recording a URL twice is a no-op.
+	Record(ctx context.Context, a Asset) error
+	// Forget drops url from the registry once the file is deleted.
+	Forget(ctx context.Context, url string) error
+
+	// Orphans lists up to limit files uploaded before the cutoff that no
+	// row points at, oldest first.
+	Orphans(ctx context.Context, before time.Time, limit int) ([]Asset, error)
+	// OrphanReport sums up every orphan uploaded before the cutoff,
+	// listing up to limit.
+	OrphanReport(ctx context.Context, before time.Time, limit int) (*OrphanReport, error)
+	// Claim removes the asset from the registry if it's still an orphan,
+	// reporting whether it did. The file itself is the caller's to delete.
+	Claim(ctx context.Context, id int64) (bool, error)
+}
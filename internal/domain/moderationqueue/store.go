@@ -0,0 +1,125 @@
+package moderationqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Enqueue(ctx context.Context, item *Item) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO moderation_queue (content_type, content_id, user_id, content, reason, checker)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (content_type, content_id) WHERE status = 'pending'
+		DO UPDATE SET content = EXCLUDED.content, reason = EXCLUDED.reason,
+		              checker = EXCLUDED.checker, created_at = NOW()
+		RETURNING id, status, created_at
+	`, item.ContentType, item.ContentID, item.UserID, item.Content, item.Reason, item.Checker).
+		Scan(&item.ID, &item.Status, &item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("enqueue moderation item: %w", err)
+	}
+	return nil
+}
+
+const itemSelect = `
+	SELECT q.id, q.content_type, q.content_id, q.user_id, q.content, q.reason, q.checker, q.status,
+	       q.decided_by, q.decided_at, q.created_at,
+	       COALESCE(TRIM(u.first_name || ' ' || u.last_name), '') AS user_name
+	FROM moderation_queue q
+	LEFT JOIN users u ON u.id = q.user_id`
+
+func itemScanArgs(it *Item) []any {
+	return []any{
+		&it.ID, &it.ContentType, &it.ContentID, &it.UserID, &it.Content, &it.Reason, &it.Checker, &it.Status,
+		&it.DecidedBy, &it.DecidedAt, &it.CreatedAt,
+		&it.UserName,
+	}
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Item, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH filtered AS (` + itemSelect + `
+			WHERE ($1 = '' OR q.status = $1)
+			  AND ($2 = '' OR q.content_type = $2)
+		)
+		SELECT filtered.*, COUNT(*) OVER() AS total_count
+		FROM filtered
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, f.Status, f.ContentType, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list moderation items: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Item{}
+	var total int
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(append(itemScanArgs(&it), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan moderation item: %w", err)
+		}
+		list = append(list, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (*Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var it Item
+	err := r.db.QueryRow(ctx, itemSelect+` WHERE q.id = $1`, id).Scan(itemScanArgs(&it)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("get moderation item: %w", err)
+	}
+	return &it, nil
+}
+
+func (r *Repository) Decide(ctx context.Context, id int64, status string, decidedBy int64) (*Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE moderation_queue
+		SET status = $2, decided_by = $3, decided_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, status, decidedBy)
+	if err != nil {
+		return nil, fmt.Errorf("decide moderation item: %w", err)
+	}
+
+	it, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrAlreadyDecided
+	}
+	return it, nil
+}
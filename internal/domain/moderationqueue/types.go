@@ -0,0 +1,64 @@
+package moderationqueue
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+var (
+	ErrItemNotFound   = errors.New("moderation item not found")
+	ErrAlreadyDecided = errors.New("moderation item is already decided")
+)
+
+// What a queue item's content_id points at.
+const (
+	ContentReview       = "review"
+	ContentGameQuestion = "game_question"
+	ContentGameReply    = "game_reply"
+	ContentProfileName  = "profile_name"
+)
+
+// Statuses. approved keeps the content (publishing a held review); removed
+// takes it down.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRemoved  = "removed"
+)
+
+type Item struct {
+	ID          int64      `json:"id"`
+	ContentType string     `json:"content_type"`
+	ContentID   int64      `json:"content_id"`
+	UserID      *int64     `json:"user_id,omitempty"`
+	Content     string     `json:"content"`
+	Reason      string     `json:"reason"`
+	Checker     string     `json:"checker"`
+	Status      string     `json:"status"`
+	DecidedBy   *int64     `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Author's name, filled on the list.
+	UserName string `json:"user_name,omitempty"`
+}
+
+type Filter struct {
+	Status      string
+	ContentType string
+}
+
+type Store interface {
+	// Enqueue adds the flagged content to the queue. Content already
+	// pending is updated to the newly flagged text instead.
+	Enqueue(ctx context.Context, item *Item) error
+	// List returns items oldest first, so the queue is worked in order,
+	// with the total ignoring limit and offset.
+	List(ctx context.Context, f Filter, limit, offset int) ([]Item, int, error)
+	Get(ctx context.Context, id int64) (*Item, error)
+	// Decide approves or removes a pending item.
+	Decide(ctx context.Context, id int64, status string, decidedBy int64) (*Item, error)
+}
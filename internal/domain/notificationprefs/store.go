@@ -0,0 +1,155 @@
+package notificationprefs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const prefColumns = `
+	booking_updates_push, booking_updates_email,
+	game_invites_push, game_invites_email,
+	marketing_push, marketing_email,
+	reminders_push,
+	locale,
+	updated_at
+`
+
+func scanPreferences(row pgx.Row, p *Preferences) error {
+	return row.Scan(
+		&p.BookingUpdatesPush, &p.BookingUpdatesEmail,
+		&p.GameInvitesPush, &p.GameInvitesEmail,
+		&p.MarketingPush, &p.MarketingEmail,
+		&p.RemindersPush,
+		&p.Locale,
+		&p.UpdatedAt,
+	)
+}
+
+func (r *Repository) Get(ctx context.Context, userID int64) (*Preferences, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var p Preferences
+	err := scanPreferences(r.db.QueryRow(ctx,
+		`SELECT `+prefColumns+` FROM notification_preferences WHERE user_id = $1`, userID,
+	), &p)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			d := Defaults()
+			return &d, nil
+		}
+		return nil, fmt.Errorf("get notification preferences: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *Repository) Update(ctx context.Context, userID int64, upd UpdatePreferences) (*Preferences, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Insert with the column defaults for anything not provided, or patch
+	// the existing row in place.
+	q := `
+		INSERT INTO notification_preferences (
+			user_id,
+			booking_updates_push, booking_updates_email,
+			game_invites_push, game_invites_email,
+			marketing_push, marketing_email,
+			reminders_push,
+			locale
+		)
+		VALUES (
+			$1,
+			COALESCE($2, TRUE), COALESCE($3, TRUE),
+			COALESCE($4, TRUE), COALESCE($5, TRUE),
+			COALESCE($6, TRUE), COALESCE($7, FALSE),
+			COALESCE($8, TRUE),
+			COALESCE($9, 'en')
+		)
+		ON CONFLICT (user_id) DO UPDATE SET
+			booking_updates_push  = COALESCE($2, notification_preferences.booking_updates_push),
+			booking_updates_email = COALESCE($3, notification_preferences.booking_updates_email),
+			game_invites_push     = COALESCE($4, notification_preferences.game_invites_push),
+			game_invites_email    = COALESCE($5, notification_preferences.game_invites_email),
+			marketing_push        = COALESCE($6, notification_preferences.marketing_push),
+			marketing_email       = COALESCE($7, notification_preferences.marketing_email),
+			reminders_push        = COALESCE($8, notification_preferences.reminders_push),
+			locale                = COALESCE($9, notification_preferences.locale),
+			updated_at            = NOW()
+		RETURNING ` + prefColumns
+
+	var p Preferences
+	if err := scanPreferences(r.db.QueryRow(ctx, q, userID,
+		upd.BookingUpdatesPush, upd.BookingUpdatesEmail,
+		upd.GameInvitesPush, upd.GameInvitesEmail,
+		upd.MarketingPush, upd.MarketingEmail,
+		upd.RemindersPush,
+		upd.Locale,
+	), &p); err != nil {
+		return nil, fmt.Errorf("update notification preferences: %w", err)
+	}
+	return &p, nil
+}
+
+// prefColumnsByCategory maps a category/channel pair to its column. The
+// result is interpolated into SQL, so it must only ever come from this table.
+var prefColumnsByCategory = map[Category]map[Channel]string{
+	CategoryBookingUpdates: {ChannelPush: "booking_updates_push", ChannelEmail: "booking_updates_email"},
+	CategoryGameInvites:    {ChannelPush: "game_invites_push", ChannelEmail: "game_invites_email"},
+	CategoryMarketing:      {ChannelPush: "marketing_push", ChannelEmail: "marketing_email"},
+	CategoryReminders:      {ChannelPush: "reminders_push"},
+}
+
+func (r *Repository) FilterAllowed(ctx context.Context, userIDs []int64, category Category, channel Channel) ([]int64, error) {
+	if len(userIDs) == 0 {
+		return []int64{}, nil
+	}
+	col, ok := prefColumnsByCategory[category][channel]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification category %q on channel %q", category, channel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Compare against the row when present, otherwise against the column default.
+	q := fmt.Sprintf(`
+		SELECT u.id
+		FROM unnest($1::bigint[]) AS u(id)
+		LEFT JOIN notification_preferences np ON np.user_id = u.id
+		WHERE COALESCE(np.%s, $2)
+	`, col)
+
+	defaultAllowed := Defaults().Allows(category, channel)
+
+	rows, err := r.db.Query(ctx, q, userIDs, defaultAllowed)
+	if err != nil {
+		return nil, fmt.Errorf("filter notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	allowed := make([]int64, 0, len(userIDs))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan user id: %w", err)
+		}
+		allowed = append(allowed, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return allowed, nil
+}
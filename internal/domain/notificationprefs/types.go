@@ -0,0 +1,100 @@
+package notificationprefs
+
+import (
+	"context"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// Category groups notifications a user can opt out of. Transactional
+// messages (account emails, support replies) have no category and are
+// always sent.
+type Category string
+
+const (
+	// CategoryBookingUpdates covers booking requests, confirmations and cancellations.
+	CategoryBookingUpdates Category = "booking_updates"
+	// CategoryGameInvites covers join requests, their outcome, game
+	// cancellations and game Q&A.
+	CategoryGameInvites Category = "game_invites"
+	// CategoryMarketing covers venue announcements and promotions.
+	CategoryMarketing Category = "marketing"
+	// CategoryReminders covers start-time reminders for bookings and games
+	// and review invitations after a booking. They are push only.
+	CategoryReminders Category = "reminders"
+)
+
+type Channel string
+
+const (
+	ChannelPush  Channel = "push"
+	ChannelEmail Channel = "email"
+)
+
+// Preferences mirrors the notification_preferences row. Defaults apply
+// until the user saves their first change.
+type Preferences struct {
+	BookingUpdatesPush  bool `json:"booking_updates_push"`
+	BookingUpdatesEmail bool `json:"booking_updates_email"`
+	GameInvitesPush     bool `json:"game_invites_push"`
+	GameInvitesEmail    bool `json:"game_invites_email"`
+	MarketingPush       bool `json:"marketing_push"`
+	MarketingEmail      bool `json:"marketing_email"`
+	RemindersPush       bool `json:"reminders_push"`
+	// Locale picks the language of emails: "en" or "ne".
+	Locale    string    `json:"locale"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Defaults are what a user without a saved row gets; keep in sync with the
+// column defaults in the migration.
+func Defaults() Preferences {
+	return Preferences{
+		BookingUpdatesPush:  true,
+		BookingUpdatesEmail: true,
+		GameInvitesPush:     true,
+		GameInvitesEmail:    true,
+		MarketingPush:       true,
+		MarketingEmail:      false,
+		RemindersPush:       true,
+		Locale:              "en",
+	}
+}
+
+// Allows reports whether these preferences accept the category on the channel.
+func (p Preferences) Allows(category Category, channel Channel) bool {
+	push := channel == ChannelPush
+	switch category {
+	case CategoryBookingUpdates:
+		return (push && p.BookingUpdatesPush) || (!push && p.BookingUpdatesEmail)
+	case CategoryGameInvites:
+		return (push && p.GameInvitesPush) || (!push && p.GameInvitesEmail)
+	case CategoryMarketing:
+		return (push && p.MarketingPush) || (!push && p.MarketingEmail)
+	case CategoryReminders:
+		return push && p.RemindersPush
+	}
+	return false
+}
+
+// UpdatePreferences is a partial update; nil fields keep their current value.
+type UpdatePreferences struct {
+	BookingUpdatesPush  *bool
+	BookingUpdatesEmail *bool
+	GameInvitesPush     *bool
+	GameInvitesEmail    *bool
+	MarketingPush       *bool
+	MarketingEmail      *bool
+	RemindersPush       *bool
+	Locale              *string
+}
+
+type Store interface {
+	Get(ctx context.Context, userID int64) (*Preferences, error)
+	Update(ctx context.Context, userID int64, upd UpdatePreferences) (*Preferences, error)
+
+	// FilterAllowed returns the subset of userIDs that accept the category on
+	// the channel. Users without a saved row are judged by the defaults.
+	FilterAllowed(ctx context.Context, userIDs []int64, category Category, channel Channel) ([]int64, error)
+}
@@ -7,6 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"khel/internal/infra/dbx"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type Repository struct {
@@ -45,6 +49,7 @@ FROM orders WHERE id=$1`, id).
 // - The result is SNAPSHOTTED into:
 //   - orders.subtotal_cents / orders.discount_cents / orders.total_cents
 //   - order_items.unit_price_cents (final unit price after discount)
+//   - order_items.unit_cost_cents (the variant's cost price, for margins)
 //
 // This ensures the payment gateway amount matches what the user is intended to pay.
 //
@@ -233,7 +238,8 @@ WITH cart_lines AS (
     p.id           AS product_id,
     p.name         AS product_name,
     pv.attributes  AS variant_attributes,
-    pv.price_cents AS list_unit_price_cents
+    pv.price_cents AS list_unit_price_cents,
+    pv.cost_price_cents AS unit_cost_cents
   FROM cart_items ci
   JOIN product_variants pv ON pv.id = ci.product_variant_id
   JOIN products p          ON p.id  = pv.product_id
@@ -278,6 +284,7 @@ priced AS (
     cl.product_name,
     cl.variant_attributes,
     cl.quantity,
+    cl.unit_cost_cents,
 
     CASE
       WHEN bd.deal_price_cents IS NOT NULL
@@ -298,7 +305,7 @@ priced AS (
 )
 INSERT INTO order_items (
   order_id, product_id, product_variant_id, product_name, variant_attributes,
-  quantity, unit_price_cents, total_price_cents
+  quantity, unit_price_cents, total_price_cents, unit_cost_cents
 )
 SELECT
   $2,
@@ -308,7 +315,8 @@ SELECT
   variant_attributes,
   quantity,
   final_unit_price_cents,
-  quantity * final_unit_price_cents
+  quantity * final_unit_price_cents,
+  unit_cost_cents
 FROM priced;
 	`, cartID, o.ID); err != nil {
 		return nil, 0, fmt.Errorf("copy order_items (priced): %w", err)
@@ -603,3 +611,98 @@ WHERE id = $1`,
 	}
 	return nil
 }
+
+func (r *Repository) CancelByUser(ctx context.Context, userID, orderID int64, window time.Duration, reason *string) (*Order, error) {
+	var o Order
+	err := r.q.QueryRow(ctx, `
+SELECT id,user_id,order_number,status,payment_status,payment_method,paid_at,
+       subtotal_cents,discount_cents,tax_cents,shipping_cents,total_cents,created_at
+FROM orders
+WHERE id=$1 AND user_id=$2
+FOR UPDATE`,
+		orderID, userID,
+	).Scan(
+		&o.ID, &o.UserID, &o.OrderNumber, &o.Status, &o.PaymentStatus, &o.PaymentMethod, &o.PaidAt,
+		&o.SubtotalCents, &o.DiscountCents, &o.TaxCents, &o.ShippingCents, &o.TotalCents, &o.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("lock order: %w", err)
+	}
+
+	if !slices.Contains(cancellableStatuses, o.Status) {
+		return nil, ErrNotCancellable
+	}
+	if time.Since(o.CreatedAt) > window {
+		return nil, ErrCancelWindowClosed
+	}
+
+	if err := r.UpdateStatus(ctx, orderID, "cancelled", UpdateStatusOpts{CancelledReason: reason}); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// MarkRefunded records that the order's payment went back to the buyer.
+func (r *Repository) MarkRefunded(ctx context.Context, orderID int64) error {
+	_, err := r.q.Exec(ctx, `
+UPDATE orders
+SET payment_status = 'refunded'::payment_status,
+    updated_at     = now()
+WHERE id = $1`, orderID)
+	if err != nil {
+		return fmt.Errorf("mark order refunded: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) VariantSales(ctx context.Context, from, to time.Time) ([]VariantSales, error) {
+	rows, err := r.q.Query(ctx, `
+SELECT
+  oi.product_variant_id,
+  MAX(oi.product_name) AS product_name,
+  (ARRAY_AGG(oi.variant_attributes ORDER BY oi.id DESC))[1] AS variant_attributes,
+  SUM(oi.quantity) AS units_sold,
+  SUM(oi.total_price_cents) AS revenue_cents,
+  COALESCE(SUM(oi.quantity * oi.unit_cost_cents), 0) AS cost_cents,
+  COALESCE(SUM(oi.total_price_cents) FILTER (WHERE oi.unit_cost_cents IS NOT NULL), 0) AS costed_revenue_cents,
+  COALESCE(SUM(oi.quantity) FILTER (WHERE oi.unit_cost_cents IS NULL), 0) AS units_without_cost
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+WHERE o.status IN ('processing','shipped','delivered')
+  AND o.created_at >= $1 AND o.created_at < $2
+GROUP BY oi.product_variant_id
+ORDER BY revenue_cents DESC`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("variant sales: %w", err)
+	}
+	defer rows.Close()
+
+	out := []VariantSales{}
+	for rows.Next() {
+		var (
+			v             VariantSales
+			attrs         []byte
+			costedRevenue int64
+		)
+		if err := rows.Scan(&v.ProductVariantID, &v.ProductName, &attrs, &v.UnitsSold, &v.RevenueCents,
+			&v.CostCents, &costedRevenue, &v.UnitsWithoutCost); err != nil {
+			return nil, fmt.Errorf("scan variant sales: %w", err)
+		}
+		if len(attrs) > 0 {
+			_ = json.Unmarshal(attrs, &v.VariantAttrs)
+		}
+		v.MarginCents = costedRevenue - v.CostCents
+		if costedRevenue > 0 {
+			pct := float64(v.MarginCents) * 100 / float64(costedRevenue)
+			v.MarginPercent = &pct
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
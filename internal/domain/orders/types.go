@@ -2,9 +2,20 @@ package orders
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+var (
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrNotCancellable     = errors.New("order can no longer be cancelled")
+	ErrCancelWindowClosed = errors.New("the cancellation window for this order has closed")
+)
+
+// cancellableStatuses are the states a buyer may cancel from: anything not
+// yet shipped, delivered or already closed.
+var cancellableStatuses = []string{"pending", "awaiting_payment", "payment_failed", "processing"}
+
 type Order struct {
 	ID            int64      `json:"id"`
 	UserID        int64      `json:"user_id"`
@@ -43,6 +54,21 @@ type OrderItem struct {
 	TotalPriceCents  int64          `json:"total_price_cents"`
 }
 
+// VariantSales is one variant's sales and margin over a period. Revenue is
+// after discounts. Cost and margin only cover units whose cost was known at
+// checkout; UnitsWithoutCost says how many were left out.
+type VariantSales struct {
+	ProductVariantID *int64   `json:"product_variant_id,omitempty"`
+	ProductName      string   `json:"product_name"`
+	VariantAttrs     any      `json:"variant_attributes"`
+	UnitsSold        int64    `json:"units_sold"`
+	RevenueCents     int64    `json:"revenue_cents"`
+	CostCents        int64    `json:"cost_cents"`
+	MarginCents      int64    `json:"margin_cents"`
+	MarginPercent    *float64 `json:"margin_percent,omitempty"`
+	UnitsWithoutCost int64    `json:"units_without_cost"`
+}
+
 // Detailed view: order + items
 type OrderDetail struct {
 	Order Order       `json:"order"`
@@ -69,4 +95,16 @@ type Store interface {
 	ListAll(ctx context.Context, status string, limit, offset int) ([]Order, int, error)
 	GetDetail(ctx context.Context, orderID int64) (*OrderDetail, error)
 	UpdateStatus(ctx context.Context, orderID int64, status string, opts UpdateStatusOpts) error
+
+	// CancelByUser cancels the buyer's own unshipped order if it was placed
+	// within window. It returns the order as it was before cancelling, so
+	// the caller can see whether it was paid. Assumes this is called INSIDE
+	// a transaction.
+	CancelByUser(ctx context.Context, userID, orderID int64, window time.Duration, reason *string) (*Order, error)
+	MarkRefunded(ctx context.Context, orderID int64) error
+
+	// VariantSales reports sales per variant for orders placed in [from, to)
+	// that went through: processing, shipped or delivered. Highest revenue
+	// first.
+	VariantSales(ctx context.Context, from, to time.Time) ([]VariantSales, error)
 }
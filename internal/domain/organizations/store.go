@@ -0,0 +1,392 @@
+package organizations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const orgColumns = `
+	o.id, o.name, o.owner_id, o.payout_bank_name, o.payout_account_name, o.payout_account_number,
+	o.display_name, o.brand_color, o.logo_url,
+	(SELECT COUNT(*) FROM venues v WHERE v.organization_id = o.id)::int,
+	o.created_at, o.updated_at`
+
+func scanOrg(row pgx.Row, o *Organization, extra ...any) error {
+	dest := []any{
+		&o.ID, &o.Name, &o.OwnerID, &o.PayoutBankName, &o.PayoutAccountName, &o.PayoutAccountNumber,
+		&o.DisplayName, &o.BrandColor, &o.LogoURL, &o.VenueCount, &o.CreatedAt, &o.UpdatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func (r *Repository) Create(ctx context.Context, org *Organization) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO organizations (name, owner_id, payout_bank_name, payout_account_name, payout_account_number)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at, updated_at
+		`, org.Name, org.OwnerID, org.PayoutBankName, org.PayoutAccountName, org.PayoutAccountNumber).
+			Scan(&org.ID, &org.CreatedAt, &org.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("create organization: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO organization_members (organization_id, user_id, role)
+			VALUES ($1, $2, $3)
+		`, org.ID, org.OwnerID, RoleOwner)
+		if err != nil {
+			return fmt.Errorf("add organization owner: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) GetByID(ctx context.Context, orgID int64) (*Organization, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var o Organization
+	err := scanOrg(r.db.QueryRow(ctx, `SELECT `+orgColumns+` FROM organizations o WHERE o.id = $1`, orgID), &o)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+	return &o, nil
+}
+
+func (r *Repository) ListForUser(ctx context.Context, userID int64) ([]Organization, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+orgColumns+`, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Organization{}
+	for rows.Next() {
+		var o Organization
+		if err := scanOrg(rows, &o, &o.Role); err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		list = append(list, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Update(ctx context.Context, orgID int64, in UpdateInput) (*Organization, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE organizations
+		SET name = COALESCE($2, name),
+		    payout_bank_name = COALESCE($3, payout_bank_name),
+		    payout_account_name = COALESCE($4, payout_account_name),
+		    payout_account_number = COALESCE($5, payout_account_number),
+		    display_name = COALESCE($6, display_name),
+		    brand_color = COALESCE($7, brand_color),
+		    updated_at = NOW()
+		WHERE id = $1
+	`, orgID, in.Name, in.PayoutBankName, in.PayoutAccountName, in.PayoutAccountNumber, in.DisplayName, in.BrandColor)
+	if err != nil {
+		return nil, fmt.Errorf("update organization: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+	return r.GetByID(ctx, orgID)
+}
+
+func (r *Repository) SetLogo(ctx context.Context, orgID int64, logoURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var old *string
+	err := r.db.QueryRow(ctx, `
+		UPDATE organizations o
+		SET logo_url = $2, updated_at = NOW()
+		FROM (SELECT logo_url FROM organizations WHERE id = $1 FOR UPDATE) prev
+		WHERE o.id = $1
+		RETURNING prev.logo_url
+	`, orgID, logoURL).Scan(&old)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("set organization logo: %w", err)
+	}
+	if old == nil {
+		return "", nil
+	}
+	return *old, nil
+}
+
+func (r *Repository) MemberRole(ctx context.Context, orgID, userID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var role string
+	err := r.db.QueryRow(ctx, `
+		SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2
+	`, orgID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotMember
+		}
+		return "", fmt.Errorf("get member role: %w", err)
+	}
+	return role, nil
+}
+
+func (r *Repository) ListMembers(ctx context.Context, orgID int64) ([]Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.first_name, u.last_name, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'manager' THEN 1 ELSE 2 END, u.first_name
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Member{}
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.UserID, &m.FirstName, &m.LastName, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		list = append(list, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) AddMember(ctx context.Context, orgID, userID int64, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`, orgID, userID, role)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return ErrAlreadyMember
+			case "23503":
+				return ErrMemberNotFound
+			}
+		}
+		return fmt.Errorf("add member: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemberRole changes a member's role. The owner's role is tied to
+// organizations.owner_id and cannot be changed here.
+func (r *Repository) UpdateMemberRole(ctx context.Context, orgID, userID int64, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE organization_members
+		SET role = $3
+		WHERE organization_id = $1 AND user_id = $2 AND role <> 'owner'
+	`, orgID, userID, role)
+	if err != nil {
+		return fmt.Errorf("update member role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+func (r *Repository) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var role string
+	err := r.db.QueryRow(ctx, `
+		DELETE FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2 AND role <> 'owner'
+		RETURNING role
+	`, orgID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if owner, _ := r.MemberRole(ctx, orgID, userID); owner == RoleOwner {
+				return ErrLastOwner
+			}
+			return ErrMemberNotFound
+		}
+		return fmt.Errorf("remove member: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) AttachVenue(ctx context.Context, orgID, venueID, ownerID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE venues
+		SET organization_id = $1, updated_at = NOW()
+		WHERE id = $2 AND owner_id = $3
+	`, orgID, venueID, ownerID)
+	if err != nil {
+		return fmt.Errorf("attach venue: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrVenueNotOwned
+	}
+	return nil
+}
+
+func (r *Repository) DetachVenue(ctx context.Context, orgID, venueID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE venues
+		SET organization_id = NULL, updated_at = NOW()
+		WHERE id = $2 AND organization_id = $1
+	`, orgID, venueID)
+	if err != nil {
+		return fmt.Errorf("detach venue: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrVenueNotInOrg
+	}
+	return nil
+}
+
+func (r *Repository) ListVenues(ctx context.Context, orgID int64) ([]Venue, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, address, status::text, owner_id
+		FROM venues
+		WHERE organization_id = $1
+		ORDER BY name
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list organization venues: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Venue{}
+	for rows.Next() {
+		var v Venue
+		if err := rows.Scan(&v.ID, &v.Name, &v.Address, &v.Status, &v.OwnerID); err != nil {
+			return nil, fmt.Errorf("scan venue: %w", err)
+		}
+		list = append(list, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Earnings(ctx context.Context, orgID int64, from, to time.Time) ([]VenueEarnings, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Same money rules as venueearnings, grouped per venue.
+	rows, err := r.db.Query(ctx, `
+		WITH filtered AS (
+			SELECT
+				b.venue_id,
+				b.payment_method,
+				COALESCE(b.total_price, 0) AS slot_earning,
+				COALESCE(b.final_amount, b.paid_amount, b.total_price, 0) AS total_earning
+			FROM bookings b
+			JOIN venues v ON v.id = b.venue_id
+			WHERE v.organization_id = $1
+			  AND b.status = 'done'
+			  AND b.paid_at IS NOT NULL
+			  AND b.paid_at >= $2
+			  AND b.paid_at < $3
+		)
+		SELECT
+			v.id,
+			v.name,
+			COUNT(f.venue_id)::INT,
+			COALESCE(SUM(f.slot_earning), 0)::INT,
+			COALESCE(SUM(GREATEST(f.total_earning - f.slot_earning, 0)), 0)::INT,
+			COALESCE(SUM(f.total_earning), 0)::INT,
+			COALESCE(SUM(f.total_earning) FILTER (
+				WHERE LOWER(COALESCE(f.payment_method, '')) = 'cash'
+			), 0)::INT,
+			COALESCE(SUM(f.total_earning) FILTER (
+				WHERE LOWER(COALESCE(f.payment_method, '')) IN ('online', 'card', 'stripe', 'esewa', 'khalti')
+			), 0)::INT
+		FROM venues v
+		LEFT JOIN filtered f ON f.venue_id = v.id
+		WHERE v.organization_id = $1
+		GROUP BY v.id, v.name
+		ORDER BY v.name
+	`, orgID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("organization earnings: %w", err)
+	}
+	defer rows.Close()
+
+	list := []VenueEarnings{}
+	for rows.Next() {
+		var e VenueEarnings
+		if err := rows.Scan(
+			&e.VenueID, &e.VenueName, &e.TotalBookings, &e.SlotEarning, &e.InventoryEarning,
+			&e.TotalEarning, &e.CashEarning, &e.OnlineEarning,
+		); err != nil {
+			return nil, fmt.Errorf("scan venue earnings: %w", err)
+		}
+		list = append(list, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
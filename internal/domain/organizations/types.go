@@ -0,0 +1,130 @@
+package organizations
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+const (
+	RoleOwner   = "owner"
+	RoleManager = "manager"
+	RoleStaff   = "staff"
+)
+
+var (
+	ErrNotFound       = errors.New("organization not found")
+	ErrNotMember      = errors.New("user is not a member of this organization")
+	ErrMemberNotFound = errors.New("member not found")
+	ErrAlreadyMember  = errors.New("user is already a member")
+	ErrLastOwner      = errors.New("the organization owner cannot be removed")
+	ErrVenueNotOwned  = errors.New("venue not found or not owned by you")
+	ErrVenueNotInOrg  = errors.New("venue does not belong to this organization")
+)
+
+type Organization struct {
+	ID                  int64     `json:"id"`
+	Name                string    `json:"name"`
+	OwnerID             int64     `json:"owner_id"`
+	PayoutBankName      *string   `json:"payout_bank_name,omitempty"`
+	PayoutAccountName   *string   `json:"payout_account_name,omitempty"`
+	PayoutAccountNumber *string   `json:"payout_account_number,omitempty"`
+	DisplayName         *string   `json:"display_name,omitempty"`
+	BrandColor          *string   `json:"brand_color,omitempty"`
+	LogoURL             *string   `json:"logo_url,omitempty"`
+	VenueCount          int       `json:"venue_count"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	// Role is the requesting user's role; only set on listings for a user.
+	Role string `json:"role,omitempty"`
+}
+
+type UpdateInput struct {
+	Name                *string
+	PayoutBankName      *string
+	PayoutAccountName   *string
+	PayoutAccountNumber *string
+	DisplayName         *string
+	BrandColor          *string
+}
+
+type Member struct {
+	UserID    int64     `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Venue struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	OwnerID int64  `json:"owner_id"`
+}
+
+// VenueEarnings is one venue's line on the organization dashboard. Amounts
+// follow venueearnings: total includes inventory sold during the game.
+type VenueEarnings struct {
+	VenueID          int64  `json:"venue_id"`
+	VenueName        string `json:"venue_name"`
+	TotalBookings    int    `json:"total_bookings"`
+	SlotEarning      int    `json:"slot_earning"`
+	InventoryEarning int    `json:"inventory_earning"`
+	TotalEarning     int    `json:"total_earning"`
+	CashEarning      int    `json:"cash_earning"`
+	OnlineEarning    int    `json:"online_earning"`
+}
+
+type Dashboard struct {
+	OrganizationID int64           `json:"organization_id"`
+	StartDate      time.Time       `json:"start_date"`
+	EndDate        time.Time       `json:"end_date"`
+	TotalBookings  int             `json:"total_bookings"`
+	TotalEarning   int             `json:"total_earning"`
+	Venues         []VenueEarnings `json:"venues"`
+}
+
+// Settlement is what the platform owes the organization for a period: money
+// collected online. Cash was taken at the venue and is shown for reference.
+type Settlement struct {
+	OrganizationID      int64           `json:"organization_id"`
+	StartDate           time.Time       `json:"start_date"`
+	EndDate             time.Time       `json:"end_date"`
+	PayoutBankName      *string         `json:"payout_bank_name,omitempty"`
+	PayoutAccountName   *string         `json:"payout_account_name,omitempty"`
+	PayoutAccountNumber *string         `json:"payout_account_number,omitempty"`
+	Payable             int             `json:"payable"`
+	CollectedAtVenue    int             `json:"collected_at_venue"`
+	Venues              []VenueEarnings `json:"venues"`
+}
+
+type Store interface {
+	// Create inserts the organization and makes ownerID its owner member.
+	Create(ctx context.Context, org *Organization) error
+	GetByID(ctx context.Context, orgID int64) (*Organization, error)
+	ListForUser(ctx context.Context, userID int64) ([]Organization, error)
+	Update(ctx context.Context, orgID int64, in UpdateInput) (*Organization, error)
+	// SetLogo stores the logo URL and returns the one it replaced, if any.
+	SetLogo(ctx context.Context, orgID int64, logoURL string) (string, error)
+
+	// MemberRole returns the user's role or ErrNotMember.
+	MemberRole(ctx context.Context, orgID, userID int64) (string, error)
+	ListMembers(ctx context.Context, orgID int64) ([]Member, error)
+	AddMember(ctx context.Context, orgID, userID int64, role string) error
+	UpdateMemberRole(ctx context.Context, orgID, userID int64, role string) error
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+
+	// AttachVenue moves a venue owned by ownerID into the organization.
+	AttachVenue(ctx context.Context, orgID, venueID, ownerID int64) error
+	DetachVenue(ctx context.Context, orgID, venueID int64) error
+	ListVenues(ctx context.Context, orgID int64) ([]Venue, error)
+
+	// Earnings returns per-venue earnings for completed, paid bookings with
+	// paid_at in [from, to).
+	Earnings(ctx context.Context, orgID int64, from, to time.Time) ([]VenueEarnings, error)
+}
@@ -0,0 +1,350 @@
+package paymentsplits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/domain/bookings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, bookingID, createdBy int64, totalAmount int, shares []ShareInput) (*Split, error) {
+	sum := 0
+	for _, s := range shares {
+		sum += s.Amount
+	}
+	if sum != totalAmount {
+		return nil, ErrAmountMismatch
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		// A canceled split can be replaced by a new one, unless a late payment
+		// landed on it: deleting it would cascade away the record of that money.
+		var paidShares int
+		err := tx.QueryRow(ctx, `
+			SELECT COUNT(*)::int
+			FROM payment_splits s
+			JOIN payment_split_shares sh ON sh.split_id = s.id AND sh.status = 'paid'
+			WHERE s.booking_id = $1 AND s.status = 'canceled'
+		`, bookingID).Scan(&paidShares)
+		if err != nil {
+			return fmt.Errorf("check canceled split: %w", err)
+		}
+		if paidShares > 0 {
+			return ErrSharesPaid
+		}
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM payment_splits WHERE booking_id = $1 AND status = 'canceled'
+		`, bookingID); err != nil {
+			return fmt.Errorf("clear canceled split: %w", err)
+		}
+
+		var splitID int64
+		err = tx.QueryRow(ctx, `
+			INSERT INTO payment_splits (booking_id, created_by, total_amount)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, bookingID, createdBy, totalAmount).Scan(&splitID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrAlreadyExists
+			}
+			return fmt.Errorf("create payment split: %w", err)
+		}
+
+		for _, s := range shares {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO payment_split_shares (split_id, user_id, amount)
+				VALUES ($1, $2, $3)
+			`, splitID, s.UserID, s.Amount)
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+					return ErrUserNotFound
+				}
+				return fmt.Errorf("create share: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByBooking(ctx, bookingID)
+}
+
+func (r *Repository) GetByBooking(ctx context.Context, bookingID int64) (*Split, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var s Split
+	err := r.db.QueryRow(ctx, `
+		SELECT id, booking_id, created_by, total_amount, status, paid_at, created_at, updated_at
+		FROM payment_splits
+		WHERE booking_id = $1
+	`, bookingID).Scan(&s.ID, &s.BookingID, &s.CreatedBy, &s.TotalAmount, &s.Status, &s.PaidAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get payment split: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT sh.id, sh.split_id, sh.user_id, u.first_name, u.last_name, sh.amount, sh.status,
+		       sh.provider, sh.provider_ref, sh.paid_at, sh.last_reminded_at, sh.created_at
+		FROM payment_split_shares sh
+		JOIN users u ON u.id = sh.user_id
+		WHERE sh.split_id = $1
+		ORDER BY sh.id
+	`, s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list shares: %w", err)
+	}
+	defer rows.Close()
+
+	s.Shares = []Share{}
+	for rows.Next() {
+		var sh Share
+		if err := rows.Scan(
+			&sh.ID, &sh.SplitID, &sh.UserID, &sh.FirstName, &sh.LastName, &sh.Amount, &sh.Status,
+			&sh.Provider, &sh.ProviderRef, &sh.PaidAt, &sh.LastRemindedAt, &sh.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan share: %w", err)
+		}
+		sh.BookingID = s.BookingID
+		if sh.Status == SharePaid {
+			s.PaidAmount += sh.Amount
+		}
+		s.Shares = append(s.Shares, sh)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *Repository) GetShare(ctx context.Context, shareID int64) (*Share, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var sh Share
+	err := r.db.QueryRow(ctx, `
+		SELECT sh.id, sh.split_id, s.booking_id, sh.user_id, u.first_name, u.last_name, sh.amount, sh.status,
+		       sh.provider, sh.provider_ref, sh.paid_at, sh.last_reminded_at, sh.created_at
+		FROM payment_split_shares sh
+		JOIN payment_splits s ON s.id = sh.split_id
+		JOIN users u ON u.id = sh.user_id
+		WHERE sh.id = $1
+	`, shareID).Scan(
+		&sh.ID, &sh.SplitID, &sh.BookingID, &sh.UserID, &sh.FirstName, &sh.LastName, &sh.Amount, &sh.Status,
+		&sh.Provider, &sh.ProviderRef, &sh.PaidAt, &sh.LastRemindedAt, &sh.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShareNotFound
+		}
+		return nil, fmt.Errorf("get share: %w", err)
+	}
+	return &sh, nil
+}
+
+func (r *Repository) Cancel(ctx context.Context, bookingID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var status string
+	var paidShares int
+	err := r.db.QueryRow(ctx, `
+		SELECT s.status,
+		       (SELECT COUNT(*) FROM payment_split_shares sh WHERE sh.split_id = s.id AND sh.status = 'paid')::int
+		FROM payment_splits s
+		WHERE s.booking_id = $1
+	`, bookingID).Scan(&status, &paidShares)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get payment split: %w", err)
+	}
+	if status != StatusOpen {
+		return ErrSplitClosed
+	}
+	if paidShares > 0 {
+		return ErrSharesPaid
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE payment_splits s
+		SET status = 'canceled', updated_at = NOW()
+		WHERE s.booking_id = $1
+		  AND s.status = 'open'
+		  AND NOT EXISTS (
+			SELECT 1 FROM payment_split_shares sh WHERE sh.split_id = s.id AND sh.status = 'paid'
+		  )
+	`, bookingID)
+	if err != nil {
+		return fmt.Errorf("cancel payment split: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// A share was paid between the check and the update.
+		return ErrSharesPaid
+	}
+	return nil
+}
+
+func (r *Repository) SetShareProvider(ctx context.Context, shareID int64, provider, ref string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE payment_split_shares
+		SET provider = $2, provider_ref = NULLIF($3, ''), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, shareID, provider, ref)
+	if err != nil {
+		return fmt.Errorf("set share provider: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) MarkSharePaid(ctx context.Context, shareID int64) (*PaidResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var res PaidResult
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		// Lock the split so concurrent payments settle it exactly once.
+		var shareStatus, splitStatus string
+		err := tx.QueryRow(ctx, `
+			SELECT sh.status, s.status, s.id, s.booking_id
+			FROM payment_split_shares sh
+			JOIN payment_splits s ON s.id = sh.split_id
+			WHERE sh.id = $1
+			FOR UPDATE OF s
+		`, shareID).Scan(&shareStatus, &splitStatus, &res.SplitID, &res.BookingID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrShareNotFound
+			}
+			return fmt.Errorf("lock payment split: %w", err)
+		}
+		if shareStatus == SharePaid {
+			return nil
+		}
+
+		// The money has been taken, so a late payment on a canceled split is
+		// still recorded; it just doesn't settle anything.
+		if _, err := tx.Exec(ctx, `
+			UPDATE payment_split_shares
+			SET status = 'paid', paid_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+		`, shareID); err != nil {
+			return fmt.Errorf("mark share paid: %w", err)
+		}
+		if splitStatus != StatusOpen {
+			return nil
+		}
+
+		var unpaid int
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM payment_split_shares WHERE split_id = $1 AND status = 'pending'
+		`, res.SplitID).Scan(&unpaid); err != nil {
+			return fmt.Errorf("count unpaid shares: %w", err)
+		}
+		if unpaid > 0 {
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE payment_splits
+			SET status = 'paid', paid_at = NOW(), updated_at = NOW()
+			WHERE id = $1
+		`, res.SplitID); err != nil {
+			return fmt.Errorf("settle payment split: %w", err)
+		}
+		res.Settled = true
+
+		if err := bookings.Attribute(ctx, tx, 0, bookings.SourcePaymentSplit); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE bookings
+			SET status = 'confirmed', updated_at = NOW()
+			WHERE id = $1 AND status = 'pending'
+		`, res.BookingID)
+		if err != nil {
+			return fmt.Errorf("confirm booking: %w", err)
+		}
+		res.Confirmed = tag.RowsAffected() > 0
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *Repository) ClaimReminders(ctx context.Context, every time.Duration, limit int) ([]Reminder, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		WITH due AS (
+			SELECT sh.id
+			FROM payment_split_shares sh
+			JOIN payment_splits s ON s.id = sh.split_id
+			JOIN bookings b ON b.id = s.booking_id
+			WHERE sh.status = 'pending'
+			  AND s.status = 'open'
+			  AND b.status IN ('pending', 'confirmed')
+			  AND b.start_time > NOW()
+			  AND COALESCE(sh.last_reminded_at, sh.created_at) <= NOW() - make_interval(secs => $1)
+			ORDER BY b.start_time
+			LIMIT $2
+			FOR UPDATE OF sh SKIP LOCKED
+		)
+		UPDATE payment_split_shares sh
+		SET last_reminded_at = NOW()
+		FROM due, payment_splits s, bookings b, venues v
+		WHERE sh.id = due.id
+		  AND s.id = sh.split_id
+		  AND b.id = s.booking_id
+		  AND v.id = b.venue_id
+		RETURNING sh.id, s.id, b.id, sh.user_id, sh.amount, v.name, b.start_time
+	`, every.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim split reminders: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Reminder{}
+	for rows.Next() {
+		var rm Reminder
+		if err := rows.Scan(&rm.ShareID, &rm.SplitID, &rm.BookingID, &rm.UserID, &rm.Amount, &rm.VenueName, &rm.StartTime); err != nil {
+			return nil, fmt.Errorf("scan split reminder: %w", err)
+		}
+		list = append(list, rm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
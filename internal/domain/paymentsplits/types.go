@@ -0,0 +1,112 @@
+package paymentsplits
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+const (
+	StatusOpen     = "open"
+	StatusPaid     = "paid"
+	StatusCanceled = "canceled"
+
+	SharePending = "pending"
+	SharePaid    = "paid"
+)
+
+// MaxShares caps how many players one booking can be split between.
+const MaxShares = 30
+
+var (
+	ErrNotFound       = errors.New("payment split not found")
+	ErrShareNotFound  = errors.New("share not found")
+	ErrAlreadyExists  = errors.New("booking already has a payment split")
+	ErrAlreadyPaid    = errors.New("share is already paid")
+	ErrSplitClosed    = errors.New("payment split is no longer open")
+	ErrSharesPaid     = errors.New("payment split has paid shares")
+	ErrUserNotFound   = errors.New("participant not found")
+	ErrAmountMismatch = errors.New("shares must add up to the booking price")
+)
+
+type Share struct {
+	ID             int64      `json:"id"`
+	SplitID        int64      `json:"split_id"`
+	BookingID      int64      `json:"booking_id"`
+	UserID         int64      `json:"user_id"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	Amount         int        `json:"amount"`
+	Status         string     `json:"status"`
+	Provider       *string    `json:"provider,omitempty"`
+	ProviderRef    *string    `json:"-"`
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+	LastRemindedAt *time.Time `json:"last_reminded_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type Split struct {
+	ID          int64      `json:"id"`
+	BookingID   int64      `json:"booking_id"`
+	CreatedBy   int64      `json:"created_by"`
+	TotalAmount int        `json:"total_amount"`
+	PaidAmount  int        `json:"paid_amount"`
+	Status      string     `json:"status"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Shares      []Share    `json:"shares"`
+}
+
+// ShareInput is one participant's part of a new split.
+type ShareInput struct {
+	UserID int64
+	Amount int
+}
+
+// PaidResult says what happened when a share was marked paid.
+type PaidResult struct {
+	SplitID   int64
+	BookingID int64
+	// Settled is true when this payment was the last one outstanding.
+	Settled bool
+	// Confirmed is true when settling the split confirmed a pending booking.
+	Confirmed bool
+}
+
+// Reminder is an unpaid share due for a nudge.
+type Reminder struct {
+	ShareID   int64
+	SplitID   int64
+	BookingID int64
+	UserID    int64
+	Amount    int
+	VenueName string
+	StartTime time.Time
+}
+
+type Store interface {
+	// Create splits bookingID between the given shares, which must add up
+	// to totalAmount. A canceled split is replaced, unless one of its shares
+	// was paid, which returns ErrSharesPaid.
+	Create(ctx context.Context, bookingID, createdBy int64, totalAmount int, shares []ShareInput) (*Split, error)
+	GetByBooking(ctx context.Context, bookingID int64) (*Split, error)
+	GetShare(ctx context.Context, shareID int64) (*Share, error)
+	// Cancel closes an open split that has no paid shares.
+	Cancel(ctx context.Context, bookingID int64) error
+
+	// SetShareProvider records the gateway and its reference for the
+	// share's latest payment attempt.
+	SetShareProvider(ctx context.Context, shareID int64, provider, ref string) error
+	// MarkSharePaid marks the share paid. When it is the last unpaid share
+	// the split is settled and a pending booking is confirmed in the same
+	// transaction. Marking a paid share again is a no-op.
+	MarkSharePaid(ctx context.Context, shareID int64) (*PaidResult, error)
+
+	// ClaimReminders returns up to limit unpaid shares of open splits for
+	// upcoming bookings that have not been reminded within every, and stamps
+	// them as reminded.
+	ClaimReminders(ctx context.Context, every time.Duration, limit int) ([]Reminder, error)
+}
@@ -10,7 +10,7 @@ import (
 
 type LogsRepository struct{ q dbx.Querier }
 
-func NewLogsRepository(q dbx.Querier) *LogsRepository {
+func NewLogsRepository(q dbx.Querier) LogsStore {
 	return &LogsRepository{q: q}
 }
 
@@ -0,0 +1,302 @@
+package photomigrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/infra/dbx"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const migrationSelect = `
+	SELECT m.id, m.status, m.format, m.max_width, m.per_minute,
+	       COUNT(i.id)::INT,
+	       COUNT(i.id) FILTER (WHERE i.status = 'pending')::INT,
+	       COUNT(i.id) FILTER (WHERE i.status = 'done')::INT,
+	       COUNT(i.id) FILTER (WHERE i.status = 'skipped')::INT,
+	       COUNT(i.id) FILTER (WHERE i.status = 'failed')::INT,
+	       COALESCE(SUM(i.bytes_before) FILTER (WHERE i.status = 'done'), 0)::BIGINT,
+	       COALESCE(SUM(i.bytes_after) FILTER (WHERE i.status = 'done'), 0)::BIGINT,
+	       m.created_by, m.created_at, m.finished_at
+	FROM photo_migrations m
+	LEFT JOIN photo_migration_items i ON i.migration_id = m.id`
+
+func scanMigration(row pgx.Row, m *Migration) error {
+	err := row.Scan(
+		&m.ID, &m.Status, &m.Format, &m.MaxWidth, &m.PerMinute,
+		&m.Images, &m.Pending, &m.Done, &m.Skipped, &m.Failed,
+		&m.BytesBefore, &m.BytesAfter,
+		&m.CreatedBy, &m.CreatedAt, &m.FinishedAt,
+	)
+	if err != nil {
+		return err
+	}
+	m.BytesSaved = m.BytesBefore - m.BytesAfter
+	if m.BytesBefore > 0 {
+		pct := float64(m.BytesSaved) * 100 / float64(m.BytesBefore)
+		m.SavedPercent = &pct
+	}
+	return nil
+}
+
+func getMigration(ctx context.Context, q dbx.Querier, id int64) (*Migration, error) {
+	var m Migration
+	err := scanMigration(q.QueryRow(ctx, migrationSelect+` WHERE m.id = $1 GROUP BY m.id`, id), &m)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get photo migration: %w", err)
+	}
+	return &m, nil
+}
+
+func (r *Repository) Start(ctx context.Context, s Settings, createdBy int64) (*Migration, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var m *Migration
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO photo_migrations (format, max_width, per_minute, created_by)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, s.Format, s.MaxWidth, s.PerMinute, createdBy).Scan(&id)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrAlreadyRunning
+			}
+			return fmt.Errorf("create photo migration: %w", err)
+		}
+
+		// Demo venues are regenerated from scratch, so they're not worth it.
+		_, err = tx.Exec(ctx, `
+			INSERT INTO photo_migration_items (migration_id, venue_id, old_url)
+			SELECT $1, v.id, u.url
+			FROM venues v
+			CROSS JOIN LATERAL unnest(v.image_urls) AS u(url)
+			WHERE NOT v.is_demo
+			  AND u.url LIKE 'https://res.cloudinary.com/%/image/upload/%'
+			  AND lower(u.url) !~ '\.(webp|avif)$'
+			ORDER BY v.id
+			ON CONFLICT (migration_id, venue_id, old_url) DO NOTHING
+		`, id)
+		if err != nil {
+			return fmt.Errorf("collect venue photos: %w", err)
+		}
+
+		m, err = getMigration(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (*Migration, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return getMigration(ctx, r.db, id)
+}
+
+func (r *Repository) List(ctx context.Context) ([]Migration, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, migrationSelect+` GROUP BY m.id ORDER BY m.created_at DESC LIMIT 50`)
+	if err != nil {
+		return nil, fmt.Errorf("list photo migrations: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Migration{}
+	for rows.Next() {
+		var m Migration
+		if err := scanMigration(rows, &m); err != nil {
+			return nil, fmt.Errorf("scan photo migration: %w", err)
+		}
+		list = append(list, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) ListFailed(ctx context.Context, id int64) ([]FailedItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, venue_id, old_url, COALESCE(error, ''), processed_at
+		FROM photo_migration_items
+		WHERE migration_id = $1 AND status = 'failed'
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list failed photos: %w", err)
+	}
+	defer rows.Close()
+
+	list := []FailedItem{}
+	for rows.Next() {
+		var f FailedItem
+		if err := rows.Scan(&f.ID, &f.VenueID, &f.OldURL, &f.Error, &f.ProcessedAt); err != nil {
+			return nil, fmt.Errorf("scan failed photo: %w", err)
+		}
+		list = append(list, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Cancel(ctx context.Context, id int64) (*Migration, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE photo_migrations
+		SET status = 'canceled', finished_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("cancel photo migration: %w", err)
+	}
+	m, err := getMigration(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotRunning
+	}
+	return m, nil
+}
+
+func (r *Repository) NextItems(ctx context.Context, id int64, limit int) ([]Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, i.venue_id, i.old_url
+		FROM photo_migration_items i
+		JOIN photo_migrations m ON m.id = i.migration_id
+		WHERE i.migration_id = $1
+		  AND i.status = 'pending'
+		  AND m.status = 'running'
+		ORDER BY i.id
+		LIMIT $2
+	`, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("next photos: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(&it.ID, &it.VenueID, &it.OldURL); err != nil {
+			return nil, fmt.Errorf("scan photo: %w", err)
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return items, nil
+}
+
+func (r *Repository) Replace(ctx context.Context, item Item, newURL string, bytesBefore, bytesAfter int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	replaced := false
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE venues
+			SET image_urls = array_replace(image_urls, $2, $3), updated_at = NOW()
+			WHERE id = $1 AND $2 = ANY(image_urls)
+		`, item.VenueID, item.OldURL, newURL)
+		if err != nil {
+			return fmt.Errorf("replace venue photo: %w", err)
+		}
+		replaced = tag.RowsAffected() > 0
+
+		if !replaced {
+			return closeItem(ctx, tx, item.ID, ItemSkipped, "photo removed from the venue meanwhile")
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE photo_migration_items
+			SET status = 'done', new_url = $2, bytes_before = $3, bytes_after = $4, processed_at = NOW()
+			WHERE id = $1
+		`, item.ID, newURL, bytesBefore, bytesAfter)
+		if err != nil {
+			return fmt.Errorf("record replaced photo: %w", err)
+		}
+		return nil
+	})
+	return replaced, err
+}
+
+func closeItem(ctx context.Context, q dbx.Querier, itemID int64, status, reason string) error {
+	_, err := q.Exec(ctx, `
+		UPDATE photo_migration_items
+		SET status = $2, error = $3, processed_at = NOW()
+		WHERE id = $1
+	`, itemID, status, reason)
+	if err != nil {
+		return fmt.Errorf("close photo item: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Skip(ctx context.Context, itemID int64, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return closeItem(ctx, r.db, itemID, ItemSkipped, reason)
+}
+
+func (r *Repository) Fail(ctx context.Context, itemID int64, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return closeItem(ctx, r.db, itemID, ItemFailed, reason)
+}
+
+func (r *Repository) Finish(ctx context.Context, id int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE photo_migrations m
+		SET status = 'completed', finished_at = NOW()
+		WHERE m.id = $1
+		  AND m.status = 'running'
+		  AND NOT EXISTS (
+			SELECT 1 FROM photo_migration_items i
+			WHERE i.migration_id = m.id AND i.status = 'pending'
+		  )
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("finish photo migration: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,101 @@
+package photomigrations
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 30
+
+var (
+	ErrNotFound       = errors.New("photo migration not found")
+	ErrAlreadyRunning = errors.New("a photo migration is already running")
+	ErrNotRunning     = errors.New("photo migration is not running")
+)
+
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusCanceled  = "canceled"
+)
+
+const (
+	ItemPending = "pending"
+	ItemDone    = "done"
+	ItemSkipped = "skipped"
+	ItemFailed  = "failed"
+)
+
+// Settings say what photos are turned into and how fast.
+type Settings struct {
+	Format    string `json:"format"`
+	MaxWidth  int    `json:"max_width"`
+	PerMinute int    `json:"per_minute"`
+}
+
+// Report sums up a migration's photos. Bytes only count the photos that
+// were replaced; SavedPercent is null until one was.
+type Report struct {
+	Images       int      `json:"images"`
+	Pending      int      `json:"pending"`
+	Done         int      `json:"done"`
+	Skipped      int      `json:"skipped"`
+	Failed       int      `json:"failed"`
+	BytesBefore  int64    `json:"bytes_before"`
+	BytesAfter   int64    `json:"bytes_after"`
+	BytesSaved   int64    `json:"bytes_saved"`
+	SavedPercent *float64 `json:"saved_percent"`
+}
+
+// Migration is one pass over the venue photos stored on Cloudinary.
+type Migration struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+	Settings
+	Report
+	CreatedBy  *int64     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Item is one venue photo still to be re-encoded.
+type Item struct {
+	ID      int64  `json:"id"`
+	VenueID int64  `json:"venue_id"`
+	OldURL  string `json:"old_url"`
+}
+
+// FailedItem is a photo that could not be re-encoded, for the report.
+type FailedItem struct {
+	ID          int64     `json:"id"`
+	VenueID     int64     `json:"venue_id"`
+	OldURL      string    `json:"old_url"`
+	Error       string    `json:"error"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+type Store interface {
+	// Start records a migration covering every Cloudinary photo of a real
+	// venue not already in a modern format.
+	Start(ctx context.Context, s Settings, createdBy int64) (*Migration, error)
+	Get(ctx context.Context, id int64) (*Migration, error)
+	List(ctx context.Context) ([]Migration, error)
+	ListFailed(ctx context.Context, id int64) ([]FailedItem, error)
+	// Cancel stops a running migration. Photos already replaced stay so.
+	Cancel(ctx context.Context, id int64) (*Migration, error)
+
+	// NextItems returns up to limit pending photos, none once the
+	// migration is no longer running.
+	NextItems(ctx context.Context, id int64, limit int) ([]Item, error)
+	// Replace swaps the venue's photo for newURL and marks the item done.
+	// It returns false, marking the item skipped instead, when the owner
+	// removed the photo in the meantime.
+	Replace(ctx context.Context, item Item, newURL string, bytesBefore, bytesAfter int64) (bool, error)
+	// Skip and Fail close an item without touching the venue.
+	Skip(ctx context.Context, itemID int64, reason string) error
+	Fail(ctx context.Context, itemID int64, reason string) error
+	// Finish completes a running migration once nothing is pending and
+	// reports whether it did.
+	Finish(ctx context.Context, id int64) (bool, error)
+}
@@ -0,0 +1,173 @@
+package pricealerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// slotPrice is the cheapest active facility price covering the alert's start
+// time. Used as a correlated subquery with the alert aliased as a.
+const slotPrice = `
+	SELECT MIN(vp.price)
+	FROM venue_pricing vp
+	JOIN facilities f ON f.id = vp.facility_id AND f.is_active
+	WHERE vp.venue_id = a.venue_id
+	  AND vp.day_of_week = a.day_of_week
+	  AND vp.start_time <= a.start_time
+	  AND vp.end_time > a.start_time
+`
+
+func (r *Repository) Create(ctx context.Context, a *Alert) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var count int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM price_alerts WHERE user_id = $1`, a.UserID).Scan(&count); err != nil {
+			return fmt.Errorf("count price alerts: %w", err)
+		}
+		if count >= MaxPerUser {
+			return ErrLimitExceeded
+		}
+
+		err := tx.QueryRow(ctx, `
+			INSERT INTO price_alerts (user_id, venue_id, day_of_week, start_time, price_below)
+			VALUES ($1, $2, $3, $4::time, $5)
+			RETURNING id, created_at
+		`, a.UserID, a.VenueID, a.DayOfWeek, a.StartTime, a.PriceBelow).Scan(&a.ID, &a.CreatedAt)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case "23505":
+					return ErrDuplicate
+				case "23503":
+					return ErrVenueNotFound
+				}
+			}
+			return fmt.Errorf("create price alert: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]Alert, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, a.user_id, a.venue_id, v.name, a.day_of_week, to_char(a.start_time, 'HH24:MI:SS'),
+		       a.price_below, (`+slotPrice+`), a.last_notified_price, a.last_notified_at, a.created_at
+		FROM price_alerts a
+		JOIN venues v ON v.id = a.venue_id
+		WHERE a.user_id = $1
+		ORDER BY a.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list price alerts: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Alert{}
+	for rows.Next() {
+		var a Alert
+		if err := rows.Scan(
+			&a.ID, &a.UserID, &a.VenueID, &a.VenueName, &a.DayOfWeek, &a.StartTime,
+			&a.PriceBelow, &a.CurrentPrice, &a.LastNotifiedPrice, &a.LastNotifiedAt, &a.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan price alert: %w", err)
+		}
+		list = append(list, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, userID, alertID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM price_alerts WHERE id = $1 AND user_id = $2`, alertID, userID)
+	if err != nil {
+		return fmt.Errorf("delete price alert: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EvaluateVenue re-arms alerts whose slot went back above the threshold,
+// then claims alerts whose slot is below it and cheaper than the last price
+// we told the user about.
+func (r *Repository) EvaluateVenue(ctx context.Context, venueID int64) ([]Triggered, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	triggered := []Triggered{}
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE price_alerts a
+			SET last_notified_price = NULL
+			WHERE a.venue_id = $1
+			  AND a.last_notified_price IS NOT NULL
+			  AND COALESCE((`+slotPrice+`), a.price_below) >= a.price_below
+		`, venueID)
+		if err != nil {
+			return fmt.Errorf("re-arm price alerts: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, `
+			WITH due AS (
+				SELECT a.id, (`+slotPrice+`) AS price
+				FROM price_alerts a
+				WHERE a.venue_id = $1
+			)
+			UPDATE price_alerts a
+			SET last_notified_price = due.price,
+			    last_notified_at = NOW()
+			FROM due, venues v
+			WHERE a.id = due.id
+			  AND v.id = a.venue_id
+			  AND due.price < a.price_below
+			  AND (a.last_notified_price IS NULL OR due.price < a.last_notified_price)
+			RETURNING a.id, a.user_id, a.venue_id, v.name, a.day_of_week, to_char(a.start_time, 'HH24:MI'), due.price
+		`, venueID)
+		if err != nil {
+			return fmt.Errorf("claim price alerts: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var t Triggered
+			if err := rows.Scan(&t.AlertID, &t.UserID, &t.VenueID, &t.VenueName, &t.DayOfWeek, &t.StartTime, &t.Price); err != nil {
+				return fmt.Errorf("scan triggered alert: %w", err)
+			}
+			triggered = append(triggered, t)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return triggered, nil
+}
@@ -0,0 +1,55 @@
+package pricealerts
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// MaxPerUser caps how many slots one user can watch.
+const MaxPerUser = 20
+
+var (
+	ErrNotFound      = errors.New("price alert not found")
+	ErrVenueNotFound = errors.New("venue not found")
+	ErrDuplicate     = errors.New("you already have an alert for this slot")
+	ErrLimitExceeded = errors.New("price alert limit reached")
+)
+
+// Alert watches one weekly slot of a venue. StartTime is local Nepal time
+// (HH:MM:SS), the same as venue_pricing.
+type Alert struct {
+	ID                int64      `json:"id"`
+	UserID            int64      `json:"user_id"`
+	VenueID           int64      `json:"venue_id"`
+	VenueName         string     `json:"venue_name"`
+	DayOfWeek         string     `json:"day_of_week"`
+	StartTime         string     `json:"start_time"`
+	PriceBelow        int        `json:"price_below"`
+	CurrentPrice      *int       `json:"current_price,omitempty"`
+	LastNotifiedPrice *int       `json:"last_notified_price,omitempty"`
+	LastNotifiedAt    *time.Time `json:"last_notified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Triggered is an alert whose slot just dropped below its threshold.
+type Triggered struct {
+	AlertID   int64
+	UserID    int64
+	VenueID   int64
+	VenueName string
+	DayOfWeek string
+	StartTime string
+	Price     int
+}
+
+type Store interface {
+	Create(ctx context.Context, a *Alert) error
+	ListByUser(ctx context.Context, userID int64) ([]Alert, error)
+	Delete(ctx context.Context, userID, alertID int64) error
+	// EvaluateVenue checks every alert on the venue against the current
+	// weekly pricing and claims the ones that should notify.
+	EvaluateVenue(ctx context.Context, venueID int64) ([]Triggered, error)
+}
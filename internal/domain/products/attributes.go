@@ -0,0 +1,345 @@
+package products
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ------------------------------------
+// Attribute definitions and facets
+// ------------------------------------
+
+const attributeColumns = `a.id, a.category_id, a.key, a.name, a.type, a.allowed_values, a.required,
+	a.filterable, a.sort_order, a.created_at, a.updated_at`
+
+func scanAttribute(row pgx.Row, a *AttributeDefinition) error {
+	return row.Scan(&a.ID, &a.CategoryID, &a.Key, &a.Name, &a.Type, &a.AllowedValues, &a.Required,
+		&a.Filterable, &a.SortOrder, &a.CreatedAt, &a.UpdatedAt)
+}
+
+// inheritedAttributesSQL lists the definitions that apply to the category
+// the start query yields, walking up through its ancestors.
+const inheritedAttributesSQL = `
+	WITH RECURSIVE chain AS (
+		SELECT id, parent_id, 0 AS depth FROM categories WHERE id = (%s)
+		UNION ALL
+		SELECT c.id, c.parent_id, ch.depth + 1
+		FROM categories c
+		JOIN chain ch ON c.id = ch.parent_id
+	)
+	SELECT * FROM (
+		SELECT DISTINCT ON (a.key) ` + attributeColumns + `
+		FROM category_attributes a
+		JOIN chain ch ON ch.id = a.category_id
+		ORDER BY a.key, ch.depth
+	) a
+	ORDER BY a.sort_order, a.key
+`
+
+func (r *Repository) listInheritedAttributes(ctx context.Context, start string, arg int64) ([]*AttributeDefinition, error) {
+	rows, err := r.db.Query(ctx, fmt.Sprintf(inheritedAttributesSQL, start), arg)
+	if err != nil {
+		return nil, fmt.Errorf("list attributes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*AttributeDefinition{}
+	for rows.Next() {
+		var a AttributeDefinition
+		if err := scanAttribute(rows, &a); err != nil {
+			return nil, fmt.Errorf("scan attribute: %w", err)
+		}
+		out = append(out, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) ListCategoryAttributes(ctx context.Context, categoryID int64) ([]*AttributeDefinition, error) {
+	return r.listInheritedAttributes(ctx, `$1`, categoryID)
+}
+
+func (r *Repository) ProductAttributes(ctx context.Context, productID int64) ([]*AttributeDefinition, error) {
+	return r.listInheritedAttributes(ctx, `SELECT category_id FROM products WHERE id = $1`, productID)
+}
+
+func (r *Repository) CreateCategoryAttribute(ctx context.Context, a *AttributeDefinition) error {
+	const q = `
+		INSERT INTO category_attributes (category_id, key, name, type, allowed_values, required, filterable, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at;
+	`
+	err := r.db.QueryRow(ctx, q, a.CategoryID, a.Key, a.Name, a.Type, a.AllowedValues, a.Required, a.Filterable, a.SortOrder).
+		Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return ErrDuplicateAttribute
+			case "23503":
+				return ErrCategoryNotFound
+			}
+		}
+		return fmt.Errorf("create attribute: %w", err)
+	}
+	return nil
+}
+
+// UpdateCategoryAttribute changes everything but the key and the type,
+// which existing variants already use.
+func (r *Repository) UpdateCategoryAttribute(ctx context.Context, a *AttributeDefinition) error {
+	const q = `
+		UPDATE category_attributes a
+		SET name = $3, allowed_values = $4, required = $5, filterable = $6, sort_order = $7, updated_at = NOW()
+		WHERE a.id = $1 AND a.category_id = $2
+		RETURNING ` + attributeColumns + `;
+	`
+	err := scanAttribute(r.db.QueryRow(ctx, q, a.ID, a.CategoryID, a.Name, a.AllowedValues, a.Required, a.Filterable, a.SortOrder), a)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAttributeNotFound
+		}
+		return fmt.Errorf("update attribute: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteCategoryAttribute(ctx context.Context, categoryID, id int64) error {
+	ct, err := r.db.Exec(ctx, `DELETE FROM category_attributes WHERE id = $1 AND category_id = $2`, id, categoryID)
+	if err != nil {
+		return fmt.Errorf("delete attribute: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrAttributeNotFound
+	}
+	return nil
+}
+
+// json encodes the filter for the queries; no filter is an empty object.
+func (f AttributeFilter) json() (string, error) {
+	if len(f) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return "", fmt.Errorf("marshal attribute filter: %w", err)
+	}
+	return string(b), nil
+}
+
+// variantMatchSQL is the condition that product p has an active variant
+// matching the attribute filter passed as parameter $n.
+func variantMatchSQL(n int) string {
+	return fmt.Sprintf(`($%[1]d::jsonb = '{}'::jsonb OR EXISTS (
+    SELECT 1 FROM product_variants fv
+    WHERE fv.product_id = p.id AND fv.is_active = TRUE
+      AND NOT EXISTS (
+        SELECT 1 FROM jsonb_each($%[1]d::jsonb) f(key, vals)
+        WHERE NOT COALESCE(f.vals ? (fv.attributes->>f.key), FALSE)
+      )
+  ))`, n)
+}
+
+// ProductFacets uses the definitions of the category, its ancestors and its
+// subcategories. Each attribute's counts apply every filter but its own, so
+// picking a second value of the same attribute widens the results.
+func (r *Repository) ProductFacets(ctx context.Context, categorySlug string, attrs AttributeFilter) ([]Facet, error) {
+	attrJSON, err := attrs.json()
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+WITH RECURSIVE cat_subtree AS (
+  SELECT id FROM categories
+  WHERE ($1 = '' OR slug = $1) AND deleted_at IS NULL
+  UNION ALL
+  SELECT c.id FROM categories c
+  JOIN cat_subtree cs ON c.parent_id = cs.id
+  WHERE c.deleted_at IS NULL
+),
+ancestors AS (
+  SELECT id, parent_id FROM categories WHERE $1 <> '' AND slug = $1 AND deleted_at IS NULL
+  UNION ALL
+  SELECT c.id, c.parent_id FROM categories c
+  JOIN ancestors an ON c.id = an.parent_id
+),
+defs AS (
+  SELECT DISTINCT ON (a.key) a.key, a.name, a.type, a.allowed_values, a.sort_order
+  FROM category_attributes a
+  WHERE a.filterable
+    AND (a.category_id IN (SELECT id FROM cat_subtree) OR a.category_id IN (SELECT id FROM ancestors))
+  ORDER BY a.key, a.sort_order, a.id
+)
+SELECT d.key, d.name, d.type, d.allowed_values, cnt.value, COALESCE(cnt.products, 0)::INT
+FROM defs d
+LEFT JOIN LATERAL (
+  SELECT v.attributes->>d.key AS value, COUNT(DISTINCT p.id) AS products
+  FROM products p
+  JOIN product_variants v ON v.product_id = p.id AND v.is_active = TRUE
+  WHERE p.deleted_at IS NULL
+    AND ($1 = '' OR p.category_id IN (SELECT id FROM cat_subtree))
+    AND v.attributes ? d.key
+    AND NOT EXISTS (
+      SELECT 1 FROM jsonb_each($2::jsonb) f(key, vals)
+      WHERE f.key <> d.key
+        AND NOT COALESCE(f.vals ? (v.attributes->>f.key), FALSE)
+    )
+  GROUP BY 1
+) cnt ON TRUE
+ORDER BY d.sort_order, d.key;
+`
+	rows, err := r.db.Query(ctx, q, categorySlug, attrJSON)
+	if err != nil {
+		return nil, fmt.Errorf("product facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := []Facet{}
+	allowed := map[string][]string{}
+	for rows.Next() {
+		var (
+			f        Facet
+			values   []string
+			value    *string
+			products int
+		)
+		if err := rows.Scan(&f.Key, &f.Name, &f.Type, &values, &value, &products); err != nil {
+			return nil, fmt.Errorf("scan facet: %w", err)
+		}
+		if n := len(facets); n == 0 || facets[n-1].Key != f.Key {
+			f.Values = []FacetValue{}
+			facets = append(facets, f)
+			allowed[f.Key] = values
+		}
+		if value != nil {
+			last := &facets[len(facets)-1]
+			last.Values = append(last.Values, FacetValue{Value: *value, Count: products})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	for i := range facets {
+		sortFacetValues(&facets[i], allowed[facets[i].Key])
+	}
+	return facets, nil
+}
+
+// sortFacetValues puts enum values in their defined order and numbers in
+// numeric order; anything else sorts as text.
+func sortFacetValues(f *Facet, allowed []string) {
+	slices.SortFunc(f.Values, func(a, b FacetValue) int {
+		switch f.Type {
+		case AttrEnum:
+			ia, ib := slices.Index(allowed, a.Value), slices.Index(allowed, b.Value)
+			if ia < 0 {
+				ia = len(allowed)
+			}
+			if ib < 0 {
+				ib = len(allowed)
+			}
+			if ia != ib {
+				return ia - ib
+			}
+		case AttrNumber:
+			na, errA := strconv.ParseFloat(a.Value, 64)
+			nb, errB := strconv.ParseFloat(b.Value, 64)
+			if errA == nil && errB == nil && na != nb {
+				return cmp.Compare(na, nb)
+			}
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+}
+
+// AttributeError lists what is wrong with a variant's attributes.
+type AttributeError struct {
+	Problems []string
+}
+
+func (e *AttributeError) Error() string {
+	return "invalid attributes: " + strings.Join(e.Problems, "; ")
+}
+
+// ValidateAttributes checks a variant's attributes against the definitions
+// for its product's category. Categories without definitions take any
+// attributes; otherwise only defined keys are allowed.
+func ValidateAttributes(defs []*AttributeDefinition, attrs map[string]any) error {
+	if len(defs) == 0 {
+		return nil
+	}
+
+	var problems []string
+	byKey := make(map[string]*AttributeDefinition, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+		if _, ok := attrs[d.Key]; !ok && d.Required {
+			problems = append(problems, fmt.Sprintf("%s is required", d.Key))
+		}
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		d, ok := byKey[k]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not an attribute of this category", k))
+			continue
+		}
+		if msg := checkAttributeValue(d, attrs[k]); msg != "" {
+			problems = append(problems, fmt.Sprintf("%s %s", k, msg))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &AttributeError{Problems: problems}
+	}
+	return nil
+}
+
+func checkAttributeValue(d *AttributeDefinition, v any) string {
+	switch d.Type {
+	case AttrEnum:
+		s, ok := v.(string)
+		if n, isNum := v.(float64); isNum {
+			s, ok = strconv.FormatFloat(n, 'f', -1, 64), true
+		}
+		if !ok || !slices.Contains(d.AllowedValues, s) {
+			return "must be one of " + strings.Join(d.AllowedValues, ", ")
+		}
+	case AttrNumber:
+		if _, ok := v.(float64); !ok {
+			return "must be a number"
+		}
+	case AttrBoolean:
+		if _, ok := v.(bool); !ok {
+			return "must be true or false"
+		}
+	case AttrText:
+		s, ok := v.(string)
+		if !ok || strings.TrimSpace(s) == "" {
+			return "must be non-empty text"
+		}
+		if len(s) > 200 {
+			return "must be at most 200 characters"
+		}
+	}
+	return ""
+}
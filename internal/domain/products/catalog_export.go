@@ -0,0 +1,67 @@
+package products
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ------------------------------------
+// Catalog export and merchant feed
+// ------------------------------------
+
+// EachCatalogRow calls fn for every variant of the catalog, ordered by
+// product, without holding the whole catalog in memory. With publicOnly it
+// skips drafts and inactive variants and leaves cost prices out, as the
+// merchant feed needs. An error from fn stops the scan and is returned.
+func (r *Repository) EachCatalogRow(ctx context.Context, publicOnly bool, fn func(CatalogRow) error) error {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.name, p.slug, p.description,
+		       c.slug, c.name, b.slug, b.name, p.is_active,
+		       v.id, v.sku, v.barcode, v.price_cents,
+		       CASE WHEN $1 THEN NULL ELSE v.cost_price_cents END,
+		       v.attributes, v.is_active,
+		       ARRAY(
+		           SELECT pi.url FROM product_images pi
+		           WHERE pi.product_id = p.id
+		             AND (pi.product_variant_id IS NULL OR pi.product_variant_id = v.id)
+		           ORDER BY pi.product_variant_id IS NULL, pi.is_primary DESC, pi.sort_order, pi.id
+		       )
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id AND c.deleted_at IS NULL
+		LEFT JOIN brands b ON b.id = p.brand_id AND b.deleted_at IS NULL
+		LEFT JOIN product_variants v ON v.product_id = p.id
+		WHERE p.deleted_at IS NULL
+		  AND (NOT $1 OR (p.is_active AND v.is_active))
+		ORDER BY p.id, v.id
+	`, publicOnly)
+	if err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			row   CatalogRow
+			attrs []byte
+		)
+		if err := rows.Scan(&row.ProductID, &row.Name, &row.Slug, &row.Description,
+			&row.CategorySlug, &row.CategoryName, &row.BrandSlug, &row.BrandName, &row.ProductActive,
+			&row.VariantID, &row.SKU, &row.Barcode, &row.PriceCents, &row.CostPriceCents,
+			&attrs, &row.VariantActive, &row.ImageURLs); err != nil {
+			return fmt.Errorf("scan catalog row: %w", err)
+		}
+		if len(attrs) > 0 {
+			if err := json.Unmarshal(attrs, &row.Attributes); err != nil {
+				return fmt.Errorf("unmarshal attributes: %w", err)
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list catalog: %w", err)
+	}
+	return nil
+}
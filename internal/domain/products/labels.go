@@ -0,0 +1,292 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ------------------------------------
+// Labels (product card badges)
+// ------------------------------------
+
+func (r *Repository) ListLabels(ctx context.Context) ([]*ProductLabel, error) {
+	const q = `
+		SELECT l.id, l.key, l.name, l.color, l.priority, l.auto_rule, l.is_active,
+		       (SELECT COUNT(*) FROM product_label_assignments a WHERE a.label_id = l.id),
+		       l.created_at, l.updated_at
+		FROM product_labels l
+		ORDER BY l.priority DESC, l.id;
+	`
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*ProductLabel{}
+	for rows.Next() {
+		var l ProductLabel
+		if err := rows.Scan(&l.ID, &l.Key, &l.Name, &l.Color, &l.Priority, &l.AutoRule, &l.IsActive,
+			&l.Products, &l.CreatedAt, &l.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan label: %w", err)
+		}
+		out = append(out, &l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+// CreateLabel adds a manual label; auto rules only come from migrations.
+func (r *Repository) CreateLabel(ctx context.Context, l *ProductLabel) error {
+	const q = `
+		INSERT INTO product_labels (key, name, color, priority, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at;
+	`
+	err := r.db.QueryRow(ctx, q, l.Key, l.Name, l.Color, l.Priority, l.IsActive).Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateLabel
+		}
+		return fmt.Errorf("create label: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) UpdateLabel(ctx context.Context, l *ProductLabel) error {
+	const q = `
+		UPDATE product_labels
+		SET name = $2, color = $3, priority = $4, is_active = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING key, auto_rule, created_at, updated_at;
+	`
+	err := r.db.QueryRow(ctx, q, l.ID, l.Name, l.Color, l.Priority, l.IsActive).Scan(&l.Key, &l.AutoRule, &l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLabelNotFound
+		}
+		return fmt.Errorf("update label: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteLabel(ctx context.Context, id int64) error {
+	var autoRule *string
+	if err := r.db.QueryRow(ctx, `SELECT auto_rule FROM product_labels WHERE id = $1`, id).Scan(&autoRule); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLabelNotFound
+		}
+		return fmt.Errorf("get label: %w", err)
+	}
+	if autoRule != nil {
+		return ErrAutoLabel
+	}
+	if _, err := r.db.Exec(ctx, `DELETE FROM product_labels WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete label: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SetProductLabels(ctx context.Context, productID int64, labelIDs []int64) ([]CardLabel, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if labelIDs == nil {
+		labelIDs = []int64{}
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`, productID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check product: %w", err)
+	}
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	var known int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM product_labels WHERE id = ANY($1)`, labelIDs).Scan(&known); err != nil {
+		return nil, fmt.Errorf("check labels: %w", err)
+	}
+	if known != len(labelIDs) {
+		return nil, ErrLabelNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM product_label_assignments
+		WHERE product_id = $1 AND source = 'manual' AND NOT (label_id = ANY($2))
+	`, productID, labelIDs); err != nil {
+		return nil, fmt.Errorf("clear product labels: %w", err)
+	}
+	// Picking a label by hand pins it, even one the refresh had added.
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO product_label_assignments (product_id, label_id, source)
+		SELECT $1, unnest($2::bigint[]), 'manual'
+		ON CONFLICT (product_id, label_id) DO UPDATE SET source = 'manual'
+	`, productID, labelIDs); err != nil {
+		return nil, fmt.Errorf("set product labels: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	byProduct, err := r.cardLabels(ctx, []int64{productID})
+	if err != nil {
+		return nil, err
+	}
+	return byProduct[productID], nil
+}
+
+// RefreshAutoLabels recomputes every auto rule in one transaction, so cards
+// never show a half-applied refresh.
+func (r *Repository) RefreshAutoLabels(ctx context.Context) (*LabelRefreshResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Products each rule currently selects. Inactive labels select nothing,
+	// which clears their auto assignments.
+	const matches = `
+		WITH rules AS (
+			SELECT id, auto_rule FROM product_labels
+			WHERE auto_rule IS NOT NULL AND is_active = TRUE
+		),
+		sales AS (
+			SELECT oi.product_id, SUM(oi.quantity) AS units
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.payment_status = 'paid'
+			  AND o.status NOT IN ('cancelled', 'refunded')
+			  AND o.created_at >= NOW() - make_interval(secs => $2)
+			  AND oi.product_id IS NOT NULL
+			GROUP BY oi.product_id
+		),
+		ranked AS (
+			SELECT s.product_id,
+			       ROW_NUMBER() OVER (ORDER BY s.units DESC, s.product_id) AS pos,
+			       COUNT(*) OVER () AS sellers
+			FROM sales s
+			JOIN products p ON p.id = s.product_id AND p.deleted_at IS NULL
+		)
+		SELECT p.id, ru.id
+		FROM rules ru
+		JOIN products p ON ru.auto_rule = 'new'
+		 AND p.deleted_at IS NULL
+		 AND p.created_at >= NOW() - make_interval(secs => $1)
+		UNION
+		SELECT rk.product_id, ru.id
+		FROM rules ru
+		JOIN ranked rk ON ru.auto_rule = 'bestseller'
+		 AND rk.pos <= GREATEST(1, CEIL(rk.sellers * $3::float8))
+	`
+
+	if _, err := tx.Exec(ctx, `
+		CREATE TEMP TABLE label_matches (product_id BIGINT, label_id BIGINT) ON COMMIT DROP
+	`); err != nil {
+		return nil, fmt.Errorf("prepare label refresh: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO label_matches `+matches,
+		NewProductWindow.Seconds(), BestsellerWindow.Seconds(), BestsellerShare); err != nil {
+		return nil, fmt.Errorf("match auto labels: %w", err)
+	}
+
+	res := &LabelRefreshResult{}
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM product_label_assignments a
+		WHERE a.source = 'auto'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM label_matches m
+		      WHERE m.product_id = a.product_id AND m.label_id = a.label_id
+		  )
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("remove stale auto labels: %w", err)
+	}
+	res.Removed = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx, `
+		INSERT INTO product_label_assignments (product_id, label_id, source)
+		SELECT product_id, label_id, 'auto' FROM label_matches
+		ON CONFLICT (product_id, label_id) DO NOTHING
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("add auto labels: %w", err)
+	}
+	res.Added = tag.RowsAffected()
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return res, nil
+}
+
+// cardLabels loads the active labels of each product, highest priority
+// first.
+func (r *Repository) cardLabels(ctx context.Context, productIDs []int64) (map[int64][]CardLabel, error) {
+	out := make(map[int64][]CardLabel, len(productIDs))
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT a.product_id, l.key, l.name, l.color
+		FROM product_label_assignments a
+		JOIN product_labels l ON l.id = a.label_id
+		WHERE a.product_id = ANY($1) AND l.is_active = TRUE
+		ORDER BY a.product_id, l.priority DESC, l.id
+	`, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load card labels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID int64
+			l         CardLabel
+		)
+		if err := rows.Scan(&productID, &l.Key, &l.Name, &l.Color); err != nil {
+			return nil, fmt.Errorf("scan card label: %w", err)
+		}
+		out[productID] = append(out[productID], l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+// attachLabels fills Labels on each card; cards without labels get an
+// empty list so the app can always range over it.
+func (r *Repository) attachLabels(ctx context.Context, cards []*ProductCard) error {
+	ids := make([]int64, len(cards))
+	for i, c := range cards {
+		ids[i] = c.ID
+	}
+	byProduct, err := r.cardLabels(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, c := range cards {
+		c.Labels = byProduct[c.ID]
+		if c.Labels == nil {
+			c.Labels = []CardLabel{}
+		}
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
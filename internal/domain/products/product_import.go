@@ -0,0 +1,208 @@
+package products
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ------------------------------------
+// Bulk product import
+// ------------------------------------
+
+// importRowError ties a failed insert to the CSV line it came from.
+type importRowError struct {
+	line int
+	sku  string
+	err  error
+}
+
+func (e *importRowError) Error() string { return e.err.Error() }
+
+func (r *Repository) ImportProducts(ctx context.Context, items []ProductImport, batchSize int) (*ProductImportResult, error) {
+	res := &ProductImportResult{Issues: []ProductImportIssue{}}
+	// skip reports why a product wasn't created against the line at fault,
+	// and its other rows as skipped.
+	skip := func(p ProductImport, line int, sku, msg string) {
+		res.Issues = append(res.Issues, ProductImportIssue{Line: line, Slug: p.Slug, SKU: sku, Error: msg})
+		for _, v := range p.Variants {
+			if v.Line != line {
+				res.Issues = append(res.Issues, ProductImportIssue{Line: v.Line, Slug: p.Slug, SKU: v.SKU, Error: fmt.Sprintf("skipped: line %d of this product failed", line)})
+			}
+		}
+	}
+
+	categories, err := r.idsBySlug(ctx, "categories", items, func(p ProductImport) *string { return p.CategorySlug })
+	if err != nil {
+		return nil, err
+	}
+	brands, err := r.idsBySlug(ctx, "brands", items, func(p ProductImport) *string { return p.BrandSlug })
+	if err != nil {
+		return nil, err
+	}
+
+	attrDefs := map[int64][]*AttributeDefinition{}
+
+	for start := 0; start < len(items); start += batchSize {
+		batch := items[start:min(start+batchSize, len(items))]
+		err := r.WithTx(ctx, func(tx pgx.Tx) error {
+			for _, p := range batch {
+				var categoryID, brandID *int64
+				if p.CategorySlug != nil {
+					id, ok := categories[strings.ToLower(*p.CategorySlug)]
+					if !ok {
+						skip(p, p.Line, "", fmt.Sprintf("unknown category %q", *p.CategorySlug))
+						continue
+					}
+					categoryID = &id
+				}
+				if p.BrandSlug != nil {
+					id, ok := brands[strings.ToLower(*p.BrandSlug)]
+					if !ok {
+						skip(p, p.Line, "", fmt.Sprintf("unknown brand %q", *p.BrandSlug))
+						continue
+					}
+					brandID = &id
+				}
+				if categoryID != nil {
+					defs, ok := attrDefs[*categoryID]
+					if !ok {
+						var err error
+						if defs, err = r.ListCategoryAttributes(ctx, *categoryID); err != nil {
+							return err
+						}
+						attrDefs[*categoryID] = defs
+					}
+					if v, err := firstInvalidVariant(defs, p.Variants); err != nil {
+						skip(p, v.Line, v.SKU, err.Error())
+						continue
+					}
+				}
+
+				// A savepoint per product, so one bad product doesn't take
+				// the rest of the batch down with it.
+				sp, err := tx.Begin(ctx)
+				if err != nil {
+					return fmt.Errorf("savepoint: %w", err)
+				}
+				if err := insertImportedProduct(ctx, sp, p, categoryID, brandID); err != nil {
+					_ = sp.Rollback(ctx)
+					var rowErr *importRowError
+					if !errors.As(err, &rowErr) {
+						return err
+					}
+					skip(p, rowErr.line, rowErr.sku, rowErr.Error())
+					continue
+				}
+				if err := sp.Commit(ctx); err != nil {
+					return fmt.Errorf("release savepoint: %w", err)
+				}
+				res.Products++
+				res.Variants += len(p.Variants)
+				res.Images += len(p.ImageURLs)
+			}
+			return nil
+		})
+		if err != nil {
+			// Earlier batches are committed; the counts say how far it got.
+			return res, fmt.Errorf("import products: %w", err)
+		}
+	}
+	return res, nil
+}
+
+func firstInvalidVariant(defs []*AttributeDefinition, variants []VariantImport) (VariantImport, error) {
+	for _, v := range variants {
+		if err := ValidateAttributes(defs, v.Attributes); err != nil {
+			return v, err
+		}
+	}
+	return VariantImport{}, nil
+}
+
+// idsBySlug resolves the category or brand slugs the import names, matched
+// case-insensitively and ignoring trashed rows.
+func (r *Repository) idsBySlug(ctx context.Context, table string, items []ProductImport, slug func(ProductImport) *string) (map[string]int64, error) {
+	var slugs []string
+	for _, p := range items {
+		if s := slug(p); s != nil {
+			slugs = append(slugs, strings.ToLower(*s))
+		}
+	}
+	out := map[string]int64{}
+	if len(slugs) == 0 {
+		return out, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT LOWER(slug), id FROM `+table+`
+		WHERE LOWER(slug) = ANY($1) AND deleted_at IS NULL
+	`, slugs)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s string
+		var id int64
+		if err := rows.Scan(&s, &id); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", table, err)
+		}
+		out[s] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", table, err)
+	}
+	return out, nil
+}
+
+// insertImportedProduct writes one product with its variants and images.
+// Errors caused by the row's data come back as *importRowError.
+func insertImportedProduct(ctx context.Context, tx pgx.Tx, p ProductImport, categoryID, brandID *int64) error {
+	var productID int64
+	err := tx.QueryRow(ctx, `
+		INSERT INTO products (name, slug, description, category_id, brand_id, is_active)
+		VALUES ($1, $2, $3, $4, $5, false)
+		RETURNING id
+	`, p.Name, p.Slug, p.Description, categoryID, brandID).Scan(&productID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return &importRowError{line: p.Line, err: fmt.Errorf("product with slug '%s' already exists", p.Slug)}
+		}
+		return fmt.Errorf("insert product %q: %w", p.Slug, err)
+	}
+
+	for _, v := range p.Variants {
+		attrJSON, err := json.Marshal(v.Attributes)
+		if err != nil {
+			return &importRowError{line: v.Line, sku: v.SKU, err: fmt.Errorf("marshal attributes: %w", err)}
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO product_variants (product_id, sku, barcode, price_cents, cost_price_cents, attributes)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, productID, v.SKU, v.Barcode, v.PriceCents, v.CostPriceCents, attrJSON)
+		if err != nil {
+			if dup := variantUniqueViolation(err); dup != nil {
+				return &importRowError{line: v.Line, sku: v.SKU, err: dup}
+			}
+			return fmt.Errorf("insert variant %q: %w", v.SKU, err)
+		}
+	}
+
+	for i, url := range p.ImageURLs {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO product_images (product_id, url, is_primary, sort_order)
+			VALUES ($1, $2, $3, $4)
+		`, productID, url, i == 0, i)
+		if err != nil {
+			return fmt.Errorf("insert image for %q: %w", p.Slug, err)
+		}
+	}
+	return nil
+}
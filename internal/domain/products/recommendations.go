@@ -0,0 +1,195 @@
+package products
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ------------------------------------
+// Recommendations
+// ------------------------------------
+
+// RefreshRecommendations scores every pair of products that shared at least
+// MinSharedOrders paid orders by cosine similarity of their baskets, so a
+// pair of bestsellers doesn't drown out a niche product's real companion.
+// The old set is replaced in one transaction.
+func (r *Repository) RefreshRecommendations(ctx context.Context) (*RecommendationRefreshResult, error) {
+	res := &RecommendationRefreshResult{}
+	err := r.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM product_recommendations`); err != nil {
+			return fmt.Errorf("clear recommendations: %w", err)
+		}
+
+		tag, err := tx.Exec(ctx, `
+			WITH baskets AS (
+				SELECT DISTINCT oi.order_id, oi.product_id
+				FROM order_items oi
+				JOIN orders o ON o.id = oi.order_id
+				JOIN products p ON p.id = oi.product_id AND p.deleted_at IS NULL
+				WHERE o.payment_status = 'paid'
+				  AND o.status NOT IN ('cancelled', 'refunded')
+				  AND o.created_at >= NOW() - make_interval(secs => $1)
+			),
+			counts AS (
+				SELECT product_id, COUNT(*) AS n FROM baskets GROUP BY product_id
+			),
+			pairs AS (
+				SELECT a.product_id, b.product_id AS related_product_id, COUNT(*) AS shared
+				FROM baskets a
+				JOIN baskets b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+				GROUP BY a.product_id, b.product_id
+				HAVING COUNT(*) >= $2
+			),
+			scored AS (
+				SELECT pr.product_id, pr.related_product_id, pr.shared,
+				       pr.shared / sqrt(ca.n::float8 * cb.n) AS score
+				FROM pairs pr
+				JOIN counts ca ON ca.product_id = pr.product_id
+				JOIN counts cb ON cb.product_id = pr.related_product_id
+			),
+			ranked AS (
+				SELECT s.*, ROW_NUMBER() OVER (
+					PARTITION BY s.product_id
+					ORDER BY s.score DESC, s.shared DESC, s.related_product_id
+				) AS pos
+				FROM scored s
+			)
+			INSERT INTO product_recommendations (product_id, related_product_id, orders, score)
+			SELECT product_id, related_product_id, shared, score
+			FROM ranked
+			WHERE pos <= $3
+		`, CoPurchaseWindow.Seconds(), MinSharedOrders, MaxRecommendations)
+		if err != nil {
+			return fmt.Errorf("score recommendations: %w", err)
+		}
+		res.Pairs = tag.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *Repository) RelatedProducts(ctx context.Context, productID int64, limit int) (*RelatedProducts, error) {
+	together, err := r.queryIDs(ctx, `
+		SELECT rc.related_product_id
+		FROM product_recommendations rc
+		JOIN products p ON p.id = rc.related_product_id
+		WHERE rc.product_id = $1
+		  AND p.is_active AND p.deleted_at IS NULL
+		  AND EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_active)
+		ORDER BY rc.score DESC, rc.orders DESC, rc.related_product_id
+		LIMIT $2
+	`, productID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("frequently bought together: %w", err)
+	}
+
+	// Same category counts for more than same brand: a shopper looking at
+	// boots wants other boots before the brand's socks.
+	similar, err := r.queryIDs(ctx, `
+		SELECT p.id
+		FROM products me
+		JOIN products p ON p.id <> me.id
+		 AND (p.category_id = me.category_id OR p.brand_id = me.brand_id)
+		WHERE me.id = $1
+		  AND p.is_active AND p.deleted_at IS NULL
+		  AND NOT (p.id = ANY($3))
+		  AND EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_active)
+		ORDER BY 2 * COALESCE(p.category_id = me.category_id, FALSE)::int
+		           + COALESCE(p.brand_id = me.brand_id, FALSE)::int DESC,
+		         p.created_at DESC, p.id DESC
+		LIMIT $2
+	`, productID, limit, together)
+	if err != nil {
+		return nil, fmt.Errorf("similar products: %w", err)
+	}
+
+	out := &RelatedProducts{}
+	if out.FrequentlyBoughtTogether, err = r.ListCardsByIDs(ctx, together); err != nil {
+		return nil, err
+	}
+	if out.Similar, err = r.ListCardsByIDs(ctx, similar); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repository) queryIDs(ctx context.Context, q string, args ...any) ([]int64, error) {
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListCardsByIDs builds labelled cards for the products among ids that are
+// not deleted, in the order given.
+func (r *Repository) ListCardsByIDs(ctx context.Context, ids []int64) ([]*ProductCard, error) {
+	cards := make([]*ProductCard, 0, len(ids))
+	if len(ids) == 0 {
+		return cards, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.name, COALESCE(p.slug, ''), p.description,
+		       p.category_id, c.name, p.brand_id, b.name,
+		       p.is_active, p.created_at, p.updated_at,
+		       mp.min_price_cents, img.url
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS ids(id, pos)
+		JOIN products p ON p.id = ids.id AND p.deleted_at IS NULL
+		LEFT JOIN categories c ON c.id = p.category_id
+		LEFT JOIN brands b ON b.id = p.brand_id
+		LEFT JOIN LATERAL (
+			SELECT MIN(v.price_cents) AS min_price_cents
+			FROM product_variants v
+			WHERE v.product_id = p.id AND v.is_active = TRUE
+		) mp ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT i.url
+			FROM product_images i
+			WHERE i.product_id = p.id
+			ORDER BY i.is_primary DESC, i.sort_order ASC, i.id ASC
+			LIMIT 1
+		) img ON TRUE
+		ORDER BY ids.pos
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("load product cards: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pc ProductCard
+		if err := rows.Scan(
+			&pc.ID, &pc.Name, &pc.Slug, &pc.Description,
+			&pc.CategoryID, &pc.CategoryName, &pc.BrandID, &pc.BrandName,
+			&pc.IsActive, &pc.CreatedAt, &pc.UpdatedAt,
+			&pc.MinPriceCents, &pc.PrimaryImageURL,
+		); err != nil {
+			return nil, fmt.Errorf("scan product card: %w", err)
+		}
+		cards = append(cards, &pc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	if err := r.attachLabels(ctx, cards); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
@@ -6,12 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
 )
 
 var (
@@ -25,6 +25,16 @@ var (
 	ErrDuplicateSlug       = errors.New("slug already exists")
 	ErrInvalidParent       = errors.New("invalid parent category")
 	ErrCircularDependency  = errors.New("circular dependency detected")
+	ErrProductNotFound     = errors.New("product not found")
+	ErrNotInTrash          = errors.New("item is not in the trash")
+	ErrDuplicateSKU        = errors.New("a variant with this sku already exists")
+	ErrDuplicateBarcode    = errors.New("a variant with this barcode already exists")
+	ErrLabelNotFound       = errors.New("label not found")
+	ErrDuplicateLabel      = errors.New("a label with this key already exists")
+	ErrAutoLabel           = errors.New("labels with an auto rule cannot be deleted; deactivate them instead")
+	ErrAttributeNotFound   = errors.New("attribute not found")
+	ErrDuplicateAttribute  = errors.New("the category already defines an attribute with this key")
+	ErrVariantNotFound     = errors.New("variant not found")
 )
 
 // Store is the data access abstraction for the products domain.
@@ -66,8 +76,12 @@ type Store interface {
 	ListProductCards(
 		ctx context.Context,
 		categorySlug string,
+		attrs AttributeFilter,
 		limit, offset int,
 	) ([]*ProductCard, int, error)
+	// ProductFacets counts products per value of each filterable attribute
+	// in the category's subtree.
+	ProductFacets(ctx context.Context, categorySlug string, attrs AttributeFilter) ([]Facet, error)
 	GetProductDetailBySlug(ctx context.Context, slug string) (*ProductDetail, error)
 	ListAdminProductCards(ctx context.Context, limit, offset int) ([]*AdminProductCard, int, error)
 
@@ -83,6 +97,41 @@ type Store interface {
 	UpdateVariant(ctx context.Context, v *ProductVariant) error
 	DeleteVariant(ctx context.Context, id int64) error
 	ListAllVariants(ctx context.Context, limit, offset int) ([]*ProductVariant, int, error)
+	// GetVariantBySKU matches case-insensitively. Like GetVariantByID it
+	// returns nil, nil when nothing matches.
+	GetVariantBySKU(ctx context.Context, sku string) (*ProductVariant, error)
+	GetVariantByBarcode(ctx context.Context, barcode string) (*ProductVariant, error)
+	ListVariantsForExport(ctx context.Context) ([]VariantExportRow, error)
+	// ImportVariants applies every row or none. Rows naming an unknown SKU
+	// come back as issues and nothing is written.
+	ImportVariants(ctx context.Context, rows []VariantImportRow) ([]VariantImportIssue, error)
+	// ImportProducts creates draft products with their variants and images,
+	// batchSize products per transaction. A product that fails is reported
+	// in the result's issues and the rest carry on.
+	ImportProducts(ctx context.Context, items []ProductImport, batchSize int) (*ProductImportResult, error)
+	// EachCatalogRow streams the catalog one variant at a time.
+	EachCatalogRow(ctx context.Context, publicOnly bool, fn func(CatalogRow) error) error
+
+	// Labels
+	ListLabels(ctx context.Context) ([]*ProductLabel, error)
+	CreateLabel(ctx context.Context, l *ProductLabel) error
+	UpdateLabel(ctx context.Context, l *ProductLabel) error
+	DeleteLabel(ctx context.Context, id int64) error
+	// SetProductLabels replaces the product's manual labels. Auto-assigned
+	// labels not in the list are left to the refresh.
+	SetProductLabels(ctx context.Context, productID int64, labelIDs []int64) ([]CardLabel, error)
+	// RefreshAutoLabels reapplies the auto rules to the whole catalog.
+	RefreshAutoLabels(ctx context.Context) (*LabelRefreshResult, error)
+
+	// Attribute definitions
+	// ListCategoryAttributes returns the category's definitions together
+	// with those it inherits; a key defined closer to the category wins.
+	ListCategoryAttributes(ctx context.Context, categoryID int64) ([]*AttributeDefinition, error)
+	// ProductAttributes is ListCategoryAttributes for the product's category.
+	ProductAttributes(ctx context.Context, productID int64) ([]*AttributeDefinition, error)
+	CreateCategoryAttribute(ctx context.Context, a *AttributeDefinition) error
+	UpdateCategoryAttribute(ctx context.Context, a *AttributeDefinition) error
+	DeleteCategoryAttribute(ctx context.Context, categoryID, id int64) error
 
 	// Product images
 	CreateProductImage(ctx context.Context, img *ProductImage) (*ProductImage, error)
@@ -92,14 +141,46 @@ type Store interface {
 	UpdateProductImage(ctx context.Context, img *ProductImage) (*ProductImage, error)
 	DeleteProductImage(ctx context.Context, id int64) error
 	ReorderProductImages(ctx context.Context, productID int64, orderedIDs []int64) error
+
+	// Recommendations
+	// RefreshRecommendations recomputes the co-purchase scores from paid
+	// orders in CoPurchaseWindow, replacing the previous set.
+	RefreshRecommendations(ctx context.Context) (*RecommendationRefreshResult, error)
+	// RelatedProducts returns up to limit published products of each kind.
+	RelatedProducts(ctx context.Context, productID int64, limit int) (*RelatedProducts, error)
+	// ListCardsByIDs builds labelled cards for the products among ids that
+	// are not deleted, in the order given.
+	ListCardsByIDs(ctx context.Context, ids []int64) ([]*ProductCard, error)
+
+	// Wishlist
+	// AddToWishlist saves a published product, pinned to variantID when it
+	// is set; saving it again replaces the pin.
+	AddToWishlist(ctx context.Context, userID, productID int64, variantID *int64) error
+	RemoveFromWishlist(ctx context.Context, userID, productID int64) error
+	ListWishlist(ctx context.Context, userID int64, limit, offset int) ([]WishlistItem, int, error)
+	// WishlistedProductIDs returns which of productIDs the user saved.
+	WishlistedProductIDs(ctx context.Context, userID int64, productIDs []int64) (map[int64]struct{}, error)
+	// ClaimWishlistPriceDrops brings every saved price of the product (0 for
+	// all products) up to date and returns the ones that went down.
+	ClaimWishlistPriceDrops(ctx context.Context, productID int64) ([]WishlistPriceDrop, error)
+
+	// Trash (soft-deleted brands, categories and products)
+	ListTrashedBrands(ctx context.Context, limit, offset int) ([]*Brand, int, error)
+	ListTrashedCategories(ctx context.Context, limit, offset int) ([]*Category, int, error)
+	ListTrashedProducts(ctx context.Context, limit, offset int) ([]*Product, int, error)
+	RestoreBrand(ctx context.Context, id int64) (*Brand, error)
+	RestoreCategory(ctx context.Context, id int64) (*Category, error)
+	RestoreProduct(ctx context.Context, id int64) (*Product, error)
+	PurgeTrash(ctx context.Context, olderThan time.Duration) (*PurgeResult, error)
 }
 
 type Repository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	logger *zap.SugaredLogger
 }
 
-func NewRepository(db *pgxpool.Pool) Store {
-	return &Repository{db: db}
+func NewRepository(db *pgxpool.Pool, logger *zap.SugaredLogger) Store {
+	return &Repository{db: db, logger: logger}
 }
 
 // ------------------------------------
@@ -114,7 +195,7 @@ func (r *Repository) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error
 	// Use Rollback with check
 	defer func() {
 		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			log.Printf("warning: rollback failed: %v", err)
+			r.logger.Warnw("rollback failed", "error", err)
 		}
 	}()
 
@@ -177,7 +258,7 @@ func (r *Repository) BrandExistsByNameOrSlug(ctx context.Context, name, slug str
 }
 
 func (r *Repository) GetBrandByID(ctx context.Context, id int64) (*Brand, error) {
-	query := `SELECT id, name, slug, description, logo_url, created_at, updated_at FROM brands WHERE id = $1;`
+	query := `SELECT id, name, slug, description, logo_url, created_at, updated_at FROM brands WHERE id = $1 AND deleted_at IS NULL;`
 	b := &Brand{}
 	if err := r.db.QueryRow(ctx, query, id).
 		Scan(&b.ID, &b.Name, &b.Slug, &b.Description, &b.LogoURL, &b.CreatedAt, &b.UpdatedAt); err != nil {
@@ -204,6 +285,7 @@ func (r *Repository) ListBrandsWithTotal(ctx context.Context, limit, offset int)
 		SELECT id, name, slug, description, logo_url, created_at, updated_at,
 		       COUNT(*) OVER() AS total_count
 		FROM brands
+		WHERE deleted_at IS NULL
 		ORDER BY LOWER(name) ASC, id ASC
 		LIMIT $1 OFFSET $2;
 	`
@@ -236,7 +318,7 @@ func (r *Repository) ListBrandsWithTotal(ctx context.Context, limit, offset int)
 
 	// Fallback: user paged past the end → no rows, but total may be > 0.
 	if len(brands) == 0 && offset > 0 {
-		const countQ = `SELECT COUNT(*) FROM brands;`
+		const countQ = `SELECT COUNT(*) FROM brands WHERE deleted_at IS NULL;`
 		if err := r.db.QueryRow(ctx, countQ).Scan(&totalCount); err != nil {
 			return nil, 0, fmt.Errorf("count brands: %w", err)
 		}
@@ -254,7 +336,7 @@ func (r *Repository) UpdateBrand(ctx context.Context, b *Brand) error {
 			description = COALESCE($3, description),
 			logo_url = COALESCE($4, logo_url),
 			updated_at = now()
-		WHERE id = $5;
+		WHERE id = $5 AND deleted_at IS NULL;
 	`
 	_, err := r.db.Exec(ctx, query,
 		b.Name, b.Slug, b.Description, b.LogoURL, b.ID)
@@ -264,15 +346,14 @@ func (r *Repository) UpdateBrand(ctx context.Context, b *Brand) error {
 	return nil
 }
 
+// DeleteBrand moves a brand to the trash. The row is kept until the purge job
+// removes it, so it can still be restored with RestoreBrand.
 func (r *Repository) DeleteBrand(ctx context.Context, id int64) error {
-	cmd, err := r.db.Exec(ctx, `DELETE FROM brands WHERE id=$1;`, id)
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE brands
+		SET deleted_at = now(), updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL;`, id)
 	if err != nil {
-		// 23503 = foreign_key_violation (useful if your schema is RESTRICT)
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
-			// bubble up; handler will map to 409
-			return fmt.Errorf("brand has dependent records: %w", err)
-		}
 		return fmt.Errorf("delete brand: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
@@ -283,7 +364,7 @@ func (r *Repository) DeleteBrand(ctx context.Context, id int64) error {
 
 func (r *Repository) BrandHasProducts(ctx context.Context, id int64) (bool, error) {
 	var exists bool
-	err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE brand_id=$1)`, id).Scan(&exists)
+	err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE brand_id=$1 AND deleted_at IS NULL)`, id).Scan(&exists)
 	return exists, err
 }
 
@@ -335,7 +416,7 @@ func (r *Repository) CreateCategory(ctx context.Context, c *Category) (*Category
 
 func (r *Repository) CountCategories(ctx context.Context) (int, error) {
 	var n int
-	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM categories`).Scan(&n); err != nil {
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM categories WHERE deleted_at IS NULL`).Scan(&n); err != nil {
 		return 0, fmt.Errorf("count categories: %w", err)
 	}
 	return n, nil
@@ -345,7 +426,7 @@ func (r *Repository) GetCategoryByID(ctx context.Context, id int64) (*Category,
 	query := `
         SELECT id, name, slug, parent_id, image_urls, is_active, created_at, updated_at 
         FROM categories 
-        WHERE id = $1;
+        WHERE id = $1 AND deleted_at IS NULL;
     `
 
 	category := &Category{}
@@ -376,6 +457,7 @@ func (r *Repository) ListCategories(ctx context.Context, limit, offset int) ([]*
 			id, name, slug, parent_id, image_urls, is_active, created_at, updated_at,
 			COUNT(*) OVER() AS total_count
 		FROM categories 
+		WHERE deleted_at IS NULL
 		ORDER BY id 
 		LIMIT $1 OFFSET $2;`
 
@@ -439,7 +521,7 @@ func (r *Repository) UpdateCategory(ctx context.Context, c *Category) (*Category
             image_urls = COALESCE(NULLIF($4, '{}'::text[]), image_urls),
             is_active = COALESCE($5, is_active),
             updated_at = NOW()
-        WHERE id = $6
+        WHERE id = $6 AND deleted_at IS NULL
         RETURNING id, name, slug, parent_id, image_urls, is_active, created_at, updated_at;
     `
 
@@ -469,10 +551,13 @@ func (r *Repository) DeleteCategory(ctx context.Context, id int64) error {
 		return err
 	}
 	if hasChildren {
-		return fmt.Errorf("cannot delete category with children")
+		return ErrCategoryHasChildren
 	}
 
-	result, err := r.db.Exec(ctx, "DELETE FROM categories WHERE id = $1;", id)
+	result, err := r.db.Exec(ctx, `
+		UPDATE categories
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL;`, id)
 	if err != nil {
 		return fmt.Errorf("delete category: %w", err)
 	}
@@ -517,7 +602,7 @@ func (r *Repository) categorySlugExists(ctx context.Context, slug string, exclud
 func (r *Repository) hasChildren(ctx context.Context, parentID int64) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(ctx,
-		"SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = $1)",
+		"SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = $1 AND deleted_at IS NULL)",
 		parentID).Scan(&exists)
 	return exists, err
 }
@@ -541,9 +626,9 @@ func validateCategory(c *Category) error {
 func (r *Repository) GetCategoryStats(ctx context.Context, categoryID int64) (map[string]interface{}, error) {
 	query := `
 		SELECT 
-			(SELECT COUNT(*) FROM products WHERE category_id = $1 AND is_active = true) as product_count,
-			(SELECT COUNT(*) FROM categories WHERE parent_id = $1 AND is_active = true) as children_count,
-			(SELECT COUNT(*) FROM categories WHERE parent_id = $1) as total_children_count
+			(SELECT COUNT(*) FROM products WHERE category_id = $1 AND is_active = true AND deleted_at IS NULL) as product_count,
+			(SELECT COUNT(*) FROM categories WHERE parent_id = $1 AND is_active = true AND deleted_at IS NULL) as children_count,
+			(SELECT COUNT(*) FROM categories WHERE parent_id = $1 AND deleted_at IS NULL) as total_children_count
 	`
 
 	stats := make(map[string]interface{})
@@ -576,6 +661,7 @@ func (r *Repository) SearchCategories(ctx context.Context, query string, limit,
 		FROM categories 
 		WHERE 
 			(is_active = true) AND
+			(deleted_at IS NULL) AND
 			(
 				name % $1 OR                    -- Trigram similarity
 				word_similarity($1, name) > 0.4 OR  -- Match whole words
@@ -631,6 +717,7 @@ func (r *Repository) FullTextSearchCategories(ctx context.Context, query string,
 			ts_rank_cd(fts, plainto_tsquery('english', $1)) as rank
 		FROM categories 
 		WHERE fts @@ plainto_tsquery('english', $1)
+		  AND deleted_at IS NULL
 		ORDER BY rank DESC, name ASC
 		LIMIT $2 OFFSET $3
 	`
@@ -671,7 +758,7 @@ func (r *Repository) GetCategoryTree(ctx context.Context, includeInactive bool)
 				0 as level,
 				ARRAY[id] as path
 			FROM categories 
-			WHERE parent_id IS NULL
+			WHERE parent_id IS NULL AND deleted_at IS NULL
 			UNION ALL
 			SELECT 
 				c.id, c.name, c.slug, c.parent_id, c.image_urls, c.is_active, 
@@ -680,6 +767,7 @@ func (r *Repository) GetCategoryTree(ctx context.Context, includeInactive bool)
 				ct.path || c.id
 			FROM categories c
 			INNER JOIN category_tree ct ON c.parent_id = ct.id
+			WHERE c.deleted_at IS NULL
 		)
 		SELECT * FROM category_tree
 		WHERE is_active = true OR $1 = true
@@ -794,7 +882,7 @@ func (r *Repository) CreateProduct(ctx context.Context, p *Product) (*Product, e
 }
 
 func (r *Repository) GetProductByID(ctx context.Context, id int64) (*Product, error) {
-	query := `SELECT id, name, slug, description, category_id, brand_id, is_active, created_at, updated_at FROM products WHERE id=$1;`
+	query := `SELECT id, name, slug, description, category_id, brand_id, is_active, created_at, updated_at FROM products WHERE id=$1 AND deleted_at IS NULL;`
 	p := &Product{}
 	if err := r.db.QueryRow(ctx, query, id).
 		Scan(&p.ID, &p.Name, &p.Slug, &p.Description, &p.CategoryID, &p.BrandID, &p.IsActive, &p.CreatedAt, &p.UpdatedAt); err != nil {
@@ -814,6 +902,7 @@ func (r *Repository) ListProducts(ctx context.Context, limit, offset int) (produ
 	rows, err := r.db.Query(ctx, `
         SELECT id, name, slug, description, category_id, brand_id, is_active, created_at, updated_at
         FROM products
+        WHERE deleted_at IS NULL
         ORDER BY id DESC
         LIMIT $1 OFFSET $2
     `, limit, offset)
@@ -836,7 +925,7 @@ func (r *Repository) ListProducts(ctx context.Context, limit, offset int) (produ
 	}
 
 	// Get total count for pagination UI (showing "Showing 1-10 of 150 products")
-	err = r.db.QueryRow(ctx, `SELECT COUNT(*) FROM products`).Scan(&totalCount)
+	err = r.db.QueryRow(ctx, `SELECT COUNT(*) FROM products WHERE deleted_at IS NULL`).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, fmt.Errorf("count products: %w", err)
 	}
@@ -879,7 +968,7 @@ func (r *Repository) UpdateProduct(ctx context.Context, p *Product) (*Product, e
 	query := `
 		UPDATE products 
 		SET name=$1, slug=$2, description=$3, category_id=$4, brand_id=$5, is_active=$6, updated_at=now()
-		WHERE id=$7 RETURNING id, name, slug, description,
+		WHERE id=$7 AND deleted_at IS NULL RETURNING id, name, slug, description,
         category_id, brand_id, is_active, created_at, updated_at;
 	`
 	updated := &Product{}
@@ -899,11 +988,19 @@ func (r *Repository) UpdateProduct(ctx context.Context, p *Product) (*Product, e
 	return updated, nil
 }
 
+// DeleteProduct moves a product to the trash. Variants, images and order
+// history stay untouched so RestoreProduct can bring it back as it was.
 func (r *Repository) DeleteProduct(ctx context.Context, id int64) error {
-	_, err := r.db.Exec(ctx, `DELETE FROM products WHERE id=$1;`, id)
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE products
+		SET deleted_at = now(), updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL;`, id)
 	if err != nil {
 		return fmt.Errorf("delete product: %w", err)
 	}
+	if cmd.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
 	return nil
 }
 
@@ -911,7 +1008,7 @@ func (r *Repository) GetProductBySlug(ctx context.Context, slug string) (*Produc
 	query := `SELECT id, name, slug, description, 
                      category_id, brand_id, is_active,
                      created_at, updated_at
-              FROM products WHERE slug = $1;`
+              FROM products WHERE slug = $1 AND deleted_at IS NULL;`
 
 	product := &Product{}
 	err := r.db.QueryRow(ctx, query, slug).Scan(
@@ -931,7 +1028,7 @@ func (r *Repository) GetProductBySlug(ctx context.Context, slug string) (*Produc
 func (r *Repository) categoryExists(ctx context.Context, categoryID int64) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(ctx,
-		"SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND is_active = true)",
+		"SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND is_active = true AND deleted_at IS NULL)",
 		categoryID,
 	).Scan(&exists)
 	return exists, err
@@ -940,7 +1037,7 @@ func (r *Repository) categoryExists(ctx context.Context, categoryID int64) (bool
 func (r *Repository) brandExists(ctx context.Context, brandID int64) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(ctx,
-		"SELECT EXISTS(SELECT 1 FROM brands WHERE id = $1)",
+		"SELECT EXISTS(SELECT 1 FROM brands WHERE id = $1 AND deleted_at IS NULL)",
 		brandID,
 	).Scan(&exists)
 	return exists, err
@@ -986,13 +1083,16 @@ func (r *Repository) CreateVariant(ctx context.Context, v *ProductVariant) (*Pro
 	}
 
 	query := `
-		INSERT INTO product_variants (product_id, price_cents, attributes, is_active)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, product_id, price_cents, attributes, is_active, created_at, updated_at;
+		INSERT INTO product_variants (product_id, sku, barcode, price_cents, cost_price_cents, attributes, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, product_id, sku, barcode, price_cents, cost_price_cents, attributes, is_active, created_at, updated_at;
 	`
-	row := r.db.QueryRow(ctx, query, v.ProductID, v.PriceCents, attrJSON, v.IsActive)
+	row := r.db.QueryRow(ctx, query, v.ProductID, v.SKU, v.Barcode, v.PriceCents, v.CostPriceCents, attrJSON, v.IsActive)
 	var attrData []byte
-	if err := row.Scan(&v.ID, &v.ProductID, &v.PriceCents, &attrData, &v.IsActive, &v.CreatedAt, &v.UpdatedAt); err != nil {
+	if err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Barcode, &v.PriceCents, &v.CostPriceCents, &attrData, &v.IsActive, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		if dup := variantUniqueViolation(err); dup != nil {
+			return nil, dup
+		}
 		return nil, fmt.Errorf("create variant: %w", err)
 	}
 	if err := json.Unmarshal(attrData, &v.Attributes); err != nil {
@@ -1002,11 +1102,23 @@ func (r *Repository) CreateVariant(ctx context.Context, v *ProductVariant) (*Pro
 }
 
 func (r *Repository) GetVariantByID(ctx context.Context, id int64) (*ProductVariant, error) {
-	query := `SELECT id, product_id, price_cents, attributes, is_active, created_at, updated_at FROM product_variants WHERE id=$1;`
+	return r.getVariant(ctx, `id=$1`, id)
+}
+
+func (r *Repository) GetVariantBySKU(ctx context.Context, sku string) (*ProductVariant, error) {
+	return r.getVariant(ctx, `LOWER(sku)=LOWER($1)`, sku)
+}
+
+func (r *Repository) GetVariantByBarcode(ctx context.Context, barcode string) (*ProductVariant, error) {
+	return r.getVariant(ctx, `barcode=$1`, barcode)
+}
+
+func (r *Repository) getVariant(ctx context.Context, where string, arg any) (*ProductVariant, error) {
+	query := `SELECT id, product_id, sku, barcode, price_cents, cost_price_cents, attributes, is_active, created_at, updated_at FROM product_variants WHERE ` + where + `;`
 	v := &ProductVariant{}
 	var attrData []byte
-	if err := r.db.QueryRow(ctx, query, id).
-		Scan(&v.ID, &v.ProductID, &v.PriceCents, &attrData, &v.IsActive, &v.CreatedAt, &v.UpdatedAt); err != nil {
+	if err := r.db.QueryRow(ctx, query, arg).
+		Scan(&v.ID, &v.ProductID, &v.SKU, &v.Barcode, &v.PriceCents, &v.CostPriceCents, &attrData, &v.IsActive, &v.CreatedAt, &v.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
@@ -1017,7 +1129,7 @@ func (r *Repository) GetVariantByID(ctx context.Context, id int64) (*ProductVari
 }
 
 func (r *Repository) ListVariantsByProduct(ctx context.Context, productID int64) ([]*ProductVariant, error) {
-	query := `SELECT id, product_id, price_cents, attributes, is_active, created_at, updated_at FROM product_variants WHERE product_id=$1;`
+	query := `SELECT id, product_id, sku, barcode, price_cents, cost_price_cents, attributes, is_active, created_at, updated_at FROM product_variants WHERE product_id=$1;`
 	rows, err := r.db.Query(ctx, query, productID)
 	if err != nil {
 		return nil, fmt.Errorf("list variants: %w", err)
@@ -1028,7 +1140,7 @@ func (r *Repository) ListVariantsByProduct(ctx context.Context, productID int64)
 	for rows.Next() {
 		var v ProductVariant
 		var attrData []byte
-		if err := rows.Scan(&v.ID, &v.ProductID, &v.PriceCents, &attrData, &v.IsActive, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		if err := rows.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Barcode, &v.PriceCents, &v.CostPriceCents, &attrData, &v.IsActive, &v.CreatedAt, &v.UpdatedAt); err != nil {
 			return nil, err
 		}
 		json.Unmarshal(attrData, &v.Attributes)
@@ -1045,11 +1157,14 @@ func (r *Repository) UpdateVariant(ctx context.Context, v *ProductVariant) error
 
 	query := `
 		UPDATE product_variants 
-		SET price_cents=$1, attributes=$2, is_active=$3, updated_at=now()
-		WHERE id=$4;
+		SET sku=$1, barcode=$2, price_cents=$3, cost_price_cents=$4, attributes=$5, is_active=$6, updated_at=now()
+		WHERE id=$7;
 	`
-	_, err = r.db.Exec(ctx, query, v.PriceCents, attrJSON, v.IsActive, v.ID)
+	_, err = r.db.Exec(ctx, query, v.SKU, v.Barcode, v.PriceCents, v.CostPriceCents, attrJSON, v.IsActive, v.ID)
 	if err != nil {
+		if dup := variantUniqueViolation(err); dup != nil {
+			return dup
+		}
 		return fmt.Errorf("update variant: %w", err)
 	}
 	return nil
@@ -1075,7 +1190,10 @@ func (r *Repository) ListAllVariants(ctx context.Context, limit, offset int) ([]
 	SELECT 
 		id, 
 		product_id, 
+		sku,
+		barcode,
 		price_cents, 
+		cost_price_cents,
 		attributes, 
 		is_active, 
 		created_at, 
@@ -1102,7 +1220,10 @@ func (r *Repository) ListAllVariants(ctx context.Context, limit, offset int) ([]
 		if err := rows.Scan(
 			&v.ID,
 			&v.ProductID,
+			&v.SKU,
+			&v.Barcode,
 			&v.PriceCents,
+			&v.CostPriceCents,
 			&attrData,
 			&v.IsActive,
 			&v.CreatedAt,
@@ -1144,11 +1265,11 @@ func (r *Repository) CreateProductImage(ctx context.Context, img *ProductImage)
 			q := `
 				INSERT INTO product_images (product_id, product_variant_id, url, alt, is_primary, sort_order)
 				VALUES ($1, $2, $3, $4, $5, $6)
-				RETURNING id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at
+				RETURNING id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at, variants
 			`
 			row := tx.QueryRow(ctx, q, img.ProductID, img.ProductVariantID, img.URL, img.Alt, img.IsPrimary, img.SortOrder)
 			created = &ProductImage{}
-			if err := row.Scan(&created.ID, &created.ProductID, &created.ProductVariantID, &created.URL, &created.Alt, &created.IsPrimary, &created.SortOrder, &created.CreatedAt, &created.UpdatedAt); err != nil {
+			if err := row.Scan(&created.ID, &created.ProductID, &created.ProductVariantID, &created.URL, &created.Alt, &created.IsPrimary, &created.SortOrder, &created.CreatedAt, &created.UpdatedAt, &created.Variants); err != nil {
 				return fmt.Errorf("insert product_image: %w", err)
 			}
 			return nil
@@ -1163,11 +1284,11 @@ func (r *Repository) CreateProductImage(ctx context.Context, img *ProductImage)
 	q := `
 		INSERT INTO product_images (product_id, product_variant_id, url, alt, is_primary, sort_order)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at
+		RETURNING id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at, variants
 	`
 	created := &ProductImage{}
 	if err := r.db.QueryRow(ctx, q, img.ProductID, img.ProductVariantID, img.URL, img.Alt, img.IsPrimary, img.SortOrder).
-		Scan(&created.ID, &created.ProductID, &created.ProductVariantID, &created.URL, &created.Alt, &created.IsPrimary, &created.SortOrder, &created.CreatedAt, &created.UpdatedAt); err != nil {
+		Scan(&created.ID, &created.ProductID, &created.ProductVariantID, &created.URL, &created.Alt, &created.IsPrimary, &created.SortOrder, &created.CreatedAt, &created.UpdatedAt, &created.Variants); err != nil {
 		return nil, fmt.Errorf("create product_image: %w", err)
 	}
 	return created, nil
@@ -1175,10 +1296,10 @@ func (r *Repository) CreateProductImage(ctx context.Context, img *ProductImage)
 
 // GetProductImageByID fetches a single image by id.
 func (r *Repository) GetProductImageByID(ctx context.Context, id int64) (*ProductImage, error) {
-	q := `SELECT id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at FROM product_images WHERE id = $1`
+	q := `SELECT id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at, variants FROM product_images WHERE id = $1`
 	img := &ProductImage{}
 	if err := r.db.QueryRow(ctx, q, id).
-		Scan(&img.ID, &img.ProductID, &img.ProductVariantID, &img.URL, &img.Alt, &img.IsPrimary, &img.SortOrder, &img.CreatedAt, &img.UpdatedAt); err != nil {
+		Scan(&img.ID, &img.ProductID, &img.ProductVariantID, &img.URL, &img.Alt, &img.IsPrimary, &img.SortOrder, &img.CreatedAt, &img.UpdatedAt, &img.Variants); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
@@ -1190,7 +1311,7 @@ func (r *Repository) GetProductImageByID(ctx context.Context, id int64) (*Produc
 // ListProductImagesByProduct lists images for a product ordered by primary desc, sort_order asc, created_at asc
 func (r *Repository) ListProductImagesByProduct(ctx context.Context, productID int64) ([]*ProductImage, error) {
 	q := `
-		SELECT id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at
+		SELECT id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at, variants
 		FROM product_images
 		WHERE product_id = $1
 		ORDER BY is_primary DESC, sort_order ASC, created_at ASC
@@ -1204,7 +1325,7 @@ func (r *Repository) ListProductImagesByProduct(ctx context.Context, productID i
 	var out []*ProductImage
 	for rows.Next() {
 		var img ProductImage
-		if err := rows.Scan(&img.ID, &img.ProductID, &img.ProductVariantID, &img.URL, &img.Alt, &img.IsPrimary, &img.SortOrder, &img.CreatedAt, &img.UpdatedAt); err != nil {
+		if err := rows.Scan(&img.ID, &img.ProductID, &img.ProductVariantID, &img.URL, &img.Alt, &img.IsPrimary, &img.SortOrder, &img.CreatedAt, &img.UpdatedAt, &img.Variants); err != nil {
 			return nil, fmt.Errorf("scan product_image: %w", err)
 		}
 		out = append(out, &img)
@@ -1246,12 +1367,13 @@ func (r *Repository) UpdateProductImage(ctx context.Context, img *ProductImage)
 		UPDATE product_images
 		SET
 			url = COALESCE(NULLIF($1, ''), url),
+			variants = CASE WHEN NULLIF($1, '') IS NULL OR $1 = url THEN variants END,
 			alt = COALESCE($2, alt),
 			is_primary = COALESCE($3, is_primary),
 			sort_order = COALESCE($4, sort_order),
 			updated_at = now()
 		WHERE id = $5
-		RETURNING id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at
+		RETURNING id, product_id, product_variant_id, url, alt, is_primary, sort_order, created_at, updated_at, variants
 	`
 	// We intentionally pass empty string for url if not provided (NULLIF handles it)
 	var alt interface{} = img.Alt
@@ -1266,7 +1388,7 @@ func (r *Repository) UpdateProductImage(ctx context.Context, img *ProductImage)
 
 	updated := &ProductImage{}
 	if err := r.db.QueryRow(ctx, q, img.URL, alt, isPrimary, sortOrder, img.ID).
-		Scan(&updated.ID, &updated.ProductID, &updated.ProductVariantID, &updated.URL, &updated.Alt, &updated.IsPrimary, &updated.SortOrder, &updated.CreatedAt, &updated.UpdatedAt); err != nil {
+		Scan(&updated.ID, &updated.ProductID, &updated.ProductVariantID, &updated.URL, &updated.Alt, &updated.IsPrimary, &updated.SortOrder, &updated.CreatedAt, &updated.UpdatedAt, &updated.Variants); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
@@ -1324,6 +1446,7 @@ func (r *Repository) ReorderProductImages(ctx context.Context, productID int64,
 func (r *Repository) ListProductCards(
 	ctx context.Context,
 	categorySlug string,
+	attrs AttributeFilter,
 	limit, offset int,
 ) ([]*ProductCard, int, error) {
 
@@ -1345,12 +1468,14 @@ WITH RECURSIVE cat_subtree AS (
   SELECT id, slug
   FROM categories
   WHERE ($1 = '' OR slug = $1)
+    AND deleted_at IS NULL
 
   UNION ALL
 
   SELECT c.id, c.slug
   FROM categories c
   INNER JOIN cat_subtree cs ON c.parent_id = cs.id
+  WHERE c.deleted_at IS NULL
 )
 `
 
@@ -1476,12 +1601,18 @@ LEFT JOIN LATERAL (
 ) off ON TRUE
 
 WHERE
-  ($1 = '' OR p.category_id IN (SELECT id FROM cat_subtree))
+  p.deleted_at IS NULL
+  AND ($1 = '' OR p.category_id IN (SELECT id FROM cat_subtree))
+  AND ` + variantMatchSQL(4) + `
 ORDER BY p.id DESC
 LIMIT $2 OFFSET $3;
 `
 
-	rows, err := r.db.Query(ctx, dataSQL, categorySlug, limit, offset)
+	attrJSON, err := attrs.json()
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.Query(ctx, dataSQL, categorySlug, limit, offset, attrJSON)
 	if err != nil {
 		return nil, 0, fmt.Errorf("list product cards: %w", err)
 	}
@@ -1628,13 +1759,19 @@ LIMIT $2 OFFSET $3;
 	countSQL := catCTE + `
 SELECT COUNT(*)
 FROM products p
-WHERE ($1 = '' OR p.category_id IN (SELECT id FROM cat_subtree));
+WHERE p.deleted_at IS NULL
+  AND ($1 = '' OR p.category_id IN (SELECT id FROM cat_subtree))
+  AND ` + variantMatchSQL(2) + `;
 `
 	var total int
-	if err := r.db.QueryRow(ctx, countSQL, categorySlug).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countSQL, categorySlug, attrJSON).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count products: %w", err)
 	}
 
+	if err := r.attachLabels(ctx, cards); err != nil {
+		return nil, 0, err
+	}
+
 	return cards, total, nil
 }
 
@@ -1647,7 +1784,7 @@ func (r *Repository) GetProductDetailBySlug(ctx context.Context, slug string) (*
       FROM products p
       LEFT JOIN brands b     ON b.id = p.brand_id
       LEFT JOIN categories c ON c.id = p.category_id
-      WHERE p.slug = $1
+      WHERE p.slug = $1 AND p.deleted_at IS NULL
       LIMIT 1;
     `
 
@@ -1784,7 +1921,7 @@ func (r *Repository) GetProductDetailBySlug(ctx context.Context, slug string) (*
 
 	// 2) active variants (ordered by price asc)
 	vSQL := `
-      SELECT id, product_id, price_cents, attributes, is_active, created_at, updated_at
+      SELECT id, product_id, sku, barcode, price_cents, attributes, is_active, created_at, updated_at
       FROM product_variants
       WHERE product_id = $1 AND is_active = true
       ORDER BY price_cents ASC, id ASC;
@@ -1802,6 +1939,8 @@ func (r *Repository) GetProductDetailBySlug(ctx context.Context, slug string) (*
 		if err := vRows.Scan(
 			&v.ID,
 			&v.ProductID,
+			&v.SKU,
+			&v.Barcode,
 			&v.PriceCents,
 			&attr,
 			&v.IsActive,
@@ -1858,6 +1997,7 @@ func (r *Repository) ListAdminProductCards(ctx context.Context, limit, offset in
       LEFT JOIN LATERAL (
           SELECT COUNT(*) AS cnt FROM product_images i WHERE i.product_id = p.id
       ) i_cnt ON true
+      WHERE p.deleted_at IS NULL
       ORDER BY p.id DESC
       LIMIT $1 OFFSET $2;
     `
@@ -1908,7 +2048,7 @@ func (r *Repository) ListAdminProductCards(ctx context.Context, limit, offset in
 	}
 
 	var total int
-	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM products`).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM products WHERE deleted_at IS NULL`).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count products: %w", err)
 	}
 	return out, total, nil
@@ -1940,6 +2080,7 @@ LEFT JOIN LATERAL (
   LIMIT 1
 ) img ON true
 WHERE p.is_active = true
+  AND p.deleted_at IS NULL
   AND (
     p.name ILIKE '%' || $1 || '%'
     OR COALESCE(p.description, '') ILIKE '%' || $1 || '%'
@@ -2001,6 +2142,10 @@ LIMIT $2 OFFSET $3;
 		return nil, 0, fmt.Errorf("rows: %w", err)
 	}
 
+	if err := r.attachLabels(ctx, out); err != nil {
+		return nil, 0, err
+	}
+
 	return out, total, nil
 }
 
@@ -2021,6 +2166,7 @@ WITH ranked AS (
     ts_rank_cd(p.fts, plainto_tsquery('english', $1)) AS rank
   FROM products p
   WHERE p.fts @@ plainto_tsquery('english', $1)
+    AND p.deleted_at IS NULL
 )
 SELECT
   r.id, r.name, r.slug, r.description,
@@ -2096,6 +2242,14 @@ LIMIT $2 OFFSET $3;
 		return nil, 0, fmt.Errorf("rows: %w", err)
 	}
 
+	cards := make([]*ProductCard, len(out))
+	for i := range out {
+		cards[i] = &out[i].ProductCard
+	}
+	if err := r.attachLabels(ctx, cards); err != nil {
+		return nil, 0, err
+	}
+
 	return out, total, nil
 }
 
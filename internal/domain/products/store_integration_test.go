@@ -0,0 +1,161 @@
+//go:build integration
+
+package products
+
+import (
+	"context"
+	"testing"
+
+	"khel/internal/testutil"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+// catalog inserts rows with raw SQL so the tests only depend on the card
+// queries, not on the create paths.
+type catalog struct {
+	t  *testing.T
+	db *pgxpool.Pool
+}
+
+func (c catalog) id(sql string, args ...any) int64 {
+	c.t.Helper()
+	var id int64
+	if err := c.db.QueryRow(context.Background(), sql+" RETURNING id", args...).Scan(&id); err != nil {
+		c.t.Fatalf("%s: %v", sql, err)
+	}
+	return id
+}
+
+func (c catalog) category(slug string, parent *int64) int64 {
+	return c.id(`INSERT INTO categories (name, slug, parent_id) VALUES ($1, $1, $2)`, slug, parent)
+}
+
+func (c catalog) product(slug string, categoryID int64) int64 {
+	return c.id(`INSERT INTO products (name, slug, category_id) VALUES ($1, $1, $2)`, slug, categoryID)
+}
+
+func (c catalog) variant(productID int64, sku string, priceCents int64, active bool) int64 {
+	return c.id(`INSERT INTO product_variants (product_id, sku, price_cents, is_active) VALUES ($1, $2, $3, $4)`,
+		productID, sku, priceCents, active)
+}
+
+func cardsBySlug(cards []*ProductCard) map[string]*ProductCard {
+	out := make(map[string]*ProductCard, len(cards))
+	for _, c := range cards {
+		out[c.Slug] = c
+	}
+	return out
+}
+
+func TestListProductCardsCategorySubtree(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewRepository(db)
+	c := catalog{t, db}
+
+	footwear := c.category("footwear", nil)
+	futsal := c.category("futsal-shoes", &footwear)
+	balls := c.category("balls", nil)
+
+	c.product("shoe-a", futsal)
+	c.product("shoe-b", footwear)
+	c.product("ball-a", balls)
+	deleted := c.product("shoe-deleted", futsal)
+	if _, err := db.Exec(context.Background(), `UPDATE products SET deleted_at = NOW() WHERE id = $1`, deleted); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		slug string
+		want []string
+	}{
+		{"footwear", []string{"shoe-b", "shoe-a"}}, // includes the child category
+		{"futsal-shoes", []string{"shoe-a"}},
+		{"", []string{"ball-a", "shoe-b", "shoe-a"}},
+		{"unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run("slug="+tt.slug, func(t *testing.T) {
+			cards, total, err := repo.ListProductCards(context.Background(), tt.slug, nil, 10, 0)
+			if err != nil {
+				t.Fatalf("ListProductCards: %v", err)
+			}
+			if total != len(tt.want) || len(cards) != len(tt.want) {
+				t.Fatalf("got %d cards (total %d), want %d", len(cards), total, len(tt.want))
+			}
+			for i, slug := range tt.want {
+				if cards[i].Slug != slug {
+					t.Errorf("card %d = %s, want %s (newest first)", i, cards[i].Slug, slug)
+				}
+			}
+		})
+	}
+}
+
+func TestListProductCardsPriceImageAndOffer(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewRepository(db)
+	c := catalog{t, db}
+	ctx := context.Background()
+
+	cat := c.category("rackets", nil)
+	racket := c.product("racket", cat)
+	c.variant(racket, "R-1", 5000, true)
+	cheap := c.variant(racket, "R-2", 4000, true)
+	c.variant(racket, "R-3", 1000, false) // inactive variants don't set the price
+
+	c.id(`INSERT INTO product_images (product_id, url, sort_order) VALUES ($1, 'https://img/2', 0)`, racket)
+	c.id(`INSERT INTO product_images (product_id, url, is_primary, sort_order) VALUES ($1, 'https://img/primary', TRUE, 5)`, racket)
+
+	plain := c.product("plain", cat)
+
+	sale := c.id(`INSERT INTO featured_collections (key, title, type) VALUES ('sale', 'Sale', 'deals')`)
+	expired := c.id(`INSERT INTO featured_collections (key, title, type, ends_at) VALUES ('old', 'Old', 'deals', NOW() - INTERVAL '1 day')`)
+	// A product-level offer, a better variant-level one, and one in an
+	// expired collection that must be ignored.
+	c.id(`INSERT INTO featured_items (collection_id, position, product_id, deal_percent) VALUES ($1, 1, $2, 10)`, sale, racket)
+	variantOffer := c.id(`INSERT INTO featured_items (collection_id, position, product_variant_id, deal_price_cents, badge_text) VALUES ($1, 2, $2, 3500, 'Hot')`, sale, cheap)
+	c.id(`INSERT INTO featured_items (collection_id, position, product_id, deal_price_cents) VALUES ($1, 1, $2, 100)`, expired, plain)
+
+	cards, _, err := repo.ListProductCards(ctx, "rackets", nil, 10, 0)
+	if err != nil {
+		t.Fatalf("ListProductCards: %v", err)
+	}
+	bySlug := cardsBySlug(cards)
+
+	r := bySlug["racket"]
+	if r == nil {
+		t.Fatal("racket card missing")
+	}
+	if r.MinPriceCents == nil || *r.MinPriceCents != 4000 {
+		t.Errorf("MinPriceCents = %v, want 4000", r.MinPriceCents)
+	}
+	if r.PrimaryImageURL == nil || *r.PrimaryImageURL != "https://img/primary" {
+		t.Errorf("PrimaryImageURL = %v, want the primary image", r.PrimaryImageURL)
+	}
+	if r.Offer == nil {
+		t.Fatal("racket has no offer")
+	}
+	if r.Offer.FeaturedItemID != variantOffer {
+		t.Errorf("offer = featured item %d, want the variant offer %d", r.Offer.FeaturedItemID, variantOffer)
+	}
+	if r.Offer.DealPriceCents == nil || *r.Offer.DealPriceCents != 3500 {
+		t.Errorf("DealPriceCents = %v, want 3500", r.Offer.DealPriceCents)
+	}
+	if r.Offer.ProductVariantID == nil || *r.Offer.ProductVariantID != cheap {
+		t.Errorf("ProductVariantID = %v, want %d", r.Offer.ProductVariantID, cheap)
+	}
+
+	p := bySlug["plain"]
+	if p == nil {
+		t.Fatal("plain card missing")
+	}
+	if p.MinPriceCents != nil || p.PrimaryImageURL != nil {
+		t.Errorf("plain card has price %v / image %v, want neither", p.MinPriceCents, p.PrimaryImageURL)
+	}
+	if p.Offer != nil {
+		t.Errorf("plain card has an offer from an expired collection: %+v", p.Offer)
+	}
+}
@@ -0,0 +1,303 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ------------------------------------
+// Trash (soft-deleted catalog rows)
+// ------------------------------------
+
+func (r *Repository) ListTrashedBrands(ctx context.Context, limit, offset int) ([]*Brand, int, error) {
+	const q = `
+		SELECT id, name, slug, description, logo_url, created_at, updated_at, deleted_at,
+		       COUNT(*) OVER() AS total_count
+		FROM brands
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2;
+	`
+	rows, err := r.db.Query(ctx, q, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list trashed brands: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		brands []*Brand
+		total  int
+	)
+	for rows.Next() {
+		var b Brand
+		if err := rows.Scan(&b.ID, &b.Name, &b.Slug, &b.Description, &b.LogoURL,
+			&b.CreatedAt, &b.UpdatedAt, &b.DeletedAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan trashed brand: %w", err)
+		}
+		brands = append(brands, &b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return brands, total, nil
+}
+
+func (r *Repository) ListTrashedCategories(ctx context.Context, limit, offset int) ([]*Category, int, error) {
+	const q = `
+		SELECT id, name, slug, parent_id, image_urls, is_active, created_at, updated_at, deleted_at,
+		       COUNT(*) OVER() AS total_count
+		FROM categories
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2;
+	`
+	rows, err := r.db.Query(ctx, q, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list trashed categories: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		list  []*Category
+		total int
+	)
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.ParentID, &c.ImageURLs, &c.IsActive,
+			&c.CreatedAt, &c.UpdatedAt, &c.DeletedAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan trashed category: %w", err)
+		}
+		list = append(list, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) ListTrashedProducts(ctx context.Context, limit, offset int) ([]*Product, int, error) {
+	const q = `
+		SELECT id, name, COALESCE(slug, ''), description, category_id, brand_id, is_active,
+		       created_at, updated_at, deleted_at,
+		       COUNT(*) OVER() AS total_count
+		FROM products
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2;
+	`
+	rows, err := r.db.Query(ctx, q, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list trashed products: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		list  []*Product
+		total int
+	)
+	for rows.Next() {
+		var p Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Slug, &p.Description, &p.CategoryID, &p.BrandID,
+			&p.IsActive, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan trashed product: %w", err)
+		}
+		list = append(list, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+// RestoreBrand takes a brand out of the trash.
+func (r *Repository) RestoreBrand(ctx context.Context, id int64) (*Brand, error) {
+	const q = `
+		UPDATE brands
+		SET deleted_at = NULL, updated_at = now()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id, name, slug, description, logo_url, created_at, updated_at;
+	`
+	b := &Brand{}
+	err := r.db.QueryRow(ctx, q, id).
+		Scan(&b.ID, &b.Name, &b.Slug, &b.Description, &b.LogoURL, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotInTrash
+		}
+		return nil, fmt.Errorf("restore brand: %w", err)
+	}
+	return b, nil
+}
+
+// RestoreCategory takes a category out of the trash. A category whose parent is
+// still trashed cannot be restored, otherwise it would vanish from the tree.
+func (r *Repository) RestoreCategory(ctx context.Context, id int64) (*Category, error) {
+	const q = `
+		UPDATE categories c
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE c.id = $1
+		  AND c.deleted_at IS NOT NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM categories p
+		      WHERE p.id = c.parent_id AND p.deleted_at IS NOT NULL
+		  )
+		RETURNING c.id, c.name, c.slug, c.parent_id, c.image_urls, c.is_active, c.created_at, c.updated_at;
+	`
+	c := &Category{}
+	err := r.db.QueryRow(ctx, q, id).
+		Scan(&c.ID, &c.Name, &c.Slug, &c.ParentID, &c.ImageURLs, &c.IsActive, &c.CreatedAt, &c.UpdatedAt)
+	if err == nil {
+		return c, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("restore category: %w", err)
+	}
+
+	// Tell "not in trash" apart from "parent still trashed".
+	var parentTrashed bool
+	err = r.db.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM categories c
+			JOIN categories p ON p.id = c.parent_id
+			WHERE c.id = $1 AND c.deleted_at IS NOT NULL AND p.deleted_at IS NOT NULL
+		)`, id).Scan(&parentTrashed)
+	if err != nil {
+		return nil, fmt.Errorf("check category parent: %w", err)
+	}
+	if parentTrashed {
+		return nil, ErrInvalidParent
+	}
+	return nil, ErrNotInTrash
+}
+
+// RestoreProduct takes a product out of the trash.
+func (r *Repository) RestoreProduct(ctx context.Context, id int64) (*Product, error) {
+	const q = `
+		UPDATE products
+		SET deleted_at = NULL, updated_at = now()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id, name, COALESCE(slug, ''), description, category_id, brand_id, is_active, created_at, updated_at;
+	`
+	p := &Product{}
+	err := r.db.QueryRow(ctx, q, id).
+		Scan(&p.ID, &p.Name, &p.Slug, &p.Description, &p.CategoryID, &p.BrandID, &p.IsActive, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotInTrash
+		}
+		return nil, fmt.Errorf("restore product: %w", err)
+	}
+	return p, nil
+}
+
+// PurgeTrash hard-deletes catalog rows that have been in the trash for longer
+// than olderThan. Rows still referenced by orders, carts, featured items or
+// other (even trashed) catalog rows are skipped and retried on the next run.
+func (r *Repository) PurgeTrash(ctx context.Context, olderThan time.Duration) (*PurgeResult, error) {
+	cutoff := time.Now().Add(-olderThan)
+	res := &PurgeResult{}
+
+	err := r.WithTx(ctx, func(tx pgx.Tx) error {
+		// 1) Products first, so brands/categories they pointed to become free.
+		rows, err := tx.Query(ctx, `
+			SELECT p.id
+			FROM products p
+			WHERE p.deleted_at IS NOT NULL
+			  AND p.deleted_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM order_items oi WHERE oi.product_id = p.id)
+			  AND NOT EXISTS (SELECT 1 FROM featured_items fi WHERE fi.product_id = p.id)
+			  AND NOT EXISTS (
+			      SELECT 1
+			      FROM product_variants v
+			      WHERE v.product_id = p.id
+			        AND (
+			          EXISTS (SELECT 1 FROM order_items oi WHERE oi.product_variant_id = v.id)
+			          OR EXISTS (SELECT 1 FROM cart_items ci WHERE ci.product_variant_id = v.id)
+			          OR EXISTS (SELECT 1 FROM featured_items fi WHERE fi.product_variant_id = v.id)
+			        )
+			  )
+			FOR UPDATE OF p SKIP LOCKED;`, cutoff)
+		if err != nil {
+			return fmt.Errorf("select purgeable products: %w", err)
+		}
+		productIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+		if err != nil {
+			return fmt.Errorf("scan purgeable products: %w", err)
+		}
+
+		if len(productIDs) > 0 {
+			rows, err = tx.Query(ctx, `SELECT url FROM product_images WHERE product_id = ANY($1);`, productIDs)
+			if err != nil {
+				return fmt.Errorf("select product images: %w", err)
+			}
+			urls, err := pgx.CollectRows(rows, pgx.RowTo[string])
+			if err != nil {
+				return fmt.Errorf("scan product images: %w", err)
+			}
+			res.ImageURLs = append(res.ImageURLs, urls...)
+
+			// variants and images go with the product (ON DELETE CASCADE)
+			cmd, err := tx.Exec(ctx, `DELETE FROM products WHERE id = ANY($1);`, productIDs)
+			if err != nil {
+				return fmt.Errorf("purge products: %w", err)
+			}
+			res.Products = cmd.RowsAffected()
+		}
+
+		// 2) Brands nobody points at anymore.
+		rows, err = tx.Query(ctx, `
+			DELETE FROM brands b
+			WHERE b.deleted_at IS NOT NULL
+			  AND b.deleted_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM products p WHERE p.brand_id = b.id)
+			RETURNING COALESCE(b.logo_url, '');`, cutoff)
+		if err != nil {
+			return fmt.Errorf("purge brands: %w", err)
+		}
+		logos, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("scan purged brands: %w", err)
+		}
+		res.Brands = int64(len(logos))
+		for _, u := range logos {
+			if u != "" {
+				res.ImageURLs = append(res.ImageURLs, u)
+			}
+		}
+
+		// 3) Leaf categories with no products and no children left.
+		rows, err = tx.Query(ctx, `
+			DELETE FROM categories c
+			WHERE c.deleted_at IS NOT NULL
+			  AND c.deleted_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM categories ch WHERE ch.parent_id = c.id)
+			  AND NOT EXISTS (SELECT 1 FROM products p WHERE p.category_id = c.id)
+			RETURNING COALESCE(c.image_urls, '{}');`, cutoff)
+		if err != nil {
+			return fmt.Errorf("purge categories: %w", err)
+		}
+		images, err := pgx.CollectRows(rows, pgx.RowTo[[]string])
+		if err != nil {
+			return fmt.Errorf("scan purged categories: %w", err)
+		}
+		res.Categories = int64(len(images))
+		for _, urls := range images {
+			for _, u := range urls {
+				if u != "" {
+					res.ImageURLs = append(res.ImageURLs, u)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
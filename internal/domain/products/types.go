@@ -1,26 +1,31 @@
 package products
 
-import "time"
+import (
+	"khel/internal/images"
+	"time"
+)
 
 type Brand struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Slug        *string   `json:"slug"`
-	Description *string   `json:"description,omitempty"`
-	LogoURL     *string   `json:"logo_url,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Slug        *string    `json:"slug"`
+	Description *string    `json:"description,omitempty"`
+	LogoURL     *string    `json:"logo_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Category struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Slug      string    `json:"slug"`
-	ParentID  *int64    `json:"parent_id,omitempty"`
-	ImageURLs []string  `json:"image_urls,omitempty"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	ParentID  *int64     `json:"parent_id,omitempty"`
+	ImageURLs []string   `json:"image_urls,omitempty"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // CategoryWithRank includes relevance score for full-text search
@@ -44,25 +49,70 @@ type CategoryWithChildren struct {
 }
 
 type Product struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Slug        string    `json:"slug"`
-	Description *string   `json:"description,omitempty"`
-	CategoryID  *int64    `json:"category_id,omitempty"`
-	BrandID     *int64    `json:"brand_id,omitempty"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	Description *string    `json:"description,omitempty"`
+	CategoryID  *int64     `json:"category_id,omitempty"`
+	BrandID     *int64     `json:"brand_id,omitempty"`
+	IsActive    bool       `json:"is_active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// PurgeResult reports what the trash purge removed for good. ImageURLs are the
+// Cloudinary assets that no longer have an owner and can be cleaned up.
+type PurgeResult struct {
+	Brands     int64    `json:"brands"`
+	Categories int64    `json:"categories"`
+	Products   int64    `json:"products"`
+	ImageURLs  []string `json:"-"`
 }
 
 type ProductVariant struct {
-	ID         int64          `json:"id"`
-	ProductID  int64          `json:"product_id"`
-	PriceCents int64          `json:"price_cents"`
-	Attributes map[string]any `json:"attributes,omitempty"`
-	IsActive   bool           `json:"is_active"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
+	ID         int64   `json:"id"`
+	ProductID  int64   `json:"product_id"`
+	SKU        string  `json:"sku"`
+	Barcode    *string `json:"barcode,omitempty"`
+	PriceCents int64   `json:"price_cents"`
+	// CostPriceCents is the merchant's landed cost per unit. Public handlers
+	// must clear it before responding.
+	CostPriceCents *int64         `json:"cost_price_cents,omitempty"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+	IsActive       bool           `json:"is_active"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// VariantExportRow is one line of the variant CSV used by the warehouse.
+type VariantExportRow struct {
+	VariantID      int64
+	ProductID      int64
+	ProductName    string
+	SKU            string
+	Barcode        *string
+	PriceCents     int64
+	CostPriceCents *int64
+	IsActive       bool
+}
+
+// VariantImportRow updates the variant with the given SKU. Nil fields are
+// left as they are; ClearBarcode removes the barcode.
+type VariantImportRow struct {
+	Line           int
+	SKU            string
+	Barcode        *string
+	ClearBarcode   bool
+	PriceCents     *int64
+	CostPriceCents *int64
+	IsActive       *bool
+}
+
+type VariantImportIssue struct {
+	Line  int    `json:"line"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
 }
 
 type ProductImage struct {
@@ -75,6 +125,8 @@ type ProductImage struct {
 	SortOrder        int       `json:"sort_order"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
+	// Variants is nil until the image has been processed.
+	Variants *images.Variants `json:"variants,omitempty"`
 }
 
 // Lightweight “card” for lists
@@ -93,7 +145,117 @@ type ProductCard struct {
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 
-	Offer *ProductOffer `json:"offer,omitempty"`
+	Offer  *ProductOffer `json:"offer,omitempty"`
+	Labels []CardLabel   `json:"labels"`
+	// Wishlisted is only set for signed-in shoppers.
+	Wishlisted *bool `json:"wishlisted,omitempty"`
+}
+
+// Recommendation tuning.
+const (
+	// CoPurchaseWindow is how far back orders count towards "bought together".
+	CoPurchaseWindow = 180 * 24 * time.Hour
+	// MinSharedOrders is how many orders two products must share before
+	// they are recommended together, so one odd basket isn't a pattern.
+	MinSharedOrders = 2
+	// MaxRecommendations caps how many pairs are kept per product.
+	MaxRecommendations = 12
+)
+
+// RelatedProducts backs the product detail screen. FrequentlyBoughtTogether
+// comes from the nightly co-purchase scores; Similar fills in from the same
+// category and brand and never repeats those.
+type RelatedProducts struct {
+	FrequentlyBoughtTogether []*ProductCard `json:"frequently_bought_together"`
+	Similar                  []*ProductCard `json:"similar"`
+}
+
+// RecommendationRefreshResult counts the pairs a refresh stored.
+type RecommendationRefreshResult struct {
+	Pairs int64 `json:"pairs"`
+}
+
+// Label auto rules.
+const (
+	LabelRuleNew        = "new"
+	LabelRuleBestseller = "bestseller"
+)
+
+const (
+	// NewProductWindow is how recent a product must be for the "new" rule.
+	NewProductWindow = 30 * 24 * time.Hour
+	// BestsellerWindow is the sales period the "bestseller" rule ranks on.
+	BestsellerWindow = 90 * 24 * time.Hour
+	// BestsellerShare is the top share of selling products that qualify.
+	BestsellerShare = 0.10
+)
+
+type ProductLabel struct {
+	ID        int64     `json:"id"`
+	Key       string    `json:"key"`
+	Name      string    `json:"name"`
+	Color     *string   `json:"color,omitempty"`
+	Priority  int       `json:"priority"`
+	AutoRule  *string   `json:"auto_rule,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	Products  int       `json:"products"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CardLabel is the badge the app renders on a product card.
+type CardLabel struct {
+	Key   string  `json:"key"`
+	Name  string  `json:"name"`
+	Color *string `json:"color,omitempty"`
+}
+
+type LabelRefreshResult struct {
+	Added   int64 `json:"added"`
+	Removed int64 `json:"removed"`
+}
+
+// Attribute types.
+const (
+	AttrEnum    = "enum"
+	AttrNumber  = "number"
+	AttrText    = "text"
+	AttrBoolean = "boolean"
+)
+
+// AttributeDefinition describes one variant attribute of a category's
+// products. CategoryID is where it is defined, which may be an ancestor of
+// the category it was looked up for.
+type AttributeDefinition struct {
+	ID            int64     `json:"id"`
+	CategoryID    int64     `json:"category_id"`
+	Key           string    `json:"key"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	AllowedValues []string  `json:"allowed_values"`
+	Required      bool      `json:"required"`
+	Filterable    bool      `json:"filterable"`
+	SortOrder     int       `json:"sort_order"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// AttributeFilter maps an attribute key to the values it may take. A
+// product matches when one of its active variants matches every key.
+type AttributeFilter map[string][]string
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facet is a filterable attribute with how many products each value would
+// leave, given the other filters in force.
+type Facet struct {
+	Key    string       `json:"key"`
+	Name   string       `json:"name"`
+	Type   string       `json:"type"`
+	Values []FacetValue `json:"values"`
 }
 
 type ProductOffer struct {
@@ -135,3 +297,94 @@ type AdminProductCard struct {
 	VariantsCount int `json:"variants_count"`
 	ImagesCount   int `json:"images_count"`
 }
+
+// ProductImport is one product of a bulk CSV import, built from the rows
+// that share its slug. Products are created as drafts.
+type ProductImport struct {
+	Line         int
+	Name         string
+	Slug         string
+	Description  *string
+	CategorySlug *string
+	BrandSlug    *string
+	Variants     []VariantImport
+	ImageURLs    []string
+}
+
+type VariantImport struct {
+	Line           int
+	SKU            string
+	Barcode        *string
+	PriceCents     int64
+	CostPriceCents *int64
+	Attributes     map[string]any
+}
+
+type ProductImportIssue struct {
+	Line  int    `json:"line"`
+	Slug  string `json:"slug,omitempty"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ProductImportResult counts what a bulk import created. Issues lists the
+// rows that were skipped and why.
+type ProductImportResult struct {
+	Products int                  `json:"products"`
+	Variants int                  `json:"variants"`
+	Images   int                  `json:"images"`
+	Issues   []ProductImportIssue `json:"issues"`
+}
+
+// CatalogRow is one variant of the catalog export and the merchant feed,
+// with its product's details. Products without variants come through once
+// with the variant fields nil.
+type CatalogRow struct {
+	ProductID      int64          `json:"product_id"`
+	Name           string         `json:"name"`
+	Slug           string         `json:"slug"`
+	Description    *string        `json:"description,omitempty"`
+	CategorySlug   *string        `json:"category,omitempty"`
+	CategoryName   *string        `json:"-"`
+	BrandSlug      *string        `json:"brand,omitempty"`
+	BrandName      *string        `json:"-"`
+	ProductActive  bool           `json:"product_is_active"`
+	VariantID      *int64         `json:"variant_id,omitempty"`
+	SKU            *string        `json:"sku,omitempty"`
+	Barcode        *string        `json:"barcode,omitempty"`
+	PriceCents     *int64         `json:"price_cents,omitempty"`
+	CostPriceCents *int64         `json:"cost_price_cents,omitempty"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+	VariantActive  *bool          `json:"variant_is_active,omitempty"`
+	// ImageURLs lists the variant's own images first, then the product's,
+	// primary first.
+	ImageURLs []string `json:"image_urls"`
+}
+
+// WishlistItem is a saved product with what it costs now. PriceCents is the
+// pinned variant's price, or the cheapest active variant's; it is nil when
+// nothing is on sale. Available is false once the product is unpublished,
+// trashed or out of variants.
+type WishlistItem struct {
+	ProductID       int64     `json:"product_id"`
+	Name            string    `json:"name"`
+	Slug            string    `json:"slug"`
+	PrimaryImageURL *string   `json:"primary_image_url,omitempty"`
+	VariantID       *int64    `json:"variant_id,omitempty"`
+	PriceCents      *int64    `json:"price_cents,omitempty"`
+	SavedPriceCents *int64    `json:"saved_price_cents,omitempty"`
+	Available       bool      `json:"available"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// WishlistPriceDrop is a saved product that got cheaper than its shopper
+// last knew.
+type WishlistPriceDrop struct {
+	UserID        int64  `json:"user_id"`
+	ProductID     int64  `json:"product_id"`
+	Name          string `json:"name"`
+	Slug          string `json:"slug"`
+	VariantID     *int64 `json:"variant_id,omitempty"`
+	OldPriceCents int64  `json:"old_price_cents"`
+	NewPriceCents int64  `json:"new_price_cents"`
+}
@@ -0,0 +1,98 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ------------------------------------
+// Variant SKU/barcode import and export
+// ------------------------------------
+
+// variantUniqueViolation maps a unique-index violation on product_variants
+// to the matching domain error, or returns nil.
+func variantUniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return nil
+	}
+	switch pgErr.ConstraintName {
+	case "ux_product_variants_sku":
+		return ErrDuplicateSKU
+	case "ux_product_variants_barcode":
+		return ErrDuplicateBarcode
+	}
+	return nil
+}
+
+func (r *Repository) ListVariantsForExport(ctx context.Context) ([]VariantExportRow, error) {
+	const q = `
+		SELECT v.id, v.product_id, p.name, v.sku, v.barcode, v.price_cents, v.cost_price_cents, v.is_active
+		FROM product_variants v
+		JOIN products p ON p.id = v.product_id
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.name, v.id;
+	`
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list variants for export: %w", err)
+	}
+	defer rows.Close()
+
+	out := []VariantExportRow{}
+	for rows.Next() {
+		var v VariantExportRow
+		if err := rows.Scan(&v.VariantID, &v.ProductID, &v.ProductName, &v.SKU, &v.Barcode,
+			&v.PriceCents, &v.CostPriceCents, &v.IsActive); err != nil {
+			return nil, fmt.Errorf("scan variant: %w", err)
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) ImportVariants(ctx context.Context, rows []VariantImportRow) ([]VariantImportIssue, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var issues []VariantImportIssue
+	for _, row := range rows {
+		tag, err := tx.Exec(ctx, `
+			UPDATE product_variants
+			SET barcode = CASE WHEN $2 THEN NULL ELSE COALESCE($3, barcode) END,
+			    price_cents = COALESCE($4, price_cents),
+			    cost_price_cents = COALESCE($5, cost_price_cents),
+			    is_active = COALESCE($6, is_active),
+			    updated_at = now()
+			WHERE LOWER(sku) = LOWER($1);
+		`, row.SKU, row.ClearBarcode, row.Barcode, row.PriceCents, row.CostPriceCents, row.IsActive)
+		if err != nil {
+			if dup := variantUniqueViolation(err); dup != nil {
+				// The transaction is aborted; report this row and stop.
+				issues = append(issues, VariantImportIssue{Line: row.Line, SKU: row.SKU, Error: dup.Error()})
+				return issues, nil
+			}
+			return nil, fmt.Errorf("import variant %q: %w", row.SKU, err)
+		}
+		if tag.RowsAffected() == 0 {
+			issues = append(issues, VariantImportIssue{Line: row.Line, SKU: row.SKU, Error: "unknown sku"})
+		}
+	}
+	if len(issues) > 0 {
+		return issues, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return nil, nil
+}
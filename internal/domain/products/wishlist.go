@@ -0,0 +1,191 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// wishlistPrice is what a saved product costs now: the pinned variant's
+// price, or the cheapest active variant's. Used as a correlated subquery
+// with the wishlist row aliased as w.
+const wishlistPrice = `
+	COALESCE(
+		(SELECT v.price_cents FROM product_variants v WHERE v.id = w.variant_id AND v.is_active),
+		(SELECT MIN(v.price_cents) FROM product_variants v WHERE v.product_id = w.product_id AND v.is_active)
+	)`
+
+func (r *Repository) AddToWishlist(ctx context.Context, userID, productID int64, variantID *int64) error {
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		var ok bool
+		err := tx.QueryRow(ctx, `
+			SELECT TRUE FROM products
+			WHERE id = $1 AND is_active AND deleted_at IS NULL
+		`, productID).Scan(&ok)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrProductNotFound
+			}
+			return fmt.Errorf("check product: %w", err)
+		}
+		if variantID != nil {
+			err := tx.QueryRow(ctx, `
+				SELECT TRUE FROM product_variants
+				WHERE id = $1 AND product_id = $2 AND is_active
+			`, *variantID, productID).Scan(&ok)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return ErrVariantNotFound
+				}
+				return fmt.Errorf("check variant: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO product_wishlist (user_id, product_id, variant_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, product_id) DO UPDATE SET variant_id = EXCLUDED.variant_id
+		`, userID, productID, variantID)
+		if err != nil {
+			return fmt.Errorf("add to wishlist: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE product_wishlist w
+			SET seen_price_cents = `+wishlistPrice+`
+			WHERE w.user_id = $1 AND w.product_id = $2
+		`, userID, productID)
+		if err != nil {
+			return fmt.Errorf("record wishlist price: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) RemoveFromWishlist(ctx context.Context, userID, productID int64) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM product_wishlist WHERE user_id = $1 AND product_id = $2
+	`, userID, productID)
+	if err != nil {
+		return fmt.Errorf("remove from wishlist: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListWishlist(ctx context.Context, userID int64, limit, offset int) ([]WishlistItem, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM product_wishlist WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count wishlist: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.name, COALESCE(p.slug, ''),
+		       (SELECT i.url FROM product_images i
+		        WHERE i.product_id = p.id
+		        ORDER BY i.is_primary DESC, i.sort_order ASC, i.id ASC
+		        LIMIT 1),
+		       w.variant_id, cur.price, w.seen_price_cents,
+		       p.is_active AND p.deleted_at IS NULL AND cur.price IS NOT NULL,
+		       w.created_at
+		FROM product_wishlist w
+		JOIN products p ON p.id = w.product_id
+		CROSS JOIN LATERAL (SELECT `+wishlistPrice+` AS price) cur
+		WHERE w.user_id = $1
+		ORDER BY w.created_at DESC, p.id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list wishlist: %w", err)
+	}
+	defer rows.Close()
+
+	items := []WishlistItem{}
+	for rows.Next() {
+		var it WishlistItem
+		if err := rows.Scan(&it.ProductID, &it.Name, &it.Slug, &it.PrimaryImageURL,
+			&it.VariantID, &it.PriceCents, &it.SavedPriceCents, &it.Available, &it.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan wishlist item: %w", err)
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return items, total, nil
+}
+
+func (r *Repository) WishlistedProductIDs(ctx context.Context, userID int64, productIDs []int64) (map[int64]struct{}, error) {
+	ids := make(map[int64]struct{}, len(productIDs))
+	if len(productIDs) == 0 {
+		return ids, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT product_id FROM product_wishlist
+		WHERE user_id = $1 AND product_id = ANY($2)
+	`, userID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("wishlisted products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan wishlisted product: %w", err)
+		}
+		ids[id] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return ids, nil
+}
+
+// ClaimWishlistPriceDrops moves each saved price to the current one in the
+// same statement that reports the drops, so a drop is only ever handed out
+// once. Rises are recorded too, so coming back down counts as a drop.
+func (r *Repository) ClaimWishlistPriceDrops(ctx context.Context, productID int64) ([]WishlistPriceDrop, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH cur AS (
+			SELECT w.user_id, w.product_id, w.seen_price_cents AS old_price, `+wishlistPrice+` AS price
+			FROM product_wishlist w
+			JOIN products p ON p.id = w.product_id AND p.is_active AND p.deleted_at IS NULL
+			WHERE ($1 = 0 OR w.product_id = $1)
+			FOR UPDATE OF w
+		),
+		moved AS (
+			UPDATE product_wishlist w
+			SET seen_price_cents = cur.price
+			FROM cur
+			WHERE w.user_id = cur.user_id
+			  AND w.product_id = cur.product_id
+			  AND cur.price IS NOT NULL
+			  AND cur.price IS DISTINCT FROM cur.old_price
+			RETURNING w.user_id, w.product_id, w.variant_id, cur.old_price, cur.price
+		)
+		SELECT m.user_id, p.id, p.name, COALESCE(p.slug, ''), m.variant_id, m.old_price, m.price
+		FROM moved m
+		JOIN products p ON p.id = m.product_id
+		WHERE m.old_price IS NOT NULL AND m.price < m.old_price
+		ORDER BY p.id, m.user_id
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("claim wishlist price drops: %w", err)
+	}
+	defer rows.Close()
+
+	drops := []WishlistPriceDrop{}
+	for rows.Next() {
+		var d WishlistPriceDrop
+		if err := rows.Scan(&d.UserID, &d.ProductID, &d.Name, &d.Slug, &d.VariantID, &d.OldPriceCents, &d.NewPriceCents); err != nil {
+			return nil, fmt.Errorf("scan wishlist price drop: %w", err)
+		}
+		drops = append(drops, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return drops, nil
+}
@@ -0,0 +1,283 @@
+package refunds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetPolicy(ctx context.Context, venueID int64) (Policy, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	p := DefaultPolicy(venueID)
+	err := r.db.QueryRow(ctx, `
+		SELECT full_refund_hours, late_refund_percent, updated_at
+		FROM venue_refund_policies
+		WHERE venue_id = $1
+	`, venueID).Scan(&p.FullRefundHours, &p.LateRefundPercent, &p.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return p, fmt.Errorf("get refund policy: %w", err)
+	}
+	return p, nil
+}
+
+func (r *Repository) SetPolicy(ctx context.Context, p *Policy) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO venue_refund_policies (venue_id, full_refund_hours, late_refund_percent)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (venue_id) DO UPDATE
+		SET full_refund_hours = EXCLUDED.full_refund_hours,
+		    late_refund_percent = EXCLUDED.late_refund_percent,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, p.VenueID, p.FullRefundHours, p.LateRefundPercent).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("set refund policy: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) PaidPayments(ctx context.Context, bookingID int64) ([]Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT sh.id, sh.user_id, sh.amount, sh.provider, COALESCE(sh.provider_ref, '')
+		FROM payment_split_shares sh
+		JOIN payment_splits s ON s.id = sh.split_id
+		WHERE s.booking_id = $1
+		  AND sh.status = 'paid'
+		  AND sh.provider IS NOT NULL
+		UNION ALL
+		SELECT 0, d.user_id, d.amount, d.provider, COALESCE(d.provider_ref, '')
+		FROM booking_deposits d
+		WHERE d.booking_id = $1
+		  AND d.status = 'paid'
+		  AND d.provider IS NOT NULL
+		ORDER BY 1
+	`, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("list booking payments: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Payment{}
+	for rows.Next() {
+		var p Payment
+		if err := rows.Scan(&p.ShareID, &p.UserID, &p.Amount, &p.Provider, &p.ProviderRef); err != nil {
+			return nil, fmt.Errorf("scan booking payment: %w", err)
+		}
+		list = append(list, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Create(ctx context.Context, rf *Refund) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO refunds (booking_id, venue_id, requested_by, paid_amount, refund_percent, refund_amount, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at, updated_at
+	`, rf.BookingID, rf.VenueID, rf.RequestedBy, rf.PaidAmount, rf.RefundPercent, rf.RefundAmount, rf.Reason).
+		Scan(&rf.ID, &rf.Status, &rf.CreatedAt, &rf.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAlreadyRequested
+		}
+		return fmt.Errorf("create refund: %w", err)
+	}
+	return nil
+}
+
+const refundSelect = `
+	SELECT id, booking_id, venue_id, requested_by, paid_amount, refund_percent, refund_amount, reason,
+	       status, decided_by, decided_at, decision_note, created_at, updated_at
+	FROM refunds`
+
+func refundScanArgs(rf *Refund) []any {
+	return []any{
+		&rf.ID, &rf.BookingID, &rf.VenueID, &rf.RequestedBy, &rf.PaidAmount, &rf.RefundPercent, &rf.RefundAmount, &rf.Reason,
+		&rf.Status, &rf.DecidedBy, &rf.DecidedAt, &rf.DecisionNote, &rf.CreatedAt, &rf.UpdatedAt,
+	}
+}
+
+func (r *Repository) get(ctx context.Context, where string, arg int64) (*Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var rf Refund
+	if err := r.db.QueryRow(ctx, refundSelect+` WHERE `+where+` = $1`, arg).Scan(refundScanArgs(&rf)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get refund: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, share_id, user_id, amount, provider, provider_ref, status, error, created_at
+		FROM refund_items
+		WHERE refund_id = $1
+		ORDER BY id
+	`, rf.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list refund items: %w", err)
+	}
+	defer rows.Close()
+
+	rf.Items = []Item{}
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(&it.ID, &it.ShareID, &it.UserID, &it.Amount, &it.Provider, &it.ProviderRef, &it.Status, &it.Error, &it.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan refund item: %w", err)
+		}
+		rf.Items = append(rf.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return &rf, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int64) (*Refund, error) {
+	return r.get(ctx, "id", id)
+}
+
+func (r *Repository) GetByBooking(ctx context.Context, bookingID int64) (*Refund, error) {
+	return r.get(ctx, "booking_id", bookingID)
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Refund, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH filtered AS (` + refundSelect + `
+			WHERE ($1 = '' OR status = $1)
+			  AND ($2::bigint IS NULL OR venue_id = $2)
+		)
+		SELECT filtered.*, COUNT(*) OVER() AS total_count
+		FROM filtered
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, f.Status, f.VenueID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list refunds: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Refund{}
+	var total int
+	for rows.Next() {
+		var rf Refund
+		if err := rows.Scan(append(refundScanArgs(&rf), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan refund: %w", err)
+		}
+		list = append(list, rf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) decide(ctx context.Context, id, decidedBy int64, note *string, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE refunds
+		SET status = $2, decided_by = $3, decision_note = $4, decided_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, status, decidedBy, note)
+	if err != nil {
+		return fmt.Errorf("decide refund: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrNotPending
+	}
+	return nil
+}
+
+func (r *Repository) RaiseToFull(ctx context.Context, bookingID int64, paidAmount int, reason *string) (*Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var rf Refund
+	err := r.db.QueryRow(ctx, `
+		UPDATE refunds
+		SET paid_amount = $2, refund_percent = 100, refund_amount = $2, reason = $3,
+		    status = 'pending', decided_by = NULL, decided_at = NULL, decision_note = NULL, updated_at = NOW()
+		WHERE booking_id = $1 AND status IN ('pending', 'rejected')
+		RETURNING id, booking_id, venue_id, requested_by, paid_amount, refund_percent, refund_amount, reason,
+		          status, decided_by, decided_at, decision_note, created_at, updated_at
+	`, bookingID, paidAmount, reason).Scan(refundScanArgs(&rf)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := r.GetByBooking(ctx, bookingID); err != nil {
+				return nil, err
+			}
+			return nil, ErrNotPending
+		}
+		return nil, fmt.Errorf("raise refund: %w", err)
+	}
+	return &rf, nil
+}
+
+func (r *Repository) Reject(ctx context.Context, id, decidedBy int64, note *string) error {
+	return r.decide(ctx, id, decidedBy, note, StatusRejected)
+}
+
+func (r *Repository) Approve(ctx context.Context, id, decidedBy int64, note *string) error {
+	return r.decide(ctx, id, decidedBy, note, StatusProcessing)
+}
+
+func (r *Repository) Complete(ctx context.Context, id int64, status string, items []Item) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		for _, it := range items {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO refund_items (refund_id, share_id, user_id, amount, provider, provider_ref, status, error)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`, id, it.ShareID, it.UserID, it.Amount, it.Provider, it.ProviderRef, it.Status, it.Error)
+			if err != nil {
+				return fmt.Errorf("insert refund item: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE refunds SET status = $2, updated_at = NOW() WHERE id = $1
+		`, id, status); err != nil {
+			return fmt.Errorf("complete refund: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,124 @@
+package refunds
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusRejected   = "rejected"
+	StatusRefunded   = "refunded"
+	StatusManual     = "manual"
+	StatusFailed     = "failed"
+
+	ItemRefunded = "refunded"
+	ItemManual   = "manual"
+	ItemFailed   = "failed"
+)
+
+var (
+	ErrNotFound         = errors.New("refund not found")
+	ErrAlreadyRequested = errors.New("a refund was already requested for this booking")
+	ErrNotPending       = errors.New("refund has already been decided")
+)
+
+// Policy decides how much of an online payment comes back when a booking is
+// canceled. Canceling at least FullRefundHours before the start returns
+// everything; later cancellations return LateRefundPercent.
+type Policy struct {
+	VenueID           int64     `json:"venue_id"`
+	FullRefundHours   int       `json:"full_refund_hours"`
+	LateRefundPercent int       `json:"late_refund_percent"`
+	UpdatedAt         time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultPolicy applies to venues that never set their own.
+func DefaultPolicy(venueID int64) Policy {
+	return Policy{VenueID: venueID, FullRefundHours: 24, LateRefundPercent: 50}
+}
+
+// Percent returns the share of the payment to refund for a booking starting
+// at start and canceled at canceledAt.
+func (p Policy) Percent(canceledAt, start time.Time) int {
+	if start.Sub(canceledAt) >= time.Duration(p.FullRefundHours)*time.Hour {
+		return 100
+	}
+	return p.LateRefundPercent
+}
+
+// Payment is an online payment made towards a booking: a split share, or
+// a guest's deposit (ShareID 0).
+type Payment struct {
+	ShareID     int64
+	UserID      int64
+	Amount      int
+	Provider    string
+	ProviderRef string
+}
+
+type Item struct {
+	ID          int64     `json:"id"`
+	ShareID     *int64    `json:"share_id,omitempty"`
+	UserID      int64     `json:"user_id"`
+	Amount      int       `json:"amount"`
+	Provider    string    `json:"provider"`
+	ProviderRef *string   `json:"provider_ref,omitempty"`
+	Status      string    `json:"status"`
+	Error       *string   `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Refund struct {
+	ID            int64      `json:"id"`
+	BookingID     int64      `json:"booking_id"`
+	VenueID       int64      `json:"venue_id"`
+	RequestedBy   int64      `json:"requested_by"`
+	PaidAmount    int        `json:"paid_amount"`
+	RefundPercent int        `json:"refund_percent"`
+	RefundAmount  int        `json:"refund_amount"`
+	Reason        *string    `json:"reason,omitempty"`
+	Status        string     `json:"status"`
+	DecidedBy     *int64     `json:"decided_by,omitempty"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	DecisionNote  *string    `json:"decision_note,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Items         []Item     `json:"items,omitempty"`
+}
+
+type Filter struct {
+	Status  string
+	VenueID *int64
+}
+
+type Store interface {
+	GetPolicy(ctx context.Context, venueID int64) (Policy, error)
+	SetPolicy(ctx context.Context, p *Policy) error
+
+	// PaidPayments lists the online payments made towards the booking.
+	PaidPayments(ctx context.Context, bookingID int64) ([]Payment, error)
+
+	Create(ctx context.Context, rf *Refund) error
+	GetByID(ctx context.Context, id int64) (*Refund, error)
+	GetByBooking(ctx context.Context, bookingID int64) (*Refund, error)
+	List(ctx context.Context, f Filter, limit, offset int) ([]Refund, int, error)
+
+	// RaiseToFull turns the booking's pending or rejected refund into a
+	// pending refund of all of paidAmount, for when the venue cancels after
+	// the player already asked for a policy-sized one. A refund already
+	// being paid out is left alone and ErrNotPending is returned.
+	RaiseToFull(ctx context.Context, bookingID int64, paidAmount int, reason *string) (*Refund, error)
+
+	// Reject declines a pending refund.
+	Reject(ctx context.Context, id, decidedBy int64, note *string) error
+	// Approve moves a pending refund to processing so only one caller pays
+	// it out.
+	Approve(ctx context.Context, id, decidedBy int64, note *string) error
+	// Complete records the outcome of each payout and the final status.
+	Complete(ctx context.Context, id int64, status string, items []Item) error
+}
@@ -0,0 +1,152 @@
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) ClaimDue(ctx context.Context, lead, floor time.Duration, limit int) ([]Due, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Manual bookings are made by the owner on a walk-in's behalf, so the
+	// booking user being the venue owner means there is nobody to remind.
+	q := `
+		WITH candidates AS (
+			SELECT 'booking' AS subject_type, b.id AS subject_id, b.user_id,
+			       b.start_time, v.name AS venue_name, NULL::text AS sport_type,
+			       NULL::text AS safety_info, NULL::text AS emergency_contact_name,
+			       NULL::text AS emergency_contact_phone
+			FROM bookings b
+			JOIN venues v ON v.id = b.venue_id
+			WHERE b.status = 'confirmed'
+			  AND b.user_id <> v.owner_id
+			  AND b.start_time >  NOW() + make_interval(mins => $2)
+			  AND b.start_time <= NOW() + make_interval(mins => $1)
+
+			UNION ALL
+
+			SELECT 'game', g.id, gp.user_id,
+			       g.start_time, v.name, g.sport_type::text,
+			       g.safety_info, g.emergency_contact_name, g.emergency_contact_phone
+			FROM games g
+			JOIN game_players gp ON gp.game_id = g.id
+			JOIN venues v ON v.id = g.venue_id
+			WHERE g.status = 'active'
+			  AND g.start_time >  NOW() + make_interval(mins => $2)
+			  AND g.start_time <= NOW() + make_interval(mins => $1)
+		),
+		claimed AS (
+			INSERT INTO reminder_log (subject_type, subject_id, user_id, lead_minutes)
+			SELECT subject_type, subject_id, user_id, $1
+			FROM candidates c
+			WHERE NOT EXISTS (
+				SELECT 1 FROM reminder_log rl
+				WHERE rl.subject_type = c.subject_type
+				  AND rl.subject_id = c.subject_id
+				  AND rl.user_id = c.user_id
+				  AND rl.lead_minutes = $1
+			)
+			ORDER BY start_time
+			LIMIT $3
+			ON CONFLICT DO NOTHING
+			RETURNING subject_type, subject_id, user_id
+		)
+		SELECT c.subject_type, c.subject_id, c.user_id, c.start_time, c.venue_name, c.sport_type,
+		       c.safety_info, c.emergency_contact_name, c.emergency_contact_phone
+		FROM claimed cl
+		JOIN candidates c
+		  ON c.subject_type = cl.subject_type
+		 AND c.subject_id = cl.subject_id
+		 AND c.user_id = cl.user_id
+		ORDER BY c.start_time, c.subject_id
+	`
+	rows, err := r.db.Query(ctx, q, int(lead/time.Minute), int(floor/time.Minute), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim due reminders: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Due{}
+	for rows.Next() {
+		var d Due
+		if err := rows.Scan(&d.SubjectType, &d.SubjectID, &d.UserID, &d.StartTime, &d.VenueName, &d.SportType,
+			&d.SafetyInfo, &d.EmergencyContactName, &d.EmergencyContactPhone); err != nil {
+			return nil, fmt.Errorf("scan reminder: %w", err)
+		}
+		list = append(list, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+// ClaimReviewInvites only looks at bookings that ended within the last day
+// past the delay, so a missed run or the first deploy doesn't invite players
+// for old bookings.
+func (r *Repository) ClaimReviewInvites(ctx context.Context, limit int) ([]ReviewInvite, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	q := `
+		WITH candidates AS (
+			SELECT b.id AS booking_id, b.user_id, b.venue_id, v.name AS venue_name, b.end_time
+			FROM bookings b
+			JOIN venues v ON v.id = b.venue_id
+			WHERE b.status = 'done'
+			  AND b.user_id <> v.owner_id
+			  AND b.end_time <= NOW() - make_interval(mins => $1)
+			  AND b.end_time >  NOW() - make_interval(mins => $1) - INTERVAL '1 day'
+			  AND NOT EXISTS (SELECT 1 FROM review_invitations ri WHERE ri.booking_id = b.id)
+			  AND NOT EXISTS (
+				SELECT 1 FROM reviews rv
+				WHERE rv.user_id = b.user_id
+				  AND rv.venue_id = b.venue_id
+				  AND rv.created_at > NOW() - make_interval(days => $2)
+			  )
+			ORDER BY b.end_time
+			LIMIT $3
+		),
+		claimed AS (
+			INSERT INTO review_invitations (booking_id, user_id, venue_id)
+			SELECT booking_id, user_id, venue_id FROM candidates
+			ON CONFLICT DO NOTHING
+			RETURNING booking_id
+		)
+		SELECT DISTINCT ON (c.user_id, c.venue_id)
+		       c.booking_id, c.user_id, c.venue_id, c.venue_name, c.end_time
+		FROM claimed cl
+		JOIN candidates c ON c.booking_id = cl.booking_id
+		ORDER BY c.user_id, c.venue_id, c.end_time DESC
+	`
+	rows, err := r.db.Query(ctx, q, int(ReviewInviteDelay/time.Minute), int(ReviewCooldown/(24*time.Hour)), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim review invites: %w", err)
+	}
+	defer rows.Close()
+
+	list := []ReviewInvite{}
+	for rows.Next() {
+		var inv ReviewInvite
+		if err := rows.Scan(&inv.BookingID, &inv.UserID, &inv.VenueID, &inv.VenueName, &inv.EndTime); err != nil {
+			return nil, fmt.Errorf("scan review invite: %w", err)
+		}
+		list = append(list, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
@@ -0,0 +1,63 @@
+package reminders
+
+import (
+	"context"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// Leads are how long before the start time reminders go out, largest first.
+var Leads = []time.Duration{2 * time.Hour, 30 * time.Minute}
+
+// Subjects
+const (
+	SubjectBooking = "booking"
+	SubjectGame    = "game"
+)
+
+// Due is one reminder owed to one user.
+type Due struct {
+	SubjectType string
+	SubjectID   int64
+	UserID      int64
+	StartTime   time.Time
+	VenueName   string
+	// SportType, SafetyInfo and the emergency contact are only set for games.
+	SportType             *string
+	SafetyInfo            *string
+	EmergencyContactName  *string
+	EmergencyContactPhone *string
+}
+
+// ReviewInviteDelay is how long after a checked-in booking ends the player
+// is asked to review the venue.
+const ReviewInviteDelay = 2 * time.Hour
+
+// ReviewCooldown suppresses the invitation when the player already reviewed
+// the venue this recently.
+const ReviewCooldown = 90 * 24 * time.Hour
+
+// ReviewInvite asks one player to review the venue of a finished booking.
+type ReviewInvite struct {
+	BookingID int64
+	UserID    int64
+	VenueID   int64
+	VenueName string
+	EndTime   time.Time
+}
+
+type Store interface {
+	// ClaimDue returns reminders for confirmed bookings and active games that
+	// start in (now+floor, now+lead], and records them as sent in the same
+	// statement so each (subject, user, lead) is only ever claimed once.
+	// Anything starting within floor is left to the next, shorter lead.
+	ClaimDue(ctx context.Context, lead, floor time.Duration, limit int) ([]Due, error)
+
+	// ClaimReviewInvites returns bookings the venue closed as played that
+	// ended at least ReviewInviteDelay ago, skipping players who reviewed the
+	// venue within ReviewCooldown, and records them so each booking is only
+	// claimed once. A player with several such bookings at the same venue
+	// gets one invitation.
+	ClaimReviewInvites(ctx context.Context, limit int) ([]ReviewInvite, error)
+}
@@ -0,0 +1,269 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Block(ctx context.Context, blockerID, blockedID int64) error {
+	if blockerID == blockedID {
+		return ErrSelfBlock
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO blocked_users (blocker_id, blocked_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, blockerID, blockedID); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("block user: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM friendships
+			WHERE LEAST(requester_id, addressee_id) = LEAST($1::bigint, $2::bigint)
+			  AND GREATEST(requester_id, addressee_id) = GREATEST($1::bigint, $2::bigint)
+		`, blockerID, blockedID); err != nil {
+			return fmt.Errorf("remove friendship: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM followers
+			WHERE (user_id = $1 AND follower_id = $2) OR (user_id = $2 AND follower_id = $1)
+		`, blockerID, blockedID); err != nil {
+			return fmt.Errorf("remove followers: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE game_join_requests gr
+			SET status = 'rejected', updated_at = NOW()
+			FROM games g
+			WHERE g.id = gr.game_id
+			  AND gr.status = 'pending'
+			  AND ((g.admin_id = $1 AND gr.user_id = $2) OR (g.admin_id = $2 AND gr.user_id = $1))
+		`, blockerID, blockedID); err != nil {
+			return fmt.Errorf("reject join requests: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) Unblock(ctx context.Context, blockerID, blockedID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM blocked_users WHERE blocker_id = $1 AND blocked_id = $2
+	`, blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("unblock user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotBlocked
+	}
+	return nil
+}
+
+func (r *Repository) ListBlocked(ctx context.Context, userID int64) ([]BlockedUser, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.first_name, u.last_name, u.profile_picture_url, b.created_at
+		FROM blocked_users b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list blocked users: %w", err)
+	}
+	defer rows.Close()
+
+	list := []BlockedUser{}
+	for rows.Next() {
+		var b BlockedUser
+		if err := rows.Scan(&b.UserID, &b.FirstName, &b.LastName, &b.ProfilePictureURL, &b.BlockedAt); err != nil {
+			return nil, fmt.Errorf("scan blocked user: %w", err)
+		}
+		list = append(list, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) IsBlocked(ctx context.Context, a, b int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var blocked bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM blocked_users
+			WHERE (blocker_id = $1 AND blocked_id = $2)
+			   OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`, a, b).Scan(&blocked)
+	if err != nil {
+		return false, fmt.Errorf("check block: %w", err)
+	}
+	return blocked, nil
+}
+
+func (r *Repository) BlockedAmong(ctx context.Context, userID int64, others []int64) (map[int64]bool, error) {
+	blocked := make(map[int64]bool)
+	if len(others) == 0 {
+		return blocked, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT blocked_id FROM blocked_users WHERE blocker_id = $1 AND blocked_id = ANY($2)
+		UNION
+		SELECT blocker_id FROM blocked_users WHERE blocked_id = $1 AND blocker_id = ANY($2)
+	`, userID, others)
+	if err != nil {
+		return nil, fmt.Errorf("list blocks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan block: %w", err)
+		}
+		blocked[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return blocked, nil
+}
+
+func (r *Repository) Report(ctx context.Context, rep *Report) error {
+	if rep.ReporterID != nil && *rep.ReporterID == rep.ReportedID {
+		return ErrSelfReport
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO user_reports (reporter_id, reported_id, game_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at
+	`, rep.ReporterID, rep.ReportedID, rep.GameID, rep.Reason, rep.Details).Scan(&rep.ID, &rep.Status, &rep.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("create report: %w", err)
+	}
+	return nil
+}
+
+const reportSelect = `
+	SELECT rp.id, rp.reporter_id, rp.reported_id, rp.game_id, rp.reason, rp.details, rp.status,
+	       rp.resolution_note, rp.resolved_by, rp.resolved_at, rp.created_at,
+	       COALESCE(TRIM(reporter.first_name || ' ' || reporter.last_name), '') AS reporter_name,
+	       TRIM(reported.first_name || ' ' || reported.last_name) AS reported_name,
+	       (SELECT COUNT(*) FROM user_reports o WHERE o.reported_id = rp.reported_id AND o.status = 'open')::int AS open_reports
+	FROM user_reports rp
+	LEFT JOIN users reporter ON reporter.id = rp.reporter_id
+	JOIN users reported ON reported.id = rp.reported_id`
+
+func reportScanArgs(rep *Report) []any {
+	return []any{
+		&rep.ID, &rep.ReporterID, &rep.ReportedID, &rep.GameID, &rep.Reason, &rep.Details, &rep.Status,
+		&rep.ResolutionNote, &rep.ResolvedBy, &rep.ResolvedAt, &rep.CreatedAt,
+		&rep.ReporterName, &rep.ReportedName, &rep.OpenReports,
+	}
+}
+
+func (r *Repository) ListReports(ctx context.Context, f ReportFilter, limit, offset int) ([]Report, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH filtered AS (` + reportSelect + `
+			WHERE ($1 = '' OR rp.status = $1)
+			  AND ($2::bigint IS NULL OR rp.reported_id = $2)
+		)
+		SELECT filtered.*, COUNT(*) OVER() AS total_count
+		FROM filtered
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, f.Status, f.ReportedID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list reports: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Report{}
+	var total int
+	for rows.Next() {
+		var rep Report
+		if err := rows.Scan(append(reportScanArgs(&rep), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan report: %w", err)
+		}
+		list = append(list, rep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) ResolveReport(ctx context.Context, id int64, status string, note *string, resolvedBy int64) (*Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE user_reports
+		SET status = $2, resolution_note = $3, resolved_by = $4, resolved_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`, id, status, note, resolvedBy)
+	if err != nil {
+		return nil, fmt.Errorf("resolve report: %w", err)
+	}
+
+	var rep Report
+	err = r.db.QueryRow(ctx, reportSelect+` WHERE rp.id = $1`, id).Scan(reportScanArgs(&rep)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("get report: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrAlreadyResolved
+	}
+	return &rep, nil
+}
@@ -0,0 +1,92 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+var (
+	ErrSelfBlock       = errors.New("cannot block yourself")
+	ErrSelfReport      = errors.New("cannot report yourself")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrNotBlocked      = errors.New("user is not blocked")
+	ErrReportNotFound  = errors.New("report not found")
+	ErrAlreadyResolved = errors.New("report is already resolved")
+)
+
+// Why a player was reported.
+const (
+	ReasonHarassment      = "harassment"
+	ReasonAbusiveLanguage = "abusive_language"
+	ReasonCheating        = "cheating"
+	ReasonNoShow          = "no_show"
+	ReasonFakeProfile     = "fake_profile"
+	ReasonOther           = "other"
+)
+
+// Report statuses. A report is open until an admin actions or dismisses it.
+const (
+	StatusOpen      = "open"
+	StatusActioned  = "actioned"
+	StatusDismissed = "dismissed"
+)
+
+// BlockedUser is someone the caller has blocked.
+type BlockedUser struct {
+	UserID            int64     `json:"user_id"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	ProfilePictureURL *string   `json:"profile_picture_url,omitempty"`
+	BlockedAt         time.Time `json:"blocked_at"`
+}
+
+type Report struct {
+	ID             int64      `json:"id"`
+	ReporterID     *int64     `json:"reporter_id,omitempty"`
+	ReportedID     int64      `json:"reported_id"`
+	GameID         *int64     `json:"game_id,omitempty"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status"`
+	ResolutionNote *string    `json:"resolution_note,omitempty"`
+	ResolvedBy     *int64     `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Filled on the admin list.
+	ReporterName string `json:"reporter_name,omitempty"`
+	ReportedName string `json:"reported_name,omitempty"`
+	// OpenReports counts the open reports against the reported player, this
+	// one included, so repeat offenders stand out during triage.
+	OpenReports int `json:"open_reports,omitempty"`
+}
+
+type ReportFilter struct {
+	Status     string
+	ReportedID *int64
+}
+
+type Store interface {
+	// Block hides the two users from each other. It ends any friendship
+	// between them, removes either one following the other and rejects
+	// their pending requests to join each other's games. Blocking someone
+	// twice is a no-op.
+	Block(ctx context.Context, blockerID, blockedID int64) error
+	Unblock(ctx context.Context, blockerID, blockedID int64) error
+	ListBlocked(ctx context.Context, userID int64) ([]BlockedUser, error)
+	// IsBlocked reports whether either user has blocked the other.
+	IsBlocked(ctx context.Context, a, b int64) (bool, error)
+	// BlockedAmong returns which of others have a block with userID, in
+	// either direction.
+	BlockedAmong(ctx context.Context, userID int64, others []int64) (map[int64]bool, error)
+
+	Report(ctx context.Context, rep *Report) error
+	// ListReports returns reports newest first, with the total ignoring
+	// limit and offset.
+	ListReports(ctx context.Context, f ReportFilter, limit, offset int) ([]Report, int, error)
+	// ResolveReport moves an open report to actioned or dismissed.
+	ResolveReport(ctx context.Context, id int64, status string, note *string, resolvedBy int64) (*Report, error)
+}
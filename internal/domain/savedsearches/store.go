@@ -0,0 +1,205 @@
+package savedsearches
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const searchColumns = `
+	id, user_id, name, sport_type, latitude, longitude, radius_km, min_price, max_price,
+	to_char(start_from, 'HH24:MI:SS'), to_char(start_to, 'HH24:MI:SS'),
+	notify, last_notified_at, created_at, updated_at
+`
+
+func scanSearch(row pgx.Row, s *Search) error {
+	return row.Scan(
+		&s.ID, &s.UserID, &s.Name, &s.SportType, &s.Latitude, &s.Longitude, &s.RadiusKm,
+		&s.MinPrice, &s.MaxPrice, &s.StartFrom, &s.StartTo,
+		&s.Notify, &s.LastNotifiedAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+}
+
+func (r *Repository) Create(ctx context.Context, s *Search) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var count int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM saved_searches WHERE user_id = $1`, s.UserID).Scan(&count); err != nil {
+			return fmt.Errorf("count saved searches: %w", err)
+		}
+		if count >= MaxPerUser {
+			return ErrLimitExceeded
+		}
+
+		row := tx.QueryRow(ctx, `
+			INSERT INTO saved_searches (
+				user_id, name, sport_type, latitude, longitude, radius_km,
+				min_price, max_price, start_from, start_to, notify
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::time, $10::time, $11)
+			RETURNING `+searchColumns,
+			s.UserID, s.Name, s.SportType, s.Latitude, s.Longitude, s.RadiusKm,
+			s.MinPrice, s.MaxPrice, s.StartFrom, s.StartTo, s.Notify,
+		)
+		if err := scanSearch(row, s); err != nil {
+			return fmt.Errorf("create saved search: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]Search, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+searchColumns+`
+		FROM saved_searches
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Search{}
+	for rows.Next() {
+		var s Search
+		if err := scanSearch(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan saved search: %w", err)
+		}
+		list = append(list, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+// Update replaces every criterion of the user's search with those in s.
+func (r *Repository) Update(ctx context.Context, s *Search) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	row := r.db.QueryRow(ctx, `
+		UPDATE saved_searches
+		SET name = $3, sport_type = $4, latitude = $5, longitude = $6, radius_km = $7,
+		    min_price = $8, max_price = $9, start_from = $10::time, start_to = $11::time,
+		    notify = $12, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+searchColumns,
+		s.ID, s.UserID, s.Name, s.SportType, s.Latitude, s.Longitude, s.RadiusKm,
+		s.MinPrice, s.MaxPrice, s.StartFrom, s.StartTo, s.Notify,
+	)
+	if err := scanSearch(row, s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("update saved search: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, userID, searchID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, searchID, userID)
+	if err != nil {
+		return fmt.Errorf("delete saved search: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MatchGame records the matches and returns one per user, so a user with
+// several fitting searches is told once. Only public, active games that
+// have not started are matched, and never for a user with a block either
+// way with the host.
+func (r *Repository) MatchGame(ctx context.Context, gameID int64) ([]Match, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		WITH game AS (
+			SELECT g.id, g.admin_id, g.sport_type, g.price, g.start_time,
+			       (g.start_time AT TIME ZONE 'Asia/Kathmandu')::time AS local_start,
+			       v.name AS venue_name, v.location
+			FROM games g
+			JOIN venues v ON v.id = g.venue_id
+			WHERE g.id = $1
+			  AND g.visibility = 'public'
+			  AND g.status = 'active'
+			  AND g.start_time > NOW()
+		), fits AS (
+			SELECT s.id, s.user_id
+			FROM saved_searches s, game
+			WHERE s.notify
+			  AND s.user_id <> game.admin_id
+			  AND NOT EXISTS (
+			        SELECT 1 FROM blocked_users b
+			        WHERE (b.blocker_id = s.user_id AND b.blocked_id = game.admin_id)
+			           OR (b.blocker_id = game.admin_id AND b.blocked_id = s.user_id)
+			  )
+			  AND (s.sport_type IS NULL OR s.sport_type = game.sport_type)
+			  AND (s.min_price IS NULL OR COALESCE(game.price, 0) >= s.min_price)
+			  AND (s.max_price IS NULL OR COALESCE(game.price, 0) <= s.max_price)
+			  AND (s.radius_km IS NULL OR ST_DWithin(
+			        game.location,
+			        ST_SetSRID(ST_MakePoint(s.longitude, s.latitude), 4326)::geography,
+			        s.radius_km * 1000))
+			  AND (s.start_from IS NULL
+			       OR (s.start_from <= s.start_to AND game.local_start BETWEEN s.start_from AND s.start_to)
+			       OR (s.start_from > s.start_to AND (game.local_start >= s.start_from OR game.local_start <= s.start_to)))
+		), claimed AS (
+			INSERT INTO saved_search_matches (saved_search_id, game_id)
+			SELECT id, $1 FROM fits
+			ON CONFLICT DO NOTHING
+			RETURNING saved_search_id
+		), notified AS (
+			UPDATE saved_searches s
+			SET last_notified_at = NOW()
+			FROM claimed c
+			WHERE s.id = c.saved_search_id
+			RETURNING s.id, s.user_id, s.name
+		)
+		SELECT DISTINCT ON (n.user_id)
+		       n.id, n.name, n.user_id, game.id, game.sport_type, game.venue_name, game.start_time, game.price
+		FROM notified n, game
+		ORDER BY n.user_id, n.id
+	`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("match saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []Match{}
+	for rows.Next() {
+		var m Match
+		if err := rows.Scan(&m.SearchID, &m.SearchName, &m.UserID, &m.GameID, &m.SportType, &m.VenueName, &m.StartTime, &m.Price); err != nil {
+			return nil, fmt.Errorf("scan saved search match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return matches, nil
+}
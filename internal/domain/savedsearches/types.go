@@ -0,0 +1,62 @@
+package savedsearches
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// MaxPerUser caps how many searches one user can save.
+const MaxPerUser = 10
+
+var (
+	ErrNotFound      = errors.New("saved search not found")
+	ErrLimitExceeded = errors.New("saved search limit reached")
+)
+
+// Search is a saved game filter. Nil fields match any game. StartFrom and
+// StartTo are local Nepal time (HH:MM:SS) and the window may wrap past
+// midnight, e.g. 20:00 to 02:00.
+type Search struct {
+	ID             int64      `json:"id"`
+	UserID         int64      `json:"user_id"`
+	Name           string     `json:"name"`
+	SportType      *string    `json:"sport_type,omitempty"`
+	Latitude       *float64   `json:"latitude,omitempty"`
+	Longitude      *float64   `json:"longitude,omitempty"`
+	RadiusKm       *int       `json:"radius_km,omitempty"`
+	MinPrice       *int       `json:"min_price,omitempty"`
+	MaxPrice       *int       `json:"max_price,omitempty"`
+	StartFrom      *string    `json:"start_from,omitempty"`
+	StartTo        *string    `json:"start_to,omitempty"`
+	Notify         bool       `json:"notify"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Match is a new game that fits one of a user's saved searches. A user
+// whose several searches fit the game gets one match.
+type Match struct {
+	SearchID   int64
+	SearchName string
+	UserID     int64
+	GameID     int64
+	SportType  string
+	VenueName  string
+	StartTime  time.Time
+	Price      *int
+}
+
+type Store interface {
+	Create(ctx context.Context, s *Search) error
+	ListByUser(ctx context.Context, userID int64) ([]Search, error)
+	Update(ctx context.Context, s *Search) error
+	Delete(ctx context.Context, userID, searchID int64) error
+	// MatchGame claims the saved searches, other than the host's or those
+	// of users blocked either way with the host, that the game fits and has
+	// not been matched against before.
+	MatchGame(ctx context.Context, gameID int64) ([]Match, error)
+}
@@ -0,0 +1,183 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// Each branch matches on full text where the table has it and on a name
+// substring everywhere, so a half-typed "futs" still finds futsal venues.
+// A branch is skipped outright when its type isn't asked for.
+const searchQuery = `
+	(SELECT 'venue', v.id, v.name, v.address, NULL::text, v.image_urls[1], NULL::timestamptz,
+	        GREATEST(ts_rank_cd(v.fts, plainto_tsquery('english', $1)), similarity(v.name, $1))::float8 AS rank
+	 FROM venues v
+	 WHERE 'venue' = ANY($2)
+	   AND v.status = 'active' AND NOT v.is_demo
+	   AND (v.fts @@ plainto_tsquery('english', $1) OR v.name ILIKE '%' || $1 || '%')
+	 ORDER BY rank DESC, v.id
+	 LIMIT $3)
+	UNION ALL
+	(SELECT 'game', g.id, v.name, g.sport_type || COALESCE(' · ' || g.game_level, ''), NULL, v.image_urls[1], g.start_time,
+	        GREATEST(ts_rank_cd(v.fts, plainto_tsquery('english', $1)), similarity(v.name, $1),
+	                 CASE WHEN g.sport_type ILIKE $1 || '%' THEN 1 ELSE 0 END)::float8 AS rank
+	 FROM games g
+	 JOIN venues v ON v.id = g.venue_id
+	 WHERE 'game' = ANY($2)
+	   AND g.visibility = 'public' AND g.status = 'active' AND g.start_time > NOW()
+	   AND v.status = 'active' AND NOT v.is_demo
+	   AND (v.fts @@ plainto_tsquery('english', $1)
+	        OR v.name ILIKE '%' || $1 || '%'
+	        OR g.sport_type ILIKE $1 || '%')
+	   AND ($4::bigint = 0 OR NOT EXISTS (
+	        SELECT 1 FROM blocked_users b
+	        WHERE (b.blocker_id = $4 AND b.blocked_id = g.admin_id)
+	           OR (b.blocker_id = g.admin_id AND b.blocked_id = $4)
+	   ))
+	 ORDER BY rank DESC, g.start_time, g.id
+	 LIMIT $3)
+	UNION ALL
+	(SELECT 'product', p.id, p.name, b.name, p.slug,
+	        (SELECT i.url FROM product_images i
+	         WHERE i.product_id = p.id
+	         ORDER BY i.is_primary DESC, i.sort_order ASC, i.id ASC
+	         LIMIT 1),
+	        NULL,
+	        GREATEST(ts_rank_cd(p.fts, plainto_tsquery('english', $1)), similarity(p.name, $1))::float8 AS rank
+	 FROM products p
+	 LEFT JOIN brands b ON b.id = p.brand_id
+	 WHERE 'product' = ANY($2)
+	   AND p.is_active AND p.deleted_at IS NULL
+	   AND (p.fts @@ plainto_tsquery('english', $1) OR p.name ILIKE '%' || $1 || '%')
+	 ORDER BY rank DESC, p.id
+	 LIMIT $3)
+	UNION ALL
+	(SELECT 'brand', b.id, b.name, NULL, b.slug, b.logo_url, NULL,
+	        similarity(b.name, $1)::float8 AS rank
+	 FROM brands b
+	 WHERE 'brand' = ANY($2)
+	   AND b.deleted_at IS NULL
+	   AND (b.name ILIKE '%' || $1 || '%' OR b.name % $1)
+	 ORDER BY rank DESC, b.id
+	 LIMIT $3)`
+
+func (r *Repository) Search(ctx context.Context, q string, types []string, limit int, viewerID int64) (*Results, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, searchQuery, q, types, limit, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	defer rows.Close()
+
+	byType := make(map[string][]Hit, len(types))
+	for rows.Next() {
+		var h Hit
+		if err := rows.Scan(&h.Type, &h.ID, &h.Title, &h.Subtitle, &h.Slug, &h.ImageURL, &h.StartsAt, &h.Rank); err != nil {
+			return nil, fmt.Errorf("scan search hit: %w", err)
+		}
+		byType[h.Type] = append(byType[h.Type], h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	res := &Results{Query: q, Groups: []Group{}}
+	for _, t := range Types {
+		if !slices.Contains(types, t) {
+			continue
+		}
+		items := byType[t]
+		if items == nil {
+			items = []Hit{}
+		}
+		res.Groups = append(res.Groups, Group{Type: t, Items: items})
+	}
+	return res, nil
+}
+
+func (r *Repository) VenueDocuments(ctx context.Context, f DocumentFilter) ([]VenueDocument, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT v.id, v.name, v.sport, v.address, v.description
+		FROM venues v
+		WHERE v.status = 'active' AND NOT v.is_demo
+		  AND ($1::bigint[] IS NULL OR v.id = ANY($1))
+		  AND v.id > $2
+		ORDER BY v.id
+		LIMIT $3
+	`, f.IDs, f.AfterID, documentLimit(f))
+	if err != nil {
+		return nil, fmt.Errorf("venue documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []VenueDocument{}
+	for rows.Next() {
+		var d VenueDocument
+		if err := rows.Scan(&d.ID, &d.Name, &d.Sport, &d.Address, &d.Description); err != nil {
+			return nil, fmt.Errorf("scan venue document: %w", err)
+		}
+		docs = append(docs, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return docs, nil
+}
+
+func (r *Repository) ProductDocuments(ctx context.Context, f DocumentFilter) ([]ProductDocument, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.name, p.description, b.name, c.name
+		FROM products p
+		LEFT JOIN brands b ON b.id = p.brand_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE p.is_active AND p.deleted_at IS NULL
+		  AND ($1::bigint[] IS NULL OR p.id = ANY($1))
+		  AND p.id > $2
+		ORDER BY p.id
+		LIMIT $3
+	`, f.IDs, f.AfterID, documentLimit(f))
+	if err != nil {
+		return nil, fmt.Errorf("product documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []ProductDocument{}
+	for rows.Next() {
+		var d ProductDocument
+		if err := rows.Scan(&d.ID, &d.Name, &d.Description, &d.Brand, &d.Category); err != nil {
+			return nil, fmt.Errorf("scan product document: %w", err)
+		}
+		docs = append(docs, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return docs, nil
+}
+
+// documentLimit returns f.Limit, or enough for every ID asked for.
+func documentLimit(f DocumentFilter) int {
+	if f.Limit > 0 {
+		return f.Limit
+	}
+	return len(f.IDs)
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"context"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+const (
+	DefaultLimit = 5
+	MaxLimit     = 20
+)
+
+// Result types, in the order groups are returned.
+const (
+	TypeVenue   = "venue"
+	TypeGame    = "game"
+	TypeProduct = "product"
+	TypeBrand   = "brand"
+)
+
+var Types = []string{TypeVenue, TypeGame, TypeProduct, TypeBrand}
+
+// Hit is one search result. Rank is only comparable within its group:
+// full-text ranks and name similarity are on different scales.
+type Hit struct {
+	Type     string  `json:"type"`
+	ID       int64   `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle *string `json:"subtitle,omitempty"`
+	// Slug is set for products and brands.
+	Slug     *string `json:"slug,omitempty"`
+	ImageURL *string `json:"image_url,omitempty"`
+	// StartsAt is set for games.
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	Rank     float64    `json:"rank"`
+}
+
+type Group struct {
+	Type  string `json:"type"`
+	Items []Hit  `json:"items"`
+}
+
+type Results struct {
+	Query  string  `json:"query"`
+	Groups []Group `json:"groups"`
+}
+
+// VenueDocument is what an external search index holds for an active
+// venue.
+type VenueDocument struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Sport       string  `json:"sport"`
+	Address     string  `json:"address"`
+	Description *string `json:"description,omitempty"`
+}
+
+// ProductDocument is what an external search index holds for a published
+// product.
+type ProductDocument struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	Brand       *string `json:"brand,omitempty"`
+	Category    *string `json:"category,omitempty"`
+}
+
+// DocumentFilter picks documents either by IDs or, for a full reindex, a
+// page of up to Limit ordered by id after AfterID.
+type DocumentFilter struct {
+	IDs     []int64
+	AfterID int64
+	Limit   int
+}
+
+type Store interface {
+	// Search matches q against active venues, upcoming public games,
+	// published products and brands, returning up to limit hits per type.
+	// Only the given types are searched, each getting a group even when
+	// empty. Games whose admin has a block with viewerID are left out; 0
+	// for anonymous callers.
+	Search(ctx context.Context, q string, types []string, limit int, viewerID int64) (*Results, error)
+
+	// VenueDocuments and ProductDocuments leave out anything that should
+	// not be searchable, so an ID asked for but missing is to be removed
+	// from the index.
+	VenueDocuments(ctx context.Context, f DocumentFilter) ([]VenueDocument, error)
+	ProductDocuments(ctx context.Context, f DocumentFilter) ([]ProductDocument, error)
+}
@@ -0,0 +1,251 @@
+package settlements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// CreateBatch only looks back four weeks before the period, so the first
+// run does not bill a venue's whole history. Canceled bookings with an open
+// refund wait for the refund to be decided.
+func (r *Repository) CreateBatch(ctx context.Context, p Period) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	q := `
+		WITH candidates AS (
+			SELECT b.id AS booking_id, b.venue_id, b.status, b.start_time, b.total_price,
+			       COALESCE(pay.amount, 0) AS online,
+			       COALESCE(ref.amount, 0) AS refunded,
+			       COALESCE(b.commission_percent, commission_percent_at(b.venue_id, b.created_at)) AS pct
+			FROM bookings b
+			LEFT JOIN LATERAL (
+				SELECT SUM(sh.amount)::INT AS amount
+				FROM payment_splits s
+				JOIN payment_split_shares sh ON sh.split_id = s.id
+				WHERE s.booking_id = b.id AND sh.status = 'paid'
+			) pay ON TRUE
+			LEFT JOIN LATERAL (
+				SELECT SUM(ri.amount)::INT AS amount
+				FROM refunds rf
+				JOIN refund_items ri ON ri.refund_id = rf.id
+				WHERE rf.booking_id = b.id AND ri.status IN ('refunded', 'manual')
+			) ref ON TRUE
+			WHERE b.end_time < $2
+			  AND b.start_time >= $1::timestamptz - INTERVAL '28 days'
+			  AND NOT EXISTS (SELECT 1 FROM venues v WHERE v.id = b.venue_id AND v.is_demo)
+			  AND (b.status IN ('confirmed', 'done') OR (b.status = 'canceled' AND COALESCE(pay.amount, 0) > 0))
+			  AND NOT EXISTS (SELECT 1 FROM settlement_items si WHERE si.booking_id = b.id)
+			  AND NOT EXISTS (
+				SELECT 1 FROM refunds rf
+				WHERE rf.booking_id = b.id AND rf.status IN ('pending', 'processing')
+			  )
+		),
+		priced AS (
+			SELECT *,
+			       ROUND(GREATEST(
+					CASE WHEN status = 'canceled' THEN online - refunded ELSE total_price END, 0
+			       ) * pct / 100.0)::INT AS commission
+			FROM candidates
+		),
+		batches AS (
+			INSERT INTO settlements (
+				venue_id, period_start, period_end, booking_count, gross_amount,
+				online_amount, refunded_amount, commission_amount, payout_amount
+			)
+			SELECT venue_id, $3::date, $4::date, COUNT(*),
+			       SUM(CASE WHEN status = 'canceled' THEN 0 ELSE total_price END),
+			       SUM(online), SUM(refunded), SUM(commission),
+			       SUM(online - refunded - commission)
+			FROM priced
+			GROUP BY venue_id
+			ON CONFLICT (venue_id, period_start) DO NOTHING
+			RETURNING id, venue_id
+		),
+		items AS (
+			INSERT INTO settlement_items (
+				settlement_id, booking_id, booking_status, start_time, booking_amount,
+				online_amount, refunded_amount, commission_percent, commission_amount, net_amount
+			)
+			SELECT bt.id, p.booking_id, p.status, p.start_time, p.total_price,
+			       p.online, p.refunded, p.pct, p.commission, p.online - p.refunded - p.commission
+			FROM priced p
+			JOIN batches bt ON bt.venue_id = p.venue_id
+		)
+		SELECT COUNT(*) FROM batches
+	`
+	var n int
+	err := r.db.QueryRow(ctx, q,
+		p.Start, p.End, p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"),
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("create settlement batch: %w", err)
+	}
+	return n, nil
+}
+
+const settlementSelect = `
+	SELECT s.id, s.venue_id, v.name, s.period_start::text, s.period_end::text, s.booking_count,
+	       s.gross_amount, s.online_amount, s.refunded_amount, s.commission_amount, s.payout_amount,
+	       s.status, s.payout_reference, s.note, s.paid_at, s.created_at, s.updated_at
+	FROM settlements s
+	JOIN venues v ON v.id = s.venue_id`
+
+func settlementScanArgs(s *Settlement) []any {
+	return []any{
+		&s.ID, &s.VenueID, &s.VenueName, &s.PeriodStart, &s.PeriodEnd, &s.BookingCount,
+		&s.GrossAmount, &s.OnlineAmount, &s.RefundedAmount, &s.CommissionAmount, &s.PayoutAmount,
+		&s.Status, &s.PayoutReference, &s.Note, &s.PaidAt, &s.CreatedAt, &s.UpdatedAt,
+	}
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int64) (*Settlement, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var s Settlement
+	if err := r.db.QueryRow(ctx, settlementSelect+` WHERE s.id = $1`, id).Scan(settlementScanArgs(&s)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get settlement: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, booking_id, booking_status, start_time, booking_amount, online_amount,
+		       refunded_amount, commission_percent, commission_amount, net_amount
+		FROM settlement_items
+		WHERE settlement_id = $1
+		ORDER BY start_time, id
+	`, s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list settlement items: %w", err)
+	}
+	defer rows.Close()
+
+	s.Items = []Item{}
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(
+			&it.ID, &it.BookingID, &it.BookingStatus, &it.StartTime, &it.BookingAmount, &it.OnlineAmount,
+			&it.RefundedAmount, &it.CommissionPercent, &it.CommissionAmount, &it.NetAmount,
+		); err != nil {
+			return nil, fmt.Errorf("scan settlement item: %w", err)
+		}
+		s.Items = append(s.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	days, err := r.db.Query(ctx, `
+		SELECT DISTINCT (b.start_time AT TIME ZONE 'Asia/Kathmandu')::date::text AS day
+		FROM bookings b
+		WHERE b.venue_id = $1
+		  AND b.status IN ('confirmed', 'done')
+		  AND (b.start_time AT TIME ZONE 'Asia/Kathmandu')::date >= $2::date
+		  AND (b.start_time AT TIME ZONE 'Asia/Kathmandu')::date < $3::date
+		  AND NOT EXISTS (
+			SELECT 1 FROM venue_day_closes c
+			WHERE c.venue_id = b.venue_id
+			  AND c.business_date = (b.start_time AT TIME ZONE 'Asia/Kathmandu')::date
+			  AND c.reopened_at IS NULL
+		  )
+		ORDER BY day
+	`, s.VenueID, s.PeriodStart, s.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("list unclosed days: %w", err)
+	}
+	defer days.Close()
+	for days.Next() {
+		var d string
+		if err := days.Scan(&d); err != nil {
+			return nil, fmt.Errorf("scan unclosed day: %w", err)
+		}
+		s.UnclosedDays = append(s.UnclosedDays, d)
+	}
+	if err := days.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Settlement, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH filtered AS (` + settlementSelect + `
+			WHERE ($1 = '' OR s.status = $1)
+			  AND ($2::bigint IS NULL OR s.venue_id = $2)
+			  AND ($3::bigint IS NULL OR v.owner_id = $3 OR EXISTS (
+				SELECT 1 FROM organization_members m
+				WHERE m.organization_id = v.organization_id
+				  AND m.user_id = $3
+				  AND m.role IN ('owner', 'manager')
+			  ))
+		)
+		SELECT filtered.*, COUNT(*) OVER() AS total_count
+		FROM filtered
+		ORDER BY period_start DESC, id DESC
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := r.db.Query(ctx, query, f.Status, f.VenueID, f.ManagerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list settlements: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Settlement{}
+	var total int
+	for rows.Next() {
+		var s Settlement
+		if err := rows.Scan(append(settlementScanArgs(&s), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan settlement: %w", err)
+		}
+		list = append(list, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) UpdateStatus(ctx context.Context, id int64, from, to string, reference, note *string) error {
+	if !CanTransition(from, to) {
+		return ErrInvalidTransition
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE settlements
+		SET status = $3,
+		    payout_reference = COALESCE($4, payout_reference),
+		    note = COALESCE($5, note),
+		    paid_at = CASE WHEN $3 = 'paid' THEN NOW() ELSE paid_at END,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = $2
+	`, id, from, to, reference, note)
+	if err != nil {
+		return fmt.Errorf("update settlement status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvalidTransition
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package settlements
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+const (
+	StatusPending = "pending"
+	StatusOnHold  = "on_hold"
+	StatusPaid    = "paid"
+)
+
+var (
+	ErrNotFound          = errors.New("settlement not found")
+	ErrInvalidTransition = errors.New("settlement cannot move to that status")
+)
+
+// CanTransition reports whether a settlement may move from one status to
+// another. Paid is final.
+func CanTransition(from, to string) bool {
+	switch from {
+	case StatusPending:
+		return to == StatusOnHold || to == StatusPaid
+	case StatusOnHold:
+		return to == StatusPending || to == StatusPaid
+	}
+	return false
+}
+
+// Item is one booking in a settlement. The commission percent is the one
+// copied onto the booking when it was confirmed. Canceled bookings only
+// appear when part of an online payment was kept; their commission is
+// charged on the kept amount instead of the booking price.
+type Item struct {
+	ID                int64     `json:"id"`
+	BookingID         int64     `json:"booking_id"`
+	BookingStatus     string    `json:"booking_status"`
+	StartTime         time.Time `json:"start_time"`
+	BookingAmount     int       `json:"booking_amount"`
+	OnlineAmount      int       `json:"online_amount"`
+	RefundedAmount    int       `json:"refunded_amount"`
+	CommissionPercent int       `json:"commission_percent"`
+	CommissionAmount  int       `json:"commission_amount"`
+	NetAmount         int       `json:"net_amount"`
+}
+
+// Settlement is a venue's statement for one week. PayoutAmount is what the
+// platform owes the venue: online payments less refunds and commission. It
+// is negative when the venue owes commission on cash bookings.
+type Settlement struct {
+	ID               int64      `json:"id"`
+	VenueID          int64      `json:"venue_id"`
+	VenueName        string     `json:"venue_name"`
+	PeriodStart      string     `json:"period_start"`
+	PeriodEnd        string     `json:"period_end"`
+	BookingCount     int        `json:"booking_count"`
+	GrossAmount      int        `json:"gross_amount"`
+	OnlineAmount     int        `json:"online_amount"`
+	RefundedAmount   int        `json:"refunded_amount"`
+	CommissionAmount int        `json:"commission_amount"`
+	PayoutAmount     int        `json:"payout_amount"`
+	Status           string     `json:"status"`
+	PayoutReference  *string    `json:"payout_reference,omitempty"`
+	Note             *string    `json:"note,omitempty"`
+	PaidAt           *time.Time `json:"paid_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	Items            []Item     `json:"items,omitempty"`
+	// UnclosedDays are the period's days with bookings that the venue
+	// hasn't closed yet. Their figures can still change.
+	UnclosedDays []string `json:"unclosed_days,omitempty"`
+}
+
+// Period is the week a batch covers, [Start, End) in Nepal time.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+type Filter struct {
+	Status  string
+	VenueID *int64
+	// ManagerID limits the list to venues the user owns or manages through
+	// an organization.
+	ManagerID *int64
+}
+
+type Store interface {
+	// CreateBatch settles every eligible booking that ended before the end
+	// of the period, one settlement per venue. Bookings from earlier weeks
+	// that were not settled yet, e.g. because a refund was still open, are
+	// picked up too. Running it again for the same period is a no-op. It
+	// returns the number of settlements created.
+	CreateBatch(ctx context.Context, p Period) (int, error)
+
+	GetByID(ctx context.Context, id int64) (*Settlement, error)
+	List(ctx context.Context, f Filter, limit, offset int) ([]Settlement, int, error)
+	// UpdateStatus moves a settlement from one status to another; it fails
+	// with ErrInvalidTransition if the settlement is no longer in from.
+	UpdateStatus(ctx context.Context, id int64, from, to string, reference, note *string) error
+}
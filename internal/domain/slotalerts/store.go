@@ -0,0 +1,172 @@
+package slotalerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, a *Alert) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `SELECT name FROM venues WHERE id = $1`, a.VenueID).Scan(&a.VenueName); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrVenueNotFound
+			}
+			return fmt.Errorf("get venue: %w", err)
+		}
+		if a.FacilityID != nil {
+			var name string
+			err := tx.QueryRow(ctx, `SELECT name FROM facilities WHERE id = $1 AND venue_id = $2`, *a.FacilityID, a.VenueID).Scan(&name)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return ErrFacilityNotFound
+				}
+				return fmt.Errorf("get facility: %w", err)
+			}
+			a.FacilityName = &name
+		}
+
+		var pending int
+		var duplicate bool
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*),
+			       COALESCE(BOOL_OR(venue_id = $2 AND facility_id IS NOT DISTINCT FROM $3 AND start_time = $4 AND end_time = $5), FALSE)
+			FROM slot_alerts
+			WHERE user_id = $1 AND notified_at IS NULL AND end_time > NOW()
+		`, a.UserID, a.VenueID, a.FacilityID, a.StartTime, a.EndTime).Scan(&pending, &duplicate); err != nil {
+			return fmt.Errorf("count slot alerts: %w", err)
+		}
+		if duplicate {
+			return ErrDuplicate
+		}
+		if pending >= MaxPerUser {
+			return ErrLimitExceeded
+		}
+
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO slot_alerts (user_id, venue_id, facility_id, start_time, end_time)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+		`, a.UserID, a.VenueID, a.FacilityID, a.StartTime, a.EndTime).Scan(&a.ID, &a.CreatedAt); err != nil {
+			return fmt.Errorf("create slot alert: %w", err)
+		}
+		a.Status = StatusWaiting
+		return nil
+	})
+}
+
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]Alert, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, a.user_id, a.venue_id, v.name, a.facility_id, f.name,
+		       a.start_time, a.end_time,
+		       CASE
+		           WHEN a.notified_at IS NOT NULL THEN 'notified'
+		           WHEN a.end_time <= NOW() THEN 'expired'
+		           ELSE 'waiting'
+		       END,
+		       a.notified_at, a.created_at
+		FROM slot_alerts a
+		JOIN venues v ON v.id = a.venue_id
+		LEFT JOIN facilities f ON f.id = a.facility_id
+		WHERE a.user_id = $1
+		ORDER BY a.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list slot alerts: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Alert{}
+	for rows.Next() {
+		var a Alert
+		if err := rows.Scan(&a.ID, &a.UserID, &a.VenueID, &a.VenueName, &a.FacilityID, &a.FacilityName,
+			&a.StartTime, &a.EndTime, &a.Status, &a.NotifiedAt, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan slot alert: %w", err)
+		}
+		list = append(list, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, userID, alertID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM slot_alerts WHERE id = $1 AND user_id = $2`, alertID, userID)
+	if err != nil {
+		return fmt.Errorf("delete slot alert: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MatchReleased claims the alerts in one statement, so two releases of
+// overlapping bookings cannot both fire the same alert.
+func (r *Repository) MatchReleased(ctx context.Context, b Released) ([]Triggered, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		WITH fired AS (
+			UPDATE slot_alerts
+			SET notified_at = NOW()
+			WHERE venue_id = $1
+			  AND (facility_id IS NULL OR facility_id = $2)
+			  AND user_id <> $3
+			  AND notified_at IS NULL
+			  AND start_time < $5
+			  AND end_time > $4
+			  AND $5 > NOW()
+			RETURNING id, user_id
+		)
+		SELECT fired.id, fired.user_id, v.name, COALESCE(f.name, '')
+		FROM fired
+		JOIN venues v ON v.id = $1
+		LEFT JOIN facilities f ON f.id = $2
+	`, b.VenueID, b.FacilityID, b.UserID, b.StartTime, b.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("match slot alerts: %w", err)
+	}
+	defer rows.Close()
+
+	triggered := []Triggered{}
+	for rows.Next() {
+		t := Triggered{
+			VenueID:    b.VenueID,
+			FacilityID: b.FacilityID,
+			StartTime:  b.StartTime,
+			EndTime:    b.EndTime,
+		}
+		if err := rows.Scan(&t.AlertID, &t.UserID, &t.VenueName, &t.FacilityName); err != nil {
+			return nil, fmt.Errorf("scan slot alert: %w", err)
+		}
+		triggered = append(triggered, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return triggered, nil
+}
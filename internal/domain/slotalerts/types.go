@@ -0,0 +1,76 @@
+package slotalerts
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// MaxPerUser caps how many pending alerts one user can hold.
+const MaxPerUser = 20
+
+const (
+	StatusWaiting  = "waiting"
+	StatusNotified = "notified"
+	StatusExpired  = "expired"
+)
+
+var (
+	ErrNotFound         = errors.New("slot alert not found")
+	ErrVenueNotFound    = errors.New("venue not found")
+	ErrFacilityNotFound = errors.New("facility not found at this venue")
+	ErrDuplicate        = errors.New("you already have an alert for this time range")
+	ErrLimitExceeded    = errors.New("slot alert limit reached")
+)
+
+// Alert asks to be told when any booking overlapping [StartTime, EndTime)
+// at the venue, or at one facility of it, is canceled or rejected. Status
+// is derived: waiting until it fires, notified once it has, expired when
+// the range passed without a slot freeing up.
+type Alert struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	VenueID      int64      `json:"venue_id"`
+	VenueName    string     `json:"venue_name"`
+	FacilityID   *int64     `json:"facility_id,omitempty"`
+	FacilityName *string    `json:"facility_name,omitempty"`
+	StartTime    time.Time  `json:"start_time"`
+	EndTime      time.Time  `json:"end_time"`
+	Status       string     `json:"status"`
+	NotifiedAt   *time.Time `json:"notified_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Released is a booking that just gave its time back.
+type Released struct {
+	BookingID  int64
+	VenueID    int64
+	FacilityID int64
+	UserID     int64
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// Triggered is an alert that fired for a released booking.
+type Triggered struct {
+	AlertID      int64
+	UserID       int64
+	VenueID      int64
+	VenueName    string
+	FacilityID   int64
+	FacilityName string
+	StartTime    time.Time
+	EndTime      time.Time
+}
+
+type Store interface {
+	Create(ctx context.Context, a *Alert) error
+	ListByUser(ctx context.Context, userID int64) ([]Alert, error)
+	Delete(ctx context.Context, userID, alertID int64) error
+	// MatchReleased marks every waiting alert overlapping the released
+	// booking as notified and returns them. The booking's own user is
+	// skipped.
+	MatchReleased(ctx context.Context, b Released) ([]Triggered, error)
+}
@@ -3,94 +3,215 @@ package storage
 import (
 	"context"
 	"fmt"
+	"khel/internal/audit"
 	"khel/internal/domain/accesscontrol"
 	"khel/internal/domain/admindashboard"
 	"khel/internal/domain/ads"
 	"khel/internal/domain/appreviews"
+	"khel/internal/domain/availability"
+	"khel/internal/domain/bookingrules"
 	"khel/internal/domain/bookings"
 	"khel/internal/domain/carts"
+	"khel/internal/domain/checkins"
+	"khel/internal/domain/closures"
+	"khel/internal/domain/commissions"
+	"khel/internal/domain/demosandboxes"
+	"khel/internal/domain/disputes"
 	"khel/internal/domain/facilities"
 	"khel/internal/domain/featured"
+	"khel/internal/domain/feed"
 	"khel/internal/domain/followers"
+	"khel/internal/domain/friends"
+	"khel/internal/domain/gamepayments"
 	"khel/internal/domain/gameqa"
 	"khel/internal/domain/games"
+	"khel/internal/domain/guests"
+	"khel/internal/domain/helpcenter"
+	"khel/internal/domain/holidays"
+	"khel/internal/domain/homelayout"
+	"khel/internal/domain/imagevariants"
+	"khel/internal/domain/inbox"
 	"khel/internal/domain/inventory"
+	"khel/internal/domain/mediaassets"
+	"khel/internal/domain/moderationqueue"
+	"khel/internal/domain/notificationprefs"
 	"khel/internal/domain/orders"
+	"khel/internal/domain/organizations"
+	"khel/internal/domain/paymentsplits"
 	"khel/internal/domain/paymentsrepo"
+	"khel/internal/domain/photomigrations"
+	"khel/internal/domain/pricealerts"
 	"khel/internal/domain/products"
 	"khel/internal/domain/pushtokens"
+	"khel/internal/domain/refunds"
+	"khel/internal/domain/reminders"
+	"khel/internal/domain/safety"
+	"khel/internal/domain/savedsearches"
+	"khel/internal/domain/search"
+	"khel/internal/domain/settlements"
+	"khel/internal/domain/slotalerts"
+	"khel/internal/domain/support"
 	"khel/internal/domain/users"
+	"khel/internal/domain/venueannouncements"
+	"khel/internal/domain/venuebenchmark"
+	"khel/internal/domain/venuecalendar"
 	"khel/internal/domain/venuecustomers"
+	"khel/internal/domain/venuedaycloses"
 	"khel/internal/domain/venueearnings"
+	"khel/internal/domain/venueforecast"
 	"khel/internal/domain/venuerequest"
 	venuereviews "khel/internal/domain/venuereview"
 	"khel/internal/domain/venues"
+	"khel/internal/domain/venuestaff"
+	"khel/internal/domain/venueverification"
+	"khel/internal/jobs"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
 )
 
 type Sales struct {
 	Carts    carts.Store
 	Orders   orders.Store
 	Payments paymentsrepo.Store
-	PayLogs  *paymentsrepo.LogsRepository
+	PayLogs  paymentsrepo.LogsStore
 }
 
 type Container struct {
-	pool           *pgxpool.Pool                // IMPORTANT: set the pool so WithSalesTx works
-	orderGen       *orders.OrderNumberGenerator //unexported intentionally
-	Users          users.Store
-	VenueRequests  venuerequest.RequestStore
-	Venues         venues.Store
-	Facilities     facilities.Store
-	VenueCustomers venuecustomers.Store
-	VenuesReviews  venuereviews.Store
-	VenueEarnings  venueearnings.Store
-	Inventory      inventory.Store
-	Followers      followers.Store
-	Games          games.Store
-	Bookings       bookings.Store
-	GameQA         gameqa.Store
-	AppReviews     appreviews.Store
-	PushTokens     pushtokens.Store
-	Ads            ads.Store
-	AdminDashboard admindashboard.Store
-	AccessControl  accesscontrol.Store
-	Products       products.Store
-	Sales          Sales
-	Featured       featured.Store
+	pool               *pgxpool.Pool                // IMPORTANT: set the pool so WithSalesTx works
+	orderGen           *orders.OrderNumberGenerator //unexported intentionally
+	Users              users.Store
+	Availability       availability.Store
+	VenueRequests      venuerequest.RequestStore
+	Venues             venues.Store
+	Facilities         facilities.Store
+	VenueCustomers     venuecustomers.Store
+	VenuesReviews      venuereviews.Store
+	VenueEarnings      venueearnings.Store
+	VenueForecast      venueforecast.Store
+	VenueCalendar      venuecalendar.Store
+	VenueBenchmark     venuebenchmark.Store
+	VenueAnnouncements venueannouncements.Store
+	VenueVerification  venueverification.Store
+	VenueStaff         venuestaff.Store
+	Inventory          inventory.Store
+	Followers          followers.Store
+	Friends            friends.Store
+	ModerationQueue    moderationqueue.Store
+	Safety             safety.Store
+	Feed               feed.Store
+	Games              games.Store
+	Bookings           bookings.Store
+	Disputes           disputes.Store
+	GameQA             gameqa.Store
+	AppReviews         appreviews.Store
+	PushTokens         pushtokens.Store
+	NotificationPrefs  notificationprefs.Store
+	Inbox              inbox.Store
+	Reminders          reminders.Store
+	PriceAlerts        pricealerts.Store
+	SavedSearches      savedsearches.Store
+	Search             search.Store
+	SlotAlerts         slotalerts.Store
+	Organizations      organizations.Store
+	PaymentSplits      paymentsplits.Store
+	Guests             guests.Store
+	Refunds            refunds.Store
+	BookingRules       bookingrules.Store
+	CheckIns           checkins.Store
+	GamePayments       gamepayments.Store
+	Holidays           holidays.Store
+	Closures           closures.Store
+	DayCloses          venuedaycloses.Store
+	DemoSandboxes      demosandboxes.Store
+	PhotoMigrations    photomigrations.Store
+	ImageVariants      imagevariants.Store
+	MediaAssets        mediaassets.Store
+	Settlements        settlements.Store
+	Commissions        commissions.Store
+	Ads                ads.Store
+	AdminDashboard     admindashboard.Store
+	AccessControl      accesscontrol.Store
+	Products           products.Store
+	Sales              Sales
+	Featured           featured.Store
+	Audit              audit.Store
+	Support            support.Store
+	HelpCenter         helpcenter.Store
+	HomeLayout         homelayout.Store
+	Jobs               jobs.Store
 }
 
-func NewContainer(db *pgxpool.Pool, orderGen *orders.OrderNumberGenerator) *Container {
+func NewContainer(db *pgxpool.Pool, orderGen *orders.OrderNumberGenerator, logger *zap.SugaredLogger) *Container {
 	return &Container{
-		pool:           db,
-		Users:          users.NewRepository(db),
-		orderGen:       orderGen,
-		VenueRequests:  venuerequest.NewRepository(db),
-		Venues:         venues.NewRepository(db),
-		Facilities:     facilities.NewRepository(db),
-		VenueCustomers: venuecustomers.NewRepository(db),
-		VenueEarnings:  venueearnings.NewRepository(db),
-		VenuesReviews:  venuereviews.NewRepository(db),
-		Inventory:      inventory.NewRepository(db),
-		Followers:      followers.NewRepository(db),
-		Games:          games.NewRepository(db),
-		Bookings:       bookings.NewRepository(db),
-		GameQA:         gameqa.NewRepository(db),
-		AppReviews:     appreviews.NewRepository(db),
-		PushTokens:     pushtokens.NewRepository(db),
-		Ads:            ads.NewRepository(db),
-		AdminDashboard: admindashboard.NewRepository(db),
-		AccessControl:  accesscontrol.NewRepository(db),
-		Products:       products.NewRepository(db),
+		pool:               db,
+		Users:              users.NewRepository(db),
+		Availability:       availability.NewRepository(db),
+		orderGen:           orderGen,
+		VenueRequests:      venuerequest.NewRepository(db),
+		Venues:             venues.NewRepository(db),
+		Facilities:         facilities.NewRepository(db),
+		VenueCustomers:     venuecustomers.NewRepository(db),
+		VenueEarnings:      venueearnings.NewRepository(db),
+		VenueForecast:      venueforecast.NewRepository(db),
+		VenueCalendar:      venuecalendar.NewRepository(db),
+		VenueBenchmark:     venuebenchmark.NewRepository(db),
+		VenueAnnouncements: venueannouncements.NewRepository(db),
+		VenueVerification:  venueverification.NewRepository(db),
+		VenueStaff:         venuestaff.NewRepository(db),
+		VenuesReviews:      venuereviews.NewRepository(db),
+		Inventory:          inventory.NewRepository(db),
+		Followers:          followers.NewRepository(db),
+		Friends:            friends.NewRepository(db),
+		ModerationQueue:    moderationqueue.NewRepository(db),
+		Safety:             safety.NewRepository(db),
+		Feed:               feed.NewRepository(db),
+		Games:              games.NewRepository(db),
+		Bookings:           bookings.NewRepository(db),
+		Disputes:           disputes.NewRepository(db),
+		GameQA:             gameqa.NewRepository(db),
+		AppReviews:         appreviews.NewRepository(db),
+		PushTokens:         pushtokens.NewRepository(db),
+		NotificationPrefs:  notificationprefs.NewRepository(db),
+		Reminders:          reminders.NewRepository(db),
+		PriceAlerts:        pricealerts.NewRepository(db),
+		SavedSearches:      savedsearches.NewRepository(db),
+		Search:             search.NewRepository(db),
+		SlotAlerts:         slotalerts.NewRepository(db),
+		Organizations:      organizations.NewRepository(db),
+		PaymentSplits:      paymentsplits.NewRepository(db),
+		Guests:             guests.NewRepository(db),
+		Refunds:            refunds.NewRepository(db),
+		BookingRules:       bookingrules.NewRepository(db),
+		CheckIns:           checkins.NewRepository(db),
+		GamePayments:       gamepayments.NewRepository(db),
+		Holidays:           holidays.NewRepository(db),
+		Closures:           closures.NewRepository(db),
+		DayCloses:          venuedaycloses.NewRepository(db),
+		DemoSandboxes:      demosandboxes.NewRepository(db),
+		PhotoMigrations:    photomigrations.NewRepository(db),
+		ImageVariants:      imagevariants.NewRepository(db),
+		MediaAssets:        mediaassets.NewRepository(db),
+		Settlements:        settlements.NewRepository(db),
+		Commissions:        commissions.NewRepository(db),
+		Inbox:              inbox.NewRepository(db),
+		Ads:                ads.NewRepository(db),
+		AdminDashboard:     admindashboard.NewRepository(db),
+		AccessControl:      accesscontrol.NewRepository(db),
+		Products:           products.NewRepository(db, logger),
 		Sales: Sales{
 			Carts:    carts.NewRepository(db),
 			Orders:   orders.NewRepository(db, orderGen),
 			Payments: paymentsrepo.NewRepository(db),
 			PayLogs:  paymentsrepo.NewLogsRepository(db),
 		},
-		Featured: featured.NewRepository(db),
+		Featured:   featured.NewRepository(db),
+		Audit:      audit.NewRepository(db),
+		Support:    support.NewRepository(db),
+		HelpCenter: helpcenter.NewRepository(db),
+		HomeLayout: homelayout.NewRepository(db),
+		Jobs:       jobs.NewRepository(db),
 	}
 }
 
@@ -99,7 +220,7 @@ type SalesTx struct {
 	Carts    carts.Store
 	Orders   orders.Store
 	Payments paymentsrepo.Store
-	PayLogs  *paymentsrepo.LogsRepository
+	PayLogs  paymentsrepo.LogsStore
 }
 
 // WithSalesTx runs a sales unit-of-work atomically.
@@ -0,0 +1,282 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const ticketColumns = `
+	id, user_id, category, subject, status, booking_id, order_id, assigned_to,
+	last_message_at, resolved_at, closed_at, created_at, updated_at
+`
+
+func scanTicket(row pgx.Row, t *Ticket) error {
+	return row.Scan(
+		&t.ID, &t.UserID, &t.Category, &t.Subject, &t.Status, &t.BookingID, &t.OrderID, &t.AssignedTo,
+		&t.LastMessageAt, &t.ResolvedAt, &t.ClosedAt, &t.CreatedAt, &t.UpdatedAt,
+	)
+}
+
+func (r *Repository) Create(ctx context.Context, in CreateTicketInput) (*Ticket, error) {
+	var t Ticket
+
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		// The referenced booking/order must belong to the user opening the ticket.
+		if in.BookingID != nil {
+			var ok bool
+			if err := tx.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM bookings WHERE id = $1 AND user_id = $2)`,
+				*in.BookingID, in.UserID,
+			).Scan(&ok); err != nil {
+				return fmt.Errorf("check booking: %w", err)
+			}
+			if !ok {
+				return ErrInvalidReference
+			}
+		}
+		if in.OrderID != nil {
+			var ok bool
+			if err := tx.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1 AND user_id = $2)`,
+				*in.OrderID, in.UserID,
+			).Scan(&ok); err != nil {
+				return fmt.Errorf("check order: %w", err)
+			}
+			if !ok {
+				return ErrInvalidReference
+			}
+		}
+
+		q := `
+			INSERT INTO support_tickets (user_id, category, subject, booking_id, order_id)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING ` + ticketColumns
+		if err := scanTicket(tx.QueryRow(ctx, q, in.UserID, in.Category, in.Subject, in.BookingID, in.OrderID), &t); err != nil {
+			return fmt.Errorf("create ticket: %w", err)
+		}
+
+		m, err := insertMessage(ctx, tx, t.ID, in.UserID, false, in.Body, in.AttachmentURLs)
+		if err != nil {
+			return err
+		}
+		t.Messages = []Message{*m}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func insertMessage(ctx context.Context, tx pgx.Tx, ticketID, authorID int64, isStaff bool, body string, attachmentURLs []string) (*Message, error) {
+	if attachmentURLs == nil {
+		attachmentURLs = []string{}
+	}
+	var m Message
+	err := tx.QueryRow(ctx, `
+		INSERT INTO support_ticket_messages (ticket_id, author_id, is_staff, body, attachment_urls)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, ticket_id, author_id, is_staff, body, attachment_urls, created_at
+	`, ticketID, authorID, isStaff, body, attachmentURLs).Scan(
+		&m.ID, &m.TicketID, &m.AuthorID, &m.IsStaff, &m.Body, &m.AttachmentURLs, &m.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert ticket message: %w", err)
+	}
+	return &m, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (*Ticket, error) {
+	var t Ticket
+	if err := scanTicket(r.db.QueryRow(ctx, `SELECT `+ticketColumns+` FROM support_tickets WHERE id = $1`, id), &t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, fmt.Errorf("get ticket: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, ticket_id, author_id, is_staff, body, attachment_urls, created_at
+		FROM support_ticket_messages
+		WHERE ticket_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list ticket messages: %w", err)
+	}
+	defer rows.Close()
+
+	t.Messages = []Message{}
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.ID, &m.TicketID, &m.AuthorID, &m.IsStaff, &m.Body, &m.AttachmentURLs, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan ticket message: %w", err)
+		}
+		t.Messages = append(t.Messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Ticket, int, error) {
+	q := `
+		SELECT ` + ticketColumns + `, COUNT(*) OVER() AS total_count
+		FROM support_tickets
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR category = $2)
+		  AND ($3::bigint IS NULL OR assigned_to = $3)
+		  AND ($4::bigint IS NULL OR user_id = $4)
+		ORDER BY last_message_at DESC, id DESC
+		LIMIT $5 OFFSET $6
+	`
+	rows, err := r.db.Query(ctx, q, f.Status, f.Category, f.AssignedTo, f.UserID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list tickets: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Ticket{}
+	var total int
+	for rows.Next() {
+		var t Ticket
+		if err := rows.Scan(
+			&t.ID, &t.UserID, &t.Category, &t.Subject, &t.Status, &t.BookingID, &t.OrderID, &t.AssignedTo,
+			&t.LastMessageAt, &t.ResolvedAt, &t.ClosedAt, &t.CreatedAt, &t.UpdatedAt, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan ticket: %w", err)
+		}
+		list = append(list, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) AddMessage(ctx context.Context, ticketID int64, authorID int64, isStaff bool, body string, attachmentURLs []string) (*Message, *Ticket, error) {
+	var (
+		m *Message
+		t Ticket
+	)
+
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var status string
+		err := tx.QueryRow(ctx, `SELECT status FROM support_tickets WHERE id = $1 FOR UPDATE`, ticketID).Scan(&status)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrTicketNotFound
+			}
+			return fmt.Errorf("lock ticket: %w", err)
+		}
+		if status == StatusClosed {
+			return ErrTicketClosed
+		}
+
+		next := status
+		switch {
+		case isStaff:
+			next = StatusAwaitingUser
+		case status == StatusAwaitingUser || status == StatusResolved:
+			next = StatusOpen
+		}
+
+		m, err = insertMessage(ctx, tx, ticketID, authorID, isStaff, body, attachmentURLs)
+		if err != nil {
+			return err
+		}
+
+		q := `
+			UPDATE support_tickets
+			SET status = $2,
+			    last_message_at = NOW(),
+			    resolved_at = NULL,
+			    updated_at = NOW()
+			WHERE id = $1
+			RETURNING ` + ticketColumns
+		if err := scanTicket(tx.QueryRow(ctx, q, ticketID, next), &t); err != nil {
+			return fmt.Errorf("update ticket: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, &t, nil
+}
+
+func (r *Repository) SetStatus(ctx context.Context, ticketID int64, status string) (*Ticket, error) {
+	var t Ticket
+
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var current string
+		err := tx.QueryRow(ctx, `SELECT status FROM support_tickets WHERE id = $1 FOR UPDATE`, ticketID).Scan(&current)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrTicketNotFound
+			}
+			return fmt.Errorf("lock ticket: %w", err)
+		}
+		if !CanTransition(current, status) {
+			return ErrInvalidTransition
+		}
+
+		q := `
+			UPDATE support_tickets
+			SET status = $2,
+			    resolved_at = CASE WHEN $2 = 'resolved' THEN NOW()
+			                       WHEN $2 = 'closed' THEN resolved_at
+			                       ELSE NULL END,
+			    closed_at = CASE WHEN $2 = 'closed' THEN NOW() ELSE NULL END,
+			    updated_at = NOW()
+			WHERE id = $1
+			RETURNING ` + ticketColumns
+		if err := scanTicket(tx.QueryRow(ctx, q, ticketID, status), &t); err != nil {
+			return fmt.Errorf("set ticket status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *Repository) Assign(ctx context.Context, ticketID int64, assigneeID *int64) (*Ticket, error) {
+	// Picking up an open ticket moves it to in_progress.
+	q := `
+		UPDATE support_tickets
+		SET assigned_to = $2,
+		    status = CASE WHEN $2::bigint IS NOT NULL AND status = 'open' THEN 'in_progress' ELSE status END,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + ticketColumns
+
+	var t Ticket
+	if err := scanTicket(r.db.QueryRow(ctx, q, ticketID, assigneeID), &t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTicketNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, ErrInvalidAssignee
+		}
+		return nil, fmt.Errorf("assign ticket: %w", err)
+	}
+	return &t, nil
+}
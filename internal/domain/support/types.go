@@ -0,0 +1,114 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTicketNotFound    = errors.New("ticket not found")
+	ErrTicketClosed      = errors.New("ticket is closed")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrInvalidReference  = errors.New("booking or order does not belong to user")
+	ErrInvalidAssignee   = errors.New("assignee not found")
+)
+
+// Categories
+const (
+	CategoryBooking = "booking"
+	CategoryPayment = "payment"
+	CategoryOrder   = "order"
+	CategoryAccount = "account"
+	CategoryVenue   = "venue"
+	CategoryGame    = "game"
+	CategoryOther   = "other"
+)
+
+// Statuses
+const (
+	StatusOpen         = "open"
+	StatusInProgress   = "in_progress"
+	StatusAwaitingUser = "awaiting_user"
+	StatusResolved     = "resolved"
+	StatusClosed       = "closed"
+)
+
+// allowedTransitions lists the statuses staff may move a ticket to.
+var allowedTransitions = map[string][]string{
+	StatusOpen:         {StatusInProgress, StatusAwaitingUser, StatusResolved, StatusClosed},
+	StatusInProgress:   {StatusOpen, StatusAwaitingUser, StatusResolved, StatusClosed},
+	StatusAwaitingUser: {StatusOpen, StatusInProgress, StatusResolved, StatusClosed},
+	StatusResolved:     {StatusOpen, StatusInProgress, StatusClosed},
+	StatusClosed:       {},
+}
+
+// CanTransition reports whether a ticket may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, s := range allowedTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+type Ticket struct {
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	Category      string     `json:"category"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	BookingID     *int64     `json:"booking_id,omitempty"`
+	OrderID       *int64     `json:"order_id,omitempty"`
+	AssignedTo    *int64     `json:"assigned_to,omitempty"`
+	LastMessageAt time.Time  `json:"last_message_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Messages []Message `json:"messages,omitempty"`
+}
+
+type Message struct {
+	ID             int64     `json:"id"`
+	TicketID       int64     `json:"ticket_id"`
+	AuthorID       *int64    `json:"author_id,omitempty"`
+	IsStaff        bool      `json:"is_staff"`
+	Body           string    `json:"body"`
+	AttachmentURLs []string  `json:"attachment_urls"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type CreateTicketInput struct {
+	UserID         int64
+	Category       string
+	Subject        string
+	Body           string
+	AttachmentURLs []string
+	BookingID      *int64
+	OrderID        *int64
+}
+
+// Filter narrows admin listing. Zero values are ignored.
+type Filter struct {
+	Status     string
+	Category   string
+	AssignedTo *int64
+	UserID     *int64
+}
+
+type Store interface {
+	// Create opens a ticket with its first message.
+	Create(ctx context.Context, in CreateTicketInput) (*Ticket, error)
+	// Get returns the ticket with all messages.
+	Get(ctx context.Context, id int64) (*Ticket, error)
+	List(ctx context.Context, f Filter, limit, offset int) ([]Ticket, int, error)
+
+	// AddMessage appends a message and moves the status along: a staff reply
+	// sets awaiting_user, a user reply reopens awaiting_user/resolved tickets.
+	AddMessage(ctx context.Context, ticketID int64, authorID int64, isStaff bool, body string, attachmentURLs []string) (*Message, *Ticket, error)
+	SetStatus(ctx context.Context, ticketID int64, status string) (*Ticket, error)
+	Assign(ctx context.Context, ticketID int64, assigneeID *int64) (*Ticket, error)
+}
@@ -0,0 +1,117 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Anonymization keeps the users row, and every row pointing at it, so
+// booking counts, game rosters, ratings and revenue stay correct. What
+// identifies the person is overwritten; data that only ever mattered to
+// them is deleted outright.
+
+func (r *Repository) Anonymize(ctx context.Context, userID int64) (*AnonymizeResult, error) {
+	return r.anonymize(ctx, userID, false)
+}
+
+func (r *Repository) AnonymizeDue(ctx context.Context, userID int64) (*AnonymizeResult, error) {
+	return r.anonymize(ctx, userID, true)
+}
+
+func (r *Repository) anonymize(ctx context.Context, userID int64, onlyDue bool) (*AnonymizeResult, error) {
+	res := &AnonymizeResult{}
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		// Locking the row means a sign-in that cancels a pending deletion
+		// either lands first, so the due check fails, or waits for us.
+		var (
+			anonymized bool
+			due        bool
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT profile_picture_url,
+			       anonymized_at IS NOT NULL,
+			       COALESCE(deletion_scheduled_for <= NOW(), FALSE)
+			FROM users
+			WHERE id = $1
+			FOR UPDATE
+		`, userID).Scan(&res.ProfilePictureURL, &anonymized, &due)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("lock user: %w", err)
+		}
+		if anonymized {
+			return ErrAlreadyAnonymized
+		}
+		if onlyDue && !due {
+			return ErrNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE users
+			SET first_name = 'Deleted',
+			    last_name = 'User',
+			    email = 'deleted-' || id || '@deleted.invalid',
+			    phone = 'deleted-' || id,
+			    password = ''::bytea,
+			    profile_picture_url = NULL,
+			    skill_level = NULL,
+			    refresh_token = NULL,
+			    reset_password_token = NULL,
+			    reset_password_expires = NULL,
+			    is_active = FALSE,
+			    deletion_scheduled_for = NULL,
+			    anonymized_at = NOW(),
+			    updated_at = NOW()
+			WHERE id = $1
+		`, userID); err != nil {
+			return fmt.Errorf("anonymize user: %w", err)
+		}
+
+		scrub := []struct {
+			what  string
+			query string
+		}{
+			{"bookings", `UPDATE bookings SET customer_name = NULL, customer_phone = NULL, note = NULL WHERE user_id = $1`},
+			{"games", `UPDATE games SET instruction = NULL WHERE admin_id = $1`},
+			{"orders", `
+				UPDATE orders
+				SET shipping_name = 'Deleted User', shipping_phone = '', shipping_address = '',
+				    shipping_city = '', shipping_postal_code = NULL, notes = NULL
+				WHERE user_id = $1`},
+			// Ratings stay so venue averages don't move; the words go.
+			{"reviews", `UPDATE reviews SET comment = NULL, updated_at = NOW() WHERE user_id = $1`},
+			// Answered requests are roster history; an open one would let a
+			// host accept someone who no longer exists.
+			{"game join requests", `DELETE FROM game_join_requests WHERE user_id = $1 AND status = 'pending'`},
+			{"push tokens", `DELETE FROM user_push_tokens WHERE user_id = $1`},
+			{"invitations", `DELETE FROM user_invitations WHERE user_id = $1`},
+			{"roles", `DELETE FROM user_roles WHERE user_id = $1`},
+			{"notifications", `DELETE FROM notifications WHERE user_id = $1`},
+			{"notification preferences", `DELETE FROM notification_preferences WHERE user_id = $1`},
+			{"favorite venues", `DELETE FROM favorite_venues WHERE user_id = $1`},
+			{"shortlisted games", `DELETE FROM shortlisted_games WHERE user_id = $1`},
+			{"followers", `DELETE FROM followers WHERE user_id = $1 OR follower_id = $1`},
+			{"friendships", `DELETE FROM friendships WHERE requester_id = $1 OR addressee_id = $1`},
+			{"availability", `DELETE FROM user_availability WHERE user_id = $1`},
+			{"price alerts", `DELETE FROM price_alerts WHERE user_id = $1`},
+			{"saved searches", `DELETE FROM saved_searches WHERE user_id = $1`},
+			{"slot alerts", `DELETE FROM slot_alerts WHERE user_id = $1`},
+		}
+		for _, s := range scrub {
+			if _, err := tx.Exec(ctx, s.query, userID); err != nil {
+				return fmt.Errorf("anonymize %s: %w", s.what, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,209 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *Repository) RequestDeletion(ctx context.Context, userID int64, grace time.Duration) (time.Time, error) {
+	var due time.Time
+	err := r.db.QueryRow(ctx, `
+		UPDATE users
+		SET deletion_requested_at = COALESCE(deletion_requested_at, NOW()),
+		    deletion_scheduled_for = COALESCE(deletion_scheduled_for, NOW() + make_interval(secs => $2)),
+		    refresh_token = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND anonymized_at IS NULL
+		RETURNING deletion_scheduled_for
+	`, userID, grace.Seconds()).Scan(&due)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrNotFound
+		}
+		return time.Time{}, fmt.Errorf("request deletion: %w", err)
+	}
+	return due, nil
+}
+
+func (r *Repository) CancelDeletion(ctx context.Context, userID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET deletion_requested_at = NULL, deletion_scheduled_for = NULL, updated_at = NOW()
+		WHERE id = $1 AND deletion_scheduled_for IS NOT NULL AND anonymized_at IS NULL
+	`, userID)
+	if err != nil {
+		return false, fmt.Errorf("cancel deletion: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *Repository) ListDueDeletions(ctx context.Context, limit int) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id
+		FROM users
+		WHERE deletion_scheduled_for <= NOW() AND anonymized_at IS NULL
+		ORDER BY deletion_scheduled_for
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due deletions: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("list due deletions: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *Repository) ExportData(ctx context.Context, userID int64) (*DataExport, error) {
+	out := &DataExport{
+		GeneratedAt:    time.Now().UTC(),
+		Bookings:       []ExportBooking{},
+		Games:          []ExportGame{},
+		Orders:         []ExportOrder{},
+		Reviews:        []ExportReview{},
+		FavoriteVenues: []ExportFavoriteVenue{},
+	}
+
+	p := &out.Profile
+	err := r.db.QueryRow(ctx, `
+		SELECT u.id, u.first_name, u.last_name, u.email, u.phone, u.profile_picture_url, u.skill_level,
+		       COALESCE(ARRAY(
+		           SELECT rr.name FROM user_roles ur JOIN roles rr ON rr.id = ur.role_id
+		           WHERE ur.user_id = u.id ORDER BY rr.name
+		       ), '{}'),
+		       u.created_at
+		FROM users u
+		WHERE u.id = $1
+	`, userID).Scan(&p.ID, &p.FirstName, &p.LastName, &p.Email, &p.Phone, &p.ProfilePictureURL, &p.SkillLevel, &p.Roles, &p.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("export profile: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT b.id, b.venue_id, v.name, b.start_time, b.end_time, b.total_price, b.status::text,
+		       b.customer_name, b.customer_phone, b.note, b.created_at
+		FROM bookings b
+		JOIN venues v ON v.id = b.venue_id
+		WHERE b.user_id = $1
+		ORDER BY b.start_time DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("export bookings: %w", err)
+	}
+	for rows.Next() {
+		var b ExportBooking
+		if err := rows.Scan(&b.ID, &b.VenueID, &b.VenueName, &b.StartTime, &b.EndTime, &b.TotalPrice, &b.Status,
+			&b.CustomerName, &b.CustomerPhone, &b.Note, &b.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan booking: %w", err)
+		}
+		out.Bookings = append(out.Bookings, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT g.id, v.name, g.sport_type, gp.role, g.start_time, g.end_time, g.status, gp.joined_at
+		FROM game_players gp
+		JOIN games g ON g.id = gp.game_id
+		JOIN venues v ON v.id = g.venue_id
+		WHERE gp.user_id = $1
+		ORDER BY g.start_time DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("export games: %w", err)
+	}
+	for rows.Next() {
+		var g ExportGame
+		if err := rows.Scan(&g.ID, &g.VenueName, &g.SportType, &g.Role, &g.StartTime, &g.EndTime, &g.Status, &g.JoinedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan game: %w", err)
+		}
+		out.Games = append(out.Games, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT id, order_number, status::text, payment_status::text, total_cents,
+		       shipping_name, shipping_phone, shipping_address, shipping_city, created_at
+		FROM orders
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("export orders: %w", err)
+	}
+	for rows.Next() {
+		var o ExportOrder
+		if err := rows.Scan(&o.ID, &o.OrderNumber, &o.Status, &o.PaymentStatus, &o.TotalCents,
+			&o.ShippingName, &o.ShippingPhone, &o.ShippingAddress, &o.ShippingCity, &o.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan order: %w", err)
+		}
+		out.Orders = append(out.Orders, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT id, venue_id, rating, comment, status, created_at
+		FROM reviews
+		WHERE user_id = $1 AND status <> 'deleted'
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("export reviews: %w", err)
+	}
+	for rows.Next() {
+		var rv ExportReview
+		if err := rows.Scan(&rv.ID, &rv.VenueID, &rv.Rating, &rv.Comment, &rv.Status, &rv.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan review: %w", err)
+		}
+		out.Reviews = append(out.Reviews, rv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT f.venue_id, v.name, f.created_at
+		FROM favorite_venues f
+		JOIN venues v ON v.id = f.venue_id
+		WHERE f.user_id = $1
+		ORDER BY f.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("export favorite venues: %w", err)
+	}
+	for rows.Next() {
+		var f ExportFavoriteVenue
+		if err := rows.Scan(&f.VenueID, &f.VenueName, &f.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan favorite venue: %w", err)
+		}
+		out.FavoriteVenues = append(out.FavoriteVenues, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	return out, nil
+}
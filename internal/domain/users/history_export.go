@@ -0,0 +1,144 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// historyExportStaleAfter is how long a pending export blocks a new request.
+// A job that hasn't finished by then has most likely died.
+const historyExportStaleAfter = 15 * time.Minute
+
+const historyExportColumns = `id, user_id, status, row_count, created_at, completed_at, expires_at`
+
+func scanHistoryExport(row pgx.Row, e *HistoryExport) error {
+	return row.Scan(&e.ID, &e.UserID, &e.Status, &e.RowCount, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt)
+}
+
+func (r *Repository) CreateHistoryExport(ctx context.Context, userID int64) (*HistoryExport, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var e HistoryExport
+	err := scanHistoryExport(r.db.QueryRow(ctx, `
+		SELECT `+historyExportColumns+`
+		FROM history_exports
+		WHERE user_id = $1 AND status = 'pending'
+		  AND created_at > NOW() - make_interval(secs => $2)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID, historyExportStaleAfter.Seconds()), &e)
+	if err == nil {
+		return &e, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("find pending history export: %w", err)
+	}
+
+	// expires_at is pushed out again when the file is ready; until then it
+	// only bounds how long an abandoned row lingers.
+	err = scanHistoryExport(r.db.QueryRow(ctx, `
+		INSERT INTO history_exports (user_id, expires_at)
+		VALUES ($1, NOW() + make_interval(secs => $2))
+		RETURNING `+historyExportColumns,
+		userID, HistoryExportTTL.Seconds()), &e)
+	if err != nil {
+		return nil, false, fmt.Errorf("create history export: %w", err)
+	}
+	return &e, true, nil
+}
+
+// ListHistory returns the user's bookings and the games they played that
+// have already started, newest first.
+func (r *Repository) ListHistory(ctx context.Context, userID int64) ([]HistoryEntry, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT 'booking', b.id, v.name, COALESCE(f.sport, v.sport), NULL::text,
+		       b.start_time, b.end_time, b.total_price, b.status::text, NULL::int, NULL::int
+		FROM bookings b
+		JOIN venues v ON v.id = b.venue_id
+		LEFT JOIN facilities f ON f.id = b.facility_id
+		WHERE b.user_id = $1 AND b.start_time < NOW()
+
+		UNION ALL
+
+		SELECT 'game', g.id, v.name, g.sport_type, gp.role,
+		       g.start_time, g.end_time, g.price, g.status, g.score_a, g.score_b
+		FROM game_players gp
+		JOIN games g ON g.id = gp.game_id
+		JOIN venues v ON v.id = g.venue_id
+		WHERE gp.user_id = $1 AND g.start_time < NOW()
+
+		ORDER BY 6 DESC, 2 DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list history: %w", err)
+	}
+	defer rows.Close()
+
+	var out []HistoryEntry
+	for rows.Next() {
+		var h HistoryEntry
+		if err := rows.Scan(&h.Kind, &h.ID, &h.VenueName, &h.Sport, &h.Role,
+			&h.StartTime, &h.EndTime, &h.Price, &h.Status, &h.ScoreA, &h.ScoreB); err != nil {
+			return nil, fmt.Errorf("scan history entry: %w", err)
+		}
+		out = append(out, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) CompleteHistoryExport(ctx context.Context, exportID int64, content []byte, rowCount int) (*HistoryExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var e HistoryExport
+	err := scanHistoryExport(r.db.QueryRow(ctx, `
+		UPDATE history_exports
+		SET status = 'ready', content = $2, row_count = $3,
+		    completed_at = NOW(), expires_at = NOW() + make_interval(secs => $4)
+		WHERE id = $1
+		RETURNING `+historyExportColumns,
+		exportID, content, rowCount, HistoryExportTTL.Seconds()), &e)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("complete history export: %w", err)
+	}
+	return &e, nil
+}
+
+func (r *Repository) GetHistoryExportFile(ctx context.Context, exportID int64) (*HistoryExport, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var e HistoryExport
+	var content []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT `+historyExportColumns+`, content
+		FROM history_exports
+		WHERE id = $1 AND status = 'ready' AND expires_at > NOW()
+	`, exportID).Scan(&e.ID, &e.UserID, &e.Status, &e.RowCount, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt, &content)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("get history export: %w", err)
+	}
+	return &e, content, nil
+}
+
+func (r *Repository) PurgeExpiredHistoryExports(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM history_exports WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("purge history exports: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"khel/internal/database"
 
-	"log"
 	"strings"
 	"time"
 
@@ -38,6 +37,29 @@ type Store interface {
 	ListAdminUsers(ctx context.Context, filters AdminListUsersFilters, limit, offset int) ([]AdminUserRow, int, error)
 	GetAdminUserStats(ctx context.Context, userID int64) (*AdminUserStatsRow, error)
 	AdminCreateUser(ctx context.Context, user *User) (*User, error)
+
+	// RequestDeletion schedules the account for anonymization after grace
+	// and signs it out everywhere. Asking again keeps the first schedule.
+	RequestDeletion(ctx context.Context, userID int64, grace time.Duration) (time.Time, error)
+	// CancelDeletion clears a pending deletion. It reports whether one was pending.
+	CancelDeletion(ctx context.Context, userID int64) (bool, error)
+	ListDueDeletions(ctx context.Context, limit int) ([]int64, error)
+	// Anonymize scrubs the user's personal data right away. It fails with
+	// ErrAlreadyAnonymized when there is nothing left to scrub.
+	Anonymize(ctx context.Context, userID int64) (*AnonymizeResult, error)
+	// AnonymizeDue is Anonymize for the deletion job. It returns ErrNotFound
+	// when the deletion was cancelled in the meantime.
+	AnonymizeDue(ctx context.Context, userID int64) (*AnonymizeResult, error)
+	ExportData(ctx context.Context, userID int64) (*DataExport, error)
+
+	// CreateHistoryExport starts a history export, or returns the one that
+	// is still pending for the user; created reports which.
+	CreateHistoryExport(ctx context.Context, userID int64) (export *HistoryExport, created bool, err error)
+	ListHistory(ctx context.Context, userID int64) ([]HistoryEntry, error)
+	CompleteHistoryExport(ctx context.Context, exportID int64, content []byte, rows int) (*HistoryExport, error)
+	// GetHistoryExportFile returns a ready, unexpired export with its CSV.
+	GetHistoryExportFile(ctx context.Context, exportID int64) (*HistoryExport, []byte, error)
+	PurgeExpiredHistoryExports(ctx context.Context) (int64, error)
 }
 
 type Repository struct {
@@ -88,8 +110,18 @@ SELECT
 
 func (r *Repository) Create(ctx context.Context, tx pgx.Tx, user *User) error {
 
+	// Registering with a guest's phone takes over the guest's row, so the
+	// bookings made from venue widgets stay with the new account.
 	query := `
-	  INSERT INTO users (first_name, last_name, password, email, phone) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at
+	  INSERT INTO users (first_name, last_name, password, email, phone) VALUES ($1, $2, $3, $4, $5)
+	  ON CONFLICT (phone) DO UPDATE
+	  SET first_name = EXCLUDED.first_name,
+	      last_name = EXCLUDED.last_name,
+	      password = EXCLUDED.password,
+	      email = EXCLUDED.email,
+	      is_guest = FALSE
+	  WHERE users.is_guest
+	  RETURNING id, created_at, updated_at, xmax <> 0
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
@@ -97,10 +129,13 @@ func (r *Repository) Create(ctx context.Context, tx pgx.Tx, user *User) error {
 
 	err := tx.QueryRow(
 		ctx, query, user.FirstName, user.LastName, user.Password.hash, user.Email, user.Phone,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.ClaimedGuest)
 
 	if err != nil {
 		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// The phone belongs to a full account.
+			return ErrDuplicatePhoneNumber
 
 		case err.Error() == `pq: duplicate key value violates unique constraint "users_email_key"`:
 			return ErrDuplicateEmail
@@ -175,7 +210,6 @@ func (r *Repository) UpdateUser(ctx context.Context, userID int64, updates map[s
 
 	_, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
-		log.Printf("Failed to update user: %v", err)
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 	return nil
@@ -205,6 +239,7 @@ func (r *Repository) GetByID(ctx context.Context, userID int64) (*User, error) {
 			skill_level,
 			no_of_games,
 			is_active,
+			deletion_scheduled_for,
 			created_at,
 			updated_at
 		FROM users
@@ -227,6 +262,7 @@ func (r *Repository) GetByID(ctx context.Context, userID int64) (*User, error) {
 		&user.SkillLevel,
 		&user.NoOfGames,
 		&user.IsActive,
+		&user.DeletionScheduledFor,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -378,7 +414,7 @@ func (r *Repository) delete(ctx context.Context, tx pgx.Tx, id int64) error {
 
 func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
-		SELECT id, first_name, phone, email, password, created_at FROM users
+		SELECT id, first_name, phone, email, password, deletion_scheduled_for, created_at FROM users
 		WHERE email = $1 AND is_active = true
 	`
 
@@ -392,6 +428,7 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error
 		&user.Phone,
 		&user.Email,
 		&user.Password.hash,
+		&user.DeletionScheduledFor,
 		&user.CreatedAt,
 	)
 	if err != nil {
@@ -30,8 +30,12 @@ type User struct {
 	IsActive             bool           `json:"is_active"`
 	ResetPasswordToken   string         `json:"-"` // Sensitive data
 	ResetPasswordExpires time.Time      `json:"-"` // Internal use only
+	DeletionScheduledFor *time.Time     `json:"deletion_scheduled_for,omitempty"`
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at"`
+	// ClaimedGuest is set by Create when the account took over a guest
+	// user's row instead of adding one.
+	ClaimedGuest bool `json:"-"`
 }
 
 type AdminUserRow struct {
@@ -66,6 +70,127 @@ type AdminUserStatsRow struct {
 	LastGameAt    *time.Time
 }
 
+// DeletionGracePeriod is how long a deletion request can be cancelled by
+// signing in again.
+const DeletionGracePeriod = 14 * 24 * time.Hour
+
+var (
+	ErrDeletionPending   = errors.New("account is scheduled for deletion")
+	ErrAlreadyAnonymized = errors.New("user is already anonymized")
+)
+
+// AnonymizeResult carries what is left to clean up outside the database.
+type AnonymizeResult struct {
+	ProfilePictureURL *string
+}
+
+// DataExport is everything the user can download about themselves.
+type DataExport struct {
+	GeneratedAt    time.Time             `json:"generated_at"`
+	Profile        ExportProfile         `json:"profile"`
+	Bookings       []ExportBooking       `json:"bookings"`
+	Games          []ExportGame          `json:"games"`
+	Orders         []ExportOrder         `json:"orders"`
+	Reviews        []ExportReview        `json:"reviews"`
+	FavoriteVenues []ExportFavoriteVenue `json:"favorite_venues"`
+}
+
+type ExportProfile struct {
+	ID                int64     `json:"id"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	Email             string    `json:"email"`
+	Phone             string    `json:"phone"`
+	ProfilePictureURL *string   `json:"profile_picture_url,omitempty"`
+	SkillLevel        *string   `json:"skill_level,omitempty"`
+	Roles             []string  `json:"roles"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ExportBooking struct {
+	ID            int64     `json:"id"`
+	VenueID       int64     `json:"venue_id"`
+	VenueName     string    `json:"venue_name"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	TotalPrice    int       `json:"total_price"`
+	Status        string    `json:"status"`
+	CustomerName  *string   `json:"customer_name,omitempty"`
+	CustomerPhone *string   `json:"customer_phone,omitempty"`
+	Note          *string   `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ExportGame struct {
+	ID        int64     `json:"id"`
+	VenueName string    `json:"venue_name"`
+	SportType *string   `json:"sport_type,omitempty"`
+	Role      *string   `json:"role,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type ExportOrder struct {
+	ID              int64     `json:"id"`
+	OrderNumber     string    `json:"order_number"`
+	Status          string    `json:"status"`
+	PaymentStatus   string    `json:"payment_status"`
+	TotalCents      int64     `json:"total_cents"`
+	ShippingName    string    `json:"shipping_name"`
+	ShippingPhone   string    `json:"shipping_phone"`
+	ShippingAddress string    `json:"shipping_address"`
+	ShippingCity    string    `json:"shipping_city"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ExportReview struct {
+	ID        int64     `json:"id"`
+	VenueID   int64     `json:"venue_id"`
+	Rating    int       `json:"rating"`
+	Comment   *string   `json:"comment,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportFavoriteVenue struct {
+	VenueID   int64     `json:"venue_id"`
+	VenueName string    `json:"venue_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HistoryExportTTL is how long a finished history export can be downloaded.
+const HistoryExportTTL = 24 * time.Hour
+
+// HistoryExport is a requested CSV of the user's past bookings and games.
+// Status is pending until the background job has built the file, then ready.
+type HistoryExport struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"-"`
+	Status      string     `json:"status"`
+	RowCount    *int       `json:"row_count,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
+
+// HistoryEntry is one past booking or game in a history export. Role and
+// the score are only set for games.
+type HistoryEntry struct {
+	Kind      string // "booking" or "game"
+	ID        int64
+	VenueName string
+	Sport     *string
+	Role      *string
+	StartTime time.Time
+	EndTime   time.Time
+	Price     *int
+	Status    string
+	ScoreA    *int
+	ScoreB    *int
+}
+
 // Password struct to store plain text and hash
 type password struct {
 	text *string `json:"-"` // Hide plaintext password
@@ -0,0 +1,206 @@
+package venueannouncements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const announcementColumns = `
+	id, venue_id, author_id, title, body, publish_at, expires_at, notified_at, created_at, updated_at
+`
+
+func scanAnnouncement(row pgx.Row, a *Announcement) error {
+	return row.Scan(
+		&a.ID, &a.VenueID, &a.AuthorID, &a.Title, &a.Body,
+		&a.PublishAt, &a.ExpiresAt, &a.NotifiedAt, &a.CreatedAt, &a.UpdatedAt,
+	)
+}
+
+func (r *Repository) Create(ctx context.Context, a *Announcement) error {
+	q := `
+		INSERT INTO venue_announcements (venue_id, author_id, title, body, publish_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + announcementColumns
+
+	if err := scanAnnouncement(r.db.QueryRow(ctx, q,
+		a.VenueID, a.AuthorID, a.Title, a.Body, a.PublishAt, a.ExpiresAt,
+	), a); err != nil {
+		return fmt.Errorf("create announcement: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, venueID, id int64, upd UpdateAnnouncement) (*Announcement, error) {
+	// Moving publish_at resets notified_at so a rescheduled post is pushed again
+	// when it goes live.
+	q := `
+		UPDATE venue_announcements
+		SET title       = COALESCE($3, title),
+		    body        = COALESCE($4, body),
+		    publish_at  = COALESCE($5, publish_at),
+		    expires_at  = CASE WHEN $7 THEN NULL ELSE COALESCE($6, expires_at) END,
+		    notified_at = CASE WHEN $5::timestamptz IS NOT NULL AND $5 > NOW() THEN NULL ELSE notified_at END,
+		    updated_at  = NOW()
+		WHERE id = $1 AND venue_id = $2
+		RETURNING ` + announcementColumns
+
+	var a Announcement
+	err := scanAnnouncement(r.db.QueryRow(ctx, q,
+		id, venueID, upd.Title, upd.Body, upd.PublishAt, upd.ExpiresAt, upd.ClearExpiry,
+	), &a)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAnnouncementNotFound
+		}
+		return nil, fmt.Errorf("update announcement: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, venueID, id int64) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM venue_announcements WHERE id = $1 AND venue_id = $2`, id, venueID)
+	if err != nil {
+		return fmt.Errorf("delete announcement: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAnnouncementNotFound
+	}
+	return nil
+}
+
+func (r *Repository) ListActive(ctx context.Context, venueID int64) ([]Announcement, error) {
+	q := `
+		SELECT ` + announcementColumns + `
+		FROM venue_announcements
+		WHERE venue_id = $1
+		  AND publish_at <= NOW()
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY publish_at DESC, id DESC
+		LIMIT 20
+	`
+	rows, err := r.db.Query(ctx, q, venueID)
+	if err != nil {
+		return nil, fmt.Errorf("list active announcements: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Announcement{}
+	for rows.Next() {
+		var a Announcement
+		if err := scanAnnouncement(rows, &a); err != nil {
+			return nil, fmt.Errorf("scan announcement: %w", err)
+		}
+		list = append(list, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) ListByVenue(ctx context.Context, venueID int64, limit, offset int) ([]Announcement, int, error) {
+	q := `
+		SELECT ` + announcementColumns + `, COUNT(*) OVER() AS total_count
+		FROM venue_announcements
+		WHERE venue_id = $1
+		ORDER BY publish_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, q, venueID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list announcements: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Announcement{}
+	var total int
+	for rows.Next() {
+		var a Announcement
+		if err := rows.Scan(
+			&a.ID, &a.VenueID, &a.AuthorID, &a.Title, &a.Body,
+			&a.PublishAt, &a.ExpiresAt, &a.NotifiedAt, &a.CreatedAt, &a.UpdatedAt, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan announcement: %w", err)
+		}
+		list = append(list, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) ClaimPendingNotifications(ctx context.Context, limit int) ([]PendingNotification, error) {
+	q := `
+		WITH due AS (
+			SELECT id
+			FROM venue_announcements
+			WHERE notified_at IS NULL
+			  AND publish_at <= NOW()
+			  AND (expires_at IS NULL OR expires_at > NOW())
+			ORDER BY publish_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE venue_announcements a
+		SET notified_at = NOW()
+		FROM due, venues v
+		WHERE a.id = due.id AND v.id = a.venue_id
+		RETURNING a.id, a.venue_id, a.author_id, a.title, a.body, a.publish_at, a.expires_at,
+		          a.notified_at, a.created_at, a.updated_at, v.name
+	`
+	rows, err := r.db.Query(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim pending announcements: %w", err)
+	}
+	defer rows.Close()
+
+	list := []PendingNotification{}
+	for rows.Next() {
+		var p PendingNotification
+		if err := rows.Scan(
+			&p.ID, &p.VenueID, &p.AuthorID, &p.Title, &p.Body, &p.PublishAt, &p.ExpiresAt,
+			&p.NotifiedAt, &p.CreatedAt, &p.UpdatedAt, &p.VenueName,
+		); err != nil {
+			return nil, fmt.Errorf("scan pending announcement: %w", err)
+		}
+		list = append(list, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) AudienceUserIDs(ctx context.Context, venueID int64) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `SELECT user_id FROM favorite_venues WHERE venue_id = $1`, venueID)
+	if err != nil {
+		return nil, fmt.Errorf("list announcement audience: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return ids, nil
+}
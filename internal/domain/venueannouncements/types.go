@@ -0,0 +1,55 @@
+package venueannouncements
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrAnnouncementNotFound = errors.New("announcement not found")
+
+type Announcement struct {
+	ID         int64      `json:"id"`
+	VenueID    int64      `json:"venue_id"`
+	AuthorID   *int64     `json:"author_id,omitempty"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	PublishAt  time.Time  `json:"publish_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// PendingNotification is a live announcement whose audience has not been pushed yet.
+type PendingNotification struct {
+	Announcement
+	VenueName string
+}
+
+// UpdateAnnouncement holds optional fields; nil means unchanged.
+// ClearExpiry removes expires_at.
+type UpdateAnnouncement struct {
+	Title       *string
+	Body        *string
+	PublishAt   *time.Time
+	ExpiresAt   *time.Time
+	ClearExpiry bool
+}
+
+type Store interface {
+	Create(ctx context.Context, a *Announcement) error
+	Update(ctx context.Context, venueID, id int64, upd UpdateAnnouncement) (*Announcement, error)
+	Delete(ctx context.Context, venueID, id int64) error
+
+	// ListActive returns announcements currently live for the public.
+	ListActive(ctx context.Context, venueID int64) ([]Announcement, error)
+	// ListByVenue returns every announcement, including scheduled and expired, for the owner.
+	ListByVenue(ctx context.Context, venueID int64, limit, offset int) ([]Announcement, int, error)
+
+	// ClaimPendingNotifications marks up to limit live, un-notified
+	// announcements as notified and returns them.
+	ClaimPendingNotifications(ctx context.Context, limit int) ([]PendingNotification, error)
+	// AudienceUserIDs returns users who favorited the venue.
+	AudienceUserIDs(ctx context.Context, venueID int64) ([]int64, error)
+}
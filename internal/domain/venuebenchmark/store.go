@@ -0,0 +1,84 @@
+package venuebenchmark
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Compare(ctx context.Context, venueID int64, radiusKm int) ([]Band, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// Pricing rows are split into the hours they cover so a 10:00-14:00 row
+	// counts towards both morning and afternoon. A facility's own sport wins
+	// over the venue's.
+	rows, err := r.db.Query(ctx, `
+		WITH me AS (
+			SELECT id, location FROM venues WHERE id = $1
+		),
+		hours AS (
+			SELECT vp.venue_id, COALESCE(f.sport, v.sport) AS sport, vp.price,
+			       EXTRACT(HOUR FROM h)::int AS hour
+			FROM venue_pricing vp
+			JOIN facilities f ON f.id = vp.facility_id AND f.is_active
+			JOIN venues v ON v.id = vp.venue_id
+			CROSS JOIN me
+			CROSS JOIN LATERAL generate_series(
+				date '2000-01-01' + vp.start_time,
+				date '2000-01-01' + vp.end_time - interval '1 hour',
+				interval '1 hour'
+			) AS h
+			WHERE v.id = me.id
+			   OR (v.status = 'active' AND ST_DWithin(v.location, me.location, $2 * 1000))
+		),
+		banded AS (
+			SELECT venue_id, sport,
+			       CASE WHEN hour < 12 THEN 'morning'
+			            WHEN hour < 17 THEN 'afternoon'
+			            ELSE 'evening' END AS band,
+			       AVG(price)::float8 AS price
+			FROM hours
+			GROUP BY 1, 2, 3
+		)
+		SELECT b.sport, b.band,
+		       MAX(b.price) FILTER (WHERE b.venue_id = $1),
+		       COUNT(*) FILTER (WHERE b.venue_id <> $1)::int,
+		       percentile_cont(0.25) WITHIN GROUP (ORDER BY b.price) FILTER (WHERE b.venue_id <> $1),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY b.price) FILTER (WHERE b.venue_id <> $1),
+		       percentile_cont(0.75) WITHIN GROUP (ORDER BY b.price) FILTER (WHERE b.venue_id <> $1)
+		FROM banded b
+		WHERE (b.sport, b.band) IN (SELECT sport, band FROM banded WHERE venue_id = $1)
+		GROUP BY b.sport, b.band
+		ORDER BY b.sport, array_position(ARRAY['morning', 'afternoon', 'evening'], b.band)
+	`, venueID, radiusKm)
+	if err != nil {
+		return nil, fmt.Errorf("compare venue prices: %w", err)
+	}
+	defer rows.Close()
+
+	bands := []Band{}
+	for rows.Next() {
+		var b Band
+		if err := rows.Scan(&b.Sport, &b.Band, &b.MyPrice, &b.Venues, &b.P25, &b.Median, &b.P75); err != nil {
+			return nil, fmt.Errorf("scan price band: %w", err)
+		}
+		if b.Venues < MinVenues {
+			b.P25, b.Median, b.P75 = nil, nil, nil
+		}
+		bands = append(bands, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("compare venue prices: %w", err)
+	}
+	return bands, nil
+}
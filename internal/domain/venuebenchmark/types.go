@@ -0,0 +1,53 @@
+package venuebenchmark
+
+import (
+	"context"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 10
+
+const (
+	DefaultRadiusKm = 5
+	MaxRadiusKm     = 25
+
+	// MinVenues is the fewest nearby venues a band needs before its
+	// percentiles are shown, so no single competitor's price can be read
+	// off them.
+	MinVenues = 3
+)
+
+// Time bands, by the hour a priced hour starts.
+const (
+	BandMorning   = "morning"   // before 12:00
+	BandAfternoon = "afternoon" // 12:00 to 16:59
+	BandEvening   = "evening"   // 17:00 onwards
+)
+
+// Band compares the venue's hourly price for one sport and time band with
+// venues nearby. Each venue counts once, at its average hourly price over
+// the band's priced hours across the week.
+type Band struct {
+	Sport   string  `json:"sport"`
+	Band    string  `json:"band"`
+	MyPrice float64 `json:"my_price"`
+	// Venues is how many nearby venues price this sport in this band.
+	Venues int `json:"venues"`
+	// The percentiles are null when Venues is below MinVenues.
+	P25    *float64 `json:"p25"`
+	Median *float64 `json:"median"`
+	P75    *float64 `json:"p75"`
+}
+
+type Benchmark struct {
+	VenueID   int64  `json:"venue_id"`
+	RadiusKm  int    `json:"radius_km"`
+	MinVenues int    `json:"min_venues"`
+	Bands     []Band `json:"bands"`
+}
+
+type Store interface {
+	// Compare returns a Band for every sport and time band the venue has
+	// active pricing in, against active venues within radiusKm.
+	Compare(ctx context.Context, venueID int64, radiusKm int) ([]Band, error)
+}
@@ -0,0 +1,30 @@
+package venuecalendar
+
+import "strings"
+
+// Build lays hours out as days of 24 cells.
+func Build(hours []Hour) []Day {
+	days := []Day{}
+	for _, h := range hours {
+		date := h.Date.Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, Day{
+				Date:      date,
+				DayOfWeek: strings.ToLower(h.Date.Weekday().String()),
+				Hours:     make([]Cell, 0, 24),
+			})
+		}
+		cell := Cell{
+			Hour:      h.Hour,
+			State:     h.State(),
+			Confirmed: h.Confirmed,
+			Pending:   h.Pending,
+		}
+		if cell.State != StateClosed {
+			cell.Free = max(h.Open-h.Confirmed-h.Pending, 0)
+		}
+		day := &days[len(days)-1]
+		day.Hours = append(day.Hours, cell)
+	}
+	return days
+}
@@ -0,0 +1,85 @@
+package venuecalendar
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Hours(ctx context.Context, venueID int64, facilityID *int64, from, to time.Time) ([]Hour, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// from is a local midnight, so one-hour steps land on local hours even
+	// with Nepal's +05:45 offset.
+	rows, err := r.db.Query(ctx, `
+		WITH hours AS (
+			SELECT h AS hour_start, h AT TIME ZONE 'Asia/Kathmandu' AS local_start
+			FROM generate_series($3::timestamptz, $4::timestamptz - interval '1 hour', interval '1 hour') AS h
+		),
+		fac AS (
+			SELECT id FROM facilities
+			WHERE venue_id = $1 AND is_active
+			  AND ($2::bigint IS NULL OR id = $2)
+		),
+		busy AS (
+			SELECT b.facility_id, b.start_time, b.end_time, b.status IN ('confirmed', 'done') AS confirmed
+			FROM bookings b
+			JOIN fac f ON f.id = b.facility_id
+			WHERE b.status IN ('pending', 'confirmed', 'done')
+			  AND b.start_time < $4 AND b.end_time > $3
+		)
+		SELECT
+			h.local_start::date,
+			EXTRACT(HOUR FROM h.local_start)::int,
+			(SELECT COUNT(*) FROM fac f WHERE EXISTS (
+				SELECT 1 FROM venue_pricing vp
+				WHERE vp.venue_id = $1
+				  AND vp.facility_id = f.id
+				  AND vp.day_of_week = lower(to_char(h.local_start, 'FMDay'))
+				  AND vp.start_time <= h.local_start::time
+				  AND vp.end_time > h.local_start::time
+			))::int,
+			COUNT(DISTINCT b.facility_id) FILTER (WHERE b.confirmed)::int,
+			(COUNT(DISTINCT b.facility_id) - COUNT(DISTINCT b.facility_id) FILTER (WHERE b.confirmed))::int,
+			EXISTS (
+				SELECT 1 FROM venue_closures c
+				WHERE c.venue_id = $1
+				  AND c.start_time < h.hour_start + interval '1 hour'
+				  AND c.end_time > h.hour_start
+			)
+		FROM hours h
+		LEFT JOIN busy b
+		       ON b.start_time < h.hour_start + interval '1 hour'
+		      AND b.end_time > h.hour_start
+		GROUP BY h.hour_start, h.local_start
+		ORDER BY h.hour_start
+	`, venueID, facilityID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("venue calendar: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Hour{}
+	for rows.Next() {
+		var h Hour
+		if err := rows.Scan(&h.Date, &h.Hour, &h.Open, &h.Confirmed, &h.Pending, &h.Closure); err != nil {
+			return nil, fmt.Errorf("scan venue calendar: %w", err)
+		}
+		list = append(list, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
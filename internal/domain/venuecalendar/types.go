@@ -0,0 +1,85 @@
+package venuecalendar
+
+import (
+	"context"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 10
+
+const (
+	DefaultDays = 7
+	MaxDays     = 31
+)
+
+// States of an hour, from the point of view of someone trying to book it.
+const (
+	// StateFree has at least one open facility nobody has booked or
+	// requested.
+	StateFree = "free"
+	// StatePending has no free facility left, but some only hold requests
+	// the owner hasn't answered.
+	StatePending = "pending"
+	// StateConfirmed is fully booked.
+	StateConfirmed = "confirmed"
+	// StateClosed is outside pricing hours or under an emergency closure.
+	StateClosed = "closed"
+)
+
+// Cell is one local hour of the calendar. The counts are facilities.
+type Cell struct {
+	Hour      int    `json:"hour"` // 0-23, local
+	State     string `json:"state"`
+	Confirmed int    `json:"confirmed"`
+	Pending   int    `json:"pending"`
+	Free      int    `json:"free"`
+}
+
+type Day struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	DayOfWeek string `json:"day_of_week"`
+	Hours     []Cell `json:"hours"`
+}
+
+type Calendar struct {
+	VenueID    int64  `json:"venue_id"`
+	FacilityID *int64 `json:"facility_id,omitempty"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Days       []Day  `json:"days"`
+}
+
+// Hour is a row of Hours: how the venue's facilities stand in one local
+// hour.
+type Hour struct {
+	Date time.Time // local date
+	Hour int       // 0-23, local
+	// Open counts the active facilities inside pricing hours.
+	Open      int
+	Confirmed int
+	// Pending counts facilities with a request but no confirmed booking.
+	Pending int
+	// Closure is true under an emergency closure.
+	Closure bool
+}
+
+// State sums the hour up for the calendar.
+func (h Hour) State() string {
+	switch {
+	case h.Closure || h.Open == 0:
+		return StateClosed
+	case h.Confirmed+h.Pending < h.Open:
+		return StateFree
+	case h.Pending > 0:
+		return StatePending
+	default:
+		return StateConfirmed
+	}
+}
+
+type Store interface {
+	// Hours returns one row per local hour in [from, to) for the venue, or
+	// one facility of it when facilityID is set. from must be a local
+	// midnight.
+	Hours(ctx context.Context, venueID int64, facilityID *int64, from, to time.Time) ([]Hour, error)
+}
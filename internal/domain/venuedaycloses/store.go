@@ -0,0 +1,186 @@
+package venuedaycloses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"khel/internal/database"
+	"khel/internal/infra/dbx"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// summaryQuery adds up the bookings that start on a Nepal date. A venue
+// no-show dispute that wasn't dismissed turns a confirmed or done booking
+// into a no-show.
+const summaryQuery = `
+	WITH day AS (
+		SELECT b.id, b.status::text AS status, b.end_time, b.final_amount, b.payment_method,
+		       EXISTS (
+				SELECT 1 FROM booking_disputes d
+				WHERE d.booking_id = b.id
+				  AND d.reason = 'no_show'
+				  AND d.opener_role = 'venue'
+				  AND d.resolution IS DISTINCT FROM 'dismissed'
+		       ) AS no_show
+		FROM bookings b
+		WHERE b.venue_id = $1
+		  AND (b.start_time AT TIME ZONE 'Asia/Kathmandu')::date = $2::date
+	)
+	SELECT
+		COUNT(*) FILTER (WHERE status IN ('confirmed', 'done') AND NOT no_show),
+		COUNT(*) FILTER (WHERE status IN ('confirmed', 'done') AND no_show),
+		COUNT(*) FILTER (WHERE status = 'canceled'),
+		COUNT(*) FILTER (WHERE status = 'pending' OR (status = 'confirmed' AND end_time > NOW())),
+		COALESCE(SUM(final_amount) FILTER (WHERE status = 'done' AND payment_method = 'cash'), 0)::INT,
+		COALESCE(SUM(final_amount) FILTER (WHERE status = 'done' AND payment_method <> 'cash'), 0)::INT,
+		(
+			SELECT COALESCE(SUM(sh.amount), 0)::INT
+			FROM payment_splits s
+			JOIN payment_split_shares sh ON sh.split_id = s.id
+			WHERE s.booking_id IN (SELECT id FROM day) AND sh.status = 'paid'
+		)
+	FROM day
+`
+
+func summarize(ctx context.Context, q dbx.Querier, venueID int64, date time.Time) (*Summary, error) {
+	s := Summary{VenueID: venueID, BusinessDate: date.Format("2006-01-02")}
+	err := q.QueryRow(ctx, summaryQuery, venueID, s.BusinessDate).Scan(
+		&s.BookingsHonored, &s.NoShows, &s.Canceled, &s.Unfinished,
+		&s.CashAmount, &s.CounterOtherAmount, &s.OnlineAmount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summarize day: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *Repository) Summarize(ctx context.Context, venueID int64, date time.Time) (*Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return summarize(ctx, r.db, venueID, date)
+}
+
+const dayCloseColumns = `id, venue_id, business_date::text, bookings_honored, no_shows, canceled,
+	cash_amount, counter_other_amount, online_amount, note, closed_by, closed_at,
+	reopened_at, reopened_by, reopen_reason`
+
+func scanDayClose(row pgx.Row, c *DayClose) error {
+	return row.Scan(
+		&c.ID, &c.VenueID, &c.BusinessDate, &c.BookingsHonored, &c.NoShows, &c.Canceled,
+		&c.CashAmount, &c.CounterOtherAmount, &c.OnlineAmount, &c.Note, &c.ClosedBy, &c.ClosedAt,
+		&c.ReopenedAt, &c.ReopenedBy, &c.ReopenReason,
+	)
+}
+
+func (r *Repository) Close(ctx context.Context, venueID int64, date time.Time, closedBy int64, note *string) (*DayClose, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var c DayClose
+	err := database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		// Holding the day's bookings keeps the figures from moving between
+		// the summary and the insert; once the row exists the trigger
+		// takes over.
+		if _, err := tx.Exec(ctx, `
+			SELECT 1 FROM bookings
+			WHERE venue_id = $1
+			  AND (start_time AT TIME ZONE 'Asia/Kathmandu')::date = $2::date
+			FOR UPDATE
+		`, venueID, date.Format("2006-01-02")); err != nil {
+			return fmt.Errorf("lock day bookings: %w", err)
+		}
+
+		s, err := summarize(ctx, tx, venueID, date)
+		if err != nil {
+			return err
+		}
+		if s.Unfinished > 0 {
+			return ErrDayNotOver
+		}
+
+		err = scanDayClose(tx.QueryRow(ctx, `
+			INSERT INTO venue_day_closes (
+				venue_id, business_date, bookings_honored, no_shows, canceled,
+				cash_amount, counter_other_amount, online_amount, note, closed_by
+			)
+			VALUES ($1, $2::date, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING `+dayCloseColumns,
+			venueID, s.BusinessDate, s.BookingsHonored, s.NoShows, s.Canceled,
+			s.CashAmount, s.CounterOtherAmount, s.OnlineAmount, note, closedBy), &c)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrAlreadyClosed
+			}
+			return fmt.Errorf("insert day close: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *Repository) ListByVenue(ctx context.Context, venueID int64, limit int) ([]DayClose, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+dayCloseColumns+`
+		FROM venue_day_closes
+		WHERE venue_id = $1
+		ORDER BY business_date DESC, id DESC
+		LIMIT $2
+	`, venueID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list day closes: %w", err)
+	}
+	defer rows.Close()
+
+	list := []DayClose{}
+	for rows.Next() {
+		var c DayClose
+		if err := scanDayClose(rows, &c); err != nil {
+			return nil, fmt.Errorf("scan day close: %w", err)
+		}
+		list = append(list, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
+
+func (r *Repository) Reopen(ctx context.Context, venueID int64, date time.Time, adminID int64, reason string) (*DayClose, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var c DayClose
+	err := scanDayClose(r.db.QueryRow(ctx, `
+		UPDATE venue_day_closes
+		SET reopened_at = NOW(), reopened_by = $3, reopen_reason = $4
+		WHERE venue_id = $1 AND business_date = $2::date AND reopened_at IS NULL
+		RETURNING `+dayCloseColumns,
+		venueID, date.Format("2006-01-02"), adminID, reason), &c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("reopen day: %w", err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,84 @@
+package venuedaycloses
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const QueryTimeoutDuration = time.Second * 10
+
+var (
+	ErrNotFound      = errors.New("day close not found")
+	ErrAlreadyClosed = errors.New("that day is already closed")
+	ErrDayNotOver    = errors.New("the day still has bookings that haven't finished or been answered")
+	// ErrDayClosed is what a write to a booking on a closed day turns into.
+	ErrDayClosed = errors.New("that business day has been closed; an admin must reopen it before its bookings can change")
+)
+
+// closedDaySQLState is raised by the bookings_guard_closed_day trigger.
+const closedDaySQLState = "KH001"
+
+// IsClosedDayWrite reports whether err, from any booking write, was
+// refused because the booking's day is closed.
+func IsClosedDayWrite(err error) bool {
+	if errors.Is(err, ErrDayClosed) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == closedDaySQLState
+}
+
+// Summary is what a business day (Nepal date) at a venue came to. Cash and
+// counter amounts are what was taken at checkout; online is what players
+// paid through the app.
+type Summary struct {
+	VenueID         int64  `json:"venue_id"`
+	BusinessDate    string `json:"business_date"`
+	BookingsHonored int    `json:"bookings_honored"`
+	NoShows         int    `json:"no_shows"`
+	Canceled        int    `json:"canceled"`
+	// Unfinished counts bookings still pending or not yet over; the day
+	// can't be closed while it is above zero.
+	Unfinished         int `json:"unfinished"`
+	CashAmount         int `json:"cash_amount"`
+	CounterOtherAmount int `json:"counter_other_amount"` // eSewa, Khalti and other taken at the counter
+	OnlineAmount       int `json:"online_amount"`
+}
+
+// DayClose is a summary the owner confirmed. It is never edited; reopening
+// stamps ReopenedAt and a later close adds a new row.
+type DayClose struct {
+	ID                 int64      `json:"id"`
+	VenueID            int64      `json:"venue_id"`
+	BusinessDate       string     `json:"business_date"`
+	BookingsHonored    int        `json:"bookings_honored"`
+	NoShows            int        `json:"no_shows"`
+	Canceled           int        `json:"canceled"`
+	CashAmount         int        `json:"cash_amount"`
+	CounterOtherAmount int        `json:"counter_other_amount"`
+	OnlineAmount       int        `json:"online_amount"`
+	Note               *string    `json:"note,omitempty"`
+	ClosedBy           *int64     `json:"closed_by,omitempty"`
+	ClosedAt           time.Time  `json:"closed_at"`
+	ReopenedAt         *time.Time `json:"reopened_at,omitempty"`
+	ReopenedBy         *int64     `json:"reopened_by,omitempty"`
+	ReopenReason       *string    `json:"reopen_reason,omitempty"`
+}
+
+type Store interface {
+	// Summarize works out the day's figures without saving anything.
+	Summarize(ctx context.Context, venueID int64, date time.Time) (*Summary, error)
+	// Close summarizes the day and records it in one transaction. It
+	// fails with ErrDayNotOver while bookings are unfinished and with
+	// ErrAlreadyClosed when a standing close exists.
+	Close(ctx context.Context, venueID int64, date time.Time, closedBy int64, note *string) (*DayClose, error)
+	// ListByVenue returns the venue's closes, reopened ones included,
+	// newest day first.
+	ListByVenue(ctx context.Context, venueID int64, limit int) ([]DayClose, error)
+	// Reopen is the admin override that lets a closed day's bookings
+	// change again.
+	Reopen(ctx context.Context, venueID int64, date time.Time, adminID int64, reason string) (*DayClose, error)
+}
@@ -42,7 +42,15 @@ func (r *Repository) GetVenueEarnings(ctx context.Context, venueID int64, filter
 				GREATEST(
 					COALESCE(b.final_amount, b.paid_amount, b.total_price, 0) - COALESCE(b.total_price, 0),
 					0
-				) AS inventory_earning
+				) AS inventory_earning,
+
+				-- platform commission is charged on the slot price at the rate
+				-- copied onto the booking when it was confirmed
+				ROUND(
+					COALESCE(b.total_price, 0)
+					* COALESCE(b.commission_percent, commission_percent_at(b.venue_id, b.created_at))
+					/ 100.0
+				) AS commission
 
 			FROM bookings b
 			WHERE b.venue_id = $1
@@ -69,7 +77,9 @@ func (r *Repository) GetVenueEarnings(ctx context.Context, venueID int64, filter
 			COALESCE(SUM(total_earning) FILTER (
 				WHERE payment_method IS NULL
 				   OR LOWER(COALESCE(payment_method, '')) NOT IN ('cash', 'online', 'card', 'stripe', 'esewa', 'khalti')
-			), 0)::INT AS other_earning
+			), 0)::INT AS other_earning,
+
+			COALESCE(SUM(commission), 0)::INT AS commission
 
 		FROM filtered_bookings;
 	`
@@ -82,11 +92,13 @@ func (r *Repository) GetVenueEarnings(ctx context.Context, venueID int64, filter
 		&result.Summary.CashEarning,
 		&result.Summary.OnlineEarning,
 		&result.Summary.OtherEarning,
+		&result.Summary.Commission,
 	)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	result.Summary.NetEarning = result.Summary.TotalEarning - result.Summary.Commission
 	result.Summary.Period = string(filter.Period)
 	result.Summary.StartDate = filter.StartDate
 	result.Summary.EndDate = filter.EndDate
@@ -45,6 +45,12 @@ func IsValidPeriod(s string) bool {
 // TotalEarning:
 //   - Comes from bookings.final_amount
 //   - This is the real total amount venue owner earned from booking + inventory
+//
+// Commission:
+//   - Platform commission on SlotEarning, at each booking's own rate
+//
+// NetEarning:
+//   - TotalEarning - Commission
 type VenueEarningSummary struct {
 	Period string `json:"period"`
 
@@ -60,6 +66,9 @@ type VenueEarningSummary struct {
 	CashEarning   int `json:"cash_earning"`
 	OnlineEarning int `json:"online_earning"`
 	OtherEarning  int `json:"other_earning"`
+
+	Commission int `json:"commission"`
+	NetEarning int `json:"net_earning"`
 }
 
 // DailyEarning is useful for frontend chart/list.
@@ -0,0 +1,80 @@
+package venueforecast
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// z80 is the normal quantile for an 80% two-sided band.
+const z80 = 1.2816
+
+// Build turns history into next week's forecast. Observations must cover
+// whole weeks ending the day before start; capacity is how many facilities
+// the history covers. Hours that were never bookable are left out.
+func Build(obs []Observation, capacity int, start time.Time) []SlotForecast {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	type key struct {
+		weekday time.Weekday
+		hour    int
+	}
+	series := map[key][]float64{}
+	open := map[key]bool{}
+	for _, o := range obs {
+		k := key{o.Date.Weekday(), o.Hour}
+		occ := math.Min(o.Minute/float64(60*capacity), 1)
+		series[k] = append(series[k], occ)
+		if o.Open {
+			open[k] = true
+		}
+	}
+
+	slots := []SlotForecast{}
+	for d := 0; d < 7; d++ {
+		day := start.AddDate(0, 0, d)
+		for hour := 0; hour < 24; hour++ {
+			k := key{day.Weekday(), hour}
+			values := series[k]
+			if !open[k] || len(values) == 0 {
+				continue
+			}
+
+			mean, sd := meanStdDev(values)
+			slots = append(slots, SlotForecast{
+				Date:          day.Format("2006-01-02"),
+				DayOfWeek:     strings.ToLower(day.Weekday().String()),
+				Hour:          hour,
+				Forecast:      round(mean),
+				Lower:         round(math.Max(mean-z80*sd, 0)),
+				Upper:         round(math.Min(mean+z80*sd, 1)),
+				SeasonalNaive: round(values[len(values)-1]),
+				WeeksObserved: len(values),
+			})
+		}
+	}
+	return slots
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)-1))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,72 @@
+package venueforecast
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Observations(ctx context.Context, venueID int64, facilityID *int64, from, to time.Time) ([]Observation, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	// from is a local midnight, so one-hour steps land on local hours even
+	// with Nepal's +05:45 offset.
+	rows, err := r.db.Query(ctx, `
+		WITH hours AS (
+			SELECT h AS hour_start, h AT TIME ZONE 'Asia/Kathmandu' AS local_start
+			FROM generate_series($3::timestamptz, $4::timestamptz - interval '1 hour', interval '1 hour') AS h
+		)
+		SELECT
+			h.local_start::date,
+			EXTRACT(HOUR FROM h.local_start)::int,
+			COALESCE(SUM(
+				EXTRACT(EPOCH FROM LEAST(b.end_time, h.hour_start + interval '1 hour') - GREATEST(b.start_time, h.hour_start))
+			) / 60, 0)::float8,
+			EXISTS (
+				SELECT 1
+				FROM venue_pricing vp
+				WHERE vp.venue_id = $1
+				  AND ($2::bigint IS NULL OR vp.facility_id = $2)
+				  AND vp.day_of_week = lower(to_char(h.local_start, 'FMDay'))
+				  AND vp.start_time <= h.local_start::time
+				  AND vp.end_time > h.local_start::time
+			)
+		FROM hours h
+		LEFT JOIN bookings b
+		       ON b.venue_id = $1
+		      AND ($2::bigint IS NULL OR b.facility_id = $2)
+		      AND b.status IN ('confirmed', 'done')
+		      AND b.start_time < h.hour_start + interval '1 hour'
+		      AND b.end_time > h.hour_start
+		GROUP BY h.hour_start, h.local_start
+		ORDER BY h.hour_start
+	`, venueID, facilityID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("venue occupancy: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Observation{}
+	for rows.Next() {
+		var o Observation
+		if err := rows.Scan(&o.Date, &o.Hour, &o.Minute, &o.Open); err != nil {
+			return nil, fmt.Errorf("scan occupancy: %w", err)
+		}
+		list = append(list, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, nil
+}
@@ -0,0 +1,53 @@
+package venueforecast
+
+import (
+	"context"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 10
+
+const (
+	DefaultWeeks = 8
+	MaxWeeks     = 26
+)
+
+// Observation is how busy one local hour was in the history window.
+type Observation struct {
+	Date   time.Time // local date
+	Hour   int       // 0-23, local
+	Minute float64   // booked facility-minutes in the hour
+	// Open is true when the hour was inside pricing hours, i.e. bookable.
+	Open bool
+}
+
+// SlotForecast projects occupancy (0-1) for one hour of next week.
+type SlotForecast struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	DayOfWeek string `json:"day_of_week"`
+	Hour      int    `json:"hour"`
+	// Forecast is the moving average of the same hour over past weeks.
+	Forecast float64 `json:"forecast"`
+	// Lower and Upper bound an 80% band around Forecast.
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	// SeasonalNaive is the same hour last week.
+	SeasonalNaive float64 `json:"seasonal_naive"`
+	WeeksObserved int     `json:"weeks_observed"`
+}
+
+type Forecast struct {
+	VenueID    int64          `json:"venue_id"`
+	FacilityID *int64         `json:"facility_id,omitempty"`
+	Weeks      int            `json:"weeks"`
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Slots      []SlotForecast `json:"slots"`
+}
+
+type Store interface {
+	// Observations returns one row per local hour in [from, to) for the
+	// venue, or one facility of it when facilityID is set. Confirmed and
+	// completed bookings count as occupied.
+	Observations(ctx context.Context, venueID int64, facilityID *int64, from, to time.Time) ([]Observation, error)
+}
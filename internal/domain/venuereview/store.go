@@ -2,18 +2,30 @@ package venuereviews
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"khel/internal/database"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Store interface {
+	// CreateReview publishes the review, or holds it when it arrives with a
+	// HoldReason (content moderation flagged it) or is part of a burst of
+	// 1-star reviews from new accounts. It fails with
+	// ErrReviewCooldown when the user reviewed the venue in the last 90 days.
 	CreateReview(context.Context, *Review) error
 	GetReviews(context.Context, int64) ([]Review, error)
 	DeleteReview(context.Context, int64, int64) error
 	GetReviewStats(context.Context, int64) (int, float64, error)
 	IsReviewOwner(ctx context.Context, reviewID int64, userID int64) (bool, error)
-	HasReview(ctx context.Context, venueID, userID int64) (bool, error)
+
+	ListForModeration(ctx context.Context, status string, limit, offset int) ([]ModerationReview, int, error)
+	// Moderate publishes or rejects a held review.
+	Moderate(ctx context.Context, reviewID, moderatorID int64, status string) error
 }
 
 type Repository struct {
@@ -25,26 +37,108 @@ func NewRepository(db *pgxpool.Pool) Store {
 }
 
 func (r *Repository) CreateReview(ctx context.Context, review *Review) error {
-	query := `
-        INSERT INTO reviews (venue_id, user_id, rating, comment)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id, created_at, updated_at
-    `
-	return r.db.QueryRow(ctx, query,
-		review.VenueID,
-		review.UserID,
-		review.Rating,
-		review.Comment,
-	).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+	return database.WithTx(r.db, ctx, func(tx pgx.Tx) error {
+		var accountCreated time.Time
+		if err := tx.QueryRow(ctx, `SELECT created_at FROM users WHERE id = $1`, review.UserID).Scan(&accountCreated); err != nil {
+			return fmt.Errorf("get reviewer: %w", err)
+		}
+
+		review.Status = StatusPublished
+		if review.HoldReason != nil {
+			review.Status = StatusHeld
+		} else if review.Rating == 1 && time.Since(accountCreated) < NewAccountAge {
+			held, err := holdBurst(ctx, tx, review.VenueID)
+			if err != nil {
+				return err
+			}
+			if held {
+				reason := HoldReasonBurst
+				review.Status = StatusHeld
+				review.HoldReason = &reason
+			}
+		}
+
+		// A review written after the cooldown replaces the earlier one.
+		if _, err := tx.Exec(ctx, `
+			UPDATE reviews
+			SET status = 'replaced', updated_at = NOW()
+			WHERE venue_id = $1 AND user_id = $2 AND status = 'published'
+		`, review.VenueID, review.UserID); err != nil {
+			return fmt.Errorf("replace earlier review: %w", err)
+		}
+
+		query := `
+			INSERT INTO reviews (venue_id, user_id, rating, comment, status, hold_reason)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, updated_at
+		`
+		err := tx.QueryRow(ctx, query,
+			review.VenueID,
+			review.UserID,
+			review.Rating,
+			review.Comment,
+			review.Status,
+			review.HoldReason,
+		).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23P01" {
+				return ErrReviewCooldown
+			}
+			return fmt.Errorf("create review: %w", err)
+		}
+		return nil
+	})
+}
+
+// holdBurst reports whether one more 1-star review from a new account would
+// make a burst on the venue, and if so holds the published ones already in
+// it. The per-venue lock keeps concurrent reviews from each missing the
+// other.
+func holdBurst(ctx context.Context, tx pgx.Tx, venueID int64) (bool, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('review_burst'), $1::int)`, venueID); err != nil {
+		return false, fmt.Errorf("lock venue reviews: %w", err)
+	}
+
+	burst := `
+		SELECT r.id
+		FROM reviews r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.venue_id = $1
+		  AND r.rating = 1
+		  AND r.status IN ('published', 'held')
+		  AND r.created_at > NOW() - make_interval(mins => $2)
+		  AND u.created_at > NOW() - make_interval(hours => $3)
+	`
+	rows, err := tx.Query(ctx, burst, venueID, int(BurstWindow/time.Minute), int(NewAccountAge/time.Hour))
+	if err != nil {
+		return false, fmt.Errorf("check review burst: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return false, fmt.Errorf("check review burst: %w", err)
+	}
+	if len(ids)+1 < BurstThreshold {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE reviews
+		SET status = 'held', hold_reason = $2, updated_at = NOW()
+		WHERE id = ANY($1) AND status = 'published'
+	`, ids, HoldReasonBurst); err != nil {
+		return false, fmt.Errorf("hold review burst: %w", err)
+	}
+	return true, nil
 }
 
 func (r *Repository) GetReviews(ctx context.Context, venueID int64) ([]Review, error) {
 	query := `
-        SELECT vr.id, vr.venue_id, vr.user_id, vr.rating, vr.comment, 
+        SELECT vr.id, vr.venue_id, vr.user_id, vr.rating, vr.comment, vr.status,
                vr.created_at, vr.updated_at, u.first_name, u.profile_picture_url
         FROM reviews vr
         JOIN users u ON u.id = vr.user_id
-        WHERE vr.venue_id = $1
+        WHERE vr.venue_id = $1 AND vr.status = 'published'
         ORDER BY vr.created_at DESC
     `
 	rows, err := r.db.Query(ctx, query, venueID)
@@ -62,6 +156,7 @@ func (r *Repository) GetReviews(ctx context.Context, venueID int64) ([]Review, e
 			&review.UserID,
 			&review.Rating,
 			&review.Comment,
+			&review.Status,
 			&review.CreatedAt,
 			&review.UpdatedAt,
 			&review.UserName,
@@ -78,8 +173,9 @@ func (r *Repository) GetReviews(ctx context.Context, venueID int64) ([]Review, e
 
 func (r *Repository) DeleteReview(ctx context.Context, reviewID, userID int64) error {
 	query := `
-        DELETE FROM reviews 
-        WHERE id = $1 AND user_id = $2
+        UPDATE reviews
+        SET status = 'deleted', updated_at = NOW()
+        WHERE id = $1 AND user_id = $2 AND status <> 'deleted'
     `
 	result, err := r.db.Exec(ctx, query, reviewID, userID)
 	if err != nil {
@@ -100,7 +196,7 @@ func (r *Repository) GetReviewStats(ctx context.Context, venueID int64) (total i
             COUNT(id) as total_reviews,
             COALESCE(AVG(rating), 0) as average_rating
         FROM reviews
-        WHERE venue_id = $1
+        WHERE venue_id = $1 AND status = 'published'
     `
 	err = r.db.QueryRow(ctx, query, venueID).Scan(&total, &average)
 	return total, average, err
@@ -116,15 +212,63 @@ func (r *Repository) IsReviewOwner(ctx context.Context, reviewID int64, userID i
 	return reviewUserID == userID, nil
 }
 
-// HasReview returns true if a review by this user on this venue already exists.
-func (r *Repository) HasReview(ctx context.Context, venueID, userID int64) (bool, error) {
-	var exists bool
+func (r *Repository) ListForModeration(ctx context.Context, status string, limit, offset int) ([]ModerationReview, int, error) {
 	query := `
-        SELECT EXISTS (
-          SELECT 1 FROM reviews
-          WHERE venue_id = $1 AND user_id = $2
-        )
-    `
-	err := r.db.QueryRow(ctx, query, venueID, userID).Scan(&exists)
-	return exists, err
+		SELECT r.id, r.venue_id, r.user_id, r.rating, r.comment, r.status, r.hold_reason,
+		       r.created_at, r.updated_at, u.first_name, u.profile_picture_url,
+		       v.name, u.email, u.created_at, r.moderated_by, r.moderated_at,
+		       COUNT(*) OVER() AS total_count
+		FROM reviews r
+		JOIN users u ON u.id = r.user_id
+		JOIN venues v ON v.id = r.venue_id
+		WHERE ($1::text = '' OR r.status = $1)
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list reviews for moderation: %w", err)
+	}
+	defer rows.Close()
+
+	list := []ModerationReview{}
+	var total int
+	for rows.Next() {
+		var m ModerationReview
+		if err := rows.Scan(
+			&m.ID, &m.VenueID, &m.UserID, &m.Rating, &m.Comment, &m.Status, &m.HoldReason,
+			&m.CreatedAt, &m.UpdatedAt, &m.UserName, &m.AvatarURL,
+			&m.VenueName, &m.UserEmail, &m.AccountCreatedAt, &m.ModeratedBy, &m.ModeratedAt,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan review: %w", err)
+		}
+		list = append(list, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) Moderate(ctx context.Context, reviewID, moderatorID int64, status string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE reviews
+		SET status = $2, moderated_by = $3, moderated_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'held'
+	`, reviewID, status, moderatorID)
+	if err != nil {
+		return fmt.Errorf("moderate review: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		var exists bool
+		if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM reviews WHERE id = $1)`, reviewID).Scan(&exists); err != nil {
+			return fmt.Errorf("moderate review: %w", err)
+		}
+		if !exists {
+			return ErrReviewNotFound
+		}
+		return ErrReviewNotHeld
+	}
+	return nil
 }
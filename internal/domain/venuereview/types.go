@@ -1,17 +1,61 @@
 package venuereviews
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// Review statuses. Only published reviews are shown and count towards a
+// venue's rating.
+const (
+	StatusPublished = "published"
+	StatusHeld      = "held"
+	StatusRejected  = "rejected"
+	StatusReplaced  = "replaced"
+	StatusDeleted   = "deleted"
+)
+
+// Review-bombing protection: a 1-star review from an account younger than
+// NewAccountAge is held for moderation when it makes BurstThreshold such
+// reviews on the same venue within BurstWindow. The earlier ones in the
+// burst are held too.
+const (
+	NewAccountAge  = 7 * 24 * time.Hour
+	BurstWindow    = time.Hour
+	BurstThreshold = 3
+)
+
+const HoldReasonBurst = "burst of 1-star reviews from new accounts"
+
+var (
+	ErrReviewCooldown = errors.New("you can review a venue once every 90 days")
+	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewNotHeld  = errors.New("review is not held for moderation")
+)
 
 type Review struct {
-	ID        int64     `json:"id"`
-	VenueID   int64     `json:"venue_id"`
-	UserID    int64     `json:"user_id"`
-	Rating    int       `json:"rating"` // 1-5
-	Comment   string    `json:"comment"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         int64     `json:"id"`
+	VenueID    int64     `json:"venue_id"`
+	UserID     int64     `json:"user_id"`
+	Rating     int       `json:"rating"` // 1-5
+	Comment    string    `json:"comment"`
+	Status     string    `json:"status"`
+	HoldReason *string   `json:"hold_reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 
 	// Joined fields
 	UserName  string  `json:"user_name,omitempty"`
 	AvatarURL *string `json:"avatar_url,omitempty"`
 }
+
+// ModerationReview is a review as moderators see it, with what they need to
+// judge it.
+type ModerationReview struct {
+	Review
+	VenueName        string     `json:"venue_name"`
+	UserEmail        string     `json:"user_email"`
+	AccountCreatedAt time.Time  `json:"account_created_at"`
+	ModeratedBy      *int64     `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+}
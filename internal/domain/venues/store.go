@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"khel/internal/images"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -50,16 +53,20 @@ func (r *Repository) Create(ctx context.Context, venue *Venue) error {
     INSERT INTO venues (
       owner_id, name, address, location,
       description, amenities, open_time,
-      image_urls, sport, phone_number
+      image_urls, sport, phone_number, status
     ) VALUES (
       $1, $2, $3,
       ST_SetSRID(ST_MakePoint($4, $5), 4326),
-      $6, $7, $8, $9, $10, $11
+      $6, $7, $8, $9, $10, $11, $12::venue_status
     )
     RETURNING id, created_at, updated_at
     `
 
-	// Build the args array—make absolutely sure you have exactly 11 items here:
+	if venue.Status == "" {
+		venue.Status = VenueStatusPendingReview
+	}
+
+	// Build the args array—make absolutely sure you have exactly 12 items here:
 	args := []interface{}{
 		venue.OwnerID,
 		venue.Name,
@@ -72,6 +79,7 @@ func (r *Repository) Create(ctx context.Context, venue *Venue) error {
 		[]string{}, // initial empty image_urls
 		venue.Sport,
 		venue.PhoneNumber,
+		venue.Status,
 	}
 	row := r.db.QueryRow(ctx, query, args...)
 	if err := row.Scan(&venue.ID, &venue.CreatedAt, &venue.UpdatedAt); err != nil {
@@ -197,8 +205,20 @@ func (r *Repository) IsOwner(ctx context.Context, venueID int64, userID int64) (
 		return true, nil
 	}
 
-	// If the user is not the owner
-	return false, nil
+	// Owners and managers of the venue's organization manage it too.
+	var orgManager bool
+	err = r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM venues v
+			JOIN organization_members m ON m.organization_id = v.organization_id
+			WHERE v.id = $1 AND m.user_id = $2 AND m.role IN ('owner', 'manager')
+		)
+	`, venueID, userID).Scan(&orgManager)
+	if err != nil {
+		return false, err
+	}
+	return orgManager, nil
 }
 
 func (r *Repository) GetOwnedVenueIDs(ctx context.Context, userID int64) ([]int64, error) {
@@ -226,16 +246,28 @@ func (r *Repository) GetOwnedVenueIDs(ctx context.Context, userID int64) ([]int6
 // GetVenueByID retrieves a venue by its ID.
 func (r *Repository) GetVenueByID(ctx context.Context, venueID int64) (*Venue, error) {
 	query := `
-	SELECT id, owner_id, name, address, description, amenities, open_time, image_urls, sport, phone_number, created_at, updated_at 
-	FROM venues 
-	WHERE id = $1`
+	SELECT v.id, v.owner_id, v.name, v.address, v.description, v.amenities, v.open_time, v.image_urls, v.sport, v.phone_number, v.created_at, v.updated_at,
+		o.name, o.display_name, o.brand_color, o.logo_url,
+		v.wheelchair_access, v.accessible_toilets, v.accessible_parking, v.accessibility_notes
+	FROM venues v
+	LEFT JOIN organizations o ON o.id = v.organization_id
+	WHERE v.id = $1`
 	row := r.db.QueryRow(ctx, query, venueID)
 	var v Venue
 	var amenitiesJSON []byte
 	var imageURLsJSON []byte
-	if err := row.Scan(&v.ID, &v.OwnerID, &v.Name, &v.Address, &v.Description, &amenitiesJSON, &v.OpenTime, &imageURLsJSON, &v.Sport, &v.PhoneNumber, &v.CreatedAt, &v.UpdatedAt); err != nil {
+	var b orgBranding
+	var a Accessibility
+	if err := row.Scan(&v.ID, &v.OwnerID, &v.Name, &v.Address, &v.Description, &amenitiesJSON, &v.OpenTime, &imageURLsJSON, &v.Sport, &v.PhoneNumber, &v.CreatedAt, &v.UpdatedAt,
+		&b.name, &b.displayName, &b.color, &b.logoURL,
+		&a.WheelchairAccess, &a.AccessibleToilets, &a.AccessibleParking, &a.Notes); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVenueNotFound
+		}
 		return nil, err
 	}
+	v.Branding = b.toBranding()
+	v.Accessibility = &a
 	// Unmarshal JSON arrays.
 	if err := json.Unmarshal(amenitiesJSON, &v.Amenities); err != nil {
 		return nil, err
@@ -264,6 +296,30 @@ func (r *Repository) List(ctx context.Context, filter VenueFilter) ([]VenueListi
 		argCounter++
 	}
 
+	// Facility attribute filters
+	if filter.SurfaceType != nil || filter.Covered != nil {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM facilities f
+			WHERE f.venue_id = v.id
+			  AND f.is_active
+			  AND ($%d::text IS NULL OR LOWER(f.surface_type) = LOWER($%d::text))
+			  AND ($%d::boolean IS NULL OR f.is_covered = $%d::boolean)
+		)`, argCounter, argCounter, argCounter+1, argCounter+1))
+		args = append(args, filter.SurfaceType, filter.Covered)
+		argCounter += 2
+	}
+
+	// Accessibility filters
+	if filter.WheelchairAccess {
+		where = append(where, "v.wheelchair_access IS TRUE")
+	}
+	if filter.AccessibleToilets {
+		where = append(where, "v.accessible_toilets IS TRUE")
+	}
+	if filter.AccessibleParking {
+		where = append(where, "v.accessible_parking IS TRUE")
+	}
+
 	// 2) Location filter
 	hasLocation := filter.Latitude != nil && filter.Longitude != nil && filter.Distance != nil
 	var lonPos, latPos int
@@ -291,6 +347,7 @@ func (r *Repository) List(ctx context.Context, filter VenueFilter) ([]VenueListi
 		WITH venue_stats AS (
 			SELECT venue_id, COUNT(*) AS total_reviews, AVG(rating) AS average_rating
 			FROM reviews
+			WHERE status = 'published'
 			GROUP BY venue_id
 		)
 		SELECT
@@ -398,18 +455,31 @@ func (r *Repository) GetVenueDetail(ctx context.Context, venueID int64) (*VenueD
 		COUNT(DISTINCT r.id) AS total_reviews,
 		COALESCE(AVG(r.rating), 0) AS average_rating,
 		COUNT(DISTINCT CASE WHEN g.start_time > NOW() THEN g.id END) AS upcoming_games,
-		COUNT(DISTINCT CASE WHEN g.status = 'completed' THEN g.id END) AS completed_games
+		COUNT(DISTINCT CASE WHEN g.status = 'completed' THEN g.id END) AS completed_games,
+		o.name,
+		o.display_name,
+		o.brand_color,
+		o.logo_url,
+		v.wheelchair_access,
+		v.accessible_toilets,
+		v.accessible_parking,
+		v.accessibility_notes,
+		v.image_variants
 	FROM venues v
-	LEFT JOIN reviews r ON v.id = r.venue_id
+	LEFT JOIN reviews r ON v.id = r.venue_id AND r.status = 'published'
 	LEFT JOIN games g ON v.id = g.venue_id
+	LEFT JOIN organizations o ON o.id = v.organization_id
 	WHERE v.id = $1
 	GROUP BY 
 		v.id, v.owner_id, v.name, v.address, v.location, v.description,
-		v.phone_number, v.amenities, v.open_time, v.sport, v.image_urls, v.created_at, v.updated_at
+		v.phone_number, v.amenities, v.open_time, v.sport, v.image_urls, v.created_at, v.updated_at,
+		o.id
 	`
 
 	var vd VenueDetail
 	var longitude, latitude float64
+	var b orgBranding
+	var a Accessibility
 
 	err := r.db.QueryRow(ctx, query, venueID).Scan(
 		&vd.ID,
@@ -430,6 +500,15 @@ func (r *Repository) GetVenueDetail(ctx context.Context, venueID int64) (*VenueD
 		&vd.AverageRating,
 		&vd.UpcomingGames,
 		&vd.CompletedGames,
+		&b.name,
+		&b.displayName,
+		&b.color,
+		&b.logoURL,
+		&a.WheelchairAccess,
+		&a.AccessibleToilets,
+		&a.AccessibleParking,
+		&a.Notes,
+		&vd.ImageVariants,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -437,6 +516,11 @@ func (r *Repository) GetVenueDetail(ctx context.Context, venueID int64) (*VenueD
 		}
 		return nil, err
 	}
+	vd.Branding = b.toBranding()
+	vd.Accessibility = &a
+	maps.DeleteFunc(vd.ImageVariants, func(url string, _ images.Variants) bool {
+		return !slices.Contains(vd.ImageURLs, url)
+	})
 
 	// Set the Location slice with [latitude, longitude]. Adjust the order if necessary.
 	vd.Location = []float64{latitude, longitude}
@@ -486,6 +570,7 @@ func (r *Repository) ListWithTotal(ctx context.Context, filter AdminVenueFilter)
 		WITH venue_stats AS (
 			SELECT venue_id, COUNT(*) AS total_reviews, AVG(rating) AS average_rating
 			FROM reviews
+			WHERE status = 'published'
 			GROUP BY venue_id
 		)
 		SELECT
@@ -566,11 +651,16 @@ func (r *Repository) GetImageURLs(ctx context.Context, venueID int64) ([]string,
 // just get basic venueinfo without images and rating and rate count, much
 // lighter than get venue details method.
 func (r *Repository) GetVenueInfo(ctx context.Context, venueID int64) (*VenueInfo, error) {
-	query := `SELECT id, name, address, ST_X(location::geometry) as longitude,
-		ST_Y(location::geometry) as latitude, description, amenities, open_time, phone_number, status FROM venues WHERE venues.id = $1`
+	query := `SELECT venues.id, venues.name, address, ST_X(location::geometry) as longitude,
+		ST_Y(location::geometry) as latitude, description, amenities, open_time, phone_number, status,
+		o.name, o.display_name, o.brand_color, o.logo_url,
+		wheelchair_access, accessible_toilets, accessible_parking, accessibility_notes,
+		auto_accept_after_minutes
+		FROM venues LEFT JOIN organizations o ON o.id = venues.organization_id WHERE venues.id = $1`
 
 	var VenueInfo VenueInfo
 	var longitude, latitude float64
+	var b orgBranding
 
 	err := r.db.QueryRow(ctx, query, venueID).Scan(
 		&VenueInfo.ID,
@@ -583,6 +673,15 @@ func (r *Repository) GetVenueInfo(ctx context.Context, venueID int64) (*VenueInf
 		&VenueInfo.OpenTime,
 		&VenueInfo.PhoneNumber,
 		&VenueInfo.Status,
+		&b.name,
+		&b.displayName,
+		&b.color,
+		&b.logoURL,
+		&VenueInfo.Accessibility.WheelchairAccess,
+		&VenueInfo.Accessibility.AccessibleToilets,
+		&VenueInfo.Accessibility.AccessibleParking,
+		&VenueInfo.Accessibility.Notes,
+		&VenueInfo.AutoAcceptAfterMinutes,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -590,6 +689,7 @@ func (r *Repository) GetVenueInfo(ctx context.Context, venueID int64) (*VenueInf
 		}
 		return nil, err
 	}
+	VenueInfo.Branding = b.toBranding()
 
 	VenueInfo.Location = []float64{latitude, longitude}
 
@@ -718,6 +818,7 @@ func (r *Repository) SearchVenues(ctx context.Context, query string) ([]VenueLis
 	WITH venue_stats AS (
 		SELECT venue_id, COUNT(*) AS total_reviews, AVG(rating) AS average_rating
 		FROM reviews
+		WHERE status = 'published'
 		GROUP BY venue_id
 	)
 	SELECT
@@ -797,6 +898,7 @@ func (r *Repository) FullTextSearchVenues(ctx context.Context, query string) ([]
 	WITH venue_stats AS (
 		SELECT venue_id, COUNT(*) AS total_reviews, AVG(rating) AS average_rating
 		FROM reviews
+		WHERE status = 'published'
 		GROUP BY venue_id
 	),
 	ranked AS (
@@ -875,6 +977,72 @@ func (r *Repository) FullTextSearchVenues(ctx context.Context, query string) ([]
 	return out, nil
 }
 
+// ListingsByIDs returns the active venues among ids, in the order given.
+func (r *Repository) ListingsByIDs(ctx context.Context, ids []int64) ([]VenueListing, error) {
+	out := []VenueListing{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		WITH venue_stats AS (
+			SELECT venue_id, COUNT(*) AS total_reviews, AVG(rating) AS average_rating
+			FROM reviews
+			WHERE status = 'published' AND venue_id = ANY($1)
+			GROUP BY venue_id
+		)
+		SELECT
+			v.id,
+			v.name,
+			v.address,
+			ST_X(v.location::geometry) AS longitude,
+			ST_Y(v.location::geometry) AS latitude,
+			v.image_urls,
+			v.open_time,
+			v.phone_number,
+			v.sport,
+			COALESCE(vs.total_reviews, 0) AS total_reviews,
+			COALESCE(vs.average_rating, 0) AS average_rating
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS ids(id, pos)
+		JOIN venues v ON v.id = ids.id
+		LEFT JOIN venue_stats vs ON vs.venue_id = v.id
+		WHERE v.status = 'active'
+		ORDER BY ids.pos
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("venues by ids: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v VenueListing
+		var openTime sql.NullString
+		if err := rows.Scan(
+			&v.ID,
+			&v.Name,
+			&v.Address,
+			&v.Longitude,
+			&v.Latitude,
+			&v.ImageURLs,
+			&openTime,
+			&v.PhoneNumber,
+			&v.Sport,
+			&v.TotalReviews,
+			&v.AverageRating,
+		); err != nil {
+			return nil, fmt.Errorf("scan venue: %w", err)
+		}
+		if openTime.Valid {
+			v.OpenTime = &openTime.String
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows venues: %w", err)
+	}
+	return out, nil
+}
+
 func (r *Repository) UpdateVenueStatusOwner(
 	ctx context.Context,
 	venueID int64,
@@ -919,3 +1087,88 @@ func (r *Repository) UpdateVenueStatusOwner(
 
 	return nil
 }
+
+func (r *Repository) MapClusters(ctx context.Context, filter MapClusterFilter) ([]MapCluster, error) {
+	query := `
+		WITH pts AS (
+			SELECT v.id, v.name, v.location::geometry AS geom
+			FROM venues v
+			WHERE v.status = 'active'
+			  AND v.location && ST_MakeEnvelope($1, $2, $3, $4, 4326)::geography
+			  AND ($6::text IS NULL OR v.sport = $6::text)
+		)
+		SELECT
+			COUNT(*),
+			ST_Y(ST_Centroid(ST_Collect(geom))),
+			ST_X(ST_Centroid(ST_Collect(geom))),
+			ST_XMin(ST_Extent(geom)), ST_YMin(ST_Extent(geom)),
+			ST_XMax(ST_Extent(geom)), ST_YMax(ST_Extent(geom)),
+			MIN(id),
+			MIN(name)
+		FROM pts
+		GROUP BY ST_SnapToGrid(geom, $5)
+		ORDER BY COUNT(*) DESC
+	`
+	rows, err := r.db.Query(ctx, query,
+		filter.MinLon, filter.MinLat, filter.MaxLon, filter.MaxLat,
+		filter.CellSize, filter.Sport,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cluster venues: %w", err)
+	}
+	defer rows.Close()
+
+	clusters := []MapCluster{}
+	for rows.Next() {
+		var (
+			c    MapCluster
+			id   int64
+			name string
+		)
+		if err := rows.Scan(
+			&c.Count, &c.Latitude, &c.Longitude,
+			&c.Bounds[0], &c.Bounds[1], &c.Bounds[2], &c.Bounds[3],
+			&id, &name,
+		); err != nil {
+			return nil, fmt.Errorf("scan cluster: %w", err)
+		}
+		if c.Count == 1 {
+			c.VenueID, c.VenueName = &id, &name
+		}
+		clusters = append(clusters, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return clusters, nil
+}
+
+func (r *Repository) SetAccessibility(ctx context.Context, venueID int64, a Accessibility) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE venues
+		SET wheelchair_access = $2, accessible_toilets = $3, accessible_parking = $4,
+		    accessibility_notes = $5, updated_at = NOW()
+		WHERE id = $1
+	`, venueID, a.WheelchairAccess, a.AccessibleToilets, a.AccessibleParking, a.Notes)
+	if err != nil {
+		return fmt.Errorf("set venue accessibility: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrVenueNotFound
+	}
+	return nil
+}
+
+func (r *Repository) SetAutoAccept(ctx context.Context, venueID int64, minutes *int) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE venues SET auto_accept_after_minutes = $2, updated_at = NOW()
+		WHERE id = $1
+	`, venueID, minutes)
+	if err != nil {
+		return fmt.Errorf("set venue auto-accept: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrVenueNotFound
+	}
+	return nil
+}
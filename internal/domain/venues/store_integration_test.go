@@ -0,0 +1,124 @@
+//go:build integration
+
+package venues
+
+import (
+	"context"
+	"testing"
+
+	"khel/internal/testutil"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+func names(listings []VenueListing) []string {
+	out := make([]string, len(listings))
+	for i, v := range listings {
+		out[i] = v.Name
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestListByDistance(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewRepository(db)
+
+	// Roughly 0.5 km, 4 km and 12.5 km from Thamel.
+	testutil.CreateVenue(t, db, testutil.Venue{Name: "Near", Lng: 85.3140, Lat: 27.7190})
+	testutil.CreateVenue(t, db, testutil.Venue{Name: "Mid", Lng: 85.3420, Lat: 27.6915, Sport: "basketball"})
+	testutil.CreateVenue(t, db, testutil.Venue{Name: "Far", Lng: 85.4298, Lat: 27.6710})
+	testutil.CreateVenue(t, db, testutil.Venue{Name: "Near but held", Lng: 85.3130, Lat: 27.7160, Status: "hold"})
+
+	lng, lat := 85.3123, 27.7154
+	within := func(meters float64, sport *string) []string {
+		t.Helper()
+		got, err := repo.List(context.Background(), VenueFilter{
+			Sport: sport, Longitude: &lng, Latitude: &lat, Distance: &meters, Page: 1, Limit: 20,
+		})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		return names(got)
+	}
+
+	if got, want := within(5000, nil), []string{"Near", "Mid"}; !equal(got, want) {
+		t.Errorf("within 5 km = %v, want %v (nearest first)", got, want)
+	}
+	if got, want := within(20000, nil), []string{"Near", "Mid", "Far"}; !equal(got, want) {
+		t.Errorf("within 20 km = %v, want %v", got, want)
+	}
+	basketball := "basketball"
+	if got, want := within(20000, &basketball), []string{"Mid"}; !equal(got, want) {
+		t.Errorf("basketball within 20 km = %v, want %v", got, want)
+	}
+}
+
+func TestListPaginatesByName(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewRepository(db)
+
+	for _, name := range []string{"Charlie", "Alpha", "Bravo"} {
+		testutil.CreateVenue(t, db, testutil.Venue{Name: name})
+	}
+
+	page := func(n int) []string {
+		t.Helper()
+		got, err := repo.List(context.Background(), VenueFilter{Page: n, Limit: 2})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		return names(got)
+	}
+	if got, want := page(1), []string{"Alpha", "Bravo"}; !equal(got, want) {
+		t.Errorf("page 1 = %v, want %v", got, want)
+	}
+	if got, want := page(2), []string{"Charlie"}; !equal(got, want) {
+		t.Errorf("page 2 = %v, want %v", got, want)
+	}
+}
+
+func TestSearchVenues(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewRepository(db)
+
+	testutil.CreateVenue(t, db, testutil.Venue{Name: "Baneshwor Futsal Arena"})
+	testutil.CreateVenue(t, db, testutil.Venue{Name: "Boudha Court", Sport: "badminton"})
+	testutil.CreateVenue(t, db, testutil.Venue{Name: "Hidden Futsal", Status: "requested"})
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"futsal", []string{"Baneshwor Futsal Arena"}}, // matches name and sport, once
+		{"BADMIN", []string{"Boudha Court"}},           // sport, any case
+		{"  court ", []string{"Boudha Court"}},
+		{"cricket", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := repo.SearchVenues(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("SearchVenues: %v", err)
+			}
+			if !equal(names(got), tt.want) {
+				t.Errorf("got %v, want %v", names(got), tt.want)
+			}
+		})
+	}
+
+	if _, err := repo.SearchVenues(context.Background(), "   "); err == nil {
+		t.Error("blank query was accepted")
+	}
+}
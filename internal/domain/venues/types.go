@@ -3,7 +3,9 @@ package venues
 import (
 	"context"
 	"errors"
+	"khel/internal/images"
 	"khel/internal/params"
+	"math"
 	"time"
 )
 
@@ -16,6 +18,9 @@ const (
 	VenueStatusActive    VenueStatus = "active"
 	VenueStatusRejected  VenueStatus = "rejected"
 	VenueStatusHold      VenueStatus = "hold"
+	// VenueStatusPendingReview is where venues owners create start, until
+	// a superadmin has checked their documents.
+	VenueStatusPendingReview VenueStatus = "pending_review"
 )
 
 // Venue represents a venue in the database
@@ -31,8 +36,47 @@ type Venue struct {
 	OpenTime    *string   `json:"open_time,omitempty"`
 	Sport       string    `json:"sport"`
 	ImageURLs   []string  `json:"image_urls,omitempty"` // Array of image URLs
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// Status is what Create inserted, pending review unless set; reads
+	// leave it empty.
+	Status   VenueStatus `json:"status,omitempty"`
+	Branding *Branding   `json:"branding,omitempty"`
+	// Accessibility is filled in by GetVenueByID and GetVenueDetail.
+	Accessibility *Accessibility `json:"accessibility,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// Accessibility describes how easy the venue is to use for people with
+// disabilities. A nil field means the owner hasn't said either way.
+type Accessibility struct {
+	WheelchairAccess  *bool   `json:"wheelchair_access"`
+	AccessibleToilets *bool   `json:"accessible_toilets"`
+	AccessibleParking *bool   `json:"accessible_parking"`
+	Notes             *string `json:"notes,omitempty"`
+}
+
+// Branding is the look of the organization a venue belongs to. It is nil for
+// venues that are not part of an organization.
+type Branding struct {
+	DisplayName string  `json:"display_name"`
+	Color       *string `json:"color,omitempty"`
+	LogoURL     *string `json:"logo_url,omitempty"`
+}
+
+// orgBranding collects the LEFT JOINed organization columns of a venue row.
+type orgBranding struct {
+	name, displayName, color, logoURL *string
+}
+
+func (b orgBranding) toBranding() *Branding {
+	if b.name == nil {
+		return nil
+	}
+	out := &Branding{DisplayName: *b.name, Color: b.color, LogoURL: b.logoURL}
+	if b.displayName != nil {
+		out.DisplayName = *b.displayName
+	}
+	return out
 }
 
 type VenueInfo struct {
@@ -45,6 +89,12 @@ type VenueInfo struct {
 	Amenities   []string  `json:"amenities,omitempty"` // Array of strings
 	OpenTime    *string   `json:"open_time,omitempty"`
 	Status      string    `json:"status"`
+	Branding    *Branding `json:"branding,omitempty"`
+	// Accessibility is always set here so the owner's form can prefill it.
+	Accessibility Accessibility `json:"accessibility"`
+	// AutoAcceptAfterMinutes is how long a booking request may wait before
+	// it is confirmed automatically; nil when the owner answers every one.
+	AutoAcceptAfterMinutes *int `json:"auto_accept_after_minutes"`
 }
 
 // VenueDetail extends Venue with aggregation fields from reviews and games.
@@ -54,6 +104,9 @@ type VenueDetail struct {
 	AverageRating  float64 `json:"average_rating"`
 	UpcomingGames  int     `json:"upcoming_games"`
 	CompletedGames int     `json:"completed_games"`
+	// ImageVariants holds the processed photos, keyed by their image_urls
+	// entry. Photos not processed yet are missing.
+	ImageVariants map[string]images.Variants `json:"image_variants,omitempty"`
 }
 
 type VenueFilter struct {
@@ -61,8 +114,17 @@ type VenueFilter struct {
 	Latitude  *float64
 	Longitude *float64
 	Distance  *float64 // meters
-	Page      int
-	Limit     int
+	// SurfaceType and Covered keep venues with at least one active facility
+	// that matches.
+	SurfaceType *string
+	Covered     *bool
+	// The accessibility filters keep only venues whose owner said yes;
+	// false means no filter.
+	WheelchairAccess  bool
+	AccessibleToilets bool
+	AccessibleParking bool
+	Page              int
+	Limit             int
 }
 
 type VenueListing struct {
@@ -86,6 +148,34 @@ type FavoriteVenue struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// MapClusterFilter selects the active venues inside a bounding box for the
+// map view. Venues are grouped into square cells CellSize degrees wide.
+type MapClusterFilter struct {
+	MinLon, MinLat float64
+	MaxLon, MaxLat float64
+	CellSize       float64
+	Sport          *string
+}
+
+// MapCluster is a group of nearby venues drawn as one pin. A cluster of one
+// carries the venue itself so the app can open it directly.
+type MapCluster struct {
+	Count     int     `json:"count"`
+	Latitude  float64 `json:"latitude"`  // centroid
+	Longitude float64 `json:"longitude"` // centroid
+	// Bounds of the venues in the cluster as [min_lon, min_lat, max_lon,
+	// max_lat]; the app zooms to it when the pin is tapped.
+	Bounds    [4]float64 `json:"bounds"`
+	VenueID   *int64     `json:"venue_id,omitempty"`
+	VenueName *string    `json:"venue_name,omitempty"`
+}
+
+// ClusterCellSize is the cell width in degrees for a web map zoom level:
+// a quarter of a 256px tile, so pins on screen stay about 64px apart.
+func ClusterCellSize(zoom int) float64 {
+	return 360 / math.Exp2(float64(zoom)) / 4
+}
+
 type VenueListingWithRank struct {
 	VenueListing
 	Rank float64 `json:"rank"`
@@ -129,4 +219,16 @@ type Store interface {
 	// Search Functionality
 	SearchVenues(ctx context.Context, query string) ([]VenueListing, error)
 	FullTextSearchVenues(ctx context.Context, query string) ([]VenueListingWithRank, error)
+	// ListingsByIDs returns the active venues among ids, in the order given.
+	ListingsByIDs(ctx context.Context, ids []int64) ([]VenueListing, error)
+
+	// MapClusters groups the active venues in a bounding box for the map.
+	MapClusters(ctx context.Context, filter MapClusterFilter) ([]MapCluster, error)
+
+	// SetAccessibility replaces the venue's accessibility info.
+	SetAccessibility(ctx context.Context, venueID int64, a Accessibility) error
+
+	// SetAutoAccept sets, or with nil clears, how many minutes a pending
+	// booking waits before it is confirmed without the owner.
+	SetAutoAccept(ctx context.Context, venueID int64, minutes *int) error
 }
@@ -0,0 +1,266 @@
+package venuestaff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+// roleQuery resolves $2's role at venue $1. Owners and managers of the
+// venue's organization count as owners, as they do in venues.IsOwner.
+const roleQuery = `
+	SELECT CASE
+		WHEN v.owner_id = $2 THEN 'owner'
+		WHEN EXISTS (
+			SELECT 1 FROM organization_members m
+			WHERE m.organization_id = v.organization_id AND m.user_id = $2
+			  AND m.role IN ('owner', 'manager')
+		) THEN 'owner'
+		ELSE (
+			SELECT s.role FROM venue_staff s
+			WHERE s.venue_id = v.id AND s.user_id = $2
+		)
+	END
+	FROM venues v
+	WHERE v.id = $1
+`
+
+func (r *Repository) Role(ctx context.Context, venueID, userID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var role *string
+	err := r.db.QueryRow(ctx, roleQuery, venueID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && role == nil) {
+		return "", ErrNoAccess
+	}
+	if err != nil {
+		return "", fmt.Errorf("venue role: %w", err)
+	}
+	return *role, nil
+}
+
+func (r *Repository) List(ctx context.Context, venueID int64) ([]Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id, s.venue_id, s.user_id, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+		       s.role, s.invited_email, s.invited_phone, s.invited_by, s.accepted_at, s.created_at
+		FROM venue_staff s
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.venue_id = $1
+		ORDER BY s.accepted_at IS NULL, s.role, s.created_at
+	`, venueID)
+	if err != nil {
+		return nil, fmt.Errorf("list venue staff: %w", err)
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.ID, &m.VenueID, &m.UserID, &m.FirstName, &m.LastName,
+			&m.Role, &m.InvitedEmail, &m.InvitedPhone, &m.InvitedBy, &m.AcceptedAt, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan venue staff: %w", err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list venue staff: %w", err)
+	}
+	return members, nil
+}
+
+func (r *Repository) Invite(ctx context.Context, m *Member) (*int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin invite: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var inviteeID *int64
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM users
+		WHERE ($1::text IS NOT NULL AND LOWER(email) = LOWER($1))
+		   OR ($2::text IS NOT NULL AND phone = $2)
+		ORDER BY id
+		LIMIT 1
+	`, m.InvitedEmail, m.InvitedPhone).Scan(&inviteeID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("find invitee: %w", err)
+	}
+
+	if inviteeID != nil {
+		var role *string
+		err := tx.QueryRow(ctx, roleQuery, m.VenueID, *inviteeID).Scan(&role)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("invitee role: %w", err)
+		}
+		if role != nil {
+			return nil, ErrAlreadyStaff
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO venue_staff (venue_id, role, invited_email, invited_phone, invited_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, m.VenueID, m.Role, m.InvitedEmail, m.InvitedPhone, m.InvitedBy).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrAlreadyInvited
+		}
+		return nil, fmt.Errorf("invite venue staff: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit invite: %w", err)
+	}
+	return inviteeID, nil
+}
+
+func (r *Repository) Remove(ctx context.Context, venueID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM venue_staff WHERE id = $1 AND venue_id = $2`, id, venueID)
+	if err != nil {
+		return fmt.Errorf("remove venue staff: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// sentTo matches pending invitations addressed to the email or phone in
+// the given query parameters.
+func sentTo(email, phone string) string {
+	return fmt.Sprintf(`
+		s.accepted_at IS NULL
+		AND ((s.invited_email IS NOT NULL AND LOWER(s.invited_email) = LOWER(%s))
+		  OR (s.invited_phone IS NOT NULL AND s.invited_phone = %s))
+	`, email, phone)
+}
+
+func (r *Repository) Invitations(ctx context.Context, email, phone string) ([]Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id, s.venue_id, v.name, s.role,
+		       TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')),
+		       s.created_at
+		FROM venue_staff s
+		JOIN venues v ON v.id = s.venue_id
+		LEFT JOIN users u ON u.id = s.invited_by
+		WHERE `+sentTo("$1", "$2")+`
+		ORDER BY s.created_at DESC
+	`, email, phone)
+	if err != nil {
+		return nil, fmt.Errorf("list venue invitations: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Invitation{}
+	for rows.Next() {
+		var inv Invitation
+		if err := rows.Scan(&inv.ID, &inv.VenueID, &inv.VenueName, &inv.Role, &inv.InviterName, &inv.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan venue invitation: %w", err)
+		}
+		out = append(out, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list venue invitations: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) Accept(ctx context.Context, id, userID int64, email, phone string) (*Membership, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var m Membership
+	err := r.db.QueryRow(ctx, `
+		UPDATE venue_staff s
+		SET user_id = $2, accepted_at = NOW()
+		FROM venues v
+		WHERE s.id = $1 AND v.id = s.venue_id AND `+sentTo("$3", "$4")+`
+		RETURNING s.venue_id, v.name, s.role
+	`, id, userID, email, phone).Scan(&m.VenueID, &m.VenueName, &m.Role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrAlreadyStaff
+		}
+		return nil, fmt.Errorf("accept venue invitation: %w", err)
+	}
+	return &m, nil
+}
+
+func (r *Repository) Decline(ctx context.Context, id int64, email, phone string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM venue_staff s
+		WHERE s.id = $1 AND `+sentTo("$2", "$3"),
+		id, email, phone)
+	if err != nil {
+		return fmt.Errorf("decline venue invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (r *Repository) Memberships(ctx context.Context, userID int64) ([]Membership, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT s.venue_id, v.name, s.role
+		FROM venue_staff s
+		JOIN venues v ON v.id = s.venue_id
+		WHERE s.user_id = $1
+		ORDER BY v.name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list venue memberships: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Membership{}
+	for rows.Next() {
+		var m Membership
+		if err := rows.Scan(&m.VenueID, &m.VenueName, &m.Role); err != nil {
+			return nil, fmt.Errorf("scan venue membership: %w", err)
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list venue memberships: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package venuestaff
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+// Roles a user can have at a venue, most trusted first.
+const (
+	// RoleOwner is the venue's owner, or an owner or manager of the
+	// organization it belongs to.
+	RoleOwner = "owner"
+	// RoleManager runs the venue: pricing, facilities, inventory and
+	// bookings, but not its staff, status or deletion.
+	RoleManager = "manager"
+	// RoleStaff works the desk: bookings, games and the day's schedule.
+	RoleStaff = "staff"
+)
+
+var (
+	ErrNoAccess           = errors.New("user has no role at this venue")
+	ErrMemberNotFound     = errors.New("staff member not found")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrAlreadyInvited     = errors.New("that email or phone already has an invitation to this venue")
+	ErrAlreadyStaff       = errors.New("user already works at this venue")
+)
+
+// Member is someone on a venue's staff, or invited to be. UserID and the
+// names are empty until the invitation is accepted.
+type Member struct {
+	ID           int64      `json:"id"`
+	VenueID      int64      `json:"venue_id"`
+	UserID       *int64     `json:"user_id,omitempty"`
+	FirstName    string     `json:"first_name,omitempty"`
+	LastName     string     `json:"last_name,omitempty"`
+	Role         string     `json:"role"`
+	InvitedEmail *string    `json:"invited_email,omitempty"`
+	InvitedPhone *string    `json:"invited_phone,omitempty"`
+	InvitedBy    *int64     `json:"invited_by,omitempty"`
+	AcceptedAt   *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Invitation is a pending invite as its recipient sees it.
+type Invitation struct {
+	ID          int64     `json:"id"`
+	VenueID     int64     `json:"venue_id"`
+	VenueName   string    `json:"venue_name"`
+	Role        string    `json:"role"`
+	InviterName string    `json:"inviter_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Membership is a venue the user works at.
+type Membership struct {
+	VenueID   int64  `json:"venue_id"`
+	VenueName string `json:"venue_name"`
+	Role      string `json:"role"`
+}
+
+type Store interface {
+	// Role returns the user's role at the venue, or ErrNoAccess.
+	Role(ctx context.Context, venueID, userID int64) (string, error)
+	List(ctx context.Context, venueID int64) ([]Member, error)
+	// Invite saves m as a pending invitation and returns the user its email
+	// or phone belongs to, if any. It fails with ErrAlreadyStaff when that
+	// user already has a role at the venue.
+	Invite(ctx context.Context, m *Member) (*int64, error)
+	// Remove deletes a member or withdraws an invitation.
+	Remove(ctx context.Context, venueID, id int64) error
+
+	// Invitations lists pending invitations sent to the email or phone.
+	Invitations(ctx context.Context, email, phone string) ([]Invitation, error)
+	// Accept makes userID a member through an invitation sent to their
+	// email or phone.
+	Accept(ctx context.Context, id, userID int64, email, phone string) (*Membership, error)
+	Decline(ctx context.Context, id int64, email, phone string) error
+	// Memberships lists the venues userID works at as manager or staff.
+	Memberships(ctx context.Context, userID int64) ([]Membership, error)
+}
@@ -0,0 +1,212 @@
+package venueverification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Get(ctx context.Context, venueID int64) (*Verification, error) {
+	v := Verification{VenueID: venueID, Documents: []Document{}}
+	err := r.db.QueryRow(ctx, `
+		SELECT name, owner_id, status::text, review_reason, reviewed_by, reviewed_at
+		FROM venues
+		WHERE id = $1
+	`, venueID).Scan(&v.VenueName, &v.OwnerID, &v.Status, &v.Reason, &v.ReviewedBy, &v.ReviewedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVenueNotFound
+		}
+		return nil, fmt.Errorf("get venue verification: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, venue_id, kind, url, file_name, uploaded_by, created_at
+		FROM venue_verification_documents
+		WHERE venue_id = $1
+		ORDER BY created_at, id
+	`, venueID)
+	if err != nil {
+		return nil, fmt.Errorf("list verification documents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d Document
+		if err := rows.Scan(&d.ID, &d.VenueID, &d.Kind, &d.URL, &d.FileName, &d.UploadedBy, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan verification document: %w", err)
+		}
+		v.Documents = append(v.Documents, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list verification documents: %w", err)
+	}
+	return &v, nil
+}
+
+func (r *Repository) AddDocument(ctx context.Context, d *Document) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO venue_verification_documents (venue_id, kind, url, file_name, uploaded_by)
+		SELECT id, $2, $3, $4, $5
+		FROM venues
+		WHERE id = $1 AND status IN ('pending_review', 'rejected')
+		RETURNING id, created_at
+	`, d.VenueID, d.Kind, d.URL, d.FileName, d.UploadedBy).Scan(&d.ID, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotUnderReview
+	}
+	if err != nil {
+		return fmt.Errorf("add verification document: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteDocument(ctx context.Context, venueID, documentID int64) (string, error) {
+	var url, status string
+	err := r.db.QueryRow(ctx, `
+		SELECT d.url, v.status::text
+		FROM venue_verification_documents d
+		JOIN venues v ON v.id = d.venue_id
+		WHERE d.id = $1 AND d.venue_id = $2
+	`, documentID, venueID).Scan(&url, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrDocumentNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get verification document: %w", err)
+	}
+	if status != "pending_review" && status != "rejected" {
+		return "", ErrNotUnderReview
+	}
+
+	// The status check is repeated so a review landing in between wins.
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM venue_verification_documents d
+		USING venues v
+		WHERE d.id = $1 AND d.venue_id = $2 AND v.id = d.venue_id
+		  AND v.status IN ('pending_review', 'rejected')
+	`, documentID, venueID)
+	if err != nil {
+		return "", fmt.Errorf("delete verification document: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return "", ErrNotUnderReview
+	}
+	return url, nil
+}
+
+func (r *Repository) Resubmit(ctx context.Context, venueID int64) error {
+	var status string
+	var documents int
+	err := r.db.QueryRow(ctx, `
+		SELECT v.status::text,
+		       (SELECT COUNT(*) FROM venue_verification_documents d WHERE d.venue_id = v.id)
+		FROM venues v
+		WHERE v.id = $1
+	`, venueID).Scan(&status, &documents)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrVenueNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("get venue status: %w", err)
+	}
+	if status != "rejected" {
+		return ErrNotRejected
+	}
+	if documents == 0 {
+		return ErrNoDocuments
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE venues
+		SET status = 'pending_review', review_reason = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'rejected'
+	`, venueID)
+	if err != nil {
+		return fmt.Errorf("resubmit venue: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotRejected
+	}
+	return nil
+}
+
+func (r *Repository) Queue(ctx context.Context, limit, offset int) ([]QueueItem, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM venues WHERE status = 'pending_review'`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count review queue: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT v.id, v.name, v.address, v.sport, v.owner_id,
+		       TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')),
+		       (SELECT COUNT(*) FROM venue_verification_documents d WHERE d.venue_id = v.id),
+		       v.created_at
+		FROM venues v
+		JOIN users u ON u.id = v.owner_id
+		WHERE v.status = 'pending_review'
+		ORDER BY v.created_at, v.id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list review queue: %w", err)
+	}
+	defer rows.Close()
+
+	items := []QueueItem{}
+	for rows.Next() {
+		var it QueueItem
+		if err := rows.Scan(&it.VenueID, &it.Name, &it.Address, &it.Sport, &it.OwnerID,
+			&it.OwnerName, &it.DocumentCount, &it.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan review queue: %w", err)
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("list review queue: %w", err)
+	}
+	return items, total, nil
+}
+
+func (r *Repository) Review(ctx context.Context, venueID, reviewerID int64, decision Decision, reason *string) error {
+	next := "active"
+	if decision == DecisionReject {
+		next = "rejected"
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE venues
+		SET status = $2::venue_status,
+		    review_reason = $3,
+		    reviewed_by = $4,
+		    reviewed_at = NOW(),
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'pending_review'
+	`, venueID, next, reason, reviewerID)
+	if err != nil {
+		return fmt.Errorf("review venue: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM venues WHERE id = $1)`, venueID).Scan(&exists); err != nil {
+		return fmt.Errorf("review venue: %w", err)
+	}
+	if !exists {
+		return ErrVenueNotFound
+	}
+	return ErrNotPendingReview
+}
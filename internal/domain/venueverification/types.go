@@ -0,0 +1,84 @@
+package venueverification
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const QueryTimeoutDuration = time.Second * 5
+
+var (
+	ErrVenueNotFound    = errors.New("venue not found")
+	ErrDocumentNotFound = errors.New("document not found")
+	// ErrNotUnderReview means the venue isn't pending review or rejected, so
+	// its documents are settled.
+	ErrNotUnderReview = errors.New("venue is not under review")
+	// ErrNotPendingReview means there's no open review to decide.
+	ErrNotPendingReview = errors.New("venue is not pending review")
+	ErrNotRejected      = errors.New("venue was not rejected")
+	ErrNoDocuments      = errors.New("upload at least one document first")
+)
+
+// DocumentKinds are the documents an owner can prove the venue with.
+var DocumentKinds = []string{"ownership", "lease", "business_registration", "tax_registration", "other"}
+
+type Decision string
+
+const (
+	// DecisionApprove makes the venue active, so it shows in listings.
+	DecisionApprove Decision = "approve"
+	// DecisionReject sends it back to the owner with the reason.
+	DecisionReject Decision = "reject"
+)
+
+type Document struct {
+	ID         int64     `json:"id"`
+	VenueID    int64     `json:"venue_id"`
+	Kind       string    `json:"kind"`
+	URL        string    `json:"-"`
+	FileName   string    `json:"file_name"`
+	UploadedBy *int64    `json:"uploaded_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// DownloadURL is a short-lived signed link, filled in by the handler.
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// Verification is where a venue stands in review. Reason is the last
+// rejection's, kept until the owner resubmits.
+type Verification struct {
+	VenueID    int64      `json:"venue_id"`
+	VenueName  string     `json:"venue_name"`
+	OwnerID    int64      `json:"owner_id"`
+	Status     string     `json:"status"`
+	Reason     *string    `json:"reason,omitempty"`
+	ReviewedBy *int64     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	Documents  []Document `json:"documents"`
+}
+
+// QueueItem is a venue waiting for a superadmin.
+type QueueItem struct {
+	VenueID       int64     `json:"venue_id"`
+	Name          string    `json:"name"`
+	Address       string    `json:"address"`
+	Sport         string    `json:"sport"`
+	OwnerID       int64     `json:"owner_id"`
+	OwnerName     string    `json:"owner_name"`
+	DocumentCount int       `json:"document_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Store interface {
+	Get(ctx context.Context, venueID int64) (*Verification, error)
+	// AddDocument saves d while the venue is pending review or rejected.
+	AddDocument(ctx context.Context, d *Document) error
+	// DeleteDocument removes a document while the venue is pending review or
+	// rejected, returning its URL so the file can be deleted.
+	DeleteDocument(ctx context.Context, venueID, documentID int64) (string, error)
+	// Resubmit puts a rejected venue back in the queue.
+	Resubmit(ctx context.Context, venueID int64) error
+	// Queue lists venues pending review, oldest first.
+	Queue(ctx context.Context, limit, offset int) ([]QueueItem, int, error)
+	Review(ctx context.Context, venueID, reviewerID int64, decision Decision, reason *string) error
+}
@@ -0,0 +1,99 @@
+// Package events is a small durable event bus on top of the job queue.
+// Publishing enqueues one job per subscriber, so a failing subscriber is
+// retried on its own without re-running the others, and shows up on the
+// admin jobs page like any other job.
+package events
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/jobs"
+	"time"
+)
+
+// Event names
+const (
+	GameCompleted      = "game.completed"
+	BookingReleased    = "booking.released"
+	InventoryRestocked = "inventory.restocked"
+	VenueChanged       = "venue.changed"
+	ProductChanged     = "product.changed"
+)
+
+// GameCompletedPayload is published once per game when the completion job
+// flips it to completed.
+type GameCompletedPayload struct {
+	GameID         int64     `json:"game_id"`
+	VenueID        int64     `json:"venue_id"`
+	AdminID        int64     `json:"admin_id"`
+	SportType      string    `json:"sport_type"`
+	EndTime        time.Time `json:"end_time"`
+	RatingClosesAt time.Time `json:"rating_closes_at"`
+}
+
+// BookingReleasedPayload is published when a booking is canceled or
+// rejected and its time is free again.
+type BookingReleasedPayload struct {
+	BookingID  int64     `json:"booking_id"`
+	VenueID    int64     `json:"venue_id"`
+	FacilityID int64     `json:"facility_id"`
+	UserID     int64     `json:"user_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Status     string    `json:"status"`
+}
+
+// InventoryRestockedPayload is published when a received purchase order
+// brings an item that had run out back into stock.
+type InventoryRestockedPayload struct {
+	VenueID         int64  `json:"venue_id"`
+	InventoryItemID int64  `json:"inventory_item_id"`
+	ItemName        string `json:"item_name"`
+	QuantityAfter   int    `json:"quantity_after"`
+	PurchaseOrderID int64  `json:"purchase_order_id"`
+}
+
+// VenueChangedPayload is published when anything a venue is searched by
+// may have changed, including its status or its removal.
+type VenueChangedPayload struct {
+	VenueID int64 `json:"venue_id"`
+}
+
+// ProductChangedPayload is the same for products.
+type ProductChangedPayload struct {
+	ProductID int64 `json:"product_id"`
+}
+
+type Bus struct {
+	runner      *jobs.Runner
+	store       jobs.Store
+	subscribers map[string][]string // event -> job kinds
+}
+
+func NewBus(runner *jobs.Runner, store jobs.Store) *Bus {
+	return &Bus{
+		runner:      runner,
+		store:       store,
+		subscribers: make(map[string][]string),
+	}
+}
+
+// Subscribe registers fn under the job kind "event.<event>.<subscriber>".
+// Call before the runner starts.
+func (b *Bus) Subscribe(event, subscriber string, fn jobs.HandlerFunc) {
+	kind := fmt.Sprintf("event.%s.%s", event, subscriber)
+	b.runner.Register(kind, fn)
+	b.subscribers[event] = append(b.subscribers[event], kind)
+}
+
+// Publish queues the event for every subscriber. key identifies this
+// occurrence (e.g. the game id); publishing the same key again while a
+// delivery is still pending is a no-op.
+func (b *Bus) Publish(ctx context.Context, event, key string, payload any) error {
+	for _, kind := range b.subscribers[event] {
+		if _, err := b.store.Enqueue(ctx, kind, payload, jobs.EnqueueOptions{UniqueKey: kind + ":" + key}); err != nil {
+			return fmt.Errorf("publish %s to %s: %w", event, kind, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package images
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+)
+
+// Blurhash component counts. 4x3 suits both landscape venue photos and
+// square product shots.
+const (
+	blurhashX = 4
+	blurhashY = 3
+)
+
+const base83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Blurhash encodes img as described at https://blurha.sh. img should be
+// small already; every pixel is visited once per component.
+func Blurhash(img image.Image) (string, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return "", fmt.Errorf("blurhash: empty image")
+	}
+
+	// Linear RGB of every pixel, converted once.
+	lin := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, bl := rgb8(img, b.Min.X+x, b.Min.Y+y)
+			lin[y*w+x] = [3]float64{srgbToLinear(r), srgbToLinear(g), srgbToLinear(bl)}
+		}
+	}
+
+	factors := make([][3]float64, 0, blurhashX*blurhashY)
+	for j := 0; j < blurhashY; j++ {
+		for i := 0; i < blurhashX; i++ {
+			norm := 2.0
+			if i == 0 && j == 0 {
+				norm = 1
+			}
+			var f [3]float64
+			for y := 0; y < h; y++ {
+				cy := math.Cos(math.Pi * float64(j) * float64(y) / float64(h))
+				for x := 0; x < w; x++ {
+					basis := norm * cy * math.Cos(math.Pi*float64(i)*float64(x)/float64(w))
+					p := lin[y*w+x]
+					f[0] += basis * p[0]
+					f[1] += basis * p[1]
+					f[2] += basis * p[2]
+				}
+			}
+			scale := 1 / float64(w*h)
+			factors = append(factors, [3]float64{f[0] * scale, f[1] * scale, f[2] * scale})
+		}
+	}
+
+	var sb strings.Builder
+	encode83(&sb, (blurhashX-1)+(blurhashY-1)*9, 1)
+
+	dc, ac := factors[0], factors[1:]
+	var maxAC float64
+	for _, f := range ac {
+		maxAC = math.Max(maxAC, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+	}
+	quantisedMax := int(math.Max(0, math.Min(82, math.Floor(maxAC*166-0.5))))
+	maxValue := float64(quantisedMax+1) / 166
+	encode83(&sb, quantisedMax, 1)
+
+	encode83(&sb, linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4)
+	for _, f := range ac {
+		q := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		encode83(&sb, q(f[0])*19*19+q(f[1])*19+q(f[2]), 2)
+	}
+	return sb.String(), nil
+}
+
+func encode83(sb *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		sb.WriteByte(base83[digit])
+	}
+}
+
+func rgb8(img image.Image, x, y int) (r, g, b int) {
+	cr, cg, cb, _ := img.At(x, y).RGBA()
+	return int(cr >> 8), int(cg >> 8), int(cb >> 8)
+}
+
+func srgbToLinear(v int) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+// DominantColor is the average of the most common colour in img, with
+// channels bucketed to 4 bits so near-identical shades count together.
+// It comes back as #rrggbb.
+func DominantColor(img image.Image) string {
+	type sum struct{ n, r, g, b int }
+	buckets := map[int]*sum{}
+	var best *sum
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b := rgb8(img, x, y)
+			key := (r>>4)<<8 | (g>>4)<<4 | b>>4
+			s := buckets[key]
+			if s == nil {
+				s = &sum{}
+				buckets[key] = s
+			}
+			s.n++
+			s.r += r
+			s.g += g
+			s.b += b
+			if best == nil || s.n > best.n {
+				best = s
+			}
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.n, best.g/best.n, best.b/best.n)
+}
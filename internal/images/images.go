@@ -0,0 +1,63 @@
+// Package images derives the sizes and placeholders the apps show for an
+// uploaded image. Sizes are Cloudinary transformations of the original, so
+// only the placeholder needs the image itself.
+package images
+
+import (
+	"strings"
+)
+
+// Size is a named Cloudinary transformation.
+type Size struct {
+	Name           string
+	Transformation string
+}
+
+// Sizes are generated eagerly on upload, smallest first. c_limit never
+// upscales, so a small original is served as is.
+var Sizes = []Size{
+	{Name: "thumb", Transformation: "c_limit,w_320,q_auto,f_auto"},
+	{Name: "medium", Transformation: "c_limit,w_800,q_auto,f_auto"},
+	{Name: "large", Transformation: "c_limit,w_1600,q_auto,f_auto"},
+}
+
+// Variants are the sizes of one image plus what to show while they load.
+// Blurhash and DominantColor are empty when the image couldn't be read.
+type Variants struct {
+	ThumbURL      string `json:"thumb_url"`
+	MediumURL     string `json:"medium_url"`
+	LargeURL      string `json:"large_url"`
+	Blurhash      string `json:"blurhash,omitempty"`
+	DominantColor string `json:"dominant_color,omitempty"`
+}
+
+// Eager is the value for Cloudinary's eager upload parameter, asking for
+// every size to be made up front instead of on first request.
+func Eager() string {
+	t := make([]string, len(Sizes))
+	for i, s := range Sizes {
+		t[i] = s.Transformation
+	}
+	return strings.Join(t, "|")
+}
+
+// VariantURL is url with transformation applied. Only Cloudinary upload
+// URLs can be transformed; anything else comes back unchanged.
+func VariantURL(url, transformation string) string {
+	const marker = "/image/upload/"
+	i := strings.Index(url, marker)
+	if i < 0 || !strings.Contains(url[:i], "res.cloudinary.com") {
+		return url
+	}
+	i += len(marker)
+	return url[:i] + transformation + "/" + url[i:]
+}
+
+// SizeURLs fills in the URLs of every size of url.
+func SizeURLs(url string) Variants {
+	return Variants{
+		ThumbURL:  VariantURL(url, Sizes[0].Transformation),
+		MediumURL: VariantURL(url, Sizes[1].Transformation),
+		LargeURL:  VariantURL(url, Sizes[2].Transformation),
+	}
+}
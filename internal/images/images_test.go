@@ -0,0 +1,76 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestVariantURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "cloudinary",
+			url:  "https://res.cloudinary.com/demo/image/upload/v1712/venues/venue_1.jpg",
+			want: "https://res.cloudinary.com/demo/image/upload/c_limit,w_320,q_auto,f_auto/v1712/venues/venue_1.jpg",
+		},
+		{
+			name: "elsewhere",
+			url:  "https://example.com/image/upload/racket.jpg",
+			want: "https://example.com/image/upload/racket.jpg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VariantURL(tt.url, Sizes[0].Transformation); got != tt.want {
+				t.Errorf("VariantURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlurhashSolidColour(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{R: 0x20, G: 0x80, B: 0xc0, A: 0xff})
+		}
+	}
+
+	hash, err := Blurhash(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 size flag + 1 max + 4 DC + 2 per AC component.
+	if want := 6 + 2*(blurhashX*blurhashY-1); len(hash) != want {
+		t.Fatalf("len(hash) = %d, want %d", len(hash), want)
+	}
+	if hash[:1] != "L" {
+		t.Errorf("size flag = %q, want %q for 4x3", hash[:1], "L")
+	}
+
+	// The DC term is the image's average colour.
+	var dc int
+	for _, c := range hash[2:6] {
+		dc = dc*83 + indexOf(byte(c))
+	}
+	if dc != 0x2080c0 {
+		t.Errorf("DC = %06x, want 2080c0", dc)
+	}
+
+	if got := DominantColor(img); got != "#2080c0" {
+		t.Errorf("DominantColor() = %q, want #2080c0", got)
+	}
+}
+
+func indexOf(c byte) int {
+	for i := 0; i < len(base83); i++ {
+		if base83[i] == c {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,106 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"khel/internal/tracing"
+	"net/http"
+	"time"
+)
+
+// ErrUnusable means the image can't be read, and trying again won't help:
+// it's gone, or in a format that can't be decoded.
+var ErrUnusable = errors.New("image unusable")
+
+// sampleTransformation asks Cloudinary for a copy just big enough for the
+// placeholder, as a JPEG whatever the original format.
+const sampleTransformation = "c_limit,w_32,h_32,f_jpg"
+
+// sampleSize caps the pixels the placeholder is computed from, for images
+// that don't come from Cloudinary and arrive at full size.
+const sampleSize = 32
+
+// maxDownload is the most read of an image that isn't on Cloudinary.
+const maxDownload = 10 << 20
+
+type Processor struct {
+	httpClient *http.Client
+}
+
+func NewProcessor() *Processor {
+	return &Processor{
+		httpClient: &http.Client{
+			Timeout:   20 * time.Second,
+			Transport: tracing.Transport(tracing.KindCloudinary, nil),
+		},
+	}
+}
+
+// Process returns the variants of the image at url. Errors wrapping
+// ErrUnusable are permanent; the rest are worth retrying.
+func (p *Processor) Process(ctx context.Context, url string) (Variants, error) {
+	v := SizeURLs(url)
+
+	img, err := p.fetch(ctx, VariantURL(url, sampleTransformation))
+	if err != nil {
+		return v, err
+	}
+	img = sample(img, sampleSize)
+
+	v.Blurhash, err = Blurhash(img)
+	if err != nil {
+		return v, fmt.Errorf("%w: %v", ErrUnusable, err)
+	}
+	v.DominantColor = DominantColor(img)
+	return v, nil
+}
+
+func (p *Processor) fetch(ctx context.Context, url string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnusable, err)
+	}
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch image: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 && res.StatusCode < 500 {
+		return nil, fmt.Errorf("%w: status %d", ErrUnusable, res.StatusCode)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch image: status %d", res.StatusCode)
+	}
+
+	img, _, err := image.Decode(io.LimitReader(res.Body, maxDownload))
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode: %v", ErrUnusable, err)
+	}
+	return img, nil
+}
+
+// sample shrinks img by nearest neighbour so neither side exceeds size.
+// It's only used for placeholders, where the detail lost doesn't show.
+func sample(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	scale := float64(size) / float64(max(w, h))
+	sw, sh := max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+	out := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			out.Set(x, y, img.At(b.Min.X+x*w/sw, b.Min.Y+y*h/sh))
+		}
+	}
+	return out
+}
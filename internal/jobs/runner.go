@@ -0,0 +1,304 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HandlerFunc runs one job. A returned error schedules a retry with backoff
+// until the job runs out of attempts.
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+const (
+	pollInterval   = 2 * time.Second
+	claimBatch     = 10
+	jobTimeout     = 2 * time.Minute
+	staleAfter     = 10 * time.Minute
+	rescueInterval = time.Minute
+	pruneAfter     = 7 * 24 * time.Hour
+	// Run history is small (one row per periodic tick) and is what tells us
+	// a schedule stalled, so it is kept longer than finished jobs.
+	pruneRunsAfter = 30 * 24 * time.Hour
+)
+
+type periodic struct {
+	kind     string
+	interval time.Duration
+}
+
+// Runner polls the jobs table and dispatches claimed jobs to registered handlers.
+type Runner struct {
+	store    Store
+	logger   *zap.SugaredLogger
+	workerID string
+	handlers map[string]HandlerFunc
+	periodic []periodic
+}
+
+func NewRunner(store Store, logger *zap.SugaredLogger) *Runner {
+	host, _ := os.Hostname()
+	return &Runner{
+		store:    store,
+		logger:   logger,
+		workerID: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+// Register binds a handler to a job kind. Call before Start.
+func (rn *Runner) Register(kind string, fn HandlerFunc) {
+	rn.handlers[kind] = fn
+}
+
+// Every enqueues kind once at Start and then every interval. The kind is used
+// as the unique key, so instances racing on the same tick only queue one job.
+func (rn *Runner) Every(kind string, interval time.Duration) {
+	rn.periodic = append(rn.periodic, periodic{kind: kind, interval: interval})
+}
+
+// Schedules returns the periodic kinds with their most recent run. A kind is
+// overdue when nothing started within two intervals.
+func (rn *Runner) Schedules(ctx context.Context) ([]Schedule, error) {
+	kinds := make([]string, 0, len(rn.periodic))
+	for _, p := range rn.periodic {
+		kinds = append(kinds, p.kind)
+	}
+
+	latest, err := rn.store.LatestRuns(ctx, kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Schedule, 0, len(rn.periodic))
+	for _, p := range rn.periodic {
+		s := Schedule{Kind: p.kind, Interval: p.interval.String(), Overdue: true}
+		if run, ok := latest[p.kind]; ok {
+			s.LastRun = &run
+			s.Overdue = time.Since(run.StartedAt) > 2*p.interval
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (rn *Runner) isPeriodic(kind string) bool {
+	for _, p := range rn.periodic {
+		if p.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before retrying after the given attempt:
+// 30s, 1m, 2m, 4m ... capped at 1h.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := 30 * time.Second
+	for i := 1; i < attempt && d < time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, time.Hour)
+}
+
+// Start launches the poll loop and periodic schedulers. They stop when ctx is done.
+func (rn *Runner) Start(ctx context.Context) {
+	kinds := make([]string, 0, len(rn.handlers))
+	for k := range rn.handlers {
+		kinds = append(kinds, k)
+	}
+
+	for _, p := range rn.periodic {
+		go rn.schedule(ctx, p)
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				rn.logger.Errorf("Recovered from panic in job runner: %v", r)
+			}
+		}()
+
+		poll := time.NewTicker(pollInterval)
+		defer poll.Stop()
+		rescue := time.NewTicker(rescueInterval)
+		defer rescue.Stop()
+
+		rn.rescue(ctx)
+
+		for {
+			select {
+			case <-ctx.Done():
+				rn.logger.Info("Stopped job runner due to context cancellation")
+				return
+			case <-rescue.C:
+				rn.rescue(ctx)
+			case <-poll.C:
+				rn.poll(ctx, kinds)
+			}
+		}
+	}()
+}
+
+func (rn *Runner) schedule(ctx context.Context, p periodic) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := rn.store.Enqueue(ctx, p.kind, struct{}{}, EnqueueOptions{UniqueKey: p.kind}); err != nil && ctx.Err() == nil {
+			rn.logger.Errorw("jobs: schedule periodic job failed", "kind", p.kind, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (rn *Runner) rescue(ctx context.Context) {
+	n, err := rn.store.RescueStale(ctx, time.Now().Add(-staleAfter))
+	if err != nil {
+		if ctx.Err() == nil {
+			rn.logger.Errorw("jobs: rescue stale failed", "error", err)
+		}
+		return
+	}
+	if n > 0 {
+		rn.logger.Warnw("jobs: requeued stale jobs", "count", n)
+	}
+
+	if _, err := rn.store.PruneFinished(ctx, time.Now().Add(-pruneAfter)); err != nil && ctx.Err() == nil {
+		rn.logger.Errorw("jobs: prune finished failed", "error", err)
+	}
+	if _, err := rn.store.PruneRuns(ctx, time.Now().Add(-pruneRunsAfter)); err != nil && ctx.Err() == nil {
+		rn.logger.Errorw("jobs: prune runs failed", "error", err)
+	}
+}
+
+func (rn *Runner) poll(ctx context.Context, kinds []string) {
+	if len(kinds) == 0 {
+		return
+	}
+
+	claimed, err := rn.store.Claim(ctx, rn.workerID, kinds, claimBatch)
+	if err != nil {
+		if ctx.Err() == nil {
+			rn.logger.Errorw("jobs: claim failed", "error", err)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range claimed {
+		wg.Add(1)
+		go func(j Job) {
+			defer wg.Done()
+			rn.run(ctx, j)
+		}(claimed[i])
+	}
+	wg.Wait()
+}
+
+func (rn *Runner) run(ctx context.Context, j Job) {
+	// Finish bookkeeping even if we are shutting down, otherwise the job sits
+	// in running until the rescue sweep picks it up.
+	bookCtx, cancelBook := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancelBook()
+
+	var rows *atomic.Int64
+	if rn.isPeriodic(j.Kind) {
+		rows = new(atomic.Int64)
+		rows.Store(-1)
+	}
+
+	started := time.Now()
+	err := rn.invoke(ctx, j, rows)
+	if rows != nil {
+		rn.recordRun(bookCtx, j, started, rows.Load(), err)
+	}
+
+	if err == nil {
+		if err := rn.store.Complete(bookCtx, j.ID); err != nil {
+			rn.logger.Errorw("jobs: mark complete failed", "job_id", j.ID, "kind", j.Kind, "error", err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if j.Attempts < j.MaxAttempts {
+		t := time.Now().Add(Backoff(j.Attempts))
+		retryAt = &t
+	}
+
+	rn.logger.Warnw("jobs: job failed",
+		"job_id", j.ID,
+		"kind", j.Kind,
+		"attempt", j.Attempts,
+		"max_attempts", j.MaxAttempts,
+		"dead", retryAt == nil,
+		"error", err,
+	)
+
+	if err := rn.store.Fail(bookCtx, j.ID, err.Error(), retryAt); err != nil {
+		rn.logger.Errorw("jobs: mark failed failed", "job_id", j.ID, "kind", j.Kind, "error", err)
+	}
+}
+
+// recordRun writes the job_runs row for a periodic job. rows is -1 when the
+// handler did not report a count.
+func (rn *Runner) recordRun(ctx context.Context, j Job, started time.Time, rows int64, runErr error) {
+	finished := time.Now()
+	jobID := j.ID
+	run := Run{
+		JobID:      &jobID,
+		Kind:       j.Kind,
+		Worker:     rn.workerID,
+		StartedAt:  started,
+		FinishedAt: finished,
+		DurationMS: finished.Sub(started).Milliseconds(),
+		Succeeded:  runErr == nil,
+	}
+	if rows >= 0 {
+		run.RowsAffected = &rows
+	}
+	if runErr != nil {
+		msg := runErr.Error()
+		run.Error = &msg
+	}
+
+	if err := rn.store.RecordRun(ctx, &run); err != nil {
+		rn.logger.Errorw("jobs: record run failed", "job_id", j.ID, "kind", j.Kind, "error", err)
+	}
+}
+
+func (rn *Runner) invoke(ctx context.Context, j Job, rows *atomic.Int64) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	fn, ok := rn.handlers[j.Kind]
+	if !ok {
+		return fmt.Errorf("no handler registered for kind %q", j.Kind)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+	if rows != nil {
+		ctx = context.WithValue(ctx, rowsKey{}, rows)
+	}
+
+	return fn(ctx, j.Payload)
+}
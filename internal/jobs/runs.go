@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Run is one execution of a periodic job.
+type Run struct {
+	ID           int64     `json:"id"`
+	JobID        *int64    `json:"job_id,omitempty"`
+	Kind         string    `json:"kind"`
+	Worker       string    `json:"worker"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	DurationMS   int64     `json:"duration_ms"`
+	RowsAffected *int64    `json:"rows_affected,omitempty"`
+	Error        *string   `json:"error,omitempty"`
+	Succeeded    bool      `json:"succeeded"`
+}
+
+// Schedule is a periodic job with its most recent run, if any.
+type Schedule struct {
+	Kind     string `json:"kind"`
+	Interval string `json:"interval"`
+	LastRun  *Run   `json:"last_run,omitempty"`
+	// Overdue means no run started within two intervals, i.e. the schedule
+	// has likely stopped firing.
+	Overdue bool `json:"overdue"`
+}
+
+type rowsKey struct{}
+
+// SetRowsAffected lets a periodic job report how many rows it touched; the
+// runner stores it on the job_runs record. It is a no-op for other jobs.
+func SetRowsAffected(ctx context.Context, n int64) {
+	if p, ok := ctx.Value(rowsKey{}).(*atomic.Int64); ok {
+		p.Store(n)
+	}
+}
+
+func (r *Repository) RecordRun(ctx context.Context, run *Run) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO job_runs (job_id, kind, worker, started_at, finished_at, duration_ms, rows_affected, error, succeeded)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, run.JobID, run.Kind, run.Worker, run.StartedAt, run.FinishedAt, run.DurationMS, run.RowsAffected, run.Error, run.Succeeded).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("record job run: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ListRuns(ctx context.Context, kind string, limit, offset int) ([]Run, int, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, job_id, kind, worker, started_at, finished_at, duration_ms, rows_affected, error, succeeded,
+		       COUNT(*) OVER() AS total_count
+		FROM job_runs
+		WHERE ($1 = '' OR kind = $1)
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, kind, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list job runs: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Run{}
+	var total int
+	for rows.Next() {
+		var run Run
+		if err := rows.Scan(
+			&run.ID, &run.JobID, &run.Kind, &run.Worker, &run.StartedAt, &run.FinishedAt,
+			&run.DurationMS, &run.RowsAffected, &run.Error, &run.Succeeded, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan job run: %w", err)
+		}
+		list = append(list, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) LatestRuns(ctx context.Context, kinds []string) (map[string]Run, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (kind)
+		       id, job_id, kind, worker, started_at, finished_at, duration_ms, rows_affected, error, succeeded
+		FROM job_runs
+		WHERE kind = ANY($1)
+		ORDER BY kind, started_at DESC
+	`, kinds)
+	if err != nil {
+		return nil, fmt.Errorf("latest job runs: %w", err)
+	}
+	defer rows.Close()
+
+	latest := make(map[string]Run, len(kinds))
+	for rows.Next() {
+		var run Run
+		if err := rows.Scan(
+			&run.ID, &run.JobID, &run.Kind, &run.Worker, &run.StartedAt, &run.FinishedAt,
+			&run.DurationMS, &run.RowsAffected, &run.Error, &run.Succeeded,
+		); err != nil {
+			return nil, fmt.Errorf("scan job run: %w", err)
+		}
+		latest[run.Kind] = run
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return latest, nil
+}
+
+func (r *Repository) PruneRuns(ctx context.Context, startedBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM job_runs WHERE started_at < $1`, startedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("prune job runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
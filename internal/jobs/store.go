@@ -0,0 +1,241 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Store {
+	return &Repository{db: db}
+}
+
+const jobColumns = `
+	id, kind, payload, status, attempts, max_attempts, unique_key, run_at,
+	locked_at, locked_by, last_error, finished_at, created_at, updated_at
+`
+
+func scanJob(row pgx.Row, j *Job) error {
+	return row.Scan(
+		&j.ID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.UniqueKey, &j.RunAt,
+		&j.LockedAt, &j.LockedBy, &j.LastError, &j.FinishedAt, &j.CreatedAt, &j.UpdatedAt,
+	)
+}
+
+func (r *Repository) Enqueue(ctx context.Context, kind string, payload any, opts EnqueueOptions) (*Job, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal job payload: %w", err)
+	}
+
+	runAt := opts.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	var uniqueKey *string
+	if opts.UniqueKey != "" {
+		uniqueKey = &opts.UniqueKey
+	}
+
+	q := `
+		INSERT INTO jobs (kind, payload, max_attempts, unique_key, run_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (unique_key) WHERE unique_key IS NOT NULL AND status IN ('queued', 'running')
+		DO NOTHING
+		RETURNING ` + jobColumns
+
+	var j Job
+	if err := scanJob(r.db.QueryRow(ctx, q, kind, b, maxAttempts, uniqueKey, runAt), &j); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("enqueue job: %w", err)
+	}
+	return &j, nil
+}
+
+func (r *Repository) Claim(ctx context.Context, workerID string, kinds []string, limit int) ([]Job, error) {
+	q := `
+		WITH due AS (
+			SELECT id
+			FROM jobs
+			WHERE status = 'queued'
+			  AND run_at <= NOW()
+			  AND kind = ANY($2)
+			ORDER BY run_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE jobs j
+		SET status = 'running',
+		    attempts = j.attempts + 1,
+		    locked_at = NOW(),
+		    locked_by = $1,
+		    updated_at = NOW()
+		FROM due
+		WHERE j.id = due.id
+		RETURNING ` + jobColumns
+
+	rows, err := r.db.Query(ctx, q, workerID, kinds, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim jobs: %w", err)
+	}
+	defer rows.Close()
+
+	claimed := []Job{}
+	for rows.Next() {
+		var j Job
+		if err := scanJob(rows, &j); err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		claimed = append(claimed, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return claimed, nil
+}
+
+func (r *Repository) Complete(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE jobs
+		SET status = 'succeeded',
+		    finished_at = NOW(),
+		    locked_at = NULL,
+		    locked_by = NULL,
+		    last_error = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("complete job: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Fail(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE jobs
+		SET status = CASE WHEN $3::timestamptz IS NULL THEN 'dead' ELSE 'queued' END,
+		    run_at = COALESCE($3::timestamptz, run_at),
+		    finished_at = CASE WHEN $3::timestamptz IS NULL THEN NOW() ELSE NULL END,
+		    last_error = $2,
+		    locked_at = NULL,
+		    locked_by = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`, id, errMsg, retryAt)
+	if err != nil {
+		return fmt.Errorf("fail job: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) RescueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE jobs
+		SET status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'queued' END,
+		    finished_at = CASE WHEN attempts >= max_attempts THEN NOW() ELSE NULL END,
+		    last_error = COALESCE(last_error, 'worker lost while running'),
+		    locked_at = NULL,
+		    locked_by = NULL,
+		    updated_at = NOW()
+		WHERE status = 'running' AND locked_at < $1
+	`, lockedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("rescue stale jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *Repository) PruneFinished(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM jobs WHERE status = 'succeeded' AND finished_at < $1
+	`, finishedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("prune jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (*Job, error) {
+	var j Job
+	err := scanJob(r.db.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id), &j)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+	return &j, nil
+}
+
+func (r *Repository) List(ctx context.Context, f Filter, limit, offset int) ([]Job, int, error) {
+	q := `
+		SELECT ` + jobColumns + `, COUNT(*) OVER() AS total_count
+		FROM jobs
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR kind = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, q, f.Status, f.Kind, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Job{}
+	var total int
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(
+			&j.ID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.UniqueKey, &j.RunAt,
+			&j.LockedAt, &j.LockedBy, &j.LastError, &j.FinishedAt, &j.CreatedAt, &j.UpdatedAt, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan job: %w", err)
+		}
+		list = append(list, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration: %w", err)
+	}
+	return list, total, nil
+}
+
+func (r *Repository) Retry(ctx context.Context, id int64) (*Job, error) {
+	q := `
+		UPDATE jobs
+		SET status = 'queued',
+		    attempts = 0,
+		    run_at = NOW(),
+		    finished_at = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+		RETURNING ` + jobColumns
+
+	var j Job
+	if err := scanJob(r.db.QueryRow(ctx, q, id), &j); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.Get(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrNotRetryable
+		}
+		return nil, fmt.Errorf("retry job: %w", err)
+	}
+	return &j, nil
+}
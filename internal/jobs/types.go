@@ -0,0 +1,85 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("job not found")
+	ErrNotRetryable = errors.New("only dead jobs can be retried")
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+const DefaultMaxAttempts = 5
+
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	UniqueKey   *string         `json:"unique_key,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedAt    *time.Time      `json:"locked_at,omitempty"`
+	LockedBy    *string         `json:"locked_by,omitempty"`
+	LastError   *string         `json:"last_error,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// EnqueueOptions tweak a single Enqueue call. The zero value runs the job
+// as soon as possible with DefaultMaxAttempts.
+type EnqueueOptions struct {
+	RunAt       time.Time
+	MaxAttempts int
+	// UniqueKey dedupes: while a queued/running job holds the key,
+	// further enqueues with the same key are dropped.
+	UniqueKey string
+}
+
+// Filter narrows List. Zero values are ignored.
+type Filter struct {
+	Status string
+	Kind   string
+}
+
+type Store interface {
+	// Enqueue inserts a job. It returns (nil, nil) when UniqueKey is already held.
+	Enqueue(ctx context.Context, kind string, payload any, opts EnqueueOptions) (*Job, error)
+
+	// Claim locks up to limit due jobs for workerID and marks them running.
+	Claim(ctx context.Context, workerID string, kinds []string, limit int) ([]Job, error)
+	Complete(ctx context.Context, id int64) error
+	// Fail records err. The job is requeued at retryAt, or marked dead when
+	// retryAt is nil.
+	Fail(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error
+	// RescueStale requeues running jobs locked before the cutoff
+	// (their worker crashed or was killed mid-run).
+	RescueStale(ctx context.Context, lockedBefore time.Time) (int64, error)
+	// PruneFinished deletes succeeded jobs finished before the cutoff.
+	PruneFinished(ctx context.Context, finishedBefore time.Time) (int64, error)
+
+	Get(ctx context.Context, id int64) (*Job, error)
+	List(ctx context.Context, f Filter, limit, offset int) ([]Job, int, error)
+	// Retry puts a dead job back in the queue with a fresh attempt budget.
+	Retry(ctx context.Context, id int64) (*Job, error)
+
+	// RecordRun stores one execution of a periodic job.
+	RecordRun(ctx context.Context, run *Run) error
+	ListRuns(ctx context.Context, kind string, limit, offset int) ([]Run, int, error)
+	// LatestRuns returns the most recent run per kind; kinds that never ran
+	// are absent.
+	LatestRuns(ctx context.Context, kinds []string) (map[string]Run, error)
+	PruneRuns(ctx context.Context, startedBefore time.Time) (int64, error)
+}
@@ -3,15 +3,23 @@ package mailer
 import "embed"
 
 const (
-	FromName              = "Khel"
-	maxRetires            = 3
-	UserWelcomeTemplate   = "user_invitation.tmpl"
-	ResetPasswordTemplate = "reset_password.tmpl"
+	FromName                    = "Khel"
+	maxRetires                  = 3
+	UserWelcomeTemplate         = "user_invitation.tmpl"
+	ResetPasswordTemplate       = "reset_password.tmpl"
+	SupportReplyTemplate        = "support_reply.tmpl"
+	BookingConfirmationTemplate = "booking_confirmation.tmpl"
+	BookingRejectionTemplate    = "booking_rejection.tmpl"
+	GameInviteTemplate          = "game_invite.tmpl"
+	ReviewInviteTemplate        = "review_invite.tmpl"
+	VenueStaffInviteTemplate    = "venue_staff_invite.tmpl"
 )
 
 //go:embed "templates"
 var FS embed.FS
 
 type Client interface {
-	Send(templateFile, username, email string, data any) (int, error)
+	// Send renders templateFile in locale and delivers it. An unknown or
+	// empty locale falls back to DefaultLocale.
+	Send(templateFile, locale, username, email string, data any) (int, error)
 }
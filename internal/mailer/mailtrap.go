@@ -1,13 +1,15 @@
 package mailer
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"khel/internal/tracing"
 	"time"
 
-	"text/template"
-
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	gomail "gopkg.in/mail.v2"
 )
 
@@ -27,21 +29,14 @@ func NewMailTrapClient(apiKey, fromEmail string) (mailtrapClient, error) {
 	}, nil
 }
 
-func (m mailtrapClient) Send(templateFile, username, email string, data any) (int, error) {
-	// Template parsing and building
-	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
-	if err != nil {
-		return -1, err
-	}
-
-	subject := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(subject, "subject", data)
-	if err != nil {
-		return -1, err
-	}
+func (m mailtrapClient) Send(templateFile, locale, username, email string, data any) (status int, err error) {
+	_, span := tracing.Start(context.Background(), tracing.KindMailtrap+" send",
+		semconv.PeerService(tracing.KindMailtrap),
+		attribute.String("mail.template", templateFile),
+	)
+	defer func() { tracing.End(span, err) }()
 
-	body := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(body, "body", data)
+	subject, body, err := Render(templateFile, locale, data)
 	if err != nil {
 		return -1, err
 	}
@@ -49,19 +44,17 @@ func (m mailtrapClient) Send(templateFile, username, email string, data any) (in
 	message := gomail.NewMessage()
 	message.SetHeader("From", m.fromEmail)
 	message.SetHeader("To", email)
-	message.SetHeader("Subject", subject.String())
+	message.SetHeader("Subject", subject)
 
-	message.AddAlternative("text/html", body.String())
+	message.AddAlternative("text/html", body)
 
 	dialer := gomail.NewDialer("live.smtp.mailtrap.io", 2525, "api", m.apiKey)
 
-	if err := dialer.DialAndSend(message); err != nil {
-		return -1, err
-	}
 	var retryErr error
 	for i := 0; i < maxRetires; i++ {
-		retryErr := dialer.DialAndSend(message)
+		retryErr = dialer.DialAndSend(message)
 		if retryErr != nil {
+			span.AddEvent("send failed", trace.WithAttributes(attribute.Int("attempt", i+1)))
 			// exponential backoff
 			time.Sleep(time.Second * time.Duration(i+1))
 			continue
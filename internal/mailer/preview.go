@@ -0,0 +1,66 @@
+package mailer
+
+// PreviewData is sample data for each template, used by the dev-only email
+// preview endpoint. Keep it in step with the fields each template reads.
+var PreviewData = map[string]any{
+	UserWelcomeTemplate: map[string]any{
+		"Username":      "Aarav",
+		"ActivationURL": "https://khel.example.com/confirm?token=preview",
+	},
+	ResetPasswordTemplate: map[string]any{
+		"Username": "Aarav",
+		"ResetURL": "https://khel.example.com/reset-password/?token=preview",
+	},
+	SupportReplyTemplate: map[string]any{
+		"Username": "Aarav",
+		"TicketID": 42,
+		"Subject":  "Charged twice for my booking",
+		"Reply":    "Sorry about that! We've refunded the duplicate charge.\nIt should reach you within 3 working days.",
+	},
+	BookingConfirmationTemplate: map[string]any{
+		"Username":  "Aarav",
+		"BookingID": "rE7xJX1G",
+		"VenueName": "Dhuku Futsal",
+		"Date":      "Sat, 12 Jul 2025",
+		"StartTime": "6:00 PM",
+		"EndTime":   "7:00 PM",
+		"Price":     2500,
+		// Leave the brand fields empty to preview the default Khel header.
+		"BrandName":  "Dhuku Sports",
+		"BrandColor": "#1D4ED8",
+		"LogoURL":    "",
+	},
+	BookingRejectionTemplate: map[string]any{
+		"Username":   "Aarav",
+		"BookingID":  "rE7xJX1G",
+		"VenueName":  "Dhuku Futsal",
+		"Date":       "Sat, 12 Jul 2025",
+		"StartTime":  "6:00 PM",
+		"EndTime":    "7:00 PM",
+		"BrandName":  "",
+		"BrandColor": "",
+		"LogoURL":    "",
+	},
+	GameInviteTemplate: map[string]any{
+		"Username":    "Aarav",
+		"InviterName": "Sujan",
+		"SportType":   "futsal",
+		"VenueName":   "Dhuku Futsal",
+		"Date":        "Sat, 12 Jul 2025",
+		"StartTime":   "6:00 PM",
+		"GameURL":     "https://khel.example.com/games/17",
+	},
+	ReviewInviteTemplate: map[string]any{
+		"Username":  "Aarav",
+		"VenueName": "Dhuku Futsal",
+		"Date":      "Sat, 12 Jul 2025",
+		"ReviewURL": "https://khel.example.com/venues/8/review",
+	},
+	VenueStaffInviteTemplate: map[string]any{
+		"Username":    "Aarav",
+		"InviterName": "Sujan",
+		"VenueName":   "Dhuku Futsal",
+		"Role":        "manager",
+		"InviteURL":   "https://khel.example.com/venue-invitations",
+	},
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Locales emails can be rendered in.
+const (
+	LocaleEnglish = "en"
+	LocaleNepali  = "ne"
+	DefaultLocale = LocaleEnglish
+)
+
+// NormalizeLocale maps a stored locale or an Accept-Language header
+// ("ne-NP,ne;q=0.9,en;q=0.8") to a supported locale, falling back to
+// DefaultLocale.
+func NormalizeLocale(s string) string {
+	for _, part := range strings.Split(s, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		switch lang {
+		case LocaleEnglish, LocaleNepali:
+			return lang
+		}
+	}
+	return DefaultLocale
+}
+
+// Render builds the subject and HTML body of a template.
+//
+// Each template defines "body" and a default "subject"; a template may add
+// "subject.<locale>" (e.g. "subject.ne") to translate the subject line. The
+// body goes through html/template so user-supplied values are escaped; the
+// subject is a plain header and uses text/template.
+func Render(templateFile, locale string, data any) (subject, body string, err error) {
+	textTmpl, err := texttemplate.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	subjectName := "subject"
+	if t := textTmpl.Lookup("subject." + NormalizeLocale(locale)); t != nil {
+		subjectName = t.Name()
+	}
+	subjectBuf := new(bytes.Buffer)
+	if err := textTmpl.ExecuteTemplate(subjectBuf, subjectName, data); err != nil {
+		return "", "", err
+	}
+
+	htmlTmpl, err := htmltemplate.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return "", "", err
+	}
+	bodyBuf := new(bytes.Buffer)
+	if err := htmlTmpl.ExecuteTemplate(bodyBuf, "body", data); err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subjectBuf.String()), bodyBuf.String(), nil
+}
@@ -0,0 +1,183 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"khel/internal/images"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+type Cloudinary struct {
+	cld *cloudinary.Cloudinary
+}
+
+var _ ObjectStorage = (*Cloudinary)(nil)
+
+func NewCloudinary(cld *cloudinary.Cloudinary) *Cloudinary {
+	return &Cloudinary{cld: cld}
+}
+
+func (c *Cloudinary) Name() string { return "cloudinary" }
+
+func (c *Cloudinary) Upload(ctx context.Context, r io.Reader, opts UploadOptions) (*Object, error) {
+	params := uploader.UploadParams{
+		Folder:         opts.Folder,
+		PublicID:       opts.Name,
+		Overwrite:      api.Bool(opts.Overwrite),
+		Transformation: opts.Transformation,
+	}
+	if opts.Variants {
+		params.Eager = images.Eager()
+		params.EagerAsync = api.Bool(true)
+	}
+	if opts.Private {
+		params.Type = api.Private
+	}
+
+	resp, err := c.cld.Upload.Upload(ctx, r, params)
+	if err == nil && resp.Error.Message != "" {
+		err = errors.New(resp.Error.Message)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cloudinary upload: %w", err)
+	}
+	return &Object{URL: resp.SecureURL, Bytes: int64(resp.Bytes)}, nil
+}
+
+func (c *Cloudinary) Delete(ctx context.Context, photoURL string) error {
+	if !IsCloudinaryURL(photoURL) {
+		return ErrNotOwned
+	}
+	publicID, err := CloudinaryPublicID(photoURL)
+	if err != nil {
+		return fmt.Errorf("failed to extract public ID: %w", err)
+	}
+
+	params := uploader.DestroyParams{PublicID: publicID}
+	if d := cloudinaryDelivery(photoURL); d.private {
+		params.Type, params.ResourceType = api.Private, d.resourceType
+	}
+	_, err = c.cld.Upload.Destroy(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to delete photo from Cloudinary: %w", err)
+	}
+	return nil
+}
+
+// SignedURL returns photoURL for public files. Private ones get a download
+// link through the API that expires after ttl.
+func (c *Cloudinary) SignedURL(_ context.Context, photoURL string, ttl time.Duration) (string, error) {
+	if !IsCloudinaryURL(photoURL) {
+		return "", ErrNotOwned
+	}
+	d := cloudinaryDelivery(photoURL)
+	if !d.private {
+		return photoURL, nil
+	}
+	publicID, err := CloudinaryPublicID(photoURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract public ID: %w", err)
+	}
+	expires := time.Now().Add(ttl)
+	return c.cld.Upload.PrivateDownloadURL(uploader.PrivateDownloadURLParams{
+		PublicID:     publicID,
+		Format:       strings.TrimPrefix(filepath.Ext(d.path), "."),
+		DeliveryType: api.Private,
+		ExpiresAt:    &expires,
+		ResourceType: api.AssetType(d.resourceType),
+	})
+}
+
+func IsCloudinaryURL(photoURL string) bool {
+	u, err := url.Parse(photoURL)
+	return err == nil && u.Host == "res.cloudinary.com" &&
+		(strings.Contains(u.Path, "/upload/") || strings.Contains(u.Path, "/private/"))
+}
+
+type delivery struct {
+	resourceType string
+	private      bool
+	path         string
+}
+
+// cloudinaryDelivery reads the resource and delivery type out of a URL
+// shaped like /<cloud>/<resource type>/<delivery type>/...
+func cloudinaryDelivery(photoURL string) delivery {
+	u, err := url.Parse(photoURL)
+	if err != nil {
+		return delivery{}
+	}
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) < 3 {
+		return delivery{path: u.Path}
+	}
+	return delivery{resourceType: parts[1], private: parts[2] == api.Private, path: u.Path}
+}
+
+// CloudinaryPublicID extracts the public ID from a Cloudinary delivery URL,
+// skipping any transformations and the version.
+func CloudinaryPublicID(photoURL string) (string, error) {
+	parsedURL, err := url.Parse(photoURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL format: %w", err)
+	}
+
+	parts := strings.Split(parsedURL.Path, "/")
+
+	uploadIndex := -1
+	for i, part := range parts {
+		if part == "upload" || part == api.Private {
+			uploadIndex = i
+			break
+		}
+	}
+
+	if uploadIndex == -1 || uploadIndex+1 >= len(parts) {
+		return "", errors.New("failed to find upload path in Cloudinary URL")
+	}
+
+	publicParts := parts[uploadIndex+1:]
+
+	// Skip transformations and version parts until we reach actual folder/public ID.
+	for len(publicParts) > 0 {
+		part := publicParts[0]
+
+		if strings.HasPrefix(part, "v") && len(part) > 1 {
+			if _, err := strconv.ParseInt(part[1:], 10, 64); err == nil {
+				publicParts = publicParts[1:]
+				break
+			}
+		}
+
+		// Transformation usually contains comma, like w_800,h_450,c_fill
+		if strings.Contains(part, ",") {
+			publicParts = publicParts[1:]
+			continue
+		}
+
+		break
+	}
+
+	if len(publicParts) == 0 {
+		return "", errors.New("failed to extract public ID from Cloudinary URL")
+	}
+
+	publicID := strings.Join(publicParts, "/")
+	publicID = strings.TrimSuffix(publicID, filepath.Ext(publicID))
+
+	if publicID == "" {
+		return "", errors.New("empty Cloudinary public ID")
+	}
+
+	return publicID, nil
+}
@@ -0,0 +1,90 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local keeps files in a directory that the API serves itself. It's meant
+// for development, so nothing needs an account to run.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+var _ ObjectStorage = (*Local)(nil)
+
+// NewLocal stores files under dir, to be served at baseURL.
+func NewLocal(dir, baseURL string) *Local {
+	return &Local{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (l *Local) Name() string { return "local" }
+
+func (l *Local) Upload(_ context.Context, r io.Reader, opts UploadOptions) (*Object, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("local upload: read: %w", err)
+	}
+	key, _ := objectKey(opts, body)
+
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("local upload: %w", err)
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !opts.Overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("local upload: %w", err)
+	}
+	if _, err := f.Write(body); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("local upload: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("local upload: %w", err)
+	}
+	return &Object{URL: l.baseURL + "/" + uriEncode(key, false), Bytes: int64(len(body))}, nil
+}
+
+func (l *Local) Delete(_ context.Context, fileURL string) error {
+	path, ok := l.path(fileURL)
+	if !ok {
+		return ErrNotOwned
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("local delete: %w", err)
+	}
+	return nil
+}
+
+// SignedURL returns fileURL: every local file is served publicly.
+func (l *Local) SignedURL(_ context.Context, fileURL string, _ time.Duration) (string, error) {
+	if _, ok := l.path(fileURL); !ok {
+		return "", ErrNotOwned
+	}
+	return fileURL, nil
+}
+
+func (l *Local) path(fileURL string) (string, bool) {
+	escaped, ok := strings.CutPrefix(fileURL, l.baseURL+"/")
+	if !ok {
+		return "", false
+	}
+	key, err := url.PathUnescape(escaped)
+	if err != nil || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", false
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), true
+}
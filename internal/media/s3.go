@@ -0,0 +1,293 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"khel/internal/tracing"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPresignTTL is the longest a SigV4 presigned URL may last.
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// privatePrefix starts the key of every private upload.
+const privatePrefix = "private/"
+
+type S3Config struct {
+	// Endpoint is the service's base URL, e.g. https://s3.ap-south-1.amazonaws.com
+	// or a MinIO/R2 address. Buckets are addressed by path.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicURL is where the bucket is served from, such as a CDN. Empty
+	// means the endpoint, which needs the bucket to allow public reads.
+	// Private uploads go under the private/ prefix, which the bucket policy
+	// and CDN must keep unreadable.
+	PublicURL string
+}
+
+// S3 stores files in an S3-compatible bucket, signing requests with AWS
+// Signature Version 4.
+type S3 struct {
+	cfg        S3Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+var _ ObjectStorage = (*S3)(nil)
+
+func NewS3(cfg S3Config) *S3 {
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+	return &S3{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout:   60 * time.Second,
+			Transport: tracing.Transport(tracing.KindStorage, nil),
+		},
+		now: time.Now,
+	}
+}
+
+func (s *S3) Name() string { return "s3" }
+
+func (s *S3) Upload(ctx context.Context, r io.Reader, opts UploadOptions) (*Object, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("s3 upload: read: %w", err)
+	}
+	key, contentType := objectKey(opts, body)
+	if opts.Private {
+		key = privatePrefix + key
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("s3 upload: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if !opts.Overwrite {
+		req.Header.Set("If-None-Match", "*")
+	}
+	if err := s.do(req, body); err != nil {
+		return nil, fmt.Errorf("s3 upload %s: %w", key, err)
+	}
+	if opts.Private {
+		return &Object{URL: s.objectURL(key), Bytes: int64(len(body))}, nil
+	}
+	return &Object{URL: s.publicURL(key), Bytes: int64(len(body))}, nil
+}
+
+func (s *S3) Delete(ctx context.Context, fileURL string) error {
+	key, ok := s.key(fileURL)
+	if !ok {
+		return ErrNotOwned
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("s3 delete: %w", err)
+	}
+	if err := s.do(req, nil); err != nil {
+		return fmt.Errorf("s3 delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3) SignedURL(_ context.Context, fileURL string, ttl time.Duration) (string, error) {
+	key, ok := s.key(fileURL)
+	if !ok {
+		return "", ErrNotOwned
+	}
+	u, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return "", err
+	}
+	return s.presign(http.MethodGet, u, s.now(), min(ttl, maxPresignTTL)), nil
+}
+
+func (s *S3) objectURL(key string) string {
+	return s.cfg.Endpoint + "/" + uriEncode(s.cfg.Bucket, false) + "/" + uriEncode(key, false)
+}
+
+func (s *S3) publicURL(key string) string {
+	if s.cfg.PublicURL != "" {
+		return s.cfg.PublicURL + "/" + uriEncode(key, false)
+	}
+	return s.objectURL(key)
+}
+
+// key is the object key of a URL handed out by publicURL, or by objectURL
+// for private files.
+func (s *S3) key(fileURL string) (string, bool) {
+	for _, base := range []string{s.cfg.PublicURL, s.cfg.Endpoint + "/" + uriEncode(s.cfg.Bucket, false)} {
+		if base == "" {
+			continue
+		}
+		escaped, ok := strings.CutPrefix(fileURL, base+"/")
+		if !ok || escaped == "" {
+			continue
+		}
+		key, err := url.PathUnescape(escaped)
+		if err != nil {
+			return "", false
+		}
+		return key, true
+	}
+	return "", false
+}
+
+func (s *S3) do(req *http.Request, body []byte) error {
+	sum := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(sum[:]), s.now())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Deleting a missing key succeeds on S3 but is a 404 on some clones.
+	if resp.StatusCode >= 300 && !(req.Method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// sign adds SigV4 headers to req, signing host, the x-amz headers and any
+// content type.
+func (s *S3) sign(req *http.Request, payloadHash string, t time.Time) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	for _, h := range []string{"Content-Type", "If-None-Match"} {
+		if v := req.Header.Get(h); v != "" {
+			headers[strings.ToLower(h)] = v
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(t)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, s.signature(t, amzDate, scope, canonical)))
+}
+
+// presign returns u with a SigV4 query signature valid for ttl from t.
+func (s *S3) presign(method string, u *url.URL, t time.Time, ttl time.Duration) string {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	scope := s.scope(t)
+
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", s.cfg.AccessKeyID+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+	query := canonicalQuery(q)
+
+	canonical := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		query,
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	signed := *u
+	signed.RawQuery = query + "&X-Amz-Signature=" + s.signature(t, amzDate, scope, canonical)
+	return signed.String()
+}
+
+func (s *S3) scope(t time.Time) string {
+	return t.UTC().Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+func (s *S3) signature(t time.Time, amzDate, scope, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), t.UTC().Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, as SigV4
+// wants. Slashes are kept unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
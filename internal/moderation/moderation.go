@@ -0,0 +1,112 @@
+// Package moderation screens text users write (reviews, game chat, profile
+// names) behind a Checker, so the word list it starts with can later be
+// swapped for an external moderation API.
+package moderation
+
+import (
+	"context"
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+// Verdict is a Checker's answer for one piece of text.
+type Verdict struct {
+	Flagged bool
+	// Reason says why the text was flagged, for the moderators.
+	Reason string
+}
+
+// Checker decides whether text needs a moderator to look at it. Callers
+// treat an error as not flagged, so a checker outage never stops users
+// posting.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+//go:embed words.txt
+var defaultWords string
+
+// DefaultWords is the word list the API ships with, one entry per line in
+// words.txt. Blank lines and lines starting with # are skipped.
+func DefaultWords() []string {
+	var words []string
+	for _, line := range strings.Split(defaultWords, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words
+}
+
+// WordList flags text containing any of its words or phrases. Matching is
+// on whole words, ignores case and punctuation, and undoes the common
+// letter-for-digit swaps, so "B4DW0RD!" matches "badword" but "Scunthorpe"
+// doesn't match a word inside it.
+type WordList struct {
+	words   map[string]struct{}
+	phrases [][]string
+}
+
+var _ Checker = (*WordList)(nil)
+
+func NewWordList(words []string) *WordList {
+	wl := &WordList{words: make(map[string]struct{})}
+	for _, w := range words {
+		tokens := tokenize(w)
+		switch len(tokens) {
+		case 0:
+		case 1:
+			wl.words[tokens[0]] = struct{}{}
+		default:
+			wl.phrases = append(wl.phrases, tokens)
+		}
+	}
+	return wl
+}
+
+func (wl *WordList) Name() string { return "wordlist" }
+
+func (wl *WordList) Check(_ context.Context, text string) (Verdict, error) {
+	tokens := tokenize(text)
+	for _, t := range tokens {
+		if _, ok := wl.words[t]; ok {
+			return Verdict{Flagged: true, Reason: "contains a blocked word: " + t}, nil
+		}
+	}
+	for _, p := range wl.phrases {
+		if containsRun(tokens, p) {
+			return Verdict{Flagged: true, Reason: "contains a blocked phrase: " + strings.Join(p, " ")}, nil
+		}
+	}
+	return Verdict{}, nil
+}
+
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// tokenize lowercases text, undoes leetspeak and splits it into words.
+func tokenize(text string) []string {
+	text = leet.Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+func containsRun(tokens, run []string) bool {
+	for i := 0; i+len(run) <= len(tokens); i++ {
+		match := true
+		for j := range run {
+			if tokens[i+j] != run[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWordList(t *testing.T) {
+	wl := NewWordList([]string{"badword", "go away now"})
+
+	tests := []struct {
+		text    string
+		flagged bool
+	}{
+		{"great game today", false},
+		{"what a badword", true},
+		{"B4DW0RD!", true},
+		{"badwords aside", false},
+		{"please go  away, now", true},
+		{"go away", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		v, err := wl.Check(context.Background(), tt.text)
+		if err != nil {
+			t.Fatalf("Check(%q): %v", tt.text, err)
+		}
+		if v.Flagged != tt.flagged {
+			t.Errorf("Check(%q) flagged = %v, want %v", tt.text, v.Flagged, tt.flagged)
+		}
+		if v.Flagged && v.Reason == "" {
+			t.Errorf("Check(%q) flagged without a reason", tt.text)
+		}
+	}
+}
+
+func TestDefaultWordsSkipsComments(t *testing.T) {
+	words := DefaultWords()
+	if len(words) == 0 {
+		t.Fatal("no default words")
+	}
+	for _, w := range words {
+		if w[0] == '#' {
+			t.Errorf("comment line %q in default words", w)
+		}
+	}
+}
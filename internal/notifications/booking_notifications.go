@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"khel/internal/domain/bookings"
+	"khel/internal/domain/notificationprefs"
 	"khel/internal/domain/storage"
+	"strconv"
 
 	"github.com/9ssi7/exponent"
 )
@@ -19,16 +22,6 @@ const (
 )
 
 func SendBookingNotification(ctx context.Context, push PushSender, store *storage.Container, userID int64, event BookingEvent, bookingID string) error {
-	// Fetch tokens for the user
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{userID})
-	if err != nil {
-		return err
-	}
-	tokens := tokensMap[userID]
-	if len(tokens) == 0 {
-		return errors.New("no push tokens")
-	}
-
 	// Prepare Notification Content
 	var title, body string
 	switch event {
@@ -49,6 +42,26 @@ func SendBookingNotification(ctx context.Context, push PushSender, store *storag
 		body = fmt.Sprintf("Your booking (ID: %s) has an update. ", bookingID)
 	}
 
+	//the data field is what your app receives when a push notification is tapped, and it usually drives deep linking
+	data := map[string]string{
+		"type":      "booking",
+		"event":     string(event),
+		"bookingId": bookingID,
+		"screen":    "settings", // / is already at client router.push(`/${data.screen}`);
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	// Fetch tokens for the user
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, []int64{userID})
+	if err != nil {
+		return err
+	}
+	tokens := tokensMap[userID]
+	if len(tokens) == 0 {
+		return errors.New("no push tokens")
+	}
+
 	// Prepare Expo messages
 	msgs := make([]*exponent.Message, 0, len(tokens))
 	for _, t := range tokens {
@@ -58,14 +71,7 @@ func SendBookingNotification(ctx context.Context, push PushSender, store *storag
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			//the data field is what your app receives when a push notification is tapped, and it usually drives deep linking
-			Data: map[string]string{
-				"type":      "booking",
-				"event":     string(event),
-				"bookingId": bookingID,
-				"screen":    "settings", // / is already at client router.push(`/${data.screen}`);
-
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -76,3 +82,97 @@ func SendBookingNotification(ctx context.Context, push PushSender, store *storag
 	}
 	return nil
 }
+
+// SendBookingAutoAccepted - tell the owner a booking request was confirmed
+// for them because it waited past the venue's auto-accept window. The
+// player hears about it through the usual BookingAccepted notification.
+func SendBookingAutoAccepted(ctx context.Context, push PushSender, store *storage.Container, a bookings.AutoAccepted, bookingID string) error {
+
+	title := "Booking auto-accepted"
+	body := fmt.Sprintf("A booking at %s for %s wasn't answered within %d minutes, so it was confirmed automatically.",
+		a.VenueName, a.StartTime.In(nepalTime).Format("Mon 3:04 PM"), a.WaitedMinutes)
+	data := map[string]string{
+		"type":      "booking",
+		"event":     string(BookingAccepted),
+		"reason":    "auto_accepted",
+		"bookingId": bookingID,
+		"venueId":   strconv.FormatInt(a.VenueID, 10),
+		"screen":    "settings",
+	}
+
+	saveToInbox(ctx, store, []int64{a.OwnerID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, []int64{a.OwnerID})
+	if err != nil {
+		return fmt.Errorf("error getting owner tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[a.OwnerID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending auto-accept notification: %w", err)
+	}
+	return nil
+}
+
+// SendBookingExpired tells the player their request was released because
+// the venue didn't answer it in time.
+func SendBookingExpired(ctx context.Context, push PushSender, store *storage.Container, e bookings.Expired, bookingID string) error {
+
+	title := "Booking request expired"
+	when := e.StartTime.In(nepalTime).Format("Mon 3:04 PM")
+	body := fmt.Sprintf("%s didn't answer your request for %s in time, so it was released. Try another slot.", e.VenueName, when)
+	if e.Started {
+		body = fmt.Sprintf("%s didn't answer your request for %s before it started, so it was released.", e.VenueName, when)
+	}
+	data := map[string]string{
+		"type":      "booking",
+		"event":     string(BookingRejected),
+		"reason":    "expired",
+		"bookingId": bookingID,
+		"venueId":   strconv.FormatInt(e.VenueID, 10),
+		"screen":    "settings",
+	}
+
+	saveToInbox(ctx, store, []int64{e.UserID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, []int64{e.UserID})
+	if err != nil {
+		return fmt.Errorf("error getting player tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[e.UserID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending booking expired notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/closures"
+	"khel/internal/domain/games"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/storage"
+	"strconv"
+	"time"
+
+	"github.com/9ssi7/exponent"
+)
+
+// closureReasons phrases a closure reason for the end of "closed due to ...".
+var closureReasons = map[string]string{
+	closures.ReasonFlood:    "flooding",
+	closures.ReasonPowerCut: "a power cut",
+	closures.ReasonWeather:  "bad weather",
+	closures.ReasonOther:    "an emergency",
+}
+
+func closureReason(reason string) string {
+	if l, ok := closureReasons[reason]; ok {
+		return l
+	}
+	return closureReasons[closures.ReasonOther]
+}
+
+// SendClosureBookingCanceled - tell a player their booking was canceled by an
+// emergency closure, and how much comes back to them.
+func SendClosureBookingCanceled(ctx context.Context, push PushSender, store *storage.Container, userID int64, bookingID string, venueName, reason string, start time.Time, refundAmount int) error {
+
+	title := "Booking canceled: venue closed"
+	body := fmt.Sprintf("%s is closed due to %s, so your booking on %s was canceled.",
+		venueName, closureReason(reason), start.In(nepalTime).Format("Mon 3:04 PM"))
+	if refundAmount > 0 {
+		body += fmt.Sprintf(" Rs. %d is being refunded in full.", refundAmount)
+	}
+	data := map[string]string{
+		"type":      "booking",
+		"event":     string(BookingCanceled),
+		"reason":    "venue_closed",
+		"bookingId": bookingID,
+		"screen":    "settings",
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, []int64{userID})
+	if err != nil {
+		return fmt.Errorf("error getting user tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[userID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending closure booking notification: %w", err)
+	}
+	return nil
+}
+
+// SendClosureGameCanceled - tell the players, the bench and anyone still
+// waiting on a join request that the game was called off by a closure.
+func SendClosureGameCanceled(ctx context.Context, push PushSender, store *storage.Container, g closures.CanceledGame, venueName, reason string) error {
+
+	playerIDs, err := store.Games.GetAllGamePlayerIDs(ctx, g.ID)
+	if err != nil {
+		return fmt.Errorf("error getting game players: %w", err)
+	}
+	benchIDs, err := store.Games.GetBenchPlayerIDs(ctx, g.ID)
+	if err != nil {
+		return fmt.Errorf("error getting bench players: %w", err)
+	}
+	userIDs := append(append(playerIDs, benchIDs...), g.RejectedRequesters...)
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	title := "Game canceled: venue closed"
+	game := "game"
+	if g.SportType != "" {
+		game = g.SportType + " game"
+	}
+	body := fmt.Sprintf("%s is closed due to %s, so your %s on %s was canceled.",
+		venueName, closureReason(reason), game, g.StartTime.In(nepalTime).Format("Mon 3:04 PM"))
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(g.ID, 10))
+	data := map[string]string{
+		"type":    "game_canceled",
+		"reason":  games.CancelReasonVenueClosed,
+		"game_id": strconv.FormatInt(g.ID, 10),
+		"screen":  screen,
+	}
+
+	saveToInbox(ctx, store, userIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting player tokens: %w", err)
+	}
+
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending closure game notifications: %w", err)
+	}
+	return nil
+}
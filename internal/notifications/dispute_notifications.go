@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendDisputeUpdate - tell the parties of a booking dispute that it was opened or resolved.
+func SendDisputeUpdate(ctx context.Context, push PushSender, store *storage.Container, userIDs []int64, disputeID, bookingID int64, title, body string) error {
+
+	data := map[string]string{
+		"type":       "booking_dispute",
+		"dispute_id": strconv.FormatInt(disputeID, 10),
+		"bookingId":  strconv.FormatInt(bookingID, 10),
+		"screen":     fmt.Sprintf("disputes/%s", strconv.FormatInt(disputeID, 10)),
+	}
+
+	saveToInbox(ctx, store, userIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting dispute party tokens: %w", err)
+	}
+
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending dispute notification: %w", err)
+	}
+	return nil
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"khel/internal/domain/gamepayments"
+	"khel/internal/domain/games"
+	"khel/internal/domain/notificationprefs"
 	"khel/internal/domain/storage"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/9ssi7/exponent"
@@ -14,7 +19,19 @@ import (
 // SendJoinRequestToAdmin - notify game admin(s) that a user requested to join with requesterName
 func SendJoinRequestToAdmin(ctx context.Context, push PushSender, store *storage.Container, AdminID int64, gameID int64, requesterName string) error {
 
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{AdminID})
+	title := "New game join request"
+	body := fmt.Sprintf("%s has sent a join request", requesterName)
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "game_join_request",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, []int64{AdminID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, []int64{AdminID})
 	if err != nil {
 		return err
 	}
@@ -26,9 +43,6 @@ func SendJoinRequestToAdmin(ctx context.Context, push PushSender, store *storage
 	//Prepare expo messages
 
 	msgs := make([]*exponent.Message, 0, len(tokens))
-	title := "New game join request"
-	body := fmt.Sprintf("%s has sent a join request", requesterName)
-	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
 	for _, t := range tokens {
 		//wrap the string token in exponent.Token to satisfy the type
 
@@ -37,12 +51,7 @@ func SendJoinRequestToAdmin(ctx context.Context, push PushSender, store *storage
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			Data: map[string]string{
-				"type":    "game_join_request",
-				"game_id": strconv.FormatInt(gameID, 10),
-				"screen":  screen,
-				//in client we do router.push(`/${data.screen}`)
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -65,7 +74,19 @@ func SendDeleteJoinRequestToAdmin(ctx context.Context, push PushSender, store *s
 		return err
 	}
 
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{AdminID})
+	title := "Join request withdrawn"
+	body := fmt.Sprintf("%s has withdrew join request", requesterName)
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "game_delete_join_request",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, []int64{AdminID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, []int64{AdminID})
 	if err != nil {
 		return err
 	}
@@ -78,9 +99,6 @@ func SendDeleteJoinRequestToAdmin(ctx context.Context, push PushSender, store *s
 	//Prepare expo messages
 
 	msgs := make([]*exponent.Message, 0, len(tokens))
-	title := "Join request withdrawn"
-	body := fmt.Sprintf("%s has withdrew join request", requesterName)
-	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
 	for _, t := range tokens {
 		//wrap the string token in exponent.Token to satisfy the type
 
@@ -89,12 +107,7 @@ func SendDeleteJoinRequestToAdmin(ctx context.Context, push PushSender, store *s
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			Data: map[string]string{
-				"type":    "game_delete_join_request",
-				"game_id": strconv.FormatInt(gameID, 10),
-				"screen":  screen,
-				//in client we do router.push(`/${data.screen}`)
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -109,7 +122,19 @@ func SendDeleteJoinRequestToAdmin(ctx context.Context, push PushSender, store *s
 // SendRejectJoinRequestToUser - notify the requesting user that request was rejected by the game admin
 func SendRejectJoinRequestToUser(ctx context.Context, push PushSender, store *storage.Container, userID int64, gameID int64) error {
 
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{userID})
+	title := "Join request rejected"
+	body := "Your request to join the game was not accepted"
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "reject_game_join_request",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, []int64{userID})
 	if err != nil {
 		return err
 	}
@@ -122,9 +147,6 @@ func SendRejectJoinRequestToUser(ctx context.Context, push PushSender, store *st
 	//Prepare expo messages
 
 	msgs := make([]*exponent.Message, 0, len(tokens))
-	title := "Join request rejected"
-	body := "Your request to join the game was not accepted"
-	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
 	for _, t := range tokens {
 		//wrap the string token in exponent.Token to satisfy the type
 
@@ -133,12 +155,7 @@ func SendRejectJoinRequestToUser(ctx context.Context, push PushSender, store *st
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			Data: map[string]string{
-				"type":    "reject_game_join_request",
-				"game_id": strconv.FormatInt(gameID, 10),
-				"screen":  screen,
-				//in client we do router.push(`/${data.screen}`)
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -153,7 +170,19 @@ func SendRejectJoinRequestToUser(ctx context.Context, push PushSender, store *st
 // SendAcceptJoinRequestToUser - notify the requesting user that request was rejected by the game admin
 func SendAcceptJoinRequestToUser(ctx context.Context, push PushSender, store *storage.Container, userID int64, gameID int64) error {
 
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{userID})
+	title := "Join request accepted"
+	body := "Your game join request was accepted"
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "accept_game_join_request",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, []int64{userID})
 	if err != nil {
 		return err
 	}
@@ -166,9 +195,6 @@ func SendAcceptJoinRequestToUser(ctx context.Context, push PushSender, store *st
 	//Prepare expo messages
 
 	msgs := make([]*exponent.Message, 0, len(tokens))
-	title := "Join request accepted"
-	body := "Your game join request was accepted"
-	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
 	for _, t := range tokens {
 		//wrap the string token in exponent.Token to satisfy the type
 
@@ -177,12 +203,7 @@ func SendAcceptJoinRequestToUser(ctx context.Context, push PushSender, store *st
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			Data: map[string]string{
-				"type":    "accept_game_join_request",
-				"game_id": strconv.FormatInt(gameID, 10),
-				"screen":  screen,
-				//in client we do router.push(`/${data.screen}`)
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -203,12 +224,31 @@ func SendCancelGameToPlayers(ctx context.Context, push PushSender, store *storag
 		return fmt.Errorf("error getting game players: %w", err)
 	}
 
+	// Bench players were waiting for a seat, so they hear about it too
+	benchIDs, err := store.Games.GetBenchPlayerIDs(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("error getting bench players: %w", err)
+	}
+	playerIDs = append(playerIDs, benchIDs...)
+
 	if len(playerIDs) == 0 {
 		return errors.New("no players found for the game")
 	}
 
+	title := "Game Canceled"
+	body := "The game you were registered for has been canceled"
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "game_canceled",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, playerIDs, title, body, data)
+
 	// Get push tokens for all players
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, playerIDs)
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, playerIDs)
 	if err != nil {
 		return fmt.Errorf("error getting player tokens: %w", err)
 	}
@@ -227,9 +267,6 @@ func SendCancelGameToPlayers(ctx context.Context, push PushSender, store *storag
 
 	// Prepare expo messages
 	msgs := make([]*exponent.Message, 0, len(allTokens))
-	title := "Game Canceled"
-	body := "The game you were registered for has been canceled"
-	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
 
 	for _, t := range compactTokens {
 		token := exponent.Token(t)
@@ -237,12 +274,7 @@ func SendCancelGameToPlayers(ctx context.Context, push PushSender, store *storag
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			Data: map[string]string{
-				"type":    "game_canceled",
-				"game_id": strconv.FormatInt(gameID, 10),
-				"screen":  screen,
-				// Client will navigate to games list
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -266,7 +298,19 @@ func NotifyGameQuestionToAdmin(ctx context.Context, push PushSender, store *stor
 		return err
 	}
 
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{AdminID})
+	title := "New game Message"
+	body := fmt.Sprintf("%s has sent a message", requesterName)
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "game_message_send",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, []int64{AdminID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, []int64{AdminID})
 	if err != nil {
 		return err
 	}
@@ -279,9 +323,6 @@ func NotifyGameQuestionToAdmin(ctx context.Context, push PushSender, store *stor
 	//Prepare expo messages
 
 	msgs := make([]*exponent.Message, 0, len(tokens))
-	title := "New game Message"
-	body := fmt.Sprintf("%s has sent a message", requesterName)
-	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
 	for _, t := range tokens {
 		//wrap the string token in exponent.Token to satisfy the type
 
@@ -290,12 +331,7 @@ func NotifyGameQuestionToAdmin(ctx context.Context, push PushSender, store *stor
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			Data: map[string]string{
-				"type":    "game_message_send",
-				"game_id": strconv.FormatInt(gameID, 10),
-				"screen":  screen,
-				//in client we do router.push(`/${data.screen}`)
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -317,7 +353,19 @@ func SendQuestionReply(ctx context.Context, push PushSender, store *storage.Cont
 		return err
 	}
 
-	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{userID})
+	title := "New reply to your question"
+	body := "Admin has reply your question"
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "game_reply_send",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, []int64{userID})
 	if err != nil {
 		return err
 	}
@@ -330,9 +378,6 @@ func SendQuestionReply(ctx context.Context, push PushSender, store *storage.Cont
 	//Prepare expo messages
 
 	msgs := make([]*exponent.Message, 0, len(tokens))
-	title := "New reply to your question"
-	body := "Admin has reply your question"
-	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
 	for _, t := range tokens {
 		//wrap the string token in exponent.Token to satisfy the type
 
@@ -341,12 +386,7 @@ func SendQuestionReply(ctx context.Context, push PushSender, store *storage.Cont
 			To:    []*exponent.Token{&token},
 			Title: title,
 			Body:  body,
-			Data: map[string]string{
-				"type":    "game_reply_send",
-				"game_id": strconv.FormatInt(gameID, 10),
-				"screen":  screen,
-				//in client we do router.push(`/${data.screen}`)
-			},
+			Data:  data,
 		}
 		msgs = append(msgs, msg)
 	}
@@ -357,3 +397,392 @@ func SendQuestionReply(ctx context.Context, push PushSender, store *storage.Cont
 	return nil
 
 }
+
+// SendGameSummary - after a game completes, invite its players to rate each
+// other and vote for an MVP. No tokens is not an error here: this runs as a
+// job and would otherwise be retried for nothing.
+func SendGameSummary(ctx context.Context, push PushSender, store *storage.Container, gameID int64, sportType string, ratingClosesAt time.Time) error {
+
+	playerIDs, err := store.Games.GetAllGamePlayerIDs(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("error getting game players: %w", err)
+	}
+	if len(playerIDs) == 0 {
+		return nil
+	}
+
+	title := "Good game! 🏆"
+	game := "game"
+	if sportType != "" {
+		game = sportType + " game"
+	}
+	body := fmt.Sprintf("How was your %s? Rate your teammates and vote for the MVP before %s.",
+		game, ratingClosesAt.In(nepalTime).Format("Mon 3:04 PM"))
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "game_completed",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, playerIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, playerIDs)
+	if err != nil {
+		return fmt.Errorf("error getting player tokens: %w", err)
+	}
+
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending game summary notifications: %w", err)
+	}
+	return nil
+}
+
+// SendGameAutoCancelled - tell the players, and anyone still waiting on a
+// join request, that the game was called off for lack of players.
+func SendGameAutoCancelled(ctx context.Context, push PushSender, store *storage.Container, g games.AutoCancelledGame) error {
+
+	playerIDs, err := store.Games.GetAllGamePlayerIDs(ctx, g.ID)
+	if err != nil {
+		return fmt.Errorf("error getting game players: %w", err)
+	}
+	benchIDs, err := store.Games.GetBenchPlayerIDs(ctx, g.ID)
+	if err != nil {
+		return fmt.Errorf("error getting bench players: %w", err)
+	}
+	userIDs := append(append(playerIDs, benchIDs...), g.RejectedRequesters...)
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	title := "Game canceled"
+	game := "game"
+	if g.SportType != "" {
+		game = g.SportType + " game"
+	}
+	body := fmt.Sprintf("Your %s on %s was canceled: it needed %d players and had %d.",
+		game, g.StartTime.In(nepalTime).Format("Mon 3:04 PM"), g.MinPlayers, g.PlayerCount)
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(g.ID, 10))
+	data := map[string]string{
+		"type":    "game_canceled",
+		"reason":  games.CancelReasonInsufficientPlayers,
+		"game_id": strconv.FormatInt(g.ID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, userIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting player tokens: %w", err)
+	}
+
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending auto-cancel notifications: %w", err)
+	}
+	return nil
+}
+
+// gameFieldLabels names the edited game fields the way players read them.
+var gameFieldLabels = map[string]string{
+	"start_time":  "time",
+	"end_time":    "time",
+	"max_players": "player limit",
+	"price":       "price",
+	"format":      "format",
+	"game_level":  "level",
+	"visibility":  "visibility",
+	"instruction": "instructions",
+
+	"safety_info":             "safety info",
+	"emergency_contact_name":  "emergency contact",
+	"emergency_contact_phone": "emergency contact",
+}
+
+// SendGameUpdatedToPlayers - tell everyone in the game, and on its bench,
+// which details the organiser changed. The editor isn't notified.
+func SendGameUpdatedToPlayers(ctx context.Context, push PushSender, store *storage.Container, gameID, editorID int64, changes []games.FieldChange) error {
+
+	playerIDs, err := store.Games.GetAllGamePlayerIDs(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("error getting game players: %w", err)
+	}
+	benchIDs, err := store.Games.GetBenchPlayerIDs(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("error getting bench players: %w", err)
+	}
+	userIDs := make([]int64, 0, len(playerIDs)+len(benchIDs))
+	for _, id := range append(playerIDs, benchIDs...) {
+		if id != editorID {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	labels := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if l, ok := gameFieldLabels[c.Field]; ok && !slices.Contains(labels, l) {
+			labels = append(labels, l)
+		}
+	}
+	body := fmt.Sprintf("The organiser changed the %s.", joinLabels(labels))
+	for _, c := range changes {
+		if start, ok := c.New.(time.Time); ok && c.Field == "start_time" {
+			body += fmt.Sprintf(" It now starts %s.", start.In(nepalTime).Format("Mon 3:04 PM"))
+		}
+	}
+
+	title := "Game updated"
+	screen := fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10))
+	data := map[string]string{
+		"type":    "game_updated",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  screen,
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, userIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting player tokens: %w", err)
+	}
+
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending game updated notifications: %w", err)
+	}
+	return nil
+}
+
+// joinLabels turns ["time", "price", "level"] into "time, price and level".
+func joinLabels(labels []string) string {
+	switch len(labels) {
+	case 0:
+		return "details"
+	case 1:
+		return labels[0]
+	}
+	return strings.Join(labels[:len(labels)-1], ", ") + " and " + labels[len(labels)-1]
+}
+
+// SendBenchedToUser - tell an accepted user the game is full and they are
+// on the bench, with their place in the queue.
+func SendBenchedToUser(ctx context.Context, push PushSender, store *storage.Container, userID, gameID int64, position int) error {
+	title := "You're on the bench"
+	body := fmt.Sprintf("The game is full, so you're number %d on the bench. We'll move you in as soon as a spot opens up.", position)
+	data := map[string]string{
+		"type":    "game_benched",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10)),
+		//in client we do router.push(`/${data.screen}`)
+	}
+	return sendGameUserPush(ctx, push, store, userID, title, body, data)
+}
+
+// SendBenchPromotedToUser - tell a bench player a spot opened up and they
+// are now playing.
+func SendBenchPromotedToUser(ctx context.Context, push PushSender, store *storage.Container, userID, gameID int64) error {
+	title := "You're in! 🎉"
+	body := "A spot opened up and you've been moved from the bench into the game."
+	data := map[string]string{
+		"type":    "game_bench_promoted",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10)),
+		//in client we do router.push(`/${data.screen}`)
+	}
+	return sendGameUserPush(ctx, push, store, userID, title, body, data)
+}
+
+// SendRemovedFromGame - tell a player the admin or an assistant took them
+// out of the game.
+func SendRemovedFromGame(ctx context.Context, push PushSender, store *storage.Container, userID, gameID int64) error {
+	title := "Removed from game"
+	body := "The organiser removed you from a game you had joined."
+	data := map[string]string{
+		"type":    "game_player_removed",
+		"game_id": strconv.FormatInt(gameID, 10),
+	}
+	return sendGameUserPush(ctx, push, store, userID, title, body, data)
+}
+
+// SendPlayerJoinedToAdmin - tell the admin a player was seated straight
+// away under the game's auto-accept policy.
+func SendPlayerJoinedToAdmin(ctx context.Context, push PushSender, store *storage.Container, adminID, gameID int64, playerName string) error {
+	title := "New player joined"
+	body := fmt.Sprintf("%s joined your game", playerName)
+	data := map[string]string{
+		"type":    "game_player_joined",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10)),
+		//in client we do router.push(`/${data.screen}`)
+	}
+	return sendGameUserPush(ctx, push, store, adminID, title, body, data)
+}
+
+// SendJoinRequestExpired - tell a requester their request closed because the
+// game started, or stopped running, before the admin answered.
+func SendJoinRequestExpired(ctx context.Context, push PushSender, store *storage.Container, userID, gameID int64) error {
+	title := "Join request expired"
+	body := "Your request to join the game closed without an answer from the organiser."
+	data := map[string]string{
+		"type":    "game_join_request_expired",
+		"game_id": strconv.FormatInt(gameID, 10),
+	}
+	return sendGameUserPush(ctx, push, store, userID, title, body, data)
+}
+
+// SendGameInvitation - tell invited users someone wants them in a game.
+func SendGameInvitation(ctx context.Context, push PushSender, store *storage.Container, userIDs []int64, gameID int64, inviterName string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	title := "You're invited to a game"
+	body := fmt.Sprintf("%s invited you to join their game", inviterName)
+	data := map[string]string{
+		"type":    "game_invitation",
+		"game_id": strconv.FormatInt(gameID, 10),
+		"screen":  fmt.Sprintf("games/%s", strconv.FormatInt(gameID, 10)),
+		//in client we do router.push(`/${data.screen}`)
+	}
+
+	saveToInbox(ctx, store, userIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting invitee tokens: %w", err)
+	}
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending game invitations: %w", err)
+	}
+	return nil
+}
+
+// SendGamePaymentReminder - nudge a player who hasn't paid the admin for a
+// game they played.
+func SendGamePaymentReminder(ctx context.Context, push PushSender, store *storage.Container, rm gamepayments.Reminder) error {
+	playedOn := rm.StartTime.In(nepalTime).Format("Mon Jan 2")
+	title := "Settle up for your game 💸"
+	body := fmt.Sprintf("You still owe %s Rs. %d for the %s game on %s.", rm.AdminName, rm.Amount, rm.SportType, playedOn)
+	data := map[string]string{
+		"type":    "game_payment_reminder",
+		"game_id": strconv.FormatInt(rm.GameID, 10),
+		"screen":  fmt.Sprintf("games/%s", strconv.FormatInt(rm.GameID, 10)),
+		//in client we do router.push(`/${data.screen}`)
+	}
+	return sendGameUserPush(ctx, push, store, rm.UserID, title, body, data)
+}
+
+func sendGameUserPush(ctx context.Context, push PushSender, store *storage.Container, userID int64, title, body string, data map[string]string) error {
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryGameInvites, []int64{userID})
+	if err != nil {
+		return fmt.Errorf("error getting user tokens: %w", err)
+	}
+	tokens := dedupe(tokensMap[userID])
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(tokens))
+	for _, t := range tokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending %s notification: %w", data["type"], err)
+	}
+	return nil
+}
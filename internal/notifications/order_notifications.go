@@ -0,0 +1,59 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/accesscontrol"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendOrderCancelledToMerchants - tell the store's merchants a buyer
+// cancelled an order so they do not pack it.
+func SendOrderCancelledToMerchants(ctx context.Context, push PushSender, store *storage.Container, orderID int64, orderNumber, body string) error {
+	merchantIDs, err := store.AccessControl.UserIDsWithRole(ctx, string(accesscontrol.RoleMerchant))
+	if err != nil {
+		return fmt.Errorf("error getting merchants: %w", err)
+	}
+	if len(merchantIDs) == 0 {
+		return nil
+	}
+
+	title := "Order " + orderNumber + " cancelled"
+	data := map[string]string{
+		"type":     "order_cancelled",
+		"order_id": strconv.FormatInt(orderID, 10),
+		"screen":   fmt.Sprintf("store/admin/orders/%d", orderID),
+	}
+
+	saveToInbox(ctx, store, merchantIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, merchantIDs)
+	if err != nil {
+		return fmt.Errorf("error getting merchant tokens: %w", err)
+	}
+
+	var msgs []*exponent.Message
+	for _, userID := range merchantIDs {
+		for _, tk := range dedupe(tokensMap[userID]) {
+			token := exponent.Token(tk)
+			msgs = append(msgs, &exponent.Message{
+				To:    []*exponent.Token{&token},
+				Title: title,
+				Body:  body,
+				Data:  data,
+			})
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending order cancelled notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/pricealerts"
+	"khel/internal/domain/storage"
+	"strconv"
+	"strings"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendPriceAlert - tell a user that a slot they watch dropped below their
+// price. The user asked for it, so it is not gated by marketing preferences.
+func SendPriceAlert(ctx context.Context, push PushSender, store *storage.Container, t pricealerts.Triggered) error {
+	day := t.DayOfWeek
+	if day != "" {
+		day = strings.ToUpper(day[:1]) + day[1:]
+	}
+
+	title := fmt.Sprintf("Price drop at %s 💸", t.VenueName)
+	body := fmt.Sprintf("%s %s is now Rs. %d/hr.", day, t.StartTime, t.Price)
+	data := map[string]string{
+		"type":     "price_alert",
+		"alert_id": strconv.FormatInt(t.AlertID, 10),
+		"venue_id": strconv.FormatInt(t.VenueID, 10),
+		"screen":   fmt.Sprintf("venues/%s", strconv.FormatInt(t.VenueID, 10)),
+	}
+
+	saveToInbox(ctx, store, []int64{t.UserID}, title, body, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{t.UserID})
+	if err != nil {
+		return fmt.Errorf("error getting price alert tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[t.UserID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, tk := range compactTokens {
+		token := exponent.Token(tk)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending price alert: %w", err)
+	}
+	return nil
+}
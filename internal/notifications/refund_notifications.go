@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendRefundUpdate - tell a player their refund was approved, rejected or paid out.
+func SendRefundUpdate(ctx context.Context, push PushSender, store *storage.Container, userID, refundID, bookingID int64, title, body string) error {
+	data := map[string]string{
+		"type":      "refund",
+		"refund_id": strconv.FormatInt(refundID, 10),
+		"bookingId": strconv.FormatInt(bookingID, 10),
+		"screen":    fmt.Sprintf("bookings/%s/refund", strconv.FormatInt(bookingID, 10)),
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, []int64{userID})
+	if err != nil {
+		return fmt.Errorf("error getting refund tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[userID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, tk := range compactTokens {
+		token := exponent.Token(tk)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending refund notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/reminders"
+	"khel/internal/domain/storage"
+	"strconv"
+	"time"
+
+	"github.com/9ssi7/exponent"
+)
+
+// nepalTime is where start times are shown. Nepal has no DST, so the fixed
+// +05:45 offset is an exact fallback when tzdata is missing.
+var nepalTime = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return time.FixedZone("NPT", 5*60*60+45*60)
+	}
+	return loc
+}()
+
+// SendStartReminder - remind users that their booking or game starts soon.
+// Reminders are only useful until the start time, so they are not kept in
+// the inbox.
+func SendStartReminder(ctx context.Context, push PushSender, store *storage.Container, userIDs []int64, due reminders.Due, lead time.Duration) error {
+	startsAt := due.StartTime.In(nepalTime).Format("3:04 PM")
+
+	var title, body, screen string
+	switch due.SubjectType {
+	case reminders.SubjectGame:
+		sport := "Your game"
+		if due.SportType != nil && *due.SportType != "" {
+			sport = fmt.Sprintf("Your %s game", *due.SportType)
+		}
+		title = fmt.Sprintf("Game starts in %s ⏰", formatLead(lead))
+		body = fmt.Sprintf("%s at %s starts at %s.", sport, due.VenueName, startsAt)
+		if due.SafetyInfo != nil && *due.SafetyInfo != "" {
+			body += " " + *due.SafetyInfo
+		}
+		if due.EmergencyContactPhone != nil && *due.EmergencyContactPhone != "" {
+			contact := *due.EmergencyContactPhone
+			if due.EmergencyContactName != nil && *due.EmergencyContactName != "" {
+				contact = fmt.Sprintf("%s (%s)", *due.EmergencyContactName, contact)
+			}
+			body += fmt.Sprintf(" Emergency contact: %s.", contact)
+		}
+		screen = fmt.Sprintf("games/%s", strconv.FormatInt(due.SubjectID, 10))
+	default:
+		title = fmt.Sprintf("Booking starts in %s ⏰", formatLead(lead))
+		body = fmt.Sprintf("Your booking at %s starts at %s.", due.VenueName, startsAt)
+		screen = "settings"
+	}
+
+	data := map[string]string{
+		"type":       "reminder",
+		"subject":    due.SubjectType,
+		"subject_id": strconv.FormatInt(due.SubjectID, 10),
+		"screen":     screen,
+	}
+	if due.EmergencyContactPhone != nil && *due.EmergencyContactPhone != "" {
+		data["emergency_contact_phone"] = *due.EmergencyContactPhone
+	}
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryReminders, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting reminder tokens: %w", err)
+	}
+
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending start reminder: %w", err)
+	}
+	return nil
+}
+
+func formatLead(lead time.Duration) string {
+	if lead >= time.Hour && lead%time.Hour == 0 {
+		if lead == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", int(lead/time.Hour))
+	}
+	return fmt.Sprintf("%d minutes", int(lead/time.Minute))
+}
+
+// SendReviewInvite - ask a player to rate the venue they just played at.
+// The tap opens the venue's review form.
+func SendReviewInvite(ctx context.Context, push PushSender, store *storage.Container, inv reminders.ReviewInvite) error {
+	title := "How was your game? ⭐"
+	body := fmt.Sprintf("Rate %s and help other players pick where to play.", inv.VenueName)
+
+	data := map[string]string{
+		"type":      "review_invite",
+		"venueId":   strconv.FormatInt(inv.VenueID, 10),
+		"bookingId": strconv.FormatInt(inv.BookingID, 10),
+		"screen":    fmt.Sprintf("venues/%s/review", strconv.FormatInt(inv.VenueID, 10)),
+	}
+
+	userIDs := []int64{inv.UserID}
+	saveToInbox(ctx, store, userIDs, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryReminders, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting review invite tokens: %w", err)
+	}
+	compactTokens := dedupe(tokensMap[inv.UserID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending review invite: %w", err)
+	}
+	return nil
+}
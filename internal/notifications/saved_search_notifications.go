@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/savedsearches"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendSavedSearchMatch - tell a user that a new game fits one of their saved
+// searches. The user asked for it, so it is not gated by preferences.
+func SendSavedSearchMatch(ctx context.Context, push PushSender, store *storage.Container, m savedsearches.Match) error {
+	title := fmt.Sprintf("New %s game near you ⚽", m.SportType)
+	startsAt := m.StartTime.In(nepalTime).Format("Mon 3:04 PM")
+	body := fmt.Sprintf("%s at %s matches %q.", startsAt, m.VenueName, m.SearchName)
+	if m.Price != nil {
+		body = fmt.Sprintf("%s at %s for Rs. %d matches %q.", startsAt, m.VenueName, *m.Price, m.SearchName)
+	}
+	data := map[string]string{
+		"type":      "saved_search_match",
+		"search_id": strconv.FormatInt(m.SearchID, 10),
+		"game_id":   strconv.FormatInt(m.GameID, 10),
+		"screen":    fmt.Sprintf("games/%s", strconv.FormatInt(m.GameID, 10)),
+	}
+
+	saveToInbox(ctx, store, []int64{m.UserID}, title, body, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{m.UserID})
+	if err != nil {
+		return fmt.Errorf("error getting saved search tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[m.UserID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, tk := range compactTokens {
+		token := exponent.Token(tk)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending saved search match: %w", err)
+	}
+	return nil
+}
@@ -3,14 +3,26 @@ package notifications
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/storage"
 	"time"
 
 	"github.com/9ssi7/exponent"
+	"go.uber.org/zap"
 )
 
 const DefaultAsyncTimeout = 8 * time.Second
 
+// logger reports failures the senders swallow so the push still goes out,
+// such as saving to the inbox. It discards everything until SetLogger.
+var logger = zap.NewNop().Sugar()
+
+// SetLogger sets the logger the package reports swallowed failures to.
+func SetLogger(l *zap.SugaredLogger) {
+	logger = l
+}
+
 // PushSender is just an abstraction over any push sender,
 // but here it's directly tied to the exponent SDK types.
 type PushSender interface {
@@ -18,8 +30,9 @@ type PushSender interface {
 	PublishSingle(ctx context.Context, msg *exponent.Message) ([]*exponent.MessageResponse, error)
 }
 
-// CallAsync = run my function asynchronously with a timeout and print error log
-func CallAsync(fn func(ctx context.Context) error, operationName string) {
+// CallAsync runs fn in the background with a 5 second timeout and logs the
+// outcome to logger.
+func CallAsync(logger *zap.SugaredLogger, fn func(ctx context.Context) error, operationName string) {
 	go func() {
 		start := time.Now()
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -27,22 +40,43 @@ func CallAsync(fn func(ctx context.Context) error, operationName string) {
 
 		if err := fn(ctx); err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
-				log.Printf("🚨 TIMEOUT: %s took too long (>5s)", operationName)
+				logger.Errorw("async operation timed out", "operation", operationName, "timeout", 5*time.Second)
 			} else {
-				log.Printf("❌ ERROR: %s failed: %v", operationName, err)
+				logger.Errorw("async operation failed", "operation", operationName, "error", err)
 			}
-		} else {
-			duration := time.Since(start)
-			log.Printf("✅ SUCCESS: %s completed in %v", operationName, duration)
+			return
+		}
 
-			// Warn if it's getting slow but didn't timeout
-			if duration > 3*time.Second {
-				log.Printf("⚠️  WARNING: %s is slow: %v", operationName, duration)
-			}
+		duration := time.Since(start)
+		// Warn if it's getting slow but didn't timeout
+		if duration > 3*time.Second {
+			logger.Warnw("async operation is slow", "operation", operationName, "duration", duration)
+			return
 		}
+		logger.Debugw("async operation completed", "operation", operationName, "duration", duration)
 	}()
 }
 
+// pushTokensFor returns push tokens for the users who have not opted out of
+// push for the category. Every categorised sender goes through here so
+// preferences are checked before anything is dispatched.
+func pushTokensFor(ctx context.Context, store *storage.Container, category notificationprefs.Category, userIDs []int64) (map[int64][]string, error) {
+	allowed, err := store.NotificationPrefs.FilterAllowed(ctx, userIDs, category, notificationprefs.ChannelPush)
+	if err != nil {
+		return nil, fmt.Errorf("check notification preferences: %w", err)
+	}
+	return store.PushTokens.GetTokensByUserIDs(ctx, allowed)
+}
+
+// saveToInbox records the notification in each recipient's in-app inbox. It
+// runs before the push so users without tokens, or who opted out of push,
+// still see it. A failure is logged and the push goes out regardless.
+func saveToInbox(ctx context.Context, store *storage.Container, userIDs []int64, title, body string, data map[string]string) {
+	if err := store.Inbox.Create(ctx, userIDs, data["type"], title, body, data); err != nil {
+		logger.Errorw("failed to save notification to inbox", "type", data["type"], "users", len(userIDs), "error", err)
+	}
+}
+
 func dedupe(tokens []string) []string {
 	// set a already seem tokens
 	seen := map[string]struct{}{}
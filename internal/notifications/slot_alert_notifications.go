@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/slotalerts"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendSlotFreed - tell a user that a slot they were waiting for at a venue
+// was just freed. The user asked for it, so it is not gated by preferences.
+func SendSlotFreed(ctx context.Context, push PushSender, store *storage.Container, t slotalerts.Triggered) error {
+	where := t.VenueName
+	if t.FacilityName != "" {
+		where = fmt.Sprintf("%s (%s)", t.VenueName, t.FacilityName)
+	}
+	start := t.StartTime.In(nepalTime)
+	title := fmt.Sprintf("A slot opened up at %s ⏰", t.VenueName)
+	body := fmt.Sprintf("%s %s-%s at %s is free again. Book it before someone else does.",
+		start.Format("Mon Jan 2"), start.Format("3:04 PM"), t.EndTime.In(nepalTime).Format("3:04 PM"), where)
+	data := map[string]string{
+		"type":        "slot_alert",
+		"alert_id":    strconv.FormatInt(t.AlertID, 10),
+		"venue_id":    strconv.FormatInt(t.VenueID, 10),
+		"facility_id": strconv.FormatInt(t.FacilityID, 10),
+		"screen":      fmt.Sprintf("venues/%s", strconv.FormatInt(t.VenueID, 10)),
+	}
+
+	saveToInbox(ctx, store, []int64{t.UserID}, title, body, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{t.UserID})
+	if err != nil {
+		return fmt.Errorf("error getting slot alert tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[t.UserID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, tk := range compactTokens {
+		token := exponent.Token(tk)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending slot alert: %w", err)
+	}
+	return nil
+}
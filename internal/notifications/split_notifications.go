@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/paymentsplits"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendSplitRequest - tell participants they owe a share of a booking. Each
+// share has its own amount, so every user gets their own message.
+func SendSplitRequest(ctx context.Context, push PushSender, store *storage.Container, organizer string, venueName string, split *paymentsplits.Split) error {
+	for _, sh := range split.Shares {
+		if sh.UserID == split.CreatedBy || sh.Status == paymentsplits.SharePaid {
+			continue
+		}
+		title := "Split the booking 💸"
+		body := fmt.Sprintf("%s added you to a booking at %s. Your share is Rs. %d.", organizer, venueName, sh.Amount)
+		if err := sendSplitPush(ctx, push, store, sh.UserID, title, body, split.BookingID, sh.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendSplitReminder - nudge a participant whose share is still unpaid.
+func SendSplitReminder(ctx context.Context, push PushSender, store *storage.Container, rm paymentsplits.Reminder) error {
+	startsAt := rm.StartTime.In(nepalTime).Format("Mon 3:04 PM")
+	title := "Your share is still unpaid ⏰"
+	body := fmt.Sprintf("Pay Rs. %d for %s on %s so the booking can be confirmed.", rm.Amount, rm.VenueName, startsAt)
+	return sendSplitPush(ctx, push, store, rm.UserID, title, body, rm.BookingID, rm.ShareID)
+}
+
+func sendSplitPush(ctx context.Context, push PushSender, store *storage.Container, userID int64, title, body string, bookingID, shareID int64) error {
+	data := map[string]string{
+		"type":       "payment_split",
+		"booking_id": strconv.FormatInt(bookingID, 10),
+		"share_id":   strconv.FormatInt(shareID, 10),
+		"screen":     fmt.Sprintf("splits/%s", strconv.FormatInt(shareID, 10)),
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryBookingUpdates, []int64{userID})
+	if err != nil {
+		return fmt.Errorf("error getting split tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[userID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, tk := range compactTokens {
+		token := exponent.Token(tk)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending split notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendSupportReply - tell the ticket owner that support has replied.
+func SendSupportReply(ctx context.Context, push PushSender, store *storage.Container, userID, ticketID int64, subject string) error {
+
+	title := "Khel Support replied"
+	screen := fmt.Sprintf("support/%s", strconv.FormatInt(ticketID, 10))
+	data := map[string]string{
+		"type":      "support_reply",
+		"ticket_id": strconv.FormatInt(ticketID, 10),
+		"screen":    screen,
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, subject, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{userID})
+	if err != nil {
+		return fmt.Errorf("error getting ticket owner tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[userID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  subject,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending support reply notification: %w", err)
+	}
+	return nil
+}
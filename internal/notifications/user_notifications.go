@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendHistoryExportReady - tell the user their history CSV can be downloaded
+// from url until it expires.
+func SendHistoryExportReady(ctx context.Context, push PushSender, store *storage.Container, userID, exportID int64, url string) error {
+
+	title := "Your Khel history is ready"
+	body := "Tap to download your bookings and games as a CSV. The link works for 24 hours."
+	data := map[string]string{
+		"type":      "history_export_ready",
+		"export_id": strconv.FormatInt(exportID, 10),
+		"url":       url,
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{userID})
+	if err != nil {
+		return fmt.Errorf("error getting user tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[userID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending history export notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/notificationprefs"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendVenueAnnouncement - notify everyone who favorited the venue about a new announcement.
+// A venue nobody follows (or whose followers have no tokens) is not an error.
+func SendVenueAnnouncement(ctx context.Context, push PushSender, store *storage.Container, venueID, announcementID int64, venueName, title string) error {
+
+	userIDs, err := store.VenueAnnouncements.AudienceUserIDs(ctx, venueID)
+	if err != nil {
+		return fmt.Errorf("error getting venue followers: %w", err)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	screen := fmt.Sprintf("venues/%s", strconv.FormatInt(venueID, 10))
+	data := map[string]string{
+		"type":            "venue_announcement",
+		"venue_id":        strconv.FormatInt(venueID, 10),
+		"announcement_id": strconv.FormatInt(announcementID, 10),
+		"screen":          screen,
+	}
+
+	saveToInbox(ctx, store, userIDs, venueName, title, data)
+
+	tokensMap, err := pushTokensFor(ctx, store, notificationprefs.CategoryMarketing, userIDs)
+	if err != nil {
+		return fmt.Errorf("error getting follower tokens: %w", err)
+	}
+
+	allTokens := make([]string, 0)
+	for _, tokens := range tokensMap {
+		allTokens = append(allTokens, tokens...)
+	}
+	compactTokens := dedupe(allTokens)
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msg := &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: venueName,
+			Body:  title,
+			Data:  data,
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending venue announcement: %w", err)
+	}
+	return nil
+}
+
+// SendVenueReviewDecision - tell the owner a superadmin approved or rejected
+// their venue. It's transactional, so preferences don't apply.
+func SendVenueReviewDecision(ctx context.Context, push PushSender, store *storage.Container, ownerID, venueID int64, title, body string) error {
+
+	data := map[string]string{
+		"type":     "venue_review",
+		"venue_id": strconv.FormatInt(venueID, 10),
+		"screen":   fmt.Sprintf("venues/%s/verification", strconv.FormatInt(venueID, 10)),
+	}
+
+	saveToInbox(ctx, store, []int64{ownerID}, title, body, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{ownerID})
+	if err != nil {
+		return fmt.Errorf("error getting venue owner tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[ownerID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending venue review notification: %w", err)
+	}
+	return nil
+}
+
+// SendVenueStaffInvite - tell a user they were invited to work at a venue.
+func SendVenueStaffInvite(ctx context.Context, push PushSender, store *storage.Container, userID, venueID, invitationID int64, venueName, role string) error {
+
+	title := "Venue invitation"
+	body := fmt.Sprintf("You've been invited to join %s as %s.", venueName, role)
+	data := map[string]string{
+		"type":          "venue_staff_invite",
+		"venue_id":      strconv.FormatInt(venueID, 10),
+		"invitation_id": strconv.FormatInt(invitationID, 10),
+		"screen":        "venue-invitations",
+	}
+
+	saveToInbox(ctx, store, []int64{userID}, title, body, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{userID})
+	if err != nil {
+		return fmt.Errorf("error getting invitee tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[userID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, t := range compactTokens {
+		token := exponent.Token(t)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending venue invitation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"khel/internal/domain/products"
+	"khel/internal/domain/storage"
+	"strconv"
+
+	"github.com/9ssi7/exponent"
+)
+
+// SendWishlistPriceDrop - tell a shopper that a product they saved got
+// cheaper. Saving it was asking to hear about this, so like price alerts it
+// is not gated by marketing preferences.
+func SendWishlistPriceDrop(ctx context.Context, push PushSender, store *storage.Container, d products.WishlistPriceDrop) error {
+	title := "Price drop on your wishlist 🛒"
+	body := fmt.Sprintf("%s is now Rs. %s (was Rs. %s).", d.Name, formatCents(d.NewPriceCents), formatCents(d.OldPriceCents))
+	data := map[string]string{
+		"type":       "wishlist_price_drop",
+		"product_id": strconv.FormatInt(d.ProductID, 10),
+		"screen":     fmt.Sprintf("store/products/%s", d.Slug),
+	}
+	if d.VariantID != nil {
+		data["variant_id"] = strconv.FormatInt(*d.VariantID, 10)
+	}
+
+	saveToInbox(ctx, store, []int64{d.UserID}, title, body, data)
+
+	tokensMap, err := store.PushTokens.GetTokensByUserIDs(ctx, []int64{d.UserID})
+	if err != nil {
+		return fmt.Errorf("error getting wishlist tokens: %w", err)
+	}
+
+	compactTokens := dedupe(tokensMap[d.UserID])
+	if len(compactTokens) == 0 {
+		return nil
+	}
+
+	msgs := make([]*exponent.Message, 0, len(compactTokens))
+	for _, tk := range compactTokens {
+		token := exponent.Token(tk)
+		msgs = append(msgs, &exponent.Message{
+			To:    []*exponent.Token{&token},
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	if _, err := push.Publish(ctx, msgs); err != nil {
+		return fmt.Errorf("error sending wishlist price drop: %w", err)
+	}
+	return nil
+}
+
+// formatCents renders a store price in rupees, leaving off paisa when there
+// are none.
+func formatCents(cents int64) string {
+	if cents%100 == 0 {
+		return strconv.FormatInt(cents/100, 10)
+	}
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
@@ -0,0 +1,164 @@
+package params
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bind copies query parameters into the struct dst points to. Fields are
+// matched by their `query:"name"` tag; fields without one are skipped, except
+// embedded structs, which are bound into as well. Parameters that are missing
+// or blank leave the field alone, so defaults are set on dst beforehand.
+//
+// Supported field types are strings, bools, ints, floats and time.Time, plus
+// pointers to them; a pointer field is only set when the parameter is given.
+// Times are parsed as RFC3339 unless the field has a `layout:"..."` tag, and
+// in UTC unless it has a `tz:"..."` tag naming a location.
+//
+// A value that can't be parsed is reported as a *BindError. Range and enum
+// checks are left to validate tags, run on dst after Bind.
+func Bind(q url.Values, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("params: Bind needs a pointer to a struct, got %T", dst)
+	}
+	return bindStruct(q, v.Elem())
+}
+
+// BindError reports a query parameter whose value doesn't fit its field.
+type BindError struct {
+	Param string // query parameter name
+	Want  string // what the value should have looked like
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("invalid %s: must be %s", e.Param, e.Want)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func bindStruct(q url.Values, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+
+		name, ok := sf.Tag.Lookup("query")
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				if err := bindStruct(q, fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		raw := strings.TrimSpace(q.Get(name))
+		if raw == "" {
+			continue
+		}
+
+		if fv.Kind() == reflect.Pointer {
+			p := reflect.New(fv.Type().Elem())
+			if err := setValue(p.Elem(), raw, name, sf.Tag); err != nil {
+				return err
+			}
+			fv.Set(p)
+			continue
+		}
+		if err := setValue(fv, raw, name, sf.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setValue(fv reflect.Value, raw, name string, tag reflect.StructTag) error {
+	if fv.Type() == timeType {
+		layout := tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		loc := time.UTC
+		if tz := tag.Get("tz"); tz != "" {
+			l, err := time.LoadLocation(tz)
+			if err != nil {
+				return fmt.Errorf("params: load location %q for %s: %w", tz, name, err)
+			}
+			loc = l
+		}
+		tm, err := time.ParseInLocation(layout, raw, loc)
+		if err != nil {
+			return &BindError{Param: name, Want: "a time like " + layout}
+		}
+		fv.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return &BindError{Param: name, Want: "true or false"}
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return &BindError{Param: name, Want: "a whole number"}
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return &BindError{Param: name, Want: "a non-negative whole number"}
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return &BindError{Param: name, Want: "a number"}
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("params: unsupported type %s for %s", fv.Type(), name)
+	}
+	return nil
+}
+
+// LimitOffset is the ?limit=&offset= pair used by admin lists. Set the
+// default limit before binding.
+type LimitOffset struct {
+	Limit  int `query:"limit" validate:"min=1,max=100"`
+	Offset int `query:"offset" validate:"min=0"`
+}
+
+// Pagination converts to a Pagination with a best-effort page number.
+func (lo LimitOffset) Pagination() Pagination {
+	page := 1
+	if lo.Limit > 0 {
+		page = lo.Offset/lo.Limit + 1
+	}
+	return Pagination{Limit: lo.Limit, Offset: lo.Offset, Page: page}
+}
+
+// PageLimit is the ?page=&limit= pair. Set the defaults (page 1 and the
+// list's limit) before binding.
+type PageLimit struct {
+	Page  int `query:"page" validate:"min=1"`
+	Limit int `query:"limit" validate:"min=1,max=100"`
+}
+
+// Pagination converts to a Pagination with the matching offset.
+func (pl PageLimit) Pagination() Pagination {
+	return Pagination{Limit: pl.Limit, Page: pl.Page, Offset: (pl.Page - 1) * pl.Limit}
+}
@@ -0,0 +1,100 @@
+package params
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type listQuery struct {
+	LimitOffset
+	Search *string   `query:"search"`
+	Active *bool     `query:"active"`
+	Lat    float64   `query:"lat"`
+	Day    time.Time `query:"day" layout:"2006-01-02" tz:"Asia/Kathmandu"`
+	Ignore string
+}
+
+func TestBind(t *testing.T) {
+	q := url.Values{
+		"limit":  {"25"},
+		"offset": {" 50 "},
+		"search": {"futsal"},
+		"active": {"false"},
+		"lat":    {"27.7"},
+		"day":    {"2025-06-28"},
+		"Ignore": {"x"},
+	}
+	got := listQuery{LimitOffset: LimitOffset{Limit: 10}}
+	if err := Bind(q, &got); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	if got.Limit != 25 || got.Offset != 50 {
+		t.Errorf("LimitOffset = %+v, want 25/50", got.LimitOffset)
+	}
+	if got.Search == nil || *got.Search != "futsal" {
+		t.Errorf("Search = %v, want futsal", got.Search)
+	}
+	if got.Active == nil || *got.Active {
+		t.Errorf("Active = %v, want false", got.Active)
+	}
+	if got.Lat != 27.7 {
+		t.Errorf("Lat = %v, want 27.7", got.Lat)
+	}
+	if got.Day.Format(time.RFC3339) != "2025-06-28T00:00:00+05:45" {
+		t.Errorf("Day = %s, want midnight in Kathmandu", got.Day.Format(time.RFC3339))
+	}
+	if got.Ignore != "" {
+		t.Errorf("untagged field was bound: %q", got.Ignore)
+	}
+	if p := got.Pagination(); p.Page != 3 {
+		t.Errorf("Pagination().Page = %d, want 3", p.Page)
+	}
+}
+
+func TestBindKeepsDefaults(t *testing.T) {
+	got := listQuery{LimitOffset: LimitOffset{Limit: 10}}
+	if err := Bind(url.Values{"limit": {""}}, &got); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if got.Limit != 10 || got.Search != nil || got.Active != nil {
+		t.Errorf("got %+v, want defaults untouched", got)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		query url.Values
+		param string
+	}{
+		{url.Values{"limit": {"ten"}}, "limit"},
+		{url.Values{"active": {"maybe"}}, "active"},
+		{url.Values{"lat": {"north"}}, "lat"},
+		{url.Values{"day": {"28/06/2025"}}, "day"},
+	}
+	for _, tt := range tests {
+		var got listQuery
+		err := Bind(tt.query, &got)
+		var berr *BindError
+		if !errors.As(err, &berr) {
+			t.Errorf("Bind(%v) error = %v, want a *BindError", tt.query, err)
+			continue
+		}
+		if berr.Param != tt.param {
+			t.Errorf("Bind(%v) Param = %q, want %q", tt.query, berr.Param, tt.param)
+		}
+	}
+
+	if err := Bind(url.Values{}, listQuery{}); err == nil {
+		t.Error("Bind with a non-pointer: want an error")
+	}
+}
+
+func TestPageLimitPagination(t *testing.T) {
+	p := PageLimit{Page: 3, Limit: 20}.Pagination()
+	if p.Offset != 40 || p.Page != 3 || p.Limit != 20 {
+		t.Errorf("Pagination() = %+v, want offset 40", p)
+	}
+}
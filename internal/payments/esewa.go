@@ -177,9 +177,11 @@ func (e *EsewaAdapter) VerifyPayment(ctx context.Context, req PaymentVerifyReque
 
 	ref := transactionUUID // provider_ref for esewa in your system should be transaction_uuid
 	return PaymentVerifyResponse{
-		Success:  success,
-		State:    state,
-		Terminal: terminal,
+		Success:     success,
+		State:       state,
+		Terminal:    terminal,
+		ProviderRef: out.TransactionUUID,
+		Amount:      out.TotalAmount,
 		Raw: map[string]any{
 			"http_status":     resp.StatusCode,
 			"transactionUUID": ref,
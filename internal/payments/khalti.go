@@ -163,7 +163,72 @@ func (k *KhaltiAdapter) VerifyPayment(ctx context.Context, req PaymentVerifyRequ
 		State:    state,
 		Terminal: terminal,
 		// ProviderRef for Khalti should be pidx in your system
-		ProviderRef: pidx,
+		ProviderRef: res.Pidx,
+		Amount:      float64(res.TotalAmount) / 100,
+		Raw: map[string]any{
+			"http_status": resp.StatusCode,
+			"body":        json.RawMessage(raw),
+		},
+	}, nil
+}
+
+func (k *KhaltiAdapter) refundURL(transactionID string) string {
+	if k.IsProduction {
+		return "https://khalti.com/api/merchant-transaction/" + transactionID + "/refund/"
+	}
+	return "https://dev.khalti.com/api/merchant-transaction/" + transactionID + "/refund/"
+}
+
+// RefundPayment refunds all or part of a completed payment. Khalti refunds by
+// transaction id, so the pidx we store is looked up first.
+func (k *KhaltiAdapter) RefundPayment(ctx context.Context, req RefundRequest) (RefundResponse, error) {
+	pidx := strings.TrimSpace(req.TransactionID)
+	if pidx == "" {
+		return RefundResponse{}, fmt.Errorf("khalti refund requires pidx")
+	}
+
+	body, _ := json.Marshal(map[string]string{"pidx": pidx})
+	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, k.lookupURL(), bytes.NewBuffer(body))
+	httpReq.Header.Set("Authorization", "key "+k.SecretKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := k.httpClient.Do(httpReq)
+	if err != nil {
+		return RefundResponse{}, fmt.Errorf("khalti lookup request: %w", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	var lookup struct {
+		Status        string `json:"status"`
+		TransactionID string `json:"transaction_id"`
+	}
+	if err := json.Unmarshal(raw, &lookup); err != nil {
+		return RefundResponse{}, fmt.Errorf("khalti lookup decode: http=%d err=%w body=%s", resp.StatusCode, err, string(raw))
+	}
+	if !strings.EqualFold(lookup.Status, "Completed") || lookup.TransactionID == "" {
+		return RefundResponse{}, fmt.Errorf("khalti payment %s is not refundable (status %q)", pidx, lookup.Status)
+	}
+
+	body, _ = json.Marshal(map[string]int{"amount": int(req.Amount * 100)})
+	httpReq, _ = http.NewRequestWithContext(ctx, http.MethodPost, k.refundURL(lookup.TransactionID), bytes.NewBuffer(body))
+	httpReq.Header.Set("Authorization", "key "+k.SecretKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err = k.httpClient.Do(httpReq)
+	if err != nil {
+		return RefundResponse{}, fmt.Errorf("khalti refund request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ = io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return RefundResponse{}, fmt.Errorf("khalti refund failed: http=%d body=%s", resp.StatusCode, string(raw))
+	}
+
+	return RefundResponse{
+		ProviderRef: lookup.TransactionID,
+		State:       "Refunded",
 		Raw: map[string]any{
 			"http_status": resp.StatusCode,
 			"body":        json.RawMessage(raw),
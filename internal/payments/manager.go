@@ -5,6 +5,16 @@ import (
 	"fmt"
 )
 
+// Processor starts, verifies and refunds payments by gateway name. Handlers
+// depend on it rather than on *PaymentManager so tests can substitute a fake.
+type Processor interface {
+	InitiatePayment(ctx context.Context, method string, req PaymentRequest) (PaymentResponse, error)
+	VerifyPayment(ctx context.Context, method string, req PaymentVerifyRequest) (PaymentVerifyResponse, error)
+	RefundPayment(ctx context.Context, method string, req RefundRequest) (RefundResponse, error)
+}
+
+var _ Processor = (*PaymentManager)(nil)
+
 type PaymentManager struct {
 	gateways map[string]PaymentGateway
 }
@@ -0,0 +1,41 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrRefundNotSupported is returned for gateways without a refund API. The
+// refund has to be paid out by hand.
+var ErrRefundNotSupported = errors.New("gateway does not support refunds")
+
+type RefundRequest struct {
+	// TransactionID is the gateway reference stored for the payment (pidx
+	// for khalti, transaction_uuid for esewa).
+	TransactionID string
+	Amount        float64
+}
+
+type RefundResponse struct {
+	ProviderRef string         `json:"provider_ref,omitempty"`
+	State       string         `json:"state,omitempty"`
+	Raw         map[string]any `json:"raw,omitempty"`
+}
+
+// Refunder is implemented by gateways that can refund a completed payment.
+type Refunder interface {
+	RefundPayment(ctx context.Context, req RefundRequest) (RefundResponse, error)
+}
+
+func (m *PaymentManager) RefundPayment(ctx context.Context, method string, req RefundRequest) (RefundResponse, error) {
+	gateway, ok := m.gateways[method]
+	if !ok {
+		return RefundResponse{}, fmt.Errorf("gateway not registered: %s", method)
+	}
+	refunder, ok := gateway.(Refunder)
+	if !ok {
+		return RefundResponse{}, ErrRefundNotSupported
+	}
+	return refunder.RefundPayment(ctx, req)
+}
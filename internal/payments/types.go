@@ -38,6 +38,7 @@ type PaymentVerifyResponse struct {
 	Success     bool           `json:"success"`
 	Terminal    bool           `json:"terminal,omitempty"`
 	State       string         `json:"state,omitempty"`        // gateway state for debugging/decisioning
-	ProviderRef string         `json:"provider_ref,omitempty"` //used for khalti pidx
+	ProviderRef string         `json:"provider_ref,omitempty"` // the gateway's reference for the payment it checked (khalti pidx, esewa transaction_uuid)
+	Amount      float64        `json:"amount,omitempty"`       // what the gateway says the payment is for, in rupees
 	Raw         map[string]any `json:"raw,omitempty"`          // optional, safe metadata (no secrets)
 }
@@ -0,0 +1,223 @@
+package searchindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"khel/internal/domain/products"
+	"khel/internal/domain/search"
+	"khel/internal/domain/venues"
+	"khel/internal/tracing"
+	"net/http"
+	"time"
+)
+
+// settings per index. indexed_at is filterable so Prune can drop what a
+// full reindex didn't touch.
+var meiliSettings = map[string]map[string]any{
+	IndexVenues: {
+		"searchableAttributes": []string{"name", "sport", "address", "description"},
+		"filterableAttributes": []string{"indexed_at"},
+	},
+	IndexProducts: {
+		"searchableAttributes": []string{"name", "brand", "category", "description"},
+		"filterableAttributes": []string{"indexed_at"},
+	},
+}
+
+type meiliVenue struct {
+	search.VenueDocument
+	IndexedAt int64 `json:"indexed_at"`
+}
+
+type meiliProduct struct {
+	search.ProductDocument
+	IndexedAt int64 `json:"indexed_at"`
+}
+
+type meiliSearchResponse struct {
+	Hits []struct {
+		ID    int64   `json:"id"`
+		Score float64 `json:"_rankingScore"`
+	} `json:"hits"`
+	EstimatedTotalHits int `json:"estimatedTotalHits"`
+}
+
+// Meilisearch ranks with a Meilisearch server and hydrates the hits from
+// the database. Writes are queued by the server as tasks; failures show up
+// in its task list, not here.
+type Meilisearch struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+	venues     venues.Store
+	products   products.Store
+}
+
+var _ Indexer = (*Meilisearch)(nil)
+
+func NewMeilisearch(baseURL, apiKey string, v venues.Store, p products.Store) *Meilisearch {
+	return &Meilisearch{
+		url:    baseURL,
+		apiKey: apiKey,
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: tracing.Transport(tracing.KindSearch, nil),
+		},
+		venues:   v,
+		products: p,
+	}
+}
+
+func (m *Meilisearch) Name() string { return "meilisearch" }
+
+func (m *Meilisearch) SearchVenues(ctx context.Context, q string) ([]venues.VenueListingWithRank, error) {
+	res, err := m.search(ctx, IndexVenues, q, VenueLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, ranks := hitIDs(res)
+	listings, err := m.venues.ListingsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]venues.VenueListingWithRank, len(listings))
+	for i, v := range listings {
+		out[i] = venues.VenueListingWithRank{VenueListing: v, Rank: ranks[v.ID]}
+	}
+	return out, nil
+}
+
+func (m *Meilisearch) SearchProducts(ctx context.Context, q string, limit, offset int) ([]*products.ProductCardWithRank, int, error) {
+	res, err := m.search(ctx, IndexProducts, q, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids, ranks := hitIDs(res)
+	cards, err := m.products.ListCardsByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]*products.ProductCardWithRank, len(cards))
+	for i, c := range cards {
+		out[i] = &products.ProductCardWithRank{ProductCard: *c, Rank: ranks[c.ID]}
+	}
+	return out, res.EstimatedTotalHits, nil
+}
+
+func hitIDs(res *meiliSearchResponse) ([]int64, map[int64]float64) {
+	ids := make([]int64, len(res.Hits))
+	ranks := make(map[int64]float64, len(res.Hits))
+	for i, h := range res.Hits {
+		ids[i] = h.ID
+		ranks[h.ID] = h.Score
+	}
+	return ids, ranks
+}
+
+func (m *Meilisearch) search(ctx context.Context, index, q string, limit, offset int) (*meiliSearchResponse, error) {
+	var res meiliSearchResponse
+	err := m.do(ctx, http.MethodPost, "/indexes/"+index+"/search", map[string]any{
+		"q":                    q,
+		"limit":                limit,
+		"offset":               offset,
+		"attributesToRetrieve": []string{"id"},
+		"showRankingScore":     true,
+	}, &res)
+	if err != nil {
+		return nil, fmt.Errorf("meilisearch search %s: %w", index, err)
+	}
+	return &res, nil
+}
+
+func (m *Meilisearch) Configure(ctx context.Context) error {
+	for _, index := range []string{IndexVenues, IndexProducts} {
+		if err := m.do(ctx, http.MethodPatch, "/indexes/"+index+"/settings", meiliSettings[index], nil); err != nil {
+			return fmt.Errorf("meilisearch configure %s: %w", index, err)
+		}
+	}
+	return nil
+}
+
+func (m *Meilisearch) UpsertVenues(ctx context.Context, docs []search.VenueDocument, indexedAt time.Time) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	body := make([]meiliVenue, len(docs))
+	for i, d := range docs {
+		body[i] = meiliVenue{VenueDocument: d, IndexedAt: indexedAt.Unix()}
+	}
+	return m.upsert(ctx, IndexVenues, body)
+}
+
+func (m *Meilisearch) UpsertProducts(ctx context.Context, docs []search.ProductDocument, indexedAt time.Time) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	body := make([]meiliProduct, len(docs))
+	for i, d := range docs {
+		body[i] = meiliProduct{ProductDocument: d, IndexedAt: indexedAt.Unix()}
+	}
+	return m.upsert(ctx, IndexProducts, body)
+}
+
+func (m *Meilisearch) upsert(ctx context.Context, index string, docs any) error {
+	if err := m.do(ctx, http.MethodPost, "/indexes/"+index+"/documents?primaryKey=id", docs, nil); err != nil {
+		return fmt.Errorf("meilisearch upsert %s: %w", index, err)
+	}
+	return nil
+}
+
+func (m *Meilisearch) Delete(ctx context.Context, index string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := m.do(ctx, http.MethodPost, "/indexes/"+index+"/documents/delete-batch", ids, nil); err != nil {
+		return fmt.Errorf("meilisearch delete %s: %w", index, err)
+	}
+	return nil
+}
+
+func (m *Meilisearch) Prune(ctx context.Context, index string, t time.Time) error {
+	err := m.do(ctx, http.MethodPost, "/indexes/"+index+"/documents/delete", map[string]any{
+		"filter": fmt.Sprintf("indexed_at < %d", t.Unix()),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("meilisearch prune %s: %w", index, err)
+	}
+	return nil
+}
+
+func (m *Meilisearch) do(ctx context.Context, method, path string, body, out any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.url+path, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,65 @@
+// Package searchindex puts the full-text search endpoints behind a Backend,
+// so the ranking can come from Postgres FTS (the default) or from an
+// external engine that venues and products are mirrored into.
+package searchindex
+
+import (
+	"context"
+	"khel/internal/domain/products"
+	"khel/internal/domain/search"
+	"khel/internal/domain/venues"
+	"time"
+)
+
+// Index names, shared by every Indexer.
+const (
+	IndexVenues   = "venues"
+	IndexProducts = "products"
+)
+
+// VenueLimit is how many venues a venue search returns, as the Postgres
+// search always has.
+const VenueLimit = 8
+
+// Backend answers the full-text search endpoints. Results are always read
+// from the database, so they never show stale names or prices.
+type Backend interface {
+	Name() string
+	SearchVenues(ctx context.Context, q string) ([]venues.VenueListingWithRank, error)
+	SearchProducts(ctx context.Context, q string, limit, offset int) ([]*products.ProductCardWithRank, int, error)
+}
+
+// Indexer is a Backend that keeps its own copy of the documents. Writes
+// may be applied by the engine after they return.
+type Indexer interface {
+	Backend
+	// Configure creates the indexes and sets which fields are searched.
+	Configure(ctx context.Context) error
+	UpsertVenues(ctx context.Context, docs []search.VenueDocument, indexedAt time.Time) error
+	UpsertProducts(ctx context.Context, docs []search.ProductDocument, indexedAt time.Time) error
+	Delete(ctx context.Context, index string, ids []int64) error
+	// Prune deletes documents last written before t, after a full reindex
+	// wrote every live one.
+	Prune(ctx context.Context, index string, t time.Time) error
+}
+
+type postgres struct {
+	venues   venues.Store
+	products products.Store
+}
+
+// NewPostgres returns the default Backend, the tables' own FTS columns.
+// They are kept up to date by Postgres, so there is nothing to index.
+func NewPostgres(v venues.Store, p products.Store) Backend {
+	return &postgres{venues: v, products: p}
+}
+
+func (b *postgres) Name() string { return "postgres" }
+
+func (b *postgres) SearchVenues(ctx context.Context, q string) ([]venues.VenueListingWithRank, error) {
+	return b.venues.FullTextSearchVenues(ctx, q)
+}
+
+func (b *postgres) SearchProducts(ctx context.Context, q string, limit, offset int) ([]*products.ProductCardWithRank, int, error) {
+	return b.products.FullTextSearchProducts(ctx, q, limit, offset)
+}
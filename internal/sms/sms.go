@@ -0,0 +1,89 @@
+// Package sms sends text messages to Nepali mobile numbers.
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"khel/internal/tracing"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Sender interface {
+	// Send texts body to phone, a 10-digit mobile number.
+	Send(ctx context.Context, phone, body string) error
+}
+
+const sparrowURL = "https://api.sparrowsms.com/v2/sms/"
+
+type sparrow struct {
+	token  string
+	from   string
+	client *http.Client
+}
+
+// NewSparrow sends through Sparrow SMS with the account's token and
+// approved sender identity.
+func NewSparrow(token, from string) Sender {
+	return &sparrow{
+		token: token,
+		from:  from,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: tracing.Transport(tracing.KindSMS, nil),
+		},
+	}
+}
+
+func (s *sparrow) Send(ctx context.Context, phone, body string) error {
+	form := url.Values{}
+	form.Set("token", s.token)
+	form.Set("from", s.from)
+	form.Set("to", phone)
+	form.Set("text", body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sparrowURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sparrow sms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var out struct {
+			Response string `json:"response"`
+		}
+		if json.Unmarshal(raw, &out) == nil && out.Response != "" {
+			return fmt.Errorf("sparrow sms: %s (status %d)", out.Response, resp.StatusCode)
+		}
+		return fmt.Errorf("sparrow sms: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type logSender struct {
+	logger *zap.SugaredLogger
+}
+
+// NewLog writes messages to logger instead of sending them, for
+// development.
+func NewLog(logger *zap.SugaredLogger) Sender {
+	return logSender{logger: logger}
+}
+
+func (l logSender) Send(_ context.Context, phone, body string) error {
+	l.logger.Infow("sms not sent (log backend)", "to", phone, "body", body)
+	return nil
+}
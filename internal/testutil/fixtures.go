@@ -0,0 +1,80 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var fixtureSeq atomic.Int64
+
+// CreateUser inserts an active user and returns its ID.
+func CreateUser(t testing.TB, db *pgxpool.Pool) int64 {
+	t.Helper()
+
+	n := fixtureSeq.Add(1)
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO users (email, phone, password, first_name, last_name, is_active)
+		VALUES ($1, $2, 'x', 'Test', $3, TRUE)
+		RETURNING id
+	`, fmt.Sprintf("user%d@test.khel.dev", n), fmt.Sprintf("96%08d", n), fmt.Sprintf("User %d", n)).Scan(&id)
+	if err != nil {
+		t.Fatalf("testutil: create user: %v", err)
+	}
+	return id
+}
+
+// Venue describes a venue to insert. Zero fields get defaults.
+type Venue struct {
+	OwnerID  int64
+	Name     string
+	Sport    string
+	Status   string
+	Lng, Lat float64
+}
+
+// CreateVenue inserts a venue with its default facility and returns both
+// IDs. Without an OwnerID a new user owns it.
+func CreateVenue(t testing.TB, db *pgxpool.Pool, v Venue) (venueID, facilityID int64) {
+	t.Helper()
+
+	if v.OwnerID == 0 {
+		v.OwnerID = CreateUser(t, db)
+	}
+	if v.Name == "" {
+		v.Name = fmt.Sprintf("Venue %d", fixtureSeq.Add(1))
+	}
+	if v.Sport == "" {
+		v.Sport = "futsal"
+	}
+	if v.Status == "" {
+		v.Status = "active"
+	}
+	if v.Lng == 0 && v.Lat == 0 {
+		v.Lng, v.Lat = 85.3240, 27.7172 // Kathmandu
+	}
+
+	ctx := context.Background()
+	err := db.QueryRow(ctx, `
+		INSERT INTO venues (owner_id, name, address, location, sport, phone_number, status)
+		VALUES ($1, $2, 'Kathmandu', ST_SetSRID(ST_MakePoint($3, $4), 4326)::geography, $5, '9800000000', $6::venue_status)
+		RETURNING id
+	`, v.OwnerID, v.Name, v.Lng, v.Lat, v.Sport, v.Status).Scan(&venueID)
+	if err != nil {
+		t.Fatalf("testutil: create venue: %v", err)
+	}
+
+	err = db.QueryRow(ctx, `
+		INSERT INTO facilities (venue_id, name, sport, is_default)
+		VALUES ($1, 'Main Facility', $2, TRUE)
+		RETURNING id
+	`, venueID, v.Sport).Scan(&facilityID)
+	if err != nil {
+		t.Fatalf("testutil: create facility: %v", err)
+	}
+	return venueID, facilityID
+}
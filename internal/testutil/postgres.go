@@ -0,0 +1,257 @@
+// Package testutil runs repository tests against a real Postgres with
+// PostGIS. The first test that asks for a database starts a throwaway
+// container with the docker CLI and applies every migration to a template
+// database; each test then gets its own copy of that template, so tests
+// can run in parallel and never see each other's rows.
+//
+// Set KHEL_TEST_DATABASE_URL to a server you already run (the user must be
+// able to create databases) to skip docker. Without either, tests that need
+// a database are skipped.
+//
+// Repository tests carry the integration build tag:
+//
+//	go test -tags integration ./internal/domain/...
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	image        = "postgis/postgis:16-3.4"
+	templateName = "khel_template"
+	password     = "khel-test"
+)
+
+var (
+	setupOnce sync.Once
+	serverURL *url.URL // points at the postgres database of the server
+	setupErr  error
+	container string
+	dbCounter atomic.Int64
+)
+
+// Main runs the package's tests and removes the container afterwards. Use
+// it from TestMain:
+//
+//	func TestMain(m *testing.M) { testutil.Main(m) }
+func Main(m *testing.M) {
+	code := m.Run()
+	if container != "" {
+		_ = exec.Command("docker", "rm", "-f", container).Run()
+	}
+	os.Exit(code)
+}
+
+// DB returns a pool on a fresh database with the full migrated schema. The
+// database is dropped when the test ends.
+func DB(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	setupOnce.Do(func() { setupErr = setup() })
+	if errors.Is(setupErr, errNoServer) {
+		t.Skip(setupErr)
+	}
+	if setupErr != nil {
+		t.Fatalf("testutil: %v", setupErr)
+	}
+
+	name := fmt.Sprintf("khel_test_%d_%d", os.Getpid(), dbCounter.Add(1))
+	ctx := context.Background()
+	if err := adminExec(ctx, fmt.Sprintf(`CREATE DATABASE %s TEMPLATE %s`, name, templateName)); err != nil {
+		t.Fatalf("testutil: create database: %v", err)
+	}
+
+	pool, err := pgxpool.New(ctx, dbURL(name))
+	if err != nil {
+		t.Fatalf("testutil: connect: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		if err := adminExec(context.Background(), fmt.Sprintf(`DROP DATABASE IF EXISTS %s WITH (FORCE)`, name)); err != nil {
+			t.Logf("testutil: drop %s: %v", name, err)
+		}
+	})
+	return pool
+}
+
+// Tx begins a transaction on pool that is rolled back when the test ends.
+// It suits fixtures and queries that take a pgx.Tx; repositories that hold
+// the pool see only what is committed.
+func Tx(t testing.TB, pool *pgxpool.Pool) pgx.Tx {
+	t.Helper()
+
+	tx, err := pool.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("testutil: begin: %v", err)
+	}
+	t.Cleanup(func() { _ = tx.Rollback(context.Background()) })
+	return tx
+}
+
+var errNoServer = errors.New("no test database: set KHEL_TEST_DATABASE_URL or install docker")
+
+func setup() error {
+	raw := os.Getenv("KHEL_TEST_DATABASE_URL")
+	if raw == "" {
+		var err error
+		if raw, err = startContainer(); err != nil {
+			return err
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("parse database url: %w", err)
+	}
+	serverURL = u
+
+	if err := waitReady(raw, time.Minute); err != nil {
+		return err
+	}
+	return buildTemplate()
+}
+
+func startContainer() (string, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return "", errNoServer
+	}
+
+	out, err := exec.Command("docker", "run", "-d", "--rm",
+		"-e", "POSTGRES_PASSWORD="+password,
+		"-p", "127.0.0.1::5432",
+		image,
+	).Output()
+	if err != nil {
+		return "", fmt.Errorf("start %s: %w", image, cmdErr(err))
+	}
+	container = strings.TrimSpace(string(out))
+
+	out, err = exec.Command("docker", "port", container, "5432/tcp").Output()
+	if err != nil {
+		return "", fmt.Errorf("find container port: %w", cmdErr(err))
+	}
+	// "127.0.0.1:49153", possibly followed by an IPv6 line.
+	hostPort := strings.TrimSpace(strings.Split(string(out), "\n")[0])
+
+	return fmt.Sprintf("postgres://postgres:%s@%s/postgres?sslmode=disable", password, hostPort), nil
+}
+
+func waitReady(raw string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		conn, err := pgx.Connect(ctx, raw)
+		if err == nil {
+			err = conn.Ping(ctx)
+			conn.Close(ctx)
+		}
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("database not ready after %s: %w", timeout, err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// buildTemplate creates the template database and applies every up
+// migration to it in order, the way golang-migrate would.
+func buildTemplate() error {
+	ctx := context.Background()
+	if err := adminExec(ctx, `DROP DATABASE IF EXISTS `+templateName); err != nil {
+		return fmt.Errorf("drop old template: %w", err)
+	}
+	if err := adminExec(ctx, `CREATE DATABASE `+templateName); err != nil {
+		return fmt.Errorf("create template: %w", err)
+	}
+
+	dir, err := migrationsDir()
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	conn, err := pgx.Connect(ctx, dbURL(templateName))
+	if err != nil {
+		return fmt.Errorf("connect to template: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	for _, f := range files {
+		sql, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		// No arguments, so pgx uses the simple protocol and a file may hold
+		// several statements.
+		if _, err := conn.Exec(ctx, string(sql)); err != nil {
+			return fmt.Errorf("migration %s: %w", filepath.Base(f), err)
+		}
+	}
+	return nil
+}
+
+// migrationsDir finds cmd/migrate/migrations from the package under test by
+// walking up to the module root.
+func migrationsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.Join(dir, "cmd", "migrate", "migrations"), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("go.mod not found above the test's directory")
+		}
+		dir = parent
+	}
+}
+
+func adminExec(ctx context.Context, sql string) error {
+	conn, err := pgx.Connect(ctx, serverURL.String())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	_, err = conn.Exec(ctx, sql)
+	return err
+}
+
+func dbURL(name string) string {
+	u := *serverURL
+	u.Path = "/" + name
+	return u.String()
+}
+
+func cmdErr(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
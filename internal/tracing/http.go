@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport wraps base so each outbound call is a client span against kind
+// and carries the request's trace context to the remote service.
+func Transport(kind string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return kind + " " + r.Method
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(semconv.PeerService(kind))),
+	)
+}
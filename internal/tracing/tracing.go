@@ -0,0 +1,108 @@
+// Package tracing sets up OpenTelemetry for the API: a tracer provider that
+// exports spans over OTLP/HTTP, W3C trace context propagation, and the
+// instrumentation shared by the database pool and outbound HTTP clients.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName scopes the spans this package and its callers create.
+const instrumentationName = "khel"
+
+// Kinds name the services outbound spans are recorded against
+// (peer.service).
+const (
+	KindCloudinary = "cloudinary"
+	KindExpo       = "expo"
+	KindMailtrap   = "mailtrap"
+	KindSearch     = "search"
+	KindSMS        = "sms"
+	KindStorage    = "storage"
+)
+
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// OTLPEndpoint is the collector's OTLP/HTTP URL, e.g.
+	// http://otel-collector:4318. Spans are not exported when it is empty.
+	OTLPEndpoint string
+}
+
+// Setup installs the global tracer provider and propagator. Spans are always
+// created, so every request has a trace ID for the logs, but only leave the
+// process when cfg.OTLPEndpoint is set. The returned func flushes spans not
+// yet exported and should be called on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(cfg.ServiceVersion),
+			semconv.DeploymentEnvironment(cfg.Environment),
+		)),
+	}
+	if cfg.OTLPEndpoint != "" {
+		exp, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return tp.Shutdown, nil
+}
+
+// Start opens a span for work that isn't an HTTP call or a query, such as
+// an SMTP send:
+//
+//	ctx, span := tracing.Start(ctx, "mailtrap send")
+//	defer func() { tracing.End(span, err) }()
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marks span failed when err is set, then ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the hex trace ID of the span in ctx, or "" when there is
+// none.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// NewID returns n random bytes hex encoded.
+func NewID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}